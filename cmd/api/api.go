@@ -5,21 +5,32 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/balebbae/RESA/docs" // This is required to genearte swagger docs
 	"github.com/balebbae/RESA/internal/auth"
-	"github.com/balebbae/RESA/internal/env"
+	"github.com/balebbae/RESA/internal/billing"
+	"github.com/balebbae/RESA/internal/features"
+	"github.com/balebbae/RESA/internal/integrations/holidays"
+	"github.com/balebbae/RESA/internal/integrations/weather"
 	"github.com/balebbae/RESA/internal/mailer"
 	"github.com/balebbae/RESA/internal/ratelimiter"
+	"github.com/balebbae/RESA/internal/storage"
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/balebbae/RESA/internal/webhooks"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 
+	"github.com/andybalholm/brotli"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -32,13 +43,37 @@ type application struct {
 	cacheStorage  cache.Storage
 	logger        *zap.SugaredLogger
 	mailer        mailer.Client
+	// emailEvents verifies SendGrid's event webhook; nil when it isn't configured
+	emailEvents   *mailer.EventVerifier
 	authenticator auth.Authenticator
 	oauthProvider *auth.GoogleOAuthProvider
 	rateLimiter   ratelimiter.Limiter
+	billing       billing.Client
+	features      *features.Resolver
+	blobs         storage.Blob
+	// weather forecasts schedule days; nil when the integration is off
+	weather weather.Forecaster
+	// holidays lists the public holidays added to special dates; nil when the integration is off
+	holidays holidays.Provider
+	// webhooks posts queued events to the endpoints restaurants register
+	webhooks *webhooks.Sender
+	// queryMetrics times the store's queries; nil when they aren't instrumented
+	queryMetrics *store.QueryMetrics
+	// cacheStaleness tallies what the cache verifier finds; nil when it isn't running
+	cacheStaleness *cacheStaleness
+	// runtime holds the settings that reload without a restart; see settings()
+	runtime          atomic.Pointer[runtimeSettings]
+	settingsWatchers []func(old, new *runtimeSettings)
+	reloadMu         sync.Mutex
+	// envFile is re-read on reload; nil reads only the process environment
+	envFile *envFile
+	// maintenanceSwitch is the operator's maintenance setting; see maintenance()
+	maintenanceSwitch maintenanceSwitch
 }
 
 type config struct {
 	addr string
+	grpcAddr string
 	db dbConfig
 	env string
 	apiURL string
@@ -46,7 +81,30 @@ type config struct {
 	frontendURL string
 	auth authConfig
 	redisCfg redisConfig
-	rateLimiter ratelimiter.Config
+	billing billing.Config
+	storage storage.Config
+	weather weather.Config
+	holidays holidays.Config
+	cors corsConfig
+	uploads uploadConfig
+	repairInterval time.Duration
+	invitationSweepInterval time.Duration
+	scheduleRetentionInterval time.Duration
+	milestoneDigestInterval time.Duration
+	messageDeliveryInterval time.Duration
+	leaveAccrualInterval time.Duration
+	webhookDeliveryInterval time.Duration
+	savedReportInterval time.Duration
+	notificationDigestInterval time.Duration
+	retentionPolicyInterval time.Duration
+	holidayPopulationInterval time.Duration
+	cacheVerify cacheVerifyConfig
+	requestLog requestLogConfig
+}
+
+type uploadConfig struct {
+	maxBytes int64
+	urlExpiry time.Duration
 }
 
 type redisConfig struct {
@@ -66,6 +124,15 @@ type tokenConfig struct {
 	secret string
 	exp time.Duration
 	iss string
+	aud string
+	// keyID names the signing key in each token's kid header
+	keyID string
+	// previousSecrets are retired "kid:secret" HS256 keys still accepted
+	previousSecrets string
+	// rsaPrivateKeyFile switches signing to RS256; secret then only verifies older tokens
+	rsaPrivateKeyFile string
+	// previousRSAKeyFiles are retired "kid:path" RS256 public keys still accepted
+	previousRSAKeyFiles string
 }
 
 type basicConfig struct {
@@ -83,17 +150,42 @@ type mailConfig struct {
 	sendGrid sendGridConfig
 	fromEmail string
 	exp time.Duration
+	// userText is how shift notes and event descriptions are rendered in emails
+	userText mailer.TextPolicy
+}
+
+// emailQuotaConfig caps email blasts per restaurant; a limit of 0 turns the quota off
+type emailQuotaConfig struct {
+	limit int
+	window time.Duration
 }
 
 type sendGridConfig struct {
 	apiKey string
+	// webhookPublicKey verifies the signed event webhook; empty turns the webhook off
+	webhookPublicKey string
 }
 
 type dbConfig struct {
 	addr string
+	// replicaAddr is an optional read replica for list queries; empty reads from addr
+	replicaAddr string
+	// maxOpenConns caps the pgx pool; minConns stay open once maxIdleTime closes the rest
 	maxOpenConns int
-	maxIdleConns int
+	minConns int
 	maxIdleTime string
+	maxLifetime string
+	// statementCacheCapacity is how many prepared statements each connection keeps;
+	// 0 describes every query instead, for poolers that can't keep prepared statements
+	statementCacheCapacity int
+	// queryTimeout and batchQueryTimeout cap single store calls and batch writes
+	queryTimeout time.Duration
+	batchQueryTimeout time.Duration
+	// slowQueryThreshold is how long a query runs before it's logged as slow; 0 never logs
+	slowQueryThreshold time.Duration
+	// rowLevelSecurity scopes the transactions of restaurant routes to the restaurant
+	// through the row-level security policies, behind the handlers' own checks
+	rowLevelSecurity bool
 }
 
 func (app *application) mount() http.Handler {
@@ -101,168 +193,539 @@ func (app *application) mount() http.Handler {
 
 	r.Use(middleware.RequestID)
   	r.Use(middleware.RealIP)
-  	r.Use(middleware.Logger)
+  	r.Use(app.requestLoggerMiddleware())
   	r.Use(middleware.Recoverer)
 	  
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{env.GetString("CORS_ALLOWED_ORIGIN", "http://localhost:3000")},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false,
-		MaxAge:           300,
-	}))
+	r.Use(cors.Handler(app.corsOptions()))
 	
-	if app.config.rateLimiter.Enabled {
-		r.Use(app.RateLimiterMiddleware)
-	}	
+	r.Use(app.RateLimiterMiddleware)
 
 	r.Use(middleware.Timeout(60 * time.Second))
+
+	// gzip/deflate/brotli for JSON responses, negotiated via Accept-Encoding
+	compressor := middleware.NewCompressor(5, "application/json")
+	compressor.SetEncoder("br", func(w io.Writer, level int) io.Writer {
+		return brotli.NewWriterLevel(w, level)
+	})
+	r.Use(compressor.Handler)
+	r.Use(primaryForWritesMiddleware)
 	
 	r.Route("/v1", func(r chi.Router) {
-		// Public + basic‑auth
-
-		// operations
-		r.With(app.BasicAuthMiddleware()).Get("/health", app.healthCheckHandler) // Basic auth middleware
-		r.With(app.BasicAuthMiddleware()).Get("/debug/vars", expvar.Handler().ServeHTTP)
+		r.Use(withAPIVersion(apiV1))
+		app.routes(r)
+	})
 
-		docsURL := fmt.Sprintf("%s/swagger/doc.json", app.config.addr)
-		r.With(app.BasicAuthMiddleware()).Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(docsURL))) // Basic auth middleware
+	// v2 serves the same routes with every response in the data/meta/errors envelope
+	r.Route("/v2", func(r chi.Router) {
+		r.Use(withAPIVersion(apiV2))
+		app.routes(r)
+	})
 
-		// Authentication (public)
-		r.Route("/authentication", func(r chi.Router) {
-			r.Post("/user", app.registerUserHandler)
-			r.Post("/token", app.createTokenHandler)
-			r.Post("/refresh", app.refreshTokenHandler)
-			r.Post("/resend-confirmation", app.resendConfirmationHandler)
+	return r
+}
 
-			// Google OAuth routes
-			r.Post("/google", app.googleLoginHandler)
-			r.Post("/google/callback", app.googleCallbackHandler)
-		})
-		
-		// User self‑service 
-		r.Route("/users", func(r chi.Router) {
-			r.Put("/activate/{token}", app.activateUserHandler)
+// routes registers the API's endpoints; mount calls it once per version
+func (app *application) routes(r chi.Router) {
+	r.Use(app.maintenanceMiddleware)
+
+	// Public + basic‑auth
+
+	// operations
+	r.With(app.BasicAuthMiddleware()).Get("/health", app.healthCheckHandler) // Basic auth middleware
+	r.With(app.BasicAuthMiddleware()).Get("/debug/vars", expvar.Handler().ServeHTTP)
+	r.With(app.BasicAuthMiddleware()).Get("/debug/queries", app.queryDiagnosticsHandler)
+	r.With(app.BasicAuthMiddleware()).Post("/debug/reload", app.reloadSettingsHandler)
+	r.With(app.BasicAuthMiddleware()).Get("/debug/maintenance", app.getMaintenanceHandler)
+	r.With(app.BasicAuthMiddleware()).Put("/debug/maintenance", app.updateMaintenanceHandler)
+	r.With(app.BasicAuthMiddleware()).Delete("/debug/maintenance", app.clearMaintenanceHandler)
+
+	// the caller's rate limit budget
+	r.Get("/rate-limit", app.getRateLimitHandler)
+
+	docsURL := fmt.Sprintf("%s/swagger/doc.json", app.config.addr)
+	r.With(app.BasicAuthMiddleware()).Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL(docsURL))) // Basic auth middleware
+
+	// Authentication (public)
+	r.Route("/authentication", func(r chi.Router) {
+		r.Post("/user", app.registerUserHandler)
+		r.Post("/token", app.createTokenHandler)
+		r.Post("/token/verify", app.verifyLoginHandler)
+		r.Post("/refresh", app.refreshTokenHandler)
+		r.Post("/resend-confirmation", app.resendConfirmationHandler)
+		r.Get("/activation-status", app.activationStatusHandler)
+		r.Get("/jwks.json", app.getJWKSHandler)
+
+		// Google OAuth routes
+		r.Post("/google", app.googleLoginHandler)
+		r.Post("/google/callback", app.googleCallbackHandler)
+	})
+	
+	// Employee profile photos (public; the avatar ID in the URL is the capability)
+	r.Get("/avatars/{employeeID}/{avatarID}", app.getAvatarHandler)
 
-			// r.With(app.AuthTokenMiddleware).Get("/me", app.getCurrentUserHandler)
-			// r.With(app.AuthTokenMiddleware).Patch("/me", app.updateCurrentUserHandler)
-		})
+	// Billing (Stripe)
+	r.Route("/billing", func(r chi.Router) {
+		r.Post("/webhook", app.billingWebhookHandler)
+		r.With(app.AuthTokenMiddleware).Get("/portal", app.getBillingPortalHandler)
+	})
 
-		// All app features require valid JWT 
-		r.Route("/restaurants", func(r chi.Router) { 
-			r.Use(app.AuthTokenMiddleware) 
-			r.Post("/", app.createRestaurantHandler)
-			r.Get("/",  app.getRestaurantsHandler)
+	// SendGrid delivery events for schedule emails
+	r.Post("/email/events", app.emailEventWebhookHandler)
 
+	// User self‑service 
+	r.Route("/users", func(r chi.Router) {
+		r.Put("/activate/{token}", app.activateUserHandler)
+		r.With(app.AuthTokenMiddleware).Put("/me/locale", app.updateUserLocaleHandler)
+		r.With(app.AuthTokenMiddleware).Get("/me/data-export", app.userDataExportHandler)
+		r.With(app.AuthTokenMiddleware).Get("/me/memberships", app.getMyMembershipsHandler)
 
-			r.Route("/{restaurantID}", func(r chi.Router){ 
-				r.Use(app.restaurantsContextMiddleware)
+		r.With(app.AuthTokenMiddleware).Get("/me/security-events", app.listSecurityEventsHandler)
 
-				// restaurant CRUD
-				r.Get("/", app.getRestaurantHandler)
-				r.Patch("/", app.checkRestaurantOwnership(app.updateRestaurantHandler)) 
-				r.Delete("/", app.checkRestaurantOwnership(app.deleteRestaurantHandler)) 
+		// signed-in devices
+		r.Route("/me/sessions", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/", app.listSessionsHandler)
+			r.Delete("/{sessionID}", app.revokeSessionHandler)
+		})
 
-				// roles
-				r.Route("/roles", func(r chi.Router) {
-					r.Get("/",  app.getRolesHandler)
-					r.Post("/", app.checkRestaurantOwnership(app.createRoleHandler))
-					r.Route("/{roleID}", func(r chi.Router) {
-						r.Get("/",    app.getRoleHandler)
-						r.Patch("/",  app.checkRestaurantOwnership(app.updateRoleHandler))
-						r.Delete("/", app.checkRestaurantOwnership(app.deleteRoleHandler))
+		// two-factor authentication with an authenticator app
+		r.Route("/me/two-factor", func(r chi.Router) {
+			r.Use(app.AuthTokenMiddleware)
+			r.Get("/", app.getTwoFactorHandler)
+			r.Put("/", app.updateTwoFactorHandler)
+			r.Post("/enroll", app.enrollTwoFactorHandler)
+			r.Post("/confirm", app.confirmTwoFactorHandler)
+			r.Post("/recovery-codes", app.regenerateRecoveryCodesHandler)
+		})
 
-						// get employees for role
-						r.Get("/employees", app.getRoleEmployeesHandler)
-					})
-				})
+		// r.With(app.AuthTokenMiddleware).Get("/me", app.getCurrentUserHandler)
+		// r.With(app.AuthTokenMiddleware).Patch("/me", app.updateCurrentUserHandler)
+	})
 
-				// employees
-				r.Route("/employees", func(r chi.Router) {
-					r.Get("/",  app.getEmployeesHandler)
-					r.Post("/", app.checkRestaurantOwnership(app.createEmployeeHandler))
-					r.Route("/{employeeID}", func(r chi.Router) {
-						r.Get("/",    app.getEmployeeHandler)
-						r.Patch("/",  app.checkRestaurantOwnership(app.updateEmployeeHandler))
-						r.Delete("/", app.checkRestaurantOwnership(app.deleteEmployeeHandler))
-
-						// manage employee ⇄ role
-						r.Get("/roles",                 app.getEmployeeRolesHandler)
-						r.Post("/roles",                app.checkRestaurantOwnership(app.addEmployeeRolesHandler))
-						r.Delete("/roles/{roleID}",     app.checkRestaurantOwnership(app.removeEmployeeRoleHandler))
-					})
-				})
+	// in-app notifications for the signed-in owner
+	r.Route("/notifications", func(r chi.Router) {
+		r.Use(app.AuthTokenMiddleware)
+		r.Get("/", app.getNotificationsHandler)
+		r.Get("/unread-count", app.getUnreadNotificationCountHandler)
+		r.Post("/read-all", app.markAllNotificationsReadHandler)
+		r.Post("/{notificationID}/read", app.markNotificationReadHandler)
+	})
 
-				// recurring shift templates
-				r.Route("/shift-templates", func(r chi.Router) {
-					r.Get("/",  app.getShiftTemplatesHandler)
-					r.Post("/", app.checkRestaurantOwnership(app.createShiftTemplateHandler))
-					r.Route("/{templateID}", func(r chi.Router) {
-						r.Get("/",    app.getShiftTemplateHandler)
-						r.Patch("/",  app.checkRestaurantOwnership(app.updateShiftTemplateHandler))
-						r.Delete("/", app.checkRestaurantOwnership(app.deleteShiftTemplateHandler))
-						r.Get("/roles", app.getShiftTemplateRolesHandler)
-					})
-				})
+	// employee portal: shifts assigned to employee records with the signed-in user's email
+	r.Route("/employee/me", func(r chi.Router) {
+		r.Use(app.AuthTokenMiddleware)
+		r.Get("/shifts", app.getMyShiftsHandler)
+		r.Post("/shifts/{shiftID}/acknowledge", app.acknowledgeShiftHandler)
+		r.Put("/shifts/{shiftID}/feedback", app.submitShiftFeedbackHandler)
+		r.Get("/bid-rounds", app.getMyBiddableShiftsHandler)
+		r.Put("/bid-rounds/{roundID}/preferences", app.submitBidPreferencesHandler)
+	})
 
-				// weekly schedules
-				r.Route("/schedules", func(r chi.Router) {
-					r.Get("/",  app.getSchedulesHandler)
-					r.Post("/", app.checkRestaurantOwnership(app.createScheduleHandler))
+	// Shared schedules (public; the share link token in the URL is the capability)
+	r.Get("/shared/schedules/{token}", app.getSharedScheduleHandler)
 
-					r.Route("/{scheduleID}", func(r chi.Router) {
-						r.Get("/",    app.getScheduleHandler)
-						r.Patch("/",  app.checkRestaurantOwnership(app.updateScheduleHandler))
-						r.Delete("/", app.checkRestaurantOwnership(app.deleteScheduleHandler))
+	// Display boards (public; the board token in the URL is the capability)
+	r.Get("/display/boards/{token}", app.getDisplayBoardHandler)
 
-						// publish (email out)
-						r.Post("/publish", app.checkRestaurantOwnership(app.publishScheduleHandler))
+	// Document acknowledgments (public; the emailed link token is the capability)
+	r.Get("/document-acknowledgments/{token}",  app.getDocumentToAcknowledgeHandler)
+	r.Post("/document-acknowledgments/{token}", app.acknowledgeDocumentHandler)
 
-						// send schedule emails to employees
-						r.Post("/send-email", app.checkRestaurantOwnership(app.sendScheduleEmailHandler))
+	// Employee email verification (public; the emailed link token is the capability)
+	r.Post("/email-verifications/{token}", app.verifyEmailHandler)
 
-						// auto-populate shifts from templates
-						r.Post("/auto-populate", app.checkRestaurantOwnership(app.autoPopulateScheduleHandler))
+	// Calendar feeds (public; the feed token in the URL is the capability).
+	// CalDAV clients sync with PROPFIND and REPORT, webcal subscribers GET
+	r.Route("/caldav/{token}", func(r chi.Router) {
+		r.Options("/",                app.calDAVOptionsHandler)
+		r.Get("/",                    app.getCalendarFeedHandler)
+		r.Method(methodPropfind, "/", http.HandlerFunc(app.propfindCalendarFeedHandler))
+		r.Method(methodReport, "/",   http.HandlerFunc(app.reportCalendarFeedHandler))
+		r.Get("/{event}",             app.getCalendarEventHandler)
+	})
 
-						// scheduled shifts inside a schedule
-						r.Route("/shifts", func(r chi.Router) {
-							r.Get("/",  app.getScheduledShiftsHandler)
-							r.Post("/", app.checkRestaurantOwnership(app.createScheduledShiftHandler))
+	// sales pushed by a restaurant's point of sale, authenticated by its POS token
+	r.Post("/pos/sales", app.posSalesWebhookHandler)
 
-							r.Route("/{shiftID}", func(r chi.Router) {
-								r.Get("/",    app.getScheduledShiftHandler)
-								r.Patch("/",  app.checkRestaurantOwnership(app.updateScheduledShiftHandler))
-								r.Delete("/", app.checkRestaurantOwnership(app.deleteScheduledShiftHandler))
+	// time clock on a shared device, authenticated by its kiosk token
+	r.Route("/kiosk", func(r chi.Router) {
+		r.Use(app.KioskAuthMiddleware)
+		r.Get("/employees", app.getKioskEmployeesHandler)
+		r.Post("/clock", app.kioskClockHandler)
+	})
 
-								// assign / unassign employee
-								r.Patch("/assign", app.checkRestaurantOwnership(app.assignEmployeeToShiftHandler))
-								r.Delete("/assign", app.checkRestaurantOwnership(app.unassignEmployeeFromShiftHandler))
-							})
-						})
-					})
+	// All app features require valid JWT 
+	r.Route("/restaurants", func(r chi.Router) { 
+		r.Use(app.AuthTokenMiddleware) 
+		r.Post("/", app.enforcePlanLimit(planResourceRestaurants, app.createRestaurantHandler))
+		r.Get("/",  app.getRestaurantsHandler)
+
+
+		r.Route("/{restaurantID}", func(r chi.Router){ 
+			r.Use(app.restaurantsContextMiddleware)
+			r.Use(app.bumpResponseVersion)
+
+			// restaurant CRUD
+			r.Get("/", app.getRestaurantHandler)
+			r.Patch("/", app.checkRestaurantOwnership(app.updateRestaurantHandler)) 
+			// these work on archived restaurants, so they check the owner themselves
+			// instead of going through checkRestaurantOwnership
+			r.Delete("/", app.deleteRestaurantHandler)
+			r.Post("/archive", app.archiveRestaurantHandler)
+			r.Post("/unarchive", app.unarchiveRestaurantHandler)
+			r.Get("/export", app.exportRestaurantHandler)
+			// copy the setup into a new restaurant for another location
+			r.Post("/clone", app.checkRestaurantOwnership(app.enforcePlanLimit(planResourceRestaurants, app.cloneRestaurantHandler)))
+
+			// feature flags resolved for this restaurant
+			r.Get("/features", app.getRestaurantFeaturesHandler)
+
+			// re-sync denormalized shift fields
+			r.Post("/repair-denormalized", app.checkRestaurantOwnership(app.repairDenormalizedHandler))
+
+			// roles
+			r.Route("/roles", func(r chi.Router) {
+				r.Get("/",  app.getRolesHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createRoleHandler))
+				r.Route("/{roleID}", func(r chi.Router) {
+					r.Get("/",    app.getRoleHandler)
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateRoleHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteRoleHandler))
+
+					// get employees for role
+					r.Get("/employees", app.getRoleEmployeesHandler)
+					// what deleting it would change
+					r.Get("/usage", app.getRoleUsageHandler)
+
+					// certifications required for role
+					r.Get("/certifications", app.getRoleCertificationsHandler)
+					r.Put("/certifications", app.checkRestaurantOwnership(app.setRoleCertificationsHandler))
+				})
+			})
+
+			// employees
+			r.Route("/employees", func(r chi.Router) {
+				r.Get("/",  app.getEmployeesHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.enforcePlanLimit(planResourceEmployees, app.createEmployeeHandler)))
+				r.Route("/{employeeID}", func(r chi.Router) {
+					r.Get("/",    app.getEmployeeHandler)
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateEmployeeHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteEmployeeHandler))
+
+					// manage employee ⇄ role
+					r.Get("/roles",                 app.getEmployeeRolesHandler)
+					r.Post("/roles",                app.checkRestaurantOwnership(app.addEmployeeRolesHandler))
+					r.Delete("/roles/{roleID}",     app.checkRestaurantOwnership(app.removeEmployeeRoleHandler))
+
+					// employee certifications
+					r.Get("/certifications",                       app.getEmployeeCertificationsHandler)
+					r.Put("/certifications/{certificationID}",    app.checkRestaurantOwnership(app.setEmployeeCertificationHandler))
+					r.Delete("/certifications/{certificationID}", app.checkRestaurantOwnership(app.removeEmployeeCertificationHandler))
+
+					// profile photo
+					r.Put("/avatar",    app.checkRestaurantOwnership(app.uploadEmployeeAvatarHandler))
+					r.Delete("/avatar", app.checkRestaurantOwnership(app.deleteEmployeeAvatarHandler))
+
+					// time clock kiosk PIN
+					r.Post("/pin",   app.checkRestaurantOwnership(app.rotateEmployeePINHandler))
+					r.Delete("/pin", app.checkRestaurantOwnership(app.deleteEmployeePINHandler))
+
+					// link for the employee to verify their email address
+					r.Post("/email-verification", app.checkRestaurantOwnership(app.requestEmailVerificationHandler))
+
+					// employee documents (signed forms, ...)
+					r.Get("/documents",  app.getEmployeeDocumentsHandler)
+					r.Post("/documents", app.checkRestaurantOwnership(app.uploadEmployeeDocumentHandler))
+
+					// private notes kept by the owner
+					r.Get("/manager-notes",  app.getEmployeeNotesHandler)
+					r.Post("/manager-notes", app.checkRestaurantOwnership(app.createEmployeeNoteHandler))
+
+					// in-app notifications sent to the employee
+					r.Get("/notifications", app.getEmployeeNotificationsHandler)
+
+					// paid leave balance, leave paid and adjustments
+					r.Get("/leave",  app.getEmployeeLeaveHandler)
+					r.Post("/leave", app.checkRestaurantOwnership(app.createLeaveEntryHandler))
+
+					// privacy requests: anonymize the employee, archived restaurants included
+					r.Post("/erase", app.eraseEmployeeHandler)
+				})
+			})
+
+			// certifications (food handler card, alcohol service permit, ...)
+			r.Route("/certifications", func(r chi.Router) {
+				r.Get("/",  app.getCertificationsHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createCertificationHandler))
+				r.Get("/expiring", app.getExpiringCertificationsHandler)
+				r.Post("/expiring/send-email", app.checkRestaurantOwnership(app.sendCertificationExpiryEmailHandler))
+				r.Route("/{certificationID}", func(r chi.Router) {
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateCertificationHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteCertificationHandler))
+				})
+			})
+
+			// documents (handbooks, checklists, ...) kept in blob storage
+			r.Route("/documents", func(r chi.Router) {
+				r.Get("/",  app.getDocumentsHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.uploadDocumentHandler))
+				r.Route("/{documentID}", func(r chi.Router) {
+					r.Get("/",         app.getDocumentHandler)
+					r.Get("/download", app.downloadDocumentHandler)
+					r.Get("/acknowledgments",  app.getDocumentAcknowledgmentsHandler)
+					r.Post("/acknowledgments", app.checkRestaurantOwnership(app.requestDocumentAcknowledgmentsHandler))
+					r.Delete("/",      app.checkRestaurantOwnership(app.deleteDocumentHandler))
+				})
+			})
+
+			// time clock kiosks and the time they recorded
+			r.Route("/kiosks", func(r chi.Router) {
+				r.Get("/",             app.getKiosksHandler)
+				r.Post("/",            app.checkRestaurantOwnership(app.createKioskHandler))
+				r.Delete("/{kioskID}", app.checkRestaurantOwnership(app.revokeKioskHandler))
+			})
+
+			// read-only screens showing the day's shifts
+			r.Route("/display-boards", func(r chi.Router) {
+				r.Get("/",             app.getDisplayBoardsHandler)
+				r.Post("/",            app.checkRestaurantOwnership(app.createDisplayBoardHandler))
+				r.Delete("/{boardID}", app.checkRestaurantOwnership(app.revokeDisplayBoardHandler))
+			})
+
+			// calendars of published shifts that calendar apps subscribe to
+			r.Route("/calendar-feeds", func(r chi.Router) {
+				r.Get("/",            app.getCalendarFeedsHandler)
+				r.Post("/",           app.checkRestaurantOwnership(app.createCalendarFeedHandler))
+				r.Delete("/{feedID}", app.checkRestaurantOwnership(app.revokeCalendarFeedHandler))
+			})
+			r.Get("/time-entries", app.getTimeEntriesHandler)
+
+			// staffing reports from past shifts
+			r.Get("/reports/heatmap", app.getCoverageHeatmapHandler)
+			r.Get("/reports/demand-vs-staffing", app.getDemandVsStaffingHandler)
+			r.Get("/reports/staffing-gaps", app.getStaffingGapsHandler)
+			r.Route("/reports/saved", func(r chi.Router) {
+				r.Get("/",  app.getSavedReportsHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createSavedReportHandler))
+				r.Route("/{reportID}", func(r chi.Router) {
+					r.Get("/",    app.getSavedReportHandler)
+					r.Put("/",    app.checkRestaurantOwnership(app.updateSavedReportHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteSavedReportHandler))
+					r.Get("/run", app.runSavedReportHandler)
 				})
+			})
+			r.Get("/reports/shift-feedback", app.getShiftFeedbackReportHandler)
+
+			// sales imported from the point of sale, by upload or its webhook
+			r.Route("/sales", func(r chi.Router) {
+				r.Get("/",                 app.getSalesHandler)
+				r.Post("/",                app.checkRestaurantOwnership(app.importSalesHandler))
+				r.Post("/webhook-token",   app.checkRestaurantOwnership(app.createSalesWebhookTokenHandler))
+				r.Delete("/webhook-token", app.checkRestaurantOwnership(app.deleteSalesWebhookTokenHandler))
+			})
+
+			// URLs the restaurant's events are posted to, for HR and other systems
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Get("/",  app.getWebhooksHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createWebhookHandler))
+				r.Delete("/{webhookID}",         app.checkRestaurantOwnership(app.deleteWebhookHandler))
+				r.Get("/{webhookID}/deliveries", app.getWebhookDeliveriesHandler)
+			})
+
+			// announcements to staff, sent now or scheduled
+			r.Route("/messages", func(r chi.Router) {
+				r.Get("/",  app.getMessagesHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.sendMessageHandler))
+				r.Route("/{messageID}", func(r chi.Router) {
+					r.Get("/",    app.getMessageHandler)
+					r.Delete("/", app.checkRestaurantOwnership(app.cancelMessageHandler))
+				})
+			})
+
+			// incremental sync for offline-capable clients
+			r.Get("/sync", app.syncRestaurantHandler)
+			// every shift across schedules, a page at a time
+			r.Get("/shifts", app.listRestaurantShiftsHandler)
+
+			// shift leads: other users who manage the shifts of some roles
+			r.Route("/members", func(r chi.Router) {
+				r.Get("/",            app.getMembersHandler)
+				r.Post("/",           app.checkRestaurantOwnership(app.addMemberHandler))
+				r.Put("/{userID}",    app.checkRestaurantOwnership(app.updateMemberHandler))
+				r.Delete("/{userID}", app.checkRestaurantOwnership(app.removeMemberHandler))
+			})
+
+			// setup wizard progress and starter data
+			r.Get("/onboarding",              app.getOnboardingHandler)
+			r.Post("/onboarding/sample-data", app.checkRestaurantOwnership(app.addOnboardingSampleDataHandler))
+
+			// operating hours and holiday exceptions
+			r.Route("/operating-hours", func(r chi.Router) {
+				r.Get("/",         app.getOperatingHoursHandler)
+				r.Put("/",         app.checkRestaurantOwnership(app.updateOperatingHoursHandler))
+				r.Get("/calendar", app.getEffectiveHoursHandler)
+				r.Get("/exceptions",  app.getHoursExceptionsHandler)
+				r.Post("/exceptions", app.checkRestaurantOwnership(app.createHoursExceptionHandler))
+				r.Delete("/exceptions/{exceptionID}", app.checkRestaurantOwnership(app.deleteHoursExceptionHandler))
+			})
+
+			// working-hour compliance rules
+			r.Get("/compliance-rules", app.getComplianceRulesHandler)
+			r.Put("/compliance-rules", app.checkRestaurantOwnership(app.updateComplianceRulesHandler))
+
+			// paid leave accrual
+			r.Get("/leave-policy",    app.getLeavePolicyHandler)
+			r.Put("/leave-policy",    app.checkRestaurantOwnership(app.updateLeavePolicyHandler))
+			r.Post("/leave-accruals", app.checkRestaurantOwnership(app.accrueLeaveHandler))
+			r.Get("/leave-balances",  app.getLeaveBalancesHandler)
+
+			// how long employee and time clock data is kept
+			r.Route("/retention-policies", func(r chi.Router) {
+				r.Get("/",          app.getRetentionPoliciesHandler)
+				r.Post("/run",      app.checkRestaurantOwnership(app.runRetentionPoliciesHandler))
+				r.Put("/{kind}",    app.checkRestaurantOwnership(app.updateRetentionPolicyHandler))
+				r.Delete("/{kind}", app.checkRestaurantOwnership(app.deleteRetentionPolicyHandler))
+			})
+
+			// schedule email customization
+			r.Route("/email-templates", func(r chi.Router) {
+				r.Get("/",     app.getEmailTemplateHandler)
+				r.Put("/",     app.checkRestaurantOwnership(app.updateEmailTemplateHandler))
+				r.Delete("/",  app.checkRestaurantOwnership(app.deleteEmailTemplateHandler))
+				r.Post("/preview", app.previewEmailTemplateHandler)
+			})
+
+			// recurring shift templates
+			r.Route("/shift-templates", func(r chi.Router) {
+				r.Get("/",  app.getShiftTemplatesHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createShiftTemplateHandler))
+				r.Get("/suggestions", app.getShiftTemplateSuggestionsHandler)
+				r.Get("/duplicates",  app.getShiftTemplateDuplicatesHandler)
+				r.Post("/merge",      app.checkRestaurantOwnership(app.mergeShiftTemplatesHandler))
+				r.Route("/{templateID}", func(r chi.Router) {
+					r.Get("/",    app.getShiftTemplateHandler)
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateShiftTemplateHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteShiftTemplateHandler))
+					r.Get("/roles", app.getShiftTemplateRolesHandler)
+				})
+			})
+
+			// weekly schedules
+			r.Route("/schedules", func(r chi.Router) {
+				r.Get("/",  app.getSchedulesHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createScheduleHandler))
+				r.Post("/bulk-archive", app.checkRestaurantOwnership(app.bulkArchiveSchedulesHandler))
+
+				r.Route("/{scheduleID}", func(r chi.Router) {
+					r.Get("/",    app.getScheduleHandler)
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateScheduleHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteScheduleHandler))
+
+					// notes on individual days; the week's note is the schedule's own
+					r.Get("/day-notes", app.getScheduleDayNotesHandler)
+					r.Put("/day-notes/{date}", app.checkRestaurantOwnership(app.setScheduleDayNoteHandler))
+					r.Delete("/day-notes/{date}", app.checkRestaurantOwnership(app.deleteScheduleDayNoteHandler))
+
+					// read-only links for people without an account
+					r.Post("/share-link", app.checkRestaurantOwnership(app.createShareLinkHandler))
+					r.Get("/share-links", app.checkRestaurantOwnership(app.getShareLinksHandler))
+					r.Patch("/share-links/{linkID}", app.checkRestaurantOwnership(app.updateShareLinkHandler))
+					r.Delete("/share-links/{linkID}", app.checkRestaurantOwnership(app.revokeShareLinkHandler))
+
+					// publish (email out)
+					r.Post("/publish", app.checkRestaurantOwnership(app.publishScheduleHandler))
+
+					// send schedule emails to employees
+					r.Post("/send-email", app.checkRestaurantOwnership(app.requireFeature(features.ScheduleEmails, app.sendScheduleEmailHandler)))
+					r.Post("/send-email/retry-failures", app.checkRestaurantOwnership(app.requireFeature(features.ScheduleEmails, app.retryScheduleEmailFailuresHandler)))
+					// what an employee's schedule email would look like, without sending it
+					r.Get("/email-preview", app.previewScheduleEmailHandler)
+
+					// delivery status of every email sent for the schedule
+					r.Get("/email-status", app.getScheduleEmailStatusHandler)
+					r.Get("/email-blasts", app.getScheduleEmailBlastsHandler)
+
+					// projected labor cost against the weekly budget
+					r.Get("/labor-cost", app.cacheResponse(app.getScheduleLaborCostHandler))
+
+					// staffing per day, with the forecast when weather is configured
+					r.Get("/coverage", app.getScheduleCoverageHandler)
+
+					// editable spreadsheet of the schedule
+					r.Get("/export.xlsx", app.exportScheduleXLSXHandler)
+
+					// changes since publish, and emails to just the affected employees
+					r.Get("/changes", app.getScheduleChangesHandler)
+					r.Get("/published-version", app.getPublishedScheduleVersionHandler)
+					r.Post("/notify-changes", app.checkRestaurantOwnership(app.requireFeature(features.ScheduleEmails, app.notifyScheduleChangesHandler)))
+
+					// which employees have acknowledged their shifts, and reminders for the rest
+					r.Get("/acknowledgments", app.getScheduleAcknowledgmentsHandler)
+					r.Post("/acknowledgments/remind", app.checkRestaurantOwnership(app.requireFeature(features.ScheduleEmails, app.remindUnacknowledgedShiftsHandler)))
+
+					// auto-populate shifts from templates
+					r.Post("/auto-populate", app.checkRestaurantOwnership(app.requireFeature(features.AutoPopulate, app.autoPopulateScheduleHandler)))
+					// assign open shifts by the restaurant's assignment policy
+					r.Post("/auto-assign", app.checkRestaurantOwnership(app.requireFeature(features.AutoAssign, app.autoAssignScheduleHandler)))
+
+					// dates and roles at risk of going unstaffed, before populating or publishing
+					r.Get("/pre-check", app.getSchedulePreCheckHandler)
+
+					// open shifts employees bid on, allocated by seniority and fairness
+					r.Route("/bid-rounds", func(r chi.Router) {
+						r.Get("/",  app.getBidRoundsHandler)
+						r.Post("/", app.checkRestaurantOwnership(app.requireFeature(features.AutoAssign, app.createBidRoundHandler)))
+						r.Get("/{roundID}", app.getBidRoundHandler)
+						r.Post("/{roundID}/allocate", app.checkRestaurantOwnership(app.requireFeature(features.AutoAssign, app.allocateBidRoundHandler)))
+					})
 
-				// events (standalone, not linked to schedules)
-				r.Route("/events", func(r chi.Router) {
-					r.Get("/",  app.getEventsHandler)
-					r.Post("/", app.checkRestaurantOwnership(app.createEventHandler))
+					// scheduled shifts inside a schedule
+					r.Route("/shifts", func(r chi.Router) {
+						r.Get("/",  app.checkShiftAccess(app.cacheResponse(app.getScheduledShiftsHandler)))
+						r.Post("/", app.checkShiftAccess(app.createScheduledShiftHandler))
 
-					r.Route("/{eventID}", func(r chi.Router) {
-						r.Get("/",    app.getEventHandler)
-						r.Patch("/",  app.checkRestaurantOwnership(app.updateEventHandler))
-						r.Delete("/", app.checkRestaurantOwnership(app.deleteEventHandler))
+						r.Route("/{shiftID}", func(r chi.Router) {
+							r.Get("/",    app.checkShiftAccess(app.getScheduledShiftHandler))
+							r.Patch("/",  app.checkShiftAccess(app.updateScheduledShiftHandler))
+							r.Delete("/", app.checkShiftAccess(app.deleteScheduledShiftHandler))
 
-						// event employee assignments
-						r.Get("/employees",                 app.getEventEmployeesHandler)
-						r.Post("/employees",                app.checkRestaurantOwnership(app.assignEventEmployeesHandler))
-						r.Delete("/employees/{employeeID}", app.checkRestaurantOwnership(app.removeEventEmployeeHandler))
+							// assign / unassign employee
+							r.Patch("/assign", app.checkShiftAccess(app.assignEmployeeToShiftHandler))
+							r.Delete("/assign", app.checkShiftAccess(app.unassignEmployeeFromShiftHandler))
+
+							// who changed the shift and how
+							r.Get("/history", app.getShiftHistoryHandler)
+						})
 					})
 				})
-            })
+			})
+
+			// events (standalone, not linked to schedules)
+			r.Route("/events", func(r chi.Router) {
+				r.Get("/",  app.getEventsHandler)
+				r.Post("/", app.checkRestaurantOwnership(app.createEventHandler))
+
+				r.Route("/{eventID}", func(r chi.Router) {
+					r.Get("/",    app.getEventHandler)
+					r.Patch("/",  app.checkRestaurantOwnership(app.updateEventHandler))
+					r.Delete("/", app.checkRestaurantOwnership(app.deleteEventHandler))
+
+					// event employee assignments
+					r.Get("/employees",                 app.getEventEmployeesHandler)
+					r.Post("/employees",                app.checkRestaurantOwnership(app.assignEventEmployeesHandler))
+					r.Delete("/employees/{employeeID}", app.checkRestaurantOwnership(app.removeEventEmployeeHandler))
+
+					// extra staffing scheduled for the event
+					r.Post("/shifts", app.checkRestaurantOwnership(app.createEventShiftHandler))
+
+					// badges scanned at the event to take attendance
+					r.Post("/badges",    app.checkRestaurantOwnership(app.issueEventBadgesHandler))
+					r.Post("/check-ins", app.checkRestaurantOwnership(app.checkInToEventHandler))
+					r.Get("/attendance", app.getEventAttendanceHandler)
+				})
+			})
         })
-	})
-	return r
+    })
 }
 
 func (app *application) run(mux http.Handler) error {
@@ -279,6 +742,23 @@ func (app *application) run(mux http.Handler) error {
 		IdleTimeout: time.Minute,
 	}
 
+	// gRPC API for internal consumers, served alongside HTTP when configured
+	var grpcServer *grpc.Server
+	if app.config.grpcAddr != "" {
+		listener, err := net.Listen("tcp", app.config.grpcAddr)
+		if err != nil {
+			return err
+		}
+
+		grpcServer = app.newGRPCServer()
+		go func() {
+			app.logger.Infow("grpc server has started", "addr", app.config.grpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				app.logger.Errorw("grpc server stopped", "error", err)
+			}
+		}()
+	}
+
 	shutdown := make(chan error)
 
 	go func () {
@@ -292,6 +772,10 @@ func (app *application) run(mux http.Handler) error {
 
 		app.logger.Infow("server caught", "signal", s.String())
 
+		if grpcServer != nil {
+			grpcServer.GracefulStop()
+		}
+
 		shutdown <- server.Shutdown(ctx)
 	}()
 
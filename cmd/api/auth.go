@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/balebbae/RESA/internal/i18n"
 	"github.com/balebbae/RESA/internal/mailer"
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/golang-jwt/jwt/v5"
@@ -19,6 +20,7 @@ type RegisterUserPayload struct {
 	FirstName string `json:"first_name" validate:"required,max=255"`
 	LastName string `json:"last_name" validate:"required,max=255"`
 	Password string `json:"password" validate:"required,min=3,max=72"`
+	Locale *string `json:"locale" validate:"omitempty,oneof=en es"`
 }
 
 type UserWithToken struct {
@@ -54,6 +56,15 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		Email: payload.Email,
 		FirstName: payload.FirstName,
 		LastName: payload.LastName,
+		Locale: payload.Locale,
+	}
+
+	// Remember the browser's language when the client didn't pick one
+	if user.Locale == nil {
+		if locale, ok := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language")); ok {
+			tag := string(locale)
+			user.Locale = &tag
+		}
 	}
 
 	// Hash the user password
@@ -101,7 +112,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 
 	// Send mail
 	// TODO:: Make async to scale to many users 
-	status, err := app.mailer.Send(mailer.UserWelcomeTemplate, user.FirstName, user.Email, vars, !isProdEnv)
+	status, err := app.mailer.Send(mailer.Localized(mailer.UserWelcomeTemplate, i18n.Resolve(user.Locale)), user.FirstName, user.Email, vars, !isProdEnv)
 	if err != nil {
 		app.logger.Errorw("error sending welcome email", "error", err)
 
@@ -115,7 +126,14 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 	}
 	app.logger.Infow("Email sent", "status code", status)
 
-	if err := app.jsonResponse(w, http.StatusCreated, userWithToken); err != nil {
+	// Create the Stripe customer; signup still succeeds if billing is unavailable
+	if app.billing != nil {
+		if _, err := app.provisionSubscription(ctx, user); err != nil {
+			app.logger.Warnw("failed to provision subscription", "user_id", user.ID, "error", err)
+		}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, userWithToken); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -163,7 +181,7 @@ func (app *application) resendConfirmationHandler(w http.ResponseWriter, r *http
 		// Don't reveal if user exists or is already active (security best practice)
 		// Just return generic success message
 		app.logger.Infow("Resend confirmation failed", "email", payload.Email, "error", err)
-		if err := app.jsonResponse(w, http.StatusOK, map[string]string{
+		if err := app.jsonResponse(w, r, http.StatusOK, map[string]string{
 			"message": "If an account with that email exists and is not yet activated, a confirmation email has been sent.",
 		}); err != nil {
 			app.internalServerError(w, r, err)
@@ -184,7 +202,12 @@ func (app *application) resendConfirmationHandler(w http.ResponseWriter, r *http
 	}
 
 	// Send email
-	status, err := app.mailer.Send(mailer.UserWelcomeTemplate, user.FirstName, user.Email, vars, !isProdEnv)
+	locale := requestLocale(r)
+	if user.Locale != nil {
+		locale = i18n.Resolve(user.Locale)
+	}
+
+	status, err := app.mailer.Send(mailer.Localized(mailer.UserWelcomeTemplate, locale), user.FirstName, user.Email, vars, !isProdEnv)
 	if err != nil {
 		app.logger.Errorw("error sending confirmation email", "error", err)
 		app.internalServerError(w, r, err)
@@ -192,7 +215,7 @@ func (app *application) resendConfirmationHandler(w http.ResponseWriter, r *http
 	}
 	app.logger.Infow("Confirmation email resent", "status code", status, "email", user.Email)
 
-	if err := app.jsonResponse(w, http.StatusOK, map[string]string{
+	if err := app.jsonResponse(w, r, http.StatusOK, map[string]string{
 		"message": "Confirmation email has been sent. Please check your inbox.",
 	}); err != nil {
 		app.internalServerError(w, r, err)
@@ -214,6 +237,7 @@ type CreateUserTokenPayload struct {
 //	@Produce		json
 //	@Param			payload	body		CreateUserTokenPayload	true	"User credentials"
 //	@Success		200		{string}	string					"Token"
+//	@Success		202		{object}	TwoFactorChallenge		"The account asks for a second factor; answer it at /authentication/token/verify"
 //	@Failure		400		{object}	error
 //	@Failure		401		{object}	error
 //	@Failure		500		{object}	error
@@ -248,17 +272,8 @@ func (app *application) createTokenHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Generate token using helper that includes all user details
-	token, err := app.generateTokenForUser(user)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return 
-	}
-
-	// send it to the client
-	if err := app.jsonResponse(w, http.StatusCreated, token); err != nil {
-		app.internalServerError(w, r, err)
-	}
+	// the token, or a challenge for the second factor when the account has one
+	app.completeSignIn(w, r, user, http.StatusCreated)
 }
 
 // refreshTokenHandler godoc
@@ -340,8 +355,22 @@ func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Generate new token with updated user info
-	newToken, err := app.generateTokenForUser(user)
+	// Keep the token's session, pushing out its expiry; a token from before
+	// sessions existed starts one
+	var newToken string
+	if sessionID := sessionIDFromClaims(claims); sessionID != 0 {
+		err = app.store.Sessions.Extend(r.Context(), sessionID, userID, time.Now().Add(app.config.auth.token.exp))
+		if err == store.ErrNotFound {
+			app.unauthorizedErrorResponse(w, r, fmt.Errorf("session has been signed out"))
+			return
+		}
+		if err == nil {
+			// Generate new token with updated user info
+			newToken, err = app.generateTokenForUser(user, sessionID)
+		}
+	} else {
+		newToken, err = app.startSession(r, user)
+	}
 	if err != nil {
 		app.logger.Errorw("failed to generate new token", "error", err)
 		app.internalServerError(w, r, err)
@@ -351,7 +380,7 @@ func (app *application) refreshTokenHandler(w http.ResponseWriter, r *http.Reque
 	app.logger.Infow("token refreshed successfully", "user_id", userID)
 
 	// Return the new token
-	if err := app.jsonResponse(w, http.StatusOK, newToken); err != nil {
+	if err := app.jsonResponse(w, r, http.StatusOK, newToken); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -385,7 +414,7 @@ func (app *application) googleLoginHandler(w http.ResponseWriter, r *http.Reques
 
 	app.logger.Infow("Google OAuth initiated", "state", state)
 
-	if err := app.jsonResponse(w, http.StatusOK, response); err != nil {
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -437,15 +466,7 @@ func (app *application) googleCallbackHandler(w http.ResponseWriter, r *http.Req
 	if err == nil {
 		// Existing Google user - generate token and return
 		app.logger.Infow("Existing Google user logged in", "user_id", user.ID)
-		token, err := app.generateTokenForUser(user)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
-
-		if err := app.jsonResponse(w, http.StatusOK, token); err != nil {
-			app.internalServerError(w, r, err)
-		}
+		app.completeSignIn(w, r, user, http.StatusOK)
 		return
 	}
 
@@ -471,15 +492,7 @@ func (app *application) googleCallbackHandler(w http.ResponseWriter, r *http.Req
 		user.GoogleID = &googleUser.ID
 		user.AvatarURL = &googleUser.Picture
 
-		token, err := app.generateTokenForUser(user)
-		if err != nil {
-			app.internalServerError(w, r, err)
-			return
-		}
-
-		if err := app.jsonResponse(w, http.StatusOK, token); err != nil {
-			app.internalServerError(w, r, err)
-		}
+		app.completeSignIn(w, r, user, http.StatusOK)
 		return
 	}
 
@@ -512,26 +525,33 @@ func (app *application) googleCallbackHandler(w http.ResponseWriter, r *http.Req
 
 	app.logger.Infow("New user created with Google OAuth", "user_id", newUser.ID, "email", newUser.Email)
 
-	token, err := app.generateTokenForUser(newUser)
+	if app.billing != nil {
+		if _, err := app.provisionSubscription(ctx, newUser); err != nil {
+			app.logger.Warnw("failed to provision subscription", "user_id", newUser.ID, "error", err)
+		}
+	}
+
+	token, err := app.startSession(r, newUser)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusCreated, token); err != nil {
+	if err := app.jsonResponse(w, r, http.StatusCreated, token); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
 
-// generateTokenForUser is a helper function to generate JWT token for a user
-func (app *application) generateTokenForUser(user *store.User) (string, error) {
+// generateTokenForUser is a helper function to generate JWT token for a user;
+// sessionID goes in the sid claim unless it is 0
+func (app *application) generateTokenForUser(user *store.User, sessionID int64) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":        user.ID,
 		"exp":        time.Now().Add(app.config.auth.token.exp).Unix(),
 		"iat":        time.Now().Unix(),
 		"nbf":        time.Now().Unix(),
 		"iss":        app.config.auth.token.iss,
-		"aud":        app.config.auth.token.iss,
+		"aud":        app.config.auth.token.aud,
 		"email":      user.Email,
 		"first_name": user.FirstName,
 		"last_name":  user.LastName,
@@ -541,6 +561,10 @@ func (app *application) generateTokenForUser(user *store.User) (string, error) {
 		claims["avatar_url"] = *user.AvatarURL
 	}
 
+	if sessionID != 0 {
+		claims["sid"] = sessionID
+	}
+
 	return app.authenticator.GenerateToken(claims)
 }
 
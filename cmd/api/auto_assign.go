@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// assignmentRuleLabels reads the restaurant's assignment policy in audit summaries
+var assignmentRuleLabels = map[store.AssignmentPolicy]string{
+	store.AssignmentSeniorityFirst: "seniority first",
+	store.AssignmentRotateFairly:   "rotating fairly",
+}
+
+// autoAssignCandidate is an employee who may be offered shifts, with the roles they hold
+type autoAssignCandidate struct {
+	Employee *store.Employee
+	RoleIDs  map[int64]bool
+}
+
+// autoAssignCandidates pairs each employee with the roles they hold, loaded for
+// the whole restaurant in one query
+func (app *application) autoAssignCandidates(ctx context.Context, restaurantID int64, employees []*store.Employee) ([]*autoAssignCandidate, error) {
+	roleIDs, err := app.store.Employees.RoleIDsByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*autoAssignCandidate, 0, len(employees))
+	for _, employee := range employees {
+		c := &autoAssignCandidate{Employee: employee, RoleIDs: make(map[int64]bool, len(roleIDs[employee.ID]))}
+		for _, id := range roleIDs[employee.ID] {
+			c.RoleIDs[id] = true
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// autoAssignment is one open shift and the employee picked for it
+type autoAssignment struct {
+	Shift      *store.ScheduledShift
+	EmployeeID int64
+}
+
+type autoAssignPlan struct {
+	Assignments []autoAssignment
+	// Unfilled lists the open shifts no candidate could take
+	Unfilled []int64
+}
+
+// autoAssignResult is the response of auto-assigning a schedule. A dry run
+// returns the shifts as they would be assigned, without assigning them.
+type autoAssignResult struct {
+	DryRun           bool                   `json:"dry_run"`
+	AssignmentRule   store.AssignmentPolicy `json:"assignment_rule"`
+	AssignedCount    int                    `json:"assigned_count"`
+	Shifts           []*ShiftResponse       `json:"shifts"`
+	UnfilledShiftIDs []int64                `json:"unfilled_shift_ids"`
+}
+
+// planAutoAssign fills the open shifts in date and start order. For each one,
+// candidates who hold the role and aren't already working at that time are
+// ranked by the policy, and the first one eligible for it takes the shift;
+// eligible is given the shifts the plan has booked for them so far.
+// shifts is everything on the schedule, so hours already assigned count
+// toward rotating fairly.
+func planAutoAssign(
+	policy store.AssignmentPolicy,
+	shifts, open []*store.ScheduledShift,
+	candidates []*autoAssignCandidate,
+	eligible func(employeeID int64, shift *store.ScheduledShift, booked []*store.ScheduledShift) (bool, error),
+) (*autoAssignPlan, error) {
+	booked := make(map[int64][]*store.ScheduledShift)
+	minutes := make(map[int64]int)
+	for _, shift := range shifts {
+		if shift.EmployeeID != nil {
+			booked[*shift.EmployeeID] = append(booked[*shift.EmployeeID], shift)
+			minutes[*shift.EmployeeID] += shiftMinutes(shift)
+		}
+	}
+
+	open = append([]*store.ScheduledShift(nil), open...)
+	sort.SliceStable(open, func(i, j int) bool {
+		if !open[i].ShiftDate.Equal(open[j].ShiftDate) {
+			return open[i].ShiftDate.Before(open[j].ShiftDate)
+		}
+		return hourMinute(open[i].StartTime) < hourMinute(open[j].StartTime)
+	})
+
+	plan := &autoAssignPlan{Unfilled: []int64{}}
+	for _, shift := range open {
+		var picked *store.Employee
+		for _, c := range rankCandidates(policy, candidates, minutes) {
+			if !c.RoleIDs[shift.RoleID] || overlapsAny(shift, booked[c.Employee.ID]) {
+				continue
+			}
+			ok, err := eligible(c.Employee.ID, shift, booked[c.Employee.ID])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				picked = c.Employee
+				break
+			}
+		}
+
+		if picked == nil {
+			plan.Unfilled = append(plan.Unfilled, shift.ID)
+			continue
+		}
+
+		plan.Assignments = append(plan.Assignments, autoAssignment{Shift: shift, EmployeeID: picked.ID})
+		booked[picked.ID] = append(booked[picked.ID], shift)
+		minutes[picked.ID] += shiftMinutes(shift)
+	}
+
+	return plan, nil
+}
+
+// rankCandidates orders candidates by the policy. Seniority first puts the
+// highest seniority ahead and spreads ties by hours; rotating fairly puts the
+// fewest hours so far ahead and breaks ties by seniority. Manual only ranks no one.
+func rankCandidates(policy store.AssignmentPolicy, candidates []*autoAssignCandidate, minutes map[int64]int) []*autoAssignCandidate {
+	if policy != store.AssignmentSeniorityFirst && policy != store.AssignmentRotateFairly {
+		return nil
+	}
+
+	ranked := append([]*autoAssignCandidate(nil), candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Employee, ranked[j].Employee
+		seniority, hours := a.Seniority > b.Seniority, minutes[a.ID] < minutes[b.ID]
+		if policy == store.AssignmentRotateFairly {
+			if minutes[a.ID] != minutes[b.ID] {
+				return hours
+			}
+			if a.Seniority != b.Seniority {
+				return seniority
+			}
+		} else {
+			if a.Seniority != b.Seniority {
+				return seniority
+			}
+			if minutes[a.ID] != minutes[b.ID] {
+				return hours
+			}
+		}
+		return a.ID < b.ID
+	})
+	return ranked
+}
+
+// overlapsAny reports whether the shift runs at the same time as any of the others
+func overlapsAny(shift *store.ScheduledShift, others []*store.ScheduledShift) bool {
+	date := shift.ShiftDate.Format("2006-01-02")
+	for _, other := range others {
+		if other.ShiftDate.Format("2006-01-02") != date {
+			continue
+		}
+		if hourMinute(shift.StartTime) < hourMinute(other.EndTime) && hourMinute(other.StartTime) < hourMinute(shift.EndTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// autoAssignScheduleHandler godoc
+//
+//	@Summary		Auto-assign open shifts
+//	@Description	Assigns the schedule's unassigned shifts by the restaurant's assignment_policy. seniority_first offers each shift to the eligible employee with the highest seniority; rotate_fairly to the one with the fewest hours on the schedule so far. Eligible employees hold the shift's role and its required certifications, aren't already working at that time, and wouldn't break a compliance rule set to block. Published shifts inside the schedule lock, and shifts out for bids, are left alone. Each assignment is recorded in the shift history with the rule that chose it. Answers 409 when the policy is manual_only. With dry_run=true it returns the shifts as they would be assigned without assigning them.
+//	@Tags			scheduled-shifts
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			dry_run			query		bool	false	"Preview the assignments without making them"
+//	@Success		200				{object}	autoAssignResult
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/auto-assign [post]
+func (app *application) autoAssignScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	policy := restaurant.AssignmentPolicy
+	if _, ok := assignmentRuleLabels[policy]; !ok {
+		app.conflictResponse(w, r, errors.New("the restaurant assigns shifts manually; set its assignment_policy to seniority_first or rotate_fairly to auto-assign"))
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// shifts out for bids go to the bidders
+	biddable, err := app.store.Bids.OpenShiftIDs(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	bidding := make(map[int64]bool, len(biddable))
+	for _, id := range biddable {
+		bidding[id] = true
+	}
+
+	now := time.Now()
+	var open []*store.ScheduledShift
+	for _, shift := range shifts {
+		if shift.EmployeeID != nil || bidding[shift.ID] {
+			continue
+		}
+		if schedule.PublishedAt != nil && restaurant.ShiftLocked(shiftStart(restaurant, shift), now) {
+			continue
+		}
+		open = append(open, shift)
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	candidates, err := app.autoAssignCandidates(r.Context(), restaurant.ID, employees)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	from, to := shiftDates(open)
+	checker, err := app.newComplianceChecker(r.Context(), restaurant.ID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	plan, err := planAutoAssign(policy, shifts, open, candidates, app.eligibleForBatch(r.Context(), checker))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	actorID := getUserFromContext(r).ID
+	result := autoAssignResult{
+		DryRun:           r.URL.Query().Get(dryRunParam) == "true",
+		AssignmentRule:   policy,
+		Shifts:           []*ShiftResponse{},
+		UnfilledShiftIDs: plan.Unfilled,
+	}
+
+	// A dry run stops before writing anything
+	if result.DryRun {
+		names := make(map[int64]string, len(employees))
+		for _, employee := range employees {
+			names[employee.ID] = employee.FullName
+		}
+		for _, a := range plan.Assignments {
+			planned := *a.Shift
+			employeeID, name := a.EmployeeID, names[a.EmployeeID]
+			planned.EmployeeID, planned.EmployeeName = &employeeID, &name
+			result.Shifts = append(result.Shifts, newShiftResponse(&planned))
+		}
+		result.AssignedCount = len(result.Shifts)
+		app.jsonResponse(w, r, http.StatusOK, result)
+		return
+	}
+
+	for _, a := range plan.Assignments {
+		employeeID := a.EmployeeID
+		if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), a.Shift.ID, store.ShiftAssignment{EmployeeID: &employeeID}); err != nil {
+			// Changed since the plan was made; leave it for the owner
+			if errors.Is(err, store.ErrNotFound) || errors.Is(err, store.ErrRoleMismatch) {
+				result.UnfilledShiftIDs = append(result.UnfilledShiftIDs, a.Shift.ID)
+				continue
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		shift, err := app.store.ScheduledShifts.GetByID(r.Context(), a.Shift.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		app.recordAudit(r.Context(), autoAssignedEntry(actorID, policy, a.Shift, shift))
+		app.notifyShiftChanged(r.Context(), a.Shift, shift)
+		result.Shifts = append(result.Shifts, newShiftResponse(shift))
+	}
+	result.AssignedCount = len(result.Shifts)
+
+	app.jsonResponse(w, r, http.StatusOK, result)
+}
+
+// autoAssignedEntry is the audit entry of an automatic assignment, naming the rule that picked the employee
+func autoAssignedEntry(actorID int64, policy store.AssignmentPolicy, before, after *store.ScheduledShift) *store.AuditEntry {
+	entry := shiftChangeEntry(actorID, before, after)
+	if entry == nil {
+		return nil
+	}
+	entry.Summary += fmt.Sprintf(" automatically (%s)", assignmentRuleLabels[policy])
+	entry.Changes["assignment_rule"] = store.AuditChange{To: policy}
+	return entry
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestPlanAutoAssign(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	employeeID := func(id int64) *int64 { return &id }
+	shift := func(id int64, date time.Time, start, end string, employee *int64) *store.ScheduledShift {
+		return &store.ScheduledShift{ID: id, RoleID: 1, ShiftDate: date, StartTime: store.TimeOfDay(start), EndTime: store.TimeOfDay(end), EmployeeID: employee}
+	}
+	candidates := []*autoAssignCandidate{
+		{Employee: &store.Employee{ID: 1, Seniority: 2}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 2, Seniority: 9}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 3, Seniority: 5}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 4, Seniority: 20}, RoleIDs: map[int64]bool{2: true}},
+	}
+	eligible := func(int64, *store.ScheduledShift, []*store.ScheduledShift) (bool, error) { return true, nil }
+	picked := func(plan *autoAssignPlan) map[int64]int64 {
+		got := make(map[int64]int64)
+		for _, a := range plan.Assignments {
+			got[a.Shift.ID] = a.EmployeeID
+		}
+		return got
+	}
+
+	t.Run("seniority first gives each shift to the most senior free employee", func(t *testing.T) {
+		open := []*store.ScheduledShift{
+			shift(10, monday, "09:00", "17:00", nil),
+			shift(11, monday, "12:00", "20:00", nil),
+			shift(12, monday.AddDate(0, 0, 1), "09:00", "17:00", nil),
+		}
+
+		plan, err := planAutoAssign(store.AssignmentSeniorityFirst, open, open, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// shift 11 overlaps the senior employee's shift 10, so it goes to the next in line
+		want := map[int64]int64{10: 2, 11: 3, 12: 2}
+		got := picked(plan)
+		for id, employee := range want {
+			if got[id] != employee {
+				t.Errorf("shift %d went to %d, want %d", id, got[id], employee)
+			}
+		}
+	})
+
+	t.Run("rotating fairly counts hours already on the schedule", func(t *testing.T) {
+		assigned := shift(9, monday, "06:00", "08:00", employeeID(1))
+		open := []*store.ScheduledShift{
+			shift(10, monday.AddDate(0, 0, 1), "09:00", "17:00", nil),
+			shift(11, monday.AddDate(0, 0, 2), "09:00", "17:00", nil),
+			shift(12, monday.AddDate(0, 0, 3), "09:00", "11:00", nil),
+		}
+
+		plan, err := planAutoAssign(store.AssignmentRotateFairly, append([]*store.ScheduledShift{assigned}, open...), open, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 2 and 3 start with no hours and 2 is more senior; then employee 1 has the fewest
+		want := map[int64]int64{10: 2, 11: 3, 12: 1}
+		got := picked(plan)
+		for id, employee := range want {
+			if got[id] != employee {
+				t.Errorf("shift %d went to %d, want %d", id, got[id], employee)
+			}
+		}
+	})
+
+	t.Run("leaves shifts no one is certified for unfilled", func(t *testing.T) {
+		open := []*store.ScheduledShift{shift(10, monday, "09:00", "17:00", nil)}
+
+		plan, err := planAutoAssign(store.AssignmentSeniorityFirst, open, open, candidates, func(int64, *store.ScheduledShift, []*store.ScheduledShift) (bool, error) {
+			return false, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(plan.Assignments) != 0 || len(plan.Unfilled) != 1 || plan.Unfilled[0] != 10 {
+			t.Errorf("plan = %+v", plan)
+		}
+	})
+
+	t.Run("manual only assigns nothing", func(t *testing.T) {
+		open := []*store.ScheduledShift{shift(10, monday, "09:00", "17:00", nil)}
+
+		plan, err := planAutoAssign(store.AssignmentManualOnly, open, open, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(plan.Assignments) != 0 {
+			t.Errorf("assignments = %+v", plan.Assignments)
+		}
+	})
+}
+
+func TestAutoAssignSchedule(t *testing.T) {
+	setup := func(t *testing.T, policy store.AssignmentPolicy) (*application, *[]*store.AuditEntry) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID, AssignmentPolicy: policy}, nil
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-03-02", EndDate: "2026-03-08"}, nil
+			},
+		}
+
+		open := &store.ScheduledShift{ID: 10, ScheduleID: 5, RestaurantID: 3, RoleID: 1, ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "09:00:00", EndTime: "17:00:00"}
+		var assignedTo *int64
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{open}, nil
+			},
+			AssignEmployeeFunc: func(_ context.Context, _ int64, a store.ShiftAssignment) error {
+				assignedTo = a.EmployeeID
+				return nil
+			},
+			GetByIDFunc: func(context.Context, int64) (*store.ScheduledShift, error) {
+				after := *open
+				name := "Maria Lopez"
+				after.EmployeeID, after.EmployeeName = assignedTo, &name
+				return &after, nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) {
+				return []*store.Employee{{ID: 7, FullName: "Maria Lopez", Seniority: 3}}, nil
+			},
+			RoleIDsByRestaurantFunc: func(context.Context, int64) (map[int64][]int64, error) {
+				return map[int64][]int64{7: {1}}, nil
+			},
+		}
+		app.store.Certifications = &store.MockCertificationStorer{
+			MissingForAssignmentFunc: func(context.Context, int64, int64, time.Time) ([]string, error) {
+				return nil, nil
+			},
+		}
+		recorded := &[]*store.AuditEntry{}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(_ context.Context, entries []*store.AuditEntry) error {
+				*recorded = append(*recorded, entries...)
+				return nil
+			},
+		}
+		app.store.Notifications = &store.MockNotificationStorer{}
+		return app, recorded
+	}
+
+	t.Run("assigns open shifts and records the rule", func(t *testing.T) {
+		app, recorded := setup(t, store.AssignmentSeniorityFirst)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/auto-assign", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data autoAssignResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.AssignedCount != 1 || body.Data.AssignmentRule != store.AssignmentSeniorityFirst {
+			t.Errorf("result = %+v", body.Data)
+		}
+
+		if len(*recorded) != 1 {
+			t.Fatalf("recorded %d audit entries, want 1", len(*recorded))
+		}
+		entry := (*recorded)[0]
+		if entry.Action != store.AuditAssigned || !strings.Contains(entry.Summary, "seniority first") {
+			t.Errorf("entry = %+v", entry)
+		}
+		if entry.Changes["assignment_rule"].To != store.AssignmentSeniorityFirst {
+			t.Errorf("assignment_rule = %+v", entry.Changes["assignment_rule"])
+		}
+	})
+
+	t.Run("a dry run previews the assignments without making them", func(t *testing.T) {
+		app, recorded := setup(t, store.AssignmentSeniorityFirst)
+		app.store.ScheduledShifts.(*store.MockScheduledShiftStorer).AssignEmployeeFunc = func(context.Context, int64, store.ShiftAssignment) error {
+			t.Error("a dry run assigned a shift")
+			return nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/auto-assign?dry_run=true", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data autoAssignResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		result := body.Data
+		if !result.DryRun || result.AssignedCount != 1 || len(result.Shifts) != 1 {
+			t.Fatalf("result = %+v, want one planned assignment", result)
+		}
+		if shift := result.Shifts[0]; shift.ID != 10 || *shift.EmployeeID != 7 || *shift.EmployeeName != "Maria Lopez" {
+			t.Errorf("planned shift = %+v, want shift 10 for Maria Lopez", shift)
+		}
+		if len(*recorded) != 0 {
+			t.Error("a dry run recorded audit entries")
+		}
+	})
+
+	t.Run("leaves a shift open rather than break a blocking compliance rule", func(t *testing.T) {
+		app, recorded := setup(t, store.AssignmentSeniorityFirst)
+		app.store.Compliance.(*store.MockComplianceStorer).GetFunc = func(_ context.Context, id int64) (*store.ComplianceRules, error) {
+			rules, _ := store.ComplianceTemplate("eu")
+			rules.RestaurantID = id
+			return &rules, nil
+		}
+		// Maria closed the night before, too late to open at 9
+		employeeID := int64(7)
+		app.store.ScheduledShifts.(*store.MockScheduledShiftStorer).ListByRestaurantAndRangeFunc = func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.ScheduledShift, error) {
+			return []*store.ScheduledShift{{ID: 9, RestaurantID: 3, EmployeeID: &employeeID, ShiftDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), StartTime: "17:00:00", EndTime: "23:00:00"}}, nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/auto-assign", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data autoAssignResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.AssignedCount != 0 || len(body.Data.UnfilledShiftIDs) != 1 || len(*recorded) != 0 {
+			t.Errorf("result = %+v, want shift 10 left open", body.Data)
+		}
+	})
+
+	t.Run("refuses when the restaurant assigns manually", func(t *testing.T) {
+		app, recorded := setup(t, store.AssignmentManualOnly)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/auto-assign", ""), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if len(*recorded) != 0 {
+			t.Error("audit entries were recorded")
+		}
+	})
+}
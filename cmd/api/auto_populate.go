@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// dryRunParam previews what a generating endpoint would create without writing it
+const dryRunParam = "dry_run"
+
+// previewPopulateParam asks schedule creation for the auto-populate preview of
+// the new schedule instead of creating it
+const previewPopulateParam = "preview_populate"
+
+// autoPopulatePlan is what auto-populating a schedule would do: the shifts to
+// create, in order, and what was left out
+type autoPopulatePlan struct {
+	Shifts []*store.ScheduledShift
+	// OutsideHours lines up with Shifts: true where the shift is flagged outside operating hours
+	OutsideHours        []bool
+	SkippedExisting     int
+	SkippedOutsideHours int
+	Days                []*autoPopulateDay
+}
+
+// autoPopulateDay is one schedule day of an auto-populate preview
+type autoPopulateDay struct {
+	Date                  string `json:"date"`
+	ShiftCount            int    `json:"shift_count"`
+	OutsideOperatingHours int    `json:"outside_operating_hours"`
+	SkippedExisting       int    `json:"skipped_existing"`
+	SkippedOutsideHours   int    `json:"skipped_outside_hours"`
+}
+
+// autoPopulatePreview is the dry-run response of auto-populate. Shifts have no
+// IDs yet; those outside operating hours carry an outside_operating_hours warning
+type autoPopulatePreview struct {
+	DryRun              bool               `json:"dry_run"`
+	WouldCreateCount    int                `json:"would_create_count"`
+	OutsideHoursCount   int                `json:"outside_operating_hours_count"`
+	SkippedExisting     int                `json:"skipped_existing"`
+	SkippedOutsideHours int                `json:"skipped_outside_hours"`
+	Days                []*autoPopulateDay `json:"days"`
+	Shifts              []*ShiftResponse   `json:"shifts"`
+}
+
+// planAutoPopulate lays every template's roles over the schedule's days from
+// start to end that fall in the template's effective window and, for a week A
+// or B template, in its week of the rotation from anchor. It skips shifts the
+// schedule already has from the same template and role, and those outside
+// operating hours when the restaurant blocks them.
+func planAutoPopulate(schedule *store.Schedule, start, end time.Time, templates []*store.ShiftTemplate, anchor *store.DateOnly, existing []*store.ScheduledShift, hours *hoursCheck) *autoPopulatePlan {
+	existingMap := make(map[string]bool)
+	for _, shift := range existing {
+		if shift.ShiftTemplateID != nil {
+			existingMap[autoPopulateKey(shift.ShiftDate, *shift.ShiftTemplateID, shift.RoleID)] = true
+		}
+	}
+
+	plan := &autoPopulatePlan{Days: []*autoPopulateDay{}}
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day := &autoPopulateDay{Date: date.Format("2006-01-02")}
+		plan.Days = append(plan.Days, day)
+
+		dayOfWeek := int(date.Weekday()) // 0=Sunday, 6=Saturday
+		for _, template := range templates {
+			if template.DayOfWeek != dayOfWeek || !template.ActiveOn(store.DateOnly(day.Date), anchor) {
+				continue
+			}
+
+			for _, roleID := range template.RoleIDs {
+				if existingMap[autoPopulateKey(date, template.ID, roleID)] {
+					day.SkippedExisting++
+					plan.SkippedExisting++
+					continue
+				}
+
+				// Created unassigned
+				shift := &store.ScheduledShift{
+					ScheduleID:      schedule.ID,
+					RestaurantID:    schedule.RestaurantID,
+					ShiftTemplateID: &template.ID,
+					RoleID:          roleID,
+					ShiftDate:       date,
+					StartTime:       template.StartTime,
+					EndTime:         template.EndTime,
+					Notes:           template.Notes,
+				}
+
+				// Closed days and shifts outside operating hours are skipped or flagged per the restaurant's setting
+				outside := hours.violation(shift) != nil
+				if outside && hours.blocks() {
+					day.SkippedOutsideHours++
+					plan.SkippedOutsideHours++
+					continue
+				}
+
+				day.ShiftCount++
+				if outside {
+					day.OutsideOperatingHours++
+				}
+				plan.Shifts = append(plan.Shifts, shift)
+				plan.OutsideHours = append(plan.OutsideHours, outside)
+			}
+		}
+	}
+
+	return plan
+}
+
+// preview is the plan as a dry-run response
+func (p *autoPopulatePlan) preview() *autoPopulatePreview {
+	preview := &autoPopulatePreview{
+		DryRun:              true,
+		WouldCreateCount:    len(p.Shifts),
+		SkippedExisting:     p.SkippedExisting,
+		SkippedOutsideHours: p.SkippedOutsideHours,
+		Days:                p.Days,
+		Shifts:              []*ShiftResponse{},
+	}
+
+	for i, shift := range p.Shifts {
+		if p.OutsideHours[i] {
+			shift.Warnings = []store.ShiftWarning{store.WarningOutsideHours}
+			preview.OutsideHoursCount++
+		}
+		preview.Shifts = append(preview.Shifts, newShiftResponse(shift))
+	}
+
+	return preview
+}
+
+// autoPopulateKey identifies a template's shift for one role on one day
+func autoPopulateKey(date time.Time, templateID, roleID int64) string {
+	return date.Format("2006-01-02") + "-" +
+		strconv.FormatInt(templateID, 10) + "-" +
+		strconv.FormatInt(roleID, 10)
+}
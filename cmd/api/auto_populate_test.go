@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestPlanAutoPopulate(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	sunday := monday.AddDate(0, 0, 6)
+	schedule := &store.Schedule{ID: 4, RestaurantID: 3}
+
+	templates := []*store.ShiftTemplate{
+		{ID: 1, DayOfWeek: 1, StartTime: "09:00:00", EndTime: "15:00:00", RoleIDs: []int64{5, 6}},
+		{ID: 2, DayOfWeek: 2, StartTime: "20:00:00", EndTime: "23:00:00", RoleIDs: []int64{5}},
+		{ID: 3, DayOfWeek: 3, StartTime: "09:00:00", EndTime: "15:00:00"},
+	}
+	templateID := int64(1)
+	existing := []*store.ScheduledShift{{ShiftTemplateID: &templateID, RoleID: 5, ShiftDate: monday}}
+
+	hours := func(enforcement store.HoursEnforcement) *hoursCheck {
+		return &hoursCheck{hours: &store.OperatingHours{
+			Enforcement: enforcement,
+			Days:        []*store.OperatingDay{{DayOfWeek: 2, OpenTime: "09:00:00", CloseTime: "17:00:00"}},
+		}}
+	}
+
+	t.Run("flags shifts outside operating hours", func(t *testing.T) {
+		plan := planAutoPopulate(schedule, monday, sunday, templates, nil, existing, hours(store.HoursEnforcementWarn))
+
+		if len(plan.Shifts) != 2 || plan.SkippedExisting != 1 || plan.SkippedOutsideHours != 0 {
+			t.Fatalf("plan = %d shifts, %d existing, %d outside hours skipped; want 2, 1, 0",
+				len(plan.Shifts), plan.SkippedExisting, plan.SkippedOutsideHours)
+		}
+		if plan.Shifts[0].RoleID != 6 || plan.Shifts[0].ScheduleID != 4 || plan.Shifts[0].RestaurantID != 3 || plan.Shifts[0].EmployeeID != nil {
+			t.Errorf("first shift = %+v, want unassigned role 6 in schedule 4", plan.Shifts[0])
+		}
+		if len(plan.Days) != 7 {
+			t.Fatalf("days = %d, want 7", len(plan.Days))
+		}
+		if mon := plan.Days[0]; mon.Date != "2026-10-12" || mon.ShiftCount != 1 || mon.SkippedExisting != 1 {
+			t.Errorf("monday = %+v", mon)
+		}
+		if tue := plan.Days[1]; tue.ShiftCount != 1 || tue.OutsideOperatingHours != 1 {
+			t.Errorf("tuesday = %+v", tue)
+		}
+
+		preview := plan.preview()
+		if !preview.DryRun || preview.WouldCreateCount != 2 || preview.OutsideHoursCount != 1 {
+			t.Errorf("preview = %+v", preview)
+		}
+		if w := preview.Shifts[1].Warnings; len(w) != 1 || w[0] != store.WarningOutsideHours {
+			t.Errorf("tuesday shift warnings = %v, want outside_operating_hours", w)
+		}
+	})
+
+	t.Run("skips shifts outside operating hours when blocked", func(t *testing.T) {
+		plan := planAutoPopulate(schedule, monday, sunday, templates, nil, existing, hours(store.HoursEnforcementBlock))
+
+		if len(plan.Shifts) != 1 || plan.SkippedOutsideHours != 1 || plan.Days[1].SkippedOutsideHours != 1 {
+			t.Errorf("plan = %d shifts, %d outside hours skipped; want 1, 1", len(plan.Shifts), plan.SkippedOutsideHours)
+		}
+	})
+
+	t.Run("no enforcement", func(t *testing.T) {
+		plan := planAutoPopulate(schedule, monday, sunday, templates, nil, nil, nil)
+
+		if len(plan.Shifts) != 3 || plan.preview().OutsideHoursCount != 0 {
+			t.Errorf("plan = %d shifts, want 3 with no warnings", len(plan.Shifts))
+		}
+	})
+
+	t.Run("only uses seasonal templates within their window", func(t *testing.T) {
+		from, until := store.DateOnly("2026-10-14"), store.DateOnly("2026-10-31")
+		seasonal := []*store.ShiftTemplate{
+			{ID: 7, DayOfWeek: 1, StartTime: "17:00:00", EndTime: "22:00:00", RoleIDs: []int64{5}, EffectiveFrom: &from},
+			{ID: 8, DayOfWeek: 3, StartTime: "17:00:00", EndTime: "22:00:00", RoleIDs: []int64{5}, EffectiveFrom: &from, EffectiveUntil: &until},
+		}
+
+		plan := planAutoPopulate(schedule, monday, sunday, seasonal, nil, nil, nil)
+
+		if len(plan.Shifts) != 1 || *plan.Shifts[0].ShiftTemplateID != 8 {
+			t.Errorf("plan = %d shifts, want only Wednesday's from template 8", len(plan.Shifts))
+		}
+	})
+
+	t.Run("alternates week a and b templates from the anchor", func(t *testing.T) {
+		weekA, weekB := store.WeekA, store.WeekB
+		rotating := []*store.ShiftTemplate{
+			{ID: 9, DayOfWeek: 1, StartTime: "09:00:00", EndTime: "15:00:00", RoleIDs: []int64{5}, WeekParity: &weekA},
+			{ID: 10, DayOfWeek: 1, StartTime: "15:00:00", EndTime: "22:00:00", RoleIDs: []int64{5}, WeekParity: &weekB},
+		}
+		// three weeks before this one, so this is week b
+		anchor := store.DateOnly("2026-09-21")
+
+		plan := planAutoPopulate(schedule, monday, sunday.AddDate(0, 0, 7), rotating, &anchor, nil, nil)
+
+		if len(plan.Shifts) != 2 || *plan.Shifts[0].ShiftTemplateID != 10 || *plan.Shifts[1].ShiftTemplateID != 9 {
+			t.Errorf("plan = %d shifts, want template 10 this week and 9 the next", len(plan.Shifts))
+		}
+		if plan := planAutoPopulate(schedule, monday, sunday, rotating, nil, nil, nil); len(plan.Shifts) != 0 {
+			t.Errorf("plan = %d shifts without an anchor, want none", len(plan.Shifts))
+		}
+	})
+}
+
+func TestRotationWeek(t *testing.T) {
+	anchor := store.DateOnly("2026-10-07")
+	for date, want := range map[store.DateOnly]store.WeekParity{
+		"2026-10-07": store.WeekA,
+		"2026-10-13": store.WeekA,
+		"2026-10-14": store.WeekB,
+		"2026-10-21": store.WeekA,
+		"2026-10-06": store.WeekB,
+		"2026-09-30": store.WeekB,
+		"2026-09-29": store.WeekA,
+	} {
+		if got, err := store.RotationWeek(anchor, date); err != nil || got != want {
+			t.Errorf("RotationWeek(%s) = %q (%v), want %q", date, got, err, want)
+		}
+	}
+}
+
+func TestCreateSchedulePopulatePreview(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	created := false
+	app.store.Schedules = &store.MockScheduleStorer{
+		CreateFunc: func(context.Context, *store.Schedule) error {
+			created = true
+			return nil
+		},
+	}
+	app.store.ShiftTemplates = &store.MockShiftTemplateStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.ShiftTemplate, error) {
+			return []*store.ShiftTemplate{
+				{ID: 1, DayOfWeek: 1, StartTime: "09:00:00", EndTime: "15:00:00", RoleIDs: []int64{5, 6}},
+				{ID: 2, DayOfWeek: 3, StartTime: "17:00:00", EndTime: "22:00:00", RoleIDs: []int64{5}},
+			}, nil
+		},
+	}
+	app.store.OperatingHours = &store.MockOperatingHoursStorer{
+		GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+			return &store.OperatingHours{RestaurantID: restaurantID}, nil
+		},
+		ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+			return nil, nil
+		},
+	}
+
+	body := `{"start_date":"2026-10-12","end_date":"2026-10-18"}`
+	rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules?preview_populate=true", body), app.mount())
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if created {
+		t.Error("preview created the schedule")
+	}
+
+	var resp struct {
+		Data autoPopulatePreview `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Data.DryRun || resp.Data.WouldCreateCount != 3 || len(resp.Data.Days) != 7 {
+		t.Errorf("preview = %+v, want 3 shifts over 7 days", resp.Data)
+	}
+	if resp.Data.Days[0].ShiftCount != 2 || resp.Data.Days[2].ShiftCount != 1 {
+		t.Errorf("days = %+v, want 2 shifts Monday and 1 Wednesday", resp.Data.Days)
+	}
+}
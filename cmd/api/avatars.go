@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/imaging"
+	"github.com/balebbae/RESA/internal/storage"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAvatarSize = "md"
+	// maxAvatarSide guards against decompression bombs: small files that decode to huge images
+	maxAvatarSide = 6000
+	avatarQuality = 85
+)
+
+// avatarSizes are the square variants generated for each upload, in pixels
+var avatarSizes = map[string]int{
+	"sm": 64,
+	"md": 256,
+	"lg": 512,
+}
+
+var avatarContentTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// UploadEmployeeAvatar godoc
+//
+//	@Summary		Uploads an employee's profile photo
+//	@Description	Uploads a PNG, JPEG or GIF as multipart/form-data, replacing any existing photo. The image is center-cropped to a square and stored in sm (64px), md (256px) and lg (512px) variants.
+//	@Tags			employee
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			employeeID		path		int		true	"Employee ID"
+//	@Param			file			formData	file	true	"Photo"
+//	@Success		200				{object}	store.Employee
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		413				{object}	error
+//	@Failure		415				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/avatar [put]
+func (app *application) uploadEmployeeAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	upload, err := app.readUpload(w, r, avatarContentTypes)
+	if err != nil {
+		app.uploadErrorResponse(w, r, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	defer upload.file.Close()
+
+	img, err := decodeAvatar(upload.file)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	avatarID := uuid.New().String()
+	if err := app.putAvatarVariants(r.Context(), employee, avatarID, img); err != nil {
+		app.deleteAvatarVariants(r.Context(), employee, avatarID)
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Employees.SetAvatar(r.Context(), employee.ID, &avatarID); err != nil {
+		app.deleteAvatarVariants(r.Context(), employee, avatarID)
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if employee.AvatarID != nil {
+		app.deleteAvatarVariants(r.Context(), employee, *employee.AvatarID)
+	}
+
+	employee.AvatarID = &avatarID
+	app.setAvatarURLs(employee)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, employee); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteEmployeeAvatar godoc
+//
+//	@Summary		Removes an employee's profile photo
+//	@Description	Removes the employee's photo and its stored variants
+//	@Tags			employee
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			employeeID		path	int	true	"Employee ID"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/avatar [delete]
+func (app *application) deleteEmployeeAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	if employee.AvatarID == nil {
+		app.notFoundResponse(w, r, errors.New("employee has no avatar"))
+		return
+	}
+
+	if err := app.store.Employees.SetAvatar(r.Context(), employee.ID, nil); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.deleteAvatarVariants(r.Context(), employee, *employee.AvatarID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetAvatar godoc
+//
+//	@Summary		Serves an employee's profile photo
+//	@Description	Redirects to a short-lived URL for the photo. This is the avatar_url on employee responses; it needs no token so it works in img tags, and stops working once the photo is replaced or removed.
+//	@Tags			employee
+//	@Param			employeeID	path	int		true	"Employee ID"
+//	@Param			avatarID	path	string	true	"Avatar ID"
+//	@Param			size		query	string	false	"sm (64px), md (256px, default) or lg (512px)"
+//	@Success		302			"Found"
+//	@Failure		400			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Router			/avatars/{employeeID}/{avatarID} [get]
+func (app *application) getAvatarHandler(w http.ResponseWriter, r *http.Request) {
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = defaultAvatarSize
+	}
+	if _, ok := avatarSizes[size]; !ok {
+		app.badRequestResponse(w, r, errors.New("size must be one of sm, md or lg"))
+		return
+	}
+
+	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The avatar ID is unguessable and changes on every upload, so it doubles as the access check
+	avatarID := chi.URLParam(r, "avatarID")
+	if employee.AvatarID == nil || *employee.AvatarID != avatarID {
+		app.notFoundResponse(w, r, errors.New("avatar not found"))
+		return
+	}
+
+	url, err := app.blobs.PresignGet(r.Context(), avatarKey(employee, avatarID, size), app.config.uploads.urlExpiry, "")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Browsers may reuse the redirect for part of the presigned URL's lifetime
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(app.config.uploads.urlExpiry.Seconds()/2)))
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// setAvatarURLs fills in avatar_url for employees that have a photo
+func (app *application) setAvatarURLs(employees ...*store.Employee) {
+	for _, employee := range employees {
+		if employee.AvatarID == nil {
+			employee.AvatarURL = ""
+			continue
+		}
+		employee.AvatarURL = fmt.Sprintf("%s/v1/avatars/%d/%s", app.externalBaseURL(), employee.ID, *employee.AvatarID)
+	}
+}
+
+// externalBaseURL is the API's public origin; EXTERNAL_URL may be configured without a scheme
+func (app *application) externalBaseURL() string {
+	base := strings.TrimRight(app.config.apiURL, "/")
+	if strings.HasPrefix(base, "http://") || strings.HasPrefix(base, "https://") {
+		return base
+	}
+	if app.config.env == "production" {
+		return "https://" + base
+	}
+	return "http://" + base
+}
+
+func (app *application) putAvatarVariants(ctx context.Context, employee *store.Employee, avatarID string, img image.Image) error {
+	for size, side := range avatarSizes {
+		buf := new(bytes.Buffer)
+		if err := jpeg.Encode(buf, imaging.SquareThumbnail(img, side), &jpeg.Options{Quality: avatarQuality}); err != nil {
+			return err
+		}
+
+		if err := app.blobs.Put(ctx, avatarKey(employee, avatarID, size), buf, int64(buf.Len()), "image/jpeg"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteAvatarVariants removes an avatar's images; failures only leave orphaned files, so they're logged
+func (app *application) deleteAvatarVariants(ctx context.Context, employee *store.Employee, avatarID string) {
+	for size := range avatarSizes {
+		key := avatarKey(employee, avatarID, size)
+		if err := app.blobs.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			app.logger.Warnw("failed to delete avatar variant", "key", key, "error", err)
+		}
+	}
+}
+
+func avatarKey(employee *store.Employee, avatarID, size string) string {
+	return fmt.Sprintf("restaurants/%d/employees/%d/avatars/%s/%s.jpg", employee.RestaurantID, employee.ID, avatarID, size)
+}
+
+// decodeAvatar checks the image's dimensions before decoding it
+func decodeAvatar(file io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image: %w", err)
+	}
+	if cfg.Width > maxAvatarSide || cfg.Height > maxAvatarSide {
+		return nil, fmt.Errorf("image must be at most %dx%d pixels", maxAvatarSide, maxAvatarSide)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read image: %w", err)
+	}
+
+	return img, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestDecodeAvatar(t *testing.T) {
+	encode := func(t *testing.T, w, h int) *bytes.Reader {
+		t.Helper()
+		buf := new(bytes.Buffer)
+		if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+			t.Fatal(err)
+		}
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	t.Run("should decode a normal image", func(t *testing.T) {
+		img, err := decodeAvatar(encode(t, 300, 200))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img.Bounds().Dx() != 300 {
+			t.Errorf("expected width 300, got %d", img.Bounds().Dx())
+		}
+	})
+
+	t.Run("should reject oversized dimensions", func(t *testing.T) {
+		if _, err := decodeAvatar(encode(t, maxAvatarSide+1, 1)); err == nil {
+			t.Error("expected an error for an oversized image")
+		}
+	})
+}
+
+func TestSetAvatarURLs(t *testing.T) {
+	app := newTestApplication(t)
+	app.config.apiURL = "localhost:8080"
+
+	avatarID := "3f1c0a52-9a1e-4c1b-8d6e-2b7f5e0d9c11"
+	with := &store.Employee{ID: 7, AvatarID: &avatarID}
+	without := &store.Employee{ID: 8, AvatarURL: "stale"}
+
+	app.setAvatarURLs(with, without)
+
+	if want := "http://localhost:8080/v1/avatars/7/" + avatarID; with.AvatarURL != want {
+		t.Errorf("expected %q, got %q", want, with.AvatarURL)
+	}
+	if without.AvatarURL != "" {
+		t.Errorf("expected no avatar URL, got %q", without.AvatarURL)
+	}
+}
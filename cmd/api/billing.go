@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/billing"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type planResource string
+
+const (
+	planResourceRestaurants planResource = "restaurants"
+	planResourceEmployees   planResource = "employees"
+)
+
+const maxWebhookBytes = 65_536
+
+type BillingPortalResponse struct {
+	URL string `json:"url"`
+}
+
+// GetBillingPortal godoc
+//
+//	@Summary		Creates a billing portal session
+//	@Description	Generates a Stripe customer-portal session URL for the authenticated user
+//	@Tags			billing
+//	@Produce		json
+//	@Success		200	{object}	BillingPortalResponse
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/billing/portal [get]
+func (app *application) getBillingPortalHandler(w http.ResponseWriter, r *http.Request) {
+	if app.billing == nil {
+		app.notFoundResponse(w, r, errors.New("billing is not enabled"))
+		return
+	}
+
+	ctx := r.Context()
+	user := getUserFromContext(r)
+
+	sub, err := app.store.Subscriptions.GetByUserID(ctx, user.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		// Accounts created before billing was enabled get a customer on first visit
+		sub, err = app.provisionSubscription(ctx, user)
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	url, err := app.billing.CreatePortalSession(ctx, sub.StripeCustomerID, app.config.billing.PortalReturnURL)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, BillingPortalResponse{URL: url}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// BillingWebhook godoc
+//
+//	@Summary		Receives Stripe webhook events
+//	@Description	Verifies the Stripe signature and syncs subscription state
+//	@Tags			billing
+//	@Accept			json
+//	@Success		200
+//	@Failure		400	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/billing/webhook [post]
+func (app *application) billingWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if app.billing == nil {
+		app.notFoundResponse(w, r, errors.New("billing is not enabled"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBytes)
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	event, err := app.billing.ParseWebhook(payload, r.Header.Get("Stripe-Signature"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	switch event.Type {
+	case "customer.subscription.created", "customer.subscription.updated", "customer.subscription.deleted":
+		err = app.syncSubscription(ctx, event)
+	case "invoice.payment_failed":
+		err = app.markSubscriptionPastDue(ctx, event.CustomerID)
+	default:
+		app.logger.Debugw("ignoring billing event", "event_id", event.ID, "type", event.Type)
+	}
+
+	if err != nil {
+		// Unknown customers are acknowledged so Stripe stops retrying them
+		if !errors.Is(err, store.ErrNotFound) {
+			app.internalServerError(w, r, err)
+			return
+		}
+		app.logger.Warnw("billing event for unknown customer", "event_id", event.ID, "customer_id", event.CustomerID)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// enforcePlanLimit rejects creation of a resource once the owner's plan limit is reached
+func (app *application) enforcePlanLimit(resource planResource, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.billing == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+
+		var ownerID int64
+		var count int
+		var err error
+
+		switch resource {
+		case planResourceRestaurants:
+			ownerID = getUserFromContext(r).ID
+			count, err = app.store.Restaurants.CountByUser(ctx, ownerID)
+		case planResourceEmployees:
+			restaurant := getRestaurantFromContext(r)
+			ownerID = restaurant.UserID
+			count, err = app.store.Employees.CountByRestaurant(ctx, restaurant.ID)
+		}
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		plan, err := app.effectivePlan(ctx, ownerID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		limits := billing.LimitsFor(plan)
+		max := limits.MaxRestaurants
+		if resource == planResourceEmployees {
+			max = limits.MaxEmployees
+		}
+
+		if max != billing.Unlimited && count >= max {
+			app.paymentRequiredResponse(w, r, fmt.Errorf("the %s plan allows up to %d %s, upgrade your plan to add more", plan, max, resource))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// effectivePlan returns the plan a user is currently entitled to
+func (app *application) effectivePlan(ctx context.Context, userID int64) (billing.Plan, error) {
+	sub, err := app.store.Subscriptions.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return billing.PlanFree, nil
+		}
+		return "", err
+	}
+
+	if !billing.IsActiveStatus(sub.Status) {
+		return billing.PlanFree, nil
+	}
+
+	return billing.Plan(sub.Plan), nil
+}
+
+// provisionSubscription creates the Stripe customer (and free-plan subscription when configured) for a user
+func (app *application) provisionSubscription(ctx context.Context, user *store.User) (*store.Subscription, error) {
+	customerID, err := app.billing.CreateCustomer(ctx, user.Email, user.FirstName+" "+user.LastName, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &store.Subscription{
+		UserID:           user.ID,
+		StripeCustomerID: customerID,
+		Plan:             string(billing.PlanFree),
+		Status:           "active",
+	}
+
+	if priceID := app.config.billing.Prices[billing.PlanFree]; priceID != "" {
+		stripeSub, err := app.billing.CreateSubscription(ctx, customerID, priceID)
+		if err != nil {
+			return nil, err
+		}
+		sub.StripeSubscriptionID = &stripeSub.ID
+		sub.Status = stripeSub.Status
+		sub.CurrentPeriodEnd = &stripeSub.CurrentPeriodEnd
+	}
+
+	if err := app.store.Subscriptions.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (app *application) syncSubscription(ctx context.Context, event *billing.Event) error {
+	sub, err := app.store.Subscriptions.GetByCustomerID(ctx, event.Subscription.CustomerID)
+	if err != nil {
+		return err
+	}
+
+	sub.StripeSubscriptionID = &event.Subscription.ID
+	sub.Status = event.Subscription.Status
+	sub.CurrentPeriodEnd = &event.Subscription.CurrentPeriodEnd
+
+	if event.Type == "customer.subscription.deleted" {
+		sub.Plan = string(billing.PlanFree)
+	} else {
+		plan, err := app.config.billing.PlanForPrice(event.Subscription.PriceID)
+		if err != nil {
+			app.logger.Warnw("subscription has unknown price", "customer_id", sub.StripeCustomerID, "price_id", event.Subscription.PriceID)
+		} else {
+			sub.Plan = string(plan)
+		}
+	}
+
+	return app.store.Subscriptions.Update(ctx, sub)
+}
+
+func (app *application) markSubscriptionPastDue(ctx context.Context, customerID string) error {
+	sub, err := app.store.Subscriptions.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+
+	sub.Status = "past_due"
+
+	return app.store.Subscriptions.Update(ctx, sub)
+}
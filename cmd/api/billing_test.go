@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/billing"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestBillingWebhook(t *testing.T) {
+	const secret = "whsec_test"
+
+	signed := func(payload string) *http.Request {
+		timestamp := time.Now().Unix()
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(fmt.Sprintf("%d.%s", timestamp, payload)))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/billing/webhook", bytes.NewBufferString(payload))
+		req.Header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil))))
+		return req
+	}
+	subscriptionEvent := func(eventType, status, priceID string) string {
+		return fmt.Sprintf(`{"id":"evt_1","type":%q,"data":{"object":{"id":"sub_1","customer":"cus_1","status":%q,"current_period_end":1767225600,"items":{"data":[{"price":{"id":%q}}]}}}}`,
+			eventType, status, priceID)
+	}
+
+	setup := func(t *testing.T) (*application, **store.Subscription) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.billing = billing.NewStripeClient("sk_test", secret)
+		app.config.billing.Prices = map[billing.Plan]string{
+			billing.PlanFree:     "price_free",
+			billing.PlanPro:      "price_pro",
+			billing.PlanBusiness: "price_business",
+		}
+
+		var updated *store.Subscription
+		app.store.Subscriptions = &store.MockSubscriptionStorer{
+			GetByCustomerIDFunc: func(_ context.Context, customerID string) (*store.Subscription, error) {
+				if customerID != "cus_1" {
+					return nil, store.ErrNotFound
+				}
+				return &store.Subscription{UserID: testUserID, StripeCustomerID: customerID, Plan: string(billing.PlanPro), Status: "active"}, nil
+			},
+			UpdateFunc: func(_ context.Context, sub *store.Subscription) error {
+				updated = sub
+				return nil
+			},
+		}
+		return app, &updated
+	}
+
+	tests := []struct {
+		name       string
+		payload    string
+		wantPlan   billing.Plan
+		wantStatus string
+	}{
+		{"subscription created", subscriptionEvent("customer.subscription.created", "trialing", "price_business"), billing.PlanBusiness, "trialing"},
+		{"subscription upgraded", subscriptionEvent("customer.subscription.updated", "active", "price_business"), billing.PlanBusiness, "active"},
+		{"subscription downgraded", subscriptionEvent("customer.subscription.updated", "active", "price_free"), billing.PlanFree, "active"},
+		{"unknown price keeps the plan", subscriptionEvent("customer.subscription.updated", "active", "price_legacy"), billing.PlanPro, "active"},
+		{"subscription deleted", subscriptionEvent("customer.subscription.deleted", "canceled", "price_pro"), billing.PlanFree, "canceled"},
+		{"payment failed", `{"id":"evt_2","type":"invoice.payment_failed","data":{"object":{"id":"in_1","customer":"cus_1"}}}`, billing.PlanPro, "past_due"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, updated := setup(t)
+
+			rr := executeRequest(signed(tt.payload), app.mount())
+
+			checkResponseCode(t, http.StatusOK, rr.Code)
+			if *updated == nil {
+				t.Fatal("subscription was not updated")
+			}
+			if (*updated).Plan != string(tt.wantPlan) || (*updated).Status != tt.wantStatus {
+				t.Errorf("subscription = %s/%s, want %s/%s", (*updated).Plan, (*updated).Status, tt.wantPlan, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("tampered payload", func(t *testing.T) {
+		app, updated := setup(t)
+		req := signed(subscriptionEvent("customer.subscription.updated", "active", "price_free"))
+		req.Body = io.NopCloser(strings.NewReader(subscriptionEvent("customer.subscription.updated", "active", "price_business")))
+
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if *updated != nil {
+			t.Error("an unsigned event changed the subscription")
+		}
+	})
+
+	t.Run("other events are acknowledged and ignored", func(t *testing.T) {
+		app, updated := setup(t)
+
+		rr := executeRequest(signed(`{"id":"evt_3","type":"customer.created","data":{"object":{"id":"cus_1"}}}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if *updated != nil {
+			t.Error("an ignored event changed the subscription")
+		}
+	})
+
+	t.Run("unknown customers are acknowledged", func(t *testing.T) {
+		app, _ := setup(t)
+
+		rr := executeRequest(signed(`{"id":"evt_4","type":"invoice.payment_failed","data":{"object":{"customer":"cus_gone"}}}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+}
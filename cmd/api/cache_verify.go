@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// cacheVerifyConfig is how often the cache verifier runs and how many entries
+// of each kind it checks; an interval of 0 turns it off
+type cacheVerifyConfig struct {
+	interval time.Duration
+	sample   int
+}
+
+// cacheStaleness counts what the cache verifier found, per kind of entry
+type cacheStaleness struct {
+	mu    sync.Mutex
+	kinds map[string]*cacheKindStaleness
+}
+
+// cacheKindStaleness is the verifier's tally for one kind of cached entry.
+// Stale entries are refreshed from the database, or evicted when the row is gone.
+type cacheKindStaleness struct {
+	Checked   int64      `json:"checked"`
+	Stale     int64      `json:"stale"`
+	Refreshed int64      `json:"refreshed"`
+	Evicted   int64      `json:"evicted"`
+	LastRun   *time.Time `json:"last_run,omitempty"`
+}
+
+func newCacheStaleness() *cacheStaleness {
+	return &cacheStaleness{kinds: map[string]*cacheKindStaleness{}}
+}
+
+// record adds a verifier run's tally for kind
+func (c *cacheStaleness) record(kind string, run cacheKindStaleness) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total, ok := c.kinds[kind]
+	if !ok {
+		total = &cacheKindStaleness{}
+		c.kinds[kind] = total
+	}
+	total.Checked += run.Checked
+	total.Stale += run.Stale
+	total.Refreshed += run.Refreshed
+	total.Evicted += run.Evicted
+	total.LastRun = run.LastRun
+}
+
+// Stats is a copy of the tallies, for expvar
+func (c *cacheStaleness) Stats() map[string]cacheKindStaleness {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := make(map[string]cacheKindStaleness, len(c.kinds))
+	for kind, total := range c.kinds {
+		stats[kind] = *total
+	}
+	return stats
+}
+
+// runCacheVerifier periodically checks a sample of the cached restaurants and
+// schedules against the database
+func (app *application) runCacheVerifier(cfg cacheVerifyConfig) {
+	ticker := time.NewTicker(cfg.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		app.verifyCache(context.Background(), cfg.sample)
+	}
+}
+
+// verifyCache compares up to sample cached restaurants and schedules with their
+// rows by updated_at, refreshing the entries that drifted and evicting those
+// whose rows are gone
+func (app *application) verifyCache(ctx context.Context, sample int) {
+	if restaurants := app.cacheStorage.Restaurants; restaurants != nil {
+		ids, err := restaurants.Sample(ctx, sample)
+		if err != nil {
+			app.logger.Errorw("sampling cached restaurants failed", "error", err)
+		} else {
+			app.recordCacheStaleness("restaurants", verifyEntries(ids,
+				func(id int64) (*store.Restaurant, error) { return restaurants.Get(ctx, id) },
+				func(id int64) (*store.Restaurant, error) { return app.store.Restaurants.GetByID(ctx, id) },
+				func(r *store.Restaurant) time.Time { return r.UpdatedAt },
+				func(r *store.Restaurant) error { return restaurants.Set(ctx, r) },
+				func(id int64) error { return restaurants.Delete(ctx, id) },
+			))
+		}
+	}
+
+	if schedules := app.cacheStorage.Schedules; schedules != nil {
+		ids, err := schedules.Sample(ctx, sample)
+		if err != nil {
+			app.logger.Errorw("sampling cached schedules failed", "error", err)
+		} else {
+			app.recordCacheStaleness("schedules", verifyEntries(ids,
+				func(id int64) (*store.Schedule, error) { return schedules.Get(ctx, id) },
+				func(id int64) (*store.Schedule, error) { return app.store.Schedules.GetByID(ctx, id) },
+				func(s *store.Schedule) time.Time { return s.UpdatedAt },
+				func(s *store.Schedule) error { return schedules.Set(ctx, s) },
+				func(id int64) error { return schedules.Delete(ctx, id) },
+			))
+		}
+	}
+}
+
+func (app *application) recordCacheStaleness(kind string, run cacheKindStaleness) {
+	if app.cacheStaleness != nil {
+		app.cacheStaleness.record(kind, run)
+	}
+	if run.Stale > 0 {
+		app.logger.Warnw("stale cache entries",
+			"kind", kind,
+			"checked", run.Checked,
+			"stale", run.Stale,
+			"refreshed", run.Refreshed,
+			"evicted", run.Evicted)
+	}
+}
+
+// verifyEntries checks the cached entries with the given IDs against the
+// database. Entries that expired since sampling aren't counted, and one that
+// can't be refreshed is evicted rather than left to serve a stale value.
+func verifyEntries[T any](
+	ids []int64,
+	cached func(int64) (*T, error),
+	current func(int64) (*T, error),
+	updatedAt func(*T) time.Time,
+	refresh func(*T) error,
+	evict func(int64) error,
+) cacheKindStaleness {
+	now := time.Now()
+	run := cacheKindStaleness{LastRun: &now}
+
+	for _, id := range ids {
+		entry, err := cached(id)
+		if err != nil || entry == nil {
+			continue
+		}
+		run.Checked++
+
+		row, err := current(id)
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			run.Stale++
+			if evict(id) == nil {
+				run.Evicted++
+			}
+		case err != nil:
+			// a database error says nothing about the entry
+		case !updatedAt(row).Equal(updatedAt(entry)):
+			run.Stale++
+			if refresh(row) == nil {
+				run.Refreshed++
+			} else if evict(id) == nil {
+				run.Evicted++
+			}
+		}
+	}
+	return run
+}
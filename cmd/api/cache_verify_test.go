@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+)
+
+func TestVerifyCache(t *testing.T) {
+	saved := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	edited := saved.Add(time.Hour)
+
+	app, _ := newMockedApplication(t, testUserID)
+	app.cacheStaleness = newCacheStaleness()
+
+	// schedule 1 is current, 2 was edited since it was cached, 3 was deleted
+	// and 4 expired between sampling and checking
+	cached := map[int64]*store.Schedule{
+		1: {ID: 1, UpdatedAt: saved},
+		2: {ID: 2, UpdatedAt: saved},
+		3: {ID: 3, UpdatedAt: saved},
+	}
+	var refreshed, evicted []int64
+	app.cacheStorage.Schedules = &cache.MockScheduleStorer{
+		SampleFunc: func(context.Context, int) ([]int64, error) {
+			return []int64{1, 2, 3, 4}, nil
+		},
+		GetFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return cached[id], nil
+		},
+		SetFunc: func(_ context.Context, s *store.Schedule) error {
+			refreshed = append(refreshed, s.ID)
+			return nil
+		},
+		DeleteFunc: func(_ context.Context, id int64) error {
+			evicted = append(evicted, id)
+			return nil
+		},
+	}
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			switch id {
+			case 1:
+				return &store.Schedule{ID: 1, UpdatedAt: saved}, nil
+			case 2:
+				return &store.Schedule{ID: 2, UpdatedAt: edited}, nil
+			}
+			return nil, store.ErrNotFound
+		},
+	}
+
+	app.cacheStorage.Restaurants = &cache.MockRestaurantStorer{
+		SampleFunc: func(context.Context, int) ([]int64, error) {
+			return []int64{1}, nil
+		},
+		GetFunc: func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID}, nil
+		},
+	}
+
+	app.verifyCache(context.Background(), 10)
+
+	if len(refreshed) != 1 || refreshed[0] != 2 {
+		t.Errorf("refreshed = %v, want the edited schedule", refreshed)
+	}
+	if len(evicted) != 1 || evicted[0] != 3 {
+		t.Errorf("evicted = %v, want the deleted schedule", evicted)
+	}
+
+	stats := app.cacheStaleness.Stats()
+	if s := stats["schedules"]; s.Checked != 3 || s.Stale != 2 || s.Refreshed != 1 || s.Evicted != 1 {
+		t.Errorf("schedule stats = %+v", s)
+	}
+	if r := stats["restaurants"]; r.Checked != 1 || r.Stale != 0 {
+		t.Errorf("restaurant stats = %+v", r)
+	}
+}
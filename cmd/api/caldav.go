@@ -0,0 +1,515 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// WebDAV methods CalDAV clients sync with; both only read
+const (
+	methodPropfind = "PROPFIND"
+	methodReport   = "REPORT"
+)
+
+const (
+	// calendarFeedPastDays is how far back a calendar feed keeps shifts
+	calendarFeedPastDays = 30
+	// maxCalDAVBodyBytes bounds a PROPFIND or REPORT request body
+	maxCalDAVBodyBytes  = 1 << 20
+	calendarContentType = "text/calendar; charset=utf-8"
+)
+
+func init() {
+	chi.RegisterMethod(methodPropfind)
+	chi.RegisterMethod(methodReport)
+}
+
+// calendarCollection is a calendar feed as CalDAV serves it: one resource per shift
+type calendarCollection struct {
+	href        string
+	displayName string
+	// ctag changes whenever any event does, so clients know to sync
+	ctag   string
+	events []*calendarEvent
+}
+
+type calendarEvent struct {
+	href  string
+	etag  string
+	ics   string
+	shift *store.ScheduledShift
+}
+
+// CalDAVOptions godoc
+//
+//	@Summary		CalDAV capabilities of a calendar feed
+//	@Description	Answers a CalDAV client's OPTIONS probe with the DAV classes (1, calendar-access) and methods the feed supports: GET, PROPFIND and REPORT (calendar-query, calendar-multiget), read-only
+//	@Tags			calendar-feeds
+//	@Param			token	path	string	true	"Calendar feed token"
+//	@Success		200		"CalDAV capabilities"
+//	@Router			/caldav/{token} [options]
+func (app *application) calDAVOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, calendar-access")
+	w.Header().Set("Allow", strings.Join([]string{http.MethodOptions, http.MethodGet, http.MethodHead, methodPropfind, methodReport}, ", "))
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCalendarFeed godoc
+//
+//	@Summary		Shows a calendar feed as iCalendar
+//	@Description	Serves every event of the feed as one iCalendar file, for apps that subscribe to a webcal URL: the published shifts of the restaurant, or of the feed's employee, from 30 days ago on. It needs no account: the token in the URL is the access, and it stops working once the feed is revoked or the restaurant is archived. CalDAV clients sync the same collection with PROPFIND and REPORT. Answers carry an ETag; a 304 means nothing changed.
+//	@Tags			calendar-feeds
+//	@Produce		text/calendar
+//	@Param			token	path		string	true	"Calendar feed token"
+//	@Success		200		{string}	string	"iCalendar"
+//	@Success		304		{string}	string	"Not modified"
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/caldav/{token} [get]
+func (app *application) getCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.calendarCollectionFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	etag := `"` + collection.ctag + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeCalendar(w, collection.displayName, collection.events)
+}
+
+// GetCalendarEvent godoc
+//
+//	@Summary		Shows one event of a calendar feed
+//	@Description	Serves one shift of the feed, by the href PROPFIND lists it under, as an iCalendar file
+//	@Tags			calendar-feeds
+//	@Produce		text/calendar
+//	@Param			token	path		string	true	"Calendar feed token"
+//	@Param			event	path		string	true	"Event resource, e.g. shift-42.ics"
+//	@Success		200		{string}	string	"iCalendar"
+//	@Success		304		{string}	string	"Not modified"
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/caldav/{token}/{event} [get]
+func (app *application) getCalendarEventHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.calendarCollectionFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	href := collection.href + chi.URLParam(r, "event")
+	for _, event := range collection.events {
+		if event.href != href {
+			continue
+		}
+		w.Header().Set("ETag", event.etag)
+		if etagMatches(r, event.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		writeCalendar(w, collection.displayName, []*calendarEvent{event})
+		return
+	}
+
+	app.notFoundResponse(w, r, errors.New("event not found"))
+}
+
+// propfindCalendarFeedHandler lists the collection's properties and, unless the
+// Depth header is 0, each event's href and ETag. Every property the feed has is
+// returned whatever the request body asks for.
+func (app *application) propfindCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.calendarCollectionFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	ms := newDAVMultistatus()
+	ms.Responses = append(ms.Responses, davResponse{
+		Href: collection.href,
+		Propstat: &davPropstat{
+			Prop: davProp{
+				ResourceType: &davResourceType{Collection: &struct{}{}, Calendar: &struct{}{}},
+				DisplayName:  collection.displayName,
+				CTag:         collection.ctag,
+				ETag:         `"` + collection.ctag + `"`,
+				ComponentSet: &davComponentSet{Comp: davComp{Name: "VEVENT"}},
+				Privileges:   &davPrivilegeSet{Privilege: davPrivilege{Read: &struct{}{}}},
+			},
+			Status: davStatus(http.StatusOK),
+		},
+	})
+
+	if r.Header.Get("Depth") != "0" {
+		for _, event := range collection.events {
+			ms.Responses = append(ms.Responses, davResponse{
+				Href: event.href,
+				Propstat: &davPropstat{
+					Prop:   davProp{ETag: event.etag, ContentType: calendarContentType},
+					Status: davStatus(http.StatusOK),
+				},
+			})
+		}
+	}
+
+	app.writeMultistatus(w, r, ms)
+}
+
+// reportCalendarFeedHandler answers a calendar-multiget with the events it
+// names, and a calendar-query with every event of the feed
+func (app *application) reportCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	collection, ok := app.calendarCollectionFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	report, hrefs, err := readCalendarReport(http.MaxBytesReader(w, r.Body, maxCalDAVBodyBytes))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ms := newDAVMultistatus()
+	withData := func(event *calendarEvent) davResponse {
+		var ics strings.Builder
+		writeCalendarTo(&ics, collection.displayName, []*calendarEvent{event})
+		return davResponse{
+			Href: event.href,
+			Propstat: &davPropstat{
+				Prop:   davProp{ETag: event.etag, CalendarData: ics.String()},
+				Status: davStatus(http.StatusOK),
+			},
+		}
+	}
+
+	switch report {
+	case "calendar-multiget":
+		byHref := make(map[string]*calendarEvent, len(collection.events))
+		for _, event := range collection.events {
+			byHref[event.href] = event
+		}
+		for _, href := range hrefs {
+			if event, ok := byHref[href]; ok {
+				ms.Responses = append(ms.Responses, withData(event))
+				continue
+			}
+			ms.Responses = append(ms.Responses, davResponse{Href: href, Status: davStatus(http.StatusNotFound)})
+		}
+	case "calendar-query":
+		for _, event := range collection.events {
+			ms.Responses = append(ms.Responses, withData(event))
+		}
+	default:
+		app.badRequestResponse(w, r, fmt.Errorf("unsupported report %q", report))
+		return
+	}
+
+	app.writeMultistatus(w, r, ms)
+}
+
+// calendarCollectionFromURL loads the calendar feed behind the {token} link
+// with its events
+func (app *application) calendarCollectionFromURL(w http.ResponseWriter, r *http.Request) (*calendarCollection, bool) {
+	ctx := r.Context()
+	token := chi.URLParam(r, "token")
+
+	feed, err := app.store.CalendarFeeds.Authenticate(ctx, token)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("calendar feed not found or revoked"))
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	restaurant, err := app.store.Restaurants.GetByID(ctx, feed.RestaurantID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	location := restaurant.Location()
+	from := store.DateOnly(time.Now().In(location).AddDate(0, 0, -calendarFeedPastDays).Format("2006-01-02"))
+	shifts, err := app.store.CalendarFeeds.ListShifts(ctx, restaurant.ID, feed.EmployeeID, from)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	// The token is in the URL: keep the feed out of shared caches, search
+	// results and Referer headers, but let clients revalidate what they have
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	return newCalendarCollection(fmt.Sprintf("/v1/caldav/%s/", token), feed, restaurant, shifts), true
+}
+
+// newCalendarCollection renders each shift as an event of the collection at href
+func newCalendarCollection(href string, feed *store.CalendarFeed, restaurant *store.Restaurant, shifts []*store.ScheduledShift) *calendarCollection {
+	collection := &calendarCollection{
+		href:        href,
+		displayName: feed.Name,
+		events:      make([]*calendarEvent, 0, len(shifts)),
+	}
+
+	location := restaurant.Location()
+	ctag := sha256.New()
+	for _, shift := range shifts {
+		var summary string
+		switch {
+		case feed.EmployeeID != nil:
+			summary = fmt.Sprintf("%s at %s", shift.RoleName, restaurant.Name)
+		case shift.EmployeeName != nil:
+			summary = fmt.Sprintf("%s: %s", shift.RoleName, *shift.EmployeeName)
+		default:
+			summary = fmt.Sprintf("%s: open shift", shift.RoleName)
+		}
+		if shift.Training {
+			summary += " (training)"
+		}
+
+		start, end := calendarShiftSpan(shift, location)
+		ics := icsEvent(fmt.Sprintf("shift-%d@resa", shift.ID), summary, restaurant.Address, shift.Notes, start, end, shift.UpdatedAt)
+		sum := sha256.Sum256([]byte(ics))
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		ctag.Write([]byte(etag))
+
+		collection.events = append(collection.events, &calendarEvent{
+			href:  fmt.Sprintf("%sshift-%d.ics", href, shift.ID),
+			etag:  etag,
+			ics:   ics,
+			shift: shift,
+		})
+	}
+	collection.ctag = hex.EncodeToString(ctag.Sum(nil)[:8])
+
+	return collection
+}
+
+// calendarShiftSpan is when the shift starts and ends in the restaurant's
+// timezone; shifts end the day they start
+func calendarShiftSpan(shift *store.ScheduledShift, location *time.Location) (time.Time, time.Time) {
+	y, m, d := shift.ShiftDate.Date()
+	at := func(t store.TimeOfDay) time.Time {
+		clock, _ := time.Parse("15:04", hourMinute(t))
+		return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, location)
+	}
+
+	return at(shift.StartTime), at(shift.EndTime)
+}
+
+// icsEvent is a VEVENT with its times in UTC, its lines folded and ending in CRLF
+func icsEvent(uid, summary, location, description string, start, end, modified time.Time) string {
+	const stamp = "20060102T150405Z"
+
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(foldICSLine(name + ":" + value))
+	}
+	line("BEGIN", "VEVENT")
+	line("UID", uid)
+	line("DTSTAMP", modified.UTC().Format(stamp))
+	line("LAST-MODIFIED", modified.UTC().Format(stamp))
+	line("DTSTART", start.UTC().Format(stamp))
+	line("DTEND", end.UTC().Format(stamp))
+	line("SUMMARY", escapeICSText(summary))
+	if location != "" {
+		line("LOCATION", escapeICSText(location))
+	}
+	if description != "" {
+		line("DESCRIPTION", escapeICSText(description))
+	}
+	line("END", "VEVENT")
+	return b.String()
+}
+
+func writeCalendar(w http.ResponseWriter, name string, events []*calendarEvent) {
+	var b strings.Builder
+	writeCalendarTo(&b, name, events)
+
+	w.Header().Set("Content-Type", calendarContentType)
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, b.String())
+}
+
+// writeCalendarTo wraps the events in a VCALENDAR named name
+func writeCalendarTo(b *strings.Builder, name string, events []*calendarEvent) {
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//RESA//Shift calendar//EN\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	b.WriteString(foldICSLine("X-WR-CALNAME:" + escapeICSText(name)))
+	for _, event := range events {
+		b.WriteString(event.ics)
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+}
+
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeICSText escapes a value of an iCalendar TEXT property
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// foldICSLine ends the content line in CRLF, folded so no line is longer than
+// 75 octets and no UTF-8 character is split
+func foldICSLine(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+	width := limit
+	for len(line) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines begin with the space
+		width = limit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// readCalendarReport returns the name of the REPORT in the body and, for a
+// calendar-multiget, the hrefs it asks for
+func readCalendarReport(body io.Reader) (string, []string, error) {
+	decoder := xml.NewDecoder(body)
+
+	var report string
+	var hrefs []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid REPORT body: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if report == "" {
+			report = start.Name.Local
+			continue
+		}
+		if start.Name.Space == "DAV:" && start.Name.Local == "href" {
+			var href string
+			if err := decoder.DecodeElement(&href, &start); err != nil {
+				return "", nil, fmt.Errorf("invalid REPORT body: %w", err)
+			}
+			hrefs = append(hrefs, path.Clean(strings.TrimSpace(href)))
+		}
+	}
+
+	if report == "" {
+		return "", nil, errors.New("REPORT body is empty")
+	}
+	return report, hrefs, nil
+}
+
+// davMultistatus is a WebDAV 207 Multi-Status body, written with the DAV:,
+// CalDAV and CalendarServer prefixes declared on the root
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"d:multistatus"`
+	DAV       string        `xml:"xmlns:d,attr"`
+	CalDAV    string        `xml:"xmlns:c,attr"`
+	CalServer string        `xml:"xmlns:cs,attr"`
+	Responses []davResponse `xml:"d:response"`
+}
+
+type davResponse struct {
+	Href     string       `xml:"d:href"`
+	Propstat *davPropstat `xml:"d:propstat,omitempty"`
+	Status   string       `xml:"d:status,omitempty"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"d:prop"`
+	Status string  `xml:"d:status"`
+}
+
+type davProp struct {
+	ResourceType *davResourceType `xml:"d:resourcetype,omitempty"`
+	DisplayName  string           `xml:"d:displayname,omitempty"`
+	CTag         string           `xml:"cs:getctag,omitempty"`
+	ETag         string           `xml:"d:getetag,omitempty"`
+	ContentType  string           `xml:"d:getcontenttype,omitempty"`
+	ComponentSet *davComponentSet `xml:"c:supported-calendar-component-set,omitempty"`
+	Privileges   *davPrivilegeSet `xml:"d:current-user-privilege-set,omitempty"`
+	CalendarData string           `xml:"c:calendar-data,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"d:collection,omitempty"`
+	Calendar   *struct{} `xml:"c:calendar,omitempty"`
+}
+
+type davComponentSet struct {
+	Comp davComp `xml:"c:comp"`
+}
+
+type davComp struct {
+	Name string `xml:"name,attr"`
+}
+
+type davPrivilegeSet struct {
+	Privilege davPrivilege `xml:"d:privilege"`
+}
+
+type davPrivilege struct {
+	Read *struct{} `xml:"d:read,omitempty"`
+}
+
+func newDAVMultistatus() *davMultistatus {
+	return &davMultistatus{
+		DAV:       "DAV:",
+		CalDAV:    "urn:ietf:params:xml:ns:caldav",
+		CalServer: "http://calendarserver.org/ns/",
+		Responses: []davResponse{},
+	}
+}
+
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+func (app *application) writeMultistatus(w http.ResponseWriter, r *http.Request, ms *davMultistatus) {
+	body, err := xml.Marshal(ms)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, xml.Header)
+	w.Write(body)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type CreateCalendarFeedPayload struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+	// EmployeeID limits the feed to the employee's own shifts; without it the
+	// feed has every shift of the restaurant
+	EmployeeID *int64 `json:"employee_id" validate:"omitempty,min=1"`
+}
+
+// CalendarFeedWithToken is a new calendar feed with its token and URLs, which
+// are only ever shown here
+type CalendarFeedWithToken struct {
+	*store.CalendarFeed
+	Token string `json:"token"`
+	// URL is the CalDAV collection to add to a calendar app
+	URL string `json:"url"`
+	// WebcalURL subscribes apps that take an iCalendar feed rather than CalDAV
+	WebcalURL string `json:"webcal_url"`
+}
+
+// CreateCalendarFeed godoc
+//
+//	@Summary		Creates a calendar feed
+//	@Description	Creates a read-only CalDAV calendar of the restaurant's published shifts, or of one employee's with employee_id, that calendar apps (Apple Calendar, Thunderbird, DAVx5) add by url and keep in sync as shifts change. Apps that only subscribe to iCalendar feeds use webcal_url. The token and URLs are not shown again.
+//	@Tags			calendar-feeds
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		CreateCalendarFeedPayload	true	"Feed name and employee"
+//	@Success		201				{object}	CalendarFeedWithToken
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/calendar-feeds [post]
+func (app *application) createCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+
+	var payload CreateCalendarFeedPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	if payload.EmployeeID != nil {
+		employee, err := app.store.Employees.GetByID(ctx, *payload.EmployeeID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.notFoundResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		if employee.RestaurantID != restaurant.ID {
+			app.notFoundResponse(w, r, errors.New("employee not found in this restaurant"))
+			return
+		}
+	}
+
+	feed := &store.CalendarFeed{
+		RestaurantID: restaurant.ID,
+		EmployeeID:   payload.EmployeeID,
+		Name:         strings.TrimSpace(payload.Name),
+		CreatedBy:    &user.ID,
+	}
+	token := uuid.New().String()
+	if err := app.store.CalendarFeeds.Create(ctx, feed, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	url := fmt.Sprintf("%s/v1/caldav/%s/", app.externalBaseURL(), token)
+	_, hostAndPath, _ := strings.Cut(url, "://")
+	response := &CalendarFeedWithToken{CalendarFeed: feed, Token: token, URL: url, WebcalURL: "webcal://" + hostAndPath}
+	if err := app.jsonResponse(w, r, http.StatusCreated, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetCalendarFeeds godoc
+//
+//	@Summary		Lists a restaurant's calendar feeds
+//	@Description	Lists the restaurant's calendar feeds, revoked ones included, with when each was last synced
+//	@Tags			calendar-feeds
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.CalendarFeed
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/calendar-feeds [get]
+func (app *application) getCalendarFeedsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	feeds, err := app.store.CalendarFeeds.List(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, feeds); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RevokeCalendarFeed godoc
+//
+//	@Summary		Revokes a calendar feed
+//	@Description	Stops the feed's token from working; subscribed calendars stop syncing
+//	@Tags			calendar-feeds
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			feedID			path		int	true	"Calendar feed ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/calendar-feeds/{feedID} [delete]
+func (app *application) revokeCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	feedID, err := strconv.ParseInt(chi.URLParam(r, "feedID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.CalendarFeeds.Revoke(r.Context(), restaurant.ID, feedID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
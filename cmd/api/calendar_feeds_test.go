@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCreateCalendarFeed(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.config.apiURL = "api.example.com"
+	var saved *store.CalendarFeed
+	var savedToken string
+	app.store.CalendarFeeds = &store.MockCalendarFeedStorer{
+		CreateFunc: func(_ context.Context, feed *store.CalendarFeed, token string) error {
+			saved, savedToken = feed, token
+			feed.ID = 6
+			return nil
+		},
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/calendar-feeds", `{"name": " All shifts "}`), app.mount())
+
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+	var body struct {
+		Data CalendarFeedWithToken `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://api.example.com/v1/caldav/" + savedToken + "/"; body.Data.URL != want {
+		t.Errorf("url = %q, want %q", body.Data.URL, want)
+	}
+	if want := "webcal://api.example.com/v1/caldav/" + savedToken + "/"; body.Data.WebcalURL != want {
+		t.Errorf("webcal_url = %q, want %q", body.Data.WebcalURL, want)
+	}
+	if saved.RestaurantID != 1 || saved.Name != "All shifts" || saved.EmployeeID != nil {
+		t.Errorf("feed = %+v", saved)
+	}
+}
+
+func TestCalendarFeedCalDAV(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		return &store.Restaurant{ID: id, Name: "Blue Door", Address: "1 Main St, Springfield"}, nil
+	}
+	ana := "Ana Diaz"
+	app.store.CalendarFeeds = &store.MockCalendarFeedStorer{
+		AuthenticateFunc: func(_ context.Context, token string) (*store.CalendarFeed, error) {
+			if token != "feed-token" {
+				return nil, store.ErrNotFound
+			}
+			return &store.CalendarFeed{ID: 6, RestaurantID: 3, Name: "All shifts"}, nil
+		},
+		ListShiftsFunc: func(context.Context, int64, *int64, store.DateOnly) ([]*store.ScheduledShift, error) {
+			return []*store.ScheduledShift{
+				{ID: 41, ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "09:00:00", EndTime: "17:00:00", RoleName: "Server", EmployeeName: &ana},
+				{ID: 42, ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "18:00:00", EndTime: "23:00:00", RoleName: "Cook"},
+			}, nil
+		},
+	}
+
+	t.Run("PROPFIND lists each shift with its ETag", func(t *testing.T) {
+		req := httptest.NewRequest(methodPropfind, "/v1/caldav/feed-token/", nil)
+		req.Header.Set("Depth", "1")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusMultiStatus, rr.Code)
+		body := rr.Body.String()
+		for _, want := range []string{"<d:href>/v1/caldav/feed-token/</d:href>", "<c:calendar></c:calendar>", "<d:href>/v1/caldav/feed-token/shift-41.ics</d:href>", "<d:href>/v1/caldav/feed-token/shift-42.ics</d:href>", "<d:getetag>"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body does not contain %q:\n%s", want, body)
+			}
+		}
+	})
+
+	t.Run("calendar-multiget returns the events asked for", func(t *testing.T) {
+		req := httptest.NewRequest(methodReport, "/v1/caldav/feed-token/", strings.NewReader(`<?xml version="1.0"?>
+<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <d:href>/v1/caldav/feed-token/shift-42.ics</d:href>
+  <d:href>/v1/caldav/feed-token/shift-99.ics</d:href>
+</c:calendar-multiget>`))
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusMultiStatus, rr.Code)
+		body := rr.Body.String()
+		if !strings.Contains(body, "UID:shift-42@resa") || strings.Contains(body, "UID:shift-41@resa") {
+			t.Errorf("body should carry shift 42 only:\n%s", body)
+		}
+		if !strings.Contains(body, "<d:status>HTTP/1.1 404 Not Found</d:status>") {
+			t.Errorf("the unknown href is not reported missing:\n%s", body)
+		}
+	})
+
+	t.Run("GET serves the collection as iCalendar", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/caldav/feed-token/", nil), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get("Content-Type"); got != calendarContentType {
+			t.Errorf("Content-Type = %q", got)
+		}
+		body := rr.Body.String()
+		for _, want := range []string{"BEGIN:VCALENDAR\r\n", "SUMMARY:Server: Ana Diaz\r\n", "SUMMARY:Cook: open shift\r\n", "LOCATION:1 Main St\\, Springfield\r\n", "DTSTART:20260302T180000Z\r\n", "DTEND:20260302T230000Z\r\n"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("body does not contain %q:\n%s", want, body)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/caldav/feed-token/", nil)
+		req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+		rr = executeRequest(req, app.mount())
+		checkResponseCode(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("a revoked or unknown token", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/caldav/other-token/", nil), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestFoldICSLine(t *testing.T) {
+	folded := foldICSLine("DESCRIPTION:" + strings.Repeat("é", 60))
+
+	for _, line := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != "DESCRIPTION:"+strings.Repeat("é", 60)+"\r\n" {
+		t.Errorf("unfolded = %q", unfolded)
+	}
+}
@@ -0,0 +1,788 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultExpiringWithinDays = 30
+
+type CreateCertificationPayload struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpdateCertificationPayload struct {
+	Name *string `json:"name" validate:"omitempty,max=100"`
+}
+
+type SetEmployeeCertificationPayload struct {
+	IssuedOn  *string `json:"issued_on" validate:"omitempty,datetime=2006-01-02"`
+	ExpiresOn *string `json:"expires_on" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type SetRoleCertificationsPayload struct {
+	CertificationIDs []int64 `json:"certification_ids" validate:"dive,gt=0"`
+}
+
+type CertificationExpiryEmailResponse struct {
+	Sent           bool              `json:"sent"`
+	Email          string            `json:"email"`
+	Certifications int               `json:"certifications"`
+	Quota          *cache.EmailQuota `json:"quota,omitempty"`
+}
+
+type certificationExpiryEmailItem struct {
+	EmployeeName      string
+	CertificationName string
+	ExpiresOn         string
+	Expired           bool
+}
+
+// GetCertifications godoc
+//
+//	@Summary		Lists restaurant's certifications
+//	@Description	Fetches all certifications tracked by a restaurant
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.Certification
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications [get]
+func (app *application) getCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	certs, err := app.store.Certifications.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, certs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateCertification godoc
+//
+//	@Summary		Creates a certification
+//	@Description	Creates a certification (e.g. food handler card) for a restaurant
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		CreateCertificationPayload	true	"Certification payload"
+//	@Success		201				{object}	store.Certification
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications [post]
+func (app *application) createCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var payload CreateCertificationPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	cert := &store.Certification{
+		RestaurantID: restaurant.ID,
+		Name:         payload.Name,
+	}
+
+	if err := app.store.Certifications.Create(r.Context(), cert); err != nil {
+		if errors.Is(err, store.ErrDuplicateCertification) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, cert); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateCertification godoc
+//
+//	@Summary		Updates a certification
+//	@Description	Renames a certification
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			certificationID	path		int							true	"Certification ID"
+//	@Param			payload			body		UpdateCertificationPayload	true	"Certification payload"
+//	@Success		200				{object}	store.Certification
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications/{certificationID} [patch]
+func (app *application) updateCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	certificationID, err := strconv.ParseInt(chi.URLParam(r, "certificationID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	cert, err := app.getRestaurantCertification(r.Context(), restaurant.ID, certificationID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var payload UpdateCertificationPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Name != nil {
+		cert.Name = *payload.Name
+	}
+
+	if err := app.store.Certifications.Update(r.Context(), cert); err != nil {
+		switch {
+		case errors.Is(err, store.ErrDuplicateCertification):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, cert); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteCertification godoc
+//
+//	@Summary		Deletes a certification
+//	@Description	Deletes a certification along with every employee record and role requirement for it
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			certificationID	path	int	true	"Certification ID"
+//	@Success		204
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications/{certificationID} [delete]
+func (app *application) deleteCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	certificationID, err := strconv.ParseInt(chi.URLParam(r, "certificationID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.getRestaurantCertification(r.Context(), restaurant.ID, certificationID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Certifications.Delete(r.Context(), certificationID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetEmployeeCertifications godoc
+//
+//	@Summary		Lists employee's certifications
+//	@Description	Fetches the certifications an employee holds with their expiry dates
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{array}		store.EmployeeCertification
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/certifications [get]
+func (app *application) getEmployeeCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if employee.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("employee not found in this restaurant"))
+		return
+	}
+
+	certs, err := app.store.Certifications.ListByEmployee(r.Context(), employeeID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, certs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SetEmployeeCertification godoc
+//
+//	@Summary		Records an employee's certification
+//	@Description	Adds a certification to an employee, or replaces its issue/expiry dates
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int								true	"Restaurant ID"
+//	@Param			employeeID		path		int								true	"Employee ID"
+//	@Param			certificationID	path		int								true	"Certification ID"
+//	@Param			payload			body		SetEmployeeCertificationPayload	true	"Certification dates (YYYY-MM-DD)"
+//	@Success		200				{object}	store.EmployeeCertification
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/certifications/{certificationID} [put]
+func (app *application) setEmployeeCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	certificationID, err := strconv.ParseInt(chi.URLParam(r, "certificationID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if employee.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("employee not found in this restaurant"))
+		return
+	}
+
+	cert, err := app.getRestaurantCertification(r.Context(), restaurant.ID, certificationID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var payload SetEmployeeCertificationPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.IssuedOn != nil && payload.ExpiresOn != nil && *payload.ExpiresOn < *payload.IssuedOn {
+		app.badRequestResponse(w, r, errors.New("expires_on must not be before issued_on"))
+		return
+	}
+
+	ec := &store.EmployeeCertification{
+		EmployeeID:        employee.ID,
+		EmployeeName:      employee.FullName,
+		CertificationID:   cert.ID,
+		CertificationName: cert.Name,
+	}
+	if payload.IssuedOn != nil {
+		issuedOn := store.DateOnly(*payload.IssuedOn)
+		ec.IssuedOn = &issuedOn
+	}
+	if payload.ExpiresOn != nil {
+		expiresOn := store.DateOnly(*payload.ExpiresOn)
+		ec.ExpiresOn = &expiresOn
+	}
+
+	if err := app.store.Certifications.SetForEmployee(r.Context(), ec); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, ec); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RemoveEmployeeCertification godoc
+//
+//	@Summary		Removes an employee's certification
+//	@Description	Removes a certification record from an employee
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			employeeID		path	int	true	"Employee ID"
+//	@Param			certificationID	path	int	true	"Certification ID"
+//	@Success		204
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/certifications/{certificationID} [delete]
+func (app *application) removeEmployeeCertificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	certificationID, err := strconv.ParseInt(chi.URLParam(r, "certificationID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.getRestaurantCertification(r.Context(), restaurant.ID, certificationID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Certifications.RemoveFromEmployee(r.Context(), employeeID, certificationID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRoleCertifications godoc
+//
+//	@Summary		Lists role's required certifications
+//	@Description	Fetches the certifications an employee must hold to be assigned shifts for a role
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			roleID			path		int	true	"Role ID"
+//	@Success		200				{array}		store.Certification
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/roles/{roleID}/certifications [get]
+func (app *application) getRoleCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	roleID, err := strconv.ParseInt(chi.URLParam(r, "roleID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), roleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if role.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("role not found in this restaurant"))
+		return
+	}
+
+	certs, err := app.store.Certifications.ListRequiredByRole(r.Context(), roleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, certs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SetRoleCertifications godoc
+//
+//	@Summary		Sets role's required certifications
+//	@Description	Replaces the certifications required to be assigned shifts for a role
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int								true	"Restaurant ID"
+//	@Param			roleID			path		int								true	"Role ID"
+//	@Param			payload			body		SetRoleCertificationsPayload	true	"Required certification IDs"
+//	@Success		200				{array}		store.Certification
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/roles/{roleID}/certifications [put]
+func (app *application) setRoleCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	roleID, err := strconv.ParseInt(chi.URLParam(r, "roleID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), roleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if role.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("role not found in this restaurant"))
+		return
+	}
+
+	var payload SetRoleCertificationsPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Only certifications from this restaurant can be required
+	for _, certificationID := range payload.CertificationIDs {
+		if _, err := app.getRestaurantCertification(r.Context(), restaurant.ID, certificationID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.badRequestResponse(w, r, errors.New("one or more certifications do not belong to this restaurant"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	if err := app.store.Certifications.SetRequiredByRole(r.Context(), roleID, payload.CertificationIDs); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	certs, err := app.store.Certifications.ListRequiredByRole(r.Context(), roleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, certs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetExpiringCertifications godoc
+//
+//	@Summary		Lists expiring certifications
+//	@Description	Reports employee certifications that have expired or expire within the given number of days
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			days			query		int	false	"Look-ahead window in days (default 30)"
+//	@Success		200				{array}		store.EmployeeCertification
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications/expiring [get]
+func (app *application) getExpiringCertificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	until, err := expiringUntil(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	certs, err := app.store.Certifications.ListExpiring(r.Context(), restaurant.ID, store.DateOnly(until.Format("2006-01-02")))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, certs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SendCertificationExpiryEmail godoc
+//
+//	@Summary		Emails the expiring certifications report
+//	@Description	Sends the restaurant owner a list of certifications that have expired or expire within the given number of days
+//	@Tags			certification
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			days			query		int	false	"Look-ahead window in days (default 30)"
+//	@Success		200				{object}	CertificationExpiryEmailResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/certifications/expiring/send-email [post]
+func (app *application) sendCertificationExpiryEmailHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	until, err := expiringUntil(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	certs, err := app.store.Certifications.ListExpiring(r.Context(), restaurant.ID, store.DateOnly(until.Format("2006-01-02")))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := CertificationExpiryEmailResponse{
+		Email:          user.Email,
+		Certifications: len(certs),
+	}
+
+	// Nothing to report
+	if len(certs) == 0 {
+		if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+	response.Quota = quota
+
+	today := restaurant.Today(time.Now()).Format("2006-01-02")
+	items := make([]certificationExpiryEmailItem, 0, len(certs))
+	for _, ec := range certs {
+		expiresOn := ec.ExpiresOn.String()
+		items = append(items, certificationExpiryEmailItem{
+			EmployeeName:      ec.EmployeeName,
+			CertificationName: ec.CertificationName,
+			ExpiresOn:         expiresOn,
+			Expired:           expiresOn < today,
+		})
+	}
+
+	emailData := struct {
+		ManagerName    string
+		RestaurantName string
+		Until          string
+		Certifications []certificationExpiryEmailItem
+	}{
+		ManagerName:    user.FirstName,
+		RestaurantName: restaurant.Name,
+		Until:          until.Format("2006-01-02"),
+		Certifications: items,
+	}
+
+	isProdEnv := app.config.env == "production"
+	if _, err := app.mailer.Send(mailer.Localized(mailer.CertificationExpiryTemplate, requestLocale(r)), user.FirstName, user.Email, emailData, !isProdEnv); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response.Sent = true
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getRestaurantCertification loads a certification and checks it belongs to the restaurant
+func (app *application) getRestaurantCertification(ctx context.Context, restaurantID, certificationID int64) (*store.Certification, error) {
+	cert, err := app.store.Certifications.GetByID(ctx, certificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cert.RestaurantID != restaurantID {
+		return nil, store.ErrNotFound
+	}
+
+	return cert, nil
+}
+
+// expiringUntil reads the ?days= look-ahead window and returns the last date it covers
+func expiringUntil(r *http.Request) (time.Time, error) {
+	days := defaultExpiringWithinDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 0 {
+			return time.Time{}, errors.New("days must be a non-negative integer")
+		}
+		days = parsed
+	}
+
+	return time.Now().AddDate(0, 0, days), nil
+}
@@ -0,0 +1,539 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// Compliance rules, as named in violations
+const (
+	ruleMinRest          = "min_rest"
+	ruleConsecutiveDays  = "max_consecutive_days"
+	ruleMinorDailyHours  = "minor_daily_hours"
+	ruleMinorWeeklyHours = "minor_weekly_hours"
+	ruleMinorLatestEnd   = "minor_latest_end"
+)
+
+// ComplianceRulesPayload picks a jurisdiction template; any other field set
+// overrides the template's value. Set a limit to 0 or a severity to off to turn
+// its rule off, and minor_latest_end to "" to drop that limit.
+type ComplianceRulesPayload struct {
+	Jurisdiction            string  `json:"jurisdiction" validate:"required,oneof=custom us_federal california eu"`
+	MinRestHours            *int    `json:"min_rest_hours" validate:"omitempty,min=0,max=24"`
+	RestSeverity            *string `json:"rest_severity" validate:"omitempty,oneof=off warn block"`
+	MaxConsecutiveDays      *int    `json:"max_consecutive_days" validate:"omitempty,min=0,max=13"`
+	ConsecutiveDaysSeverity *string `json:"consecutive_days_severity" validate:"omitempty,oneof=off warn block"`
+	MinorAge                *int    `json:"minor_age" validate:"omitempty,min=0,max=21"`
+	MinorMaxDailyHours      *int    `json:"minor_max_daily_hours" validate:"omitempty,min=0,max=24"`
+	MinorMaxWeeklyHours     *int    `json:"minor_max_weekly_hours" validate:"omitempty,min=0,max=168"`
+	MinorLatestEnd          *string `json:"minor_latest_end"`
+	MinorSeverity           *string `json:"minor_severity" validate:"omitempty,oneof=off warn block"`
+}
+
+// ComplianceViolation is a shift that breaks one of the restaurant's compliance rules
+type ComplianceViolation struct {
+	Rule         string                   `json:"rule"`
+	Severity     store.ComplianceSeverity `json:"severity"`
+	EmployeeID   int64                    `json:"employee_id"`
+	EmployeeName string                   `json:"employee_name"`
+	ShiftID      int64                    `json:"shift_id"`
+	Date         store.DateOnly           `json:"date"`
+	Message      string                   `json:"message"`
+}
+
+// GetComplianceRules godoc
+//
+//	@Summary		Gets a restaurant's compliance rules
+//	@Description	Returns the working-hour rules checked when employees are assigned and schedules are published. Restaurants that haven't set any get the custom template, which checks nothing.
+//	@Tags			compliance
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.ComplianceRules
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/compliance-rules [get]
+func (app *application) getComplianceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	rules, err := app.store.Compliance.Get(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, rules); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateComplianceRules godoc
+//
+//	@Summary		Sets a restaurant's compliance rules
+//	@Description	Starts from a jurisdiction template (custom checks nothing; us_federal, california and eu follow the common limits there) and applies the fields given over it. Rules are minimum rest hours between working days, maximum consecutive working days, and daily hours, weekly hours (over the restaurant's weeks, from its week_start) and latest end time for employees younger than minor_age, going by their birthday. A rule set to block refuses assignments and publishing that break it; warn lets them through with a warning.
+//	@Tags			compliance
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			payload			body		ComplianceRulesPayload	true	"Compliance rules"
+//	@Success		200				{object}	store.ComplianceRules
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/compliance-rules [put]
+func (app *application) updateComplianceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload ComplianceRulesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	rules, err := payload.rules()
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	rules.RestaurantID = restaurant.ID
+
+	if err := app.store.Compliance.Replace(r.Context(), rules); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, rules); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// rules applies the payload over its jurisdiction's template
+func (p ComplianceRulesPayload) rules() (*store.ComplianceRules, error) {
+	rules, ok := store.ComplianceTemplate(p.Jurisdiction)
+	if !ok {
+		return nil, fmt.Errorf("unknown jurisdiction %q", p.Jurisdiction)
+	}
+
+	for field, value := range map[*int]*int{
+		&rules.MinRestHours:        p.MinRestHours,
+		&rules.MaxConsecutiveDays:  p.MaxConsecutiveDays,
+		&rules.MinorAge:            p.MinorAge,
+		&rules.MinorMaxDailyHours:  p.MinorMaxDailyHours,
+		&rules.MinorMaxWeeklyHours: p.MinorMaxWeeklyHours,
+	} {
+		if value != nil {
+			*field = *value
+		}
+	}
+	for field, value := range map[*store.ComplianceSeverity]*string{
+		&rules.RestSeverity:            p.RestSeverity,
+		&rules.ConsecutiveDaysSeverity: p.ConsecutiveDaysSeverity,
+		&rules.MinorSeverity:           p.MinorSeverity,
+	} {
+		if value != nil {
+			*field = store.ComplianceSeverity(*value)
+		}
+	}
+
+	if p.MinorLatestEnd != nil {
+		rules.MinorLatestEnd = nil
+		if *p.MinorLatestEnd != "" {
+			end, err := time.Parse("15:04", *p.MinorLatestEnd)
+			if err != nil {
+				return nil, errors.New("minor_latest_end must be in format HH:MM")
+			}
+			latest := store.TimeOfDay(end.Format("15:04:05"))
+			rules.MinorLatestEnd = &latest
+		}
+	}
+
+	return &rules, nil
+}
+
+// complianceChecker checks assignments against the restaurant's compliance
+// rules, so a single assignment and a batch of them (auto-assign, bid
+// allocation) go through the same check
+type complianceChecker struct {
+	// rules is nil when no rule is on
+	rules *store.ComplianceRules
+	// restaurant's weeks are the ones the weekly limits count
+	restaurant *store.Restaurant
+	employees  map[int64]*store.Employee
+	// shifts are each employee's shifts around the dates being assigned
+	shifts map[int64][]*store.ScheduledShift
+}
+
+// newComplianceChecker loads the rules, and what checking assignments to
+// shifts from one date to another against them takes
+func (app *application) newComplianceChecker(ctx context.Context, restaurantID int64, from, to time.Time) (*complianceChecker, error) {
+	rules, err := app.store.Compliance.Get(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	if !rules.Enabled() {
+		return &complianceChecker{}, nil
+	}
+
+	restaurant, err := app.restaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := complianceLookaround(rules)
+	nearby, err := app.store.ScheduledShifts.ListByRestaurantAndRange(ctx, restaurantID,
+		dateOnly(from.AddDate(0, 0, -days)), dateOnly(to.AddDate(0, 0, days)))
+	if err != nil {
+		return nil, err
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &complianceChecker{
+		rules:      rules,
+		restaurant: restaurant,
+		employees:  make(map[int64]*store.Employee, len(employees)),
+		shifts:     make(map[int64][]*store.ScheduledShift),
+	}
+	for _, employee := range employees {
+		checker.employees[employee.ID] = employee
+	}
+	for _, s := range nearby {
+		if s.EmployeeID != nil {
+			checker.shifts[*s.EmployeeID] = append(checker.shifts[*s.EmployeeID], s)
+		}
+	}
+	return checker, nil
+}
+
+// check reports the rules the employee breaks by taking the shift, counting
+// their shifts around it and booked, the ones a batch already gave them
+func (c *complianceChecker) check(shift *store.ScheduledShift, employeeID int64, booked []*store.ScheduledShift) []ComplianceViolation {
+	if c.rules == nil {
+		return nil
+	}
+	employee, ok := c.employees[employeeID]
+	if !ok {
+		// the assignment itself reports the missing employee
+		return nil
+	}
+
+	assigned := *shift
+	assigned.EmployeeID = &employeeID
+	shifts := []*store.ScheduledShift{&assigned}
+	seen := map[int64]bool{shift.ID: true}
+	for _, s := range append(c.shifts[employeeID], booked...) {
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			shifts = append(shifts, s)
+		}
+	}
+
+	return checkCompliance(c.rules, c.restaurant, employee, shifts, func(s *store.ScheduledShift) bool { return s == &assigned })
+}
+
+// scheduleCompliance checks the rules for every employee with a shift on the
+// schedule, against their shifts on it and around it
+func (app *application) scheduleCompliance(ctx context.Context, schedule *store.Schedule) ([]ComplianceViolation, error) {
+	rules, err := app.store.Compliance.Get(ctx, schedule.RestaurantID)
+	if err != nil || !rules.Enabled() {
+		return nil, err
+	}
+
+	restaurant, err := app.restaurantByID(ctx, schedule.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := schedule.StartDate.ToTime()
+	if err != nil {
+		return nil, err
+	}
+	end, err := schedule.EndDate.ToTime()
+	if err != nil {
+		return nil, err
+	}
+
+	days := complianceLookaround(rules)
+	shifts, err := app.store.ScheduledShifts.ListByRestaurantAndRange(ctx, schedule.RestaurantID,
+		dateOnly(start.AddDate(0, 0, -days)), dateOnly(end.AddDate(0, 0, days)))
+	if err != nil {
+		return nil, err
+	}
+
+	byEmployee := make(map[int64][]*store.ScheduledShift)
+	onSchedule := make(map[int64]bool)
+	for _, s := range shifts {
+		if s.EmployeeID == nil {
+			continue
+		}
+		byEmployee[*s.EmployeeID] = append(byEmployee[*s.EmployeeID], s)
+		if s.ScheduleID == schedule.ID {
+			onSchedule[*s.EmployeeID] = true
+		}
+	}
+	if len(onSchedule) == 0 {
+		return nil, nil
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(ctx, schedule.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := []ComplianceViolation{}
+	for _, employee := range employees {
+		if !onSchedule[employee.ID] {
+			continue
+		}
+		violations = append(violations, checkCompliance(rules, restaurant, employee, byEmployee[employee.ID], func(s *store.ScheduledShift) bool {
+			return s.ScheduleID == schedule.ID
+		})...)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Date < violations[j].Date })
+	return violations, nil
+}
+
+// shiftDates is the first and last date of the shifts
+func shiftDates(shifts []*store.ScheduledShift) (time.Time, time.Time) {
+	var from, to time.Time
+	for i, s := range shifts {
+		if i == 0 || s.ShiftDate.Before(from) {
+			from = s.ShiftDate
+		}
+		if i == 0 || s.ShiftDate.After(to) {
+			to = s.ShiftDate
+		}
+	}
+	return from, to
+}
+
+// complianceLookaround is how many days either side of the shifts being checked
+// the rules look at: a week for the weekly limits, more for long streaks
+func complianceLookaround(rules *store.ComplianceRules) int {
+	return max(7, rules.MaxConsecutiveDays)
+}
+
+// checkCompliance tests the employee's shifts against the rules, reporting only
+// violations that involve a shift for which checked is true. Weekly limits
+// count the restaurant's weeks.
+func checkCompliance(rules *store.ComplianceRules, restaurant *store.Restaurant, employee *store.Employee, shifts []*store.ScheduledShift, checked func(*store.ScheduledShift) bool) []ComplianceViolation {
+	shifts = append([]*store.ScheduledShift(nil), shifts...)
+	sort.Slice(shifts, func(i, j int) bool {
+		si, _ := shiftSpan(shifts[i])
+		sj, _ := shiftSpan(shifts[j])
+		return si.Before(sj)
+	})
+
+	// working days in order, each with its shifts
+	type workday struct {
+		date   time.Time
+		shifts []*store.ScheduledShift
+	}
+	var days []*workday
+	for _, s := range shifts {
+		y, m, d := s.ShiftDate.Date()
+		date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		if n := len(days); n > 0 && days[n-1].date.Equal(date) {
+			days[n-1].shifts = append(days[n-1].shifts, s)
+			continue
+		}
+		days = append(days, &workday{date: date, shifts: []*store.ScheduledShift{s}})
+	}
+	firstChecked := func(days ...*workday) *store.ScheduledShift {
+		for _, d := range days {
+			for _, s := range d.shifts {
+				if checked(s) {
+					return s
+				}
+			}
+		}
+		return nil
+	}
+
+	violations := []ComplianceViolation{}
+	report := func(rule string, severity store.ComplianceSeverity, shift *store.ScheduledShift, format string, args ...any) {
+		violations = append(violations, ComplianceViolation{
+			Rule:         rule,
+			Severity:     severity,
+			EmployeeID:   employee.ID,
+			EmployeeName: employee.FullName,
+			ShiftID:      shift.ID,
+			Date:         dateOnly(shift.ShiftDate),
+			Message:      employee.FullName + " " + fmt.Sprintf(format, args...),
+		})
+	}
+
+	// rest is measured between working days, so a split shift doesn't count against it
+	if rules.MinRestHours > 0 && rules.RestSeverity != store.ComplianceOff {
+		for i := 1; i < len(days); i++ {
+			prev, next := days[i-1], days[i]
+			shift := firstChecked(next, prev)
+			if shift == nil {
+				continue
+			}
+			_, finished := shiftSpan(prev.shifts[len(prev.shifts)-1])
+			for _, s := range prev.shifts {
+				if _, end := shiftSpan(s); end.After(finished) {
+					finished = end
+				}
+			}
+			started, _ := shiftSpan(next.shifts[0])
+			if rest := started.Sub(finished); rest < time.Duration(rules.MinRestHours)*time.Hour {
+				report(ruleMinRest, rules.RestSeverity, shift, "has %s of rest between %s and %s, less than %d hours",
+					formatRest(rest), prev.date.Format("2006-01-02"), next.date.Format("2006-01-02"), rules.MinRestHours)
+			}
+		}
+	}
+
+	if rules.MaxConsecutiveDays > 0 && rules.ConsecutiveDaysSeverity != store.ComplianceOff {
+		for start := 0; start < len(days); {
+			end := start
+			for end+1 < len(days) && days[end+1].date.Equal(days[end].date.AddDate(0, 0, 1)) {
+				end++
+			}
+			streak := days[start : end+1]
+			if len(streak) > rules.MaxConsecutiveDays {
+				if shift := firstChecked(streak...); shift != nil {
+					report(ruleConsecutiveDays, rules.ConsecutiveDaysSeverity, shift, "works %d days in a row from %s to %s, more than %d",
+						len(streak), streak[0].date.Format("2006-01-02"), streak[len(streak)-1].date.Format("2006-01-02"), rules.MaxConsecutiveDays)
+				}
+			}
+			start = end + 1
+		}
+	}
+
+	if rules.MinorAge > 0 && rules.MinorSeverity != store.ComplianceOff && employee.Birthday != nil {
+		birthday, err := employee.Birthday.ToTime()
+		if err != nil {
+			return violations
+		}
+
+		type week struct {
+			start   time.Time
+			minutes int
+			days    []*workday
+		}
+		var weeks []*week
+		weekOf := make(map[time.Time]*week)
+		for _, d := range days {
+			if ageOn(birthday, d.date) >= rules.MinorAge {
+				continue
+			}
+
+			minutes := 0
+			for _, s := range d.shifts {
+				minutes += shiftMinutes(s)
+				if rules.MinorLatestEnd != nil && checked(s) && hourMinute(s.EndTime) > hourMinute(*rules.MinorLatestEnd) {
+					report(ruleMinorLatestEnd, rules.MinorSeverity, s, "is under %d and works until %s on %s, past %s",
+						rules.MinorAge, hourMinute(s.EndTime), d.date.Format("2006-01-02"), hourMinute(*rules.MinorLatestEnd))
+				}
+			}
+			if rules.MinorMaxDailyHours > 0 && minutes > rules.MinorMaxDailyHours*60 {
+				if shift := firstChecked(d); shift != nil {
+					report(ruleMinorDailyHours, rules.MinorSeverity, shift, "is under %d and works %s hours on %s, more than %d",
+						rules.MinorAge, formatHours(minutes), d.date.Format("2006-01-02"), rules.MinorMaxDailyHours)
+				}
+			}
+
+			start, _ := restaurant.WeekOf(d.date)
+			w, ok := weekOf[start]
+			if !ok {
+				w = &week{start: start}
+				weekOf[start] = w
+				weeks = append(weeks, w)
+			}
+			w.minutes += minutes
+			w.days = append(w.days, d)
+		}
+
+		if rules.MinorMaxWeeklyHours > 0 {
+			for _, w := range weeks {
+				if w.minutes <= rules.MinorMaxWeeklyHours*60 {
+					continue
+				}
+				if shift := firstChecked(w.days...); shift != nil {
+					report(ruleMinorWeeklyHours, rules.MinorSeverity, shift, "is under %d and works %s hours in the week of %s, more than %d",
+						rules.MinorAge, formatHours(w.minutes), w.start.Format("2006-01-02"), rules.MinorMaxWeeklyHours)
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// shiftSpan is when the shift starts and ends on its date
+func shiftSpan(shift *store.ScheduledShift) (time.Time, time.Time) {
+	y, m, d := shift.ShiftDate.Date()
+	at := func(t store.TimeOfDay) time.Time {
+		clock, _ := time.Parse("15:04", hourMinute(t))
+		return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+	}
+	return at(shift.StartTime), at(shift.EndTime)
+}
+
+// ageOn is how many full years old someone born on birthday is on date
+func ageOn(birthday, date time.Time) int {
+	age := date.Year() - birthday.Year()
+	if date.Month() < birthday.Month() || (date.Month() == birthday.Month() && date.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
+
+func formatHours(minutes int) string {
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(minutes)/60), ".0")
+}
+
+func formatRest(rest time.Duration) string {
+	minutes := max(0, int(rest.Minutes()))
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// blockingViolations picks out the violations of rules set to block
+func blockingViolations(violations []ComplianceViolation) []ComplianceViolation {
+	var blocking []ComplianceViolation
+	for _, v := range violations {
+		if v.Severity == store.ComplianceBlock {
+			blocking = append(blocking, v)
+		}
+	}
+	return blocking
+}
+
+// complianceError describes the violations in one message
+func complianceError(violations []ComplianceViolation) error {
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return errors.New("compliance rules broken: " + strings.Join(messages, "; "))
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCheckCompliance(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	shift := func(id int64, d int, start, end store.TimeOfDay) *store.ScheduledShift {
+		return &store.ScheduledShift{ID: id, ScheduleID: 5, ShiftDate: day(d), StartTime: start, EndTime: end}
+	}
+	all := func(*store.ScheduledShift) bool { return true }
+	rules := func(jurisdiction string) *store.ComplianceRules {
+		r, _ := store.ComplianceTemplate(jurisdiction)
+		return &r
+	}
+	rulesOf := func(violations []ComplianceViolation) []string {
+		names := []string{}
+		for _, v := range violations {
+			names = append(names, v.Rule)
+		}
+		return names
+	}
+
+	monday := &store.Restaurant{WeekStart: int(time.Monday)}
+	adult := &store.Employee{ID: 7, FullName: "Alex Smith"}
+	birthday := store.DateOnly("2010-06-03")
+	minor := &store.Employee{ID: 8, FullName: "Sam Lee", Birthday: &birthday}
+
+	t.Run("rest between working days", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{
+			shift(1, 1, "11:00:00", "14:00:00"),
+			shift(2, 1, "17:00:00", "23:00:00"),
+			shift(3, 2, "07:00:00", "15:00:00"),
+			shift(4, 3, "10:00:00", "18:00:00"),
+		}
+		got := checkCompliance(rules("eu"), monday, adult, shifts, all)
+		if len(got) != 1 || got[0].Rule != ruleMinRest || got[0].ShiftID != 3 || got[0].Severity != store.ComplianceBlock {
+			t.Fatalf("violations = %+v, want only the 8h rest before shift 3", got)
+		}
+		if got[0].Message != "Alex Smith has 8h of rest between 2026-06-01 and 2026-06-02, less than 11 hours" {
+			t.Errorf("message = %q", got[0].Message)
+		}
+	})
+
+	t.Run("consecutive days", func(t *testing.T) {
+		var shifts []*store.ScheduledShift
+		for d := 1; d <= 7; d++ {
+			shifts = append(shifts, shift(int64(d), d, "10:00:00", "16:00:00"))
+		}
+		got := checkCompliance(rules("california"), monday, adult, shifts, all)
+		if len(got) != 1 || got[0].Rule != ruleConsecutiveDays || got[0].Severity != store.ComplianceWarn {
+			t.Fatalf("violations = %+v, want a warning for the seven day streak", got)
+		}
+
+		// a day off breaks the streak
+		shifts = slices.Delete(shifts, 3, 4)
+		if got := checkCompliance(rules("california"), monday, adult, shifts, all); len(got) != 0 {
+			t.Errorf("violations = %+v, want none with a day off", got)
+		}
+	})
+
+	t.Run("minors until their birthday", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{
+			shift(1, 1, "12:00:00", "20:30:00"),
+			shift(2, 2, "14:00:00", "22:00:00"),
+			// turns 16 on the 3rd
+			shift(3, 3, "14:00:00", "23:00:00"),
+		}
+		got := checkCompliance(rules("us_federal"), monday, minor, shifts, all)
+		if want := []string{ruleMinorLatestEnd, ruleMinorDailyHours, ruleMinorLatestEnd}; !slices.Equal(rulesOf(got), want) {
+			t.Errorf("rules = %v, want %v", rulesOf(got), want)
+		}
+
+		noBirthday := &store.Employee{ID: 9}
+		if got := checkCompliance(rules("us_federal"), monday, noBirthday, shifts, all); len(got) != 0 {
+			t.Errorf("violations = %+v, want none without a birthday", got)
+		}
+	})
+
+	t.Run("minor weekly hours", func(t *testing.T) {
+		var shifts []*store.ScheduledShift
+		// Monday 8 June to Saturday 13 June, 7 hours a day
+		for d := 8; d <= 13; d++ {
+			shifts = append(shifts, shift(int64(d), d, "10:00:00", "17:00:00"))
+		}
+		young := &store.Employee{ID: 8, FullName: "Sam Lee", Birthday: &birthday}
+		r := rules("eu")
+		r.MinRestHours, r.MaxConsecutiveDays = 0, 0
+
+		got := checkCompliance(r, monday, young, shifts, all)
+		if len(got) != 1 || got[0].Rule != ruleMinorWeeklyHours || got[0].Message != "Sam Lee is under 18 and works 42 hours in the week of 2026-06-08, more than 40" {
+			t.Errorf("violations = %+v", got)
+		}
+
+		// the restaurant's weeks are counted, whichever day they begin
+		sunday := &store.Restaurant{WeekStart: int(time.Sunday)}
+		got = checkCompliance(r, sunday, young, shifts, all)
+		if len(got) != 1 || got[0].Message != "Sam Lee is under 18 and works 42 hours in the week of 2026-06-07, more than 40" {
+			t.Errorf("violations with Sunday weeks = %+v", got)
+		}
+		thursday := &store.Restaurant{WeekStart: int(time.Thursday)}
+		if got := checkCompliance(r, thursday, young, shifts, all); len(got) != 0 {
+			t.Errorf("violations with Thursday weeks = %+v, want none with 21 hours in each", got)
+		}
+	})
+
+	t.Run("only checked shifts are reported", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{
+			shift(1, 1, "17:00:00", "23:00:00"),
+			shift(2, 2, "07:00:00", "15:00:00"),
+			shift(3, 10, "09:00:00", "17:00:00"),
+		}
+		got := checkCompliance(rules("eu"), monday, adult, shifts, func(s *store.ScheduledShift) bool { return s.ID == 3 })
+		if len(got) != 0 {
+			t.Errorf("violations = %+v, want none involving shift 3", got)
+		}
+
+		// a violation with an unchecked shift lands on the checked one
+		got = checkCompliance(rules("eu"), monday, adult, shifts, func(s *store.ScheduledShift) bool { return s.ID == 1 })
+		if len(got) != 1 || got[0].ShiftID != 1 {
+			t.Errorf("violations = %+v, want the rest violation on shift 1", got)
+		}
+	})
+
+	t.Run("custom checks nothing", func(t *testing.T) {
+		if rules("custom").Enabled() {
+			t.Error("the custom template has a rule on")
+		}
+	})
+}
+
+func TestComplianceRulesPayload(t *testing.T) {
+	warn, hours, noLimit := "warn", 10, ""
+	payload := ComplianceRulesPayload{
+		Jurisdiction:   "eu",
+		MinRestHours:   &hours,
+		RestSeverity:   &warn,
+		MinorLatestEnd: &noLimit,
+	}
+	if err := Validate.Struct(payload); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := payload.rules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules.MinRestHours != 10 || rules.RestSeverity != store.ComplianceWarn || rules.MinorLatestEnd != nil {
+		t.Errorf("rules = %+v, want the overrides", rules)
+	}
+	if rules.MaxConsecutiveDays != 6 || rules.MinorAge != 18 || rules.MinorSeverity != store.ComplianceBlock {
+		t.Errorf("rules = %+v, want the rest from the eu template", rules)
+	}
+
+	for _, jurisdiction := range store.ComplianceJurisdictions {
+		if err := Validate.Struct(ComplianceRulesPayload{Jurisdiction: jurisdiction}); err != nil {
+			t.Errorf("jurisdiction %s: %v", jurisdiction, err)
+		}
+	}
+
+	late := "25:00"
+	if _, err := (ComplianceRulesPayload{Jurisdiction: "custom", MinorLatestEnd: &late}).rules(); err == nil {
+		t.Error("want an error for an invalid latest end")
+	}
+}
+
+func TestComplianceOnAssignAndPublish(t *testing.T) {
+	employeeID := int64(7)
+	june := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	// the night before, on last week's schedule
+	previous := &store.ScheduledShift{ID: 41, ScheduleID: 4, RestaurantID: 3, EmployeeID: &employeeID, ShiftDate: june(1), StartTime: "17:00:00", EndTime: "23:00:00"}
+	opening := &store.ScheduledShift{ID: 42, ScheduleID: 5, RestaurantID: 3, ShiftDate: june(2), StartTime: "07:00:00", EndTime: "15:00:00"}
+
+	setup := func(t *testing.T, severity store.ComplianceSeverity) (*application, *bool) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.compliance.GetFunc = func(_ context.Context, id int64) (*store.ComplianceRules, error) {
+			rules, _ := store.ComplianceTemplate("eu")
+			rules.RestaurantID, rules.RestSeverity = id, severity
+			return &rules, nil
+		}
+
+		changed := false
+		assigned := *opening
+		assigned.EmployeeID = &employeeID
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-06-02", EndDate: "2026-06-08"}, nil
+			},
+			PublishFunc: func(context.Context, int64, time.Time) error {
+				changed = true
+				return nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				if changed {
+					copied := assigned
+					return &copied, nil
+				}
+				copied := *opening
+				return &copied, nil
+			},
+			ListByRestaurantAndRangeFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{previous, &assigned}, nil
+			},
+			ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{&assigned}, nil
+			},
+			AssignEmployeeFunc: func(context.Context, int64, store.ShiftAssignment) error {
+				changed = true
+				return nil
+			},
+		}
+		employee := &store.Employee{ID: employeeID, FullName: "Alex Smith"}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc:          func(context.Context, int64) (*store.Employee, error) { return employee, nil },
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) { return []*store.Employee{employee}, nil },
+			GetRolesFunc: func(context.Context, int64, int64) ([]*store.Role, error) {
+				return []*store.Role{{ID: opening.RoleID}}, nil
+			},
+		}
+		app.store.Certifications = &store.MockCertificationStorer{
+			MissingForAssignmentFunc: func(context.Context, int64, int64, time.Time) ([]string, error) { return nil, nil },
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		app.store.Notifications = &store.MockNotificationStorer{
+			CreateManyFunc: func(context.Context, []*store.Notification) error { return nil },
+		}
+		return app, &changed
+	}
+
+	assign := func(app *application) *http.Request {
+		return authedRequest(t, app, http.MethodPatch, "/v1/restaurants/3/schedules/5/shifts/42/assign", `{"employee_id": 7}`)
+	}
+	publish := func(app *application, query string) *http.Request {
+		return authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/publish"+query, "")
+	}
+
+	t.Run("blocking rule refuses the assignment", func(t *testing.T) {
+		app, changed := setup(t, store.ComplianceBlock)
+
+		rr := executeRequest(assign(app), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if *changed {
+			t.Error("assignment breaking a blocking rule was saved")
+		}
+	})
+
+	t.Run("warning rule flags the assignment", func(t *testing.T) {
+		app, changed := setup(t, store.ComplianceWarn)
+
+		rr := executeRequest(assign(app), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data store.ScheduledShift `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !*changed || !slices.Contains(body.Data.Warnings, store.WarningCompliance) {
+			t.Errorf("changed = %v, warnings = %v, want the assignment saved with a compliance warning", *changed, body.Data.Warnings)
+		}
+	})
+
+	t.Run("blocking rule refuses assigning in a patch", func(t *testing.T) {
+		app, changed := setup(t, store.ComplianceBlock)
+		app.store.OperatingHours = &store.MockOperatingHoursStorer{
+			GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+				return &store.OperatingHours{RestaurantID: restaurantID}, nil
+			},
+			ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+				return nil, nil
+			},
+		}
+		app.store.ScheduledShifts.(*store.MockScheduledShiftStorer).UpdateFunc = func(context.Context, *store.ScheduledShift) error {
+			*changed = true
+			return nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/3/schedules/5/shifts/42", `{"employee_id": 7}`), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if *changed {
+			t.Error("patch breaking a blocking rule was saved")
+		}
+	})
+
+	t.Run("blocking rule refuses publishing even with force", func(t *testing.T) {
+		app, published := setup(t, store.ComplianceBlock)
+
+		rr := executeRequest(publish(app, "?force=true"), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if *published {
+			t.Error("schedule breaking a blocking rule was published")
+		}
+		var body struct {
+			Violations []ComplianceViolation `json:"violations"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Violations) != 1 || body.Violations[0].ShiftID != 42 || body.Violations[0].Rule != ruleMinRest {
+			t.Errorf("violations = %+v", body.Violations)
+		}
+	})
+
+	t.Run("warning rule publishes with warnings", func(t *testing.T) {
+		app, published := setup(t, store.ComplianceWarn)
+
+		rr := executeRequest(publish(app, ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data PublishScheduleResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !*published || len(body.Data.ComplianceWarnings) != 1 || body.Data.LaborCost != nil {
+			t.Errorf("published = %v, result = %+v", *published, body.Data)
+		}
+	})
+}
+
+func TestComplianceCheckerCountsBookedShifts(t *testing.T) {
+	rules, _ := store.ComplianceTemplate("eu")
+	employeeID := int64(7)
+	checker := &complianceChecker{
+		rules:     &rules,
+		employees: map[int64]*store.Employee{employeeID: {ID: employeeID, FullName: "Alex Smith"}},
+	}
+	closing := &store.ScheduledShift{ID: 41, ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "17:00:00", EndTime: "23:00:00"}
+	opening := &store.ScheduledShift{ID: 42, ShiftDate: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC), StartTime: "07:00:00", EndTime: "15:00:00"}
+
+	if violations := checker.check(opening, employeeID, nil); len(violations) != 0 {
+		t.Errorf("violations = %+v, want none on its own", violations)
+	}
+
+	// a batch that already gave them the closing shift can't give them the opening one
+	violations := checker.check(opening, employeeID, []*store.ScheduledShift{closing})
+	if len(violations) != 1 || violations[0].Rule != ruleMinRest || violations[0].ShiftID != 42 {
+		t.Errorf("violations = %+v, want the opening shift short of rest", violations)
+	}
+}
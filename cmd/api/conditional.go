@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// notModified sets ETag/Last-Modified for a collection response and, when the client's
+// If-None-Match still matches, writes a 304 so the handler can skip loading the collection.
+func (app *application) notModified(w http.ResponseWriter, r *http.Request, scope string, version *store.CollectionVersion) bool {
+	etag := fmt.Sprintf(`W/"%s-%d-%d"`, scope, version.Count, version.LastModified.UnixNano())
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if !version.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", version.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
+
+// etagMatches reports whether the request's If-None-Match names etag
+func etagMatches(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestNotModified(t *testing.T) {
+	app := newTestApplication(t)
+	version := &store.CollectionVersion{Count: 3, LastModified: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	first := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/restaurants/1/roles", nil)
+	if app.notModified(first, req, "roles-1", version) {
+		t.Fatal("expected a full response without If-None-Match")
+	}
+
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+
+	t.Run("should return 304 when the ETag matches", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/restaurants/1/roles", nil)
+		req.Header.Set("If-None-Match", etag)
+
+		if !app.notModified(rr, req, "roles-1", version) {
+			t.Fatal("expected not modified")
+		}
+		checkResponseCode(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("should change the ETag when a row is deleted", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/restaurants/1/roles", nil)
+		req.Header.Set("If-None-Match", etag)
+
+		deleted := &store.CollectionVersion{Count: 2, LastModified: version.LastModified}
+		if app.notModified(rr, req, "roles-1", deleted) {
+			t.Fatal("expected a full response after a deletion")
+		}
+	})
+}
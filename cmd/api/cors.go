@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-chi/cors"
+)
+
+type corsConfig struct {
+	// origins is a comma-separated list of allowed origins, each with at most one
+	// "*" wildcard (https://*.example.com, http://localhost:*); empty falls back to
+	// the environment's default
+	origins string
+	// headers are request headers allowed on top of the defaults, comma-separated
+	headers string
+	// maxAge is how long a browser may cache a preflight response
+	maxAge time.Duration
+}
+
+var corsDefaultHeaders = []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Access-Control-Request-Method", "Access-Control-Request-Headers", "If-None-Match"}
+
+// corsOptions builds the CORS policy from the config. Without configured origins a
+// development server accepts any localhost port and everywhere else only the frontend.
+func (app *application) corsOptions() cors.Options {
+	origins := splitList(app.config.cors.origins)
+	if len(origins) == 0 {
+		if app.config.env == "development" {
+			origins = []string{"http://localhost:*", "http://127.0.0.1:*"}
+		} else {
+			origins = []string{app.config.frontendURL}
+		}
+	}
+
+	return cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   append(append([]string{}, corsDefaultHeaders...), splitList(app.config.cors.headers)...),
+		ExposedHeaders:   []string{"Link", "ETag", "Last-Modified", "API-Version", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
+		AllowCredentials: false,
+		MaxAge:           int(app.config.cors.maxAge / time.Second),
+	}
+}
+
+// splitList splits a comma-separated setting, dropping blanks
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
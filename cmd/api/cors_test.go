@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.config.env = "production"
+	app.config.cors = corsConfig{
+		origins: "https://app.example.com, https://*.staging.example.com",
+		headers: "X-Request-ID",
+		maxAge:  10 * time.Minute,
+	}
+
+	preflight := func(origin, method, headers string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/restaurants/1", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		return executeRequest(req, app.mount())
+	}
+
+	t.Run("allows a listed origin to PATCH", func(t *testing.T) {
+		rr := preflight("https://app.example.com", http.MethodPatch, "Authorization, Content-Type")
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != http.MethodPatch {
+			t.Errorf("Access-Control-Allow-Methods = %q, want PATCH", got)
+		}
+		if got := rr.Header().Get("Access-Control-Max-Age"); got != "600" {
+			t.Errorf("Access-Control-Max-Age = %q, want 600", got)
+		}
+	})
+
+	t.Run("matches a wildcard origin", func(t *testing.T) {
+		rr := preflight("https://pr-42.staging.example.com", http.MethodGet, "")
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://pr-42.staging.example.com" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+		}
+	})
+
+	t.Run("rejects other origins", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.example.com", "http://localhost:3000"} {
+			rr := preflight(origin, http.MethodGet, "")
+			if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("%s: Access-Control-Allow-Origin = %q, want none", origin, got)
+			}
+		}
+	})
+
+	t.Run("allows configured headers only", func(t *testing.T) {
+		rr := preflight("https://app.example.com", http.MethodPost, "X-Request-ID")
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "X-Request-Id" {
+			t.Errorf("Access-Control-Allow-Headers = %q, want X-Request-Id", got)
+		}
+
+		rr = preflight("https://app.example.com", http.MethodPost, "X-Debug")
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin = %q for an unlisted header, want none", got)
+		}
+	})
+
+	t.Run("falls back to any localhost port in development", func(t *testing.T) {
+		app.config.env = "development"
+		app.config.cors.origins = ""
+		defer func() { app.config.env = "production" }()
+
+		rr := preflight("http://localhost:5173", http.MethodDelete, "")
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+		}
+	})
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// displayBoardRefreshSeconds is how often a board is meant to poll for changes
+const displayBoardRefreshSeconds = 60
+
+type CreateDisplayBoardPayload struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// DisplayBoardWithToken is a new display board with its token and URLs, which
+// are only ever shown here
+type DisplayBoardWithToken struct {
+	*store.DisplayBoard
+	Token   string `json:"token"`
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url"`
+}
+
+// BoardView is the day's published shifts as a display board shows them
+type BoardView struct {
+	RestaurantName string         `json:"restaurant_name"`
+	BoardName      string         `json:"board_name"`
+	Date           store.DateOnly `json:"date"`
+	// RefreshSeconds is how often to poll; send the ETag back as If-None-Match
+	// and a 304 means nothing changed
+	RefreshSeconds int          `json:"refresh_seconds"`
+	Shifts         []BoardShift `json:"shifts"`
+}
+
+// BoardShift is a shift on a display board, without internal IDs
+type BoardShift struct {
+	StartTime    store.TimeOfDay `json:"start_time"`
+	EndTime      store.TimeOfDay `json:"end_time"`
+	RoleName     string          `json:"role_name"`
+	RoleColor    string          `json:"role_color"`
+	EmployeeName *string         `json:"employee_name,omitempty"`
+	Training     bool            `json:"training"`
+}
+
+// CreateDisplayBoard godoc
+//
+//	@Summary		Registers a display board
+//	@Description	Registers a read-only screen, such as a TV in the back of house, that shows the day's published shifts. The response carries the board's token and URLs, which are not shown again; the screen opens html_url, or polls url for JSON.
+//	@Tags			display-boards
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		CreateDisplayBoardPayload	true	"Board name"
+//	@Success		201				{object}	DisplayBoardWithToken
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/display-boards [post]
+func (app *application) createDisplayBoardHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+
+	var payload CreateDisplayBoardPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	board := &store.DisplayBoard{RestaurantID: restaurant.ID, Name: strings.TrimSpace(payload.Name), CreatedBy: &user.ID}
+	token := uuid.New().String()
+	if err := app.store.DisplayBoards.Create(r.Context(), board, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	url := fmt.Sprintf("%s/v1/display/boards/%s", app.externalBaseURL(), token)
+	response := &DisplayBoardWithToken{DisplayBoard: board, Token: token, URL: url, HTMLURL: url + "?format=html"}
+	if err := app.jsonResponse(w, r, http.StatusCreated, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetDisplayBoards godoc
+//
+//	@Summary		Lists a restaurant's display boards
+//	@Description	Lists the restaurant's display boards, revoked ones included, with when each last polled
+//	@Tags			display-boards
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.DisplayBoard
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/display-boards [get]
+func (app *application) getDisplayBoardsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	boards, err := app.store.DisplayBoards.List(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, boards); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RevokeDisplayBoard godoc
+//
+//	@Summary		Revokes a display board
+//	@Description	Stops the board's token from working; the screen shows nothing more
+//	@Tags			display-boards
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			boardID			path		int	true	"Display board ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/display-boards/{boardID} [delete]
+func (app *application) revokeDisplayBoardHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	boardID, err := strconv.ParseInt(chi.URLParam(r, "boardID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.DisplayBoards.Revoke(r.Context(), restaurant.ID, boardID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDisplayBoard godoc
+//
+//	@Summary		Shows a display board
+//	@Description	Shows the day's shifts from published schedules, with names, roles and times, for a screen in the back of house. It needs no account: the token in the URL is the access, and it stops working once the board is revoked or the restaurant is archived. The day is today in tz, an IANA timezone such as America/Chicago, or the restaurant's timezone without one. Answers carry an ETag; poll every refresh_seconds with If-None-Match and a 304 means nothing changed. format=html serves a page that reloads itself.
+//	@Tags			display-boards
+//	@Produce		json,html
+//	@Param			token	path		string	true	"Display board token"
+//	@Param			tz		query		string	false	"IANA timezone the day is taken in; defaults to the restaurant's"
+//	@Param			format	query		string	false	"html for a page instead of JSON"
+//	@Success		200		{object}	BoardView
+//	@Success		304		{string}	string	"Not modified"
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/display/boards/{token} [get]
+func (app *application) getDisplayBoardHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var location *time.Location
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("unknown timezone %q", tz))
+			return
+		}
+		location = loc
+	}
+
+	board, err := app.store.DisplayBoards.Authenticate(ctx, chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("display board not found or revoked"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	restaurant, err := app.store.Restaurants.GetByID(ctx, board.RestaurantID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if location == nil {
+		location = restaurant.Location()
+	}
+	today := time.Now().In(location)
+	date := store.DateOnly(today.Format("2006-01-02"))
+	shifts, err := app.store.DisplayBoards.ListShifts(ctx, restaurant.ID, date)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	view := &BoardView{
+		RestaurantName: restaurant.Name,
+		BoardName:      board.Name,
+		Date:           date,
+		RefreshSeconds: displayBoardRefreshSeconds,
+		Shifts:         make([]BoardShift, 0, len(shifts)),
+	}
+	for _, s := range shifts {
+		view.Shifts = append(view.Shifts, BoardShift{
+			StartTime:    s.StartTime,
+			EndTime:      s.EndTime,
+			RoleName:     s.RoleName,
+			RoleColor:    s.RoleColor,
+			EmployeeName: s.EmployeeName,
+			Training:     s.Training,
+		})
+	}
+
+	// Render first so the ETag is of exactly what would be sent, and a
+	// failure can still be reported as JSON
+	html := r.URL.Query().Get("format") == "html"
+	var body []byte
+	if html {
+		var buf bytes.Buffer
+		if err := displayBoardTemplate.Execute(&buf, displayBoardPage(view, today, requestLocale(r))); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		body = buf.Bytes()
+	} else if body, err = json.Marshal(view); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"board-` + hex.EncodeToString(sum[:8]) + `"`
+
+	// The token is in the URL: keep the board out of shared caches, search
+	// results and Referer headers, but let the screen revalidate what it has
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !html {
+		if err := app.jsonResponse(w, r, http.StatusOK, view); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+type displayBoardRow struct {
+	Time      string
+	RoleName  string
+	RoleColor string
+	Employee  string
+	Training  bool
+}
+
+type displayBoardPageData struct {
+	RestaurantName string
+	Day            string
+	RefreshSeconds int
+	Shifts         []displayBoardRow
+}
+
+func displayBoardPage(view *BoardView, day time.Time, locale i18n.Locale) displayBoardPageData {
+	page := displayBoardPageData{
+		RestaurantName: view.RestaurantName,
+		Day:            i18n.FormatWeekday(locale, day),
+		RefreshSeconds: view.RefreshSeconds,
+	}
+	for _, s := range view.Shifts {
+		row := displayBoardRow{
+			Time:      i18n.FormatTimeOfDay(locale, string(s.StartTime)) + " – " + i18n.FormatTimeOfDay(locale, string(s.EndTime)),
+			RoleName:  s.RoleName,
+			RoleColor: s.RoleColor,
+			Training:  s.Training,
+		}
+		if s.EmployeeName != nil {
+			row.Employee = *s.EmployeeName
+		}
+		page.Shifts = append(page.Shifts, row)
+	}
+	return page
+}
+
+var displayBoardTemplate = template.Must(template.New("display_board").Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width" />
+    <meta name="robots" content="noindex" />
+    <meta http-equiv="refresh" content="{{.RefreshSeconds}}" />
+    <title>{{.RestaurantName}} · {{.Day}}</title>
+    <style>
+      body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background: #111; color: #eee; margin: 0; padding: 32px; font-size: 28px; }
+      h1 { margin: 0; font-size: 48px; }
+      .day { color: #aaa; margin-top: 4px; }
+      table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+      td { padding: 12px 16px; border-bottom: 1px solid #333; }
+      .role { border-left: 8px solid #555; }
+      .open { color: #888; font-style: italic; }
+    </style>
+  </head>
+  <body>
+    <h1>{{.RestaurantName}}</h1>
+    <p class="day">{{.Day}}</p>
+    {{if .Shifts}}
+    <table>
+      {{range .Shifts}}
+      <tr>
+        <td>{{.Time}}</td>
+        <td class="role" style="border-left-color: {{.RoleColor}}">{{.RoleName}}{{if .Training}} (training){{end}}</td>
+        <td>{{if .Employee}}{{.Employee}}{{else}}<span class="open">Open</span>{{end}}</td>
+      </tr>
+      {{end}}
+    </table>
+    {{else}}
+    <p class="open">No shifts today</p>
+    {{end}}
+  </body>
+</html>
+`))
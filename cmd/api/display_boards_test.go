@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCreateDisplayBoard(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.config.apiURL = "api.example.com"
+	var saved *store.DisplayBoard
+	var savedToken string
+	app.store.DisplayBoards = &store.MockDisplayBoardStorer{
+		CreateFunc: func(_ context.Context, board *store.DisplayBoard, token string) error {
+			saved, savedToken = board, token
+			board.ID = 4
+			return nil
+		},
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/display-boards", `{"name": " Kitchen TV "}`), app.mount())
+
+	checkResponseCode(t, http.StatusCreated, rr.Code)
+	var body struct {
+		Data DisplayBoardWithToken `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.Token == "" || body.Data.Token != savedToken {
+		t.Errorf("token = %q, want the stored %q", body.Data.Token, savedToken)
+	}
+	if want := "http://api.example.com/v1/display/boards/" + savedToken + "?format=html"; body.Data.HTMLURL != want {
+		t.Errorf("html_url = %q, want %q", body.Data.HTMLURL, want)
+	}
+	if saved.RestaurantID != 1 || saved.Name != "Kitchen TV" || saved.CreatedBy == nil || *saved.CreatedBy != testUserID {
+		t.Errorf("board = %+v", saved)
+	}
+}
+
+func TestGetDisplayBoard(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		return &store.Restaurant{ID: id, Name: "Blue Door"}, nil
+	}
+	var asked store.DateOnly
+	app.store.DisplayBoards = &store.MockDisplayBoardStorer{
+		AuthenticateFunc: func(_ context.Context, token string) (*store.DisplayBoard, error) {
+			if token != "board-token" {
+				return nil, store.ErrNotFound
+			}
+			return &store.DisplayBoard{ID: 4, RestaurantID: 3, Name: "Kitchen TV"}, nil
+		},
+		ListShiftsFunc: func(_ context.Context, restaurantID int64, date store.DateOnly) ([]*store.BoardShift, error) {
+			asked = date
+			name := "<b>Ana</b>"
+			return []*store.BoardShift{
+				{StartTime: "09:00:00", EndTime: "17:00:00", RoleName: "Server", RoleColor: "#ff0000", EmployeeName: &name},
+				{StartTime: "11:00:00", EndTime: "19:00:00", RoleName: "Cook", RoleColor: "#00ff00"},
+			}, nil
+		},
+	}
+
+	t.Run("serves today's shifts with an ETag to poll against", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/display/boards/board-token?tz=Pacific/Kiritimati", nil), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data BoardView `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+		if want := store.DateOnly(time.Now().In(kiritimati).Format("2006-01-02")); asked != want || body.Data.Date != want {
+			t.Errorf("date = %q (asked %q), want %q", body.Data.Date, asked, want)
+		}
+		if body.Data.RestaurantName != "Blue Door" || len(body.Data.Shifts) != 2 || body.Data.Shifts[1].EmployeeName != nil {
+			t.Errorf("board = %+v", body.Data)
+		}
+
+		etag := rr.Header().Get("ETag")
+		if etag == "" {
+			t.Fatal("no ETag")
+		}
+		req := httptest.NewRequest(http.MethodGet, "/v1/display/boards/board-token?tz=Pacific/Kiritimati", nil)
+		req.Header.Set("If-None-Match", etag)
+		rr = executeRequest(req, app.mount())
+		checkResponseCode(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("serves an escaped page that reloads itself", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/display/boards/board-token?format=html", nil), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		page := rr.Body.String()
+		for _, want := range []string{`http-equiv="refresh"`, "Blue Door", "&lt;b&gt;Ana&lt;/b&gt;", "Open"} {
+			if !strings.Contains(page, want) {
+				t.Errorf("page is missing %q", want)
+			}
+		}
+		if strings.Contains(page, "<b>Ana</b>") {
+			t.Error("page has the employee name unescaped")
+		}
+	})
+
+	t.Run("without tz the day is the restaurant's", func(t *testing.T) {
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, Name: "Blue Door", Timezone: "Pacific/Pago_Pago"}, nil
+		}
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/display/boards/board-token", nil), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		pagoPago, _ := time.LoadLocation("Pacific/Pago_Pago")
+		if want := store.DateOnly(time.Now().In(pagoPago).Format("2006-01-02")); asked != want {
+			t.Errorf("asked for %q, want %q", asked, want)
+		}
+	})
+
+	t.Run("an unknown timezone is refused", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/display/boards/board-token?tz=Mars/Olympus", nil), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("a revoked board is not found", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/display/boards/other-token", nil), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// documentAcknowledgmentDays is how long an emailed acknowledgment link works
+const documentAcknowledgmentDays = 30
+
+var errEmployeeDocument = errors.New("only restaurant-wide documents can be sent for acknowledgment")
+
+type RequestDocumentAcknowledgmentsPayload struct {
+	// EmployeeIDs defaults to every employee of the restaurant
+	EmployeeIDs []int64 `json:"employee_ids" validate:"omitempty,dive,min=1"`
+}
+
+type RequestDocumentAcknowledgmentsResponse struct {
+	SendScheduleEmailResponse
+	// AlreadyAcknowledged counts the employees skipped because they had signed
+	AlreadyAcknowledged int `json:"already_acknowledged"`
+}
+
+// DocumentAcknowledgmentReport is who has acknowledged a document and who hasn't yet
+type DocumentAcknowledgmentReport struct {
+	DocumentID             int64                           `json:"document_id"`
+	Requested              int                             `json:"requested"`
+	Acknowledged           int                             `json:"acknowledged"`
+	OutstandingEmployeeIDs []int64                         `json:"outstanding_employee_ids"`
+	Acknowledgments        []*store.DocumentAcknowledgment `json:"acknowledgments"`
+}
+
+// DocumentToAcknowledgeView is the document behind an acknowledgment link, with a
+// short-lived URL to read it
+type DocumentToAcknowledgeView struct {
+	*store.DocumentToAcknowledge
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+type AcknowledgeDocumentPayload struct {
+	// SignedName is the employee's name as they typed it to sign
+	SignedName string `json:"signed_name" validate:"required,max=255"`
+}
+
+// DocumentAcknowledgmentEmailData contains the data for the acknowledgment request email template
+type DocumentAcknowledgmentEmailData struct {
+	RestaurantName string
+	EmployeeName   string
+	DocumentName   string
+	AcknowledgeURL string
+	ExpiresOn      string
+}
+
+// RequestDocumentAcknowledgments godoc
+//
+//	@Summary		Asks employees to acknowledge a document
+//	@Description	Emails each employee (all of them unless employee_ids is given) a personal link to read the restaurant-wide document and sign that they have, and posts them a notification. The link works for 30 days; asking again sends a new one. Employees who already signed are skipped.
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int										true	"Restaurant ID"
+//	@Param			documentID		path		int										true	"Document ID"
+//	@Param			payload			body		RequestDocumentAcknowledgmentsPayload	false	"Employees to ask"
+//	@Success		200				{object}	RequestDocumentAcknowledgmentsResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents/{documentID}/acknowledgments [post]
+func (app *application) requestDocumentAcknowledgmentsHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.restaurantDocumentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if doc.EmployeeID != nil {
+		app.badRequestResponse(w, r, errEmployeeDocument)
+		return
+	}
+
+	var payload RequestDocumentAcknowledgmentsPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	restaurant := getRestaurantFromContext(r)
+
+	var employees []*store.Employee
+	var err error
+	if len(payload.EmployeeIDs) == 0 {
+		employees, err = app.store.Employees.ListByRestaurant(ctx, restaurant.ID)
+	} else {
+		employees, err = app.store.Employees.GetByIDs(ctx, payload.EmployeeIDs)
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	for _, employee := range employees {
+		if employee.RestaurantID != restaurant.ID {
+			app.notFoundResponse(w, r, fmt.Errorf("employee %d not found in this restaurant", employee.ID))
+			return
+		}
+	}
+
+	response := RequestDocumentAcknowledgmentsResponse{
+		SendScheduleEmailResponse: SendScheduleEmailResponse{
+			TotalRecipients: len(employees),
+			Failures:        []SendScheduleEmailFailure{},
+		},
+	}
+
+	if len(employees) > 0 {
+		quota, ok := app.takeEmailQuota(w, r, restaurant.ID)
+		if !ok {
+			return
+		}
+		response.Quota = quota
+	}
+
+	user := getUserFromContext(r)
+	isProdEnv := app.config.env == "production"
+	expiresAt := time.Now().AddDate(0, 0, documentAcknowledgmentDays).UTC()
+	var notifications []*store.Notification
+
+	fail := func(employee *store.Employee, err error) {
+		response.Failed++
+		response.Failures = append(response.Failures, SendScheduleEmailFailure{
+			EmployeeID:   employee.ID,
+			EmployeeName: employee.FullName,
+			Email:        employee.Email,
+			Error:        err.Error(),
+		})
+	}
+
+	for _, employee := range employees {
+		if employee.Email == "" {
+			fail(employee, errors.New("no email address"))
+			continue
+		}
+		if employee.EmailBouncedAt != nil {
+			fail(employee, errEmailBounced)
+			continue
+		}
+
+		ack := &store.DocumentAcknowledgment{
+			DocumentID:   doc.ID,
+			RestaurantID: restaurant.ID,
+			EmployeeID:   employee.ID,
+			ExpiresAt:    expiresAt,
+		}
+		token := uuid.New().String()
+		if err := app.store.DocumentAcks.Request(ctx, ack, token); err != nil {
+			if errors.Is(err, store.ErrAlreadyAcknowledged) {
+				response.TotalRecipients--
+				response.AlreadyAcknowledged++
+				continue
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		locale := i18n.Resolve(employee.Locale, user.Locale)
+		emailData := &DocumentAcknowledgmentEmailData{
+			RestaurantName: mailer.PlainText(restaurant.Name),
+			EmployeeName:   mailer.PlainText(employee.FullName),
+			DocumentName:   mailer.PlainText(doc.Name),
+			AcknowledgeURL: fmt.Sprintf("%s/acknowledge/%s", app.config.frontendURL, token),
+			ExpiresOn:      i18n.FormatDate(locale, expiresAt.In(restaurant.Location())),
+		}
+		if _, err := app.mailer.Send(mailer.Localized(mailer.DocumentAcknowledgmentTemplate, locale), employee.FullName, employee.Email, emailData, !isProdEnv); err != nil {
+			app.logger.Warnw("failed to send document acknowledgment request",
+				"employee_id", employee.ID,
+				"document_id", doc.ID,
+				"error", err,
+			)
+			fail(employee, err)
+			continue
+		}
+
+		data, _ := json.Marshal(map[string]any{"document_id": doc.ID, "acknowledgment_id": ack.ID})
+		notifications = append(notifications, &store.Notification{
+			RestaurantID: restaurant.ID,
+			EmployeeID:   &employee.ID,
+			Type:         store.NotificationDocumentAck,
+			Title:        fmt.Sprintf("Please acknowledge %s", doc.Name),
+			Body:         "Check your email for the link to read and sign it",
+			Data:         data,
+		})
+		response.Successful++
+	}
+
+	app.notify(ctx, notifications)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetDocumentAcknowledgments godoc
+//
+//	@Summary		Reports who has acknowledged a document
+//	@Description	Lists every acknowledgment requested for the document with when, and under what typed name, each employee signed; outstanding_employee_ids are those who haven't yet
+//	@Tags			document
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			documentID		path		int	true	"Document ID"
+//	@Success		200				{object}	DocumentAcknowledgmentReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents/{documentID}/acknowledgments [get]
+func (app *application) getDocumentAcknowledgmentsHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.restaurantDocumentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	acks, err := app.store.DocumentAcks.ListByDocument(r.Context(), doc.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	report := &DocumentAcknowledgmentReport{
+		DocumentID:             doc.ID,
+		Requested:              len(acks),
+		OutstandingEmployeeIDs: []int64{},
+		Acknowledgments:        acks,
+	}
+	for _, ack := range acks {
+		if ack.AcknowledgedAt != nil {
+			report.Acknowledged++
+			continue
+		}
+		report.OutstandingEmployeeIDs = append(report.OutstandingEmployeeIDs, ack.EmployeeID)
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetDocumentToAcknowledge godoc
+//
+//	@Summary		Shows a document to acknowledge
+//	@Description	Shows the document behind an emailed acknowledgment link, with a short-lived download URL and, once signed, the signature. It needs no account: the token in the URL is the access. An unsigned link stops working when it expires or the restaurant is archived.
+//	@Tags			document
+//	@Produce		json
+//	@Param			token	path		string	true	"Acknowledgment token"
+//	@Success		200		{object}	DocumentToAcknowledgeView
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/document-acknowledgments/{token} [get]
+func (app *application) getDocumentToAcknowledgeHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.documentToAcknowledgeFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	view := &DocumentToAcknowledgeView{DocumentToAcknowledge: doc}
+	if app.blobs != nil {
+		url, err := app.blobs.PresignGet(r.Context(), doc.ObjectKey, app.config.uploads.urlExpiry, doc.DocumentName)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		view.DownloadURL = url
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, view); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AcknowledgeDocument godoc
+//
+//	@Summary		Signs a document acknowledgment
+//	@Description	Records that the employee holding the link has read the document, under the name they typed and with the device they used. A document can only be signed once.
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			token	path		string						true	"Acknowledgment token"
+//	@Param			payload	body		AcknowledgeDocumentPayload	true	"Signature"
+//	@Success		200		{object}	store.DocumentAcknowledgment
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/document-acknowledgments/{token} [post]
+func (app *application) acknowledgeDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.documentToAcknowledgeFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var payload AcknowledgeDocumentPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	userAgent, ip := clientDevice(r)
+	ack := doc.DocumentAcknowledgment
+	if err := app.store.DocumentAcks.Acknowledge(r.Context(), ack, payload.SignedName, ip, userAgent); err != nil {
+		if errors.Is(err, store.ErrAlreadyAcknowledged) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, ack); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// documentToAcknowledgeFromURL loads the acknowledgment request behind the {token} link
+func (app *application) documentToAcknowledgeFromURL(w http.ResponseWriter, r *http.Request) (*store.DocumentToAcknowledge, bool) {
+	doc, err := app.store.DocumentAcks.Authenticate(r.Context(), chi.URLParam(r, "token"), time.Now())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("acknowledgment link not found or expired"))
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	// The token is in the URL: keep the page out of caches and Referer headers
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	return doc, true
+}
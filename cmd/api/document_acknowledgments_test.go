@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/storage"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestAcknowledgeDocument(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	var signedAs string
+	app.store.DocumentAcks = &store.MockDocumentAcknowledgmentStorer{
+		AuthenticateFunc: func(_ context.Context, token string, _ time.Time) (*store.DocumentToAcknowledge, error) {
+			if token != "good" && token != "signed" {
+				return nil, store.ErrNotFound
+			}
+			return &store.DocumentToAcknowledge{
+				DocumentAcknowledgment: &store.DocumentAcknowledgment{ID: 7, EmployeeID: 3},
+				DocumentName:           "Handbook.pdf",
+				RestaurantName:         "Bistro",
+			}, nil
+		},
+		AcknowledgeFunc: func(_ context.Context, ack *store.DocumentAcknowledgment, signedName, _, _ string) error {
+			if ack.ID != 7 {
+				t.Errorf("acknowledged request %d, want 7", ack.ID)
+			}
+			if signedAs != "" {
+				return store.ErrAlreadyAcknowledged
+			}
+			signedAs = signedName
+			now := time.Now()
+			ack.AcknowledgedAt, ack.SignedName = &now, &signedName
+			return nil
+		},
+	}
+	sign := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/document-acknowledgments/"+token, strings.NewReader(body))
+		return executeRequest(req, app.mount())
+	}
+
+	t.Run("shows the document without an account", func(t *testing.T) {
+		rr := executeRequest(httptest.NewRequest(http.MethodGet, "/v1/document-acknowledgments/good", nil), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !strings.Contains(rr.Body.String(), "Handbook.pdf") {
+			t.Errorf("body = %s, want the document name", rr.Body.String())
+		}
+		if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("Cache-Control = %q, want no-store", got)
+		}
+	})
+
+	t.Run("an unknown or expired link is not found", func(t *testing.T) {
+		checkResponseCode(t, http.StatusNotFound, sign("bad", `{"signed_name":"Ana Diaz"}`).Code)
+	})
+
+	t.Run("requires a signature", func(t *testing.T) {
+		checkResponseCode(t, http.StatusBadRequest, sign("good", `{"signed_name":""}`).Code)
+	})
+
+	t.Run("signs once", func(t *testing.T) {
+		checkResponseCode(t, http.StatusOK, sign("good", `{"signed_name":"Ana Diaz"}`).Code)
+		if signedAs != "Ana Diaz" {
+			t.Errorf("signed as %q, want Ana Diaz", signedAs)
+		}
+		checkResponseCode(t, http.StatusConflict, sign("signed", `{"signed_name":"Someone Else"}`).Code)
+	})
+}
+
+// stubBlobs stands in for blob storage in handlers that only check it's configured
+type stubBlobs struct{ storage.Blob }
+
+func TestRequestDocumentAcknowledgments(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+	app.blobs = stubBlobs{}
+
+	var employeeID *int64
+	app.store.Documents = &store.MockDocumentStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Document, error) {
+			return &store.Document{ID: id, RestaurantID: 1, EmployeeID: employeeID, Name: "Handbook.pdf"}, nil
+		},
+	}
+	bounced := time.Now()
+	app.store.Employees = &store.MockEmployeeStorer{
+		ListByRestaurantFunc: func(_ context.Context, _ int64) ([]*store.Employee, error) {
+			return []*store.Employee{
+				{ID: 1, RestaurantID: 1, FullName: "Ana", Email: "ana@example.com"},
+				{ID: 2, RestaurantID: 1, FullName: "Ben", Email: "ben@example.com", EmailBouncedAt: &bounced},
+				{ID: 3, RestaurantID: 1, FullName: "Cy", Email: "cy@example.com"},
+			}, nil
+		},
+	}
+	var requested []int64
+	app.store.DocumentAcks = &store.MockDocumentAcknowledgmentStorer{
+		RequestFunc: func(_ context.Context, ack *store.DocumentAcknowledgment, token string) error {
+			if token == "" || ack.DocumentID != 5 {
+				t.Errorf("request for document %d with token %q", ack.DocumentID, token)
+			}
+			if ack.EmployeeID == 3 {
+				return store.ErrAlreadyAcknowledged
+			}
+			requested = append(requested, ack.EmployeeID)
+			return nil
+		},
+	}
+	app.store.Notifications = &store.MockNotificationStorer{
+		CreateManyFunc: func(_ context.Context, _ []*store.Notification) error { return nil },
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/documents/5/acknowledgments", ""), app.mount())
+	checkResponseCode(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Data RequestDocumentAcknowledgmentsResponse `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	got := body.Data
+	if got.TotalRecipients != 2 || got.Successful != 1 || got.Failed != 1 || got.AlreadyAcknowledged != 1 {
+		t.Errorf("response = %+v, want Ana emailed, Ben bounced, Cy already signed", got)
+	}
+	if len(requested) != 1 || requested[0] != 1 {
+		t.Errorf("requested = %v, want only Ana", requested)
+	}
+	if len(mail.sent) != 1 || mail.sent[0] != "document_acknowledgment.go.tmpl ana@example.com" {
+		t.Errorf("sent = %v, want the request emailed to Ana", mail.sent)
+	}
+
+	t.Run("an employee's own document can't be sent", func(t *testing.T) {
+		employeeID = new(int64)
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/documents/5/acknowledgments", ""), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
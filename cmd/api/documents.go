@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/storage"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// documentContentTypes are the sniffed types accepted for documents
+var documentContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+// GetDocuments godoc
+//
+//	@Summary		Lists restaurant's documents
+//	@Description	Fetches restaurant-wide documents (handbooks, checklists) with short-lived download URLs; per-employee documents are listed under the employee
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.Document
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents [get]
+func (app *application) getDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	docs, err := app.store.Documents.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.presignDocuments(r.Context(), docs...); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, docs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UploadDocument godoc
+//
+//	@Summary		Uploads a restaurant document
+//	@Description	Uploads a restaurant-wide document as multipart/form-data. Accepts PDF, PNG, JPEG, GIF, WebP and plain text up to the configured size.
+//	@Tags			document
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			file			formData	file	true	"Document"
+//	@Param			name			formData	string	false	"Display name (defaults to the file name)"
+//	@Success		201				{object}	store.Document
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		413				{object}	error
+//	@Failure		415				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents [post]
+func (app *application) uploadDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	key := fmt.Sprintf("restaurants/%d/documents/%s", restaurant.ID, uuid.New())
+	app.storeDocument(w, r, restaurant.ID, nil, key)
+}
+
+// GetEmployeeDocuments godoc
+//
+//	@Summary		Lists employee's documents
+//	@Description	Fetches documents attached to an employee (signed forms, ...) with short-lived download URLs
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{array}		store.Document
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/documents [get]
+func (app *application) getEmployeeDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	docs, err := app.store.Documents.ListByEmployee(r.Context(), employee.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.presignDocuments(r.Context(), docs...); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, docs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UploadEmployeeDocument godoc
+//
+//	@Summary		Uploads an employee document
+//	@Description	Attaches a document to an employee as multipart/form-data. Accepts PDF, PNG, JPEG, GIF, WebP and plain text up to the configured size.
+//	@Tags			document
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			employeeID		path		int		true	"Employee ID"
+//	@Param			file			formData	file	true	"Document"
+//	@Param			name			formData	string	false	"Display name (defaults to the file name)"
+//	@Success		201				{object}	store.Document
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		413				{object}	error
+//	@Failure		415				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/documents [post]
+func (app *application) uploadEmployeeDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	key := fmt.Sprintf("restaurants/%d/employees/%d/documents/%s", restaurant.ID, employee.ID, uuid.New())
+	app.storeDocument(w, r, restaurant.ID, &employee.ID, key)
+}
+
+// GetDocument godoc
+//
+//	@Summary		Gets a document
+//	@Description	Fetches a document's metadata with a short-lived download URL
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			documentID		path		int	true	"Document ID"
+//	@Success		200				{object}	store.Document
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents/{documentID} [get]
+func (app *application) getDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.restaurantDocumentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.presignDocuments(r.Context(), doc); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, doc); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DownloadDocument godoc
+//
+//	@Summary		Downloads a document
+//	@Description	Redirects to a short-lived presigned URL for the document's file
+//	@Tags			document
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			documentID		path	int	true	"Document ID"
+//	@Success		302				"Found"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents/{documentID}/download [get]
+func (app *application) downloadDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.restaurantDocumentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.presignDocuments(r.Context(), doc); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, doc.DownloadURL, http.StatusFound)
+}
+
+// DeleteDocument godoc
+//
+//	@Summary		Deletes a document
+//	@Description	Deletes a document and its stored file
+//	@Tags			document
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			documentID		path	int	true	"Document ID"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/documents/{documentID} [delete]
+func (app *application) deleteDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := app.restaurantDocumentFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	// Remove the file first so a failure leaves the row around to retry the delete
+	if err := app.blobs.Delete(r.Context(), doc.ObjectKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Documents.Delete(r.Context(), doc.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// storeDocument uploads the request's file under key and records it
+func (app *application) storeDocument(w http.ResponseWriter, r *http.Request, restaurantID int64, employeeID *int64, key string) {
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return
+	}
+
+	upload, err := app.readUpload(w, r, documentContentTypes)
+	if err != nil {
+		app.uploadErrorResponse(w, r, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	defer upload.file.Close()
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		name = upload.name
+	}
+	if len(name) > 255 {
+		app.badRequestResponse(w, r, errors.New("name must be at most 255 characters"))
+		return
+	}
+
+	if err := app.blobs.Put(r.Context(), key, upload.file, upload.size, upload.contentType); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	doc := &store.Document{
+		RestaurantID: restaurantID,
+		EmployeeID:   employeeID,
+		Name:         name,
+		ObjectKey:    key,
+		ContentType:  upload.contentType,
+		SizeBytes:    upload.size,
+		UploadedBy:   &user.ID,
+	}
+
+	if err := app.store.Documents.Create(r.Context(), doc); err != nil {
+		if err := app.blobs.Delete(r.Context(), key); err != nil {
+			app.logger.Warnw("failed to clean up uploaded file", "key", key, "error", err)
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.presignDocuments(r.Context(), doc); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, doc); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// restaurantDocumentFromURL loads the {documentID} document after checking the caller owns the restaurant it belongs to
+func (app *application) restaurantDocumentFromURL(w http.ResponseWriter, r *http.Request) (*store.Document, bool) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return nil, false
+	}
+
+	if app.blobs == nil {
+		app.notFoundResponse(w, r, errBlobStorageDisabled)
+		return nil, false
+	}
+
+	documentID, err := strconv.ParseInt(chi.URLParam(r, "documentID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	doc, err := app.store.Documents.GetByID(r.Context(), documentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if doc.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("document not found in this restaurant"))
+		return nil, false
+	}
+
+	return doc, true
+}
+
+// restaurantEmployeeFromURL loads the {employeeID} employee and checks it belongs to the restaurant
+func (app *application) restaurantEmployeeFromURL(w http.ResponseWriter, r *http.Request, restaurantID int64) (*store.Employee, bool) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if employee.RestaurantID != restaurantID {
+		app.notFoundResponse(w, r, errors.New("employee not found in this restaurant"))
+		return nil, false
+	}
+
+	return employee, true
+}
+
+func (app *application) presignDocuments(ctx context.Context, docs ...*store.Document) error {
+	for _, doc := range docs {
+		url, err := app.blobs.PresignGet(ctx, doc.ObjectKey, app.config.uploads.urlExpiry, doc.Name)
+		if err != nil {
+			return err
+		}
+		doc.DownloadURL = url
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// maxEmailEventBytes bounds a batch from the event webhook, which SendGrid fills with up to a few thousand events
+const maxEmailEventBytes = 4 << 20
+
+var errEmailBounced = errors.New("email address bounced; update it to send again")
+
+// ScheduleEmailStatus is what became of the emails sent for a schedule
+type ScheduleEmailStatus struct {
+	ScheduleID int64 `json:"schedule_id"`
+	// Counts tallies the latest email to each employee by status
+	Counts map[string]int `json:"counts"`
+	// Employees is the latest email to each employee
+	Employees []*store.EmailDelivery `json:"employees"`
+	// History is every email sent for the schedule, newest first
+	History []*store.EmailDelivery `json:"history"`
+}
+
+// sendTrackedScheduleEmail sends one of a schedule's emails to the employee,
+// recorded so the event webhook can report whether it arrived. Addresses that
+// hard-bounced are skipped until the employee's email changes.
+func (app *application) sendTrackedScheduleEmail(ctx context.Context, schedule *store.Schedule, kind string, employee *store.Employee, templateFile string, data any) error {
+	isSandbox := app.config.env != "production"
+	delivery := &store.EmailDelivery{
+		ScheduleID:   schedule.ID,
+		RestaurantID: schedule.RestaurantID,
+		EmployeeID:   &employee.ID,
+		Email:        employee.Email,
+		Kind:         kind,
+	}
+
+	if employee.EmailBouncedAt != nil {
+		detail := errEmailBounced.Error()
+		delivery.Status = store.DeliveryFailed
+		delivery.Detail = &detail
+		if err := app.store.EmailDeliveries.Create(ctx, delivery); err != nil {
+			app.logger.Warnw("failed to record skipped email", "employee_id", employee.ID, "error", err)
+		}
+		return errEmailBounced
+	}
+
+	// An email that can't be tracked is still worth sending
+	if err := app.store.EmailDeliveries.Create(ctx, delivery); err != nil {
+		app.logger.Warnw("failed to record email delivery, sending untracked", "employee_id", employee.ID, "error", err)
+		_, err := app.mailer.Send(templateFile, employee.FullName, employee.Email, data, isSandbox)
+		return err
+	}
+
+	_, err := app.mailer.SendTracked(templateFile, employee.FullName, employee.Email, data, isSandbox, strconv.FormatInt(delivery.ID, 10))
+	if err != nil {
+		if markErr := app.store.EmailDeliveries.MarkFailed(ctx, delivery.ID, err.Error()); markErr != nil {
+			app.logger.Warnw("failed to record email failure", "delivery_id", delivery.ID, "error", markErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// deliveryEvent maps a webhook event onto a delivery status; false for events that don't change it
+func deliveryEvent(event mailer.Event) (*store.DeliveryEvent, bool) {
+	id, err := strconv.ParseInt(event.TrackingID, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+
+	e := &store.DeliveryEvent{DeliveryID: id, At: event.Time()}
+	switch event.Event {
+	case "delivered":
+		e.Status = store.DeliveryDelivered
+	case "deferred":
+		e.Status = store.DeliveryDeferred
+		e.Detail = event.Response
+	case "bounce":
+		e.Status = store.DeliveryBounced
+		e.Detail = event.Reason
+		e.HardBounce = event.HardBounce()
+	case "dropped":
+		e.Status = store.DeliveryDropped
+		e.Detail = event.Reason
+	case "spamreport":
+		e.Status = store.DeliverySpamReport
+	default:
+		return nil, false
+	}
+	return e, true
+}
+
+// EmailEventWebhook godoc
+//
+//	@Summary		Receives SendGrid delivery events
+//	@Description	SendGrid's signed event webhook. Delivered, deferred, bounce, dropped and spam report events update the schedule emails they're about; a hard bounce flags the employee's address so schedule emails skip it. Other events and untracked mail are ignored. 404 unless SENDGRID_WEBHOOK_PUBLIC_KEY is set.
+//	@Tags			email
+//	@Accept			json
+//	@Param			X-Twilio-Email-Event-Webhook-Signature	header	string	true	"ECDSA signature"
+//	@Param			X-Twilio-Email-Event-Webhook-Timestamp	header	string	true	"Signed timestamp"
+//	@Success		200										"OK"
+//	@Failure		400										{object}	error
+//	@Failure		404										{object}	error
+//	@Failure		500										{object}	error
+//	@Router			/email/events [post]
+func (app *application) emailEventWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if app.emailEvents == nil {
+		app.notFoundResponse(w, r, errors.New("email event webhook is not enabled"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxEmailEventBytes)
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	events, err := app.emailEvents.ParseEvents(payload, r.Header.Get(mailer.SignatureHeader), r.Header.Get(mailer.TimestampHeader))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	for _, event := range events {
+		e, ok := deliveryEvent(event)
+		if !ok {
+			continue
+		}
+
+		if err := app.store.EmailDeliveries.RecordEvent(r.Context(), e); err != nil {
+			// Deliveries of deleted schedules are acknowledged so SendGrid stops retrying them
+			if errors.Is(err, store.ErrNotFound) {
+				app.logger.Debugw("email event for unknown delivery", "delivery_id", e.DeliveryID, "event", event.Event)
+				continue
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetScheduleEmailStatus godoc
+//
+//	@Summary		Shows whether a schedule's emails arrived
+//	@Description	Lists every schedule, change and reminder email sent for the schedule with its delivery status (sent, failed, delivered, deferred, bounced, dropped or spam_report) as reported by SendGrid, and the latest email to each employee with totals by status
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{object}	ScheduleEmailStatus
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/email-status [get]
+func (app *application) getScheduleEmailStatusHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := app.store.EmailDeliveries.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, scheduleEmailStatus(schedule.ID, history)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetScheduleEmailBlasts godoc
+//
+//	@Summary		Lists a schedule's email runs
+//	@Description	Every send-email run on the schedule, newest first, with how many recipients it reached and the ones it failed to reach and why. A retry of failed recipients names the run it retried in retry_of.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{array}		store.EmailBlast
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/email-blasts [get]
+func (app *application) getScheduleEmailBlastsHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	blasts, err := app.store.EmailDeliveries.ListBlasts(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, blasts); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// scheduleEmailStatus picks the latest email to each employee out of the newest-first history
+func scheduleEmailStatus(scheduleID int64, history []*store.EmailDelivery) *ScheduleEmailStatus {
+	status := &ScheduleEmailStatus{
+		ScheduleID: scheduleID,
+		Counts:     map[string]int{},
+		Employees:  []*store.EmailDelivery{},
+		History:    history,
+	}
+
+	seen := make(map[int64]bool)
+	for _, delivery := range history {
+		// Erased employees' deliveries are deleted; ones whose employee was removed only count in the history
+		if delivery.EmployeeID == nil || seen[*delivery.EmployeeID] {
+			continue
+		}
+		seen[*delivery.EmployeeID] = true
+		status.Employees = append(status.Employees, delivery)
+		status.Counts[delivery.Status]++
+	}
+
+	return status
+}
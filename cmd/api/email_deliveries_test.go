@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestEmailEventWebhook(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setup := func(t *testing.T) (*application, *[]*store.DeliveryEvent) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.emailEvents, err = mailer.NewEventVerifier(base64.StdEncoding.EncodeToString(publicKey))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorded := &[]*store.DeliveryEvent{}
+		app.store.EmailDeliveries = &store.MockEmailDeliveryStorer{
+			RecordEventFunc: func(_ context.Context, event *store.DeliveryEvent) error {
+				if event.DeliveryID == 404 {
+					return store.ErrNotFound
+				}
+				*recorded = append(*recorded, event)
+				return nil
+			},
+		}
+		return app, recorded
+	}
+
+	signed := func(t *testing.T, payload string) *http.Request {
+		timestamp := "1760000000"
+		digest := sha256.Sum256([]byte(timestamp + payload))
+		signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "/v1/email/events", strings.NewReader(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(mailer.SignatureHeader, base64.StdEncoding.EncodeToString(signature))
+		req.Header.Set(mailer.TimestampHeader, timestamp)
+		return req
+	}
+
+	events := func(t *testing.T, events ...map[string]any) string {
+		payload, err := json.Marshal(events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(payload)
+	}
+
+	t.Run("records delivery events", func(t *testing.T) {
+		app, recorded := setup(t)
+
+		payload := events(t,
+			map[string]any{"resa_delivery_id": "7", "event": "delivered", "timestamp": 1760000000},
+			map[string]any{"resa_delivery_id": "8", "event": "bounce", "type": "bounce", "reason": "550 no such user", "timestamp": 1760000001},
+			map[string]any{"resa_delivery_id": "9", "event": "bounce", "type": "blocked", "reason": "spam filter", "timestamp": 1760000002},
+			map[string]any{"resa_delivery_id": "7", "event": "open", "timestamp": 1760000003},
+			map[string]any{"event": "delivered", "timestamp": 1760000004},
+			map[string]any{"resa_delivery_id": "404", "event": "delivered", "timestamp": 1760000005},
+		)
+		rr := executeRequest(signed(t, payload), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*recorded) != 3 {
+			t.Fatalf("recorded %d events, want 3", len(*recorded))
+		}
+		if e := (*recorded)[0]; e.DeliveryID != 7 || e.Status != store.DeliveryDelivered || !e.At.Equal(time.Unix(1760000000, 0)) {
+			t.Errorf("delivered event = %+v", e)
+		}
+		if e := (*recorded)[1]; e.Status != store.DeliveryBounced || !e.HardBounce || e.Detail != "550 no such user" {
+			t.Errorf("bounce event = %+v", e)
+		}
+		if e := (*recorded)[2]; e.Status != store.DeliveryBounced || e.HardBounce {
+			t.Errorf("blocked event = %+v, want a soft bounce", e)
+		}
+	})
+
+	t.Run("bad signature", func(t *testing.T) {
+		app, recorded := setup(t)
+
+		req := signed(t, events(t, map[string]any{"resa_delivery_id": "7", "event": "delivered"}))
+		req.Header.Set(mailer.TimestampHeader, "1760000001")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if len(*recorded) != 0 {
+			t.Error("events from an unsigned batch were recorded")
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		app, _ := setup(t)
+		app.emailEvents = nil
+
+		rr := executeRequest(signed(t, "[]"), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestSendTrackedScheduleEmail(t *testing.T) {
+	schedule := &store.Schedule{ID: 4, RestaurantID: 3}
+
+	t.Run("bounced address is skipped", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+		var created *store.EmailDelivery
+		app.store.EmailDeliveries = &store.MockEmailDeliveryStorer{
+			CreateFunc: func(_ context.Context, delivery *store.EmailDelivery) error {
+				created = delivery
+				return nil
+			},
+		}
+		bouncedAt := time.Now()
+		employee := &store.Employee{ID: 12, Email: "gone@example.com", EmailBouncedAt: &bouncedAt}
+
+		err := app.sendTrackedScheduleEmail(context.Background(), schedule, store.EmailKindSchedule, employee, mailer.ScheduleNotificationTemplate, nil)
+
+		if err != errEmailBounced {
+			t.Errorf("err = %v, want errEmailBounced", err)
+		}
+		if created == nil || created.Status != store.DeliveryFailed {
+			t.Errorf("delivery = %+v, want a failed delivery", created)
+		}
+	})
+}
+
+func TestScheduleEmailStatus(t *testing.T) {
+	employee := func(id int64) *int64 { return &id }
+	history := []*store.EmailDelivery{
+		{ID: 5, EmployeeID: employee(1), Status: store.DeliveryBounced},
+		{ID: 4, EmployeeID: employee(2), Status: store.DeliveryDelivered},
+		{ID: 3, EmployeeID: employee(1), Status: store.DeliveryDelivered},
+		{ID: 2, EmployeeID: nil, Status: store.DeliveryDelivered},
+	}
+
+	status := scheduleEmailStatus(4, history)
+
+	if len(status.Employees) != 2 || status.Employees[0].ID != 5 || status.Employees[1].ID != 4 {
+		t.Errorf("employees = %+v, want the latest email to each", status.Employees)
+	}
+	if status.Counts[store.DeliveryBounced] != 1 || status.Counts[store.DeliveryDelivered] != 1 {
+		t.Errorf("counts = %v", status.Counts)
+	}
+	if len(status.History) != 4 {
+		t.Errorf("history has %d emails, want 4", len(status.History))
+	}
+}
+
+func TestRetryScheduleEmailFailures(t *testing.T) {
+	setup := func(t *testing.T, last *store.EmailBlast) (*application, *fakeMailer, *[]*store.EmailBlast) {
+		app, _ := newMockedApplication(t, testUserID)
+		mail := &fakeMailer{}
+		app.mailer = mail
+		mockScheduleEmailStores(app, nil)
+
+		recorded := &[]*store.EmailBlast{}
+		app.store.EmailDeliveries = &store.MockEmailDeliveryStorer{
+			LatestBlastFunc: func(context.Context, int64) (*store.EmailBlast, error) {
+				if last == nil {
+					return nil, store.ErrNotFound
+				}
+				return last, nil
+			},
+			CreateFunc: func(_ context.Context, delivery *store.EmailDelivery) error {
+				delivery.ID = 1
+				return nil
+			},
+			CreateBlastFunc: func(_ context.Context, blast *store.EmailBlast) error {
+				blast.ID = 10
+				*recorded = append(*recorded, blast)
+				return nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDsFunc: func(_ context.Context, ids []int64) ([]*store.Employee, error) {
+				return []*store.Employee{
+					{ID: 2, RestaurantID: 1, FullName: "Ben Lee", Email: "ben@example.com"},
+					{ID: 3, RestaurantID: 1, FullName: "Cy Park"},
+				}, nil
+			},
+		}
+		return app, mail, recorded
+	}
+
+	t.Run("re-sends to the failed recipients only", func(t *testing.T) {
+		app, mail, recorded := setup(t, &store.EmailBlast{ID: 9, ScheduleID: 5, IncludeEvents: false, Failures: []*store.EmailBlastFailure{
+			{EmployeeID: 2, Email: "ben@example.com", Error: "timeout"},
+			{EmployeeID: 3, Error: "no email address"},
+		}})
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/send-email/retry-failures", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data SendScheduleEmailResponse `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Data.TotalRecipients != 2 || response.Data.Successful != 1 || response.Data.BlastID != 10 {
+			t.Errorf("response = %+v, want Ben reached and Cy failed again", response.Data)
+		}
+		if len(mail.sent) != 1 || mail.sent[0] != "schedule_notification.go.tmpl ben@example.com" {
+			t.Errorf("sent = %v, want Ben's email only", mail.sent)
+		}
+		if len(*recorded) != 1 || (*recorded)[0].RetryOf == nil || *(*recorded)[0].RetryOf != 9 || len((*recorded)[0].Failures) != 1 || (*recorded)[0].Failures[0].EmployeeID != 3 {
+			t.Errorf("recorded %+v, want a retry of blast 9 that failed Cy", *recorded)
+		}
+	})
+
+	t.Run("nothing to retry", func(t *testing.T) {
+		app, mail, _ := setup(t, &store.EmailBlast{ID: 9, ScheduleID: 5, Failures: []*store.EmailBlastFailure{}})
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/send-email/retry-failures", ""), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if len(mail.sent) != 0 {
+			t.Errorf("sent = %v, want nothing", mail.sent)
+		}
+	})
+
+	t.Run("never sent", func(t *testing.T) {
+		app, _, _ := setup(t, nil)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/send-email/retry-failures", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store/cache"
+)
+
+// takeEmailQuota counts an email blast against the restaurant's quota. It writes
+// a 429 and returns false when the quota is used up; the quota is nil when it's off.
+// A broken quota store doesn't stop emails, it only loses the count
+func (app *application) takeEmailQuota(w http.ResponseWriter, r *http.Request, restaurantID int64) (*cache.EmailQuota, bool) {
+	cfg := app.settings().emailQuota
+	if cfg.limit <= 0 || app.cacheStorage.EmailQuota == nil {
+		return nil, true
+	}
+
+	quota, ok, err := app.cacheStorage.EmailQuota.Take(r.Context(), restaurantID, cfg.limit, cfg.window)
+	if err != nil {
+		app.logger.Warnw("failed to check email quota, sending anyway", "restaurant_id", restaurantID, "error", err)
+		return nil, true
+	}
+
+	if !ok {
+		app.emailQuotaExceededResponse(w, r, time.Until(quota.ResetsAt))
+		return nil, false
+	}
+
+	return quota, true
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store/cache"
+)
+
+func TestTakeEmailQuota(t *testing.T) {
+	app := newTestApplication(t)
+	app.cacheStorage.EmailQuota = cache.NewMemoryEmailQuotaStore()
+	app.runtime.Store(&runtimeSettings{emailQuota: emailQuotaConfig{limit: 2, window: time.Hour}})
+
+	for i := 1; i <= 2; i++ {
+		rr := httptest.NewRecorder()
+		quota, ok := app.takeEmailQuota(rr, httptest.NewRequest(http.MethodPost, "/", nil), 1)
+		if !ok {
+			t.Fatalf("send %d was refused", i)
+		}
+		if quota.Used != i || quota.Remaining != 2-i {
+			t.Errorf("send %d: used %d, remaining %d", i, quota.Used, quota.Remaining)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	if _, ok := app.takeEmailQuota(rr, httptest.NewRequest(http.MethodPost, "/", nil), 1); ok {
+		t.Fatal("expected the third send to be refused")
+	}
+	checkResponseCode(t, http.StatusTooManyRequests, rr.Code)
+
+	retryAfter, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+	if err != nil || retryAfter <= 0 || retryAfter > 3600 {
+		t.Errorf("unexpected Retry-After %q", rr.Header().Get("Retry-After"))
+	}
+
+	// Other restaurants have their own quota
+	if _, ok := app.takeEmailQuota(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), 2); !ok {
+		t.Error("another restaurant's send was refused")
+	}
+}
+
+func TestTakeEmailQuotaDisabled(t *testing.T) {
+	app := newTestApplication(t)
+	app.cacheStorage.EmailQuota = cache.NewMemoryEmailQuotaStore()
+
+	quota, ok := app.takeEmailQuota(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil), 1)
+	if !ok || quota != nil {
+		t.Errorf("expected no quota when the limit is 0, got %v, %v", quota, ok)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// EmailTemplatePayload customizes the restaurant's schedule emails. Subject and header_message may use
+// {{.RestaurantName}}, {{.EmployeeName}}, {{.ScheduleStart}} and {{.ScheduleEnd}}; empty fields use the defaults.
+type EmailTemplatePayload struct {
+	Subject       string `json:"subject" validate:"max=200"`
+	HeaderMessage string `json:"header_message" validate:"max=2000"`
+	LogoURL       string `json:"logo_url" validate:"omitempty,url,startswith=https://,max=2048"`
+	AccentColor   string `json:"accent_color" validate:"omitempty,hexcolor,len=7"`
+}
+
+type EmailTemplatePreviewResponse struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+}
+
+// GetEmailTemplate godoc
+//
+//	@Summary		Gets restaurant's email customization
+//	@Description	Fetches the subject, header message, logo and accent color used for the restaurant's schedule emails; empty fields use the defaults
+//	@Tags			email-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.EmailTemplate
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/email-templates [get]
+func (app *application) getEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	tmpl, err := app.store.EmailTemplates.GetByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			app.internalServerError(w, r, err)
+			return
+		}
+		tmpl = &store.EmailTemplate{RestaurantID: restaurant.ID}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, tmpl); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateEmailTemplate godoc
+//
+//	@Summary		Customizes restaurant's emails
+//	@Description	Saves the subject, header message, logo and accent color used for the restaurant's schedule emails
+//	@Tags			email-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			payload			body		EmailTemplatePayload	true	"Email customization"
+//	@Success		200				{object}	store.EmailTemplate
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/email-templates [put]
+func (app *application) updateEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var payload EmailTemplatePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := validateEmailTemplatePayload(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	tmpl := &store.EmailTemplate{
+		RestaurantID:  restaurant.ID,
+		Subject:       payload.Subject,
+		HeaderMessage: payload.HeaderMessage,
+		LogoURL:       payload.LogoURL,
+		AccentColor:   payload.AccentColor,
+	}
+
+	if err := app.store.EmailTemplates.Upsert(r.Context(), tmpl); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, tmpl); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteEmailTemplate godoc
+//
+//	@Summary		Resets restaurant's email customization
+//	@Description	Removes the restaurant's email customization so the default schedule email is used
+//	@Tags			email-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Success		204				"No Content"
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/email-templates [delete]
+func (app *application) deleteEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	if err := app.store.EmailTemplates.Delete(r.Context(), restaurant.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PreviewEmailTemplate godoc
+//
+//	@Summary		Previews restaurant's schedule email
+//	@Description	Renders a sample schedule email with the given customization, or the saved one when the body is empty, without sending anything
+//	@Tags			email-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			payload			body		EmailTemplatePayload	false	"Unsaved email customization"
+//	@Success		200				{object}	EmailTemplatePreviewResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/email-templates/preview [post]
+func (app *application) previewEmailTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var branding mailer.Branding
+	if r.ContentLength == 0 {
+		saved, err := app.scheduleEmailBranding(r.Context(), restaurant.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		branding = saved
+	} else {
+		var payload EmailTemplatePayload
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		if err := validateEmailTemplatePayload(payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		branding = brandingFromPayload(payload)
+	}
+
+	locale := requestLocale(r)
+	data := sampleScheduleEmailData(restaurant.Name, strings.TrimSpace(user.FirstName+" "+user.LastName), locale)
+	rendered, err := branding.Render(brandingVars(data))
+	if err != nil {
+		app.badRequestResponse(w, r, fmt.Errorf("invalid template: %w", err))
+		return
+	}
+	data.Branding = rendered
+
+	subject, body, err := mailer.Render(mailer.Localized(mailer.ScheduleNotificationTemplate, locale), data)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, EmailTemplatePreviewResponse{Subject: subject, HTML: body}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// scheduleEmailBranding loads the restaurant's email customization; restaurants without one get the zero value (defaults)
+func (app *application) scheduleEmailBranding(ctx context.Context, restaurantID int64) (mailer.Branding, error) {
+	tmpl, err := app.store.EmailTemplates.GetByRestaurant(ctx, restaurantID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return mailer.Branding{}, nil
+		}
+		return mailer.Branding{}, err
+	}
+
+	return mailer.Branding{
+		Subject:       tmpl.Subject,
+		HeaderMessage: tmpl.HeaderMessage,
+		LogoURL:       tmpl.LogoURL,
+		AccentColor:   tmpl.AccentColor,
+	}, nil
+}
+
+// validateEmailTemplatePayload checks field formats and that the subject and header render against sample data
+func validateEmailTemplatePayload(payload EmailTemplatePayload) error {
+	if err := Validate.Struct(payload); err != nil {
+		return err
+	}
+
+	data := sampleScheduleEmailData("Restaurant", "Employee", i18n.Default)
+	if _, err := brandingFromPayload(payload).Render(brandingVars(data)); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	return nil
+}
+
+func brandingFromPayload(payload EmailTemplatePayload) mailer.Branding {
+	return mailer.Branding{
+		Subject:       payload.Subject,
+		HeaderMessage: payload.HeaderMessage,
+		LogoURL:       payload.LogoURL,
+		AccentColor:   payload.AccentColor,
+	}
+}
+
+func brandingVars(data *ScheduleEmailData) mailer.BrandingVars {
+	return mailer.BrandingVars{
+		RestaurantName: data.RestaurantName,
+		EmployeeName:   data.EmployeeName,
+		ScheduleStart:  data.ScheduleStart,
+		ScheduleEnd:    data.ScheduleEnd,
+	}
+}
+
+// sampleScheduleEmailData builds next week's schedule email with placeholder shifts for previews
+func sampleScheduleEmailData(restaurantName, employeeName string, locale i18n.Locale) *ScheduleEmailData {
+	start := time.Now().AddDate(0, 0, 7)
+	start = start.AddDate(0, 0, -int(start.Weekday())+1) // Monday of next week
+	end := start.AddDate(0, 0, 6)
+
+	shifts := []ScheduleEmailShift{
+		{
+			Date:      i18n.FormatWeekday(locale, start),
+			StartTime: i18n.FormatTimeOfDay(locale, "09:00:00"),
+			EndTime:   i18n.FormatTimeOfDay(locale, "17:00:00"),
+			RoleName:  "Server",
+			RoleColor: "#3498db",
+		},
+		{
+			Date:      i18n.FormatWeekday(locale, start.AddDate(0, 0, 2)),
+			StartTime: i18n.FormatTimeOfDay(locale, "16:00:00"),
+			EndTime:   i18n.FormatTimeOfDay(locale, "23:00:00"),
+			RoleName:  "Bartender",
+			RoleColor: "#9b59b6",
+			Notes:     mailer.TextEscaped.HTML("Inventory count after close"),
+		},
+	}
+
+	sampleHours := &store.OperatingHours{}
+	for day := 0; day < 7; day++ {
+		sampleHours.Days = append(sampleHours.Days, &store.OperatingDay{
+			DayOfWeek: day,
+			Closed:    day == int(time.Sunday),
+			OpenTime:  "08:00:00",
+			CloseTime: "23:00:00",
+		})
+	}
+	hours := transformHoursForEmail(effectiveHours(sampleHours, nil, start, end), locale)
+
+	return &ScheduleEmailData{
+		RestaurantName: restaurantName,
+		EmployeeName:   employeeName,
+		ScheduleStart:  i18n.FormatDay(locale, start.Format("2006-01-02")),
+		ScheduleEnd:    i18n.FormatDay(locale, end.Format("2006-01-02")),
+		Shifts:         shifts,
+		Hours:          hours,
+		HasShifts:      true,
+		HasHours:       true,
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestScheduleEmailBranding(t *testing.T) {
+	data := sampleScheduleEmailData("Bob's Diner", "Alex Smith", i18n.Default)
+
+	t.Run("should render placeholders in the subject", func(t *testing.T) {
+		branding := mailer.Branding{Subject: "{{.RestaurantName}}: shifts for {{.EmployeeName}}"}
+
+		rendered, err := branding.Render(brandingVars(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data.Branding = rendered
+
+		subject, _, err := mailer.Render(mailer.ScheduleNotificationTemplate, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(subject, "shifts for Alex Smith") {
+			t.Fatalf("expected customized subject, got %q", subject)
+		}
+	})
+
+	t.Run("should escape markup and unsafe URLs", func(t *testing.T) {
+		branding := mailer.Branding{
+			HeaderMessage: `<script>alert("hi")</script>`,
+			LogoURL:       `javascript:alert(1)`,
+			AccentColor:   `red;}</style><script>`,
+		}
+
+		rendered, err := branding.Render(brandingVars(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data.Branding = rendered
+
+		_, body, err := mailer.Render(mailer.ScheduleNotificationTemplate, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(body, "<script>") || strings.Contains(body, "javascript:") {
+			t.Fatal("expected owner-supplied content to be escaped")
+		}
+	})
+
+	t.Run("should reject unknown placeholders", func(t *testing.T) {
+		err := validateEmailTemplatePayload(EmailTemplatePayload{Subject: "{{.Password}}"})
+		if err == nil {
+			t.Fatal("expected an error for an unknown field")
+		}
+	})
+}
+
+func TestScheduleEmailTrainingShifts(t *testing.T) {
+	trainee, trainer := int64(1), int64(2)
+	trainerName := "Jordan Lee"
+	trainerShiftID := int64(20)
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	shifts := []*store.ScheduledShift{
+		{ID: 20, EmployeeID: &trainer, EmployeeName: &trainerName, RoleName: "Bartender", ShiftDate: date, StartTime: "16:00:00", EndTime: "23:00:00"},
+		{ID: 21, EmployeeID: &trainee, RoleName: "Bartender", ShiftDate: date, StartTime: "16:00:00", EndTime: "23:00:00", Training: true, TrainerShiftID: &trainerShiftID},
+	}
+
+	employee := &store.Employee{ID: trainee, FullName: "Alex Smith"}
+	schedule := &store.Schedule{StartDate: "2026-03-02", EndDate: "2026-03-08"}
+	data := buildScheduleEmailData(employee, shifts, nil, "Bob's Diner", schedule, i18n.Default, mailer.TextEscaped)
+
+	_, body, err := mailer.Render(mailer.ScheduleNotificationTemplate, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "Training with Jordan Lee") {
+		t.Fatal("expected the training shift to name the trainer")
+	}
+}
+
+func TestScheduleEmailEscapesUserText(t *testing.T) {
+	employeeID := int64(1)
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	shifts := []*store.ScheduledShift{
+		{ID: 1, EmployeeID: &employeeID, RoleName: "Server", ShiftDate: date, StartTime: "09:00:00", EndTime: "17:00:00", Notes: "**Early** <script>alert(1)</script>"},
+	}
+	events := []*store.Event{
+		{Title: "Party<img src=x onerror=alert(1)>", Description: "- <iframe src=//evil.test></iframe>", Date: "2026-03-03", StartTime: "18:00:00", EndTime: "22:00:00"},
+	}
+
+	employee := &store.Employee{ID: employeeID, FullName: "Alex Smith"}
+	schedule := &store.Schedule{StartDate: "2026-03-02", EndDate: "2026-03-08", Note: "Spring <script>break</script>"}
+	dayNotes := []*store.ScheduleDayNote{{Date: "2026-03-03", Note: "Inspection <iframe src=//evil.test></iframe>"}}
+
+	for _, policy := range []mailer.TextPolicy{mailer.TextEscaped, mailer.TextMarkdown} {
+		data := buildScheduleEmailData(employee, shifts, events, "<b>Bob's</b> Diner", schedule, i18n.Default, policy)
+		data.DayNotes = transformDayNotesForEmail(dayNotes, i18n.Default, policy)
+		data.HasDayNotes = true
+
+		_, body, err := mailer.Render(mailer.ScheduleNotificationTemplate, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range []string{"<script", "<img", "<iframe", "<b>"} {
+			if strings.Contains(body, tag) {
+				t.Errorf("policy %d: expected %s to be escaped", policy, tag)
+			}
+		}
+		if !strings.Contains(body, "Spring") || !strings.Contains(body, "Inspection") {
+			t.Errorf("policy %d: expected the schedule and day notes in the email", policy)
+		}
+		if policy == mailer.TextMarkdown && !strings.Contains(body, "<strong>Early</strong>") {
+			t.Error("expected markdown to render bold notes")
+		}
+	}
+}
+
+func TestPreviewScheduleEmail(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+
+	employeeID := int64(7)
+	spanish := "es"
+	app.store.Employees = &store.MockEmployeeStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+			restaurantID := int64(1)
+			if id == 8 {
+				restaurantID = 2
+			}
+			return &store.Employee{ID: id, RestaurantID: restaurantID, FullName: "Ana Diaz", Email: "ana@example.com", Locale: &spanish}, nil
+		},
+	}
+	mockScheduleEmailStores(app, []*store.ScheduledShift{
+		{ID: 1, EmployeeID: &employeeID, ShiftDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), StartTime: "09:00:00", EndTime: "17:00:00", RoleName: "Server"},
+	})
+
+	rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/schedules/5/email-preview?employee_id=7", ""), app.mount())
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	var response struct {
+		Data ScheduleEmailPreview `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	preview := response.Data
+	if !preview.Preview || preview.EmployeeID != 7 || preview.Email != "ana@example.com" || preview.Locale != i18n.Spanish {
+		t.Errorf("preview = %+v, want Ana's email in Spanish marked as a preview", preview)
+	}
+	if !strings.Contains(preview.HTML, "Ana Diaz") || !strings.Contains(preview.HTML, "Server") {
+		t.Errorf("html does not include Ana's shift: %s", preview.HTML)
+	}
+	if len(mail.sent) != 0 {
+		t.Errorf("sent %v, want nothing sent", mail.sent)
+	}
+
+	t.Run("employee of another restaurant", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/schedules/5/email-preview?employee_id=8", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("missing employee_id", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/schedules/5/email-preview", ""), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+// mockScheduleEmailStores serves schedule 2026-03-02 – 2026-03-08 of restaurant 1 with
+// the shifts, and no customization, hours or day notes, for building its emails
+func mockScheduleEmailStores(app *application, shifts []*store.ScheduledShift) {
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 1, StartDate: "2026-03-02", EndDate: "2026-03-08"}, nil
+		},
+	}
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) { return shifts, nil },
+	}
+	app.store.EmailTemplates = &store.MockEmailTemplateStorer{
+		GetByRestaurantFunc: func(context.Context, int64) (*store.EmailTemplate, error) { return nil, store.ErrNotFound },
+	}
+	app.store.OperatingHours = &store.MockOperatingHoursStorer{
+		GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+			return &store.OperatingHours{RestaurantID: restaurantID}, nil
+		},
+		ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+			return nil, nil
+		},
+	}
+	app.store.ScheduleNotes = &store.MockScheduleNoteStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduleDayNote, error) { return nil, nil },
+	}
+}
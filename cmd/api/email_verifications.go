@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// emailVerificationDays is how long an emailed verification link works
+const emailVerificationDays = 7
+
+var (
+	errEmailUnverified      = errors.New("email address not verified; send the employee a verification link")
+	errEmailAlreadyVerified = errors.New("the employee has already verified this email address")
+)
+
+// EmailVerificationRequest is the verification link sent to an employee's address
+type EmailVerificationRequest struct {
+	EmployeeID int64             `json:"employee_id"`
+	Email      string            `json:"email"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Quota      *cache.EmailQuota `json:"quota,omitempty"`
+}
+
+// EmailVerificationEmailData contains the data for the email verification template
+type EmailVerificationEmailData struct {
+	RestaurantName string
+	EmployeeName   string
+	VerifyURL      string
+	ExpiresOn      string
+}
+
+// RequestEmailVerification godoc
+//
+//	@Summary		Sends an employee a link to verify their email
+//	@Description	Emails the employee a personal link that confirms their current address is theirs, replacing any earlier link. The link works for 7 days and only for the address it was sent to. Until the address is verified the employee is flagged in send-email responses, and a restaurant with require_email_verification holds their schedule emails.
+//	@Tags			employee
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{object}	EmailVerificationRequest
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/email-verification [post]
+func (app *application) requestEmailVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	switch {
+	case employee.Email == "":
+		app.badRequestResponse(w, r, errors.New("the employee has no email address"))
+		return
+	case employee.EmailVerifiedAt != nil:
+		app.conflictResponse(w, r, errEmailAlreadyVerified)
+		return
+	case employee.EmailBouncedAt != nil:
+		app.conflictResponse(w, r, errEmailBounced)
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	token := uuid.New().String()
+	expiresAt := time.Now().AddDate(0, 0, emailVerificationDays).UTC()
+	if err := app.store.Employees.RequestEmailVerification(ctx, employee, token, expiresAt); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	locale := i18n.Resolve(employee.Locale, getUserFromContext(r).Locale)
+	emailData := &EmailVerificationEmailData{
+		RestaurantName: mailer.PlainText(restaurant.Name),
+		EmployeeName:   mailer.PlainText(employee.FullName),
+		VerifyURL:      fmt.Sprintf("%s/verify-email/%s", app.config.frontendURL, token),
+		ExpiresOn:      i18n.FormatDate(locale, expiresAt.In(restaurant.Location())),
+	}
+	isProdEnv := app.config.env == "production"
+	if _, err := app.mailer.Send(mailer.Localized(mailer.EmailVerificationTemplate, locale), employee.FullName, employee.Email, emailData, !isProdEnv); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := &EmailVerificationRequest{
+		EmployeeID: employee.ID,
+		Email:      employee.Email,
+		ExpiresAt:  expiresAt,
+		Quota:      quota,
+	}
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// VerifyEmail godoc
+//
+//	@Summary		Verifies an employee's email
+//	@Description	Confirms the address behind an emailed verification link and uses the link up. It needs no account: the token in the URL is the access. The link stops working when it expires, the employee's email changes or the restaurant is archived.
+//	@Tags			employee
+//	@Produce		json
+//	@Param			token	path		string	true	"Verification token"
+//	@Success		200		{object}	store.VerifiedEmail
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/email-verifications/{token} [post]
+func (app *application) verifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	// The token is in the URL: keep the page out of caches and Referer headers
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	verified, err := app.store.Employees.VerifyEmail(r.Context(), chi.URLParam(r, "token"), time.Now())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("verification link not found, expired or already used"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, verified); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
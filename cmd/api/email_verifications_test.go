@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestRequestEmailVerification(t *testing.T) {
+	setup := func(t *testing.T, employee *store.Employee) (*application, *fakeMailer, *string) {
+		app, _ := newMockedApplication(t, testUserID)
+		mail := &fakeMailer{}
+		app.mailer = mail
+		var token string
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(context.Context, int64) (*store.Employee, error) { return employee, nil },
+			RequestEmailVerificationFunc: func(_ context.Context, _ *store.Employee, issued string, _ time.Time) error {
+				token = issued
+				return nil
+			},
+		}
+		return app, mail, &token
+	}
+
+	t.Run("emails a link to the current address", func(t *testing.T) {
+		app, mail, token := setup(t, &store.Employee{ID: 3, RestaurantID: 1, FullName: "Ana Diaz", Email: "ana@example.com"})
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/3/email-verification", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if *token == "" {
+			t.Error("no verification token was stored")
+		}
+		if len(mail.sent) != 1 || mail.sent[0] != "email_verification.go.tmpl ana@example.com" {
+			t.Errorf("sent = %v, want the link emailed to Ana", mail.sent)
+		}
+	})
+
+	t.Run("an address verified already", func(t *testing.T) {
+		verified := time.Now()
+		app, mail, _ := setup(t, &store.Employee{ID: 3, RestaurantID: 1, Email: "ana@example.com", EmailVerifiedAt: &verified})
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/3/email-verification", ""), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if len(mail.sent) != 0 {
+			t.Errorf("sent = %v, want nothing", mail.sent)
+		}
+	})
+
+	t.Run("another restaurant's employee", func(t *testing.T) {
+		app, _, _ := setup(t, &store.Employee{ID: 3, RestaurantID: 2, Email: "ana@example.com"})
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/3/email-verification", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.Employees = &store.MockEmployeeStorer{
+		VerifyEmailFunc: func(_ context.Context, token string, _ time.Time) (*store.VerifiedEmail, error) {
+			if token != "good" {
+				return nil, store.ErrNotFound
+			}
+			return &store.VerifiedEmail{EmployeeID: 3, Email: "ana@example.com", VerifiedAt: time.Now()}, nil
+		},
+	}
+
+	rr := executeRequest(httptest.NewRequest(http.MethodPost, "/v1/email-verifications/good", nil), app.mount())
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	rr = executeRequest(httptest.NewRequest(http.MethodPost, "/v1/email-verifications/used", nil), app.mount())
+	checkResponseCode(t, http.StatusNotFound, rr.Code)
+}
+
+func TestSendScheduleEmailFlagsUnverifiedAddresses(t *testing.T) {
+	send := func(t *testing.T, requireVerification bool) (SendScheduleEmailResponse, *fakeMailer) {
+		app, _ := newMockedApplication(t, testUserID)
+		mail := &fakeMailer{}
+		app.mailer = mail
+		mockScheduleEmailStores(app, nil)
+		app.store.Restaurants = &store.MockRestaurantStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Restaurant, error) {
+				return &store.Restaurant{ID: id, UserID: testUserID, RequireEmailVerification: requireVerification}, nil
+			},
+		}
+		verified := time.Now()
+		app.store.Employees = &store.MockEmployeeStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) {
+				return []*store.Employee{
+					{ID: 2, RestaurantID: 1, FullName: "Ben Lee", Email: "ben@example.com", EmailVerifiedAt: &verified},
+					{ID: 3, RestaurantID: 1, FullName: "Cy Park", Email: "cy@example.com"},
+				}, nil
+			},
+		}
+		app.store.EmailDeliveries = &store.MockEmailDeliveryStorer{
+			CreateFunc:      func(context.Context, *store.EmailDelivery) error { return nil },
+			CreateBlastFunc: func(context.Context, *store.EmailBlast) error { return nil },
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/send-email", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data SendScheduleEmailResponse `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data, mail
+	}
+
+	t.Run("flags them by default", func(t *testing.T) {
+		response, mail := send(t, false)
+
+		if response.Successful != 2 || len(response.UnverifiedEmployeeIDs) != 1 || response.UnverifiedEmployeeIDs[0] != 3 {
+			t.Errorf("response = %+v, want both sent and Cy flagged", response)
+		}
+		if len(mail.sent) != 2 {
+			t.Errorf("sent = %v, want both emails", mail.sent)
+		}
+	})
+
+	t.Run("holds them when verification is required", func(t *testing.T) {
+		response, mail := send(t, true)
+
+		if response.Successful != 1 || response.Failed != 1 || response.Failures[0].Error != errEmailUnverified.Error() {
+			t.Errorf("response = %+v, want Cy's email held", response)
+		}
+		if len(mail.sent) != 1 || mail.sent[0] != "schedule_notification.go.tmpl ben@example.com" {
+			t.Errorf("sent = %v, want Ben's email only", mail.sent)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type CreateEmployeeNotePayload struct {
+	// Body is Markdown, shown as rich text
+	Body string `json:"body" validate:"required,max=10000"`
+}
+
+// GetEmployeeNotes godoc
+//
+//	@Summary		Lists the manager notes on an employee
+//	@Description	Private notes the restaurant owner keeps on the employee, newest first, each with its author and time. They are only served here: employee-facing endpoints, shift leads and exports never include them.
+//	@Tags			employee
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{array}		store.EmployeeNote
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/manager-notes [get]
+func (app *application) getEmployeeNotesHandler(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.employeeForNotes(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := app.store.EmployeeNotes.ListByEmployee(r.Context(), employee.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, notes); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateEmployeeNote godoc
+//
+//	@Summary		Adds a manager note to an employee
+//	@Description	Adds a timestamped private note, signed by the signed-in owner. Notes can't be edited or deleted; add another to correct one. The addition goes in the audit log, without the note's text.
+//	@Tags			employee
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			employeeID		path		int							true	"Employee ID"
+//	@Param			payload			body		CreateEmployeeNotePayload	true	"Note"
+//	@Success		201				{object}	store.EmployeeNote
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/manager-notes [post]
+func (app *application) createEmployeeNoteHandler(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.employeeForNotes(w, r)
+	if !ok {
+		return
+	}
+
+	var payload CreateEmployeeNotePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	note := &store.EmployeeNote{
+		RestaurantID: employee.RestaurantID,
+		EmployeeID:   employee.ID,
+		Body:         payload.Body,
+		AuthorUserID: &user.ID,
+		AuthorName:   strings.TrimSpace(user.FirstName + " " + user.LastName),
+	}
+	if err := app.store.EmployeeNotes.Create(r.Context(), note); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordAudit(r.Context(), &store.AuditEntry{
+		RestaurantID: employee.RestaurantID,
+		EntityType:   store.AuditEntityEmployeeNote,
+		EntityID:     note.ID,
+		Action:       store.AuditCreated,
+		Summary:      fmt.Sprintf("Added a manager note on %s", employee.FullName),
+		ActorUserID:  &user.ID,
+	})
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, note); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// employeeForNotes loads the employee in the URL for their restaurant's owner only:
+// shift leads manage shifts, not people, so they don't see manager notes
+func (app *application) employeeForNotes(w http.ResponseWriter, r *http.Request) (*store.Employee, bool) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return nil, false
+	}
+
+	return app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestEmployeeNotes(t *testing.T) {
+	setup := func(t *testing.T, userID int64) (*application, *[]*store.AuditEntry) {
+		app, _ := newMockedApplication(t, userID)
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				return &store.Employee{ID: id, RestaurantID: 3, FullName: "Ana Diaz"}, nil
+			},
+		}
+		app.store.EmployeeNotes = &store.MockEmployeeNoteStorer{
+			ListByEmployeeFunc: func(context.Context, int64) ([]*store.EmployeeNote, error) {
+				return []*store.EmployeeNote{{ID: 1, Body: "Asked for fewer closes"}}, nil
+			},
+			CreateFunc: func(_ context.Context, note *store.EmployeeNote) error {
+				note.ID = 2
+				return nil
+			},
+		}
+		var audited []*store.AuditEntry
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(_ context.Context, entries []*store.AuditEntry) error {
+				audited = append(audited, entries...)
+				return nil
+			},
+		}
+		return app, &audited
+	}
+
+	t.Run("the owner adds a note and it is audited without its text", func(t *testing.T) {
+		app, audited := setup(t, testUserID)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/employees/5/manager-notes", `{"body":"**Great** with new hires"}`), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if len(*audited) != 1 {
+			t.Fatalf("audit entries = %d, want 1", len(*audited))
+		}
+		entry := (*audited)[0]
+		if entry.EntityType != store.AuditEntityEmployeeNote || entry.EntityID != 2 || entry.Action != store.AuditCreated || len(entry.Changes) != 0 {
+			t.Errorf("audit entry = %+v", entry)
+		}
+	})
+
+	t.Run("a note needs a body", func(t *testing.T) {
+		app, _ := setup(t, testUserID)
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/employees/5/manager-notes", `{"body":""}`), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("only the owner sees them", func(t *testing.T) {
+		app, _ := setup(t, testUserID)
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/employees/5/manager-notes", ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		other, _ := setup(t, testUserID+1)
+		rr = executeRequest(authedRequest(t, other, http.MethodGet, "/v1/restaurants/3/employees/5/manager-notes", ""), other.mount())
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -2,21 +2,50 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
 type CreateEmployeePayload struct {
-	FullName     string  `json:"full_name" validate:"required,max=255"`
-	Email        string  `json:"email" validate:"required,email,max=255"`
+	FullName        string  `json:"full_name" validate:"required,max=255"`
+	Email           string  `json:"email" validate:"required,email,max=255"`
+	Locale          *string `json:"locale" validate:"omitempty,oneof=en es"`
+	HourlyRateCents *int    `json:"hourly_rate_cents" validate:"omitempty,min=0"`
+	Seniority       int     `json:"seniority" validate:"min=0"`
+	// Birthday and HireDate are optional YYYY-MM-DD dates for the staff milestone digest
+	Birthday string `json:"birthday"`
+	HireDate string `json:"hire_date"`
+	// ExternalID is the employee's ID in an HR system, unique within the restaurant
+	ExternalID string `json:"external_id" validate:"max=255"`
+	// NotificationMode overrides the restaurant's for the employee: immediate, daily or shift_day
+	NotificationMode *string `json:"notification_mode" validate:"omitempty,oneof=immediate daily shift_day"`
+	// TerminatedOn is the optional YYYY-MM-DD day the employee left, for retention policies
+	TerminatedOn string `json:"terminated_on"`
 }
 
+// UpdateEmployeePayload is a merge patch: null clears the locale, hourly rate,
+// birthday, hire date, external ID, notification mode and termination date and
+// resets seniority to 0; the name and email can't be cleared
 type UpdateEmployeePayload struct {
-	FullName     *string  `json:"full_name" validate:"omitempty,max=255"`
-	Email        *string  `json:"email" validate:"omitempty,email,max=255"`
+	FullName        Patch[string] `json:"full_name" validate:"omitempty,max=255" swaggertype:"string"`
+	Email           Patch[string] `json:"email" validate:"omitempty,email,max=255" swaggertype:"string"`
+	Locale          Patch[string] `json:"locale" validate:"omitempty,oneof=en es" swaggertype:"string"`
+	HourlyRateCents Patch[int]    `json:"hourly_rate_cents" validate:"omitempty,min=0" swaggertype:"integer"`
+	Seniority       Patch[int]    `json:"seniority" validate:"omitempty,min=0" swaggertype:"integer"`
+	// Birthday and HireDate set a YYYY-MM-DD date; an empty string clears it too
+	Birthday Patch[string] `json:"birthday" swaggertype:"string"`
+	HireDate Patch[string] `json:"hire_date" swaggertype:"string"`
+	// ExternalID sets the employee's ID in an HR system; an empty string clears it too
+	ExternalID Patch[string] `json:"external_id" validate:"omitempty,max=255" swaggertype:"string"`
+	// NotificationMode overrides the restaurant's for the employee; null follows the restaurant again
+	NotificationMode Patch[string] `json:"notification_mode" validate:"omitempty,oneof=immediate daily shift_day" swaggertype:"string"`
+	// TerminatedOn sets the YYYY-MM-DD day the employee left; an empty string clears it too
+	TerminatedOn Patch[string] `json:"terminated_on" swaggertype:"string"`
 }
 
 type AddEmployeeRolesPayload struct {
@@ -45,8 +74,8 @@ func (app *application) getEmployeesHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -55,10 +84,12 @@ func (app *application) getEmployeesHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+	version, err := app.store.Versions.Employees(r.Context(), restaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if app.notModified(w, r, fmt.Sprintf("employees-%d", restaurantID), version) {
 		return
 	}
 
@@ -68,7 +99,9 @@ func (app *application) getEmployeesHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, employees)
+	app.setAvatarURLs(employees...)
+
+	err = app.jsonResponse(w, r, http.StatusOK, employees)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -88,6 +121,7 @@ func (app *application) getEmployeesHandler(w http.ResponseWriter, r *http.Reque
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/employees [post]
@@ -99,8 +133,8 @@ func (app *application) createEmployeeHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -109,13 +143,6 @@ func (app *application) createEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	var payload CreateEmployeePayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -127,19 +154,52 @@ func (app *application) createEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	birthday, err := optionalDate("birthday", payload.Birthday)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	hireDate, err := optionalDate("hire_date", payload.HireDate)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	terminatedOn, err := optionalDate("terminated_on", payload.TerminatedOn)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	// Create employee using restaurant ID from URL
 	employee := &store.Employee{
-		RestaurantID: restaurantID,
-		FullName:     payload.FullName,
-		Email:        payload.Email,
+		RestaurantID:    restaurantID,
+		FullName:        payload.FullName,
+		Email:           payload.Email,
+		Locale:          payload.Locale,
+		HourlyRateCents: payload.HourlyRateCents,
+		Seniority:       payload.Seniority,
+		Birthday:        birthday,
+		HireDate:        hireDate,
+		ExternalID:      optionalString(payload.ExternalID),
+		TerminatedOn:    terminatedOn,
+	}
+	if payload.NotificationMode != nil {
+		mode := store.NotificationMode(*payload.NotificationMode)
+		employee.NotificationMode = &mode
 	}
 
 	if err := app.store.Employees.Create(r.Context(), employee); err != nil {
+		if errors.Is(err, store.ErrDuplicateEmployee) || errors.Is(err, store.ErrDuplicateExternalID) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusCreated, employee)
+	app.emitEmployeeEvent(r.Context(), webhookEmployeeCreated, nil, employee)
+
+	err = app.jsonResponse(w, r, http.StatusCreated, employee)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -175,8 +235,8 @@ func (app *application) getEmployeeHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -185,13 +245,6 @@ func (app *application) getEmployeeHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -208,7 +261,9 @@ func (app *application) getEmployeeHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, employee)
+	app.setAvatarURLs(employee)
+
+	err = app.jsonResponse(w, r, http.StatusOK, employee)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -218,9 +273,9 @@ func (app *application) getEmployeeHandler(w http.ResponseWriter, r *http.Reques
 // UpdateEmployee godoc
 //
 //	@Summary		Updates an employee
-//	@Description	Updates an employee by ID
+//	@Description	Updates an employee by ID as a JSON merge patch (RFC 7386): fields left out are unchanged; null clears locale, hourly_rate_cents, birthday, hire_date, external_id and terminated_on and resets seniority to 0
 //	@Tags			employee
-//	@Accept			json
+//	@Accept			json,application/merge-patch+json
 //	@Produce		json
 //	@Param			restaurant_id	path		int						true	"Restaurant ID"
 //	@Param			id				path		int						true	"Employee ID"
@@ -229,6 +284,7 @@ func (app *application) getEmployeeHandler(w http.ResponseWriter, r *http.Reques
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/employees/{id} [patch]
@@ -246,8 +302,8 @@ func (app *application) updateEmployeeHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -256,13 +312,6 @@ func (app *application) updateEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing employee
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
@@ -292,22 +341,68 @@ func (app *application) updateEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if err := errors.Join(payload.FullName.NotNull("full_name"), payload.Email.NotNull("email")); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	before := *employee
+
 	// Update fields if provided
-	if payload.FullName != nil {
-		employee.FullName = *payload.FullName
+	payload.FullName.Apply(&employee.FullName)
+	payload.Email.Apply(&employee.Email)
+	payload.HourlyRateCents.ApplyPtr(&employee.HourlyRateCents)
+	payload.Locale.ApplyPtr(&employee.Locale)
+	payload.Seniority.Apply(&employee.Seniority)
+
+	if payload.Birthday.Set {
+		if employee.Birthday, err = optionalDate("birthday", payload.Birthday.Value); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if payload.HireDate.Set {
+		if employee.HireDate, err = optionalDate("hire_date", payload.HireDate.Value); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
 	}
 
-	if payload.Email != nil {
-		employee.Email = *payload.Email
+	if payload.ExternalID.Set {
+		employee.ExternalID = optionalString(payload.ExternalID.Value)
+	}
+
+	if payload.TerminatedOn.Set {
+		if employee.TerminatedOn, err = optionalDate("terminated_on", payload.TerminatedOn.Value); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if payload.NotificationMode.Set {
+		employee.NotificationMode = nil
+		if payload.NotificationMode.Present() {
+			mode := store.NotificationMode(payload.NotificationMode.Value)
+			employee.NotificationMode = &mode
+		}
 	}
 
 	// Save updates
 	if err := app.store.Employees.Update(r.Context(), employee); err != nil {
+		if errors.Is(err, store.ErrDuplicateEmployee) || errors.Is(err, store.ErrDuplicateExternalID) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, employee)
+	app.emitEmployeeEvent(r.Context(), webhookEmployeeUpdated, &before, employee)
+
+	app.setAvatarURLs(employee)
+
+	err = app.jsonResponse(w, r, http.StatusOK, employee)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -343,8 +438,8 @@ func (app *application) deleteEmployeeHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -353,13 +448,6 @@ func (app *application) deleteEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing employee
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
@@ -387,6 +475,8 @@ func (app *application) deleteEmployeeHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	app.emitEmployeeEvent(r.Context(), webhookEmployeeDeactivated, nil, employee)
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -421,8 +511,8 @@ func (app *application) addEmployeeRolesHandler(w http.ResponseWriter, r *http.R
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -431,13 +521,6 @@ func (app *application) addEmployeeRolesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Check if employee exists and belongs to this restaurant
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
@@ -529,8 +612,8 @@ func (app *application) removeEmployeeRoleHandler(w http.ResponseWriter, r *http
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -539,13 +622,6 @@ func (app *application) removeEmployeeRoleHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Check if employee exists and belongs to this restaurant
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
@@ -624,8 +700,7 @@ func (app *application) getEmployeeRolesHandler(w http.ResponseWriter, r *http.R
 	user := getUserFromContext(r)
 
 	// Verify restaurant ownership
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
 			app.notFoundResponse(w, r, err)
@@ -635,11 +710,6 @@ func (app *application) getEmployeeRolesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if restaurant.UserID != user.ID {
-		app.unauthorizedErrorResponse(w, r, errors.New("not restaurant owner"))
-		return
-	}
-
 	// Verify employee exists and belongs to restaurant
 	employee, err := app.store.Employees.GetByID(r.Context(), employeeID)
 	if err != nil {
@@ -664,7 +734,15 @@ func (app *application) getEmployeeRolesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, roles); err != nil {
+	if err := app.jsonResponse(w, r, http.StatusOK, roles); err != nil {
 		app.internalServerError(w, r, err)
 	}
-}
\ No newline at end of file
+}
+// optionalString is the trimmed value, nil when that leaves it empty
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -1,62 +1,194 @@
 package main
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
 )
 
 func (app *application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Errorw("internal error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	if store.IsTimeout(err) {
+		app.timeoutResponse(w, r, err)
+		return
+	}
+
+	app.logger.Errorw("internal error", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
-	writeJSONError(w, http.StatusInternalServerError, 
+	app.errorJSON(w, r, http.StatusInternalServerError, 
 	"the server encounttered a problem")
 }
 
-// func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request) {
-// 	app.logger.Warnw("forbidden", "method", r.Method, "path", r.URL.Path, "error")
+// timeoutResponse reports a store call that ran out of time as 503 rather than
+// 500, so clients can tell a slow database from a broken request and retry
+func (app *application) timeoutResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("query timeout", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	w.Header().Set("Retry-After", "1")
 
-// 	writeJSONError(w, http.StatusForbidden, 
-// 	"forbidden")
-// }
+	app.errorJSON(w, r, http.StatusServiceUnavailable, "the request timed out, please try again")
+}
+
+func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("forbidden", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusForbidden, err.Error())
+}
 
 func (app *application) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("bad request", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.logger.Warnf("bad request", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
-	writeJSONError(w, http.StatusBadRequest, 
-	err.Error())
+	app.errorJSON(w, r, http.StatusBadRequest, 
+	validationTranslator.Translate(err, requestLocale(r)))
 }
 
-// func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
-// 	app.logger.Errorw("conflict response", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+func (app *application) conflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Errorw("conflict response", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
-// 	writeJSONError(w, http.StatusConflict, 
-// 	err.Error())
-// }
+	app.errorJSON(w, r, http.StatusConflict, 
+	err.Error())
+}
 
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("not found error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.logger.Warnf("not found error", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
-	writeJSONError(w, http.StatusNotFound, 
+	app.errorJSON(w, r, http.StatusNotFound, 
 	"not found")
 }
 
+func (app *application) goneResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("gone response", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusGone, err.Error())
+}
+
+func (app *application) lockedResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("locked response", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusLocked, err.Error())
+}
+
+// overBudgetResponse refuses to publish a schedule over its labor budget, with
+// the per-day breakdown so the owner knows what to trim
+func (app *application) overBudgetResponse(w http.ResponseWriter, r *http.Request, cost *LaborCost) {
+	err := laborBudgetError(cost)
+	app.logger.Warnw("over labor budget", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	message := err.Error() + "; publish with force=true to publish anyway"
+	if requestAPIVersion(r) == apiV2 {
+		writeJSON(w, http.StatusConflict, &envelopeV2{
+			Meta:   newResponseMeta(r),
+			Errors: []apiError{{Code: "over_labor_budget", Message: message, Details: cost}},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusConflict, map[string]any{"error": message, "labor_cost": cost})
+}
+
+// complianceViolationResponse refuses to publish a schedule that breaks compliance
+// rules set to block, listing every violation so the owner can fix them together
+func (app *application) complianceViolationResponse(w http.ResponseWriter, r *http.Request, violations []ComplianceViolation) {
+	err := complianceError(blockingViolations(violations))
+	app.logger.Warnw("compliance rules broken", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	if requestAPIVersion(r) == apiV2 {
+		writeJSON(w, http.StatusConflict, &envelopeV2{
+			Meta:   newResponseMeta(r),
+			Errors: []apiError{{Code: "compliance_violation", Message: err.Error(), Details: violations}},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "violations": violations})
+}
+
+// strandedShiftsResponse refuses to shrink a schedule past its shifts, listing
+// them so the owner can move them first or have them deleted
+func (app *application) strandedShiftsResponse(w http.ResponseWriter, r *http.Request, shifts []*store.ScheduledShift) {
+	err := fmt.Errorf("%d shifts fall outside the schedule's new dates", len(shifts))
+	app.logger.Warnw("schedule shrink strands shifts", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	message := err.Error() + "; move them, or update with stranded_shifts=delete to delete them"
+	stranded := newShiftResponses(shifts)
+	if requestAPIVersion(r) == apiV2 {
+		writeJSON(w, http.StatusConflict, &envelopeV2{
+			Meta:   newResponseMeta(r),
+			Errors: []apiError{{Code: "stranded_shifts", Message: message, Details: stranded}},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusConflict, map[string]any{"error": message, "stranded_shifts": stranded})
+}
+
+// maintenanceResponse refuses a request the maintenance mode pauses, telling
+// the client when to try again
+func (app *application) maintenanceResponse(w http.ResponseWriter, r *http.Request, m *Maintenance) {
+	app.logger.Infow("paused for maintenance", "method", r.Method, "path", redactedPath(r), "mode", m.Mode)
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(m.RetryAfterSeconds, 1)))
+
+	message := maintenanceMessage(m)
+	if requestAPIVersion(r) == apiV2 {
+		writeJSON(w, http.StatusServiceUnavailable, &envelopeV2{
+			Meta:   newResponseMeta(r),
+			Errors: []apiError{{Code: "maintenance", Message: message, Details: m}},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": message, "maintenance": m})
+}
+
+func (app *application) payloadTooLargeResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("payload too large", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusRequestEntityTooLarge, err.Error())
+}
+
+func (app *application) unsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("unsupported media type", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusUnsupportedMediaType, err.Error())
+}
+
 func (app *application) unauthorizedErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("unauthorized error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.logger.Warnf("unauthorized error", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
-	writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+	app.errorJSON(w, r, http.StatusUnauthorized, "unauthorized")
 }
 
 func (app *application) unauthorizedBasicErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Warnf("unauthorized basic error", "method", r.Method, "path", r.URL.Path, "error", err.Error())
+	app.logger.Warnf("unauthorized basic error", "method", r.Method, "path", redactedPath(r), "error", err.Error())
 
 	w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
 
-	writeJSONError(w, http.StatusUnauthorized, "unauthorized")
+	app.errorJSON(w, r, http.StatusUnauthorized, "unauthorized")
+}
+
+func (app *application) rateLimiterExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	app.logger.Warnw("rate limit exceeded", "method", r.Method, "path", redactedPath(r))
+
+	seconds := strconv.Itoa(resetSeconds(retryAfter))
+	w.Header().Set("Retry-After", seconds)
+
+	app.errorJSON(w, r, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+seconds+"s")
 }
+func (app *application) emailQuotaExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	app.logger.Warnw("email quota exceeded", "method", r.Method, "path", redactedPath(r))
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
 
-func (app *application) rateLimiterExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter string) {
-	app.logger.Warnw("rate limit exceeded", "method", r.Method, "path", r.URL.Path)
-	
-	w.Header().Set("Retry-After", retryAfter)
+	app.errorJSON(w, r, http.StatusTooManyRequests, fmt.Sprintf("email sending limit reached for this restaurant, try again in %s", retryAfter.Round(time.Second)))
+}
 
-	writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded, retry after: "+retryAfter)
-}
\ No newline at end of file
+func (app *application) paymentRequiredResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.Warnw("payment required", "method", r.Method, "path", redactedPath(r), "error", err.Error())
+
+	app.errorJSON(w, r, http.StatusPaymentRequired, err.Error())
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type IssueEventBadgesPayload struct {
+	// EmployeeIDs default to the employees assigned to the event
+	EmployeeIDs []int64 `json:"employee_ids,omitempty" validate:"omitempty,dive,gt=0"`
+}
+
+// EventBadgeWithToken is a new badge with its token, which is only ever shown here
+type EventBadgeWithToken struct {
+	*store.EventBadge
+	EmployeeName string `json:"employee_name"`
+	// Token is what the badge's QR code or NFC tag carries
+	Token string `json:"token"`
+}
+
+type EventCheckInPayload struct {
+	Token string `json:"token" validate:"required,max=100"`
+	// Method is how the badge was read; qr by default
+	Method string `json:"method,omitempty" validate:"omitempty,oneof=qr nfc"`
+}
+
+// EventCheckInResult is a scanned badge's check-in; a repeat scan answers
+// with the first one
+type EventCheckInResult struct {
+	*store.EventCheckIn
+	AlreadyCheckedIn bool `json:"already_checked_in"`
+}
+
+// EventAttendance is who came to an event: the assigned employees, checked in
+// or not, then anyone else whose badge was scanned
+type EventAttendance struct {
+	EventID int64 `json:"event_id"`
+	// Expected is how many employees are assigned; Attended how many of them checked in
+	Expected int `json:"expected"`
+	Attended int `json:"attended"`
+	Absent   int `json:"absent"`
+	// WalkIns checked in without being assigned to the event
+	WalkIns   int             `json:"walk_ins"`
+	Attendees []EventAttendee `json:"attendees"`
+}
+
+type EventAttendee struct {
+	EmployeeID  int64      `json:"employee_id"`
+	FullName    string     `json:"full_name"`
+	Assigned    bool       `json:"assigned"`
+	CheckedIn   bool       `json:"checked_in"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+	Method      string     `json:"method,omitempty"`
+}
+
+// IssueEventBadges godoc
+//
+//	@Summary		Issues event badges
+//	@Description	Issues a badge per employee for the event, by default to everyone assigned to it. Each badge's token goes in a QR code or NFC tag that a manager scans at the event to take attendance; tokens are not shown again, and issuing a badge again replaces the employee's old one.
+//	@Tags			event
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int						true	"Restaurant ID"
+//	@Param			eventID			path		int						true	"Event ID"
+//	@Param			payload			body		IssueEventBadgesPayload	true	"Employees to issue badges to; {} for the assigned ones"
+//	@Success		201				{array}		EventBadgeWithToken
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/events/{eventID}/badges [post]
+func (app *application) issueEventBadgesHandler(w http.ResponseWriter, r *http.Request) {
+	event, ok := app.restaurantEventFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var payload IssueEventBadgesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var employees []*store.Employee
+	var err error
+	if len(payload.EmployeeIDs) == 0 {
+		employees, err = app.store.Events.GetEmployees(r.Context(), event.ID)
+	} else if err = app.validateEventEmployees(r.Context(), event.RestaurantID, payload.EmployeeIDs); err == nil {
+		employees, err = app.store.Employees.GetByIDs(r.Context(), payload.EmployeeIDs)
+	}
+	if err != nil {
+		if errors.Is(err, errEventEmployeesMissing) || errors.Is(err, errEventEmployeesForeign) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if len(employees) == 0 {
+		app.badRequestResponse(w, r, errors.New("the event has no employees assigned; pass employee_ids"))
+		return
+	}
+
+	badges := make([]*EventBadgeWithToken, 0, len(employees))
+	for _, employee := range employees {
+		badge := &store.EventBadge{RestaurantID: event.RestaurantID, EventID: event.ID, EmployeeID: employee.ID}
+		token := uuid.New().String()
+		if err := app.store.EventBadges.Issue(r.Context(), badge, token); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		badges = append(badges, &EventBadgeWithToken{EventBadge: badge, EmployeeName: employee.FullName, Token: token})
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, badges); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CheckInToEvent godoc
+//
+//	@Summary		Checks an employee in to an event
+//	@Description	Records a scan of an employee's event badge. The first scan checks them in with 201; scanning again answers 200 with the first check-in and already_checked_in set.
+//	@Tags			event
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int					true	"Restaurant ID"
+//	@Param			eventID			path		int					true	"Event ID"
+//	@Param			payload			body		EventCheckInPayload	true	"Scanned badge token"
+//	@Success		200				{object}	EventCheckInResult
+//	@Success		201				{object}	EventCheckInResult
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/events/{eventID}/check-ins [post]
+func (app *application) checkInToEventHandler(w http.ResponseWriter, r *http.Request) {
+	event, ok := app.restaurantEventFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var payload EventCheckInPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if payload.Method == "" {
+		payload.Method = store.CheckInQR
+	}
+
+	badge, err := app.store.EventBadges.Authenticate(r.Context(), event.ID, payload.Token)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.badRequestResponse(w, r, errors.New("badge is not valid for this event"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	employee, err := app.store.Employees.GetByID(r.Context(), badge.EmployeeID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	checkIn := &store.EventCheckIn{
+		RestaurantID: event.RestaurantID,
+		EventID:      event.ID,
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.FullName,
+		Method:       payload.Method,
+		ScannedBy:    &user.ID,
+	}
+	first, err := app.store.EventBadges.RecordCheckIn(r.Context(), checkIn)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if !first {
+		status = http.StatusOK
+	}
+	if err := app.jsonResponse(w, r, status, &EventCheckInResult{EventCheckIn: checkIn, AlreadyCheckedIn: !first}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetEventAttendance godoc
+//
+//	@Summary		Reports attendance at an event
+//	@Description	Lists the employees assigned to the event with whether and when their badge was scanned, then anyone checked in without being assigned, with the totals
+//	@Tags			event
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			eventID			path		int	true	"Event ID"
+//	@Success		200				{object}	EventAttendance
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/events/{eventID}/attendance [get]
+func (app *application) getEventAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	event, ok := app.restaurantEventFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	assigned, err := app.store.Events.GetEmployees(r.Context(), event.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	checkIns, err := app.store.EventBadges.ListCheckIns(r.Context(), event.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, eventAttendance(event.ID, assigned, checkIns)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// restaurantEventFromURL loads the URL's event, answering 404 when it isn't
+// the restaurant's
+func (app *application) restaurantEventFromURL(w http.ResponseWriter, r *http.Request) (*store.Event, bool) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid event ID"))
+		return nil, false
+	}
+
+	event, err := app.store.Events.GetByID(r.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if event.RestaurantID != getRestaurantFromContext(r).ID {
+		app.notFoundResponse(w, r, errors.New("event not found"))
+		return nil, false
+	}
+
+	return event, true
+}
+
+// eventAttendance matches the event's check-ins against the employees assigned to it
+func eventAttendance(eventID int64, assigned []*store.Employee, checkIns []*store.EventCheckIn) *EventAttendance {
+	byEmployee := make(map[int64]*store.EventCheckIn, len(checkIns))
+	for _, c := range checkIns {
+		byEmployee[c.EmployeeID] = c
+	}
+
+	attendance := &EventAttendance{EventID: eventID, Expected: len(assigned), Attendees: []EventAttendee{}}
+	isAssigned := make(map[int64]bool, len(assigned))
+	for _, employee := range assigned {
+		isAssigned[employee.ID] = true
+		attendee := EventAttendee{EmployeeID: employee.ID, FullName: employee.FullName, Assigned: true}
+		if c, ok := byEmployee[employee.ID]; ok {
+			attendee.CheckedIn, attendee.CheckedInAt, attendee.Method = true, &c.CheckedInAt, c.Method
+			attendance.Attended++
+		}
+		attendance.Attendees = append(attendance.Attendees, attendee)
+	}
+	attendance.Absent = attendance.Expected - attendance.Attended
+
+	for _, c := range checkIns {
+		if isAssigned[c.EmployeeID] {
+			continue
+		}
+		attendance.WalkIns++
+		attendance.Attendees = append(attendance.Attendees, EventAttendee{
+			EmployeeID:  c.EmployeeID,
+			FullName:    c.EmployeeName,
+			CheckedIn:   true,
+			CheckedInAt: &c.CheckedInAt,
+			Method:      c.Method,
+		})
+	}
+
+	return attendance
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestEventAttendance(t *testing.T) {
+	scanned := time.Date(2026, 6, 1, 17, 55, 0, 0, time.UTC)
+	assigned := []*store.Employee{{ID: 1, FullName: "Alex Smith"}, {ID: 2, FullName: "Sam Lee"}}
+	checkIns := []*store.EventCheckIn{
+		{EmployeeID: 2, EmployeeName: "Sam Lee", Method: store.CheckInNFC, CheckedInAt: scanned},
+		{EmployeeID: 3, EmployeeName: "Kim Park", Method: store.CheckInQR, CheckedInAt: scanned.Add(time.Minute)},
+	}
+
+	attendance := eventAttendance(8, assigned, checkIns)
+
+	if attendance.Expected != 2 || attendance.Attended != 1 || attendance.Absent != 1 || attendance.WalkIns != 1 {
+		t.Fatalf("attendance = %+v, want 1 of 2 attended and 1 walk-in", attendance)
+	}
+	alex, sam, kim := attendance.Attendees[0], attendance.Attendees[1], attendance.Attendees[2]
+	if alex.CheckedIn || !alex.Assigned {
+		t.Errorf("alex = %+v, want assigned and absent", alex)
+	}
+	if !sam.CheckedIn || !sam.CheckedInAt.Equal(scanned) || sam.Method != store.CheckInNFC {
+		t.Errorf("sam = %+v, want checked in by NFC at %v", sam, scanned)
+	}
+	if kim.Assigned || !kim.CheckedIn || kim.FullName != "Kim Park" {
+		t.Errorf("kim = %+v, want a walk-in", kim)
+	}
+}
+
+func TestEventBadges(t *testing.T) {
+	setup := func(t *testing.T) (*application, map[int64]string) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.store.Events = &store.MockEventStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Event, error) {
+				return &store.Event{ID: id, RestaurantID: 1, Title: "All-hands"}, nil
+			},
+			GetEmployeesFunc: func(context.Context, int64) ([]*store.Employee, error) {
+				return []*store.Employee{{ID: 7, RestaurantID: 1, FullName: "Alex Smith"}}, nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				return &store.Employee{ID: id, RestaurantID: 1, FullName: "Alex Smith"}, nil
+			},
+		}
+
+		tokens := map[int64]string{}
+		checkedIn := false
+		app.store.EventBadges = &store.MockEventBadgeStorer{
+			IssueFunc: func(_ context.Context, badge *store.EventBadge, token string) error {
+				tokens[badge.EmployeeID] = token
+				badge.ID = badge.EmployeeID
+				return nil
+			},
+			AuthenticateFunc: func(_ context.Context, eventID int64, token string) (*store.EventBadge, error) {
+				for employeeID, issued := range tokens {
+					if issued == token {
+						return &store.EventBadge{EventID: eventID, EmployeeID: employeeID}, nil
+					}
+				}
+				return nil, store.ErrNotFound
+			},
+			RecordCheckInFunc: func(_ context.Context, c *store.EventCheckIn) (bool, error) {
+				first := !checkedIn
+				checkedIn = true
+				return first, nil
+			},
+		}
+		return app, tokens
+	}
+
+	t.Run("badges go to the assigned employees by default", func(t *testing.T) {
+		app, tokens := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/events/8/badges", `{}`), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		var body struct {
+			Data []EventBadgeWithToken `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != 1 || body.Data[0].EmployeeName != "Alex Smith" || body.Data[0].Token == "" || body.Data[0].Token != tokens[7] {
+			t.Errorf("badges = %+v, want Alex's with the stored token", body.Data)
+		}
+	})
+
+	t.Run("scanning a badge checks in once", func(t *testing.T) {
+		app, tokens := setup(t)
+		tokens[7] = "badge-token"
+		scan := func(body string) (int, EventCheckInResult) {
+			rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/events/8/check-ins", body), app.mount())
+			var resp struct {
+				Data EventCheckInResult `json:"data"`
+			}
+			json.NewDecoder(rr.Body).Decode(&resp)
+			return rr.Code, resp.Data
+		}
+
+		status, result := scan(`{"token": "badge-token", "method": "nfc"}`)
+		if status != http.StatusCreated || result.AlreadyCheckedIn || result.EmployeeID != 7 || result.Method != store.CheckInNFC {
+			t.Errorf("first scan: %d %+v, want Alex checked in by NFC", status, result)
+		}
+
+		status, result = scan(`{"token": "badge-token"}`)
+		if status != http.StatusOK || !result.AlreadyCheckedIn {
+			t.Errorf("second scan: %d %+v, want already checked in", status, result)
+		}
+
+		if status, _ := scan(`{"token": "someone-elses"}`); status != http.StatusBadRequest {
+			t.Errorf("unknown badge: status %d, want 400", status)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -22,23 +23,34 @@ type CreateEventPayload struct {
 	EmployeeIDs []int64 `json:"employee_ids,omitempty"`
 }
 
+// UpdateEventPayload is a merge patch: null clears the description and the
+// assigned employees; the title, date and times can't be cleared
 type UpdateEventPayload struct {
-	Title       *string `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
-	Description *string `json:"description,omitempty"`
-	Date        *string `json:"date,omitempty"`
-	StartTime   *string `json:"start_time,omitempty"`
-	EndTime     *string `json:"end_time,omitempty"`
-	EmployeeIDs []int64 `json:"employee_ids,omitempty"`
+	Title       Patch[string]  `json:"title" validate:"omitempty,min=1,max=255" swaggertype:"string"`
+	Description Patch[string]  `json:"description" swaggertype:"string"`
+	Date        Patch[string]  `json:"date" swaggertype:"string"`
+	StartTime   Patch[string]  `json:"start_time" swaggertype:"string"`
+	EndTime     Patch[string]  `json:"end_time" swaggertype:"string"`
+	EmployeeIDs Patch[[]int64] `json:"employee_ids" validate:"omitempty,dive,gt=0" swaggertype:"array,integer"`
 }
 
 type AssignEventEmployeesPayload struct {
 	EmployeeIDs []int64 `json:"employee_ids" validate:"required,dive,gt=0"`
 }
 
+type CreateEventShiftPayload struct {
+	RoleID     int64  `json:"role_id" validate:"required,gt=0"`
+	EmployeeID *int64 `json:"employee_id,omitempty" validate:"omitempty,gt=0"`
+	// StartTime and EndTime default to the event's own times
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+	Notes     string `json:"notes,omitempty"`
+}
+
 // GetEvents godoc
 //
 //	@Summary		Lists restaurant's events
-//	@Description	Fetches all events for a restaurant, optionally filtered by date range
+//	@Description	Fetches all events for a restaurant, optionally filtered by date range, with their linked shifts and staffing coverage
 //	@Tags			event
 //	@Accept			json
 //	@Produce		json
@@ -58,9 +70,9 @@ func (app *application) getEventsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -69,13 +81,6 @@ func (app *application) getEventsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Check for optional date range query params
 	startDateStr := r.URL.Query().Get("start_date")
 	endDateStr := r.URL.Query().Get("end_date")
@@ -108,7 +113,7 @@ func (app *application) getEventsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, events); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusOK, events); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -136,9 +141,9 @@ func (app *application) createEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -147,13 +152,6 @@ func (app *application) createEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	var payload CreateEventPayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -205,20 +203,13 @@ func (app *application) createEventHandler(w http.ResponseWriter, r *http.Reques
 	// Assign employees if provided
 	if len(payload.EmployeeIDs) > 0 {
 		// Verify all employees belong to this restaurant
-		for _, empID := range payload.EmployeeIDs {
-			emp, err := app.store.Employees.GetByID(r.Context(), empID)
-			if err != nil {
-				if errors.Is(err, store.ErrNotFound) {
-					app.badRequestResponse(w, r, errors.New("one or more employees do not exist"))
-					return
-				}
-				app.internalServerError(w, r, err)
-				return
-			}
-			if emp.RestaurantID != restaurantID {
-				app.badRequestResponse(w, r, errors.New("one or more employees do not belong to this restaurant"))
+		if err := app.validateEventEmployees(r.Context(), restaurantID, payload.EmployeeIDs); err != nil {
+			if errors.Is(err, errEventEmployeesMissing) || errors.Is(err, errEventEmployeesForeign) {
+				app.badRequestResponse(w, r, err)
 				return
 			}
+			app.internalServerError(w, r, err)
+			return
 		}
 
 		if err := app.store.Events.AssignEmployees(r.Context(), event.ID, payload.EmployeeIDs); err != nil {
@@ -227,7 +218,7 @@ func (app *application) createEventHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	if err = app.jsonResponse(w, http.StatusCreated, event); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusCreated, event); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -235,7 +226,7 @@ func (app *application) createEventHandler(w http.ResponseWriter, r *http.Reques
 // GetEvent godoc
 //
 //	@Summary		Fetches an event
-//	@Description	Fetches an event by ID
+//	@Description	Fetches an event by ID with its linked shifts and staffing coverage
 //	@Tags			event
 //	@Accept			json
 //	@Produce		json
@@ -260,9 +251,9 @@ func (app *application) getEventHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -271,13 +262,6 @@ func (app *application) getEventHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -294,7 +278,7 @@ func (app *application) getEventHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, event); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusOK, event); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -302,9 +286,9 @@ func (app *application) getEventHandler(w http.ResponseWriter, r *http.Request)
 // UpdateEvent godoc
 //
 //	@Summary		Updates an event
-//	@Description	Updates an event by ID
+//	@Description	Updates an event by ID as a JSON merge patch (RFC 7386): fields left out are unchanged, null clears the description, and employee_ids replaces the assigned employees (null or [] removes them all)
 //	@Tags			event
-//	@Accept			json
+//	@Accept			json,application/merge-patch+json
 //	@Produce		json
 //	@Param			restaurant_id	path		int					true	"Restaurant ID"
 //	@Param			eventID			path		int					true	"Event ID"
@@ -329,9 +313,9 @@ func (app *application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -340,13 +324,6 @@ func (app *application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing event
 	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
@@ -376,9 +353,19 @@ func (app *application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if err := errors.Join(
+		payload.Title.NotNull("title"),
+		payload.Date.NotNull("date"),
+		payload.StartTime.NotNull("start_time"),
+		payload.EndTime.NotNull("end_time"),
+	); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	// Update fields if provided
-	if payload.Title != nil {
-		trimmedTitle := strings.TrimSpace(*payload.Title)
+	if payload.Title.Present() {
+		trimmedTitle := strings.TrimSpace(payload.Title.Value)
 		if trimmedTitle == "" {
 			app.badRequestResponse(w, r, errors.New("title cannot be empty or whitespace only"))
 			return
@@ -386,37 +373,35 @@ func (app *application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		event.Title = trimmedTitle
 	}
 
-	if payload.Description != nil {
-		event.Description = *payload.Description
-	}
+	payload.Description.Apply(&event.Description)
 
 	// Store original values for validation
 	date := event.Date
 	startTime := event.StartTime
 	endTime := event.EndTime
 
-	if payload.Date != nil {
-		if _, err := time.Parse("2006-01-02", *payload.Date); err != nil {
+	if payload.Date.Present() {
+		if _, err := time.Parse("2006-01-02", payload.Date.Value); err != nil {
 			app.badRequestResponse(w, r, errors.New("invalid date format, use YYYY-MM-DD"))
 			return
 		}
-		date = store.DateOnly(*payload.Date)
+		date = store.DateOnly(payload.Date.Value)
 	}
 
-	if payload.StartTime != nil {
-		if _, err := time.Parse("15:04", *payload.StartTime); err != nil {
+	if payload.StartTime.Present() {
+		if _, err := time.Parse("15:04", payload.StartTime.Value); err != nil {
 			app.badRequestResponse(w, r, errors.New("invalid start time format, use 24-hour format (HH:MM)"))
 			return
 		}
-		startTime = store.TimeOfDay(*payload.StartTime)
+		startTime = store.TimeOfDay(payload.StartTime.Value)
 	}
 
-	if payload.EndTime != nil {
-		if _, err := time.Parse("15:04", *payload.EndTime); err != nil {
+	if payload.EndTime.Present() {
+		if _, err := time.Parse("15:04", payload.EndTime.Value); err != nil {
 			app.badRequestResponse(w, r, errors.New("invalid end time format, use 24-hour format (HH:MM)"))
 			return
 		}
-		endTime = store.TimeOfDay(*payload.EndTime)
+		endTime = store.TimeOfDay(payload.EndTime.Value)
 	}
 
 	// Ensure end time is after start time
@@ -436,32 +421,27 @@ func (app *application) updateEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Update employee assignments if provided
-	if payload.EmployeeIDs != nil {
+	// Replace employee assignments if provided; null or [] removes them all
+	if payload.EmployeeIDs.Set {
+		employeeIDs := payload.EmployeeIDs.Value
+
 		// Verify all employees belong to this restaurant
-		for _, empID := range payload.EmployeeIDs {
-			emp, err := app.store.Employees.GetByID(r.Context(), empID)
-			if err != nil {
-				if errors.Is(err, store.ErrNotFound) {
-					app.badRequestResponse(w, r, errors.New("one or more employees do not exist"))
-					return
-				}
-				app.internalServerError(w, r, err)
-				return
-			}
-			if emp.RestaurantID != restaurantID {
-				app.badRequestResponse(w, r, errors.New("one or more employees do not belong to this restaurant"))
+		if err := app.validateEventEmployees(r.Context(), restaurantID, employeeIDs); err != nil {
+			if errors.Is(err, errEventEmployeesMissing) || errors.Is(err, errEventEmployeesForeign) {
+				app.badRequestResponse(w, r, err)
 				return
 			}
+			app.internalServerError(w, r, err)
+			return
 		}
 
-		if err := app.store.Events.ReplaceEmployees(r.Context(), event.ID, payload.EmployeeIDs); err != nil {
+		if err := app.store.Events.ReplaceEmployees(r.Context(), event.ID, employeeIDs); err != nil {
 			app.internalServerError(w, r, err)
 			return
 		}
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, event); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusOK, event); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -494,9 +474,9 @@ func (app *application) deleteEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -505,13 +485,6 @@ func (app *application) deleteEventHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing event to verify ownership
 	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
@@ -570,9 +543,9 @@ func (app *application) getEventEmployeesHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -581,13 +554,6 @@ func (app *application) getEventEmployeesHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get the event to verify it belongs to this restaurant
 	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
@@ -615,7 +581,7 @@ func (app *application) getEventEmployeesHandler(w http.ResponseWriter, r *http.
 		employees = []*store.Employee{}
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, employees); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusOK, employees); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -650,9 +616,9 @@ func (app *application) assignEventEmployeesHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -661,13 +627,6 @@ func (app *application) assignEventEmployeesHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Verify event exists and belongs to restaurant
 	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
@@ -697,20 +656,13 @@ func (app *application) assignEventEmployeesHandler(w http.ResponseWriter, r *ht
 	}
 
 	// Verify all employees exist and belong to this restaurant
-	for _, empID := range payload.EmployeeIDs {
-		emp, err := app.store.Employees.GetByID(r.Context(), empID)
-		if err != nil {
-			if errors.Is(err, store.ErrNotFound) {
-				app.badRequestResponse(w, r, errors.New("one or more employees do not exist"))
-				return
-			}
-			app.internalServerError(w, r, err)
-			return
-		}
-		if emp.RestaurantID != restaurantID {
-			app.badRequestResponse(w, r, errors.New("one or more employees do not belong to this restaurant"))
+	if err := app.validateEventEmployees(r.Context(), restaurantID, payload.EmployeeIDs); err != nil {
+		if errors.Is(err, errEventEmployeesMissing) || errors.Is(err, errEventEmployeesForeign) {
+			app.badRequestResponse(w, r, err)
 			return
 		}
+		app.internalServerError(w, r, err)
+		return
 	}
 
 	if err := app.store.Events.AssignEmployees(r.Context(), eventID, payload.EmployeeIDs); err != nil {
@@ -756,8 +708,19 @@ func (app *application) removeEventEmployeeHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if restaurant exists and user has access
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Verify event exists and belongs to restaurant
+	event, err := app.store.Events.GetByID(r.Context(), eventID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
@@ -767,10 +730,64 @@ func (app *application) removeEventEmployeeHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if user owns this restaurant
+	if event.RestaurantID != restaurantID {
+		app.notFoundResponse(w, r, errors.New("event not found"))
+		return
+	}
+
+	// Remove employee from event
+	err = app.store.Events.RemoveEmployee(r.Context(), eventID, employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("employee is not assigned to this event"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateEventShift godoc
+//
+//	@Summary		Creates a shift for an event
+//	@Description	Creates a scheduled shift linked to the event, on the event's date and by default at its times, in the schedule covering that date. With employee_id, the employee must hold the role and its required certifications, or it answers 409.
+//	@Tags			event
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int						true	"Restaurant ID"
+//	@Param			eventID			path		int						true	"Event ID"
+//	@Param			payload			body		CreateEventShiftPayload	true	"Shift payload"
+//	@Success		201				{object}	ShiftResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/events/{eventID}/shifts [post]
+func (app *application) createEventShiftHandler(w http.ResponseWriter, r *http.Request) {
+	restaurantID, err := strconv.ParseInt(chi.URLParam(r, "restaurantID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Check if restaurant exists and user has access to it
 	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
 		return
 	}
 
@@ -790,16 +807,176 @@ func (app *application) removeEventEmployeeHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Remove employee from event
-	err = app.store.Events.RemoveEmployee(r.Context(), eventID, employeeID)
+	var payload CreateEventShiftPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	startTime, endTime := event.StartTime, event.EndTime
+	if payload.StartTime != "" {
+		if _, err := time.Parse("15:04", payload.StartTime); err != nil {
+			app.badRequestResponse(w, r, errors.New("invalid start time format, use 24-hour format (HH:MM)"))
+			return
+		}
+		startTime = store.TimeOfDay(payload.StartTime)
+	}
+
+	if payload.EndTime != "" {
+		if _, err := time.Parse("15:04", payload.EndTime); err != nil {
+			app.badRequestResponse(w, r, errors.New("invalid end time format, use 24-hour format (HH:MM)"))
+			return
+		}
+		endTime = store.TimeOfDay(payload.EndTime)
+	}
+
+	if startTime >= endTime {
+		app.badRequestResponse(w, r, errors.New("end time must be after start time"))
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), payload.RoleID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if role == nil || role.RestaurantID != restaurantID {
+		app.badRequestResponse(w, r, errors.New("role does not belong to this restaurant"))
+		return
+	}
+
+	if payload.EmployeeID != nil {
+		if err := app.validateEventEmployees(r.Context(), restaurantID, []int64{*payload.EmployeeID}); err != nil {
+			if errors.Is(err, errEventEmployeesMissing) || errors.Is(err, errEventEmployeesForeign) {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	schedule, err := app.store.Schedules.GetByDate(r.Context(), restaurantID, event.Date)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, errors.New("employee is not assigned to this event"))
+			app.conflictResponse(w, r, errors.New("no schedule covers the event's date, create one first"))
 			return
 		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	shiftDate, err := event.Date.ToTime()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	shift := &store.ScheduledShift{
+		ScheduleID:   schedule.ID,
+		RestaurantID: restaurantID,
+		RoleID:       payload.RoleID,
+		EmployeeID:   payload.EmployeeID,
+		ShiftDate:    shiftDate,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Notes:        payload.Notes,
+		EventID:      &event.ID,
+	}
+
+	hours, err := app.operatingHoursCheck(r.Context(), restaurantID, shift.ShiftDate, shift.ShiftDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	outsideHours := hours.violation(shift)
+	if outsideHours != nil && hours.blocks() {
+		app.badRequestResponse(w, r, outsideHours)
+		return
+	}
+
+	var violations []ComplianceViolation
+	if shift.EmployeeID != nil {
+		var ok bool
+		if violations, ok = app.checkAssignment(w, r, shift, *shift.EmployeeID); !ok {
+			return
+		}
+	}
+
+	if err := app.store.ScheduledShifts.Create(r.Context(), shift); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.auditShiftsCreated(r.Context(), user.ID, shift)
+
+	if outsideHours != nil {
+		shift.Warnings = append(shift.Warnings, store.WarningOutsideHours)
+	}
+	app.warnComplianceViolations(shift, violations)
+
+	if err = app.jsonResponse(w, r, http.StatusCreated, newShiftResponse(shift)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+var (
+	errShiftEventMissing = errors.New("event not found in this restaurant")
+	errShiftEventDate    = errors.New("a shift linked to an event must be on the event's date")
+)
+
+// validateShiftEvent checks a shift can be linked to the event
+func (app *application) validateShiftEvent(ctx context.Context, restaurantID, eventID int64, shiftDate time.Time) error {
+	event, err := app.store.Events.GetByID(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return errShiftEventMissing
+		}
+		return err
+	}
+
+	if event.RestaurantID != restaurantID {
+		return errShiftEventMissing
+	}
+
+	if shiftDate.Format("2006-01-02") != event.Date.String() {
+		return errShiftEventDate
+	}
+
+	return nil
+}
+
+var (
+	errEventEmployeesMissing = errors.New("one or more employees do not exist")
+	errEventEmployeesForeign = errors.New("one or more employees do not belong to this restaurant")
+)
+
+// validateEventEmployees checks in a single query that every ID is an employee of the restaurant
+func (app *application) validateEventEmployees(ctx context.Context, restaurantID int64, employeeIDs []int64) error {
+	employees, err := app.store.Employees.GetByIDs(ctx, employeeIDs)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[int64]bool, len(employees))
+	for _, emp := range employees {
+		if emp.RestaurantID != restaurantID {
+			return errEventEmployeesForeign
+		}
+		found[emp.ID] = true
+	}
+
+	for _, empID := range employeeIDs {
+		if !found[empID] {
+			return errEventEmployeesMissing
+		}
+	}
+
+	return nil
 }
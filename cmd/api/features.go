@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/billing"
+	"github.com/balebbae/RESA/internal/env"
+	"github.com/balebbae/RESA/internal/features"
+)
+
+type RestaurantFeaturesResponse struct {
+	Plan     billing.Plan           `json:"plan"`
+	Features map[features.Flag]bool `json:"features"`
+}
+
+// GetRestaurantFeatures godoc
+//
+//	@Summary		Lists restaurant's feature flags
+//	@Description	Resolves feature flags for a restaurant from its plan tier and configured overrides
+//	@Tags			restaurant
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Restaurant ID"
+//	@Success		200	{object}	RestaurantFeaturesResponse
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{id}/features [get]
+func (app *application) getRestaurantFeaturesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	plan, err := app.featurePlan(r.Context(), restaurant.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := RestaurantFeaturesResponse{
+		Plan:     plan,
+		Features: app.features.Resolve(plan, restaurant.ID, restaurant.UserID),
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// requireFeature rejects requests to a restaurant route when the flag is off for that restaurant
+func (app *application) requireFeature(flag features.Flag, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.featureAllowed(w, r, flag) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// featureAllowed checks the flag for the restaurant in the request, answering
+// 403 and returning false when it's off, for handlers where only part of the
+// work is behind it
+func (app *application) featureAllowed(w http.ResponseWriter, r *http.Request, flag features.Flag) bool {
+	if app.features == nil {
+		return true
+	}
+
+	restaurant := getRestaurantFromContext(r)
+
+	plan, err := app.featurePlan(r.Context(), restaurant.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+
+	if !app.features.Enabled(flag, plan, restaurant.ID, restaurant.UserID) {
+		app.forbiddenResponse(w, r, fmt.Errorf("feature %s is not enabled for this restaurant", flag))
+		return false
+	}
+	return true
+}
+
+// featurePlan is the plan tier used for flag defaults; without billing every account gets the top tier
+func (app *application) featurePlan(ctx context.Context, userID int64) (billing.Plan, error) {
+	if app.billing == nil {
+		return billing.PlanBusiness, nil
+	}
+	return app.effectivePlan(ctx, userID)
+}
+
+// loadFeatureConfig reads flag overrides from the environment
+func loadFeatureConfig() (features.Config, error) {
+	var cfg features.Config
+	var err error
+
+	if cfg.Enabled, err = features.ParseList(env.GetString("FEATURES_ENABLED", "")); err != nil {
+		return cfg, err
+	}
+	if cfg.Disabled, err = features.ParseList(env.GetString("FEATURES_DISABLED", "")); err != nil {
+		return cfg, err
+	}
+	if cfg.Restaurants, err = features.ParseOverrides(env.GetString("FEATURES_RESTAURANT_OVERRIDES", "")); err != nil {
+		return cfg, err
+	}
+	if cfg.Users, err = features.ParseOverrides(env.GetString("FEATURES_USER_OVERRIDES", "")); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/pkg/schedulingpb"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// schedulingServer serves the scheduling gRPC API on top of the same store as the HTTP handlers
+type schedulingServer struct {
+	schedulingpb.UnimplementedSchedulingServiceServer
+	app *application
+}
+
+func (app *application) newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(app.grpcAuthInterceptor))
+	schedulingpb.RegisterSchedulingServiceServer(server, &schedulingServer{app: app})
+	return server
+}
+
+// grpcAuthInterceptor is the gRPC counterpart of AuthTokenMiddleware
+func (app *application) grpcAuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := app.grpcMaintenance(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is missing")
+	}
+
+	parts := strings.Split(values[0], " ") // authorization: Bearer <token>
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is malformed")
+	}
+
+	jwtToken, err := app.authenticator.ValidateToken(parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	claims, _ := jwtToken.Claims.(jwt.MapClaims)
+
+	userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	user, err := app.store.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if _, err := app.checkSession(ctx, claims, user.ID); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(context.WithValue(ctx, userCtx, user), req)
+}
+
+func (s *schedulingServer) ListShifts(ctx context.Context, req *schedulingpb.ListShiftsRequest) (*schedulingpb.ListShiftsResponse, error) {
+	if _, err := s.authorizeSchedule(ctx, req.GetRestaurantId(), req.GetScheduleId()); err != nil {
+		return nil, err
+	}
+
+	shifts, err := s.app.store.ScheduledShifts.ListBySchedule(ctx, req.GetScheduleId())
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	response := &schedulingpb.ListShiftsResponse{Shifts: make([]*schedulingpb.Shift, 0, len(shifts))}
+	for _, shift := range shifts {
+		response.Shifts = append(response.Shifts, shiftToProto(shift))
+	}
+
+	return response, nil
+}
+
+func (s *schedulingServer) CreateShift(ctx context.Context, req *schedulingpb.CreateShiftRequest) (*schedulingpb.Shift, error) {
+	// Writes read from the primary, as primaryForWritesMiddleware does for HTTP
+	ctx = store.UsePrimary(ctx)
+	if _, err := s.authorizeSchedule(ctx, req.GetRestaurantId(), req.GetScheduleId()); err != nil {
+		return nil, err
+	}
+	if err := s.rejectArchived(ctx, req.GetRestaurantId()); err != nil {
+		return nil, err
+	}
+
+	shiftDate, err := time.Parse("2006-01-02", req.GetShiftDate())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "shift date must be in format YYYY-MM-DD")
+	}
+	if _, err := time.Parse("15:04", req.GetStartTime()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "start time must be in format HH:MM")
+	}
+	if _, err := time.Parse("15:04", req.GetEndTime()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "end time must be in format HH:MM")
+	}
+	if req.GetStartTime() >= req.GetEndTime() {
+		return nil, status.Error(codes.InvalidArgument, "end time must be after start time")
+	}
+
+	shift := &store.ScheduledShift{
+		ScheduleID:      req.GetScheduleId(),
+		RestaurantID:    req.GetRestaurantId(),
+		ShiftTemplateID: req.ShiftTemplateId,
+		RoleID:          req.GetRoleId(),
+		EmployeeID:      req.EmployeeId,
+		ShiftDate:       shiftDate,
+		StartTime:       store.TimeOfDay(req.GetStartTime()),
+		EndTime:         store.TimeOfDay(req.GetEndTime()),
+		Notes:           req.GetNotes(),
+	}
+
+	if shift.EmployeeID != nil {
+		if _, err := s.app.vetAssignment(ctx, shift, *shift.EmployeeID); err != nil {
+			return nil, s.grpcError(err)
+		}
+	}
+
+	if err := s.app.store.ScheduledShifts.Create(ctx, shift); err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	created, err := s.app.store.ScheduledShifts.GetByID(ctx, shift.ID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	s.app.retireResponses(ctx, created.RestaurantID)
+	s.app.auditShiftsCreated(ctx, userFromContext(ctx).ID, created)
+
+	return shiftToProto(created), nil
+}
+
+func (s *schedulingServer) AssignEmployee(ctx context.Context, req *schedulingpb.AssignEmployeeRequest) (*schedulingpb.Shift, error) {
+	ctx = store.UsePrimary(ctx)
+	user := userFromContext(ctx)
+	if err := s.app.checkRestaurantAccess(ctx, req.GetRestaurantId(), user.ID); err != nil {
+		return nil, s.grpcError(err)
+	}
+	if err := s.rejectArchived(ctx, req.GetRestaurantId()); err != nil {
+		return nil, err
+	}
+
+	shift, err := s.app.store.ScheduledShifts.GetByID(ctx, req.GetShiftId())
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	if shift.RestaurantID != req.GetRestaurantId() {
+		return nil, status.Error(codes.NotFound, "shift not found")
+	}
+
+	// There's no override over gRPC; locked shifts are changed by the owner over HTTP with override_lock
+	restaurant, err := s.app.store.Restaurants.GetByID(ctx, shift.RestaurantID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	locked, err := s.app.lockedShift(ctx, restaurant, shift)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	if locked != nil {
+		return nil, status.Error(codes.FailedPrecondition, shiftLockError(restaurant, locked).Error())
+	}
+
+	if req.EmployeeId != nil {
+		assigned := *shift
+		assigned.Training = false
+		if _, err := s.app.vetAssignment(ctx, &assigned, req.GetEmployeeId()); err != nil {
+			return nil, s.grpcError(err)
+		}
+	}
+
+	if err := s.app.store.ScheduledShifts.AssignEmployee(ctx, shift.ID, store.ShiftAssignment{EmployeeID: req.EmployeeId}); err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	updated, err := s.app.store.ScheduledShifts.GetByID(ctx, shift.ID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	s.app.retireResponses(ctx, updated.RestaurantID)
+	s.app.auditShiftChanged(ctx, user.ID, shift, updated)
+
+	return shiftToProto(updated), nil
+}
+
+func (s *schedulingServer) PublishSchedule(ctx context.Context, req *schedulingpb.PublishScheduleRequest) (*schedulingpb.Schedule, error) {
+	ctx = store.UsePrimary(ctx)
+	schedule, err := s.authorizeSchedule(ctx, req.GetRestaurantId(), req.GetScheduleId())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rejectArchived(ctx, req.GetRestaurantId()); err != nil {
+		return nil, err
+	}
+
+	if schedule.PublishedAt != nil {
+		return nil, status.Error(codes.FailedPrecondition, "schedule is already published")
+	}
+
+	violations, err := s.app.scheduleCompliance(ctx, schedule)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	if blocking := blockingViolations(violations); len(blocking) > 0 {
+		return nil, status.Error(codes.FailedPrecondition, complianceError(blocking).Error())
+	}
+
+	// There's no force over gRPC; schedules over budget are published over HTTP with force
+	restaurant, err := s.app.store.Restaurants.GetByID(ctx, schedule.RestaurantID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	if restaurant.WeeklyLaborBudgetCents != nil {
+		cost, err := s.app.scheduleLaborCost(ctx, restaurant, schedule)
+		if err != nil {
+			return nil, s.grpcError(err)
+		}
+		if cost.OverBudget() {
+			return nil, status.Error(codes.FailedPrecondition, laborBudgetError(cost).Error())
+		}
+	}
+
+	if err := s.app.store.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	s.app.retireResponses(ctx, schedule.RestaurantID)
+
+	published, err := s.app.store.Schedules.GetByID(ctx, schedule.ID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	if s.app.config.redisCfg.enabled && s.app.cacheStorage.Schedules != nil {
+		if err := s.app.cacheStorage.Schedules.Set(ctx, published); err != nil {
+			s.app.logger.Warnw("failed to update published schedule in cache", "schedule_id", published.ID, "error", err)
+		}
+	}
+
+	s.app.notifySchedulePublished(ctx, published, userFromContext(ctx).ID)
+
+	return scheduleToProto(published), nil
+}
+
+// authorizeSchedule checks the caller owns the restaurant and the schedule belongs to it
+func (s *schedulingServer) authorizeSchedule(ctx context.Context, restaurantID, scheduleID int64) (*store.Schedule, error) {
+	user := userFromContext(ctx)
+	if err := s.app.checkRestaurantAccess(ctx, restaurantID, user.ID); err != nil {
+		return nil, s.grpcError(err)
+	}
+
+	schedule, err := s.app.store.Schedules.GetByID(ctx, scheduleID)
+	if err != nil {
+		return nil, s.grpcError(err)
+	}
+	if schedule.RestaurantID != restaurantID {
+		return nil, status.Error(codes.NotFound, "schedule not found")
+	}
+
+	return schedule, nil
+}
+
+// rejectArchived refuses changes to archived restaurants, as checkRestaurantOwnership does over HTTP
+func (s *schedulingServer) rejectArchived(ctx context.Context, restaurantID int64) error {
+	restaurant, err := s.app.store.Restaurants.GetByID(ctx, restaurantID)
+	if err != nil {
+		return s.grpcError(err)
+	}
+	if restaurant.Archived() {
+		return status.Error(codes.FailedPrecondition, errRestaurantArchived.Error())
+	}
+	return nil
+}
+
+// grpcError maps store errors to gRPC status codes, hiding internal details like internalServerError does
+func (s *schedulingServer) grpcError(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return status.Error(codes.NotFound, "not found")
+	}
+	var refused *assignmentRefusedError
+	if errors.Is(err, store.ErrRoleMismatch) || errors.As(err, &refused) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, store.ErrShiftOutsideSchedule) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.app.logger.Errorw("grpc internal error", "error", err.Error())
+	return status.Error(codes.Internal, "the server encountered a problem")
+}
+
+func userFromContext(ctx context.Context) *store.User {
+	user, _ := ctx.Value(userCtx).(*store.User)
+	return user
+}
+
+func shiftToProto(shift *store.ScheduledShift) *schedulingpb.Shift {
+	return &schedulingpb.Shift{
+		Id:              shift.ID,
+		ScheduleId:      shift.ScheduleID,
+		RestaurantId:    shift.RestaurantID,
+		ShiftTemplateId: shift.ShiftTemplateID,
+		RoleId:          shift.RoleID,
+		EmployeeId:      shift.EmployeeID,
+		ShiftDate:       shift.ShiftDate.Format("2006-01-02"),
+		StartTime:       string(shift.StartTime),
+		EndTime:         string(shift.EndTime),
+		Notes:           shift.Notes,
+		EmployeeName:    shift.EmployeeName,
+		RoleName:        shift.RoleName,
+		RoleColor:       shift.RoleColor,
+	}
+}
+
+func scheduleToProto(schedule *store.Schedule) *schedulingpb.Schedule {
+	pb := &schedulingpb.Schedule{
+		Id:           schedule.ID,
+		RestaurantId: schedule.RestaurantID,
+		StartDate:    schedule.StartDate.String(),
+		EndDate:      schedule.EndDate.String(),
+	}
+	if schedule.PublishedAt != nil {
+		pb.PublishedAt = schedule.PublishedAt.Format(time.RFC3339)
+	}
+	return pb
+}
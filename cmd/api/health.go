@@ -13,6 +13,14 @@ func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Reques
 		"version": version,
 	}
 
+	// v1 has always answered health checks without an envelope
+	if requestAPIVersion(r) != apiV1 {
+		if err := app.jsonResponse(w, r, http.StatusOK, data); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
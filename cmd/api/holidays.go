@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/balebbae/RESA/internal/integrations/holidays"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	// maxHolidayNameRunes is the longest special date name the hours exceptions keep
+	maxHolidayNameRunes = 100
+	// holidayFetchTimeout bounds one call to the holiday provider
+	holidayFetchTimeout = 5 * time.Second
+)
+
+// holidayYears are the years whose holidays restaurants get: this one, and
+// the next so its schedules can be planned ahead
+func holidayYears(now time.Time) []int {
+	return []int{now.Year(), now.Year() + 1}
+}
+
+// holidayLookup fetches each region's holidays for a year only once
+type holidayLookup struct {
+	provider holidays.Provider
+	fetched  map[string][]holidays.Holiday
+}
+
+func newHolidayLookup(provider holidays.Provider) *holidayLookup {
+	return &holidayLookup{provider: provider, fetched: make(map[string][]holidays.Holiday)}
+}
+
+func (l *holidayLookup) get(ctx context.Context, region string, year int) ([]holidays.Holiday, error) {
+	key := fmt.Sprintf("%s/%d", region, year)
+	if days, ok := l.fetched[key]; ok {
+		return days, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, holidayFetchTimeout)
+	defer cancel()
+
+	days, err := l.provider.Holidays(ctx, region, year)
+	if err != nil {
+		return nil, err
+	}
+	l.fetched[key] = days
+	return days, nil
+}
+
+func (app *application) runHolidayPopulation(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		added, err := app.populateHolidays(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("holiday population failed", "error", err)
+			continue
+		}
+
+		if added > 0 {
+			app.logger.Infow("added public holidays", "count", added)
+		}
+	}
+}
+
+// populateHolidays adds the public holidays of this year and the next to the
+// special dates of every restaurant with a holiday region that doesn't have
+// them yet, returning how many it added. A restaurant that fails is retried on
+// the next run.
+func (app *application) populateHolidays(ctx context.Context, now time.Time) (int, error) {
+	lookup := newHolidayLookup(app.holidays)
+
+	total := 0
+	for _, year := range holidayYears(now) {
+		calendars, err := app.store.OperatingHours.ListHolidayCalendars(ctx, year)
+		if err != nil {
+			return total, err
+		}
+
+		for _, calendar := range calendars {
+			added, err := app.addHolidays(ctx, lookup, calendar, year, now)
+			if err != nil {
+				app.logger.Warnw("failed to add public holidays", "restaurant_id", calendar.RestaurantID, "region", calendar.Region, "year", year, "error", err)
+				continue
+			}
+			total += added
+		}
+	}
+	return total, nil
+}
+
+// addRestaurantHolidays adds the holidays of a restaurant that just picked its
+// holiday region, so they show without waiting for the background job, which
+// retries whatever fails here
+func (app *application) addRestaurantHolidays(ctx context.Context, lookup *holidayLookup, restaurant *store.Restaurant) {
+	calendar := &store.HolidayCalendar{
+		RestaurantID: restaurant.ID,
+		Region:       restaurant.HolidayRegion,
+		Closed:       restaurant.HolidaysClosed,
+	}
+
+	now := time.Now().UTC()
+	for _, year := range holidayYears(now) {
+		if _, err := app.addHolidays(ctx, lookup, calendar, year, now); err != nil {
+			app.logger.Warnw("failed to add public holidays", "restaurant_id", restaurant.ID, "region", calendar.Region, "year", year, "error", err)
+		}
+	}
+}
+
+// addHolidays adds a year of the calendar's holidays as special dates, named
+// in the region's language
+func (app *application) addHolidays(ctx context.Context, lookup *holidayLookup, calendar *store.HolidayCalendar, year int, now time.Time) (int, error) {
+	days, err := lookup.get(ctx, calendar.Region, year)
+	if err != nil {
+		return 0, err
+	}
+
+	exceptions := make([]*store.HoursException, 0, len(days))
+	for _, d := range days {
+		name := d.LocalName
+		if name == "" {
+			name = d.Name
+		}
+		if runes := []rune(name); len(runes) > maxHolidayNameRunes {
+			name = string(runes[:maxHolidayNameRunes])
+		}
+		exceptions = append(exceptions, &store.HoursException{
+			Date:   store.DateOnly(d.Date),
+			Name:   name,
+			Closed: calendar.Closed,
+		})
+	}
+
+	return app.store.OperatingHours.AddHolidays(ctx, calendar, year, exceptions, dateOnly(now))
+}
+
+// holidayNames maps the dates of public holidays among the exceptions to their names
+func holidayNames(exceptions []*store.HoursException) map[store.DateOnly]string {
+	names := make(map[store.DateOnly]string)
+	for _, ex := range exceptions {
+		if ex.HolidayRegion != "" {
+			names[ex.Date] = ex.Name
+		}
+	}
+	return names
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/integrations/holidays"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type fakeHolidayProvider struct {
+	calls int
+}
+
+func (p *fakeHolidayProvider) Holidays(_ context.Context, region string, year int) ([]holidays.Holiday, error) {
+	p.calls++
+	if region == "ZZ" {
+		return nil, holidays.ErrUnknownRegion
+	}
+	return []holidays.Holiday{
+		{Date: time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), LocalName: "Neujahr", Name: "New Year's Day"},
+	}, nil
+}
+
+func TestPopulateHolidays(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	provider := &fakeHolidayProvider{}
+	app.holidays = provider
+
+	type addition struct {
+		calendar store.HolidayCalendar
+		year     int
+		holidays []*store.HoursException
+	}
+	var added []addition
+	app.store.OperatingHours = &store.MockOperatingHoursStorer{
+		ListHolidayCalendarsFunc: func(_ context.Context, year int) ([]*store.HolidayCalendar, error) {
+			return []*store.HolidayCalendar{
+				{RestaurantID: 1, Region: "DE"},
+				{RestaurantID: 2, Region: "DE", Closed: true},
+			}, nil
+		},
+		AddHolidaysFunc: func(_ context.Context, calendar *store.HolidayCalendar, year int, holidays []*store.HoursException, _ store.DateOnly) (int, error) {
+			added = append(added, addition{*calendar, year, holidays})
+			return len(holidays), nil
+		},
+	}
+
+	total, err := app.populateHolidays(context.Background(), time.Date(2026, 11, 20, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// this year's and next year's, fetched once per region and year
+	if total != 4 || len(added) != 4 || provider.calls != 2 {
+		t.Fatalf("total = %d, added = %+v, provider calls = %d", total, added, provider.calls)
+	}
+	if a := added[0]; a.year != 2026 || a.holidays[0].Date != "2026-01-01" || a.holidays[0].Name != "Neujahr" || a.holidays[0].Closed {
+		t.Errorf("first addition = %+v %+v, want New Year's Day 2026 on regular hours", a, a.holidays[0])
+	}
+	if a := added[3]; a.year != 2027 || a.calendar.RestaurantID != 2 || !a.holidays[0].Closed {
+		t.Errorf("last addition = %+v %+v, want a 2027 closure for restaurant 2", a, a.holidays[0])
+	}
+}
+
+func TestUpdateRestaurantHolidayRegion(t *testing.T) {
+	setup := func(t *testing.T) (*application, *int) {
+		app, mocks := newMockedApplication(t, testUserID)
+		app.holidays = &fakeHolidayProvider{}
+		mocks.restaurants.UpdateFunc = func(context.Context, *store.Restaurant) error { return nil }
+		years := 0
+		app.store.OperatingHours = &store.MockOperatingHoursStorer{
+			AddHolidaysFunc: func(_ context.Context, calendar *store.HolidayCalendar, _ int, holidays []*store.HoursException, _ store.DateOnly) (int, error) {
+				years++
+				return len(holidays), nil
+			},
+		}
+		return app, &years
+	}
+
+	t.Run("adds the region's holidays straight away", func(t *testing.T) {
+		app, years := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1", `{"holiday_region": "de-by"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data store.Restaurant `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.HolidayRegion != "DE-BY" {
+			t.Errorf("holiday_region = %q, want DE-BY", body.Data.HolidayRegion)
+		}
+		if *years != 2 {
+			t.Errorf("added %d years of holidays, want this year's and next", *years)
+		}
+	})
+
+	for _, region := range []string{"Bavaria", "ZZ"} {
+		t.Run("rejects "+region, func(t *testing.T) {
+			app, years := setup(t)
+
+			rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1", `{"holiday_region": "`+region+`"}`), app.mount())
+
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+			if *years != 0 {
+				t.Errorf("added %d years of holidays, want none", *years)
+			}
+		})
+	}
+}
+
+func TestScheduleEmailNamesHolidays(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.mailer = &fakeMailer{}
+	app.store.Employees = &store.MockEmployeeStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+			return &store.Employee{ID: id, RestaurantID: 1, FullName: "Ana Diaz", Email: "ana@example.com"}, nil
+		},
+	}
+	mockScheduleEmailStores(app, nil)
+	app.store.OperatingHours.(*store.MockOperatingHoursStorer).ListExceptionsFunc = func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+		return []*store.HoursException{
+			{Date: "2026-03-04", Name: "Inventory", Closed: true},
+			{Date: "2026-03-06", Name: "Founders' Day", HolidayRegion: "US"},
+		}, nil
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/schedules/5/email-preview?employee_id=7", ""), app.mount())
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	var response struct {
+		Data ScheduleEmailPreview `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	html := response.Data.HTML
+	if !strings.Contains(html, "Public Holidays") || !strings.Contains(html, "Founders&#39; Day") {
+		t.Errorf("html does not name the holiday: %s", html)
+	}
+}
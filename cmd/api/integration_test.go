@@ -0,0 +1,364 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/auth"
+	"github.com/balebbae/RESA/internal/features"
+	"github.com/balebbae/RESA/internal/integration"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"go.uber.org/zap"
+)
+
+// testEnv is nil when docker isn't available, and the integration tests skip
+var testEnv *integration.Env
+
+func TestMain(m *testing.M) {
+	env, err := integration.Start(context.Background())
+	switch {
+	case errors.Is(err, integration.ErrNoDocker):
+		fmt.Fprintln(os.Stderr, "skipping api integration tests:", err)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	default:
+		testEnv = env
+	}
+
+	code := m.Run()
+	if testEnv != nil {
+		if err := testEnv.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	os.Exit(code)
+}
+
+// recordingMailer keeps sent emails instead of calling SendGrid
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (m *recordingMailer) Send(templateFile, username, email string, data any, isSandbox bool) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, email)
+	return http.StatusAccepted, nil
+}
+
+func (m *recordingMailer) SendTracked(templateFile, username, email string, data any, isSandbox bool, trackingID string) (int, error) {
+	return m.Send(templateFile, username, email, data, isSandbox)
+}
+
+func (m *recordingMailer) SendWithAttachments(templateFile, username, email string, data any, isSandbox bool, attachments []mailer.Attachment) (int, error) {
+	return m.Send(templateFile, username, email, data, isSandbox)
+}
+
+// newIntegrationApplication builds the app on the containers' Postgres and
+// Redis, with the Redis caches turned on
+func newIntegrationApplication(t *testing.T) (*application, *recordingMailer) {
+	t.Helper()
+	if testEnv == nil {
+		t.Skip("docker is not available")
+	}
+
+	mail := &recordingMailer{}
+	app := &application{
+		config: config{
+			env:         "test",
+			frontendURL: "http://localhost:5173",
+			mail:        mailConfig{exp: time.Hour},
+			auth: authConfig{
+				token: tokenConfig{secret: "integration-test", exp: time.Hour, iss: "resa", aud: "resa"},
+			},
+			redisCfg: redisConfig{enabled: true},
+		},
+		store:         store.NewStorage(testEnv.DB),
+		cacheStorage:  cache.NewRedisStorage(testEnv.Redis),
+		logger:        zap.NewNop().Sugar(),
+		mailer:        mail,
+		authenticator: auth.NewJWTAuthenticator("integration-test", "resa", "resa"),
+		features:      features.NewResolver(features.Config{}),
+	}
+	return app, mail
+}
+
+// call sends body as JSON and decodes the response's data into out, when given
+func call(t *testing.T, mux http.Handler, method, target, token, body string, wantStatus int, out any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rr := executeRequest(req, mux)
+	if rr.Code != wantStatus {
+		t.Fatalf("%s %s: got %d, want %d: %s", method, target, rr.Code, wantStatus, rr.Body.String())
+	}
+
+	if out != nil {
+		envelope := struct {
+			Data any `json:"data"`
+		}{Data: out}
+		if err := json.NewDecoder(rr.Body).Decode(&envelope); err != nil {
+			t.Fatalf("%s %s: decoding response: %v", method, target, err)
+		}
+	}
+}
+
+// TestSchedulingFlow walks an owner from registration to a published
+// schedule: register, activate, log in, create a restaurant, a role and a
+// shift template, create a week's schedule, auto-populate it and publish it
+func TestSchedulingFlow(t *testing.T) {
+	app, mail := newIntegrationApplication(t)
+	mux := app.mount()
+	ctx := context.Background()
+
+	email := fmt.Sprintf("flow-%d@example.com", time.Now().UnixNano())
+
+	var registered UserWithToken
+	call(t, mux, http.MethodPost, "/v1/authentication/user", "",
+		fmt.Sprintf(`{"email":%q,"first_name":"Flo","last_name":"Owner","password":"secret-password"}`, email),
+		http.StatusCreated, &registered)
+	if len(mail.sent) != 1 || mail.sent[0] != email {
+		t.Fatalf("welcome emails = %v, want one to %s", mail.sent, email)
+	}
+
+	// inactive users can't log in yet
+	call(t, mux, http.MethodPost, "/v1/authentication/token", "",
+		fmt.Sprintf(`{"email":%q,"password":"secret-password"}`, email), http.StatusUnauthorized, nil)
+
+	call(t, mux, http.MethodPut, "/v1/users/activate/"+registered.Token, "", "", http.StatusNoContent, nil)
+
+	var token string
+	call(t, mux, http.MethodPost, "/v1/authentication/token", "",
+		fmt.Sprintf(`{"email":%q,"password":"secret-password"}`, email), http.StatusCreated, &token)
+
+	var restaurant store.Restaurant
+	call(t, mux, http.MethodPost, "/v1/restaurants", token,
+		`{"name":"Flow Diner","address":"2 Flow Ave"}`, http.StatusCreated, &restaurant)
+	base := fmt.Sprintf("/v1/restaurants/%d", restaurant.ID)
+
+	var role store.Role
+	call(t, mux, http.MethodPost, base+"/roles", token, `{"name":"Server"}`, http.StatusCreated, &role)
+
+	// 2026-06-01 is a Monday
+	var template store.ShiftTemplate
+	call(t, mux, http.MethodPost, base+"/shift-templates", token,
+		fmt.Sprintf(`{"name":"Monday lunch","day_of_week":1,"start_time":"11:00","end_time":"15:00","role_ids":[%d]}`, role.ID),
+		http.StatusCreated, &template)
+
+	var schedule store.Schedule
+	call(t, mux, http.MethodPost, base+"/schedules", token,
+		`{"start_date":"2026-06-01","end_date":"2026-06-07"}`, http.StatusCreated, &schedule)
+	scheduleURL := fmt.Sprintf("%s/schedules/%d", base, schedule.ID)
+
+	var populated struct {
+		CreatedCount int     `json:"created_count"`
+		CreatedIDs   []int64 `json:"created_ids"`
+	}
+	// a dry run previews the shift without creating it
+	var preview autoPopulatePreview
+	call(t, mux, http.MethodPost, scheduleURL+"/auto-populate?dry_run=true", token, "", http.StatusOK, &preview)
+	if !preview.DryRun || preview.WouldCreateCount != 1 || len(preview.Shifts) != 1 {
+		t.Fatalf("dry run = %+v, want one shift previewed", preview)
+	}
+
+	call(t, mux, http.MethodPost, scheduleURL+"/auto-populate", token, "", http.StatusOK, &populated)
+	if populated.CreatedCount != 1 {
+		t.Fatalf("auto-populate created %d shifts, want 1", populated.CreatedCount)
+	}
+
+	// a second run finds the shift already there
+	call(t, mux, http.MethodPost, scheduleURL+"/auto-populate", token, "", http.StatusOK, &populated)
+	if populated.CreatedCount != 0 {
+		t.Errorf("second auto-populate created %d shifts, want 0", populated.CreatedCount)
+	}
+
+	var shifts []store.ScheduledShift
+	call(t, mux, http.MethodGet, scheduleURL+"/shifts", token, "", http.StatusOK, &shifts)
+	if len(shifts) != 1 {
+		t.Fatalf("schedule has %d shifts, want 1", len(shifts))
+	}
+	if shifts[0].RoleID != role.ID || shifts[0].ShiftTemplateID == nil || *shifts[0].ShiftTemplateID != template.ID {
+		t.Errorf("shift = %+v, want role %d from template %d", shifts[0], role.ID, template.ID)
+	}
+
+	call(t, mux, http.MethodPost, scheduleURL+"/publish", token, "", http.StatusNoContent, nil)
+	call(t, mux, http.MethodPost, scheduleURL+"/publish", token, "", http.StatusBadRequest, nil)
+
+	published, err := app.store.Schedules.GetByID(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.PublishedAt == nil {
+		t.Fatal("schedule has no publish time in the database")
+	}
+
+	// publishing refreshes the cached schedule
+	cached, err := app.cacheStorage.Schedules.Get(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached == nil || cached.PublishedAt == nil {
+		t.Errorf("cached schedule = %+v, want it published", cached)
+	}
+
+	// routes check the owner of the restaurant they loaded; a check outside
+	// them, as over gRPC, fills the ownership cache
+	if err := app.checkRestaurantAccess(ctx, restaurant.ID, registered.ID); err != nil {
+		t.Fatal(err)
+	}
+	ownership, err := app.cacheStorage.Ownership.Get(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ownership == nil || ownership.OwnerID != registered.ID {
+		t.Errorf("cached ownership = %+v, want owner %d", ownership, registered.ID)
+	}
+}
+
+func TestOtherOwnersCannotSeeTheSchedule(t *testing.T) {
+	app, _ := newIntegrationApplication(t)
+	mux := app.mount()
+	ctx := context.Background()
+
+	owner, intruder := integrationUser(t, app), integrationUser(t, app)
+
+	restaurant := &store.Restaurant{UserID: owner.ID, Name: "Private Kitchen", Address: "3 Closed Rd"}
+	if err := app.store.Restaurants.Create(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := app.generateTokenForUser(intruder, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := fmt.Sprintf("/v1/restaurants/%d", restaurant.ID)
+	call(t, mux, http.MethodGet, base+"/schedules", token, "", http.StatusNotFound, nil)
+	call(t, mux, http.MethodPost, base+"/roles", token, `{"name":"Server"}`, http.StatusNotFound, nil)
+	call(t, mux, http.MethodPost, base+"/schedules", token,
+		`{"start_date":"2026-06-01","end_date":"2026-06-07"}`, http.StatusNotFound, nil)
+}
+
+// TestTwoFactorSignIn sets up an authenticator app and signs in with it: the
+// password only earns a challenge, a code can't be replayed, and each
+// recovery code and challenge works once
+func TestTwoFactorSignIn(t *testing.T) {
+	app, _ := newIntegrationApplication(t)
+	mux := app.mount()
+
+	user := integrationUser(t, app)
+	token, err := app.generateTokenForUser(user, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var enrollment TwoFactorEnrollment
+	call(t, mux, http.MethodPost, "/v1/users/me/two-factor/enroll", token, "", http.StatusCreated, &enrollment)
+
+	now := time.Now()
+	code, err := auth.TOTPCode(enrollment.Secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recovery RecoveryCodes
+	call(t, mux, http.MethodPost, "/v1/users/me/two-factor/confirm", token,
+		fmt.Sprintf(`{"code":%q}`, code), http.StatusOK, &recovery)
+	if len(recovery.Codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(recovery.Codes), recoveryCodeCount)
+	}
+
+	signIn := func(t *testing.T) string {
+		t.Helper()
+		var challenge TwoFactorChallenge
+		call(t, mux, http.MethodPost, "/v1/authentication/token", "",
+			fmt.Sprintf(`{"email":%q,"password":"secret-password"}`, user.Email), http.StatusAccepted, &challenge)
+		if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" {
+			t.Fatalf("challenge = %+v", challenge)
+		}
+		return challenge.ChallengeToken
+	}
+	verify := func(challenge, code string) string {
+		return fmt.Sprintf(`{"challenge_token":%q,"code":%q}`, challenge, code)
+	}
+
+	challenge := signIn(t)
+	// the code that confirmed the app was used up
+	call(t, mux, http.MethodPost, "/v1/authentication/token/verify", "", verify(challenge, code), http.StatusUnauthorized, nil)
+	var signedIn string
+	call(t, mux, http.MethodPost, "/v1/authentication/token/verify", "", verify(challenge, recovery.Codes[0]), http.StatusCreated, &signedIn)
+	call(t, mux, http.MethodGet, "/v1/users/me/two-factor", signedIn, "", http.StatusOK, nil)
+	call(t, mux, http.MethodPost, "/v1/authentication/token/verify", "", verify(challenge, recovery.Codes[1]), http.StatusUnauthorized, nil)
+
+	challenge = signIn(t)
+	call(t, mux, http.MethodPost, "/v1/authentication/token/verify", "", verify(challenge, recovery.Codes[0]), http.StatusUnauthorized, nil)
+	next, err := auth.TOTPCode(enrollment.Secret, now.Add(auth.TOTPPeriod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	call(t, mux, http.MethodPost, "/v1/authentication/token/verify", "", verify(challenge, next), http.StatusCreated, nil)
+
+	var status TwoFactorStatus
+	call(t, mux, http.MethodGet, "/v1/users/me/two-factor", token, "", http.StatusOK, &status)
+	if !status.Enrolled || !status.Enabled || status.RecoveryCodesLeft != recoveryCodeCount-1 {
+		t.Errorf("status = %+v", status)
+	}
+
+	// turning codes off brings back the plain token
+	call(t, mux, http.MethodPut, "/v1/users/me/two-factor", token,
+		fmt.Sprintf(`{"enabled":false,"code":%q}`, recovery.Codes[2]), http.StatusNoContent, nil)
+	call(t, mux, http.MethodPost, "/v1/authentication/token", "",
+		fmt.Sprintf(`{"email":%q,"password":"secret-password"}`, user.Email), http.StatusCreated, nil)
+}
+
+// integrationUser creates an active user directly in the store
+func integrationUser(t *testing.T, app *application) *store.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &store.User{
+		Email:     fmt.Sprintf("user-%d@example.com", time.Now().UnixNano()),
+		FirstName: "Int",
+		LastName:  "User",
+	}
+	if err := user.Password.Set("secret-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Activate hashes the plain token it is given, as the handler does
+	token := user.Email
+	if err := app.store.Users.CreateAndInvite(ctx, user, hashToken(token), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.store.Users.Activate(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// hashToken is how invitation tokens are stored
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// invitationRetention is how long expired invitations are kept so their tokens still report as expired rather than invalid
+const invitationRetention = 7 * 24 * time.Hour
+
+// ActivationStatus godoc
+//
+//	@Summary		Checks an invitation token
+//	@Description	Reports whether an activation token is valid, expired, already used or invalid without consuming it, so the frontend can show the right message
+//	@Tags			authentication
+//	@Produce		json
+//	@Param			token	query		string	true	"Invitation token"
+//	@Success		200		{object}	store.ActivationStatus
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/authentication/activation-status [get]
+func (app *application) activationStatusHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		app.badRequestResponse(w, r, errors.New("token is required"))
+		return
+	}
+
+	status, err := app.store.Users.ActivationStatus(r.Context(), token)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, status); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// runInvitationSweep periodically deletes invitations that expired more than invitationRetention ago
+func (app *application) runInvitationSweep(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := app.store.Users.DeleteExpiredInvitations(context.Background(), time.Now().Add(-invitationRetention))
+		if err != nil {
+			app.logger.Errorw("invitation sweep failed", "error", err)
+			continue
+		}
+
+		if deleted > 0 {
+			app.logger.Infow("deleted expired invitations", "count", deleted)
+		}
+	}
+}
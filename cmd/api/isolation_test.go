@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// The tenants of the isolation tests: the caller owns ownRestaurantID, and
+// otherOwnerID owns otherRestaurantID and every record the stores hand back
+const (
+	ownRestaurantID   = 1
+	otherRestaurantID = 2
+	otherOwnerID      = 2
+)
+
+// newOtherTenantApplication builds an app whose every store method succeeds with
+// records belonging to the other tenant, so a handler that misses an ownership
+// check answers with them instead of refusing
+func newOtherTenantApplication(t *testing.T) *application {
+	t.Helper()
+
+	app, mocks := newMockedApplication(t, testUserID)
+	for _, storer := range []any{app.store, app.cacheStorage} {
+		fields := reflect.ValueOf(storer)
+		for i := 0; i < fields.NumField(); i++ {
+			if mock := fields.Field(i); !mock.IsNil() {
+				fillOtherTenant(mock.Elem().Elem())
+			}
+		}
+	}
+
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		owner := int64(otherOwnerID)
+		if id == ownRestaurantID {
+			owner = testUserID
+		}
+		return &store.Restaurant{ID: id, UserID: owner}, nil
+	}
+	members := app.store.Members.(*store.MockMemberStorer)
+	members.GetFunc = func(context.Context, int64, int64) (*store.Member, error) {
+		return nil, store.ErrNotFound
+	}
+	members.ListByRestaurantFunc = func(context.Context, int64) ([]*store.Member, error) {
+		return nil, nil
+	}
+
+	// these find their records by restaurant in the query, so only the other
+	// tenant's restaurant has any
+	members.UpdateRolesFunc = func(_ context.Context, m *store.Member) error {
+		return inOtherRestaurant(m.RestaurantID)
+	}
+	members.DeleteFunc = func(_ context.Context, restaurantID, _ int64) error {
+		return inOtherRestaurant(restaurantID)
+	}
+	app.store.TimeClock.(*store.MockTimeClockStorer).RevokeKioskFunc = func(_ context.Context, restaurantID, _ int64) error {
+		return inOtherRestaurant(restaurantID)
+	}
+	mocks.webhooks.DeleteEndpointFunc = func(_ context.Context, restaurantID, _ int64) error {
+		return inOtherRestaurant(restaurantID)
+	}
+	app.store.DisplayBoards.(*store.MockDisplayBoardStorer).RevokeFunc = func(_ context.Context, restaurantID, _ int64) error {
+		return inOtherRestaurant(restaurantID)
+	}
+	app.store.CalendarFeeds.(*store.MockCalendarFeedStorer).RevokeFunc = func(_ context.Context, restaurantID, _ int64) error {
+		return inOtherRestaurant(restaurantID)
+	}
+
+	// roles named in request bodies are the caller's own, leaving the IDs in
+	// the URL the only ones of the other tenant
+	mocks.roles.GetByIDsFunc = func(_ context.Context, ids []int64) ([]*store.Role, error) {
+		roles := make([]*store.Role, len(ids))
+		for i, id := range ids {
+			roles[i] = &store.Role{ID: id, RestaurantID: ownRestaurantID}
+		}
+		return roles, nil
+	}
+	return app
+}
+
+// inOtherRestaurant is what a store method scoped to restaurantID returns for
+// records of the other tenant
+func inOtherRestaurant(restaurantID int64) error {
+	if restaurantID != otherRestaurantID {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// fillOtherTenant sets every unset Func of a generated mock to one returning
+// otherTenantValue for each result
+func fillOtherTenant(mock reflect.Value) {
+	for i := 0; i < mock.NumField(); i++ {
+		field := mock.Field(i)
+		if field.Kind() != reflect.Func || !field.IsNil() {
+			continue
+		}
+		fn := field.Type()
+		field.Set(reflect.MakeFunc(fn, func([]reflect.Value) []reflect.Value {
+			results := make([]reflect.Value, fn.NumOut())
+			for j := range results {
+				results[j] = otherTenantValue(fn.Out(j))
+			}
+			return results
+		}))
+	}
+}
+
+// otherTenantValue is a value of type t that belongs to the other tenant: structs
+// carry its restaurant and owner, collections are empty and errors nil
+func otherTenantValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Pointer:
+		v := reflect.New(t.Elem())
+		v.Elem().Set(otherTenantValue(t.Elem()))
+		return v
+	case reflect.Struct:
+		v := reflect.New(t).Elem()
+		for name, id := range map[string]int64{"RestaurantID": otherRestaurantID, "UserID": otherOwnerID} {
+			if f := v.FieldByName(name); f.IsValid() && f.CanSet() && f.Kind() == reflect.Int64 {
+				f.SetInt(id)
+			}
+		}
+		return v
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0)
+	case reflect.Map:
+		return reflect.MakeMap(t)
+	default:
+		return reflect.Zero(t)
+	}
+}
+
+// tenantBodies are request bodies for the routes that validate theirs before
+// looking anything up
+var tenantBodies = map[string]string{
+	"PUT /v1/restaurants/{restaurantID}/members/{userID}": `{"role_ids": [1]}`,
+}
+
+// tenantRequest fills in the route's URL parameters, restaurantID with the given
+// restaurant and the rest with IDs that resolve to the other tenant's records
+func tenantRequest(t *testing.T, app *application, method, route string, restaurantID string) *http.Request {
+	t.Helper()
+
+	var path strings.Builder
+	for _, part := range strings.Split(strings.TrimSuffix(route, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		path.WriteString("/")
+		switch part {
+		case "{restaurantID}":
+			path.WriteString(restaurantID)
+		case "{date}":
+			path.WriteString("2026-06-01")
+		case "{kind}":
+			path.WriteString(string(store.RetentionDeleteTimeEntries))
+		default:
+			if strings.HasPrefix(part, "{") {
+				path.WriteString("1")
+			} else {
+				path.WriteString(part)
+			}
+		}
+	}
+
+	body := ""
+	if method != http.MethodGet && method != http.MethodHead && method != http.MethodDelete {
+		body = "{}"
+	}
+	if b, ok := tenantBodies[method+" "+route]; ok {
+		body = b
+	}
+	return authedRequest(t, app, method, path.String(), body)
+}
+
+// TestTenantIsolation calls every route under a restaurant as a user who doesn't
+// own it, then every route with IDs below the restaurant as its owner, with those
+// IDs belonging to the other tenant. Each must refuse with 403 or 404; anything
+// else means the route reads or writes another tenant's data.
+func TestTenantIsolation(t *testing.T) {
+	app := newOtherTenantApplication(t)
+	mux := app.mount()
+
+	type route struct{ method, pattern string }
+	var routes []route
+	err := chi.Walk(mux.(chi.Routes), func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.HasPrefix(pattern, "/v1/restaurants/{restaurantID}") {
+			routes = append(routes, route{method, pattern})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) == 0 {
+		t.Fatal("walked no restaurant routes")
+	}
+
+	t.Run("other owner's restaurant", func(t *testing.T) {
+		for _, rt := range routes {
+			rr := executeRequest(tenantRequest(t, app, rt.method, rt.pattern, "2"), mux)
+			if rr.Code != http.StatusNotFound && rr.Code != http.StatusForbidden {
+				t.Errorf("%s %s: status %d, want 403 or 404", rt.method, rt.pattern, rr.Code)
+			}
+		}
+	})
+
+	t.Run("other owner's records in own restaurant", func(t *testing.T) {
+		for _, rt := range routes {
+			child := strings.TrimPrefix(rt.pattern, "/v1/restaurants/{restaurantID}")
+			child = strings.NewReplacer("{date}", "", "{kind}", "").Replace(child)
+			if !strings.Contains(child, "{") {
+				continue
+			}
+
+			rr := executeRequest(tenantRequest(t, app, rt.method, rt.pattern, "1"), mux)
+			if rr.Code != http.StatusNotFound && rr.Code != http.StatusForbidden {
+				t.Errorf("%s %s: status %d, want 403 or 404", rt.method, rt.pattern, rr.Code)
+			}
+		}
+	})
+}
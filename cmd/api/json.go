@@ -6,13 +6,24 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/balebbae/RESA/internal/i18n"
 	"github.com/go-playground/validator/v10"
 )
 
 var Validate *validator.Validate
 
+// validationTranslator renders Validate's errors in the request's locale
+var validationTranslator *i18n.ValidationTranslator
+
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+	Validate.RegisterCustomTypeFunc(patchValue, patchTypes...)
+
+	var err error
+	validationTranslator, err = i18n.NewValidationTranslator(Validate)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) error {
@@ -39,10 +50,27 @@ func writeJSONError(w http.ResponseWriter, status int, message string) error {
 	return writeJSON(w, status, &envolope{Error: message})
 }
 
-func (app *application) jsonResponse(w http.ResponseWriter, status int, data any) error {
+// jsonResponse writes data in the envelope of the request's API version
+func (app *application) jsonResponse(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	if requestAPIVersion(r) == apiV2 {
+		return writeJSON(w, status, &envelopeV2{Data: data, Meta: newResponseMeta(r)})
+	}
+
 	type envelope struct {
 		Data any `json:"data"`
 	}
 
 	return writeJSON(w, status, &envelope{Data: data})
+}
+
+// errorJSON writes an error message in the envelope of the request's API version
+func (app *application) errorJSON(w http.ResponseWriter, r *http.Request, status int, message string) error {
+	if requestAPIVersion(r) == apiV2 {
+		return writeJSON(w, status, &envelopeV2{
+			Meta:   newResponseMeta(r),
+			Errors: []apiError{{Code: errorCode(status), Message: message}},
+		})
+	}
+
+	return writeJSONError(w, status, message)
 }
\ No newline at end of file
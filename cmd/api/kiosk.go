@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type kioskKey string
+
+const kioskCtx kioskKey = "kiosk"
+
+// kioskPINDigits is the length of the PINs the server generates
+const kioskPINDigits = 6
+
+// Actions a clock request can record
+const (
+	clockActionIn  = "clock_in"
+	clockActionOut = "clock_out"
+)
+
+type CreateKioskPayload struct {
+	Name string `json:"name" validate:"required,min=1,max=100"`
+}
+
+// KioskWithToken is a newly registered kiosk and the token it authenticates
+// with, which is only ever shown here
+type KioskWithToken struct {
+	*store.Kiosk
+	Token string `json:"token"`
+}
+
+// EmployeePIN is an employee's new kiosk PIN, only ever shown when it's set
+type EmployeePIN struct {
+	EmployeeID int64  `json:"employee_id"`
+	PIN        string `json:"pin"`
+}
+
+type ClockPayload struct {
+	EmployeeID int64  `json:"employee_id" validate:"required"`
+	PIN        string `json:"pin" validate:"required,numeric,min=4,max=8"`
+}
+
+// ClockResult is what a clock request recorded
+type ClockResult struct {
+	Action string           `json:"action"`
+	Entry  *store.TimeEntry `json:"entry"`
+}
+
+// CreateKiosk godoc
+//
+//	@Summary		Registers a time clock kiosk
+//	@Description	Registers a shared device employees clock in and out on. The response carries the kiosk's token, which is not shown again; the device sends it as "Authorization: Kiosk <token>".
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		CreateKioskPayload	true	"Kiosk name"
+//	@Success		201				{object}	KioskWithToken
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/kiosks [post]
+func (app *application) createKioskHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload CreateKioskPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	kiosk := &store.Kiosk{RestaurantID: restaurant.ID, Name: strings.TrimSpace(payload.Name)}
+	token := uuid.New().String()
+	if err := app.store.TimeClock.CreateKiosk(r.Context(), kiosk, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, &KioskWithToken{Kiosk: kiosk, Token: token}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetKiosks godoc
+//
+//	@Summary		Lists a restaurant's kiosks
+//	@Description	Lists the restaurant's registered kiosks, revoked ones included, with when each was last used
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.Kiosk
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/kiosks [get]
+func (app *application) getKiosksHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	kiosks, err := app.store.TimeClock.ListKiosks(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, kiosks); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RevokeKiosk godoc
+//
+//	@Summary		Revokes a kiosk
+//	@Description	Stops the kiosk's token from working; time entries it recorded are kept
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			kioskID			path		int	true	"Kiosk ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/kiosks/{kioskID} [delete]
+func (app *application) revokeKioskHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	kioskID, err := strconv.ParseInt(chi.URLParam(r, "kioskID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.TimeClock.RevokeKiosk(r.Context(), restaurant.ID, kioskID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateEmployeePIN godoc
+//
+//	@Summary		Sets a new kiosk PIN for an employee
+//	@Description	Generates a new random 6-digit PIN for the employee, replacing any old one and lifting a lockout. The PIN is only shown in this response.
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{object}	EmployeePIN
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/pin [post]
+func (app *application) rotateEmployeePINHandler(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.restaurantEmployeeFromURL(w, r, getRestaurantFromContext(r).ID)
+	if !ok {
+		return
+	}
+
+	pin, err := generatePIN()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TimeClock.SetPIN(r.Context(), employee.ID, pin); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, &EmployeePIN{EmployeeID: employee.ID, PIN: pin}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteEmployeePIN godoc
+//
+//	@Summary		Removes an employee's kiosk PIN
+//	@Description	Removes the employee's PIN, so they no longer appear on the kiosk or can clock in there
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/pin [delete]
+func (app *application) deleteEmployeePINHandler(w http.ResponseWriter, r *http.Request) {
+	employee, ok := app.restaurantEmployeeFromURL(w, r, getRestaurantFromContext(r).ID)
+	if !ok {
+		return
+	}
+
+	if err := app.store.TimeClock.DeletePIN(r.Context(), employee.ID); err != nil {
+		if errors.Is(err, store.ErrPINNotSet) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetTimeEntries godoc
+//
+//	@Summary		Lists clock-ins
+//	@Description	Lists the time entries clocked in between from and to, inclusive (UTC dates, default the last 7 days). Entries without clock_out_at are still open.
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{array}		store.TimeEntry
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/time-entries [get]
+func (app *application) getTimeEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	today := restaurant.Today(time.Now())
+	from, to, err := parseDateRange(r, today.AddDate(0, 0, -6), today)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	entries, err := app.store.TimeClock.ListTimeEntries(r.Context(), restaurant.ID, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, entries); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetKioskEmployees godoc
+//
+//	@Summary		Lists the employees who can clock in on this kiosk
+//	@Description	Lists the kiosk restaurant's employees who have a PIN, with when the clocked-in ones clocked in. Authenticated with "Authorization: Kiosk <token>".
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{array}		store.KioskEmployee
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		KioskAuth
+//	@Router			/kiosk/employees [get]
+func (app *application) getKioskEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+	kiosk := getKioskFromContext(r)
+
+	employees, err := app.store.TimeClock.ListKioskEmployees(r.Context(), kiosk.RestaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, employees); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// KioskClock godoc
+//
+//	@Summary		Clocks an employee in or out
+//	@Description	Checks the employee's PIN and clocks them out if they're clocked in, in otherwise. After 5 wrong PINs in a row the employee is locked out of the kiosk for 15 minutes. Authenticated with "Authorization: Kiosk <token>".
+//	@Tags			kiosk
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		ClockPayload	true	"Employee and PIN"
+//	@Success		200		{object}	ClockResult
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error	"Wrong PIN"
+//	@Failure		404		{object}	error
+//	@Failure		423		{object}	error	"Locked out after too many wrong PINs"
+//	@Failure		500		{object}	error
+//	@Security		KioskAuth
+//	@Router			/kiosk/clock [post]
+func (app *application) kioskClockHandler(w http.ResponseWriter, r *http.Request) {
+	kiosk := getKioskFromContext(r)
+
+	var payload ClockPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Only the kiosk's own restaurant's employees, so a kiosk can't guess at or
+	// lock out anyone else's PIN
+	employee, err := app.store.Employees.GetByID(r.Context(), payload.EmployeeID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if err != nil || employee.RestaurantID != kiosk.RestaurantID {
+		app.notFoundResponse(w, r, errors.New("employee not found in this restaurant"))
+		return
+	}
+
+	now := time.Now()
+	if err := app.store.TimeClock.VerifyPIN(r.Context(), employee.ID, payload.PIN, now); err != nil {
+		switch {
+		case errors.Is(err, store.ErrPINNotSet):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrPINMismatch):
+			// 403 rather than 401, which would read as the kiosk's own token failing
+			app.forbiddenResponse(w, r, err)
+		case errors.Is(err, store.ErrPINLocked):
+			app.lockedResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	entry, err := app.store.TimeClock.ClockInOrOut(r.Context(), kiosk.RestaurantID, employee.ID, &kiosk.ID, now)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	result := &ClockResult{Action: clockActionIn, Entry: entry}
+	if entry.ClockOutAt != nil {
+		result.Action = clockActionOut
+	}
+	if err := app.jsonResponse(w, r, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// KioskAuthMiddleware authenticates a kiosk by the "Authorization: Kiosk <token>" header
+func (app *application) KioskAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Skip authentication for OPTIONS requests (CORS preflight)
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		parts := strings.Split(r.Header.Get("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != "Kiosk" || parts[1] == "" {
+			app.unauthorizedErrorResponse(w, r, fmt.Errorf("kiosk authorization header is missing or malformed"))
+			return
+		}
+
+		kiosk, err := app.store.TimeClock.AuthenticateKiosk(r.Context(), parts[1])
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.unauthorizedErrorResponse(w, r, errors.New("unknown or revoked kiosk token"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), kioskCtx, kiosk)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getKioskFromContext(r *http.Request) *store.Kiosk {
+	kiosk, _ := r.Context().Value(kioskCtx).(*store.Kiosk)
+	return kiosk
+}
+
+// generatePIN returns a random kioskPINDigits-digit PIN
+func generatePIN() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < kioskPINDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", kioskPINDigits, n), nil
+}
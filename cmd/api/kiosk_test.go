@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const testKioskToken = "kiosk-token"
+
+func kioskRequest(t *testing.T, method, target, body string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Kiosk "+testKioskToken)
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestKioskClock(t *testing.T) {
+	setup := func(t *testing.T, verify error) (*application, *bool) {
+		app, _ := newMockedApplication(t, testUserID)
+		clocked := new(bool)
+		app.store.TimeClock = &store.MockTimeClockStorer{
+			AuthenticateKioskFunc: func(_ context.Context, token string) (*store.Kiosk, error) {
+				if token != testKioskToken {
+					return nil, store.ErrNotFound
+				}
+				return &store.Kiosk{ID: 2, RestaurantID: 3}, nil
+			},
+			VerifyPINFunc: func(context.Context, int64, string, time.Time) error { return verify },
+			ClockInOrOutFunc: func(_ context.Context, restaurantID, employeeID int64, kioskID *int64, now time.Time) (*store.TimeEntry, error) {
+				*clocked = true
+				return &store.TimeEntry{RestaurantID: restaurantID, EmployeeID: employeeID, KioskID: kioskID, ClockInAt: now}, nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				restaurantID := int64(3)
+				if id == 99 {
+					restaurantID = 8
+				}
+				return &store.Employee{ID: id, RestaurantID: restaurantID}, nil
+			},
+		}
+		return app, clocked
+	}
+
+	t.Run("clocks in", func(t *testing.T) {
+		app, clocked := setup(t, nil)
+
+		rr := executeRequest(kioskRequest(t, http.MethodPost, "/v1/kiosk/clock", `{"employee_id": 7, "pin": "123456"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data ClockResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !*clocked || body.Data.Action != clockActionIn || body.Data.Entry.KioskID == nil || *body.Data.Entry.KioskID != 2 {
+			t.Errorf("result = %+v", body.Data)
+		}
+	})
+
+	t.Run("wrong PIN", func(t *testing.T) {
+		app, clocked := setup(t, store.ErrPINMismatch)
+
+		rr := executeRequest(kioskRequest(t, http.MethodPost, "/v1/kiosk/clock", `{"employee_id": 7, "pin": "000000"}`), app.mount())
+
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+		if *clocked {
+			t.Error("clocked with a wrong PIN")
+		}
+	})
+
+	t.Run("locked out", func(t *testing.T) {
+		app, _ := setup(t, store.ErrPINLocked)
+
+		rr := executeRequest(kioskRequest(t, http.MethodPost, "/v1/kiosk/clock", `{"employee_id": 7, "pin": "123456"}`), app.mount())
+
+		checkResponseCode(t, http.StatusLocked, rr.Code)
+	})
+
+	t.Run("another restaurant's employee", func(t *testing.T) {
+		app, _ := setup(t, nil)
+		verified := false
+		app.store.TimeClock.(*store.MockTimeClockStorer).VerifyPINFunc = func(context.Context, int64, string, time.Time) error {
+			verified = true
+			return nil
+		}
+
+		rr := executeRequest(kioskRequest(t, http.MethodPost, "/v1/kiosk/clock", `{"employee_id": 99, "pin": "123456"}`), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+		if verified {
+			t.Error("checked the PIN of another restaurant's employee")
+		}
+	})
+
+	t.Run("unknown kiosk token", func(t *testing.T) {
+		app, _ := setup(t, nil)
+		req := kioskRequest(t, http.MethodGet, "/v1/kiosk/employees", "")
+		req.Header.Set("Authorization", "Kiosk revoked")
+
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("user token is not a kiosk token", func(t *testing.T) {
+		app, _ := setup(t, nil)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/kiosk/employees", ""), app.mount())
+
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestGeneratePIN(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		pin, err := generatePIN()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pin) != kioskPINDigits || strings.Trim(pin, "0123456789") != "" {
+			t.Fatalf("pin = %q, want %d digits", pin, kioskPINDigits)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// LaborCost is a schedule's projected labor cost against the restaurant's budget
+type LaborCost struct {
+	ScheduleID int64 `json:"schedule_id"`
+	// BudgetCents is the weekly budget prorated to the schedule's length; absent without a budget
+	BudgetCents     *int    `json:"budget_cents,omitempty"`
+	TotalCents      int     `json:"total_cents"`
+	OverBudgetCents int     `json:"over_budget_cents"`
+	Hours           float64 `json:"hours"`
+	// UnpricedHours are on shifts that are unassigned or whose employee has no hourly rate
+	UnpricedHours float64        `json:"unpriced_hours"`
+	Days          []LaborCostDay `json:"days"`
+}
+
+// LaborCostDay is one date of a schedule's labor cost
+type LaborCostDay struct {
+	Date          store.DateOnly `json:"date"`
+	Hours         float64        `json:"hours"`
+	CostCents     int            `json:"cost_cents"`
+	UnpricedHours float64        `json:"unpriced_hours"`
+}
+
+// OverBudget reports whether the projected cost exceeds the budget
+func (c *LaborCost) OverBudget() bool {
+	return c.OverBudgetCents > 0
+}
+
+// GetScheduleLaborCost godoc
+//
+//	@Summary		Projects a schedule's labor cost
+//	@Description	Prices every shift at its employee's hourly rate and compares the total to the restaurant's weekly labor budget, prorated to the schedule's length, with a per-day breakdown. Unassigned shifts and employees without a rate count as unpriced hours.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			id				path		int	true	"Schedule ID"
+//	@Success		200				{object}	LaborCost
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/schedules/{id}/labor-cost [get]
+func (app *application) getScheduleLaborCostHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	cost, err := app.scheduleLaborCost(r.Context(), getRestaurantFromContext(r), schedule)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, cost); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// scheduleLaborCost loads the schedule's shifts and the restaurant's hourly rates and prices them
+func (app *application) scheduleLaborCost(ctx context.Context, restaurant *store.Restaurant, schedule *store.Schedule) (*LaborCost, error) {
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[int64]int, len(employees))
+	for _, e := range employees {
+		if e.HourlyRateCents != nil {
+			rates[e.ID] = *e.HourlyRateCents
+		}
+	}
+
+	return laborCost(schedule, shifts, rates, restaurant.WeeklyLaborBudgetCents), nil
+}
+
+// laborCost prices the shifts at their employees' hourly rates, one day entry
+// per date of the schedule
+func laborCost(schedule *store.Schedule, shifts []*store.ScheduledShift, rates map[int64]int, weeklyBudget *int) *LaborCost {
+	cost := &LaborCost{ScheduleID: schedule.ID, Days: []LaborCostDay{}}
+
+	start, errStart := schedule.StartDate.ToTime()
+	end, errEnd := schedule.EndDate.ToTime()
+	index := make(map[store.DateOnly]int)
+	if errStart == nil && errEnd == nil {
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			index[dateOnly(d)] = len(cost.Days)
+			cost.Days = append(cost.Days, LaborCostDay{Date: dateOnly(d)})
+		}
+	}
+
+	for _, shift := range shifts {
+		minutes := shiftMinutes(shift)
+		hours := float64(minutes) / 60
+
+		date := dateOnly(shift.ShiftDate)
+		i, ok := index[date]
+		if !ok {
+			i = len(cost.Days)
+			index[date] = i
+			cost.Days = append(cost.Days, LaborCostDay{Date: date})
+		}
+		day := &cost.Days[i]
+
+		day.Hours += hours
+		cost.Hours += hours
+
+		rate, priced := 0, false
+		if shift.EmployeeID != nil {
+			rate, priced = rates[*shift.EmployeeID]
+		}
+		if !priced {
+			day.UnpricedHours += hours
+			cost.UnpricedHours += hours
+			continue
+		}
+
+		// Round each shift to the cent, half up
+		cents := (minutes*rate + 30) / 60
+		day.CostCents += cents
+		cost.TotalCents += cents
+	}
+
+	if weeklyBudget != nil && len(index) > 0 && errStart == nil && errEnd == nil {
+		days := int(end.Sub(start).Hours()/24) + 1
+		budget := int(math.Round(float64(*weeklyBudget) * float64(days) / 7))
+		cost.BudgetCents = &budget
+		if cost.TotalCents > budget {
+			cost.OverBudgetCents = cost.TotalCents - budget
+		}
+	}
+
+	return cost
+}
+
+// shiftMinutes is how long the shift runs
+func shiftMinutes(shift *store.ScheduledShift) int {
+	start, err := time.Parse("15:04", hourMinute(shift.StartTime))
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse("15:04", hourMinute(shift.EndTime))
+	if err != nil || !end.After(start) {
+		return 0
+	}
+	return int(end.Sub(start).Minutes())
+}
+
+// laborBudgetError describes by how much the schedule is over budget
+func laborBudgetError(cost *LaborCost) error {
+	return fmt.Errorf("projected labor cost of %s is %s over the %s budget",
+		formatCents(cost.TotalCents), formatCents(cost.OverBudgetCents), formatCents(*cost.BudgetCents))
+}
+
+func formatCents(cents int) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestLaborCost(t *testing.T) {
+	cook, server, volunteer := int64(1), int64(2), int64(3)
+	rates := map[int64]int{cook: 2000, server: 1250, volunteer: 0}
+	budget := 70000
+
+	schedule := &store.Schedule{ID: 5, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	monday := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	shifts := []*store.ScheduledShift{
+		{EmployeeID: &cook, ShiftDate: monday, StartTime: "09:00:00", EndTime: "17:00:00"},
+		{EmployeeID: &server, ShiftDate: monday, StartTime: "11:00:00", EndTime: "14:20:00"},
+		{EmployeeID: &volunteer, ShiftDate: monday.AddDate(0, 0, 1), StartTime: "10:00:00", EndTime: "12:00:00"},
+		{ShiftDate: monday.AddDate(0, 0, 2), StartTime: "18:00:00", EndTime: "22:00:00"},
+	}
+
+	cost := laborCost(schedule, shifts, rates, &budget)
+
+	// 8h at $20 plus 3h20m at $12.50 ($41.666... rounds to $41.67)
+	if cost.TotalCents != 16000+4167 {
+		t.Errorf("total = %d cents, want %d", cost.TotalCents, 16000+4167)
+	}
+	if cost.UnpricedHours != 4 {
+		t.Errorf("unpriced hours = %v, want the 4 unassigned hours", cost.UnpricedHours)
+	}
+	if len(cost.Days) != 7 {
+		t.Fatalf("days = %d, want one per date of the schedule", len(cost.Days))
+	}
+	if cost.Days[0].CostCents != 20167 || cost.Days[1].CostCents != 0 || cost.Days[1].Hours != 2 {
+		t.Errorf("days = %+v", cost.Days[:2])
+	}
+	if cost.BudgetCents == nil || *cost.BudgetCents != budget || cost.OverBudget() {
+		t.Errorf("budget = %v, over = %d; want %d and not over", cost.BudgetCents, cost.OverBudgetCents, budget)
+	}
+
+	t.Run("prorates the weekly budget", func(t *testing.T) {
+		short := &store.Schedule{ID: 6, StartDate: "2026-06-01", EndDate: "2026-06-03"}
+		cost := laborCost(short, shifts, rates, &budget)
+		if cost.BudgetCents == nil || *cost.BudgetCents != 30000 {
+			t.Fatalf("budget = %v, want 3/7 of the week", cost.BudgetCents)
+		}
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		small := 14000
+		cost := laborCost(schedule, shifts, rates, &small)
+		if !cost.OverBudget() || cost.OverBudgetCents != 20167-14000 {
+			t.Errorf("over budget by %d, want %d", cost.OverBudgetCents, 20167-14000)
+		}
+	})
+
+	t.Run("no budget", func(t *testing.T) {
+		cost := laborCost(schedule, shifts, rates, nil)
+		if cost.BudgetCents != nil || cost.OverBudget() {
+			t.Errorf("cost = %+v, want no budget", cost)
+		}
+	})
+}
+
+func TestPublishOverLaborBudget(t *testing.T) {
+	employeeID := int64(7)
+	setup := func(t *testing.T) (*application, *bool) {
+		app, mocks := newMockedApplication(t, testUserID)
+		budget := 10000
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID, WeeklyLaborBudgetCents: &budget}, nil
+		}
+
+		published := false
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-06-01", EndDate: "2026-06-07"}, nil
+			},
+			PublishFunc: func(context.Context, int64, time.Time) error {
+				published = true
+				return nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{{
+					EmployeeID: &employeeID,
+					ShiftDate:  time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+					StartTime:  "09:00:00",
+					EndTime:    "17:00:00",
+				}}, nil
+			},
+		}
+		rate := 2000
+		app.store.Employees = &store.MockEmployeeStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) {
+				return []*store.Employee{{ID: employeeID, HourlyRateCents: &rate}}, nil
+			},
+		}
+		app.store.Notifications = &store.MockNotificationStorer{
+			CreateManyFunc: func(context.Context, []*store.Notification) error { return nil },
+		}
+		return app, &published
+	}
+
+	t.Run("refused with the breakdown", func(t *testing.T) {
+		app, published := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/publish", ""), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if *published {
+			t.Error("schedule over budget was published")
+		}
+
+		var body struct {
+			Error     string    `json:"error"`
+			LaborCost LaborCost `json:"labor_cost"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Error == "" || body.LaborCost.OverBudgetCents != 6000 || len(body.LaborCost.Days) != 7 {
+			t.Errorf("response = %+v", body)
+		}
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		app, published := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/publish?force=true", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !*published {
+			t.Error("forced publish did not publish")
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	// leaveAccrualDelay holds a week back from accruing for a day after it ends,
+	// so shifts clocked out late still count
+	leaveAccrualDelay = 24 * time.Hour
+	// maxLeaveBalanceDays bounds the range of the balances report
+	maxLeaveBalanceDays = 366
+)
+
+// LeavePolicyPayload is a restaurant's paid leave accrual: accrual_hours of
+// leave for every per_hours worked or scheduled, a week at a time from
+// accrue_from, a Monday
+type LeavePolicyPayload struct {
+	Enabled      bool    `json:"enabled"`
+	Basis        string  `json:"basis" validate:"required,oneof=worked scheduled"`
+	AccrualHours float64 `json:"accrual_hours" validate:"gt=0,max=100"`
+	PerHours     float64 `json:"per_hours" validate:"gt=0,max=1000"`
+	// MaxBalanceHours caps the balance accruals build up to; 0 for no cap
+	MaxBalanceHours float64 `json:"max_balance_hours" validate:"min=0,max=10000"`
+	AccrueFrom      string  `json:"accrue_from" validate:"required"`
+}
+
+// LeaveEntryPayload records leave paid to the employee, or an adjustment to
+// their balance. Hours are those taken for paid leave, and added (or, when
+// negative, removed) for an adjustment.
+type LeaveEntryPayload struct {
+	Kind  string  `json:"kind" validate:"required,oneof=paid adjustment"`
+	Hours float64 `json:"hours" validate:"required,min=-10000,max=10000"`
+	// Date is the day of leave paid, or the day an adjustment applies from
+	Date string `json:"date" validate:"required"`
+	Note string `json:"note" validate:"max=500"`
+}
+
+// EmployeeLeave is an employee's paid leave balance and its entries
+type EmployeeLeave struct {
+	Balance float64             `json:"balance"`
+	Entries []*store.LeaveEntry `json:"entries"`
+}
+
+// GetLeavePolicy godoc
+//
+//	@Summary		Gets a restaurant's paid leave policy
+//	@Description	Returns how the restaurant's employees accrue paid leave. Restaurants that haven't set one get a disabled policy of an hour for every 30 worked.
+//	@Tags			leave
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.LeavePolicy
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/leave-policy [get]
+func (app *application) getLeavePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	policy, err := app.store.Leave.GetPolicy(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateLeavePolicy godoc
+//
+//	@Summary		Sets a restaurant's paid leave policy
+//	@Description	Employees accrue accrual_hours of paid leave for every per_hours of their basis: hours clocked on the time clock (worked) or assigned in published schedules (scheduled). Each week, Monday to Sunday, from accrue_from accrues a day after it ends, up to max_balance_hours when that isn't 0. Changing the policy applies to weeks not yet accrued.
+//	@Tags			leave
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		LeavePolicyPayload	true	"Leave policy"
+//	@Success		200				{object}	store.LeavePolicy
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/leave-policy [put]
+func (app *application) updateLeavePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload LeavePolicyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	accrueFrom, err := time.Parse("2006-01-02", payload.AccrueFrom)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("accrue_from must be in format YYYY-MM-DD"))
+		return
+	}
+	if accrueFrom.Weekday() != time.Monday {
+		app.badRequestResponse(w, r, errors.New("accrue_from must be a Monday"))
+		return
+	}
+
+	policy := &store.LeavePolicy{
+		RestaurantID:    restaurant.ID,
+		Enabled:         payload.Enabled,
+		Basis:           store.LeaveBasis(payload.Basis),
+		AccrualHours:    payload.AccrualHours,
+		PerHours:        payload.PerHours,
+		MaxBalanceHours: payload.MaxBalanceHours,
+		AccrueFrom:      dateOnly(accrueFrom),
+	}
+	if err := app.store.Leave.ReplacePolicy(r.Context(), policy); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AccrueLeave godoc
+//
+//	@Summary		Accrues paid leave now
+//	@Description	Accrues the weeks that ended over a day ago and haven't been, as the background job does every hour, and returns the new accruals. A disabled policy accrues nothing.
+//	@Tags			leave
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.LeaveEntry
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/leave-accruals [post]
+func (app *application) accrueLeaveHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	accrued, err := app.store.Leave.AccrueThrough(r.Context(), restaurant.ID, time.Now().UTC().Add(-leaveAccrualDelay))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, accrued); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetLeaveBalances godoc
+//
+//	@Summary		Reports employees' paid leave
+//	@Description	Each employee's paid leave balance now, with the hours accrued, paid and adjusted dated from through to (default the 4 weeks up to today, at most 366 days), for payroll. Accruals are dated by the Monday of their week.
+//	@Tags			leave
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{array}		store.LeaveBalance
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/leave-balances [get]
+func (app *application) getLeaveBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	today := restaurant.Today(time.Now())
+	from, to, err := parseDateRange(r, today.AddDate(0, 0, -27), today)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if to.Sub(from) >= maxLeaveBalanceDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("the range can be at most %d days", maxLeaveBalanceDays))
+		return
+	}
+
+	balances, err := app.store.Leave.ListBalances(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, balances); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetEmployeeLeave godoc
+//
+//	@Summary		Gets an employee's paid leave
+//	@Description	The employee's paid leave balance and every accrual, leave paid and adjustment behind it, newest first.
+//	@Tags			leave
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{object}	EmployeeLeave
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/leave [get]
+func (app *application) getEmployeeLeaveHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	entries, err := app.store.Leave.ListEntries(r.Context(), employee.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	leave := &EmployeeLeave{Entries: entries}
+	for _, e := range entries {
+		leave.Balance += e.Hours
+	}
+	leave.Balance = roundHours(leave.Balance)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, leave); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateLeaveEntry godoc
+//
+//	@Summary		Records paid leave or an adjustment
+//	@Description	Deducts the hours of paid leave taken on a day from the employee's balance, refusing with 409 if they haven't that many, or adjusts the balance by the hours given. The entry goes in the audit log.
+//	@Tags			leave
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			employeeID		path		int					true	"Employee ID"
+//	@Param			payload			body		LeaveEntryPayload	true	"Leave entry"
+//	@Success		201				{object}	store.LeaveEntry
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/leave [post]
+func (app *application) createLeaveEntryHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	var payload LeaveEntryPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", payload.Date)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("date must be in format YYYY-MM-DD"))
+		return
+	}
+
+	hours := roundHours(payload.Hours)
+	kind := store.LeaveEntryKind(payload.Kind)
+	if kind == store.LeavePaid {
+		if hours <= 0 {
+			app.badRequestResponse(w, r, errors.New("paid leave must be a positive number of hours"))
+			return
+		}
+		hours = -hours
+	}
+
+	user := getUserFromContext(r)
+	entry := &store.LeaveEntry{
+		RestaurantID: restaurant.ID,
+		EmployeeID:   employee.ID,
+		Kind:         kind,
+		Hours:        hours,
+		Date:         dateOnly(date),
+		Note:         payload.Note,
+		CreatedBy:    &user.ID,
+	}
+	if err := app.store.Leave.AddEntry(r.Context(), entry); err != nil {
+		switch {
+		case errors.Is(err, store.ErrInsufficientLeave):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	summary := fmt.Sprintf("Adjusted %s's paid leave by %g hours", employee.FullName, entry.Hours)
+	if kind == store.LeavePaid {
+		summary = fmt.Sprintf("Paid %s %g hours of leave on %s", employee.FullName, -entry.Hours, entry.Date)
+	}
+	app.recordAudit(r.Context(), &store.AuditEntry{
+		RestaurantID: restaurant.ID,
+		EntityType:   store.AuditEntityLeave,
+		EntityID:     entry.ID,
+		Action:       store.AuditCreated,
+		Summary:      summary,
+		ActorUserID:  &user.ID,
+	})
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, entry); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// roundHours rounds to the hundredth of an hour leave is kept in
+func roundHours(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+func (app *application) runLeaveAccrual(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		accrued, err := app.accrueLeave(context.Background(), time.Now().UTC().Add(-leaveAccrualDelay))
+		if err != nil {
+			app.logger.Errorw("leave accrual failed", "error", err)
+			continue
+		}
+
+		if accrued > 0 {
+			app.logger.Infow("accrued paid leave", "count", accrued)
+		}
+	}
+}
+
+// accrueLeave accrues the weeks ended by until for every restaurant with an
+// enabled policy, returning how many accruals it added. A restaurant that
+// fails is retried on the next run.
+func (app *application) accrueLeave(ctx context.Context, until time.Time) (int, error) {
+	restaurants, err := app.store.Leave.ListAccruingRestaurants(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range restaurants {
+		accrued, err := app.store.Leave.AccrueThrough(ctx, id, until)
+		if err != nil {
+			app.logger.Warnw("failed to accrue paid leave", "restaurant_id", id, "error", err)
+			continue
+		}
+		total += len(accrued)
+	}
+	return total, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestLeave(t *testing.T) {
+	setup := func(t *testing.T, balance float64) (*application, *[]*store.LeaveEntry) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				return &store.Employee{ID: id, RestaurantID: 1, FullName: "Alex Smith"}, nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+
+		var added []*store.LeaveEntry
+		app.store.Leave = &store.MockLeaveStorer{
+			AddEntryFunc: func(_ context.Context, e *store.LeaveEntry) error {
+				if e.Kind == store.LeavePaid && balance+e.Hours < 0 {
+					return store.ErrInsufficientLeave
+				}
+				added = append(added, e)
+				return nil
+			},
+			ReplacePolicyFunc: func(context.Context, *store.LeavePolicy) error { return nil },
+		}
+		return app, &added
+	}
+
+	t.Run("paid leave is deducted", func(t *testing.T) {
+		app, added := setup(t, 8)
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/7/leave", `{"kind": "paid", "hours": 7.5, "date": "2026-06-12"}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if len(*added) != 1 || (*added)[0].Hours != -7.5 || (*added)[0].EmployeeID != 7 {
+			t.Errorf("added = %+v, want 7.5 hours deducted from employee 7", *added)
+		}
+	})
+
+	t.Run("paid leave over the balance is refused", func(t *testing.T) {
+		app, added := setup(t, 4)
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/7/leave", `{"kind": "paid", "hours": 8, "date": "2026-06-12"}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if len(*added) != 0 {
+			t.Errorf("added = %+v, want nothing", *added)
+		}
+	})
+
+	t.Run("paid leave must be positive", func(t *testing.T) {
+		app, _ := setup(t, 8)
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees/7/leave", `{"kind": "paid", "hours": -2, "date": "2026-06-12"}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("balance sums the entries", func(t *testing.T) {
+		app, _ := setup(t, 0)
+		app.store.Leave.(*store.MockLeaveStorer).ListEntriesFunc = func(context.Context, int64) ([]*store.LeaveEntry, error) {
+			return []*store.LeaveEntry{
+				{Kind: store.LeavePaid, Hours: -2.5},
+				{Kind: store.LeaveAccrual, Hours: 1.1},
+				{Kind: store.LeaveAccrual, Hours: 2.2},
+			}, nil
+		}
+
+		req := authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/employees/7/leave", "")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data EmployeeLeave `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.Balance != 0.8 || len(body.Data.Entries) != 3 {
+			t.Errorf("leave = %+v, want a balance of 0.8 over 3 entries", body.Data)
+		}
+	})
+
+	t.Run("policy accrues from a Monday", func(t *testing.T) {
+		app, _ := setup(t, 0)
+
+		for from, want := range map[string]int{"2026-06-01": http.StatusOK, "2026-06-03": http.StatusBadRequest} {
+			body := `{"enabled": true, "basis": "worked", "accrual_hours": 1, "per_hours": 30, "accrue_from": "` + from + `"}`
+			req := authedRequest(t, app, http.MethodPut, "/v1/restaurants/1/leave-policy", body)
+			rr := executeRequest(req, app.mount())
+
+			if rr.Code != want {
+				t.Errorf("accrue_from %s: status %d, want %d", from, rr.Code, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type UpdateLocalePayload struct {
+	Locale *string `json:"locale" validate:"omitempty,oneof=en es"`
+}
+
+// requestLocale picks the locale for a response: the signed-in user's setting, then Accept-Language, then the default
+func requestLocale(r *http.Request) i18n.Locale {
+	if user := getUserFromContext(r); user != nil && user.Locale != nil {
+		if locale, ok := i18n.Parse(*user.Locale); ok {
+			return locale
+		}
+	}
+
+	if locale, ok := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language")); ok {
+		return locale
+	}
+
+	return i18n.Default
+}
+
+// UpdateUserLocale godoc
+//
+//	@Summary		Sets the current user's language
+//	@Description	Sets the language used for the user's emails and API messages; null follows the browser's Accept-Language
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	UpdateLocalePayload	true	"Locale (en or es)"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/locale [put]
+func (app *application) updateUserLocaleHandler(w http.ResponseWriter, r *http.Request) {
+	var payload UpdateLocalePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Users.UpdateLocale(r.Context(), user.ID, payload.Locale); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
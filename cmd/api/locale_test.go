@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/i18n"
+)
+
+func TestRequestLocale(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           i18n.Locale
+	}{
+		{"", i18n.English},
+		{"es-MX,es;q=0.9,en;q=0.8", i18n.Spanish},
+		{"fr-FR,fr;q=0.9,es;q=0.5,en;q=0.4", i18n.Spanish},
+		{"en;q=0.2,es;q=0.8", i18n.Spanish},
+		{"de", i18n.English},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+
+		if got := requestLocale(req); got != tt.want {
+			t.Errorf("requestLocale(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestTranslatedValidationErrors(t *testing.T) {
+	app := newTestApplication(t)
+	err := Validate.Struct(CreateEmployeePayload{Email: "not-an-email"})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Accept-Language", "es")
+	app.badRequestResponse(rr, req, err)
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	if body := rr.Body.String(); !strings.Contains(body, "full_name es un campo requerido") {
+		t.Fatalf("expected a Spanish message naming the json field, got %s", body)
+	}
+}
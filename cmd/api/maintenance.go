@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/balebbae/RESA/internal/env"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maintenanceMode is how much of the API maintenance pauses
+type maintenanceMode string
+
+const (
+	maintenanceOff maintenanceMode = "off"
+	// maintenanceReadOnly answers reads and refuses writes
+	maintenanceReadOnly maintenanceMode = "read_only"
+	// maintenanceFull refuses everything but the health check and debug routes
+	maintenanceFull maintenanceMode = "full"
+)
+
+// maintenanceRefresh is how long an instance goes on the switch it last read
+// from Redis before reading it again
+const maintenanceRefresh = 2 * time.Second
+
+// maintenanceConfig is the configured maintenance mode, in effect while no
+// operator has set the switch
+type maintenanceConfig struct {
+	mode       maintenanceMode
+	message    string
+	retryAfter time.Duration
+}
+
+func loadMaintenanceConfig() (maintenanceConfig, error) {
+	cfg := maintenanceConfig{
+		mode:       maintenanceMode(env.GetString("MAINTENANCE_MODE", string(maintenanceOff))),
+		message:    env.GetString("MAINTENANCE_MESSAGE", ""),
+		retryAfter: time.Second * time.Duration(env.GetInt("MAINTENANCE_RETRY_AFTER_SECONDS", 300)),
+	}
+	if !cfg.mode.valid() {
+		return maintenanceConfig{}, fmt.Errorf("unknown MAINTENANCE_MODE %q", cfg.mode)
+	}
+	return cfg, nil
+}
+
+func (m maintenanceMode) valid() bool {
+	return m == maintenanceOff || m == maintenanceReadOnly || m == maintenanceFull
+}
+
+// blocks reports whether the mode refuses a request with the method
+func (m maintenanceMode) blocks(method string) bool {
+	switch m {
+	case maintenanceFull:
+		return true
+	case maintenanceReadOnly:
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, methodPropfind, methodReport:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Maintenance is the maintenance mode in effect and where it comes from
+type Maintenance struct {
+	Mode              maintenanceMode `json:"mode"`
+	Message           string          `json:"message"`
+	RetryAfterSeconds int             `json:"retry_after_seconds"`
+	// Source is "config" for the environment, "switch" once an operator sets it
+	Source string     `json:"source"`
+	SetAt  *time.Time `json:"set_at,omitempty"`
+}
+
+// MaintenancePayload sets the maintenance switch
+type MaintenancePayload struct {
+	Mode              maintenanceMode `json:"mode" validate:"required,oneof=off read_only full"`
+	Message           string          `json:"message" validate:"max=500"`
+	RetryAfterSeconds int             `json:"retry_after_seconds" validate:"omitempty,min=1,max=86400"`
+}
+
+// maintenanceSwitch is the operator's maintenance setting. With Redis it's
+// shared and read through a short-lived copy; without it, it lives here.
+type maintenanceSwitch struct {
+	mu      sync.Mutex
+	value   *cache.Maintenance
+	fetched time.Time
+}
+
+// maintenance returns the maintenance mode in effect: the switch if an operator
+// set it, the configuration otherwise. When Redis can't be read the last
+// switch read stays in effect.
+func (app *application) maintenance(ctx context.Context) *Maintenance {
+	app.maintenanceSwitch.mu.Lock()
+	value := app.maintenanceSwitch.value
+	if shared := app.cacheStorage.Maintenance; shared != nil && time.Since(app.maintenanceSwitch.fetched) > maintenanceRefresh {
+		if fresh, err := shared.Get(ctx); err != nil {
+			app.logger.Warnw("failed to read the maintenance switch", "error", err)
+		} else {
+			value = fresh
+			app.maintenanceSwitch.value = fresh
+		}
+		app.maintenanceSwitch.fetched = time.Now()
+	}
+	app.maintenanceSwitch.mu.Unlock()
+
+	if value != nil {
+		return &Maintenance{
+			Mode:              maintenanceMode(value.Mode),
+			Message:           value.Message,
+			RetryAfterSeconds: value.RetryAfterSeconds,
+			Source:            "switch",
+			SetAt:             &value.SetAt,
+		}
+	}
+
+	cfg := app.settings().maintenance
+	mode := cfg.mode
+	if mode == "" {
+		mode = maintenanceOff
+	}
+	return &Maintenance{
+		Mode:              mode,
+		Message:           cfg.message,
+		RetryAfterSeconds: int(cfg.retryAfter.Seconds()),
+		Source:            "config",
+	}
+}
+
+// setMaintenance stores the switch, or clears it back to the configuration when value is nil
+func (app *application) setMaintenance(ctx context.Context, value *cache.Maintenance) error {
+	if shared := app.cacheStorage.Maintenance; shared != nil {
+		var err error
+		if value == nil {
+			err = shared.Clear(ctx)
+		} else {
+			err = shared.Set(ctx, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	app.maintenanceSwitch.mu.Lock()
+	app.maintenanceSwitch.value = value
+	app.maintenanceSwitch.fetched = time.Now()
+	app.maintenanceSwitch.mu.Unlock()
+	return nil
+}
+
+// maintenanceMiddleware refuses the requests the maintenance mode pauses. The
+// health check and debug routes stay up so operators can watch the API and
+// lift the switch.
+func (app *application) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if operationsPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if m := app.maintenance(r.Context()); m.Mode.blocks(r.Method) {
+			app.maintenanceResponse(w, r, m)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// operationsPath reports whether path is the health check or a debug route, of any API version
+func operationsPath(path string) bool {
+	for _, version := range []string{"/" + string(apiV1), "/" + string(apiV2)} {
+		if rest, ok := strings.CutPrefix(path, version); ok {
+			return rest == "/health" || strings.HasPrefix(rest, "/debug/")
+		}
+	}
+	return false
+}
+
+// grpcMaintenance refuses the gRPC calls the maintenance mode pauses. Calls
+// that only read are named Get or List.
+func (app *application) grpcMaintenance(ctx context.Context, fullMethod string) error {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	httpMethod := http.MethodPost
+	if strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List") {
+		httpMethod = http.MethodGet
+	}
+
+	if m := app.maintenance(ctx); m.Mode.blocks(httpMethod) {
+		return status.Error(codes.Unavailable, maintenanceMessage(m))
+	}
+	return nil
+}
+
+// maintenanceMessage is the message refused requests get
+func maintenanceMessage(m *Maintenance) string {
+	if m.Message != "" {
+		return m.Message
+	}
+	if m.Mode == maintenanceReadOnly {
+		return "the API is read-only for maintenance, please try again later"
+	}
+	return "the API is down for maintenance, please try again later"
+}
+
+func (app *application) getMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.jsonResponse(w, r, http.StatusOK, app.maintenance(r.Context())); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// updateMaintenanceHandler sets the maintenance switch, which overrides the
+// configured mode on every instance until it's cleared
+func (app *application) updateMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	var payload MaintenancePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	value := &cache.Maintenance{
+		Mode:              string(payload.Mode),
+		Message:           payload.Message,
+		RetryAfterSeconds: payload.RetryAfterSeconds,
+		SetAt:             time.Now().UTC(),
+	}
+	if value.RetryAfterSeconds == 0 {
+		value.RetryAfterSeconds = int(app.settings().maintenance.retryAfter.Seconds())
+	}
+
+	if err := app.setMaintenance(r.Context(), value); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.logger.Warnw("maintenance switch set", "mode", value.Mode)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, app.maintenance(r.Context())); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// clearMaintenanceHandler hands maintenance back to the configuration
+func (app *application) clearMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.setMaintenance(r.Context(), nil); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.logger.Warnw("maintenance switch cleared")
+
+	if err := app.jsonResponse(w, r, http.StatusOK, app.maintenance(r.Context())); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
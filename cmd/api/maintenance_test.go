@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store/cache"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	newApp := func(t *testing.T, mode maintenanceMode) *application {
+		app, _ := newMockedApplication(t, testUserID)
+		app.config.auth.basic = basicConfig{user: "ops", pass: "s3cret"}
+		app.runtime.Store(&runtimeSettings{maintenance: maintenanceConfig{mode: mode, retryAfter: 5 * time.Minute}})
+		return app
+	}
+	opsRequest := func(t *testing.T, method, body, pass string) *http.Request {
+		req, err := http.NewRequest(method, "/v1/debug/maintenance", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.SetBasicAuth("ops", pass)
+		return req
+	}
+
+	t.Run("read-only refuses writes and answers reads", func(t *testing.T) {
+		app := newApp(t, maintenanceReadOnly)
+		mux := app.mount()
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants", `{"name":"Bistro"}`), mux)
+		checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+		if got := rr.Header().Get("Retry-After"); got != "300" {
+			t.Errorf("Retry-After = %q, want 300", got)
+		}
+
+		var body struct {
+			Error       string       `json:"error"`
+			Maintenance *Maintenance `json:"maintenance"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Error == "" || body.Maintenance == nil || body.Maintenance.Mode != maintenanceReadOnly {
+			t.Errorf("body = %+v", body)
+		}
+
+		rr = executeRequest(authedRequest(t, app, http.MethodGet, "/v1/rate-limit", ""), mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("full refuses reads but not the health check", func(t *testing.T) {
+		app := newApp(t, maintenanceFull)
+		mux := app.mount()
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v2/rate-limit", ""), mux)
+		checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+		if !strings.Contains(rr.Body.String(), `"code":"maintenance"`) {
+			t.Errorf("v2 body = %s, want a maintenance error", rr.Body.String())
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/health", nil)
+		req.SetBasicAuth("ops", "s3cret")
+		rr = executeRequest(req, mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("the switch overrides the configuration until cleared", func(t *testing.T) {
+		app := newApp(t, maintenanceOff)
+		var shared *cache.Maintenance
+		app.cacheStorage.Maintenance = &cache.MockMaintenanceStorer{
+			GetFunc: func(context.Context) (*cache.Maintenance, error) { return shared, nil },
+			SetFunc: func(_ context.Context, m *cache.Maintenance) error {
+				shared = m
+				return nil
+			},
+			ClearFunc: func(context.Context) error {
+				shared = nil
+				return nil
+			},
+		}
+		mux := app.mount()
+
+		rr := executeRequest(opsRequest(t, http.MethodPut, `{"mode":"full"}`, "wrong"), mux)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+		if shared != nil {
+			t.Fatal("switch set without the right credentials")
+		}
+
+		rr = executeRequest(opsRequest(t, http.MethodPut, `{"mode":"full","message":"Upgrading the database"}`, "s3cret"), mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if shared == nil || shared.Mode != "full" || shared.RetryAfterSeconds != 300 {
+			t.Fatalf("shared switch = %+v", shared)
+		}
+
+		rr = executeRequest(authedRequest(t, app, http.MethodGet, "/v1/rate-limit", ""), mux)
+		checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+		if !strings.Contains(rr.Body.String(), "Upgrading the database") {
+			t.Errorf("body = %s, want the operator's message", rr.Body.String())
+		}
+
+		rr = executeRequest(opsRequest(t, http.MethodDelete, "", "s3cret"), mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		rr = executeRequest(authedRequest(t, app, http.MethodGet, "/v1/rate-limit", ""), mux)
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+}
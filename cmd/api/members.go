@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type AddMemberPayload struct {
+	Email   string  `json:"email" validate:"required,email,max=255"`
+	RoleIDs []int64 `json:"role_ids" validate:"required,min=1"`
+}
+
+type UpdateMemberPayload struct {
+	RoleIDs []int64 `json:"role_ids" validate:"required,min=1"`
+}
+
+// GetMembers godoc
+//
+//	@Summary		Lists a restaurant's members
+//	@Description	Lists the users other than the owner with access to the restaurant. Shift leads see and edit only the shifts of their roles.
+//	@Tags			members
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.Member
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/members [get]
+func (app *application) getMembersHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	members, err := app.store.Members.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, members); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AddMember godoc
+//
+//	@Summary		Adds a shift lead
+//	@Description	Gives an existing user shift lead access to the restaurant for the given roles: they can list, create, edit and assign those roles' shifts and nothing else
+//	@Tags			members
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		AddMemberPayload	true	"User email and roles"
+//	@Success		201				{object}	store.Member
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/members [post]
+func (app *application) addMemberHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload AddMemberPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user, err := app.store.Users.GetByEmail(r.Context(), strings.TrimSpace(payload.Email))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("no user with that email"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if user.ID == restaurant.UserID {
+		app.badRequestResponse(w, r, errors.New("the owner already has full access"))
+		return
+	}
+
+	if !app.checkMemberRoles(w, r, restaurant.ID, payload.RoleIDs) {
+		return
+	}
+
+	member := &store.Member{
+		RestaurantID: restaurant.ID,
+		UserID:       user.ID,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Permission:   store.PermissionShiftLead,
+		RoleIDs:      payload.RoleIDs,
+	}
+	if err := app.store.Members.Create(r.Context(), member); err != nil {
+		if errors.Is(err, store.ErrDuplicateMember) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.invalidateRestaurantAccess(r.Context(), restaurant.ID)
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, member); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateMember godoc
+//
+//	@Summary		Changes a shift lead's roles
+//	@Description	Replaces the roles whose shifts the member manages
+//	@Tags			members
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			userID			path		int					true	"Member's user ID"
+//	@Param			payload			body		UpdateMemberPayload	true	"Roles"
+//	@Success		200				{object}	store.Member
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/members/{userID} [put]
+func (app *application) updateMemberHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid user ID"))
+		return
+	}
+
+	var payload UpdateMemberPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !app.checkMemberRoles(w, r, restaurant.ID, payload.RoleIDs) {
+		return
+	}
+
+	member := &store.Member{RestaurantID: restaurant.ID, UserID: userID, RoleIDs: payload.RoleIDs}
+	if err := app.store.Members.UpdateRoles(r.Context(), member); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.invalidateRestaurantAccess(r.Context(), restaurant.ID)
+
+	updated, err := app.store.Members.Get(r.Context(), restaurant.ID, userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, updated); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Removes a member
+//	@Description	Revokes the user's access to the restaurant
+//	@Tags			members
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			userID			path	int	true	"Member's user ID"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/members/{userID} [delete]
+func (app *application) removeMemberHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid user ID"))
+		return
+	}
+
+	if err := app.store.Members.Delete(r.Context(), restaurant.ID, userID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	app.invalidateRestaurantAccess(r.Context(), restaurant.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMyMemberships godoc
+//
+//	@Summary		Lists the restaurants the current user is a member of
+//	@Description	Lists the restaurants other owners have given the user access to, with the roles they manage; archived restaurants are left out
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{array}		store.Membership
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/memberships [get]
+func (app *application) getMyMembershipsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	memberships, err := app.store.Members.ListByUser(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, memberships); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// checkMemberRoles rejects role IDs that aren't the restaurant's
+func (app *application) checkMemberRoles(w http.ResponseWriter, r *http.Request, restaurantID int64, roleIDs []int64) bool {
+	roles, err := app.store.Roles.GetByIDs(r.Context(), roleIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+
+	found := make(map[int64]bool, len(roles))
+	for _, role := range roles {
+		if role.RestaurantID == restaurantID {
+			found[role.ID] = true
+		}
+	}
+	for _, id := range roleIDs {
+		if !found[id] {
+			app.badRequestResponse(w, r, fmt.Errorf("role %d not found", id))
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Patch is one field of a JSON merge-patch (RFC 7386) body: left out, it
+// leaves the value unchanged; null clears it; anything else sets it.
+// PATCH handlers take application/json and application/merge-patch+json alike.
+type Patch[T any] struct {
+	// Set is whether the field was in the body at all
+	Set bool
+	// Null is whether it was null, clearing the value
+	Null  bool
+	Value T
+}
+
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	p.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		p.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &p.Value)
+}
+
+// Present reports whether the field sets a value
+func (p Patch[T]) Present() bool {
+	return p.Set && !p.Null
+}
+
+// Ptr returns the value set, or nil when the field is left out or null
+func (p Patch[T]) Ptr() *T {
+	if !p.Present() {
+		return nil
+	}
+	v := p.Value
+	return &v
+}
+
+// Apply writes the field to dst: the value set, or the zero value for null
+func (p Patch[T]) Apply(dst *T) {
+	if !p.Set {
+		return
+	}
+	var zero T
+	*dst = zero
+	if !p.Null {
+		*dst = p.Value
+	}
+}
+
+// ApplyPtr writes the field to an optional dst: the value set, or nil for null
+func (p Patch[T]) ApplyPtr(dst **T) {
+	if p.Set {
+		*dst = p.Ptr()
+	}
+}
+
+// NotNull refuses null for a field that can't be cleared
+func (p Patch[T]) NotNull(field string) error {
+	if p.Null {
+		return fmt.Errorf("%s cannot be null", field)
+	}
+	return nil
+}
+
+// validationValue hands Validate the value set, nil when there's none so
+// omitempty skips it
+func (p Patch[T]) validationValue() any {
+	if !p.Present() {
+		return nil
+	}
+	return p.Value
+}
+
+// patchValue lets Validate check a Patch's value against the field's tags
+func patchValue(field reflect.Value) any {
+	return field.Interface().(interface{ validationValue() any }).validationValue()
+}
+
+// patchTypes are the Patch instantiations payloads use, registered with Validate
+var patchTypes = []any{
+	Patch[string]{},
+	Patch[int]{},
+	Patch[int64]{},
+	Patch[time.Time]{},
+	Patch[[]int64]{},
+}
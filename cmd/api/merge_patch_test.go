@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestPatchDecoding(t *testing.T) {
+	var payload struct {
+		Absent  Patch[string]  `json:"absent"`
+		Null    Patch[string]  `json:"null"`
+		Value   Patch[string]  `json:"value" validate:"omitempty,max=5"`
+		Numbers Patch[[]int64] `json:"numbers" validate:"omitempty,dive,gt=0"`
+	}
+	if err := json.Unmarshal([]byte(`{"null": null, "value": "hi", "numbers": [1, 2]}`), &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.Absent.Set || !payload.Null.Set || !payload.Null.Null || !payload.Value.Present() || payload.Value.Value != "hi" {
+		t.Errorf("payload = %+v, want absent unset, null cleared and value set", payload)
+	}
+	if err := Validate.Struct(payload); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+
+	notes := "keep"
+	payload.Absent.Apply(&notes)
+	if notes != "keep" {
+		t.Errorf("notes = %q after an absent field, want it unchanged", notes)
+	}
+	payload.Null.Apply(&notes)
+	if notes != "" {
+		t.Errorf("notes = %q after null, want it cleared", notes)
+	}
+	locale := &notes
+	payload.Null.ApplyPtr(&locale)
+	if locale != nil {
+		t.Errorf("locale = %v after null, want nil", *locale)
+	}
+	if payload.Null.NotNull("null") == nil || payload.Value.NotNull("value") != nil {
+		t.Error("NotNull should refuse only the null field")
+	}
+
+	// the field's tags validate the value set
+	payload.Value.Value = "too long"
+	payload.Numbers.Value = []int64{0}
+	if err := Validate.Struct(payload); err == nil {
+		t.Error("validate passed a value over max and a zero ID")
+	}
+}
+
+func TestMergePatchUpdates(t *testing.T) {
+	shiftApp := func(t *testing.T) (*application, **store.ScheduledShift) {
+		app, _ := newMockedApplication(t, testUserID)
+		employeeID, eventID := int64(7), int64(3)
+		var saved *store.ScheduledShift
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				if saved != nil {
+					return saved, nil
+				}
+				return &store.ScheduledShift{ID: id, ScheduleID: 5, RestaurantID: 1, RoleID: 2, EmployeeID: &employeeID, EventID: &eventID,
+					ShiftDate: time.Now().AddDate(0, 1, 0), StartTime: "09:00", EndTime: "17:00", Notes: "bring keys"}, nil
+			},
+			UpdateFunc: func(_ context.Context, shift *store.ScheduledShift) error {
+				saved = shift
+				return nil
+			},
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 1}, nil
+			},
+		}
+		app.store.OperatingHours = &store.MockOperatingHoursStorer{
+			GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+				return &store.OperatingHours{RestaurantID: restaurantID}, nil
+			},
+			ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+				return nil, nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		return app, &saved
+	}
+
+	t.Run("null unassigns a shift and clears its notes and event", func(t *testing.T) {
+		app, saved := shiftApp(t)
+
+		req := authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5/shifts/42", `{"employee_id": null, "notes": null, "event_id": null}`)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if s := *saved; s == nil || s.EmployeeID != nil || s.EventID != nil || s.Notes != "" || s.StartTime != "09:00" {
+			t.Errorf("saved %+v, want it unassigned, unlinked and without notes, times unchanged", s)
+		}
+	})
+
+	t.Run("a shift's role and times can't be cleared", func(t *testing.T) {
+		app, saved := shiftApp(t)
+
+		req := authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5/shifts/42", `{"role_id": null, "start_time": null}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if *saved != nil {
+			t.Errorf("saved %+v, want nothing", *saved)
+		}
+	})
+
+	t.Run("null removes an event's employees", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+		var replaced []int64
+		replaceCalled := false
+		app.store.Events = &store.MockEventStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Event, error) {
+				return &store.Event{ID: id, RestaurantID: 1, Title: "Wine tasting", Description: "Upstairs", Date: "2026-06-01", StartTime: "18:00", EndTime: "21:00"}, nil
+			},
+			UpdateFunc: func(_ context.Context, event *store.Event) error {
+				if event.Description != "" || event.Title != "Wine tasting" {
+					t.Errorf("event = %+v, want the description cleared and the title kept", event)
+				}
+				return nil
+			},
+			ReplaceEmployeesFunc: func(_ context.Context, _ int64, employeeIDs []int64) error {
+				replaceCalled, replaced = true, employeeIDs
+				return nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDsFunc: func(context.Context, []int64) ([]*store.Employee, error) { return nil, nil },
+		}
+
+		req := authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/events/8", `{"description": null, "employee_ids": null}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !replaceCalled || len(replaced) != 0 {
+			t.Errorf("replaced with %v (called %v), want no employees", replaced, replaceCalled)
+		}
+
+		req = authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/events/8", `{"title": null}`)
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, app.mount()).Code)
+	})
+
+	t.Run("null clears an employee's optional fields", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+		locale, rate, hired := "es", 1800, store.DateOnly("2024-04-01")
+		var saved *store.Employee
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				return &store.Employee{ID: id, RestaurantID: 1, FullName: "Alex Smith", Email: "alex@example.com", Locale: &locale, HourlyRateCents: &rate, HireDate: &hired, Seniority: 3}, nil
+			},
+			UpdateFunc: func(_ context.Context, e *store.Employee) error {
+				saved = e
+				return nil
+			},
+		}
+
+		req := authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/employees/9", `{"locale": null, "hourly_rate_cents": null, "hire_date": null, "seniority": null}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if saved == nil || saved.Locale != nil || saved.HourlyRateCents != nil || saved.HireDate != nil || saved.Seniority != 0 || saved.FullName != "Alex Smith" {
+			t.Errorf("saved %+v, want the optional fields cleared and the name kept", saved)
+		}
+
+		req = authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/employees/9", `{"email": null}`)
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, app.mount()).Code)
+	})
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultMessagesLimit = 50
+	maxMessagesLimit     = 200
+	// maxMessageScheduleDays is how far ahead a message can be scheduled
+	maxMessageScheduleDays = 90
+)
+
+// SendMessagePayload is an announcement to the restaurant's staff. Subject and body may
+// reference {{.FirstName}}, {{.EmployeeName}} and {{.RestaurantName}}.
+type SendMessagePayload struct {
+	Subject string `json:"subject" validate:"required,max=200"`
+	Body    string `json:"body" validate:"required,max=10000"`
+	// Channels defaults to email; in_app also posts each employee a notification
+	Channels []string `json:"channels" validate:"omitempty,unique,dive,oneof=email in_app"`
+	// RoleIDs and EmployeeIDs narrow the recipients to the employees with one of the
+	// roles plus those listed; without either the message goes to everyone
+	RoleIDs     []int64 `json:"role_ids" validate:"omitempty,max=100,unique,dive,min=1"`
+	EmployeeIDs []int64 `json:"employee_ids" validate:"omitempty,max=1000,unique,dive,min=1"`
+	// SendAt schedules the message; omitted or in the past sends it now
+	SendAt *time.Time `json:"send_at"`
+	// Critical emails it straight away even to employees who get digests
+	Critical bool `json:"critical"`
+}
+
+type SendMessageResponse struct {
+	Message *store.Message `json:"message"`
+	// Failures are the emails that couldn't be sent, for a message sent now
+	Failures []SendScheduleEmailFailure `json:"failures"`
+	// Queued counts the emails held for employees' digests
+	Queued int               `json:"queued"`
+	Quota  *cache.EmailQuota `json:"quota,omitempty"`
+}
+
+// AnnouncementEmailData contains the data for the announcement email template
+type AnnouncementEmailData struct {
+	RestaurantName string
+	Subject        string
+	Body           template.HTML
+}
+
+// SendMessage godoc
+//
+//	@Summary		Sends an announcement to staff
+//	@Description	Emails an ad hoc message to every employee, or to those with one of role_ids plus those in employee_ids, and with the in_app channel also posts it as a notification. Subject and body are templates that may use {{.FirstName}}, {{.EmployeeName}} and {{.RestaurantName}}. Employees who get digests find the email in their next one unless the message is critical. With a future send_at (up to 90 days ahead) the message is scheduled instead, its recipients picked when it goes out, and can be canceled until then. Emailing counts against the restaurant's email quota when the message is created.
+//	@Tags			message
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		SendMessagePayload	true	"Message"
+//	@Success		201				{object}	SendMessageResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/messages [post]
+func (app *application) sendMessageHandler(w http.ResponseWriter, r *http.Request) {
+	var payload SendMessagePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	now := time.Now()
+
+	message := &store.Message{
+		RestaurantID: restaurant.ID,
+		CreatedBy:    &user.ID,
+		Subject:      strings.TrimSpace(payload.Subject),
+		Body:         strings.TrimSpace(payload.Body),
+		Channels:     payload.Channels,
+		Critical:     payload.Critical,
+		RoleIDs:      payload.RoleIDs,
+		EmployeeIDs:  payload.EmployeeIDs,
+		Status:       store.MessageSending,
+		SendAt:       now.UTC(),
+	}
+	if len(message.Channels) == 0 {
+		message.Channels = []string{store.MessageChannelEmail}
+	}
+	if message.RoleIDs == nil {
+		message.RoleIDs = []int64{}
+	}
+	if message.EmployeeIDs == nil {
+		message.EmployeeIDs = []int64{}
+	}
+	if payload.SendAt != nil && payload.SendAt.After(now) {
+		if payload.SendAt.After(now.AddDate(0, 0, maxMessageScheduleDays)) {
+			app.badRequestResponse(w, r, fmt.Errorf("send_at must be within %d days", maxMessageScheduleDays))
+			return
+		}
+		message.Status = store.MessageScheduled
+		message.SendAt = payload.SendAt.UTC()
+	}
+
+	// catch template mistakes now rather than for each recipient
+	sample := mailer.AnnouncementVars{RestaurantName: restaurant.Name, EmployeeName: "Alex Smith", FirstName: "Alex"}
+	for field, text := range map[string]string{"subject": message.Subject, "body": message.Body} {
+		if _, err := mailer.RenderAnnouncement(text, sample); err != nil {
+			app.badRequestResponse(w, r, fmt.Errorf("invalid %s template: %w", field, err))
+			return
+		}
+	}
+
+	if err := app.checkMessageRecipients(ctx, restaurant.ID, message); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := SendMessageResponse{Message: message, Failures: []SendScheduleEmailFailure{}}
+	if message.HasChannel(store.MessageChannelEmail) {
+		quota, ok := app.takeEmailQuota(w, r, restaurant.ID)
+		if !ok {
+			return
+		}
+		response.Quota = quota
+	}
+
+	if err := app.store.Messages.Create(ctx, message); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if message.Status == store.MessageSending {
+		failures, queued, err := app.deliverMessage(ctx, message, restaurant)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		response.Failures, response.Queued = failures, queued
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetMessages godoc
+//
+//	@Summary		Lists sent and scheduled messages
+//	@Description	Returns the restaurant's announcements newest first, with how many employees each reached and how many emails failed. Page with before=<id of the last message>.
+//	@Tags			message
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			limit			query		int	false	"Page size (default 50, max 200)"
+//	@Param			before			query		int	false	"Return messages older than this ID"
+//	@Success		200				{array}		store.Message
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/messages [get]
+func (app *application) getMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	limit := defaultMessagesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxMessagesLimit {
+			app.badRequestResponse(w, r, fmt.Errorf("limit must be between 1 and %d", maxMessagesLimit))
+			return
+		}
+		limit = n
+	}
+
+	var before int64
+	if v := r.URL.Query().Get("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			app.badRequestResponse(w, r, errors.New("before must be a message ID"))
+			return
+		}
+		before = n
+	}
+
+	messages, err := app.store.Messages.ListByRestaurant(r.Context(), restaurant.ID, before, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, messages); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetMessage godoc
+//
+//	@Summary		Fetches a message
+//	@Tags			message
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			messageID		path		int	true	"Message ID"
+//	@Success		200				{object}	store.Message
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/messages/{messageID} [get]
+func (app *application) getMessageHandler(w http.ResponseWriter, r *http.Request) {
+	message, ok := app.restaurantMessageFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, message); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CancelMessage godoc
+//
+//	@Summary		Cancels a scheduled message
+//	@Description	Stops a scheduled message from going out. A message that is being sent or has been can't be canceled.
+//	@Tags			message
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			messageID		path		int	true	"Message ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/messages/{messageID} [delete]
+func (app *application) cancelMessageHandler(w http.ResponseWriter, r *http.Request) {
+	message, ok := app.restaurantMessageFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.Messages.Cancel(r.Context(), message.ID); err != nil {
+		if errors.Is(err, store.ErrMessageNotScheduled) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// restaurantMessageFromURL loads the message in the URL, writing a 404 unless it
+// belongs to the restaurant and the user owns it
+func (app *application) restaurantMessageFromURL(w http.ResponseWriter, r *http.Request) (*store.Message, bool) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return nil, false
+	}
+
+	messageID, err := strconv.ParseInt(chi.URLParam(r, "messageID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	message, err := app.store.Messages.GetByID(r.Context(), messageID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if message.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("message not found"))
+		return nil, false
+	}
+
+	return message, true
+}
+
+// checkMessageRecipients returns ErrNotFound unless every role and employee the
+// message is addressed to belongs to the restaurant
+func (app *application) checkMessageRecipients(ctx context.Context, restaurantID int64, message *store.Message) error {
+	if len(message.RoleIDs) > 0 {
+		roles, err := app.store.Roles.GetByIDs(ctx, message.RoleIDs)
+		if err != nil {
+			return err
+		}
+		if len(roles) != len(message.RoleIDs) {
+			return fmt.Errorf("role not found: %w", store.ErrNotFound)
+		}
+		for _, role := range roles {
+			if role.RestaurantID != restaurantID {
+				return fmt.Errorf("role %d not found: %w", role.ID, store.ErrNotFound)
+			}
+		}
+	}
+
+	if len(message.EmployeeIDs) > 0 {
+		employees, err := app.store.Employees.GetByIDs(ctx, message.EmployeeIDs)
+		if err != nil {
+			return err
+		}
+		if len(employees) != len(message.EmployeeIDs) {
+			return fmt.Errorf("employee not found: %w", store.ErrNotFound)
+		}
+		for _, employee := range employees {
+			if employee.RestaurantID != restaurantID {
+				return fmt.Errorf("employee %d not found: %w", employee.ID, store.ErrNotFound)
+			}
+		}
+	}
+
+	return nil
+}
+
+// deliverMessage sends the message to its recipients on each of its channels and
+// records it as sent, returning the emails that failed and how many were held for
+// digests. Each employee gets the text rendered for them; one whose text can't be
+// rendered gets nothing.
+func (app *application) deliverMessage(ctx context.Context, message *store.Message, restaurant *store.Restaurant) ([]SendScheduleEmailFailure, int, error) {
+	employees, err := app.store.Messages.Recipients(ctx, message)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	isProdEnv := app.config.env == "production"
+	failures := []SendScheduleEmailFailure{}
+	var notifications []*store.Notification
+	var digestItems []*store.DigestItem
+	var queued []*store.Employee
+
+	fail := func(employee *store.Employee, err error) {
+		failures = append(failures, SendScheduleEmailFailure{
+			EmployeeID:   employee.ID,
+			EmployeeName: employee.FullName,
+			Email:        employee.Email,
+			Error:        err.Error(),
+		})
+	}
+
+	for _, employee := range employees {
+		vars := mailer.AnnouncementVars{
+			RestaurantName: mailer.PlainText(restaurant.Name),
+			EmployeeName:   mailer.PlainText(employee.FullName),
+			FirstName:      firstName(employee.FullName),
+		}
+		subject, err := mailer.RenderAnnouncement(message.Subject, vars)
+		if err != nil {
+			fail(employee, err)
+			continue
+		}
+		body, err := mailer.RenderAnnouncement(message.Body, vars)
+		if err != nil {
+			fail(employee, err)
+			continue
+		}
+
+		if message.HasChannel(store.MessageChannelEmail) {
+			switch {
+			case employee.Email == "":
+				fail(employee, errors.New("no email address"))
+			case employee.EmailBouncedAt != nil:
+				fail(employee, errEmailBounced)
+			case !message.Critical && restaurant.NotificationModeFor(employee).Digest():
+				digestItems = append(digestItems, &store.DigestItem{
+					RestaurantID: message.RestaurantID,
+					EmployeeID:   employee.ID,
+					Kind:         store.DigestAnnouncement,
+					Subject:      mailer.PlainText(subject),
+					Body:         body,
+				})
+				queued = append(queued, employee)
+			default:
+				emailData := &AnnouncementEmailData{
+					RestaurantName: vars.RestaurantName,
+					Subject:        mailer.PlainText(subject),
+					Body:           app.config.mail.userText.HTML(body),
+				}
+				if _, err := app.mailer.Send(mailer.AnnouncementTemplate, employee.FullName, employee.Email, emailData, !isProdEnv); err != nil {
+					app.logger.Warnw("failed to send announcement",
+						"employee_id", employee.ID,
+						"message_id", message.ID,
+						"error", err,
+					)
+					fail(employee, err)
+				}
+			}
+		}
+
+		if message.HasChannel(store.MessageChannelInApp) {
+			data, _ := json.Marshal(map[string]any{"message_id": message.ID})
+			notifications = append(notifications, &store.Notification{
+				RestaurantID: message.RestaurantID,
+				EmployeeID:   &employee.ID,
+				Type:         store.NotificationAnnouncement,
+				Title:        mailer.PlainText(subject),
+				Body:         body,
+				Data:         data,
+			})
+		}
+	}
+
+	if len(digestItems) > 0 {
+		if err := app.store.NotificationDigests.Enqueue(ctx, digestItems); err != nil {
+			app.logger.Warnw("failed to queue announcement for digests", "message_id", message.ID, "error", err)
+			for _, employee := range queued {
+				fail(employee, err)
+			}
+			digestItems = nil
+		}
+	}
+
+	app.notify(ctx, notifications)
+
+	message.Recipients, message.Failed = len(employees), len(failures)
+	if err := app.store.Messages.Complete(ctx, message); err != nil {
+		return nil, 0, err
+	}
+
+	return failures, len(digestItems), nil
+}
+
+// runMessageDelivery periodically sends the scheduled messages that are due
+func (app *application) runMessageDelivery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sent, err := app.deliverDueMessages(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("scheduled message delivery failed", "error", err)
+			continue
+		}
+
+		if sent > 0 {
+			app.logger.Infow("sent scheduled messages", "count", sent)
+		}
+	}
+}
+
+// deliverDueMessages claims the scheduled messages due by now and sends them, returning
+// how many went out. A message is claimed before it's sent so several instances never
+// send it twice; one that fails stays claimed rather than being sent again.
+func (app *application) deliverDueMessages(ctx context.Context, now time.Time) (int, error) {
+	messages, err := app.store.Messages.ClaimDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, message := range messages {
+		restaurant, err := app.store.Restaurants.GetByID(ctx, message.RestaurantID)
+		if err != nil {
+			app.logger.Warnw("failed to load restaurant for scheduled message", "message_id", message.ID, "error", err)
+			continue
+		}
+
+		if _, _, err := app.deliverMessage(ctx, message, restaurant); err != nil {
+			app.logger.Warnw("failed to send scheduled message", "message_id", message.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// firstName is the first word of a full name, for greetings
+func firstName(fullName string) string {
+	name := mailer.PlainText(fullName)
+	if first, _, ok := strings.Cut(name, " "); ok {
+		return first
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestSendMessage(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+
+	bounced := time.Now()
+	app.store.Roles = &store.MockRoleStorer{
+		GetByIDsFunc: func(_ context.Context, ids []int64) ([]*store.Role, error) {
+			roles := []*store.Role{}
+			for _, id := range ids {
+				restaurantID := int64(1)
+				if id == 99 {
+					restaurantID = 2
+				}
+				roles = append(roles, &store.Role{ID: id, RestaurantID: restaurantID})
+			}
+			return roles, nil
+		},
+	}
+	var created []*store.Message
+	app.store.Messages = &store.MockMessageStorer{
+		CreateFunc: func(_ context.Context, m *store.Message) error {
+			m.ID = int64(len(created) + 1)
+			created = append(created, m)
+			return nil
+		},
+		RecipientsFunc: func(_ context.Context, m *store.Message) ([]*store.Employee, error) {
+			return []*store.Employee{
+				{ID: 1, RestaurantID: 1, FullName: "Ana Diaz", Email: "ana@example.com"},
+				{ID: 2, RestaurantID: 1, FullName: "Ben Lee", Email: "ben@example.com", EmailBouncedAt: &bounced},
+				{ID: 3, RestaurantID: 1, FullName: "Cy"},
+			}, nil
+		},
+		CompleteFunc: func(_ context.Context, m *store.Message) error {
+			now := time.Now()
+			m.Status, m.SentAt = store.MessageSent, &now
+			return nil
+		},
+	}
+	var notified []*store.Notification
+	app.store.Notifications = &store.MockNotificationStorer{
+		CreateManyFunc: func(_ context.Context, n []*store.Notification) error {
+			notified = append(notified, n...)
+			return nil
+		},
+	}
+
+	send := func(body string) (int, SendMessageResponse) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/messages", body), app.mount())
+		var response struct {
+			Data SendMessageResponse `json:"data"`
+		}
+		if rr.Code == http.StatusCreated {
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rr.Code, response.Data
+	}
+
+	t.Run("sends now by email and in-app", func(t *testing.T) {
+		code, got := send(`{"subject":"Hi {{.FirstName}}","body":"Staff meeting Monday","channels":["email","in_app"],"role_ids":[4]}`)
+		checkResponseCode(t, http.StatusCreated, code)
+
+		if got.Message.Status != store.MessageSent || got.Message.Recipients != 3 || got.Message.Failed != 2 {
+			t.Errorf("message = %+v, want sent to 3 with 2 failed emails", got.Message)
+		}
+		if len(got.Failures) != 2 || got.Failures[0].EmployeeID != 2 || got.Failures[1].EmployeeID != 3 {
+			t.Errorf("failures = %+v, want Ben bounced and Cy without an address", got.Failures)
+		}
+		if len(mail.sent) != 1 || mail.sent[0] != "announcement.go.tmpl ana@example.com" {
+			t.Errorf("sent = %v, want only Ana emailed", mail.sent)
+		}
+		if len(notified) != 3 || notified[0].Title != "Hi Ana" || notified[2].Title != "Hi Cy" {
+			t.Errorf("notifications = %+v, want one per employee with their name", notified)
+		}
+	})
+
+	t.Run("schedules for later", func(t *testing.T) {
+		mail.sent = nil
+		sendAt := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+		code, got := send(`{"subject":"Holiday hours","body":"We close early","send_at":"` + sendAt + `"}`)
+		checkResponseCode(t, http.StatusCreated, code)
+
+		if got.Message.Status != store.MessageScheduled {
+			t.Errorf("status = %q, want scheduled", got.Message.Status)
+		}
+		if len(mail.sent) != 0 {
+			t.Errorf("sent = %v, want nothing until send_at", mail.sent)
+		}
+	})
+
+	t.Run("rejects a broken template", func(t *testing.T) {
+		code, _ := send(`{"subject":"Hi {{.Nickname}}","body":"x"}`)
+		checkResponseCode(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("rejects another restaurant's role", func(t *testing.T) {
+		code, _ := send(`{"subject":"Hi","body":"x","role_ids":[99]}`)
+		checkResponseCode(t, http.StatusNotFound, code)
+	})
+
+	t.Run("rejects unknown channels", func(t *testing.T) {
+		code, _ := send(`{"subject":"Hi","body":"x","channels":["sms"]}`)
+		checkResponseCode(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestCancelMessage(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.Messages = &store.MockMessageStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Message, error) {
+			return &store.Message{ID: id, RestaurantID: 1}, nil
+		},
+		CancelFunc: func(_ context.Context, id int64) error {
+			if id == 2 {
+				return store.ErrMessageNotScheduled
+			}
+			return nil
+		},
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/1/messages/1", ""), app.mount())
+	checkResponseCode(t, http.StatusNoContent, rr.Code)
+
+	rr = executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/1/messages/2", ""), app.mount())
+	checkResponseCode(t, http.StatusConflict, rr.Code)
+}
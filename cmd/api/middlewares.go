@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -44,6 +46,9 @@ func (app *application) restaurantsContextMiddleware(next http.Handler) http.Han
 		}
 
 		ctx = context.WithValue(ctx, restaurantCtx, restaurant)
+		if app.config.db.rowLevelSecurity {
+			ctx = store.ScopeToRestaurant(ctx, restaurant.ID)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -105,7 +110,19 @@ func (app *application) AuthTokenMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		sessionID, err := app.checkSession(ctx, claims, user.ID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.unauthorizedErrorResponse(w, r, fmt.Errorf("session has been signed out"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		setLoggedUser(ctx, user.ID)
 		ctx = context.WithValue(ctx, userCtx, user)
+		ctx = context.WithValue(ctx, sessionIDCtx, sessionID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 
 	})
@@ -140,6 +157,7 @@ func (app *application) BasicAuthMiddleware() func(http.Handler) http.Handler {
 			creds := strings.SplitN(string(decoded), ":", 2)
 			if len(creds) != 2 || creds[0] != username || creds[1] != pass {
 				app.unauthorizedBasicErrorResponse(w, r, fmt.Errorf("invalid credentials"))
+				return
 			}
 
 			next.ServeHTTP(w, r)
@@ -147,10 +165,115 @@ func (app *application) BasicAuthMiddleware() func(http.Handler) http.Handler {
 	}
 }
 
+// ownership cache counters, exposed on /debug/vars
+var (
+	ownershipCacheHits   = expvar.NewInt("ownership_cache_hits")
+	ownershipCacheMisses = expvar.NewInt("ownership_cache_misses")
+)
+
+// checkRestaurantAccess verifies userID owns restaurantID. Behind restaurantsContextMiddleware
+// it compares the owner of the restaurant already in the context; elsewhere, as over gRPC, it
+// consults the ownership cache before the database. A missing restaurant and one owned by
+// someone else both return store.ErrNotFound.
+func (app *application) checkRestaurantAccess(ctx context.Context, restaurantID, userID int64) error {
+	ownerID := int64(0)
+	if restaurant, ok := ctx.Value(restaurantCtx).(*store.Restaurant); ok && restaurant.ID == restaurantID {
+		ownerID = restaurant.UserID
+	} else {
+		ownership, err := app.restaurantOwnership(ctx, restaurantID)
+		if err != nil {
+			return err
+		}
+		ownerID = ownership.OwnerID
+	}
+
+	if ownerID != userID {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// restaurantByID is the restaurant restaurantsContextMiddleware loaded when the
+// context has it, and otherwise reads it from the store
+func (app *application) restaurantByID(ctx context.Context, restaurantID int64) (*store.Restaurant, error) {
+	if restaurant, ok := ctx.Value(restaurantCtx).(*store.Restaurant); ok && restaurant.ID == restaurantID {
+		return restaurant, nil
+	}
+	return app.store.Restaurants.GetByID(ctx, restaurantID)
+}
+
+// restaurantMember returns what the user may do with the restaurant as one of its members,
+// or store.ErrNotFound when they aren't one
+func (app *application) restaurantMember(ctx context.Context, restaurantID, userID int64) (*cache.MemberAccess, error) {
+	ownership, err := app.restaurantOwnership(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	member, ok := ownership.Members[userID]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return member, nil
+}
+
+// restaurantOwnership is the restaurant's owner and members from the ownership cache,
+// filling it from the database on a miss
+func (app *application) restaurantOwnership(ctx context.Context, restaurantID int64) (*cache.Ownership, error) {
+	if app.cacheStorage.Ownership != nil {
+		ownership, err := app.cacheStorage.Ownership.Get(ctx, restaurantID)
+		if err != nil {
+			app.logger.Warnw("ownership cache get failed", "restaurant_id", restaurantID, "error", err)
+		} else if ownership != nil {
+			ownershipCacheHits.Add(1)
+			return ownership, nil
+		}
+	}
+	ownershipCacheMisses.Add(1)
+
+	restaurant, err := app.restaurantByID(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := app.store.Members.ListByRestaurant(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownership := &cache.Ownership{OwnerID: restaurant.UserID, Members: make(map[int64]*cache.MemberAccess, len(members))}
+	for _, member := range members {
+		ownership.Members[member.UserID] = &cache.MemberAccess{Permission: member.Permission, RoleIDs: member.RoleIDs}
+	}
+
+	if app.cacheStorage.Ownership != nil {
+		if err := app.cacheStorage.Ownership.Set(ctx, restaurantID, ownership); err != nil {
+			app.logger.Warnw("ownership cache set failed", "restaurant_id", restaurantID, "error", err)
+		}
+	}
+
+	return ownership, nil
+}
+
+// invalidateRestaurantAccess drops the cached owner and members so the next check re-reads the database
+func (app *application) invalidateRestaurantAccess(ctx context.Context, restaurantID int64) {
+	if app.cacheStorage.Ownership == nil {
+		return
+	}
+	if err := app.cacheStorage.Ownership.Delete(ctx, restaurantID); err != nil {
+		app.logger.Warnw("ownership cache delete failed", "restaurant_id", restaurantID, "error", err)
+	}
+}
+
 func (app *application) checkRestaurantOwnership(next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := getUserFromContext(r)
 		restaurant := getRestaurantFromContext(r)
+
+		// archived restaurants are read-only until unarchived
+		if restaurant.Archived() {
+			app.conflictResponse(w, r, errRestaurantArchived)
+			return
+		}
 		
 		// if it is the users restaurant 
 		if restaurant.UserID == user.ID {
@@ -158,18 +281,36 @@ func (app *application) checkRestaurantOwnership(next http.HandlerFunc) http.Han
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
 	})
 }
 
+// RateLimiterMiddleware counts every request against its client's budget and reports
+// what's left in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
 func (app *application) RateLimiterMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if app.config.rateLimiter.Enabled {
-			if allow, retryAfter := app.rateLimiter.Allow(r.RemoteAddr); !allow {
-				app.rateLimiterExceededResponse(w, r, retryAfter.String())
+		if app.settings().rateLimiter.Enabled {
+			identity := rateLimitIdentity(r)
+			allow, status := app.rateLimiter.Allow(identity)
+			setRateLimitHeaders(w, status)
+			if !allow {
+				rateLimitExceeded.Add(1)
+				app.rateLimiterExceededResponse(w, r, status.Reset)
 				return
 			}
+			app.warnNearRateLimit(r, identity, status)
 		}
 		next.ServeHTTP(w, r)
 	})
-}
\ No newline at end of file
+}
+// primaryForWritesMiddleware keeps every read of a mutating request on the
+// primary, so a handler never validates or responds with rows the replica
+// hasn't caught up on yet
+func primaryForWritesMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			r = r.WithContext(store.UsePrimary(r.Context()))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
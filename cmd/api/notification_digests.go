@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// NotificationDigestEmailData contains the data for the notification digest email template
+type NotificationDigestEmailData struct {
+	RestaurantName string
+	FirstName      string
+	Items          []NotificationDigestEmailItem
+}
+
+type NotificationDigestEmailItem struct {
+	Subject string
+	Body    template.HTML
+}
+
+// runNotificationDigests periodically emails employees who get digests the shift
+// changes and announcements held for them
+func (app *application) runNotificationDigests(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sent, err := app.sendNotificationDigests(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("notification digest failed", "error", err)
+			continue
+		}
+
+		if sent > 0 {
+			app.logger.Infow("sent notification digests", "count", sent)
+		}
+	}
+}
+
+// sendNotificationDigests claims the digests due at now and emails each employee
+// theirs, returning how many went out. Items are claimed before they're sent so
+// several instances never send them twice; a digest that fails isn't retried.
+func (app *application) sendNotificationDigests(ctx context.Context, now time.Time) (int, error) {
+	digests, err := app.store.NotificationDigests.ClaimDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	isProdEnv := app.config.env == "production"
+	sent := 0
+	for _, digest := range digests {
+		employee := digest.Employee
+		if employee.Email == "" || employee.EmailBouncedAt != nil {
+			app.logger.Warnw("skipped notification digest for an employee without a working email",
+				"employee_id", employee.ID,
+				"items", len(digest.Items),
+			)
+			continue
+		}
+
+		data := &NotificationDigestEmailData{
+			RestaurantName: mailer.PlainText(digest.RestaurantName),
+			FirstName:      firstName(employee.FullName),
+		}
+		for _, item := range digest.Items {
+			data.Items = append(data.Items, NotificationDigestEmailItem{
+				Subject: mailer.PlainText(item.Subject),
+				Body:    app.config.mail.userText.HTML(item.Body),
+			})
+		}
+
+		if _, err := app.mailer.Send(mailer.NotificationDigestTemplate, employee.FullName, employee.Email, data, !isProdEnv); err != nil {
+			app.logger.Warnw("failed to send notification digest", "employee_id", employee.ID, "error", err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// scheduleChangesDigestItem describes an employee's schedule changes for their digest
+func scheduleChangesDigestItem(employee *store.Employee, data *ScheduleChangesEmailData) *store.DigestItem {
+	describe := func(shift ScheduleEmailShift) string {
+		return fmt.Sprintf("%s shift on %s, %s–%s", shift.RoleName, shift.Date, shift.StartTime, shift.EndTime)
+	}
+
+	var lines []string
+	for _, shift := range data.Added {
+		lines = append(lines, "- New: "+describe(shift))
+	}
+	for _, shift := range data.Removed {
+		lines = append(lines, "- Removed: "+describe(shift))
+	}
+	for _, change := range data.Changed {
+		lines = append(lines, "- Changed: "+describe(change.Before)+" is now "+change.After.Date+", "+change.After.StartTime+"–"+change.After.EndTime)
+	}
+
+	return &store.DigestItem{
+		RestaurantID: employee.RestaurantID,
+		EmployeeID:   employee.ID,
+		Kind:         store.DigestScheduleChanges,
+		Subject:      fmt.Sprintf("Schedule changes for %s – %s", data.ScheduleStart, data.ScheduleEnd),
+		Body:         strings.Join(lines, "\n"),
+	}
+}
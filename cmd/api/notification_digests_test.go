@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestAnnouncementDigests(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		return &store.Restaurant{ID: id, UserID: testUserID, Name: "Bistro", NotificationMode: store.NotifyDaily}, nil
+	}
+	immediate := store.NotifyImmediately
+	app.store.Messages = &store.MockMessageStorer{
+		CreateFunc: func(context.Context, *store.Message) error { return nil },
+		RecipientsFunc: func(context.Context, *store.Message) ([]*store.Employee, error) {
+			return []*store.Employee{
+				{ID: 1, RestaurantID: 1, FullName: "Ana Diaz", Email: "ana@example.com"},
+				{ID: 2, RestaurantID: 1, FullName: "Ben Lee", Email: "ben@example.com", NotificationMode: &immediate},
+			}, nil
+		},
+		CompleteFunc: func(context.Context, *store.Message) error { return nil },
+	}
+	var queued []*store.DigestItem
+	app.store.NotificationDigests = &store.MockNotificationDigestStorer{
+		EnqueueFunc: func(_ context.Context, items []*store.DigestItem) error {
+			queued = append(queued, items...)
+			return nil
+		},
+	}
+	app.store.Notifications = &store.MockNotificationStorer{}
+
+	send := func(body string) SendMessageResponse {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/messages", body), app.mount())
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		var response struct {
+			Data SendMessageResponse `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data
+	}
+
+	got := send(`{"subject":"Hi {{.FirstName}}","body":"Staff meeting Monday"}`)
+	if got.Queued != 1 || len(queued) != 1 || queued[0].EmployeeID != 1 || queued[0].Subject != "Hi Ana" || queued[0].Kind != store.DigestAnnouncement {
+		t.Errorf("queued %d: %+v, want Ana's announcement held for her digest", got.Queued, queued)
+	}
+	if len(mail.sent) != 1 || mail.sent[0] != "announcement.go.tmpl ben@example.com" {
+		t.Errorf("sent = %v, want only Ben, who opted out of digests, emailed", mail.sent)
+	}
+
+	mail.sent, queued = nil, nil
+	got = send(`{"subject":"Closed today","body":"Burst pipe","critical":true}`)
+	if got.Queued != 0 || len(queued) != 0 || len(mail.sent) != 2 {
+		t.Errorf("queued %d, sent %v, want a critical message emailed to both", got.Queued, mail.sent)
+	}
+}
+
+func TestSendNotificationDigests(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+
+	bounced := time.Now()
+	now := time.Date(2026, 6, 2, 7, 5, 0, 0, time.UTC)
+	app.store.NotificationDigests = &store.MockNotificationDigestStorer{
+		ClaimDueFunc: func(_ context.Context, at time.Time) ([]*store.EmployeeDigest, error) {
+			if !at.Equal(now) {
+				t.Errorf("claimed at %v, want %v", at, now)
+			}
+			return []*store.EmployeeDigest{
+				{
+					Employee:       &store.Employee{ID: 1, FullName: "Ana Diaz", Email: "ana@example.com"},
+					RestaurantName: "Bistro",
+					Items: []*store.DigestItem{
+						{Kind: store.DigestScheduleChanges, Subject: "Schedule changes", Body: "- New: Server shift"},
+						{Kind: store.DigestAnnouncement, Subject: "Staff meeting", Body: "Monday"},
+					},
+				},
+				{
+					Employee:       &store.Employee{ID: 2, FullName: "Ben Lee", Email: "ben@example.com", EmailBouncedAt: &bounced},
+					RestaurantName: "Bistro",
+					Items:          []*store.DigestItem{{Kind: store.DigestAnnouncement, Subject: "Staff meeting"}},
+				},
+			}, nil
+		},
+	}
+
+	sent, err := app.sendNotificationDigests(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(mail.sent) != 1 || mail.sent[0] != "notification_digest.go.tmpl ana@example.com" {
+		t.Errorf("sent %d: %v, want one digest to Ana and none to Ben's bounced address", sent, mail.sent)
+	}
+}
+
+func TestScheduleChangesDigestItem(t *testing.T) {
+	employee := &store.Employee{ID: 7, RestaurantID: 1}
+	shift := ScheduleEmailShift{Date: "Mon, Jun 1", StartTime: "9:00 AM", EndTime: "5:00 PM", RoleName: "Server"}
+	later := ScheduleEmailShift{Date: "Tue, Jun 2", StartTime: "10:00 AM", EndTime: "6:00 PM", RoleName: "Server"}
+
+	item := scheduleChangesDigestItem(employee, &ScheduleChangesEmailData{
+		ScheduleStart: "Jun 1",
+		ScheduleEnd:   "Jun 7",
+		Added:         []ScheduleEmailShift{shift},
+		Changed:       []ScheduleEmailShiftChange{{Before: shift, After: later}},
+	})
+
+	want := "- New: Server shift on Mon, Jun 1, 9:00 AM–5:00 PM\n- Changed: Server shift on Mon, Jun 1, 9:00 AM–5:00 PM is now Tue, Jun 2, 10:00 AM–6:00 PM"
+	if item.EmployeeID != 7 || item.Kind != store.DigestScheduleChanges || item.Subject != "Schedule changes for Jun 1 – Jun 7" || item.Body != want {
+		t.Errorf("item = %+v, want the changes listed for employee 7", item)
+	}
+}
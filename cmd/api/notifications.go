@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 200
+)
+
+type NotificationsResponse struct {
+	Notifications []*store.Notification `json:"notifications"`
+	UnreadCount   int                   `json:"unread_count"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+type MarkAllReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+// GetNotifications godoc
+//
+//	@Summary		Lists the current user's notifications
+//	@Description	Returns notifications newest first with the unread count for the bell icon. Page with before=<id of the last notification>.
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Param			unread	query		bool	false	"Only unread notifications"
+//	@Param			limit	query		int		false	"Page size (default 50, max 200)"
+//	@Param			before	query		int		false	"Return notifications older than this ID"
+//	@Success		200		{object}	NotificationsResponse
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/notifications [get]
+func (app *application) getNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	app.listNotifications(w, r, store.UserRecipient(user.ID))
+}
+
+// GetUnreadNotificationCount godoc
+//
+//	@Summary	Counts the current user's unread notifications
+//	@Tags		notifications
+//	@Produce	json
+//	@Success	200	{object}	UnreadCountResponse
+//	@Failure	401	{object}	error
+//	@Failure	500	{object}	error
+//	@Security	ApiKeyAuth
+//	@Router		/notifications/unread-count [get]
+func (app *application) getUnreadNotificationCountHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	count, err := app.store.Notifications.UnreadCount(r.Context(), store.UserRecipient(user.ID))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, UnreadCountResponse{UnreadCount: count}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// MarkNotificationRead godoc
+//
+//	@Summary	Marks a notification as read
+//	@Tags		notifications
+//	@Param		notificationID	path	int	true	"Notification ID"
+//	@Success	204				"No Content"
+//	@Failure	400				{object}	error
+//	@Failure	401				{object}	error
+//	@Failure	404				{object}	error
+//	@Failure	500				{object}	error
+//	@Security	ApiKeyAuth
+//	@Router		/notifications/{notificationID}/read [post]
+func (app *application) markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "notificationID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid notification ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Notifications.MarkRead(r.Context(), store.UserRecipient(user.ID), notificationID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("notification not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllNotificationsRead godoc
+//
+//	@Summary	Marks all of the current user's notifications as read
+//	@Tags		notifications
+//	@Produce	json
+//	@Success	200	{object}	MarkAllReadResponse
+//	@Failure	401	{object}	error
+//	@Failure	500	{object}	error
+//	@Security	ApiKeyAuth
+//	@Router		/notifications/read-all [post]
+func (app *application) markAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	updated, err := app.store.Notifications.MarkAllRead(r.Context(), store.UserRecipient(user.ID))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, MarkAllReadResponse{Updated: updated}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetEmployeeNotifications godoc
+//
+//	@Summary		Lists an employee's notifications
+//	@Description	Returns the notifications sent to an employee (schedule published, shift changes) newest first with their unread count
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			employeeID		path		int		true	"Employee ID"
+//	@Param			unread			query		bool	false	"Only unread notifications"
+//	@Param			limit			query		int		false	"Page size (default 50, max 200)"
+//	@Param			before			query		int		false	"Return notifications older than this ID"
+//	@Success		200				{object}	NotificationsResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/notifications [get]
+func (app *application) getEmployeeNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	app.listNotifications(w, r, store.EmployeeRecipient(employee.ID))
+}
+
+func (app *application) listNotifications(w http.ResponseWriter, r *http.Request, recipient store.NotificationRecipient) {
+	q, err := notificationQuery(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	notifications, err := app.store.Notifications.List(ctx, recipient, q)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	count, err := app.store.Notifications.UnreadCount(ctx, recipient)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := NotificationsResponse{Notifications: notifications, UnreadCount: count}
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func notificationQuery(r *http.Request) (store.NotificationQuery, error) {
+	q := store.NotificationQuery{
+		UnreadOnly: r.URL.Query().Get("unread") == "true",
+		Limit:      defaultNotificationsLimit,
+	}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxNotificationsLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxNotificationsLimit)
+		}
+		q.Limit = limit
+	}
+
+	if v := r.URL.Query().Get("before"); v != "" {
+		before, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || before < 1 {
+			return q, errors.New("before must be a notification ID")
+		}
+		q.BeforeID = before
+	}
+
+	return q, nil
+}
+
+// notify records notifications without failing the request that triggered them
+func (app *application) notify(ctx context.Context, notifications []*store.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	if err := app.store.Notifications.CreateMany(ctx, notifications); err != nil {
+		app.logger.Warnw("failed to record notifications", "count", len(notifications), "error", err)
+	}
+}
+
+// notifySchedulePublished tells every scheduled employee their week is out and confirms it to the owner
+func (app *application) notifySchedulePublished(ctx context.Context, schedule *store.Schedule, ownerID int64) {
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.logger.Warnw("failed to load shifts for publish notifications", "schedule_id", schedule.ID, "error", err)
+		return
+	}
+
+	data, _ := json.Marshal(map[string]any{
+		"schedule_id": schedule.ID,
+		"start_date":  schedule.StartDate,
+		"end_date":    schedule.EndDate,
+	})
+	week := fmt.Sprintf("%s – %s", i18n.FormatDay(i18n.English, string(schedule.StartDate)), i18n.FormatDay(i18n.English, string(schedule.EndDate)))
+
+	seen := make(map[int64]bool)
+	var notifications []*store.Notification
+	for _, shift := range shifts {
+		if shift.EmployeeID == nil || seen[*shift.EmployeeID] {
+			continue
+		}
+		seen[*shift.EmployeeID] = true
+
+		notifications = append(notifications, &store.Notification{
+			RestaurantID: schedule.RestaurantID,
+			EmployeeID:   shift.EmployeeID,
+			Type:         store.NotificationSchedulePublished,
+			Title:        "Your schedule is out",
+			Body:         fmt.Sprintf("Your shifts for %s have been published.", week),
+			Data:         data,
+		})
+	}
+
+	notifications = append(notifications, &store.Notification{
+		RestaurantID: schedule.RestaurantID,
+		UserID:       &ownerID,
+		Type:         store.NotificationSchedulePublished,
+		Title:        "Schedule published",
+		Body:         fmt.Sprintf("The schedule for %s was published to %d employees.", week, len(seen)),
+		Data:         data,
+	})
+
+	app.notify(ctx, notifications)
+}
+
+// notifyShiftChanged tells the employees on a published shift what changed; after is nil when the shift was deleted
+func (app *application) notifyShiftChanged(ctx context.Context, before, after *store.ScheduledShift) {
+	if before == nil {
+		return
+	}
+
+	schedule, err := app.store.Schedules.GetByID(ctx, before.ScheduleID)
+	if err != nil {
+		app.logger.Warnw("failed to load schedule for shift notifications", "schedule_id", before.ScheduleID, "error", err)
+		return
+	}
+	// Staff only see published schedules, so draft edits aren't news to them
+	if schedule.PublishedAt == nil {
+		return
+	}
+
+	notification := func(shift *store.ScheduledShift, change, title, body string) *store.Notification {
+		data, _ := json.Marshal(map[string]any{
+			"shift_id":    shift.ID,
+			"schedule_id": shift.ScheduleID,
+			"change":      change,
+		})
+		return &store.Notification{
+			RestaurantID: shift.RestaurantID,
+			EmployeeID:   shift.EmployeeID,
+			Type:         store.NotificationShiftChanged,
+			Title:        title,
+			Body:         body,
+			Data:         data,
+		}
+	}
+
+	var notifications []*store.Notification
+	switch {
+	case after == nil:
+		if before.EmployeeID != nil {
+			notifications = append(notifications, notification(before, "removed", "Shift cancelled", "Your "+describeShift(before)+" was cancelled."))
+		}
+	case !sameEmployee(before.EmployeeID, after.EmployeeID):
+		if before.EmployeeID != nil {
+			notifications = append(notifications, notification(before, "unassigned", "Removed from a shift", "You are no longer scheduled for "+describeShift(before)+"."))
+		}
+		if after.EmployeeID != nil {
+			notifications = append(notifications, notification(after, "assigned", "New shift", "You have been scheduled for "+describeShift(after)+"."))
+		}
+	case after.EmployeeID != nil && describeShift(before) != describeShift(after):
+		notifications = append(notifications, notification(after, "updated", "Shift changed", "Your "+describeShift(before)+" is now "+describeShift(after)+"."))
+	}
+
+	app.notify(ctx, notifications)
+}
+
+func describeShift(shift *store.ScheduledShift) string {
+	return fmt.Sprintf("%s shift on %s, %s–%s",
+		shift.RoleName,
+		i18n.FormatShortDate(i18n.English, shift.ShiftDate),
+		i18n.FormatTimeOfDay(i18n.English, string(shift.StartTime)),
+		i18n.FormatTimeOfDay(i18n.English, string(shift.EndTime)),
+	)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNotificationQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		unread  bool
+		limit   int
+		before  int64
+		wantErr bool
+	}{
+		{"", false, defaultNotificationsLimit, 0, false},
+		{"unread=true&limit=10&before=42", true, 10, 42, false},
+		{"limit=0", false, 0, 0, true},
+		{"limit=500", false, 0, 0, true},
+		{"before=abc", false, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/v1/notifications?"+tt.query, nil)
+		q, err := notificationQuery(r)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if q.UnreadOnly != tt.unread || q.Limit != tt.limit || q.BeforeID != tt.before {
+			t.Errorf("%q: got %+v", tt.query, q)
+		}
+	}
+}
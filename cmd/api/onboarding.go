@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// Onboarding steps, in the order the wizard walks them
+const (
+	onboardingRoles          = "roles"
+	onboardingEmployees      = "employees"
+	onboardingShiftTemplates = "shift_templates"
+	onboardingFirstSchedule  = "first_schedule"
+)
+
+// OnboardingStep is one step of the setup wizard and whether it's done
+type OnboardingStep struct {
+	Key   string `json:"key"`
+	Done  bool   `json:"done"`
+	Count int    `json:"count"`
+}
+
+// OnboardingState is how far a restaurant is through setup
+type OnboardingState struct {
+	RestaurantID int64            `json:"restaurant_id"`
+	Steps        []OnboardingStep `json:"steps"`
+	Completed    int              `json:"completed"`
+	Total        int              `json:"total"`
+	Done         bool             `json:"done"`
+	// NextStep is the first step not done yet, absent once every step is
+	NextStep string `json:"next_step,omitempty"`
+	// CanAddSampleData is true while the restaurant is empty enough for sample data
+	CanAddSampleData bool `json:"can_add_sample_data"`
+}
+
+// GetOnboarding godoc
+//
+//	@Summary		Gets a restaurant's setup progress
+//	@Description	Reports which setup steps are done (roles, employees, shift_templates, first_schedule), the next one to do, and whether sample data can still be added
+//	@Tags			onboarding
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	OnboardingState
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/onboarding [get]
+func (app *application) getOnboardingHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	progress, err := app.store.Onboarding.Progress(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, onboardingState(restaurant.ID, progress)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AddOnboardingSampleData godoc
+//
+//	@Summary		Adds sample data to a new restaurant
+//	@Description	Sets up a starter configuration to edit: four roles, six employees with example.com emails and hourly rates, lunch and dinner shift templates for every day plus a weekend bar shift, and a draft schedule for next week ready to auto-populate. Only allowed while the restaurant has no roles, employees, shift templates or schedules.
+//	@Tags			onboarding
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		201				{object}	store.SampleDataResult
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/onboarding/sample-data [post]
+func (app *application) addOnboardingSampleDataHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	result, err := app.store.Onboarding.SeedSample(r.Context(), restaurant.ID, onboardingSampleData(time.Now()))
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrRestaurantNotEmpty):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func onboardingState(restaurantID int64, progress *store.OnboardingProgress) *OnboardingState {
+	state := &OnboardingState{
+		RestaurantID: restaurantID,
+		Steps: []OnboardingStep{
+			{Key: onboardingRoles, Count: progress.Roles},
+			{Key: onboardingEmployees, Count: progress.Employees},
+			{Key: onboardingShiftTemplates, Count: progress.ShiftTemplates},
+			{Key: onboardingFirstSchedule, Count: progress.Schedules},
+		},
+	}
+
+	for i := range state.Steps {
+		step := &state.Steps[i]
+		step.Done = step.Count > 0
+		if step.Done {
+			state.Completed++
+		} else if state.NextStep == "" {
+			state.NextStep = step.Key
+		}
+	}
+	state.Total = len(state.Steps)
+	state.Done = state.Completed == state.Total
+	state.CanAddSampleData = state.Completed == 0
+
+	return state
+}
+
+// onboardingSampleData is a small full-service restaurant with next week's
+// schedule, Monday to Sunday, as a draft
+func onboardingSampleData(now time.Time) *store.SampleData {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysToMonday := (8 - int(today.Weekday())) % 7
+	if daysToMonday == 0 {
+		daysToMonday = 7
+	}
+	monday := today.AddDate(0, 0, daysToMonday)
+
+	rate := func(cents int) *int { return &cents }
+
+	sample := &store.SampleData{
+		Roles: []store.SampleRole{
+			{Name: "Server", Color: "#3B82F6"},
+			{Name: "Cook", Color: "#EF4444"},
+			{Name: "Host", Color: "#10B981"},
+			{Name: "Bartender", Color: "#F59E0B"},
+		},
+		Employees: []store.SampleEmployee{
+			{FullName: "Maria Lopez", Email: "maria.lopez@example.com", HourlyRateCents: rate(1500), Roles: []string{"Server", "Host"}},
+			{FullName: "James Carter", Email: "james.carter@example.com", HourlyRateCents: rate(1500), Roles: []string{"Server"}},
+			{FullName: "Aiko Tanaka", Email: "aiko.tanaka@example.com", HourlyRateCents: rate(1400), Roles: []string{"Host", "Server"}},
+			{FullName: "Sam Okafor", Email: "sam.okafor@example.com", HourlyRateCents: rate(2000), Roles: []string{"Cook"}},
+			{FullName: "Lena Fischer", Email: "lena.fischer@example.com", HourlyRateCents: rate(1900), Roles: []string{"Cook"}},
+			{FullName: "Diego Ramos", Email: "diego.ramos@example.com", HourlyRateCents: rate(1700), Roles: []string{"Bartender", "Server"}},
+		},
+		ScheduleStart: dateOnly(monday),
+		ScheduleEnd:   dateOnly(monday.AddDate(0, 0, 6)),
+	}
+
+	for day := 0; day < 7; day++ {
+		sample.ShiftTemplates = append(sample.ShiftTemplates,
+			store.SampleShiftTemplate{Name: "Lunch", DayOfWeek: day, StartTime: "11:00", EndTime: "15:00", Roles: []string{"Server", "Cook"}},
+			store.SampleShiftTemplate{Name: "Dinner", DayOfWeek: day, StartTime: "17:00", EndTime: "22:00", Roles: []string{"Server", "Cook", "Host"}},
+		)
+	}
+	for _, day := range []time.Weekday{time.Friday, time.Saturday} {
+		sample.ShiftTemplates = append(sample.ShiftTemplates,
+			store.SampleShiftTemplate{Name: "Bar", DayOfWeek: int(day), StartTime: "18:00", EndTime: "23:00", Roles: []string{"Bartender"}},
+		)
+	}
+
+	return sample
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestOnboardingState(t *testing.T) {
+	t.Run("new restaurant", func(t *testing.T) {
+		state := onboardingState(3, &store.OnboardingProgress{})
+		if state.Completed != 0 || state.Total != 4 || state.Done || state.NextStep != onboardingRoles || !state.CanAddSampleData {
+			t.Errorf("state = %+v", state)
+		}
+	})
+
+	t.Run("next step skips what is done", func(t *testing.T) {
+		state := onboardingState(3, &store.OnboardingProgress{Roles: 2, ShiftTemplates: 5})
+		if state.Completed != 2 || state.NextStep != onboardingEmployees || state.CanAddSampleData {
+			t.Errorf("state = %+v", state)
+		}
+	})
+
+	t.Run("done", func(t *testing.T) {
+		state := onboardingState(3, &store.OnboardingProgress{Roles: 1, Employees: 1, ShiftTemplates: 1, Schedules: 1})
+		if !state.Done || state.NextStep != "" {
+			t.Errorf("state = %+v", state)
+		}
+	})
+}
+
+func TestOnboardingSampleData(t *testing.T) {
+	// A Wednesday; the sample schedule starts the following Monday
+	sample := onboardingSampleData(time.Date(2026, 6, 3, 15, 0, 0, 0, time.UTC))
+
+	if sample.ScheduleStart != "2026-06-08" || sample.ScheduleEnd != "2026-06-14" {
+		t.Errorf("schedule = %s to %s, want next Monday to Sunday", sample.ScheduleStart, sample.ScheduleEnd)
+	}
+	if monday := onboardingSampleData(time.Date(2026, 6, 8, 9, 0, 0, 0, time.UTC)); monday.ScheduleStart != "2026-06-15" {
+		t.Errorf("schedule from a Monday starts %s, want the next week", monday.ScheduleStart)
+	}
+
+	roles := make(map[string]bool)
+	for _, role := range sample.Roles {
+		roles[role.Name] = true
+	}
+	for _, employee := range sample.Employees {
+		for _, name := range employee.Roles {
+			if !roles[name] {
+				t.Errorf("employee %s has unknown role %q", employee.FullName, name)
+			}
+		}
+	}
+	for _, template := range sample.ShiftTemplates {
+		for _, name := range template.Roles {
+			if !roles[name] {
+				t.Errorf("template %s has unknown role %q", template.Name, name)
+			}
+		}
+	}
+}
+
+func TestAddOnboardingSampleData(t *testing.T) {
+	t.Run("created", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.store.Onboarding = &store.MockOnboardingStorer{
+			SeedSampleFunc: func(_ context.Context, restaurantID int64, sample *store.SampleData) (*store.SampleDataResult, error) {
+				return &store.SampleDataResult{Roles: len(sample.Roles), ScheduleID: 9}, nil
+			},
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/onboarding/sample-data", ""), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		var body struct {
+			Data store.SampleDataResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.ScheduleID != 9 || body.Data.Roles != 4 {
+			t.Errorf("result = %+v", body.Data)
+		}
+	})
+
+	t.Run("restaurant not empty", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.store.Onboarding = &store.MockOnboardingStorer{
+			SeedSampleFunc: func(context.Context, int64, *store.SampleData) (*store.SampleDataResult, error) {
+				return nil, store.ErrRestaurantNotEmpty
+			},
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/onboarding/sample-data", ""), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("not the owner", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID+1)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/onboarding", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
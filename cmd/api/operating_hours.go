@@ -0,0 +1,506 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// maxHoursCalendarDays bounds the effective hours endpoint to about a quarter
+	maxHoursCalendarDays  = 93
+	defaultExceptionsDays = 365
+)
+
+type OperatingDayPayload struct {
+	DayOfWeek *int   `json:"day_of_week" validate:"required,min=0,max=6"`
+	Closed    bool   `json:"closed"`
+	OpenTime  string `json:"open_time" validate:"required_unless=Closed true"`
+	CloseTime string `json:"close_time" validate:"required_unless=Closed true"`
+}
+
+// UpdateOperatingHoursPayload replaces the whole week; send an empty days list to remove the hours
+type UpdateOperatingHoursPayload struct {
+	Enforcement string                `json:"enforcement" validate:"required,oneof=off warn block"`
+	Days        []OperatingDayPayload `json:"days" validate:"omitempty,len=7,dive"`
+}
+
+type CreateHoursExceptionPayload struct {
+	Date      string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Closed    bool   `json:"closed"`
+	OpenTime  string `json:"open_time" validate:"required_unless=Closed true"`
+	CloseTime string `json:"close_time" validate:"required_unless=Closed true"`
+}
+
+// GetOperatingHours godoc
+//
+//	@Summary		Gets a restaurant's operating hours
+//	@Description	Returns the weekly hours and how shifts outside them are treated (off, warn or block). Days is empty until hours are set.
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.OperatingHours
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours [get]
+func (app *application) getOperatingHoursHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	hours, err := app.store.OperatingHours.Get(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, hours); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateOperatingHours godoc
+//
+//	@Summary		Sets a restaurant's operating hours
+//	@Description	Replaces the weekly hours. Days must list all seven weekdays (0 = Sunday) once each, or be empty to remove the hours. Times are HH:MM and ignored on closed days.
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		UpdateOperatingHoursPayload	true	"Operating hours"
+//	@Success		200				{object}	store.OperatingHours
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours [put]
+func (app *application) updateOperatingHoursHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var payload UpdateOperatingHoursPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hours := &store.OperatingHours{
+		RestaurantID: restaurant.ID,
+		Enforcement:  store.HoursEnforcement(payload.Enforcement),
+		Days:         make([]*store.OperatingDay, 0, len(payload.Days)),
+	}
+
+	seen := make(map[int]bool)
+	for _, d := range payload.Days {
+		if seen[*d.DayOfWeek] {
+			app.badRequestResponse(w, r, fmt.Errorf("day_of_week %d is listed more than once", *d.DayOfWeek))
+			return
+		}
+		seen[*d.DayOfWeek] = true
+
+		opens, closes, err := parseHoursRange(d.Closed, d.OpenTime, d.CloseTime)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		hours.Days = append(hours.Days, &store.OperatingDay{
+			DayOfWeek: *d.DayOfWeek,
+			Closed:    d.Closed,
+			OpenTime:  opens,
+			CloseTime: closes,
+		})
+	}
+
+	if err := app.store.OperatingHours.Replace(r.Context(), hours); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	updated, err := app.store.OperatingHours.Get(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, updated); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetEffectiveHours godoc
+//
+//	@Summary		Gets the hours in effect for a date range
+//	@Description	Resolves the weekly hours and exceptions into one entry per date, for shading the schedule calendar. Dates without hours are omitted. Defaults to the restaurant's current week, from its week_start in its timezone; at most 93 days.
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{array}		store.EffectiveHours
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours/calendar [get]
+func (app *application) getEffectiveHoursHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	weekStart, weekEnd := restaurant.WeekOf(restaurant.Today(time.Now()))
+
+	from, to, err := parseDateRange(r, weekStart, weekEnd)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if to.Sub(from) >= maxHoursCalendarDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("date range must be at most %d days", maxHoursCalendarDays))
+		return
+	}
+
+	hours, exceptions, err := app.loadOperatingHours(r.Context(), restaurant.ID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, effectiveHours(hours, exceptions, from, to)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetHoursExceptions godoc
+//
+//	@Summary		Lists special hours
+//	@Description	Lists holiday closures and other one-off hours between from and to. Defaults to the next 365 days.
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{array}		store.HoursException
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours/exceptions [get]
+func (app *application) getHoursExceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	today := restaurant.Today(time.Now())
+	from, to, err := parseDateRange(r, today, today.AddDate(0, 0, defaultExceptionsDays))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	exceptions, err := app.store.OperatingHours.ListExceptions(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, exceptions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateHoursException godoc
+//
+//	@Summary		Adds special hours for a date
+//	@Description	Overrides the weekly hours on one date, e.g. closed for a holiday or open late for an event
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		CreateHoursExceptionPayload	true	"Special hours"
+//	@Success		201				{object}	store.HoursException
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours/exceptions [post]
+func (app *application) createHoursExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var payload CreateHoursExceptionPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	opens, closes, err := parseHoursRange(payload.Closed, payload.OpenTime, payload.CloseTime)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	exception := &store.HoursException{
+		RestaurantID: restaurant.ID,
+		Date:         store.DateOnly(payload.Date),
+		Name:         payload.Name,
+		Closed:       payload.Closed,
+		OpenTime:     opens,
+		CloseTime:    closes,
+	}
+
+	if err := app.store.OperatingHours.CreateException(r.Context(), exception); err != nil {
+		if errors.Is(err, store.ErrDuplicateHoursException) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, exception); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteHoursException godoc
+//
+//	@Summary		Removes special hours
+//	@Description	Removes a date's special hours so the weekly hours apply again
+//	@Tags			operating-hours
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			exceptionID		path	int	true	"Exception ID"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/operating-hours/exceptions/{exceptionID} [delete]
+func (app *application) deleteHoursExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	exceptionID, err := strconv.ParseInt(chi.URLParam(r, "exceptionID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	exception, err := app.store.OperatingHours.GetException(r.Context(), exceptionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if exception.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("exception not found"))
+		return
+	}
+
+	if err := app.store.OperatingHours.DeleteException(r.Context(), exceptionID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// hoursCheck holds what's needed to test shifts against a restaurant's operating hours
+type hoursCheck struct {
+	hours      *store.OperatingHours
+	exceptions []*store.HoursException
+}
+
+// operatingHoursCheck loads the hours covering from..to; the check is nil when enforcement is off
+func (app *application) operatingHoursCheck(ctx context.Context, restaurantID int64, from, to time.Time) (*hoursCheck, error) {
+	hours, exceptions, err := app.loadOperatingHours(ctx, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if hours.Enforcement == store.HoursEnforcementOff {
+		return nil, nil
+	}
+
+	return &hoursCheck{hours: hours, exceptions: exceptions}, nil
+}
+
+// violation describes how the shift falls outside operating hours, or returns nil if it doesn't
+func (c *hoursCheck) violation(shift *store.ScheduledShift) error {
+	if c == nil {
+		return nil
+	}
+
+	effective := c.hours.On(shift.ShiftDate, c.exceptions)
+	if effective == nil || effective.Covers(shift.StartTime, shift.EndTime) {
+		return nil
+	}
+
+	date := shift.ShiftDate.Format("2006-01-02")
+	if effective.Closed {
+		return fmt.Errorf("the restaurant is closed on %s", date)
+	}
+	return fmt.Errorf("shift falls outside operating hours on %s (%s - %s)", date, effective.OpenTime, effective.CloseTime)
+}
+
+func (c *hoursCheck) blocks() bool {
+	return c != nil && c.hours.Enforcement == store.HoursEnforcementBlock
+}
+
+func (app *application) loadOperatingHours(ctx context.Context, restaurantID int64, from, to time.Time) (*store.OperatingHours, []*store.HoursException, error) {
+	hours, err := app.store.OperatingHours.Get(ctx, restaurantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exceptions, err := app.store.OperatingHours.ListExceptions(ctx, restaurantID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hours, exceptions, nil
+}
+
+// effectiveHours lists the hours for each date from..to, skipping dates without hours
+func effectiveHours(hours *store.OperatingHours, exceptions []*store.HoursException, from, to time.Time) []*store.EffectiveHours {
+	days := []*store.EffectiveHours{}
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		if effective := hours.On(date, exceptions); effective != nil {
+			days = append(days, effective)
+		}
+	}
+	return days
+}
+
+// transformHoursForEmail lists the restaurant's hours for each day of a schedule
+func transformHoursForEmail(days []*store.EffectiveHours, locale i18n.Locale) []ScheduleEmailHours {
+	result := make([]ScheduleEmailHours, 0, len(days))
+	for _, d := range days {
+		result = append(result, ScheduleEmailHours{
+			Date:      i18n.FormatDay(locale, string(d.Date)),
+			Closed:    d.Closed,
+			OpenTime:  i18n.FormatTimeOfDay(locale, string(d.OpenTime)),
+			CloseTime: i18n.FormatTimeOfDay(locale, string(d.CloseTime)),
+			Note:      mailer.PlainText(d.Exception),
+		})
+	}
+	return result
+}
+
+// parseHoursRange validates HH:MM open and close times; closed days carry no times
+func parseHoursRange(closed bool, openTime, closeTime string) (store.TimeOfDay, store.TimeOfDay, error) {
+	if closed {
+		return "", "", nil
+	}
+
+	if _, err := time.Parse("15:04", openTime); err != nil {
+		return "", "", errors.New("open time must be in format HH:MM")
+	}
+	if _, err := time.Parse("15:04", closeTime); err != nil {
+		return "", "", errors.New("close time must be in format HH:MM")
+	}
+	if openTime >= closeTime {
+		return "", "", errors.New("close time must be after open time")
+	}
+
+	return store.TimeOfDay(openTime + ":00"), store.TimeOfDay(closeTime + ":00"), nil
+}
+
+// parseDateRange reads the from and to query parameters, falling back to the given defaults
+func parseDateRange(r *http.Request, defaultFrom, defaultTo time.Time) (time.Time, time.Time, error) {
+	from, to := defaultFrom, defaultTo
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("from must be in format YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return from, to, errors.New("to must be in format YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return from, to, errors.New("to must not be before from")
+	}
+
+	return from, to, nil
+}
+
+func dateOnly(t time.Time) store.DateOnly {
+	return store.DateOnly(t.Format("2006-01-02"))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestHoursCheck(t *testing.T) {
+	hours := &store.OperatingHours{Enforcement: store.HoursEnforcementBlock}
+	for day := 0; day < 7; day++ {
+		hours.Days = append(hours.Days, &store.OperatingDay{
+			DayOfWeek: day,
+			Closed:    day == int(time.Sunday),
+			OpenTime:  "10:00:00",
+			CloseTime: "22:00:00",
+		})
+	}
+
+	exceptions := []*store.HoursException{
+		{Date: "2026-12-25", Name: "Christmas", Closed: true},
+		{Date: "2026-12-31", Name: "New Year's Eve", OpenTime: "10:00:00", CloseTime: "23:30:00"},
+		{Date: "2026-12-24", Name: "Christmas Eve", HolidayRegion: "US"},
+	}
+
+	check := &hoursCheck{hours: hours, exceptions: exceptions}
+
+	shift := func(date, start, end string) *store.ScheduledShift {
+		d, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &store.ScheduledShift{ShiftDate: d, StartTime: store.TimeOfDay(start), EndTime: store.TimeOfDay(end)}
+	}
+
+	tests := []struct {
+		name    string
+		shift   *store.ScheduledShift
+		outside bool
+	}{
+		{"within hours", shift("2026-12-22", "10:00", "22:00"), false},
+		{"starts before opening", shift("2026-12-22", "09:30", "15:00"), true},
+		{"ends after closing", shift("2026-12-22", "17:00", "22:30"), true},
+		{"weekly closed day", shift("2026-12-27", "12:00", "16:00"), true},
+		{"holiday closure", shift("2026-12-25", "12:00", "16:00"), true},
+		{"extended holiday hours", shift("2026-12-31", "18:00", "23:30"), false},
+		{"public holiday on regular hours", shift("2026-12-24", "10:00", "22:00"), false},
+		{"outside regular hours on a public holiday", shift("2026-12-24", "17:00", "23:00"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := check.violation(tt.shift); (err != nil) != tt.outside {
+				t.Errorf("expected outside=%v, got %v", tt.outside, err)
+			}
+		})
+	}
+
+	t.Run("should not check restaurants without hours", func(t *testing.T) {
+		empty := &hoursCheck{hours: &store.OperatingHours{Enforcement: store.HoursEnforcementBlock}}
+		if err := empty.violation(shift("2026-12-22", "03:00", "04:00")); err != nil {
+			t.Errorf("expected no violation, got %v", err)
+		}
+	})
+
+	t.Run("should not check when enforcement is off", func(t *testing.T) {
+		var off *hoursCheck
+		if err := off.violation(shift("2026-12-25", "12:00", "16:00")); err != nil || off.blocks() {
+			t.Errorf("expected a nil check to allow everything, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type accessKey string
+
+const accessCtx accessKey = "restaurantAccess"
+
+// restaurantAccess is what the signed-in user may do with a restaurant's shifts:
+// everything as its owner, or only the shifts of their roles as a shift lead
+type restaurantAccess struct {
+	owner   bool
+	roleIDs map[int64]bool
+}
+
+func (a *restaurantAccess) canManageRole(roleID int64) bool {
+	return a.owner || a.roleIDs[roleID]
+}
+
+// filterShifts drops the shifts the user can't see
+func (a *restaurantAccess) filterShifts(shifts []*store.ScheduledShift) []*store.ScheduledShift {
+	if a.owner {
+		return shifts
+	}
+	visible := make([]*store.ScheduledShift, 0, len(shifts))
+	for _, shift := range shifts {
+		if a.roleIDs[shift.RoleID] {
+			visible = append(visible, shift)
+		}
+	}
+	return visible
+}
+
+// scope names the slice of shifts the access covers, to keep revalidated listings apart
+func (a *restaurantAccess) scope() string {
+	if a.owner {
+		return "all"
+	}
+	ids := make([]string, 0, len(a.roleIDs))
+	for id := range a.roleIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	sort.Strings(ids)
+	return "roles-" + strings.Join(ids, ".")
+}
+
+// restaurantAccessFor resolves the user's access to the restaurant; store.ErrNotFound when they have none
+func (app *application) restaurantAccessFor(ctx context.Context, restaurant *store.Restaurant, userID int64) (*restaurantAccess, error) {
+	if restaurant.UserID == userID {
+		return &restaurantAccess{owner: true}, nil
+	}
+
+	member, err := app.restaurantMember(ctx, restaurant.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Permission != store.PermissionShiftLead {
+		return nil, store.ErrNotFound
+	}
+
+	access := &restaurantAccess{roleIDs: make(map[int64]bool, len(member.RoleIDs))}
+	for _, id := range member.RoleIDs {
+		access.roleIDs[id] = true
+	}
+	return access, nil
+}
+
+// checkScheduleAccess is checkRestaurantAccess that also lets the restaurant's shift leads read its schedules
+func (app *application) checkScheduleAccess(ctx context.Context, restaurantID, userID int64) error {
+	err := app.checkRestaurantAccess(ctx, restaurantID, userID)
+	if !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	member, merr := app.restaurantMember(ctx, restaurantID, userID)
+	if merr != nil {
+		return merr
+	}
+	if member.Permission != store.PermissionShiftLead {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// checkShiftAccess lets the owner and the restaurant's shift leads through to the shift handlers,
+// with their access in the request context
+func (app *application) checkShiftAccess(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := getUserFromContext(r)
+		restaurant := getRestaurantFromContext(r)
+
+		// archived restaurants are read-only until unarchived
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && restaurant.Archived() {
+			app.conflictResponse(w, r, errRestaurantArchived)
+			return
+		}
+
+		access, err := app.restaurantAccessFor(r.Context(), restaurant, user.ID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				app.notFoundResponse(w, r, errors.New("restaurant not found"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), accessCtx, access)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
+
+func getAccessFromContext(r *http.Request) *restaurantAccess {
+	access, _ := r.Context().Value(accessCtx).(*restaurantAccess)
+	return access
+}
+
+// restaurantShiftFromURL loads the shift in the URL, checking it belongs to the restaurant
+// and schedule and is for a role the user manages
+func (app *application) restaurantShiftFromURL(w http.ResponseWriter, r *http.Request) (*store.ScheduledShift, bool) {
+	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
+		return nil, false
+	}
+
+	shift, err := app.store.ScheduledShifts.GetByID(r.Context(), shiftID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	restaurant := getRestaurantFromContext(r)
+	if shift.RestaurantID != restaurant.ID || chi.URLParam(r, "scheduleID") != strconv.FormatInt(shift.ScheduleID, 10) {
+		app.notFoundResponse(w, r, errors.New("shift not found"))
+		return nil, false
+	}
+
+	if !getAccessFromContext(r).canManageRole(shift.RoleID) {
+		app.forbiddenResponse(w, r, errRoleOutOfScope(shift.RoleID))
+		return nil, false
+	}
+
+	return shift, true
+}
+
+func errRoleOutOfScope(roleID int64) error {
+	return fmt.Errorf("you don't manage shifts for role %d", roleID)
+}
+
+// scheduleInRestaurant loads the schedule in the URL, checking it belongs to the restaurant
+func (app *application) scheduleInRestaurant(w http.ResponseWriter, r *http.Request) (*store.Schedule, bool) {
+	scheduleID, err := strconv.ParseInt(chi.URLParam(r, "scheduleID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid schedule ID"))
+		return nil, false
+	}
+
+	schedule, err := app.store.Schedules.GetByID(r.Context(), scheduleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if schedule.RestaurantID != getRestaurantFromContext(r).ID {
+		app.notFoundResponse(w, r, errors.New("schedule not found"))
+		return nil, false
+	}
+
+	return schedule, true
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+)
+
+func TestCheckRestaurantOwnership(t *testing.T) {
+	app, _ := newMockedApplication(t, 2)
+
+	rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/kiosks", `{"name": "Front"}`), app.mount())
+
+	checkResponseCode(t, http.StatusNotFound, rr.Code)
+}
+
+func TestShiftLeadAccess(t *testing.T) {
+	// user 1 leads role 5 in restaurant 3, which user 2 owns
+	setup := func(t *testing.T, member bool) *application {
+		app, _ := newMockedApplication(t, 2)
+		app.store.Members = &store.MockMemberStorer{
+			ListByRestaurantFunc: func(_ context.Context, restaurantID int64) ([]*store.Member, error) {
+				if !member {
+					return nil, nil
+				}
+				return []*store.Member{{RestaurantID: restaurantID, UserID: 1, Permission: store.PermissionShiftLead, RoleIDs: []int64{5}}}, nil
+			},
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3}, nil
+			},
+		}
+		app.store.Versions = &store.MockVersionStorer{
+			ScheduledShiftsFunc: func(context.Context, int64) (*store.CollectionVersion, error) {
+				return &store.CollectionVersion{Count: 2, LastModified: time.Now()}, nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(_ context.Context, scheduleID int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{
+					{ID: 10, ScheduleID: scheduleID, RestaurantID: 3, RoleID: 5},
+					{ID: 11, ScheduleID: scheduleID, RestaurantID: 3, RoleID: 6},
+				}, nil
+			},
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				return &store.ScheduledShift{ID: id, ScheduleID: 4, RestaurantID: 3, RoleID: id - 5}, nil
+			},
+		}
+		return app
+	}
+
+	t.Run("lists only the lead's roles", func(t *testing.T) {
+		app := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/4/shifts", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data []*store.ScheduledShift `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != 1 || body.Data[0].ID != 10 {
+			t.Errorf("shifts = %+v, want only shift 10", body.Data)
+		}
+	})
+
+	t.Run("gets a shift of their role", func(t *testing.T) {
+		app := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/4/shifts/10", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("shift of another role", func(t *testing.T) {
+		app := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/4/shifts/11", ""), app.mount())
+
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("creating a shift for another role", func(t *testing.T) {
+		app := setup(t, true)
+		body := `{"role_id": 6, "shift_date": "2025-11-03T00:00:00Z", "start_time": "09:00", "end_time": "17:00"}`
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/4/shifts", body), app.mount())
+
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("owner-only routes", func(t *testing.T) {
+		app := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/4/publish", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		app := setup(t, false)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/4/shifts", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestAddMember(t *testing.T) {
+	setup := func(t *testing.T) (*application, *store.Member) {
+		app, mocks := newMockedApplication(t, testUserID)
+		created := &store.Member{}
+		mocks.users.GetByEmailFunc = func(_ context.Context, email string) (*store.User, error) {
+			return &store.User{ID: 7, Email: email}, nil
+		}
+		mocks.roles.GetByIDsFunc = func(_ context.Context, ids []int64) ([]*store.Role, error) {
+			roles := []*store.Role{}
+			for _, id := range ids {
+				restaurantID := int64(3)
+				if id == 99 {
+					restaurantID = 8
+				}
+				roles = append(roles, &store.Role{ID: id, RestaurantID: restaurantID})
+			}
+			return roles, nil
+		}
+		app.store.Members = &store.MockMemberStorer{
+			CreateFunc: func(_ context.Context, m *store.Member) error {
+				*created = *m
+				return nil
+			},
+		}
+		return app, created
+	}
+
+	t.Run("added", func(t *testing.T) {
+		app, created := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/members", `{"email": "lead@example.com", "role_ids": [5]}`), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if created.UserID != 7 || created.Permission != store.PermissionShiftLead {
+			t.Errorf("member = %+v", created)
+		}
+	})
+
+	t.Run("role of another restaurant", func(t *testing.T) {
+		app, created := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/members", `{"email": "lead@example.com", "role_ids": [5, 99]}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if created.UserID != 0 {
+			t.Error("member was added")
+		}
+	})
+}
+
+func TestRestaurantAccessCache(t *testing.T) {
+	lead := []*store.Member{{RestaurantID: 3, UserID: 9, Permission: store.PermissionShiftLead, RoleIDs: []int64{5}}}
+
+	t.Run("the restaurant in the context skips the cache", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.roles.CreateFunc = func(context.Context, *store.Role) error { return nil }
+		mocks.ownership.GetFunc = func(context.Context, int64) (*cache.Ownership, error) {
+			t.Error("ownership cache read for the restaurant in the context")
+			return nil, nil
+		}
+		lookups := 0
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			lookups++
+			return &store.Restaurant{ID: id, UserID: testUserID}, nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if lookups != 1 {
+			t.Errorf("restaurant lookups = %d, want 1", lookups)
+		}
+	})
+
+	t.Run("miss reads the database and fills the cache", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		app.store.Members = &store.MockMemberStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Member, error) { return lead, nil },
+		}
+		var cached *cache.Ownership
+		mocks.ownership.SetFunc = func(_ context.Context, restaurantID int64, ownership *cache.Ownership) error {
+			if restaurantID == 3 {
+				cached = ownership
+			}
+			return nil
+		}
+
+		if err := app.checkRestaurantAccess(context.Background(), 3, testUserID); err != nil {
+			t.Fatal(err)
+		}
+
+		if cached == nil || cached.OwnerID != testUserID || cached.Members[9] == nil || cached.Members[9].RoleIDs[0] != 5 {
+			t.Errorf("cached %+v, want the owner and the shift lead", cached)
+		}
+	})
+
+	t.Run("hit skips the database", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.ownership.GetFunc = func(context.Context, int64) (*cache.Ownership, error) {
+			return &cache.Ownership{OwnerID: testUserID, Members: map[int64]*cache.MemberAccess{
+				9: {Permission: store.PermissionShiftLead, RoleIDs: []int64{5}},
+			}}, nil
+		}
+		mocks.ownership.SetFunc = func(context.Context, int64, *cache.Ownership) error {
+			t.Error("cache refilled on a hit")
+			return nil
+		}
+		mocks.restaurants.GetByIDFunc = func(context.Context, int64) (*store.Restaurant, error) {
+			t.Error("restaurant read on a hit")
+			return nil, store.ErrNotFound
+		}
+		app.store.Members = &store.MockMemberStorer{}
+
+		if err := app.checkRestaurantAccess(context.Background(), 3, testUserID); err != nil {
+			t.Errorf("owner: %v", err)
+		}
+		if err := app.checkScheduleAccess(context.Background(), 3, 9); err != nil {
+			t.Errorf("shift lead: %v", err)
+		}
+		if err := app.checkScheduleAccess(context.Background(), 3, 10); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("stranger: err = %v, want not found", err)
+		}
+	})
+
+	t.Run("cached owner is authoritative", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.ownership.GetFunc = func(context.Context, int64) (*cache.Ownership, error) {
+			return &cache.Ownership{OwnerID: testUserID + 1}, nil
+		}
+
+		if err := app.checkRestaurantAccess(context.Background(), 3, testUserID); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("err = %v, want not found", err)
+		}
+	})
+
+	t.Run("cache errors fall back to the database", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.ownership.GetFunc = func(context.Context, int64) (*cache.Ownership, error) {
+			return nil, context.DeadlineExceeded
+		}
+
+		if err := app.checkRestaurantAccess(context.Background(), 3, testUserID); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("member changes invalidate it", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		app.store.Members = &store.MockMemberStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Member, error) { return lead, nil },
+			DeleteFunc:           func(context.Context, int64, int64) error { return nil },
+		}
+		var deleted []int64
+		mocks.ownership.DeleteFunc = func(_ context.Context, restaurantID int64) error {
+			deleted = append(deleted, restaurantID)
+			return nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/members/9", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if len(deleted) != 1 || deleted[0] != 3 {
+			t.Errorf("invalidated %v, want [3]", deleted)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/export"
+	"github.com/balebbae/RESA/internal/storage"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// UserDataExport godoc
+//
+//	@Summary		Exports everything stored about the current user
+//	@Description	Downloads a ZIP of JSON files for a data access request: user.json (the account), subscription.json, notifications.json, and restaurants/{id}.json with all the data of every restaurant the user owns, archived ones included. Uploaded files are listed but not included; the restaurant export has them.
+//	@Tags			users
+//	@Produce		application/zip
+//	@Success		200	{file}		file
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/data-export [get]
+func (app *application) userDataExportHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	ctx := store.UsePrimary(r.Context())
+	exportedAt := time.Now().UTC()
+
+	subscription, err := app.store.Subscriptions.GetByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	notifications, err := app.allNotifications(ctx, store.UserRecipient(user.ID))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var restaurants []*RestaurantExport
+	for _, archived := range []bool{false, true} {
+		owned, err := app.store.Restaurants.ListByUser(ctx, user.ID, archived)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		for _, restaurant := range owned {
+			data, err := app.collectRestaurantExport(ctx, restaurant)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			restaurants = append(restaurants, data)
+		}
+	}
+
+	w.Header().Set("Content-Type", export.ZIPContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%d-data-%s.zip"`, user.ID, exportedAt.Format("20060102-150405")))
+	w.WriteHeader(http.StatusOK)
+
+	archive := export.NewArchive(w)
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"user.json", map[string]any{"exported_at": exportedAt, "user": user}},
+		{"subscription.json", subscription},
+		{"notifications.json", notifications},
+	}
+	for _, data := range restaurants {
+		files = append(files, struct {
+			name string
+			v    any
+		}{fmt.Sprintf("restaurants/%d.json", data.Restaurant.ID), data})
+	}
+
+	for _, f := range files {
+		if err := archive.AddJSON(f.name, f.v); err != nil {
+			app.logger.Warnw("user data export interrupted", "user_id", user.ID, "error", err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		app.logger.Warnw("user data export interrupted", "user_id", user.ID, "error", err)
+	}
+}
+
+// allNotifications reads every notification of the recipient, a page at a time
+func (app *application) allNotifications(ctx context.Context, recipient store.NotificationRecipient) ([]*store.Notification, error) {
+	all := []*store.Notification{}
+	q := store.NotificationQuery{Limit: maxNotificationsLimit}
+
+	for {
+		page, err := app.store.Notifications.List(ctx, recipient, q)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < q.Limit {
+			return all, nil
+		}
+		q.BeforeID = page[len(page)-1].ID
+	}
+}
+
+// EraseEmployee godoc
+//
+//	@Summary		Erases an employee's personal data
+//	@Description	Anonymizes an employee for a privacy request: their name becomes "Former employee #ID" everywhere it was copied (shifts, published schedule snapshots, shift history), their email, language and avatar are cleared, and their documents, certifications and notifications are deleted. The employee's shifts and roles are kept so scheduling history and hour totals still add up. This cannot be undone.
+//	@Tags			employees
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			employeeID		path		int	true	"Employee ID"
+//	@Success		200				{object}	store.EmployeeErasure
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/employees/{employeeID}/erase [post]
+func (app *application) eraseEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	employee, ok := app.restaurantEmployeeFromURL(w, r, restaurant.ID)
+	if !ok {
+		return
+	}
+
+	erasure, err := app.eraseEmployee(r.Context(), employee)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, erasure); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// eraseEmployee anonymizes the employee and deletes their stored files
+func (app *application) eraseEmployee(ctx context.Context, employee *store.Employee) (*store.EmployeeErasure, error) {
+	erasure, err := app.store.Employees.Erase(ctx, employee.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Retention policies erase from a background job, outside any route
+	app.retireResponses(ctx, employee.RestaurantID)
+
+	// The rows are gone, so files left behind are only unreachable; log and move on
+	if app.blobs != nil {
+		for _, key := range erasure.DocumentKeys {
+			if err := app.blobs.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				app.logger.Warnw("failed to delete erased employee's document", "key", key, "error", err)
+			}
+		}
+		if erasure.AvatarID != nil {
+			app.deleteAvatarVariants(ctx, employee, *erasure.AvatarID)
+		}
+	}
+
+	return erasure, nil
+}
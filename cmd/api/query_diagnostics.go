@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// QueryDiagnostics is how the store's queries have performed since the server started
+type QueryDiagnostics struct {
+	// Queries are timings by store method, the most time spent first
+	Queries []store.QueryStat `json:"queries"`
+	// SlowQueries are the latest queries over DB_SLOW_QUERY_MS, newest first, with
+	// their EXPLAIN ANALYZE plans when Explain is on
+	SlowQueries []store.SlowQuery `json:"slow_queries"`
+	Explain     bool              `json:"explain"`
+}
+
+// queryDiagnosticsHandler serves query timings and slow queries next to /debug/vars,
+// so regressions show up without an APM
+func (app *application) queryDiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
+	if app.queryMetrics == nil {
+		app.notFoundResponse(w, r, errors.New("query metrics are not enabled"))
+		return
+	}
+
+	diagnostics := &QueryDiagnostics{
+		Queries:     app.queryMetrics.Stats(),
+		SlowQueries: app.queryMetrics.SlowQueries(),
+		Explain:     app.queryMetrics.Explains(),
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, diagnostics); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
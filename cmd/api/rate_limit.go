@@ -0,0 +1,93 @@
+package main
+
+import (
+	"expvar"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/ratelimiter"
+)
+
+var (
+	rateLimitNearLimit = expvar.NewInt("rate_limit_near_limit")
+	rateLimitExceeded  = expvar.NewInt("rate_limit_exceeded")
+)
+
+// RateLimitStatus is the calling client's request budget
+type RateLimitStatus struct {
+	Enabled bool `json:"enabled"`
+	// Identity is what the budget is kept by: the client's IP address
+	Identity     string `json:"identity,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Remaining    int    `json:"remaining,omitempty"`
+	ResetSeconds int    `json:"reset_seconds,omitempty"`
+	// WindowSeconds is how long each budget lasts
+	WindowSeconds int `json:"window_seconds,omitempty"`
+}
+
+// GetRateLimit godoc
+//
+//	@Summary		Shows the caller's rate limit budget
+//	@Description	Reports how many requests the calling client may make in the current window, how many are left (this request included) and the seconds until the budget resets, the same figures as the RateLimit-* headers on every response. enabled is false when rate limiting is off.
+//	@Tags			ops
+//	@Produce		json
+//	@Success		200	{object}	RateLimitStatus
+//	@Router			/rate-limit [get]
+func (app *application) getRateLimitHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := app.settings().rateLimiter
+	response := RateLimitStatus{Enabled: cfg.Enabled}
+	if response.Enabled {
+		identity := rateLimitIdentity(r)
+		status := app.rateLimiter.Peek(identity)
+		response.Identity = identity
+		response.Limit = status.Limit
+		response.Remaining = status.Remaining
+		response.ResetSeconds = resetSeconds(status.Reset)
+		response.WindowSeconds = int(cfg.TimeFrame.Seconds())
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// rateLimitIdentity is who a request is counted against: its client's address without
+// the port, which RealIP has already taken from X-Forwarded-For behind a proxy
+func rateLimitIdentity(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, status ratelimiter.Status) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(status.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(resetSeconds(status.Reset)))
+}
+
+// warnNearRateLimit logs, once a window, the request that leaves a client with a tenth
+// of its budget
+func (app *application) warnNearRateLimit(r *http.Request, identity string, status ratelimiter.Status) {
+	if status.Remaining != status.Limit/10 {
+		return
+	}
+
+	rateLimitNearLimit.Add(1)
+	app.logger.Warnw("rate limit nearly reached",
+		"identity", identity,
+		"limit", status.Limit,
+		"remaining", status.Remaining,
+		"method", r.Method,
+		"path", redactedPath(r),
+	)
+}
+
+// resetSeconds rounds a wait up to whole seconds, as the headers carry it
+func resetSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
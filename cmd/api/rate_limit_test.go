@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/ratelimiter"
+)
+
+func TestRateLimit(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.runtime.Store(&runtimeSettings{rateLimiter: ratelimiter.Config{Enabled: true, RequestPerTimeFrame: 3, TimeFrame: time.Minute}})
+	app.rateLimiter = ratelimiter.NewFixedWindowLimiter(3, time.Minute)
+	mux := app.mount()
+
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/rate-limit", nil)
+		req.RemoteAddr = remoteAddr
+		return executeRequest(req, mux)
+	}
+
+	rr := get("203.0.113.7:51000")
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	if rr.Header().Get("RateLimit-Limit") != "3" || rr.Header().Get("RateLimit-Remaining") != "2" || rr.Header().Get("RateLimit-Reset") != "60" {
+		t.Errorf("headers = %v", rr.Header())
+	}
+
+	var body struct {
+		Data RateLimitStatus `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if got := body.Data; !got.Enabled || got.Identity != "203.0.113.7" || got.Remaining != 2 || got.WindowSeconds != 60 {
+		t.Errorf("status = %+v", got)
+	}
+
+	// another connection from the same address shares the budget
+	get("203.0.113.7:51001")
+	get("203.0.113.7:51002")
+	rr = get("203.0.113.7:51003")
+	checkResponseCode(t, http.StatusTooManyRequests, rr.Code)
+	if rr.Header().Get("RateLimit-Remaining") != "0" || rr.Header().Get("Retry-After") != "60" {
+		t.Errorf("headers = %v, want no budget and a retry in seconds", rr.Header())
+	}
+
+	if rr := get("198.51.100.2:40000"); rr.Code != http.StatusOK || rr.Header().Get("RateLimit-Remaining") != "2" {
+		t.Errorf("another client got %d with %s remaining", rr.Code, rr.Header().Get("RateLimit-Remaining"))
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// RepairDenormalized godoc
+//
+//	@Summary		Re-syncs denormalized shift fields
+//	@Description	Rewrites role name/color and employee name on the restaurant's scheduled shifts from their source rows and reports how many had drifted
+//	@Tags			restaurant
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Restaurant ID"
+//	@Success		200	{object}	store.DenormalizedRepair
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{id}/repair-denormalized [post]
+func (app *application) repairDenormalizedHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurant.ID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	repair, err := app.store.ScheduledShifts.RepairDenormalized(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if repair.RoleFields > 0 || repair.EmployeeNames > 0 {
+		app.logger.Warnw("repaired denormalized shift fields",
+			"restaurant_id", restaurant.ID,
+			"role_fields", repair.RoleFields,
+			"employee_names", repair.EmployeeNames)
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, repair); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// runDenormalizedRepair periodically re-syncs denormalized shift fields across all restaurants
+func (app *application) runDenormalizedRepair(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		repair, err := app.store.ScheduledShifts.RepairDenormalized(context.Background(), 0)
+		if err != nil {
+			app.logger.Errorw("denormalized repair failed", "error", err)
+			continue
+		}
+
+		if repair.RoleFields > 0 || repair.EmployeeNames > 0 {
+			app.retireAllResponses(context.Background())
+			app.logger.Warnw("repaired denormalized shift fields",
+				"role_fields", repair.RoleFields,
+				"employee_names", repair.EmployeeNames)
+		}
+	}
+}
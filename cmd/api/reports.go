@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	defaultHeatmapWeeks = 8
+	maxHeatmapWeeks     = 52
+)
+
+// CoverageHeatmap is the average staffing of each role by weekday (0 = Sunday) and hour of day
+// over the weeks up to yesterday
+type CoverageHeatmap struct {
+	Weeks int            `json:"weeks"`
+	From  store.DateOnly `json:"from"`
+	To    store.DateOnly `json:"to"`
+	Roles []RoleCoverage `json:"roles"`
+}
+
+// RoleCoverage holds one role's matrices, indexed [day_of_week][hour]. Staffed is the
+// average number of assigned shifts covering the hour; Open is the average left unassigned.
+type RoleCoverage struct {
+	RoleID    int64          `json:"role_id"`
+	RoleName  string         `json:"role_name"`
+	RoleColor string         `json:"role_color"`
+	Staffed   [7][24]float64 `json:"staffed"`
+	Open      [7][24]float64 `json:"open"`
+}
+
+// GetCoverageHeatmap godoc
+//
+//	@Summary		Staffing heatmap from past shifts
+//	@Description	Averages the last N full weeks of scheduled shifts into a day-of-week × hour-of-day matrix per role: staffed is the assigned headcount, open the shifts nobody was assigned to. A shift counts toward every hour it overlaps.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			weeks			query		int	false	"Weeks of history (default 8, max 52)"
+//	@Success		200				{object}	CoverageHeatmap
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/heatmap [get]
+func (app *application) getCoverageHeatmapHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	weeks := defaultHeatmapWeeks
+	if weeksStr := r.URL.Query().Get("weeks"); weeksStr != "" {
+		var err error
+		weeks, err = strconv.Atoi(weeksStr)
+		if err != nil || weeks < 1 || weeks > maxHeatmapWeeks {
+			app.badRequestResponse(w, r, fmt.Errorf("weeks must be between 1 and %d", maxHeatmapWeeks))
+			return
+		}
+	}
+
+	// whole weeks ending yesterday, so every weekday appears exactly weeks times
+	today := restaurant.Today(time.Now())
+	from := store.DateOnly(today.AddDate(0, 0, -7*weeks).Format("2006-01-02"))
+	to := store.DateOnly(today.AddDate(0, 0, -1).Format("2006-01-02"))
+
+	cells, err := app.store.ScheduledShifts.ListCoverage(r.Context(), restaurant.ID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, coverageHeatmap(cells, weeks, from, to)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// coverageHeatmap lays the cells, ordered by role, out as one matrix pair per role
+func coverageHeatmap(cells []*store.CoverageCell, weeks int, from, to store.DateOnly) *CoverageHeatmap {
+	heatmap := &CoverageHeatmap{Weeks: weeks, From: from, To: to, Roles: []RoleCoverage{}}
+
+	average := func(count int) float64 {
+		return math.Round(float64(count)/float64(weeks)*100) / 100
+	}
+
+	var role *RoleCoverage
+	for _, cell := range cells {
+		if role == nil || role.RoleID != cell.RoleID {
+			heatmap.Roles = append(heatmap.Roles, RoleCoverage{
+				RoleID:    cell.RoleID,
+				RoleName:  cell.RoleName,
+				RoleColor: cell.RoleColor,
+			})
+			role = &heatmap.Roles[len(heatmap.Roles)-1]
+		}
+		role.Staffed[cell.DayOfWeek][cell.Hour] = average(cell.Staffed)
+		role.Open[cell.DayOfWeek][cell.Hour] = average(cell.Shifts - cell.Staffed)
+	}
+
+	return heatmap
+}
+
+const (
+	defaultDemandDays = 56
+	maxDemandDays     = 366
+	// minCorrelationDays is how many days with both figures a correlation needs
+	minCorrelationDays = 3
+)
+
+// DemandVsStaffing sets a restaurant's daily sales and covers beside the hours it
+// scheduled, as a basis for staffing to demand
+type DemandVsStaffing struct {
+	From     store.DateOnly      `json:"from"`
+	To       store.DateOnly      `json:"to"`
+	Days     []DemandStaffingDay `json:"days"`
+	Weekdays []WeekdayDemand     `json:"weekdays"`
+	// SalesHoursCorrelation is the Pearson correlation of daily sales with scheduled hours,
+	// over days with both; absent with too few days or figures that never vary
+	SalesHoursCorrelation  *float64 `json:"sales_hours_correlation,omitempty"`
+	CoversHoursCorrelation *float64 `json:"covers_hours_correlation,omitempty"`
+}
+
+// DemandStaffingDay is a date's demand and scheduled hours, with the demand per hour
+// worked when both are known
+type DemandStaffingDay struct {
+	*store.DemandDay
+	SalesPerLaborHourCents *int64   `json:"sales_per_labor_hour_cents,omitempty"`
+	CoversPerLaborHour     *float64 `json:"covers_per_labor_hour,omitempty"`
+	// Holiday names the public holiday on the date, which explains many a busy or quiet day
+	Holiday string `json:"holiday,omitempty"`
+}
+
+// WeekdayDemand averages one weekday (0 = Sunday) over the days in the range that have sales data
+type WeekdayDemand struct {
+	DayOfWeek             int     `json:"day_of_week"`
+	Days                  int     `json:"days"`
+	AverageSalesCents     int64   `json:"average_sales_cents"`
+	AverageCovers         float64 `json:"average_covers"`
+	AverageScheduledHours float64 `json:"average_scheduled_hours"`
+}
+
+// GetDemandVsStaffing godoc
+//
+//	@Summary		Compares sales with scheduled hours
+//	@Description	Puts each day's imported sales and covers beside the hours of the shifts scheduled that day, from through to (default the 8 weeks up to yesterday, at most 366 days). Days gain sales and covers per labor hour, weekdays are averaged over the days with sales data, and the correlations show how closely staffing has followed demand. Public holidays among the restaurant's special dates are named in holiday.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{object}	DemandVsStaffing
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/demand-vs-staffing [get]
+func (app *application) getDemandVsStaffingHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	yesterday := restaurant.Today(time.Now()).AddDate(0, 0, -1)
+	from, to, err := parseDateRange(r, yesterday.AddDate(0, 0, 1-defaultDemandDays), yesterday)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if to.Sub(from) >= maxDemandDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("the range can be at most %d days", maxDemandDays))
+		return
+	}
+
+	days, err := app.store.Sales.DemandByDay(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	exceptions, err := app.store.OperatingHours.ListExceptions(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	report := demandVsStaffing(days, dateOnly(from), dateOnly(to))
+	holidays := holidayNames(exceptions)
+	for i := range report.Days {
+		report.Days[i].Holiday = holidays[report.Days[i].Date]
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func demandVsStaffing(days []*store.DemandDay, from, to store.DateOnly) *DemandVsStaffing {
+	report := &DemandVsStaffing{From: from, To: to, Days: make([]DemandStaffingDay, 0, len(days)), Weekdays: make([]WeekdayDemand, 7)}
+
+	type totals struct {
+		days          int
+		sales, covers float64
+		hours         float64
+	}
+	var weekdays [7]totals
+	var salesX, salesY, coversX, coversY []float64
+
+	for _, d := range days {
+		day := DemandStaffingDay{DemandDay: d}
+		if d.ScheduledHours > 0 {
+			if d.SalesCents != nil {
+				perHour := int64(math.Round(float64(*d.SalesCents) / d.ScheduledHours))
+				day.SalesPerLaborHourCents = &perHour
+			}
+			if d.Covers != nil {
+				perHour := math.Round(float64(*d.Covers)/d.ScheduledHours*100) / 100
+				day.CoversPerLaborHour = &perHour
+			}
+		}
+		report.Days = append(report.Days, day)
+
+		if d.SalesCents == nil && d.Covers == nil {
+			continue
+		}
+		date, err := d.Date.ToTime()
+		if err != nil {
+			continue
+		}
+		w := &weekdays[date.Weekday()]
+		w.days++
+		w.hours += d.ScheduledHours
+		if d.SalesCents != nil {
+			w.sales += float64(*d.SalesCents)
+			salesX, salesY = append(salesX, float64(*d.SalesCents)), append(salesY, d.ScheduledHours)
+		}
+		if d.Covers != nil {
+			w.covers += float64(*d.Covers)
+			coversX, coversY = append(coversX, float64(*d.Covers)), append(coversY, d.ScheduledHours)
+		}
+	}
+
+	for i, w := range weekdays {
+		report.Weekdays[i] = WeekdayDemand{DayOfWeek: i, Days: w.days}
+		if w.days == 0 {
+			continue
+		}
+		n := float64(w.days)
+		report.Weekdays[i].AverageSalesCents = int64(math.Round(w.sales / n))
+		report.Weekdays[i].AverageCovers = math.Round(w.covers/n*100) / 100
+		report.Weekdays[i].AverageScheduledHours = math.Round(w.hours/n*100) / 100
+	}
+
+	report.SalesHoursCorrelation = correlation(salesX, salesY)
+	report.CoversHoursCorrelation = correlation(coversX, coversY)
+
+	return report
+}
+
+// correlation is the Pearson correlation coefficient of x and y, rounded to two places
+func correlation(x, y []float64) *float64 {
+	n := float64(len(x))
+	if len(x) < minCorrelationDays {
+		return nil
+	}
+
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+
+	r := math.Round(cov/math.Sqrt(varX*varY)*100) / 100
+	return &r
+}
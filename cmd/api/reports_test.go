@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCoverageHeatmap(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	var from, to store.DateOnly
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListCoverageFunc: func(_ context.Context, _ int64, f, t store.DateOnly) ([]*store.CoverageCell, error) {
+			from, to = f, t
+			return []*store.CoverageCell{
+				{RoleID: 1, RoleName: "Server", DayOfWeek: 5, Hour: 18, Shifts: 8, Staffed: 6},
+				{RoleID: 1, RoleName: "Server", DayOfWeek: 5, Hour: 19, Shifts: 4, Staffed: 4},
+				{RoleID: 2, RoleName: "Cook", DayOfWeek: 0, Hour: 9, Shifts: 2, Staffed: 0},
+			}, nil
+		},
+	}
+
+	t.Run("averages each role's shifts over the weeks", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/reports/heatmap?weeks=2", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data CoverageHeatmap `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		heatmap := body.Data
+		if len(heatmap.Roles) != 2 || heatmap.Roles[0].RoleName != "Server" || heatmap.Roles[1].RoleName != "Cook" {
+			t.Fatalf("roles = %+v", heatmap.Roles)
+		}
+		server := heatmap.Roles[0]
+		if server.Staffed[5][18] != 3 || server.Open[5][18] != 1 || server.Staffed[5][19] != 2 || server.Open[5][19] != 0 {
+			t.Errorf("server friday evening: staffed %v, open %v", server.Staffed[5], server.Open[5])
+		}
+		if heatmap.Roles[1].Open[0][9] != 1 {
+			t.Errorf("cook sunday 9am open = %v, want 1", heatmap.Roles[1].Open[0][9])
+		}
+		if heatmap.Weeks != 2 || from != heatmap.From || to != heatmap.To || from >= to {
+			t.Errorf("range = %s to %s over %d weeks, queried %s to %s", heatmap.From, heatmap.To, heatmap.Weeks, from, to)
+		}
+	})
+
+	t.Run("rejects weeks out of range", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/reports/heatmap?weeks=53", ""), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type requestLogKey string
+
+const requestLogCtx requestLogKey = "requestLog"
+
+const redactedValue = "[REDACTED]"
+
+type requestLogConfig struct {
+	// sampleEvery logs one in this many successful requests to a sampled read
+	// route; 1 or less logs every request
+	sampleEvery int
+}
+
+// sampledRoutes are the read routes polled often enough that logging every
+// successful request drowns out the rest; failures are always logged
+var sampledRoutes = map[string]bool{
+	"/health":                                                   true,
+	"/notifications":                                            true,
+	"/notifications/unread-count":                               true,
+	"/restaurants":                                              true,
+	"/restaurants/{restaurantID}":                               true,
+	"/restaurants/{restaurantID}/features":                      true,
+	"/restaurants/{restaurantID}/roles":                         true,
+	"/restaurants/{restaurantID}/employees":                     true,
+	"/restaurants/{restaurantID}/events":                        true,
+	"/restaurants/{restaurantID}/schedules":                     true,
+	"/restaurants/{restaurantID}/schedules/{scheduleID}":        true,
+	"/restaurants/{restaurantID}/schedules/{scheduleID}/shifts": true,
+}
+
+// sensitiveHeaders are logged as redactedValue
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Stripe-Signature":    true,
+}
+
+// requestLogFields is filled in by middlewares further down the chain, which
+// only see the logger's request through this shared pointer
+type requestLogFields struct {
+	userID int64
+}
+
+// setLoggedUser records the authenticated user on the request's log entry
+func setLoggedUser(ctx context.Context, userID int64) {
+	if fields, ok := ctx.Value(requestLogCtx).(*requestLogFields); ok {
+		fields.userID = userID
+	}
+}
+
+// requestLoggerMiddleware logs one structured entry per request once it has
+// been served. Secrets in the path, query and headers are redacted, and
+// successful requests to sampledRoutes are sampled.
+func (app *application) requestLoggerMiddleware() func(http.Handler) http.Handler {
+	sampleEvery := uint64(max(app.config.requestLog.sampleEvery, 1))
+	var counts sync.Map // route -> *atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			fields := &requestLogFields{}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), requestLogCtx, fields)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := routePattern(r)
+			sampled := status < http.StatusBadRequest &&
+				(r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+				sampledRoutes[route] && sampleEvery > 1
+			if sampled {
+				n, _ := counts.LoadOrStore(route, new(atomic.Uint64))
+				if n.(*atomic.Uint64).Add(1)%sampleEvery != 1 {
+					return
+				}
+			}
+
+			keysAndValues := []any{
+				"request_id", middleware.GetReqID(r.Context()),
+				"method", r.Method,
+				"route", route,
+				"path", redactedPath(r),
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"duration_ms", time.Since(start).Milliseconds(),
+				"remote_addr", r.RemoteAddr,
+			}
+			if query := redactedQuery(r.URL.Query()); query != "" {
+				keysAndValues = append(keysAndValues, "query", query)
+			}
+			if fields.userID != 0 {
+				keysAndValues = append(keysAndValues, "user_id", fields.userID)
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if restaurantID := rctx.URLParam("restaurantID"); restaurantID != "" {
+					keysAndValues = append(keysAndValues, "restaurant_id", restaurantID)
+				}
+			}
+			if sampled {
+				keysAndValues = append(keysAndValues, "sample_rate", sampleEvery)
+			}
+
+			switch {
+			case status >= http.StatusInternalServerError:
+				keysAndValues = append(keysAndValues, "headers", redactedHeaders(r.Header))
+				app.logger.Errorw("request", keysAndValues...)
+			case status >= http.StatusBadRequest:
+				keysAndValues = append(keysAndValues, "headers", redactedHeaders(r.Header))
+				app.logger.Warnw("request", keysAndValues...)
+			default:
+				app.logger.Infow("request", keysAndValues...)
+			}
+		})
+	}
+}
+
+// routePattern is the matched route without its /v1 or /v2 prefix or trailing
+// slash, or empty when nothing matched
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+
+	pattern := rctx.RoutePattern()
+	for _, prefix := range []string{"/" + string(apiV1), "/" + string(apiV2)} {
+		if rest, ok := strings.CutPrefix(pattern, prefix); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			pattern = rest
+			break
+		}
+	}
+	if pattern != "/" {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// isSensitiveKey reports whether a parameter or field of this name holds a secret
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "code", "state", "key", "sig":
+		return true
+	}
+	for _, part := range []string{"password", "token", "secret", "signature", "api_key", "api-key", "apikey", "credential"} {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedPath is the request path with the values of sensitive route
+// parameters, such as an activation token, replaced
+func redactedPath(r *http.Request) string {
+	path := r.URL.Path
+
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return path
+	}
+	for i, key := range rctx.URLParams.Keys {
+		if value := rctx.URLParams.Values[i]; value != "" && isSensitiveKey(key) {
+			path = strings.ReplaceAll(path, value, redactedValue)
+		}
+	}
+	return path
+}
+
+// redactedQuery is the encoded query with the values of sensitive parameters replaced
+func redactedQuery(query url.Values) string {
+	for key, values := range query {
+		if isSensitiveKey(key) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return query.Encode()
+}
+
+// redactedHeaders is a copy of the headers with credentials replaced
+func redactedHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for key, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] || isSensitiveKey(key) {
+			headers[key] = redactedValue
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	return headers
+}
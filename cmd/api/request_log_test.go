@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestLogger(t *testing.T) {
+	newApp := func(sampleEvery int) (*application, *observer.ObservedLogs) {
+		app := newTestApplication(t)
+		core, logs := observer.New(zapcore.InfoLevel)
+		app.logger = zap.New(core).Sugar()
+		app.config.requestLog.sampleEvery = sampleEvery
+		return app, logs
+	}
+	requestEntries := func(logs *observer.ObservedLogs) []observer.LoggedEntry {
+		return logs.FilterMessage("request").AllUntimed()
+	}
+
+	t.Run("logs the route, user and restaurant", func(t *testing.T) {
+		app, logs := newApp(1)
+		token, _ := app.authenticator.GenerateToken(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/v1/restaurants/1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		executeRequest(req, app.mount())
+
+		entries := requestEntries(logs)
+		if len(entries) != 1 {
+			t.Fatalf("got %d request entries, want 1", len(entries))
+		}
+		fields := entries[0].ContextMap()
+		if fields["route"] != "/restaurants/{restaurantID}" || fields["restaurant_id"] != "1" || fields["user_id"] == nil {
+			t.Errorf("unexpected fields %v", fields)
+		}
+	})
+
+	t.Run("redacts tokens and credentials", func(t *testing.T) {
+		app, logs := newApp(1)
+
+		req, _ := http.NewRequest(http.MethodPut, "/v1/users/activate/s3cr3t-activation?password=hunter2", nil)
+		req.Header.Set("Authorization", "Bearer hunter3")
+		executeRequest(req, app.mount())
+
+		if len(requestEntries(logs)) != 1 {
+			t.Fatalf("expected a request entry")
+		}
+		for _, entry := range logs.AllUntimed() {
+			logged := fmt.Sprint(entry.Message, entry.ContextMap())
+			for _, secret := range []string{"s3cr3t-activation", "hunter2", "hunter3"} {
+				if strings.Contains(logged, secret) {
+					t.Errorf("%q was logged: %s", secret, logged)
+				}
+			}
+		}
+	})
+
+	t.Run("samples busy read routes", func(t *testing.T) {
+		app, logs := newApp(3)
+		mux := app.mount()
+
+		for range 4 {
+			req, _ := http.NewRequest(http.MethodGet, "/v1/health", nil)
+			req.SetBasicAuth(app.config.auth.basic.user, app.config.auth.basic.pass)
+			executeRequest(req, mux)
+		}
+
+		entries := requestEntries(logs)
+		if len(entries) != 2 {
+			t.Fatalf("got %d request entries, want 2 of 4", len(entries))
+		}
+		if rate := entries[0].ContextMap()["sample_rate"]; rate != uint64(3) {
+			t.Errorf("got sample_rate %v, want 3", rate)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// cacheStatusHeader tells clients and load tests whether a response came from the cache
+const cacheStatusHeader = "X-Cache"
+
+// response cache counters, exposed on /debug/vars
+var (
+	responseCacheHits   = expvar.NewInt("response_cache_hits")
+	responseCacheMisses = expvar.NewInt("response_cache_misses")
+)
+
+// cacheResponse serves a restaurant GET route from the response cache. A miss
+// runs the handler and keeps the data of a 200, keyed by the restaurant's
+// version, the user and the URL, so it is never shared between users. Any
+// successful write to the restaurant moves it to a new version. Without Redis
+// there is no response cache and every request reaches the handler.
+func (app *application) cacheResponse(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		responses := app.cacheStorage.Responses
+		if responses == nil || r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		restaurant := getRestaurantFromContext(r)
+		key := fmt.Sprintf("u%d:%s", getUserFromContext(r).ID, r.URL.RequestURI())
+
+		version, err := responses.Version(ctx, restaurant.ID)
+		if err != nil {
+			app.logger.Warnw("response cache version failed", "restaurant_id", restaurant.ID, "error", err)
+			next(w, r)
+			return
+		}
+
+		cached, err := responses.Get(ctx, restaurant.ID, version, key)
+		if err != nil {
+			app.logger.Warnw("response cache get failed", "restaurant_id", restaurant.ID, "error", err)
+		}
+		if cached != nil {
+			responseCacheHits.Add(1)
+			w.Header().Set(cacheStatusHeader, "HIT")
+			if cached.ETag != "" {
+				w.Header().Set("ETag", cached.ETag)
+				w.Header().Set("Cache-Control", "private, no-cache")
+				if cached.LastModified != "" {
+					w.Header().Set("Last-Modified", cached.LastModified)
+				}
+				if etagMatches(r, cached.ETag) {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+			app.jsonResponse(w, r, http.StatusOK, cached.Data)
+			return
+		}
+
+		responseCacheMisses.Add(1)
+		w.Header().Set(cacheStatusHeader, "MISS")
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		if rec.status != http.StatusOK {
+			return
+		}
+
+		// Both API versions keep the payload under data; the envelope is rebuilt per request
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(rec.body.Bytes(), &body); err != nil || body.Data == nil {
+			return
+		}
+
+		response := &cache.CachedResponse{
+			Data:         body.Data,
+			ETag:         w.Header().Get("ETag"),
+			LastModified: w.Header().Get("Last-Modified"),
+		}
+		if err := responses.Set(ctx, restaurant.ID, version, key, response); err != nil {
+			app.logger.Warnw("response cache set failed", "restaurant_id", restaurant.ID, "error", err)
+		}
+	}
+}
+
+// bumpResponseVersion retires the restaurant's cached responses after any
+// write to it that succeeded
+func (app *application) bumpResponseVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.cacheStorage.Responses == nil || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		app.retireResponses(r.Context(), getRestaurantFromContext(r).ID)
+	})
+}
+
+// retireResponses moves the restaurant to a new response cache version. Every
+// write to the restaurant outside its HTTP routes, over gRPC or from a
+// background job, calls it once the write has succeeded.
+func (app *application) retireResponses(ctx context.Context, restaurantID int64) {
+	if app.cacheStorage.Responses == nil {
+		return
+	}
+	if err := app.cacheStorage.Responses.Bump(ctx, restaurantID); err != nil {
+		app.logger.Warnw("response cache bump failed", "restaurant_id", restaurantID, "error", err)
+	}
+}
+
+// retireAllResponses moves every restaurant to a new response cache version,
+// for background jobs whose writes span restaurants
+func (app *application) retireAllResponses(ctx context.Context) {
+	if app.cacheStorage.Responses == nil {
+		return
+	}
+	if err := app.cacheStorage.Responses.BumpAll(ctx); err != nil {
+		app.logger.Warnw("response cache bump failed", "error", err)
+	}
+}
+
+// responseRecorder passes a response through while keeping a copy of its body
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
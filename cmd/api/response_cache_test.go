@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/balebbae/RESA/pkg/schedulingpb"
+)
+
+func TestResponseCache(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+
+	versions := map[int64]int64{}
+	entries := map[string]*cache.CachedResponse{}
+	app.cacheStorage.Responses = &cache.MockResponseStorer{
+		VersionFunc: func(_ context.Context, restaurantID int64) (int64, error) {
+			return versions[restaurantID], nil
+		},
+		BumpFunc: func(_ context.Context, restaurantID int64) error {
+			versions[restaurantID]++
+			return nil
+		},
+		GetFunc: func(_ context.Context, restaurantID, version int64, key string) (*cache.CachedResponse, error) {
+			return entries[fmt.Sprintf("%d-%d-%s", restaurantID, version, key)], nil
+		},
+		SetFunc: func(_ context.Context, restaurantID, version int64, key string, response *cache.CachedResponse) error {
+			entries[fmt.Sprintf("%d-%d-%s", restaurantID, version, key)] = response
+			return nil
+		},
+	}
+
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 3}, nil
+		},
+	}
+	app.store.Versions = &store.MockVersionStorer{
+		ScheduledShiftsFunc: func(context.Context, int64) (*store.CollectionVersion, error) {
+			return &store.CollectionVersion{Count: 1, LastModified: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}, nil
+		},
+	}
+	loads := 0
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+			loads++
+			return []*store.ScheduledShift{{ID: 10, ScheduleID: 5, RestaurantID: 3, RoleID: 1}}, nil
+		},
+	}
+	mocks.restaurants.UpdateFunc = func(context.Context, *store.Restaurant) error { return nil }
+
+	listShifts := func(t *testing.T, wantCache string) *http.Response {
+		t.Helper()
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/shifts", ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get(cacheStatusHeader); got != wantCache {
+			t.Errorf("%s = %q, want %q", cacheStatusHeader, got, wantCache)
+		}
+		return rr.Result()
+	}
+
+	t.Run("serves the second read from the cache", func(t *testing.T) {
+		miss := listShifts(t, "MISS")
+		hit := listShifts(t, "HIT")
+
+		if loads != 1 {
+			t.Errorf("shifts loaded %d times, want 1", loads)
+		}
+		if hit.Header.Get("ETag") == "" || hit.Header.Get("ETag") != miss.Header.Get("ETag") {
+			t.Errorf("ETag = %q, want the handler's %q", hit.Header.Get("ETag"), miss.Header.Get("ETag"))
+		}
+	})
+
+	t.Run("answers a matching If-None-Match from the cache", func(t *testing.T) {
+		req := authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/shifts", "")
+		req.Header.Set("If-None-Match", listShifts(t, "HIT").Header.Get("ETag"))
+
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("a failed write keeps the cache", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/3", `{"schedule_lock_hours":1000}`), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+
+		listShifts(t, "HIT")
+	})
+
+	t.Run("a write to the restaurant retires it", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/3", `{"name":"Cedar Grill"}`), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		listShifts(t, "MISS")
+		if loads != 2 {
+			t.Errorf("shifts loaded %d times, want 2", loads)
+		}
+	})
+}
+
+func TestResponsesRetiredOutsideRoutes(t *testing.T) {
+	setup := func(t *testing.T) (*application, map[int64]int) {
+		app, _ := newMockedApplication(t, testUserID)
+		bumps := map[int64]int{}
+		app.cacheStorage.Responses = &cache.MockResponseStorer{
+			BumpFunc: func(_ context.Context, restaurantID int64) error {
+				bumps[restaurantID]++
+				return nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		return app, bumps
+	}
+
+	t.Run("a shift created over gRPC", func(t *testing.T) {
+		app, bumps := setup(t)
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3}, nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			CreateFunc: func(_ context.Context, shift *store.ScheduledShift) error {
+				shift.ID = 10
+				return nil
+			},
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				return &store.ScheduledShift{ID: id, ScheduleID: 5, RestaurantID: 3, RoleID: 1}, nil
+			},
+		}
+		ctx := context.WithValue(context.Background(), userCtx, &store.User{ID: testUserID})
+		server := &schedulingServer{app: app}
+
+		_, err := server.CreateShift(ctx, &schedulingpb.CreateShiftRequest{
+			RestaurantId: 3, ScheduleId: 5, RoleId: 1, ShiftDate: "2026-03-02", StartTime: "09:00", EndTime: "17:00",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bumps[3] != 1 {
+			t.Errorf("restaurant 3 bumped %d times, want 1", bumps[3])
+		}
+	})
+
+	t.Run("an employee erased by a retention policy", func(t *testing.T) {
+		app, bumps := setup(t)
+		terminated := store.DateOnly("2020-01-31")
+		app.store.Retention = &store.MockRetentionStorer{
+			TerminatedBeforeFunc: func(context.Context, int64, store.DateOnly) ([]*store.Employee, error) {
+				return []*store.Employee{{ID: 7, RestaurantID: 3, TerminatedOn: &terminated}}, nil
+			},
+			MarkRunFunc: func(context.Context, *store.RetentionPolicy, time.Time) error { return nil },
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			EraseFunc: func(_ context.Context, id int64) (*store.EmployeeErasure, error) {
+				return &store.EmployeeErasure{Employee: &store.Employee{ID: id, RestaurantID: 3}}, nil
+			},
+		}
+		policy := &store.RetentionPolicy{ID: 1, RestaurantID: 3, Kind: store.RetentionAnonymizeTerminated, AfterMonths: 12, Enabled: true}
+
+		if _, err := app.applyRetentionPolicy(context.Background(), policy, time.Now(), false, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		if bumps[3] != 1 {
+			t.Errorf("restaurant 3 bumped %d times, want 1", bumps[3])
+		}
+	})
+}
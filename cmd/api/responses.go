@@ -0,0 +1,71 @@
+package main
+
+import (
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// Response types are the API's representation of store models. Handlers map
+// models into them instead of serializing store structs, so columns can be
+// added, renamed or denormalized without the API changing with them, and a new
+// API version can get a type and mapper of its own next to these.
+
+// ShiftResponse is a scheduled shift as the API sends it
+type ShiftResponse struct {
+	ID              int64           `json:"id"`
+	ScheduleID      int64           `json:"schedule_id"`
+	RestaurantID    int64           `json:"restaurant_id"`
+	ShiftTemplateID *int64          `json:"shift_template_id,omitempty"`
+	RoleID          int64           `json:"role_id"`
+	EmployeeID      *int64          `json:"employee_id,omitempty"`
+	ShiftDate       time.Time       `json:"shift_date"`
+	StartTime       store.TimeOfDay `json:"start_time"`
+	EndTime         store.TimeOfDay `json:"end_time"`
+	Notes           string          `json:"notes"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	EmployeeName    *string         `json:"employee_name,omitempty"`
+	RoleName        string          `json:"role_name"`
+	RoleColor       string          `json:"role_color"`
+	// Training shifts let an employee work a role they don't hold yet, alongside the trainer's shift
+	Training       bool   `json:"training"`
+	TrainerShiftID *int64 `json:"trainer_shift_id,omitempty"`
+	// EventID links extra staffing to the event it covers
+	EventID *int64 `json:"event_id,omitempty"`
+	// Warnings are computed for the request, never stored
+	Warnings []store.ShiftWarning `json:"warnings,omitempty"`
+}
+
+func newShiftResponse(shift *store.ScheduledShift) *ShiftResponse {
+	return &ShiftResponse{
+		ID:              shift.ID,
+		ScheduleID:      shift.ScheduleID,
+		RestaurantID:    shift.RestaurantID,
+		ShiftTemplateID: shift.ShiftTemplateID,
+		RoleID:          shift.RoleID,
+		EmployeeID:      shift.EmployeeID,
+		ShiftDate:       shift.ShiftDate,
+		StartTime:       shift.StartTime,
+		EndTime:         shift.EndTime,
+		Notes:           shift.Notes,
+		CreatedAt:       shift.CreatedAt,
+		UpdatedAt:       shift.UpdatedAt,
+		EmployeeName:    shift.EmployeeName,
+		RoleName:        shift.RoleName,
+		RoleColor:       shift.RoleColor,
+		Training:        shift.Training,
+		TrainerShiftID:  shift.TrainerShiftID,
+		EventID:         shift.EventID,
+		Warnings:        shift.Warnings,
+	}
+}
+
+// newShiftResponses maps the shifts in order; none is an empty list, not null
+func newShiftResponses(shifts []*store.ScheduledShift) []*ShiftResponse {
+	responses := make([]*ShiftResponse, 0, len(shifts))
+	for _, shift := range shifts {
+		responses = append(responses, newShiftResponse(shift))
+	}
+	return responses
+}
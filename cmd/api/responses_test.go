@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestShiftResponseKeepsWireFormat(t *testing.T) {
+	templateID, employeeID, trainerShiftID, eventID := int64(3), int64(4), int64(5), int64(6)
+	name := "Ana Diaz"
+	shift := &store.ScheduledShift{
+		ID:              1,
+		ScheduleID:      2,
+		RestaurantID:    1,
+		ShiftTemplateID: &templateID,
+		RoleID:          7,
+		EmployeeID:      &employeeID,
+		ShiftDate:       time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		StartTime:       "09:00",
+		EndTime:         "17:00",
+		Notes:           "Opening",
+		CreatedAt:       time.Date(2026, 5, 20, 8, 0, 0, 0, time.UTC),
+		UpdatedAt:       time.Date(2026, 5, 21, 8, 0, 0, 0, time.UTC),
+		EmployeeName:    &name,
+		RoleName:        "Server",
+		RoleColor:       "#ff0000",
+		Training:        true,
+		TrainerShiftID:  &trainerShiftID,
+		EventID:         &eventID,
+		Warnings:        []store.ShiftWarning{store.WarningDoubleBooked},
+	}
+
+	want, err := json.Marshal(shift)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(newShiftResponse(shift))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("response = %s\nwant the store shift's JSON %s", got, want)
+	}
+
+	if shifts := newShiftResponses(nil); shifts == nil || len(shifts) != 0 {
+		t.Errorf("newShiftResponses(nil) = %v, want an empty list", shifts)
+	}
+}
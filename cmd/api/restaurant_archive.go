@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/export"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+var (
+	errRestaurantArchived = errors.New("restaurant is archived; unarchive it to make changes")
+	errExportBeforeDelete = errors.New("archive the restaurant and download its export before deleting it")
+)
+
+// RestaurantExport is everything stored for a restaurant, as of ExportedAt
+type RestaurantExport struct {
+	ExportedAt             time.Time                      `json:"exported_at"`
+	Restaurant             *store.Restaurant              `json:"restaurant"`
+	Roles                  []*store.Role                  `json:"roles"`
+	Employees              []*store.Employee              `json:"employees"`
+	Certifications         []*store.Certification         `json:"certifications"`
+	EmployeeCertifications []*store.EmployeeCertification `json:"employee_certifications"`
+	ShiftTemplates         []*store.ShiftTemplate         `json:"shift_templates"`
+	Schedules              []*store.Schedule              `json:"schedules"`
+	ScheduleDayNotes       []*store.ScheduleDayNote       `json:"schedule_day_notes"`
+	ScheduledShifts        []*store.ScheduledShift        `json:"scheduled_shifts"`
+	Events                 []*store.Event                 `json:"events"`
+	OperatingHours         *store.OperatingHours          `json:"operating_hours"`
+	HoursExceptions        []*store.HoursException        `json:"hours_exceptions"`
+	EmailTemplate          *store.EmailTemplate           `json:"email_template,omitempty"`
+	// Documents lists the files' details; the ZIP export also holds the files themselves
+	Documents   []*store.Document   `json:"documents"`
+	AuditLog    []*store.AuditEntry `json:"audit_log"`
+	TimeEntries []*store.TimeEntry  `json:"time_entries"`
+}
+
+// ArchiveRestaurant godoc
+//
+//	@Summary		Archives a Restaurant
+//	@Description	Hides the restaurant from the restaurant list and makes it read-only, keeping all of its data. Archiving an archived restaurant is a no-op.
+//	@Tags			restaurant
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.Restaurant
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/archive [post]
+func (app *application) archiveRestaurantHandler(w http.ResponseWriter, r *http.Request) {
+	app.setRestaurantArchived(w, r, true)
+}
+
+// UnarchiveRestaurant godoc
+//
+//	@Summary		Unarchives a Restaurant
+//	@Description	Lists the restaurant again and allows changes to it
+//	@Tags			restaurant
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{object}	store.Restaurant
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/unarchive [post]
+func (app *application) unarchiveRestaurantHandler(w http.ResponseWriter, r *http.Request) {
+	app.setRestaurantArchived(w, r, false)
+}
+
+func (app *application) setRestaurantArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	ctx := r.Context()
+
+	if err := app.store.Restaurants.SetArchived(ctx, restaurant, archived); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The cached copy would still show the old archive state
+	if app.config.redisCfg.enabled && app.cacheStorage.Restaurants != nil {
+		if err := app.cacheStorage.Restaurants.Set(ctx, restaurant); err != nil {
+			app.logger.Warnw("failed to update restaurant in cache", "restaurant_id", restaurant.ID, "error", err)
+		}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, restaurant); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// ExportRestaurant godoc
+//
+//	@Summary		Exports all of a Restaurant's data
+//	@Description	Downloads everything stored for the restaurant: a ZIP with one JSON file per kind of record and the uploaded documents, or with format=json a single JSON document without the document files. A permanent delete requires an export taken after the restaurant was archived.
+//	@Tags			restaurant
+//	@Produce		application/zip
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			format			query		string	false	"zip (default) or json"
+//	@Success		200				{file}		file
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/export [get]
+func (app *application) exportRestaurantHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "json" {
+		app.badRequestResponse(w, r, errors.New("format must be zip or json"))
+		return
+	}
+
+	// The export has to include every change made before it, which a lagging replica may not have
+	ctx := store.UsePrimary(r.Context())
+
+	data, err := app.collectRestaurantExport(ctx, restaurant)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("restaurant-%d-export-%s", restaurant.ID, data.ExportedAt.Format("20060102-150405"))
+
+	if format == "json" {
+		body, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(body); err != nil {
+			app.logger.Warnw("restaurant export interrupted", "restaurant_id", restaurant.ID, "error", err)
+			return
+		}
+
+		app.markRestaurantExported(ctx, restaurant, data.ExportedAt)
+		return
+	}
+
+	// The archive streams, so a failure past this point can only cut it short;
+	// the export is recorded only once the whole archive was written
+	w.Header().Set("Content-Type", export.ZIPContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	if err := app.writeRestaurantArchive(ctx, export.NewArchive(w), data); err != nil {
+		app.logger.Warnw("restaurant export interrupted", "restaurant_id", restaurant.ID, "error", err)
+		return
+	}
+
+	app.markRestaurantExported(ctx, restaurant, data.ExportedAt)
+}
+
+func (app *application) markRestaurantExported(ctx context.Context, restaurant *store.Restaurant, exportedAt time.Time) {
+	if err := app.store.Restaurants.MarkExported(ctx, restaurant, exportedAt); err != nil {
+		app.logger.Warnw("failed to record restaurant export", "restaurant_id", restaurant.ID, "error", err)
+	}
+}
+
+func (app *application) collectRestaurantExport(ctx context.Context, restaurant *store.Restaurant) (*RestaurantExport, error) {
+	var err error
+	data := &RestaurantExport{
+		// Taken before reading so nothing changed after it is missing from the export
+		ExportedAt: time.Now().UTC(),
+		Restaurant: restaurant,
+	}
+
+	if data.Roles, err = app.store.Roles.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.Employees, err = app.store.Employees.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.Certifications, err = app.store.Certifications.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+
+	data.EmployeeCertifications = []*store.EmployeeCertification{}
+	for _, employee := range data.Employees {
+		certifications, err := app.store.Certifications.ListByEmployee(ctx, employee.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.EmployeeCertifications = append(data.EmployeeCertifications, certifications...)
+	}
+
+	if data.ShiftTemplates, err = app.store.ShiftTemplates.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.Schedules, err = app.store.Schedules.ListByRestaurant(ctx, restaurant.ID, false); err != nil {
+		return nil, err
+	}
+	// Archived schedules are hidden from the schedule list, not from the export
+	archived, err := app.store.Schedules.ListByRestaurant(ctx, restaurant.ID, true)
+	if err != nil {
+		return nil, err
+	}
+	data.Schedules = append(data.Schedules, archived...)
+
+	data.ScheduleDayNotes = []*store.ScheduleDayNote{}
+	data.ScheduledShifts = []*store.ScheduledShift{}
+	for _, schedule := range data.Schedules {
+		notes, err := app.store.ScheduleNotes.ListBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.ScheduleDayNotes = append(data.ScheduleDayNotes, notes...)
+
+		shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.ScheduledShifts = append(data.ScheduledShifts, shifts...)
+	}
+
+	if data.Events, err = app.store.Events.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.OperatingHours, err = app.store.OperatingHours.Get(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.HoursExceptions, err = app.store.OperatingHours.ListExceptions(ctx, restaurant.ID, "0001-01-01", "9999-12-31"); err != nil {
+		return nil, err
+	}
+
+	data.EmailTemplate, err = app.store.EmailTemplates.GetByRestaurant(ctx, restaurant.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	if data.Documents, err = app.store.Documents.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	if data.AuditLog, err = app.store.AuditLog.ListByRestaurant(ctx, restaurant.ID); err != nil {
+		return nil, err
+	}
+	data.TimeEntries, err = app.store.TimeClock.ListTimeEntries(ctx, restaurant.ID, time.Time{}, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// writeRestaurantArchive writes the export as one JSON file per kind of record,
+// plus the uploaded documents under documents/ when file storage is configured
+func (app *application) writeRestaurantArchive(ctx context.Context, archive *export.Archive, data *RestaurantExport) error {
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"restaurant.json", map[string]any{"exported_at": data.ExportedAt, "restaurant": data.Restaurant}},
+		{"roles.json", data.Roles},
+		{"employees.json", data.Employees},
+		{"certifications.json", data.Certifications},
+		{"employee_certifications.json", data.EmployeeCertifications},
+		{"shift_templates.json", data.ShiftTemplates},
+		{"schedules.json", data.Schedules},
+		{"schedule_day_notes.json", data.ScheduleDayNotes},
+		{"scheduled_shifts.json", data.ScheduledShifts},
+		{"events.json", data.Events},
+		{"operating_hours.json", data.OperatingHours},
+		{"hours_exceptions.json", data.HoursExceptions},
+		{"email_template.json", data.EmailTemplate},
+		{"documents.json", data.Documents},
+		{"audit_log.json", data.AuditLog},
+		{"time_entries.json", data.TimeEntries},
+	}
+
+	for _, f := range files {
+		if err := archive.AddJSON(f.name, f.v); err != nil {
+			return err
+		}
+	}
+
+	if app.blobs != nil {
+		for _, doc := range data.Documents {
+			if err := app.addDocumentToArchive(ctx, archive, doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+func (app *application) addDocumentToArchive(ctx context.Context, archive *export.Archive, doc *store.Document) error {
+	object, err := app.blobs.Get(ctx, doc.ObjectKey)
+	if err != nil {
+		return fmt.Errorf("document %d: %w", doc.ID, err)
+	}
+	defer object.Body.Close()
+
+	return archive.AddFile(documentArchiveName(doc), object.Body)
+}
+
+// documentArchiveName keeps document names unique and inside the documents/ folder
+func documentArchiveName(doc *store.Document) string {
+	name := strings.NewReplacer("/", "-", `\`, "-").Replace(doc.Name)
+	if name == "" || name == "." || name == ".." {
+		name = "document"
+	}
+	return fmt.Sprintf("documents/%d-%s", doc.ID, name)
+}
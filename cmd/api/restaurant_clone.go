@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type CloneRestaurantPayload struct {
+	Name    string  `json:"name" validate:"required,max=255"`
+	Address string  `json:"address" validate:"required,max=500"`
+	Phone   *string `json:"phone,omitempty" validate:"omitempty,max=20"`
+	// IncludeEmployees copies the employees with their roles and certifications too
+	IncludeEmployees bool `json:"include_employees"`
+}
+
+// RestaurantCloneResponse is the new restaurant and, per kind of entity, the
+// ID of each copied one in the source mapped to its ID in the new restaurant
+type RestaurantCloneResponse struct {
+	Restaurant *store.Restaurant `json:"restaurant"`
+	IDMap      store.CloneIDMap  `json:"id_map"`
+}
+
+// CloneRestaurant godoc
+//
+//	@Summary		Clones a Restaurant
+//	@Description	Creates a new restaurant with a copy of this one's setup: settings, operating hours, email template, roles, certifications and shift templates, and with include_employees=true its employees with their roles and certifications. Schedules, shifts, events, holidays and members are not copied. Everything is copied in one transaction; id_map maps each source ID to the new one.
+//	@Tags			restaurant
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Restaurant ID"
+//	@Param			payload	body		CloneRestaurantPayload	true	"The new restaurant"
+//	@Success		201		{object}	RestaurantCloneResponse
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		402		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{id}/clone [post]
+func (app *application) cloneRestaurantHandler(w http.ResponseWriter, r *http.Request) {
+	source := getRestaurantFromContext(r)
+
+	var payload CloneRestaurantPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	clone := &store.RestaurantClone{
+		SourceID: source.ID,
+		Restaurant: &store.Restaurant{
+			UserID:  getUserFromContext(r).ID,
+			Name:    payload.Name,
+			Address: payload.Address,
+			Phone:   payload.Phone,
+		},
+		IncludeEmployees: payload.IncludeEmployees,
+	}
+
+	ctx := r.Context()
+	if err := app.store.Restaurants.Clone(ctx, clone); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if app.config.redisCfg.enabled && app.cacheStorage.Restaurants != nil {
+		if err := app.cacheStorage.Restaurants.Set(ctx, clone.Restaurant); err != nil {
+			app.logger.Warnw("failed to cache cloned restaurant", "restaurant_id", clone.Restaurant.ID, "error", err)
+		}
+	}
+
+	app.jsonResponse(w, r, http.StatusCreated, RestaurantCloneResponse{
+		Restaurant: clone.Restaurant,
+		IDMap:      clone.IDMap,
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCloneRestaurant(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+
+	var cloned *store.RestaurantClone
+	mocks.restaurants.CloneFunc = func(_ context.Context, clone *store.RestaurantClone) error {
+		cloned = clone
+		clone.Restaurant.ID = 9
+		clone.IDMap = store.CloneIDMap{
+			Roles:          map[int64]int64{4: 40},
+			Certifications: map[int64]int64{},
+			ShiftTemplates: map[int64]int64{6: 60},
+			Employees:      map[int64]int64{7: 70},
+		}
+		return nil
+	}
+
+	t.Run("clones into a new restaurant and maps the IDs", func(t *testing.T) {
+		body := `{"name":"Cedar Grill Uptown","address":"9 Hill St","include_employees":true}`
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/clone", body), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if cloned.SourceID != 3 || !cloned.IncludeEmployees || cloned.Restaurant.UserID != testUserID {
+			t.Errorf("clone = %+v", cloned)
+		}
+
+		var resp struct {
+			Data struct {
+				Restaurant store.Restaurant `json:"restaurant"`
+				IDMap      struct {
+					Roles     map[string]int64 `json:"roles"`
+					Employees map[string]int64 `json:"employees"`
+				} `json:"id_map"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Data.Restaurant.ID != 9 || resp.Data.Restaurant.Name != "Cedar Grill Uptown" {
+			t.Errorf("restaurant = %+v", resp.Data.Restaurant)
+		}
+		if resp.Data.IDMap.Roles["4"] != 40 || resp.Data.IDMap.Employees["7"] != 70 {
+			t.Errorf("id_map = %+v", resp.Data.IDMap)
+		}
+	})
+
+	t.Run("requires a name and address", func(t *testing.T) {
+		cloned = nil
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/clone", `{"name":"Uptown"}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if cloned != nil {
+			t.Error("restaurant was cloned")
+		}
+	})
+}
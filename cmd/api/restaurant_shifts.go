@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	defaultShiftPageLimit = 500
+	maxShiftPageLimit     = 5000
+)
+
+// ShiftPage is one page of a restaurant's shifts in date order. NextCursor is set
+// while more follow; pass it as cursor to fetch them.
+type ShiftPage struct {
+	Shifts     []*ShiftResponse `json:"shifts"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// shiftCursor encodes the last shift of a page; clients treat it as opaque
+func shiftCursor(shift *store.ScheduledShift) string {
+	return shift.ShiftDate.Format("2006-01-02") + "_" + strconv.FormatInt(shift.ID, 10)
+}
+
+func parseShiftCursor(cursor string) (*store.ShiftCursor, error) {
+	invalid := errors.New("invalid shift cursor")
+
+	date, id, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, invalid
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, invalid
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || n < 1 {
+		return nil, invalid
+	}
+	return &store.ShiftCursor{ShiftDate: store.DateOnly(date), ID: n}, nil
+}
+
+// parseShiftPageLimit reads a page size query parameter, which defaults to defaultShiftPageLimit
+func parseShiftPageLimit(r *http.Request, param string) (int, error) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return defaultShiftPageLimit, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxShiftPageLimit {
+		return 0, fmt.Errorf("%s must be between 1 and %d", param, maxShiftPageLimit)
+	}
+	return n, nil
+}
+
+// ListRestaurantShifts godoc
+//
+//	@Summary		Pages through a restaurant's shift history
+//	@Description	Returns the restaurant's shifts across all schedules in date order, optionally only those dated from through to. Follow next_cursor until it is absent to read them all; each page costs the same however far into the history it is.
+//	@Tags			scheduled-shifts
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First shift date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last shift date (YYYY-MM-DD)"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Param			limit			query		int		false	"Page size (default 500, max 5000)"
+//	@Success		200				{object}	ShiftPage
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/shifts [get]
+func (app *application) listRestaurantShiftsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	from, to := store.MinShiftDate, store.MaxShiftDate
+	for param, bound := range map[string]*store.DateOnly{"from": &from, "to": &to} {
+		if v := r.URL.Query().Get(param); v != "" {
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				app.badRequestResponse(w, r, fmt.Errorf("%s must be in format YYYY-MM-DD", param))
+				return
+			}
+			*bound = store.DateOnly(v)
+		}
+	}
+	if to < from {
+		app.badRequestResponse(w, r, errors.New("to must not be before from"))
+		return
+	}
+
+	limit, err := parseShiftPageLimit(r, "limit")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var after *store.ShiftCursor
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		if after, err = parseShiftCursor(v); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	// one extra row tells whether another page follows
+	shifts, err := app.store.ScheduledShifts.ListPage(r.Context(), restaurant.ID, from, to, after, limit+1)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	page := &ShiftPage{}
+	if len(shifts) > limit {
+		shifts = shifts[:limit]
+		page.NextCursor = shiftCursor(shifts[limit-1])
+	}
+	page.Shifts = newShiftResponses(shifts)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, page); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
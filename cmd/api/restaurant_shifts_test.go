@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestListRestaurantShifts(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+
+	// five shifts in (shift_date, id) order
+	var all []*store.ScheduledShift
+	for i := 0; i < 5; i++ {
+		all = append(all, &store.ScheduledShift{ID: int64(10 - i), RestaurantID: 1, ShiftDate: time.Date(2020, 1, 1+i, 0, 0, 0, 0, time.UTC)})
+	}
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{ListPageFunc: func(_ context.Context, _ int64, from, to store.DateOnly, after *store.ShiftCursor, limit int) ([]*store.ScheduledShift, error) {
+		if from != store.MinShiftDate || to != store.MaxShiftDate {
+			t.Errorf("range = %s..%s, want unbounded", from, to)
+		}
+		page := []*store.ScheduledShift{}
+		for _, s := range all {
+			date := store.DateOnly(s.ShiftDate.Format("2006-01-02"))
+			if after != nil && (date < after.ShiftDate || date == after.ShiftDate && s.ID <= after.ID) {
+				continue
+			}
+			if len(page) < limit {
+				page = append(page, s)
+			}
+		}
+		return page, nil
+	}}
+
+	list := func(target string) ShiftPage {
+		t.Helper()
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, target, ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data ShiftPage `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Data
+	}
+
+	var ids []int64
+	target := "/v1/restaurants/1/shifts?limit=2"
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("paging did not stop")
+		}
+		page := list(target)
+		for _, s := range page.Shifts {
+			ids = append(ids, s.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		target = "/v1/restaurants/1/shifts?limit=2&cursor=" + page.NextCursor
+	}
+	if len(ids) != 5 || ids[0] != 10 || ids[4] != 6 {
+		t.Errorf("paged through %v, want every shift once in date order", ids)
+	}
+
+	for _, bad := range []string{"cursor=2020-01-01", "cursor=x_3", "limit=0", "from=2020-02-01&to=2020-01-01"} {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/shifts?"+bad, ""), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	}
+}
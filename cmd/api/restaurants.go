@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/balebbae/RESA/internal/integrations/holidays"
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/go-chi/chi/v5"
 )
@@ -76,7 +79,7 @@ func (app *application) createRestaurantHandler(w http.ResponseWriter, r *http.R
 	}
 
 	// Send JSON response
-	if err = app.jsonResponse(w, http.StatusCreated, restaurant); err != nil {
+	if err = app.jsonResponse(w, r, http.StatusCreated, restaurant); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -121,7 +124,7 @@ func (app *application) getRestaurantHandler(w http.ResponseWriter, r *http.Requ
 			if cachedRestaurant.UserID == user.ID {
 				app.logger.Debugw("cache hit for restaurant", "restaurant_id", restaurantID)
 				fmt.Println("CACHE HIT")
-				err = app.jsonResponse(w, http.StatusOK, cachedRestaurant)
+				err = app.jsonResponse(w, r, http.StatusOK, cachedRestaurant)
 				if err != nil {
 					app.internalServerError(w, r, err)
 				}
@@ -130,7 +133,17 @@ func (app *application) getRestaurantHandler(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	// Cache miss or validation failed - get from database
+	// Cache miss or validation failed - check the user may see it before reading the database
+	user := getUserFromContext(r)
+	if err := app.checkScheduleAccess(ctx, restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("restaurant not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
 	_, err := app.store.Restaurants.GetByID(ctx, restaurant.ID)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -146,7 +159,7 @@ func (app *application) getRestaurantHandler(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, restaurant)
+	err = app.jsonResponse(w, r, http.StatusOK, restaurant)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -157,12 +170,41 @@ type UpdateRestaurantPayload struct {
 	Name *string `json:"name" validate:"omitempty,max=255"`
 	Address *string `json:"address" validate:"omitempty,max=255"`
 	Phone *string `json:"phone" validate:"omitempty,max=20"`
+	// ScheduleLockHours locks published shifts this many hours before they start; 0 turns it off
+	ScheduleLockHours *int `json:"schedule_lock_hours" validate:"omitempty,min=0,max=168"`
+	// WeeklyLaborBudgetCents is checked when schedules are published; 0 removes the budget
+	WeeklyLaborBudgetCents *int `json:"weekly_labor_budget_cents" validate:"omitempty,min=0"`
+	// ScheduleRetentionMonths archives schedules that ended this many months ago; 0 turns it off
+	ScheduleRetentionMonths *int `json:"schedule_retention_months" validate:"omitempty,min=0,max=120"`
+	// AssignmentPolicy ranks employees when shifts are assigned automatically
+	AssignmentPolicy *string `json:"assignment_policy" validate:"omitempty,oneof=seniority_first rotate_fairly manual_only"`
+	// StaffMilestoneDigest turns the owner's weekly birthday and work anniversary email on or off
+	StaffMilestoneDigest *bool `json:"staff_milestone_digest"`
+	// Latitude and Longitude locate the restaurant for schedule weather; they're set together
+	Latitude *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+	// NotificationMode is how staff get shift changes and announcements: immediate, daily or shift_day
+	NotificationMode *string `json:"notification_mode" validate:"omitempty,oneof=immediate daily shift_day"`
+	// DigestHour is the hour (UTC) from which digests go out
+	DigestHour *int `json:"digest_hour" validate:"omitempty,min=0,max=23"`
+	// Timezone is the restaurant's IANA timezone, e.g. America/Chicago
+	Timezone *string `json:"timezone" validate:"omitempty,timezone"`
+	// WeekStart is the day the restaurant's weeks begin, 0 (Sunday) to 6 (Saturday)
+	WeekStart *int `json:"week_start" validate:"omitempty,min=0,max=6"`
+	// RotationAnchor (YYYY-MM-DD) starts week a of the two-week shift template rotation; an empty string clears it
+	RotationAnchor *string `json:"rotation_anchor"`
+	// RequireEmailVerification holds schedule emails to employees until they've verified their address
+	RequireEmailVerification *bool `json:"require_email_verification"`
+	// HolidayRegion (e.g. US, US-CA) picks whose public holidays are added to the special dates; an empty string turns it off
+	HolidayRegion *string `json:"holiday_region"`
+	// HolidaysClosed adds public holidays as closures rather than days on regular hours
+	HolidaysClosed *bool `json:"holidays_closed"`
 }
 
 // UpdateRestaurant godoc
 //
 //	@Summary		Updates a Restaurant
-//	@Description	Updates a Restaurant by ID
+//	@Description	Updates a Restaurant by ID. schedule_lock_hours (0-168, 0 = off) stops edits to published shifts that start within that many hours unless the request passes override_lock=true. weekly_labor_budget_cents is checked when schedules are published; 0 removes it. schedule_retention_months (0-120, 0 = off) archives schedules that ended more than that many months ago. assignment_policy picks who auto-assign offers a shift to: seniority_first (highest employee seniority), rotate_fairly (fewest scheduled hours) or manual_only (auto-assign off). staff_milestone_digest emails the owner each week the staff birthdays and work anniversaries of the coming seven days. latitude and longitude, given together, add the weather forecast to schedule coverage. notification_mode sets how staff get shift change and announcement emails: immediate, or held for one digest a day (daily) or on the days they work (shift_day) sent from digest_hour (0-23 UTC); employees can override it and critical notices are always sent straight away. timezone, an IANA name such as America/Chicago (default UTC), is the one dates and times in staff emails and the display board are shown in, and the one the schedule lock reads shift start times in. week_start, 0 (Sunday) to 6 (Saturday, default 1), is the day the restaurant's weeks begin: the default week of the hours calendar and the weeks the minors' weekly hours limit counts follow it. rotation_anchor (YYYY-MM-DD, empty clears) starts week a of the alternating two-week rotation that shift templates with a week_parity follow. require_email_verification holds schedule emails to employees who haven't confirmed their address through a verification link. holiday_region, a country (US) or subdivision (US-CA) code, adds that region's public holidays for this year and next to the hours exceptions once, as closures when holidays_closed is set and otherwise as days on regular hours named for the holiday; coverage reports and schedule emails show their names. An empty holiday_region turns it off. It needs the holidays integration, and a region it knows nothing of is rejected.
 //	@Tags			restaurant
 //	@Accept			json
 //	@Produce		json
@@ -205,20 +247,113 @@ func (app *application) updateRestaurantHandler(w http.ResponseWriter, r *http.R
 		restaurant.Phone = nil
 	}
 
+	if payload.ScheduleLockHours != nil {
+		restaurant.ScheduleLockHours = *payload.ScheduleLockHours
+	}
+
+	if payload.WeeklyLaborBudgetCents != nil {
+		restaurant.WeeklyLaborBudgetCents = payload.WeeklyLaborBudgetCents
+		if *payload.WeeklyLaborBudgetCents == 0 {
+			restaurant.WeeklyLaborBudgetCents = nil
+		}
+	}
+
+	if payload.ScheduleRetentionMonths != nil {
+		restaurant.ScheduleRetentionMonths = payload.ScheduleRetentionMonths
+		if *payload.ScheduleRetentionMonths == 0 {
+			restaurant.ScheduleRetentionMonths = nil
+		}
+	}
+
+	if payload.AssignmentPolicy != nil {
+		restaurant.AssignmentPolicy = store.AssignmentPolicy(*payload.AssignmentPolicy)
+	}
+
+	if payload.StaffMilestoneDigest != nil {
+		restaurant.StaffMilestoneDigest = *payload.StaffMilestoneDigest
+	}
+
+	if payload.Latitude != nil {
+		restaurant.Latitude, restaurant.Longitude = payload.Latitude, payload.Longitude
+	}
+
+	if payload.NotificationMode != nil {
+		restaurant.NotificationMode = store.NotificationMode(*payload.NotificationMode)
+	}
+
+	if payload.DigestHour != nil {
+		restaurant.DigestHour = *payload.DigestHour
+	}
+
+	if payload.Timezone != nil {
+		// the timezone tag loads the name, and time.LoadLocation takes "Local" as the server's own zone
+		if *payload.Timezone == "Local" {
+			app.badRequestResponse(w, r, errors.New("timezone must be an IANA name such as America/Chicago"))
+			return
+		}
+		restaurant.Timezone = *payload.Timezone
+	}
+
+	if payload.WeekStart != nil {
+		restaurant.WeekStart = *payload.WeekStart
+	}
+
+	if payload.RotationAnchor != nil {
+		if restaurant.RotationAnchor, err = optionalDate("rotation_anchor", *payload.RotationAnchor); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if payload.RequireEmailVerification != nil {
+		restaurant.RequireEmailVerification = *payload.RequireEmailVerification
+	}
+
+	if payload.HolidaysClosed != nil {
+		restaurant.HolidaysClosed = *payload.HolidaysClosed
+	}
+
+	var newHolidays *holidayLookup
+	if payload.HolidayRegion != nil {
+		region := ""
+		if strings.TrimSpace(*payload.HolidayRegion) != "" {
+			if region, _, err = holidays.ParseRegion(*payload.HolidayRegion); err != nil {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+		}
+
+		if region != "" && region != restaurant.HolidayRegion && app.holidays != nil {
+			newHolidays = newHolidayLookup(app.holidays)
+			// a region the provider knows nothing of would never get holidays;
+			// any other failure is left to the background job to retry
+			if _, err := newHolidays.get(r.Context(), region, time.Now().UTC().Year()); errors.Is(err, holidays.ErrUnknownRegion) {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+		}
+		restaurant.HolidayRegion = region
+	}
+
 	err = app.store.Restaurants.Update(r.Context(), restaurant)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	if newHolidays != nil {
+		app.addRestaurantHolidays(r.Context(), newHolidays, restaurant)
+	}
+
 	// Update the restaurant in cache
 	if app.config.redisCfg.enabled && app.cacheStorage.Restaurants != nil {
 		if err := app.cacheStorage.Restaurants.Set(r.Context(), restaurant); err != nil {
 			app.logger.Warnw("failed to update restaurant in cache", "restaurant_id", restaurant.ID, "error", err)
 		}
 	}
+	app.invalidateRestaurantAccess(r.Context(), restaurant.ID)
 
-	err = app.jsonResponse(w, http.StatusOK, restaurant)
+	err = app.jsonResponse(w, r, http.StatusOK, restaurant)
 	if err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -227,13 +362,14 @@ func (app *application) updateRestaurantHandler(w http.ResponseWriter, r *http.R
 // DeleteRestaurant godoc
 //
 //	@Summary		Deletes a Restaurant
-//	@Description	Delete a Restaurant by ID
+//	@Description	Permanently deletes a Restaurant and all of its data. The restaurant must be archived and exported since (GET /restaurants/{id}/export), otherwise 409.
 //	@Tags			restaurant
 //	@Accept			json
 //	@Produce		json
 //	@Param			id	path		int	true	"Restaurant ID"
 //	@Success		204	{object}	string
 //	@Failure		404	{object}	error
+//	@Failure		409	{object}	error
 //	@Failure		500	{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{id} [delete]
@@ -245,6 +381,20 @@ func (app *application) deleteRestaurantHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	// Deleting cascades to everything the restaurant owns, so the owner must
+	// have archived it and downloaded what is about to be lost
+	if !restaurant.ExportedSinceArchived() {
+		app.conflictResponse(w, r, errExportBeforeDelete)
+		return
+	}
+
 	ctx := r.Context()
 
 	// Delete from cache before deleting from database
@@ -256,6 +406,7 @@ func (app *application) deleteRestaurantHandler(w http.ResponseWriter, r *http.R
 			app.logger.Debugw("deleted restaurant from cache", "restaurant_id", id)
 		}
 	}
+	app.invalidateRestaurantAccess(ctx, id)
 
 	err = app.store.Restaurants.Delete(ctx, id)
 	if err != nil {
@@ -274,20 +425,23 @@ func (app *application) deleteRestaurantHandler(w http.ResponseWriter, r *http.R
 // GetRestaurants godoc
 //
 //	@Summary		Lists user's restaurants
-//	@Description	Fetches all restaurants belonging to the authenticated user
+//	@Description	Fetches all restaurants belonging to the authenticated user; archived restaurants are only listed, on their own, with archived=true
 //	@Tags			restaurant
 //	@Accept			json
 //	@Produce		json
-//	@Success		200	{array}		store.Restaurant
-//	@Failure		401	{object}	error
-//	@Failure		500	{object}	error
+//	@Param			archived	query		bool	false	"List archived restaurants instead"
+//	@Success		200			{array}		store.Restaurant
+//	@Failure		401			{object}	error
+//	@Failure		500			{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants [get]
 func (app *application) getRestaurantsHandler(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	ctx := r.Context()
 
-	restaurants, err := app.store.Restaurants.ListByUser(ctx, user.ID)
+	archived := r.URL.Query().Get("archived") == "true"
+
+	restaurants, err := app.store.Restaurants.ListByUser(ctx, user.ID, archived)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -307,7 +461,7 @@ func (app *application) getRestaurantsHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, restaurants)
+	err = app.jsonResponse(w, r, http.StatusOK, restaurants)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
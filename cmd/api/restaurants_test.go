@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
 )
 
 func TestGetRestaurant(t *testing.T) {
@@ -39,3 +43,31 @@ func TestGetRestaurant(t *testing.T) {
 	})
 }
 
+func TestUpdateRestaurantTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     int
+	}{
+		{"America/Chicago", http.StatusOK},
+		{"Mars/Olympus", http.StatusBadRequest},
+		{"Local", http.StatusBadRequest},
+		{"CST +6", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			app, mocks := newMockedApplication(t, testUserID)
+			var saved string
+			mocks.restaurants.UpdateFunc = func(_ context.Context, restaurant *store.Restaurant) error {
+				saved = restaurant.Timezone
+				return nil
+			}
+
+			rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/3", fmt.Sprintf(`{"timezone":%q}`, tt.timezone)), app.mount())
+
+			checkResponseCode(t, tt.want, rr.Code)
+			if tt.want == http.StatusOK && saved != tt.timezone {
+				t.Errorf("saved timezone %q, want %q", saved, tt.timezone)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// RetentionPolicyPayload sets how many months a kind of data is kept
+type RetentionPolicyPayload struct {
+	AfterMonths int `json:"after_months" validate:"required,min=1,max=1200"`
+	// Enabled defaults to true; a disabled policy keeps its setting but purges nothing
+	Enabled *bool `json:"enabled"`
+}
+
+// RetentionReport is what a run of a restaurant's retention policies purged, or
+// would purge on a dry run
+type RetentionReport struct {
+	DryRun   bool                     `json:"dry_run"`
+	Policies []*RetentionPolicyReport `json:"policies"`
+}
+
+// RetentionPolicyReport is what one policy purged: data from before Cutoff
+type RetentionPolicyReport struct {
+	Kind   store.RetentionKind `json:"kind"`
+	Cutoff store.DateOnly      `json:"cutoff"`
+	// Employees are those anonymized, named as they were before
+	Employees   []RetentionEmployee `json:"employees,omitempty"`
+	TimeEntries int64               `json:"time_entries"`
+}
+
+type RetentionEmployee struct {
+	ID           int64          `json:"id"`
+	FullName     string         `json:"full_name"`
+	TerminatedOn store.DateOnly `json:"terminated_on"`
+}
+
+// purged reports whether the policy removed anything
+func (r *RetentionPolicyReport) purged() bool {
+	return len(r.Employees) > 0 || r.TimeEntries > 0
+}
+
+// GetRetentionPolicies godoc
+//
+//	@Summary		Lists a restaurant's retention policies
+//	@Description	Returns how long the restaurant keeps each kind of data it has a policy for: anonymize_terminated_employees erases employees after_months after their terminated_on date, delete_time_entries deletes clocked-out time entries after_months after they started. Kinds without a policy are kept forever.
+//	@Tags			retention
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.RetentionPolicy
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/retention-policies [get]
+func (app *application) getRetentionPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	policies, err := app.store.Retention.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, policies); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateRetentionPolicy godoc
+//
+//	@Summary		Sets a retention policy
+//	@Description	Creates or replaces the restaurant's policy for a kind of data. The background job applies enabled policies; POST .../retention-policies/run with dry_run=true shows what they would purge first.
+//	@Tags			retention
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			kind			path		string					true	"Kind of data"	Enums(anonymize_terminated_employees, delete_time_entries)
+//	@Param			payload			body		RetentionPolicyPayload	true	"Retention policy"
+//	@Success		200				{object}	store.RetentionPolicy
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/retention-policies/{kind} [put]
+func (app *application) updateRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	kind, err := retentionKindFromURL(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload RetentionPolicyPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	policy := &store.RetentionPolicy{
+		RestaurantID: restaurant.ID,
+		Kind:         kind,
+		AfterMonths:  payload.AfterMonths,
+		Enabled:      payload.Enabled == nil || *payload.Enabled,
+	}
+	if err := app.store.Retention.Upsert(r.Context(), policy); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, policy); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteRetentionPolicy godoc
+//
+//	@Summary		Deletes a retention policy
+//	@Description	Removes the restaurant's policy for a kind of data, which is then kept forever
+//	@Tags			retention
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			kind			path		string	true	"Kind of data"	Enums(anonymize_terminated_employees, delete_time_entries)
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/retention-policies/{kind} [delete]
+func (app *application) deleteRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	kind, err := retentionKindFromURL(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.Retention.Delete(r.Context(), restaurant.ID, kind); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunRetentionPolicies godoc
+//
+//	@Summary		Applies the retention policies now
+//	@Description	Applies the restaurant's enabled retention policies, as the background job does, and reports what each purged: the employees anonymized and the number of time entries deleted from before its cutoff date. Each policy that purged something adds a retention_policy entry to the audit log. With dry_run=true nothing is changed and the report is what would be purged.
+//	@Tags			retention
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			dry_run			query		bool	false	"Report what would be purged without purging it"
+//	@Success		200				{object}	RetentionReport
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/retention-policies/run [post]
+func (app *application) runRetentionPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	ctx := r.Context()
+
+	policies, err := app.store.Retention.ListByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	report := &RetentionReport{DryRun: r.URL.Query().Get(dryRunParam) == "true", Policies: []*RetentionPolicyReport{}}
+	now := time.Now().UTC()
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+		policyReport, err := app.applyRetentionPolicy(ctx, policy, now, report.DryRun, &user.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		report.Policies = append(report.Policies, policyReport)
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func retentionKindFromURL(r *http.Request) (store.RetentionKind, error) {
+	kind := store.RetentionKind(chi.URLParam(r, "kind"))
+	switch kind {
+	case store.RetentionAnonymizeTerminated, store.RetentionDeleteTimeEntries:
+		return kind, nil
+	}
+	return "", fmt.Errorf("unknown retention policy kind %q", kind)
+}
+
+// applyRetentionPolicy purges the data the policy covers at now, or only reports
+// it on a dry run. A real run records what it purged in the audit log, by the
+// actor when there is one, and marks the policy run. An employee who fails to
+// erase is logged and left for the next run.
+func (app *application) applyRetentionPolicy(ctx context.Context, policy *store.RetentionPolicy, now time.Time, dryRun bool, actorUserID *int64) (*RetentionPolicyReport, error) {
+	report := &RetentionPolicyReport{Kind: policy.Kind, Cutoff: policy.Cutoff(now)}
+
+	var changes map[string]store.AuditChange
+	var summary string
+	switch policy.Kind {
+	case store.RetentionAnonymizeTerminated:
+		employees, err := app.store.Retention.TerminatedBefore(ctx, policy.RestaurantID, report.Cutoff)
+		if err != nil {
+			return nil, err
+		}
+		var ids []int64
+		for _, employee := range employees {
+			if !dryRun {
+				if _, err := app.eraseEmployee(ctx, employee); err != nil {
+					app.logger.Warnw("failed to erase terminated employee", "employee_id", employee.ID, "error", err)
+					continue
+				}
+			}
+			report.Employees = append(report.Employees, RetentionEmployee{ID: employee.ID, FullName: employee.FullName, TerminatedOn: *employee.TerminatedOn})
+			ids = append(ids, employee.ID)
+		}
+		summary = fmt.Sprintf("Anonymized %d employees terminated before %s", len(ids), report.Cutoff)
+		changes = map[string]store.AuditChange{"employee_ids": {To: ids}}
+
+	case store.RetentionDeleteTimeEntries:
+		var err error
+		if dryRun {
+			report.TimeEntries, err = app.store.Retention.CountTimeEntriesBefore(ctx, policy.RestaurantID, report.Cutoff)
+		} else {
+			report.TimeEntries, err = app.store.Retention.DeleteTimeEntriesBefore(ctx, policy.RestaurantID, report.Cutoff)
+		}
+		if err != nil {
+			return nil, err
+		}
+		summary = fmt.Sprintf("Deleted %d time entries from before %s", report.TimeEntries, report.Cutoff)
+		changes = map[string]store.AuditChange{"time_entries": {From: report.TimeEntries, To: 0}}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	if report.purged() {
+		app.recordAudit(ctx, &store.AuditEntry{
+			RestaurantID: policy.RestaurantID,
+			EntityType:   store.AuditEntityRetention,
+			EntityID:     policy.ID,
+			Action:       store.AuditPurged,
+			Summary:      summary,
+			Changes:      changes,
+			ActorUserID:  actorUserID,
+		})
+	}
+	if err := app.store.Retention.MarkRun(ctx, policy, now); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// runRetentionPolicies periodically applies every restaurant's enabled retention policies
+func (app *application) runRetentionPolicies(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := app.applyRetentionPolicies(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("retention policies failed", "error", err)
+			continue
+		}
+
+		if purged > 0 {
+			app.logger.Infow("applied retention policies", "purged", purged)
+		}
+	}
+}
+
+// applyRetentionPolicies applies every enabled policy at now, returning how many
+// purged something. A policy that fails is retried on the next run.
+func (app *application) applyRetentionPolicies(ctx context.Context, now time.Time) (int, error) {
+	policies, err := app.store.Retention.ListEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, policy := range policies {
+		report, err := app.applyRetentionPolicy(ctx, policy, now, false, nil)
+		if err != nil {
+			app.logger.Warnw("failed to apply retention policy", "restaurant_id", policy.RestaurantID, "kind", policy.Kind, "error", err)
+			continue
+		}
+		if report.purged() {
+			purged++
+		}
+	}
+	return purged, nil
+}
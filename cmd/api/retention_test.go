@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestRunRetentionPolicies(t *testing.T) {
+	setup := func(t *testing.T) (*application, *[]int64, *[]*store.AuditEntry) {
+		app, _ := newMockedApplication(t, testUserID)
+		var erased []int64
+		var audited []*store.AuditEntry
+		terminated := store.DateOnly("2023-01-31")
+		app.store.Retention = &store.MockRetentionStorer{
+			ListByRestaurantFunc: func(_ context.Context, restaurantID int64) ([]*store.RetentionPolicy, error) {
+				return []*store.RetentionPolicy{
+					{ID: 1, RestaurantID: restaurantID, Kind: store.RetentionAnonymizeTerminated, AfterMonths: 24, Enabled: true},
+					{ID: 2, RestaurantID: restaurantID, Kind: store.RetentionDeleteTimeEntries, AfterMonths: 36, Enabled: true},
+					{ID: 3, RestaurantID: restaurantID, Kind: store.RetentionDeleteTimeEntries, AfterMonths: 1},
+				}, nil
+			},
+			TerminatedBeforeFunc: func(_ context.Context, restaurantID int64, before store.DateOnly) ([]*store.Employee, error) {
+				if want := store.DateOnly(time.Now().UTC().AddDate(-2, 0, 0).Format("2006-01-02")); before != want {
+					t.Errorf("terminated before %s, want %s", before, want)
+				}
+				return []*store.Employee{{ID: 7, RestaurantID: restaurantID, FullName: "Ana Diaz", TerminatedOn: &terminated}}, nil
+			},
+			CountTimeEntriesBeforeFunc:  func(context.Context, int64, store.DateOnly) (int64, error) { return 12, nil },
+			DeleteTimeEntriesBeforeFunc: func(context.Context, int64, store.DateOnly) (int64, error) { return 12, nil },
+			MarkRunFunc: func(_ context.Context, policy *store.RetentionPolicy, at time.Time) error {
+				policy.LastRunAt = &at
+				return nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			EraseFunc: func(_ context.Context, id int64) (*store.EmployeeErasure, error) {
+				erased = append(erased, id)
+				return &store.EmployeeErasure{}, nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(_ context.Context, entries []*store.AuditEntry) error {
+				audited = append(audited, entries...)
+				return nil
+			},
+		}
+		return app, &erased, &audited
+	}
+
+	run := func(t *testing.T, app *application, target string) RetentionReport {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, target, ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var response struct {
+			Data RetentionReport `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response.Data
+	}
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		app, erased, audited := setup(t)
+
+		report := run(t, app, "/v1/restaurants/1/retention-policies/run?dry_run=true")
+
+		if !report.DryRun || len(report.Policies) != 2 {
+			t.Fatalf("report = %+v, want the two enabled policies previewed", report)
+		}
+		if len(report.Policies[0].Employees) != 1 || report.Policies[0].Employees[0].FullName != "Ana Diaz" || report.Policies[1].TimeEntries != 12 {
+			t.Errorf("report = %+v, want Ana and 12 time entries", report.Policies)
+		}
+		if len(*erased) != 0 || len(*audited) != 0 {
+			t.Errorf("erased %v and audited %d entries, want nothing changed", *erased, len(*audited))
+		}
+	})
+
+	t.Run("run purges and audits", func(t *testing.T) {
+		app, erased, audited := setup(t)
+
+		report := run(t, app, "/v1/restaurants/1/retention-policies/run")
+
+		if report.DryRun || len(report.Policies) != 2 {
+			t.Fatalf("report = %+v, want the two enabled policies applied", report)
+		}
+		if len(*erased) != 1 || (*erased)[0] != 7 {
+			t.Errorf("erased %v, want employee 7", *erased)
+		}
+		if len(*audited) != 2 || (*audited)[0].Action != store.AuditPurged || (*audited)[1].Summary != "Deleted 12 time entries from before "+string(report.Policies[1].Cutoff) {
+			t.Errorf("audited %+v, want a purge entry per policy", *audited)
+		}
+		if (*audited)[0].ActorUserID == nil || *(*audited)[0].ActorUserID != testUserID {
+			t.Errorf("actor = %v, want the owner who ran it", (*audited)[0].ActorUserID)
+		}
+	})
+}
+
+func TestUpdateRetentionPolicyKind(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+
+	rr := executeRequest(authedRequest(t, app, http.MethodPut, "/v1/restaurants/1/retention-policies/delete_everything", `{"after_months": 12}`), app.mount())
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
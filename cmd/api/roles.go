@@ -2,23 +2,31 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/go-chi/chi/v5"
 )
 
+var errRoleInUse = errors.New("the role is still used by shift templates, shifts or employees; pass reassign_to=<roleID> or force=true to delete it")
+
 // type roleKey string
 // const roleCtx roleKey = "role"
 type CreateRolePayload struct {
 	Name    string  `json:"name" validate:"required,max=50"`
 	Color   string  `json:"color" validate:"omitempty,len=7"`
+	// TargetHeadcount is how many employees should be able to work the role
+	TargetHeadcount *int `json:"target_headcount" validate:"omitempty,min=1,max=1000"`
 }
 
 type UpdateRolePayload struct {
 	Name    *string  `json:"name" validate:"omitempty,max=50"`
 	Color   *string  `json:"color" validate:"omitempty,len=7"`
+	// TargetHeadcount of 0 removes the role's target
+	TargetHeadcount *int `json:"target_headcount" validate:"omitempty,min=0,max=1000"`
 }
 
 // GetRoles godoc
@@ -43,8 +51,8 @@ func (app *application) getRolesHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -53,10 +61,12 @@ func (app *application) getRolesHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+	version, err := app.store.Versions.Roles(r.Context(), restaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if app.notModified(w, r, fmt.Sprintf("roles-%d", restaurantID), version) {
 		return
 	}
 
@@ -66,7 +76,7 @@ func (app *application) getRolesHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, roles)
+	err = app.jsonResponse(w, r, http.StatusOK, roles)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -86,6 +96,7 @@ func (app *application) getRolesHandler(w http.ResponseWriter, r *http.Request)
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/roles [post]
@@ -97,8 +108,8 @@ func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -107,13 +118,6 @@ func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	var payload CreateRolePayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -132,17 +136,22 @@ func (app *application) createRoleHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	role := &store.Role{
-		RestaurantID: restaurantID,
-		Name:         payload.Name,
-		Color:        color,
+		RestaurantID:    restaurantID,
+		Name:            payload.Name,
+		Color:           color,
+		TargetHeadcount: payload.TargetHeadcount,
 	}
 
 	if err := app.store.Roles.Create(r.Context(), role); err != nil {
+		if errors.Is(err, store.ErrDuplicateRole) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusCreated, role)
+	err = app.jsonResponse(w, r, http.StatusCreated, role)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -178,8 +187,8 @@ func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -188,13 +197,6 @@ func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	role, err := app.store.Roles.GetByID(r.Context(), roleID)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -211,7 +213,7 @@ func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, role)
+	err = app.jsonResponse(w, r, http.StatusOK, role)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -232,6 +234,7 @@ func (app *application) getRoleHandler(w http.ResponseWriter, r *http.Request) {
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/roles/{id} [patch]
@@ -249,8 +252,8 @@ func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -259,13 +262,6 @@ func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing role
 	role, err := app.store.Roles.GetByID(r.Context(), roleID)
 	if err != nil {
@@ -304,13 +300,24 @@ func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request
 		role.Color = *payload.Color
 	}
 
+	if payload.TargetHeadcount != nil {
+		role.TargetHeadcount = payload.TargetHeadcount
+		if *payload.TargetHeadcount == 0 {
+			role.TargetHeadcount = nil
+		}
+	}
+
 	// Save updates
 	if err := app.store.Roles.Update(r.Context(), role); err != nil {
+		if errors.Is(err, store.ErrDuplicateRole) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, role)
+	err = app.jsonResponse(w, r, http.StatusOK, role)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -320,15 +327,19 @@ func (app *application) updateRoleHandler(w http.ResponseWriter, r *http.Request
 // DeleteRole godoc
 //
 //	@Summary		Deletes a role
-//	@Description	Deletes a role by ID
+//	@Description	Deletes a role by ID. A role still used by shift templates, shifts or employees (see GET .../usage) is only deleted with reassign_to, which moves all of it to another role first, or force=true, which deletes its shifts and drops it from templates and employees. Either way it happens in one transaction.
 //	@Tags			role
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurant_id	path		int	true	"Restaurant ID"
-//	@Param			id				path		int	true	"Role ID"
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			id				path		int		true	"Role ID"
+//	@Param			reassign_to		query		int		false	"Role to move the shifts, templates and employees to"
+//	@Param			force			query		bool	false	"Delete the role's shifts along with it"
 //	@Success		204				{object}	string
+//	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/roles/{id} [delete]
@@ -346,8 +357,8 @@ func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -356,13 +367,6 @@ func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing role
 	role, err := app.store.Roles.GetByID(r.Context(), roleID)
 	if err != nil {
@@ -380,8 +384,52 @@ func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Delete role
-	if err := app.store.Roles.Delete(r.Context(), roleID); err != nil {
+	query := r.URL.Query()
+	force := query.Get("force") == "true"
+	reassignTo := query.Get("reassign_to")
+	if force && reassignTo != "" {
+		app.badRequestResponse(w, r, errors.New("pass either reassign_to or force, not both"))
+		return
+	}
+
+	ctx := r.Context()
+	switch {
+	case reassignTo != "":
+		toRoleID, err := strconv.ParseInt(reassignTo, 10, 64)
+		if err != nil || toRoleID == roleID {
+			app.badRequestResponse(w, r, errors.New("reassign_to must be another role's ID"))
+			return
+		}
+
+		toRole, err := app.store.Roles.GetByID(ctx, toRoleID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if err != nil || toRole.RestaurantID != restaurantID {
+			app.badRequestResponse(w, r, errors.New("reassign_to is not a role of this restaurant"))
+			return
+		}
+
+		err = app.store.Roles.Reassign(ctx, roleID, toRoleID)
+
+	case force:
+		err = app.store.Roles.ForceDelete(ctx, roleID)
+
+	default:
+		usage, usageErr := app.store.Roles.Usage(ctx, roleID, store.DateOnly(time.Now().UTC().Format("2006-01-02")))
+		if usageErr != nil {
+			app.internalServerError(w, r, usageErr)
+			return
+		}
+		if usage.InUse() {
+			app.conflictResponse(w, r, errRoleInUse)
+			return
+		}
+
+		err = app.store.Roles.Delete(ctx, roleID)
+	}
+	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -393,6 +441,61 @@ func (app *application) deleteRoleHandler(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetRoleUsage godoc
+//
+//	@Summary		Shows what uses a role
+//	@Description	Lists the shift templates and employees that refer to the role and counts its shifts from today on and before, i.e. what deleting it would change
+//	@Tags			role
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			roleID			path		int	true	"Role ID"
+//	@Success		200				{object}	store.RoleUsage
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/roles/{roleID}/usage [get]
+func (app *application) getRoleUsageHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	roleID, err := strconv.ParseInt(chi.URLParam(r, "roleID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	role, err := app.store.Roles.GetByID(r.Context(), roleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if role.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("role not found in this restaurant"))
+		return
+	}
+
+	usage, err := app.store.Roles.Usage(r.Context(), roleID, store.DateOnly(restaurant.Today(time.Now()).Format("2006-01-02")))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, usage); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
 // GetRoleEmployees godoc
 //
 //	@Summary		Get employees for a role
@@ -425,8 +528,7 @@ func (app *application) getRoleEmployeesHandler(w http.ResponseWriter, r *http.R
 	user := getUserFromContext(r)
 
 	// Verify restaurant ownership
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
 			app.notFoundResponse(w, r, err)
@@ -436,11 +538,6 @@ func (app *application) getRoleEmployeesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if restaurant.UserID != user.ID {
-		app.unauthorizedErrorResponse(w, r, errors.New("not restaurant owner"))
-		return
-	}
-
 	// Verify role exists and belongs to restaurant
 	role, err := app.store.Roles.GetByID(r.Context(), roleID)
 	if err != nil {
@@ -465,7 +562,7 @@ func (app *application) getRoleEmployeesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, employees); err != nil {
+	if err := app.jsonResponse(w, r, http.StatusOK, employees); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
\ No newline at end of file
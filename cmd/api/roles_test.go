@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const testUserID = 1
+
+func TestCreateRoleAuthorization(t *testing.T) {
+	t.Run("owner creates the role", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		var created *store.Role
+		mocks.roles.CreateFunc = func(_ context.Context, role *store.Role) error {
+			role.ID = 9
+			created = role
+			return nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if created == nil || created.RestaurantID != 3 || created.Name != "Server" || created.Color != "#6B7280" {
+			t.Fatalf("created role = %+v", created)
+		}
+
+		var body struct {
+			Data store.Role `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.ID != 9 {
+			t.Errorf("response role ID = %d, want 9", body.Data.ID)
+		}
+	})
+
+	t.Run("other users get not found", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID+1)
+		mocks.roles.CreateFunc = func(context.Context, *store.Role) error {
+			t.Error("role created for a restaurant the user does not own")
+			return nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("missing restaurant is not found", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.restaurants.GetByIDFunc = func(context.Context, int64) (*store.Restaurant, error) {
+			return nil, store.ErrNotFound
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("archived restaurant is read-only", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		archivedAt := time.Now()
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID, ArchivedAt: &archivedAt}, nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("duplicate name conflicts", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.roles.CreateFunc = func(context.Context, *store.Role) error {
+			return store.ErrDuplicateRole
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("store timeout is unavailable, not an error", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.roles.CreateFunc = func(context.Context, *store.Role) error {
+			return context.DeadlineExceeded
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", `{"name":"Server"}`), app.mount())
+
+		checkResponseCode(t, http.StatusServiceUnavailable, rr.Code)
+		if rr.Header().Get("Retry-After") == "" {
+			t.Error("want a Retry-After header")
+		}
+	})
+}
+
+func TestCreateRoleValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed json", `{"name":`},
+		{"unknown field", `{"name":"Server","rank":1}`},
+		{"missing name", `{"color":"#FFFFFF"}`},
+		{"name too long", `{"name":"` + strings.Repeat("a", 51) + `"}`},
+		{"short color", `{"name":"Server","color":"#FFF"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newMockedApplication(t, testUserID)
+
+			rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/roles", tt.body), app.mount())
+
+			checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestDeleteRoleInUse(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.roles.GetByIDFunc = func(_ context.Context, id int64) (*store.Role, error) {
+		restaurantID := int64(3)
+		if id == 8 {
+			restaurantID = 4
+		}
+		return &store.Role{ID: id, RestaurantID: restaurantID}, nil
+	}
+	mocks.roles.UsageFunc = func(_ context.Context, roleID int64, _ store.DateOnly) (*store.RoleUsage, error) {
+		usage := &store.RoleUsage{RoleID: roleID, ShiftTemplates: []*store.RoleUsageTemplate{}, Employees: []*store.RoleUsageEmployee{}}
+		if roleID == 5 {
+			usage.FutureShifts = 2
+		}
+		return usage, nil
+	}
+	var deleted []string
+	mocks.roles.DeleteFunc = func(_ context.Context, id int64) error {
+		deleted = append(deleted, "delete")
+		return nil
+	}
+	mocks.roles.ReassignFunc = func(_ context.Context, id, toID int64) error {
+		if id != 5 || toID != 6 {
+			t.Errorf("reassigned %d to %d, want 5 to 6", id, toID)
+		}
+		deleted = append(deleted, "reassign")
+		return nil
+	}
+	mocks.roles.ForceDeleteFunc = func(context.Context, int64) error {
+		deleted = append(deleted, "force")
+		return nil
+	}
+	remove := func(target string) int {
+		return executeRequest(authedRequest(t, app, http.MethodDelete, target, ""), app.mount()).Code
+	}
+
+	t.Run("an unused role is deleted", func(t *testing.T) {
+		checkResponseCode(t, http.StatusNoContent, remove("/v1/restaurants/3/roles/7"))
+	})
+
+	t.Run("a role in use needs reassign_to or force", func(t *testing.T) {
+		checkResponseCode(t, http.StatusConflict, remove("/v1/restaurants/3/roles/5"))
+	})
+
+	t.Run("reassigns to another role of the restaurant", func(t *testing.T) {
+		checkResponseCode(t, http.StatusNoContent, remove("/v1/restaurants/3/roles/5?reassign_to=6"))
+		checkResponseCode(t, http.StatusBadRequest, remove("/v1/restaurants/3/roles/5?reassign_to=8"))
+		checkResponseCode(t, http.StatusBadRequest, remove("/v1/restaurants/3/roles/5?reassign_to=5"))
+	})
+
+	t.Run("force deletes its shifts too", func(t *testing.T) {
+		checkResponseCode(t, http.StatusNoContent, remove("/v1/restaurants/3/roles/5?force=true"))
+		checkResponseCode(t, http.StatusBadRequest, remove("/v1/restaurants/3/roles/5?force=true&reassign_to=6"))
+	})
+
+	want := []string{"delete", "reassign", "force"}
+	if strings.Join(deleted, ",") != strings.Join(want, ",") {
+		t.Errorf("deletions = %v, want %v", deleted, want)
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/google/uuid"
+)
+
+// maxSalesRecords bounds a single import, about a year of hourly figures
+const maxSalesRecords = 10_000
+
+// ImportSalesPayload is a batch of sales from a point of sale
+type ImportSalesPayload struct {
+	Records []SalesRecordPayload `json:"records" validate:"required,min=1,max=10000,dive"`
+}
+
+// SalesRecordPayload is a day's total, or one hour's when hour is given; at least one
+// of sales_cents and covers is required
+type SalesRecordPayload struct {
+	Date       string `json:"date" validate:"required,datetime=2006-01-02"`
+	Hour       *int   `json:"hour" validate:"omitempty,min=0,max=23"`
+	SalesCents *int64 `json:"sales_cents" validate:"omitempty,min=0"`
+	Covers     *int   `json:"covers" validate:"omitempty,min=0"`
+}
+
+type ImportSalesResponse struct {
+	Imported int `json:"imported"`
+}
+
+// SalesWebhookToken is the restaurant's new POS token, only ever shown here
+type SalesWebhookToken struct {
+	Token string `json:"token"`
+}
+
+// ImportSales godoc
+//
+//	@Summary		Imports sales from a point of sale
+//	@Description	Saves daily or hourly sales and cover counts, replacing figures already imported for the same day or hour. Send JSON records, or a CSV file with Content-Type text/csv and a header row naming its columns: date (YYYY-MM-DD) and any of hour (0-23, blank for the whole day), sales (in currency units, e.g. 1234.50), sales_cents and covers.
+//	@Tags			sales
+//	@Accept			json
+//	@Accept			text/csv
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		ImportSalesPayload	true	"Sales records"
+//	@Success		200				{object}	ImportSalesResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/sales [post]
+func (app *application) importSalesHandler(w http.ResponseWriter, r *http.Request) {
+	app.importSales(w, r, getRestaurantFromContext(r).ID, "")
+}
+
+// GetSales godoc
+//
+//	@Summary		Lists imported sales
+//	@Description	Lists the sales and cover counts imported for the restaurant from through to (default the last 28 days), by date and hour with whole-day figures first
+//	@Tags			sales
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Success		200				{array}		store.SalesRecord
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/sales [get]
+func (app *application) getSalesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	today := restaurant.Today(time.Now())
+	from, to, err := parseDateRange(r, today.AddDate(0, 0, -28), today)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	records, err := app.store.Sales.List(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, records); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateSalesWebhookToken godoc
+//
+//	@Summary		Issues the POS webhook token
+//	@Description	Issues the token the restaurant's point of sale posts sales to POST /pos/sales with, as "Authorization: POS <token>". It replaces any earlier token and is not shown again.
+//	@Tags			sales
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		201				{object}	SalesWebhookToken
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/sales/webhook-token [post]
+func (app *application) createSalesWebhookTokenHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	token := uuid.New().String()
+	if err := app.store.Sales.SetWebhookToken(r.Context(), restaurant.ID, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, &SalesWebhookToken{Token: token}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteSalesWebhookToken godoc
+//
+//	@Summary		Revokes the POS webhook token
+//	@Description	Stops the restaurant's point of sale from posting sales until a new token is issued
+//	@Tags			sales
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		204				{object}	string
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/sales/webhook-token [delete]
+func (app *application) deleteSalesWebhookTokenHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	if err := app.store.Sales.DeleteWebhookToken(r.Context(), restaurant.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("no POS webhook token"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PostPOSSales godoc
+//
+//	@Summary		Receives sales from a point of sale
+//	@Description	Webhook for a restaurant's point of sale, authenticated by the restaurant's POS token as "Authorization: POS <token>". Takes the same JSON or CSV as POST /restaurants/{restaurantID}/sales.
+//	@Tags			sales
+//	@Accept			json
+//	@Accept			text/csv
+//	@Produce		json
+//	@Param			payload	body		ImportSalesPayload	true	"Sales records"
+//	@Success		200		{object}	ImportSalesResponse
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/pos/sales [post]
+func (app *application) posSalesWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "POS" || parts[1] == "" {
+		app.unauthorizedErrorResponse(w, r, errors.New("POS authorization header is missing or malformed"))
+		return
+	}
+
+	restaurantID, err := app.store.Sales.AuthenticateWebhook(r.Context(), parts[1])
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r, errors.New("unknown or revoked POS token"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.importSales(w, r, restaurantID, store.SalesSourceWebhook)
+}
+
+// importSales reads the records in the request body and saves them to the restaurant.
+// Without a source they're marked as coming from the CSV or JSON they were sent as.
+func (app *application) importSales(w http.ResponseWriter, r *http.Request, restaurantID int64, source string) {
+	var (
+		payloads []SalesRecordPayload
+		err      error
+	)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		payloads, err = parseSalesCSV(r.Body)
+		if source == "" {
+			source = store.SalesSourceCSV
+		}
+	} else {
+		var payload ImportSalesPayload
+		err = readJSON(w, r, &payload)
+		payloads = payload.Records
+		if source == "" {
+			source = store.SalesSourceAPI
+		}
+	}
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(ImportSalesPayload{Records: payloads}); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	records := make([]*store.SalesRecord, len(payloads))
+	for i, p := range payloads {
+		if p.SalesCents == nil && p.Covers == nil {
+			app.badRequestResponse(w, r, fmt.Errorf("record %d has neither sales nor covers", i+1))
+			return
+		}
+		records[i] = &store.SalesRecord{
+			RestaurantID: restaurantID,
+			Date:         store.DateOnly(p.Date),
+			Hour:         p.Hour,
+			SalesCents:   p.SalesCents,
+			Covers:       p.Covers,
+			Source:       source,
+		}
+	}
+
+	if err := app.store.Sales.Import(r.Context(), records); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, &ImportSalesResponse{Imported: len(records)}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// parseSalesCSV reads sales records from CSV with a header row. Errors name the line,
+// counting the header as line 1.
+func parseSalesCSV(body io.Reader) ([]SalesRecordPayload, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("the CSV needs a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, errors.New("the CSV needs a date column")
+	}
+
+	var records []SalesRecordPayload
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(records) == maxSalesRecords {
+			return nil, fmt.Errorf("a CSV can hold at most %d records", maxSalesRecords)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := SalesRecordPayload{Date: field("date")}
+		if v := field("hour"); v != "" {
+			hour, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: hour %q is not a number", line, v)
+			}
+			record.Hour = &hour
+		}
+		if v := field("sales_cents"); v != "" {
+			cents, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: sales_cents %q is not a whole number", line, v)
+			}
+			record.SalesCents = &cents
+		} else if v := field("sales"); v != "" {
+			amount, err := strconv.ParseFloat(strings.NewReplacer("$", "", ",", "").Replace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: sales %q is not an amount", line, v)
+			}
+			cents := int64(math.Round(amount * 100))
+			record.SalesCents = &cents
+		}
+		if v := field("covers"); v != "" {
+			covers, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: covers %q is not a number", line, v)
+			}
+			record.Covers = &covers
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
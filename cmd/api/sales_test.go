@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestParseSalesCSV(t *testing.T) {
+	records, err := parseSalesCSV(strings.NewReader("Date,Hour,Sales,Covers\n2026-03-02,,\"$1,234.50\",88\n2026-03-02,12,310.25,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want two", records)
+	}
+	if r := records[0]; r.Hour != nil || *r.SalesCents != 123450 || *r.Covers != 88 {
+		t.Errorf("day total = %+v", r)
+	}
+	if r := records[1]; *r.Hour != 12 || *r.SalesCents != 31025 || r.Covers != nil {
+		t.Errorf("noon = %+v", r)
+	}
+
+	for name, csv := range map[string]string{
+		"no date column": "day,sales\n2026-03-02,10\n",
+		"a bad amount":   "date,sales\n2026-03-02,ten\n",
+		"a bad hour":     "date,hour,covers\n2026-03-02,noon,5\n",
+	} {
+		if _, err := parseSalesCSV(strings.NewReader(csv)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestImportSales(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	var imported []*store.SalesRecord
+	app.store.Sales = &store.MockSalesStorer{
+		ImportFunc: func(_ context.Context, records []*store.SalesRecord) error {
+			imported = records
+			return nil
+		},
+		AuthenticateWebhookFunc: func(_ context.Context, token string) (int64, error) {
+			if token != "pos-token" {
+				return 0, store.ErrNotFound
+			}
+			return 7, nil
+		},
+	}
+
+	t.Run("imports a CSV upload", func(t *testing.T) {
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/sales", "date,sales,covers\n2026-03-02,1200.00,80\n2026-03-03,950,61\n")
+		req.Header.Set("Content-Type", "text/csv")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(imported) != 2 || imported[0].RestaurantID != 3 || imported[0].Source != store.SalesSourceCSV || *imported[0].SalesCents != 120000 {
+			t.Errorf("imported = %+v", imported)
+		}
+	})
+
+	t.Run("a record needs sales or covers", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/sales", `{"records":[{"date":"2026-03-02","hour":12}]}`), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("the POS webhook imports to the token's restaurant", func(t *testing.T) {
+		post := func(auth string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/v1/pos/sales", strings.NewReader(`{"records":[{"date":"2026-03-02","hour":18,"covers":24}]}`))
+			req.Header.Set("Authorization", auth)
+			return executeRequest(req, app.mount())
+		}
+
+		checkResponseCode(t, http.StatusUnauthorized, post("POS wrong").Code)
+		checkResponseCode(t, http.StatusUnauthorized, post("Bearer pos-token").Code)
+
+		checkResponseCode(t, http.StatusOK, post("POS pos-token").Code)
+		if len(imported) != 1 || imported[0].RestaurantID != 7 || imported[0].Source != store.SalesSourceWebhook || *imported[0].Covers != 24 {
+			t.Errorf("imported = %+v", imported)
+		}
+	})
+}
+
+func TestSalesDefaultWindowInRestaurantTimezone(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		return &store.Restaurant{ID: id, UserID: testUserID, Timezone: "Pacific/Kiritimati"}, nil
+	}
+	var from, to store.DateOnly
+	app.store.Sales = &store.MockSalesStorer{
+		ListFunc: func(_ context.Context, _ int64, f, u store.DateOnly) ([]*store.SalesRecord, error) {
+			from, to = f, u
+			return nil, nil
+		},
+	}
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/7/sales", ""), app.mount())
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	today := time.Now().In(kiritimati)
+	if want := store.DateOnly(today.Format("2006-01-02")); to != want {
+		t.Errorf("to = %s, want the restaurant's today %s", to, want)
+	}
+	if want := store.DateOnly(today.AddDate(0, 0, -28).Format("2006-01-02")); from != want {
+		t.Errorf("from = %s, want %s", from, want)
+	}
+}
+
+func TestDemandVsStaffing(t *testing.T) {
+	sales := func(cents int64) *int64 { return &cents }
+	days := []*store.DemandDay{
+		{Date: "2026-03-02", SalesCents: sales(100000), ScheduledHours: 20}, // Monday
+		{Date: "2026-03-03", SalesCents: sales(150000), ScheduledHours: 30},
+		{Date: "2026-03-04", SalesCents: sales(200000), ScheduledHours: 40},
+		{Date: "2026-03-09", SalesCents: sales(140000), ScheduledHours: 20}, // Monday
+		{Date: "2026-03-10", ScheduledHours: 25},                            // no sales imported
+	}
+
+	report := demandVsStaffing(days, "2026-03-02", "2026-03-10")
+
+	if got := report.Days[0].SalesPerLaborHourCents; got == nil || *got != 5000 {
+		t.Errorf("sales per labor hour = %v, want 5000", got)
+	}
+	if report.Days[4].SalesPerLaborHourCents != nil {
+		t.Error("a day without sales has sales per labor hour")
+	}
+	if monday := report.Weekdays[1]; monday.Days != 2 || monday.AverageSalesCents != 120000 || monday.AverageScheduledHours != 20 {
+		t.Errorf("monday = %+v", monday)
+	}
+	if tuesday := report.Weekdays[2]; tuesday.Days != 1 {
+		t.Errorf("tuesday = %+v, want the day without sales left out", tuesday)
+	}
+	if r := report.SalesHoursCorrelation; r == nil || *r < 0.5 {
+		t.Errorf("correlation = %v, want strongly positive", r)
+	}
+	if report.CoversHoursCorrelation != nil {
+		t.Error("a correlation without covers")
+	}
+}
@@ -0,0 +1,671 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// defaultReportPeriodDays is the week before the day a report runs
+	defaultReportPeriodDays = 7
+	maxReportPeriodDays     = 366
+)
+
+// SavedReportPayload configures a saved report. Filters left empty include
+// every role or employee; recipients left empty email the restaurant's owner.
+type SavedReportPayload struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Kind        string  `json:"kind" validate:"required,oneof=hours_by_employee coverage_by_role labor_cost"`
+	RoleIDs     []int64 `json:"role_ids" validate:"max=100"`
+	EmployeeIDs []int64 `json:"employee_ids" validate:"max=500"`
+	// PeriodDays is how many days up to the day before a run it covers; default 7
+	PeriodDays int    `json:"period_days" validate:"omitempty,min=1,max=366"`
+	Delivery   string `json:"delivery" validate:"omitempty,oneof=none weekly"`
+	// DeliveryWeekday is the day a weekly report is emailed, 0 = Sunday; default Monday
+	DeliveryWeekday *int     `json:"delivery_weekday" validate:"omitempty,min=0,max=6"`
+	Recipients      []string `json:"recipients" validate:"max=10,dive,email"`
+}
+
+// ReportResult is a saved report run over a period, laid out as the table its
+// CSV holds
+type ReportResult struct {
+	ReportID int64          `json:"report_id"`
+	Name     string         `json:"name"`
+	Kind     string         `json:"kind"`
+	From     store.DateOnly `json:"from"`
+	To       store.DateOnly `json:"to"`
+	Columns  []string       `json:"columns"`
+	Rows     [][]string     `json:"rows"`
+}
+
+// apply copies the payload onto report, filling in the defaults
+func (p *SavedReportPayload) apply(report *store.SavedReport) {
+	report.Name = strings.TrimSpace(p.Name)
+	report.Kind = p.Kind
+	report.RoleIDs = nonNil(p.RoleIDs)
+	report.EmployeeIDs = nonNil(p.EmployeeIDs)
+	report.PeriodDays = p.PeriodDays
+	if report.PeriodDays == 0 {
+		report.PeriodDays = defaultReportPeriodDays
+	}
+	report.Delivery = p.Delivery
+	if report.Delivery == "" {
+		report.Delivery = store.ReportDeliveryNone
+	}
+	report.DeliveryWeekday = int(time.Monday)
+	if p.DeliveryWeekday != nil {
+		report.DeliveryWeekday = *p.DeliveryWeekday
+	}
+	report.Recipients = make([]string, 0, len(p.Recipients))
+	for _, email := range p.Recipients {
+		report.Recipients = append(report.Recipients, strings.ToLower(strings.TrimSpace(email)))
+	}
+}
+
+func nonNil(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
+
+// CreateSavedReport godoc
+//
+//	@Summary		Saves a report
+//	@Description	Saves a report configuration to run again: hours_by_employee, coverage_by_role or labor_cost over the period_days before each run, narrowed to role_ids and employee_ids. With delivery weekly it's emailed as a CSV attachment every delivery_weekday (0 = Sunday) to the recipients, or the restaurant's owner when there are none.
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			payload			body		SavedReportPayload	true	"Report configuration"
+//	@Success		201				{object}	store.SavedReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved [post]
+func (app *application) createSavedReportHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+
+	var payload SavedReportPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	report := &store.SavedReport{RestaurantID: restaurant.ID, CreatedBy: &user.ID}
+	payload.apply(report)
+	if err := app.store.SavedReports.Create(r.Context(), report); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetSavedReports godoc
+//
+//	@Summary		Lists saved reports
+//	@Description	Lists the restaurant's saved reports by name, with when each was last emailed
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.SavedReport
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved [get]
+func (app *application) getSavedReportsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	reports, err := app.store.SavedReports.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, reports); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetSavedReport godoc
+//
+//	@Summary		Gets a saved report
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			reportID		path		int	true	"Saved report ID"
+//	@Success		200				{object}	store.SavedReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved/{reportID} [get]
+func (app *application) getSavedReportHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	report, ok := app.savedReportInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateSavedReport godoc
+//
+//	@Summary		Updates a saved report
+//	@Description	Replaces the report's configuration; fields left out take their defaults
+//	@Tags			reports
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int					true	"Restaurant ID"
+//	@Param			reportID		path		int					true	"Saved report ID"
+//	@Param			payload			body		SavedReportPayload	true	"Report configuration"
+//	@Success		200				{object}	store.SavedReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved/{reportID} [put]
+func (app *application) updateSavedReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, ok := app.savedReportInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	var payload SavedReportPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	payload.apply(report)
+	if err := app.store.SavedReports.Update(r.Context(), report); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteSavedReport godoc
+//
+//	@Summary		Deletes a saved report
+//	@Description	Deletes the report, stopping its emails
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			reportID		path		int	true	"Saved report ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved/{reportID} [delete]
+func (app *application) deleteSavedReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, ok := app.savedReportInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.store.SavedReports.Delete(r.Context(), report.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RunSavedReport godoc
+//
+//	@Summary		Runs a saved report
+//	@Description	Runs the report over from through to, by default its period_days up to yesterday (at most 366 days). format=csv answers with the CSV file the weekly email attaches instead of JSON.
+//	@Tags			reports
+//	@Produce		json,text/csv
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			reportID		path		int		true	"Saved report ID"
+//	@Param			from			query		string	false	"First date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last date (YYYY-MM-DD)"
+//	@Param			format			query		string	false	"csv for a file instead of JSON"
+//	@Success		200				{object}	ReportResult
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/saved/{reportID}/run [get]
+func (app *application) runSavedReportHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	report, ok := app.savedReportInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	defaultFrom, defaultTo := reportPeriod(restaurant, report, time.Now())
+	from, to, err := parseDateRange(r, defaultFrom, defaultTo)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if to.Sub(from) >= maxReportPeriodDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("the range can be at most %d days", maxReportPeriodDays))
+		return
+	}
+
+	result, err := app.runSavedReport(r.Context(), restaurant, report, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") != "csv" {
+		if err := app.jsonResponse(w, r, http.StatusOK, result); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	body, err := result.CSV()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, result.Filename()))
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// savedReportInRestaurant loads the saved report named in the URL, answering
+// 404 when it belongs to another restaurant
+func (app *application) savedReportInRestaurant(w http.ResponseWriter, r *http.Request) (*store.SavedReport, bool) {
+	reportID, err := strconv.ParseInt(chi.URLParam(r, "reportID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid report ID"))
+		return nil, false
+	}
+
+	report, err := app.store.SavedReports.GetByID(r.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if report.RestaurantID != getRestaurantFromContext(r).ID {
+		app.notFoundResponse(w, r, errors.New("report not found"))
+		return nil, false
+	}
+
+	return report, true
+}
+
+// reportPeriod is the report's period_days ending the day before now, in the restaurant's timezone
+func reportPeriod(restaurant *store.Restaurant, report *store.SavedReport, now time.Time) (time.Time, time.Time) {
+	to := restaurant.Today(now).AddDate(0, 0, -1)
+	return to.AddDate(0, 0, 1-report.PeriodDays), to
+}
+
+// runSavedReport loads the restaurant's shifts from through to, and the hourly
+// rates when the report prices them, and runs the report over them
+func (app *application) runSavedReport(ctx context.Context, restaurant *store.Restaurant, report *store.SavedReport, from, to time.Time) (*ReportResult, error) {
+	shifts, err := app.store.ScheduledShifts.ListByRestaurantAndRange(ctx, restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[int64]int)
+	if report.Kind != store.ReportCoverageByRole {
+		employees, err := app.store.Employees.ListByRestaurant(ctx, restaurant.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range employees {
+			if e.HourlyRateCents != nil {
+				rates[e.ID] = *e.HourlyRateCents
+			}
+		}
+	}
+
+	return savedReportResult(report, dateOnly(from), dateOnly(to), shifts, rates, restaurant.WeeklyLaborBudgetCents), nil
+}
+
+// savedReportResult runs the report over the shifts its filters keep
+func savedReportResult(report *store.SavedReport, from, to store.DateOnly, shifts []*store.ScheduledShift, rates map[int64]int, weeklyBudget *int) *ReportResult {
+	result := &ReportResult{ReportID: report.ID, Name: report.Name, Kind: report.Kind, From: from, To: to, Rows: [][]string{}}
+
+	roles := make(map[int64]bool, len(report.RoleIDs))
+	for _, id := range report.RoleIDs {
+		roles[id] = true
+	}
+	employees := make(map[int64]bool, len(report.EmployeeIDs))
+	for _, id := range report.EmployeeIDs {
+		employees[id] = true
+	}
+	kept := make([]*store.ScheduledShift, 0, len(shifts))
+	for _, shift := range shifts {
+		if len(roles) > 0 && !roles[shift.RoleID] {
+			continue
+		}
+		if len(employees) > 0 && (shift.EmployeeID == nil || !employees[*shift.EmployeeID]) {
+			continue
+		}
+		kept = append(kept, shift)
+	}
+
+	switch report.Kind {
+	case store.ReportHoursByEmployee:
+		hoursByEmployee(result, kept, rates)
+	case store.ReportCoverageByRole:
+		coverageByRole(result, kept)
+	case store.ReportLaborCost:
+		laborCostByDay(result, kept, rates, weeklyBudget)
+	}
+	return result
+}
+
+// hoursByEmployee totals each employee's shifts and hours, priced at their
+// hourly rate when they have one. Open shifts have no one to count toward.
+func hoursByEmployee(result *ReportResult, shifts []*store.ScheduledShift, rates map[int64]int) {
+	result.Columns = []string{"Employee", "Shifts", "Hours", "Cost"}
+
+	type total struct {
+		name    string
+		shifts  int
+		minutes int
+		cents   int
+	}
+	totals := make(map[int64]*total)
+	for _, shift := range shifts {
+		if shift.EmployeeID == nil {
+			continue
+		}
+		t, ok := totals[*shift.EmployeeID]
+		if !ok {
+			t = &total{}
+			if shift.EmployeeName != nil {
+				t.name = *shift.EmployeeName
+			}
+			totals[*shift.EmployeeID] = t
+		}
+		minutes := shiftMinutes(shift)
+		t.shifts++
+		t.minutes += minutes
+		if rate, ok := rates[*shift.EmployeeID]; ok {
+			t.cents += (minutes*rate + 30) / 60
+		}
+	}
+
+	ids := make([]int64, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := totals[ids[i]], totals[ids[j]]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return ids[i] < ids[j]
+	})
+
+	var sum total
+	for _, id := range ids {
+		t := totals[id]
+		cost := ""
+		if _, ok := rates[id]; ok {
+			cost = formatCents(t.cents)
+		}
+		result.Rows = append(result.Rows, []string{t.name, strconv.Itoa(t.shifts), reportHours(float64(t.minutes) / 60), cost})
+		sum.shifts += t.shifts
+		sum.minutes += t.minutes
+		sum.cents += t.cents
+	}
+	result.Rows = append(result.Rows, []string{"Total", strconv.Itoa(sum.shifts), reportHours(float64(sum.minutes) / 60), formatCents(sum.cents)})
+}
+
+// coverageByRole counts each role's shifts, how many were staffed and left
+// open, and their hours
+func coverageByRole(result *ReportResult, shifts []*store.ScheduledShift) {
+	result.Columns = []string{"Role", "Shifts", "Staffed", "Open", "Hours"}
+
+	type total struct {
+		name                     string
+		shifts, staffed, minutes int
+	}
+	totals := make(map[int64]*total)
+	for _, shift := range shifts {
+		t, ok := totals[shift.RoleID]
+		if !ok {
+			t = &total{name: shift.RoleName}
+			totals[shift.RoleID] = t
+		}
+		t.shifts++
+		if shift.EmployeeID != nil {
+			t.staffed++
+		}
+		t.minutes += shiftMinutes(shift)
+	}
+
+	ids := make([]int64, 0, len(totals))
+	for id := range totals {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := totals[ids[i]], totals[ids[j]]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return ids[i] < ids[j]
+	})
+
+	for _, id := range ids {
+		t := totals[id]
+		result.Rows = append(result.Rows, []string{
+			t.name,
+			strconv.Itoa(t.shifts),
+			strconv.Itoa(t.staffed),
+			strconv.Itoa(t.shifts - t.staffed),
+			reportHours(float64(t.minutes) / 60),
+		})
+	}
+}
+
+// laborCostByDay prices each day of the period as a schedule's labor cost
+// would be, with the weekly budget prorated to the period
+func laborCostByDay(result *ReportResult, shifts []*store.ScheduledShift, rates map[int64]int, weeklyBudget *int) {
+	result.Columns = []string{"Date", "Hours", "Unpriced hours", "Cost"}
+
+	cost := laborCost(&store.Schedule{StartDate: result.From, EndDate: result.To}, shifts, rates, weeklyBudget)
+	for _, day := range cost.Days {
+		result.Rows = append(result.Rows, []string{string(day.Date), reportHours(day.Hours), reportHours(day.UnpricedHours), formatCents(day.CostCents)})
+	}
+	result.Rows = append(result.Rows, []string{"Total", reportHours(cost.Hours), reportHours(cost.UnpricedHours), formatCents(cost.TotalCents)})
+	if cost.BudgetCents != nil {
+		result.Rows = append(result.Rows, []string{"Budget", "", "", formatCents(*cost.BudgetCents)})
+	}
+}
+
+// reportHours writes hours to two places, as spreadsheets read them
+func reportHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', 2, 64)
+}
+
+// CSV writes the result as a CSV file with a header row
+func (res *ReportResult) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	if err := out.Write(res.Columns); err != nil {
+		return nil, err
+	}
+	if err := out.WriteAll(res.Rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Filename names the result's CSV file after its kind and period
+func (res *ReportResult) Filename() string {
+	return fmt.Sprintf("%s-%s-%s.csv", strings.ReplaceAll(res.Kind, "_", "-"), res.From, res.To)
+}
+
+// runSavedReportEmails periodically emails the weekly saved reports due that day
+func (app *application) runSavedReportEmails(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sent, err := app.sendSavedReports(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("saved report emails failed", "error", err)
+			continue
+		}
+
+		if sent > 0 {
+			app.logger.Infow("emailed saved reports", "count", sent)
+		}
+	}
+}
+
+// sendSavedReports claims the weekly reports due today and emails each its
+// recipients, returning how many reports went out. A report is claimed before
+// it's sent so several instances never send it twice; one that fails waits
+// for next week.
+func (app *application) sendSavedReports(ctx context.Context, now time.Time) (int, error) {
+	reports, err := app.store.SavedReports.ClaimDue(ctx, dateOnly(now))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, report := range reports {
+		if err := app.sendSavedReport(ctx, report, now); err != nil {
+			app.logger.Warnw("failed to email saved report", "report_id", report.ID, "restaurant_id", report.RestaurantID, "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// sendSavedReport runs the report over its period and emails it as a CSV
+// attachment to its recipients, or the restaurant's owner when it has none
+func (app *application) sendSavedReport(ctx context.Context, report *store.SavedReport, now time.Time) error {
+	restaurant, err := app.store.Restaurants.GetByID(ctx, report.RestaurantID)
+	if err != nil {
+		return err
+	}
+
+	owner, err := app.store.Users.GetByID(ctx, restaurant.UserID)
+	if err != nil {
+		return err
+	}
+	locale := i18n.Resolve(owner.Locale)
+
+	from, to := reportPeriod(restaurant, report, now)
+	result, err := app.runSavedReport(ctx, restaurant, report, from, to)
+	if err != nil {
+		return err
+	}
+	body, err := result.CSV()
+	if err != nil {
+		return err
+	}
+	attachment := mailer.Attachment{Filename: result.Filename(), ContentType: "text/csv", Content: body}
+
+	emailData := struct {
+		ReportName     string
+		RestaurantName string
+		From           string
+		To             string
+		Rows           int
+	}{
+		ReportName:     report.Name,
+		RestaurantName: restaurant.Name,
+		From:           i18n.FormatDate(locale, from),
+		To:             i18n.FormatDate(locale, to),
+		Rows:           len(result.Rows),
+	}
+
+	recipients := report.Recipients
+	if len(recipients) == 0 {
+		recipients = []string{owner.Email}
+	}
+
+	isProdEnv := app.config.env == "production"
+	var failed error
+	for _, email := range recipients {
+		if _, err := app.mailer.SendWithAttachments(mailer.Localized(mailer.SavedReportTemplate, locale), "", email, emailData, !isProdEnv, []mailer.Attachment{attachment}); err != nil {
+			failed = err
+		}
+	}
+	return failed
+}
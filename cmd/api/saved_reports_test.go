@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func reportShifts() []*store.ScheduledShift {
+	ana, ben := "Ana", "Ben"
+	employee := func(id int64) *int64 { return &id }
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	return []*store.ScheduledShift{
+		{RoleID: 1, RoleName: "Server", EmployeeID: employee(1), EmployeeName: &ana, ShiftDate: day(1), StartTime: "09:00:00", EndTime: "17:00:00"},
+		{RoleID: 1, RoleName: "Server", EmployeeID: employee(2), EmployeeName: &ben, ShiftDate: day(2), StartTime: "12:00:00", EndTime: "16:30:00"},
+		{RoleID: 2, RoleName: "Cook", EmployeeID: employee(1), EmployeeName: &ana, ShiftDate: day(2), StartTime: "17:00:00", EndTime: "21:00:00"},
+		{RoleID: 2, RoleName: "Cook", ShiftDate: day(2), StartTime: "10:00:00", EndTime: "14:00:00"},
+	}
+}
+
+func TestSavedReportResult(t *testing.T) {
+	rates := map[int64]int{1: 2000}
+
+	t.Run("hours by employee", func(t *testing.T) {
+		report := &store.SavedReport{Kind: store.ReportHoursByEmployee}
+		result := savedReportResult(report, "2026-06-01", "2026-06-02", reportShifts(), rates, nil)
+
+		want := [][]string{
+			{"Ana", "2", "12.00", "240.00"},
+			{"Ben", "1", "4.50", ""},
+			{"Total", "3", "16.50", "240.00"},
+		}
+		if !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("rows = %v, want %v", result.Rows, want)
+		}
+	})
+
+	t.Run("coverage by role, filtered to a role", func(t *testing.T) {
+		report := &store.SavedReport{Kind: store.ReportCoverageByRole, RoleIDs: []int64{2}}
+		result := savedReportResult(report, "2026-06-01", "2026-06-02", reportShifts(), nil, nil)
+
+		want := [][]string{{"Cook", "2", "1", "1", "8.00"}}
+		if !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("rows = %v, want %v", result.Rows, want)
+		}
+	})
+
+	t.Run("labor cost by day against the prorated budget", func(t *testing.T) {
+		budget := 70000
+		report := &store.SavedReport{Kind: store.ReportLaborCost, EmployeeIDs: []int64{1}}
+		result := savedReportResult(report, "2026-06-01", "2026-06-02", reportShifts(), rates, &budget)
+
+		want := [][]string{
+			{"2026-06-01", "8.00", "0.00", "160.00"},
+			{"2026-06-02", "4.00", "0.00", "80.00"},
+			{"Total", "12.00", "0.00", "240.00"},
+			{"Budget", "", "", "200.00"},
+		}
+		if !reflect.DeepEqual(result.Rows, want) {
+			t.Errorf("rows = %v, want %v", result.Rows, want)
+		}
+	})
+}
+
+func TestCreateSavedReport(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	var saved *store.SavedReport
+	app.store.SavedReports = &store.MockSavedReportStorer{
+		CreateFunc: func(_ context.Context, report *store.SavedReport) error {
+			saved = report
+			return nil
+		},
+	}
+
+	for body, want := range map[string]int{
+		`{"name": "Weekly hours", "kind": "tips"}`:                                                                        http.StatusBadRequest,
+		`{"name": "Weekly hours", "kind": "hours_by_employee", "recipients": ["not-email"]}`:                              http.StatusBadRequest,
+		`{"name": "Weekly hours", "kind": "hours_by_employee", "delivery_weekday": 7}`:                                    http.StatusBadRequest,
+		`{"name": " Weekly hours ", "kind": "hours_by_employee", "delivery": "weekly", "recipients": ["GM@Example.com"]}`: http.StatusCreated,
+	} {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/reports/saved", body), app.mount())
+		if rr.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rr.Code, want)
+		}
+	}
+
+	if saved == nil {
+		t.Fatal("nothing was saved")
+	}
+	if saved.Name != "Weekly hours" || saved.PeriodDays != 7 || saved.DeliveryWeekday != int(time.Monday) || saved.Recipients[0] != "gm@example.com" {
+		t.Errorf("report = %+v, want the defaults and a lowercased recipient", saved)
+	}
+}
+
+func TestRunSavedReport(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.SavedReports = &store.MockSavedReportStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.SavedReport, error) {
+			return &store.SavedReport{ID: id, RestaurantID: 1, Kind: store.ReportCoverageByRole, PeriodDays: 7}, nil
+		},
+	}
+	var from, to store.DateOnly
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByRestaurantAndRangeFunc: func(_ context.Context, _ int64, f, t store.DateOnly) ([]*store.ScheduledShift, error) {
+			from, to = f, t
+			return reportShifts(), nil
+		},
+	}
+
+	t.Run("as JSON over the week up to yesterday", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/reports/saved/3/run", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data ReportResult `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		yesterday := time.Now().UTC().AddDate(0, 0, -1)
+		if to != dateOnly(yesterday) || from != dateOnly(yesterday.AddDate(0, 0, -6)) {
+			t.Errorf("ran from %s to %s, want the 7 days to yesterday", from, to)
+		}
+		if len(body.Data.Rows) != 2 || body.Data.Columns[0] != "Role" {
+			t.Errorf("result = %+v, want a row per role", body.Data)
+		}
+	})
+
+	t.Run("as CSV", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/reports/saved/3/run?from=2026-06-01&to=2026-06-07&format=csv", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get("Content-Disposition"); !strings.Contains(got, "coverage-by-role-2026-06-01-2026-06-07.csv") {
+			t.Errorf("Content-Disposition = %q", got)
+		}
+		want := "Role,Shifts,Staffed,Open,Hours\nCook,2,1,1,8.00\nServer,2,2,0,12.50\n"
+		if rr.Body.String() != want {
+			t.Errorf("csv = %q, want %q", rr.Body.String(), want)
+		}
+	})
+}
+
+func TestSendSavedReports(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+	mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+		return &store.Restaurant{ID: id, UserID: testUserID, Name: "Blue Door"}, nil
+	}
+	mocks.users.GetByIDFunc = func(_ context.Context, id int64) (*store.User, error) {
+		return &store.User{ID: id, FirstName: "Olive", Email: "owner@example.com"}, nil
+	}
+	app.store.SavedReports = &store.MockSavedReportStorer{
+		ClaimDueFunc: func(_ context.Context, today store.DateOnly) ([]*store.SavedReport, error) {
+			return []*store.SavedReport{
+				{ID: 1, RestaurantID: 1, Name: "Hours", Kind: store.ReportHoursByEmployee, PeriodDays: 7},
+				{ID: 2, RestaurantID: 1, Name: "Coverage", Kind: store.ReportCoverageByRole, PeriodDays: 7, Recipients: []string{"gm@example.com", "chef@example.com"}},
+			}, nil
+		},
+	}
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByRestaurantAndRangeFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.ScheduledShift, error) {
+			return reportShifts(), nil
+		},
+	}
+	app.store.Employees = &store.MockEmployeeStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) { return nil, nil },
+	}
+
+	sent, err := app.sendSavedReports(context.Background(), time.Date(2026, 6, 8, 7, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 {
+		t.Errorf("sent %d reports, want 2", sent)
+	}
+
+	wantSent := []string{"saved_report.go.tmpl owner@example.com", "saved_report.go.tmpl gm@example.com", "saved_report.go.tmpl chef@example.com"}
+	if !reflect.DeepEqual(mail.sent, wantSent) {
+		t.Errorf("sent = %v, want %v", mail.sent, wantSent)
+	}
+	if len(mail.attachments) != 3 || mail.attachments[0] != "hours-by-employee-2026-06-01-2026-06-07.csv" {
+		t.Errorf("attachments = %v", mail.attachments)
+	}
+}
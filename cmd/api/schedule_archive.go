@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type BulkArchiveSchedulesPayload struct {
+	// Before archives the schedules that ended before this date
+	Before string `json:"before" validate:"required,datetime=2006-01-02"`
+}
+
+// BulkArchiveResult is how many schedules a bulk archive hid
+type BulkArchiveResult struct {
+	Archived int64 `json:"archived"`
+}
+
+// BulkArchiveSchedules godoc
+//
+//	@Summary		Archives past schedules
+//	@Description	Archives every schedule that ended before the given date, which can't be later than today. Archived schedules are left out of the schedule list unless it's called with archived=true, but are kept, shifts included, and still exported.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int							true	"Restaurant ID"
+//	@Param			payload			body		BulkArchiveSchedulesPayload	true	"Cutoff date"
+//	@Success		200				{object}	BulkArchiveResult
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/bulk-archive [post]
+func (app *application) bulkArchiveSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload BulkArchiveSchedulesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Only schedules that are over can be archived
+	if payload.Before > restaurant.Today(time.Now()).Format("2006-01-02") {
+		app.badRequestResponse(w, r, errors.New("before can't be later than today"))
+		return
+	}
+
+	archived, err := app.store.Schedules.ArchiveEndedBefore(r.Context(), restaurant.ID, store.DateOnly(payload.Before))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, &BulkArchiveResult{Archived: archived}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// runScheduleRetention periodically archives schedules older than their restaurant's retention setting
+func (app *application) runScheduleRetention(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		archived, err := app.store.Schedules.ArchiveExpired(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("schedule retention failed", "error", err)
+			continue
+		}
+
+		if archived > 0 {
+			app.retireAllResponses(context.Background())
+			app.logger.Infow("archived expired schedules", "count", archived)
+		}
+	}
+}
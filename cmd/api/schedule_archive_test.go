@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestBulkArchiveSchedules(t *testing.T) {
+	setup := func(t *testing.T) (*application, *store.DateOnly) {
+		app, _ := newMockedApplication(t, testUserID)
+		cutoff := new(store.DateOnly)
+		app.store.Schedules = &store.MockScheduleStorer{
+			ArchiveEndedBeforeFunc: func(_ context.Context, _ int64, before store.DateOnly) (int64, error) {
+				*cutoff = before
+				return 4, nil
+			},
+		}
+		return app, cutoff
+	}
+
+	t.Run("archived", func(t *testing.T) {
+		app, cutoff := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/bulk-archive", `{"before": "2025-01-01"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if *cutoff != "2025-01-01" {
+			t.Errorf("cutoff = %q, want 2025-01-01", *cutoff)
+		}
+	})
+
+	t.Run("cutoff in the future", func(t *testing.T) {
+		app, cutoff := setup(t)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/bulk-archive", `{"before": "`+tomorrow+`"}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if *cutoff != "" {
+			t.Error("schedules were archived")
+		}
+	})
+}
@@ -0,0 +1,497 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// ScheduleChanges is the difference between a schedule's shifts at a baseline and now.
+// A shift that moved and changed hands appears in both TimeChanged and Reassigned.
+type ScheduleChanges struct {
+	ScheduleID          int64                  `json:"schedule_id"`
+	Since               time.Time              `json:"since"`
+	Baseline            string                 `json:"baseline"`
+	Added               []*store.SnapshotShift `json:"added"`
+	Removed             []*store.SnapshotShift `json:"removed"`
+	TimeChanged         []ShiftChange          `json:"time_changed"`
+	Reassigned          []ShiftChange          `json:"reassigned"`
+	AffectedEmployeeIDs []int64                `json:"affected_employee_ids"`
+}
+
+// ShiftChange is a shift as it was at the baseline and as it is now
+type ShiftChange struct {
+	ShiftID int64                `json:"shift_id"`
+	Before  *store.SnapshotShift `json:"before"`
+	After   *store.SnapshotShift `json:"after"`
+}
+
+// NotifyScheduleChangesPayload picks the baseline; without since the most recent publish or notification is used.
+// Baseline "published" skips notifications, comparing with the version employees were published.
+// Critical emails everyone straight away, even employees who get digests.
+type NotifyScheduleChangesPayload struct {
+	Since    *time.Time `json:"since"`
+	Baseline string     `json:"baseline" validate:"omitempty,oneof=latest published"`
+	Critical bool       `json:"critical"`
+}
+
+// baselinePublished diffs against the published version only, not later change notifications
+const baselinePublished = "published"
+
+// employeeDelta is how a schedule changed from one employee's point of view
+type employeeDelta struct {
+	added   []*store.SnapshotShift
+	removed []*store.SnapshotShift
+	changed []ShiftChange
+}
+
+// ScheduleChangesEmailData contains the data for the schedule changes email template
+type ScheduleChangesEmailData struct {
+	RestaurantName string
+	EmployeeName   string
+	ScheduleStart  string
+	ScheduleEnd    string
+	Added          []ScheduleEmailShift
+	Removed        []ScheduleEmailShift
+	Changed        []ScheduleEmailShiftChange
+}
+
+// ScheduleEmailShiftChange shows a changed shift's old and new times
+type ScheduleEmailShiftChange struct {
+	Before ScheduleEmailShift
+	After  ScheduleEmailShift
+}
+
+// GetScheduleChanges godoc
+//
+//	@Summary		Lists changes to a published schedule
+//	@Description	Compares the schedule's shifts now with a baseline: the schedule as it was at since, or by default at its most recent publish or change notification. With baseline=published only publishes count, so the diff is against the version employees were published. Shifts are reported as added, removed, time_changed (date or times) or reassigned.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			id				path		int		true	"Schedule ID"
+//	@Param			since			query		string	false	"Baseline time (RFC 3339)"
+//	@Param			baseline		query		string	false	"latest (default) or published"
+//	@Success		200				{object}	ScheduleChanges
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/schedules/{id}/changes [get]
+func (app *application) getScheduleChangesHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var since *time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequestResponse(w, r, errors.New("since must be an RFC 3339 timestamp"))
+			return
+		}
+		since = &parsed
+	}
+
+	baseline := r.URL.Query().Get("baseline")
+	if baseline != "" && baseline != "latest" && baseline != baselinePublished {
+		app.badRequestResponse(w, r, errors.New("baseline must be latest or published"))
+		return
+	}
+
+	changes, _, err := app.scheduleChanges(r.Context(), schedule, since, baseline)
+	if err != nil {
+		app.scheduleChangesErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, changes); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// NotifyScheduleChanges godoc
+//
+//	@Summary		Emails employees affected by schedule changes
+//	@Description	Sends each employee whose shifts were added, removed, moved or reassigned since the baseline an email listing only their changes. Employees who get digests have their changes held for their next one (counted as queued) unless critical is set. Once any email is sent or queued, the current shifts become the new baseline.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int								true	"Restaurant ID"
+//	@Param			id				path		int								true	"Schedule ID"
+//	@Param			payload			body		NotifyScheduleChangesPayload	false	"Baseline"
+//	@Success		200				{object}	SendScheduleEmailResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/schedules/{id}/notify-changes [post]
+func (app *application) notifyScheduleChangesHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var payload NotifyScheduleChangesPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+
+	changes, deltas, err := app.scheduleChanges(ctx, schedule, payload.Since, payload.Baseline)
+	if err != nil {
+		app.scheduleChangesErrorResponse(w, r, err)
+		return
+	}
+
+	response := SendScheduleEmailResponse{
+		TotalRecipients: len(changes.AffectedEmployeeIDs),
+		Failures:        []SendScheduleEmailFailure{},
+	}
+
+	if len(changes.AffectedEmployeeIDs) == 0 {
+		if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	employees, err := app.store.Employees.GetByIDs(ctx, changes.AffectedEmployeeIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, schedule.RestaurantID)
+	if !ok {
+		return
+	}
+	response.Quota = quota
+
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+
+	var digestItems []*store.DigestItem
+	for _, employee := range employees {
+		if employee.Email == "" {
+			response.Failed++
+			response.Failures = append(response.Failures, SendScheduleEmailFailure{
+				EmployeeID:   employee.ID,
+				EmployeeName: employee.FullName,
+				Error:        "no email address",
+			})
+			continue
+		}
+
+		locale := i18n.Resolve(employee.Locale, user.Locale)
+		emailData := buildScheduleChangesEmailData(employee, deltas[employee.ID], restaurant.Name, schedule, locale)
+
+		if !payload.Critical && restaurant.NotificationModeFor(employee).Digest() {
+			digestItems = append(digestItems, scheduleChangesDigestItem(employee, emailData))
+			continue
+		}
+
+		err := app.sendTrackedScheduleEmail(
+			r.Context(),
+			schedule,
+			store.EmailKindChanges,
+			employee,
+			mailer.Localized(mailer.ScheduleChangesTemplate, locale),
+			emailData,
+		)
+		if err != nil {
+			app.logger.Warnw("failed to send schedule changes email",
+				"employee_id", employee.ID,
+				"email", employee.Email,
+				"error", err,
+			)
+			response.Failed++
+			response.Failures = append(response.Failures, SendScheduleEmailFailure{
+				EmployeeID:   employee.ID,
+				EmployeeName: employee.FullName,
+				Email:        employee.Email,
+				Error:        err.Error(),
+			})
+			continue
+		}
+
+		response.Successful++
+	}
+
+	if len(digestItems) > 0 {
+		if err := app.store.NotificationDigests.Enqueue(ctx, digestItems); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		response.Queued = len(digestItems)
+	}
+
+	// Changes that reached at least someone are not announced again
+	if response.Successful > 0 || response.Queued > 0 {
+		if err := app.store.ScheduleSnapshots.Create(ctx, schedule.ID, store.SnapshotNotified); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+var errScheduleNotPublished = errors.New("schedule has not been published")
+
+// GetPublishedScheduleVersion godoc
+//
+//	@Summary		Shows the schedule as published
+//	@Description	Returns the shifts exactly as they were when the schedule was last published, unaffected by later edits, with which publish it was (version counts from 1). With at, returns the version that was published at that time instead, e.g. to settle what an employee was told.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			at				query		string	false	"Time to look back to (RFC 3339)"
+//	@Success		200				{object}	store.ScheduleSnapshot
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/published-version [get]
+func (app *application) getPublishedScheduleVersionHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var at *time.Time
+	if v := r.URL.Query().Get("at"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			app.badRequestResponse(w, r, errors.New("at must be an RFC 3339 timestamp"))
+			return
+		}
+		at = &parsed
+	}
+
+	if schedule.PublishedAt == nil {
+		app.scheduleChangesErrorResponse(w, r, errScheduleNotPublished)
+		return
+	}
+
+	snapshot, err := app.store.ScheduleSnapshots.LatestPublished(r.Context(), schedule.ID, at)
+	if err != nil {
+		app.scheduleChangesErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, snapshot); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// scheduleChanges diffs the schedule's current shifts against the snapshot in effect at since;
+// with baselinePublished, the published version in effect then
+func (app *application) scheduleChanges(ctx context.Context, schedule *store.Schedule, since *time.Time, against string) (*ScheduleChanges, map[int64]*employeeDelta, error) {
+	if schedule.PublishedAt == nil {
+		return nil, nil, errScheduleNotPublished
+	}
+
+	latest := app.store.ScheduleSnapshots.Latest
+	if against == baselinePublished {
+		latest = app.store.ScheduleSnapshots.LatestPublished
+	}
+	baseline, err := latest(ctx, schedule.ID, since)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := app.store.ScheduleSnapshots.CurrentShifts(ctx, schedule.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes, deltas := diffShifts(baseline.Shifts, current)
+	changes.ScheduleID = schedule.ID
+	changes.Since = baseline.TakenAt
+	changes.Baseline = baseline.Reason
+
+	return changes, deltas, nil
+}
+
+func (app *application) scheduleChangesErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errScheduleNotPublished):
+		app.badRequestResponse(w, r, err)
+	case errors.Is(err, store.ErrNotFound):
+		app.notFoundResponse(w, r, errors.New("no published version of the schedule at that time"))
+	default:
+		app.internalServerError(w, r, err)
+	}
+}
+
+// restaurantScheduleFromURL loads the schedule in the URL, checking it belongs to the user's restaurant
+func (app *application) restaurantScheduleFromURL(w http.ResponseWriter, r *http.Request) (*store.Schedule, bool) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return nil, false
+	}
+
+	return app.scheduleInRestaurant(w, r)
+}
+
+// diffShifts compares two versions of a schedule's shifts, both overall and per affected employee
+func diffShifts(before, after []*store.SnapshotShift) (*ScheduleChanges, map[int64]*employeeDelta) {
+	changes := &ScheduleChanges{
+		Added:               []*store.SnapshotShift{},
+		Removed:             []*store.SnapshotShift{},
+		TimeChanged:         []ShiftChange{},
+		Reassigned:          []ShiftChange{},
+		AffectedEmployeeIDs: []int64{},
+	}
+	deltas := make(map[int64]*employeeDelta)
+
+	delta := func(employeeID int64) *employeeDelta {
+		if deltas[employeeID] == nil {
+			deltas[employeeID] = &employeeDelta{}
+		}
+		return deltas[employeeID]
+	}
+
+	previous := make(map[int64]*store.SnapshotShift, len(before))
+	for _, shift := range before {
+		previous[shift.ID] = shift
+	}
+
+	for _, a := range after {
+		b, ok := previous[a.ID]
+		if !ok {
+			changes.Added = append(changes.Added, a)
+			if a.EmployeeID != nil {
+				delta(*a.EmployeeID).added = append(delta(*a.EmployeeID).added, a)
+			}
+			continue
+		}
+		delete(previous, a.ID)
+
+		change := ShiftChange{ShiftID: a.ID, Before: b, After: a}
+		timeChanged := a.ShiftDate != b.ShiftDate || a.StartTime != b.StartTime || a.EndTime != b.EndTime
+		reassigned := !sameEmployee(a.EmployeeID, b.EmployeeID)
+
+		if timeChanged {
+			changes.TimeChanged = append(changes.TimeChanged, change)
+		}
+		if reassigned {
+			changes.Reassigned = append(changes.Reassigned, change)
+		}
+
+		switch {
+		case reassigned:
+			if b.EmployeeID != nil {
+				delta(*b.EmployeeID).removed = append(delta(*b.EmployeeID).removed, b)
+			}
+			if a.EmployeeID != nil {
+				delta(*a.EmployeeID).added = append(delta(*a.EmployeeID).added, a)
+			}
+		case timeChanged && a.EmployeeID != nil:
+			delta(*a.EmployeeID).changed = append(delta(*a.EmployeeID).changed, change)
+		}
+	}
+
+	for _, b := range before {
+		if _, ok := previous[b.ID]; !ok {
+			continue
+		}
+		changes.Removed = append(changes.Removed, b)
+		if b.EmployeeID != nil {
+			delta(*b.EmployeeID).removed = append(delta(*b.EmployeeID).removed, b)
+		}
+	}
+
+	for employeeID := range deltas {
+		changes.AffectedEmployeeIDs = append(changes.AffectedEmployeeIDs, employeeID)
+	}
+	sort.Slice(changes.AffectedEmployeeIDs, func(i, j int) bool {
+		return changes.AffectedEmployeeIDs[i] < changes.AffectedEmployeeIDs[j]
+	})
+
+	return changes, deltas
+}
+
+func sameEmployee(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func buildScheduleChangesEmailData(employee *store.Employee, delta *employeeDelta, restaurantName string, schedule *store.Schedule, locale i18n.Locale) *ScheduleChangesEmailData {
+	data := &ScheduleChangesEmailData{
+		RestaurantName: mailer.PlainText(restaurantName),
+		EmployeeName:   mailer.PlainText(employee.FullName),
+		ScheduleStart:  i18n.FormatDay(locale, string(schedule.StartDate)),
+		ScheduleEnd:    i18n.FormatDay(locale, string(schedule.EndDate)),
+	}
+
+	if delta == nil {
+		return data
+	}
+
+	for _, shift := range delta.added {
+		data.Added = append(data.Added, snapshotShiftForEmail(shift, locale))
+	}
+	for _, shift := range delta.removed {
+		data.Removed = append(data.Removed, snapshotShiftForEmail(shift, locale))
+	}
+	for _, change := range delta.changed {
+		data.Changed = append(data.Changed, ScheduleEmailShiftChange{
+			Before: snapshotShiftForEmail(change.Before, locale),
+			After:  snapshotShiftForEmail(change.After, locale),
+		})
+	}
+
+	return data
+}
+
+func snapshotShiftForEmail(shift *store.SnapshotShift, locale i18n.Locale) ScheduleEmailShift {
+	date := string(shift.ShiftDate)
+	if t, err := shift.ShiftDate.ToTime(); err == nil {
+		date = i18n.FormatWeekday(locale, t)
+	}
+
+	emailShift := ScheduleEmailShift{
+		Date:      date,
+		StartTime: i18n.FormatTimeOfDay(locale, string(shift.StartTime)),
+		EndTime:   i18n.FormatTimeOfDay(locale, string(shift.EndTime)),
+		RoleName:  mailer.PlainText(shift.RoleName),
+		RoleColor: shift.RoleColor,
+		Training:  shift.Training,
+	}
+	if shift.TrainerName != nil {
+		emailShift.TrainerName = mailer.PlainText(*shift.TrainerName)
+	}
+
+	return emailShift
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestDiffShifts(t *testing.T) {
+	alex, sam := int64(1), int64(2)
+
+	shift := func(id int64, employeeID *int64, date, start, end string) *store.SnapshotShift {
+		return &store.SnapshotShift{
+			ID:         id,
+			RoleName:   "Server",
+			EmployeeID: employeeID,
+			ShiftDate:  store.DateOnly(date),
+			StartTime:  store.TimeOfDay(start),
+			EndTime:    store.TimeOfDay(end),
+		}
+	}
+
+	before := []*store.SnapshotShift{
+		shift(10, &alex, "2026-03-02", "09:00:00", "17:00:00"), // unchanged
+		shift(11, &alex, "2026-03-03", "09:00:00", "17:00:00"), // moved later
+		shift(12, &alex, "2026-03-04", "09:00:00", "17:00:00"), // handed to Sam
+		shift(13, &sam, "2026-03-05", "09:00:00", "17:00:00"),  // removed
+	}
+	after := []*store.SnapshotShift{
+		shift(10, &alex, "2026-03-02", "09:00:00", "17:00:00"),
+		shift(11, &alex, "2026-03-03", "12:00:00", "20:00:00"),
+		shift(12, &sam, "2026-03-04", "09:00:00", "17:00:00"),
+		shift(14, nil, "2026-03-06", "09:00:00", "17:00:00"), // added, unassigned
+	}
+
+	changes, deltas := diffShifts(before, after)
+
+	if len(changes.Added) != 1 || changes.Added[0].ID != 14 {
+		t.Errorf("expected shift 14 added, got %+v", changes.Added)
+	}
+	if len(changes.Removed) != 1 || changes.Removed[0].ID != 13 {
+		t.Errorf("expected shift 13 removed, got %+v", changes.Removed)
+	}
+	if len(changes.TimeChanged) != 1 || changes.TimeChanged[0].ShiftID != 11 {
+		t.Errorf("expected shift 11 time changed, got %+v", changes.TimeChanged)
+	}
+	if len(changes.Reassigned) != 1 || changes.Reassigned[0].ShiftID != 12 {
+		t.Errorf("expected shift 12 reassigned, got %+v", changes.Reassigned)
+	}
+
+	if len(changes.AffectedEmployeeIDs) != 2 {
+		t.Fatalf("expected 2 affected employees, got %v", changes.AffectedEmployeeIDs)
+	}
+
+	if d := deltas[alex]; len(d.changed) != 1 || len(d.removed) != 1 || len(d.added) != 0 {
+		t.Errorf("expected Alex to see one changed and one removed shift, got %+v", d)
+	}
+	if d := deltas[sam]; len(d.added) != 1 || len(d.removed) != 1 || len(d.changed) != 0 {
+		t.Errorf("expected Sam to see one added and one removed shift, got %+v", d)
+	}
+
+	t.Run("should render only the employee's changes", func(t *testing.T) {
+		employee := &store.Employee{ID: alex, FullName: "Alex Smith"}
+		schedule := &store.Schedule{StartDate: "2026-03-02", EndDate: "2026-03-08"}
+
+		data := buildScheduleChangesEmailData(employee, deltas[alex], "Bob's Diner", schedule, i18n.Default)
+
+		_, body, err := mailer.Render(mailer.ScheduleChangesTemplate, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(body, "Changed Shifts") || !strings.Contains(body, "Removed Shifts") {
+			t.Error("expected changed and removed sections")
+		}
+		if strings.Contains(body, "New Shifts") {
+			t.Error("expected no new shifts section")
+		}
+	})
+}
+
+func TestPublishedScheduleVersion(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			if id == 6 {
+				return &store.Schedule{ID: id, RestaurantID: 3}, nil
+			}
+			return &store.Schedule{ID: id, RestaurantID: 3, PublishedAt: &published}, nil
+		},
+	}
+	var asked *time.Time
+	var usedLatest bool
+	alex := int64(1)
+	app.store.ScheduleSnapshots = &store.MockScheduleSnapshotStorer{
+		LatestPublishedFunc: func(_ context.Context, scheduleID int64, at *time.Time) (*store.ScheduleSnapshot, error) {
+			asked = at
+			if at != nil && at.Before(published) {
+				return nil, store.ErrNotFound
+			}
+			return &store.ScheduleSnapshot{ID: 9, ScheduleID: scheduleID, Reason: store.SnapshotPublished, Version: 2, Shifts: []*store.SnapshotShift{
+				{ID: 10, EmployeeID: &alex, ShiftDate: "2026-03-02", StartTime: "09:00:00", EndTime: "17:00:00"},
+			}}, nil
+		},
+		LatestFunc: func(_ context.Context, scheduleID int64, _ *time.Time) (*store.ScheduleSnapshot, error) {
+			usedLatest = true
+			return &store.ScheduleSnapshot{ScheduleID: scheduleID}, nil
+		},
+		CurrentShiftsFunc: func(_ context.Context, _ int64) ([]*store.SnapshotShift, error) {
+			return []*store.SnapshotShift{
+				{ID: 10, EmployeeID: &alex, ShiftDate: "2026-03-02", StartTime: "10:00:00", EndTime: "17:00:00"},
+			}, nil
+		},
+	}
+
+	t.Run("returns the version employees were published", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/published-version", ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var body struct {
+			Data store.ScheduleSnapshot `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.Version != 2 || len(body.Data.Shifts) != 1 || asked != nil {
+			t.Errorf("snapshot = %+v, asked at %v", body.Data, asked)
+		}
+	})
+
+	t.Run("looks back to a time", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/published-version?at=2026-02-01T00:00:00Z", ""), app.mount())
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+		if asked == nil || !asked.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("asked at %v", asked)
+		}
+	})
+
+	t.Run("an unpublished schedule has no version", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/6/published-version", ""), app.mount())
+		if rr.Code == http.StatusOK {
+			t.Errorf("code = %d, want an error", rr.Code)
+		}
+	})
+
+	t.Run("changes can be diffed against the published version", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/changes?baseline=published", ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if usedLatest {
+			t.Error("diffed against the latest snapshot")
+		}
+		if !strings.Contains(rr.Body.String(), `"time_changed":[{`) {
+			t.Errorf("body = %s, want the moved shift", rr.Body.String())
+		}
+
+		rr = executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/changes?baseline=oldest", ""), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/integrations/weather"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// ScheduleCoverage is how each day of a schedule is staffed, with the weather forecast
+// for days the provider covers, so patio staffing can follow the weather
+type ScheduleCoverage struct {
+	ScheduleID int64                 `json:"schedule_id"`
+	Days       []ScheduleCoverageDay `json:"days"`
+}
+
+// ScheduleCoverageDay is one date of a schedule. Weather is absent when the integration
+// is off, the restaurant has no location, or the date is beyond the forecast.
+type ScheduleCoverageDay struct {
+	Date    store.DateOnly `json:"date"`
+	Shifts  int            `json:"shifts"`
+	Staffed int            `json:"staffed"`
+	Open    int            `json:"open"`
+	Hours   float64        `json:"hours"`
+	Weather *weather.Day   `json:"weather,omitempty"`
+	// Holiday names the public holiday on the date, from the restaurant's special dates
+	Holiday string `json:"holiday,omitempty"`
+}
+
+// GetScheduleCoverage godoc
+//
+//	@Summary		Daily staffing of a schedule, with the weather
+//	@Description	Counts each day's shifts, how many are assigned and open, and the scheduled hours. When the weather integration is configured and the restaurant has a latitude and longitude, days within the forecast carry the weather and a patio_weather hint for mild, dry, calm days. A forecast that can't be fetched is left out rather than failing the report. Public holidays among the restaurant's special dates are named in holiday.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{object}	ScheduleCoverage
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/coverage [get]
+func (app *application) getScheduleCoverageHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	exceptions, err := app.store.OperatingHours.ListExceptions(ctx, schedule.RestaurantID, schedule.StartDate, schedule.EndDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	coverage := scheduleCoverage(schedule, shifts)
+	annotateWeather(coverage, app.scheduleForecast(ctx, getRestaurantFromContext(r), schedule))
+	annotateHolidays(coverage, holidayNames(exceptions))
+
+	if err := app.jsonResponse(w, r, http.StatusOK, coverage); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// scheduleCoverage counts the shifts, one day entry per date of the schedule
+func scheduleCoverage(schedule *store.Schedule, shifts []*store.ScheduledShift) *ScheduleCoverage {
+	coverage := &ScheduleCoverage{ScheduleID: schedule.ID, Days: []ScheduleCoverageDay{}}
+
+	index := make(map[store.DateOnly]int)
+	start, errStart := schedule.StartDate.ToTime()
+	end, errEnd := schedule.EndDate.ToTime()
+	if errStart == nil && errEnd == nil {
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			index[dateOnly(d)] = len(coverage.Days)
+			coverage.Days = append(coverage.Days, ScheduleCoverageDay{Date: dateOnly(d)})
+		}
+	}
+
+	for _, shift := range shifts {
+		date := dateOnly(shift.ShiftDate)
+		i, ok := index[date]
+		if !ok {
+			i = len(coverage.Days)
+			index[date] = i
+			coverage.Days = append(coverage.Days, ScheduleCoverageDay{Date: date})
+		}
+		day := &coverage.Days[i]
+
+		day.Shifts++
+		if shift.EmployeeID != nil {
+			day.Staffed++
+		} else {
+			day.Open++
+		}
+		day.Hours += float64(shiftMinutes(shift)) / 60
+	}
+
+	for i := range coverage.Days {
+		coverage.Days[i].Hours = math.Round(coverage.Days[i].Hours*100) / 100
+	}
+
+	return coverage
+}
+
+// scheduleForecast fetches the weather for the schedule's dates at the restaurant, or
+// nothing when it can't
+func (app *application) scheduleForecast(ctx context.Context, restaurant *store.Restaurant, schedule *store.Schedule) []weather.Day {
+	if app.weather == nil || restaurant.Latitude == nil || restaurant.Longitude == nil {
+		return nil
+	}
+
+	start, err := schedule.StartDate.ToTime()
+	if err != nil {
+		return nil
+	}
+	end, err := schedule.EndDate.ToTime()
+	if err != nil {
+		return nil
+	}
+
+	// a slow provider shouldn't hold up the staffing numbers
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	days, err := app.weather.Forecast(ctx, *restaurant.Latitude, *restaurant.Longitude, start, end)
+	if err != nil {
+		app.logger.Warnw("weather forecast failed", "restaurant_id", restaurant.ID, "error", err)
+		return nil
+	}
+	return days
+}
+
+func annotateWeather(coverage *ScheduleCoverage, forecast []weather.Day) {
+	byDate := make(map[string]weather.Day, len(forecast))
+	for _, d := range forecast {
+		byDate[d.Date] = d
+	}
+
+	for i := range coverage.Days {
+		if d, ok := byDate[string(coverage.Days[i].Date)]; ok {
+			coverage.Days[i].Weather = &d
+		}
+	}
+}
+
+func annotateHolidays(coverage *ScheduleCoverage, holidays map[store.DateOnly]string) {
+	for i := range coverage.Days {
+		coverage.Days[i].Holiday = holidays[coverage.Days[i].Date]
+	}
+}
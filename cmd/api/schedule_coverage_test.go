@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/integrations/weather"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type fakeForecaster struct {
+	days []weather.Day
+	err  error
+}
+
+func (f fakeForecaster) Forecast(context.Context, float64, float64, time.Time, time.Time) ([]weather.Day, error) {
+	return f.days, f.err
+}
+
+func TestScheduleCoverage(t *testing.T) {
+	latitude, longitude := 40.7128, -74.006
+	setup := func(t *testing.T, located bool) *application {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			restaurant := &store.Restaurant{ID: id, UserID: testUserID}
+			if located {
+				restaurant.Latitude, restaurant.Longitude = &latitude, &longitude
+			}
+			return restaurant, nil
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-06-01", EndDate: "2026-06-03"}, nil
+			},
+		}
+		employeeID := int64(1)
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{
+					{ID: 1, EmployeeID: &employeeID, ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "11:00", EndTime: "15:00"},
+					{ID: 2, ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "22:30"},
+				}, nil
+			},
+		}
+		app.store.OperatingHours = &store.MockOperatingHoursStorer{
+			ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+				return []*store.HoursException{
+					{Date: "2026-06-02", Name: "Staff party", Closed: true},
+					{Date: "2026-06-03", Name: "Whit Monday", HolidayRegion: "DE"},
+				}, nil
+			},
+		}
+		return app
+	}
+	coverage := func(t *testing.T, app *application) ScheduleCoverage {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/coverage", ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+
+		var body struct {
+			Data ScheduleCoverage `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Data
+	}
+
+	t.Run("counts each day's shifts", func(t *testing.T) {
+		got := coverage(t, setup(t, true))
+		if len(got.Days) != 3 {
+			t.Fatalf("days = %+v, want every date of the schedule", got.Days)
+		}
+		if d := got.Days[0]; d.Shifts != 2 || d.Staffed != 1 || d.Open != 1 || d.Hours != 9.5 || d.Weather != nil {
+			t.Errorf("first day = %+v", d)
+		}
+	})
+
+	t.Run("names public holidays", func(t *testing.T) {
+		got := coverage(t, setup(t, false))
+		if got.Days[1].Holiday != "" || got.Days[2].Holiday != "Whit Monday" {
+			t.Errorf("days = %+v, want the holiday on the third day only", got.Days)
+		}
+	})
+
+	t.Run("adds the forecast for days it covers", func(t *testing.T) {
+		app := setup(t, true)
+		app.weather = fakeForecaster{days: []weather.Day{{Date: "2026-06-02", Summary: "Clear", HighC: 24, PatioWeather: true}}}
+
+		got := coverage(t, app)
+		if got.Days[0].Weather != nil || got.Days[1].Weather == nil || !got.Days[1].Weather.PatioWeather {
+			t.Errorf("days = %+v, want weather on the second day only", got.Days)
+		}
+	})
+
+	t.Run("leaves the weather out without a location", func(t *testing.T) {
+		app := setup(t, false)
+		app.weather = fakeForecaster{days: []weather.Day{{Date: "2026-06-02", Summary: "Clear"}}}
+
+		if got := coverage(t, app); got.Days[1].Weather != nil {
+			t.Errorf("weather = %+v, want none", got.Days[1].Weather)
+		}
+	})
+
+	t.Run("a failed forecast doesn't fail the report", func(t *testing.T) {
+		app := setup(t, true)
+		app.weather = fakeForecaster{err: errors.New("provider down")}
+
+		if got := coverage(t, app); got.Days[1].Weather != nil {
+			t.Errorf("weather = %+v, want none", got.Days[1].Weather)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// overrideLockParam lets the owner change a locked shift anyway
+const overrideLockParam = "override_lock"
+
+// checkShiftLock answers 423 Locked when one of the shifts is inside the
+// restaurant's schedule lock, unless the owner overrides it; shift leads who
+// try to are answered 403. It returns false once it has written the response.
+func (app *application) checkShiftLock(w http.ResponseWriter, r *http.Request, shifts ...*store.ScheduledShift) bool {
+	restaurant := getRestaurantFromContext(r)
+
+	locked, err := app.lockedShift(r.Context(), restaurant, shifts...)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return false
+	}
+	if locked == nil {
+		return true
+	}
+
+	if r.URL.Query().Get(overrideLockParam) == "true" {
+		if access := getAccessFromContext(r); access == nil || !access.owner {
+			app.forbiddenResponse(w, r, fmt.Errorf("%w; only the owner can override the schedule lock", shiftLockError(restaurant, locked)))
+			return false
+		}
+		app.logger.Infow("schedule lock overridden",
+			"restaurant_id", restaurant.ID,
+			"shift_id", locked.ID,
+			"user_id", getUserFromContext(r).ID,
+		)
+		return true
+	}
+
+	app.lockedResponse(w, r, fmt.Errorf("%w; resend with %s=true to change it anyway", shiftLockError(restaurant, locked), overrideLockParam))
+	return false
+}
+
+// lockedShift returns the first shift that is on a published schedule and
+// starts within the restaurant's lock window, so last-minute changes don't
+// slip past employees who already read the schedule. Drafts are never locked.
+func (app *application) lockedShift(ctx context.Context, restaurant *store.Restaurant, shifts ...*store.ScheduledShift) (*store.ScheduledShift, error) {
+	if restaurant.ScheduleLockHours <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	for _, shift := range shifts {
+		if !restaurant.ShiftLocked(shiftStart(restaurant, shift), now) {
+			continue
+		}
+
+		schedule, err := app.store.Schedules.GetByID(ctx, shift.ScheduleID)
+		if err != nil {
+			return nil, err
+		}
+		if schedule.PublishedAt != nil {
+			return shift, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func shiftLockError(restaurant *store.Restaurant, shift *store.ScheduledShift) error {
+	return fmt.Errorf("the shift on %s at %s starts within the %d-hour schedule lock",
+		shift.ShiftDate.Format("2006-01-02"), hourMinute(shift.StartTime), restaurant.ScheduleLockHours)
+}
+
+// shiftStart is when the shift begins in the restaurant's time zone
+func shiftStart(restaurant *store.Restaurant, shift *store.ScheduledShift) time.Time {
+	clock, err := time.Parse("15:04:05", string(shift.StartTime))
+	if err != nil {
+		clock, _ = time.Parse("15:04", string(shift.StartTime))
+	}
+
+	d := shift.ShiftDate
+	return time.Date(d.Year(), d.Month(), d.Day(), clock.Hour(), clock.Minute(), 0, 0, restaurant.Location())
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestRestaurantShiftLocked(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+	restaurant := &store.Restaurant{ScheduleLockHours: 24}
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		locked bool
+	}{
+		{"well ahead", now.Add(48 * time.Hour), false},
+		{"just outside", now.Add(24*time.Hour + time.Minute), false},
+		{"at the window", now.Add(24 * time.Hour), true},
+		{"inside", now.Add(2 * time.Hour), true},
+		{"already started", now.Add(-time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := restaurant.ShiftLocked(tt.start, now); got != tt.locked {
+				t.Errorf("ShiftLocked = %v, want %v", got, tt.locked)
+			}
+		})
+	}
+
+	off := &store.Restaurant{}
+	if off.ShiftLocked(now, now) {
+		t.Error("a restaurant without a lock window locked a shift")
+	}
+}
+
+func TestShiftChangesRespectScheduleLock(t *testing.T) {
+	soon := time.Now().UTC().Add(2 * time.Hour)
+	shift := &store.ScheduledShift{
+		ID:           42,
+		ScheduleID:   5,
+		RestaurantID: 3,
+		ShiftDate:    time.Date(soon.Year(), soon.Month(), soon.Day(), 0, 0, 0, 0, time.UTC),
+		StartTime:    store.TimeOfDay(soon.Format("15:04") + ":00"),
+		EndTime:      "23:59:00",
+	}
+
+	setup := func(t *testing.T, published bool) (*application, *bool) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.restaurants.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID, ScheduleLockHours: 24}, nil
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				schedule := &store.Schedule{ID: id, RestaurantID: 3}
+				if published {
+					publishedAt := time.Now().Add(-24 * time.Hour)
+					schedule.PublishedAt = &publishedAt
+				}
+				return schedule, nil
+			},
+		}
+
+		changed := false
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			GetByIDFunc: func(context.Context, int64) (*store.ScheduledShift, error) {
+				copied := *shift
+				return &copied, nil
+			},
+			AssignEmployeeFunc: func(context.Context, int64, store.ShiftAssignment) error {
+				changed = true
+				return nil
+			},
+			DeleteFunc: func(context.Context, int64) error {
+				changed = true
+				return nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		return app, &changed
+	}
+
+	t.Run("published shift inside the window is locked", func(t *testing.T) {
+		app, changed := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42/assign", ""), app.mount())
+
+		checkResponseCode(t, http.StatusLocked, rr.Code)
+		if *changed {
+			t.Error("locked shift was changed")
+		}
+	})
+
+	t.Run("deleting a published shift inside the window is locked", func(t *testing.T) {
+		app, changed := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42", ""), app.mount())
+
+		checkResponseCode(t, http.StatusLocked, rr.Code)
+		if *changed {
+			t.Error("locked shift was deleted")
+		}
+	})
+
+	t.Run("owner overrides the lock to delete", func(t *testing.T) {
+		app, changed := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42?override_lock=true", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if !*changed {
+			t.Error("override did not delete the shift")
+		}
+	})
+
+	t.Run("owner overrides the lock", func(t *testing.T) {
+		app, changed := setup(t, true)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42/assign?override_lock=true", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !*changed {
+			t.Error("override did not change the shift")
+		}
+	})
+
+	t.Run("a shift lead can't override the lock", func(t *testing.T) {
+		app, changed := setup(t, true)
+		mocks := app.store.Restaurants.(*store.MockRestaurantStorer)
+		mocks.GetByIDFunc = func(_ context.Context, id int64) (*store.Restaurant, error) {
+			return &store.Restaurant{ID: id, UserID: testUserID + 1, ScheduleLockHours: 24}, nil
+		}
+		app.store.Members = &store.MockMemberStorer{
+			ListByRestaurantFunc: func(_ context.Context, restaurantID int64) ([]*store.Member, error) {
+				return []*store.Member{{RestaurantID: restaurantID, UserID: testUserID, Permission: store.PermissionShiftLead, RoleIDs: []int64{shift.RoleID}}}, nil
+			},
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42/assign?override_lock=true", ""), app.mount())
+
+		checkResponseCode(t, http.StatusForbidden, rr.Code)
+		if *changed || !strings.Contains(rr.Body.String(), "only the owner") {
+			t.Errorf("shift lead overrode the lock: %s", rr.Body)
+		}
+	})
+
+	t.Run("drafts are never locked", func(t *testing.T) {
+		app, changed := setup(t, false)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/shifts/42/assign", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !*changed {
+			t.Error("draft shift was not changed")
+		}
+	})
+}
+
+func TestShiftStartInRestaurantTimezone(t *testing.T) {
+	shift := &store.ScheduledShift{ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "09:00:00"}
+
+	tests := []struct {
+		timezone string
+		want     time.Time
+	}{
+		{"America/Chicago", time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)},
+		{"Asia/Tokyo", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		// a name that no longer loads falls back to UTC rather than the server's zone
+		{"Mars/Olympus", time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			if got := shiftStart(&store.Restaurant{Timezone: tt.timezone}, shift); !got.Equal(tt.want) {
+				t.Errorf("shiftStart = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestaurantWeeks(t *testing.T) {
+	// Monday 2026-03-02 01:00 UTC is still Sunday evening in Chicago
+	now := time.Date(2026, 3, 2, 1, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		restaurant *store.Restaurant
+		today      time.Time
+		start      time.Time
+	}{
+		{"Monday weeks in UTC", &store.Restaurant{WeekStart: int(time.Monday)}, day(2), day(2)},
+		{"Sunday weeks in UTC", &store.Restaurant{WeekStart: int(time.Sunday)}, day(2), day(1)},
+		{"Monday weeks in Chicago", &store.Restaurant{WeekStart: int(time.Monday), Timezone: "America/Chicago"}, day(1), time.Date(2026, 2, 23, 0, 0, 0, 0, time.UTC)},
+		{"Saturday weeks in Chicago", &store.Restaurant{WeekStart: int(time.Saturday), Timezone: "America/Chicago"}, day(1), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today := tt.restaurant.Today(now)
+			if !today.Equal(tt.today) {
+				t.Fatalf("Today = %v, want %v", today, tt.today)
+			}
+			start, end := tt.restaurant.WeekOf(today)
+			if !start.Equal(tt.start) || !end.Equal(tt.start.AddDate(0, 0, 6)) {
+				t.Errorf("WeekOf = %v to %v, want the week from %v", start, end, tt.start)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+type ScheduleDayNotePayload struct {
+	Note string `json:"note" validate:"required,max=500"`
+}
+
+// GetScheduleDayNotes godoc
+//
+//	@Summary		Lists a schedule's day notes
+//	@Description	Lists the notes on the days of the schedule, in date order. The note for the whole week is the schedule's own note field.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{array}		store.ScheduleDayNote
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/day-notes [get]
+func (app *application) getScheduleDayNotesHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	restaurant := getRestaurantFromContext(r)
+	if err := app.checkScheduleAccess(r.Context(), restaurant.ID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("restaurant not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := app.store.ScheduleNotes.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, dayNotesInSchedule(notes, schedule)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SetScheduleDayNote godoc
+//
+//	@Summary		Sets the note on a schedule day
+//	@Description	Creates or replaces the note on one day of the schedule, e.g. "Health inspection". The date has to fall within the schedule's week.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			scheduleID		path		int						true	"Schedule ID"
+//	@Param			date			path		string					true	"Day (YYYY-MM-DD)"
+//	@Param			payload			body		ScheduleDayNotePayload	true	"Note"
+//	@Success		200				{object}	store.ScheduleDayNote
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/day-notes/{date} [put]
+func (app *application) setScheduleDayNoteHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	date, err := scheduleDayFromURL(r, schedule)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload ScheduleDayNotePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	note := &store.ScheduleDayNote{ScheduleID: schedule.ID, Date: date, Note: payload.Note}
+	if err := app.store.ScheduleNotes.Upsert(r.Context(), note); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, note); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteScheduleDayNote godoc
+//
+//	@Summary		Removes the note on a schedule day
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path	int		true	"Restaurant ID"
+//	@Param			scheduleID		path	int		true	"Schedule ID"
+//	@Param			date			path	string	true	"Day (YYYY-MM-DD)"
+//	@Success		204				"No Content"
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/day-notes/{date} [delete]
+func (app *application) deleteScheduleDayNoteHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid date format, use YYYY-MM-DD"))
+		return
+	}
+
+	if err := app.store.ScheduleNotes.Delete(r.Context(), schedule.ID, dateOnly(date)); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scheduleDayFromURL reads the date URL parameter, which has to be a day of the schedule
+func scheduleDayFromURL(r *http.Request, schedule *store.Schedule) (store.DateOnly, error) {
+	date, err := time.Parse("2006-01-02", chi.URLParam(r, "date"))
+	if err != nil {
+		return "", errors.New("invalid date format, use YYYY-MM-DD")
+	}
+
+	day := dateOnly(date)
+	if day < schedule.StartDate || day > schedule.EndDate {
+		return "", errors.New("date is outside the schedule's week")
+	}
+
+	return day, nil
+}
+
+// dayNotesInSchedule keeps the notes on days the schedule still covers; a
+// schedule whose dates were changed can have notes left outside them
+func dayNotesInSchedule(notes []*store.ScheduleDayNote, schedule *store.Schedule) []*store.ScheduleDayNote {
+	inSchedule := make([]*store.ScheduleDayNote, 0, len(notes))
+	for _, note := range notes {
+		if note.Date >= schedule.StartDate && note.Date <= schedule.EndDate {
+			inSchedule = append(inSchedule, note)
+		}
+	}
+	return inSchedule
+}
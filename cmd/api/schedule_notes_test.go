@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestScheduleDayNotes(t *testing.T) {
+	setup := func(t *testing.T) (*application, *[]*store.ScheduleDayNote) {
+		app, _ := newMockedApplication(t, testUserID)
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-03-02", EndDate: "2026-03-08"}, nil
+			},
+		}
+		saved := &[]*store.ScheduleDayNote{}
+		app.store.ScheduleNotes = &store.MockScheduleNoteStorer{
+			UpsertFunc: func(_ context.Context, note *store.ScheduleDayNote) error {
+				*saved = append(*saved, note)
+				return nil
+			},
+			ListByScheduleFunc: func(_ context.Context, scheduleID int64) ([]*store.ScheduleDayNote, error) {
+				return []*store.ScheduleDayNote{
+					{ScheduleID: scheduleID, Date: "2026-02-28", Note: "left behind by a date change"},
+					{ScheduleID: scheduleID, Date: "2026-03-03", Note: "Health inspection"},
+				}, nil
+			},
+		}
+		return app, saved
+	}
+
+	t.Run("sets a note on a day of the week", func(t *testing.T) {
+		app, saved := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPut, "/v1/restaurants/3/schedules/5/day-notes/2026-03-03", `{"note":"Health inspection"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*saved) != 1 || (*saved)[0].ScheduleID != 5 || (*saved)[0].Date != "2026-03-03" {
+			t.Errorf("saved = %+v", *saved)
+		}
+	})
+
+	t.Run("rejects days outside the week", func(t *testing.T) {
+		app, saved := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPut, "/v1/restaurants/3/schedules/5/day-notes/2026-03-09", `{"note":"Inventory"}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if len(*saved) != 0 {
+			t.Error("note was saved")
+		}
+	})
+
+	t.Run("rejects an empty note", func(t *testing.T) {
+		app, _ := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPut, "/v1/restaurants/3/schedules/5/day-notes/2026-03-03", `{"note":""}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("lists only the week's notes", func(t *testing.T) {
+		app, _ := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/day-notes", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data []store.ScheduleDayNote `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != 1 || body.Data[0].Note != "Health inspection" {
+			t.Errorf("notes = %+v, want only the Tuesday note", body.Data)
+		}
+	})
+}
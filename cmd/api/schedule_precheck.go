@@ -0,0 +1,309 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	// preCheckUnfillable is a day and role no one holding the role can work
+	preCheckUnfillable = "unfillable"
+	// preCheckShort is a day and role with fewer people free than shifts to fill
+	preCheckShort = "short"
+	// preCheckReassign is a day and role that can be filled once shifts
+	// assigned to employees on leave are handed to someone else
+	preCheckReassign = "reassign"
+)
+
+// schedulePreCheck is the staffing report on a schedule before it's populated or published
+type schedulePreCheck struct {
+	ScheduleID int64 `json:"schedule_id"`
+	// OpenShifts are the schedule's shifts with no one assigned
+	OpenShifts int `json:"open_shifts"`
+	// TemplateShifts are the shifts auto-populate would still add from the templates
+	TemplateShifts int `json:"template_shifts"`
+	// LeaveConflicts are shifts assigned to an employee taking paid leave that day
+	LeaveConflicts int             `json:"leave_conflicts"`
+	AtRiskCount    int             `json:"at_risk_count"`
+	Risks          []*preCheckRisk `json:"risks"`
+}
+
+// preCheckRisk is one day and role that may go unstaffed
+type preCheckRisk struct {
+	Date     string `json:"date"`
+	RoleID   int64  `json:"role_id"`
+	RoleName string `json:"role_name"`
+	Severity string `json:"severity"`
+	// Needed counts the open, template and leave-conflicted shifts to staff
+	Needed   int `json:"needed"`
+	Unfilled int `json:"unfilled"`
+	// RoleHolders is everyone holding the role, before leave and certifications
+	RoleHolders          int     `json:"role_holders"`
+	OnLeaveEmployeeIDs   []int64 `json:"on_leave_employee_ids"`
+	MissingCertification int     `json:"missing_certification"`
+	// ShiftIDs are the open shifts already on the schedule
+	ShiftIDs              []int64 `json:"shift_ids"`
+	TemplateShifts        int     `json:"template_shifts"`
+	LeaveConflictShiftIDs []int64 `json:"leave_conflict_shift_ids"`
+}
+
+// planPreCheck staffs the schedule on paper and reports the days and roles it
+// can't cover. Open shifts, the template shifts auto-populate would add and
+// shifts whose employee is on paid leave are filled by rotating fairly among
+// the candidates who hold the role, aren't on leave that day, aren't already
+// working at that time and hold the certifications, as auto-assign would.
+// onLeave is keyed by employee, then date.
+func planPreCheck(
+	shifts, templateShifts []*store.ScheduledShift,
+	candidates []*autoAssignCandidate,
+	onLeave map[int64]map[string]bool,
+	certified func(employeeID int64, shift *store.ScheduledShift) (bool, error),
+) (*schedulePreCheck, error) {
+	report := &schedulePreCheck{Risks: []*preCheckRisk{}}
+
+	type dayRole struct {
+		date   string
+		roleID int64
+	}
+	risks := make(map[dayRole]*preCheckRisk)
+	dates := make(map[dayRole]time.Time)
+	riskFor := func(shift *store.ScheduledShift) *preCheckRisk {
+		key := dayRole{shift.ShiftDate.Format("2006-01-02"), shift.RoleID}
+		risk, ok := risks[key]
+		if !ok {
+			risk = &preCheckRisk{Date: key.date, RoleID: key.roleID, OnLeaveEmployeeIDs: []int64{}, ShiftIDs: []int64{}, LeaveConflictShiftIDs: []int64{}}
+			risks[key] = risk
+			dates[key] = shift.ShiftDate
+		}
+		risk.Needed++
+		return risk
+	}
+
+	var booked, open []*store.ScheduledShift
+	for _, shift := range shifts {
+		switch {
+		case shift.EmployeeID == nil:
+			report.OpenShifts++
+			risk := riskFor(shift)
+			risk.ShiftIDs = append(risk.ShiftIDs, shift.ID)
+			open = append(open, shift)
+		case onLeave[*shift.EmployeeID][shift.ShiftDate.Format("2006-01-02")]:
+			report.LeaveConflicts++
+			risk := riskFor(shift)
+			risk.LeaveConflictShiftIDs = append(risk.LeaveConflictShiftIDs, shift.ID)
+			unassigned := *shift
+			unassigned.EmployeeID = nil
+			open = append(open, &unassigned)
+		default:
+			booked = append(booked, shift)
+		}
+	}
+
+	// Template shifts have no IDs yet; number them below zero to tell them apart
+	for i, shift := range templateShifts {
+		report.TemplateShifts++
+		riskFor(shift).TemplateShifts++
+		numbered := *shift
+		numbered.ID = -int64(i + 1)
+		open = append(open, &numbered)
+	}
+
+	eligible := func(employeeID int64, shift *store.ScheduledShift, _ []*store.ScheduledShift) (bool, error) {
+		if onLeave[employeeID][shift.ShiftDate.Format("2006-01-02")] {
+			return false, nil
+		}
+		return certified(employeeID, shift)
+	}
+
+	plan, err := planAutoAssign(store.AssignmentRotateFairly, booked, open, candidates, eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*store.ScheduledShift, len(open))
+	for _, shift := range open {
+		byID[shift.ID] = shift
+	}
+	for _, id := range plan.Unfilled {
+		shift := byID[id]
+		risks[dayRole{shift.ShiftDate.Format("2006-01-02"), shift.RoleID}].Unfilled++
+	}
+
+	for key, risk := range risks {
+		if risk.Unfilled == 0 && len(risk.LeaveConflictShiftIDs) == 0 {
+			continue
+		}
+
+		sample := &store.ScheduledShift{RoleID: key.roleID, ShiftDate: dates[key]}
+		available := 0
+		for _, c := range candidates {
+			if !c.RoleIDs[key.roleID] {
+				continue
+			}
+			risk.RoleHolders++
+			if onLeave[c.Employee.ID][key.date] {
+				risk.OnLeaveEmployeeIDs = append(risk.OnLeaveEmployeeIDs, c.Employee.ID)
+				continue
+			}
+			ok, err := certified(c.Employee.ID, sample)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				risk.MissingCertification++
+				continue
+			}
+			available++
+		}
+
+		switch {
+		case available == 0:
+			risk.Severity = preCheckUnfillable
+		case risk.Unfilled > 0:
+			risk.Severity = preCheckShort
+		default:
+			risk.Severity = preCheckReassign
+		}
+		report.Risks = append(report.Risks, risk)
+	}
+
+	sort.Slice(report.Risks, func(i, j int) bool {
+		a, b := report.Risks[i], report.Risks[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.RoleID < b.RoleID
+	})
+	report.AtRiskCount = len(report.Risks)
+
+	return report, nil
+}
+
+// SchedulePreCheck godoc
+//
+//	@Summary		Checks a schedule can be staffed
+//	@Description	Before populating or publishing, cross-references the schedule's open shifts, and the template shifts auto-populate would add, against who holds each role, who is taking paid leave that day, who lacks a required certification and who is already working at that time. Answers with the dates and roles at risk: unfillable when no one holding the role is free, short when fewer are free than shifts to fill, and reassign when shifts are assigned to employees on leave but others can take them. Shifts out for bids count as open. Nothing is written.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{object}	schedulePreCheck
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/pre-check [get]
+func (app *application) getSchedulePreCheckHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	startDate, err := parseFlexibleDate(string(schedule.StartDate))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	endDate, err := parseFlexibleDate(string(schedule.EndDate))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	templates, err := app.store.ShiftTemplates.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	hours, err := app.operatingHoursCheck(r.Context(), restaurant.ID, startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	populate := planAutoPopulate(schedule, startDate, endDate, templates, restaurant.RotationAnchor, shifts, hours)
+
+	leave, err := app.store.Leave.ListPaidBetween(r.Context(), restaurant.ID, schedule.StartDate, schedule.EndDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	onLeave := make(map[int64]map[string]bool)
+	for _, entry := range leave {
+		if onLeave[entry.EmployeeID] == nil {
+			onLeave[entry.EmployeeID] = make(map[string]bool)
+		}
+		onLeave[entry.EmployeeID][string(entry.Date)] = true
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	candidates, err := app.autoAssignCandidates(r.Context(), restaurant.ID, employees)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Certifications depend only on the employee, role and day; ask once for each
+	checked := make(map[string]bool)
+	report, err := planPreCheck(shifts, populate.Shifts, candidates, onLeave, func(employeeID int64, shift *store.ScheduledShift) (bool, error) {
+		key := strconv.FormatInt(employeeID, 10) + "-" + strconv.FormatInt(shift.RoleID, 10) + "-" + shift.ShiftDate.Format("2006-01-02")
+		if ok, seen := checked[key]; seen {
+			return ok, nil
+		}
+		missing, err := app.store.Certifications.MissingForAssignment(r.Context(), employeeID, shift.RoleID, shift.ShiftDate)
+		if err != nil {
+			return false, err
+		}
+		checked[key] = len(missing) == 0
+		return checked[key], nil
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	report.ScheduleID = schedule.ID
+
+	if len(report.Risks) > 0 {
+		roles, err := app.store.Roles.ListByRestaurant(r.Context(), restaurant.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		names := make(map[int64]string, len(roles))
+		for _, role := range roles {
+			names[role.ID] = role.Name
+		}
+		for _, risk := range report.Risks {
+			risk.RoleName = names[risk.RoleID]
+		}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestPlanPreCheck(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	employeeID := func(id int64) *int64 { return &id }
+	shift := func(id int64, date time.Time, roleID int64, start, end string, employee *int64) *store.ScheduledShift {
+		return &store.ScheduledShift{ID: id, RoleID: roleID, ShiftDate: date, StartTime: store.TimeOfDay(start), EndTime: store.TimeOfDay(end), EmployeeID: employee}
+	}
+	candidates := []*autoAssignCandidate{
+		{Employee: &store.Employee{ID: 1}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 2}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 3}, RoleIDs: map[int64]bool{2: true}},
+	}
+	certified := func(employeeID int64, _ *store.ScheduledShift) (bool, error) { return employeeID != 3, nil }
+
+	t.Run("a schedule everyone can cover has no risks", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{
+			shift(10, monday, 1, "09:00", "17:00", nil),
+			shift(11, monday, 1, "09:00", "17:00", nil),
+		}
+
+		report, err := planPreCheck(shifts, nil, candidates, nil, certified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.OpenShifts != 2 || report.AtRiskCount != 0 {
+			t.Errorf("report = %+v, want 2 open shifts and no risks", report)
+		}
+	})
+
+	t.Run("leave and overlapping shifts leave a day short", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{
+			shift(10, monday, 1, "09:00", "17:00", employeeID(1)),
+			shift(11, monday, 1, "12:00", "20:00", nil),
+			shift(12, tuesday, 1, "09:00", "17:00", nil),
+		}
+		templates := []*store.ScheduledShift{shift(0, tuesday, 1, "12:00", "20:00", nil)}
+		onLeave := map[int64]map[string]bool{2: {"2026-03-02": true}}
+
+		report, err := planPreCheck(shifts, templates, candidates, onLeave, certified)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// employee 1 works monday already and 2 is on leave; tuesday both are free
+		if report.AtRiskCount != 1 || report.TemplateShifts != 1 {
+			t.Fatalf("report = %+v, want only monday at risk and 1 template shift", report)
+		}
+		risk := report.Risks[0]
+		if risk.Date != "2026-03-02" || risk.Severity != preCheckShort || risk.Unfilled != 1 || risk.RoleHolders != 2 {
+			t.Errorf("risk = %+v, want monday short one of 2 role holders", risk)
+		}
+		if len(risk.OnLeaveEmployeeIDs) != 1 || risk.OnLeaveEmployeeIDs[0] != 2 || len(risk.ShiftIDs) != 1 || risk.ShiftIDs[0] != 11 {
+			t.Errorf("risk = %+v, want employee 2 on leave and shift 11 open", risk)
+		}
+	})
+
+	t.Run("a role no one certified holds is unfillable", func(t *testing.T) {
+		templates := []*store.ScheduledShift{shift(0, monday, 2, "09:00", "17:00", nil)}
+
+		report, err := planPreCheck(nil, templates, candidates, nil, certified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.AtRiskCount != 1 || report.Risks[0].Severity != preCheckUnfillable || report.Risks[0].MissingCertification != 1 {
+			t.Errorf("risks = %+v, want role 2 unfillable for lack of certification", report.Risks)
+		}
+	})
+
+	t.Run("shifts assigned to someone on leave need reassigning", func(t *testing.T) {
+		shifts := []*store.ScheduledShift{shift(10, monday, 1, "09:00", "17:00", employeeID(1))}
+		onLeave := map[int64]map[string]bool{1: {"2026-03-02": true}}
+
+		report, err := planPreCheck(shifts, nil, candidates, onLeave, certified)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.LeaveConflicts != 1 || report.AtRiskCount != 1 {
+			t.Fatalf("report = %+v, want one leave conflict at risk", report)
+		}
+		if risk := report.Risks[0]; risk.Severity != preCheckReassign || risk.Unfilled != 0 || risk.LeaveConflictShiftIDs[0] != 10 {
+			t.Errorf("risk = %+v, want shift 10 to reassign", risk)
+		}
+	})
+}
+
+func TestSchedulePreCheck(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 1, StartDate: "2026-03-02", EndDate: "2026-03-08"}, nil
+		},
+	}
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+			return []*store.ScheduledShift{{ID: 10, ScheduleID: 5, RoleID: 1, ShiftDate: monday, StartTime: "09:00:00", EndTime: "17:00:00"}}, nil
+		},
+	}
+	app.store.ShiftTemplates = &store.MockShiftTemplateStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.ShiftTemplate, error) { return nil, nil },
+	}
+	app.store.OperatingHours = &store.MockOperatingHoursStorer{
+		GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+			return &store.OperatingHours{RestaurantID: restaurantID}, nil
+		},
+		ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+			return nil, nil
+		},
+	}
+	app.store.Leave = &store.MockLeaveStorer{
+		ListPaidBetweenFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.LeaveEntry, error) {
+			return []*store.LeaveEntry{{EmployeeID: 7, Kind: store.LeavePaid, Hours: -8, Date: "2026-03-02"}}, nil
+		},
+	}
+	app.store.Employees = &store.MockEmployeeStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) {
+			return []*store.Employee{{ID: 7, FullName: "Alex Smith"}}, nil
+		},
+		RoleIDsByRestaurantFunc: func(context.Context, int64) (map[int64][]int64, error) {
+			return map[int64][]int64{7: {1}}, nil
+		},
+	}
+	app.store.Certifications = &store.MockCertificationStorer{
+		MissingForAssignmentFunc: func(context.Context, int64, int64, time.Time) ([]string, error) { return nil, nil },
+	}
+	app.store.Roles = &store.MockRoleStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.Role, error) {
+			return []*store.Role{{ID: 1, Name: "Server"}}, nil
+		},
+	}
+
+	req := authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/schedules/5/pre-check", "")
+	rr := executeRequest(req, app.mount())
+
+	checkResponseCode(t, http.StatusOK, rr.Code)
+	var body struct {
+		Data schedulePreCheck `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Data.AtRiskCount != 1 {
+		t.Fatalf("report = %+v, want one risk", body.Data)
+	}
+	if risk := body.Data.Risks[0]; risk.Severity != preCheckUnfillable || risk.RoleName != "Server" || risk.OnLeaveEmployeeIDs[0] != 7 {
+		t.Errorf("risk = %+v, want Server unfillable with employee 7 on leave", risk)
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// defaultShareLinkHours is how long a share link works when no expiry is asked for
+const defaultShareLinkHours = 7 * 24
+
+// A share link lasts at most 90 days (2160 hours) from when its expiry is set
+type CreateShareLinkPayload struct {
+	// ExpiresInHours defaults to a week
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=2160"`
+}
+
+type UpdateShareLinkPayload struct {
+	// ExpiresInHours counts from now, so it can extend an expired link
+	ExpiresInHours int `json:"expires_in_hours" validate:"required,min=1,max=2160"`
+}
+
+// ShareLinkWithToken is a new share link with its token and URLs, which are
+// only ever shown here
+type ShareLinkWithToken struct {
+	*store.ShareLink
+	Token   string `json:"token"`
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url"`
+}
+
+// SharedSchedule is the read-only view of a schedule behind a share link
+type SharedSchedule struct {
+	RestaurantName string                   `json:"restaurant_name"`
+	StartDate      store.DateOnly           `json:"start_date"`
+	EndDate        store.DateOnly           `json:"end_date"`
+	Note           string                   `json:"note"`
+	DayNotes       []*store.ScheduleDayNote `json:"day_notes"`
+	Shifts         []SharedShift            `json:"shifts"`
+	ExpiresAt      time.Time                `json:"expires_at"`
+}
+
+// SharedShift is a shift as a share link shows it, without internal IDs
+type SharedShift struct {
+	Date         store.DateOnly  `json:"date"`
+	StartTime    store.TimeOfDay `json:"start_time"`
+	EndTime      store.TimeOfDay `json:"end_time"`
+	RoleName     string          `json:"role_name"`
+	RoleColor    string          `json:"role_color"`
+	EmployeeName *string         `json:"employee_name,omitempty"`
+	Training     bool            `json:"training"`
+	Notes        string          `json:"notes"`
+}
+
+// CreateShareLink godoc
+//
+//	@Summary		Creates a share link for a schedule
+//	@Description	Creates a link that shows the schedule read-only to anyone holding it, e.g. staff or partners without an account, until it expires (default 7 days, at most 90) or is revoked. The token and URLs are not shown again; url serves JSON and html_url a printable page.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			scheduleID		path		int						true	"Schedule ID"
+//	@Param			payload			body		CreateShareLinkPayload	false	"Expiry"
+//	@Success		201				{object}	ShareLinkWithToken
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/share-link [post]
+func (app *application) createShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	var payload CreateShareLinkPayload
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &payload); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	hours := payload.ExpiresInHours
+	if hours == 0 {
+		hours = defaultShareLinkHours
+	}
+
+	link := &store.ShareLink{
+		ScheduleID:   schedule.ID,
+		RestaurantID: schedule.RestaurantID,
+		ExpiresAt:    time.Now().Add(time.Duration(hours) * time.Hour).UTC(),
+	}
+	token := uuid.New().String()
+	if err := app.store.ShareLinks.Create(r.Context(), link, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	url := fmt.Sprintf("%s/v1/shared/schedules/%s", app.externalBaseURL(), token)
+	response := &ShareLinkWithToken{ShareLink: link, Token: token, URL: url, HTMLURL: url + "?format=html"}
+	if err := app.jsonResponse(w, r, http.StatusCreated, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetShareLinks godoc
+//
+//	@Summary		Lists a schedule's share links
+//	@Description	Lists the schedule's share links, expired and revoked ones included, with when each was last viewed
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{array}		store.ShareLink
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/share-links [get]
+func (app *application) getShareLinksHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	links, err := app.store.ShareLinks.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, links); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// UpdateShareLink godoc
+//
+//	@Summary		Changes when a share link expires
+//	@Description	Sets the link to expire expires_in_hours from now, which can shorten it, extend it or bring back an expired link. Revoked links stay revoked.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			scheduleID		path		int						true	"Schedule ID"
+//	@Param			linkID			path		int						true	"Share link ID"
+//	@Param			payload			body		UpdateShareLinkPayload	true	"Expiry"
+//	@Success		200				{object}	store.ShareLink
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/share-links/{linkID} [patch]
+func (app *application) updateShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "linkID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload UpdateShareLinkPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(payload.ExpiresInHours) * time.Hour).UTC()
+	link, err := app.store.ShareLinks.SetExpiry(r.Context(), schedule.ID, linkID, expiresAt)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, link); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RevokeShareLink godoc
+//
+//	@Summary		Revokes a share link
+//	@Description	Stops the link from working for good
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Param			linkID			path		int	true	"Share link ID"
+//	@Success		204				{object}	string
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/share-links/{linkID} [delete]
+func (app *application) revokeShareLinkHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, err := strconv.ParseInt(chi.URLParam(r, "linkID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.store.ShareLinks.Revoke(r.Context(), schedule.ID, linkID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSharedSchedule godoc
+//
+//	@Summary		Shows a shared schedule
+//	@Description	Shows the schedule behind a share link read-only, with its shifts and notes. It needs no account: the token in the URL is the access, and it stops working once the link expires or is revoked, or the restaurant is archived. format=html serves a printable page instead of JSON.
+//	@Tags			schedule
+//	@Produce		json,html
+//	@Param			token	path		string	true	"Share link token"
+//	@Param			format	query		string	false	"html for a page instead of JSON"
+//	@Success		200		{object}	SharedSchedule
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/shared/schedules/{token} [get]
+func (app *application) getSharedScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	link, err := app.store.ShareLinks.Authenticate(ctx, chi.URLParam(r, "token"), time.Now())
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("share link not found or expired"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	schedule, err := app.store.Schedules.GetByID(ctx, link.ScheduleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	restaurant, err := app.store.Restaurants.GetByID(ctx, link.RestaurantID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	dayNotes, err := app.store.ScheduleNotes.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	shared := &SharedSchedule{
+		RestaurantName: restaurant.Name,
+		StartDate:      schedule.StartDate,
+		EndDate:        schedule.EndDate,
+		Note:           schedule.Note,
+		DayNotes:       dayNotesInSchedule(dayNotes, schedule),
+		Shifts:         make([]SharedShift, 0, len(shifts)),
+		ExpiresAt:      link.ExpiresAt,
+	}
+	for _, s := range shifts {
+		shared.Shifts = append(shared.Shifts, SharedShift{
+			Date:         store.DateOnly(s.ShiftDate.Format("2006-01-02")),
+			StartTime:    s.StartTime,
+			EndTime:      s.EndTime,
+			RoleName:     s.RoleName,
+			RoleColor:    s.RoleColor,
+			EmployeeName: s.EmployeeName,
+			Training:     s.Training,
+			Notes:        s.Notes,
+		})
+	}
+
+	// The token is in the URL: keep the page out of caches, search results and Referer headers
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	if r.URL.Query().Get("format") != "html" {
+		if err := app.jsonResponse(w, r, http.StatusOK, shared); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Render first so a failure can still be reported as JSON
+	var buf bytes.Buffer
+	if err := sharedScheduleTemplate.Execute(&buf, sharedSchedulePage(shared, requestLocale(r))); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// sharedScheduleDay is a day of the shared schedule page
+type sharedScheduleDay struct {
+	Label  string
+	Note   string
+	Shifts []sharedScheduleRow
+}
+
+type sharedScheduleRow struct {
+	Time      string
+	RoleName  string
+	RoleColor string
+	Employee  string
+	Training  bool
+	Notes     string
+}
+
+type sharedSchedulePageData struct {
+	RestaurantName string
+	Week           string
+	Note           string
+	Days           []sharedScheduleDay
+}
+
+// sharedSchedulePage lays the shared schedule out a day at a time, every day
+// of the week included
+func sharedSchedulePage(shared *SharedSchedule, locale i18n.Locale) sharedSchedulePageData {
+	page := sharedSchedulePageData{
+		RestaurantName: shared.RestaurantName,
+		Week:           i18n.FormatDay(locale, string(shared.StartDate)) + " – " + i18n.FormatDay(locale, string(shared.EndDate)),
+		Note:           shared.Note,
+	}
+
+	start, err := shared.StartDate.ToTime()
+	if err != nil {
+		return page
+	}
+	end, err := shared.EndDate.ToTime()
+	if err != nil {
+		return page
+	}
+
+	notes := make(map[store.DateOnly]string, len(shared.DayNotes))
+	for _, note := range shared.DayNotes {
+		notes[note.Date] = note.Note
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := store.DateOnly(day.Format("2006-01-02"))
+		d := sharedScheduleDay{Label: i18n.FormatWeekday(locale, day), Note: notes[date]}
+		for _, s := range shared.Shifts {
+			if s.Date != date {
+				continue
+			}
+			row := sharedScheduleRow{
+				Time:      i18n.FormatTimeOfDay(locale, string(s.StartTime)) + " – " + i18n.FormatTimeOfDay(locale, string(s.EndTime)),
+				RoleName:  s.RoleName,
+				RoleColor: s.RoleColor,
+				Training:  s.Training,
+				Notes:     s.Notes,
+			}
+			if s.EmployeeName != nil {
+				row.Employee = *s.EmployeeName
+			}
+			d.Shifts = append(d.Shifts, row)
+		}
+		page.Days = append(page.Days, d)
+	}
+	return page
+}
+
+var sharedScheduleTemplate = template.Must(template.New("shared_schedule").Parse(`<!doctype html>
+<html>
+  <head>
+    <meta charset="utf-8" />
+    <meta name="viewport" content="width=device-width" />
+    <meta name="robots" content="noindex" />
+    <title>{{.RestaurantName}} · {{.Week}}</title>
+    <style>
+      body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.5; color: #333; max-width: 800px; margin: 0 auto; padding: 20px; }
+      h1 { color: #2c3e50; margin-bottom: 0; }
+      h2 { color: #34495e; border-bottom: 2px solid #ecf0f1; padding-bottom: 6px; margin-top: 28px; }
+      .week, .note { color: #666; }
+      table { width: 100%; border-collapse: collapse; }
+      td { padding: 6px 8px; border-bottom: 1px solid #f0f0f0; vertical-align: top; }
+      .role { border-left: 4px solid #ccc; }
+      .open { color: #999; font-style: italic; }
+      @media print { body { max-width: none; } }
+    </style>
+  </head>
+  <body>
+    <h1>{{.RestaurantName}}</h1>
+    <p class="week">{{.Week}}</p>
+    {{if .Note}}<p class="note">{{.Note}}</p>{{end}}
+    {{range .Days}}
+    <h2>{{.Label}}</h2>
+    {{if .Note}}<p class="note">{{.Note}}</p>{{end}}
+    {{if .Shifts}}
+    <table>
+      {{range .Shifts}}
+      <tr>
+        <td>{{.Time}}</td>
+        <td class="role" style="border-left-color: {{.RoleColor}}">{{.RoleName}}{{if .Training}} (training){{end}}</td>
+        <td>{{if .Employee}}{{.Employee}}{{else}}<span class="open">Open</span>{{end}}</td>
+        <td>{{.Notes}}</td>
+      </tr>
+      {{end}}
+    </table>
+    {{else}}
+    <p class="open">No shifts</p>
+    {{end}}
+    {{end}}
+  </body>
+</html>
+`))
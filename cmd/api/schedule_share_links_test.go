@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCreateShareLink(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.config.apiURL = "api.example.com"
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 3}, nil
+		},
+	}
+	var saved *store.ShareLink
+	var savedToken string
+	app.store.ShareLinks = &store.MockShareLinkStorer{
+		CreateFunc: func(_ context.Context, link *store.ShareLink, token string) error {
+			saved, savedToken = link, token
+			link.ID = 4
+			return nil
+		},
+	}
+
+	t.Run("defaults to a week", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/share-link", ""), app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		var body struct {
+			Data ShareLinkWithToken `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.Token == "" || body.Data.Token != savedToken {
+			t.Errorf("token = %q, want the stored %q", body.Data.Token, savedToken)
+		}
+		if want := "http://api.example.com/v1/shared/schedules/" + savedToken; body.Data.URL != want {
+			t.Errorf("url = %q, want %q", body.Data.URL, want)
+		}
+		if saved.ScheduleID != 5 || saved.RestaurantID != 3 {
+			t.Errorf("link = %+v", saved)
+		}
+		if left := time.Until(saved.ExpiresAt); left < 167*time.Hour || left > 168*time.Hour {
+			t.Errorf("expires in %s, want a week", left)
+		}
+	})
+
+	t.Run("rejects an expiry past 90 days", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/schedules/5/share-link", `{"expires_in_hours": 2161}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetSharedSchedule(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	expiresAt := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	app.store.ShareLinks = &store.MockShareLinkStorer{
+		AuthenticateFunc: func(_ context.Context, token string, _ time.Time) (*store.ShareLink, error) {
+			if token != "share-token" {
+				return nil, store.ErrNotFound
+			}
+			return &store.ShareLink{ID: 4, ScheduleID: 5, RestaurantID: 3, ExpiresAt: expiresAt}, nil
+		},
+	}
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 3, StartDate: "2026-03-02", EndDate: "2026-03-08", Note: "Spring break"}, nil
+		},
+	}
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) {
+			name := "<b>Ana</b>"
+			return []*store.ScheduledShift{{
+				ID: 10, ScheduleID: 5, RestaurantID: 3, RoleID: 1, RoleName: "Server", RoleColor: "#ff0000",
+				EmployeeName: &name, ShiftDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+				StartTime: "09:00:00", EndTime: "17:00:00",
+			}}, nil
+		},
+	}
+	app.store.ScheduleNotes = &store.MockScheduleNoteStorer{
+		ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduleDayNote, error) {
+			return []*store.ScheduleDayNote{{ScheduleID: 5, Date: "2026-03-04", Note: "Health inspection"}}, nil
+		},
+	}
+
+	t.Run("serves JSON without an account", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/shared/schedules/share-token", nil)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("Cache-Control = %q, want no-store", got)
+		}
+		var body struct {
+			Data SharedSchedule `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data.Shifts) != 1 || body.Data.Shifts[0].Date != "2026-03-03" || body.Data.Shifts[0].RoleName != "Server" {
+			t.Errorf("shifts = %+v", body.Data.Shifts)
+		}
+		if len(body.Data.DayNotes) != 1 || !body.Data.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("schedule = %+v", body.Data)
+		}
+	})
+
+	t.Run("serves an escaped page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/shared/schedules/share-token?format=html", nil)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+			t.Errorf("Content-Type = %q, want text/html", got)
+		}
+		page := rr.Body.String()
+		for _, want := range []string{"Spring break", "Health inspection", "&lt;b&gt;Ana&lt;/b&gt;", "No shifts"} {
+			if !strings.Contains(page, want) {
+				t.Errorf("page is missing %q", want)
+			}
+		}
+		if strings.Contains(page, "<b>Ana</b>") {
+			t.Error("page has the employee name unescaped")
+		}
+	})
+
+	t.Run("an expired or revoked link is not found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/shared/schedules/other-token", nil)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestRevokeShareLink(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 3}, nil
+		},
+	}
+	app.store.ShareLinks = &store.MockShareLinkStorer{
+		RevokeFunc: func(_ context.Context, scheduleID, linkID int64) error {
+			if scheduleID != 5 || linkID != 4 {
+				return store.ErrNotFound
+			}
+			return nil
+		},
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/share-links/4", ""), app.mount())
+	checkResponseCode(t, http.StatusNoContent, rr.Code)
+
+	rr = executeRequest(authedRequest(t, app, http.MethodDelete, "/v1/restaurants/3/schedules/5/share-links/9", ""), app.mount())
+	checkResponseCode(t, http.StatusNotFound, rr.Code)
+}
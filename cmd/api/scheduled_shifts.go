@@ -43,43 +43,68 @@ type createScheduledShiftRequest struct {
 	StartTime       string    `json:"start_time"`
 	EndTime         string    `json:"end_time"`
 	Notes           string    `json:"notes"`
+	// EventID links the shift to the event it staffs; the shift must be on the event's date
+	EventID *int64 `json:"event_id,omitempty"`
 }
 
+// updateScheduledShiftRequest is a merge patch: null clears the template,
+// employee, notes and event; role, date and times can't be cleared
 type updateScheduledShiftRequest struct {
-	ShiftTemplateID *int64    `json:"shift_template_id,omitempty"`
-	RoleID          *int64    `json:"role_id,omitempty"`
-	EmployeeID      *int64    `json:"employee_id,omitempty"`
-	ShiftDate       *time.Time `json:"shift_date,omitempty"`
-	StartTime       *string    `json:"start_time,omitempty"`
-	EndTime         *string    `json:"end_time,omitempty"`
-	Notes           *string    `json:"notes,omitempty"`
+	ShiftTemplateID Patch[int64]     `json:"shift_template_id" swaggertype:"integer"`
+	RoleID          Patch[int64]     `json:"role_id" swaggertype:"integer"`
+	EmployeeID      Patch[int64]     `json:"employee_id" swaggertype:"integer"`
+	ShiftDate       Patch[time.Time] `json:"shift_date" swaggertype:"string" format:"date-time"`
+	StartTime       Patch[string]    `json:"start_time" swaggertype:"string"`
+	EndTime         Patch[string]    `json:"end_time" swaggertype:"string"`
+	Notes           Patch[string]    `json:"notes" swaggertype:"string"`
+	// EventID links the shift to an event; null or 0 unlinks it
+	EventID Patch[int64] `json:"event_id" swaggertype:"integer"`
 }
 
 type assignEmployeeRequest struct {
 	EmployeeID *int64 `json:"employee_id"`
+	// Training assigns the employee even if they don't have the shift's role yet
+	Training       bool   `json:"training"`
+	TrainerShiftID *int64 `json:"trainer_shift_id,omitempty"`
 }
 
 // getScheduledShiftsHandler godoc
 //
 //	@Summary		List all shifts for a schedule
-//	@Description	Gets all scheduled shifts for a specific schedule
+//	@Description	Gets all scheduled shifts for a specific schedule; with include_conflicts=true each shift carries computed warnings (double_booked, overtime_risk, role_mismatch, certification_expired, outside_operating_hours)
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurantID	path		int	true	"Restaurant ID"
-//	@Param			scheduleID		path		int	true	"Schedule ID"
-//	@Success		200				{array}		store.ScheduledShift
-//	@Failure		400				{object}	error
-//	@Failure		401				{object}	error
-//	@Failure		500				{object}	error
+//	@Param			restaurantID		path		int		true	"Restaurant ID"
+//	@Param			scheduleID			path		int		true	"Schedule ID"
+//	@Param			include_conflicts	query		bool	false	"Annotate shifts with conflict warnings"
+//	@Success		200					{array}		ShiftResponse
+//	@Failure		400					{object}	error
+//	@Failure		401					{object}	error
+//	@Failure		500					{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts [get]
 func (app *application) getScheduledShiftsHandler(w http.ResponseWriter, r *http.Request) {
-	scheduleID, err := strconv.ParseInt(chi.URLParam(r, "scheduleID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, errors.New("invalid schedule ID"))
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
 		return
 	}
+	scheduleID := schedule.ID
+	access := getAccessFromContext(r)
+
+	includeConflicts := r.URL.Query().Get("include_conflicts") == "true"
+
+	// Warnings depend on more than the shifts themselves, so only plain listings are revalidated
+	if !includeConflicts {
+		version, err := app.store.Versions.ScheduledShifts(r.Context(), scheduleID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if app.notModified(w, r, fmt.Sprintf("shifts-%d-%s", scheduleID, access.scope()), version) {
+			return
+		}
+	}
 
 	// Get shifts for this schedule
 	shifts, err := app.store.ScheduledShifts.ListBySchedule(r.Context(), scheduleID)
@@ -88,22 +113,39 @@ func (app *application) getScheduledShiftsHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	app.jsonResponse(w, http.StatusOK, shifts)
+	// Shift leads only see the shifts of their roles
+	shifts = access.filterShifts(shifts)
+
+	// Optionally annotate shifts with conflict warnings
+	if includeConflicts {
+		warnings, err := app.store.ScheduledShifts.ListWarningsBySchedule(r.Context(), scheduleID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		for _, shift := range shifts {
+			shift.Warnings = warnings[shift.ID]
+		}
+	}
+
+	app.jsonResponse(w, r, http.StatusOK, newShiftResponses(shifts))
 }
 
 // createScheduledShiftHandler godoc
 //
 //	@Summary		Create a new shift
-//	@Description	Creates a new scheduled shift for a specific schedule
+//	@Description	Creates a new scheduled shift for a specific schedule; its shift_date must fall within the schedule's dates. Shifts outside operating hours are rejected or returned with an outside_operating_hours warning, depending on the restaurant's enforcement setting. Set event_id to link the shift to an event on the same date. With employee_id, the employee must hold the role and its required certifications, or it answers 409.
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
 //	@Param			restaurantID	path		int							true	"Restaurant ID"
 //	@Param			scheduleID		path		int							true	"Schedule ID"
 //	@Param			shift			body		createScheduledShiftRequest	true	"Shift information"
-//	@Success		201				{object}	store.ScheduledShift
+//	@Success		201				{object}	ShiftResponse
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts [post]
@@ -114,11 +156,11 @@ func (app *application) createScheduledShiftHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	scheduleID, err := strconv.ParseInt(chi.URLParam(r, "scheduleID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, errors.New("invalid schedule ID"))
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
 		return
 	}
+	scheduleID := schedule.ID
 
 	var req createScheduledShiftRequest
 	if err := readJSON(w, r, &req); err != nil {
@@ -126,6 +168,11 @@ func (app *application) createScheduledShiftHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
+	if !getAccessFromContext(r).canManageRole(req.RoleID) {
+		app.forbiddenResponse(w, r, errRoleOutOfScope(req.RoleID))
+		return
+	}
+
 	// Validate time format
 	if _, err := time.Parse("15:04", req.StartTime); err != nil {
 		app.badRequestResponse(w, r, errors.New("start time must be in format HH:MM"))
@@ -153,9 +200,44 @@ func (app *application) createScheduledShiftHandler(w http.ResponseWriter, r *ht
 		StartTime:       store.TimeOfDay(req.StartTime),
 		EndTime:         store.TimeOfDay(req.EndTime),
 		Notes:           req.Notes,
+		EventID:         req.EventID,
+	}
+
+	if shift.EventID != nil {
+		if err := app.validateShiftEvent(r.Context(), restaurantID, *shift.EventID, shift.ShiftDate); err != nil {
+			if errors.Is(err, errShiftEventMissing) || errors.Is(err, errShiftEventDate) {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	hours, err := app.operatingHoursCheck(r.Context(), restaurantID, shift.ShiftDate, shift.ShiftDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	outsideHours := hours.violation(shift)
+	if outsideHours != nil && hours.blocks() {
+		app.badRequestResponse(w, r, outsideHours)
+		return
+	}
+
+	var violations []ComplianceViolation
+	if shift.EmployeeID != nil {
+		if violations, ok = app.checkAssignment(w, r, shift, *shift.EmployeeID); !ok {
+			return
+		}
 	}
 
 	if err := app.store.ScheduledShifts.Create(r.Context(), shift); err != nil {
+		if errors.Is(err, store.ErrShiftOutsideSchedule) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -168,11 +250,17 @@ func (app *application) createScheduledShiftHandler(w http.ResponseWriter, r *ht
 
 		// Fallback: return the shift without joined data
 		// The frontend will still work, just without employee/role names initially
-		app.jsonResponse(w, http.StatusCreated, shift)
-		return
+		createdShift = shift
+	}
+
+	app.auditShiftsCreated(r.Context(), getUserFromContext(r).ID, createdShift)
+
+	if outsideHours != nil {
+		createdShift.Warnings = append(createdShift.Warnings, store.WarningOutsideHours)
 	}
+	app.warnComplianceViolations(createdShift, violations)
 
-	app.jsonResponse(w, http.StatusCreated, createdShift)
+	app.jsonResponse(w, r, http.StatusCreated, newShiftResponse(createdShift))
 }
 
 // getScheduledShiftHandler godoc
@@ -185,112 +273,122 @@ func (app *application) createScheduledShiftHandler(w http.ResponseWriter, r *ht
 //	@Param			restaurantID	path		int	true	"Restaurant ID"
 //	@Param			scheduleID		path		int	true	"Schedule ID"
 //	@Param			shiftID			path		int	true	"Shift ID"
-//	@Success		200				{object}	store.ScheduledShift
+//	@Success		200				{object}	ShiftResponse
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
 //	@Failure		404				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID} [get]
 func (app *application) getScheduledShiftHandler(w http.ResponseWriter, r *http.Request) {
-	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
-		return
-	}
-
-	shift, err := app.store.ScheduledShifts.GetByID(r.Context(), shiftID)
-	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
-			return
-		}
-		app.internalServerError(w, r, err)
+	shift, ok := app.restaurantShiftFromURL(w, r)
+	if !ok {
 		return
 	}
 
-	app.jsonResponse(w, http.StatusOK, shift)
+	app.jsonResponse(w, r, http.StatusOK, newShiftResponse(shift))
 }
 
 // updateScheduledShiftHandler godoc
 //
 //	@Summary		Update a shift
-//	@Description	Updates an existing scheduled shift by ID
+//	@Description	Updates an existing scheduled shift by ID as a JSON merge patch (RFC 7386): fields left out are unchanged and null clears shift_template_id, employee_id (unassigning the shift), notes and event_id. event_id links the shift to an event on the same date, 0 unlinks it. A shift_date outside the schedule's dates is refused. Changing the employee, role or date of an assigned shift answers 409 when the employee doesn't hold the role or its required certifications, unless it's a training shift.
 //	@Tags			scheduled-shifts
-//	@Accept			json
+//	@Accept			json,application/merge-patch+json
 //	@Produce		json
 //	@Param			restaurantID	path		int							true	"Restaurant ID"
 //	@Param			scheduleID		path		int							true	"Schedule ID"
 //	@Param			shiftID			path		int							true	"Shift ID"
 //	@Param			shift			body		updateScheduledShiftRequest	true	"Updated shift information"
-//	@Success		200				{object}	store.ScheduledShift
+//	@Param			override_lock	query		bool						false	"Owner only: change the shift even inside the schedule lock window"
+//	@Success		200				{object}	ShiftResponse
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		423				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID} [patch]
 func (app *application) updateScheduledShiftHandler(w http.ResponseWriter, r *http.Request) {
-	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
-		return
-	}
-
 	// Get the existing shift
-	shift, err := app.store.ScheduledShifts.GetByID(r.Context(), shiftID)
-	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
-			return
-		}
-		app.internalServerError(w, r, err)
+	shift, ok := app.restaurantShiftFromURL(w, r)
+	if !ok {
 		return
 	}
 
+	before := *shift
+
 	var req updateScheduledShiftRequest
 	if err := readJSON(w, r, &req); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	// Update fields if provided
-	if req.ShiftTemplateID != nil {
-		shift.ShiftTemplateID = req.ShiftTemplateID
-	}
-	
-	if req.RoleID != nil {
-		shift.RoleID = *req.RoleID
-	}
-	
-	if req.EmployeeID != nil {
-		shift.EmployeeID = req.EmployeeID
+	if err := errors.Join(
+		req.RoleID.NotNull("role_id"),
+		req.ShiftDate.NotNull("shift_date"),
+		req.StartTime.NotNull("start_time"),
+		req.EndTime.NotNull("end_time"),
+	); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
 	}
-	
-	if req.ShiftDate != nil {
-		shift.ShiftDate = *req.ShiftDate
+
+	// Update fields if provided
+	req.ShiftTemplateID.ApplyPtr(&shift.ShiftTemplateID)
+
+	if req.RoleID.Present() {
+		// A shift lead can't hand a shift over to a role they don't manage
+		if !getAccessFromContext(r).canManageRole(req.RoleID.Value) {
+			app.forbiddenResponse(w, r, errRoleOutOfScope(req.RoleID.Value))
+			return
+		}
+		shift.RoleID = req.RoleID.Value
 	}
-	
-	if req.StartTime != nil {
+
+	// null unassigns the shift
+	req.EmployeeID.ApplyPtr(&shift.EmployeeID)
+
+	req.ShiftDate.Apply(&shift.ShiftDate)
+
+	if req.StartTime.Present() {
 		// Validate time format
-		if _, err := time.Parse("15:04", *req.StartTime); err != nil {
+		if _, err := time.Parse("15:04", req.StartTime.Value); err != nil {
 			app.badRequestResponse(w, r, errors.New("start time must be in format HH:MM"))
 			return
 		}
-		shift.StartTime = store.TimeOfDay(*req.StartTime)
+		shift.StartTime = store.TimeOfDay(req.StartTime.Value)
 	}
 
-	if req.EndTime != nil {
+	if req.EndTime.Present() {
 		// Validate time format
-		if _, err := time.Parse("15:04", *req.EndTime); err != nil {
+		if _, err := time.Parse("15:04", req.EndTime.Value); err != nil {
 			app.badRequestResponse(w, r, errors.New("end time must be in format HH:MM"))
 			return
 		}
-		shift.EndTime = store.TimeOfDay(*req.EndTime)
+		shift.EndTime = store.TimeOfDay(req.EndTime.Value)
 	}
-	
-	if req.Notes != nil {
-		shift.Notes = *req.Notes
+
+	req.Notes.Apply(&shift.Notes)
+
+	req.EventID.ApplyPtr(&shift.EventID)
+	if shift.EventID != nil && *shift.EventID == 0 {
+		shift.EventID = nil
+	}
+
+	// Re-check the link when it or the date changed so shifts stay on their event's day
+	if shift.EventID != nil && (req.EventID.Set || req.ShiftDate.Set) {
+		if err := app.validateShiftEvent(r.Context(), shift.RestaurantID, *shift.EventID, shift.ShiftDate); err != nil {
+			if errors.Is(err, errShiftEventMissing) || errors.Is(err, errShiftEventDate) {
+				app.badRequestResponse(w, r, err)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
 	}
 
 	// Validate end time is after start time
@@ -299,43 +397,88 @@ func (app *application) updateScheduledShiftHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// Moving a shift into the lock window is as late a change as editing one already in it
+	if !app.checkShiftLock(w, r, &before, shift) {
+		return
+	}
+
+	hours, err := app.operatingHoursCheck(r.Context(), shift.RestaurantID, shift.ShiftDate, shift.ShiftDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	outsideHours := hours.violation(shift)
+	if outsideHours != nil && hours.blocks() {
+		app.badRequestResponse(w, r, outsideHours)
+		return
+	}
+
+	// Re-check the assignment whenever the employee, role, date or times changed
+	var violations []ComplianceViolation
+	if shift.EmployeeID != nil && (req.EmployeeID.Set || req.RoleID.Set || req.ShiftDate.Set || req.StartTime.Set || req.EndTime.Set) {
+		if violations, ok = app.checkAssignment(w, r, shift, *shift.EmployeeID); !ok {
+			return
+		}
+	}
+
 	if err := app.store.ScheduledShifts.Update(r.Context(), shift); err != nil {
-		if errors.Is(err, store.ErrNotFound) {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
 			app.notFoundResponse(w, r, err)
-			return
+		case errors.Is(err, store.ErrShiftOutsideSchedule):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
 		}
-		app.internalServerError(w, r, err)
 		return
 	}
 
-	app.jsonResponse(w, http.StatusOK, shift)
+	// Re-read so a changed role shows its own name in the notification and history
+	if updated, err := app.store.ScheduledShifts.GetByID(r.Context(), shift.ID); err == nil {
+		app.auditShiftChanged(r.Context(), getUserFromContext(r).ID, &before, updated)
+		app.notifyShiftChanged(r.Context(), &before, updated)
+	}
+
+	if outsideHours != nil {
+		shift.Warnings = append(shift.Warnings, store.WarningOutsideHours)
+	}
+	app.warnComplianceViolations(shift, violations)
+
+	app.jsonResponse(w, r, http.StatusOK, newShiftResponse(shift))
 }
 
 // deleteScheduledShiftHandler godoc
 //
 //	@Summary		Delete a shift
-//	@Description	Deletes a scheduled shift by ID
+//	@Description	Deletes a scheduled shift by ID; rejected with 423 if the shift is on a published schedule and starts within the restaurant's lock window, unless the owner overrides the lock
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurantID	path		int	true	"Restaurant ID"
-//	@Param			scheduleID		path		int	true	"Schedule ID"
-//	@Param			shiftID			path		int	true	"Shift ID"
-//	@Success		200				{object}	map[string]string
+//	@Param			restaurantID	path	int		true	"Restaurant ID"
+//	@Param			scheduleID		path	int		true	"Schedule ID"
+//	@Param			shiftID			path	int		true	"Shift ID"
+//	@Param			override_lock	query	bool	false	"Owner only: delete the shift even inside the schedule lock window"
+//	@Success		204				"No Content"
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		423				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID} [delete]
 func (app *application) deleteScheduledShiftHandler(w http.ResponseWriter, r *http.Request) {
-	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
+	shift, ok := app.restaurantShiftFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if !app.checkShiftLock(w, r, shift) {
 		return
 	}
 
-	if err := app.store.ScheduledShifts.Delete(r.Context(), shiftID); err != nil {
+	if err := app.store.ScheduledShifts.Delete(r.Context(), shift.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -344,14 +487,16 @@ func (app *application) deleteScheduledShiftHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	message := map[string]string{"message": "scheduled shift deleted"}
-	app.jsonResponse(w, http.StatusNoContent, message)
+	app.auditShiftChanged(r.Context(), getUserFromContext(r).ID, shift, nil)
+	app.notifyShiftChanged(r.Context(), shift, nil)
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 // assignEmployeeToShiftHandler godoc
 //
 //	@Summary		Assign employee to shift
-//	@Description	Assigns an employee to a scheduled shift
+//	@Description	Assigns an employee to a scheduled shift; rejected with 409 if the employee lacks the shift's role or a certification the role requires, or the assignment breaks a compliance rule set to block. Rules set to warn add the compliance warning to the shift. With training=true the role check is skipped so the employee can train for the role, optionally linked to the trainer's overlapping shift via trainer_shift_id.
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
@@ -359,32 +504,67 @@ func (app *application) deleteScheduledShiftHandler(w http.ResponseWriter, r *ht
 //	@Param			scheduleID		path		int						true	"Schedule ID"
 //	@Param			shiftID			path		int						true	"Shift ID"
 //	@Param			employee		body		assignEmployeeRequest	true	"Employee assignment information"
-//	@Success		200				{object}	store.ScheduledShift
+//	@Param			override_lock	query		bool					false	"Owner only: change the shift even inside the schedule lock window"
+//	@Success		200				{object}	ShiftResponse
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		423				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/assign [patch]
 func (app *application) assignEmployeeToShiftHandler(w http.ResponseWriter, r *http.Request) {
-	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
-	if err != nil {
+	var req assignEmployeeRequest
+	if err := readJSON(w, r, &req); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	var req assignEmployeeRequest
-	if err := readJSON(w, r, &req); err != nil {
-		app.badRequestResponse(w, r, err)
+	before, ok := app.restaurantShiftFromURL(w, r)
+	if !ok {
 		return
 	}
+	shiftID := before.ID
 
-	if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), shiftID, req.EmployeeID); err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			app.notFoundResponse(w, r, err)
+	if !app.checkShiftLock(w, r, before) {
+		return
+	}
+
+	// Block assignments to roles the employee doesn't hold or whose required certifications they lack
+	// or have expired, and those breaking a blocking compliance rule; the other rules warn
+	var violations []ComplianceViolation
+	if req.EmployeeID != nil {
+		assigned := *before
+		assigned.Training = req.Training
+		if violations, ok = app.checkAssignment(w, r, &assigned, *req.EmployeeID); !ok {
 			return
 		}
-		app.internalServerError(w, r, err)
+	}
+
+	if req.TrainerShiftID != nil && !req.Training {
+		app.badRequestResponse(w, r, errors.New("trainer_shift_id requires training"))
+		return
+	}
+
+	assignment := store.ShiftAssignment{
+		EmployeeID:     req.EmployeeID,
+		Training:       req.Training,
+		TrainerShiftID: req.TrainerShiftID,
+	}
+
+	if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), shiftID, assignment); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrRoleMismatch):
+			app.conflictResponse(w, r, errors.New("employee does not have the required role for this shift; assign with training=true to schedule them as a trainee"))
+		case errors.Is(err, store.ErrInvalidTrainerShift):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
 		return
 	}
 
@@ -395,7 +575,12 @@ func (app *application) assignEmployeeToShiftHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	app.jsonResponse(w, http.StatusOK, shift)
+	app.auditShiftChanged(r.Context(), getUserFromContext(r).ID, before, shift)
+	app.notifyShiftChanged(r.Context(), before, shift)
+
+	app.warnComplianceViolations(shift, violations)
+
+	app.jsonResponse(w, r, http.StatusOK, newShiftResponse(shift))
 }
 
 // unassignEmployeeFromShiftHandler godoc
@@ -405,26 +590,32 @@ func (app *application) assignEmployeeToShiftHandler(w http.ResponseWriter, r *h
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurantID	path		int	true	"Restaurant ID"
-//	@Param			scheduleID		path		int	true	"Schedule ID"
-//	@Param			shiftID			path		int	true	"Shift ID"
-//	@Success		200				{object}	store.ScheduledShift
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			shiftID			path		int		true	"Shift ID"
+//	@Param			override_lock	query		bool	false	"Owner only: change the shift even inside the schedule lock window"
+//	@Success		200				{object}	ShiftResponse
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		423				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/assign [delete]
 func (app *application) unassignEmployeeFromShiftHandler(w http.ResponseWriter, r *http.Request) {
-	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
-	if err != nil {
-		app.badRequestResponse(w, r, err)
+	before, ok := app.restaurantShiftFromURL(w, r)
+	if !ok {
+		return
+	}
+	shiftID := before.ID
+
+	if !app.checkShiftLock(w, r, before) {
 		return
 	}
 
-	// Pass nil to unassign
-	var nilEmployeeID *int64 = nil
-	if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), shiftID, nilEmployeeID); err != nil {
+	// An empty assignment unassigns
+	if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), shiftID, store.ShiftAssignment{}); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -440,18 +631,22 @@ func (app *application) unassignEmployeeFromShiftHandler(w http.ResponseWriter,
 		return
 	}
 
-	app.jsonResponse(w, http.StatusOK, shift)
+	app.auditShiftChanged(r.Context(), getUserFromContext(r).ID, before, shift)
+	app.notifyShiftChanged(r.Context(), before, shift)
+
+	app.jsonResponse(w, r, http.StatusOK, newShiftResponse(shift))
 }
 
 // autoPopulateScheduleHandler godoc
 //
 //	@Summary		Auto-populate schedule with template-based shifts
-//	@Description	Creates scheduled shifts for all shift templates that don't have shifts yet
+//	@Description	Creates scheduled shifts for all shift templates that don't have shifts yet. Shifts outside operating hours are skipped when the restaurant blocks them, otherwise listed in outside_operating_hours_ids. With dry_run=true nothing is written; the response is an autoPopulatePreview of the shifts that would be created, with a per-day breakdown and outside_operating_hours warnings.
 //	@Tags			scheduled-shifts
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurantID	path		int	true	"Restaurant ID"
-//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			dry_run			query		bool	false	"Preview the shifts without creating them"
 //	@Success		200				{object}	map[string]interface{}
 //	@Failure		400				{object}	error
 //	@Failure		404				{object}	error
@@ -489,8 +684,8 @@ func (app *application) autoPopulateScheduleHandler(w http.ResponseWriter, r *ht
 	}
 
 	// Verify restaurant ownership
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -499,12 +694,6 @@ func (app *application) autoPopulateScheduleHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get all shift templates for this restaurant (role_ids included via JSONB)
 	templates, err := app.store.ShiftTemplates.ListByRestaurant(r.Context(), restaurantID)
 	if err != nil {
@@ -519,18 +708,6 @@ func (app *application) autoPopulateScheduleHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	// Build map of existing shifts: "date-templateId-roleId" -> true
-	existingMap := make(map[string]bool)
-	for _, shift := range existingShifts {
-		if shift.ShiftTemplateID != nil {
-			// Format: "2006-01-02-templateID-roleID"
-			key := shift.ShiftDate.Format("2006-01-02") + "-" +
-				   strconv.FormatInt(*shift.ShiftTemplateID, 10) + "-" +
-				   strconv.FormatInt(shift.RoleID, 10)
-			existingMap[key] = true
-		}
-	}
-
 	// Parse schedule date range (handles both YYYY-MM-DD and ISO 8601 formats)
 	startDate, err := parseFlexibleDate(string(schedule.StartDate))
 	if err != nil {
@@ -544,50 +721,19 @@ func (app *application) autoPopulateScheduleHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	var shiftsToCreate []*store.ScheduledShift
-
-	// For each day in the schedule
-	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
-		dayOfWeek := int(date.Weekday()) // 0=Sunday, 6=Saturday
-
-		// Find templates for this day
-		for _, template := range templates {
-			if template.DayOfWeek != dayOfWeek {
-				continue
-			}
+	hours, err := app.operatingHoursCheck(r.Context(), restaurantID, startDate, endDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
 
-			// Skip if template has no roles
-			if len(template.RoleIDs) == 0 {
-				continue
-			}
+	plan := planAutoPopulate(schedule, startDate, endDate, templates, getRestaurantFromContext(r).RotationAnchor, existingShifts, hours)
+	shiftsToCreate, outsideHours := plan.Shifts, plan.OutsideHours
 
-			// Create shift for each role
-			for _, roleID := range template.RoleIDs {
-				key := date.Format("2006-01-02") + "-" +
-					   strconv.FormatInt(template.ID, 10) + "-" +
-					   strconv.FormatInt(roleID, 10)
-
-				// Skip if already exists
-				if existingMap[key] {
-					continue
-				}
-
-				// Create scheduled shift with employee_id = null
-				shift := &store.ScheduledShift{
-					ScheduleID:      scheduleID,
-					RestaurantID:    restaurantID,
-					ShiftTemplateID: &template.ID,
-					RoleID:          roleID,
-					EmployeeID:      nil, // Unassigned
-					ShiftDate:       date,
-					StartTime:       template.StartTime,
-					EndTime:         template.EndTime,
-					Notes:           template.Notes,
-				}
-
-				shiftsToCreate = append(shiftsToCreate, shift)
-			}
-		}
+	// A dry run stops before writing anything
+	if r.URL.Query().Get(dryRunParam) == "true" {
+		app.jsonResponse(w, r, http.StatusOK, plan.preview())
+		return
 	}
 
 	// Batch create shifts
@@ -598,13 +744,23 @@ func (app *application) autoPopulateScheduleHandler(w http.ResponseWriter, r *ht
 			app.internalServerError(w, r, err)
 			return
 		}
+		app.auditShiftsCreated(r.Context(), user.ID, shiftsToCreate...)
+	}
+
+	outsideHoursIDs := []int64{}
+	for i, id := range createdIDs {
+		if outsideHours[i] {
+			outsideHoursIDs = append(outsideHoursIDs, id)
+		}
 	}
 
 	response := map[string]interface{}{
-		"created_count": len(createdIDs),
-		"created_ids":   createdIDs,
+		"created_count":               len(createdIDs),
+		"created_ids":                 createdIDs,
+		"skipped_outside_hours":       plan.SkippedOutsideHours,
+		"outside_operating_hours_ids": outsideHoursIDs,
 	}
 
-	app.jsonResponse(w, http.StatusOK, response)
+	app.jsonResponse(w, r, http.StatusOK, response)
 }
 
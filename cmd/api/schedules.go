@@ -3,12 +3,17 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html/template"
 	"net/http"
 	"strconv"
 	"time"
 
+	"github.com/balebbae/RESA/internal/features"
+	"github.com/balebbae/RESA/internal/i18n"
 	"github.com/balebbae/RESA/internal/mailer"
 	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/store/cache"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -18,21 +23,31 @@ import (
 type CreateSchedulePayload struct {
 	StartDate string `json:"start_date" validate:"required"` // YYYY-MM-DD
 	EndDate   string `json:"end_date" validate:"required"`   // YYYY-MM-DD
+	Note      string `json:"note" validate:"max=500"`
 }
 
 type UpdateSchedulePayload struct {
 	StartDate *string `json:"start_date,omitempty" validate:"omitempty"` // YYYY-MM-DD
 	EndDate   *string `json:"end_date,omitempty" validate:"omitempty"`   // YYYY-MM-DD
+	// Note replaces the schedule's note; an empty note clears it
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+	// StrandedShifts is what happens to shifts the new dates no longer cover:
+	// reject (the default) refuses the update listing them, delete deletes them
+	StrandedShifts string `json:"stranded_shifts,omitempty" validate:"omitempty,oneof=reject delete"`
 }
 
+// strandedShiftsDelete has a schedule update delete the shifts its new dates no longer cover
+const strandedShiftsDelete = "delete"
+
 // GetSchedules godoc
 //
 //	@Summary		Lists restaurant's schedules
-//	@Description	Fetches all schedules for a restaurant
+//	@Description	Fetches all schedules for a restaurant; archived schedules are only listed, on their own, with archived=true
 //	@Tags			schedule
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			archived		query		bool	false	"List archived schedules instead"
 //	@Success		200				{array}		store.Schedule
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
@@ -49,8 +64,8 @@ func (app *application) getSchedulesHandler(w http.ResponseWriter, r *http.Reque
 	ctx := r.Context()
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(ctx, restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkScheduleAccess(ctx, restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -59,14 +74,17 @@ func (app *application) getSchedulesHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+	version, err := app.store.Versions.Schedules(ctx, restaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	archived := r.URL.Query().Get("archived") == "true"
+	if app.notModified(w, r, fmt.Sprintf("schedules-%d-%t", restaurantID, archived), version) {
 		return
 	}
 
-	schedules, err := app.store.Schedules.ListByRestaurant(ctx, restaurantID)
+	schedules, err := app.store.Schedules.ListByRestaurant(ctx, restaurantID, archived)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -86,7 +104,7 @@ func (app *application) getSchedulesHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, schedules)
+	err = app.jsonResponse(w, r, http.StatusOK, schedules)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -96,17 +114,20 @@ func (app *application) getSchedulesHandler(w http.ResponseWriter, r *http.Reque
 // CreateSchedule godoc
 //
 //	@Summary		Creates a schedule
-//	@Description	Creates a schedule for a restaurant
+//	@Description	Creates a schedule for a restaurant. With preview_populate=true nothing is created; the response is the autoPopulatePreview of the shifts the templates would generate for the dates, as auto-populate's dry run would give for the new schedule.
 //	@Tags			schedule
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurant_id	path		int						true	"Restaurant ID"
-//	@Param			payload			body		CreateSchedulePayload	true	"Schedule payload"
-//	@Success		201				{object}	store.Schedule
-//	@Failure		400				{object}	error
-//	@Failure		401				{object}	error
-//	@Failure		404				{object}	error
-//	@Failure		500				{object}	error
+//	@Param			restaurant_id		path		int						true	"Restaurant ID"
+//	@Param			payload				body		CreateSchedulePayload	true	"Schedule payload"
+//	@Param			preview_populate	query		bool					false	"Preview the template shifts without creating the schedule"
+//	@Success		201					{object}	store.Schedule
+//	@Success		200					{object}	autoPopulatePreview
+//	@Failure		400					{object}	error
+//	@Failure		401					{object}	error
+//	@Failure		403					{object}	error
+//	@Failure		404					{object}	error
+//	@Failure		500					{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/schedules [post]
 func (app *application) createScheduleHandler(w http.ResponseWriter, r *http.Request) {
@@ -117,8 +138,8 @@ func (app *application) createScheduleHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -127,13 +148,6 @@ func (app *application) createScheduleHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	var payload CreateSchedulePayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -168,6 +182,12 @@ func (app *application) createScheduleHandler(w http.ResponseWriter, r *http.Req
 		RestaurantID: restaurantID,
 		StartDate:    store.DateOnly(payload.StartDate),
 		EndDate:      store.DateOnly(payload.EndDate),
+		Note:         payload.Note,
+	}
+
+	if r.URL.Query().Get(previewPopulateParam) == "true" {
+		app.previewSchedulePopulate(w, r, schedule, startDate, endDate)
+		return
 	}
 
 	if err := app.store.Schedules.Create(r.Context(), schedule); err != nil {
@@ -182,13 +202,38 @@ func (app *application) createScheduleHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusCreated, schedule)
+	err = app.jsonResponse(w, r, http.StatusCreated, schedule)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 }
 
+// previewSchedulePopulate answers with the shifts auto-populate would create
+// for a schedule that isn't saved yet. Nothing exists on it to skip.
+func (app *application) previewSchedulePopulate(w http.ResponseWriter, r *http.Request, schedule *store.Schedule, start, end time.Time) {
+	if !app.featureAllowed(w, r, features.AutoPopulate) {
+		return
+	}
+
+	templates, err := app.store.ShiftTemplates.ListByRestaurant(r.Context(), schedule.RestaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	hours, err := app.operatingHoursCheck(r.Context(), schedule.RestaurantID, start, end)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	plan := planAutoPopulate(schedule, start, end, templates, getRestaurantFromContext(r).RotationAnchor, nil, hours)
+	if err := app.jsonResponse(w, r, http.StatusOK, plan.preview()); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
 // GetSchedule godoc
 //
 //	@Summary		Fetches a schedule
@@ -227,10 +272,9 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 			if cachedSchedule.RestaurantID == restaurantID {
 				// Verify user has access
 				user := getUserFromContext(r)
-				restaurant, err := app.store.Restaurants.GetByID(ctx, restaurantID)
-				if err == nil && restaurant.UserID == user.ID {
+				if err := app.checkScheduleAccess(ctx, restaurantID, user.ID); err == nil {
 					app.logger.Debugw("cache hit for schedule", "schedule_id", scheduleID)
-					err = app.jsonResponse(w, http.StatusOK, cachedSchedule)
+					err = app.jsonResponse(w, r, http.StatusOK, cachedSchedule)
 					if err != nil {
 						app.internalServerError(w, r, err)
 					}
@@ -242,8 +286,8 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 
 	// Cache miss or validation failed - get from database
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(ctx, restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkScheduleAccess(ctx, restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -252,13 +296,6 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get the schedule
 	schedule, err := app.store.Schedules.GetByID(ctx, scheduleID)
 	if err != nil {
@@ -285,7 +322,7 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, schedule)
+	err = app.jsonResponse(w, r, http.StatusOK, schedule)
 	if err != nil {
 		app.internalServerError(w, r, err)
 	}
@@ -294,7 +331,7 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 // UpdateSchedule godoc
 //
 //	@Summary		Updates a schedule
-//	@Description	Updates a schedule by ID
+//	@Description	Updates a schedule by ID. Shrinking its dates past some of its shifts is refused with 409 and the stranded_shifts listed, unless stranded_shifts is delete, which deletes them along with the update.
 //	@Tags			schedule
 //	@Accept			json
 //	@Produce		json
@@ -305,6 +342,7 @@ func (app *application) getScheduleHandler(w http.ResponseWriter, r *http.Reques
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/schedules/{id} [patch]
@@ -322,8 +360,8 @@ func (app *application) updateScheduleHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -332,13 +370,6 @@ func (app *application) updateScheduleHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing schedule
 	schedule, err := app.store.Schedules.GetByID(r.Context(), scheduleID)
 	if err != nil {
@@ -400,12 +431,41 @@ func (app *application) updateScheduleHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Shrinking the schedule mustn't leave shifts outside it
+	var stranded []*store.ScheduledShift
+	if startDate > schedule.StartDate || endDate < schedule.EndDate {
+		shifts, err := app.store.ScheduledShifts.ListBySchedule(r.Context(), schedule.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		stranded = strandedShifts(&store.Schedule{StartDate: startDate, EndDate: endDate}, shifts)
+	}
+
+	if len(stranded) > 0 && payload.StrandedShifts != strandedShiftsDelete {
+		app.strandedShiftsResponse(w, r, stranded)
+		return
+	}
+
 	// Set validated dates
 	schedule.StartDate = startDate
 	schedule.EndDate = endDate
+	if payload.Note != nil {
+		schedule.Note = *payload.Note
+	}
 
-	// Save updates
-	if err := app.store.Schedules.Update(r.Context(), schedule); err != nil {
+	// Save updates, deleting the stranded shifts along the way
+	if len(stranded) > 0 {
+		err = app.shrinkSchedule(r.Context(), user.ID, schedule, stranded)
+	} else {
+		err = app.store.Schedules.Update(r.Context(), schedule)
+	}
+	if err != nil {
+		// a shift was moved outside the new dates meanwhile
+		if errors.Is(err, store.ErrShiftOutsideSchedule) {
+			app.conflictResponse(w, r, err)
+			return
+		}
 		app.internalServerError(w, r, err)
 		return
 	}
@@ -417,13 +477,46 @@ func (app *application) updateScheduleHandler(w http.ResponseWriter, r *http.Req
 		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, schedule)
+	err = app.jsonResponse(w, r, http.StatusOK, schedule)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 }
 
+// strandedShifts returns the shifts falling outside the schedule's dates
+func strandedShifts(schedule *store.Schedule, shifts []*store.ScheduledShift) []*store.ScheduledShift {
+	stranded := []*store.ScheduledShift{}
+	for _, shift := range shifts {
+		if !schedule.Covers(shift.ShiftDate) {
+			stranded = append(stranded, shift)
+		}
+	}
+	return stranded
+}
+
+// shrinkSchedule saves the schedule's new dates, deleting the shifts they no
+// longer cover and recording each deletion like any other
+func (app *application) shrinkSchedule(ctx context.Context, userID int64, schedule *store.Schedule, stranded []*store.ScheduledShift) error {
+	deletedIDs, err := app.store.Schedules.Shrink(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	deleted := make(map[int64]bool, len(deletedIDs))
+	for _, id := range deletedIDs {
+		deleted[id] = true
+	}
+	for _, shift := range stranded {
+		if deleted[shift.ID] {
+			app.auditShiftChanged(ctx, userID, shift, nil)
+			app.notifyShiftChanged(ctx, shift, nil)
+		}
+	}
+
+	return nil
+}
+
 // DeleteSchedule godoc
 //
 //	@Summary		Deletes a schedule
@@ -453,8 +546,8 @@ func (app *application) deleteScheduleHandler(w http.ResponseWriter, r *http.Req
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -463,13 +556,6 @@ func (app *application) deleteScheduleHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing schedule to verify ownership
 	schedule, err := app.store.Schedules.GetByID(r.Context(), scheduleID)
 	if err != nil {
@@ -513,15 +599,18 @@ func (app *application) deleteScheduleHandler(w http.ResponseWriter, r *http.Req
 // PublishSchedule godoc
 //
 //	@Summary		Publishes a schedule
-//	@Description	Publishes a schedule to make it available to employees
+//	@Description	Publishes a schedule to make it available to employees. When the restaurant has a weekly labor budget and the schedule's projected cost is over it, publishing is refused with 409 and the labor cost breakdown; with force=true it publishes anyway and answers 200 with the breakdown as a warning. Shifts breaking a compliance rule set to block refuse publishing with 409 and the violations, whatever force says; rules set to warn publish and answer 200 with compliance_warnings.
 //	@Tags			schedule
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurant_id	path		int	true	"Restaurant ID"
-//	@Param			id				path		int	true	"Schedule ID"
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			id				path		int		true	"Schedule ID"
+//	@Param			force			query		bool	false	"Publish even when over the labor budget"
+//	@Success		200				{object}	PublishScheduleResult
 //	@Success		204				{object}	string
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/schedules/{id}/publish [post]
@@ -539,8 +628,8 @@ func (app *application) publishScheduleHandler(w http.ResponseWriter, r *http.Re
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -549,13 +638,6 @@ func (app *application) publishScheduleHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing schedule to verify ownership
 	schedule, err := app.store.Schedules.GetByID(r.Context(), scheduleID)
 	if err != nil {
@@ -579,6 +661,34 @@ func (app *application) publishScheduleHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Check the compliance rules; blocking violations refuse publishing even with force
+	violations, err := app.scheduleCompliance(r.Context(), schedule)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(blockingViolations(violations)) > 0 {
+		app.complianceViolationResponse(w, r, violations)
+		return
+	}
+
+	// Check the projected labor cost against the budget; force publishes over it
+	var overBudget *LaborCost
+	if restaurant := getRestaurantFromContext(r); restaurant.WeeklyLaborBudgetCents != nil {
+		cost, err := app.scheduleLaborCost(r.Context(), restaurant, schedule)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if cost.OverBudget() {
+			if r.URL.Query().Get("force") != "true" {
+				app.overBudgetResponse(w, r, cost)
+				return
+			}
+			overBudget = cost
+		}
+	}
+
 	// Publish schedule with current timestamp
 	publishTime := time.Now()
 	if err := app.store.Schedules.Publish(r.Context(), scheduleID, publishTime); err != nil {
@@ -599,9 +709,27 @@ func (app *application) publishScheduleHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
+	app.notifySchedulePublished(r.Context(), schedule, user.ID)
+
+	if overBudget != nil || len(violations) > 0 {
+		result := &PublishScheduleResult{LaborCost: overBudget, ComplianceWarnings: violations}
+		if err := app.jsonResponse(w, r, http.StatusOK, result); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// PublishScheduleResult carries the warnings of a schedule published despite them:
+// the labor cost breakdown when it was forced over budget, and the violations of
+// compliance rules set to warn
+type PublishScheduleResult struct {
+	*LaborCost
+	ComplianceWarnings []ComplianceViolation `json:"compliance_warnings,omitempty"`
+}
+
 // SendScheduleEmailPayload defines the request body for sending schedule emails
 type SendScheduleEmailPayload struct {
 	IncludeEvents bool `json:"include_events"`
@@ -612,7 +740,14 @@ type SendScheduleEmailResponse struct {
 	TotalRecipients int                        `json:"total_recipients"`
 	Successful      int                        `json:"successful"`
 	Failed          int                        `json:"failed"`
+	Queued          int                        `json:"queued,omitempty"` // held for employees' digests
 	Failures        []SendScheduleEmailFailure `json:"failures,omitempty"`
+	Quota           *cache.EmailQuota          `json:"quota,omitempty"`
+	// BlastID identifies the run in the schedule's email-blasts history
+	BlastID int64 `json:"blast_id,omitempty"`
+	// UnverifiedEmployeeIDs are the recipients whose address isn't verified yet;
+	// a restaurant that requires verification holds their emails as failures
+	UnverifiedEmployeeIDs []int64 `json:"unverified_employee_ids,omitempty"`
 }
 
 // SendScheduleEmailFailure describes a single email send failure
@@ -623,6 +758,18 @@ type SendScheduleEmailFailure struct {
 	Error        string `json:"error"`
 }
 
+// ScheduleEmailPreview is the schedule email an employee would receive, rendered
+// without sending it
+type ScheduleEmailPreview struct {
+	// Preview is always true; a preview is never sent or recorded as a delivery
+	Preview    bool        `json:"preview"`
+	EmployeeID int64       `json:"employee_id"`
+	Email      string      `json:"email"`
+	Locale     i18n.Locale `json:"locale"`
+	Subject    string      `json:"subject"`
+	HTML       string      `json:"html"`
+}
+
 // ScheduleEmailData contains all data needed for the schedule email template
 type ScheduleEmailData struct {
 	RestaurantName string
@@ -631,8 +778,17 @@ type ScheduleEmailData struct {
 	ScheduleEnd    string
 	Shifts         []ScheduleEmailShift
 	Events         []ScheduleEmailEvent
-	HasShifts      bool
-	HasEvents      bool
+	Hours          []ScheduleEmailHours
+	// ScheduleNote and DayNotes are sanitized by the restaurant's mailer.TextPolicy
+	ScheduleNote template.HTML
+	DayNotes     []ScheduleEmailDayNote
+	Holidays     []ScheduleEmailHoliday
+	HasShifts    bool
+	HasEvents    bool
+	HasHours     bool
+	HasDayNotes  bool
+	HasHolidays  bool
+	Branding     mailer.RenderedBranding
 }
 
 // ScheduleEmailShift represents a shift in the email
@@ -642,43 +798,49 @@ type ScheduleEmailShift struct {
 	EndTime   string
 	RoleName  string
 	RoleColor string
-	Notes     string
+	// Notes is sanitized by the restaurant's mailer.TextPolicy
+	Notes template.HTML
+	// Training shifts are worked alongside TrainerName, when a trainer shift is linked
+	Training    bool
+	TrainerName string
 }
 
 // ScheduleEmailEvent represents an event in the email
 type ScheduleEmailEvent struct {
 	Date        string
 	Title       string
-	Description string
+	Description template.HTML
 	StartTime   string
 	EndTime     string
 }
 
-// formatDateForDisplay formats a DateOnly for human-readable display
-func formatDateForDisplay(d store.DateOnly) string {
-	t, err := time.Parse("2006-01-02", string(d))
-	if err != nil {
-		return string(d)
-	}
-	return t.Format("Mon, Jan 2, 2006")
+// ScheduleEmailDayNote represents a note on one day of the schedule in the email
+type ScheduleEmailDayNote struct {
+	Date string
+	Note template.HTML
 }
 
-// formatShiftDateForDisplay formats a time.Time for shift display (e.g., "Monday, Jan 2")
-func formatShiftDateForDisplay(t time.Time) string {
-	return t.Format("Monday, Jan 2")
+// ScheduleEmailHoliday represents a public holiday during the schedule
+type ScheduleEmailHoliday struct {
+	Date string
+	Name string
 }
 
-// formatTimeForDisplay formats a TimeOfDay for human-readable display (e.g., "9:00 AM")
-func formatTimeForDisplay(t store.TimeOfDay) string {
-	parsed, err := time.Parse("15:04:05", string(t))
-	if err != nil {
-		// Try HH:MM format
-		parsed, err = time.Parse("15:04", string(t))
-		if err != nil {
-			return string(t)
-		}
+// ScheduleEmailHours represents the restaurant's hours on one day of the schedule
+type ScheduleEmailHours struct {
+	Date      string
+	Closed    bool
+	OpenTime  string
+	CloseTime string
+	Note      string
+}
+
+// emailTextPolicy picks how user-provided text is rendered in emails
+func emailTextPolicy(markdown bool) mailer.TextPolicy {
+	if markdown {
+		return mailer.TextMarkdown
 	}
-	return parsed.Format("3:04 PM")
+	return mailer.TextEscaped
 }
 
 // filterShiftsForEmployee returns only shifts assigned to the given employee
@@ -692,68 +854,191 @@ func filterShiftsForEmployee(shifts []*store.ScheduledShift, employeeID int64) [
 	return result
 }
 
-// transformShiftsForEmail converts ScheduledShifts to email-friendly format
-func transformShiftsForEmail(shifts []*store.ScheduledShift) []ScheduleEmailShift {
+// transformShiftsForEmail converts ScheduledShifts to email-friendly format; allShifts resolves trainer names
+func transformShiftsForEmail(shifts, allShifts []*store.ScheduledShift, locale i18n.Locale, text mailer.TextPolicy) []ScheduleEmailShift {
+	trainers := make(map[int64]string)
+	for _, s := range allShifts {
+		if s.EmployeeName != nil {
+			trainers[s.ID] = mailer.PlainText(*s.EmployeeName)
+		}
+	}
+
 	result := make([]ScheduleEmailShift, 0, len(shifts))
 	for _, s := range shifts {
-		result = append(result, ScheduleEmailShift{
-			Date:      formatShiftDateForDisplay(s.ShiftDate),
-			StartTime: formatTimeForDisplay(s.StartTime),
-			EndTime:   formatTimeForDisplay(s.EndTime),
-			RoleName:  s.RoleName,
+		shift := ScheduleEmailShift{
+			Date:      i18n.FormatWeekday(locale, s.ShiftDate),
+			StartTime: i18n.FormatTimeOfDay(locale, string(s.StartTime)),
+			EndTime:   i18n.FormatTimeOfDay(locale, string(s.EndTime)),
+			RoleName:  mailer.PlainText(s.RoleName),
 			RoleColor: s.RoleColor,
-			Notes:     s.Notes,
-		})
+			Notes:     text.HTML(s.Notes),
+			Training:  s.Training,
+		}
+		if s.TrainerShiftID != nil {
+			shift.TrainerName = trainers[*s.TrainerShiftID]
+		}
+		result = append(result, shift)
 	}
 	return result
 }
 
 // transformEventsForEmail converts Events to email-friendly format
-func transformEventsForEmail(events []*store.Event) []ScheduleEmailEvent {
+func transformEventsForEmail(events []*store.Event, locale i18n.Locale, text mailer.TextPolicy) []ScheduleEmailEvent {
 	if events == nil {
 		return nil
 	}
 	result := make([]ScheduleEmailEvent, 0, len(events))
 	for _, e := range events {
 		result = append(result, ScheduleEmailEvent{
-			Date:        formatDateForDisplay(e.Date),
-			Title:       e.Title,
-			Description: e.Description,
-			StartTime:   formatTimeForDisplay(e.StartTime),
-			EndTime:     formatTimeForDisplay(e.EndTime),
+			Date:        i18n.FormatDay(locale, string(e.Date)),
+			Title:       mailer.PlainText(e.Title),
+			Description: text.HTML(e.Description),
+			StartTime:   i18n.FormatTimeOfDay(locale, string(e.StartTime)),
+			EndTime:     i18n.FormatTimeOfDay(locale, string(e.EndTime)),
+		})
+	}
+	return result
+}
+
+// transformDayNotesForEmail converts a schedule's day notes to email-friendly format
+func transformDayNotesForEmail(notes []*store.ScheduleDayNote, locale i18n.Locale, text mailer.TextPolicy) []ScheduleEmailDayNote {
+	result := make([]ScheduleEmailDayNote, 0, len(notes))
+	for _, n := range notes {
+		result = append(result, ScheduleEmailDayNote{
+			Date: i18n.FormatDay(locale, string(n.Date)),
+			Note: text.HTML(n.Note),
+		})
+	}
+	return result
+}
+
+// transformHolidaysForEmail lists the public holidays during a schedule
+func transformHolidaysForEmail(holidays []*store.HoursException, locale i18n.Locale) []ScheduleEmailHoliday {
+	result := make([]ScheduleEmailHoliday, 0, len(holidays))
+	for _, h := range holidays {
+		result = append(result, ScheduleEmailHoliday{
+			Date: i18n.FormatDay(locale, string(h.Date)),
+			Name: mailer.PlainText(h.Name),
 		})
 	}
 	return result
 }
 
-// buildScheduleEmailData builds the email data structure for an employee
+// buildScheduleEmailData builds the email data structure for an employee; user-provided
+// text is reduced to plain text or rendered by the text policy before it reaches the template
 func buildScheduleEmailData(
 	employee *store.Employee,
 	allShifts []*store.ScheduledShift,
 	events []*store.Event,
 	restaurantName string,
 	schedule *store.Schedule,
+	locale i18n.Locale,
+	text mailer.TextPolicy,
 ) *ScheduleEmailData {
 	employeeShifts := filterShiftsForEmployee(allShifts, employee.ID)
-	emailShifts := transformShiftsForEmail(employeeShifts)
-	emailEvents := transformEventsForEmail(events)
+	emailShifts := transformShiftsForEmail(employeeShifts, allShifts, locale, text)
+	emailEvents := transformEventsForEmail(events, locale, text)
 
 	return &ScheduleEmailData{
-		RestaurantName: restaurantName,
-		EmployeeName:   employee.FullName,
-		ScheduleStart:  formatDateForDisplay(schedule.StartDate),
-		ScheduleEnd:    formatDateForDisplay(schedule.EndDate),
+		RestaurantName: mailer.PlainText(restaurantName),
+		EmployeeName:   mailer.PlainText(employee.FullName),
+		ScheduleStart:  i18n.FormatDay(locale, string(schedule.StartDate)),
+		ScheduleEnd:    i18n.FormatDay(locale, string(schedule.EndDate)),
 		Shifts:         emailShifts,
 		Events:         emailEvents,
+		ScheduleNote:   text.HTML(schedule.Note),
 		HasShifts:      len(emailShifts) > 0,
 		HasEvents:      len(emailEvents) > 0,
 	}
 }
 
+// scheduleEmail is what a schedule's emails are built from, loaded once for every recipient
+type scheduleEmail struct {
+	restaurant *store.Restaurant
+	schedule   *store.Schedule
+	shifts     []*store.ScheduledShift
+	events     []*store.Event
+	hours      []*store.EffectiveHours
+	holidays   []*store.HoursException
+	dayNotes   []*store.ScheduleDayNote
+	branding   mailer.Branding
+}
+
+// loadScheduleEmail gathers the schedule's events, when included, and the week's
+// hours, day notes and the restaurant's email customization around its shifts
+func (app *application) loadScheduleEmail(ctx context.Context, restaurant *store.Restaurant, schedule *store.Schedule, shifts []*store.ScheduledShift, includeEvents bool) (*scheduleEmail, error) {
+	content := &scheduleEmail{restaurant: restaurant, schedule: schedule, shifts: shifts}
+
+	var err error
+	if includeEvents {
+		content.events, err = app.store.Events.ListByRestaurantAndDateRange(ctx, restaurant.ID, schedule.StartDate, schedule.EndDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content.branding, err = app.scheduleEmailBranding(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restaurant hours for the week, including holiday closures
+	scheduleStart, err := parseFlexibleDate(string(schedule.StartDate))
+	if err != nil {
+		return nil, err
+	}
+	scheduleEnd, err := parseFlexibleDate(string(schedule.EndDate))
+	if err != nil {
+		return nil, err
+	}
+	hours, exceptions, err := app.loadOperatingHours(ctx, restaurant.ID, scheduleStart, scheduleEnd)
+	if err != nil {
+		return nil, err
+	}
+	content.hours = effectiveHours(hours, exceptions, scheduleStart, scheduleEnd)
+	for _, ex := range exceptions {
+		if ex.HolidayRegion != "" {
+			content.holidays = append(content.holidays, ex)
+		}
+	}
+
+	dayNotes, err := app.store.ScheduleNotes.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	content.dayNotes = dayNotesInSchedule(dayNotes, schedule)
+
+	return content, nil
+}
+
+// scheduleEmailFor builds one employee's schedule email in their locale. A
+// customization that fails to render falls back to the default subject and header.
+func (app *application) scheduleEmailFor(content *scheduleEmail, employee *store.Employee, locale i18n.Locale) *ScheduleEmailData {
+	text := app.config.mail.userText
+	data := buildScheduleEmailData(employee, content.shifts, content.events, content.restaurant.Name, content.schedule, locale, text)
+	data.Hours = transformHoursForEmail(content.hours, locale)
+	data.HasHours = len(data.Hours) > 0
+	data.DayNotes = transformDayNotesForEmail(content.dayNotes, locale, text)
+	data.HasDayNotes = len(data.DayNotes) > 0
+	data.Holidays = transformHolidaysForEmail(content.holidays, locale)
+	data.HasHolidays = len(data.Holidays) > 0
+
+	branding, err := content.branding.Render(brandingVars(data))
+	if err != nil {
+		app.logger.Warnw("failed to render email customization, using defaults",
+			"restaurant_id", content.restaurant.ID,
+			"error", err,
+		)
+	}
+	data.Branding = branding
+
+	return data
+}
+
 // SendScheduleEmail godoc
 //
 //	@Summary		Sends schedule emails to all employees
-//	@Description	Sends the schedule via email to all employees in the restaurant
+//	@Description	Sends the schedule via email to all employees in the restaurant. The run and the recipients it failed to reach are kept in the schedule's email-blasts history, and send-email/retry-failures re-sends to those. Recipients whose address isn't verified are listed in unverified_employee_ids; a restaurant with require_email_verification holds their emails as failures.
 //	@Tags			schedule
 //	@Accept			json
 //	@Produce		json
@@ -764,6 +1049,7 @@ func buildScheduleEmailData(
 //	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
 //	@Failure		500				{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/restaurants/{restaurant_id}/schedules/{id}/send-email [post]
@@ -782,9 +1068,10 @@ func (app *application) sendScheduleEmailHandler(w http.ResponseWriter, r *http.
 
 	ctx := r.Context()
 
-	// Verify restaurant ownership
-	restaurant, err := app.store.Restaurants.GetByID(ctx, restaurantID)
-	if err != nil {
+	// Verify restaurant ownership; the restaurant itself is already loaded by the context middleware
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(ctx, restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -793,12 +1080,6 @@ func (app *application) sendScheduleEmailHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get and validate schedule
 	schedule, err := app.store.Schedules.GetByID(ctx, scheduleID)
 	if err != nil {
@@ -839,22 +1120,115 @@ func (app *application) sendScheduleEmailHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	var events []*store.Event
-	if payload.IncludeEvents {
-		events, err = app.store.Events.ListByRestaurantAndDateRange(
-			ctx,
-			restaurantID,
-			schedule.StartDate,
-			schedule.EndDate,
-		)
-		if err != nil {
-			app.internalServerError(w, r, err)
+	content, err := app.loadScheduleEmail(ctx, restaurant, schedule, shifts, payload.IncludeEvents)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, restaurantID)
+	if !ok {
+		return
+	}
+
+	response := app.sendScheduleEmails(ctx, content, employees, user.Locale)
+	response.Quota = quota
+	response.BlastID = app.recordEmailBlast(ctx, schedule, payload.IncludeEvents, nil, response)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RetryScheduleEmailFailures godoc
+//
+//	@Summary		Re-sends a schedule's email to the recipients it failed to reach
+//	@Description	Sends the schedule email again to only the employees the schedule's last send-email run (or the last retry) failed to reach, with that run's include_events, and records the retry in the schedule's email-blasts history. Employees who were removed since are skipped; ones still without an address or with a bounced one fail again.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{object}	SendScheduleEmailResponse
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/send-email/retry-failures [post]
+func (app *application) retryScheduleEmailFailuresHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	ctx := r.Context()
+
+	last, err := app.store.EmailDeliveries.LatestBlast(ctx, schedule.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("the schedule has not been emailed"))
 			return
 		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if len(last.Failures) == 0 {
+		app.conflictResponse(w, r, errors.New("the last schedule email reached every recipient"))
+		return
+	}
+
+	ids := make([]int64, 0, len(last.Failures))
+	for _, failure := range last.Failures {
+		ids = append(ids, failure.EmployeeID)
+	}
+	found, err := app.store.Employees.GetByIDs(ctx, ids)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	employees := make([]*store.Employee, 0, len(found))
+	for _, employee := range found {
+		if employee.RestaurantID == restaurant.ID && employee.ErasedAt == nil {
+			employees = append(employees, employee)
+		}
+	}
+	if len(employees) == 0 {
+		app.conflictResponse(w, r, errors.New("the recipients the last schedule email failed to reach were removed"))
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	content, err := app.loadScheduleEmail(ctx, restaurant, schedule, shifts, last.IncludeEvents)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, restaurant.ID)
+	if !ok {
+		return
 	}
 
-	// Send emails
-	isProdEnv := app.config.env == "production"
+	response := app.sendScheduleEmails(ctx, content, employees, user.Locale)
+	response.Quota = quota
+	response.BlastID = app.recordEmailBlast(ctx, schedule, last.IncludeEvents, &last.ID, response)
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// sendScheduleEmails emails each employee their schedule, in their language or
+// else the owner's, and reports who it reached. Employees without an address or
+// whose address bounced count as failed.
+func (app *application) sendScheduleEmails(ctx context.Context, content *scheduleEmail, employees []*store.Employee, ownerLocale *string) SendScheduleEmailResponse {
 	response := SendScheduleEmailResponse{
 		TotalRecipients: len(employees),
 		Failures:        []SendScheduleEmailFailure{},
@@ -873,20 +1247,32 @@ func (app *application) sendScheduleEmailHandler(w http.ResponseWriter, r *http.
 			continue
 		}
 
-		emailData := buildScheduleEmailData(
-			employee,
-			shifts,
-			events,
-			restaurant.Name,
-			schedule,
-		)
+		if employee.EmailVerifiedAt == nil {
+			response.UnverifiedEmployeeIDs = append(response.UnverifiedEmployeeIDs, employee.ID)
+			if content.restaurant.RequireEmailVerification {
+				response.Failed++
+				response.Failures = append(response.Failures, SendScheduleEmailFailure{
+					EmployeeID:   employee.ID,
+					EmployeeName: employee.FullName,
+					Email:        employee.Email,
+					Error:        errEmailUnverified.Error(),
+				})
+				continue
+			}
+		}
+
+		// Employees without a language of their own get the owner's
+		locale := i18n.Resolve(employee.Locale, ownerLocale)
+
+		emailData := app.scheduleEmailFor(content, employee, locale)
 
-		_, err := app.mailer.Send(
-			mailer.ScheduleNotificationTemplate,
-			employee.FullName,
-			employee.Email,
+		err := app.sendTrackedScheduleEmail(
+			ctx,
+			content.schedule,
+			store.EmailKindSchedule,
+			employee,
+			mailer.Localized(mailer.ScheduleNotificationTemplate, locale),
 			emailData,
-			!isProdEnv,
 		)
 
 		if err != nil {
@@ -908,7 +1294,112 @@ func (app *application) sendScheduleEmailHandler(w http.ResponseWriter, r *http.
 		response.Successful++
 	}
 
-	if err := app.jsonResponse(w, http.StatusOK, response); err != nil {
+	return response
+}
+
+// recordEmailBlast keeps a send-email run in the schedule's blast history,
+// returning its ID. The emails are out either way, so a failure is only logged.
+func (app *application) recordEmailBlast(ctx context.Context, schedule *store.Schedule, includeEvents bool, retryOf *int64, response SendScheduleEmailResponse) int64 {
+	blast := &store.EmailBlast{
+		ScheduleID:      schedule.ID,
+		RestaurantID:    schedule.RestaurantID,
+		RetryOf:         retryOf,
+		IncludeEvents:   includeEvents,
+		TotalRecipients: response.TotalRecipients,
+		Successful:      response.Successful,
+		Failed:          response.Failed,
+		Failures:        make([]*store.EmailBlastFailure, 0, len(response.Failures)),
+	}
+	for _, failure := range response.Failures {
+		blast.Failures = append(blast.Failures, &store.EmailBlastFailure{
+			EmployeeID:   failure.EmployeeID,
+			EmployeeName: failure.EmployeeName,
+			Email:        failure.Email,
+			Error:        failure.Error,
+		})
+	}
+
+	if err := app.store.EmailDeliveries.CreateBlast(ctx, blast); err != nil {
+		app.logger.Warnw("failed to record schedule email blast", "schedule_id", schedule.ID, "error", err)
+		return 0
+	}
+	return blast.ID
+}
+
+// PreviewScheduleEmail godoc
+//
+//	@Summary		Previews an employee's schedule email
+//	@Description	Renders the subject and HTML the employee would receive from send-email, in their language and with the restaurant's email customization, without sending anything or using the email quota. The response is marked preview: true.
+//	@Tags			schedule
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			employee_id		query		int		true	"Employee ID"
+//	@Param			include_events	query		bool	false	"Include the week's events, as send-email's include_events does"
+//	@Success		200				{object}	ScheduleEmailPreview
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/email-preview [get]
+func (app *application) previewScheduleEmailHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	ctx := r.Context()
+
+	employeeID, err := strconv.ParseInt(r.URL.Query().Get("employee_id"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("employee_id must be an employee ID"))
+		return
+	}
+
+	employee, err := app.store.Employees.GetByID(ctx, employeeID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if employee.RestaurantID != restaurant.ID {
+		app.notFoundResponse(w, r, errors.New("employee not found"))
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	content, err := app.loadScheduleEmail(ctx, restaurant, schedule, shifts, r.URL.Query().Get("include_events") == "true")
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	locale := i18n.Resolve(employee.Locale, user.Locale)
+	subject, body, err := mailer.Render(mailer.Localized(mailer.ScheduleNotificationTemplate, locale), app.scheduleEmailFor(content, employee, locale))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	preview := &ScheduleEmailPreview{
+		Preview:    true,
+		EmployeeID: employee.ID,
+		Email:      employee.Email,
+		Locale:     locale,
+		Subject:    subject,
+		HTML:       body,
+	}
+	if err := app.jsonResponse(w, r, http.StatusOK, preview); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
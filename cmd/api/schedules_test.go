@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestUpdateScheduleDates(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T) (*application, *[]int64, *bool) {
+		app, _ := newMockedApplication(t, testUserID)
+		var audited []int64
+		updated := false
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 1, StartDate: "2026-03-02", EndDate: "2026-03-08"}, nil
+			},
+			UpdateFunc: func(context.Context, *store.Schedule) error {
+				updated = true
+				return nil
+			},
+			ShrinkFunc: func(_ context.Context, schedule *store.Schedule) ([]int64, error) {
+				if schedule.StartDate != "2026-03-03" {
+					t.Errorf("shrunk to %s, want 2026-03-03", schedule.StartDate)
+				}
+				return []int64{10}, nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(_ context.Context, scheduleID int64) ([]*store.ScheduledShift, error) {
+				return []*store.ScheduledShift{
+					{ID: 10, ScheduleID: scheduleID, ShiftDate: monday},
+					{ID: 11, ScheduleID: scheduleID, ShiftDate: monday.AddDate(0, 0, 1)},
+				}, nil
+			},
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(_ context.Context, entries []*store.AuditEntry) error {
+				for _, e := range entries {
+					audited = append(audited, e.EntityID)
+				}
+				return nil
+			},
+		}
+		return app, &audited, &updated
+	}
+
+	t.Run("shrinking past shifts lists them", func(t *testing.T) {
+		app, _, updated := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5", `{"start_date": "2026-03-03"}`), app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		var body struct {
+			Stranded []*store.ScheduledShift `json:"stranded_shifts"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Stranded) != 1 || body.Stranded[0].ID != 10 || *updated {
+			t.Errorf("stranded = %+v (updated %v), want shift 10 and nothing saved", body.Stranded, *updated)
+		}
+	})
+
+	t.Run("shrinking can delete the stranded shifts", func(t *testing.T) {
+		app, audited, _ := setup(t)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5", `{"start_date": "2026-03-03", "stranded_shifts": "delete"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*audited) != 1 || (*audited)[0] != 10 {
+			t.Errorf("audited %v, want shift 10's deletion", *audited)
+		}
+	})
+
+	t.Run("growing a schedule doesn't look at its shifts", func(t *testing.T) {
+		app, _, updated := setup(t)
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5", `{"end_date": "2026-03-09"}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if !*updated {
+			t.Error("schedule not saved")
+		}
+	})
+}
+
+func TestShiftOutsideSchedule(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+			return &store.ScheduledShift{ID: id, ScheduleID: 5, RestaurantID: 1, RoleID: 2, ShiftDate: time.Now().AddDate(0, 1, 0), StartTime: "09:00", EndTime: "17:00"}, nil
+		},
+		UpdateFunc: func(context.Context, *store.ScheduledShift) error { return store.ErrShiftOutsideSchedule },
+	}
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 1}, nil
+		},
+	}
+	app.store.OperatingHours = &store.MockOperatingHoursStorer{
+		GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+			return &store.OperatingHours{RestaurantID: restaurantID}, nil
+		},
+		ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+			return nil, nil
+		},
+	}
+
+	body := `{"shift_date": "` + time.Now().AddDate(0, 2, 0).Format(time.RFC3339) + `"}`
+	rr := executeRequest(authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5/shifts/42", body), app.mount())
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type sessionKey string
+
+const sessionIDCtx sessionKey = "session_id"
+
+const (
+	// maxUserAgentLength caps what is kept of a client's User-Agent
+	maxUserAgentLength = 512
+	// securityEventsLimit is how many of the latest security events are listed
+	securityEventsLimit = 50
+)
+
+// SessionView is a signed-in device; Current marks the one making the request
+type SessionView struct {
+	*store.Session
+	Current bool `json:"current"`
+}
+
+// sessionIDFromClaims is the token's sid claim, or 0 for tokens issued before sessions existed
+func sessionIDFromClaims(claims jwt.MapClaims) int64 {
+	sid, ok := claims["sid"].(float64)
+	if !ok {
+		return 0
+	}
+	return int64(sid)
+}
+
+// checkSession rejects a token whose session was signed out, returning the
+// session's ID. Tokens without one stay valid until they expire.
+func (app *application) checkSession(ctx context.Context, claims jwt.MapClaims, userID int64) (int64, error) {
+	sessionID := sessionIDFromClaims(claims)
+	if sessionID == 0 {
+		return 0, nil
+	}
+
+	if err := app.store.Sessions.Authenticate(ctx, sessionID, userID); err != nil {
+		return 0, err
+	}
+	return sessionID, nil
+}
+
+func getSessionIDFromContext(r *http.Request) int64 {
+	sessionID, _ := r.Context().Value(sessionIDCtx).(int64)
+	return sessionID
+}
+
+// clientDevice is the User-Agent and IP address a request came from
+func clientDevice(r *http.Request) (userAgent, ip string) {
+	userAgent = r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	ip = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return userAgent, ip
+}
+
+// startSession records a new signed-in device for the user and returns its token
+func (app *application) startSession(r *http.Request, user *store.User) (string, error) {
+	userAgent, ip := clientDevice(r)
+	session := &store.Session{
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IPAddress: ip,
+		ExpiresAt: time.Now().Add(app.config.auth.token.exp),
+	}
+	if err := app.store.Sessions.Create(r.Context(), session); err != nil {
+		return "", err
+	}
+
+	app.recordSecurityEvent(r, user.ID, store.SecurityEventSignIn, session.ID)
+
+	return app.generateTokenForUser(user, session.ID)
+}
+
+// recordSecurityEvent logs an account event without failing the request that caused it;
+// sessionID is 0 when the event isn't tied to a session
+func (app *application) recordSecurityEvent(r *http.Request, userID int64, eventType string, sessionID int64) {
+	userAgent, ip := clientDevice(r)
+	event := &store.SecurityEvent{
+		UserID:    userID,
+		Type:      eventType,
+		UserAgent: userAgent,
+		IPAddress: ip,
+	}
+	if sessionID != 0 {
+		event.SessionID = &sessionID
+	}
+
+	if err := app.store.SecurityEvents.Record(r.Context(), event); err != nil {
+		app.logger.Warnw("failed to record security event", "user_id", userID, "type", eventType, "error", err)
+	}
+}
+
+// listSessionsHandler godoc
+//
+//	@Summary		Lists the current user's signed-in devices
+//	@Description	Active sessions, most recently used first, with the device and IP address each signed in from
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{array}		SessionView
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/sessions [get]
+func (app *application) listSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	sessions, err := app.store.Sessions.ListActive(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	current := getSessionIDFromContext(r)
+	views := make([]SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, SessionView{Session: session, Current: session.ID == current})
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, views); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// revokeSessionHandler godoc
+//
+//	@Summary		Signs a device out
+//	@Description	Revokes the session; its token stops working on the next request
+//	@Tags			users
+//	@Param			sessionID	path	int	true	"Session ID"
+//	@Success		204			"No Content"
+//	@Failure		400			{object}	error
+//	@Failure		401			{object}	error
+//	@Failure		404			{object}	error
+//	@Failure		500			{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/sessions/{sessionID} [delete]
+func (app *application) revokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := strconv.ParseInt(chi.URLParam(r, "sessionID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid session ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	if err := app.store.Sessions.Revoke(r.Context(), user.ID, sessionID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("session not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.ID, store.SecurityEventSessionRevoked, sessionID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listSecurityEventsHandler godoc
+//
+//	@Summary		Lists the current user's recent security events
+//	@Description	The latest 50 sign-ins and security changes on the account, newest first, with the device each came from
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{array}		store.SecurityEvent
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/security-events [get]
+func (app *application) listSecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	events, err := app.store.SecurityEvents.ListByUser(r.Context(), user.ID, securityEventsLimit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, events); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/auth"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestSessions(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	// the test authenticator drops claims, and these tests need the sid one
+	app.authenticator = auth.NewJWTAuthenticator("session-test", "resa", "resa")
+	app.config.auth.token = tokenConfig{secret: "session-test", exp: time.Hour, iss: "resa", aud: "resa"}
+
+	revoked := map[int64]bool{8: true}
+	var events []string
+	app.store.Sessions = &store.MockSessionStorer{
+		AuthenticateFunc: func(_ context.Context, sessionID, userID int64) error {
+			if revoked[sessionID] || userID != testUserID {
+				return store.ErrNotFound
+			}
+			return nil
+		},
+		ListActiveFunc: func(context.Context, int64) ([]*store.Session, error) {
+			return []*store.Session{{ID: 7, UserAgent: "Firefox"}, {ID: 9, UserAgent: "Safari"}}, nil
+		},
+		RevokeFunc: func(_ context.Context, userID, sessionID int64) error {
+			if sessionID != 9 {
+				return store.ErrNotFound
+			}
+			revoked[sessionID] = true
+			return nil
+		},
+	}
+	app.store.SecurityEvents = &store.MockSecurityEventStorer{
+		RecordFunc: func(_ context.Context, event *store.SecurityEvent) error {
+			events = append(events, event.Type)
+			return nil
+		},
+	}
+
+	request := func(t *testing.T, method, target string, sessionID int64) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := app.generateTokenForUser(&store.User{ID: testUserID}, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return executeRequest(req, app.mount())
+	}
+
+	t.Run("marks the current session", func(t *testing.T) {
+		rr := request(t, http.MethodGet, "/v1/users/me/sessions", 7)
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data []SessionView `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if len(body.Data) != 2 || !body.Data[0].Current || body.Data[1].Current {
+			t.Errorf("sessions = %+v, want only the first current", body.Data)
+		}
+	})
+
+	t.Run("a revoked session's token stops working", func(t *testing.T) {
+		rr := request(t, http.MethodGet, "/v1/users/me/sessions", 8)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("signs another device out", func(t *testing.T) {
+		rr := request(t, http.MethodDelete, "/v1/users/me/sessions/9", 7)
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if len(events) != 1 || events[0] != store.SecurityEventSessionRevoked {
+			t.Errorf("events = %v", events)
+		}
+
+		rr = request(t, http.MethodGet, "/v1/users/me/sessions", 9)
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+
+		rr = request(t, http.MethodDelete, "/v1/users/me/sessions/3", 7)
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/balebbae/RESA/internal/env"
+	"github.com/balebbae/RESA/internal/features"
+	"github.com/balebbae/RESA/internal/ratelimiter"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap/zapcore"
+)
+
+// runtimeSettings are the parts of the configuration that can change while the
+// server runs. A reload builds a whole new value and swaps it in, so a request
+// never sees half of one.
+type runtimeSettings struct {
+	rateLimiter ratelimiter.Config
+	features    features.Config
+	logLevel    zapcore.Level
+	emailQuota  emailQuotaConfig
+	maintenance maintenanceConfig
+}
+
+// loadRuntimeSettings reads the hot-reloadable settings from the environment
+func loadRuntimeSettings() (*runtimeSettings, error) {
+	s := &runtimeSettings{
+		rateLimiter: ratelimiter.Config{
+			RequestPerTimeFrame: env.GetInt("RATELIMITER_REQUESTS_COUNT", 20),
+			TimeFrame:           time.Second * 5,
+			Enabled:             env.GetBool("RATE_LIMITER_ENABLED", true),
+		},
+		emailQuota: emailQuotaConfig{
+			limit:  env.GetInt("EMAIL_QUOTA_SENDS", 10),
+			window: time.Minute * time.Duration(env.GetInt("EMAIL_QUOTA_WINDOW_MINUTES", 60)),
+		},
+	}
+
+	var err error
+	if s.features, err = loadFeatureConfig(); err != nil {
+		return nil, err
+	}
+	if s.logLevel, err = zapcore.ParseLevel(env.GetString("LOG_LEVEL", "info")); err != nil {
+		return nil, err
+	}
+	if s.maintenance, err = loadMaintenanceConfig(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// changed names the settings that differ between s and next
+func (s *runtimeSettings) changed(next *runtimeSettings) []string {
+	changed := []string{}
+	if s.rateLimiter != next.rateLimiter {
+		changed = append(changed, "rate_limiter")
+	}
+	if !reflect.DeepEqual(s.features, next.features) {
+		changed = append(changed, "features")
+	}
+	if s.logLevel != next.logLevel {
+		changed = append(changed, "log_level")
+	}
+	if s.emailQuota != next.emailQuota {
+		changed = append(changed, "email_quota")
+	}
+	if s.maintenance != next.maintenance {
+		changed = append(changed, "maintenance")
+	}
+	return changed
+}
+
+// settings returns the runtime settings in effect. Before any are stored,
+// rate limiting and the email quota are off and the log level is info.
+func (app *application) settings() *runtimeSettings {
+	if s := app.runtime.Load(); s != nil {
+		return s
+	}
+	return &runtimeSettings{}
+}
+
+// onSettingsChange registers fn to be told about every reload that changes a setting,
+// for subsystems that keep their own copy of one. Register before the server starts.
+func (app *application) onSettingsChange(fn func(old, new *runtimeSettings)) {
+	app.settingsWatchers = append(app.settingsWatchers, fn)
+}
+
+// reloadSettings re-reads the env file and the environment, swaps in the new
+// settings and notifies the watchers, returning the names of those that changed.
+// Settings that fail to parse leave the current ones in place.
+func (app *application) reloadSettings() ([]string, error) {
+	app.reloadMu.Lock()
+	defer app.reloadMu.Unlock()
+
+	if app.envFile != nil {
+		if err := app.envFile.load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	next, err := loadRuntimeSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	old := app.settings()
+	changed := old.changed(next)
+	app.runtime.Store(next)
+	if len(changed) > 0 {
+		for _, fn := range app.settingsWatchers {
+			fn(old, next)
+		}
+	}
+
+	app.logger.Infow("settings reloaded", "changed", strings.Join(changed, ","))
+	return changed, nil
+}
+
+// reloadOnSIGHUP reloads the settings whenever the process receives SIGHUP
+func (app *application) reloadOnSIGHUP() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		if _, err := app.reloadSettings(); err != nil {
+			app.logger.Errorw("failed to reload settings", "error", err)
+		}
+	}
+}
+
+// reloadSettingsHandler reloads the settings on demand, for deployments where
+// signalling the process is awkward, and lists the ones that changed
+func (app *application) reloadSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	changed, err := app.reloadSettings()
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, map[string][]string{"changed": changed}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// envFile is a .env file that can be read again. Variables already in the process
+// environment when it was first read win over the file, on every read.
+type envFile struct {
+	path  string
+	fixed map[string]bool
+	// set are the variables the last read took from the file
+	set map[string]bool
+}
+
+func newEnvFile(path string) *envFile {
+	fixed := map[string]bool{}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		fixed[key] = true
+	}
+	return &envFile{path: path, fixed: fixed}
+}
+
+func (f *envFile) load() error {
+	vars, err := godotenv.Read(f.path)
+	if err != nil {
+		return err
+	}
+	set := map[string]bool{}
+	for key, value := range vars {
+		if f.fixed[key] {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+		set[key] = true
+	}
+
+	// a variable removed from the file goes back to its default
+	for key := range f.set {
+		if !set[key] {
+			os.Unsetenv(key)
+		}
+	}
+	f.set = set
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/features"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReloadSettings(t *testing.T) {
+	t.Setenv("RATELIMITER_REQUESTS_COUNT", "20")
+	t.Setenv("LOG_LEVEL", "info")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	write := func(contents string) {
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("EMAIL_QUOTA_SENDS=5\n")
+
+	app := newTestApplication(t)
+	app.envFile = newEnvFile(path)
+	t.Cleanup(func() { os.Unsetenv("EMAIL_QUOTA_SENDS"); os.Unsetenv("FEATURES_ENABLED") })
+
+	var notified []*runtimeSettings
+	app.onSettingsChange(func(_, s *runtimeSettings) { notified = append(notified, s) })
+
+	if _, err := app.reloadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if got := app.settings().emailQuota.limit; got != 5 {
+		t.Errorf("email quota = %d, want 5 from the file", got)
+	}
+
+	// the process environment wins over the file
+	write("EMAIL_QUOTA_SENDS=8\nRATELIMITER_REQUESTS_COUNT=99\nFEATURES_ENABLED=api_keys\n")
+	changed, err := app.reloadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(changed, ",") != "features,email_quota" {
+		t.Errorf("changed = %v, want features and email_quota", changed)
+	}
+	s := app.settings()
+	if s.emailQuota.limit != 8 || s.rateLimiter.RequestPerTimeFrame != 20 || len(s.features.Enabled) != 1 || s.features.Enabled[0] != features.APIKeys {
+		t.Errorf("settings = %+v", s)
+	}
+	if len(notified) != 2 || notified[1] != s {
+		t.Errorf("watchers saw %d reloads, want both that changed something", len(notified))
+	}
+
+	// a broken setting keeps the ones in effect
+	t.Setenv("LOG_LEVEL", "loud")
+	if _, err := app.reloadSettings(); err == nil {
+		t.Error("want an error for an unknown log level")
+	}
+	if app.settings() != s {
+		t.Error("a failed reload replaced the settings")
+	}
+
+	// removing a variable from the file restores its default
+	t.Setenv("LOG_LEVEL", "debug")
+	write("")
+	if _, err := app.reloadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if s := app.settings(); s.emailQuota.limit != 10 || s.logLevel != zapcore.DebugLevel || len(s.features.Enabled) != 0 {
+		t.Errorf("settings = %+v, want the defaults back and debug logging", s)
+	}
+}
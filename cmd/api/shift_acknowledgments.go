@@ -0,0 +1,332 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+// ScheduleAcknowledgmentReport is how many of a published schedule's assigned
+// shifts their employees have acknowledged
+type ScheduleAcknowledgmentReport struct {
+	ScheduleID                int64                              `json:"schedule_id"`
+	AssignedShifts            int                                `json:"assigned_shifts"`
+	Acknowledged              int                                `json:"acknowledged"`
+	Shifts                    []*store.ShiftAcknowledgmentStatus `json:"shifts"`
+	UnacknowledgedEmployeeIDs []int64                            `json:"unacknowledged_employee_ids"`
+}
+
+// ShiftAcknowledgmentReminderEmailData contains the data for the acknowledgment reminder email template
+type ShiftAcknowledgmentReminderEmailData struct {
+	RestaurantName string
+	EmployeeName   string
+	ScheduleStart  string
+	ScheduleEnd    string
+	Shifts         []ScheduleEmailShift
+	PortalURL      string
+}
+
+// GetMyShifts godoc
+//
+//	@Summary		Lists the signed-in employee's upcoming shifts
+//	@Description	Returns the shifts on published schedules assigned to any employee record with the signed-in user's email, from today (or from) on, with when each was acknowledged
+//	@Tags			employee portal
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"First shift date (YYYY-MM-DD, default today)"
+//	@Success		200		{array}		store.EmployeeShift
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/employee/me/shifts [get]
+func (app *application) getMyShiftsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	from := time.Now().Format("2006-01-02")
+	if v := r.URL.Query().Get("from"); v != "" {
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			app.badRequestResponse(w, r, errors.New("from must be a date (YYYY-MM-DD)"))
+			return
+		}
+		from = v
+	}
+
+	shifts, err := app.store.ShiftAcknowledgments.ListUpcomingForEmail(r.Context(), user.Email, store.DateOnly(from))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, shifts); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AcknowledgeShift godoc
+//
+//	@Summary		Acknowledges an assigned shift
+//	@Description	Confirms the signed-in employee has seen a shift assigned to them on a published schedule. Acknowledging again keeps the first time; moving or reassigning the shift asks for a new acknowledgment.
+//	@Tags			employee portal
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Shift ID"
+//	@Success		200	{object}	store.ShiftAcknowledgment
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/employee/me/shifts/{id}/acknowledge [post]
+func (app *application) acknowledgeShiftHandler(w http.ResponseWriter, r *http.Request) {
+	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	// Shifts of other employees, and ones not yet published, are not found
+	ack, err := app.store.ShiftAcknowledgments.Acknowledge(r.Context(), shiftID, user.Email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, ack); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetScheduleAcknowledgments godoc
+//
+//	@Summary		Reports shift acknowledgments for a schedule
+//	@Description	Lists every assigned shift of a published schedule with when its employee acknowledged it, and the employees with shifts still unacknowledged
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			id				path		int	true	"Schedule ID"
+//	@Success		200				{object}	ScheduleAcknowledgmentReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/schedules/{id}/acknowledgments [get]
+func (app *application) getScheduleAcknowledgmentsHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if schedule.PublishedAt == nil {
+		app.badRequestResponse(w, r, errScheduleNotPublished)
+		return
+	}
+
+	statuses, err := app.store.ShiftAcknowledgments.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, acknowledgmentReport(schedule.ID, statuses)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// RemindUnacknowledgedShifts godoc
+//
+//	@Summary		Reminds employees to acknowledge their shifts
+//	@Description	Emails each employee with upcoming shifts on the published schedule they haven't acknowledged, listing only those shifts. Employees who have acknowledged everything get nothing.
+//	@Tags			schedule
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			id				path		int	true	"Schedule ID"
+//	@Success		200				{object}	SendScheduleEmailResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		429				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/schedules/{id}/acknowledgments/remind [post]
+func (app *application) remindUnacknowledgedShiftsHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.restaurantScheduleFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	if schedule.PublishedAt == nil {
+		app.badRequestResponse(w, r, errScheduleNotPublished)
+		return
+	}
+
+	ctx := r.Context()
+
+	statuses, err := app.store.ShiftAcknowledgments.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	pending := unacknowledgedShifts(statuses, store.DateOnly(getRestaurantFromContext(r).Today(time.Now()).Format("2006-01-02")))
+
+	employeeIDs := make([]int64, 0, len(pending))
+	for employeeID := range pending {
+		employeeIDs = append(employeeIDs, employeeID)
+	}
+	sort.Slice(employeeIDs, func(i, j int) bool { return employeeIDs[i] < employeeIDs[j] })
+
+	response := SendScheduleEmailResponse{
+		TotalRecipients: len(employeeIDs),
+		Failures:        []SendScheduleEmailFailure{},
+	}
+
+	if len(employeeIDs) == 0 {
+		if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	employees, err := app.store.Employees.GetByIDs(ctx, employeeIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	quota, ok := app.takeEmailQuota(w, r, schedule.RestaurantID)
+	if !ok {
+		return
+	}
+	response.Quota = quota
+
+	restaurant := getRestaurantFromContext(r)
+	user := getUserFromContext(r)
+	portalURL := app.config.frontendURL + "/employee/shifts"
+
+	for _, employee := range employees {
+		if employee.Email == "" {
+			response.Failed++
+			response.Failures = append(response.Failures, SendScheduleEmailFailure{
+				EmployeeID:   employee.ID,
+				EmployeeName: employee.FullName,
+				Error:        "no email address",
+			})
+			continue
+		}
+
+		locale := i18n.Resolve(employee.Locale, user.Locale)
+		emailData := &ShiftAcknowledgmentReminderEmailData{
+			RestaurantName: mailer.PlainText(restaurant.Name),
+			EmployeeName:   mailer.PlainText(employee.FullName),
+			ScheduleStart:  i18n.FormatDay(locale, string(schedule.StartDate)),
+			ScheduleEnd:    i18n.FormatDay(locale, string(schedule.EndDate)),
+			PortalURL:      portalURL,
+		}
+		for _, status := range pending[employee.ID] {
+			emailData.Shifts = append(emailData.Shifts, acknowledgmentShiftForEmail(status, locale))
+		}
+
+		err := app.sendTrackedScheduleEmail(
+			r.Context(),
+			schedule,
+			store.EmailKindAcknowledgmentReminder,
+			employee,
+			mailer.Localized(mailer.ShiftAcknowledgmentReminderTemplate, locale),
+			emailData,
+		)
+		if err != nil {
+			app.logger.Warnw("failed to send shift acknowledgment reminder",
+				"employee_id", employee.ID,
+				"email", employee.Email,
+				"error", err,
+			)
+			response.Failed++
+			response.Failures = append(response.Failures, SendScheduleEmailFailure{
+				EmployeeID:   employee.ID,
+				EmployeeName: employee.FullName,
+				Email:        employee.Email,
+				Error:        err.Error(),
+			})
+			continue
+		}
+
+		response.Successful++
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// acknowledgmentReport totals a schedule's acknowledgments
+func acknowledgmentReport(scheduleID int64, statuses []*store.ShiftAcknowledgmentStatus) *ScheduleAcknowledgmentReport {
+	report := &ScheduleAcknowledgmentReport{
+		ScheduleID:                scheduleID,
+		AssignedShifts:            len(statuses),
+		Shifts:                    statuses,
+		UnacknowledgedEmployeeIDs: []int64{},
+	}
+
+	seen := make(map[int64]bool)
+	for _, status := range statuses {
+		if status.AcknowledgedAt != nil {
+			report.Acknowledged++
+			continue
+		}
+		if !seen[status.EmployeeID] {
+			seen[status.EmployeeID] = true
+			report.UnacknowledgedEmployeeIDs = append(report.UnacknowledgedEmployeeIDs, status.EmployeeID)
+		}
+	}
+	sort.Slice(report.UnacknowledgedEmployeeIDs, func(i, j int) bool {
+		return report.UnacknowledgedEmployeeIDs[i] < report.UnacknowledgedEmployeeIDs[j]
+	})
+
+	return report
+}
+
+// unacknowledgedShifts groups the unacknowledged shifts on or after today by
+// employee; past shifts aren't worth a reminder
+func unacknowledgedShifts(statuses []*store.ShiftAcknowledgmentStatus, today store.DateOnly) map[int64][]*store.ShiftAcknowledgmentStatus {
+	pending := make(map[int64][]*store.ShiftAcknowledgmentStatus)
+	for _, status := range statuses {
+		if status.AcknowledgedAt != nil || status.ShiftDate < today {
+			continue
+		}
+		pending[status.EmployeeID] = append(pending[status.EmployeeID], status)
+	}
+	return pending
+}
+
+func acknowledgmentShiftForEmail(status *store.ShiftAcknowledgmentStatus, locale i18n.Locale) ScheduleEmailShift {
+	date := string(status.ShiftDate)
+	if t, err := status.ShiftDate.ToTime(); err == nil {
+		date = i18n.FormatWeekday(locale, t)
+	}
+
+	return ScheduleEmailShift{
+		Date:      date,
+		StartTime: i18n.FormatTimeOfDay(locale, string(status.StartTime)),
+		EndTime:   i18n.FormatTimeOfDay(locale, string(status.EndTime)),
+		RoleName:  mailer.PlainText(status.RoleName),
+		RoleColor: status.RoleColor,
+		Training:  status.Training,
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestAcknowledgeShift(t *testing.T) {
+	t.Run("acknowledges the user's own shift", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.users.GetByIDFunc = func(_ context.Context, id int64) (*store.User, error) {
+			return &store.User{ID: id, Email: "sam@example.com", IsActive: true}, nil
+		}
+		ackedAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+		mocks.acknowledgments.AcknowledgeFunc = func(_ context.Context, shiftID int64, email string) (*store.ShiftAcknowledgment, error) {
+			if shiftID != 42 || email != "sam@example.com" {
+				t.Errorf("Acknowledge(%d, %q), want (42, sam@example.com)", shiftID, email)
+			}
+			return &store.ShiftAcknowledgment{ShiftID: shiftID, EmployeeID: 7, AcknowledgedAt: ackedAt}, nil
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/employee/me/shifts/42/acknowledge", ""), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data store.ShiftAcknowledgment `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Data.EmployeeID != 7 || !body.Data.AcknowledgedAt.Equal(ackedAt) {
+			t.Errorf("acknowledgment = %+v", body.Data)
+		}
+	})
+
+	t.Run("someone else's shift is not found", func(t *testing.T) {
+		app, mocks := newMockedApplication(t, testUserID)
+		mocks.acknowledgments.AcknowledgeFunc = func(context.Context, int64, string) (*store.ShiftAcknowledgment, error) {
+			return nil, store.ErrNotFound
+		}
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/employee/me/shifts/42/acknowledge", ""), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("invalid shift ID", func(t *testing.T) {
+		app, _ := newMockedApplication(t, testUserID)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/employee/me/shifts/abc/acknowledge", ""), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestScheduleAcknowledgmentsRequirePublishedSchedule(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	app.store.Schedules = &store.MockScheduleStorer{
+		GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+			return &store.Schedule{ID: id, RestaurantID: 3}, nil
+		},
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/schedules/5/acknowledgments", ""), app.mount())
+
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAcknowledgmentReport(t *testing.T) {
+	acked := time.Now()
+	statuses := []*store.ShiftAcknowledgmentStatus{
+		{ShiftID: 1, EmployeeID: 20, ShiftDate: "2026-06-01"},
+		{ShiftID: 2, EmployeeID: 10, ShiftDate: "2026-06-01", AcknowledgedAt: &acked},
+		{ShiftID: 3, EmployeeID: 20, ShiftDate: "2026-06-02"},
+		{ShiftID: 4, EmployeeID: 10, ShiftDate: "2026-06-03"},
+	}
+
+	report := acknowledgmentReport(5, statuses)
+
+	if report.AssignedShifts != 4 || report.Acknowledged != 1 {
+		t.Errorf("report = %d of %d acknowledged, want 1 of 4", report.Acknowledged, report.AssignedShifts)
+	}
+	if len(report.UnacknowledgedEmployeeIDs) != 2 || report.UnacknowledgedEmployeeIDs[0] != 10 || report.UnacknowledgedEmployeeIDs[1] != 20 {
+		t.Errorf("unacknowledged employees = %v, want [10 20]", report.UnacknowledgedEmployeeIDs)
+	}
+}
+
+func TestUnacknowledgedShiftsSkipPastAndAcknowledged(t *testing.T) {
+	acked := time.Now()
+	statuses := []*store.ShiftAcknowledgmentStatus{
+		{ShiftID: 1, EmployeeID: 10, ShiftDate: "2026-05-31"},
+		{ShiftID: 2, EmployeeID: 10, ShiftDate: "2026-06-01"},
+		{ShiftID: 3, EmployeeID: 20, ShiftDate: "2026-06-02", AcknowledgedAt: &acked},
+		{ShiftID: 4, EmployeeID: 10, ShiftDate: "2026-06-03"},
+	}
+
+	pending := unacknowledgedShifts(statuses, "2026-06-01")
+
+	if len(pending) != 1 {
+		t.Fatalf("pending employees = %d, want only employee 10", len(pending))
+	}
+	shifts := pending[10]
+	if len(shifts) != 2 || shifts[0].ShiftID != 2 || shifts[1].ShiftID != 4 {
+		t.Errorf("employee 10's pending shifts = %v, want shifts 2 and 4", shifts)
+	}
+}
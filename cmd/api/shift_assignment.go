@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// assignmentRefusedError is why an employee can't take a shift
+type assignmentRefusedError struct {
+	err error
+}
+
+func (e *assignmentRefusedError) Error() string { return e.err.Error() }
+
+func (e *assignmentRefusedError) Unwrap() error { return e.err }
+
+// vetAssignment checks the employee can take the shift, whether it's being
+// assigned to them, created for them or edited while theirs: they must hold
+// its role, unless it's a training shift, and the certifications the role
+// requires on its date, and taking it must not break a compliance rule set
+// to block. Refusals are returned as *assignmentRefusedError; the rules that
+// only warn are returned as violations.
+func (app *application) vetAssignment(ctx context.Context, shift *store.ScheduledShift, employeeID int64) ([]ComplianceViolation, error) {
+	if !shift.Training {
+		roles, err := app.store.Employees.GetRoles(ctx, employeeID, shift.RestaurantID)
+		if err != nil {
+			return nil, err
+		}
+		holds := false
+		for _, role := range roles {
+			holds = holds || role.ID == shift.RoleID
+		}
+		if !holds {
+			return nil, &assignmentRefusedError{fmt.Errorf("%w; assign with training=true to schedule them as a trainee", store.ErrRoleMismatch)}
+		}
+	}
+
+	missing, err := app.store.Certifications.MissingForAssignment(ctx, employeeID, shift.RoleID, shift.ShiftDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, &assignmentRefusedError{fmt.Errorf("employee is missing or has expired certifications required for this role: %s", strings.Join(missing, ", "))}
+	}
+
+	checker, err := app.newComplianceChecker(ctx, shift.RestaurantID, shift.ShiftDate, shift.ShiftDate)
+	if err != nil {
+		return nil, err
+	}
+	violations := checker.check(shift, employeeID, nil)
+	if blocking := blockingViolations(violations); len(blocking) > 0 {
+		return nil, &assignmentRefusedError{complianceError(blocking)}
+	}
+
+	return violations, nil
+}
+
+// checkAssignment answers 409 Conflict when the employee can't take the shift,
+// and otherwise returns the compliance rules the assignment breaks that only
+// warn. It returns false once it has written the response.
+func (app *application) checkAssignment(w http.ResponseWriter, r *http.Request, shift *store.ScheduledShift, employeeID int64) ([]ComplianceViolation, bool) {
+	violations, err := app.vetAssignment(r.Context(), shift, employeeID)
+	if err == nil {
+		return violations, true
+	}
+
+	var refused *assignmentRefusedError
+	if errors.As(err, &refused) {
+		app.conflictResponse(w, r, refused)
+		return nil, false
+	}
+	app.internalServerError(w, r, err)
+	return nil, false
+}
+
+// eligibleForBatch is how auto-assign and bid allocation decide whether an
+// employee who holds a shift's role can take it: the same certification and
+// compliance checks as a single assignment, counting the shifts the batch has
+// booked for them so far. Rules that only warn don't stop the batch.
+func (app *application) eligibleForBatch(ctx context.Context, checker *complianceChecker) func(int64, *store.ScheduledShift, []*store.ScheduledShift) (bool, error) {
+	return func(employeeID int64, shift *store.ScheduledShift, booked []*store.ScheduledShift) (bool, error) {
+		missing, err := app.store.Certifications.MissingForAssignment(ctx, employeeID, shift.RoleID, shift.ShiftDate)
+		if err != nil || len(missing) > 0 {
+			return false, err
+		}
+		return len(blockingViolations(checker.check(shift, employeeID, booked))) == 0, nil
+	}
+}
+
+// warnComplianceViolations logs the warning rules an assignment breaks and flags them on the shift
+func (app *application) warnComplianceViolations(shift *store.ScheduledShift, violations []ComplianceViolation) {
+	if len(violations) == 0 {
+		return
+	}
+	app.logger.Infow("assignment breaks compliance rules", "shift_id", shift.ID, "error", complianceError(violations).Error())
+	shift.Warnings = append(shift.Warnings, store.WarningCompliance)
+}
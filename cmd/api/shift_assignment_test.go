@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestAssignmentChecksOnShiftWrites(t *testing.T) {
+	employeeID := int64(7)
+
+	setup := func(t *testing.T, roleIDs []int64, missing []string) (*application, *bool) {
+		app, _ := newMockedApplication(t, testUserID)
+		saved := false
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				return &store.ScheduledShift{ID: id, ScheduleID: 5, RestaurantID: 1, RoleID: 2,
+					ShiftDate: time.Now().AddDate(0, 1, 0), StartTime: "09:00", EndTime: "17:00"}, nil
+			},
+			CreateFunc: func(_ context.Context, shift *store.ScheduledShift) error {
+				saved = true
+				shift.ID = 42
+				return nil
+			},
+			UpdateFunc: func(context.Context, *store.ScheduledShift) error {
+				saved = true
+				return nil
+			},
+		}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 1}, nil
+			},
+		}
+		app.store.OperatingHours = &store.MockOperatingHoursStorer{
+			GetFunc: func(_ context.Context, restaurantID int64) (*store.OperatingHours, error) {
+				return &store.OperatingHours{RestaurantID: restaurantID}, nil
+			},
+			ListExceptionsFunc: func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.HoursException, error) {
+				return nil, nil
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			GetRolesFunc: func(context.Context, int64, int64) ([]*store.Role, error) {
+				var roles []*store.Role
+				for _, id := range roleIDs {
+					roles = append(roles, &store.Role{ID: id})
+				}
+				return roles, nil
+			},
+		}
+		app.store.Certifications = &store.MockCertificationStorer{
+			MissingForAssignmentFunc: func(context.Context, int64, int64, time.Time) ([]string, error) { return missing, nil },
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		app.store.Notifications = &store.MockNotificationStorer{
+			CreateManyFunc: func(context.Context, []*store.Notification) error { return nil },
+		}
+		return app, &saved
+	}
+
+	create := func(app *application) *http.Request {
+		return authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/shifts",
+			`{"role_id": 2, "employee_id": 7, "shift_date": "2026-03-02T00:00:00Z", "start_time": "09:00", "end_time": "17:00"}`)
+	}
+	update := func(app *application) *http.Request {
+		return authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/schedules/5/shifts/42", `{"employee_id": 7}`)
+	}
+
+	cases := []struct {
+		name    string
+		roleIDs []int64
+		missing []string
+		request func(*application) *http.Request
+		want    int
+	}{
+		{"creating for an employee without the role", []int64{3}, nil, create, http.StatusConflict},
+		{"creating for an employee missing a certification", []int64{2}, []string{"Food Handler"}, create, http.StatusConflict},
+		{"creating for a qualified employee", []int64{2}, nil, create, http.StatusCreated},
+		{"assigning without the role in a patch", []int64{3}, nil, update, http.StatusConflict},
+		{"assigning with an expired certification in a patch", []int64{2}, []string{"Alcohol Service"}, update, http.StatusConflict},
+		{"assigning a qualified employee in a patch", []int64{2}, nil, update, http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			app, saved := setup(t, tc.roleIDs, tc.missing)
+
+			rr := executeRequest(tc.request(app), app.mount())
+
+			checkResponseCode(t, tc.want, rr.Code)
+			if *saved != (tc.want != http.StatusConflict) {
+				t.Errorf("saved = %v", *saved)
+			}
+		})
+	}
+
+	t.Run("a training shift skips the role", func(t *testing.T) {
+		app, _ := setup(t, nil, nil)
+		shift := &store.ScheduledShift{RestaurantID: 1, RoleID: 2, Training: true}
+
+		if _, err := app.vetAssignment(context.Background(), shift, employeeID); err != nil {
+			t.Errorf("err = %v, want the trainee allowed", err)
+		}
+	})
+}
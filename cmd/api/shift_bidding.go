@@ -0,0 +1,673 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateBidRoundPayload struct {
+	ShiftIDs []int64   `json:"shift_ids" validate:"required,min=1,max=200,unique"`
+	ClosesAt time.Time `json:"closes_at" validate:"required"`
+	// SeniorityWeight is how many hours already won one point of seniority
+	// makes up for in the draft order; 0 orders by hours alone
+	SeniorityWeight float64 `json:"seniority_weight" validate:"min=0,max=1000"`
+}
+
+type BidPreferencesPayload struct {
+	// ShiftIDs ranks the shifts wanted, first choice first; empty withdraws every bid
+	ShiftIDs []int64 `json:"shift_ids" validate:"max=200,unique"`
+}
+
+// BidRoundReport is a round with the bids on each shift and how each bidder
+// fared; awards are filled in once it's allocated
+type BidRoundReport struct {
+	Round   *store.BidRound  `json:"round"`
+	Shifts  []BidShiftResult `json:"shifts"`
+	Bidders []BidderResult   `json:"bidders"`
+	// AssignedCount is how many shifts were awarded; 0 until allocated
+	AssignedCount int `json:"assigned_count"`
+}
+
+type BidShiftResult struct {
+	ShiftID   int64           `json:"shift_id"`
+	ShiftDate store.DateOnly  `json:"shift_date"`
+	StartTime store.TimeOfDay `json:"start_time"`
+	EndTime   store.TimeOfDay `json:"end_time"`
+	RoleName  string          `json:"role_name"`
+	Bids      int             `json:"bids"`
+	// FirstChoiceBids counts the bidders who ranked the shift first
+	FirstChoiceBids     int     `json:"first_choice_bids"`
+	AwardedEmployeeID   *int64  `json:"awarded_employee_id,omitempty"`
+	AwardedEmployeeName *string `json:"awarded_employee_name,omitempty"`
+	AwardedRank         *int    `json:"awarded_rank,omitempty"`
+}
+
+type BidderResult struct {
+	EmployeeID   int64   `json:"employee_id"`
+	EmployeeName string  `json:"employee_name"`
+	Seniority    int     `json:"seniority"`
+	Ranked       int     `json:"ranked"`
+	Won          int     `json:"won"`
+	HoursWon     float64 `json:"hours_won"`
+	// FirstChoiceWon is whether they won the shift they ranked first
+	FirstChoiceWon bool `json:"first_choice_won"`
+}
+
+// bidAward is an open shift and the bidder who won it, at the rank they gave it
+type bidAward struct {
+	Shift      *store.ScheduledShift
+	EmployeeID int64
+	Rank       int
+}
+
+type bidAllocationPlan struct {
+	Awards []bidAward
+	// Unfilled lists the shifts no bidder could win
+	Unfilled []int64
+}
+
+// planBidAllocation drafts the open shifts among the bidders. In each pass every
+// bidder, in priority order, takes their highest-ranked shift still open that
+// they hold the role for, aren't already working at that time and are eligible
+// for, given the shifts the plan has booked for them so far. Priority goes to the fewest hours on the schedule, each point of
+// seniority counting as seniorityWeight hours fewer, then to seniority. Passes
+// repeat, reordered by the hours won so far, until no one wins anything.
+// prefs holds each bidder's shift IDs, first choice first.
+func planBidAllocation(
+	seniorityWeight float64,
+	shifts, open []*store.ScheduledShift,
+	prefs map[int64][]int64,
+	candidates []*autoAssignCandidate,
+	eligible func(employeeID int64, shift *store.ScheduledShift, booked []*store.ScheduledShift) (bool, error),
+) (*bidAllocationPlan, error) {
+	booked := make(map[int64][]*store.ScheduledShift)
+	minutes := make(map[int64]int)
+	for _, shift := range shifts {
+		if shift.EmployeeID != nil {
+			booked[*shift.EmployeeID] = append(booked[*shift.EmployeeID], shift)
+			minutes[*shift.EmployeeID] += shiftMinutes(shift)
+		}
+	}
+
+	available := make(map[int64]*store.ScheduledShift, len(open))
+	for _, shift := range open {
+		available[shift.ID] = shift
+	}
+
+	var bidders []*autoAssignCandidate
+	for _, c := range candidates {
+		if len(prefs[c.Employee.ID]) > 0 {
+			bidders = append(bidders, c)
+		}
+	}
+	priority := func(e *store.Employee) float64 {
+		return float64(minutes[e.ID])/60 - float64(e.Seniority)*seniorityWeight
+	}
+
+	plan := &bidAllocationPlan{Unfilled: []int64{}}
+	for won := true; won; {
+		won = false
+		sort.SliceStable(bidders, func(i, j int) bool {
+			a, b := bidders[i].Employee, bidders[j].Employee
+			if pa, pb := priority(a), priority(b); pa != pb {
+				return pa < pb
+			}
+			if a.Seniority != b.Seniority {
+				return a.Seniority > b.Seniority
+			}
+			return a.ID < b.ID
+		})
+
+		for _, c := range bidders {
+			for i, shiftID := range prefs[c.Employee.ID] {
+				shift, ok := available[shiftID]
+				if !ok || !c.RoleIDs[shift.RoleID] || overlapsAny(shift, booked[c.Employee.ID]) {
+					continue
+				}
+				ok, err := eligible(c.Employee.ID, shift, booked[c.Employee.ID])
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					continue
+				}
+
+				plan.Awards = append(plan.Awards, bidAward{Shift: shift, EmployeeID: c.Employee.ID, Rank: i + 1})
+				delete(available, shiftID)
+				booked[c.Employee.ID] = append(booked[c.Employee.ID], shift)
+				minutes[c.Employee.ID] += shiftMinutes(shift)
+				won = true
+				break
+			}
+		}
+	}
+
+	for _, shift := range open {
+		if _, ok := available[shift.ID]; ok {
+			plan.Unfilled = append(plan.Unfilled, shift.ID)
+		}
+	}
+	return plan, nil
+}
+
+// CreateBidRound godoc
+//
+//	@Summary		Opens shifts for bidding
+//	@Description	Puts unassigned shifts of the schedule up for bids until closes_at. Employees holding a shift's role see it in the employee portal and rank the ones they want; allocating the round then assigns them. Shifts out for bids are skipped by auto-assign, and can't be in two open rounds.
+//	@Tags			shift-bidding
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			scheduleID		path		int						true	"Schedule ID"
+//	@Param			payload			body		CreateBidRoundPayload	true	"Bid round"
+//	@Success		201				{object}	store.BidRound
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error	"A shift is assigned, not on the schedule or already out for bids"
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds [post]
+func (app *application) createBidRoundHandler(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	var payload CreateBidRoundPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !payload.ClosesAt.After(time.Now()) {
+		app.badRequestResponse(w, r, errors.New("closes_at must be in the future"))
+		return
+	}
+
+	user := getUserFromContext(r)
+	round := &store.BidRound{
+		RestaurantID:    schedule.RestaurantID,
+		ScheduleID:      schedule.ID,
+		ClosesAt:        payload.ClosesAt,
+		SeniorityWeight: payload.SeniorityWeight,
+		Shifts:          make([]*store.BidRoundShift, len(payload.ShiftIDs)),
+		CreatedBy:       &user.ID,
+	}
+	for i, id := range payload.ShiftIDs {
+		round.Shifts[i] = &store.BidRoundShift{ShiftID: id}
+	}
+
+	if err := app.store.Bids.Create(r.Context(), round); err != nil {
+		switch {
+		case errors.Is(err, store.ErrBidShiftUnavailable):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, round); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetBidRounds godoc
+//
+//	@Summary		Lists a schedule's bid rounds
+//	@Description	The schedule's bid rounds, newest first, with their shifts and, once allocated, who won each.
+//	@Tags			shift-bidding
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Success		200				{array}		store.BidRound
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds [get]
+func (app *application) getBidRoundsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return
+	}
+
+	rounds, err := app.store.Bids.ListBySchedule(r.Context(), schedule.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, rounds); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetBidRound godoc
+//
+//	@Summary		Reports on a bid round
+//	@Description	The round's shifts with how many bids each drew, and each bidder's shifts ranked; once allocated, who won each shift at what rank, and the shifts and hours each bidder won.
+//	@Tags			shift-bidding
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Param			roundID			path		int	true	"Bid round ID"
+//	@Success		200				{object}	BidRoundReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds/{roundID} [get]
+func (app *application) getBidRoundHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	round, ok := app.bidRoundInSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.bidRoundReport(r.Context(), round)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// AllocateBidRound godoc
+//
+//	@Summary		Allocates a bid round
+//	@Description	Closes the round and drafts its shifts among the bidders: in each pass every bidder takes their highest-ranked shift still open that they hold the role and certifications for, aren't already working at that time, and can take without breaking a compliance rule set to block. Bidders with the fewest hours on the schedule pick first, each point of seniority counting as the round's seniority_weight hours fewer, then the most senior. Passes repeat until no one wins anything; shifts no one won stay open. Awards are assigned, recorded in the shift history and notified like any assignment. Before closes_at, allocating needs force=true.
+//	@Tags			shift-bidding
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			scheduleID		path		int		true	"Schedule ID"
+//	@Param			roundID			path		int		true	"Bid round ID"
+//	@Param			force			query		bool	false	"Allocate before bidding closes"
+//	@Success		200				{object}	BidRoundReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		403				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		409				{object}	error	"Bidding is still open, or the round was already allocated"
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds/{roundID}/allocate [post]
+func (app *application) allocateBidRoundHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+	round, ok := app.bidRoundInSchedule(w, r)
+	if !ok {
+		return
+	}
+
+	if round.Status != store.BidRoundOpen {
+		app.conflictResponse(w, r, store.ErrBidRoundAllocated)
+		return
+	}
+	now := time.Now()
+	if now.Before(round.ClosesAt) && r.URL.Query().Get("force") != "true" {
+		app.conflictResponse(w, r, fmt.Errorf("bidding is open until %s; allocate with force=true to close it now", round.ClosesAt.Format(time.RFC3339)))
+		return
+	}
+
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(r.Context(), round.ScheduleID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	inRound := make(map[int64]bool, len(round.Shifts))
+	for _, s := range round.Shifts {
+		inRound[s.ShiftID] = true
+	}
+	var open []*store.ScheduledShift
+	for _, shift := range shifts {
+		if inRound[shift.ID] && shift.EmployeeID == nil {
+			open = append(open, shift)
+		}
+	}
+
+	preferences, err := app.store.Bids.ListPreferences(r.Context(), round.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	prefs := make(map[int64][]int64)
+	for _, p := range preferences {
+		prefs[p.EmployeeID] = append(prefs[p.EmployeeID], p.ShiftID)
+	}
+
+	employees, err := app.store.Employees.ListByRestaurant(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	var bidders []*store.Employee
+	for _, employee := range employees {
+		if len(prefs[employee.ID]) > 0 {
+			bidders = append(bidders, employee)
+		}
+	}
+	candidates, err := app.autoAssignCandidates(r.Context(), restaurant.ID, bidders)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	from, to := shiftDates(open)
+	checker, err := app.newComplianceChecker(r.Context(), restaurant.ID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	plan, err := planBidAllocation(round.SeniorityWeight, shifts, open, prefs, candidates, app.eligibleForBatch(r.Context(), checker))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.Bids.ClaimAllocation(r.Context(), round.ID, now); err != nil {
+		switch {
+		case errors.Is(err, store.ErrBidRoundAllocated):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	actorID := getUserFromContext(r).ID
+	awards := make([]*store.BidRoundShift, 0, len(plan.Awards))
+	for _, a := range plan.Awards {
+		employeeID, rank := a.EmployeeID, a.Rank
+		if err := app.store.ScheduledShifts.AssignEmployee(r.Context(), a.Shift.ID, store.ShiftAssignment{EmployeeID: &employeeID}); err != nil {
+			// Changed since the plan was made; leave it for the owner
+			if errors.Is(err, store.ErrNotFound) || errors.Is(err, store.ErrRoleMismatch) {
+				continue
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		shift, err := app.store.ScheduledShifts.GetByID(r.Context(), a.Shift.ID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		app.recordAudit(r.Context(), bidAwardedEntry(actorID, round.ID, rank, a.Shift, shift))
+		app.notifyShiftChanged(r.Context(), a.Shift, shift)
+		awards = append(awards, &store.BidRoundShift{ShiftID: a.Shift.ID, AwardedEmployeeID: &employeeID, AwardedRank: &rank})
+	}
+
+	if err := app.store.Bids.RecordAwards(r.Context(), round.ID, awards); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	round, err = app.store.Bids.GetByID(r.Context(), round.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	report, err := app.bidRoundReport(r.Context(), round)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, report); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// bidAwardedEntry is the audit entry of a shift won in a bid round
+func bidAwardedEntry(actorID, roundID int64, rank int, before, after *store.ScheduledShift) *store.AuditEntry {
+	entry := shiftChangeEntry(actorID, before, after)
+	if entry == nil {
+		return nil
+	}
+	entry.Summary += fmt.Sprintf(" by shift bidding (choice %d)", rank)
+	entry.Changes["bid_round_id"] = store.AuditChange{To: roundID}
+	return entry
+}
+
+// bidRoundInSchedule loads the round in the URL, checking it belongs to the schedule in the URL
+func (app *application) bidRoundInSchedule(w http.ResponseWriter, r *http.Request) (*store.BidRound, bool) {
+	schedule, ok := app.scheduleInRestaurant(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	roundID, err := strconv.ParseInt(chi.URLParam(r, "roundID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid bid round ID"))
+		return nil, false
+	}
+
+	round, err := app.store.Bids.GetByID(r.Context(), roundID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+
+	if round.ScheduleID != schedule.ID {
+		app.notFoundResponse(w, r, errors.New("bid round not found"))
+		return nil, false
+	}
+
+	return round, true
+}
+
+// bidRoundReport puts the round together with its bids and the shifts and employees they name
+func (app *application) bidRoundReport(ctx context.Context, round *store.BidRound) (*BidRoundReport, error) {
+	preferences, err := app.store.Bids.ListPreferences(ctx, round.ID)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := app.store.ScheduledShifts.ListBySchedule(ctx, round.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	employees, err := app.store.Employees.ListByRestaurant(ctx, round.RestaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBidRoundReport(round, preferences, shifts, employees), nil
+}
+
+func buildBidRoundReport(round *store.BidRound, preferences []*store.BidPreference, shifts []*store.ScheduledShift, employees []*store.Employee) *BidRoundReport {
+	shiftsByID := make(map[int64]*store.ScheduledShift, len(shifts))
+	for _, s := range shifts {
+		shiftsByID[s.ID] = s
+	}
+	employeesByID := make(map[int64]*store.Employee, len(employees))
+	for _, e := range employees {
+		employeesByID[e.ID] = e
+	}
+
+	report := &BidRoundReport{Round: round, Shifts: []BidShiftResult{}, Bidders: []BidderResult{}}
+
+	bidders := make(map[int64]*BidderResult)
+	var order []int64
+	bids := make(map[int64]int)
+	firstChoices := make(map[int64]int)
+	firstChoice := make(map[int64]int64)
+	for _, p := range preferences {
+		bids[p.ShiftID]++
+		if p.Rank == 1 {
+			firstChoices[p.ShiftID]++
+			firstChoice[p.EmployeeID] = p.ShiftID
+		}
+
+		b, ok := bidders[p.EmployeeID]
+		if !ok {
+			b = &BidderResult{EmployeeID: p.EmployeeID}
+			if e := employeesByID[p.EmployeeID]; e != nil {
+				b.EmployeeName, b.Seniority = e.FullName, e.Seniority
+			}
+			bidders[p.EmployeeID] = b
+			order = append(order, p.EmployeeID)
+		}
+		b.Ranked++
+	}
+
+	for _, rs := range round.Shifts {
+		result := BidShiftResult{
+			ShiftID:           rs.ShiftID,
+			Bids:              bids[rs.ShiftID],
+			FirstChoiceBids:   firstChoices[rs.ShiftID],
+			AwardedEmployeeID: rs.AwardedEmployeeID,
+			AwardedRank:       rs.AwardedRank,
+		}
+		shift := shiftsByID[rs.ShiftID]
+		if shift != nil {
+			result.ShiftDate = store.DateOnly(shift.ShiftDate.Format("2006-01-02"))
+			result.StartTime, result.EndTime, result.RoleName = shift.StartTime, shift.EndTime, shift.RoleName
+		}
+
+		if rs.AwardedEmployeeID != nil {
+			report.AssignedCount++
+			if e := employeesByID[*rs.AwardedEmployeeID]; e != nil {
+				result.AwardedEmployeeName = &e.FullName
+			}
+			if b := bidders[*rs.AwardedEmployeeID]; b != nil {
+				b.Won++
+				if shift != nil {
+					b.HoursWon += float64(shiftMinutes(shift)) / 60
+				}
+				if firstChoice[b.EmployeeID] == rs.ShiftID {
+					b.FirstChoiceWon = true
+				}
+			}
+		}
+		report.Shifts = append(report.Shifts, result)
+	}
+
+	for _, id := range order {
+		report.Bidders = append(report.Bidders, *bidders[id])
+	}
+	return report
+}
+
+// GetMyBiddableShifts godoc
+//
+//	@Summary		Lists shifts open for bids
+//	@Description	The shifts of open bid rounds, at restaurants with an employee record with the signed-in user's email, that the employee can bid on: still unassigned and of a role they hold. Each shift has the rank they gave it, if any, and the round's closing time.
+//	@Tags			employee portal
+//	@Produce		json
+//	@Success		200	{array}		store.BiddableShift
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/employee/me/bid-rounds [get]
+func (app *application) getMyBiddableShiftsHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	shifts, err := app.store.Bids.ListBiddableForEmail(r.Context(), user.Email, time.Now())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, shifts); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// SubmitBidPreferences godoc
+//
+//	@Summary		Ranks the shifts wanted in a bid round
+//	@Description	Replaces the signed-in employee's bids on the round with the shifts given, first choice first. Every shift must be in the round and of a role they hold. An empty list withdraws their bids. Bids can change until the round closes.
+//	@Tags			employee portal
+//	@Accept			json
+//	@Produce		json
+//	@Param			roundID	path		int						true	"Bid round ID"
+//	@Param			payload	body		BidPreferencesPayload	true	"Ranked shifts"
+//	@Success		200		{array}		store.BidPreference
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error	"Bidding has closed"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/employee/me/bid-rounds/{roundID}/preferences [put]
+func (app *application) submitBidPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	roundID, err := strconv.ParseInt(chi.URLParam(r, "roundID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid bid round ID"))
+		return
+	}
+
+	var payload BidPreferencesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	prefs, err := app.store.Bids.SubmitPreferences(r.Context(), roundID, user.Email, payload.ShiftIDs, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrBidRoundClosed):
+			app.conflictResponse(w, r, err)
+		case errors.Is(err, store.ErrBidShiftUnavailable):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, prefs); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestPlanBidAllocation(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	employeeID := func(id int64) *int64 { return &id }
+	shift := func(id int64, date time.Time, start, end string, employee *int64) *store.ScheduledShift {
+		return &store.ScheduledShift{ID: id, RoleID: 1, ShiftDate: date, StartTime: store.TimeOfDay(start), EndTime: store.TimeOfDay(end), EmployeeID: employee}
+	}
+	candidates := []*autoAssignCandidate{
+		{Employee: &store.Employee{ID: 1, Seniority: 1}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 2, Seniority: 5}, RoleIDs: map[int64]bool{1: true}},
+		{Employee: &store.Employee{ID: 3, Seniority: 9}, RoleIDs: map[int64]bool{2: true}},
+	}
+	eligible := func(int64, *store.ScheduledShift, []*store.ScheduledShift) (bool, error) { return true, nil }
+	won := func(plan *bidAllocationPlan) map[int64]int64 {
+		got := make(map[int64]int64)
+		for _, a := range plan.Awards {
+			got[a.Shift.ID] = a.EmployeeID
+		}
+		return got
+	}
+	open := []*store.ScheduledShift{
+		shift(10, monday.AddDate(0, 0, 5), "15:00", "23:00", nil),
+		shift(11, monday.AddDate(0, 0, 6), "17:00", "23:00", nil),
+		shift(12, monday.AddDate(0, 0, 1), "09:00", "13:00", nil),
+	}
+
+	t.Run("bidders take turns, the most senior first", func(t *testing.T) {
+		prefs := map[int64][]int64{1: {10, 11, 12}, 2: {10, 11, 12}}
+
+		plan, err := planBidAllocation(0, open, open, prefs, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 2 wins its first choice, 1 its second, then 1 picks first with 6 hours to 2's 8
+		want := map[int64]int64{10: 2, 11: 1, 12: 1}
+		got := won(plan)
+		for id, employee := range want {
+			if got[id] != employee {
+				t.Errorf("shift %d went to %d, want %d", id, got[id], employee)
+			}
+		}
+		if plan.Awards[1].Rank != 2 {
+			t.Errorf("employee 1 won shift 11 at rank %d, want 2", plan.Awards[1].Rank)
+		}
+	})
+
+	t.Run("hours already worked go last unless seniority outweighs them", func(t *testing.T) {
+		worked := shift(9, monday, "09:00", "17:00", employeeID(2))
+		shifts := append([]*store.ScheduledShift{worked}, open...)
+		prefs := map[int64][]int64{1: {10}, 2: {10}}
+
+		plan, err := planBidAllocation(0, shifts, open, prefs, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := won(plan)[10]; got != 1 {
+			t.Errorf("by hours alone shift 10 went to %d, want 1", got)
+		}
+
+		// 4 points of seniority over employee 1 at 3 hours each outweigh 8 hours worked
+		plan, err = planBidAllocation(3, shifts, open, prefs, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := won(plan)[10]; got != 2 {
+			t.Errorf("weighing seniority shift 10 went to %d, want 2", got)
+		}
+	})
+
+	t.Run("leaves shifts no eligible bidder wanted open", func(t *testing.T) {
+		// employee 3 doesn't hold the role
+		prefs := map[int64][]int64{3: {10, 11}, 1: {12}}
+
+		plan, err := planBidAllocation(0, open, open, prefs, candidates, eligible)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(plan.Awards) != 1 || plan.Awards[0].Shift.ID != 12 {
+			t.Errorf("awards = %+v, want only shift 12", plan.Awards)
+		}
+		if len(plan.Unfilled) != 2 || plan.Unfilled[0] != 10 || plan.Unfilled[1] != 11 {
+			t.Errorf("unfilled = %v, want [10 11]", plan.Unfilled)
+		}
+	})
+}
+
+func TestAllocateBidRound(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	setup := func(t *testing.T, closesAt time.Time) (*application, *[]*store.BidRoundShift) {
+		app, _ := newMockedApplication(t, testUserID)
+
+		shifts := []*store.ScheduledShift{
+			{ID: 10, ScheduleID: 5, RestaurantID: 1, RoleID: 1, ShiftDate: monday, StartTime: "09:00:00", EndTime: "17:00:00"},
+			{ID: 11, ScheduleID: 5, RestaurantID: 1, RoleID: 1, ShiftDate: monday, StartTime: "12:00:00", EndTime: "20:00:00"},
+		}
+		assigned := map[int64]int64{}
+		app.store.Schedules = &store.MockScheduleStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Schedule, error) {
+				return &store.Schedule{ID: id, RestaurantID: 1}, nil
+			},
+		}
+		app.store.ScheduledShifts = &store.MockScheduledShiftStorer{
+			ListByScheduleFunc: func(context.Context, int64) ([]*store.ScheduledShift, error) { return shifts, nil },
+			AssignEmployeeFunc: func(_ context.Context, id int64, a store.ShiftAssignment) error {
+				assigned[id] = *a.EmployeeID
+				return nil
+			},
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ScheduledShift, error) {
+				for _, s := range shifts {
+					if s.ID == id {
+						copied := *s
+						employee := assigned[id]
+						copied.EmployeeID = &employee
+						return &copied, nil
+					}
+				}
+				return nil, store.ErrNotFound
+			},
+		}
+		app.store.Employees = &store.MockEmployeeStorer{
+			ListByRestaurantFunc: func(context.Context, int64) ([]*store.Employee, error) {
+				return []*store.Employee{{ID: 7, FullName: "Alex Smith", Seniority: 3}, {ID: 8, FullName: "Sam Lee"}}, nil
+			},
+			RoleIDsByRestaurantFunc: func(context.Context, int64) (map[int64][]int64, error) {
+				return map[int64][]int64{7: {1}, 8: {1}}, nil
+			},
+		}
+		app.store.Certifications = &store.MockCertificationStorer{
+			MissingForAssignmentFunc: func(context.Context, int64, int64, time.Time) ([]string, error) { return nil, nil },
+		}
+		app.store.AuditLog = &store.MockAuditLogStorer{
+			RecordFunc: func(context.Context, []*store.AuditEntry) error { return nil },
+		}
+		app.store.Notifications = &store.MockNotificationStorer{
+			CreateManyFunc: func(context.Context, []*store.Notification) error { return nil },
+		}
+
+		round := &store.BidRound{ID: 3, RestaurantID: 1, ScheduleID: 5, Status: store.BidRoundOpen, ClosesAt: closesAt,
+			Shifts: []*store.BidRoundShift{{ShiftID: 10}, {ShiftID: 11}}}
+		var recorded []*store.BidRoundShift
+		bids := app.store.Bids.(*store.MockBidStorer)
+		bids.GetByIDFunc = func(context.Context, int64) (*store.BidRound, error) {
+			copied := *round
+			if recorded != nil {
+				copied.Status, copied.Shifts = store.BidRoundAllocated, recorded
+			}
+			return &copied, nil
+		}
+		bids.ListPreferencesFunc = func(context.Context, int64) ([]*store.BidPreference, error) {
+			return []*store.BidPreference{
+				{RoundID: 3, EmployeeID: 7, ShiftID: 10, Rank: 1},
+				{RoundID: 3, EmployeeID: 8, ShiftID: 10, Rank: 1},
+				{RoundID: 3, EmployeeID: 8, ShiftID: 11, Rank: 2},
+			}, nil
+		}
+		bids.ClaimAllocationFunc = func(context.Context, int64, time.Time) error { return nil }
+		bids.RecordAwardsFunc = func(_ context.Context, _ int64, awards []*store.BidRoundShift) error {
+			recorded = awards
+			return nil
+		}
+		return app, &recorded
+	}
+
+	t.Run("skips bidders who would break a blocking compliance rule", func(t *testing.T) {
+		app, recorded := setup(t, time.Now().Add(-time.Hour))
+		app.store.Compliance.(*store.MockComplianceStorer).GetFunc = func(_ context.Context, id int64) (*store.ComplianceRules, error) {
+			rules, _ := store.ComplianceTemplate("eu")
+			rules.RestaurantID = id
+			return &rules, nil
+		}
+		// Alex closed the night before, too late to open at 9
+		employeeID := int64(7)
+		app.store.ScheduledShifts.(*store.MockScheduledShiftStorer).ListByRestaurantAndRangeFunc = func(context.Context, int64, store.DateOnly, store.DateOnly) ([]*store.ScheduledShift, error) {
+			return []*store.ScheduledShift{{ID: 9, RestaurantID: 1, EmployeeID: &employeeID, ShiftDate: monday.AddDate(0, 0, -1), StartTime: "17:00:00", EndTime: "23:00:00"}}, nil
+		}
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/bid-rounds/3/allocate", "")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*recorded) != 1 || (*recorded)[0].ShiftID != 10 || *(*recorded)[0].AwardedEmployeeID != 8 {
+			t.Errorf("recorded = %+v, want shift 10 to employee 8 only", *recorded)
+		}
+	})
+
+	t.Run("assigns the winners and reports them", func(t *testing.T) {
+		app, recorded := setup(t, time.Now().Add(-time.Hour))
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/bid-rounds/3/allocate", "")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*recorded) != 2 {
+			t.Fatalf("recorded = %+v, want both shifts awarded", *recorded)
+		}
+
+		var body struct {
+			Data BidRoundReport `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		report := body.Data
+		// the senior bidder wins the contested shift; the other gets their second choice
+		if report.AssignedCount != 2 || *report.Shifts[0].AwardedEmployeeID != 7 || *report.Shifts[1].AwardedEmployeeID != 8 {
+			t.Errorf("shifts = %+v, want 10 to employee 7 and 11 to employee 8", report.Shifts)
+		}
+		if report.Shifts[0].Bids != 2 || report.Shifts[0].FirstChoiceBids != 2 {
+			t.Errorf("shift 10 = %+v, want 2 first-choice bids", report.Shifts[0])
+		}
+		for _, b := range report.Bidders {
+			if b.Won != 1 || b.HoursWon != 8 || b.FirstChoiceWon != (b.EmployeeID == 7) {
+				t.Errorf("bidder = %+v, want one 8 hour shift, the first choice for employee 7 only", b)
+			}
+		}
+	})
+
+	t.Run("waits for bidding to close", func(t *testing.T) {
+		app, recorded := setup(t, time.Now().Add(time.Hour))
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/bid-rounds/3/allocate", "")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if *recorded != nil {
+			t.Error("allocated a round still open for bids")
+		}
+
+		req = authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/schedules/5/bid-rounds/3/allocate?force=true", "")
+		rr = executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultShiftFeedbackDays = 28
+	maxShiftFeedbackDays     = 366
+	// lowShiftRating and below count as a bad shift in the report
+	lowShiftRating = 2
+)
+
+type ShiftFeedbackPayload struct {
+	Rating  int    `json:"rating" validate:"required,min=1,max=5"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+// ShiftFeedbackReport aggregates the feedback employees gave on shifts dated in
+// a range, by day and by role
+type ShiftFeedbackReport struct {
+	From          store.DateOnly              `json:"from"`
+	To            store.DateOnly              `json:"to"`
+	Responses     int                         `json:"responses"`
+	AverageRating *float64                    `json:"average_rating,omitempty"`
+	LowRatings    int                         `json:"low_ratings"`
+	Days          []ShiftFeedbackGroup        `json:"days"`
+	Roles         []ShiftFeedbackGroup        `json:"roles"`
+	Comments      []*store.ShiftFeedbackEntry `json:"comments"`
+}
+
+// ShiftFeedbackGroup is the feedback on the shifts of one day (Date) or one role (RoleName)
+type ShiftFeedbackGroup struct {
+	Date          store.DateOnly `json:"date,omitempty"`
+	RoleName      string         `json:"role_name,omitempty"`
+	Responses     int            `json:"responses"`
+	AverageRating float64        `json:"average_rating"`
+	// LowRatings counts the ratings of 2 or less
+	LowRatings int `json:"low_ratings"`
+}
+
+// SubmitShiftFeedback godoc
+//
+//	@Summary		Gives feedback on a worked shift
+//	@Description	Records the signed-in employee's rating (1-5) and optional comment on a shift assigned to them on a published schedule, once its end time has passed. Submitting again replaces the earlier feedback.
+//	@Tags			employee portal
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Shift ID"
+//	@Param			payload	body		ShiftFeedbackPayload	true	"Feedback"
+//	@Success		200		{object}	store.ShiftFeedback
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		409		{object}	error	"The shift hasn't ended yet"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/employee/me/shifts/{id}/feedback [put]
+func (app *application) submitShiftFeedbackHandler(w http.ResponseWriter, r *http.Request) {
+	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
+		return
+	}
+
+	var payload ShiftFeedbackPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	user := getUserFromContext(r)
+
+	feedback := &store.ShiftFeedback{
+		ShiftID: shiftID,
+		Rating:  payload.Rating,
+		Comment: strings.TrimSpace(payload.Comment),
+	}
+
+	// Shifts of other employees, and ones not yet published, are not found
+	if err := app.store.ShiftFeedback.Submit(r.Context(), feedback, user.Email, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		case errors.Is(err, store.ErrShiftNotEnded):
+			app.conflictResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, feedback); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// GetShiftFeedbackReport godoc
+//
+//	@Summary		Reports employee feedback on shifts
+//	@Description	Aggregates the ratings employees gave the restaurant's shifts dated from through to (default the 4 weeks up to today, at most 366 days): overall, per day and per role, with how many were rated 2 or less. Comments are listed newest shift first.
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			from			query		string	false	"First shift date (YYYY-MM-DD)"
+//	@Param			to				query		string	false	"Last shift date (YYYY-MM-DD)"
+//	@Success		200				{object}	ShiftFeedbackReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/shift-feedback [get]
+func (app *application) getShiftFeedbackReportHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	today := restaurant.Today(time.Now())
+	from, to, err := parseDateRange(r, today.AddDate(0, 0, 1-defaultShiftFeedbackDays), today)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if to.Sub(from) >= maxShiftFeedbackDays*24*time.Hour {
+		app.badRequestResponse(w, r, fmt.Errorf("the range can be at most %d days", maxShiftFeedbackDays))
+		return
+	}
+
+	entries, err := app.store.ShiftFeedback.ListByRestaurant(r.Context(), restaurant.ID, dateOnly(from), dateOnly(to))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, shiftFeedbackReport(entries, dateOnly(from), dateOnly(to))); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// shiftFeedbackReport groups entries, which come ordered by shift date, by day and by role
+func shiftFeedbackReport(entries []*store.ShiftFeedbackEntry, from, to store.DateOnly) *ShiftFeedbackReport {
+	report := &ShiftFeedbackReport{
+		From:     from,
+		To:       to,
+		Days:     []ShiftFeedbackGroup{},
+		Roles:    []ShiftFeedbackGroup{},
+		Comments: []*store.ShiftFeedbackEntry{},
+	}
+
+	type totals struct {
+		responses, low, sum int
+	}
+	add := func(t *totals, rating int) {
+		t.responses++
+		t.sum += rating
+		if rating <= lowShiftRating {
+			t.low++
+		}
+	}
+	group := func(t *totals) ShiftFeedbackGroup {
+		return ShiftFeedbackGroup{Responses: t.responses, AverageRating: averageRating(t.sum, t.responses), LowRatings: t.low}
+	}
+
+	var overall totals
+	var days []store.DateOnly
+	byDay := map[store.DateOnly]*totals{}
+	byRole := map[string]*totals{}
+	for _, e := range entries {
+		add(&overall, e.Rating)
+
+		day, ok := byDay[e.ShiftDate]
+		if !ok {
+			day = &totals{}
+			byDay[e.ShiftDate] = day
+			days = append(days, e.ShiftDate)
+		}
+		add(day, e.Rating)
+
+		role, ok := byRole[e.RoleName]
+		if !ok {
+			role = &totals{}
+			byRole[e.RoleName] = role
+		}
+		add(role, e.Rating)
+
+		if e.Comment != "" {
+			report.Comments = append(report.Comments, e)
+		}
+	}
+
+	for _, d := range days {
+		g := group(byDay[d])
+		g.Date = d
+		report.Days = append(report.Days, g)
+	}
+	for name, t := range byRole {
+		g := group(t)
+		g.RoleName = name
+		report.Roles = append(report.Roles, g)
+	}
+	sort.Slice(report.Roles, func(i, j int) bool { return report.Roles[i].RoleName < report.Roles[j].RoleName })
+	sort.SliceStable(report.Comments, func(i, j int) bool { return report.Comments[i].ShiftDate > report.Comments[j].ShiftDate })
+
+	report.Responses, report.LowRatings = overall.responses, overall.low
+	if overall.responses > 0 {
+		average := averageRating(overall.sum, overall.responses)
+		report.AverageRating = &average
+	}
+
+	return report
+}
+
+func averageRating(sum, n int) float64 {
+	return math.Round(float64(sum)/float64(n)*100) / 100
+}
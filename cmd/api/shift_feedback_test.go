@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestSubmitShiftFeedback(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.users.GetByIDFunc = func(_ context.Context, id int64) (*store.User, error) {
+		return &store.User{ID: id, Email: "sam@example.com", IsActive: true}, nil
+	}
+	app.store.ShiftFeedback = &store.MockShiftFeedbackStorer{
+		SubmitFunc: func(_ context.Context, f *store.ShiftFeedback, email string, _ time.Time) error {
+			switch f.ShiftID {
+			case 2:
+				return store.ErrShiftNotEnded
+			case 3:
+				return store.ErrNotFound
+			}
+			if email != "sam@example.com" || f.Rating != 2 || f.Comment != "we were understaffed" {
+				t.Errorf("Submit(%+v, %q), want Sam's trimmed feedback", f, email)
+			}
+			return nil
+		},
+	}
+
+	submit := func(shiftID, body string) int {
+		return executeRequest(authedRequest(t, app, http.MethodPut, "/v1/employee/me/shifts/"+shiftID+"/feedback", body), app.mount()).Code
+	}
+
+	checkResponseCode(t, http.StatusOK, submit("1", `{"rating":2,"comment":"  we were understaffed "}`))
+	checkResponseCode(t, http.StatusConflict, submit("2", `{"rating":4}`))
+	checkResponseCode(t, http.StatusNotFound, submit("3", `{"rating":4}`))
+	checkResponseCode(t, http.StatusBadRequest, submit("1", `{"rating":6}`))
+	checkResponseCode(t, http.StatusBadRequest, submit("1", `{"comment":"no rating"}`))
+}
+
+func TestShiftFeedbackReport(t *testing.T) {
+	entries := []*store.ShiftFeedbackEntry{
+		{ShiftID: 1, ShiftDate: "2026-06-01", RoleName: "Server", Rating: 2, Comment: "we were understaffed"},
+		{ShiftID: 2, ShiftDate: "2026-06-01", RoleName: "Cook", Rating: 5},
+		{ShiftID: 3, ShiftDate: "2026-06-03", RoleName: "Server", Rating: 4, Comment: "smooth night"},
+	}
+
+	report := shiftFeedbackReport(entries, "2026-06-01", "2026-06-07")
+
+	if report.Responses != 3 || report.LowRatings != 1 || report.AverageRating == nil || *report.AverageRating != 3.67 {
+		t.Errorf("overall = %d responses, %d low, average %v; want 3, 1, 3.67", report.Responses, report.LowRatings, report.AverageRating)
+	}
+	if len(report.Days) != 2 || report.Days[0].Date != "2026-06-01" || report.Days[0].AverageRating != 3.5 || report.Days[0].LowRatings != 1 {
+		t.Errorf("days = %+v, want June 1 averaging 3.5 and June 3", report.Days)
+	}
+	if len(report.Roles) != 2 || report.Roles[0].RoleName != "Cook" || report.Roles[1].Responses != 2 || report.Roles[1].AverageRating != 3 {
+		t.Errorf("roles = %+v, want Cook then Server averaging 3", report.Roles)
+	}
+	if len(report.Comments) != 2 || report.Comments[0].ShiftID != 3 {
+		t.Errorf("comments = %+v, want the two comments, newest shift first", report.Comments)
+	}
+
+	empty := shiftFeedbackReport(nil, "2026-06-01", "2026-06-07")
+	if empty.AverageRating != nil || len(empty.Days) != 0 {
+		t.Errorf("empty report = %+v", empty)
+	}
+}
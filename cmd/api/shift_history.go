@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+type ShiftHistoryResponse struct {
+	ShiftID int64 `json:"shift_id"`
+	// Deleted is set when the shift is gone and only its history remains
+	Deleted bool                `json:"deleted"`
+	Entries []*store.AuditEntry `json:"entries"`
+	// Published is the shift as employees were last published it, for settling disputes;
+	// omitted when the shift wasn't in the published version
+	Published        *store.SnapshotShift `json:"published,omitempty"`
+	PublishedVersion int                  `json:"published_version,omitempty"`
+}
+
+// GetShiftHistory godoc
+//
+//	@Summary		Lists the changes made to a shift
+//	@Description	Returns the shift's audit log oldest first: how it was created (by hand, from a template or for an event), who it was assigned and reassigned to, and changes to its time, date, role or notes, each with who made it, and the shift as it was in the last published version of the schedule. History is kept after the shift is deleted.
+//	@Tags			scheduled-shifts
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			scheduleID		path		int	true	"Schedule ID"
+//	@Param			shiftID			path		int	true	"Shift ID"
+//	@Success		200				{object}	ShiftHistoryResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/history [get]
+func (app *application) getShiftHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	restaurantID, err := strconv.ParseInt(chi.URLParam(r, "restaurantID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid restaurant ID"))
+		return
+	}
+
+	scheduleID, err := strconv.ParseInt(chi.URLParam(r, "scheduleID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid schedule ID"))
+		return
+	}
+
+	shiftID, err := strconv.ParseInt(chi.URLParam(r, "shiftID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid shift ID"))
+		return
+	}
+
+	ctx := r.Context()
+
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(ctx, restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	entries, err := app.store.AuditLog.ListByEntity(ctx, store.AuditEntityShift, shiftID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	deleted := false
+	shift, err := app.store.ScheduledShifts.GetByID(ctx, shiftID)
+	switch {
+	case err == nil:
+		if shift.RestaurantID != restaurantID || shift.ScheduleID != scheduleID {
+			app.notFoundResponse(w, r, errors.New("shift not found"))
+			return
+		}
+	case errors.Is(err, store.ErrNotFound):
+		// A deleted shift is known only by its history
+		if len(entries) == 0 || entries[0].RestaurantID != restaurantID {
+			app.notFoundResponse(w, r, errors.New("shift not found"))
+			return
+		}
+		deleted = true
+	default:
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := ShiftHistoryResponse{ShiftID: shiftID, Deleted: deleted, Entries: entries}
+
+	published, err := app.store.ScheduleSnapshots.LatestPublished(ctx, scheduleID, nil)
+	switch {
+	case err == nil:
+		for _, s := range published.Shifts {
+			if s.ID == shiftID {
+				response.Published, response.PublishedVersion = s, published.Version
+				break
+			}
+		}
+	case !errors.Is(err, store.ErrNotFound):
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// recordAudit writes audit entries without failing the request that made the changes
+func (app *application) recordAudit(ctx context.Context, entries ...*store.AuditEntry) {
+	var recorded []*store.AuditEntry
+	for _, e := range entries {
+		if e != nil {
+			recorded = append(recorded, e)
+		}
+	}
+	if len(recorded) == 0 {
+		return
+	}
+
+	if err := app.store.AuditLog.Record(ctx, recorded); err != nil {
+		app.logger.Warnw("failed to record audit log", "count", len(recorded), "error", err)
+	}
+}
+
+// auditShiftsCreated records the creation of shifts, naming the template each came from
+func (app *application) auditShiftsCreated(ctx context.Context, actorID int64, shifts ...*store.ScheduledShift) {
+	templates := make(map[int64]string)
+	entries := make([]*store.AuditEntry, 0, len(shifts))
+
+	for _, shift := range shifts {
+		template := ""
+		if shift.ShiftTemplateID != nil {
+			name, ok := templates[*shift.ShiftTemplateID]
+			if !ok {
+				if t, err := app.store.ShiftTemplates.GetByID(ctx, *shift.ShiftTemplateID); err == nil {
+					name = t.Name
+				}
+				templates[*shift.ShiftTemplateID] = name
+			}
+			template = name
+		}
+		entries = append(entries, shiftCreatedEntry(actorID, shift, template))
+	}
+
+	app.recordAudit(ctx, entries...)
+}
+
+// auditShiftChanged records an edit to a shift; after is nil when the shift was deleted
+func (app *application) auditShiftChanged(ctx context.Context, actorID int64, before, after *store.ScheduledShift) {
+	app.recordAudit(ctx, shiftChangeEntry(actorID, before, after))
+}
+
+func shiftCreatedEntry(actorID int64, shift *store.ScheduledShift, template string) *store.AuditEntry {
+	summary := "Created"
+	changes := map[string]store.AuditChange{
+		"shift_date": {To: shift.ShiftDate.Format("2006-01-02")},
+		"start_time": {To: shift.StartTime},
+		"end_time":   {To: shift.EndTime},
+		"role_id":    {To: shift.RoleID},
+	}
+
+	switch {
+	case shift.ShiftTemplateID != nil && template != "":
+		summary = fmt.Sprintf("Created from template %q", template)
+		changes["shift_template_id"] = store.AuditChange{To: *shift.ShiftTemplateID}
+	case shift.ShiftTemplateID != nil:
+		summary = fmt.Sprintf("Created from template #%d", *shift.ShiftTemplateID)
+		changes["shift_template_id"] = store.AuditChange{To: *shift.ShiftTemplateID}
+	case shift.EventID != nil:
+		summary = fmt.Sprintf("Created for event #%d", *shift.EventID)
+	}
+	if shift.EventID != nil {
+		changes["event_id"] = store.AuditChange{To: *shift.EventID}
+	}
+
+	summary += ": " + describeShift(shift)
+	if shift.EmployeeID != nil {
+		summary += ", assigned to " + shiftEmployeeLabel(shift)
+		changes["employee_id"] = store.AuditChange{To: *shift.EmployeeID}
+	}
+
+	return &store.AuditEntry{
+		RestaurantID: shift.RestaurantID,
+		EntityType:   store.AuditEntityShift,
+		EntityID:     shift.ID,
+		Action:       store.AuditCreated,
+		Summary:      summary,
+		Changes:      changes,
+		ActorUserID:  &actorID,
+	}
+}
+
+// shiftChangeEntry describes what changed between two versions of a shift, or
+// returns nil when nothing did. A change of employee alone is an assignment;
+// anything else is an update that also lists the employee change.
+func shiftChangeEntry(actorID int64, before, after *store.ScheduledShift) *store.AuditEntry {
+	if before == nil {
+		return nil
+	}
+
+	entry := &store.AuditEntry{
+		RestaurantID: before.RestaurantID,
+		EntityType:   store.AuditEntityShift,
+		EntityID:     before.ID,
+		Changes:      map[string]store.AuditChange{},
+		ActorUserID:  &actorID,
+	}
+
+	if after == nil {
+		entry.Action = store.AuditDeleted
+		entry.Summary = "Deleted: " + describeShift(before)
+		if before.EmployeeID != nil {
+			entry.Summary += ", assigned to " + shiftEmployeeLabel(before)
+			entry.Changes["employee_id"] = store.AuditChange{From: *before.EmployeeID}
+		}
+		return entry
+	}
+
+	var parts []string
+
+	if before.ShiftDate.Format("2006-01-02") != after.ShiftDate.Format("2006-01-02") {
+		entry.Changes["shift_date"] = store.AuditChange{From: before.ShiftDate.Format("2006-01-02"), To: after.ShiftDate.Format("2006-01-02")}
+		parts = append(parts, fmt.Sprintf("moved from %s to %s", i18n.FormatShortDate(i18n.English, before.ShiftDate), i18n.FormatShortDate(i18n.English, after.ShiftDate)))
+	}
+	if before.StartTime != after.StartTime || before.EndTime != after.EndTime {
+		if before.StartTime != after.StartTime {
+			entry.Changes["start_time"] = store.AuditChange{From: before.StartTime, To: after.StartTime}
+		}
+		if before.EndTime != after.EndTime {
+			entry.Changes["end_time"] = store.AuditChange{From: before.EndTime, To: after.EndTime}
+		}
+		parts = append(parts, fmt.Sprintf("time changed from %s to %s", shiftTimes(before), shiftTimes(after)))
+	}
+	if before.RoleID != after.RoleID {
+		entry.Changes["role_id"] = store.AuditChange{From: before.RoleID, To: after.RoleID}
+		parts = append(parts, fmt.Sprintf("role changed from %s to %s", before.RoleName, after.RoleName))
+	}
+	if before.Notes != after.Notes {
+		entry.Changes["notes"] = store.AuditChange{From: before.Notes, To: after.Notes}
+		parts = append(parts, "notes edited")
+	}
+	// both pointers are nullable IDs, so the employee comparison fits events too
+	if !sameEmployee(before.EventID, after.EventID) {
+		entry.Changes["event_id"] = store.AuditChange{From: before.EventID, To: after.EventID}
+		if after.EventID != nil {
+			parts = append(parts, fmt.Sprintf("linked to event #%d", *after.EventID))
+		} else {
+			parts = append(parts, "unlinked from its event")
+		}
+	}
+	if before.Training != after.Training {
+		entry.Changes["training"] = store.AuditChange{From: before.Training, To: after.Training}
+	}
+
+	employeeChanged := !sameEmployee(before.EmployeeID, after.EmployeeID)
+	if employeeChanged {
+		entry.Changes["employee_id"] = store.AuditChange{From: before.EmployeeID, To: after.EmployeeID}
+	}
+
+	switch {
+	case employeeChanged && len(parts) == 0:
+		switch {
+		case before.EmployeeID == nil:
+			entry.Action = store.AuditAssigned
+			entry.Summary = "Assigned to " + shiftEmployeeLabel(after)
+		case after.EmployeeID == nil:
+			entry.Action = store.AuditUnassigned
+			entry.Summary = "Unassigned " + shiftEmployeeLabel(before)
+		default:
+			entry.Action = store.AuditReassigned
+			entry.Summary = fmt.Sprintf("Reassigned from %s to %s", shiftEmployeeLabel(before), shiftEmployeeLabel(after))
+		}
+		if after.Training && after.EmployeeID != nil {
+			entry.Summary += " as a trainee"
+		}
+		return entry
+	case employeeChanged:
+		switch {
+		case before.EmployeeID == nil:
+			parts = append(parts, "assigned to "+shiftEmployeeLabel(after))
+		case after.EmployeeID == nil:
+			parts = append(parts, "unassigned "+shiftEmployeeLabel(before))
+		default:
+			parts = append(parts, fmt.Sprintf("reassigned from %s to %s", shiftEmployeeLabel(before), shiftEmployeeLabel(after)))
+		}
+	case len(entry.Changes) == 0:
+		return nil
+	}
+
+	entry.Action = store.AuditUpdated
+	if len(parts) == 0 {
+		// only the training flag changed
+		parts = append(parts, "training changed")
+	}
+	summary := strings.Join(parts, ", ")
+	entry.Summary = strings.ToUpper(summary[:1]) + summary[1:]
+
+	return entry
+}
+
+func shiftTimes(shift *store.ScheduledShift) string {
+	return i18n.FormatTimeOfDay(i18n.English, string(shift.StartTime)) + "–" + i18n.FormatTimeOfDay(i18n.English, string(shift.EndTime))
+}
+
+// shiftEmployeeLabel names the shift's employee as of the change, falling back to their ID
+func shiftEmployeeLabel(shift *store.ScheduledShift) string {
+	if shift.EmployeeID == nil {
+		return "nobody"
+	}
+	if shift.EmployeeName != nil && *shift.EmployeeName != "" {
+		return *shift.EmployeeName
+	}
+	return fmt.Sprintf("employee #%d", *shift.EmployeeID)
+}
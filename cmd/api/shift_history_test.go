@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestShiftChangeEntry(t *testing.T) {
+	ana, ben := int64(1), int64(2)
+	anaName, benName := "Ana", "Ben"
+
+	base := store.ScheduledShift{
+		ID:           7,
+		RestaurantID: 3,
+		RoleName:     "Server",
+		ShiftDate:    time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		StartTime:    "09:00",
+		EndTime:      "17:00",
+	}
+	with := func(change func(*store.ScheduledShift)) *store.ScheduledShift {
+		shift := base
+		change(&shift)
+		return &shift
+	}
+	assigned := func(s *store.ScheduledShift) { s.EmployeeID, s.EmployeeName = &ana, &anaName }
+
+	tests := []struct {
+		name    string
+		before  *store.ScheduledShift
+		after   *store.ScheduledShift
+		action  string
+		summary string
+	}{
+		{"assigned", &base, with(assigned), store.AuditAssigned, "Assigned to Ana"},
+		{"reassigned", with(assigned), with(func(s *store.ScheduledShift) { s.EmployeeID, s.EmployeeName = &ben, &benName }), store.AuditReassigned, "Reassigned from Ana to Ben"},
+		{"unassigned", with(assigned), &base, store.AuditUnassigned, "Unassigned Ana"},
+		{"time changed", &base, with(func(s *store.ScheduledShift) { s.StartTime = "10:00" }), store.AuditUpdated, "Time changed from 9:00 AM–5:00 PM to 10:00 AM–5:00 PM"},
+		{"deleted", &base, nil, store.AuditDeleted, "Deleted: Server shift on Mon, Mar 2, 9:00 AM–5:00 PM"},
+	}
+
+	for _, tt := range tests {
+		entry := shiftChangeEntry(5, tt.before, tt.after)
+		if entry == nil {
+			t.Fatalf("%s: expected an entry", tt.name)
+		}
+		if entry.Action != tt.action || entry.Summary != tt.summary {
+			t.Errorf("%s: got %s %q, want %s %q", tt.name, entry.Action, entry.Summary, tt.action, tt.summary)
+		}
+		if entry.EntityID != 7 || entry.RestaurantID != 3 || *entry.ActorUserID != 5 {
+			t.Errorf("%s: wrong entity or actor: %+v", tt.name, entry)
+		}
+	}
+
+	// erasing an employee finds the entries naming them through employee_id
+	if entry := shiftChangeEntry(5, with(assigned), nil); entry.Changes["employee_id"].From != ana {
+		t.Errorf("deleted assigned shift: got changes %+v, want employee_id from %d", entry.Changes, ana)
+	}
+
+	if entry := shiftChangeEntry(5, &base, with(func(*store.ScheduledShift) {})); entry != nil {
+		t.Errorf("unchanged shift: got %+v, want no entry", entry)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultDuplicateToleranceMinutes = 15
+	maxDuplicateToleranceMinutes     = 120
+	defaultDuplicateMinRoleOverlap   = 0.5
+)
+
+// ShiftTemplateDuplicates is a cluster of near-identical templates on the same day
+type ShiftTemplateDuplicates struct {
+	DayOfWeek int `json:"day_of_week"`
+	// KeepID is the suggested template to merge the others into, the oldest one
+	KeepID int64 `json:"keep_id"`
+	// RoleOverlap is the lowest share of roles any template has in common with the first, 1 when identical
+	RoleOverlap float64                `json:"role_overlap"`
+	Templates   []*store.ShiftTemplate `json:"templates"`
+}
+
+type MergeShiftTemplatesPayload struct {
+	KeepID   int64   `json:"keep_id" validate:"required"`
+	MergeIDs []int64 `json:"merge_ids" validate:"required,min=1,dive,required"`
+}
+
+// ShiftTemplateMergeResult is the kept template after a merge
+type ShiftTemplateMergeResult struct {
+	Template        *store.ShiftTemplate `json:"template"`
+	MergedIDs       []int64              `json:"merged_ids"`
+	ShiftsRepointed int64                `json:"shifts_repointed"`
+}
+
+// GetShiftTemplateDuplicates godoc
+//
+//	@Summary		Finds near-duplicate shift templates
+//	@Description	Clusters a restaurant's templates that fall on the same day, start and end within the tolerance of each other and share most of their roles. Each cluster suggests the oldest template to keep; pass it with the others to the merge endpoint.
+//	@Tags			shift-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id		path		int		true	"Restaurant ID"
+//	@Param			tolerance_minutes	query		int		false	"How far apart start and end times may be (default 15, max 120)"
+//	@Param			min_role_overlap	query		number	false	"Minimum share of roles in common between 0 and 1 (default 0.5)"
+//	@Success		200					{array}		ShiftTemplateDuplicates
+//	@Failure		400					{object}	error
+//	@Failure		401					{object}	error
+//	@Failure		404					{object}	error
+//	@Failure		500					{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/shift-templates/duplicates [get]
+func (app *application) getShiftTemplateDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurantID, err := strconv.ParseInt(chi.URLParam(r, "restaurantID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	tolerance := defaultDuplicateToleranceMinutes
+	if toleranceStr := r.URL.Query().Get("tolerance_minutes"); toleranceStr != "" {
+		tolerance, err = strconv.Atoi(toleranceStr)
+		if err != nil || tolerance < 0 || tolerance > maxDuplicateToleranceMinutes {
+			app.badRequestResponse(w, r, fmt.Errorf("tolerance_minutes must be between 0 and %d", maxDuplicateToleranceMinutes))
+			return
+		}
+	}
+
+	minOverlap := defaultDuplicateMinRoleOverlap
+	if overlapStr := r.URL.Query().Get("min_role_overlap"); overlapStr != "" {
+		minOverlap, err = strconv.ParseFloat(overlapStr, 64)
+		if err != nil || minOverlap < 0 || minOverlap > 1 {
+			app.badRequestResponse(w, r, errors.New("min_role_overlap must be between 0 and 1"))
+			return
+		}
+	}
+
+	templates, err := app.store.ShiftTemplates.ListByRestaurant(r.Context(), restaurantID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	duplicates := duplicateShiftTemplates(templates, time.Duration(tolerance)*time.Minute, minOverlap)
+	if err := app.jsonResponse(w, r, http.StatusOK, duplicates); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// MergeShiftTemplates godoc
+//
+//	@Summary		Merges shift templates into one
+//	@Description	Keeps keep_id, gives it the roles of every merged template, re-points the scheduled shifts made from the merged templates to it and deletes them, all in one transaction
+//	@Tags			shift-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int							true	"Restaurant ID"
+//	@Param			payload			body		MergeShiftTemplatesPayload	true	"Template to keep and templates to merge into it"
+//	@Success		200				{object}	ShiftTemplateMergeResult
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/shift-templates/merge [post]
+func (app *application) mergeShiftTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurantID, err := strconv.ParseInt(chi.URLParam(r, "restaurantID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var payload MergeShiftTemplatesPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	seen := map[int64]bool{payload.KeepID: true}
+	for _, id := range payload.MergeIDs {
+		if seen[id] {
+			app.badRequestResponse(w, r, fmt.Errorf("template %d is listed more than once or is also keep_id", id))
+			return
+		}
+		seen[id] = true
+	}
+
+	template, err := app.store.ShiftTemplates.GetByID(r.Context(), payload.KeepID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Verify template belongs to this restaurant
+	if template.RestaurantID != restaurantID {
+		app.notFoundResponse(w, r, errors.New("shift template not found"))
+		return
+	}
+
+	repointed, err := app.store.ShiftTemplates.Merge(r.Context(), template, payload.MergeIDs)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	result := &ShiftTemplateMergeResult{
+		Template:        template,
+		MergedIDs:       payload.MergeIDs,
+		ShiftsRepointed: repointed,
+	}
+	if err := app.jsonResponse(w, r, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// duplicateShiftTemplates clusters templates around the oldest one of each
+// cluster: same day, start and end within tolerance of it and at least
+// minOverlap of its roles in common. Templates in no cluster are left out.
+func duplicateShiftTemplates(templates []*store.ShiftTemplate, tolerance time.Duration, minOverlap float64) []ShiftTemplateDuplicates {
+	sorted := append([]*store.ShiftTemplate{}, templates...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	clustered := make(map[int64]bool)
+	duplicates := []ShiftTemplateDuplicates{}
+	for i, seed := range sorted {
+		if clustered[seed.ID] {
+			continue
+		}
+
+		group := ShiftTemplateDuplicates{
+			DayOfWeek:   seed.DayOfWeek,
+			KeepID:      seed.ID,
+			RoleOverlap: 1,
+			Templates:   []*store.ShiftTemplate{seed},
+		}
+		for _, other := range sorted[i+1:] {
+			if clustered[other.ID] || other.DayOfWeek != seed.DayOfWeek {
+				continue
+			}
+			if !withinTolerance(seed.StartTime, other.StartTime, tolerance) || !withinTolerance(seed.EndTime, other.EndTime, tolerance) {
+				continue
+			}
+			overlap := roleOverlap(seed.RoleIDs, other.RoleIDs)
+			if overlap < minOverlap {
+				continue
+			}
+
+			group.Templates = append(group.Templates, other)
+			group.RoleOverlap = math.Min(group.RoleOverlap, overlap)
+		}
+
+		if len(group.Templates) < 2 {
+			continue
+		}
+		for _, t := range group.Templates {
+			clustered[t.ID] = true
+		}
+		group.RoleOverlap = math.Round(group.RoleOverlap*100) / 100
+		duplicates = append(duplicates, group)
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].DayOfWeek < duplicates[j].DayOfWeek
+	})
+	return duplicates
+}
+
+// withinTolerance reports whether two times of day are at most tolerance apart
+func withinTolerance(a, b store.TimeOfDay, tolerance time.Duration) bool {
+	ta, errA := time.Parse("15:04", hourMinute(a))
+	tb, errB := time.Parse("15:04", hourMinute(b))
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	diff := ta.Sub(tb)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= tolerance
+}
+
+// roleOverlap is the share of roles two templates have in common (Jaccard index),
+// 1 when neither has roles
+func roleOverlap(a, b []int64) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	set := make(map[int64]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+	common, union := 0, len(set)
+	seen := make(map[int64]bool, len(b))
+	for _, id := range b {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if set[id] {
+			common++
+		} else {
+			union++
+		}
+	}
+	return float64(common) / float64(union)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestDuplicateShiftTemplates(t *testing.T) {
+	templates := []*store.ShiftTemplate{
+		{ID: 4, DayOfWeek: 1, StartTime: "11:10:00", EndTime: "15:00:00", RoleIDs: []int64{1, 2}},
+		{ID: 1, DayOfWeek: 1, StartTime: "11:00:00", EndTime: "15:00:00", RoleIDs: []int64{1}},
+		{ID: 2, DayOfWeek: 1, StartTime: "17:00:00", EndTime: "22:00:00", RoleIDs: []int64{1}},
+		{ID: 3, DayOfWeek: 2, StartTime: "11:00:00", EndTime: "15:00:00", RoleIDs: []int64{1}},
+		{ID: 5, DayOfWeek: 1, StartTime: "11:00:00", EndTime: "15:00:00", RoleIDs: []int64{3}},
+	}
+
+	duplicates := duplicateShiftTemplates(templates, 15*time.Minute, 0.5)
+
+	if len(duplicates) != 1 {
+		t.Fatalf("clusters = %+v, want one", duplicates)
+	}
+	group := duplicates[0]
+	if group.KeepID != 1 || len(group.Templates) != 2 || group.Templates[1].ID != 4 || group.RoleOverlap != 0.5 {
+		t.Errorf("cluster = %+v, want templates 1 and 4 keeping 1", group)
+	}
+
+	if tight := duplicateShiftTemplates(templates, 5*time.Minute, 0.5); len(tight) != 0 {
+		t.Errorf("clusters with a 5 minute tolerance = %+v, want none", tight)
+	}
+}
+
+func TestRoleOverlap(t *testing.T) {
+	tests := []struct {
+		a, b []int64
+		want float64
+	}{
+		{nil, nil, 1},
+		{[]int64{1, 2}, []int64{2, 1}, 1},
+		{[]int64{1, 2}, []int64{2, 3}, 1.0 / 3},
+		{[]int64{1}, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := roleOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("roleOverlap(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMergeShiftTemplates(t *testing.T) {
+	setup := func(t *testing.T, templateRestaurant int64) (*application, *[]int64) {
+		app, _ := newMockedApplication(t, testUserID)
+		merged := new([]int64)
+		app.store.ShiftTemplates = &store.MockShiftTemplateStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.ShiftTemplate, error) {
+				return &store.ShiftTemplate{ID: id, RestaurantID: templateRestaurant}, nil
+			},
+			MergeFunc: func(_ context.Context, _ *store.ShiftTemplate, ids []int64) (int64, error) {
+				*merged = ids
+				return 3, nil
+			},
+		}
+		return app, merged
+	}
+
+	t.Run("merged", func(t *testing.T) {
+		app, merged := setup(t, 3)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/shift-templates/merge", `{"keep_id": 1, "merge_ids": [4, 5]}`), app.mount())
+
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*merged) != 2 {
+			t.Errorf("merged %v, want templates 4 and 5", *merged)
+		}
+	})
+
+	t.Run("keep_id also merged", func(t *testing.T) {
+		app, merged := setup(t, 3)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/shift-templates/merge", `{"keep_id": 1, "merge_ids": [1, 5]}`), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if *merged != nil {
+			t.Error("templates were merged")
+		}
+	})
+
+	t.Run("template of another restaurant", func(t *testing.T) {
+		app, merged := setup(t, 8)
+
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/shift-templates/merge", `{"keep_id": 1, "merge_ids": [4]}`), app.mount())
+
+		checkResponseCode(t, http.StatusNotFound, rr.Code)
+		if *merged != nil {
+			t.Error("templates were merged")
+		}
+	})
+}
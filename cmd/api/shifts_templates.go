@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,6 +23,11 @@ type CreateShiftTemplatePayload struct {
 	EndTime      string  `json:"end_time" validate:"required"`
 	Notes        string  `json:"notes,omitempty"`
 	RoleIDs      []int64 `json:"role_ids,omitempty"`
+	// EffectiveFrom and EffectiveUntil (YYYY-MM-DD, inclusive) limit a seasonal template to its window
+	EffectiveFrom  string `json:"effective_from,omitempty"`
+	EffectiveUntil string `json:"effective_until,omitempty"`
+	// WeekParity (a or b) limits the template to that week of the restaurant's two-week rotation
+	WeekParity string `json:"week_parity,omitempty" validate:"omitempty,oneof=a b"`
 }
 
 type UpdateShiftTemplatePayload struct {
@@ -30,17 +37,24 @@ type UpdateShiftTemplatePayload struct {
 	EndTime      *string  `json:"end_time,omitempty" validate:"omitempty"`
 	Notes        *string  `json:"notes,omitempty"`
 	RoleIDs      []int64  `json:"role_ids,omitempty"`
+	// EffectiveFrom and EffectiveUntil replace the template's window; an empty date opens that side
+	EffectiveFrom  *string `json:"effective_from,omitempty"`
+	EffectiveUntil *string `json:"effective_until,omitempty"`
+	// WeekParity replaces the template's rotation week (a or b); an empty string applies it every week
+	WeekParity *string `json:"week_parity,omitempty"`
 }
 
 // GetShiftTemplates godoc
 //
 //	@Summary		Lists restaurant's shift templates
-//	@Description	Fetches all shift templates for a restaurant
+//	@Description	Fetches all shift templates for a restaurant; active_on lists only those whose effective window includes that day and, for week_parity templates, whose rotation week it is
 //	@Tags			shift-template
 //	@Accept			json
 //	@Produce		json
-//	@Param			restaurant_id	path		int	true	"Restaurant ID"
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			active_on		query		string	false	"Only templates in effect on this day (YYYY-MM-DD)"
 //	@Success		200				{array}		store.ShiftTemplate
+//	@Failure		400				{object}	error
 //	@Failure		401				{object}	error
 //	@Failure		404				{object}	error
 //	@Failure		500				{object}	error
@@ -54,8 +68,8 @@ func (app *application) getShiftTemplatesHandler(w http.ResponseWriter, r *http.
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -64,10 +78,9 @@ func (app *application) getShiftTemplatesHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+	activeOn, err := optionalDate("active_on", r.URL.Query().Get("active_on"))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
@@ -77,7 +90,17 @@ func (app *application) getShiftTemplatesHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, templates)
+	if activeOn != nil {
+		active := []*store.ShiftTemplate{}
+		for _, template := range templates {
+			if template.ActiveOn(*activeOn, getRestaurantFromContext(r).RotationAnchor) {
+				active = append(active, template)
+			}
+		}
+		templates = active
+	}
+
+	err = app.jsonResponse(w, r, http.StatusOK, templates)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -87,7 +110,7 @@ func (app *application) getShiftTemplatesHandler(w http.ResponseWriter, r *http.
 // CreateShiftTemplate godoc
 //
 //	@Summary		Creates a shift template
-//	@Description	Creates a shift template for a restaurant
+//	@Description	Creates a shift template for a restaurant. week_parity a or b makes it apply every other week, counted in seven-day weeks from the restaurant's rotation_anchor (week a); such templates are skipped until the restaurant sets one.
 //	@Tags			shift-template
 //	@Accept			json
 //	@Produce		json
@@ -108,8 +131,8 @@ func (app *application) createShiftTemplateHandler(w http.ResponseWriter, r *htt
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -118,13 +141,6 @@ func (app *application) createShiftTemplateHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	var payload CreateShiftTemplatePayload
 	if err := readJSON(w, r, &payload); err != nil {
 		app.badRequestResponse(w, r, err)
@@ -153,6 +169,26 @@ func (app *application) createShiftTemplateHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
+	effectiveFrom, err := optionalDate("effective_from", payload.EffectiveFrom)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	effectiveUntil, err := optionalDate("effective_until", payload.EffectiveUntil)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := checkEffectiveWindow(effectiveFrom, effectiveUntil); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	weekParity, err := optionalWeekParity(payload.WeekParity)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	// Initialize role_ids to empty slice if not provided
 	roleIDs := payload.RoleIDs
 	if roleIDs == nil {
@@ -160,13 +196,16 @@ func (app *application) createShiftTemplateHandler(w http.ResponseWriter, r *htt
 	}
 
 	template := &store.ShiftTemplate{
-		RestaurantID: restaurantID,
-		Name:         payload.Name,
-		DayOfWeek:    payload.DayOfWeek,
-		StartTime:    store.TimeOfDay(payload.StartTime),
-		EndTime:      store.TimeOfDay(payload.EndTime),
-		Notes:        payload.Notes,
-		RoleIDs:      roleIDs,
+		RestaurantID:   restaurantID,
+		Name:           payload.Name,
+		DayOfWeek:      payload.DayOfWeek,
+		StartTime:      store.TimeOfDay(payload.StartTime),
+		EndTime:        store.TimeOfDay(payload.EndTime),
+		Notes:          payload.Notes,
+		RoleIDs:        roleIDs,
+		EffectiveFrom:  effectiveFrom,
+		EffectiveUntil: effectiveUntil,
+		WeekParity:     weekParity,
 	}
 
 	if err := app.store.ShiftTemplates.Create(r.Context(), template); err != nil {
@@ -174,7 +213,7 @@ func (app *application) createShiftTemplateHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusCreated, template)
+	err = app.jsonResponse(w, r, http.StatusCreated, template)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -210,8 +249,8 @@ func (app *application) getShiftTemplateHandler(w http.ResponseWriter, r *http.R
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -220,13 +259,6 @@ func (app *application) getShiftTemplateHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get the shift template
 	template, err := app.store.ShiftTemplates.GetByID(r.Context(), templateID)
 	if err != nil {
@@ -245,7 +277,7 @@ func (app *application) getShiftTemplateHandler(w http.ResponseWriter, r *http.R
 	}
 
 	// RoleIDs are already populated from GetByID (stored in JSONB column)
-	err = app.jsonResponse(w, http.StatusOK, template)
+	err = app.jsonResponse(w, r, http.StatusOK, template)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -283,8 +315,8 @@ func (app *application) updateShiftTemplateHandler(w http.ResponseWriter, r *htt
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -293,13 +325,6 @@ func (app *application) updateShiftTemplateHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing template
 	template, err := app.store.ShiftTemplates.GetByID(r.Context(), templateID)
 	if err != nil {
@@ -385,13 +410,36 @@ func (app *application) updateShiftTemplateHandler(w http.ResponseWriter, r *htt
 	}
 	// If payload.RoleIDs is nil, keep existing template.RoleIDs (already populated from GetByID)
 
+	if payload.EffectiveFrom != nil {
+		if template.EffectiveFrom, err = optionalDate("effective_from", *payload.EffectiveFrom); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if payload.EffectiveUntil != nil {
+		if template.EffectiveUntil, err = optionalDate("effective_until", *payload.EffectiveUntil); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if err := checkEffectiveWindow(template.EffectiveFrom, template.EffectiveUntil); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if payload.WeekParity != nil {
+		if template.WeekParity, err = optionalWeekParity(*payload.WeekParity); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+
 	// Save updates (including role_ids stored as JSONB)
 	if err := app.store.ShiftTemplates.Update(r.Context(), template); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, template)
+	err = app.jsonResponse(w, r, http.StatusOK, template)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
@@ -427,8 +475,8 @@ func (app *application) deleteShiftTemplateHandler(w http.ResponseWriter, r *htt
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -437,13 +485,6 @@ func (app *application) deleteShiftTemplateHandler(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get existing template to verify ownership
 	template, err := app.store.ShiftTemplates.GetByID(r.Context(), templateID)
 	if err != nil {
@@ -503,8 +544,8 @@ func (app *application) getShiftTemplateRolesHandler(w http.ResponseWriter, r *h
 	}
 
 	// Check if restaurant exists and user has access to it
-	restaurant, err := app.store.Restaurants.GetByID(r.Context(), restaurantID)
-	if err != nil {
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
 		if errors.Is(err, store.ErrNotFound) {
 			app.notFoundResponse(w, r, err)
 			return
@@ -513,13 +554,6 @@ func (app *application) getShiftTemplateRolesHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	// Check if user owns this restaurant
-	user := getUserFromContext(r)
-	if restaurant.UserID != user.ID {
-		app.notFoundResponse(w, r, errors.New("restaurant not found"))
-		return
-	}
-
 	// Get the shift template to verify it belongs to this restaurant
 	template, err := app.store.ShiftTemplates.GetByID(r.Context(), templateID)
 	if err != nil {
@@ -537,29 +571,194 @@ func (app *application) getShiftTemplateRolesHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	// Fetch full role details for each role_id in the template
-	var roles []*store.Role
-	for _, roleID := range template.RoleIDs {
-		role, err := app.store.Roles.GetByID(r.Context(), roleID)
-		if err != nil {
-			// Skip roles that may have been deleted
-			if errors.Is(err, store.ErrNotFound) {
-				continue
-			}
-			app.internalServerError(w, r, err)
+	// Fetch full role details for the template's role_ids in one query (deleted roles are skipped)
+	roles, err := app.store.Roles.GetByIDs(r.Context(), template.RoleIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	err = app.jsonResponse(w, r, http.StatusOK, roles)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+}
+const (
+	defaultSuggestionWeeks         = 8
+	maxSuggestionWeeks             = 52
+	defaultSuggestionMinConfidence = 0.5
+)
+
+// ShiftTemplateSuggestion is a recurring shift pattern not yet covered by a template
+type ShiftTemplateSuggestion struct {
+	DayOfWeek        int                        `json:"day_of_week"`
+	StartTime        string                     `json:"start_time"`
+	EndTime          string                     `json:"end_time"`
+	RoleID           int64                      `json:"role_id"`
+	RoleName         string                     `json:"role_name"`
+	WeeksSeen        int                        `json:"weeks_seen"`
+	TypicalHeadcount int                        `json:"typical_headcount"`
+	Confidence       float64                    `json:"confidence"`
+	CreatePayload    CreateShiftTemplatePayload `json:"create_payload"`
+}
+
+// GetShiftTemplateSuggestions godoc
+//
+//	@Summary		Suggests shift templates from past shifts
+//	@Description	Mines the last N weeks of scheduled shifts for recurring day/time/role patterns that no template covers yet. Confidence is the share of weeks the pattern appeared in; create_payload can be posted as-is to create the template.
+//	@Tags			shift-template
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurant_id	path		int		true	"Restaurant ID"
+//	@Param			weeks			query		int		false	"Weeks of history to analyze (default 8, max 52)"
+//	@Param			min_confidence	query		number	false	"Minimum confidence between 0 and 1 (default 0.5)"
+//	@Success		200				{array}		ShiftTemplateSuggestion
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurant_id}/shift-templates/suggestions [get]
+func (app *application) getShiftTemplateSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurantID, err := strconv.ParseInt(chi.URLParam(r, "restaurantID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Check if restaurant exists and user has access to it
+	user := getUserFromContext(r)
+	if err := app.checkRestaurantAccess(r.Context(), restaurantID, user.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	weeks := defaultSuggestionWeeks
+	if weeksStr := r.URL.Query().Get("weeks"); weeksStr != "" {
+		weeks, err = strconv.Atoi(weeksStr)
+		if err != nil || weeks < 1 || weeks > maxSuggestionWeeks {
+			app.badRequestResponse(w, r, fmt.Errorf("weeks must be between 1 and %d", maxSuggestionWeeks))
 			return
 		}
-		roles = append(roles, role)
 	}
 
-	// Return empty array instead of null if no roles
-	if roles == nil {
-		roles = []*store.Role{}
+	minConfidence := defaultSuggestionMinConfidence
+	if confidenceStr := r.URL.Query().Get("min_confidence"); confidenceStr != "" {
+		minConfidence, err = strconv.ParseFloat(confidenceStr, 64)
+		if err != nil || minConfidence < 0 || minConfidence > 1 {
+			app.badRequestResponse(w, r, errors.New("min_confidence must be between 0 and 1"))
+			return
+		}
 	}
 
-	err = app.jsonResponse(w, http.StatusOK, roles)
+	since := time.Now().AddDate(0, 0, -7*weeks)
+	patterns, err := app.store.ScheduledShifts.ListPatterns(r.Context(), restaurantID, since)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	templates, err := app.store.ShiftTemplates.ListByRestaurant(r.Context(), restaurantID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-}
\ No newline at end of file
+
+	suggestions := []ShiftTemplateSuggestion{}
+	for _, p := range patterns {
+		if templateCoversPattern(templates, p) {
+			continue
+		}
+
+		confidence := math.Min(1, float64(p.WeeksSeen)/float64(weeks))
+		if confidence < minConfidence {
+			continue
+		}
+
+		startTime := hourMinute(p.StartTime)
+		endTime := hourMinute(p.EndTime)
+
+		suggestions = append(suggestions, ShiftTemplateSuggestion{
+			DayOfWeek:        p.DayOfWeek,
+			StartTime:        startTime,
+			EndTime:          endTime,
+			RoleID:           p.RoleID,
+			RoleName:         p.RoleName,
+			WeeksSeen:        p.WeeksSeen,
+			TypicalHeadcount: int(math.Round(p.AvgHeadcount)),
+			Confidence:       math.Round(confidence*100) / 100,
+			CreatePayload: CreateShiftTemplatePayload{
+				Name:      fmt.Sprintf("%s %s %s-%s", time.Weekday(p.DayOfWeek), p.RoleName, startTime, endTime),
+				DayOfWeek: p.DayOfWeek,
+				StartTime: startTime,
+				EndTime:   endTime,
+				RoleIDs:   []int64{p.RoleID},
+			},
+		})
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, suggestions); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// optionalDate parses a YYYY-MM-DD date from a payload or query; an empty one is nil
+func optionalDate(field, value string) (*store.DateOnly, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", field)
+	}
+	date := store.DateOnly(value)
+	return &date, nil
+}
+
+// checkEffectiveWindow rejects a template window that ends before it starts
+func checkEffectiveWindow(from, until *store.DateOnly) error {
+	if from != nil && until != nil && *until < *from {
+		return errors.New("effective_until must not be before effective_from")
+	}
+	return nil
+}
+
+// optionalWeekParity is the rotation week a or b, or nil for every week when empty
+func optionalWeekParity(value string) (*store.WeekParity, error) {
+	switch parity := store.WeekParity(value); parity {
+	case "":
+		return nil, nil
+	case store.WeekA, store.WeekB:
+		return &parity, nil
+	default:
+		return nil, errors.New("week_parity must be a or b")
+	}
+}
+
+// templateCoversPattern reports whether an existing template already produces the pattern's shifts
+func templateCoversPattern(templates []*store.ShiftTemplate, p *store.ShiftPattern) bool {
+	for _, template := range templates {
+		if template.DayOfWeek != p.DayOfWeek || template.StartTime != p.StartTime || template.EndTime != p.EndTime {
+			continue
+		}
+		for _, roleID := range template.RoleIDs {
+			if roleID == p.RoleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hourMinute trims a TimeOfDay ("HH:MM:SS") to the "HH:MM" form the create endpoints accept
+func hourMinute(t store.TimeOfDay) string {
+	s := string(t)
+	if len(s) >= 5 {
+		return s[:5]
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestShiftTemplateEffectiveWindow(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	summerFrom, summerUntil := store.DateOnly("2026-06-01"), store.DateOnly("2026-08-31")
+	created := 0
+	app.store.ShiftTemplates = &store.MockShiftTemplateStorer{
+		ListByRestaurantFunc: func(context.Context, int64) ([]*store.ShiftTemplate, error) {
+			return []*store.ShiftTemplate{
+				{ID: 1, RestaurantID: 3, Name: "Lunch"},
+				{ID: 2, RestaurantID: 3, Name: "Patio", EffectiveFrom: &summerFrom, EffectiveUntil: &summerUntil},
+			}, nil
+		},
+		CreateFunc: func(context.Context, *store.ShiftTemplate) error {
+			created++
+			return nil
+		},
+	}
+
+	listActiveOn := func(t *testing.T, date string) []store.ShiftTemplate {
+		t.Helper()
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/shift-templates?active_on="+date, ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data []store.ShiftTemplate `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Data
+	}
+
+	t.Run("active_on filters by the window", func(t *testing.T) {
+		if got := listActiveOn(t, "2026-07-04"); len(got) != 2 {
+			t.Errorf("in July: %d templates, want 2", len(got))
+		}
+		if got := listActiveOn(t, "2026-09-01"); len(got) != 1 || got[0].ID != 1 {
+			t.Errorf("in September: %+v, want only the year-round template", got)
+		}
+	})
+
+	t.Run("rejects a bad active_on", func(t *testing.T) {
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/3/shift-templates?active_on=July", ""), app.mount())
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("rejects a window that ends before it starts", func(t *testing.T) {
+		body := `{"name":"Patio","day_of_week":5,"start_time":"17:00","end_time":"22:00","effective_from":"2026-08-31","effective_until":"2026-06-01"}`
+		rr := executeRequest(authedRequest(t, app, http.MethodPost, "/v1/restaurants/3/shift-templates", body), app.mount())
+
+		checkResponseCode(t, http.StatusBadRequest, rr.Code)
+		if created != 0 {
+			t.Error("template was created")
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/balebbae/RESA/internal/i18n"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// milestoneDigestDays is how far ahead the weekly staff milestone digest looks
+const milestoneDigestDays = 7
+
+const (
+	MilestoneBirthday    = "birthday"
+	MilestoneAnniversary = "anniversary"
+)
+
+// StaffMilestone is an employee's birthday or work anniversary falling on Date;
+// Years is how many years the anniversary marks
+type StaffMilestone struct {
+	EmployeeID   int64          `json:"employee_id"`
+	EmployeeName string         `json:"employee_name"`
+	Kind         string         `json:"kind"`
+	Date         store.DateOnly `json:"date"`
+	Years        int            `json:"years,omitempty"`
+}
+
+// upcomingMilestones lists the birthdays and anniversaries falling within days of from,
+// earliest first. A Feb 29 date is celebrated on Feb 28 in other years.
+func upcomingMilestones(employees []*store.Employee, from time.Time, days int) []StaffMilestone {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, days)
+
+	// next returns the first occurrence of date's month and day on or after start
+	next := func(date time.Time) (time.Time, bool) {
+		for _, year := range []int{start.Year(), start.Year() + 1} {
+			day := date.Day()
+			if date.Month() == time.February && day == 29 && !isLeapYear(year) {
+				day = 28
+			}
+			occurs := time.Date(year, date.Month(), day, 0, 0, 0, 0, time.UTC)
+			if !occurs.Before(start) {
+				return occurs, occurs.Before(end)
+			}
+		}
+		return time.Time{}, false
+	}
+
+	milestones := []StaffMilestone{}
+	for _, employee := range employees {
+		if employee.Birthday != nil {
+			if birthday, err := employee.Birthday.ToTime(); err == nil {
+				if occurs, ok := next(birthday); ok {
+					milestones = append(milestones, StaffMilestone{
+						EmployeeID:   employee.ID,
+						EmployeeName: employee.FullName,
+						Kind:         MilestoneBirthday,
+						Date:         store.DateOnly(occurs.Format("2006-01-02")),
+					})
+				}
+			}
+		}
+
+		if employee.HireDate != nil {
+			if hired, err := employee.HireDate.ToTime(); err == nil {
+				// the hire date itself isn't an anniversary
+				if occurs, ok := next(hired); ok && occurs.Year() > hired.Year() {
+					milestones = append(milestones, StaffMilestone{
+						EmployeeID:   employee.ID,
+						EmployeeName: employee.FullName,
+						Kind:         MilestoneAnniversary,
+						Date:         store.DateOnly(occurs.Format("2006-01-02")),
+						Years:        occurs.Year() - hired.Year(),
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(milestones, func(i, j int) bool {
+		if milestones[i].Date != milestones[j].Date {
+			return milestones[i].Date < milestones[j].Date
+		}
+		return milestones[i].EmployeeName < milestones[j].EmployeeName
+	})
+	return milestones
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// runMilestoneDigests periodically sends each opted-in restaurant's owner the week's staff
+// birthdays and anniversaries, at most once every milestoneDigestDays
+func (app *application) runMilestoneDigests(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sent, err := app.sendMilestoneDigests(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("staff milestone digest failed", "error", err)
+			continue
+		}
+
+		if sent > 0 {
+			app.logger.Infow("sent staff milestone digests", "count", sent)
+		}
+	}
+}
+
+// sendMilestoneDigests claims the restaurants whose digest is due and sends each owner
+// theirs, returning how many went out. A restaurant is claimed before its digest is sent
+// so that several instances never send it twice; a failed send waits for next week.
+func (app *application) sendMilestoneDigests(ctx context.Context, now time.Time) (int, error) {
+	today := store.DateOnly(now.Format("2006-01-02"))
+	restaurants, err := app.store.Restaurants.ClaimMilestoneDigests(ctx, today)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, restaurant := range restaurants {
+		ok, err := app.sendMilestoneDigest(ctx, restaurant, now)
+		if err != nil {
+			app.logger.Warnw("failed to send staff milestone digest", "restaurant_id", restaurant.ID, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// sendMilestoneDigest notifies and emails the restaurant's owner, reporting whether it
+// did; nothing is sent when no milestones are coming up
+func (app *application) sendMilestoneDigest(ctx context.Context, restaurant *store.Restaurant, now time.Time) (bool, error) {
+	employees, err := app.store.Employees.ListByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		return false, err
+	}
+
+	milestones := upcomingMilestones(employees, now, milestoneDigestDays)
+	if len(milestones) == 0 {
+		return false, nil
+	}
+
+	owner, err := app.store.Users.GetByID(ctx, restaurant.UserID)
+	if err != nil {
+		return false, err
+	}
+	locale := i18n.Resolve(owner.Locale)
+
+	data, _ := json.Marshal(map[string]any{"milestones": milestones})
+	app.notify(ctx, []*store.Notification{{
+		RestaurantID: restaurant.ID,
+		UserID:       &owner.ID,
+		Type:         store.NotificationStaffMilestones,
+		Title:        "Staff birthdays and anniversaries this week",
+		Body:         fmt.Sprintf("%d coming up at %s", len(milestones), restaurant.Name),
+		Data:         data,
+	}})
+
+	type milestoneEmailItem struct {
+		EmployeeName string
+		Kind         string
+		Date         string
+		Years        int
+	}
+	items := make([]milestoneEmailItem, 0, len(milestones))
+	for _, milestone := range milestones {
+		date, _ := milestone.Date.ToTime()
+		items = append(items, milestoneEmailItem{
+			EmployeeName: milestone.EmployeeName,
+			Kind:         milestone.Kind,
+			Date:         i18n.FormatWeekday(locale, date),
+			Years:        milestone.Years,
+		})
+	}
+
+	emailData := struct {
+		ManagerName    string
+		RestaurantName string
+		From           string
+		Until          string
+		Milestones     []milestoneEmailItem
+	}{
+		ManagerName:    owner.FirstName,
+		RestaurantName: restaurant.Name,
+		From:           i18n.FormatWeekday(locale, now),
+		Until:          i18n.FormatWeekday(locale, now.AddDate(0, 0, milestoneDigestDays-1)),
+		Milestones:     items,
+	}
+
+	isProdEnv := app.config.env == "production"
+	if _, err := app.mailer.Send(mailer.Localized(mailer.StaffMilestonesTemplate, locale), owner.FirstName, owner.Email, emailData, !isProdEnv); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// fakeMailer records who was emailed with which template
+type fakeMailer struct {
+	sent        []string
+	attachments []string
+}
+
+func (m *fakeMailer) Send(templateFile, username, email string, data any, isSandbox bool) (int, error) {
+	m.sent = append(m.sent, templateFile+" "+email)
+	return 200, nil
+}
+
+func (m *fakeMailer) SendTracked(templateFile, username, email string, data any, isSandbox bool, trackingID string) (int, error) {
+	return m.Send(templateFile, username, email, data, isSandbox)
+}
+
+func (m *fakeMailer) SendWithAttachments(templateFile, username, email string, data any, isSandbox bool, attachments []mailer.Attachment) (int, error) {
+	for _, a := range attachments {
+		m.attachments = append(m.attachments, a.Filename)
+	}
+	return m.Send(templateFile, username, email, data, isSandbox)
+}
+
+func TestUpcomingMilestones(t *testing.T) {
+	date := func(s string) *store.DateOnly {
+		d := store.DateOnly(s)
+		return &d
+	}
+	employees := []*store.Employee{
+		{ID: 1, FullName: "Ana", Birthday: date("1990-10-20"), HireDate: date("2021-10-16")},
+		{ID: 2, FullName: "Ben", Birthday: date("1992-02-29"), HireDate: date("2026-10-18")},
+		{ID: 3, FullName: "Cy", Birthday: date("1985-10-23")},
+		{ID: 4, FullName: "Dee"},
+	}
+
+	t.Run("lists the week's milestones earliest first", func(t *testing.T) {
+		got := upcomingMilestones(employees, time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), 7)
+
+		want := []StaffMilestone{
+			{EmployeeID: 1, EmployeeName: "Ana", Kind: MilestoneAnniversary, Date: "2026-10-16", Years: 5},
+			{EmployeeID: 1, EmployeeName: "Ana", Kind: MilestoneBirthday, Date: "2026-10-20"},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("milestones = %+v, want %+v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("milestone %d = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("a leap day birthday falls on Feb 28 in other years", func(t *testing.T) {
+		got := upcomingMilestones(employees, time.Date(2027, 2, 25, 0, 0, 0, 0, time.UTC), 7)
+
+		if len(got) != 1 || got[0].EmployeeID != 2 || got[0].Date != "2027-02-28" {
+			t.Errorf("milestones = %+v, want Ben's birthday on 2027-02-28", got)
+		}
+	})
+
+	t.Run("wraps into the new year", func(t *testing.T) {
+		newYear := []*store.Employee{{ID: 5, FullName: "Eve", Birthday: date("2000-01-02")}}
+		got := upcomingMilestones(newYear, time.Date(2026, 12, 30, 0, 0, 0, 0, time.UTC), 7)
+
+		if len(got) != 1 || got[0].Date != "2027-01-02" {
+			t.Errorf("milestones = %+v, want Eve's birthday on 2027-01-02", got)
+		}
+	})
+}
+
+func TestSendMilestoneDigests(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mail := &fakeMailer{}
+	app.mailer = mail
+	mocks.restaurants.ClaimMilestoneDigestsFunc = func(context.Context, store.DateOnly) ([]*store.Restaurant, error) {
+		return []*store.Restaurant{{ID: 1, UserID: testUserID, Name: "Busy"}, {ID: 2, UserID: testUserID, Name: "Quiet"}}, nil
+	}
+	mocks.users.GetByIDFunc = func(_ context.Context, id int64) (*store.User, error) {
+		return &store.User{ID: id, FirstName: "Olivia", Email: "owner@example.com"}, nil
+	}
+	birthday := store.DateOnly("1990-10-18")
+	app.store.Employees = &store.MockEmployeeStorer{
+		ListByRestaurantFunc: func(_ context.Context, restaurantID int64) ([]*store.Employee, error) {
+			if restaurantID == 2 {
+				return []*store.Employee{{ID: 8, FullName: "No Dates"}}, nil
+			}
+			return []*store.Employee{{ID: 7, FullName: "Ana", Birthday: &birthday}}, nil
+		},
+	}
+	var notified []*store.Notification
+	app.store.Notifications = &store.MockNotificationStorer{
+		CreateManyFunc: func(_ context.Context, notifications []*store.Notification) error {
+			notified = append(notified, notifications...)
+			return nil
+		},
+	}
+
+	sent, err := app.sendMilestoneDigests(context.Background(), time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sent != 1 || len(mail.sent) != 1 || mail.sent[0] != "staff_milestones.go.tmpl owner@example.com" {
+		t.Errorf("sent = %d, emails = %v, want one digest for the restaurant with a birthday", sent, mail.sent)
+	}
+	if len(notified) != 1 || notified[0].RestaurantID != 1 || notified[0].Type != store.NotificationStaffMilestones {
+		t.Errorf("notifications = %+v", notified)
+	}
+}
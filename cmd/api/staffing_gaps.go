@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+const (
+	defaultStaffingGapWeeks = 8
+	maxStaffingGapWeeks     = 52
+	// hiringWarningOpenWeeks is how many of the weeks a role's shifts must have gone
+	// unassigned before a short roster is flagged for hiring
+	hiringWarningOpenWeeks = 3
+)
+
+const (
+	// hiringBelowTarget is a role with fewer certified employees than its target
+	hiringBelowTarget = "below_target"
+	// hiringNoTarget is a role without a target whose shifts keep going unassigned
+	hiringNoTarget = "no_target"
+)
+
+// StaffingGapReport compares each role's target headcount with its roster and
+// the employees on their way to working it
+type StaffingGapReport struct {
+	Weeks int                `json:"weeks"`
+	From  store.DateOnly     `json:"from"`
+	Roles []*RoleStaffingGap `json:"roles"`
+	// HiringWarnings are the roles whose shifts repeatedly went unassigned while
+	// the roster was short, for the dashboard
+	HiringWarnings []*HiringWarning `json:"hiring_warnings"`
+}
+
+// RoleStaffingGap is a role's roster against its target. Gap is how many more
+// certified employees it needs; GapAfterPipeline is what remains once the holders
+// still missing a certification and the employees in training are through.
+// Both are 0 for a role without a target.
+type RoleStaffingGap struct {
+	*store.RoleStaffing
+	Gap              int `json:"gap"`
+	GapAfterPipeline int `json:"gap_after_pipeline"`
+}
+
+// HiringWarning flags a role to hire for
+type HiringWarning struct {
+	RoleID    int64  `json:"role_id"`
+	RoleName  string `json:"role_name"`
+	Reason    string `json:"reason"`
+	OpenWeeks int    `json:"open_weeks"`
+	Gap       int    `json:"gap"`
+}
+
+// GetStaffingGaps godoc
+//
+//	@Summary		Staffing gaps against each role's target headcount
+//	@Description	For every role: its target_headcount, the employees holding it who haven't left (holders), those of them holding every certification the role requires (certified), and employees without the role who have training shifts in it from the start of the period on (in_training). gap is how many more certified employees the role needs; gap_after_pipeline counts uncertified holders and trainees as filled. open_weeks counts the past weeks of the period in which its shifts went unassigned. hiring_warnings lists the roles with open shifts in at least 3 of those weeks that are below target (below_target) or have no target (no_target).
+//	@Tags			reports
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			weeks			query		int	false	"Weeks of history (default 8, max 52)"
+//	@Success		200				{object}	StaffingGapReport
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/reports/staffing-gaps [get]
+func (app *application) getStaffingGapsHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	weeks := defaultStaffingGapWeeks
+	if weeksStr := r.URL.Query().Get("weeks"); weeksStr != "" {
+		var err error
+		weeks, err = strconv.Atoi(weeksStr)
+		if err != nil || weeks < 1 || weeks > maxStaffingGapWeeks {
+			app.badRequestResponse(w, r, fmt.Errorf("weeks must be between 1 and %d", maxStaffingGapWeeks))
+			return
+		}
+	}
+
+	today := time.Now().In(restaurant.Location())
+	from := store.DateOnly(today.AddDate(0, 0, -7*weeks).Format("2006-01-02"))
+
+	roles, err := app.store.Roles.Staffing(r.Context(), restaurant.ID, store.DateOnly(today.Format("2006-01-02")), from)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, staffingGapReport(roles, weeks, from)); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// staffingGapReport works out each role's gaps and which roles to hire for
+func staffingGapReport(roles []*store.RoleStaffing, weeks int, from store.DateOnly) *StaffingGapReport {
+	report := &StaffingGapReport{
+		Weeks:          weeks,
+		From:           from,
+		Roles:          make([]*RoleStaffingGap, 0, len(roles)),
+		HiringWarnings: []*HiringWarning{},
+	}
+
+	for _, role := range roles {
+		gap := &RoleStaffingGap{RoleStaffing: role}
+		if role.TargetHeadcount != nil {
+			gap.Gap = max(0, *role.TargetHeadcount-role.Certified)
+			gap.GapAfterPipeline = max(0, *role.TargetHeadcount-role.Holders-role.InTraining)
+		}
+		report.Roles = append(report.Roles, gap)
+
+		if role.OpenWeeks < hiringWarningOpenWeeks {
+			continue
+		}
+		warning := &HiringWarning{RoleID: role.RoleID, RoleName: role.RoleName, OpenWeeks: role.OpenWeeks, Gap: gap.Gap}
+		switch {
+		case role.TargetHeadcount == nil:
+			warning.Reason = hiringNoTarget
+		case gap.Gap > 0:
+			warning.Reason = hiringBelowTarget
+		default:
+			// the roster is at target, so open shifts are down to something else
+			continue
+		}
+		report.HiringWarnings = append(report.HiringWarnings, warning)
+	}
+
+	return report
+}
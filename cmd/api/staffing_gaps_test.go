@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestStaffingGapReport(t *testing.T) {
+	six, two := 6, 2
+	report := staffingGapReport([]*store.RoleStaffing{
+		{RoleID: 1, RoleName: "Server", TargetHeadcount: &six, Holders: 4, Certified: 3, InTraining: 1, OpenWeeks: 5},
+		{RoleID: 2, RoleName: "Host", TargetHeadcount: &two, Holders: 2, Certified: 2, OpenWeeks: 4},
+		{RoleID: 3, RoleName: "Cook", OpenWeeks: 3},
+		{RoleID: 4, RoleName: "Dishwasher", TargetHeadcount: &two, Holders: 1, Certified: 1, OpenWeeks: 2},
+	}, 8, "2026-08-21")
+
+	server := report.Roles[0]
+	if server.Gap != 3 || server.GapAfterPipeline != 1 {
+		t.Errorf("server gap = %d, after pipeline %d, want 3 and 1", server.Gap, server.GapAfterPipeline)
+	}
+	if report.Roles[2].Gap != 0 {
+		t.Errorf("cook gap = %d, want 0 without a target", report.Roles[2].Gap)
+	}
+
+	// hosts are at target and dishwashers weren't short often enough
+	warnings := report.HiringWarnings
+	if len(warnings) != 2 || warnings[0].RoleID != 1 || warnings[0].Reason != hiringBelowTarget || warnings[0].Gap != 3 ||
+		warnings[1].RoleID != 3 || warnings[1].Reason != hiringNoTarget {
+		t.Errorf("warnings = %+v, want servers below target and cooks without one", warnings)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// SyncResponse is what changed in a restaurant since the request's cursor. Clients
+// upsert the rows by ID, then drop the deleted ones, and pass cursor to the next sync.
+// Full is set when there was no cursor: the rows are the whole restaurant.
+// NextShiftCursor is set when a full sync's shifts stopped at shift_limit; the rest
+// come from GET /restaurants/{restaurantID}/shifts with it as cursor.
+type SyncResponse struct {
+	Cursor          string             `json:"cursor"`
+	Full            bool               `json:"full"`
+	NextShiftCursor string             `json:"next_shift_cursor,omitempty"`
+	Roles           []*store.Role      `json:"roles"`
+	Employees       []*store.Employee  `json:"employees"`
+	Schedules       []*store.Schedule  `json:"schedules"`
+	Shifts          []*ShiftResponse   `json:"shifts"`
+	Events          []*store.Event     `json:"events"`
+	Deleted         []*store.Tombstone `json:"deleted"`
+}
+
+// syncCursor encodes the point a sync resumes from; clients treat it as opaque
+func syncCursor(t time.Time) string {
+	return strconv.FormatInt(t.UnixMicro(), 10)
+}
+
+func parseSyncCursor(cursor string) (time.Time, error) {
+	micros, err := strconv.ParseInt(cursor, 10, 64)
+	if err != nil || micros < 0 {
+		return time.Time{}, errors.New("invalid sync cursor")
+	}
+	return time.UnixMicro(micros).UTC(), nil
+}
+
+// SyncRestaurant godoc
+//
+//	@Summary		Syncs a restaurant incrementally
+//	@Description	Returns the roles, employees, schedules, shifts and events changed since the cursor from the previous sync, plus the IDs of those deleted. Without since every row is returned and full is set. Upsert the rows by ID, apply the deletions, then keep cursor for the next call; a row can come back again right after a sync, so applying it twice must be harmless. A full sync with shift_limit returns only the first shifts by date, and next_shift_cursor while more remain to page through with GET /restaurants/{restaurantID}/shifts.
+//	@Tags			sync
+//	@Produce		json
+//	@Param			restaurantID	path		int		true	"Restaurant ID"
+//	@Param			since			query		string	false	"Cursor returned by the previous sync"
+//	@Param			shift_limit		query		int		false	"Most shifts a full sync returns (max 5000; default all)"
+//	@Success		200				{object}	SyncResponse
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/sync [get]
+func (app *application) syncRestaurantHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	var since *time.Time
+	if cursor := r.URL.Query().Get("since"); cursor != "" {
+		t, err := parseSyncCursor(cursor)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		since = &t
+	}
+
+	var shiftLimit int
+	if r.URL.Query().Get("shift_limit") != "" {
+		n, err := parseShiftPageLimit(r, "shift_limit")
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		shiftLimit = n
+	}
+
+	changes, err := app.store.Sync.Changes(r.Context(), restaurant.ID, since, shiftLimit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.setAvatarURLs(changes.Employees...)
+
+	response := &SyncResponse{
+		Cursor:    syncCursor(changes.ReadAt.Add(-store.SyncOverlap)),
+		Full:      since == nil,
+		Roles:     changes.Roles,
+		Employees: changes.Employees,
+		Schedules: changes.Schedules,
+		Shifts:    newShiftResponses(changes.Shifts),
+		Events:    changes.Events,
+		Deleted:   changes.Deleted,
+	}
+	if changes.MoreShifts && len(changes.Shifts) > 0 {
+		response.NextShiftCursor = shiftCursor(changes.Shifts[len(changes.Shifts)-1])
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestSyncRestaurant(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	readAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var gotSince *time.Time
+	app.store.Sync = &store.MockSyncStorer{
+		ChangesFunc: func(_ context.Context, _ int64, since *time.Time, shiftLimit int) (*store.SyncChanges, error) {
+			gotSince = since
+			changes := &store.SyncChanges{
+				ReadAt:    readAt,
+				Roles:     []*store.Role{{ID: 3, Name: "Server"}},
+				Employees: []*store.Employee{},
+				Deleted:   []*store.Tombstone{},
+			}
+			if shiftLimit == 2 {
+				changes.Shifts = []*store.ScheduledShift{
+					{ID: 40, ShiftDate: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)},
+					{ID: 12, ShiftDate: time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC)},
+				}
+				changes.MoreShifts = true
+			}
+			if since != nil {
+				changes.Deleted = append(changes.Deleted, &store.Tombstone{Entity: store.SyncEntityShift, ID: 9})
+			}
+			return changes, nil
+		},
+	}
+	sync := func(t *testing.T, target string) SyncResponse {
+		t.Helper()
+		rr := executeRequest(authedRequest(t, app, http.MethodGet, target, ""), app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		var body struct {
+			Data SyncResponse `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body.Data
+	}
+
+	first := sync(t, "/v1/restaurants/1/sync")
+	if gotSince != nil || !first.Full || len(first.Roles) != 1 {
+		t.Errorf("first sync: since = %v, response = %+v", gotSince, first)
+	}
+
+	next := sync(t, "/v1/restaurants/1/sync?since="+first.Cursor)
+	if gotSince == nil || !gotSince.Equal(readAt.Add(-store.SyncOverlap)) {
+		t.Errorf("cursor resumed from %v, want %v", gotSince, readAt.Add(-store.SyncOverlap))
+	}
+	if next.Full || len(next.Deleted) != 1 || next.Deleted[0].ID != 9 {
+		t.Errorf("next sync = %+v", next)
+	}
+
+	if next.NextShiftCursor != "" {
+		t.Errorf("next shift cursor = %q without a shift limit", next.NextShiftCursor)
+	}
+
+	limited := sync(t, "/v1/restaurants/1/sync?shift_limit=2")
+	if limited.NextShiftCursor != "2019-03-02_12" {
+		t.Errorf("next shift cursor = %q, want after the last shift", limited.NextShiftCursor)
+	}
+
+	rr := executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/sync?since=yesterday", ""), app.mount())
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+
+	rr = executeRequest(authedRequest(t, app, http.MethodGet, "/v1/restaurants/1/sync?shift_limit=0", ""), app.mount())
+	checkResponseCode(t, http.StatusBadRequest, rr.Code)
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/balebbae/RESA/internal/auth"
@@ -28,6 +30,136 @@ func newTestApplication(t *testing.T) *application {
 	}
 }
 
+// mockedStores holds the generated mocks behind an app from
+// newMockedApplication; tests swap in the funcs the handler under test calls
+type mockedStores struct {
+	users           *store.MockUserStorer
+	restaurants     *store.MockRestaurantStorer
+	roles           *store.MockRoleStorer
+	acknowledgments *store.MockShiftAcknowledgmentStorer
+	compliance      *store.MockComplianceStorer
+	bids            *store.MockBidStorer
+	webhooks        *store.MockWebhookStorer
+	ownership       *cache.MockOwnershipStorer
+}
+
+// newMockedApplication builds an app on generated mocks. Every user exists,
+// every restaurant belongs to ownerID and checks no compliance rules, no shifts
+// are out for bids, no webhook endpoints are registered, no restaurant has
+// members, and the ownership cache always misses;
+// any other store method panics, which the recoverer turns into a 500.
+func newMockedApplication(t *testing.T, ownerID int64) (*application, *mockedStores) {
+	t.Helper()
+
+	mocks := &mockedStores{
+		users: &store.MockUserStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.User, error) {
+				return &store.User{ID: id, IsActive: true}, nil
+			},
+		},
+		restaurants: &store.MockRestaurantStorer{
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Restaurant, error) {
+				return &store.Restaurant{ID: id, UserID: ownerID}, nil
+			},
+		},
+		roles:           &store.MockRoleStorer{},
+		acknowledgments: &store.MockShiftAcknowledgmentStorer{},
+		compliance: &store.MockComplianceStorer{
+			GetFunc: func(_ context.Context, id int64) (*store.ComplianceRules, error) {
+				rules, _ := store.ComplianceTemplate("custom")
+				rules.RestaurantID = id
+				return &rules, nil
+			},
+		},
+		bids: &store.MockBidStorer{
+			OpenShiftIDsFunc: func(context.Context, int64) ([]int64, error) { return nil, nil },
+		},
+		webhooks: &store.MockWebhookStorer{
+			EnqueueFunc: func(context.Context, *store.WebhookEvent) (int, error) { return 0, nil },
+		},
+		ownership: &cache.MockOwnershipStorer{
+			GetFunc:    func(context.Context, int64) (*cache.Ownership, error) { return nil, nil },
+			SetFunc:    func(context.Context, int64, *cache.Ownership) error { return nil },
+			DeleteFunc: func(context.Context, int64) error { return nil },
+		},
+	}
+
+	app := &application{
+		logger: zap.NewNop().Sugar(),
+		store: store.Storage{
+			Users:                mocks.users,
+			Restaurants:          mocks.restaurants,
+			Employees:            &store.MockEmployeeStorer{},
+			Roles:                mocks.roles,
+			ShiftTemplates:       &store.MockShiftTemplateStorer{},
+			Schedules:            &store.MockScheduleStorer{},
+			ScheduleSnapshots:    &store.MockScheduleSnapshotStorer{},
+			ScheduleNotes:        &store.MockScheduleNoteStorer{},
+			ScheduledShifts:      &store.MockScheduledShiftStorer{},
+			Events:               &store.MockEventStorer{},
+			Certifications:       &store.MockCertificationStorer{},
+			EmailTemplates:       &store.MockEmailTemplateStorer{},
+			Documents:            &store.MockDocumentStorer{},
+			DocumentAcks:         &store.MockDocumentAcknowledgmentStorer{},
+			EmployeeNotes:        &store.MockEmployeeNoteStorer{},
+			OperatingHours:       &store.MockOperatingHoursStorer{},
+			Notifications:        &store.MockNotificationStorer{},
+			AuditLog:             &store.MockAuditLogStorer{},
+			Versions:             &store.MockVersionStorer{},
+			Subscriptions:        &store.MockSubscriptionStorer{},
+			ShiftAcknowledgments: mocks.acknowledgments,
+			Onboarding:           &store.MockOnboardingStorer{},
+			TimeClock:            &store.MockTimeClockStorer{},
+			Members:              &store.MockMemberStorer{ListByRestaurantFunc: func(context.Context, int64) ([]*store.Member, error) { return nil, nil }},
+			EmailDeliveries:      &store.MockEmailDeliveryStorer{},
+			ShareLinks:           &store.MockShareLinkStorer{},
+			TwoFactor:            &store.MockTwoFactorStorer{},
+			Sessions:             &store.MockSessionStorer{},
+			SecurityEvents:       &store.MockSecurityEventStorer{},
+			Sync:                 &store.MockSyncStorer{},
+			Sales:                &store.MockSalesStorer{},
+			Messages:             &store.MockMessageStorer{},
+			ShiftFeedback:        &store.MockShiftFeedbackStorer{},
+			Compliance:           mocks.compliance,
+			Leave:                &store.MockLeaveStorer{},
+			Bids:                 mocks.bids,
+			Webhooks:             mocks.webhooks,
+			DisplayBoards:        &store.MockDisplayBoardStorer{},
+			SavedReports:         &store.MockSavedReportStorer{},
+			EventBadges:          &store.MockEventBadgeStorer{},
+			NotificationDigests:  &store.MockNotificationDigestStorer{},
+			Retention:            &store.MockRetentionStorer{},
+			CalendarFeeds:        &store.MockCalendarFeedStorer{},
+		},
+		cacheStorage: cache.Storage{
+			Schedules:   &cache.MockScheduleStorer{},
+			Restaurants: &cache.MockRestaurantStorer{},
+			Ownership:   mocks.ownership,
+			EmailQuota:  &cache.MockEmailQuotaStorer{},
+		},
+		authenticator: &auth.TestAuthenticator{},
+	}
+	return app, mocks
+}
+
+// authedRequest is a request carrying the test authenticator's token, which
+// authenticates as user 1
+func authedRequest(t *testing.T, app *application, method, target, body string) *http.Request {
+	t.Helper()
+
+	token, err := app.authenticator.GenerateToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
 func executeRequest(req *http.Request, mux http.Handler) *httptest.ResponseRecorder {
 	rr := httptest.NewRecorder()
 	mux.ServeHTTP(rr, req)
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/balebbae/RESA/internal/auth"
+)
+
+// newJWTAuthenticator builds the token keys from the config. The configured key signs:
+// AUTH_TOKEN_SECRET, or the RSA private key when one is set. In that case the secret
+// stays valid, without a key ID, so tokens issued before the switch keep working.
+// Retired keys listed in the config only verify.
+func newJWTAuthenticator(cfg tokenConfig) (*auth.JWTAuthenticator, error) {
+	signing := auth.HMACKey(cfg.keyID, cfg.secret)
+	var retired []auth.Key
+
+	if cfg.rsaPrivateKeyFile != "" {
+		key, err := auth.ReadRSAKeyFile(cfg.keyID, cfg.rsaPrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		retired = append(retired, auth.HMACKey("", cfg.secret))
+		signing = key
+	}
+
+	secrets, err := auth.ParseKeyList(cfg.previousSecrets, func(id, secret string) (auth.Key, error) {
+		return auth.HMACKey(id, secret), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	publicKeys, err := auth.ParseKeyList(cfg.previousRSAKeyFiles, auth.ReadRSAKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	retired = append(retired, secrets...)
+	retired = append(retired, publicKeys...)
+
+	return auth.NewJWTAuthenticatorWithKeys(signing, retired, cfg.aud, cfg.iss)
+}
+
+// GetJWKS godoc
+//
+//	@Summary		Token verification keys
+//	@Description	The public keys access tokens are signed with, as a JSON Web Key Set (RFC 7517) so other services can verify tokens themselves. Each token's kid header names its key. Empty unless RS256 signing is configured: shared HS256 secrets are never published.
+//	@Tags			authentication
+//	@Produce		json
+//	@Success		200	{object}	auth.JWKSet
+//	@Router			/authentication/jwks.json [get]
+func (app *application) getJWKSHandler(w http.ResponseWriter, r *http.Request) {
+	// served bare, not in the API envelope, since verifiers expect the standard document
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(app.authenticator.PublicKeys()); err != nil {
+		app.logger.Warnw("failed to write jwks", "error", err)
+	}
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/auth"
+	"github.com/balebbae/RESA/internal/mailer"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/google/uuid"
+)
+
+// recoveryCodeCount is how many recovery codes an account gets at a time
+const recoveryCodeCount = 10
+
+var errWrongSecondFactor = errors.New("the code is not valid")
+
+// TwoFactorStatus is whether the account has an authenticator app and asks for it at sign-in
+type TwoFactorStatus struct {
+	Enrolled          bool `json:"enrolled"`
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// TwoFactorEnrollment is what the authenticator app needs; clients show
+// otpauth_url as a QR code, with the secret for typing in by hand
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type TwoFactorCodePayload struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type UpdateTwoFactorPayload struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+	// Code is a current authenticator code or an unused recovery code
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// RecoveryCodes are shown once; only their hashes are kept
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// TwoFactorChallenge answers a correct password on an account that asks for
+// a code: the code goes to /authentication/token/verify with the challenge token
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type VerifyLoginPayload struct {
+	ChallengeToken string `json:"challenge_token" validate:"required,max=64"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+// completeSignIn finishes a sign-in whose first factor checked out: the JWT
+// with the given status, or a challenge when the account asks for a code
+func (app *application) completeSignIn(w http.ResponseWriter, r *http.Request, user *store.User, status int) {
+	ctx := r.Context()
+
+	tf, err := app.store.TwoFactor.Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if tf == nil || !tf.Enabled || tf.ConfirmedAt == nil {
+		token, err := app.startSession(r, user)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if err := app.jsonResponse(w, r, status, token); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	token := uuid.New().String()
+	challenge := &store.LoginChallenge{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(store.LoginChallengeTTL),
+	}
+	if err := app.store.TwoFactor.CreateChallenge(ctx, challenge, token); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := TwoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresAt:         challenge.ExpiresAt,
+	}
+	if err := app.jsonResponse(w, r, http.StatusAccepted, response); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// checkSecondFactor accepts a current authenticator code, once, or spends a
+// recovery code; errWrongSecondFactor means it was neither
+func (app *application) checkSecondFactor(ctx context.Context, tf *store.TwoFactor, code string, now time.Time) error {
+	code = strings.TrimSpace(code)
+
+	if isTOTPCode(code) {
+		step, ok := auth.VerifyTOTP(tf.Secret, code, now)
+		if !ok {
+			return errWrongSecondFactor
+		}
+		if err := app.store.TwoFactor.UseTOTPStep(ctx, tf.UserID, step); err != nil {
+			if errors.Is(err, store.ErrTOTPCodeUsed) {
+				return errWrongSecondFactor
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := app.store.TwoFactor.UseRecoveryCode(ctx, tf.UserID, auth.NormalizeRecoveryCode(code)); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return errWrongSecondFactor
+		}
+		return err
+	}
+	return nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != auth.TOTPDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyLoginHandler godoc
+//
+//	@Summary		Completes a sign-in with its second factor
+//	@Description	Exchanges the challenge token from /authentication/token and an authenticator or recovery code for a JWT. Five wrong codes end the challenge.
+//	@Tags			authentication
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		VerifyLoginPayload	true	"Challenge token and code"
+//	@Success		201		{string}	string				"Token"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/authentication/token/verify [post]
+func (app *application) verifyLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var payload VerifyLoginPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	now := time.Now()
+
+	challenge, err := app.store.TwoFactor.GetChallenge(ctx, payload.ChallengeToken, now)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r, errors.New("sign-in challenge expired or used up"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	tf, err := app.store.TwoFactor.Get(ctx, challenge.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.checkSecondFactor(ctx, tf, payload.Code, now); err != nil {
+		if errors.Is(err, errWrongSecondFactor) {
+			if err := app.store.TwoFactor.FailChallenge(ctx, challenge.ID); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			app.unauthorizedErrorResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// only one request gets to answer a challenge
+	if err := app.store.TwoFactor.DeleteChallenge(ctx, challenge.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r, errors.New("sign-in challenge already answered"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	user, err := app.store.Users.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.unauthorizedErrorResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	token, err := app.startSession(r, user)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, token); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// getTwoFactorHandler godoc
+//
+//	@Summary		Shows the current user's two-factor authentication
+//	@Description	Whether an authenticator app is set up, whether sign-in asks for it, and how many recovery codes are left
+//	@Tags			users
+//	@Produce		json
+//	@Success		200	{object}	TwoFactorStatus
+//	@Failure		401	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/two-factor [get]
+func (app *application) getTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	var status TwoFactorStatus
+	tf, err := app.store.TwoFactor.Get(r.Context(), user.ID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+	case err != nil:
+		app.internalServerError(w, r, err)
+		return
+	default:
+		status = TwoFactorStatus{
+			Enrolled:          tf.ConfirmedAt != nil,
+			Enabled:           tf.Enabled,
+			RecoveryCodesLeft: tf.RecoveryCodesLeft,
+		}
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, status); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// enrollTwoFactorHandler godoc
+//
+//	@Summary		Starts setting up an authenticator app
+//	@Description	Generates a new secret and its otpauth:// URL for a QR code. Nothing changes at sign-in until a code from the app is confirmed.
+//	@Tags			users
+//	@Produce		json
+//	@Success		201	{object}	TwoFactorEnrollment
+//	@Failure		401	{object}	error
+//	@Failure		409	{object}	error	"An authenticator app is already set up"
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/two-factor/enroll [post]
+func (app *application) enrollTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.Enroll(r.Context(), user.ID, secret); err != nil {
+		if errors.Is(err, store.ErrTwoFactorEnrolled) {
+			app.conflictResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	enrollment := TwoFactorEnrollment{
+		Secret:     secret,
+		OTPAuthURL: auth.TOTPURL(mailer.FromName, user.Email, secret),
+	}
+	if err := app.jsonResponse(w, r, http.StatusCreated, enrollment); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// confirmTwoFactorHandler godoc
+//
+//	@Summary		Confirms the authenticator app
+//	@Description	Checks a code from the newly set up app, turns on codes at sign-in and returns the recovery codes. They are shown only this once.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		TwoFactorCodePayload	true	"Code from the authenticator app"
+//	@Success		200		{object}	RecoveryCodes
+//	@Failure		400		{object}	error	"Wrong code"
+//	@Failure		401		{object}	error
+//	@Failure		404		{object}	error	"No enrollment was started"
+//	@Failure		409		{object}	error	"Already confirmed"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/two-factor/confirm [post]
+func (app *application) confirmTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var payload TwoFactorCodePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user := getUserFromContext(r)
+
+	tf, err := app.store.TwoFactor.Get(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+	if tf.ConfirmedAt != nil {
+		app.conflictResponse(w, r, store.ErrTwoFactorEnrolled)
+		return
+	}
+
+	step, ok := auth.VerifyTOTP(tf.Secret, strings.TrimSpace(payload.Code), time.Now())
+	if !ok {
+		app.badRequestResponse(w, r, errWrongSecondFactor)
+		return
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.Confirm(ctx, user.ID, step, codes); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			// confirmed by a concurrent request
+			app.conflictResponse(w, r, store.ErrTwoFactorEnrolled)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, user.ID, store.SecurityEventTwoFactorEnabled, getSessionIDFromContext(r))
+
+	if err := app.jsonResponse(w, r, http.StatusOK, RecoveryCodes{Codes: codes}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// updateTwoFactorHandler godoc
+//
+//	@Summary		Turns codes at sign-in on or off
+//	@Description	Needs a current authenticator code or an unused recovery code. The app stays set up either way.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body	UpdateTwoFactorPayload	true	"Whether sign-in asks for a code"
+//	@Success		204		"No Content"
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error	"Wrong code"
+//	@Failure		404		{object}	error	"No confirmed authenticator app"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/two-factor [put]
+func (app *application) updateTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	var payload UpdateTwoFactorPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	tf, ok := app.confirmedTwoFactor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.checkSecondFactor(ctx, tf, payload.Code, time.Now()); err != nil {
+		if errors.Is(err, errWrongSecondFactor) {
+			app.forbiddenResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.SetEnabled(ctx, tf.UserID, *payload.Enabled); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	event := store.SecurityEventTwoFactorDisabled
+	if *payload.Enabled {
+		event = store.SecurityEventTwoFactorEnabled
+	}
+	app.recordSecurityEvent(r, tf.UserID, event, getSessionIDFromContext(r))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// regenerateRecoveryCodesHandler godoc
+//
+//	@Summary		Replaces the recovery codes
+//	@Description	Needs a current authenticator code or an unused recovery code. The old codes stop working; the new ones are shown only this once.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		TwoFactorCodePayload	true	"Authenticator or recovery code"
+//	@Success		200		{object}	RecoveryCodes
+//	@Failure		400		{object}	error
+//	@Failure		401		{object}	error
+//	@Failure		403		{object}	error	"Wrong code"
+//	@Failure		404		{object}	error	"No confirmed authenticator app"
+//	@Failure		500		{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/users/me/two-factor/recovery-codes [post]
+func (app *application) regenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	var payload TwoFactorCodePayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	tf, ok := app.confirmedTwoFactor(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.checkSecondFactor(ctx, tf, payload.Code, time.Now()); err != nil {
+		if errors.Is(err, errWrongSecondFactor) {
+			app.forbiddenResponse(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.store.TwoFactor.ReplaceRecoveryCodes(ctx, tf.UserID, codes); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.recordSecurityEvent(r, tf.UserID, store.SecurityEventRecoveryCodesRegenerated, getSessionIDFromContext(r))
+
+	if err := app.jsonResponse(w, r, http.StatusOK, RecoveryCodes{Codes: codes}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// confirmedTwoFactor loads the signed-in user's confirmed enrollment, writing
+// 404 when there is none
+func (app *application) confirmedTwoFactor(w http.ResponseWriter, r *http.Request) (*store.TwoFactor, bool) {
+	user := getUserFromContext(r)
+
+	tf, err := app.store.TwoFactor.Get(r.Context(), user.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, err)
+			return nil, false
+		}
+		app.internalServerError(w, r, err)
+		return nil, false
+	}
+	if tf.ConfirmedAt == nil {
+		app.notFoundResponse(w, r, store.ErrNotFound)
+		return nil, false
+	}
+
+	return tf, true
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/auth"
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestCreateTokenAsksForSecondFactor(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	user := &store.User{ID: testUserID, Email: "owner@example.com", IsActive: true}
+	if err := user.Password.Set("secret-password"); err != nil {
+		t.Fatal(err)
+	}
+	mocks.users.GetByEmailFunc = func(context.Context, string) (*store.User, error) {
+		return user, nil
+	}
+	confirmedAt := time.Now()
+	enabled := false
+	var savedToken string
+	app.store.TwoFactor = &store.MockTwoFactorStorer{
+		GetFunc: func(_ context.Context, userID int64) (*store.TwoFactor, error) {
+			return &store.TwoFactor{UserID: userID, ConfirmedAt: &confirmedAt, Enabled: enabled}, nil
+		},
+		CreateChallengeFunc: func(_ context.Context, challenge *store.LoginChallenge, token string) error {
+			savedToken = token
+			return nil
+		},
+	}
+	app.store.Sessions = &store.MockSessionStorer{
+		CreateFunc: func(_ context.Context, session *store.Session) error {
+			session.ID = 9
+			return nil
+		},
+	}
+	app.store.SecurityEvents = &store.MockSecurityEventStorer{
+		RecordFunc: func(context.Context, *store.SecurityEvent) error { return nil },
+	}
+	signIn := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/authentication/token",
+			strings.NewReader(`{"email":"owner@example.com","password":"secret-password"}`))
+		return executeRequest(req, app.mount())
+	}
+
+	t.Run("issues the token while codes are off", func(t *testing.T) {
+		rr := signIn()
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("issues a challenge while codes are on", func(t *testing.T) {
+		enabled = true
+		rr := signIn()
+
+		checkResponseCode(t, http.StatusAccepted, rr.Code)
+		var body struct {
+			Data TwoFactorChallenge `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if !body.Data.TwoFactorRequired || body.Data.ChallengeToken == "" || body.Data.ChallengeToken != savedToken {
+			t.Errorf("challenge = %+v, want the stored token %q", body.Data, savedToken)
+		}
+	})
+}
+
+func TestVerifyLogin(t *testing.T) {
+	app, _ := newMockedApplication(t, testUserID)
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed, deleted := 0, 0
+	app.store.TwoFactor = &store.MockTwoFactorStorer{
+		GetChallengeFunc: func(_ context.Context, token string, _ time.Time) (*store.LoginChallenge, error) {
+			if token != "challenge-token" {
+				return nil, store.ErrNotFound
+			}
+			return &store.LoginChallenge{ID: 7, UserID: testUserID}, nil
+		},
+		GetFunc: func(_ context.Context, userID int64) (*store.TwoFactor, error) {
+			return &store.TwoFactor{UserID: userID, Secret: secret, Enabled: true}, nil
+		},
+		UseTOTPStepFunc: func(context.Context, int64, int64) error { return nil },
+		UseRecoveryCodeFunc: func(_ context.Context, _ int64, code string) error {
+			if code != "abcd-efgh-jkmn" {
+				return store.ErrNotFound
+			}
+			return nil
+		},
+		FailChallengeFunc: func(context.Context, int64) error {
+			failed++
+			return nil
+		},
+		DeleteChallengeFunc: func(context.Context, int64) error {
+			deleted++
+			return nil
+		},
+	}
+	app.store.Sessions = &store.MockSessionStorer{
+		CreateFunc: func(context.Context, *store.Session) error { return nil },
+	}
+	app.store.SecurityEvents = &store.MockSecurityEventStorer{
+		RecordFunc: func(context.Context, *store.SecurityEvent) error { return nil },
+	}
+	verify := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/authentication/token/verify", strings.NewReader(body))
+		return executeRequest(req, app.mount())
+	}
+
+	t.Run("a wrong code counts against the challenge", func(t *testing.T) {
+		rr := verify(`{"challenge_token":"challenge-token","code":"000000"}`)
+
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+		if failed != 1 || deleted != 0 {
+			t.Errorf("failed = %d, deleted = %d", failed, deleted)
+		}
+	})
+
+	t.Run("accepts the current code", func(t *testing.T) {
+		code, err := auth.TOTPCode(secret, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := verify(`{"challenge_token":"challenge-token","code":"` + code + `"}`)
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if deleted != 1 {
+			t.Errorf("deleted = %d, want the challenge ended", deleted)
+		}
+	})
+
+	t.Run("accepts a recovery code as typed", func(t *testing.T) {
+		rr := verify(`{"challenge_token":"challenge-token","code":" ABCD-EFGH-JKMN "}`)
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("an unknown challenge is unauthorized", func(t *testing.T) {
+		rr := verify(`{"challenge_token":"other-token","code":"abcd-efgh-jkmn"}`)
+
+		checkResponseCode(t, http.StatusUnauthorized, rr.Code)
+	})
+}
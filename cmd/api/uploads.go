@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errUploadTooLarge       = errors.New("uploaded file is too large")
+	errUnsupportedMediaType = errors.New("uploaded file type is not allowed")
+	errBlobStorageDisabled  = errors.New("file storage is not enabled")
+)
+
+// uploadedFile is a multipart file whose size and content type have been checked
+type uploadedFile struct {
+	file        multipart.File
+	name        string
+	size        int64
+	contentType string
+}
+
+// readUpload reads the "file" form field, sniffing its content type from the bytes rather
+// than trusting the client, and rejects files over the configured size or outside allowed.
+// The caller must call r.MultipartForm.RemoveAll when done.
+func (app *application) readUpload(w http.ResponseWriter, r *http.Request, allowed map[string]bool) (*uploadedFile, error) {
+	maxBytes := app.config.uploads.maxBytes
+
+	// leave room for the multipart boundaries and other fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errUploadTooLarge
+		}
+		return nil, err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("file is required: %w", err)
+	}
+
+	if header.Size > maxBytes {
+		file.Close()
+		return nil, errUploadTooLarge
+	}
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(sniff[:n]))
+	if err != nil || !allowed[contentType] {
+		file.Close()
+		return nil, errUnsupportedMediaType
+	}
+
+	return &uploadedFile{
+		file:        file,
+		name:        filepath.Base(strings.ReplaceAll(header.Filename, "\\", "/")),
+		size:        header.Size,
+		contentType: contentType,
+	}, nil
+}
+
+// uploadErrorResponse maps readUpload errors to status codes
+func (app *application) uploadErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errUploadTooLarge):
+		app.payloadTooLargeResponse(w, r, fmt.Errorf("%w (max %d MB)", err, app.config.uploads.maxBytes>>20))
+	case errors.Is(err, errUnsupportedMediaType):
+		app.unsupportedMediaTypeResponse(w, r, err)
+	default:
+		app.badRequestResponse(w, r, err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newUploadRequest(t *testing.T, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestReadUpload(t *testing.T) {
+	app := newTestApplication(t)
+	app.config.uploads.maxBytes = 1 << 10
+
+	pdf := []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n")
+
+	t.Run("should sniff the content type from the bytes", func(t *testing.T) {
+		req := newUploadRequest(t, `C:\forms\w4.exe`, pdf)
+
+		upload, err := app.readUpload(httptest.NewRecorder(), req, documentContentTypes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer req.MultipartForm.RemoveAll()
+
+		if upload.contentType != "application/pdf" {
+			t.Errorf("expected application/pdf, got %s", upload.contentType)
+		}
+		if upload.name != "w4.exe" {
+			t.Errorf("expected the base file name, got %s", upload.name)
+		}
+	})
+
+	t.Run("should reject disallowed types", func(t *testing.T) {
+		req := newUploadRequest(t, "handbook.pdf", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"))
+
+		_, err := app.readUpload(httptest.NewRecorder(), req, documentContentTypes)
+		if !errors.Is(err, errUnsupportedMediaType) {
+			t.Fatalf("expected errUnsupportedMediaType, got %v", err)
+		}
+	})
+
+	t.Run("should reject files over the limit", func(t *testing.T) {
+		req := newUploadRequest(t, "big.pdf", append(pdf, bytes.Repeat([]byte("a"), 2<<10)...))
+
+		_, err := app.readUpload(httptest.NewRecorder(), req, documentContentTypes)
+		if !errors.Is(err, errUploadTooLarge) {
+			t.Fatalf("expected errUploadTooLarge, got %v", err)
+		}
+	})
+}
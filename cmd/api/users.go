@@ -17,9 +17,11 @@ const userCtx userKey = "user"
 //	@Description	Activates/Register a user by invitation token
 //	@Tags			users
 //	@Produce		json
-//	@Param			token	path		string	true	"Invitation token"
-//	@Success		204		{string}	string	"User activated"
-//	@Failure		404		{object}	error	
+//	@Param			token	path	string	true	"Invitation token"
+//	@Success		204		"User activated"
+//	@Failure		404		{object}	error	"Token is invalid"
+//	@Failure		409		{object}	error	"Token has already been used"
+//	@Failure		410		{object}	error	"Token has expired"
 //	@Failure		500		{object}	error
 //	@Security		ApiKeyAuth
 //	@Router			/users/activate/{token} [put]
@@ -29,15 +31,17 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 	err := app.store.Users.Activate(r.Context(), token)
 	if err != nil {
 		switch err {
-		case store.ErrNotFound:
+		case store.ErrInvitationInvalid:
 			app.notFoundResponse(w, r, err)
+		case store.ErrInvitationUsed:
+			app.conflictResponse(w, r, err)
+		case store.ErrInvitationExpired:
+			app.goneResponse(w, r, err)
 		default:
 			app.internalServerError(w, r, err)
 		}
 		return
 	}
 
-	if err := app.jsonResponse(w, http.StatusNoContent, ""); err != nil {
-		app.internalServerError(w, r, err)
-	}
+	w.WriteHeader(http.StatusNoContent)
 }
\ No newline at end of file
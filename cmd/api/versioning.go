@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// apiVersion is the response contract a request was routed to. Versions only
+// change how responses are serialized; the handlers are shared.
+type apiVersion string
+
+const (
+	// apiV1 keeps the original shapes: {"data": ...} and {"error": "..."}
+	apiV1 apiVersion = "v1"
+	// apiV2 wraps every response, success or error, in envelopeV2
+	apiV2 apiVersion = "v2"
+)
+
+type apiVersionKey string
+
+const apiVersionCtx apiVersionKey = "apiVersion"
+
+// withAPIVersion tags requests under a version's route group
+func withAPIVersion(v apiVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("API-Version", string(v))
+			ctx := context.WithValue(r.Context(), apiVersionCtx, v)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// requestAPIVersion is the version the request was routed to, v1 when untagged
+func requestAPIVersion(r *http.Request) apiVersion {
+	v, ok := r.Context().Value(apiVersionCtx).(apiVersion)
+	if !ok {
+		return apiV1
+	}
+	return v
+}
+
+// envelopeV2 is the single response shape of v2: data on success, errors on failure, meta always
+type envelopeV2 struct {
+	Data   any          `json:"data"`
+	Meta   responseMeta `json:"meta"`
+	Errors []apiError   `json:"errors,omitempty"`
+}
+
+type responseMeta struct {
+	APIVersion apiVersion `json:"api_version"`
+	RequestID  string     `json:"request_id,omitempty"`
+}
+
+// apiError is one entry of a v2 error response; Code is stable for clients to branch on
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details carries what the client needs to fix the request, when there is more than the message
+	Details any `json:"details,omitempty"`
+}
+
+func newResponseMeta(r *http.Request) responseMeta {
+	return responseMeta{
+		APIVersion: requestAPIVersion(r),
+		RequestID:  middleware.GetReqID(r.Context()),
+	}
+}
+
+// errorCode turns a status into a snake_case code, e.g. 404 into "not_found"
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
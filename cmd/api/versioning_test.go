@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestResponseEnvelopes(t *testing.T) {
+	app := newTestApplication(t)
+	mux := app.mount()
+
+	testToken, err := app.authenticator.GenerateToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(t *testing.T, path string, authenticated bool) map[string]json.RawMessage {
+		req, err := http.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authenticated {
+			req.Header.Set("Authorization", "Bearer "+testToken)
+		}
+
+		rr := executeRequest(req, mux)
+
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	t.Run("v1 keeps its shapes", func(t *testing.T) {
+		body := get(t, "/v1/restaurants/1", true)
+		if _, ok := body["data"]; !ok || len(body) != 1 {
+			t.Errorf("expected only data, got %v", body)
+		}
+
+		body = get(t, "/v1/restaurants/1", false)
+		if _, ok := body["error"]; !ok || len(body) != 1 {
+			t.Errorf("expected only error, got %v", body)
+		}
+	})
+
+	t.Run("v2 wraps success in data and meta", func(t *testing.T) {
+		body := get(t, "/v2/restaurants/1", true)
+		if _, ok := body["data"]; !ok {
+			t.Errorf("expected data, got %v", body)
+		}
+		if _, ok := body["errors"]; ok {
+			t.Errorf("unexpected errors on success: %s", body["errors"])
+		}
+
+		var meta responseMeta
+		if err := json.Unmarshal(body["meta"], &meta); err != nil {
+			t.Fatal(err)
+		}
+		if meta.APIVersion != apiV2 || meta.RequestID == "" {
+			t.Errorf("unexpected meta %+v", meta)
+		}
+	})
+
+	t.Run("v2 reports failures as errors", func(t *testing.T) {
+		body := get(t, "/v2/restaurants/1", false)
+
+		var errs []apiError
+		if err := json.Unmarshal(body["errors"], &errs); err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != 1 || errs[0].Code != "unauthorized" {
+			t.Errorf("unexpected errors %+v", errs)
+		}
+		if string(body["data"]) != "null" {
+			t.Errorf("expected null data, got %s", body["data"])
+		}
+	})
+}
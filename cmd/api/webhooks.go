@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Webhook event types
+const (
+	webhookEmployeeCreated     = "employee.created"
+	webhookEmployeeUpdated     = "employee.updated"
+	webhookEmployeeDeactivated = "employee.deactivated"
+)
+
+// webhookEventTypes are the event types an endpoint can subscribe to
+var webhookEventTypes = map[string]bool{
+	webhookEmployeeCreated:     true,
+	webhookEmployeeUpdated:     true,
+	webhookEmployeeDeactivated: true,
+}
+
+// webhookSchemaVersion changes only when a payload changes incompatibly
+const webhookSchemaVersion = 1
+
+const (
+	// webhookMaxAttempts is how often a delivery is tried before it's given up on
+	webhookMaxAttempts = 8
+	// webhookLease is how long a claimed delivery is held before another worker may retry it
+	webhookLease = 2 * time.Minute
+	// webhookBatch is how many deliveries one worker pass sends at most
+	webhookBatch = 100
+)
+
+// WebhookEvent is the body of every delivery. Data depends on Type; fields are
+// only ever added to it, and always present, null when there's no value.
+type WebhookEvent struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	RestaurantID  int64     `json:"restaurant_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	Data          any       `json:"data"`
+}
+
+// EmployeeWebhookData is the data of the employee.* events. Changed lists the
+// employee fields an employee.updated event changed; it's empty otherwise.
+type EmployeeWebhookData struct {
+	Employee EmployeeWebhookRecord `json:"employee"`
+	Changed  []string              `json:"changed"`
+}
+
+// EmployeeWebhookRecord is an employee as HR systems see them. ExternalID is
+// the ID the HR system gave them, set through the employee API.
+type EmployeeWebhookRecord struct {
+	ID              int64           `json:"id"`
+	ExternalID      *string         `json:"external_id"`
+	FullName        string          `json:"full_name"`
+	Email           string          `json:"email"`
+	Locale          *string         `json:"locale"`
+	HourlyRateCents *int            `json:"hourly_rate_cents"`
+	Seniority       int             `json:"seniority"`
+	Birthday        *store.DateOnly `json:"birthday"`
+	HireDate        *store.DateOnly `json:"hire_date"`
+	Active          bool            `json:"active"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	// DeactivatedAt is when the employee was removed from the restaurant; null while active
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+}
+
+func employeeWebhookRecord(e *store.Employee) EmployeeWebhookRecord {
+	return EmployeeWebhookRecord{
+		ID:              e.ID,
+		ExternalID:      e.ExternalID,
+		FullName:        e.FullName,
+		Email:           e.Email,
+		Locale:          e.Locale,
+		HourlyRateCents: e.HourlyRateCents,
+		Seniority:       e.Seniority,
+		Birthday:        e.Birthday,
+		HireDate:        e.HireDate,
+		Active:          true,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+	}
+}
+
+// changedFields names the fields of the record that differ between before and after
+func changedFields(before, after EmployeeWebhookRecord) []string {
+	var a, b map[string]json.RawMessage
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+	json.Unmarshal(beforeJSON, &b)
+	json.Unmarshal(afterJSON, &a)
+
+	changed := []string{}
+	for _, field := range []string{"external_id", "full_name", "email", "locale", "hourly_rate_cents", "seniority", "birthday", "hire_date"} {
+		if string(a[field]) != string(b[field]) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+// emitEmployeeEvent queues an employee.* event for the restaurant's endpoints.
+// before is the employee ahead of an update, nil for other events. Failing to
+// queue it doesn't fail the change that caused it.
+func (app *application) emitEmployeeEvent(ctx context.Context, eventType string, before, employee *store.Employee) {
+	data := EmployeeWebhookData{Employee: employeeWebhookRecord(employee), Changed: []string{}}
+	switch eventType {
+	case webhookEmployeeUpdated:
+		data.Changed = changedFields(employeeWebhookRecord(before), data.Employee)
+		if len(data.Changed) == 0 {
+			return
+		}
+	case webhookEmployeeDeactivated:
+		now := time.Now().UTC()
+		data.Employee.Active, data.Employee.DeactivatedAt = false, &now
+	}
+
+	app.emitWebhook(ctx, employee.RestaurantID, eventType, data)
+}
+
+// emitWebhook queues an event for each of the restaurant's endpoints subscribed to its type
+func (app *application) emitWebhook(ctx context.Context, restaurantID int64, eventType string, data any) {
+	event := WebhookEvent{
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		SchemaVersion: webhookSchemaVersion,
+		RestaurantID:  restaurantID,
+		CreatedAt:     time.Now().UTC(),
+		Data:          data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		app.logger.Warnw("failed to encode webhook event", "type", eventType, "error", err)
+		return
+	}
+
+	if _, err := app.store.Webhooks.Enqueue(ctx, &store.WebhookEvent{
+		ID:           event.ID,
+		RestaurantID: restaurantID,
+		Type:         eventType,
+		Payload:      payload,
+	}); err != nil {
+		app.logger.Warnw("failed to queue webhook event", "type", eventType, "restaurant_id", restaurantID, "error", err)
+	}
+}
+
+type CreateWebhookPayload struct {
+	URL string `json:"url" validate:"required,url,max=2048"`
+	// Events limits the endpoint to these event types; empty sends all of them
+	Events []string `json:"events"`
+}
+
+// GetWebhooks godoc
+//
+//	@Summary		Lists the restaurant's webhook endpoints
+//	@Description	The URLs the restaurant's events are posted to, without their signing secrets.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Success		200				{array}		store.WebhookEndpoint
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/webhooks [get]
+func (app *application) getWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	endpoints, err := app.store.Webhooks.ListEndpoints(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, endpoints); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		Registers a webhook endpoint
+//	@Description	Posts the restaurant's events to an https URL: employee.created, employee.updated and employee.deactivated, or only the ones in events. Each POST carries the event as JSON with X-RESA-Event, X-RESA-Delivery (the event ID) and X-RESA-Signature headers; the signature is t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>" keyed by the secret>. Deliveries the endpoint doesn't answer with a 2xx are retried with backoff. The secret is only shown in this response.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			restaurantID	path		int						true	"Restaurant ID"
+//	@Param			payload			body		CreateWebhookPayload	true	"Endpoint"
+//	@Success		201				{object}	store.WebhookEndpoint
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/webhooks [post]
+func (app *application) createWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	var payload CreateWebhookPayload
+	if err := readJSON(w, r, &payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if err := Validate.Struct(payload); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if u, err := url.Parse(payload.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		app.badRequestResponse(w, r, errors.New("url must be an https URL"))
+		return
+	}
+	events := []string{}
+	for _, event := range payload.Events {
+		if !webhookEventTypes[event] {
+			app.badRequestResponse(w, r, fmt.Errorf("unknown event type %q", event))
+			return
+		}
+		events = append(events, event)
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	userID := getUserFromContext(r).ID
+	endpoint := &store.WebhookEndpoint{
+		RestaurantID: restaurant.ID,
+		URL:          payload.URL,
+		Secret:       secret,
+		Events:       events,
+		CreatedBy:    &userID,
+	}
+	if err := app.store.Webhooks.CreateEndpoint(r.Context(), endpoint); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusCreated, endpoint); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		Removes a webhook endpoint
+//	@Description	Stops posting events to the endpoint and drops the deliveries still queued for it.
+//	@Tags			webhooks
+//	@Param			restaurantID	path	int	true	"Restaurant ID"
+//	@Param			webhookID		path	int	true	"Webhook endpoint ID"
+//	@Success		204
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/webhooks/{webhookID} [delete]
+func (app *application) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid webhook ID"))
+		return
+	}
+
+	if err := app.store.Webhooks.DeleteEndpoint(r.Context(), restaurant.ID, webhookID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			app.notFoundResponse(w, r, errors.New("webhook not found"))
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries godoc
+//
+//	@Summary		Lists a webhook endpoint's deliveries
+//	@Description	The endpoint's latest 100 deliveries, newest first: pending (with the next attempt due), delivered, or failed after every attempt, with the last status and error the endpoint gave.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Param			restaurantID	path		int	true	"Restaurant ID"
+//	@Param			webhookID		path		int	true	"Webhook endpoint ID"
+//	@Success		200				{array}		store.WebhookDelivery
+//	@Failure		400				{object}	error
+//	@Failure		401				{object}	error
+//	@Failure		404				{object}	error
+//	@Failure		500				{object}	error
+//	@Security		ApiKeyAuth
+//	@Router			/restaurants/{restaurantID}/webhooks/{webhookID}/deliveries [get]
+func (app *application) getWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	restaurant := getRestaurantFromContext(r)
+
+	user := getUserFromContext(r)
+	if restaurant.UserID != user.ID {
+		app.notFoundResponse(w, r, errors.New("restaurant not found"))
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		app.badRequestResponse(w, r, errors.New("invalid webhook ID"))
+		return
+	}
+
+	endpoints, err := app.store.Webhooks.ListEndpoints(r.Context(), restaurant.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	found := false
+	for _, e := range endpoints {
+		found = found || e.ID == webhookID
+	}
+	if !found {
+		app.notFoundResponse(w, r, errors.New("webhook not found"))
+		return
+	}
+
+	deliveries, err := app.store.Webhooks.ListDeliveries(r.Context(), webhookID, 100)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, r, http.StatusOK, deliveries); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+func (app *application) runWebhookDelivery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		delivered, err := app.deliverDueWebhooks(context.Background(), time.Now().UTC())
+		if err != nil {
+			app.logger.Errorw("webhook delivery failed", "error", err)
+			continue
+		}
+
+		if delivered > 0 {
+			app.logger.Infow("delivered webhook events", "count", delivered)
+		}
+	}
+}
+
+// deliverDueWebhooks sends the deliveries due by now and returns how many the
+// endpoints accepted. A failed one is retried after 1, 2, 4... minutes until
+// webhookMaxAttempts, then given up on.
+func (app *application) deliverDueWebhooks(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := app.store.Webhooks.ClaimDue(ctx, now, webhookLease, webhookBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, d := range deliveries {
+		status, sendErr := app.webhooks.Send(ctx, webhooks.Delivery{
+			URL:       d.URL,
+			Secret:    d.Secret,
+			EventID:   d.EventID,
+			EventType: d.EventType,
+			Body:      d.Payload,
+		})
+
+		var errMsg string
+		var retryAt *time.Time
+		if sendErr != nil {
+			errMsg = sendErr.Error()
+			if d.Attempts+1 < webhookMaxAttempts {
+				next := now.Add(time.Minute << d.Attempts)
+				retryAt = &next
+			}
+		} else {
+			delivered++
+		}
+
+		if err := app.store.Webhooks.RecordAttempt(ctx, d.ID, status, errMsg, retryAt); err != nil {
+			app.logger.Warnw("failed to record webhook attempt", "delivery_id", d.ID, "error", err)
+		}
+	}
+	return delivered, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/balebbae/RESA/internal/webhooks"
+)
+
+func TestEmployeeWebhookEvents(t *testing.T) {
+	setup := func(t *testing.T) (*application, *[]*store.WebhookEvent) {
+		app, mocks := newMockedApplication(t, testUserID)
+		externalID := "BHR-7"
+		app.store.Employees = &store.MockEmployeeStorer{
+			CreateFunc: func(_ context.Context, e *store.Employee) error {
+				if e.ExternalID != nil && *e.ExternalID == "BHR-1" {
+					return store.ErrDuplicateExternalID
+				}
+				e.ID = 9
+				return nil
+			},
+			GetByIDFunc: func(_ context.Context, id int64) (*store.Employee, error) {
+				return &store.Employee{ID: id, RestaurantID: 1, FullName: "Alex Smith", Email: "alex@example.com", ExternalID: &externalID}, nil
+			},
+			UpdateFunc: func(context.Context, *store.Employee) error { return nil },
+			DeleteFunc: func(context.Context, int64) error { return nil },
+		}
+
+		var queued []*store.WebhookEvent
+		mocks.webhooks.EnqueueFunc = func(_ context.Context, e *store.WebhookEvent) (int, error) {
+			queued = append(queued, e)
+			return 1, nil
+		}
+		return app, &queued
+	}
+	decode := func(t *testing.T, e *store.WebhookEvent) (map[string]any, map[string]any) {
+		t.Helper()
+		var body map[string]any
+		if err := json.Unmarshal(e.Payload, &body); err != nil {
+			t.Fatal(err)
+		}
+		data := body["data"].(map[string]any)
+		return body, data["employee"].(map[string]any)
+	}
+
+	t.Run("creating an employee emits employee.created with the external_id", func(t *testing.T) {
+		app, queued := setup(t)
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees", `{"full_name": "Sam Lee", "email": "sam@example.com", "external_id": " BHR-42 "}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusCreated, rr.Code)
+		if len(*queued) != 1 || (*queued)[0].Type != webhookEmployeeCreated || (*queued)[0].RestaurantID != 1 {
+			t.Fatalf("queued = %+v, want one employee.created", *queued)
+		}
+		body, employee := decode(t, (*queued)[0])
+		if body["id"] != (*queued)[0].ID || body["schema_version"] != float64(webhookSchemaVersion) {
+			t.Errorf("event = %v, want its ID and schema version", body)
+		}
+		if employee["id"] != float64(9) || employee["external_id"] != "BHR-42" || employee["active"] != true {
+			t.Errorf("employee = %v, want employee 9, active, with the trimmed external_id", employee)
+		}
+		// every field is present, null when it has no value
+		if v, ok := employee["hire_date"]; !ok || v != nil {
+			t.Errorf("hire_date = %v (present %v), want null", v, ok)
+		}
+	})
+
+	t.Run("an external_id another employee has is refused", func(t *testing.T) {
+		app, queued := setup(t)
+
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/employees", `{"full_name": "Sam Lee", "email": "sam@example.com", "external_id": "BHR-1"}`)
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusConflict, rr.Code)
+		if len(*queued) != 0 {
+			t.Errorf("queued = %+v, want nothing", *queued)
+		}
+	})
+
+	t.Run("updates name the fields they changed", func(t *testing.T) {
+		app, queued := setup(t)
+
+		// nothing changes, nothing is sent
+		req := authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/employees/9", `{"full_name": "Alex Smith"}`)
+		rr := executeRequest(req, app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*queued) != 0 {
+			t.Fatalf("queued = %+v for an update that changed nothing", *queued)
+		}
+
+		req = authedRequest(t, app, http.MethodPatch, "/v1/restaurants/1/employees/9", `{"external_id": "", "seniority": 4}`)
+		rr = executeRequest(req, app.mount())
+		checkResponseCode(t, http.StatusOK, rr.Code)
+		if len(*queued) != 1 || (*queued)[0].Type != webhookEmployeeUpdated {
+			t.Fatalf("queued = %+v, want one employee.updated", *queued)
+		}
+
+		var event struct {
+			Data EmployeeWebhookData `json:"data"`
+		}
+		if err := json.Unmarshal((*queued)[0].Payload, &event); err != nil {
+			t.Fatal(err)
+		}
+		changed := event.Data.Changed
+		if len(changed) != 2 || changed[0] != "external_id" || changed[1] != "seniority" || event.Data.Employee.ExternalID != nil {
+			t.Errorf("data = %+v, want external_id cleared and seniority changed", event.Data)
+		}
+	})
+
+	t.Run("removing an employee emits employee.deactivated", func(t *testing.T) {
+		app, queued := setup(t)
+
+		req := authedRequest(t, app, http.MethodDelete, "/v1/restaurants/1/employees/9", "")
+		rr := executeRequest(req, app.mount())
+
+		checkResponseCode(t, http.StatusNoContent, rr.Code)
+		if len(*queued) != 1 || (*queued)[0].Type != webhookEmployeeDeactivated {
+			t.Fatalf("queued = %+v, want one employee.deactivated", *queued)
+		}
+		_, employee := decode(t, (*queued)[0])
+		if employee["active"] != false || employee["deactivated_at"] == nil || employee["external_id"] != "BHR-7" {
+			t.Errorf("employee = %v, want inactive with deactivated_at and its external_id", employee)
+		}
+	})
+}
+
+func TestCreateWebhook(t *testing.T) {
+	app, mocks := newMockedApplication(t, testUserID)
+	mocks.webhooks.CreateEndpointFunc = func(_ context.Context, e *store.WebhookEndpoint) error {
+		e.ID = 4
+		return nil
+	}
+
+	for body, want := range map[string]int{
+		`{"url": "http://hr.example.com/hook"}`:                                  http.StatusBadRequest,
+		`{"url": "https://hr.example.com/hook", "events": ["shift.created"]}`:    http.StatusBadRequest,
+		`{"url": "https://hr.example.com/hook", "events": ["employee.updated"]}`: http.StatusCreated,
+	} {
+		req := authedRequest(t, app, http.MethodPost, "/v1/restaurants/1/webhooks", body)
+		rr := executeRequest(req, app.mount())
+
+		if rr.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rr.Code, want)
+			continue
+		}
+		if want == http.StatusCreated {
+			var resp struct {
+				Data store.WebhookEndpoint `json:"data"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Data.Secret == "" || len(resp.Data.Events) != 1 {
+				t.Errorf("endpoint = %+v, want its secret and one event type", resp.Data)
+			}
+		}
+	}
+}
+
+func TestDeliverDueWebhooks(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	type attempt struct {
+		status  int
+		errMsg  string
+		retryAt *time.Time
+	}
+	setup := func(t *testing.T, attempts int) (*application, *attempt) {
+		app, mocks := newMockedApplication(t, testUserID)
+		app.webhooks = webhooks.NewSender()
+		mocks.webhooks.ClaimDueFunc = func(context.Context, time.Time, time.Duration, int) ([]*store.WebhookDelivery, error) {
+			return []*store.WebhookDelivery{{ID: 1, EventID: "0b3a", EventType: webhookEmployeeCreated, Payload: []byte(`{}`), Attempts: attempts, URL: server.URL, Secret: "whsec_test"}}, nil
+		}
+		recorded := &attempt{}
+		mocks.webhooks.RecordAttemptFunc = func(_ context.Context, _ int64, status int, errMsg string, retryAt *time.Time) error {
+			*recorded = attempt{status, errMsg, retryAt}
+			return nil
+		}
+		return app, recorded
+	}
+	now := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("a failure is retried later", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		app, recorded := setup(t, 2)
+
+		delivered, err := app.deliverDueWebhooks(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivered != 0 || recorded.status != 500 || recorded.errMsg == "" {
+			t.Errorf("delivered %d, recorded %+v; want a 500 recorded", delivered, recorded)
+		}
+		// the third attempt waits 4 minutes
+		if recorded.retryAt == nil || !recorded.retryAt.Equal(now.Add(4*time.Minute)) {
+			t.Errorf("retry at %v, want %v", recorded.retryAt, now.Add(4*time.Minute))
+		}
+	})
+
+	t.Run("the last attempt gives up", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		app, recorded := setup(t, webhookMaxAttempts-1)
+
+		if _, err := app.deliverDueWebhooks(context.Background(), now); err != nil {
+			t.Fatal(err)
+		}
+		if recorded.errMsg == "" || recorded.retryAt != nil {
+			t.Errorf("recorded %+v, want failed for good", recorded)
+		}
+	})
+
+	t.Run("a 2xx is delivered", func(t *testing.T) {
+		status = http.StatusAccepted
+		app, recorded := setup(t, 0)
+
+		delivered, err := app.deliverDueWebhooks(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if delivered != 1 || recorded.status != http.StatusAccepted || recorded.errMsg != "" {
+			t.Errorf("delivered %d, recorded %+v; want delivered", delivered, recorded)
+		}
+	})
+}
@@ -0,0 +1,290 @@
+// Command loadtest drives the hot scheduling endpoints against a running API
+// backed by a seeded database and reports latency percentiles. With -ci it
+// exits non-zero when a scenario goes over its budget in budgets.json.
+//
+//	make seed PROFILE=load-test
+//	RATE_LIMITER_ENABLED=false go run ./cmd/api
+//	go run ./cmd/loadtest -profile=load-test -ci
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/balebbae/RESA/internal/db"
+)
+
+// request is one call a scenario makes; scenarios cycle through theirs
+type request struct {
+	method string
+	path   string
+	body   []byte
+}
+
+type scenario struct {
+	name     string
+	requests []request
+}
+
+type client struct {
+	http    *http.Client
+	baseURL string
+	token   string
+}
+
+func main() {
+	baseURL := flag.String("addr", "http://localhost:8080/v1", "API base URL")
+	profile := flag.String("profile", "demo", "seeding profile the database was seeded with, used for the login")
+	email := flag.String("email", "", "owner email (default: the profile's seeded owner)")
+	password := flag.String("password", db.SeedPassword, "owner password")
+	only := flag.String("scenarios", "list-shifts,auto-populate,assign", "comma-separated scenarios to run")
+	requests := flag.Int("n", 500, "requests per scenario")
+	warmup := flag.Int("warmup", 20, "unmeasured requests per scenario before measuring")
+	concurrency := flag.Int("c", 8, "concurrent requests")
+	restaurants := flag.Int("restaurants", 5, "number of the owner's restaurants to spread requests over")
+	ci := flag.Bool("ci", false, "fail when a scenario exceeds its budget")
+	budgetsPath := flag.String("budgets", "cmd/loadtest/budgets.json", "latency budgets used with -ci")
+	flag.Parse()
+
+	if *email == "" {
+		*email = fmt.Sprintf("owner@%s.resa.test", *profile)
+	}
+
+	ctx := context.Background()
+	c := &client{http: &http.Client{Timeout: 30 * time.Second}, baseURL: strings.TrimSuffix(*baseURL, "/")}
+
+	if err := c.login(ctx, *email, *password); err != nil {
+		log.Fatalf("logging in as %s: %v (was the database seeded?)", *email, err)
+	}
+
+	scenarios, err := c.discover(ctx, *restaurants)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var budgets map[string]budget
+	if *ci {
+		if budgets, err = loadBudgets(*budgetsPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range strings.Split(*only, ",") {
+		enabled[strings.TrimSpace(name)] = true
+	}
+
+	var results []*result
+	for _, s := range scenarios {
+		if !enabled[s.name] {
+			continue
+		}
+		if len(s.requests) == 0 {
+			log.Printf("skipping %s: nothing in the seeded data to run it against", s.name)
+			continue
+		}
+
+		c.run(ctx, s, *warmup, *concurrency)
+		results = append(results, c.run(ctx, s, *requests, *concurrency))
+	}
+
+	report(os.Stdout, results)
+
+	if !*ci {
+		return
+	}
+
+	var failures []string
+	for _, r := range results {
+		b, ok := budgets[r.scenario]
+		if !ok {
+			log.Printf("no budget for %s, not checked", r.scenario)
+			continue
+		}
+		failures = append(failures, b.violations(r)...)
+	}
+
+	if len(failures) > 0 {
+		for _, f := range failures {
+			log.Println("FAIL", f)
+		}
+		os.Exit(1)
+	}
+	log.Println("all scenarios within budget")
+}
+
+// discover builds the scenarios' requests from the owner's seeded restaurants
+func (c *client) discover(ctx context.Context, limit int) ([]*scenario, error) {
+	listShifts := &scenario{name: "list-shifts"}
+	autoPopulate := &scenario{name: "auto-populate"}
+	assign := &scenario{name: "assign"}
+
+	var restaurants []struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.get(ctx, "/restaurants", &restaurants); err != nil {
+		return nil, err
+	}
+	if len(restaurants) == 0 {
+		return nil, fmt.Errorf("the owner has no restaurants to test against")
+	}
+	if len(restaurants) > limit {
+		restaurants = restaurants[:limit]
+	}
+
+	for _, r := range restaurants {
+		var schedules []struct {
+			ID          int64      `json:"id"`
+			PublishedAt *time.Time `json:"published_at"`
+		}
+		if err := c.get(ctx, fmt.Sprintf("/restaurants/%d/schedules", r.ID), &schedules); err != nil {
+			return nil, err
+		}
+
+		for i, s := range schedules {
+			shiftsPath := fmt.Sprintf("/restaurants/%d/schedules/%d/shifts", r.ID, s.ID)
+			listShifts.requests = append(listShifts.requests, request{method: http.MethodGet, path: shiftsPath})
+
+			// Auto-populate only fills missing shifts, so repeating it on a draft leaves the data stable
+			if s.PublishedAt == nil {
+				autoPopulate.requests = append(autoPopulate.requests, request{
+					method: http.MethodPost,
+					path:   fmt.Sprintf("/restaurants/%d/schedules/%d/auto-populate", r.ID, s.ID),
+				})
+			}
+
+			if i > 0 {
+				continue
+			}
+
+			// Re-assigning a shift's own employee goes through every check without changing anything
+			var shifts []struct {
+				ID         int64  `json:"id"`
+				EmployeeID *int64 `json:"employee_id"`
+			}
+			if err := c.get(ctx, shiftsPath, &shifts); err != nil {
+				return nil, err
+			}
+			for _, shift := range shifts {
+				if shift.EmployeeID == nil {
+					continue
+				}
+				body, _ := json.Marshal(map[string]int64{"employee_id": *shift.EmployeeID})
+				assign.requests = append(assign.requests, request{
+					method: http.MethodPatch,
+					path:   fmt.Sprintf("%s/%d/assign", shiftsPath, shift.ID),
+					body:   body,
+				})
+			}
+		}
+	}
+
+	return []*scenario{listShifts, autoPopulate, assign}, nil
+}
+
+// run sends n of the scenario's requests from concurrency workers
+func (c *client) run(ctx context.Context, s *scenario, n, concurrency int) *result {
+	res := &result{scenario: s.name, latencies: make([]time.Duration, 0, n)}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan request)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				start := time.Now()
+				err := c.do(ctx, req.method, req.path, req.body, nil)
+				elapsed := time.Since(start)
+
+				mu.Lock()
+				if err != nil {
+					res.errors++
+				} else {
+					res.latencies = append(res.latencies, elapsed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- s.requests[i%len(s.requests)]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return res
+}
+
+func report(w io.Writer, results []*result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\trequests\terrors\tp50\tp95\tp99\tmax\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			r.scenario,
+			len(r.latencies)+r.errors,
+			r.errors,
+			r.percentile(50).Round(time.Microsecond),
+			r.percentile(95).Round(time.Microsecond),
+			r.percentile(99).Round(time.Microsecond),
+			r.percentile(100).Round(time.Microsecond),
+		)
+	}
+	tw.Flush()
+}
+
+func (c *client) login(ctx context.Context, email, password string) error {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	return c.do(ctx, http.MethodPost, "/authentication/token", body, &c.token)
+}
+
+func (c *client) get(ctx context.Context, path string, out any) error {
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+// do sends a request and decodes the "data" envelope into out when it's not nil
+func (c *client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if out == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+
+	envelope := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	return json.NewDecoder(resp.Body).Decode(&envelope)
+}
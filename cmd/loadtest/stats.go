@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// result holds the latencies of one scenario's requests
+type result struct {
+	scenario  string
+	latencies []time.Duration
+	errors    int
+}
+
+// percentile uses the nearest-rank method; p is between 0 and 100
+func (r *result) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// duration lets budgets be written as "150ms" in JSON
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = duration(parsed)
+	return nil
+}
+
+// budget is the slowest each percentile may be; zero means unchecked
+type budget struct {
+	P50 duration `json:"p50"`
+	P95 duration `json:"p95"`
+	P99 duration `json:"p99"`
+	// MaxErrors is how many failed requests are tolerated
+	MaxErrors int `json:"max_errors"`
+}
+
+func loadBudgets(path string) (map[string]budget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets map[string]budget
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return budgets, nil
+}
+
+// violations lists every way the result went over its budget
+func (b budget) violations(r *result) []string {
+	var out []string
+
+	for _, check := range []struct {
+		name  string
+		p     float64
+		limit duration
+	}{
+		{"p50", 50, b.P50},
+		{"p95", 95, b.P95},
+		{"p99", 99, b.P99},
+	} {
+		if check.limit == 0 {
+			continue
+		}
+		if got := r.percentile(check.p); got > time.Duration(check.limit) {
+			out = append(out, fmt.Sprintf("%s: %s %s exceeds budget of %s", r.scenario, check.name, got.Round(time.Microsecond), time.Duration(check.limit)))
+		}
+	}
+
+	if r.errors > b.MaxErrors {
+		out = append(out, fmt.Sprintf("%s: %d failed requests, budget allows %d", r.scenario, r.errors, b.MaxErrors))
+	}
+
+	return out
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	r := &result{scenario: "list-shifts"}
+	for i := 100; i >= 1; i-- {
+		r.latencies = append(r.latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	cases := map[float64]time.Duration{
+		50:  50 * time.Millisecond,
+		95:  95 * time.Millisecond,
+		99:  99 * time.Millisecond,
+		100: 100 * time.Millisecond,
+	}
+	for p, want := range cases {
+		if got := r.percentile(p); got != want {
+			t.Errorf("p%v = %s, want %s", p, got, want)
+		}
+	}
+
+	if got := (&result{}).percentile(99); got != 0 {
+		t.Errorf("empty result p99 = %s, want 0", got)
+	}
+}
+
+func TestBudgetViolations(t *testing.T) {
+	r := &result{scenario: "assign", errors: 1}
+	for i := 1; i <= 100; i++ {
+		r.latencies = append(r.latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	within := budget{P50: duration(60 * time.Millisecond), P99: duration(time.Second), MaxErrors: 1}
+	if v := within.violations(r); len(v) != 0 {
+		t.Errorf("expected no violations, got %v", v)
+	}
+
+	over := budget{P50: duration(60 * time.Millisecond), P95: duration(90 * time.Millisecond)}
+	if v := over.violations(r); len(v) != 2 {
+		t.Errorf("expected p95 and error violations, got %v", v)
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS role_certifications;
+DROP TABLE IF EXISTS employee_certifications;
+DROP TABLE IF EXISTS certifications;
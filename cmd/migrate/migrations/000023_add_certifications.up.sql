@@ -0,0 +1,31 @@
+-- Certifications a restaurant tracks (food handler card, alcohol service permit, ...)
+CREATE TABLE IF NOT EXISTS certifications (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_certifications_restaurant_name UNIQUE (restaurant_id, name)
+);
+
+-- Certifications held by an employee; NULL expires_on never expires
+CREATE TABLE IF NOT EXISTS employee_certifications (
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    certification_id INT NOT NULL REFERENCES certifications(id) ON DELETE CASCADE,
+    issued_on DATE,
+    expires_on DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (employee_id, certification_id)
+);
+
+-- Certifications an employee must hold to work a role
+CREATE TABLE IF NOT EXISTS role_certifications (
+    role_id INT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    certification_id INT NOT NULL REFERENCES certifications(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, certification_id)
+);
+
+CREATE INDEX idx_certifications_restaurant_id ON certifications(restaurant_id);
+CREATE INDEX idx_employee_certifications_expires_on ON employee_certifications(expires_on) WHERE expires_on IS NOT NULL;
+CREATE INDEX idx_role_certifications_certification_id ON role_certifications(certification_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_invitations_expiry;
+
+ALTER TABLE
+    user_invitations
+DROP
+    COLUMN IF EXISTS used_at;
@@ -0,0 +1,6 @@
+ALTER TABLE
+    user_invitations
+ADD
+    COLUMN used_at TIMESTAMP(0) WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_user_invitations_expiry ON user_invitations (expiry);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS restaurant_email_templates;
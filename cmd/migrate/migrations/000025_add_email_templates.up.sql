@@ -0,0 +1,10 @@
+-- Per-restaurant customization of schedule emails; empty columns fall back to the built-in template
+CREATE TABLE IF NOT EXISTS restaurant_email_templates (
+    restaurant_id INT PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    subject VARCHAR(200) NOT NULL DEFAULT '',
+    header_message TEXT NOT NULL DEFAULT '',
+    logo_url VARCHAR(2048) NOT NULL DEFAULT '',
+    accent_color VARCHAR(7) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
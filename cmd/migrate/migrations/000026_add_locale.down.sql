@@ -0,0 +1,2 @@
+ALTER TABLE employees DROP COLUMN IF EXISTS locale;
+ALTER TABLE users DROP COLUMN IF EXISTS locale;
@@ -0,0 +1,3 @@
+-- Preferred language for emails and API messages; NULL follows the browser's Accept-Language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10);
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS locale VARCHAR(10);
@@ -0,0 +1,15 @@
+-- Files kept in blob storage; employee_id is set for per-employee documents (signed forms, ...)
+CREATE TABLE IF NOT EXISTS documents (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id INT REFERENCES employees(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    object_key TEXT NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_documents_restaurant_id ON documents(restaurant_id);
+CREATE INDEX idx_documents_employee_id ON documents(employee_id) WHERE employee_id IS NOT NULL;
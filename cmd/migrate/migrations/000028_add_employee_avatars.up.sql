@@ -0,0 +1,2 @@
+-- Identifies the current set of resized avatar images in blob storage; NULL means no photo
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS avatar_id UUID;
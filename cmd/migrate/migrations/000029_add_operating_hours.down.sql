@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS restaurant_hours_exceptions;
+DROP TABLE IF EXISTS restaurant_operating_hours;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS hours_enforcement;
@@ -0,0 +1,29 @@
+-- How shifts outside operating hours are treated: 'off' ignores them, 'warn' flags them, 'block' rejects them
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS hours_enforcement VARCHAR(10) NOT NULL DEFAULT 'warn'
+    CHECK (hours_enforcement IN ('off', 'warn', 'block'));
+
+-- Regular weekly hours, one row per weekday (0 = Sunday)
+CREATE TABLE IF NOT EXISTS restaurant_operating_hours (
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    day_of_week SMALLINT NOT NULL CHECK (day_of_week BETWEEN 0 AND 6),
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    open_time TIME,
+    close_time TIME,
+    PRIMARY KEY (restaurant_id, day_of_week),
+    CONSTRAINT chk_operating_hours_range CHECK (closed OR (open_time IS NOT NULL AND close_time IS NOT NULL AND open_time < close_time))
+);
+
+-- Holidays and other one-off dates that override the weekly hours
+CREATE TABLE IF NOT EXISTS restaurant_hours_exceptions (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    open_time TIME,
+    close_time TIME,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_hours_exceptions_restaurant_date UNIQUE (restaurant_id, date),
+    CONSTRAINT chk_hours_exceptions_range CHECK (closed OR (open_time IS NOT NULL AND close_time IS NOT NULL AND open_time < close_time))
+);
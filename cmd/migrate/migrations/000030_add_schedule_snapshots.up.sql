@@ -0,0 +1,31 @@
+-- Copies of a schedule's shifts taken when it is published or change notifications go out,
+-- used as the baseline for "what changed since" diffs
+CREATE TABLE IF NOT EXISTS schedule_snapshots (
+    id SERIAL PRIMARY KEY,
+    schedule_id INT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('published', 'notified')),
+    shifts JSONB NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_schedule_snapshots_schedule_taken ON schedule_snapshots(schedule_id, taken_at DESC);
+
+-- Schedules published before snapshots existed get their current shifts as a baseline
+INSERT INTO schedule_snapshots (schedule_id, reason, shifts)
+SELECT s.id, 'published', COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+        'id', ss.id,
+        'role_id', ss.role_id,
+        'role_name', ss.role_name,
+        'role_color', ss.role_color,
+        'employee_id', ss.employee_id,
+        'employee_name', ss.employee_name,
+        'shift_date', ss.shift_date,
+        'start_time', ss.start_time,
+        'end_time', ss.end_time
+    ) ORDER BY ss.id)
+    FROM scheduled_shifts ss
+    WHERE ss.schedule_id = s.id
+), '[]'::jsonb)
+FROM schedules s
+WHERE s.published_at IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_scheduled_shifts_trainer_shift_id;
+ALTER TABLE scheduled_shifts
+    DROP CONSTRAINT IF EXISTS chk_scheduled_shifts_trainer,
+    DROP COLUMN IF EXISTS trainer_shift_id,
+    DROP COLUMN IF EXISTS training;
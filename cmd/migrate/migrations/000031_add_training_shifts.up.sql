@@ -0,0 +1,7 @@
+-- Training shifts let an employee work a role they don't hold yet; trainer_shift_id links the trainer's shift
+ALTER TABLE scheduled_shifts
+    ADD COLUMN IF NOT EXISTS training BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS trainer_shift_id INT REFERENCES scheduled_shifts(id) ON DELETE SET NULL,
+    ADD CONSTRAINT chk_scheduled_shifts_trainer CHECK (trainer_shift_id IS NULL OR training);
+
+CREATE INDEX idx_scheduled_shifts_trainer_shift_id ON scheduled_shifts(trainer_shift_id) WHERE trainer_shift_id IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS uq_employees_restaurant_email;
+DROP INDEX IF EXISTS uq_roles_restaurant_name;
+ALTER TABLE roles ADD CONSTRAINT roles_restaurant_id_name_key UNIQUE (restaurant_id, name);
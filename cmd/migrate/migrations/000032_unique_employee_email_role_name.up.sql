@@ -0,0 +1,29 @@
+-- Role names that only differ by case get their ID appended so the case-insensitive index can be built
+UPDATE roles r
+SET name = LEFT(r.name, 40) || ' (' || r.id || ')'
+WHERE EXISTS (
+    SELECT 1 FROM roles o
+    WHERE o.restaurant_id = r.restaurant_id AND LOWER(o.name) = LOWER(r.name) AND o.id < r.id
+);
+
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS roles_restaurant_id_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_roles_restaurant_name ON roles (restaurant_id, LOWER(name));
+
+-- Duplicate employees can't be merged automatically, so ask for them to be cleaned up first
+DO $$
+DECLARE
+    duplicates INT;
+BEGIN
+    SELECT COUNT(*) INTO duplicates
+    FROM (
+        SELECT 1 FROM employees
+        GROUP BY restaurant_id, LOWER(email)
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates > 0 THEN
+        RAISE EXCEPTION '% employee email(s) are used more than once within a restaurant; merge or delete the duplicates before migrating', duplicates;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_employees_restaurant_email ON employees (restaurant_id, LOWER(email));
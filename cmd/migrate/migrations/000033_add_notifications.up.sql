@@ -0,0 +1,19 @@
+-- In-app notifications, addressed to either an owner account or an employee
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    user_id INT REFERENCES users(id) ON DELETE CASCADE,
+    employee_id INT REFERENCES employees(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_notifications_recipient CHECK ((user_id IS NULL) <> (employee_id IS NULL))
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, id DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_notifications_employee ON notifications(employee_id, id DESC) WHERE employee_id IS NOT NULL;
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id) WHERE user_id IS NOT NULL AND read_at IS NULL;
+CREATE INDEX idx_notifications_employee_unread ON notifications(employee_id) WHERE employee_id IS NOT NULL AND read_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_scheduled_shifts_event;
+
+ALTER TABLE scheduled_shifts DROP COLUMN IF EXISTS event_id;
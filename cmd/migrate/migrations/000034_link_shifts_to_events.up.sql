@@ -0,0 +1,5 @@
+-- Extra staffing for an event is scheduled as shifts linked back to it
+ALTER TABLE scheduled_shifts
+    ADD COLUMN IF NOT EXISTS event_id INT REFERENCES events(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_shifts_event ON scheduled_shifts(event_id) WHERE event_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Who changed what and when. entity_id has no foreign key so a record's history outlives it.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    summary TEXT NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    actor_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id, id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_audit_log_restaurant;
+
+ALTER TABLE restaurants
+    DROP COLUMN IF EXISTS exported_at,
+    DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,8 @@
+-- Archived restaurants keep their data but are hidden from lists and read-only.
+-- exported_at records the last full export, which a permanent delete requires.
+ALTER TABLE restaurants
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS exported_at TIMESTAMPTZ;
+
+-- Exports read the whole audit log of a restaurant
+CREATE INDEX IF NOT EXISTS idx_audit_log_restaurant ON audit_log(restaurant_id, id);
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS clear_shift_acknowledgments_on_move ON scheduled_shifts;
+DROP FUNCTION IF EXISTS clear_shift_acknowledgments();
+
+DROP INDEX IF EXISTS idx_employees_lower_email;
+
+DROP TABLE IF EXISTS shift_acknowledgments;
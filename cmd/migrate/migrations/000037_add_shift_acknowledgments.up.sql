@@ -0,0 +1,33 @@
+-- An employee confirms they've seen a shift assigned to them on a published
+-- schedule. The row is kept per assignee, so reassigning the shift leaves the
+-- new employee unacknowledged.
+CREATE TABLE IF NOT EXISTS shift_acknowledgments (
+    shift_id BIGINT NOT NULL REFERENCES scheduled_shifts(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    acknowledged_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shift_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_acknowledgments_employee ON shift_acknowledgments(employee_id);
+
+-- Employees link to their portal account by email
+CREATE INDEX IF NOT EXISTS idx_employees_lower_email ON employees (LOWER(email));
+
+-- Moving a shift asks for a fresh acknowledgment of the new time
+CREATE OR REPLACE FUNCTION clear_shift_acknowledgments()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.shift_date IS DISTINCT FROM OLD.shift_date
+        OR NEW.start_time IS DISTINCT FROM OLD.start_time
+        OR NEW.end_time IS DISTINCT FROM OLD.end_time THEN
+        DELETE FROM shift_acknowledgments WHERE shift_id = NEW.id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS clear_shift_acknowledgments_on_move ON scheduled_shifts;
+CREATE TRIGGER clear_shift_acknowledgments_on_move
+    AFTER UPDATE OF shift_date, start_time, end_time ON scheduled_shifts
+    FOR EACH ROW
+    EXECUTE FUNCTION clear_shift_acknowledgments();
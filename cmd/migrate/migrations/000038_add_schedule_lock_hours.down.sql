@@ -0,0 +1 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS schedule_lock_hours;
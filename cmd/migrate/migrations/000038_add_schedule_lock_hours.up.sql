@@ -0,0 +1,3 @@
+-- Published shifts starting within this many hours can't be edited without an override; 0 turns the lock off
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS schedule_lock_hours INT NOT NULL DEFAULT 0
+    CHECK (schedule_lock_hours BETWEEN 0 AND 168);
@@ -0,0 +1,3 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS weekly_labor_budget_cents;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS hourly_rate_cents;
@@ -0,0 +1,7 @@
+-- Hourly rates price shifts; a restaurant's weekly budget is checked when a schedule is published.
+-- NULL means not set: unpriced shifts are reported but not costed, and no budget means no check.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS hourly_rate_cents INT
+    CHECK (hourly_rate_cents >= 0);
+
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS weekly_labor_budget_cents BIGINT
+    CHECK (weekly_labor_budget_cents > 0);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS time_entries;
+DROP TABLE IF EXISTS employee_pins;
+DROP TABLE IF EXISTS kiosks;
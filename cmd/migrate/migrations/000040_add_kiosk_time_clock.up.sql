@@ -0,0 +1,38 @@
+-- A shared tablet that clocks a restaurant's employees in and out. Only the
+-- SHA-256 of its token is kept; the token is shown once, on registration.
+CREATE TABLE IF NOT EXISTS kiosks (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kiosks_restaurant ON kiosks(restaurant_id);
+
+-- An employee's kiosk PIN, bcrypt-hashed. Repeated wrong PINs lock the
+-- employee out of the kiosk until locked_until.
+CREATE TABLE IF NOT EXISTS employee_pins (
+    employee_id BIGINT PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    pin_hash BYTEA NOT NULL,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ,
+    rotated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Time actually worked: a row per clock-in, closed by the clock-out
+CREATE TABLE IF NOT EXISTS time_entries (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    kiosk_id BIGINT REFERENCES kiosks(id) ON DELETE SET NULL,
+    clock_in_at TIMESTAMPTZ NOT NULL,
+    clock_out_at TIMESTAMPTZ,
+    CHECK (clock_out_at IS NULL OR clock_out_at >= clock_in_at)
+);
+
+-- An employee is clocked in at most once at a time
+CREATE UNIQUE INDEX IF NOT EXISTS uq_time_entries_open ON time_entries(employee_id) WHERE clock_out_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_time_entries_restaurant_clock_in ON time_entries(restaurant_id, clock_in_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS restaurant_member_roles;
+DROP TABLE IF EXISTS restaurant_members;
@@ -0,0 +1,20 @@
+-- Users other than the owner with a say in part of a restaurant. A shift lead
+-- sees and edits only the shifts of the roles in restaurant_member_roles.
+CREATE TABLE IF NOT EXISTS restaurant_members (
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    permission TEXT NOT NULL CHECK (permission IN ('shift_lead')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (restaurant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_restaurant_members_user ON restaurant_members(user_id);
+
+CREATE TABLE IF NOT EXISTS restaurant_member_roles (
+    restaurant_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    PRIMARY KEY (restaurant_id, user_id, role_id),
+    FOREIGN KEY (restaurant_id, user_id) REFERENCES restaurant_members(restaurant_id, user_id) ON DELETE CASCADE
+);
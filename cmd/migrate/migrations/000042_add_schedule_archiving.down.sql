@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_schedules_restaurant_unarchived;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS schedule_retention_months;
+
+ALTER TABLE schedules DROP COLUMN IF EXISTS archived_at;
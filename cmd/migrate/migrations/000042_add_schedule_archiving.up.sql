@@ -0,0 +1,10 @@
+-- Archived schedules are hidden from the schedule list but never deleted.
+-- A restaurant's retention setting archives schedules that ended more than
+-- that many months ago; NULL keeps them listed forever.
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS schedule_retention_months INT
+    CHECK (schedule_retention_months > 0);
+
+CREATE INDEX IF NOT EXISTS idx_schedules_restaurant_unarchived
+    ON schedules(restaurant_id, end_date) WHERE archived_at IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE employees DROP COLUMN IF EXISTS email_bounce_reason;
+ALTER TABLE employees DROP COLUMN IF EXISTS email_bounced_at;
+
+DROP TABLE IF EXISTS email_deliveries;
@@ -0,0 +1,22 @@
+-- One row per tracked schedule email, updated from SendGrid's event webhook.
+-- last_event_at keeps late, out-of-order events from overwriting newer ones.
+CREATE TABLE IF NOT EXISTS email_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id BIGINT REFERENCES employees(id) ON DELETE SET NULL,
+    email TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('schedule', 'changes', 'acknowledgment_reminder')),
+    status TEXT NOT NULL DEFAULT 'sent'
+        CHECK (status IN ('sent', 'failed', 'delivered', 'deferred', 'bounced', 'dropped', 'spam_report')),
+    detail TEXT,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_event_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_deliveries_schedule ON email_deliveries(schedule_id, sent_at DESC);
+
+-- Set when an address hard-bounces; cleared when the employee's email changes
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS email_bounced_at TIMESTAMPTZ;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS email_bounce_reason TEXT;
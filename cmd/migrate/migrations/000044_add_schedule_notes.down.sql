@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS schedule_day_notes;
+
+ALTER TABLE schedules DROP COLUMN IF EXISTS note;
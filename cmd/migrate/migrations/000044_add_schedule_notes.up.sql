@@ -0,0 +1,12 @@
+-- A note for the whole week ("Spring break week") and one note per day
+-- within it ("Health inspection Tuesday")
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS schedule_day_notes (
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    note TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (schedule_id, date)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS assignment_policy;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS seniority;
@@ -0,0 +1,8 @@
+-- Seniority orders employees when shifts are assigned automatically; higher
+-- goes first. The restaurant's policy picks how candidates are ranked, and
+-- manual_only turns automatic assignment off.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS seniority INT NOT NULL DEFAULT 0
+    CHECK (seniority >= 0);
+
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS assignment_policy VARCHAR(16) NOT NULL DEFAULT 'manual_only'
+    CHECK (assignment_policy IN ('seniority_first', 'rotate_fairly', 'manual_only'));
@@ -0,0 +1,15 @@
+-- A link that shows a schedule read-only to anyone holding it, e.g. staff
+-- without an account. Only the SHA-256 of its token is kept; the token is
+-- shown once, when the link is created.
+CREATE TABLE IF NOT EXISTS schedule_share_links (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_viewed_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_share_links_schedule ON schedule_share_links(schedule_id);
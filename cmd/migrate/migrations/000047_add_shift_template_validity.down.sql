@@ -0,0 +1,4 @@
+ALTER TABLE shift_templates
+    DROP CONSTRAINT IF EXISTS shift_templates_effective_window,
+    DROP COLUMN IF EXISTS effective_until,
+    DROP COLUMN IF EXISTS effective_from;
@@ -0,0 +1,8 @@
+-- Seasonal templates only apply between these dates, inclusive; NULL leaves that side open
+ALTER TABLE shift_templates
+    ADD COLUMN IF NOT EXISTS effective_from DATE,
+    ADD COLUMN IF NOT EXISTS effective_until DATE;
+
+ALTER TABLE shift_templates
+    ADD CONSTRAINT shift_templates_effective_window
+    CHECK (effective_from IS NULL OR effective_until IS NULL OR effective_until >= effective_from);
@@ -0,0 +1,32 @@
+-- An account's authenticator app. confirmed_at is set once a code from the
+-- app has been checked; until then the secret is only a pending enrollment.
+-- enabled toggles whether sign-in asks for a code. last_step is the TOTP time
+-- step of the last code accepted, so a code can't be used twice.
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret TEXT NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    last_step BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Single-use codes for when the authenticator app is lost; only their SHA-256 is kept
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    UNIQUE (user_id, code_hash)
+);
+
+-- A password sign-in waiting for its second factor. The token is handed to
+-- the client instead of a JWT and swapped for one with a valid code.
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user ON login_challenges(user_id);
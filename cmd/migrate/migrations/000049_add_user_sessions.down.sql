@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_security_events;
+DROP TABLE IF EXISTS user_sessions;
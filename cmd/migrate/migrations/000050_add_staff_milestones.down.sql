@@ -0,0 +1,5 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS milestone_digest_sent_on;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS staff_milestone_digest;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS hire_date;
+ALTER TABLE employees DROP COLUMN IF EXISTS birthday;
@@ -0,0 +1,9 @@
+-- Optional dates for staff birthday and work anniversary reminders
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS birthday DATE;
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS hire_date DATE;
+
+-- Restaurants opt in to a weekly email of the coming week's birthdays and
+-- anniversaries. milestone_digest_sent_on is the day the last one was sent,
+-- so each goes out once a week however often the job runs.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS staff_milestone_digest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS milestone_digest_sent_on DATE;
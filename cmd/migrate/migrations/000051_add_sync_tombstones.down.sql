@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS trg_event_employees_touch_event ON event_employees;
+DROP FUNCTION IF EXISTS touch_event_on_staff_change();
+
+DROP TRIGGER IF EXISTS trg_events_sync_tombstone ON events;
+DROP TRIGGER IF EXISTS trg_scheduled_shifts_sync_tombstone ON scheduled_shifts;
+DROP TRIGGER IF EXISTS trg_schedules_sync_tombstone ON schedules;
+DROP TRIGGER IF EXISTS trg_employees_sync_tombstone ON employees;
+DROP TRIGGER IF EXISTS trg_roles_sync_tombstone ON roles;
+DROP FUNCTION IF EXISTS record_sync_tombstone();
+
+DROP TABLE IF EXISTS sync_tombstones;
+
+DROP INDEX IF EXISTS idx_events_restaurant_updated_at;
+DROP INDEX IF EXISTS idx_scheduled_shifts_restaurant_updated_at;
+DROP INDEX IF EXISTS idx_schedules_restaurant_updated_at;
+DROP INDEX IF EXISTS idx_employees_restaurant_updated_at;
+DROP INDEX IF EXISTS idx_roles_restaurant_updated_at;
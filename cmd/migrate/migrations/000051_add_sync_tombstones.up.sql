@@ -0,0 +1,65 @@
+-- Incremental sync: rows changed since a cursor are found through updated_at,
+-- deleted ones through the tombstones their delete leaves behind
+CREATE INDEX IF NOT EXISTS idx_roles_restaurant_updated_at ON roles(restaurant_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_employees_restaurant_updated_at ON employees(restaurant_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_schedules_restaurant_updated_at ON schedules(restaurant_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_scheduled_shifts_restaurant_updated_at ON scheduled_shifts(restaurant_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_events_restaurant_updated_at ON events(restaurant_id, updated_at);
+
+-- No foreign key: tombstones outlive the rows they stand for
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_sync_tombstones_restaurant_deleted_at ON sync_tombstones(restaurant_id, deleted_at);
+
+-- Record a deleted row, unless its restaurant is going with it
+CREATE OR REPLACE FUNCTION record_sync_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM restaurants WHERE id = OLD.restaurant_id) THEN
+        INSERT INTO sync_tombstones (restaurant_id, entity, entity_id)
+        VALUES (OLD.restaurant_id, TG_ARGV[0], OLD.id);
+    END IF;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_roles_sync_tombstone
+AFTER DELETE ON roles
+FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('role');
+
+CREATE TRIGGER trg_employees_sync_tombstone
+AFTER DELETE ON employees
+FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('employee');
+
+CREATE TRIGGER trg_schedules_sync_tombstone
+AFTER DELETE ON schedules
+FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('schedule');
+
+CREATE TRIGGER trg_scheduled_shifts_sync_tombstone
+AFTER DELETE ON scheduled_shifts
+FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('shift');
+
+CREATE TRIGGER trg_events_sync_tombstone
+AFTER DELETE ON events
+FOR EACH ROW EXECUTE FUNCTION record_sync_tombstone('event');
+
+-- An event's staff list is part of the event, so changing it changes the event
+CREATE OR REPLACE FUNCTION touch_event_on_staff_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE events
+    SET updated_at = NOW()
+    WHERE id = COALESCE(NEW.event_id, OLD.event_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_event_employees_touch_event
+AFTER INSERT OR DELETE ON event_employees
+FOR EACH ROW EXECUTE FUNCTION touch_event_on_staff_change();
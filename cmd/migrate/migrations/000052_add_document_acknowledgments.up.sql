@@ -0,0 +1,20 @@
+-- A request for an employee to acknowledge a restaurant document, e.g. a
+-- policy, through an emailed link. Only the SHA-256 of the link's token is
+-- kept; asking again replaces it until the employee has acknowledged.
+CREATE TABLE IF NOT EXISTS document_acknowledgments (
+    id BIGSERIAL PRIMARY KEY,
+    document_id INT NOT NULL REFERENCES documents(id) ON DELETE CASCADE,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+    -- the name the employee typed to sign, and the device they signed from
+    signed_name VARCHAR(255),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    UNIQUE (document_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_acknowledgments_employee ON document_acknowledgments(employee_id);
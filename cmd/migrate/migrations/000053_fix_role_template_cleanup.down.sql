@@ -0,0 +1,9 @@
+CREATE OR REPLACE FUNCTION remove_deleted_role_from_templates()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE shift_templates
+    SET role_ids = role_ids - OLD.id::text::jsonb
+    WHERE role_ids ? OLD.id::text;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
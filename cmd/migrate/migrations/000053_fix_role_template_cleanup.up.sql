@@ -0,0 +1,28 @@
+-- role_ids holds numbers, which the ? operator never matches, so deleting a role
+-- left its ID behind in shift templates. Match and remove it as a number.
+CREATE OR REPLACE FUNCTION remove_deleted_role_from_templates()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE shift_templates
+    SET role_ids = COALESCE((
+        SELECT jsonb_agg(e.value ORDER BY e.ord)
+        FROM jsonb_array_elements(role_ids) WITH ORDINALITY AS e(value, ord)
+        WHERE e.value <> to_jsonb(OLD.id)
+    ), '[]'::jsonb)
+    WHERE role_ids @> jsonb_build_array(OLD.id);
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+-- drop the IDs of roles already deleted
+UPDATE shift_templates t
+SET role_ids = COALESCE((
+    SELECT jsonb_agg(e.value ORDER BY e.ord)
+    FROM jsonb_array_elements(t.role_ids) WITH ORDINALITY AS e(value, ord)
+    WHERE EXISTS (SELECT 1 FROM roles r WHERE r.id = (e.value)::bigint)
+), '[]'::jsonb)
+WHERE EXISTS (
+    SELECT 1
+    FROM jsonb_array_elements(t.role_ids) AS e(value)
+    WHERE NOT EXISTS (SELECT 1 FROM roles r WHERE r.id = (e.value)::bigint)
+);
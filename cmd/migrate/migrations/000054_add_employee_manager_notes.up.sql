@@ -0,0 +1,11 @@
+-- Private notes managers keep on an employee; append-only, never shown to employees
+CREATE TABLE IF NOT EXISTS employee_manager_notes (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    author_user_id INT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_employee_manager_notes_employee ON employee_manager_notes(employee_id, id);
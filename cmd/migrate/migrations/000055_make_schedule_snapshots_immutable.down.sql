@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_schedule_snapshots_published;
+DROP TRIGGER IF EXISTS trg_forbid_snapshot_update ON schedule_snapshots;
+DROP FUNCTION IF EXISTS forbid_snapshot_update();
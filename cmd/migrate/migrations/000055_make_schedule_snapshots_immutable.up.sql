@@ -0,0 +1,20 @@
+-- A snapshot is the record of what was published or announced; it can be deleted
+-- with its schedule but not rewritten. The one exception is erasing an employee,
+-- which sets resa.erasing_employee for its transaction.
+CREATE OR REPLACE FUNCTION forbid_snapshot_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('resa.erasing_employee', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'schedule snapshots are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_forbid_snapshot_update
+BEFORE UPDATE ON schedule_snapshots
+FOR EACH ROW EXECUTE FUNCTION forbid_snapshot_update();
+
+CREATE INDEX IF NOT EXISTS idx_schedule_snapshots_published
+ON schedule_snapshots(schedule_id, taken_at)
+WHERE reason = 'published';
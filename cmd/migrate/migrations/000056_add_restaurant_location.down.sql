@@ -0,0 +1,5 @@
+ALTER TABLE restaurants DROP CONSTRAINT IF EXISTS restaurants_location_complete;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS longitude;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS latitude;
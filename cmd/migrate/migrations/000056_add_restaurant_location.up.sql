@@ -0,0 +1,10 @@
+-- Coordinates look up the weather forecast for schedules; NULL leaves weather out.
+-- They are set together or not at all.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION
+    CHECK (latitude BETWEEN -90 AND 90);
+
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION
+    CHECK (longitude BETWEEN -180 AND 180);
+
+ALTER TABLE restaurants ADD CONSTRAINT restaurants_location_complete
+    CHECK ((latitude IS NULL) = (longitude IS NULL));
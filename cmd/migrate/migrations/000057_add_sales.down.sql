@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sales_webhooks;
+
+DROP TABLE IF EXISTS sales_records;
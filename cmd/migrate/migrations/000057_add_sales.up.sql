@@ -0,0 +1,26 @@
+-- Sales and cover counts imported from a point of sale, to compare demand with
+-- staffing. A row is a whole day (hour NULL) or one hour of it; importing the
+-- same slot again replaces it.
+CREATE TABLE IF NOT EXISTS sales_records (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    sales_date DATE NOT NULL,
+    hour SMALLINT CHECK (hour BETWEEN 0 AND 23),
+    sales_cents BIGINT CHECK (sales_cents >= 0),
+    covers INT CHECK (covers >= 0),
+    source TEXT NOT NULL CHECK (source IN ('csv', 'api', 'webhook')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (sales_cents IS NOT NULL OR covers IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sales_records_slot
+    ON sales_records (restaurant_id, sales_date, (COALESCE(hour, -1)));
+
+-- The token a restaurant's POS posts sales with. Only its SHA-256 is kept;
+-- the token is shown once, when it's issued.
+CREATE TABLE IF NOT EXISTS sales_webhooks (
+    restaurant_id BIGINT PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
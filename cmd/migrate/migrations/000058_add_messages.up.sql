@@ -0,0 +1,22 @@
+-- Announcements a restaurant sends its staff. Recipients are picked when the message
+-- goes out: everyone, or the employees holding one of role_ids plus those listed in
+-- employee_ids. A scheduled message waits until send_at and can be canceled until then.
+CREATE TABLE IF NOT EXISTS messages (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    channels TEXT[] NOT NULL,
+    role_ids BIGINT[] NOT NULL DEFAULT '{}',
+    employee_ids BIGINT[] NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL CHECK (status IN ('scheduled', 'sending', 'sent', 'canceled')),
+    send_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    recipients INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_restaurant ON messages (restaurant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_due ON messages (send_at) WHERE status = 'scheduled';
@@ -0,0 +1,14 @@
+-- Quick feedback from the employee who worked a shift, once it has ended: a 1-5
+-- rating and an optional comment. Submitting again replaces it.
+CREATE TABLE IF NOT EXISTS shift_feedback (
+    shift_id BIGINT NOT NULL REFERENCES scheduled_shifts(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (shift_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shift_feedback_restaurant ON shift_feedback(restaurant_id);
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_scheduled_shifts_restaurant_date ON scheduled_shifts(restaurant_id, shift_date);
+
+DROP INDEX IF EXISTS idx_scheduled_shifts_restaurant_date_id;
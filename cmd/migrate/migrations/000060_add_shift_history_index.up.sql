@@ -0,0 +1,8 @@
+-- Keyset pages of a restaurant's shifts seek on (shift_date, id). The included
+-- columns are the ones the coverage and pattern reports read, so they can scan
+-- the index alone. It supersedes the (restaurant_id, shift_date) index.
+CREATE INDEX IF NOT EXISTS idx_scheduled_shifts_restaurant_date_id
+    ON scheduled_shifts (restaurant_id, shift_date, id)
+    INCLUDE (role_id, employee_id, start_time, end_time, role_name, role_color);
+
+DROP INDEX IF EXISTS idx_scheduled_shifts_restaurant_date;
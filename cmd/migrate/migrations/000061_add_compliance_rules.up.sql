@@ -0,0 +1,17 @@
+-- Working-hour compliance rules checked when employees are assigned and schedules
+-- are published, one row per restaurant; restaurants without one check nothing.
+-- A limit of 0 turns its rule off, as does a severity of 'off'.
+CREATE TABLE IF NOT EXISTS compliance_rules (
+    restaurant_id BIGINT PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    jurisdiction TEXT NOT NULL DEFAULT 'custom',
+    min_rest_hours SMALLINT NOT NULL DEFAULT 0 CHECK (min_rest_hours BETWEEN 0 AND 24),
+    rest_severity TEXT NOT NULL DEFAULT 'off' CHECK (rest_severity IN ('off', 'warn', 'block')),
+    max_consecutive_days SMALLINT NOT NULL DEFAULT 0 CHECK (max_consecutive_days BETWEEN 0 AND 13),
+    consecutive_days_severity TEXT NOT NULL DEFAULT 'off' CHECK (consecutive_days_severity IN ('off', 'warn', 'block')),
+    minor_age SMALLINT NOT NULL DEFAULT 0 CHECK (minor_age BETWEEN 0 AND 21),
+    minor_max_daily_hours SMALLINT NOT NULL DEFAULT 0 CHECK (minor_max_daily_hours BETWEEN 0 AND 24),
+    minor_max_weekly_hours SMALLINT NOT NULL DEFAULT 0 CHECK (minor_max_weekly_hours BETWEEN 0 AND 168),
+    minor_latest_end TIME,
+    minor_severity TEXT NOT NULL DEFAULT 'off' CHECK (minor_severity IN ('off', 'warn', 'block')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,39 @@
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'roles',
+        'employees',
+        'shift_templates',
+        'schedules',
+        'scheduled_shifts',
+        'events',
+        'certifications',
+        'restaurant_email_templates',
+        'documents',
+        'restaurant_operating_hours',
+        'restaurant_hours_exceptions',
+        'notifications',
+        'audit_log',
+        'kiosks',
+        'time_entries',
+        'restaurant_members',
+        'restaurant_member_roles',
+        'email_deliveries',
+        'schedule_share_links',
+        'sync_tombstones',
+        'document_acknowledgments',
+        'employee_manager_notes',
+        'sales_records',
+        'sales_webhooks',
+        'messages',
+        'shift_feedback',
+        'compliance_rules'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS restaurant_isolation ON %I', t);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+    END LOOP;
+END
+$$;
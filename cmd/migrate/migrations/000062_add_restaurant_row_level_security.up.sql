@@ -0,0 +1,49 @@
+-- Row-level security on every table with a restaurant_id, as defence in depth
+-- behind the API's ownership checks. A transaction that sets app.restaurant_id
+-- (the API does when DB_ROW_LEVEL_SECURITY is on) only sees and writes that
+-- restaurant's rows; without the setting every row is visible, as before.
+-- Superusers and BYPASSRLS roles skip policies, so the API has to connect as a
+-- plain role for them to apply.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY[
+        'roles',
+        'employees',
+        'shift_templates',
+        'schedules',
+        'scheduled_shifts',
+        'events',
+        'certifications',
+        'restaurant_email_templates',
+        'documents',
+        'restaurant_operating_hours',
+        'restaurant_hours_exceptions',
+        'notifications',
+        'audit_log',
+        'kiosks',
+        'time_entries',
+        'restaurant_members',
+        'restaurant_member_roles',
+        'email_deliveries',
+        'schedule_share_links',
+        'sync_tombstones',
+        'document_acknowledgments',
+        'employee_manager_notes',
+        'sales_records',
+        'sales_webhooks',
+        'messages',
+        'shift_feedback',
+        'compliance_rules'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS leave_entries;
+DROP TABLE IF EXISTS leave_policies;
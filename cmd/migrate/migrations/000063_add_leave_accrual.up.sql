@@ -0,0 +1,49 @@
+-- Paid leave accrual: each restaurant's policy, and a ledger of the hours each
+-- employee accrued, was paid out and had adjusted. A balance is the sum of an
+-- employee's entries. Restaurants without a policy row accrue nothing.
+CREATE TABLE IF NOT EXISTS leave_policies (
+    restaurant_id INT PRIMARY KEY REFERENCES restaurants(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    basis TEXT NOT NULL DEFAULT 'worked' CHECK (basis IN ('worked', 'scheduled')),
+    accrual_hours NUMERIC(5, 2) NOT NULL CHECK (accrual_hours > 0),
+    per_hours NUMERIC(5, 2) NOT NULL CHECK (per_hours > 0),
+    max_balance_hours NUMERIC(7, 2) NOT NULL DEFAULT 0 CHECK (max_balance_hours >= 0),
+    accrue_from DATE NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS leave_entries (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id INT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('accrual', 'paid', 'adjustment')),
+    hours NUMERIC(7, 2) NOT NULL,
+    -- the Monday of the week accrued, or the day of leave paid
+    entry_date DATE NOT NULL,
+    -- the hours worked or scheduled an accrual was earned on
+    basis_hours NUMERIC(7, 2),
+    note TEXT NOT NULL DEFAULT '',
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- each week accrues once per employee
+CREATE UNIQUE INDEX IF NOT EXISTS idx_leave_entries_accrual ON leave_entries(employee_id, entry_date) WHERE kind = 'accrual';
+CREATE INDEX IF NOT EXISTS idx_leave_entries_restaurant_date ON leave_entries(restaurant_id, entry_date);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['leave_policies', 'leave_entries'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bid_preferences;
+DROP TABLE IF EXISTS bid_round_shifts;
+DROP TABLE IF EXISTS bid_rounds;
@@ -0,0 +1,59 @@
+-- Shift bidding: a set of a schedule's open shifts is put up for bids until a
+-- closing time, employees rank the ones they want, and an allocation pass assigns
+-- them. Each shift records who was awarded it and at what rank.
+CREATE TABLE IF NOT EXISTS bid_rounds (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'allocated')),
+    closes_at TIMESTAMPTZ NOT NULL,
+    -- how many hours already won one point of seniority makes up for in the draft order
+    seniority_weight NUMERIC(6, 2) NOT NULL DEFAULT 0 CHECK (seniority_weight >= 0),
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    allocated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_rounds_schedule ON bid_rounds(schedule_id);
+CREATE INDEX IF NOT EXISTS idx_bid_rounds_open ON bid_rounds(restaurant_id) WHERE status = 'open';
+
+CREATE TABLE IF NOT EXISTS bid_round_shifts (
+    round_id BIGINT NOT NULL REFERENCES bid_rounds(id) ON DELETE CASCADE,
+    shift_id BIGINT NOT NULL REFERENCES scheduled_shifts(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    awarded_employee_id BIGINT REFERENCES employees(id) ON DELETE SET NULL,
+    awarded_rank INT,
+    PRIMARY KEY (round_id, shift_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bid_round_shifts_shift ON bid_round_shifts(shift_id);
+
+CREATE TABLE IF NOT EXISTS bid_preferences (
+    round_id BIGINT NOT NULL,
+    shift_id BIGINT NOT NULL,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    -- 1 is the employee's first choice
+    rank INT NOT NULL CHECK (rank > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (round_id, employee_id, shift_id),
+    UNIQUE (round_id, employee_id, rank),
+    FOREIGN KEY (round_id, shift_id) REFERENCES bid_round_shifts(round_id, shift_id) ON DELETE CASCADE
+);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['bid_rounds', 'bid_round_shifts', 'bid_preferences'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
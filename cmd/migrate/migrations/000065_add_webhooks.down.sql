@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_employees_restaurant_external_id;
+ALTER TABLE employees DROP COLUMN IF EXISTS external_id;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
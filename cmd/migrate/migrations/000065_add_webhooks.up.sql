@@ -0,0 +1,57 @@
+-- Outgoing webhooks: a restaurant registers URLs to be told about events, and
+-- each event is queued once per subscribed endpoint and delivered by a worker,
+-- retried with backoff until the endpoint answers 2xx or attempts run out.
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    -- signs each delivery so the receiver can check it came from us
+    secret TEXT NOT NULL,
+    -- the event types sent; empty for all of them
+    events TEXT[] NOT NULL DEFAULT '{}',
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_restaurant ON webhook_endpoints(restaurant_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    endpoint_id BIGINT NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    response_status INT,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at DESC);
+
+-- the employee's ID in an HR system, so it can match its records to ours
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS external_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_employees_restaurant_external_id
+    ON employees(restaurant_id, external_id) WHERE external_id IS NOT NULL;
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['webhook_endpoints', 'webhook_deliveries'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,32 @@
+-- Display boards: read-only screens, like a TV in the back of house, that show
+-- the day's published shifts. Each authenticates with a token in its URL; only
+-- the token's hash is stored.
+CREATE TABLE IF NOT EXISTS display_boards (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_display_boards_restaurant ON display_boards(restaurant_id);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['display_boards'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,41 @@
+-- Saved reports: a report kind with its filters, optionally emailed as a CSV
+-- every week on a chosen weekday by a background job.
+CREATE TABLE IF NOT EXISTS saved_reports (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('hours_by_employee', 'coverage_by_role', 'labor_cost')),
+    -- filters: empty arrays include everything
+    role_ids BIGINT[] NOT NULL DEFAULT '{}',
+    employee_ids BIGINT[] NOT NULL DEFAULT '{}',
+    -- the report covers this many days up to the day before it runs
+    period_days INT NOT NULL DEFAULT 7 CHECK (period_days BETWEEN 1 AND 366),
+    delivery TEXT NOT NULL DEFAULT 'none' CHECK (delivery IN ('none', 'weekly')),
+    -- 0 = Sunday
+    delivery_weekday SMALLINT NOT NULL DEFAULT 1 CHECK (delivery_weekday BETWEEN 0 AND 6),
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    last_sent_on DATE,
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saved_reports_restaurant ON saved_reports(restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_saved_reports_weekly ON saved_reports(delivery_weekday) WHERE delivery = 'weekly';
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['saved_reports'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
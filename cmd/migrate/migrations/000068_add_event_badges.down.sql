@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS event_check_ins;
+DROP TABLE IF EXISTS event_badges;
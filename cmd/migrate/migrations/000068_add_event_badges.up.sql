@@ -0,0 +1,44 @@
+-- Event badges: a token per employee and event, shown as a QR code or written
+-- to an NFC tag, that a manager scans to take attendance. Only the token's
+-- hash is stored; issuing a new badge replaces the employee's old one.
+CREATE TABLE IF NOT EXISTS event_badges (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (event_id, employee_id)
+);
+
+-- Event check-ins: the first scan of an employee's badge at an event
+CREATE TABLE IF NOT EXISTS event_check_ins (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    method TEXT NOT NULL CHECK (method IN ('qr', 'nfc')),
+    scanned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    checked_in_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (event_id, employee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_badges_restaurant ON event_badges(restaurant_id);
+CREATE INDEX IF NOT EXISTS idx_event_check_ins_restaurant ON event_check_ins(restaurant_id);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['event_badges', 'event_check_ins'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_check_schedule_keeps_shifts ON schedules;
+DROP FUNCTION IF EXISTS check_schedule_keeps_shifts();
+DROP TRIGGER IF EXISTS trg_check_shift_within_schedule ON scheduled_shifts;
+DROP FUNCTION IF EXISTS check_shift_within_schedule();
@@ -0,0 +1,53 @@
+-- Keep every shift within its schedule's start and end dates. A CHECK can't
+-- look at another table, so triggers refuse a shift placed outside its
+-- schedule and a schedule shrunk so that its shifts fall outside it. Both
+-- raise check_violation naming scheduled_shifts_within_schedule, which the
+-- store turns into ErrShiftOutsideSchedule. Shifts already outside their
+-- schedule are left alone until they're moved or the schedule is shrunk.
+CREATE OR REPLACE FUNCTION check_shift_within_schedule()
+RETURNS TRIGGER AS $$
+DECLARE
+    schedule_start DATE;
+    schedule_end DATE;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.shift_date = OLD.shift_date AND NEW.schedule_id = OLD.schedule_id THEN
+        RETURN NEW;
+    END IF;
+
+    SELECT start_date, end_date INTO schedule_start, schedule_end
+    FROM schedules
+    WHERE id = NEW.schedule_id;
+
+    IF NEW.shift_date < schedule_start OR NEW.shift_date > schedule_end THEN
+        RAISE EXCEPTION 'shift date % is outside schedule % (% to %)',
+            NEW.shift_date, NEW.schedule_id, schedule_start, schedule_end
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'scheduled_shifts_within_schedule';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_check_shift_within_schedule
+BEFORE INSERT OR UPDATE ON scheduled_shifts
+FOR EACH ROW EXECUTE FUNCTION check_shift_within_schedule();
+
+CREATE OR REPLACE FUNCTION check_schedule_keeps_shifts()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM scheduled_shifts
+        WHERE schedule_id = NEW.id
+          AND (shift_date < NEW.start_date OR shift_date > NEW.end_date)
+    ) THEN
+        RAISE EXCEPTION 'schedule % would leave shifts outside % to %', NEW.id, NEW.start_date, NEW.end_date
+            USING ERRCODE = 'check_violation', CONSTRAINT = 'scheduled_shifts_within_schedule';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_check_schedule_keeps_shifts
+BEFORE UPDATE OF start_date, end_date ON schedules
+FOR EACH ROW
+WHEN (NEW.start_date > OLD.start_date OR NEW.end_date < OLD.end_date)
+EXECUTE FUNCTION check_schedule_keeps_shifts();
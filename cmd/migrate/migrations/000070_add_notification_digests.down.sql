@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS notification_digest_items;
+ALTER TABLE messages DROP COLUMN IF EXISTS critical;
+ALTER TABLE employees DROP COLUMN IF EXISTS notification_mode;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS digest_hour, DROP COLUMN IF EXISTS notification_mode;
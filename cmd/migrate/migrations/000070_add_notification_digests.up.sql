@@ -0,0 +1,47 @@
+-- How staff hear about shift changes and announcements: straight away, or batched
+-- into one email a day (daily) or on the days they work (shift_day), sent from
+-- digest_hour UTC. An employee's own notification_mode overrides the restaurant's.
+ALTER TABLE restaurants
+    ADD COLUMN IF NOT EXISTS notification_mode TEXT NOT NULL DEFAULT 'immediate'
+        CHECK (notification_mode IN ('immediate', 'daily', 'shift_day')),
+    ADD COLUMN IF NOT EXISTS digest_hour SMALLINT NOT NULL DEFAULT 7
+        CHECK (digest_hour BETWEEN 0 AND 23);
+
+ALTER TABLE employees
+    ADD COLUMN IF NOT EXISTS notification_mode TEXT
+        CHECK (notification_mode IN ('immediate', 'daily', 'shift_day'));
+
+-- A critical announcement is emailed straight away whatever the mode
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS critical BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- What waits for an employee's next digest; sent_at is set when a digest claims it
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    employee_id BIGINT NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('announcement', 'schedule_changes')),
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_digest_items_pending
+    ON notification_digest_items (employee_id, created_at) WHERE sent_at IS NULL;
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['notification_digest_items'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS retention_policies;
+DROP INDEX IF EXISTS idx_employees_terminated;
+ALTER TABLE employees DROP COLUMN IF EXISTS erased_at, DROP COLUMN IF EXISTS terminated_on;
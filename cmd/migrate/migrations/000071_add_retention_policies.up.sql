@@ -0,0 +1,41 @@
+-- The day an employee left; retention policies anonymize them some months after.
+-- erased_at marks an employee whose personal data has been erased.
+ALTER TABLE employees
+    ADD COLUMN IF NOT EXISTS terminated_on DATE,
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
+UPDATE employees SET erased_at = updated_at WHERE email LIKE 'erased-%@erased.invalid';
+
+CREATE INDEX IF NOT EXISTS idx_employees_terminated
+    ON employees (restaurant_id, terminated_on) WHERE erased_at IS NULL AND terminated_on IS NOT NULL;
+
+-- Data retention rules, at most one of each kind per restaurant, applied by a
+-- background job to data older than after_months
+CREATE TABLE IF NOT EXISTS retention_policies (
+    id BIGSERIAL PRIMARY KEY,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('anonymize_terminated_employees', 'delete_time_entries')),
+    after_months INT NOT NULL CHECK (after_months BETWEEN 1 AND 1200),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (restaurant_id, kind)
+);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['retention_policies'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,2 @@
+-- The restaurant's IANA timezone; dates and times shown to staff are in it
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
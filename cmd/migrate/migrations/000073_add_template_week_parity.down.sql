@@ -0,0 +1,3 @@
+ALTER TABLE shift_templates DROP COLUMN IF EXISTS week_parity;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS rotation_anchor;
@@ -0,0 +1,6 @@
+-- Alternating two-week rotations: a template for week a or b applies every other
+-- week, counted in seven-day weeks from its restaurant's rotation_anchor
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS rotation_anchor DATE;
+
+ALTER TABLE shift_templates
+    ADD COLUMN IF NOT EXISTS week_parity TEXT CHECK (week_parity IN ('a', 'b'));
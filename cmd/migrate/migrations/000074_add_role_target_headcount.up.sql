@@ -0,0 +1,3 @@
+-- How many employees a restaurant wants able to work each role, for the staffing-gap report
+ALTER TABLE roles
+    ADD COLUMN IF NOT EXISTS target_headcount INT CHECK (target_headcount > 0);
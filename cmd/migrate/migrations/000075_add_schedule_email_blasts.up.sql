@@ -0,0 +1,16 @@
+-- One row per send-email run on a schedule, with the recipients it failed to
+-- reach so they can be retried. retry_of is the blast a retry re-sent.
+CREATE TABLE IF NOT EXISTS schedule_email_blasts (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    retry_of BIGINT REFERENCES schedule_email_blasts(id) ON DELETE SET NULL,
+    include_events BOOLEAN NOT NULL DEFAULT FALSE,
+    total_recipients INT NOT NULL,
+    successful INT NOT NULL,
+    failed INT NOT NULL,
+    failures JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_email_blasts_schedule ON schedule_email_blasts(schedule_id, created_at DESC);
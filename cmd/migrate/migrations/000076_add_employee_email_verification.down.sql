@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS employee_email_verifications;
+ALTER TABLE employees DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS require_email_verification;
@@ -0,0 +1,17 @@
+-- When set, schedules aren't emailed to an employee until they've confirmed
+-- their address through an emailed link.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS require_email_verification BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Cleared whenever the employee's email changes.
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- The outstanding verification link for an employee's address. Only the
+-- SHA-256 of the link's token is kept; asking again replaces it, and it only
+-- verifies the address it was sent to.
+CREATE TABLE IF NOT EXISTS employee_email_verifications (
+    employee_id INT PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
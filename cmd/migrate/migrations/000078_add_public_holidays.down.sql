@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS restaurant_holiday_years;
+DELETE FROM restaurant_hours_exceptions WHERE NOT closed AND open_time IS NULL;
+ALTER TABLE restaurant_hours_exceptions DROP CONSTRAINT IF EXISTS chk_hours_exceptions_range;
+ALTER TABLE restaurant_hours_exceptions ADD CONSTRAINT chk_hours_exceptions_range CHECK (closed OR (open_time IS NOT NULL AND close_time IS NOT NULL AND open_time < close_time));
+ALTER TABLE restaurant_hours_exceptions DROP COLUMN IF EXISTS holiday_region;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS holidays_closed;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS holiday_region;
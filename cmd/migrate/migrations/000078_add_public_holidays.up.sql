@@ -0,0 +1,43 @@
+-- The country, or country-subdivision (ISO 3166-2, e.g. US-CA), whose public
+-- holidays are added to the restaurant's special dates each year; '' is off.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS holiday_region VARCHAR(10) NOT NULL DEFAULT '';
+-- Holidays are added as closures when set, otherwise as days on regular hours.
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS holidays_closed BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Set on special dates added from the public holiday calendar of the region.
+ALTER TABLE restaurant_hours_exceptions ADD COLUMN IF NOT EXISTS holiday_region VARCHAR(10);
+
+-- A holiday on regular hours has neither closed nor times of its own.
+ALTER TABLE restaurant_hours_exceptions DROP CONSTRAINT IF EXISTS chk_hours_exceptions_range;
+ALTER TABLE restaurant_hours_exceptions ADD CONSTRAINT chk_hours_exceptions_range CHECK (
+    closed
+    OR (open_time IS NOT NULL AND close_time IS NOT NULL AND open_time < close_time)
+    OR (holiday_region IS NOT NULL AND open_time IS NULL AND close_time IS NULL)
+);
+
+-- The years whose holidays have been added for a region, so holidays the
+-- owner deletes aren't added back.
+CREATE TABLE IF NOT EXISTS restaurant_holiday_years (
+    restaurant_id BIGINT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+    year SMALLINT NOT NULL,
+    region VARCHAR(10) NOT NULL,
+    populated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (restaurant_id, year, region)
+);
+
+-- the same row-level security as the other restaurant tables
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['restaurant_holiday_years'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+        EXECUTE format(
+            $p$CREATE POLICY restaurant_isolation ON %I
+                USING (COALESCE(current_setting('app.restaurant_id', true), '') = ''
+                       OR restaurant_id = current_setting('app.restaurant_id', true)::BIGINT)$p$,
+            t);
+    END LOOP;
+END
+$$;
@@ -0,0 +1,2 @@
+-- The day the restaurant's weeks begin, 0 (Sunday) to 6 (Saturday); Monday by default
+ALTER TABLE restaurants ADD COLUMN IF NOT EXISTS week_start SMALLINT NOT NULL DEFAULT 1 CHECK (week_start BETWEEN 0 AND 6);
@@ -24,6 +24,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/authentication/activation-status": {
+            "get": {
+                "description": "Reports whether an activation token is valid, expired, already used or invalid without consuming it, so the frontend can show the right message",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Checks an invitation token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ActivationStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
         "/authentication/google": {
             "post": {
                 "description": "Generates and returns the Google OAuth authorization URL",
@@ -97,6 +134,26 @@ const docTemplate = `{
                 }
             }
         },
+        "/authentication/jwks.json": {
+            "get": {
+                "description": "The public keys access tokens are signed with, as a JSON Web Key Set (RFC 7517) so other services can verify tokens themselves. Each token's kid header names its key. Empty unless RS256 signing is configured: shared HS256 secrets are never published.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "authentication"
+                ],
+                "summary": "Token verification keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
         "/authentication/refresh": {
             "post": {
                 "security": [
@@ -209,6 +266,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "202": {
+                        "description": "The account asks for a second factor; answer it at /authentication/token/verify",
+                        "schema": {
+                            "$ref": "#/definitions/main.TwoFactorChallenge"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
@@ -224,9 +287,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/authentication/user": {
+        "/authentication/token/verify": {
             "post": {
-                "description": "Registers a user",
+                "description": "Exchanges the challenge token from /authentication/token and an authenticator or recovery code for a JWT. Five wrong codes end the challenge.",
                 "consumes": [
                     "application/json"
                 ],
@@ -236,64 +299,29 @@ const docTemplate = `{
                 "tags": [
                     "authentication"
                 ],
-                "summary": "Registers a user",
+                "summary": "Completes a sign-in with its second factor",
                 "parameters": [
                     {
-                        "description": "User credentials",
+                        "description": "Challenge token and code",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.RegisterUserPayload"
+                            "$ref": "#/definitions/main.VerifyLoginPayload"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "User registered",
+                        "description": "Token",
                         "schema": {
-                            "$ref": "#/definitions/main.UserWithToken"
+                            "type": "string"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            }
-        },
-        "/restaurants": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Fetches all restaurants belonging to the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "restaurant"
-                ],
-                "summary": "Lists user's restaurants",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.Restaurant"
-                            }
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -303,14 +331,11 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
+            }
+        },
+        "/authentication/user": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Creates a Restaurant",
+                "description": "Registers a user",
                 "consumes": [
                     "application/json"
                 ],
@@ -318,35 +343,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "restaurant"
+                    "authentication"
                 ],
-                "summary": "Creates a Restaurant",
+                "summary": "Registers a user",
                 "parameters": [
                     {
-                        "description": "Restaurant payload",
+                        "description": "User credentials",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateRestaurantPayload"
+                            "$ref": "#/definitions/main.RegisterUserPayload"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created",
+                        "description": "User registered",
                         "schema": {
-                            "$ref": "#/definitions/store.Restaurant"
+                            "$ref": "#/definitions/main.UserWithToken"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -354,39 +375,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{id}": {
+        "/avatars/{employeeID}/{avatarID}": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Fetches a Restaurant by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+                "description": "Redirects to a short-lived URL for the photo. This is the avatar_url on employee responses; it needs no token so it works in img tags, and stops working once the photo is replaced or removed.",
                 "tags": [
-                    "restaurant"
+                    "employee"
                 ],
-                "summary": "Fetches a Restaurant",
+                "summary": "Serves an employee's profile photo",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "id",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Avatar ID",
+                        "name": "avatarID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "sm (64px), md (256px, default) or lg (512px)",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/store.Restaurant"
-                        }
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
                     },
                     "404": {
                         "description": "Not Found",
@@ -397,40 +421,34 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/billing/portal": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Delete a Restaurant by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Generates a Stripe customer-portal session URL for the authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "restaurant"
-                ],
-                "summary": "Deletes a Restaurant",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "billing"
                 ],
+                "summary": "Creates a billing portal session",
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.BillingPortalResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -440,57 +458,26 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Updates a Restaurant by ID",
+            }
+        },
+        "/billing/webhook": {
+            "post": {
+                "description": "Verifies the Stripe signature and syncs subscription state",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "restaurant"
-                ],
-                "summary": "Updates a Restaurant",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Restaurant payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.UpdateRestaurantPayload"
-                        }
-                    }
+                    "billing"
                 ],
+                "summary": "Receives Stripe webhook events",
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/store.Restaurant"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -502,51 +489,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurantID}/schedules/{scheduleID}/auto-populate": {
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Creates scheduled shifts for all shift templates that don't have shifts yet",
-                "consumes": [
-                    "application/json"
-                ],
+        "/caldav/{token}": {
+            "get": {
+                "description": "Serves every event of the feed as one iCalendar file, for apps that subscribe to a webcal URL: the published shifts of the restaurant, or of the feed's employee, from 30 days ago on. It needs no account: the token in the URL is the access, and it stops working once the feed is revoked or the restaurant is archived. CalDAV clients sync the same collection with PROPFIND and REPORT. Answers carry an ETag; a 304 means nothing changed.",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "calendar-feeds"
                 ],
-                "summary": "Auto-populate schedule with template-based shifts",
+                "summary": "Shows a calendar feed as iCalendar",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
+                        "type": "string",
+                        "description": "Calendar feed token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "iCalendar",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -557,58 +530,70 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts": {
-            "get": {
-                "security": [
+            },
+            "options": {
+                "description": "Answers a CalDAV client's OPTIONS probe with the DAV classes (1, calendar-access) and methods the feed supports: GET, PROPFIND and REPORT (calendar-query, calendar-multiget), read-only",
+                "tags": [
+                    "calendar-feeds"
+                ],
+                "summary": "CalDAV capabilities of a calendar feed",
+                "parameters": [
                     {
-                        "ApiKeyAuth": []
+                        "type": "string",
+                        "description": "Calendar feed token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "Gets all scheduled shifts for a specific schedule",
-                "consumes": [
-                    "application/json"
-                ],
+                "responses": {
+                    "200": {
+                        "description": "CalDAV capabilities"
+                    }
+                }
+            }
+        },
+        "/caldav/{token}/{event}": {
+            "get": {
+                "description": "Serves one shift of the feed, by the href PROPFIND lists it under, as an iCalendar file",
                 "produces": [
-                    "application/json"
+                    "text/calendar"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "calendar-feeds"
                 ],
-                "summary": "List all shifts for a schedule",
+                "summary": "Shows one event of a calendar feed",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
+                        "type": "string",
+                        "description": "Calendar feed token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
+                        "type": "string",
+                        "description": "Event resource, e.g. shift-42.ics",
+                        "name": "event",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "iCalendar",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.ScheduledShift"
-                            }
+                            "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {}
                     },
                     "500": {
@@ -616,62 +601,59 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Creates a new scheduled shift for a specific schedule",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/display/boards/{token}": {
+            "get": {
+                "description": "Shows the day's shifts from published schedules, with names, roles and times, for a screen in the back of house. It needs no account: the token in the URL is the access, and it stops working once the board is revoked or the restaurant is archived. The day is today in tz, an IANA timezone such as America/Chicago, or the restaurant's timezone without one. Answers carry an ETag; poll every refresh_seconds with If-None-Match and a 304 means nothing changed. format=html serves a page that reloads itself.",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/html"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "display-boards"
                 ],
-                "summary": "Create a new shift",
+                "summary": "Shows a display board",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
+                        "type": "string",
+                        "description": "Display board token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "IANA timezone the day is taken in; defaults to the restaurant's",
+                        "name": "tz",
+                        "in": "query"
                     },
                     {
-                        "description": "Shift information",
-                        "name": "shift",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.createScheduledShiftRequest"
-                        }
+                        "type": "string",
+                        "description": "html for a page instead of JSON",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ScheduledShift"
+                            "$ref": "#/definitions/main.BoardView"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {}
                     },
                     "500": {
@@ -681,43 +663,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}": {
+        "/document-acknowledgments/{token}": {
             "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Gets a specific scheduled shift by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Shows the document behind an emailed acknowledgment link, with a short-lived download URL and, once signed, the signature. It needs no account: the token in the URL is the access. An unsigned link stops working when it expires or the restaurant is archived.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "document"
                 ],
-                "summary": "Get a shift",
+                "summary": "Shows a document to acknowledge",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Shift ID",
-                        "name": "shiftID",
+                        "type": "string",
+                        "description": "Acknowledgment token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     }
@@ -726,17 +686,9 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ScheduledShift"
+                            "$ref": "#/definitions/main.DocumentToAcknowledgeView"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -747,13 +699,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Deletes a scheduled shift by ID",
+            "post": {
+                "description": "Records that the employee holding the link has read the document, under the name they typed and with the device they used. A document can only be signed once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -761,124 +708,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "document"
                 ],
-                "summary": "Delete a shift",
+                "summary": "Signs a document acknowledgment",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
+                        "type": "string",
+                        "description": "Acknowledgment token",
+                        "name": "token",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Shift ID",
-                        "name": "shiftID",
-                        "in": "path",
-                        "required": true
+                        "description": "Signature",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.AcknowledgeDocumentPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/store.DocumentAcknowledgment"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Updates an existing scheduled shift by ID",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/email-verifications/{token}": {
+            "post": {
+                "description": "Confirms the address behind an emailed verification link and uses the link up. It needs no account: the token in the URL is the access. The link stops working when it expires, the employee's email changes or the restaurant is archived.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-shifts"
+                    "employee"
                 ],
-                "summary": "Update a shift",
+                "summary": "Verifies an employee's email",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Shift ID",
-                        "name": "shiftID",
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated shift information",
-                        "name": "shift",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.updateScheduledShiftRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ScheduledShift"
+                            "$ref": "#/definitions/store.VerifiedEmail"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -890,62 +792,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/assign": {
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "Removes an employee assignment from a scheduled shift",
+        "/email/events": {
+            "post": {
+                "description": "SendGrid's signed event webhook. Delivered, deferred, bounce, dropped and spam report events update the schedule emails they're about; a hard bounce flags the employee's address so schedule emails skip it. Other events and untracked mail are ignored. 404 unless SENDGRID_WEBHOOK_PUBLIC_KEY is set.",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "scheduled-shifts"
+                    "email"
                 ],
-                "summary": "Unassign employee from shift",
+                "summary": "Receives SendGrid delivery events",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
-                        "in": "path",
+                        "type": "string",
+                        "description": "ECDSA signature",
+                        "name": "X-Twilio-Email-Event-Webhook-Signature",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Shift ID",
-                        "name": "shiftID",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Signed timestamp",
+                        "name": "X-Twilio-Email-Event-Webhook-Timestamp",
+                        "in": "header",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/store.ScheduledShift"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -955,75 +835,37 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/employee/me/bid-rounds": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Assigns an employee to a scheduled shift",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "The shifts of open bid rounds, at restaurants with an employee record with the signed-in user's email, that the employee can bid on: still unassigned and of a role they hold. Each shift has the rank they gave it, if any, and the round's closing time.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "scheduled-shifts"
-                ],
-                "summary": "Assign employee to shift",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurantID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "scheduleID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Shift ID",
-                        "name": "shiftID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Employee assignment information",
-                        "name": "employee",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.assignEmployeeRequest"
-                        }
-                    }
+                    "employee portal"
                 ],
+                "summary": "Lists shifts open for bids",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ScheduledShift"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.BiddableShift"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1031,14 +873,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/employees": {
-            "get": {
+        "/employee/me/bid-rounds/{roundID}/preferences": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all employees for a restaurant",
+                "description": "Replaces the signed-in employee's bids on the round with the shifts given, first choice first. Every shift must be in the round and of a role they hold. An empty list withdraws their bids. Bids can change until the round closes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1046,16 +888,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "employee portal"
                 ],
-                "summary": "Lists restaurant's employees",
+                "summary": "Ranks the shifts wanted in a bid round",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "description": "Bid round ID",
+                        "name": "roundID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Ranked shifts",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BidPreferencesPayload"
+                        }
                     }
                 ],
                 "responses": {
@@ -1064,10 +915,14 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Employee"
+                                "$ref": "#/definitions/store.BidPreference"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -1076,19 +931,25 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Bidding has closed",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/employee/me/shifts": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates an employee for a restaurant",
+                "description": "Returns the shifts on published schedules assigned to any employee record with the signed-in user's email, from today (or from) on, with when each was acknowledged",
                 "consumes": [
                     "application/json"
                 ],
@@ -1096,32 +957,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "employee portal"
                 ],
-                "summary": "Creates an employee",
+                "summary": "Lists the signed-in employee's upcoming shifts",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Employee payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateEmployeePayload"
-                        }
+                        "type": "string",
+                        "description": "First shift date (YYYY-MM-DD, default today)",
+                        "name": "from",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Employee"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.EmployeeShift"
+                            }
                         }
                     },
                     "400": {
@@ -1132,10 +986,6 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1143,14 +993,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/employees/{id}": {
-            "get": {
+        "/employee/me/shifts/{id}/acknowledge": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches an employee by ID",
+                "description": "Confirms the signed-in employee has seen a shift assigned to them on a published schedule. Acknowledging again keeps the first time; moving or reassigning the shift asks for a new acknowledgment.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1158,20 +1008,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "employee portal"
                 ],
-                "summary": "Fetches an employee",
+                "summary": "Acknowledges an assigned shift",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Employee ID",
+                        "description": "Shift ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1181,9 +1024,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Employee"
+                            "$ref": "#/definitions/store.ShiftAcknowledgment"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -1197,14 +1044,16 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/employee/me/shifts/{id}/feedback": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes an employee by ID",
+                "description": "Records the signed-in employee's rating (1-5) and optional comment on a shift assigned to them on a published schedule, once its end time has passed. Submitting again replaces the earlier feedback.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1212,32 +1061,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "employee portal"
                 ],
-                "summary": "Deletes an employee",
+                "summary": "Gives feedback on a worked shift",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "description": "Shift ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Employee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Feedback",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftFeedbackPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/store.ShiftFeedback"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -1246,19 +1101,25 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "The shift hasn't ended yet",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/kiosk/clock": {
+            "post": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "KioskAuth": []
                     }
                 ],
-                "description": "Updates an employee by ID",
+                "description": "Checks the employee's PIN and clocks them out if they're clocked in, in otherwise. After 5 wrong PINs in a row the employee is locked out of the kiosk for 15 minutes. Authenticated with \"Authorization: Kiosk \u003ctoken\u003e\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -1266,31 +1127,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "kiosk"
                 ],
-                "summary": "Updates an employee",
+                "summary": "Clocks an employee in or out",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Employee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Employee payload",
+                        "description": "Employee and PIN",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateEmployeePayload"
+                            "$ref": "#/definitions/main.ClockPayload"
                         }
                     }
                 ],
@@ -1298,7 +1145,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Employee"
+                            "$ref": "#/definitions/main.ClockResult"
                         }
                     },
                     "400": {
@@ -1309,10 +1156,18 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "403": {
+                        "description": "Wrong PIN",
+                        "schema": {}
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "423": {
+                        "description": "Locked out after too many wrong PINs",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1320,14 +1175,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/employees/{id}/roles": {
+        "/kiosk/employees": {
             "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "KioskAuth": []
                     }
                 ],
-                "description": "Fetches all roles assigned to a specific employee",
+                "description": "Lists the kiosk restaurant's employees who have a PIN, with when the clocked-in ones clocked in. Authenticated with \"Authorization: Kiosk \u003ctoken\u003e\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -1335,32 +1190,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
-                ],
-                "summary": "Get employee roles",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Employee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "kiosk"
                 ],
+                "summary": "Lists the employees who can clock in on this kiosk",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Role"
+                                "$ref": "#/definitions/store.KioskEmployee"
                             }
                         }
                     },
@@ -1368,23 +1207,21 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/notifications": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Assigns multiple roles to an employee",
+                "description": "Returns notifications newest first with the unread count for the bell icon. Page with before=\u003cid of the last notification\u003e.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1392,39 +1229,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "notifications"
                 ],
-                "summary": "Assigns roles to an employee",
+                "summary": "Lists the current user's notifications",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Employee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "Role IDs payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.AddEmployeeRolesPayload"
-                        }
+                        "type": "integer",
+                        "description": "Return notifications older than this ID",
+                        "name": "before",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.NotificationsResponse"
                         }
                     },
                     "400": {
@@ -1435,10 +1267,6 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1446,53 +1274,97 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/employees/{id}/roles/{role_id}": {
-            "delete": {
+        "/notifications/read-all": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Removes a specific role from an employee",
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Marks all of the current user's notifications as read",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MarkAllReadResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "employee"
+                    "notifications"
                 ],
-                "summary": "Removes a role from an employee",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
+                "summary": "Counts the current user's unread notifications",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.UnreadCountResponse"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "Employee ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/notifications/{notificationID}/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Marks a notification as read",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "role_id",
+                        "description": "Notification ID",
+                        "name": "notificationID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -1509,14 +1381,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/events": {
+        "/pos/sales": {
+            "post": {
+                "description": "Webhook for a restaurant's point of sale, authenticated by the restaurant's POS token as \"Authorization: POS \u003ctoken\u003e\". Takes the same JSON or CSV as POST /restaurants/{restaurantID}/sales.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sales"
+                ],
+                "summary": "Receives sales from a point of sale",
+                "parameters": [
+                    {
+                        "description": "Sales records",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportSalesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportSalesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rate-limit": {
+            "get": {
+                "description": "Reports how many requests the calling client may make in the current window, how many are left (this request included) and the seconds until the budget resets, the same figures as the RateLimit-* headers on every response. enabled is false when rate limiting is off.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ops"
+                ],
+                "summary": "Shows the caller's rate limit budget",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RateLimitStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/restaurants": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all events for a restaurant, optionally filtered by date range",
+                "description": "Fetches all restaurants belonging to the authenticated user; archived restaurants are only listed, on their own, with archived=true",
                 "consumes": [
                     "application/json"
                 ],
@@ -1524,27 +1463,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Lists restaurant's events",
+                "summary": "Lists user's restaurants",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date filter (YYYY-MM-DD)",
-                        "name": "start_date",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date filter (YYYY-MM-DD)",
-                        "name": "end_date",
+                        "type": "boolean",
+                        "description": "List archived restaurants instead",
+                        "name": "archived",
                         "in": "query"
                     }
                 ],
@@ -1554,7 +1480,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Event"
+                                "$ref": "#/definitions/store.Restaurant"
                             }
                         }
                     },
@@ -1562,10 +1488,6 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1578,7 +1500,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates an event for a restaurant",
+                "description": "Creates a Restaurant",
                 "consumes": [
                     "application/json"
                 ],
@@ -1586,24 +1508,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Creates an event",
+                "summary": "Creates a Restaurant",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Event payload",
+                        "description": "Restaurant payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateEventPayload"
+                            "$ref": "#/definitions/main.CreateRestaurantPayload"
                         }
                     }
                 ],
@@ -1611,7 +1526,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.Event"
+                            "$ref": "#/definitions/store.Restaurant"
                         }
                     },
                     "400": {
@@ -1622,10 +1537,6 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1633,14 +1544,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/events/{eventID}": {
+        "/restaurants/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches an event by ID",
+                "description": "Fetches a Restaurant by ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1648,21 +1559,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Fetches an event",
+                "summary": "Fetches a Restaurant",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -1671,13 +1575,9 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Event"
+                            "$ref": "#/definitions/store.Restaurant"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -1694,7 +1594,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes an event by ID",
+                "description": "Permanently deletes a Restaurant and all of its data. The restaurant must be archived and exported since (GET /restaurants/{id}/export), otherwise 409.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1702,21 +1602,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Deletes an event",
+                "summary": "Deletes a Restaurant",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -1728,14 +1621,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -1748,7 +1641,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates an event by ID",
+                "description": "Updates a Restaurant by ID. schedule_lock_hours (0-168, 0 = off) stops edits to published shifts that start within that many hours unless the request passes override_lock=true. weekly_labor_budget_cents is checked when schedules are published; 0 removes it. schedule_retention_months (0-120, 0 = off) archives schedules that ended more than that many months ago. assignment_policy picks who auto-assign offers a shift to: seniority_first (highest employee seniority), rotate_fairly (fewest scheduled hours) or manual_only (auto-assign off). staff_milestone_digest emails the owner each week the staff birthdays and work anniversaries of the coming seven days. latitude and longitude, given together, add the weather forecast to schedule coverage. notification_mode sets how staff get shift change and announcement emails: immediate, or held for one digest a day (daily) or on the days they work (shift_day) sent from digest_hour (0-23 UTC); employees can override it and critical notices are always sent straight away. timezone, an IANA name such as America/Chicago (default UTC), is the one dates and times in staff emails and the display board are shown in, and the one the schedule lock reads shift start times in. week_start, 0 (Sunday) to 6 (Saturday, default 1), is the day the restaurant's weeks begin: the default week of the hours calendar and the weeks the minors' weekly hours limit counts follow it. rotation_anchor (YYYY-MM-DD, empty clears) starts week a of the alternating two-week rotation that shift templates with a week_parity follow. require_email_verification holds schedule emails to employees who haven't confirmed their address through a verification link. holiday_region, a country (US) or subdivision (US-CA) code, adds that region's public holidays for this year and next to the hours exceptions once, as closures when holidays_closed is set and otherwise as days on regular hours named for the holiday; coverage reports and schedule emails show their names. An empty holiday_region turns it off. It needs the holidays integration, and a region it knows nothing of is rejected.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1756,31 +1649,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Updates an event",
+                "summary": "Updates a Restaurant",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Event payload with optional fields",
+                        "description": "Restaurant payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateEventPayload"
+                            "$ref": "#/definitions/main.UpdateRestaurantPayload"
                         }
                     }
                 ],
@@ -1788,7 +1674,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Event"
+                            "$ref": "#/definitions/store.Restaurant"
                         }
                     },
                     "400": {
@@ -1810,14 +1696,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/events/{eventID}/employees": {
-            "get": {
+        "/restaurants/{id}/clone": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieves all employees assigned to an event",
+                "description": "Creates a new restaurant with a copy of this one's setup: settings, operating hours, email template, roles, certifications and shift templates, and with include_employees=true its employees with their roles and certifications. Schedules, shifts, events, holidays and members are not copied. Everything is copied in one transaction; id_map maps each source ID to the new one.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1825,39 +1711,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Get employees for an event",
+                "summary": "Clones a Restaurant",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
-                        "in": "path",
-                        "required": true
+                        "description": "The new restaurant",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CloneRestaurantPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.Employee"
-                            }
+                            "$ref": "#/definitions/main.RestaurantCloneResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {}
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -1867,14 +1760,16 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/restaurants/{id}/features": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Assigns multiple employees to an event (additive)",
+                "description": "Resolves feature flags for a restaurant from its plan tier and configured overrides",
                 "consumes": [
                     "application/json"
                 ],
@@ -1882,45 +1777,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Assigns employees to an event",
+                "summary": "Lists restaurant's feature flags",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Employee IDs payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.AssignEventEmployeesPayload"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.RestaurantFeaturesResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -1936,14 +1811,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/events/{eventID}/employees/{employeeID}": {
-            "delete": {
+        "/restaurants/{id}/repair-denormalized": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Removes a specific employee from an event",
+                "description": "Rewrites role name/color and employee name on the restaurant's scheduled shifts from their source rows and reports how many had drifted",
                 "consumes": [
                     "application/json"
                 ],
@@ -1951,37 +1826,69 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "event"
+                    "restaurant"
                 ],
-                "summary": "Removes an employee from an event",
+                "summary": "Re-syncs denormalized shift fields",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.DenormalizedRepair"
+                        }
                     },
-                    {
-                        "type": "integer",
-                        "description": "Event ID",
-                        "name": "eventID",
-                        "in": "path",
-                        "required": true
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Hides the restaurant from the restaurant list and makes it read-only, keeping all of its data. Archiving an archived restaurant is a no-op.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurant"
+                ],
+                "summary": "Archives a Restaurant",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Employee ID",
-                        "name": "employeeID",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/store.Restaurant"
                         }
                     },
                     "401": {
@@ -1999,29 +1906,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/roles": {
+        "/restaurants/{restaurantID}/calendar-feeds": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all roles for a restaurant",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the restaurant's calendar feeds, revoked ones included, with when each was last synced",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "calendar-feeds"
                 ],
-                "summary": "Lists restaurant's roles",
+                "summary": "Lists a restaurant's calendar feeds",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
@@ -2032,7 +1936,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Role"
+                                "$ref": "#/definitions/store.CalendarFeed"
                             }
                         }
                     },
@@ -2056,7 +1960,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a role for a restaurant",
+                "description": "Creates a read-only CalDAV calendar of the restaurant's published shifts, or of one employee's with employee_id, that calendar apps (Apple Calendar, Thunderbird, DAVx5) add by url and keep in sync as shifts change. Apps that only subscribe to iCalendar feeds use webcal_url. The token and URLs are not shown again.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2064,24 +1968,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "calendar-feeds"
                 ],
-                "summary": "Creates a role",
+                "summary": "Creates a calendar feed",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Role payload",
+                        "description": "Feed name and employee",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateRolePayload"
+                            "$ref": "#/definitions/main.CreateCalendarFeedPayload"
                         }
                     }
                 ],
@@ -2089,7 +1993,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.Role"
+                            "$ref": "#/definitions/main.CalendarFeedWithToken"
                         }
                     },
                     "400": {
@@ -2111,47 +2015,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/roles/{id}": {
-            "get": {
+        "/restaurants/{restaurantID}/calendar-feeds/{feedID}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a role by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stops the feed's token from working; subscribed calendars stop syncing",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "calendar-feeds"
                 ],
-                "summary": "Fetches a role",
+                "summary": "Revokes a calendar feed",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
+                        "description": "Calendar feed ID",
+                        "name": "feedID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/store.Role"
+                            "type": "string"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2165,14 +2070,16 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/restaurants/{restaurantID}/certifications": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a role by ID",
+                "description": "Fetches all certifications tracked by a restaurant",
                 "consumes": [
                     "application/json"
                 ],
@@ -2180,30 +2087,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "certification"
                 ],
-                "summary": "Deletes a role",
+                "summary": "Lists restaurant's certifications",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Certification"
+                            }
                         }
                     },
                     "401": {
@@ -2220,13 +2123,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a role by ID",
+                "description": "Creates a certification (e.g. food handler card) for a restaurant",
                 "consumes": [
                     "application/json"
                 ],
@@ -2234,39 +2137,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "certification"
                 ],
-                "summary": "Updates a role",
+                "summary": "Creates a certification",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Role payload",
+                        "description": "Certification payload",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateRolePayload"
+                            "$ref": "#/definitions/main.CreateCertificationPayload"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.Role"
+                            "$ref": "#/definitions/store.Certification"
                         }
                     },
                     "400": {
@@ -2281,6 +2177,10 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -2288,14 +2188,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/roles/{id}/employees": {
+        "/restaurants/{restaurantID}/certifications/expiring": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all employees assigned to a specific role",
+                "description": "Reports employee certifications that have expired or expire within the given number of days",
                 "consumes": [
                     "application/json"
                 ],
@@ -2303,23 +2203,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "role"
+                    "certification"
                 ],
-                "summary": "Get employees for a role",
+                "summary": "Lists expiring certifications",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Role ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Look-ahead window in days (default 30)",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2328,10 +2227,14 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.Employee"
+                                "$ref": "#/definitions/store.EmployeeCertification"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2347,14 +2250,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/schedules": {
-            "get": {
+        "/restaurants/{restaurantID}/certifications/expiring/send-email": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all schedules for a restaurant",
+                "description": "Sends the restaurant owner a list of certifications that have expired or expire within the given number of days",
                 "consumes": [
                     "application/json"
                 ],
@@ -2362,28 +2265,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "certification"
                 ],
-                "summary": "Lists restaurant's schedules",
+                "summary": "Emails the expiring certifications report",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Look-ahead window in days (default 30)",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.Schedule"
-                            }
+                            "$ref": "#/definitions/main.CertificationExpiryEmailResponse"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2392,19 +2302,25 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/restaurants/{restaurantID}/certifications/{certificationID}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a schedule for a restaurant",
+                "description": "Deletes a certification along with every employee record and role requirement for it",
                 "consumes": [
                     "application/json"
                 ],
@@ -2412,37 +2328,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "certification"
                 ],
-                "summary": "Creates a schedule",
+                "summary": "Deletes a certification",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Schedule payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateSchedulePayload"
-                        }
+                        "type": "integer",
+                        "description": "Certification ID",
+                        "name": "certificationID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/store.Schedule"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
+                    "204": {
+                        "description": "No Content"
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -2457,16 +2364,14 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/restaurants/{restaurant_id}/schedules/{id}": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a schedule by ID",
+                "description": "Renames a certification",
                 "consumes": [
                     "application/json"
                 ],
@@ -2474,32 +2379,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "certification"
                 ],
-                "summary": "Fetches a schedule",
+                "summary": "Updates a certification",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "id",
+                        "description": "Certification ID",
+                        "name": "certificationID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Certification payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateCertificationPayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Schedule"
+                            "$ref": "#/definitions/store.Certification"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2508,50 +2426,46 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/restaurants/{restaurantID}/compliance-rules": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a schedule by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns the working-hour rules checked when employees are assigned and schedules are published. Restaurants that haven't set any get the custom template, which checks nothing.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "compliance"
                 ],
-                "summary": "Deletes a schedule",
+                "summary": "Gets a restaurant's compliance rules",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/store.ComplianceRules"
                         }
                     },
                     "401": {
@@ -2568,13 +2482,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a schedule by ID",
+                "description": "Starts from a jurisdiction template (custom checks nothing; us_federal, california and eu follow the common limits there) and applies the fields given over it. Rules are minimum rest hours between working days, maximum consecutive working days, and daily hours, weekly hours (over the restaurant's weeks, from its week_start) and latest end time for employees younger than minor_age, going by their birthday. A rule set to block refuses assignments and publishing that break it; warn lets them through with a warning.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2582,31 +2496,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "compliance"
                 ],
-                "summary": "Updates a schedule",
+                "summary": "Sets a restaurant's compliance rules",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Schedule payload",
+                        "description": "Compliance rules",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateSchedulePayload"
+                            "$ref": "#/definitions/main.ComplianceRulesPayload"
                         }
                     }
                 ],
@@ -2614,7 +2521,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.Schedule"
+                            "$ref": "#/definitions/store.ComplianceRules"
                         }
                     },
                     "400": {
@@ -2636,14 +2543,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/schedules/{id}/publish": {
-            "post": {
+        "/restaurants/{restaurantID}/display-boards": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Publishes a schedule to make it available to employees",
+                "description": "Lists the restaurant's display boards, revoked ones included, with when each last polled",
                 "consumes": [
                     "application/json"
                 ],
@@ -2651,32 +2558,88 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "display-boards"
                 ],
-                "summary": "Publishes a schedule",
+                "summary": "Lists a restaurant's display boards",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.DisplayBoard"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a read-only screen, such as a TV in the back of house, that shows the day's published shifts. The response carries the board's token and URLs, which are not shown again; the screen opens html_url, or polls url for JSON.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "display-boards"
+                ],
+                "summary": "Registers a display board",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "id",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Board name",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateDisplayBoardPayload"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.DisplayBoardWithToken"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2692,14 +2655,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/schedules/{id}/send-email": {
-            "post": {
+        "/restaurants/{restaurantID}/display-boards/{boardID}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Sends the schedule via email to all employees in the restaurant",
+                "description": "Stops the board's token from working; the screen shows nothing more",
                 "consumes": [
                     "application/json"
                 ],
@@ -2707,39 +2670,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "schedule"
+                    "display-boards"
                 ],
-                "summary": "Sends schedule emails to all employees",
+                "summary": "Revokes a display board",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Schedule ID",
-                        "name": "id",
+                        "description": "Display board ID",
+                        "name": "boardID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Email options",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.SendScheduleEmailPayload"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "No Content",
                         "schema": {
-                            "$ref": "#/definitions/main.SendScheduleEmailResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -2761,14 +2715,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/shift-templates": {
+        "/restaurants/{restaurantID}/documents": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches all shift templates for a restaurant",
+                "description": "Fetches restaurant-wide documents (handbooks, checklists) with short-lived download URLs; per-employee documents are listed under the employee",
                 "consumes": [
                     "application/json"
                 ],
@@ -2776,14 +2730,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "document"
                 ],
-                "summary": "Lists restaurant's shift templates",
+                "summary": "Lists restaurant's documents",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
@@ -2794,7 +2748,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/store.ShiftTemplate"
+                                "$ref": "#/definitions/store.Document"
                             }
                         }
                     },
@@ -2818,40 +2772,44 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Creates a shift template for a restaurant",
+                "description": "Uploads a restaurant-wide document as multipart/form-data. Accepts PDF, PNG, JPEG, GIF, WebP and plain text up to the configured size.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "document"
                 ],
-                "summary": "Creates a shift template",
+                "summary": "Uploads a restaurant document",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Shift template payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.CreateShiftTemplatePayload"
-                        }
+                        "type": "file",
+                        "description": "Document",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Display name (defaults to the file name)",
+                        "name": "name",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/store.ShiftTemplate"
+                            "$ref": "#/definitions/store.Document"
                         }
                     },
                     "400": {
@@ -2866,6 +2824,14 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {}
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -2873,14 +2839,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/shift-templates/{id}": {
+        "/restaurants/{restaurantID}/documents/{documentID}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Fetches a shift template by ID",
+                "description": "Fetches a document's metadata with a short-lived download URL",
                 "consumes": [
                     "application/json"
                 ],
@@ -2888,21 +2854,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "document"
                 ],
-                "summary": "Fetches a shift template",
+                "summary": "Gets a document",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Shift Template ID",
-                        "name": "id",
+                        "description": "Document ID",
+                        "name": "documentID",
                         "in": "path",
                         "required": true
                     }
@@ -2911,9 +2877,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ShiftTemplate"
+                            "$ref": "#/definitions/store.Document"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -2934,7 +2904,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Deletes a shift template by ID",
+                "description": "Deletes a document and its stored file",
                 "consumes": [
                     "application/json"
                 ],
@@ -2942,31 +2912,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "document"
                 ],
-                "summary": "Deletes a shift template",
+                "summary": "Deletes a document",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Shift Template ID",
-                        "name": "id",
+                        "description": "Document ID",
+                        "name": "documentID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "No Content",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
                     },
                     "401": {
                         "description": "Unauthorized",
@@ -2981,14 +2952,71 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/restaurants/{restaurantID}/documents/{documentID}/acknowledgments": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Updates a shift template by ID",
+                "description": "Lists every acknowledgment requested for the document with when, and under what typed name, each employee signed; outstanding_employee_ids are those who haven't yet",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "document"
+                ],
+                "summary": "Reports who has acknowledged a document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Document ID",
+                        "name": "documentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DocumentAcknowledgmentReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Emails each employee (all of them unless employee_ids is given) a personal link to read the restaurant-wide document and sign that they have, and posts them a notification. The link works for 30 days; asking again sends a new one. Employees who already signed are skipped.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2996,31 +3024,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "document"
                 ],
-                "summary": "Updates a shift template",
+                "summary": "Asks employees to acknowledge a document",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Shift Template ID",
-                        "name": "id",
+                        "description": "Document ID",
+                        "name": "documentID",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Shift template payload with optional fields",
+                        "description": "Employees to ask",
                         "name": "payload",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateShiftTemplatePayload"
+                            "$ref": "#/definitions/main.RequestDocumentAcknowledgmentsPayload"
                         }
                     }
                 ],
@@ -3028,7 +3055,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ShiftTemplate"
+                            "$ref": "#/definitions/main.RequestDocumentAcknowledgmentsResponse"
                         }
                     },
                     "400": {
@@ -3043,6 +3070,10 @@ const docTemplate = `{
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -3050,14 +3081,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/restaurants/{restaurant_id}/shift-templates/{id}/roles": {
+        "/restaurants/{restaurantID}/documents/{documentID}/download": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Retrieves all roles associated with a shift template",
+                "description": "Redirects to a short-lived presigned URL for the document's file",
+                "tags": [
+                    "document"
+                ],
+                "summary": "Downloads a document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Document ID",
+                        "name": "documentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/email-templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches the subject, header message, logo and accent color used for the restaurant's schedule emails; empty fields use the defaults",
                 "consumes": [
                     "application/json"
                 ],
@@ -3065,35 +3147,85 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "shift-template"
+                    "email-template"
                 ],
-                "summary": "Get roles for a shift template",
+                "summary": "Gets restaurant's email customization",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Restaurant ID",
-                        "name": "restaurant_id",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.EmailTemplate"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves the subject, header message, logo and accent color used for the restaurant's schedule emails",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-template"
+                ],
+                "summary": "Customizes restaurant's emails",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Shift Template ID",
-                        "name": "id",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Email customization",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.EmailTemplatePayload"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/store.Role"
-                            }
+                            "$ref": "#/definitions/store.EmailTemplate"
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -3107,38 +3239,40 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/users/activate/{token}": {
-            "put": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Activates/Register a user by invitation token",
+                "description": "Removes the restaurant's email customization so the default schedule email is used",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "email-template"
                 ],
-                "summary": "Activates/Register a user",
+                "summary": "Resets restaurant's email customization",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Invitation token",
-                        "name": "token",
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "User activated",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
                     },
                     "404": {
                         "description": "Not Found",
@@ -3150,30 +3284,14536 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.AddEmployeeRolesPayload": {
-            "type": "object",
-            "required": [
-                "role_ids"
-            ],
-            "properties": {
-                "role_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
+        },
+        "/restaurants/{restaurantID}/email-templates/preview": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renders a sample schedule email with the given customization, or the saved one when the body is empty, without sending anything",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "email-template"
+                ],
+                "summary": "Previews restaurant's schedule email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Unsaved email customization",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.EmailTemplatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EmailTemplatePreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
                     }
                 }
             }
         },
-        "main.AssignEventEmployeesPayload": {
-            "type": "object",
+        "/restaurants/{restaurantID}/employees/{employeeID}/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Uploads a PNG, JPEG or GIF as multipart/form-data, replacing any existing photo. The image is center-cropped to a square and stored in sm (64px), md (256px) and lg (512px) variants.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Uploads an employee's profile photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Photo",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Employee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {}
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the employee's photo and its stored variants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Removes an employee's profile photo",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/certifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches the certifications an employee holds with their expiry dates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certification"
+                ],
+                "summary": "Lists employee's certifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.EmployeeCertification"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/certifications/{certificationID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a certification to an employee, or replaces its issue/expiry dates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certification"
+                ],
+                "summary": "Records an employee's certification",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Certification ID",
+                        "name": "certificationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Certification dates (YYYY-MM-DD)",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SetEmployeeCertificationPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.EmployeeCertification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a certification record from an employee",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certification"
+                ],
+                "summary": "Removes an employee's certification",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Certification ID",
+                        "name": "certificationID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/documents": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches documents attached to an employee (signed forms, ...) with short-lived download URLs",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "document"
+                ],
+                "summary": "Lists employee's documents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Document"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Attaches a document to an employee as multipart/form-data. Accepts PDF, PNG, JPEG, GIF, WebP and plain text up to the configured size.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "document"
+                ],
+                "summary": "Uploads an employee document",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Display name (defaults to the file name)",
+                        "name": "name",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Document"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {}
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/email-verification": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Emails the employee a personal link that confirms their current address is theirs, replacing any earlier link. The link works for 7 days and only for the address it was sent to. Until the address is verified the employee is flagged in send-email responses, and a restaurant with require_email_verification holds their schedule emails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Sends an employee a link to verify their email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EmailVerificationRequest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/erase": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Anonymizes an employee for a privacy request: their name becomes \"Former employee #ID\" everywhere it was copied (shifts, published schedule snapshots, shift history), their email, language and avatar are cleared, and their documents, certifications and notifications are deleted. The employee's shifts and roles are kept so scheduling history and hour totals still add up. This cannot be undone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employees"
+                ],
+                "summary": "Erases an employee's personal data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.EmployeeErasure"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/leave": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The employee's paid leave balance and every accrual, leave paid and adjustment behind it, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Gets an employee's paid leave",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EmployeeLeave"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deducts the hours of paid leave taken on a day from the employee's balance, refusing with 409 if they haven't that many, or adjusts the balance by the hours given. The entry goes in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Records paid leave or an adjustment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Leave entry",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LeaveEntryPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.LeaveEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/manager-notes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Private notes the restaurant owner keeps on the employee, newest first, each with its author and time. They are only served here: employee-facing endpoints, shift leads and exports never include them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Lists the manager notes on an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.EmployeeNote"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a timestamped private note, signed by the signed-in owner. Notes can't be edited or deleted; add another to correct one. The addition goes in the audit log, without the note's text.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Adds a manager note to an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateEmployeeNotePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.EmployeeNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the notifications sent to an employee (schedule published, shift changes) newest first with their unread count",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Lists an employee's notifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return notifications older than this ID",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotificationsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/employees/{employeeID}/pin": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generates a new random 6-digit PIN for the employee, replacing any old one and lifting a lockout. The PIN is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Sets a new kiosk PIN for an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EmployeePIN"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the employee's PIN, so they no longer appear on the kiosk or can clock in there",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Removes an employee's kiosk PIN",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Downloads everything stored for the restaurant: a ZIP with one JSON file per kind of record and the uploaded documents, or with format=json a single JSON document without the document files. A permanent delete requires an export taken after the restaurant was archived.",
+                "produces": [
+                    "application/zip",
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurant"
+                ],
+                "summary": "Exports all of a Restaurant's data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "zip (default) or json",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/kiosks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the restaurant's registered kiosks, revoked ones included, with when each was last used",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Lists a restaurant's kiosks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Kiosk"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a shared device employees clock in and out on. The response carries the kiosk's token, which is not shown again; the device sends it as \"Authorization: Kiosk \u003ctoken\u003e\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Registers a time clock kiosk",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Kiosk name",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateKioskPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.KioskWithToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/kiosks/{kioskID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops the kiosk's token from working; time entries it recorded are kept",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Revokes a kiosk",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Kiosk ID",
+                        "name": "kioskID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/leave-accruals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Accrues the weeks that ended over a day ago and haven't been, as the background job does every hour, and returns the new accruals. A disabled policy accrues nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Accrues paid leave now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.LeaveEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/leave-balances": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Each employee's paid leave balance now, with the hours accrued, paid and adjusted dated from through to (default the 4 weeks up to today, at most 366 days), for payroll. Accruals are dated by the Monday of their week.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Reports employees' paid leave",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.LeaveBalance"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/leave-policy": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how the restaurant's employees accrue paid leave. Restaurants that haven't set one get a disabled policy of an hour for every 30 worked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Gets a restaurant's paid leave policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.LeavePolicy"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Employees accrue accrual_hours of paid leave for every per_hours of their basis: hours clocked on the time clock (worked) or assigned in published schedules (scheduled). Each week, Monday to Sunday, from accrue_from accrues a day after it ends, up to max_balance_hours when that isn't 0. Changing the policy applies to weeks not yet accrued.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "leave"
+                ],
+                "summary": "Sets a restaurant's paid leave policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Leave policy",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LeavePolicyPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.LeavePolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the users other than the owner with access to the restaurant. Shift leads see and edit only the shifts of their roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Lists a restaurant's members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Member"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gives an existing user shift lead access to the restaurant for the given roles: they can list, create, edit and assign those roles' shifts and nothing else",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Adds a shift lead",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User email and roles",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.AddMemberPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/members/{userID}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the roles whose shifts the member manages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Changes a shift lead's roles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Member's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Roles",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateMemberPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the user's access to the restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Removes a member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Member's user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the restaurant's announcements newest first, with how many employees each reached and how many emails failed. Page with before=\u003cid of the last message\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Lists sent and scheduled messages",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Return messages older than this ID",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Message"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Emails an ad hoc message to every employee, or to those with one of role_ids plus those in employee_ids, and with the in_app channel also posts it as a notification. Subject and body are templates that may use {{.FirstName}}, {{.EmployeeName}} and {{.RestaurantName}}. Employees who get digests find the email in their next one unless the message is critical. With a future send_at (up to 90 days ahead) the message is scheduled instead, its recipients picked when it goes out, and can be canceled until then. Emailing counts against the restaurant's email quota when the message is created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Sends an announcement to staff",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SendMessagePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SendMessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/messages/{messageID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Fetches a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops a scheduled message from going out. A message that is being sent or has been can't be canceled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Cancels a scheduled message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/onboarding": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reports which setup steps are done (roles, employees, shift_templates, first_schedule), the next one to do, and whether sample data can still be added",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "Gets a restaurant's setup progress",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.OnboardingState"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/onboarding/sample-data": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets up a starter configuration to edit: four roles, six employees with example.com emails and hourly rates, lunch and dinner shift templates for every day plus a weekend bar shift, and a draft schedule for next week ready to auto-populate. Only allowed while the restaurant has no roles, employees, shift templates or schedules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "Adds sample data to a new restaurant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.SampleDataResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/operating-hours": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the weekly hours and how shifts outside them are treated (off, warn or block). Days is empty until hours are set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Gets a restaurant's operating hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.OperatingHours"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the weekly hours. Days must list all seven weekdays (0 = Sunday) once each, or be empty to remove the hours. Times are HH:MM and ignored on closed days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Sets a restaurant's operating hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Operating hours",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateOperatingHoursPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.OperatingHours"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/operating-hours/calendar": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolves the weekly hours and exceptions into one entry per date, for shading the schedule calendar. Dates without hours are omitted. Defaults to the restaurant's current week, from its week_start in its timezone; at most 93 days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Gets the hours in effect for a date range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.EffectiveHours"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/operating-hours/exceptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists holiday closures and other one-off hours between from and to. Defaults to the next 365 days.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Lists special hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.HoursException"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Overrides the weekly hours on one date, e.g. closed for a holiday or open late for an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Adds special hours for a date",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Special hours",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateHoursExceptionPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.HoursException"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/operating-hours/exceptions/{exceptionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a date's special hours so the weekly hours apply again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operating-hours"
+                ],
+                "summary": "Removes special hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Exception ID",
+                        "name": "exceptionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/demand-vs-staffing": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Puts each day's imported sales and covers beside the hours of the shifts scheduled that day, from through to (default the 8 weeks up to yesterday, at most 366 days). Days gain sales and covers per labor hour, weekdays are averaged over the days with sales data, and the correlations show how closely staffing has followed demand. Public holidays among the restaurant's special dates are named in holiday.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Compares sales with scheduled hours",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.DemandVsStaffing"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/heatmap": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Averages the last N full weeks of scheduled shifts into a day-of-week × hour-of-day matrix per role: staffed is the assigned headcount, open the shifts nobody was assigned to. A shift counts toward every hour it overlaps.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Staffing heatmap from past shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Weeks of history (default 8, max 52)",
+                        "name": "weeks",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.CoverageHeatmap"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/saved": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the restaurant's saved reports by name, with when each was last emailed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Lists saved reports",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.SavedReport"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves a report configuration to run again: hours_by_employee, coverage_by_role or labor_cost over the period_days before each run, narrowed to role_ids and employee_ids. With delivery weekly it's emailed as a CSV attachment every delivery_weekday (0 = Sunday) to the recipients, or the restaurant's owner when there are none.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Saves a report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report configuration",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SavedReportPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.SavedReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/saved/{reportID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Gets a saved report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Saved report ID",
+                        "name": "reportID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.SavedReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the report's configuration; fields left out take their defaults",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Updates a saved report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Saved report ID",
+                        "name": "reportID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report configuration",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SavedReportPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.SavedReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes the report, stopping its emails",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Deletes a saved report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Saved report ID",
+                        "name": "reportID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/saved/{reportID}/run": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Runs the report over from through to, by default its period_days up to yesterday (at most 366 days). format=csv answers with the CSV file the weekly email attaches instead of JSON.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Runs a saved report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Saved report ID",
+                        "name": "reportID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv for a file instead of JSON",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ReportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/shift-feedback": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Aggregates the ratings employees gave the restaurant's shifts dated from through to (default the 4 weeks up to today, at most 366 days): overall, per day and per role, with how many were rated 2 or less. Comments are listed newest shift first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Reports employee feedback on shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First shift date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last shift date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftFeedbackReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/reports/staffing-gaps": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "For every role: its target_headcount, the employees holding it who haven't left (holders), those of them holding every certification the role requires (certified), and employees without the role who have training shifts in it from the start of the period on (in_training). gap is how many more certified employees the role needs; gap_after_pipeline counts uncertified holders and trainees as filled. open_weeks counts the past weeks of the period in which its shifts went unassigned. hiring_warnings lists the roles with open shifts in at least 3 of those weeks that are below target (below_target) or have no target (no_target).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Staffing gaps against each role's target headcount",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Weeks of history (default 8, max 52)",
+                        "name": "weeks",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.StaffingGapReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/retention-policies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how long the restaurant keeps each kind of data it has a policy for: anonymize_terminated_employees erases employees after_months after their terminated_on date, delete_time_entries deletes clocked-out time entries after_months after they started. Kinds without a policy are kept forever.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "Lists a restaurant's retention policies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.RetentionPolicy"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/retention-policies/run": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies the restaurant's enabled retention policies, as the background job does, and reports what each purged: the employees anonymized and the number of time entries deleted from before its cutoff date. Each policy that purged something adds a retention_policy entry to the audit log. With dry_run=true nothing is changed and the report is what would be purged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "Applies the retention policies now",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Report what would be purged without purging it",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RetentionReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/retention-policies/{kind}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates or replaces the restaurant's policy for a kind of data. The background job applies enabled policies; POST .../retention-policies/run with dry_run=true shows what they would purge first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "Sets a retention policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "anonymize_terminated_employees",
+                            "delete_time_entries"
+                        ],
+                        "type": "string",
+                        "description": "Kind of data",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Retention policy",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RetentionPolicyPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.RetentionPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the restaurant's policy for a kind of data, which is then kept forever",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "retention"
+                ],
+                "summary": "Deletes a retention policy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "anonymize_terminated_employees",
+                            "delete_time_entries"
+                        ],
+                        "type": "string",
+                        "description": "Kind of data",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/roles/{roleID}/certifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches the certifications an employee must hold to be assigned shifts for a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certification"
+                ],
+                "summary": "Lists role's required certifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Certification"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replaces the certifications required to be assigned shifts for a role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certification"
+                ],
+                "summary": "Sets role's required certifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Required certification IDs",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SetRoleCertificationsPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Certification"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/roles/{roleID}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the shift templates and employees that refer to the role and counts its shifts from today on and before, i.e. what deleting it would change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Shows what uses a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "roleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.RoleUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/sales": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the sales and cover counts imported for the restaurant from through to (default the last 28 days), by date and hour with whole-day figures first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sales"
+                ],
+                "summary": "Lists imported sales",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.SalesRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Saves daily or hourly sales and cover counts, replacing figures already imported for the same day or hour. Send JSON records, or a CSV file with Content-Type text/csv and a header row naming its columns: date (YYYY-MM-DD) and any of hour (0-23, blank for the whole day), sales (in currency units, e.g. 1234.50), sales_cents and covers.",
+                "consumes": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sales"
+                ],
+                "summary": "Imports sales from a point of sale",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sales records",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportSalesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportSalesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/sales/webhook-token": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issues the token the restaurant's point of sale posts sales to POST /pos/sales with, as \"Authorization: POS \u003ctoken\u003e\". It replaces any earlier token and is not shown again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sales"
+                ],
+                "summary": "Issues the POS webhook token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SalesWebhookToken"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops the restaurant's point of sale from posting sales until a new token is issued",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sales"
+                ],
+                "summary": "Revokes the POS webhook token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/bulk-archive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Archives every schedule that ended before the given date, which can't be later than today. Archived schedules are left out of the schedule list unless it's called with archived=true, but are kept, shifts included, and still exported.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Archives past schedules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cutoff date",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkArchiveSchedulesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BulkArchiveResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/auto-assign": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assigns the schedule's unassigned shifts by the restaurant's assignment_policy. seniority_first offers each shift to the eligible employee with the highest seniority; rotate_fairly to the one with the fewest hours on the schedule so far. Eligible employees hold the shift's role and its required certifications, aren't already working at that time, and wouldn't break a compliance rule set to block. Published shifts inside the schedule lock, and shifts out for bids, are left alone. Each assignment is recorded in the shift history with the rule that chose it. Answers 409 when the policy is manual_only. With dry_run=true it returns the shifts as they would be assigned without assigning them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Auto-assign open shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview the assignments without making them",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.autoAssignResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/auto-populate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates scheduled shifts for all shift templates that don't have shifts yet. Shifts outside operating hours are skipped when the restaurant blocks them, otherwise listed in outside_operating_hours_ids. With dry_run=true nothing is written; the response is an autoPopulatePreview of the shifts that would be created, with a per-day breakdown and outside_operating_hours warnings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Auto-populate schedule with template-based shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview the shifts without creating them",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The schedule's bid rounds, newest first, with their shifts and, once allocated, who won each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-bidding"
+                ],
+                "summary": "Lists a schedule's bid rounds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.BidRound"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Puts unassigned shifts of the schedule up for bids until closes_at. Employees holding a shift's role see it in the employee portal and rank the ones they want; allocating the round then assigns them. Shifts out for bids are skipped by auto-assign, and can't be in two open rounds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-bidding"
+                ],
+                "summary": "Opens shifts for bidding",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bid round",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateBidRoundPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.BidRound"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "A shift is assigned, not on the schedule or already out for bids",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds/{roundID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The round's shifts with how many bids each drew, and each bidder's shifts ranked; once allocated, who won each shift at what rank, and the shifts and hours each bidder won.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-bidding"
+                ],
+                "summary": "Reports on a bid round",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Bid round ID",
+                        "name": "roundID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BidRoundReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/bid-rounds/{roundID}/allocate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Closes the round and drafts its shifts among the bidders: in each pass every bidder takes their highest-ranked shift still open that they hold the role and certifications for, aren't already working at that time, and can take without breaking a compliance rule set to block. Bidders with the fewest hours on the schedule pick first, each point of seniority counting as the round's seniority_weight hours fewer, then the most senior. Passes repeat until no one wins anything; shifts no one won stay open. Awards are assigned, recorded in the shift history and notified like any assignment. Before closes_at, allocating needs force=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-bidding"
+                ],
+                "summary": "Allocates a bid round",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Bid round ID",
+                        "name": "roundID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allocate before bidding closes",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.BidRoundReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Bidding is still open, or the round was already allocated",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/coverage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Counts each day's shifts, how many are assigned and open, and the scheduled hours. When the weather integration is configured and the restaurant has a latitude and longitude, days within the forecast carry the weather and a patio_weather hint for mild, dry, calm days. A forecast that can't be fetched is left out rather than failing the report. Public holidays among the restaurant's special dates are named in holiday.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Daily staffing of a schedule, with the weather",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleCoverage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/day-notes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the notes on the days of the schedule, in date order. The note for the whole week is the schedule's own note field.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Lists a schedule's day notes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.ScheduleDayNote"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/day-notes/{date}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates or replaces the note on one day of the schedule, e.g. \"Health inspection\". The date has to fall within the schedule's week.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Sets the note on a schedule day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleDayNotePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ScheduleDayNote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Removes the note on a schedule day",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day (YYYY-MM-DD)",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/email-blasts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Every send-email run on the schedule, newest first, with how many recipients it reached and the ones it failed to reach and why. A retry of failed recipients names the run it retried in retry_of.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Lists a schedule's email runs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.EmailBlast"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/email-preview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Renders the subject and HTML the employee would receive from send-email, in their language and with the restaurant's email customization, without sending anything or using the email quota. The response is marked preview: true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Previews an employee's schedule email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employee_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the week's events, as send-email's include_events does",
+                        "name": "include_events",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleEmailPreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/email-status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists every schedule, change and reminder email sent for the schedule with its delivery status (sent, failed, delivered, deferred, bounced, dropped or spam_report) as reported by SendGrid, and the latest email to each employee with totals by status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Shows whether a schedule's emails arrived",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleEmailStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/pre-check": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Before populating or publishing, cross-references the schedule's open shifts, and the template shifts auto-populate would add, against who holds each role, who is taking paid leave that day, who lacks a required certification and who is already working at that time. Answers with the dates and roles at risk: unfillable when no one holding the role is free, short when fewer are free than shifts to fill, and reassign when shifts are assigned to employees on leave but others can take them. Shifts out for bids count as open. Nothing is written.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Checks a schedule can be staffed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.schedulePreCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/published-version": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the shifts exactly as they were when the schedule was last published, unaffected by later edits, with which publish it was (version counts from 1). With at, returns the version that was published at that time instead, e.g. to settle what an employee was told.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Shows the schedule as published",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time to look back to (RFC 3339)",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ScheduleSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/send-email/retry-failures": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends the schedule email again to only the employees the schedule's last send-email run (or the last retry) failed to reach, with that run's include_events, and records the retry in the schedule's email-blasts history. Employees who were removed since are skipped; ones still without an address or with a bounced one fail again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Re-sends a schedule's email to the recipients it failed to reach",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SendScheduleEmailResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/share-link": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a link that shows the schedule read-only to anyone holding it, e.g. staff or partners without an account, until it expires (default 7 days, at most 90) or is revoked. The token and URLs are not shown again; url serves JSON and html_url a printable page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Creates a share link for a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Expiry",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateShareLinkPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShareLinkWithToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/share-links": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the schedule's share links, expired and revoked ones included, with when each was last viewed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Lists a schedule's share links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.ShareLink"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/share-links/{linkID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops the link from working for good",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Revokes a share link",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share link ID",
+                        "name": "linkID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the link to expire expires_in_hours from now, which can shorten it, extend it or bring back an expired link. Revoked links stay revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Changes when a share link expires",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share link ID",
+                        "name": "linkID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Expiry",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateShareLinkPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ShareLink"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets all scheduled shifts for a specific schedule; with include_conflicts=true each shift carries computed warnings (double_booked, overtime_risk, role_mismatch, certification_expired, outside_operating_hours)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "List all shifts for a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Annotate shifts with conflict warnings",
+                        "name": "include_conflicts",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.ShiftResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new scheduled shift for a specific schedule; its shift_date must fall within the schedule's dates. Shifts outside operating hours are rejected or returned with an outside_operating_hours warning, depending on the restaurant's enforcement setting. Set event_id to link the shift to an event on the same date. With employee_id, the employee must hold the role and its required certifications, or it answers 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Create a new shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shift information",
+                        "name": "shift",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.createScheduledShiftRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Gets a specific scheduled shift by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Get a shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a scheduled shift by ID; rejected with 423 if the shift is on a published schedule and starts within the restaurant's lock window, unless the owner overrides the lock",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Delete a shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Owner only: delete the shift even inside the schedule lock window",
+                        "name": "override_lock",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates an existing scheduled shift by ID as a JSON merge patch (RFC 7386): fields left out are unchanged and null clears shift_template_id, employee_id (unassigning the shift), notes and event_id. event_id links the shift to an event on the same date, 0 unlinks it. A shift_date outside the schedule's dates is refused. Changing the employee, role or date of an assigned shift answers 409 when the employee doesn't hold the role or its required certifications, unless it's a training shift.",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Update a shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated shift information",
+                        "name": "shift",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.updateScheduledShiftRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Owner only: change the shift even inside the schedule lock window",
+                        "name": "override_lock",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/assign": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes an employee assignment from a scheduled shift",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Unassign employee from shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Owner only: change the shift even inside the schedule lock window",
+                        "name": "override_lock",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assigns an employee to a scheduled shift; rejected with 409 if the employee lacks the shift's role or a certification the role requires, or the assignment breaks a compliance rule set to block. Rules set to warn add the compliance warning to the shift. With training=true the role check is skipped so the employee can train for the role, optionally linked to the trainer's overlapping shift via trainer_shift_id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Assign employee to shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employee assignment information",
+                        "name": "employee",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.assignEmployeeRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Owner only: change the shift even inside the schedule lock window",
+                        "name": "override_lock",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "423": {
+                        "description": "Locked",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/schedules/{scheduleID}/shifts/{shiftID}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the shift's audit log oldest first: how it was created (by hand, from a template or for an event), who it was assigned and reassigned to, and changes to its time, date, role or notes, each with who made it, and the shift as it was in the last published version of the schedule. History is kept after the shift is deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Lists the changes made to a shift",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift ID",
+                        "name": "shiftID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/shifts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the restaurant's shifts across all schedules in date order, optionally only those dated from through to. Follow next_cursor until it is absent to read them all; each page costs the same however far into the history it is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduled-shifts"
+                ],
+                "summary": "Pages through a restaurant's shift history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First shift date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last shift date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 500, max 5000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/sync": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the roles, employees, schedules, shifts and events changed since the cursor from the previous sync, plus the IDs of those deleted. Without since every row is returned and full is set. Upsert the rows by ID, apply the deletions, then keep cursor for the next call; a row can come back again right after a sync, so applying it twice must be harmless. A full sync with shift_limit returns only the first shifts by date, and next_shift_cursor while more remain to page through with GET /restaurants/{restaurantID}/shifts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Syncs a restaurant incrementally",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned by the previous sync",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most shifts a full sync returns (max 5000; default all)",
+                        "name": "shift_limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/time-entries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the time entries clocked in between from and to, inclusive (UTC dates, default the last 7 days). Entries without clock_out_at are still open.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "kiosk"
+                ],
+                "summary": "Lists clock-ins",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First date (YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last date (YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.TimeEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the restaurant again and allows changes to it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "restaurant"
+                ],
+                "summary": "Unarchives a Restaurant",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Restaurant"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The URLs the restaurant's events are posted to, without their signing secrets.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lists the restaurant's webhook endpoints",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.WebhookEndpoint"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Posts the restaurant's events to an https URL: employee.created, employee.updated and employee.deactivated, or only the ones in events. Each POST carries the event as JSON with X-RESA-Event, X-RESA-Delivery (the event ID) and X-RESA-Signature headers; the signature is t=\u003cunix seconds\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\" keyed by the secret\u003e. Deliveries the endpoint doesn't answer with a 2xx are retried with backoff. The secret is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Registers a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Endpoint",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateWebhookPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.WebhookEndpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/webhooks/{webhookID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stops posting events to the endpoint and drops the deliveries still queued for it.",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Removes a webhook endpoint",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook endpoint ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurantID}/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The endpoint's latest 100 deliveries, newest first: pending (with the next attempt due), delivered, or failed after every attempt, with the last status and error the endpoint gave.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lists a webhook endpoint's deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurantID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Webhook endpoint ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/employees": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all employees for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Lists restaurant's employees",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Employee"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates an employee for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Creates an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employee payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateEmployeePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Employee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/employees/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches an employee by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Fetches an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Employee"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes an employee by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Deletes an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates an employee by ID as a JSON merge patch (RFC 7386): fields left out are unchanged; null clears locale, hourly_rate_cents, birthday, hire_date, external_id and terminated_on and resets seniority to 0",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Updates an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employee payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateEmployeePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Employee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/employees/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all roles assigned to a specific employee",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Get employee roles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assigns multiple roles to an employee",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Assigns roles to an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role IDs payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.AddEmployeeRolesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/employees/{id}/roles/{role_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a specific role from an employee",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "employee"
+                ],
+                "summary": "Removes a role from an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "role_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all events for a restaurant, optionally filtered by date range, with their linked shifts and staffing coverage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Lists restaurant's events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date filter (YYYY-MM-DD)",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date filter (YYYY-MM-DD)",
+                        "name": "end_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Event"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates an event for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Creates an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateEventPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches an event by ID with its linked shifts and staffing coverage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Fetches an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Event"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes an event by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Deletes an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates an event by ID as a JSON merge patch (RFC 7386): fields left out are unchanged, null clears the description, and employee_ids replaces the assigned employees (null or [] removes them all)",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Updates an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event payload with optional fields",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateEventPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/attendance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the employees assigned to the event with whether and when their badge was scanned, then anyone checked in without being assigned, with the totals",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Reports attendance at an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EventAttendance"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/badges": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issues a badge per employee for the event, by default to everyone assigned to it. Each badge's token goes in a QR code or NFC tag that a manager scans at the event to take attendance; tokens are not shown again, and issuing a badge again replaces the employee's old one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Issues event badges",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employees to issue badges to; {} for the assigned ones",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.IssueEventBadgesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.EventBadgeWithToken"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/check-ins": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Records a scan of an employee's event badge. The first scan checks them in with 201; scanning again answers 200 with the first check-in and already_checked_in set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Checks an employee in to an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scanned badge token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.EventCheckInPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.EventCheckInResult"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.EventCheckInResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/employees": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves all employees assigned to an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Get employees for an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Employee"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Assigns multiple employees to an event (additive)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Assigns employees to an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Employee IDs payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.AssignEventEmployeesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/employees/{employeeID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a specific employee from an event",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Removes an employee from an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "employeeID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/events/{eventID}/shifts": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a scheduled shift linked to the event, on the event's date and by default at its times, in the schedule covering that date. With employee_id, the employee must hold the role and its required certifications, or it answers 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Creates a shift for an event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "eventID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shift payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateEventShiftPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all roles for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Lists restaurant's roles",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a role for a restaurant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Creates a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateRolePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/roles/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a role by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Fetches a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Role"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a role by ID. A role still used by shift templates, shifts or employees (see GET .../usage) is only deleted with reassign_to, which moves all of it to another role first, or force=true, which deletes its shifts and drops it from templates and employees. Either way it happens in one transaction.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Deletes a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role to move the shifts, templates and employees to",
+                        "name": "reassign_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Delete the role's shifts along with it",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a role by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Updates a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateRolePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/roles/{id}/employees": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all employees assigned to a specific role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "role"
+                ],
+                "summary": "Get employees for a role",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Employee"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all schedules for a restaurant; archived schedules are only listed, on their own, with archived=true",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Lists restaurant's schedules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "List archived schedules instead",
+                        "name": "archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Schedule"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a schedule for a restaurant. With preview_populate=true nothing is created; the response is the autoPopulatePreview of the shifts the templates would generate for the dates, as auto-populate's dry run would give for the new schedule.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Creates a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateSchedulePayload"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Preview the template shifts without creating the schedule",
+                        "name": "preview_populate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.autoPopulatePreview"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a schedule by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Fetches a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Schedule"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a schedule by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Deletes a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a schedule by ID. Shrinking its dates past some of its shifts is refused with 409 and the stranded_shifts listed, unless stranded_shifts is delete, which deletes them along with the update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Updates a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateSchedulePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/acknowledgments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists every assigned shift of a published schedule with when its employee acknowledged it, and the employees with shifts still unacknowledged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Reports shift acknowledgments for a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleAcknowledgmentReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/acknowledgments/remind": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Emails each employee with upcoming shifts on the published schedule they haven't acknowledged, listing only those shifts. Employees who have acknowledged everything get nothing.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Reminds employees to acknowledge their shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SendScheduleEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/changes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Compares the schedule's shifts now with a baseline: the schedule as it was at since, or by default at its most recent publish or change notification. With baseline=published only publishes count, so the diff is against the version employees were published. Shifts are reported as added, removed, time_changed (date or times) or reassigned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Lists changes to a published schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Baseline time (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest (default) or published",
+                        "name": "baseline",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ScheduleChanges"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/export.xlsx": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Downloads the schedule as an .xlsx file with one sheet per day, roles filled with their colors, and a Totals sheet of shifts and hours per employee",
+                "produces": [
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Exports a schedule as an Excel workbook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/labor-cost": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Prices every shift at its employee's hourly rate and compares the total to the restaurant's weekly labor budget, prorated to the schedule's length, with a per-day breakdown. Unassigned shifts and employees without a rate count as unpriced hours.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Projects a schedule's labor cost",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.LaborCost"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/notify-changes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends each employee whose shifts were added, removed, moved or reassigned since the baseline an email listing only their changes. Employees who get digests have their changes held for their next one (counted as queued) unless critical is set. Once any email is sent or queued, the current shifts become the new baseline.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Emails employees affected by schedule changes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Baseline",
+                        "name": "payload",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.NotifyScheduleChangesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SendScheduleEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/publish": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Publishes a schedule to make it available to employees. When the restaurant has a weekly labor budget and the schedule's projected cost is over it, publishing is refused with 409 and the labor cost breakdown; with force=true it publishes anyway and answers 200 with the breakdown as a warning. Shifts breaking a compliance rule set to block refuse publishing with 409 and the violations, whatever force says; rules set to warn publish and answer 200 with compliance_warnings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Publishes a schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Publish even when over the labor budget",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.PublishScheduleResult"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/schedules/{id}/send-email": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sends the schedule via email to all employees in the restaurant. The run and the recipients it failed to reach are kept in the schedule's email-blasts history, and send-email/retry-failures re-sends to those. Recipients whose address isn't verified are listed in unverified_employee_ids; a restaurant with require_email_verification holds their emails as failures.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Sends schedule emails to all employees",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email options",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.SendScheduleEmailPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SendScheduleEmailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches all shift templates for a restaurant; active_on lists only those whose effective window includes that day and, for week_parity templates, whose rotation week it is",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Lists restaurant's shift templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only templates in effect on this day (YYYY-MM-DD)",
+                        "name": "active_on",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.ShiftTemplate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a shift template for a restaurant. week_parity a or b makes it apply every other week, counted in seven-day weeks from the restaurant's rotation_anchor (week a); such templates are skipped until the restaurant sets one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Creates a shift template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shift template payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateShiftTemplatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.ShiftTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Clusters a restaurant's templates that fall on the same day, start and end within the tolerance of each other and share most of their roles. Each cluster suggests the oldest template to keep; pass it with the others to the merge endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Finds near-duplicate shift templates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "How far apart start and end times may be (default 15, max 120)",
+                        "name": "tolerance_minutes",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum share of roles in common between 0 and 1 (default 0.5)",
+                        "name": "min_role_overlap",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.ShiftTemplateDuplicates"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates/merge": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Keeps keep_id, gives it the roles of every merged template, re-points the scheduled shifts made from the merged templates to it and deletes them, all in one transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Merges shift templates into one",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Template to keep and templates to merge into it",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.MergeShiftTemplatesPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ShiftTemplateMergeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates/suggestions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Mines the last N weeks of scheduled shifts for recurring day/time/role patterns that no template covers yet. Confidence is the share of weeks the pattern appeared in; create_payload can be posted as-is to create the template.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Suggests shift templates from past shifts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Weeks of history to analyze (default 8, max 52)",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum confidence between 0 and 1 (default 0.5)",
+                        "name": "min_confidence",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.ShiftTemplateSuggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a shift template by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Fetches a shift template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ShiftTemplate"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Deletes a shift template by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Deletes a shift template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Updates a shift template by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Updates a shift template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shift template payload with optional fields",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateShiftTemplatePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ShiftTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/restaurants/{restaurant_id}/shift-templates/{id}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieves all roles associated with a shift template",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shift-template"
+                ],
+                "summary": "Get roles for a shift template",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Restaurant ID",
+                        "name": "restaurant_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shift Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/shared/schedules/{token}": {
+            "get": {
+                "description": "Shows the schedule behind a share link read-only, with its shifts and notes. It needs no account: the token in the URL is the access, and it stops working once the link expires or is revoked, or the restaurant is archived. format=html serves a printable page instead of JSON.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "schedule"
+                ],
+                "summary": "Shows a shared schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Share link token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "html for a page instead of JSON",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.SharedSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/activate/{token}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Activates/Register a user by invitation token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Activates/Register a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invitation token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User activated"
+                    },
+                    "404": {
+                        "description": "Token is invalid",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Token has already been used",
+                        "schema": {}
+                    },
+                    "410": {
+                        "description": "Token has expired",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/data-export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Downloads a ZIP of JSON files for a data access request: user.json (the account), subscription.json, notifications.json, and restaurants/{id}.json with all the data of every restaurant the user owns, archived ones included. Uploaded files are listed but not included; the restaurant export has them.",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Exports everything stored about the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/locale": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Sets the language used for the user's emails and API messages; null follows the browser's Accept-Language",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sets the current user's language",
+                "parameters": [
+                    {
+                        "description": "Locale (en or es)",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateLocalePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/memberships": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Lists the restaurants other owners have given the user access to, with the roles they manage; archived restaurants are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Lists the restaurants the current user is a member of",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Membership"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The latest 50 sign-ins and security changes on the account, newest first, with the device each came from",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Lists the current user's recent security events",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.SecurityEvent"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Active sessions, most recently used first, with the device and IP address each signed in from",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Lists the current user's signed-in devices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.SessionView"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{sessionID}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revokes the session; its token stops working on the next request",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Signs a device out",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "sessionID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/two-factor": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Whether an authenticator app is set up, whether sign-in asks for it, and how many recovery codes are left",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Shows the current user's two-factor authentication",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.TwoFactorStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Needs a current authenticator code or an unused recovery code. The app stays set up either way.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Turns codes at sign-in on or off",
+                "parameters": [
+                    {
+                        "description": "Whether sign-in asks for a code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateTwoFactorPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Wrong code",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "No confirmed authenticator app",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/two-factor/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks a code from the newly set up app, turns on codes at sign-in and returns the recovery codes. They are shown only this once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirms the authenticator app",
+                "parameters": [
+                    {
+                        "description": "Code from the authenticator app",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.TwoFactorCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong code",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "No enrollment was started",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Already confirmed",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/two-factor/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Generates a new secret and its otpauth:// URL for a QR code. Nothing changes at sign-in until a code from the app is confirmed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Starts setting up an authenticator app",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.TwoFactorEnrollment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "An authenticator app is already set up",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/me/two-factor/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Needs a current authenticator code or an unused recovery code. The old codes stop working; the new ones are shown only this once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Replaces the recovery codes",
+                "parameters": [
+                    {
+                        "description": "Authenticator or recovery code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.TwoFactorCodePayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RecoveryCodes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Wrong code",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "No confirmed authenticator app",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.JWKSet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.JWK"
+                    }
+                }
+            }
+        },
+        "billing.Plan": {
+            "type": "string",
+            "enum": [
+                "free",
+                "pro",
+                "business"
+            ],
+            "x-enum-varnames": [
+                "PlanFree",
+                "PlanPro",
+                "PlanBusiness"
+            ]
+        },
+        "cache.EmailQuota": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "resets_at": {
+                    "type": "string"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "i18n.Locale": {
+            "type": "string",
+            "enum": [
+                "en",
+                "es",
+                "en"
+            ],
+            "x-enum-varnames": [
+                "English",
+                "Spanish",
+                "Default"
+            ]
+        },
+        "main.AcknowledgeDocumentPayload": {
+            "type": "object",
+            "required": [
+                "signed_name"
+            ],
+            "properties": {
+                "signed_name": {
+                    "description": "SignedName is the employee's name as they typed it to sign",
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "main.AddEmployeeRolesPayload": {
+            "type": "object",
+            "required": [
+                "role_ids"
+            ],
+            "properties": {
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.AddMemberPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "role_ids"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "role_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.AssignEventEmployeesPayload": {
+            "type": "object",
+            "required": [
+                "employee_ids"
+            ],
+            "properties": {
+                "employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.BidPreferencesPayload": {
+            "type": "object",
+            "properties": {
+                "shift_ids": {
+                    "description": "ShiftIDs ranks the shifts wanted, first choice first; empty withdraws every bid",
+                    "type": "array",
+                    "maxItems": 200,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.BidRoundReport": {
+            "type": "object",
+            "properties": {
+                "assigned_count": {
+                    "description": "AssignedCount is how many shifts were awarded; 0 until allocated",
+                    "type": "integer"
+                },
+                "bidders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.BidderResult"
+                    }
+                },
+                "round": {
+                    "$ref": "#/definitions/store.BidRound"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.BidShiftResult"
+                    }
+                }
+            }
+        },
+        "main.BidShiftResult": {
+            "type": "object",
+            "properties": {
+                "awarded_employee_id": {
+                    "type": "integer"
+                },
+                "awarded_employee_name": {
+                    "type": "string"
+                },
+                "awarded_rank": {
+                    "type": "integer"
+                },
+                "bids": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "first_choice_bids": {
+                    "description": "FirstChoiceBids counts the bidders who ranked the shift first",
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "shift_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "shift_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BidderResult": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "first_choice_won": {
+                    "description": "FirstChoiceWon is whether they won the shift they ranked first",
+                    "type": "boolean"
+                },
+                "hours_won": {
+                    "type": "number"
+                },
+                "ranked": {
+                    "type": "integer"
+                },
+                "seniority": {
+                    "type": "integer"
+                },
+                "won": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.BillingPortalResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BoardShift": {
+            "type": "object",
+            "properties": {
+                "employee_name": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "training": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.BoardView": {
+            "type": "object",
+            "properties": {
+                "board_name": {
+                    "type": "string"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "refresh_seconds": {
+                    "description": "RefreshSeconds is how often to poll; send the ETag back as If-None-Match\nand a 304 means nothing changed",
+                    "type": "integer"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.BoardShift"
+                    }
+                }
+            }
+        },
+        "main.BulkArchiveResult": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.BulkArchiveSchedulesPayload": {
+            "type": "object",
+            "required": [
+                "before"
+            ],
+            "properties": {
+                "before": {
+                    "description": "Before archives the schedules that ended before this date",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CalendarFeedWithToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the CalDAV collection to add to a calendar app",
+                    "type": "string"
+                },
+                "webcal_url": {
+                    "description": "WebcalURL subscribes apps that take an iCalendar feed rather than CalDAV",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CertificationExpiryEmailResponse": {
+            "type": "object",
+            "properties": {
+                "certifications": {
+                    "type": "integer"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "quota": {
+                    "$ref": "#/definitions/cache.EmailQuota"
+                },
+                "sent": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.ClockPayload": {
+            "type": "object",
+            "required": [
+                "employee_id",
+                "pin"
+            ],
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "pin": {
+                    "type": "string",
+                    "maxLength": 8,
+                    "minLength": 4
+                }
+            }
+        },
+        "main.ClockResult": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "entry": {
+                    "$ref": "#/definitions/store.TimeEntry"
+                }
+            }
+        },
+        "main.CloneRestaurantPayload": {
+            "type": "object",
+            "required": [
+                "address",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "include_employees": {
+                    "description": "IncludeEmployees copies the employees with their roles and certifications too",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "main.ComplianceRulesPayload": {
+            "type": "object",
+            "required": [
+                "jurisdiction"
+            ],
+            "properties": {
+                "consecutive_days_severity": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "block"
+                    ]
+                },
+                "jurisdiction": {
+                    "type": "string",
+                    "enum": [
+                        "custom",
+                        "us_federal",
+                        "california",
+                        "eu"
+                    ]
+                },
+                "max_consecutive_days": {
+                    "type": "integer",
+                    "maximum": 13,
+                    "minimum": 0
+                },
+                "min_rest_hours": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 0
+                },
+                "minor_age": {
+                    "type": "integer",
+                    "maximum": 21,
+                    "minimum": 0
+                },
+                "minor_latest_end": {
+                    "type": "string"
+                },
+                "minor_max_daily_hours": {
+                    "type": "integer",
+                    "maximum": 24,
+                    "minimum": 0
+                },
+                "minor_max_weekly_hours": {
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0
+                },
+                "minor_severity": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "block"
+                    ]
+                },
+                "rest_severity": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "block"
+                    ]
+                }
+            }
+        },
+        "main.ComplianceViolation": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "severity": {
+                    "$ref": "#/definitions/store.ComplianceSeverity"
+                },
+                "shift_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.CoverageHeatmap": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.RoleCoverage"
+                    }
+                },
+                "to": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "weeks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.CreateBidRoundPayload": {
+            "type": "object",
+            "required": [
+                "closes_at",
+                "shift_ids"
+            ],
+            "properties": {
+                "closes_at": {
+                    "type": "string"
+                },
+                "seniority_weight": {
+                    "description": "SeniorityWeight is how many hours already won one point of seniority\nmakes up for in the draft order; 0 orders by hours alone",
+                    "type": "number",
+                    "maximum": 1000,
+                    "minimum": 0
+                },
+                "shift_ids": {
+                    "type": "array",
+                    "maxItems": 200,
+                    "minItems": 1,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.CreateCalendarFeedPayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "employee_id": {
+                    "description": "EmployeeID limits the feed to the employee's own shifts; without it the\nfeed has every shift of the restaurant",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.CreateCertificationPayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "main.CreateDisplayBoardPayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.CreateEmployeeNotePayload": {
+            "type": "object",
+            "required": [
+                "body"
+            ],
+            "properties": {
+                "body": {
+                    "description": "Body is Markdown, shown as rich text",
+                    "type": "string",
+                    "maxLength": 10000
+                }
+            }
+        },
+        "main.CreateEmployeePayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "full_name"
+            ],
+            "properties": {
+                "birthday": {
+                    "description": "Birthday and HireDate are optional YYYY-MM-DD dates for the staff milestone digest",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "external_id": {
+                    "description": "ExternalID is the employee's ID in an HR system, unique within the restaurant",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "full_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "hire_date": {
+                    "type": "string"
+                },
+                "hourly_rate_cents": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "es"
+                    ]
+                },
+                "notification_mode": {
+                    "description": "NotificationMode overrides the restaurant's for the employee: immediate, daily or shift_day",
+                    "type": "string",
+                    "enum": [
+                        "immediate",
+                        "daily",
+                        "shift_day"
+                    ]
+                },
+                "seniority": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "terminated_on": {
+                    "description": "TerminatedOn is the optional YYYY-MM-DD day the employee left, for retention policies",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateEventPayload": {
+            "type": "object",
+            "required": [
+                "date",
+                "end_time",
+                "start_time",
+                "title"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.CreateEventShiftPayload": {
+            "type": "object",
+            "required": [
+                "role_id"
+            ],
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "description": "StartTime and EndTime default to the event's own times",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateHoursExceptionPayload": {
+            "type": "object",
+            "required": [
+                "date",
+                "name"
+            ],
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "open_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateKioskPayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.CreateRestaurantPayload": {
+            "type": "object",
+            "required": [
+                "address",
+                "name"
+            ],
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                }
+            }
+        },
+        "main.CreateRolePayload": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "target_headcount": {
+                    "description": "TargetHeadcount is how many employees should be able to work the role",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                }
+            }
+        },
+        "main.CreateSchedulePayload": {
+            "type": "object",
+            "required": [
+                "end_date",
+                "start_date"
+            ],
+            "properties": {
+                "end_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "start_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateShareLinkPayload": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "description": "ExpiresInHours defaults to a week",
+                    "type": "integer",
+                    "maximum": 2160,
+                    "minimum": 1
+                }
+            }
+        },
+        "main.CreateShiftTemplatePayload": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "name",
+                "start_time"
+            ],
+            "properties": {
+                "day_of_week": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "effective_from": {
+                    "description": "EffectiveFrom and EffectiveUntil (YYYY-MM-DD, inclusive) limit a seasonal template to its window",
+                    "type": "string"
+                },
+                "effective_until": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "week_parity": {
+                    "description": "WeekParity (a or b) limits the template to that week of the restaurant's two-week rotation",
+                    "type": "string",
+                    "enum": [
+                        "a",
+                        "b"
+                    ]
+                }
+            }
+        },
+        "main.CreateUserTokenPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 3
+                }
+            }
+        },
+        "main.CreateWebhookPayload": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "description": "Events limits the endpoint to these event types; empty sends all of them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "main.DemandStaffingDay": {
+            "type": "object",
+            "properties": {
+                "covers": {
+                    "type": "integer"
+                },
+                "covers_per_labor_hour": {
+                    "type": "number"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "holiday": {
+                    "description": "Holiday names the public holiday on the date, which explains many a busy or quiet day",
+                    "type": "string"
+                },
+                "sales_cents": {
+                    "type": "integer"
+                },
+                "sales_per_labor_hour_cents": {
+                    "type": "integer"
+                },
+                "scheduled_hours": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.DemandVsStaffing": {
+            "type": "object",
+            "properties": {
+                "covers_hours_correlation": {
+                    "type": "number"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.DemandStaffingDay"
+                    }
+                },
+                "from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "sales_hours_correlation": {
+                    "description": "SalesHoursCorrelation is the Pearson correlation of daily sales with scheduled hours,\nover days with both; absent with too few days or figures that never vary",
+                    "type": "number"
+                },
+                "to": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "weekdays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.WeekdayDemand"
+                    }
+                }
+            }
+        },
+        "main.DisplayBoardWithToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "html_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.DocumentAcknowledgmentReport": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "integer"
+                },
+                "acknowledgments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.DocumentAcknowledgment"
+                    }
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "outstanding_employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "requested": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.DocumentToAcknowledgeView": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "document_name": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "signed_name": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.EmailTemplatePayload": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string"
+                },
+                "header_message": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "logo_url": {
+                    "type": "string",
+                    "maxLength": 2048
+                },
+                "subject": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "main.EmailTemplatePreviewResponse": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.EmailVerificationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "quota": {
+                    "$ref": "#/definitions/cache.EmailQuota"
+                }
+            }
+        },
+        "main.EmployeeLeave": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.LeaveEntry"
+                    }
+                }
+            }
+        },
+        "main.EmployeePIN": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "pin": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.EventAttendance": {
+            "type": "object",
+            "properties": {
+                "absent": {
+                    "type": "integer"
+                },
+                "attended": {
+                    "type": "integer"
+                },
+                "attendees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.EventAttendee"
+                    }
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "expected": {
+                    "description": "Expected is how many employees are assigned; Attended how many of them checked in",
+                    "type": "integer"
+                },
+                "walk_ins": {
+                    "description": "WalkIns checked in without being assigned to the event",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.EventAttendee": {
+            "type": "object",
+            "properties": {
+                "assigned": {
+                    "type": "boolean"
+                },
+                "checked_in": {
+                    "type": "boolean"
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.EventBadgeWithToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "description": "Token is what the badge's QR code or NFC tag carries",
+                    "type": "string"
+                }
+            }
+        },
+        "main.EventCheckInPayload": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "method": {
+                    "description": "Method is how the badge was read; qr by default",
+                    "type": "string",
+                    "enum": [
+                        "qr",
+                        "nfc"
+                    ]
+                },
+                "token": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "main.EventCheckInResult": {
+            "type": "object",
+            "properties": {
+                "already_checked_in": {
+                    "type": "boolean"
+                },
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "scanned_by": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.GoogleCallbackPayload": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.GoogleLoginResponse": {
+            "type": "object",
+            "properties": {
+                "auth_url": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.HiringWarning": {
+            "type": "object",
+            "properties": {
+                "gap": {
+                    "type": "integer"
+                },
+                "open_weeks": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ImportSalesPayload": {
+            "type": "object",
+            "required": [
+                "records"
+            ],
+            "properties": {
+                "records": {
+                    "type": "array",
+                    "maxItems": 10000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/main.SalesRecordPayload"
+                    }
+                }
+            }
+        },
+        "main.ImportSalesResponse": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.IssueEventBadgesPayload": {
+            "type": "object",
+            "properties": {
+                "employee_ids": {
+                    "description": "EmployeeIDs default to the employees assigned to the event",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.KioskWithToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.LaborCost": {
+            "type": "object",
+            "properties": {
+                "budget_cents": {
+                    "description": "BudgetCents is the weekly budget prorated to the schedule's length; absent without a budget",
+                    "type": "integer"
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.LaborCostDay"
+                    }
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "over_budget_cents": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "total_cents": {
+                    "type": "integer"
+                },
+                "unpriced_hours": {
+                    "description": "UnpricedHours are on shifts that are unassigned or whose employee has no hourly rate",
+                    "type": "number"
+                }
+            }
+        },
+        "main.LaborCostDay": {
+            "type": "object",
+            "properties": {
+                "cost_cents": {
+                    "type": "integer"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "unpriced_hours": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.LeaveEntryPayload": {
+            "type": "object",
+            "required": [
+                "date",
+                "hours",
+                "kind"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Date is the day of leave paid, or the day an adjustment applies from",
+                    "type": "string"
+                },
+                "hours": {
+                    "type": "number",
+                    "maximum": 10000,
+                    "minimum": -10000
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "paid",
+                        "adjustment"
+                    ]
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "main.LeavePolicyPayload": {
+            "type": "object",
+            "required": [
+                "accrue_from",
+                "basis"
+            ],
+            "properties": {
+                "accrual_hours": {
+                    "type": "number",
+                    "maximum": 100
+                },
+                "accrue_from": {
+                    "type": "string"
+                },
+                "basis": {
+                    "type": "string",
+                    "enum": [
+                        "worked",
+                        "scheduled"
+                    ]
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "max_balance_hours": {
+                    "description": "MaxBalanceHours caps the balance accruals build up to; 0 for no cap",
+                    "type": "number",
+                    "maximum": 10000,
+                    "minimum": 0
+                },
+                "per_hours": {
+                    "type": "number",
+                    "maximum": 1000
+                }
+            }
+        },
+        "main.MarkAllReadResponse": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.MergeShiftTemplatesPayload": {
+            "type": "object",
+            "required": [
+                "keep_id",
+                "merge_ids"
+            ],
+            "properties": {
+                "keep_id": {
+                    "type": "integer"
+                },
+                "merge_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.NotificationsResponse": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Notification"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.NotifyScheduleChangesPayload": {
+            "type": "object",
+            "properties": {
+                "baseline": {
+                    "type": "string",
+                    "enum": [
+                        "latest",
+                        "published"
+                    ]
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.OnboardingState": {
+            "type": "object",
+            "properties": {
+                "can_add_sample_data": {
+                    "description": "CanAddSampleData is true while the restaurant is empty enough for sample data",
+                    "type": "boolean"
+                },
+                "completed": {
+                    "type": "integer"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "next_step": {
+                    "description": "NextStep is the first step not done yet, absent once every step is",
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.OnboardingStep"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.OnboardingStep": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.OperatingDayPayload": {
+            "type": "object",
+            "required": [
+                "day_of_week"
+            ],
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "day_of_week": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "open_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.PublishScheduleResult": {
+            "type": "object",
+            "properties": {
+                "budget_cents": {
+                    "description": "BudgetCents is the weekly budget prorated to the schedule's length; absent without a budget",
+                    "type": "integer"
+                },
+                "compliance_warnings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ComplianceViolation"
+                    }
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.LaborCostDay"
+                    }
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "over_budget_cents": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "total_cents": {
+                    "type": "integer"
+                },
+                "unpriced_hours": {
+                    "description": "UnpricedHours are on shifts that are unassigned or whose employee has no hourly rate",
+                    "type": "number"
+                }
+            }
+        },
+        "main.RateLimitStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "identity": {
+                    "description": "Identity is what the budget is kept by: the client's IP address",
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "remaining": {
+                    "type": "integer"
+                },
+                "reset_seconds": {
+                    "type": "integer"
+                },
+                "window_seconds": {
+                    "description": "WindowSeconds is how long each budget lasts",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.RecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "main.RegisterUserPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "first_name",
+                "last_name",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "first_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "last_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "es"
+                    ]
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 3
+                }
+            }
+        },
+        "main.ReportResult": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "to": {
+                    "$ref": "#/definitions/store.DateOnly"
+                }
+            }
+        },
+        "main.RequestDocumentAcknowledgmentsPayload": {
+            "type": "object",
+            "properties": {
+                "employee_ids": {
+                    "description": "EmployeeIDs defaults to every employee of the restaurant",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.RequestDocumentAcknowledgmentsResponse": {
+            "type": "object",
+            "properties": {
+                "already_acknowledged": {
+                    "description": "AlreadyAcknowledged counts the employees skipped because they had signed",
+                    "type": "integer"
+                },
+                "blast_id": {
+                    "description": "BlastID identifies the run in the schedule's email-blasts history",
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SendScheduleEmailFailure"
+                    }
+                },
+                "queued": {
+                    "description": "held for employees' digests",
+                    "type": "integer"
+                },
+                "quota": {
+                    "$ref": "#/definitions/cache.EmailQuota"
+                },
+                "successful": {
+                    "type": "integer"
+                },
+                "total_recipients": {
+                    "type": "integer"
+                },
+                "unverified_employee_ids": {
+                    "description": "UnverifiedEmployeeIDs are the recipients whose address isn't verified yet;\na restaurant that requires verification holds their emails as failures",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.ResendConfirmationPayload": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "main.RestaurantCloneResponse": {
+            "type": "object",
+            "properties": {
+                "id_map": {
+                    "$ref": "#/definitions/store.CloneIDMap"
+                },
+                "restaurant": {
+                    "$ref": "#/definitions/store.Restaurant"
+                }
+            }
+        },
+        "main.RestaurantFeaturesResponse": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "plan": {
+                    "$ref": "#/definitions/billing.Plan"
+                }
+            }
+        },
+        "main.RetentionEmployee": {
+            "type": "object",
+            "properties": {
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "terminated_on": {
+                    "$ref": "#/definitions/store.DateOnly"
+                }
+            }
+        },
+        "main.RetentionPolicyPayload": {
+            "type": "object",
+            "required": [
+                "after_months"
+            ],
+            "properties": {
+                "after_months": {
+                    "type": "integer",
+                    "maximum": 1200,
+                    "minimum": 1
+                },
+                "enabled": {
+                    "description": "Enabled defaults to true; a disabled policy keeps its setting but purges nothing",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.RetentionPolicyReport": {
+            "type": "object",
+            "properties": {
+                "cutoff": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "employees": {
+                    "description": "Employees are those anonymized, named as they were before",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.RetentionEmployee"
+                    }
+                },
+                "kind": {
+                    "$ref": "#/definitions/store.RetentionKind"
+                },
+                "time_entries": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.RetentionReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "policies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.RetentionPolicyReport"
+                    }
+                }
+            }
+        },
+        "main.RoleCoverage": {
+            "type": "object",
+            "properties": {
+                "open": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "staffed": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number"
+                        }
+                    }
+                }
+            }
+        },
+        "main.RoleStaffingGap": {
+            "type": "object",
+            "properties": {
+                "certified": {
+                    "type": "integer"
+                },
+                "gap": {
+                    "type": "integer"
+                },
+                "gap_after_pipeline": {
+                    "type": "integer"
+                },
+                "holders": {
+                    "description": "Holders are the employees holding the role who haven't left; Certified are\nthose of them holding every certification it requires, unexpired",
+                    "type": "integer"
+                },
+                "in_training": {
+                    "description": "InTraining are employees without the role who have training shifts in it",
+                    "type": "integer"
+                },
+                "open_weeks": {
+                    "description": "OpenWeeks are the past weeks in which shifts of the role went unassigned",
+                    "type": "integer"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "target_headcount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SalesRecordPayload": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "covers": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "date": {
+                    "type": "string"
+                },
+                "hour": {
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0
+                },
+                "sales_cents": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "main.SalesWebhookToken": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SavedReportPayload": {
+            "type": "object",
+            "required": [
+                "kind",
+                "name"
+            ],
+            "properties": {
+                "delivery": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "weekly"
+                    ]
+                },
+                "delivery_weekday": {
+                    "description": "DeliveryWeekday is the day a weekly report is emailed, 0 = Sunday; default Monday",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "employee_ids": {
+                    "type": "array",
+                    "maxItems": 500,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "hours_by_employee",
+                        "coverage_by_role",
+                        "labor_cost"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "period_days": {
+                    "description": "PeriodDays is how many days up to the day before a run it covers; default 7",
+                    "type": "integer",
+                    "maximum": 366,
+                    "minimum": 1
+                },
+                "recipients": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.ScheduleAcknowledgmentReport": {
+            "type": "object",
+            "properties": {
+                "acknowledged": {
+                    "type": "integer"
+                },
+                "assigned_shifts": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ShiftAcknowledgmentStatus"
+                    }
+                },
+                "unacknowledged_employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.ScheduleChanges": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SnapshotShift"
+                    }
+                },
+                "affected_employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "baseline": {
+                    "type": "string"
+                },
+                "reassigned": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftChange"
+                    }
+                },
+                "removed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SnapshotShift"
+                    }
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "since": {
+                    "type": "string"
+                },
+                "time_changed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftChange"
+                    }
+                }
+            }
+        },
+        "main.ScheduleCoverage": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ScheduleCoverageDay"
+                    }
+                },
+                "schedule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ScheduleCoverageDay": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "holiday": {
+                    "description": "Holiday names the public holiday on the date, from the restaurant's special dates",
+                    "type": "string"
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "type": "integer"
+                },
+                "staffed": {
+                    "type": "integer"
+                },
+                "weather": {
+                    "$ref": "#/definitions/weather.Day"
+                }
+            }
+        },
+        "main.ScheduleDayNotePayload": {
+            "type": "object",
+            "required": [
+                "note"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "main.ScheduleEmailPreview": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "html": {
+                    "type": "string"
+                },
+                "locale": {
+                    "$ref": "#/definitions/i18n.Locale"
+                },
+                "preview": {
+                    "description": "Preview is always true; a preview is never sent or recorded as a delivery",
+                    "type": "boolean"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ScheduleEmailStatus": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "Counts tallies the latest email to each employee by status",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "employees": {
+                    "description": "Employees is the latest email to each employee",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.EmailDelivery"
+                    }
+                },
+                "history": {
+                    "description": "History is every email sent for the schedule, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.EmailDelivery"
+                    }
+                },
+                "schedule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SendMessagePayload": {
+            "type": "object",
+            "required": [
+                "body",
+                "subject"
+            ],
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "channels": {
+                    "description": "Channels defaults to email; in_app also posts each employee a notification",
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "critical": {
+                    "description": "Critical emails it straight away even to employees who get digests",
+                    "type": "boolean"
+                },
+                "employee_ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role_ids": {
+                    "description": "RoleIDs and EmployeeIDs narrow the recipients to the employees with one of the\nroles plus those listed; without either the message goes to everyone",
+                    "type": "array",
+                    "maxItems": 100,
+                    "uniqueItems": true,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "send_at": {
+                    "description": "SendAt schedules the message; omitted or in the past sends it now",
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string",
+                    "maxLength": 200
+                }
+            }
+        },
+        "main.SendMessageResponse": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "description": "Failures are the emails that couldn't be sent, for a message sent now",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SendScheduleEmailFailure"
+                    }
+                },
+                "message": {
+                    "$ref": "#/definitions/store.Message"
+                },
+                "queued": {
+                    "description": "Queued counts the emails held for employees' digests",
+                    "type": "integer"
+                },
+                "quota": {
+                    "$ref": "#/definitions/cache.EmailQuota"
+                }
+            }
+        },
+        "main.SendScheduleEmailFailure": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SendScheduleEmailPayload": {
+            "type": "object",
+            "properties": {
+                "include_events": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.SendScheduleEmailResponse": {
+            "type": "object",
+            "properties": {
+                "blast_id": {
+                    "description": "BlastID identifies the run in the schedule's email-blasts history",
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SendScheduleEmailFailure"
+                    }
+                },
+                "queued": {
+                    "description": "held for employees' digests",
+                    "type": "integer"
+                },
+                "quota": {
+                    "$ref": "#/definitions/cache.EmailQuota"
+                },
+                "successful": {
+                    "type": "integer"
+                },
+                "total_recipients": {
+                    "type": "integer"
+                },
+                "unverified_employee_ids": {
+                    "description": "UnverifiedEmployeeIDs are the recipients whose address isn't verified yet;\na restaurant that requires verification holds their emails as failures",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.SessionView": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SetEmployeeCertificationPayload": {
+            "type": "object",
+            "properties": {
+                "expires_on": {
+                    "type": "string"
+                },
+                "issued_on": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SetRoleCertificationsPayload": {
+            "type": "object",
+            "properties": {
+                "certification_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.ShareLinkWithToken": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "html_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_viewed_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.SharedSchedule": {
+            "type": "object",
+            "properties": {
+                "day_notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ScheduleDayNote"
+                    }
+                },
+                "end_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SharedShift"
+                    }
+                },
+                "start_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                }
+            }
+        },
+        "main.SharedShift": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "training": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.ShiftChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/store.SnapshotShift"
+                },
+                "before": {
+                    "$ref": "#/definitions/store.SnapshotShift"
+                },
+                "shift_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ShiftFeedbackGroup": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "type": "number"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "low_ratings": {
+                    "description": "LowRatings counts the ratings of 2 or less",
+                    "type": "integer"
+                },
+                "responses": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ShiftFeedbackPayload": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1
+                }
+            }
+        },
+        "main.ShiftFeedbackReport": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "type": "number"
+                },
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ShiftFeedbackEntry"
+                    }
+                },
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftFeedbackGroup"
+                    }
+                },
+                "from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "low_ratings": {
+                    "type": "integer"
+                },
+                "responses": {
+                    "type": "integer"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftFeedbackGroup"
+                    }
+                },
+                "to": {
+                    "$ref": "#/definitions/store.DateOnly"
+                }
+            }
+        },
+        "main.ShiftHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "Deleted is set when the shift is gone and only its history remains",
+                    "type": "boolean"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.AuditEntry"
+                    }
+                },
+                "published": {
+                    "description": "Published is the shift as employees were last published it, for settling disputes;\nomitted when the shift wasn't in the published version",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.SnapshotShift"
+                        }
+                    ]
+                },
+                "published_version": {
+                    "type": "integer"
+                },
+                "shift_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ShiftPage": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftResponse"
+                    }
+                }
+            }
+        },
+        "main.ShiftResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID links extra staffing to the event it covers",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "type": "string"
+                },
+                "shift_template_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "trainer_shift_id": {
+                    "type": "integer"
+                },
+                "training": {
+                    "description": "Training shifts let an employee work a role they don't hold yet, alongside the trainer's shift",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "description": "Warnings are computed for the request, never stored",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ShiftWarning"
+                    }
+                }
+            }
+        },
+        "main.ShiftTemplateDuplicates": {
+            "type": "object",
+            "properties": {
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "keep_id": {
+                    "description": "KeepID is the suggested template to merge the others into, the oldest one",
+                    "type": "integer"
+                },
+                "role_overlap": {
+                    "description": "RoleOverlap is the lowest share of roles any template has in common with the first, 1 when identical",
+                    "type": "number"
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ShiftTemplate"
+                    }
+                }
+            }
+        },
+        "main.ShiftTemplateMergeResult": {
+            "type": "object",
+            "properties": {
+                "merged_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "shifts_repointed": {
+                    "type": "integer"
+                },
+                "template": {
+                    "$ref": "#/definitions/store.ShiftTemplate"
+                }
+            }
+        },
+        "main.ShiftTemplateSuggestion": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "create_payload": {
+                    "$ref": "#/definitions/main.CreateShiftTemplatePayload"
+                },
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "typical_headcount": {
+                    "type": "integer"
+                },
+                "weeks_seen": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.StaffingGapReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "hiring_warnings": {
+                    "description": "HiringWarnings are the roles whose shifts repeatedly went unassigned while\nthe roster was short, for the dashboard",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.HiringWarning"
+                    }
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.RoleStaffingGap"
+                    }
+                },
+                "weeks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SyncResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Tombstone"
+                    }
+                },
+                "employees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Employee"
+                    }
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Event"
+                    }
+                },
+                "full": {
+                    "type": "boolean"
+                },
+                "next_shift_cursor": {
+                    "type": "string"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Role"
+                    }
+                },
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Schedule"
+                    }
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftResponse"
+                    }
+                }
+            }
+        },
+        "main.TwoFactorChallenge": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.TwoFactorCodePayload": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "main.TwoFactorEnrollment": {
+            "type": "object",
+            "properties": {
+                "otpauth_url": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.TwoFactorStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enrolled": {
+                    "type": "boolean"
+                },
+                "recovery_codes_left": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.UnreadCountResponse": {
+            "type": "object",
+            "properties": {
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.UpdateCertificationPayload": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "main.UpdateEmployeePayload": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "description": "Birthday and HireDate set a YYYY-MM-DD date; an empty string clears it too",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "external_id": {
+                    "description": "ExternalID sets the employee's ID in an HR system; an empty string clears it too",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "full_name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "hire_date": {
+                    "type": "string"
+                },
+                "hourly_rate_cents": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "es"
+                    ]
+                },
+                "notification_mode": {
+                    "description": "NotificationMode overrides the restaurant's for the employee; null follows the restaurant again",
+                    "type": "string",
+                    "enum": [
+                        "immediate",
+                        "daily",
+                        "shift_day"
+                    ]
+                },
+                "seniority": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "terminated_on": {
+                    "description": "TerminatedOn sets the YYYY-MM-DD day the employee left; an empty string clears it too",
+                    "type": "string"
+                }
+            }
+        },
+        "main.UpdateEventPayload": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                }
+            }
+        },
+        "main.UpdateLocalePayload": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "enum": [
+                        "en",
+                        "es"
+                    ]
+                }
+            }
+        },
+        "main.UpdateMemberPayload": {
+            "type": "object",
+            "required": [
+                "role_ids"
+            ],
+            "properties": {
+                "role_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.UpdateOperatingHoursPayload": {
+            "type": "object",
+            "required": [
+                "enforcement"
+            ],
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.OperatingDayPayload"
+                    }
+                },
+                "enforcement": {
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "block"
+                    ]
+                }
+            }
+        },
+        "main.UpdateRestaurantPayload": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "assignment_policy": {
+                    "description": "AssignmentPolicy ranks employees when shifts are assigned automatically",
+                    "type": "string",
+                    "enum": [
+                        "seniority_first",
+                        "rotate_fairly",
+                        "manual_only"
+                    ]
+                },
+                "digest_hour": {
+                    "description": "DigestHour is the hour (UTC) from which digests go out",
+                    "type": "integer",
+                    "maximum": 23,
+                    "minimum": 0
+                },
+                "holiday_region": {
+                    "description": "HolidayRegion (e.g. US, US-CA) picks whose public holidays are added to the special dates; an empty string turns it off",
+                    "type": "string"
+                },
+                "holidays_closed": {
+                    "description": "HolidaysClosed adds public holidays as closures rather than days on regular hours",
+                    "type": "boolean"
+                },
+                "latitude": {
+                    "description": "Latitude and Longitude locate the restaurant for schedule weather; they're set together",
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "notification_mode": {
+                    "description": "NotificationMode is how staff get shift changes and announcements: immediate, daily or shift_day",
+                    "type": "string",
+                    "enum": [
+                        "immediate",
+                        "daily",
+                        "shift_day"
+                    ]
+                },
+                "phone": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "require_email_verification": {
+                    "description": "RequireEmailVerification holds schedule emails to employees until they've verified their address",
+                    "type": "boolean"
+                },
+                "rotation_anchor": {
+                    "description": "RotationAnchor (YYYY-MM-DD) starts week a of the two-week shift template rotation; an empty string clears it",
+                    "type": "string"
+                },
+                "schedule_lock_hours": {
+                    "description": "ScheduleLockHours locks published shifts this many hours before they start; 0 turns it off",
+                    "type": "integer",
+                    "maximum": 168,
+                    "minimum": 0
+                },
+                "schedule_retention_months": {
+                    "description": "ScheduleRetentionMonths archives schedules that ended this many months ago; 0 turns it off",
+                    "type": "integer",
+                    "maximum": 120,
+                    "minimum": 0
+                },
+                "staff_milestone_digest": {
+                    "description": "StaffMilestoneDigest turns the owner's weekly birthday and work anniversary email on or off",
+                    "type": "boolean"
+                },
+                "timezone": {
+                    "description": "Timezone is the restaurant's IANA timezone, e.g. America/Chicago",
+                    "type": "string"
+                },
+                "week_start": {
+                    "description": "WeekStart is the day the restaurant's weeks begin, 0 (Sunday) to 6 (Saturday)",
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "weekly_labor_budget_cents": {
+                    "description": "WeeklyLaborBudgetCents is checked when schedules are published; 0 removes the budget",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "main.UpdateRolePayload": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "target_headcount": {
+                    "description": "TargetHeadcount of 0 removes the role's target",
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 0
+                }
+            }
+        },
+        "main.UpdateSchedulePayload": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "note": {
+                    "description": "Note replaces the schedule's note; an empty note clears it",
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "start_date": {
+                    "description": "YYYY-MM-DD",
+                    "type": "string"
+                },
+                "stranded_shifts": {
+                    "description": "StrandedShifts is what happens to shifts the new dates no longer cover:\nreject (the default) refuses the update listing them, delete deletes them",
+                    "type": "string",
+                    "enum": [
+                        "reject",
+                        "delete"
+                    ]
+                }
+            }
+        },
+        "main.UpdateShareLinkPayload": {
+            "type": "object",
+            "required": [
+                "expires_in_hours"
+            ],
+            "properties": {
+                "expires_in_hours": {
+                    "description": "ExpiresInHours counts from now, so it can extend an expired link",
+                    "type": "integer",
+                    "maximum": 2160,
+                    "minimum": 1
+                }
+            }
+        },
+        "main.UpdateShiftTemplatePayload": {
+            "type": "object",
+            "properties": {
+                "day_of_week": {
+                    "type": "integer",
+                    "maximum": 6,
+                    "minimum": 0
+                },
+                "effective_from": {
+                    "description": "EffectiveFrom and EffectiveUntil replace the template's window; an empty date opens that side",
+                    "type": "string"
+                },
+                "effective_until": {
+                    "type": "string"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "week_parity": {
+                    "description": "WeekParity replaces the template's rotation week (a or b); an empty string applies it every week",
+                    "type": "string"
+                }
+            }
+        },
+        "main.UpdateTwoFactorPayload": {
+            "type": "object",
+            "required": [
+                "code",
+                "enabled"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code is a current authenticator code or an unused recovery code",
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.UserWithToken": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "google_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.VerifyLoginPayload": {
+            "type": "object",
             "required": [
-                "employee_ids"
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "main.WeekdayDemand": {
+            "type": "object",
+            "properties": {
+                "average_covers": {
+                    "type": "number"
+                },
+                "average_sales_cents": {
+                    "type": "integer"
+                },
+                "average_scheduled_hours": {
+                    "type": "number"
+                },
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "days": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.assignEmployeeRequest": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "trainer_shift_id": {
+                    "type": "integer"
+                },
+                "training": {
+                    "description": "Training assigns the employee even if they don't have the shift's role yet",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.autoAssignResult": {
+            "type": "object",
+            "properties": {
+                "assigned_count": {
+                    "type": "integer"
+                },
+                "assignment_rule": {
+                    "$ref": "#/definitions/store.AssignmentPolicy"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftResponse"
+                    }
+                },
+                "unfilled_shift_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.autoPopulateDay": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "outside_operating_hours": {
+                    "type": "integer"
+                },
+                "shift_count": {
+                    "type": "integer"
+                },
+                "skipped_existing": {
+                    "type": "integer"
+                },
+                "skipped_outside_hours": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.autoPopulatePreview": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.autoPopulateDay"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "outside_operating_hours_count": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ShiftResponse"
+                    }
+                },
+                "skipped_existing": {
+                    "type": "integer"
+                },
+                "skipped_outside_hours": {
+                    "type": "integer"
+                },
+                "would_create_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.createScheduledShiftRequest": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID links the shift to the event it staffs; the shift must be on the event's date",
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "type": "string"
+                },
+                "shift_template_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.preCheckRisk": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "leave_conflict_shift_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "missing_certification": {
+                    "type": "integer"
+                },
+                "needed": {
+                    "description": "Needed counts the open, template and leave-conflicted shifts to staff",
+                    "type": "integer"
+                },
+                "on_leave_employee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "role_holders": {
+                    "description": "RoleHolders is everyone holding the role, before leave and certifications",
+                    "type": "integer"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "severity": {
+                    "type": "string"
+                },
+                "shift_ids": {
+                    "description": "ShiftIDs are the open shifts already on the schedule",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "template_shifts": {
+                    "type": "integer"
+                },
+                "unfilled": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.schedulePreCheck": {
+            "type": "object",
+            "properties": {
+                "at_risk_count": {
+                    "type": "integer"
+                },
+                "leave_conflicts": {
+                    "description": "LeaveConflicts are shifts assigned to an employee taking paid leave that day",
+                    "type": "integer"
+                },
+                "open_shifts": {
+                    "description": "OpenShifts are the schedule's shifts with no one assigned",
+                    "type": "integer"
+                },
+                "risks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.preCheckRisk"
+                    }
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "template_shifts": {
+                    "description": "TemplateShifts are the shifts auto-populate would still add from the templates",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.updateScheduledShiftRequest": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "EventID links the shift to an event; null or 0 unlinks it",
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "shift_template_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.ActivationStatus": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.AssignmentPolicy": {
+            "type": "string",
+            "enum": [
+                "seniority_first",
+                "rotate_fairly",
+                "manual_only"
+            ],
+            "x-enum-varnames": [
+                "AssignmentSeniorityFirst",
+                "AssignmentRotateFairly",
+                "AssignmentManualOnly"
+            ]
+        },
+        "store.AuditChange": {
+            "type": "object",
+            "properties": {
+                "from": {},
+                "to": {}
+            }
+        },
+        "store.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_name": {
+                    "description": "Joined from users; empty when the actor's account is gone",
+                    "type": "string"
+                },
+                "actor_user_id": {
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/store.AuditChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "entity_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.BidPreference": {
+            "type": "object",
+            "properties": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "round_id": {
+                    "type": "integer"
+                },
+                "shift_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.BidRound": {
+            "type": "object",
+            "properties": {
+                "allocated_at": {
+                    "type": "string"
+                },
+                "closes_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "seniority_weight": {
+                    "description": "SeniorityWeight is how many hours already won one point of seniority\nmakes up for when ordering who picks next; 0 orders by hours alone",
+                    "type": "number"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.BidRoundShift"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/store.BidRoundStatus"
+                }
+            }
+        },
+        "store.BidRoundShift": {
+            "type": "object",
+            "properties": {
+                "awarded_employee_id": {
+                    "type": "integer"
+                },
+                "awarded_rank": {
+                    "description": "AwardedRank is where the winner ranked the shift, 1 being their first choice",
+                    "type": "integer"
+                },
+                "shift_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.BidRoundStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "allocated"
+            ],
+            "x-enum-varnames": [
+                "BidRoundOpen",
+                "BidRoundAllocated"
+            ]
+        },
+        "store.BiddableShift": {
+            "type": "object",
+            "properties": {
+                "closes_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "round_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "shift_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.CalendarFeed": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Certification": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.CloneIDMap": {
+            "type": "object",
+            "properties": {
+                "certifications": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "employees": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "shift_templates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "store.ComplianceRules": {
+            "type": "object",
+            "properties": {
+                "consecutive_days_severity": {
+                    "$ref": "#/definitions/store.ComplianceSeverity"
+                },
+                "jurisdiction": {
+                    "description": "Jurisdiction is the template the rules started from",
+                    "type": "string"
+                },
+                "max_consecutive_days": {
+                    "type": "integer"
+                },
+                "min_rest_hours": {
+                    "type": "integer"
+                },
+                "minor_age": {
+                    "type": "integer"
+                },
+                "minor_latest_end": {
+                    "description": "MinorLatestEnd is when minors' shifts must end by; nil for no limit",
+                    "type": "string"
+                },
+                "minor_max_daily_hours": {
+                    "type": "integer"
+                },
+                "minor_max_weekly_hours": {
+                    "type": "integer"
+                },
+                "minor_severity": {
+                    "$ref": "#/definitions/store.ComplianceSeverity"
+                },
+                "rest_severity": {
+                    "$ref": "#/definitions/store.ComplianceSeverity"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is nil until the restaurant saves its own rules",
+                    "type": "string"
+                }
+            }
+        },
+        "store.ComplianceSeverity": {
+            "type": "string",
+            "enum": [
+                "off",
+                "warn",
+                "block"
+            ],
+            "x-enum-varnames": [
+                "ComplianceOff",
+                "ComplianceWarn",
+                "ComplianceBlock"
+            ]
+        },
+        "store.DateOnly": {
+            "type": "string",
+            "enum": [
+                "0001-01-01",
+                "9999-12-31"
+            ],
+            "x-enum-varnames": [
+                "MinShiftDate",
+                "MaxShiftDate"
+            ]
+        },
+        "store.DenormalizedRepair": {
+            "type": "object",
+            "properties": {
+                "employee_names": {
+                    "type": "integer"
+                },
+                "role_fields": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.DisplayBoard": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Document": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                },
+                "uploaded_by": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.DocumentAcknowledgment": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "signed_name": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EffectiveHours": {
+            "type": "object",
+            "properties": {
+                "close_time": {
+                    "type": "string"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "exception": {
+                    "type": "string"
+                },
+                "open_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EmailBlast": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.EmailBlastFailure"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "include_events": {
+                    "type": "boolean"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "retry_of": {
+                    "description": "RetryOf is the blast whose failed recipients this one re-sent to",
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "successful": {
+                    "type": "integer"
+                },
+                "total_recipients": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.EmailBlastFailure": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EmailDelivery": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "Detail is why the send failed or the message bounced or was dropped",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EmailTemplate": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "header_message": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Employee": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "description": "Birthday and HireDate feed the owner's weekly birthday and work anniversary digest; nil when not given",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "email_bounce_reason": {
+                    "type": "string"
+                },
+                "email_bounced_at": {
+                    "description": "EmailBouncedAt is set when mail to the address hard-bounced; schedule emails skip it until the email changes",
+                    "type": "string"
+                },
+                "email_verified_at": {
+                    "description": "EmailVerifiedAt is when the employee confirmed the address through a verification link; cleared when the email changes",
+                    "type": "string"
+                },
+                "erased_at": {
+                    "description": "ErasedAt is when the employee's personal data was erased",
+                    "type": "string"
+                },
+                "external_id": {
+                    "description": "ExternalID is the employee's ID in an HR system, unique within the restaurant; nil when not set",
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "hire_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "hourly_rate_cents": {
+                    "description": "prices the employee's shifts; nil when not set",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "notification_mode": {
+                    "description": "NotificationMode overrides the restaurant's notification_mode for the employee; nil follows it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.NotificationMode"
+                        }
+                    ]
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "seniority": {
+                    "description": "Seniority ranks the employee for automatic assignment under the seniority_first policy; higher goes first",
+                    "type": "integer"
+                },
+                "terminated_on": {
+                    "description": "TerminatedOn is the day the employee left; retention policies anonymize them a while after",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EmployeeCertification": {
+            "type": "object",
+            "properties": {
+                "certification_id": {
+                    "type": "integer"
+                },
+                "certification_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "expires_on": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "issued_on": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EmployeeErasure": {
+            "type": "object",
+            "properties": {
+                "certifications_deleted": {
+                    "type": "integer"
+                },
+                "documents_deleted": {
+                    "type": "integer"
+                },
+                "employee": {
+                    "$ref": "#/definitions/store.Employee"
+                },
+                "notifications_deleted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.EmployeeNote": {
+            "type": "object",
+            "properties": {
+                "author_name": {
+                    "description": "Joined from users; empty when the author's account is gone",
+                    "type": "string"
+                },
+                "author_user_id": {
+                    "type": "integer"
+                },
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.EmployeeShift": {
+            "type": "object",
+            "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "feedback_rating": {
+                    "description": "FeedbackRating is the rating the employee gave the shift after working it",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "training": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "store.Event": {
+            "type": "object",
+            "properties": {
+                "coverage": {
+                    "$ref": "#/definitions/store.EventCoverage"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "employees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.Employee"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "description": "Shifts are the scheduled shifts linked to the event for extra staffing",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.ScheduledShift"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.EventCoverage": {
+            "type": "object",
+            "properties": {
+                "open_shifts": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "type": "integer"
+                },
+                "staff": {
+                    "description": "Staff counts each employee once, whether assigned to the event, a linked shift or both",
+                    "type": "integer"
+                },
+                "staffed_shifts": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.HoursEnforcement": {
+            "type": "string",
+            "enum": [
+                "off",
+                "warn",
+                "block"
             ],
+            "x-enum-varnames": [
+                "HoursEnforcementOff",
+                "HoursEnforcementWarn",
+                "HoursEnforcementBlock"
+            ]
+        },
+        "store.HoursException": {
+            "type": "object",
             "properties": {
-                "employee_ids": {
+                "close_time": {
+                    "type": "string"
+                },
+                "closed": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "holiday_region": {
+                    "description": "HolidayRegion is set on public holidays added from the region's calendar. Those\nthat aren't closures have no times: the weekly hours apply, under the holiday's name.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "open_time": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Kiosk": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.KioskEmployee": {
+            "type": "object",
+            "properties": {
+                "clocked_in_at": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.LeaveBalance": {
+            "type": "object",
+            "properties": {
+                "accrued": {
+                    "type": "number"
+                },
+                "adjusted": {
+                    "type": "number"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "type": "string"
+                },
+                "paid": {
+                    "type": "number"
+                }
+            }
+        },
+        "store.LeaveBasis": {
+            "type": "string",
+            "enum": [
+                "worked",
+                "scheduled"
+            ],
+            "x-enum-varnames": [
+                "LeaveBasisWorked",
+                "LeaveBasisScheduled"
+            ]
+        },
+        "store.LeaveEntry": {
+            "type": "object",
+            "properties": {
+                "basis_hours": {
+                    "description": "BasisHours are the hours worked or scheduled an accrual was earned on",
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "date": {
+                    "description": "Date is the Monday of the week accrued, or the day of leave paid",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "hours": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/store.LeaveEntryKind"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.LeaveEntryKind": {
+            "type": "string",
+            "enum": [
+                "accrual",
+                "paid",
+                "adjustment"
+            ],
+            "x-enum-varnames": [
+                "LeaveAccrual",
+                "LeavePaid",
+                "LeaveAdjustment"
+            ]
+        },
+        "store.LeavePolicy": {
+            "type": "object",
+            "properties": {
+                "accrual_hours": {
+                    "type": "number"
+                },
+                "accrue_from": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "basis": {
+                    "$ref": "#/definitions/store.LeaveBasis"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "max_balance_hours": {
+                    "description": "MaxBalanceHours caps the balance accruals build up to; 0 for no cap",
+                    "type": "number"
+                },
+                "per_hours": {
+                    "type": "number"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is nil until the restaurant saves its own policy",
+                    "type": "string"
+                }
+            }
+        },
+        "store.Member": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Membership": {
+            "type": "object",
+            "properties": {
+                "permission": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "role_ids": {
                     "type": "array",
                     "items": {
                         "type": "integer"
@@ -3181,522 +17821,814 @@ const docTemplate = `{
                 }
             }
         },
-        "main.CreateEmployeePayload": {
-            "type": "object",
-            "required": [
-                "email",
-                "full_name"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255
-                },
-                "full_name": {
-                    "type": "string",
-                    "maxLength": 255
-                }
-            }
-        },
-        "main.CreateEventPayload": {
+        "store.Message": {
             "type": "object",
-            "required": [
-                "date",
-                "end_time",
-                "start_time",
-                "title"
-            ],
             "properties": {
-                "date": {
+                "body": {
                     "type": "string"
                 },
-                "description": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
                     "type": "string"
                 },
+                "created_by": {
+                    "type": "integer"
+                },
+                "critical": {
+                    "description": "Critical emails the message straight away even to employees who get digests",
+                    "type": "boolean"
+                },
                 "employee_ids": {
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
-                "end_time": {
+                "failed": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "recipients": {
+                    "description": "Recipients counts the employees the message went to, Failed the emails to them that couldn't be sent",
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "send_at": {
                     "type": "string"
                 },
-                "start_time": {
+                "sent_at": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "status": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
                 }
             }
         },
-        "main.CreateRestaurantPayload": {
+        "store.Notification": {
             "type": "object",
-            "required": [
-                "address",
-                "name"
-            ],
             "properties": {
-                "address": {
-                    "type": "string",
-                    "maxLength": 500
+                "body": {
+                    "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 255
+                "created_at": {
+                    "type": "string"
                 },
-                "phone": {
-                    "type": "string",
-                    "maxLength": 20
+                "data": {
+                    "type": "object"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "main.CreateRolePayload": {
-            "type": "object",
-            "required": [
-                "name"
+        "store.NotificationMode": {
+            "type": "string",
+            "enum": [
+                "immediate",
+                "daily",
+                "shift_day"
             ],
+            "x-enum-varnames": [
+                "NotifyImmediately",
+                "NotifyDaily",
+                "NotifyShiftDay"
+            ]
+        },
+        "store.OperatingDay": {
+            "type": "object",
             "properties": {
-                "color": {
+                "close_time": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 50
+                "closed": {
+                    "type": "boolean"
+                },
+                "day_of_week": {
+                    "type": "integer"
+                },
+                "open_time": {
+                    "type": "string"
                 }
             }
         },
-        "main.CreateSchedulePayload": {
+        "store.OperatingHours": {
             "type": "object",
-            "required": [
-                "end_date",
-                "start_date"
-            ],
             "properties": {
-                "end_date": {
-                    "description": "YYYY-MM-DD",
-                    "type": "string"
+                "days": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.OperatingDay"
+                    }
                 },
-                "start_date": {
-                    "description": "YYYY-MM-DD",
-                    "type": "string"
+                "enforcement": {
+                    "$ref": "#/definitions/store.HoursEnforcement"
+                },
+                "restaurant_id": {
+                    "type": "integer"
                 }
             }
         },
-        "main.CreateShiftTemplatePayload": {
+        "store.Restaurant": {
             "type": "object",
-            "required": [
-                "end_time",
-                "name",
-                "start_time"
-            ],
             "properties": {
-                "day_of_week": {
-                    "type": "integer",
-                    "maximum": 6,
-                    "minimum": 0
+                "address": {
+                    "type": "string"
                 },
-                "end_time": {
+                "archived_at": {
+                    "description": "ArchivedAt is set while the restaurant is archived: hidden from lists and read-only",
+                    "type": "string"
+                },
+                "assignment_policy": {
+                    "description": "AssignmentPolicy ranks employees when shifts are assigned automatically",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.AssignmentPolicy"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "digest_hour": {
+                    "description": "DigestHour is the hour of the day (UTC) from which digests go out",
+                    "type": "integer"
+                },
+                "employer_id": {
+                    "type": "integer"
+                },
+                "exported_at": {
+                    "description": "ExportedAt is when the restaurant's data was last exported in full",
+                    "type": "string"
+                },
+                "holiday_region": {
+                    "description": "HolidayRegion is the country, or country-subdivision, whose public holidays are added\nto the restaurant's special dates each year, e.g. US or US-CA; empty turns it off",
                     "type": "string"
                 },
+                "holidays_closed": {
+                    "description": "HolidaysClosed adds public holidays as closures rather than days on regular hours",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "latitude": {
+                    "description": "Latitude and Longitude locate the restaurant for weather forecasts; both or neither are set",
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                },
                 "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                    "type": "string"
                 },
-                "notes": {
+                "notification_mode": {
+                    "description": "NotificationMode is how staff get shift changes and announcements by email; employees can override it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.NotificationMode"
+                        }
+                    ]
+                },
+                "phone": {
+                    "description": "Optional field",
                     "type": "string"
                 },
-                "role_ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "require_email_verification": {
+                    "description": "RequireEmailVerification holds schedule emails to employees until they've verified their address",
+                    "type": "boolean"
                 },
-                "start_time": {
+                "rotation_anchor": {
+                    "description": "RotationAnchor starts week A of the restaurant's alternating two-week shift template rotation",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "schedule_lock_hours": {
+                    "description": "ScheduleLockHours locks published shifts from edits this many hours before they start; 0 is off",
+                    "type": "integer"
+                },
+                "schedule_retention_months": {
+                    "description": "ScheduleRetentionMonths archives schedules that ended this many months ago; nil keeps them listed",
+                    "type": "integer"
+                },
+                "staff_milestone_digest": {
+                    "description": "StaffMilestoneDigest emails the owner the coming week's staff birthdays and work anniversaries",
+                    "type": "boolean"
+                },
+                "timezone": {
+                    "description": "Timezone is the restaurant's IANA timezone, e.g. America/Chicago; moments shown to staff are in it",
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                },
+                "week_start": {
+                    "description": "WeekStart is the day the restaurant's weeks begin, 0 (Sunday) to 6 (Saturday)",
+                    "type": "integer"
+                },
+                "weekly_labor_budget_cents": {
+                    "description": "WeeklyLaborBudgetCents caps a week's projected labor cost at publish time; nil is no budget",
+                    "type": "integer"
                 }
             }
         },
-        "main.CreateUserTokenPayload": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
+        "store.RetentionKind": {
+            "type": "string",
+            "enum": [
+                "anonymize_terminated_employees",
+                "delete_time_entries"
             ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255
-                },
-                "password": {
-                    "type": "string",
-                    "maxLength": 72,
-                    "minLength": 3
-                }
-            }
+            "x-enum-varnames": [
+                "RetentionAnonymizeTerminated",
+                "RetentionDeleteTimeEntries"
+            ]
         },
-        "main.GoogleCallbackPayload": {
+        "store.RetentionPolicy": {
             "type": "object",
-            "required": [
-                "code",
-                "state"
-            ],
             "properties": {
-                "code": {
+                "after_months": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "state": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/store.RetentionKind"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "main.GoogleLoginResponse": {
+        "store.Role": {
             "type": "object",
             "properties": {
-                "auth_url": {
+                "color": {
                     "type": "string"
                 },
-                "state": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "target_headcount": {
+                    "description": "TargetHeadcount is how many employees the restaurant wants able to work the role; nil has no target",
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "main.RegisterUserPayload": {
+        "store.RoleUsage": {
             "type": "object",
-            "required": [
-                "email",
-                "first_name",
-                "last_name",
-                "password"
-            ],
             "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255
+                "employees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.RoleUsageEmployee"
+                    }
                 },
-                "first_name": {
-                    "type": "string",
-                    "maxLength": 255
+                "future_shifts": {
+                    "description": "FutureShifts are the role's shifts from today on; PastShifts the ones before",
+                    "type": "integer"
                 },
-                "last_name": {
-                    "type": "string",
-                    "maxLength": 255
+                "past_shifts": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "maxLength": 72,
-                    "minLength": 3
-                }
-            }
-        },
-        "main.ResendConfirmationPayload": {
-            "type": "object",
-            "required": [
-                "email"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255
+                "role_id": {
+                    "type": "integer"
+                },
+                "shift_templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.RoleUsageTemplate"
+                    }
                 }
             }
         },
-        "main.SendScheduleEmailFailure": {
+        "store.RoleUsageEmployee": {
             "type": "object",
             "properties": {
-                "email": {
+                "full_name": {
                     "type": "string"
                 },
-                "employee_id": {
+                "id": {
                     "type": "integer"
-                },
-                "employee_name": {
-                    "type": "string"
-                },
-                "error": {
-                    "type": "string"
                 }
             }
         },
-        "main.SendScheduleEmailPayload": {
+        "store.RoleUsageTemplate": {
             "type": "object",
             "properties": {
-                "include_events": {
-                    "type": "boolean"
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "main.SendScheduleEmailResponse": {
+        "store.SalesRecord": {
             "type": "object",
             "properties": {
-                "failed": {
+                "covers": {
                     "type": "integer"
                 },
-                "failures": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/main.SendScheduleEmailFailure"
-                    }
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
                 },
-                "successful": {
+                "hour": {
                     "type": "integer"
                 },
-                "total_recipients": {
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "sales_cents": {
                     "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdateEmployeePayload": {
+        "store.SampleDataResult": {
             "type": "object",
             "properties": {
-                "email": {
-                    "type": "string",
-                    "maxLength": 255
+                "employees": {
+                    "type": "integer"
                 },
-                "full_name": {
-                    "type": "string",
-                    "maxLength": 255
+                "roles": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shift_templates": {
+                    "type": "integer"
                 }
             }
         },
-        "main.UpdateEventPayload": {
+        "store.SavedReport": {
             "type": "object",
             "properties": {
-                "date": {
+                "created_at": {
                     "type": "string"
                 },
-                "description": {
+                "created_by": {
+                    "type": "integer"
+                },
+                "delivery": {
                     "type": "string"
                 },
+                "delivery_weekday": {
+                    "type": "integer"
+                },
                 "employee_ids": {
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
-                "end_time": {
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
                     "type": "string"
                 },
-                "start_time": {
+                "last_sent_on": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "name": {
                     "type": "string"
                 },
-                "title": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "period_days": {
+                    "description": "PeriodDays is how many days the report covers, up to the day before it runs",
+                    "type": "integer"
+                },
+                "recipients": {
+                    "description": "Recipients are emailed the report; empty for the restaurant's owner",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role_ids": {
+                    "description": "RoleIDs and EmployeeIDs narrow the shifts reported on; empty for all",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdateRestaurantPayload": {
+        "store.Schedule": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string",
-                    "maxLength": 255
+                "archived_at": {
+                    "description": "ArchivedAt is set once the schedule is archived: left out of the schedule list but kept",
+                    "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 255
+                "created_at": {
+                    "type": "string"
                 },
-                "phone": {
-                    "type": "string",
-                    "maxLength": 20
+                "end_date": {
+                    "description": "DateOnly auto-normalizes to YYYY-MM-DD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "description": "Note is shown with the whole week, e.g. \"Spring break week\"",
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "start_date": {
+                    "description": "DateOnly auto-normalizes to YYYY-MM-DD",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdateRolePayload": {
+        "store.ScheduleDayNote": {
             "type": "object",
             "properties": {
-                "color": {
+                "created_at": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 50
+                "date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "main.UpdateSchedulePayload": {
+        "store.ScheduleSnapshot": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "description": "YYYY-MM-DD",
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "start_date": {
-                    "description": "YYYY-MM-DD",
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shifts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/store.SnapshotShift"
+                    }
+                },
+                "taken_at": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version counts the schedule's publishes from 1; only set on published snapshots",
+                    "type": "integer"
                 }
             }
         },
-        "main.UpdateShiftTemplatePayload": {
+        "store.ScheduledShift": {
             "type": "object",
             "properties": {
-                "day_of_week": {
-                    "type": "integer",
-                    "maximum": 6,
-                    "minimum": 0
+                "created_at": {
+                    "type": "string"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
+                    "description": "Denormalized fields (stored in DB, synced via triggers)",
+                    "type": "string"
                 },
                 "end_time": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "maxLength": 255,
-                    "minLength": 1
+                "event_id": {
+                    "description": "EventID links extra staffing to the event it covers",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
                 },
                 "notes": {
                     "type": "string"
                 },
-                "role_ids": {
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "role_color": {
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "integer"
+                },
+                "role_name": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "shift_date": {
+                    "type": "string"
+                },
+                "shift_template_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "trainer_shift_id": {
+                    "type": "integer"
+                },
+                "training": {
+                    "description": "Training shifts let an employee work a role they don't hold yet, alongside the trainer's shift",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "description": "Computed on request (?include_conflicts=true), never stored",
                     "type": "array",
                     "items": {
-                        "type": "integer"
+                        "$ref": "#/definitions/store.ShiftWarning"
                     }
-                },
-                "start_time": {
-                    "type": "string"
                 }
             }
         },
-        "main.UserWithToken": {
+        "store.SecurityEvent": {
             "type": "object",
             "properties": {
-                "avatar_url": {
+                "created_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "id": {
+                    "type": "integer"
+                },
+                "ip_address": {
                     "type": "string"
                 },
-                "email": {
+                "session_id": {
+                    "type": "integer"
+                },
+                "type": {
                     "type": "string"
                 },
-                "first_name": {
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.ShareLink": {
+            "type": "object",
+            "properties": {
+                "created_at": {
                     "type": "string"
                 },
-                "google_id": {
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "last_name": {
+                "last_viewed_at": {
                     "type": "string"
                 },
-                "token": {
-                    "type": "string"
+                "restaurant_id": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "revoked_at": {
                     "type": "string"
+                },
+                "schedule_id": {
+                    "type": "integer"
                 }
             }
         },
-        "main.assignEmployeeRequest": {
+        "store.ShiftAcknowledgment": {
             "type": "object",
             "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
                 "employee_id": {
                     "type": "integer"
+                },
+                "shift_id": {
+                    "type": "integer"
                 }
             }
         },
-        "main.createScheduledShiftRequest": {
+        "store.ShiftAcknowledgmentStatus": {
             "type": "object",
             "properties": {
+                "acknowledged_at": {
+                    "type": "string"
+                },
                 "employee_id": {
                     "type": "integer"
                 },
+                "employee_name": {
+                    "type": "string"
+                },
                 "end_time": {
                     "type": "string"
                 },
-                "notes": {
+                "role_color": {
                     "type": "string"
                 },
-                "role_id": {
-                    "type": "integer"
+                "role_name": {
+                    "type": "string"
                 },
                 "shift_date": {
-                    "type": "string"
+                    "$ref": "#/definitions/store.DateOnly"
                 },
-                "shift_template_id": {
+                "shift_id": {
                     "type": "integer"
                 },
                 "start_time": {
                     "type": "string"
+                },
+                "training": {
+                    "type": "boolean"
                 }
             }
         },
-        "main.updateScheduledShiftRequest": {
+        "store.ShiftFeedback": {
             "type": "object",
             "properties": {
-                "employee_id": {
-                    "type": "integer"
-                },
-                "end_time": {
+                "comment": {
                     "type": "string"
                 },
-                "notes": {
+                "created_at": {
                     "type": "string"
                 },
-                "role_id": {
+                "employee_id": {
                     "type": "integer"
                 },
-                "shift_date": {
-                    "type": "string"
+                "rating": {
+                    "type": "integer"
                 },
-                "shift_template_id": {
+                "restaurant_id": {
                     "type": "integer"
                 },
-                "start_time": {
+                "shift_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "store.Employee": {
+        "store.ShiftFeedbackEntry": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "comment": {
                     "type": "string"
                 },
-                "email": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
                     "type": "string"
                 },
-                "full_name": {
+                "end_time": {
                     "type": "string"
                 },
-                "id": {
+                "rating": {
                     "type": "integer"
                 },
-                "restaurant_id": {
+                "role_name": {
+                    "type": "string"
+                },
+                "shift_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "shift_id": {
                     "type": "integer"
                 },
+                "start_time": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "store.Event": {
+        "store.ShiftTemplate": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "date": {
-                    "type": "string"
+                "day_of_week": {
+                    "type": "integer"
                 },
-                "description": {
-                    "type": "string"
+                "effective_from": {
+                    "description": "A seasonal template only applies from EffectiveFrom to EffectiveUntil, inclusive; nil leaves that side open",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.DateOnly"
+                        }
+                    ]
                 },
-                "employees": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/store.Employee"
-                    }
+                "effective_until": {
+                    "$ref": "#/definitions/store.DateOnly"
                 },
                 "end_time": {
                     "type": "string"
@@ -3704,188 +18636,267 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
                 "restaurant_id": {
                     "type": "integer"
                 },
-                "start_time": {
-                    "type": "string"
+                "role_ids": {
+                    "description": "Stored as JSONB column",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "title": {
+                "start_time": {
                     "type": "string"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "week_parity": {
+                    "description": "WeekParity limits the template to week A or B of the restaurant's two-week rotation; nil is every week",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/store.WeekParity"
+                        }
+                    ]
                 }
             }
         },
-        "store.Restaurant": {
+        "store.ShiftWarning": {
+            "type": "string",
+            "enum": [
+                "double_booked",
+                "overtime_risk",
+                "role_mismatch",
+                "certification_expired",
+                "outside_operating_hours",
+                "compliance"
+            ],
+            "x-enum-varnames": [
+                "WarningDoubleBooked",
+                "WarningOvertimeRisk",
+                "WarningRoleMismatch",
+                "WarningCertificationExpired",
+                "WarningOutsideHours",
+                "WarningCompliance"
+            ]
+        },
+        "store.SnapshotShift": {
             "type": "object",
             "properties": {
-                "address": {
+                "employee_id": {
+                    "type": "integer"
+                },
+                "employee_name": {
                     "type": "string"
                 },
-                "created_at": {
+                "end_time": {
                     "type": "string"
                 },
-                "employer_id": {
+                "id": {
                     "type": "integer"
                 },
-                "id": {
+                "role_color": {
+                    "type": "string"
+                },
+                "role_id": {
                     "type": "integer"
                 },
-                "name": {
+                "role_name": {
                     "type": "string"
                 },
-                "phone": {
-                    "description": "Optional field",
+                "shift_date": {
+                    "$ref": "#/definitions/store.DateOnly"
+                },
+                "start_time": {
                     "type": "string"
                 },
-                "updated_at": {
+                "trainer_name": {
                     "type": "string"
                 },
-                "version": {
-                    "type": "integer"
+                "training": {
+                    "type": "boolean"
                 }
             }
         },
-        "store.Role": {
+        "store.TimeEntry": {
             "type": "object",
             "properties": {
-                "color": {
+                "clock_in_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "clock_out_at": {
                     "type": "string"
                 },
-                "id": {
+                "employee_id": {
                     "type": "integer"
                 },
-                "name": {
+                "employee_name": {
                     "type": "string"
                 },
-                "restaurant_id": {
+                "id": {
                     "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "kiosk_id": {
+                    "type": "integer"
+                },
+                "restaurant_id": {
+                    "type": "integer"
                 }
             }
         },
-        "store.Schedule": {
+        "store.Tombstone": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "deleted_at": {
                     "type": "string"
                 },
-                "end_date": {
-                    "description": "DateOnly auto-normalizes to YYYY-MM-DD",
+                "entity": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
-                },
-                "published_at": {
+                }
+            }
+        },
+        "store.VerifiedEmail": {
+            "type": "object",
+            "properties": {
+                "email": {
                     "type": "string"
                 },
-                "restaurant_id": {
+                "employee_id": {
                     "type": "integer"
                 },
-                "start_date": {
-                    "description": "DateOnly auto-normalizes to YYYY-MM-DD",
+                "employee_name": {
                     "type": "string"
                 },
-                "updated_at": {
+                "restaurant_name": {
+                    "type": "string"
+                },
+                "verified_at": {
                     "type": "string"
                 }
             }
         },
-        "store.ScheduledShift": {
+        "store.WebhookDelivery": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "employee_id": {
+                "attempts": {
                     "type": "integer"
                 },
-                "employee_name": {
-                    "description": "Denormalized fields (stored in DB, synced via triggers)",
+                "created_at": {
                     "type": "string"
                 },
-                "end_time": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "id": {
+                "endpoint_id": {
                     "type": "integer"
                 },
-                "notes": {
+                "event_id": {
                     "type": "string"
                 },
-                "restaurant_id": {
-                    "type": "integer"
-                },
-                "role_color": {
+                "event_type": {
                     "type": "string"
                 },
-                "role_id": {
+                "id": {
                     "type": "integer"
                 },
-                "role_name": {
+                "last_error": {
                     "type": "string"
                 },
-                "schedule_id": {
-                    "type": "integer"
-                },
-                "shift_date": {
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "shift_template_id": {
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
                     "type": "integer"
                 },
-                "start_time": {
-                    "type": "string"
+                "restaurant_id": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "store.ShiftTemplate": {
+        "store.WebhookEndpoint": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "day_of_week": {
+                "created_by": {
                     "type": "integer"
                 },
-                "end_time": {
-                    "type": "string"
+                "events": {
+                    "description": "Events are the event types sent; empty for all of them",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "restaurant_id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "Secret signs deliveries; it's only shown when the endpoint is created",
                     "type": "string"
                 },
-                "notes": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.WeekParity": {
+            "type": "string",
+            "enum": [
+                "a",
+                "b"
+            ],
+            "x-enum-varnames": [
+                "WeekA",
+                "WeekB"
+            ]
+        },
+        "weather.Day": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "YYYY-MM-DD, local to the location",
                     "type": "string"
                 },
-                "restaurant_id": {
-                    "type": "integer"
+                "high_c": {
+                    "type": "number"
                 },
-                "role_ids": {
-                    "description": "Stored as JSONB column",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
+                "low_c": {
+                    "type": "number"
                 },
-                "start_time": {
-                    "type": "string"
+                "patio_weather": {
+                    "description": "PatioWeather is set on mild, dry, calm days, when outdoor seating is likely to be busy",
+                    "type": "boolean"
                 },
-                "updated_at": {
+                "precipitation_chance": {
+                    "description": "PrecipitationChance is the highest chance of rain or snow during the day, in percent",
+                    "type": "integer"
+                },
+                "summary": {
                     "type": "string"
+                },
+                "wind_kph": {
+                    "type": "number"
                 }
             }
         }
@@ -3895,6 +18906,12 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "KioskAuth": {
+            "description": "\"Kiosk \u003ctoken\u003e\" of a registered time clock kiosk",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
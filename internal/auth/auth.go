@@ -5,4 +5,6 @@ import "github.com/golang-jwt/jwt/v5"
 type Authenticator interface {
 	GenerateToken(claims jwt.Claims) (string, error)
 	ValidateToken(token string) (*jwt.Token, error)
+	// PublicKeys lists the keys other services can verify tokens with
+	PublicKeys() *JWKSet
 }
\ No newline at end of file
@@ -6,21 +6,59 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// JWTAuthenticator signs tokens with one key and accepts tokens signed by any of its
+// verification keys, which is how keys are rotated: the new key signs, the old ones
+// keep verifying until the tokens they signed have expired.
 type JWTAuthenticator struct {
-	secret string 
-	aud string 
-	iss string
+	signing Key
+	keys    map[string]Key
+	methods []string
+	aud     string
+	iss     string
 }
 
+// NewJWTAuthenticator signs and verifies HS256 tokens with a single shared secret
 func NewJWTAuthenticator(secret, aud, iss string) *JWTAuthenticator {
-	return &JWTAuthenticator{secret, iss, aud}
+	key := HMACKey("", secret)
+	return &JWTAuthenticator{
+		signing: key,
+		keys:    map[string]Key{key.ID: key},
+		methods: []string{jwt.SigningMethodHS256.Alg()},
+		aud:     aud,
+		iss:     iss,
+	}
 }
 
+// NewJWTAuthenticatorWithKeys signs with the signing key and also verifies with the
+// retired keys. Key IDs must be unique; the ID is sent as the token's kid header.
+func NewJWTAuthenticatorWithKeys(signing Key, retired []Key, aud, iss string) (*JWTAuthenticator, error) {
+	if !signing.canSign() {
+		return nil, fmt.Errorf("signing key %q has no private part", signing.ID)
+	}
+
+	a := &JWTAuthenticator{signing: signing, keys: map[string]Key{}, aud: aud, iss: iss}
+	for _, key := range append([]Key{signing}, retired...) {
+		if _, ok := a.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate token key id %q", key.ID)
+		}
+		a.keys[key.ID] = key
+
+		method := key.method().Alg()
+		if !contains(a.methods, method) {
+			a.methods = append(a.methods, method)
+		}
+	}
+
+	return a, nil
+}
 
 func (a *JWTAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(a.signing.method(), claims)
+	if a.signing.ID != "" {
+		token.Header["kid"] = a.signing.ID
+	}
 
-	tokenString, err := token.SignedString([]byte(a.secret))
+	tokenString, err := token.SignedString(a.signing.signingKey())
 	if err != nil {
 		return "", err
 	}
@@ -30,15 +68,53 @@ func (a *JWTAuthenticator) GenerateToken(claims jwt.Claims) (string, error) {
 
 func (a *JWTAuthenticator) ValidateToken(token string) (*jwt.Token, error) {
 	return jwt.Parse(token, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		// tokens from before key IDs were used have none, and match the key without one
+		kid, _ := t.Header["kid"].(string)
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown token key id %q", kid)
+		}
+
+		// a key only verifies its own algorithm, so a public RSA key can't be used as an HMAC secret
+		if t.Method.Alg() != key.method().Alg() {
 			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
 		}
 
-		return []byte(a.secret), nil
+		return key.verificationKey(), nil
 	},
 		jwt.WithExpirationRequired(),
 		jwt.WithAudience(a.aud),
-		jwt.WithIssuer(a.aud),
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
+		jwt.WithIssuer(a.iss),
+		jwt.WithValidMethods(a.methods),
 	)
-}
\ No newline at end of file
+}
+
+// PublicKeys returns the RSA verification keys as a JSON Web Key Set, so other services
+// can verify tokens without the secret. Shared HMAC secrets are never included.
+func (a *JWTAuthenticator) PublicKeys() *JWKSet {
+	set := &JWKSet{Keys: []JWK{}}
+
+	// the signing key first, then the rest in a stable order
+	if jwk, ok := a.signing.jwk(); ok {
+		set.Keys = append(set.Keys, jwk)
+	}
+	for _, id := range sortedKeys(a.keys) {
+		if id == a.signing.ID {
+			continue
+		}
+		if jwk, ok := a.keys[id].jwk(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+
+	return set
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func testTokenClaims(aud string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": 1,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iss": "RESA",
+		"aud": aud,
+	}
+}
+
+func newRSAKey(t *testing.T, id string) Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(private)})
+	key, err := ParseRSAKey(id, pemBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestJWTAudience(t *testing.T) {
+	a := NewJWTAuthenticator("secret", "app", "RESA")
+
+	token, err := a.GenerateToken(testTokenClaims("app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ValidateToken(token); err != nil {
+		t.Errorf("token for the audience: %v", err)
+	}
+
+	other, _ := a.GenerateToken(testTokenClaims("RESA"))
+	if _, err := a.ValidateToken(other); err == nil {
+		t.Error("a token for another audience was accepted")
+	}
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	legacy := NewJWTAuthenticator("old-secret", "RESA", "RESA")
+	oldToken, _ := legacy.GenerateToken(testTokenClaims("RESA"))
+
+	rotated, err := NewJWTAuthenticatorWithKeys(HMACKey("2026-10", "new-secret"), []Key{HMACKey("", "old-secret")}, "RESA", "RESA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, _ := rotated.GenerateToken(testTokenClaims("RESA"))
+
+	parsed, err := rotated.ValidateToken(newToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "2026-10" {
+		t.Errorf("kid = %v, want 2026-10", parsed.Header["kid"])
+	}
+	if _, err := rotated.ValidateToken(oldToken); err != nil {
+		t.Errorf("token signed before the rotation: %v", err)
+	}
+
+	// once the old key is dropped its tokens stop working
+	retired, _ := NewJWTAuthenticatorWithKeys(HMACKey("2026-10", "new-secret"), nil, "RESA", "RESA")
+	if _, err := retired.ValidateToken(oldToken); err == nil {
+		t.Error("a token signed by a dropped key was accepted")
+	}
+
+	if _, err := NewJWTAuthenticatorWithKeys(HMACKey("a", "x"), []Key{HMACKey("a", "y")}, "RESA", "RESA"); err == nil {
+		t.Error("duplicate key ids were accepted")
+	}
+}
+
+func TestJWTRS256(t *testing.T) {
+	key := newRSAKey(t, "")
+	if key.ID == "" {
+		t.Fatal("key without an id wasn't named by its thumbprint")
+	}
+
+	signer, err := NewJWTAuthenticatorWithKeys(key, []Key{HMACKey("", "secret")}, "RESA", "RESA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.GenerateToken(testTokenClaims("RESA"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another service verifies with only the public key, which can't sign
+	public := Key{ID: key.ID, PublicKey: &key.PrivateKey.PublicKey}
+	if _, err := NewJWTAuthenticatorWithKeys(public, nil, "RESA", "RESA"); err == nil {
+		t.Fatal("a public key was accepted for signing")
+	}
+	verifier, err := NewJWTAuthenticatorWithKeys(HMACKey("local", "unused"), []Key{public}, "RESA", "RESA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.ValidateToken(token); err != nil {
+		t.Errorf("RS256 token verified with the public key: %v", err)
+	}
+
+	// an HS256 token claiming the RSA key's kid must not be checked against it
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testTokenClaims("RESA"))
+	forged.Header["kid"] = key.ID
+	forgedString, _ := forged.SignedString([]byte("anything"))
+	if _, err := signer.ValidateToken(forgedString); err == nil {
+		t.Error("an HS256 token was accepted for an RS256 key")
+	}
+
+	jwks := signer.PublicKeys()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != key.ID || jwks.Keys[0].Algorithm != "RS256" {
+		t.Errorf("jwks = %+v, want only the RSA key", jwks)
+	}
+}
+
+func TestParseKeyList(t *testing.T) {
+	keys, err := ParseKeyList("a:one, b:two,", func(id, value string) (Key, error) {
+		return HMACKey(id, value), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[1].ID != "b" || string(keys[1].Secret) != "two" {
+		t.Errorf("keys = %+v", keys)
+	}
+
+	if _, err := ParseKeyList("no-id", nil); err == nil {
+		t.Error("an entry without an id was accepted")
+	}
+}
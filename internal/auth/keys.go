@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a token key named by its ID: an HS256 shared secret, or an RS256 key pair.
+// An RSA key with only PublicKey set can verify tokens but not sign them.
+type Key struct {
+	ID         string
+	Secret     []byte
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+}
+
+// JWK is an RSA public key in JSON Web Key form (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKSet is the document other services fetch to verify tokens
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// HMACKey is an HS256 key for a shared secret
+func HMACKey(id, secret string) Key {
+	return Key{ID: id, Secret: []byte(secret)}
+}
+
+// ParseRSAKey reads an RS256 key from PEM: a private key (PKCS #1 or #8) can sign and
+// verify, a public key only verify. Without an id the key is named by its thumbprint.
+func ParseRSAKey(id string, pemBytes []byte) (Key, error) {
+	key := Key{ID: id}
+	if private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		key.PrivateKey = private
+	} else if public, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		key.PublicKey = public
+	} else {
+		return Key{}, fmt.Errorf("token key %q is not an RSA key in PEM", id)
+	}
+
+	if key.ID == "" {
+		public, _ := key.rsaPublicKey()
+		key.ID = thumbprint(public)
+	}
+
+	return key, nil
+}
+
+// ReadRSAKeyFile is ParseRSAKey for a PEM file
+func ReadRSAKeyFile(id, path string) (Key, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, err
+	}
+	return ParseRSAKey(id, pemBytes)
+}
+
+// ParseKeyList reads comma-separated "id:value" pairs, as used to list retired keys
+// in the environment, calling parse for each
+func ParseKeyList(list string, parse func(id, value string) (Key, error)) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, value, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || value == "" {
+			return nil, fmt.Errorf("token key %q must be id:value", entry)
+		}
+
+		key, err := parse(id, value)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+var errNoPublicKey = errors.New("token key has no public part")
+
+// rsaPublicKey is the public half of an RS256 key
+func (k Key) rsaPublicKey() (*rsa.PublicKey, error) {
+	switch {
+	case k.PublicKey != nil:
+		return k.PublicKey, nil
+	case k.PrivateKey != nil:
+		return &k.PrivateKey.PublicKey, nil
+	}
+	return nil, errNoPublicKey
+}
+
+func (k Key) isRSA() bool {
+	return k.PrivateKey != nil || k.PublicKey != nil
+}
+
+func (k Key) canSign() bool {
+	if k.isRSA() {
+		return k.PrivateKey != nil
+	}
+	return len(k.Secret) > 0
+}
+
+func (k Key) method() jwt.SigningMethod {
+	if k.isRSA() {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodHS256
+}
+
+func (k Key) signingKey() any {
+	if k.isRSA() {
+		return k.PrivateKey
+	}
+	return k.Secret
+}
+
+func (k Key) verificationKey() any {
+	if k.isRSA() {
+		public, _ := k.rsaPublicKey()
+		return public
+	}
+	return k.Secret
+}
+
+func (k Key) jwk() (JWK, bool) {
+	if !k.isRSA() {
+		return JWK{}, false
+	}
+
+	public, _ := k.rsaPublicKey()
+	return JWK{
+		KeyType:   "RSA",
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		Modulus:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+	}, true
+}
+
+// thumbprint is a short stable ID for a public key
+func thumbprint(public *rsa.PublicKey) string {
+	sum := sha256.Sum256(x509.MarshalPKCS1PublicKey(public))
+	return hex.EncodeToString(sum[:8])
+}
+
+func sortedKeys(keys map[string]Key) []string {
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -28,4 +28,8 @@ func (a *TestAuthenticator) ValidateToken(token string) (*jwt.Token, error) {
 	return jwt.Parse(token, func(t *jwt.Token) (any, error) {
 		return []byte(secret), nil
 	})
+}
+
+func (a *TestAuthenticator) PublicKeys() *JWKSet {
+	return &JWKSet{Keys: []JWK{}}
 }
\ No newline at end of file
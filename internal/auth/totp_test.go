@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the RFC 6238 SHA-1 test key, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// The RFC's 8-digit codes, cut to their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	step, ok := VerifyTOTP(rfc6238Secret, "081804", now.Add(TOTPPeriod))
+	if !ok || step != now.Unix()/30 {
+		t.Errorf("code from the previous period: step %d, ok %t", step, ok)
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "081804", now.Add(3*TOTPPeriod)); ok {
+		t.Error("accepted a code from three periods ago")
+	}
+	if _, ok := VerifyTOTP(rfc6238Secret, "123456", now); ok {
+		t.Error("accepted a wrong code")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 14 || strings.Count(code, "-") != 2 || seen[code] {
+			t.Errorf("code %q", code)
+		}
+		seen[code] = true
+		if NormalizeRecoveryCode(" "+strings.ToUpper(code)+" ") != code {
+			t.Errorf("normalizing %q", code)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrUnknownPrice     = errors.New("unknown price id")
+)
+
+type Plan string
+
+const (
+	PlanFree     Plan = "free"
+	PlanPro      Plan = "pro"
+	PlanBusiness Plan = "business"
+)
+
+// Unlimited marks a plan limit that is never enforced
+const Unlimited = -1
+
+// Limits describes how many resources an account may own on a plan
+type Limits struct {
+	MaxRestaurants int `json:"max_restaurants"`
+	MaxEmployees   int `json:"max_employees"` // per restaurant
+}
+
+var planLimits = map[Plan]Limits{
+	PlanFree:     {MaxRestaurants: 1, MaxEmployees: 10},
+	PlanPro:      {MaxRestaurants: 3, MaxEmployees: 50},
+	PlanBusiness: {MaxRestaurants: Unlimited, MaxEmployees: Unlimited},
+}
+
+// LimitsFor returns the limits of a plan, falling back to the free plan
+func LimitsFor(plan Plan) Limits {
+	if limits, ok := planLimits[plan]; ok {
+		return limits
+	}
+	return planLimits[PlanFree]
+}
+
+// IsActiveStatus reports whether a Stripe subscription status grants paid features
+func IsActiveStatus(status string) bool {
+	return status == "active" || status == "trialing"
+}
+
+type Config struct {
+	Enabled         bool
+	SecretKey       string
+	WebhookSecret   string
+	PortalReturnURL string
+	Prices          map[Plan]string // plan -> Stripe price ID
+}
+
+// PlanForPrice maps a Stripe price ID back to the plan it belongs to
+func (c Config) PlanForPrice(priceID string) (Plan, error) {
+	for plan, id := range c.Prices {
+		if id != "" && id == priceID {
+			return plan, nil
+		}
+	}
+	return "", ErrUnknownPrice
+}
+
+type Subscription struct {
+	ID               string
+	CustomerID       string
+	Status           string
+	PriceID          string
+	CurrentPeriodEnd time.Time
+}
+
+type Event struct {
+	ID   string
+	Type string
+	// Subscription is populated for customer.subscription.* events
+	Subscription *Subscription
+	// CustomerID is populated for every event carrying a customer reference
+	CustomerID string
+}
+
+type Client interface {
+	CreateCustomer(ctx context.Context, email, name string, userID int64) (string, error)
+	CreateSubscription(ctx context.Context, customerID, priceID string) (*Subscription, error)
+	CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error)
+	ParseWebhook(payload []byte, signature string) (*Event, error)
+}
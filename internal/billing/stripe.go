@@ -0,0 +1,228 @@
+package billing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripeAPIURL     = "https://api.stripe.com/v1"
+	webhookTolerance = 5 * time.Minute
+)
+
+// StripeClient talks to the Stripe REST API directly over HTTP
+type StripeClient struct {
+	secretKey     string
+	webhookSecret string
+	httpClient    *http.Client
+}
+
+func NewStripeClient(secretKey, webhookSecret string) *StripeClient {
+	return &StripeClient{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *StripeClient) CreateCustomer(ctx context.Context, email, name string, userID int64) (string, error) {
+	form := url.Values{}
+	form.Set("email", email)
+	form.Set("name", name)
+	form.Set("metadata[user_id]", strconv.FormatInt(userID, 10))
+
+	var customer struct {
+		ID string `json:"id"`
+	}
+	if err := c.post(ctx, "/customers", form, &customer); err != nil {
+		return "", err
+	}
+
+	return customer.ID, nil
+}
+
+func (c *StripeClient) CreateSubscription(ctx context.Context, customerID, priceID string) (*Subscription, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+	form.Set("items[0][price]", priceID)
+
+	var sub stripeSubscription
+	if err := c.post(ctx, "/subscriptions", form, &sub); err != nil {
+		return nil, err
+	}
+
+	return sub.toSubscription(), nil
+}
+
+func (c *StripeClient) CreatePortalSession(ctx context.Context, customerID, returnURL string) (string, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+	form.Set("return_url", returnURL)
+
+	var session struct {
+		URL string `json:"url"`
+	}
+	if err := c.post(ctx, "/billing_portal/sessions", form, &session); err != nil {
+		return "", err
+	}
+
+	return session.URL, nil
+}
+
+// ParseWebhook verifies the Stripe-Signature header and decodes the event
+func (c *StripeClient) ParseWebhook(payload []byte, signature string) (*Event, error) {
+	if err := verifySignature(payload, signature, c.webhookSecret, time.Now()); err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object json.RawMessage `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, err
+	}
+
+	event := &Event{ID: raw.ID, Type: raw.Type}
+
+	if strings.HasPrefix(raw.Type, "customer.subscription.") {
+		var sub stripeSubscription
+		if err := json.Unmarshal(raw.Data.Object, &sub); err != nil {
+			return nil, err
+		}
+		event.Subscription = sub.toSubscription()
+		event.CustomerID = sub.Customer
+		return event, nil
+	}
+
+	var object struct {
+		Customer string `json:"customer"`
+	}
+	if err := json.Unmarshal(raw.Data.Object, &object); err == nil {
+		event.CustomerID = object.Customer
+	}
+
+	return event, nil
+}
+
+func (c *StripeClient) post(ctx context.Context, path string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeAPIURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		return fmt.Errorf("stripe %s: status %d: %s", path, res.StatusCode, apiErr.Error.Message)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+type stripeSubscription struct {
+	ID               string `json:"id"`
+	Customer         string `json:"customer"`
+	Status           string `json:"status"`
+	CurrentPeriodEnd int64  `json:"current_period_end"`
+	Items            struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+func (s stripeSubscription) toSubscription() *Subscription {
+	sub := &Subscription{
+		ID:               s.ID,
+		CustomerID:       s.Customer,
+		Status:           s.Status,
+		CurrentPeriodEnd: time.Unix(s.CurrentPeriodEnd, 0).UTC(),
+	}
+	if len(s.Items.Data) > 0 {
+		sub.PriceID = s.Items.Data[0].Price.ID
+	}
+	return sub
+}
+
+// verifySignature checks a "t=<unix>,v1=<hex>" header against HMAC-SHA256(secret, "<t>.<payload>")
+func verifySignature(payload []byte, header, secret string, now time.Time) error {
+	var timestamp string
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if now.Sub(time.Unix(ts, 0)).Abs() > webhookTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		if hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
@@ -0,0 +1,100 @@
+package billing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signature is the hex v1 signature of the payload at t
+func signature(payload []byte, secret string, t time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10) + "." + string(payload)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sign builds a Stripe-Signature header for the payload at t
+func sign(payload []byte, secret string, t time.Time) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), signature(payload, secret, t))
+}
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","type":"customer.subscription.updated"}`)
+	now := time.Unix(1767225600, 0)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		wantErr bool
+	}{
+		{"valid", payload, sign(payload, secret, now), false},
+		{"valid among rotated secrets", payload, sign(payload, "whsec_old", now) + ",v1=" + signature(payload, secret, now), false},
+		{"within the tolerance", payload, sign(payload, secret, now.Add(-4*time.Minute)), false},
+		{"tampered payload", []byte(`{"id":"evt_1","type":"customer.subscription.deleted"}`), sign(payload, secret, now), true},
+		{"wrong secret", payload, sign(payload, "whsec_other", now), true},
+		{"stale timestamp", payload, sign(payload, secret, now.Add(-webhookTolerance-time.Second)), true},
+		{"timestamp from the future", payload, sign(payload, secret, now.Add(webhookTolerance+time.Second)), true},
+		{"no timestamp", payload, "v1=" + signature(payload, secret, now), true},
+		{"no signature", payload, "t=1767225600", true},
+		{"empty header", payload, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifySignature(tt.payload, tt.header, secret, now)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("verifySignature() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("err = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestParseWebhook(t *testing.T) {
+	client := NewStripeClient("sk_test", "whsec_test")
+
+	tests := []struct {
+		name     string
+		payload  string
+		customer string
+		sub      *Subscription
+	}{
+		{
+			name:     "subscription event",
+			payload:  `{"id":"evt_1","type":"customer.subscription.updated","data":{"object":{"id":"sub_1","customer":"cus_1","status":"active","current_period_end":1767225600,"items":{"data":[{"price":{"id":"price_pro"}}]}}}}`,
+			customer: "cus_1",
+			sub:      &Subscription{ID: "sub_1", CustomerID: "cus_1", Status: "active", PriceID: "price_pro", CurrentPeriodEnd: time.Unix(1767225600, 0).UTC()},
+		},
+		{
+			name:     "invoice event",
+			payload:  `{"id":"evt_2","type":"invoice.payment_failed","data":{"object":{"id":"in_1","customer":"cus_2"}}}`,
+			customer: "cus_2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := client.ParseWebhook([]byte(tt.payload), sign([]byte(tt.payload), "whsec_test", time.Now()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.CustomerID != tt.customer {
+				t.Errorf("customer = %q, want %q", event.CustomerID, tt.customer)
+			}
+			if (event.Subscription == nil) != (tt.sub == nil) || (tt.sub != nil && *event.Subscription != *tt.sub) {
+				t.Errorf("subscription = %+v, want %+v", event.Subscription, tt.sub)
+			}
+		})
+	}
+
+	if _, err := client.ParseWebhook([]byte(`{}`), "t=1,v1=00"); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unsigned payload: err = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,587 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// BackupFormatVersion is the version of the RestaurantBackup layout. Bump it
+// when a change would make older files restore wrongly, and keep reading the
+// versions that can still be restored.
+const BackupFormatVersion = 1
+
+// ErrBackupRestaurantNotFound is returned when exporting a restaurant that doesn't exist
+var ErrBackupRestaurantNotFound = errors.New("restaurant not found")
+
+// RestaurantBackup is a restaurant's scheduling data as a self-contained JSON
+// document. The IDs only link records within the file; an import gives every
+// record a new ID, so a backup can be restored next to the original or into
+// another environment.
+type RestaurantBackup struct {
+	FormatVersion  int                    `json:"format_version"`
+	ExportedAt     time.Time              `json:"exported_at"`
+	Restaurant     BackupRestaurant       `json:"restaurant"`
+	Roles          []BackupRole           `json:"roles"`
+	Employees      []BackupEmployee       `json:"employees"`
+	ShiftTemplates []BackupShiftTemplate  `json:"shift_templates"`
+	Schedules      []BackupSchedule       `json:"schedules"`
+	Shifts         []BackupScheduledShift `json:"shifts"`
+	Events         []BackupEvent          `json:"events"`
+}
+
+type BackupRestaurant struct {
+	ID                      int64    `json:"id"`
+	Name                    string   `json:"name"`
+	Address                 string   `json:"address"`
+	Phone                   *string  `json:"phone"`
+	HoursEnforcement        string   `json:"hours_enforcement"`
+	ScheduleLockHours       int      `json:"schedule_lock_hours,omitempty"` // absent from older backups, which restore with the lock off
+	WeeklyLaborBudgetCents  *int     `json:"weekly_labor_budget_cents,omitempty"`
+	ScheduleRetentionMonths *int     `json:"schedule_retention_months,omitempty"`
+	AssignmentPolicy        string   `json:"assignment_policy,omitempty"`
+	StaffMilestoneDigest    bool     `json:"staff_milestone_digest,omitempty"`
+	Latitude                *float64 `json:"latitude,omitempty"`
+	Longitude               *float64 `json:"longitude,omitempty"`
+}
+
+type BackupRole struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type BackupEmployee struct {
+	ID              int64   `json:"id"`
+	FullName        string  `json:"full_name"`
+	Email           string  `json:"email"`
+	Locale          *string `json:"locale"`
+	HourlyRateCents *int    `json:"hourly_rate_cents,omitempty"`
+	Seniority       int     `json:"seniority,omitempty"`
+	Birthday        *string `json:"birthday,omitempty"` // YYYY-MM-DD, like hire_date; absent when not given
+	HireDate        *string `json:"hire_date,omitempty"`
+	RoleIDs         []int64 `json:"role_ids"`
+}
+
+type BackupShiftTemplate struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	DayOfWeek int     `json:"day_of_week"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Notes     string  `json:"notes"`
+	RoleIDs   []int64 `json:"role_ids"`
+	// EffectiveFrom and EffectiveUntil are YYYY-MM-DD, absent when that side of the window is open
+	EffectiveFrom  *string `json:"effective_from,omitempty"`
+	EffectiveUntil *string `json:"effective_until,omitempty"`
+}
+
+type BackupSchedule struct {
+	ID          int64                   `json:"id"`
+	StartDate   string                  `json:"start_date"`
+	EndDate     string                  `json:"end_date"`
+	PublishedAt *time.Time              `json:"published_at"`
+	ArchivedAt  *time.Time              `json:"archived_at,omitempty"`
+	Note        string                  `json:"note,omitempty"`
+	DayNotes    []BackupScheduleDayNote `json:"day_notes,omitempty"`
+}
+
+type BackupScheduleDayNote struct {
+	Date string `json:"date"`
+	Note string `json:"note"`
+}
+
+type BackupScheduledShift struct {
+	ID              int64  `json:"id"`
+	ScheduleID      int64  `json:"schedule_id"`
+	ShiftTemplateID *int64 `json:"shift_template_id"`
+	RoleID          int64  `json:"role_id"`
+	EmployeeID      *int64 `json:"employee_id"`
+	ShiftDate       string `json:"shift_date"`
+	StartTime       string `json:"start_time"`
+	EndTime         string `json:"end_time"`
+	Notes           string `json:"notes"`
+	Training        bool   `json:"training"`
+	TrainerShiftID  *int64 `json:"trainer_shift_id"`
+	EventID         *int64 `json:"event_id"`
+}
+
+type BackupEvent struct {
+	ID          int64   `json:"id"`
+	Title       string  `json:"title"`
+	Description *string `json:"description"`
+	Date        string  `json:"date"`
+	StartTime   string  `json:"start_time"`
+	EndTime     string  `json:"end_time"`
+	EmployeeIDs []int64 `json:"employee_ids"`
+}
+
+// Validate checks the backup can be restored: a known format version and
+// references that all point at records in the file
+func (b *RestaurantBackup) Validate() error {
+	if b.FormatVersion != BackupFormatVersion {
+		return fmt.Errorf("unsupported backup format version %d, want %d", b.FormatVersion, BackupFormatVersion)
+	}
+	if b.Restaurant.Name == "" {
+		return errors.New("backup has no restaurant name")
+	}
+
+	ids := func(kind string, n int, id func(int) int64) (map[int64]bool, error) {
+		seen := make(map[int64]bool, n)
+		for i := 0; i < n; i++ {
+			if seen[id(i)] {
+				return nil, fmt.Errorf("%s %d appears twice", kind, id(i))
+			}
+			seen[id(i)] = true
+		}
+		return seen, nil
+	}
+	roles, err := ids("role", len(b.Roles), func(i int) int64 { return b.Roles[i].ID })
+	if err != nil {
+		return err
+	}
+	employees, err := ids("employee", len(b.Employees), func(i int) int64 { return b.Employees[i].ID })
+	if err != nil {
+		return err
+	}
+	templates, err := ids("shift template", len(b.ShiftTemplates), func(i int) int64 { return b.ShiftTemplates[i].ID })
+	if err != nil {
+		return err
+	}
+	schedules, err := ids("schedule", len(b.Schedules), func(i int) int64 { return b.Schedules[i].ID })
+	if err != nil {
+		return err
+	}
+	shifts, err := ids("shift", len(b.Shifts), func(i int) int64 { return b.Shifts[i].ID })
+	if err != nil {
+		return err
+	}
+	events, err := ids("event", len(b.Events), func(i int) int64 { return b.Events[i].ID })
+	if err != nil {
+		return err
+	}
+
+	missing := func(kind string, id int64, from string, fromID int64) error {
+		return fmt.Errorf("%s %d references %s %d, which is not in the backup", from, fromID, kind, id)
+	}
+	for _, e := range b.Employees {
+		for _, id := range e.RoleIDs {
+			if !roles[id] {
+				return missing("role", id, "employee", e.ID)
+			}
+		}
+	}
+	for _, t := range b.ShiftTemplates {
+		for _, id := range t.RoleIDs {
+			if !roles[id] {
+				return missing("role", id, "shift template", t.ID)
+			}
+		}
+	}
+	for _, e := range b.Events {
+		for _, id := range e.EmployeeIDs {
+			if !employees[id] {
+				return missing("employee", id, "event", e.ID)
+			}
+		}
+	}
+	for _, s := range b.Shifts {
+		switch {
+		case !schedules[s.ScheduleID]:
+			return missing("schedule", s.ScheduleID, "shift", s.ID)
+		case !roles[s.RoleID]:
+			return missing("role", s.RoleID, "shift", s.ID)
+		case s.EmployeeID != nil && !employees[*s.EmployeeID]:
+			return missing("employee", *s.EmployeeID, "shift", s.ID)
+		case s.ShiftTemplateID != nil && !templates[*s.ShiftTemplateID]:
+			return missing("shift template", *s.ShiftTemplateID, "shift", s.ID)
+		case s.TrainerShiftID != nil && !shifts[*s.TrainerShiftID]:
+			return missing("shift", *s.TrainerShiftID, "shift", s.ID)
+		case s.EventID != nil && !events[*s.EventID]:
+			return missing("event", *s.EventID, "shift", s.ID)
+		}
+	}
+
+	return nil
+}
+
+// ExportRestaurant reads a restaurant's backup from one consistent snapshot
+func ExportRestaurant(ctx context.Context, db *sql.DB, restaurantID int64) (*RestaurantBackup, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// types scans the array columns
+	types := pgtype.NewMap()
+
+	b := &RestaurantBackup{
+		FormatVersion:  BackupFormatVersion,
+		ExportedAt:     time.Now().UTC(),
+		Roles:          []BackupRole{},
+		Employees:      []BackupEmployee{},
+		ShiftTemplates: []BackupShiftTemplate{},
+		Schedules:      []BackupSchedule{},
+		Shifts:         []BackupScheduledShift{},
+		Events:         []BackupEvent{},
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, name, address, phone, hours_enforcement, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, latitude, longitude
+		FROM restaurants
+		WHERE id = $1`, restaurantID,
+	).Scan(&b.Restaurant.ID, &b.Restaurant.Name, &b.Restaurant.Address, &b.Restaurant.Phone, &b.Restaurant.HoursEnforcement, &b.Restaurant.ScheduleLockHours, &b.Restaurant.WeeklyLaborBudgetCents, &b.Restaurant.ScheduleRetentionMonths, &b.Restaurant.AssignmentPolicy, &b.Restaurant.StaffMilestoneDigest, &b.Restaurant.Latitude, &b.Restaurant.Longitude)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBackupRestaurantNotFound
+		}
+		return nil, err
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT id, name, color FROM roles WHERE restaurant_id = $1 ORDER BY id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var r BackupRole
+			if err := rows.Scan(&r.ID, &r.Name, &r.Color); err != nil {
+				return err
+			}
+			b.Roles = append(b.Roles, r)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("roles: %w", err)
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT e.id, e.full_name, e.email, e.locale, e.hourly_rate_cents, e.seniority,
+		       to_char(e.birthday, 'YYYY-MM-DD'), to_char(e.hire_date, 'YYYY-MM-DD'),
+		       COALESCE(array_agg(er.role_id ORDER BY er.role_id) FILTER (WHERE er.role_id IS NOT NULL), '{}')
+		FROM employees e
+		LEFT JOIN employee_roles er ON er.employee_id = e.id
+		WHERE e.restaurant_id = $1
+		GROUP BY e.id
+		ORDER BY e.id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var e BackupEmployee
+			if err := rows.Scan(&e.ID, &e.FullName, &e.Email, &e.Locale, &e.HourlyRateCents, &e.Seniority, &e.Birthday, &e.HireDate, types.SQLScanner(&e.RoleIDs)); err != nil {
+				return err
+			}
+			b.Employees = append(b.Employees, e)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("employees: %w", err)
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT id, name, day_of_week, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), notes, role_ids,
+		       to_char(effective_from, 'YYYY-MM-DD'), to_char(effective_until, 'YYYY-MM-DD')
+		FROM shift_templates
+		WHERE restaurant_id = $1
+		ORDER BY id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var t BackupShiftTemplate
+			var roleIDs []byte
+			if err := rows.Scan(&t.ID, &t.Name, &t.DayOfWeek, &t.StartTime, &t.EndTime, &t.Notes, &roleIDs, &t.EffectiveFrom, &t.EffectiveUntil); err != nil {
+				return err
+			}
+			if err := json.Unmarshal(roleIDs, &t.RoleIDs); err != nil {
+				return err
+			}
+			b.ShiftTemplates = append(b.ShiftTemplates, t)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("shift templates: %w", err)
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT id, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'), published_at, archived_at, note
+		FROM schedules
+		WHERE restaurant_id = $1
+		ORDER BY id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var s BackupSchedule
+			if err := rows.Scan(&s.ID, &s.StartDate, &s.EndDate, &s.PublishedAt, &s.ArchivedAt, &s.Note); err != nil {
+				return err
+			}
+			b.Schedules = append(b.Schedules, s)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("schedules: %w", err)
+	}
+
+	scheduleIndex := make(map[int64]int, len(b.Schedules))
+	for i, s := range b.Schedules {
+		scheduleIndex[s.ID] = i
+	}
+	err = queryEach(ctx, tx, `
+		SELECT n.schedule_id, to_char(n.date, 'YYYY-MM-DD'), n.note
+		FROM schedule_day_notes n
+		JOIN schedules s ON s.id = n.schedule_id
+		WHERE s.restaurant_id = $1
+		ORDER BY n.schedule_id, n.date`,
+		restaurantID, func(rows *sql.Rows) error {
+			var scheduleID int64
+			var n BackupScheduleDayNote
+			if err := rows.Scan(&scheduleID, &n.Date, &n.Note); err != nil {
+				return err
+			}
+			s := &b.Schedules[scheduleIndex[scheduleID]]
+			s.DayNotes = append(s.DayNotes, n)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("schedule day notes: %w", err)
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT ev.id, ev.title, ev.description, to_char(ev.date, 'YYYY-MM-DD'),
+		       to_char(ev.start_time, 'HH24:MI'), to_char(ev.end_time, 'HH24:MI'),
+		       COALESCE(array_agg(ee.employee_id ORDER BY ee.employee_id) FILTER (WHERE ee.employee_id IS NOT NULL), '{}')
+		FROM events ev
+		LEFT JOIN event_employees ee ON ee.event_id = ev.id
+		WHERE ev.restaurant_id = $1
+		GROUP BY ev.id
+		ORDER BY ev.id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var e BackupEvent
+			if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.Date, &e.StartTime, &e.EndTime, types.SQLScanner(&e.EmployeeIDs)); err != nil {
+				return err
+			}
+			b.Events = append(b.Events, e)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("events: %w", err)
+	}
+
+	err = queryEach(ctx, tx, `
+		SELECT id, schedule_id, shift_template_id, role_id, employee_id, to_char(shift_date, 'YYYY-MM-DD'),
+		       to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), COALESCE(notes, ''),
+		       training, trainer_shift_id, event_id
+		FROM scheduled_shifts
+		WHERE restaurant_id = $1
+		ORDER BY id`,
+		restaurantID, func(rows *sql.Rows) error {
+			var s BackupScheduledShift
+			err := rows.Scan(
+				&s.ID, &s.ScheduleID, &s.ShiftTemplateID, &s.RoleID, &s.EmployeeID, &s.ShiftDate,
+				&s.StartTime, &s.EndTime, &s.Notes, &s.Training, &s.TrainerShiftID, &s.EventID,
+			)
+			if err != nil {
+				return err
+			}
+			b.Shifts = append(b.Shifts, s)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("shifts: %w", err)
+	}
+
+	return b, tx.Commit()
+}
+
+// ImportRestaurant restores a backup as a new restaurant owned by ownerID, in
+// a single transaction, and returns the new restaurant's ID. Published
+// schedules get the baseline snapshot publishing would have taken.
+func ImportRestaurant(ctx context.Context, db *sql.DB, b *RestaurantBackup, ownerID int64) (int64, error) {
+	if err := b.Validate(); err != nil {
+		return 0, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	hoursEnforcement := b.Restaurant.HoursEnforcement
+	if hoursEnforcement == "" {
+		hoursEnforcement = "warn"
+	}
+	assignmentPolicy := b.Restaurant.AssignmentPolicy
+	if assignmentPolicy == "" {
+		assignmentPolicy = "manual_only"
+	}
+
+	var restaurantID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO restaurants (employer_id, name, address, phone, hours_enforcement, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`,
+		ownerID, b.Restaurant.Name, b.Restaurant.Address, b.Restaurant.Phone, hoursEnforcement, b.Restaurant.ScheduleLockHours, b.Restaurant.WeeklyLaborBudgetCents, b.Restaurant.ScheduleRetentionMonths, assignmentPolicy, b.Restaurant.StaffMilestoneDigest, b.Restaurant.Latitude, b.Restaurant.Longitude,
+	).Scan(&restaurantID)
+	if err != nil {
+		return 0, fmt.Errorf("restaurant: %w", err)
+	}
+
+	insert := func(query string, args ...any) (int64, error) {
+		var id int64
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&id)
+		return id, err
+	}
+
+	// Each map takes an ID from the file to the ID of the imported row
+	roles := make(map[int64]int64, len(b.Roles))
+	roleByID := make(map[int64]BackupRole, len(b.Roles))
+	for _, r := range b.Roles {
+		id, err := insert(`INSERT INTO roles (restaurant_id, name, color) VALUES ($1, $2, $3) RETURNING id`,
+			restaurantID, r.Name, r.Color)
+		if err != nil {
+			return 0, fmt.Errorf("role %d: %w", r.ID, err)
+		}
+		roles[r.ID] = id
+		roleByID[r.ID] = r
+	}
+
+	employees := make(map[int64]int64, len(b.Employees))
+	employeeNames := make(map[int64]string, len(b.Employees))
+	for _, e := range b.Employees {
+		id, err := insert(`INSERT INTO employees (restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+			restaurantID, e.FullName, e.Email, e.Locale, e.HourlyRateCents, e.Seniority, e.Birthday, e.HireDate)
+		if err != nil {
+			return 0, fmt.Errorf("employee %d: %w", e.ID, err)
+		}
+		employees[e.ID] = id
+		employeeNames[e.ID] = e.FullName
+
+		for _, roleID := range e.RoleIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO employee_roles (employee_id, role_id) VALUES ($1, $2)`, id, roles[roleID]); err != nil {
+				return 0, fmt.Errorf("employee %d: %w", e.ID, err)
+			}
+		}
+	}
+
+	templates := make(map[int64]int64, len(b.ShiftTemplates))
+	for _, t := range b.ShiftTemplates {
+		roleIDs := make([]int64, 0, len(t.RoleIDs))
+		for _, roleID := range t.RoleIDs {
+			roleIDs = append(roleIDs, roles[roleID])
+		}
+		encoded, err := json.Marshal(roleIDs)
+		if err != nil {
+			return 0, err
+		}
+
+		id, err := insert(`
+			INSERT INTO shift_templates (restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, effective_from, effective_until)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id`,
+			restaurantID, t.Name, t.DayOfWeek, t.StartTime, t.EndTime, t.Notes, string(encoded), t.EffectiveFrom, t.EffectiveUntil)
+		if err != nil {
+			return 0, fmt.Errorf("shift template %d: %w", t.ID, err)
+		}
+		templates[t.ID] = id
+	}
+
+	schedules := make(map[int64]int64, len(b.Schedules))
+	for _, s := range b.Schedules {
+		id, err := insert(`
+			INSERT INTO schedules (restaurant_id, start_date, end_date, published_at, archived_at, note)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			restaurantID, s.StartDate, s.EndDate, s.PublishedAt, s.ArchivedAt, s.Note)
+		if err != nil {
+			return 0, fmt.Errorf("schedule %d: %w", s.ID, err)
+		}
+		schedules[s.ID] = id
+
+		for _, n := range s.DayNotes {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO schedule_day_notes (schedule_id, date, note) VALUES ($1, $2, $3)`, id, n.Date, n.Note); err != nil {
+				return 0, fmt.Errorf("schedule %d: %w", s.ID, err)
+			}
+		}
+	}
+
+	events := make(map[int64]int64, len(b.Events))
+	for _, e := range b.Events {
+		id, err := insert(`
+			INSERT INTO events (restaurant_id, title, description, date, start_time, end_time)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id`,
+			restaurantID, e.Title, e.Description, e.Date, e.StartTime, e.EndTime)
+		if err != nil {
+			return 0, fmt.Errorf("event %d: %w", e.ID, err)
+		}
+		events[e.ID] = id
+
+		for _, employeeID := range e.EmployeeIDs {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO event_employees (event_id, employee_id) VALUES ($1, $2)`, id, employees[employeeID]); err != nil {
+				return 0, fmt.Errorf("event %d: %w", e.ID, err)
+			}
+		}
+	}
+
+	mapped := func(ids map[int64]int64, id *int64) *int64 {
+		if id == nil {
+			return nil
+		}
+		newID := ids[*id]
+		return &newID
+	}
+
+	// Trainer links can point at any shift, so they are set once every shift exists
+	shifts := make(map[int64]int64, len(b.Shifts))
+	for _, s := range b.Shifts {
+		role := roleByID[s.RoleID]
+		var employeeName *string
+		if s.EmployeeID != nil {
+			name := employeeNames[*s.EmployeeID]
+			employeeName = &name
+		}
+
+		id, err := insert(`
+			INSERT INTO scheduled_shifts (
+				schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
+				shift_date, start_time, end_time, notes,
+				employee_name, role_name, role_color, training, event_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			RETURNING id`,
+			schedules[s.ScheduleID], restaurantID, mapped(templates, s.ShiftTemplateID), roles[s.RoleID], mapped(employees, s.EmployeeID),
+			s.ShiftDate, s.StartTime, s.EndTime, s.Notes,
+			employeeName, role.Name, role.Color, s.Training, mapped(events, s.EventID))
+		if err != nil {
+			return 0, fmt.Errorf("shift %d: %w", s.ID, err)
+		}
+		shifts[s.ID] = id
+	}
+	for _, s := range b.Shifts {
+		if s.TrainerShiftID == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE scheduled_shifts SET trainer_shift_id = $2 WHERE id = $1`, shifts[s.ID], shifts[*s.TrainerShiftID]); err != nil {
+			return 0, fmt.Errorf("shift %d: %w", s.ID, err)
+		}
+	}
+
+	if err := snapshotPublishedSchedules(ctx, tx, `s.restaurant_id = $1`, restaurantID); err != nil {
+		return 0, err
+	}
+
+	return restaurantID, tx.Commit()
+}
+
+// queryEach runs a query with a single restaurant ID argument and calls scan for every row
+func queryEach(ctx context.Context, tx *sql.Tx, query string, restaurantID int64, scan func(*sql.Rows) error) error {
+	rows, err := tx.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBackupValidate(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	valid := func() *RestaurantBackup {
+		return &RestaurantBackup{
+			FormatVersion:  BackupFormatVersion,
+			Restaurant:     BackupRestaurant{ID: 1, Name: "Cedar Grill"},
+			Roles:          []BackupRole{{ID: 10, Name: "Server"}},
+			Employees:      []BackupEmployee{{ID: 20, FullName: "Ana", RoleIDs: []int64{10}}},
+			ShiftTemplates: []BackupShiftTemplate{{ID: 30, RoleIDs: []int64{10}}},
+			Schedules:      []BackupSchedule{{ID: 40}},
+			Events:         []BackupEvent{{ID: 50, EmployeeIDs: []int64{20}}},
+			Shifts: []BackupScheduledShift{
+				{ID: 60, ScheduleID: 40, RoleID: 10, EmployeeID: id(20), ShiftTemplateID: id(30), EventID: id(50)},
+				{ID: 61, ScheduleID: 40, RoleID: 10, Training: true, TrainerShiftID: id(60)},
+			},
+		}
+	}
+
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("valid backup: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*RestaurantBackup)
+		want   string
+	}{
+		{"newer version", func(b *RestaurantBackup) { b.FormatVersion++ }, "format version"},
+		{"duplicate role", func(b *RestaurantBackup) { b.Roles = append(b.Roles, b.Roles[0]) }, "role 10 appears twice"},
+		{"employee role", func(b *RestaurantBackup) { b.Employees[0].RoleIDs = []int64{11} }, "employee 20 references role 11"},
+		{"shift schedule", func(b *RestaurantBackup) { b.Shifts[0].ScheduleID = 41 }, "shift 60 references schedule 41"},
+		{"trainer shift", func(b *RestaurantBackup) { b.Shifts[1].TrainerShiftID = id(62) }, "shift 61 references shift 62"},
+		{"event employee", func(b *RestaurantBackup) { b.Events[0].EmployeeIDs = []int64{21} }, "event 50 references employee 21"},
+	}
+
+	for _, tt := range tests {
+		b := valid()
+		tt.change(b)
+		err := b.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
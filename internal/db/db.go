@@ -3,32 +3,175 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-func New(addr string, maxOpenConns, maxIdleConns int, maxIdleTime string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", addr)
-	if err != nil {
-		return  nil, err
+// DefaultStatementCacheCapacity is pgx's own default: the prepared statements
+// each connection keeps for the queries it runs most
+const DefaultStatementCacheCapacity = 512
+
+// Pools are the connection pools of the primary and of its read replica. Read
+// is the primary itself when no replica is configured, so callers never check.
+// Both are database/sql handles over pgx pools, which WritePool and ReadPool
+// expose for their stats.
+type Pools struct {
+	Write *sql.DB
+	Read  *sql.DB
+
+	WritePool *pgxpool.Pool
+	ReadPool  *pgxpool.Pool
+}
+
+// HasReplica reports whether reads go to a separate replica
+func (p *Pools) HasReplica() bool {
+	return p.Read != p.Write
+}
+
+func (p *Pools) Close() error {
+	if p.HasReplica() {
+		p.Read.Close()
 	}
-	
-	db.SetMaxOpenConns(maxOpenConns)
-	db.SetMaxIdleConns(maxIdleConns)
+	return p.Write.Close()
+}
+
+// Wrapper wraps the connector of a pool, e.g. to instrument its queries
+type Wrapper func(driver.Connector) driver.Connector
 
-	duration, err := time.ParseDuration(maxIdleTime)
+// NewPools opens the primary at addr and, when replicaAddr is set, a replica
+// with the same pool settings. A non-nil wrap wraps both pools' connectors.
+func NewPools(addr, replicaAddr string, maxConns, minConns int, maxIdleTime, maxLifetime string, statementCacheCapacity int, wrap Wrapper) (*Pools, error) {
+	primary, primaryPool, err := open(addr, maxConns, minConns, maxIdleTime, maxLifetime, statementCacheCapacity, wrap)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetConnMaxIdleTime(duration)
-	
-	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	if replicaAddr == "" {
+		return &Pools{Write: primary, Read: primary, WritePool: primaryPool, ReadPool: primaryPool}, nil
+	}
+
+	replica, replicaPool, err := open(replicaAddr, maxConns, minConns, maxIdleTime, maxLifetime, statementCacheCapacity, wrap)
+	if err != nil {
+		primary.Close()
+		return nil, fmt.Errorf("read replica: %w", err)
+	}
+
+	return &Pools{Write: primary, Read: replica, WritePool: primaryPool, ReadPool: replicaPool}, nil
+}
+
+// New opens a single pool with pgx's default statement cache, for the tools and tests
+func New(addr string, maxConns, minConns int, maxIdleTime, maxLifetime string) (*sql.DB, error) {
+	db, _, err := open(addr, maxConns, minConns, maxIdleTime, maxLifetime, DefaultStatementCacheCapacity, nil)
+	return db, err
+}
+
+// open starts a pgx pool and returns a database/sql handle over it. The pool
+// owns the connections: database/sql keeps none idle, and closing the handle
+// closes the pool.
+func open(addr string, maxConns, minConns int, maxIdleTime, maxLifetime string, statementCacheCapacity int, wrap Wrapper) (*sql.DB, *pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config.MaxConns = int32(maxConns)
+	config.MinConns = int32(min(minConns, maxConns))
+
+	config.MaxConnIdleTime, err = time.ParseDuration(maxIdleTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Recycling connections lets a pooler or failover behind DB_ADDR rebalance them
+	config.MaxConnLifetime, err = time.ParseDuration(maxLifetime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Each connection prepares the statements it runs and keeps the latest ones.
+	// Without a cache every query is described first instead, which works behind
+	// poolers that can't keep prepared statements.
+	config.ConnConfig.StatementCacheCapacity = statementCacheCapacity
+	if statementCacheCapacity <= 0 {
+		config.ConnConfig.StatementCacheCapacity = 0
+		config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err = db.PingContext(ctx)
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return db, nil
-}
\ No newline at end of file
+	var c driver.Connector = &poolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool}
+	if wrap != nil {
+		c = wrap(c)
+	}
+	db := sql.OpenDB(c)
+	db.SetMaxIdleConns(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, pool, nil
+}
+
+// poolConnector closes the pgx pool along with the database/sql handle over it
+type poolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c *poolConnector) Close() error {
+	c.pool.Close()
+	return nil
+}
+
+// PoolStats is a pgx pool's connections and how acquiring them has gone, for expvar
+type PoolStats struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+	// AcquireCount counts every acquire, EmptyAcquireCount those that had to
+	// wait for a connection and CanceledAcquireCount those given up on.
+	// AcquireWaitMS is the time spent acquiring, summed over all of them.
+	AcquireCount         int64   `json:"acquire_count"`
+	EmptyAcquireCount    int64   `json:"empty_acquire_count"`
+	CanceledAcquireCount int64   `json:"canceled_acquire_count"`
+	AcquireWaitMS        float64 `json:"acquire_wait_ms"`
+	NewConnsCount        int64   `json:"new_conns_count"`
+	// MaxLifetimeDestroyCount and MaxIdleDestroyCount are connections closed
+	// for DB_MAX_CONN_LIFETIME and DB_MAX_IDLE_TIME
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+}
+
+// Stats snapshots the pool
+func Stats(pool *pgxpool.Pool) PoolStats {
+	s := pool.Stat()
+	return PoolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		IdleConns:               s.IdleConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		AcquireWaitMS:           float64(s.AcquireDuration()) / float64(time.Millisecond),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+	}
+}
@@ -0,0 +1,500 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SeedPassword is the password of every seeded owner account
+const SeedPassword = "password123"
+
+// profileIDSpan is the size of the ID range reserved for each profile in every table
+const profileIDSpan = 1_000_000
+
+// Profile describes the size and shape of a seeded dataset. Rows get IDs from
+// IDBase+1 upward so a profile can be torn down without touching anything else
+type Profile struct {
+	Name         string
+	Description  string
+	IDBase       int64
+	Restaurants  int
+	Roles        int
+	Employees    int
+	Weeks        int
+	ShiftsPerDay int
+}
+
+// Shifts is the number of shifts the profile seeds
+func (p Profile) Shifts() int {
+	return p.Restaurants * p.Weeks * 7 * p.ShiftsPerDay
+}
+
+var Profiles = []Profile{
+	{
+		Name:         "minimal",
+		Description:  "one restaurant with a single week, for smoke tests",
+		IDBase:       1 * profileIDSpan,
+		Restaurants:  1,
+		Roles:        3,
+		Employees:    5,
+		Weeks:        1,
+		ShiftsPerDay: 4,
+	},
+	{
+		Name:         "demo",
+		Description:  "a few restaurants with a month of schedules, for local development",
+		IDBase:       2 * profileIDSpan,
+		Restaurants:  3,
+		Roles:        6,
+		Employees:    20,
+		Weeks:        4,
+		ShiftsPerDay: 12,
+	},
+	{
+		Name:         "load-test",
+		Description:  "20 restaurants with ~50k shifts, for benchmarks and load tests",
+		IDBase:       3 * profileIDSpan,
+		Restaurants:  20,
+		Roles:        8,
+		Employees:    60,
+		Weeks:        36,
+		ShiftsPerDay: 10,
+	},
+}
+
+func LookupProfile(name string) (Profile, bool) {
+	for _, p := range Profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// Fixtures is the generated content of a profile, ready to be written
+type Fixtures struct {
+	Profile     Profile
+	Owner       seedUser
+	Restaurants []seedRestaurant
+	Roles       []seedRole
+	Employees   []seedEmployee
+	Templates   []seedTemplate
+	Schedules   []seedSchedule
+	Shifts      []seedShift
+}
+
+type seedUser struct {
+	ID        int64
+	Email     string
+	FirstName string
+	LastName  string
+}
+
+type seedRestaurant struct {
+	ID      int64
+	Name    string
+	Address string
+	Phone   string
+}
+
+type seedRole struct {
+	ID           int64
+	RestaurantID int64
+	Name         string
+	Color        string
+}
+
+type seedEmployee struct {
+	ID           int64
+	RestaurantID int64
+	FullName     string
+	Email        string
+	RoleIDs      []int64
+}
+
+type seedTemplate struct {
+	ID           int64
+	RestaurantID int64
+	Name         string
+	DayOfWeek    int
+	StartTime    string
+	EndTime      string
+	RoleIDs      []int64
+}
+
+type seedSchedule struct {
+	ID           int64
+	RestaurantID int64
+	StartDate    time.Time
+	EndDate      time.Time
+	PublishedAt  *time.Time
+}
+
+type seedShift struct {
+	ID           int64
+	ScheduleID   int64
+	RestaurantID int64
+	Role         *seedRole
+	Employee     *seedEmployee
+	Date         time.Time
+	StartTime    string
+	EndTime      string
+}
+
+var (
+	restaurantPrefixes = []string{"Golden", "Rustic", "Blue", "Copper", "Olive", "Harbor", "Maple", "Saffron", "Juniper", "Cedar"}
+	restaurantSuffixes = []string{"Spoon", "Table", "Kitchen", "Bistro", "Grill", "Tavern", "Cantina", "Diner"}
+	streets            = []string{"Main St", "Oak Ave", "Market St", "Elm St", "Park Blvd", "River Rd", "Pine St", "2nd Ave"}
+	firstNames         = []string{"Ava", "Liam", "Mia", "Noah", "Zoe", "Ethan", "Lucia", "Mateo", "Priya", "Omar", "Hana", "Diego", "Chloe", "Kai", "Nora", "Sam", "Aisha", "Leo", "Yuki", "Ivan"}
+	lastNames          = []string{"Garcia", "Smith", "Nguyen", "Patel", "Kim", "Johnson", "Lopez", "Brown", "Silva", "Chen", "Okafor", "Rossi", "Martin", "Cohen", "Haddad"}
+	seedRoles          = []seedRole{
+		{Name: "Server", Color: "#3B82F6"},
+		{Name: "Line Cook", Color: "#EF4444"},
+		{Name: "Host", Color: "#10B981"},
+		{Name: "Bartender", Color: "#8B5CF6"},
+		{Name: "Dishwasher", Color: "#6B7280"},
+		{Name: "Busser", Color: "#F59E0B"},
+		{Name: "Prep Cook", Color: "#EC4899"},
+		{Name: "Shift Lead", Color: "#14B8A6"},
+	}
+	shiftSlots = [][2]string{{"08:00:00", "14:00:00"}, {"10:00:00", "16:00:00"}, {"11:00:00", "17:00:00"}, {"16:00:00", "22:00:00"}, {"17:00:00", "23:00:00"}}
+)
+
+// Build generates the profile's fixtures. The same profile, seed and start
+// date always produce the same rows, IDs included; start is moved back to its Monday
+func Build(profile Profile, seed int64, start time.Time) *Fixtures {
+	rng := rand.New(rand.NewSource(seed))
+	start = mondayOf(start)
+
+	f := &Fixtures{
+		Profile: profile,
+		Owner: seedUser{
+			ID:        profile.IDBase + 1,
+			Email:     fmt.Sprintf("owner@%s.resa.test", profile.Name),
+			FirstName: "Seed",
+			LastName:  "Owner",
+		},
+		// Shifts point into Roles and Employees, so those never grow past their capacity
+		Roles:     make([]seedRole, 0, profile.Restaurants*profile.Roles),
+		Employees: make([]seedEmployee, 0, profile.Restaurants*profile.Employees),
+	}
+
+	// Every table counts its IDs from the profile's base
+	var roleID, employeeID, templateID, scheduleID, shiftID int64
+
+	for r := 0; r < profile.Restaurants; r++ {
+		restaurant := seedRestaurant{
+			ID:      profile.IDBase + int64(r) + 1,
+			Name:    fmt.Sprintf("%s %s", pick(rng, restaurantPrefixes), pick(rng, restaurantSuffixes)),
+			Address: fmt.Sprintf("%d %s", 100+rng.Intn(900), pick(rng, streets)),
+			Phone:   fmt.Sprintf("555-%04d", rng.Intn(10000)),
+		}
+		f.Restaurants = append(f.Restaurants, restaurant)
+
+		roles := make([]*seedRole, 0, profile.Roles)
+		roleIDs := make([]int64, 0, profile.Roles)
+		for i := 0; i < profile.Roles; i++ {
+			roleID++
+			role := seedRoles[i%len(seedRoles)]
+			role.ID = profile.IDBase + roleID
+			role.RestaurantID = restaurant.ID
+			f.Roles = append(f.Roles, role)
+			roleIDs = append(roleIDs, role.ID)
+		}
+		for i := len(f.Roles) - profile.Roles; i < len(f.Roles); i++ {
+			roles = append(roles, &f.Roles[i])
+		}
+
+		// Each employee works one or two roles, and every role has at least one employee
+		staff := make(map[int64][]int, profile.Roles)
+		firstEmployee := len(f.Employees)
+		for i := 0; i < profile.Employees; i++ {
+			employeeID++
+			first, last := pick(rng, firstNames), pick(rng, lastNames)
+			employee := seedEmployee{
+				ID:           profile.IDBase + employeeID,
+				RestaurantID: restaurant.ID,
+				FullName:     first + " " + last,
+				Email:        fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), profile.IDBase+employeeID),
+			}
+
+			primary := roles[i%len(roles)]
+			employee.RoleIDs = append(employee.RoleIDs, primary.ID)
+			staff[primary.ID] = append(staff[primary.ID], firstEmployee+i)
+			if extra := roles[rng.Intn(len(roles))]; extra.ID != primary.ID && rng.Intn(3) == 0 {
+				employee.RoleIDs = append(employee.RoleIDs, extra.ID)
+				staff[extra.ID] = append(staff[extra.ID], firstEmployee+i)
+			}
+
+			f.Employees = append(f.Employees, employee)
+		}
+
+		for day := 0; day < 7; day++ {
+			for _, t := range [][3]string{{"Morning", "09:00:00", "15:00:00"}, {"Evening", "16:00:00", "22:00:00"}} {
+				templateID++
+				f.Templates = append(f.Templates, seedTemplate{
+					ID:           profile.IDBase + templateID,
+					RestaurantID: restaurant.ID,
+					Name:         t[0],
+					DayOfWeek:    day,
+					StartTime:    t[1],
+					EndTime:      t[2],
+					RoleIDs:      roleIDs,
+				})
+			}
+		}
+
+		for w := 0; w < profile.Weeks; w++ {
+			scheduleID++
+			schedule := seedSchedule{
+				ID:           profile.IDBase + scheduleID,
+				RestaurantID: restaurant.ID,
+				StartDate:    start.AddDate(0, 0, 7*w),
+				EndDate:      start.AddDate(0, 0, 7*w+6),
+			}
+			// All but the last week are published, as if the manager works a week ahead
+			if w < profile.Weeks-1 {
+				publishedAt := schedule.StartDate.AddDate(0, 0, -3).Add(17 * time.Hour)
+				schedule.PublishedAt = &publishedAt
+			}
+			f.Schedules = append(f.Schedules, schedule)
+
+			for d := 0; d < 7; d++ {
+				date := schedule.StartDate.AddDate(0, 0, d)
+				busy := make(map[int]bool)
+
+				for s := 0; s < profile.ShiftsPerDay; s++ {
+					shiftID++
+					slot := shiftSlots[rng.Intn(len(shiftSlots))]
+					shift := seedShift{
+						ID:           profile.IDBase + shiftID,
+						ScheduleID:   schedule.ID,
+						RestaurantID: restaurant.ID,
+						Role:         roles[s%len(roles)],
+						Date:         date,
+						StartTime:    slot[0],
+						EndTime:      slot[1],
+					}
+
+					// Leave roughly one shift in seven open, and never double-book someone on a day
+					if rng.Intn(7) != 0 {
+						candidates := staff[shift.Role.ID]
+						for _, offset := range rng.Perm(len(candidates)) {
+							if i := candidates[offset]; !busy[i] {
+								busy[i] = true
+								shift.Employee = &f.Employees[i]
+								break
+							}
+						}
+					}
+
+					f.Shifts = append(f.Shifts, shift)
+				}
+			}
+		}
+	}
+
+	return f
+}
+
+// Seed replaces the profile's rows with the fixtures in a single transaction
+func Seed(ctx context.Context, db *sql.DB, f *Fixtures) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(SeedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// COPY needs the pgx connection under the transaction's, so both run on one conn
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := teardown(ctx, tx, f.Profile); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (id, email, password, first_name, last_name, is_active)
+		VALUES ($1, $2, $3, $4, $5, true)`,
+		f.Owner.ID, f.Owner.Email, hash, f.Owner.FirstName, f.Owner.LastName)
+	if err != nil {
+		return err
+	}
+
+	restaurants := make([][]any, 0, len(f.Restaurants))
+	hours := make([][]any, 0, len(f.Restaurants)*7)
+	for _, r := range f.Restaurants {
+		restaurants = append(restaurants, []any{r.ID, f.Owner.ID, r.Name, r.Address, r.Phone})
+		for day := 0; day < 7; day++ {
+			hours = append(hours, []any{r.ID, day, false, "08:00:00", "23:00:00"})
+		}
+	}
+	if err := copyRows(ctx, conn, "restaurants", []string{"id", "employer_id", "name", "address", "phone"}, restaurants); err != nil {
+		return err
+	}
+	if err := copyRows(ctx, conn, "restaurant_operating_hours", []string{"restaurant_id", "day_of_week", "closed", "open_time", "close_time"}, hours); err != nil {
+		return err
+	}
+
+	roles := make([][]any, 0, len(f.Roles))
+	for _, r := range f.Roles {
+		roles = append(roles, []any{r.ID, r.RestaurantID, r.Name, r.Color})
+	}
+	if err := copyRows(ctx, conn, "roles", []string{"id", "restaurant_id", "name", "color"}, roles); err != nil {
+		return err
+	}
+
+	employees := make([][]any, 0, len(f.Employees))
+	employeeRoles := make([][]any, 0, len(f.Employees))
+	for _, e := range f.Employees {
+		employees = append(employees, []any{e.ID, e.RestaurantID, e.FullName, e.Email})
+		for _, roleID := range e.RoleIDs {
+			employeeRoles = append(employeeRoles, []any{e.ID, roleID})
+		}
+	}
+	if err := copyRows(ctx, conn, "employees", []string{"id", "restaurant_id", "full_name", "email"}, employees); err != nil {
+		return err
+	}
+	if err := copyRows(ctx, conn, "employee_roles", []string{"employee_id", "role_id"}, employeeRoles); err != nil {
+		return err
+	}
+
+	templates := make([][]any, 0, len(f.Templates))
+	for _, t := range f.Templates {
+		roleIDs, err := json.Marshal(t.RoleIDs)
+		if err != nil {
+			return err
+		}
+		templates = append(templates, []any{t.ID, t.RestaurantID, t.Name, t.DayOfWeek, t.StartTime, t.EndTime, string(roleIDs)})
+	}
+	if err := copyRows(ctx, conn, "shift_templates", []string{"id", "restaurant_id", "name", "day_of_week", "start_time", "end_time", "role_ids"}, templates); err != nil {
+		return err
+	}
+
+	schedules := make([][]any, 0, len(f.Schedules))
+	for _, s := range f.Schedules {
+		schedules = append(schedules, []any{s.ID, s.RestaurantID, s.StartDate.Format("2006-01-02"), s.EndDate.Format("2006-01-02"), s.PublishedAt})
+	}
+	if err := copyRows(ctx, conn, "schedules", []string{"id", "restaurant_id", "start_date", "end_date", "published_at"}, schedules); err != nil {
+		return err
+	}
+
+	shifts := make([][]any, 0, len(f.Shifts))
+	for _, s := range f.Shifts {
+		var employeeID *int64
+		var employeeName *string
+		if s.Employee != nil {
+			employeeID, employeeName = &s.Employee.ID, &s.Employee.FullName
+		}
+		shifts = append(shifts, []any{
+			s.ID, s.ScheduleID, s.RestaurantID, s.Role.ID, employeeID,
+			s.Date.Format("2006-01-02"), s.StartTime, s.EndTime, employeeName, s.Role.Name, s.Role.Color,
+		})
+	}
+	shiftColumns := []string{
+		"id", "schedule_id", "restaurant_id", "role_id", "employee_id",
+		"shift_date", "start_time", "end_time", "employee_name", "role_name", "role_color",
+	}
+	if err := copyRows(ctx, conn, "scheduled_shifts", shiftColumns, shifts); err != nil {
+		return err
+	}
+
+	// Published schedules get the baseline snapshot Publish would have taken
+	if err := snapshotPublishedSchedules(ctx, tx, `s.id BETWEEN $1 AND $2`, f.Profile.IDBase, f.Profile.IDBase+profileIDSpan-1); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Teardown deletes everything the profile seeded, leaving other data alone
+func Teardown(ctx context.Context, db *sql.DB, profile Profile) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := teardown(ctx, tx, profile); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func teardown(ctx context.Context, tx *sql.Tx, profile Profile) error {
+	from, to := profile.IDBase, profile.IDBase+profileIDSpan-1
+
+	// Shifts go first because they restrict role deletion; the rest cascades from the owner
+	if _, err := tx.ExecContext(ctx, `DELETE FROM scheduled_shifts WHERE restaurant_id BETWEEN $1 AND $2`, from, to); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id BETWEEN $1 AND $2`, from, to)
+	return err
+}
+
+// snapshotPublishedSchedules takes the baseline snapshot of the published
+// schedules matching where, a condition on schedules aliased s
+func snapshotPublishedSchedules(ctx context.Context, tx *sql.Tx, where string, args ...any) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO schedule_snapshots (schedule_id, reason, shifts, taken_at)
+		SELECT s.id, 'published', COALESCE((
+			SELECT jsonb_agg(jsonb_build_object(
+				'id', ss.id,
+				'role_id', ss.role_id,
+				'role_name', ss.role_name,
+				'role_color', ss.role_color,
+				'employee_id', ss.employee_id,
+				'employee_name', ss.employee_name,
+				'shift_date', ss.shift_date,
+				'start_time', ss.start_time,
+				'end_time', ss.end_time
+			) ORDER BY ss.id)
+			FROM scheduled_shifts ss
+			WHERE ss.schedule_id = s.id
+		), '[]'::jsonb), s.published_at
+		FROM schedules s
+		WHERE s.published_at IS NOT NULL AND `+where, args...)
+	return err
+}
+
+// copyRows bulk-loads rows with COPY, which keeps the load-test profile fast.
+// It runs in the transaction open on conn.
+func copyRows(ctx context.Context, conn *sql.Conn, table string, columns []string, rows [][]any) error {
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("copying into %s: %T is not a pgx connection", table, driverConn)
+		}
+		if _, err := stdConn.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return fmt.Errorf("copying into %s: %w", table, err)
+		}
+		return nil
+	})
+}
+
+func mondayOf(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
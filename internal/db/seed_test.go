@@ -0,0 +1,52 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildIsDeterministic(t *testing.T) {
+	profile, _ := LookupProfile("demo")
+	start := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	a := Build(profile, 42, start)
+	b := Build(profile, 42, start)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed produced different fixtures")
+	}
+
+	c := Build(profile, 43, start)
+	if reflect.DeepEqual(a.Shifts, c.Shifts) {
+		t.Error("a different seed produced the same shifts")
+	}
+
+	if got := a.Schedules[0].StartDate.Weekday(); got != time.Monday {
+		t.Errorf("first schedule starts on %s, want Monday", got)
+	}
+}
+
+func TestBuildStaysInProfileRange(t *testing.T) {
+	for _, profile := range Profiles {
+		f := Build(profile, 1, time.Now())
+
+		if len(f.Shifts) != profile.Shifts() {
+			t.Errorf("%s: got %d shifts, want %d", profile.Name, len(f.Shifts), profile.Shifts())
+		}
+
+		last := f.Shifts[len(f.Shifts)-1].ID
+		if f.Shifts[0].ID <= profile.IDBase || last >= profile.IDBase+profileIDSpan {
+			t.Errorf("%s: shift IDs %d..%d fall outside the profile's range", profile.Name, f.Shifts[0].ID, last)
+		}
+
+		for _, s := range f.Shifts {
+			if s.Employee != nil && s.Employee.RestaurantID != s.RestaurantID {
+				t.Fatalf("%s: shift %d is staffed from another restaurant", profile.Name, s.ID)
+			}
+		}
+	}
+
+	if load, _ := LookupProfile("load-test"); load.Shifts() < 50_000 {
+		t.Errorf("load-test seeds %d shifts, want at least 50k", load.Shifts())
+	}
+}
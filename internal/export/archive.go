@@ -0,0 +1,49 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+)
+
+// ZIPContentType is the media type of the archives Archive writes
+const ZIPContentType = "application/zip"
+
+// Archive streams a ZIP of JSON documents and raw files, in the order they are added
+type Archive struct {
+	z *zip.Writer
+}
+
+func NewArchive(w io.Writer) *Archive {
+	return &Archive{z: zip.NewWriter(w)}
+}
+
+// AddJSON adds v as indented JSON under name
+func (a *Archive) AddJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := a.z.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// AddFile copies r into the archive under name
+func (a *Archive) AddFile(name string, r io.Reader) error {
+	f, err := a.z.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// Close finishes the archive; it is incomplete until then
+func (a *Archive) Close() error {
+	return a.z.Close()
+}
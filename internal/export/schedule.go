@@ -0,0 +1,186 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+// openShiftLabel stands in for the employee of an unassigned shift
+const openShiftLabel = "Open"
+
+// ScheduleWorkbook lays a schedule out as one sheet per day of its week, each
+// shift's role in its color, and a Totals sheet of hours per employee. Day
+// notes head their day's sheet and the schedule's note heads the Totals sheet.
+func ScheduleWorkbook(schedule *store.Schedule, shifts []*store.ScheduledShift, dayNotes []*store.ScheduleDayNote) (*Workbook, error) {
+	start, err := schedule.StartDate.ToTime()
+	if err != nil {
+		return nil, err
+	}
+	end, err := schedule.EndDate.ToTime()
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string][]*store.ScheduledShift)
+	for _, shift := range shifts {
+		day := shift.ShiftDate.Format("2006-01-02")
+		byDay[day] = append(byDay[day], shift)
+	}
+
+	notes := make(map[store.DateOnly]string, len(dayNotes))
+	for _, note := range dayNotes {
+		notes[note.Date] = note.Note
+	}
+
+	wb := &Workbook{}
+	header := Style{Bold: true}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		sheet := wb.AddSheet(day.Format("Mon Jan 2"), 10, 10, 18, 24, 8, 40)
+		if note, ok := notes[store.DateOnly(day.Format("2006-01-02"))]; ok {
+			sheet.AddRow(Cell{Value: "Note", Style: header}, Text(note))
+		}
+		sheet.AddRow(
+			Cell{Value: "Start", Style: header},
+			Cell{Value: "End", Style: header},
+			Cell{Value: "Role", Style: header},
+			Cell{Value: "Employee", Style: header},
+			Cell{Value: "Hours", Style: header},
+			Cell{Value: "Notes", Style: header},
+		)
+
+		dayShifts := byDay[day.Format("2006-01-02")]
+		sort.SliceStable(dayShifts, func(i, j int) bool {
+			if dayShifts[i].StartTime != dayShifts[j].StartTime {
+				return dayShifts[i].StartTime < dayShifts[j].StartTime
+			}
+			return dayShifts[i].RoleName < dayShifts[j].RoleName
+		})
+
+		for _, shift := range dayShifts {
+			sheet.AddRow(
+				Text(clock(shift.StartTime)),
+				Text(clock(shift.EndTime)),
+				Cell{Value: shift.RoleName, Style: Style{Fill: shift.RoleColor}},
+				Text(employeeName(shift)),
+				Number(ShiftHours(shift)),
+				Text(shift.Notes),
+			)
+		}
+
+		if len(dayShifts) == 0 {
+			sheet.AddRow(Text("No shifts"))
+		}
+	}
+
+	addTotals(wb, shifts, schedule.Note)
+
+	return wb, nil
+}
+
+type employeeTotal struct {
+	name   string
+	open   bool
+	shifts int
+	hours  float64
+}
+
+func addTotals(wb *Workbook, shifts []*store.ScheduledShift, note string) {
+	// keyed by employee so namesakes get their own rows; open shifts share 0
+	totals := make(map[int64]*employeeTotal)
+	for _, shift := range shifts {
+		var key int64
+		if shift.EmployeeID != nil {
+			key = *shift.EmployeeID
+		}
+		total, ok := totals[key]
+		if !ok {
+			total = &employeeTotal{name: employeeName(shift), open: key == 0}
+			totals[key] = total
+		}
+		total.shifts++
+		total.hours += ShiftHours(shift)
+	}
+
+	rows := make([]*employeeTotal, 0, len(totals))
+	for _, total := range totals {
+		rows = append(rows, total)
+	}
+	// Staff by name, with the open shifts last
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].open != rows[j].open {
+			return rows[j].open
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	header := Style{Bold: true}
+	sheet := wb.AddSheet("Totals", 24, 8, 8)
+	if note != "" {
+		sheet.AddRow(Cell{Value: "Note", Style: header}, Text(note))
+	}
+	sheet.AddRow(
+		Cell{Value: "Employee", Style: header},
+		Cell{Value: "Shifts", Style: header},
+		Cell{Value: "Hours", Style: header},
+	)
+
+	var allShifts int
+	var allHours float64
+	for _, total := range rows {
+		sheet.AddRow(Text(total.name), Cell{Value: total.shifts}, Number(total.hours))
+		allShifts += total.shifts
+		allHours += total.hours
+	}
+
+	sheet.AddRow(
+		Cell{Value: "Total", Style: header},
+		Cell{Value: allShifts, Style: header},
+		Cell{Value: allHours, Style: Style{Bold: true, Decimal: true}},
+	)
+}
+
+// ShiftHours is the length of a shift in hours; shifts end the day they start
+func ShiftHours(shift *store.ScheduledShift) float64 {
+	start, ok := parseClock(shift.StartTime)
+	if !ok {
+		return 0
+	}
+	end, ok := parseClock(shift.EndTime)
+	if !ok {
+		return 0
+	}
+
+	return end.Sub(start).Hours()
+}
+
+func parseClock(t store.TimeOfDay) (time.Time, bool) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if parsed, err := time.Parse(layout, string(t)); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clock shows a time of day as HH:MM, the way spreadsheet users type it
+func clock(t store.TimeOfDay) string {
+	parsed, ok := parseClock(t)
+	if !ok {
+		return string(t)
+	}
+	return parsed.Format("15:04")
+}
+
+func employeeName(shift *store.ScheduledShift) string {
+	if shift.EmployeeID == nil {
+		return openShiftLabel
+	}
+	if shift.EmployeeName == nil {
+		return fmt.Sprintf("Employee #%d", *shift.EmployeeID)
+	}
+	return *shift.EmployeeName
+}
@@ -0,0 +1,320 @@
+// Package export renders schedules into downloadable files.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// XLSXContentType is the media type of the workbooks Workbook.Write produces
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// maxSheetName is Excel's limit on the length of a sheet's name
+const maxSheetName = 31
+
+// Workbook is a minimal SpreadsheetML workbook: text and number cells, bold
+// text, solid fills and column widths. That is all an export needs, and it
+// keeps the format in the standard library.
+type Workbook struct {
+	Sheets []*Sheet
+}
+
+type Sheet struct {
+	Name string
+	// Widths sets the column widths in characters, from the first column on
+	Widths []float64
+	Rows   [][]Cell
+}
+
+// Cell is a value with its style; Value is a string or a number
+type Cell struct {
+	Value any
+	Style Style
+}
+
+type Style struct {
+	Bold bool
+	// Fill is a solid background as a #RRGGBB hex color; anything else is ignored
+	Fill string
+	// Decimal shows numbers with two decimals
+	Decimal bool
+}
+
+func Text(s string) Cell {
+	return Cell{Value: s}
+}
+
+func Number(n float64) Cell {
+	return Cell{Value: n, Style: Style{Decimal: true}}
+}
+
+// AddSheet appends a sheet, making its name valid and unique in the workbook
+func (wb *Workbook) AddSheet(name string, widths ...float64) *Sheet {
+	sheet := &Sheet{Name: wb.uniqueName(name), Widths: widths}
+	wb.Sheets = append(wb.Sheets, sheet)
+	return sheet
+}
+
+func (s *Sheet) AddRow(cells ...Cell) {
+	s.Rows = append(s.Rows, cells)
+}
+
+var invalidSheetChars = strings.NewReplacer("[", "(", "]", ")", ":", "-", "*", "", "?", "", "/", "-", `\`, "-")
+
+func (wb *Workbook) uniqueName(name string) string {
+	name = strings.Trim(invalidSheetChars.Replace(name), "' ")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncate(name, maxSheetName)
+	for n := 2; wb.hasSheet(candidate); n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncate(name, maxSheetName-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+func (wb *Workbook) hasSheet(name string) bool {
+	for _, s := range wb.Sheets {
+		if strings.EqualFold(s.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// Write encodes the workbook as an .xlsx file
+func (wb *Workbook) Write(w io.Writer) error {
+	if len(wb.Sheets) == 0 {
+		wb.AddSheet("Sheet")
+	}
+
+	styles := newStyleTable()
+	sheets := make([][]byte, len(wb.Sheets))
+	for i, sheet := range wb.Sheets {
+		sheets[i] = sheet.xml(styles)
+	}
+
+	z := zip.NewWriter(w)
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", wb.contentTypes()},
+		{"_rels/.rels", []byte(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`)},
+		{"xl/workbook.xml", wb.workbook()},
+		{"xl/_rels/workbook.xml.rels", wb.workbookRels()},
+		{"xl/styles.xml", styles.xml()},
+	}
+	for i, data := range sheets {
+		parts = append(parts, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), data})
+	}
+
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(part.data); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+func (wb *Workbook) contentTypes() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbook() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range wb.Sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.Name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.Bytes()
+}
+
+func (wb *Workbook) workbookRels() []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range wb.Sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(wb.Sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.Bytes()
+}
+
+func (s *Sheet) xml(styles *styleTable) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+
+	if len(s.Widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, width := range s.Widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+		}
+		b.WriteString(`</cols>`)
+	}
+
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.Rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			style := styles.index(cell.Style)
+
+			switch v := cell.Value.(type) {
+			case nil:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"/>`, ref, style)
+			case string:
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(v))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, formatNumber(v))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+
+	return b.Bytes()
+}
+
+func formatNumber(v any) string {
+	switch n := v.(type) {
+	case float64:
+		return strconv.FormatFloat(n, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(n)
+	case int64:
+		return strconv.FormatInt(n, 10)
+	default:
+		return "0"
+	}
+}
+
+// columnName turns a zero-based column index into its letters: 0 is A, 26 is AA
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// escape makes s safe as XML text or attribute; characters XML can't hold become U+FFFD
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+var hexColor = regexp.MustCompile(`^#?([0-9a-fA-F]{6})$`)
+
+// styleTable assigns every distinct Style a cellXfs index, with its font and fill
+type styleTable struct {
+	styles []Style
+	fills  []string
+}
+
+func newStyleTable() *styleTable {
+	// index 0 is the default style every unstyled cell uses
+	return &styleTable{styles: []Style{{}}}
+}
+
+func (t *styleTable) index(s Style) int {
+	if m := hexColor.FindStringSubmatch(s.Fill); m != nil {
+		s.Fill = strings.ToUpper(m[1])
+	} else {
+		s.Fill = ""
+	}
+
+	for i, existing := range t.styles {
+		if existing == s {
+			return i
+		}
+	}
+	t.styles = append(t.styles, s)
+	return len(t.styles) - 1
+}
+
+func (t *styleTable) fillID(color string) int {
+	if color == "" {
+		return 0
+	}
+	for i, existing := range t.fills {
+		if existing == color {
+			return i + 2
+		}
+	}
+	t.fills = append(t.fills, color)
+	// 0 and 1 are the none and gray125 fills Excel requires first
+	return len(t.fills) + 1
+}
+
+func (t *styleTable) xml() []byte {
+	var xfs bytes.Buffer
+	for _, s := range t.styles {
+		font := 0
+		if s.Bold {
+			font = 1
+		}
+		// 2 is the built-in "0.00" number format
+		numFmt := 0
+		if s.Decimal {
+			numFmt = 2
+		}
+		fmt.Fprintf(&xfs, `<xf numFmtId="%d" fontId="%d" fillId="%d" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1" applyFill="1"/>`, numFmt, font, t.fillID(s.Fill))
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>`)
+	fmt.Fprintf(&b, `<fills count="%d"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`, len(t.fills)+2)
+	for _, color := range t.fills {
+		fmt.Fprintf(&b, `<fill><patternFill patternType="solid"><fgColor rgb="FF%s"/><bgColor indexed="64"/></patternFill></fill>`, color)
+	}
+	b.WriteString(`</fills>`)
+	b.WriteString(`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	b.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(&b, `<cellXfs count="%d">%s</cellXfs>`, len(t.styles), xfs.String())
+	b.WriteString(`</styleSheet>`)
+
+	return b.Bytes()
+}
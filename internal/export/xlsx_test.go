@@ -0,0 +1,136 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/store"
+)
+
+func TestScheduleWorkbook(t *testing.T) {
+	alex, sam := int64(1), int64(2)
+	alexName, samName := "Alex Smith", "Sam <Lee> & Co"
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	schedule := &store.Schedule{StartDate: "2026-03-02", EndDate: "2026-03-08"}
+	shifts := []*store.ScheduledShift{
+		{EmployeeID: &alex, EmployeeName: &alexName, RoleName: "Server", RoleColor: "#3498db", ShiftDate: monday, StartTime: "09:00:00", EndTime: "17:00:00"},
+		{EmployeeID: &sam, EmployeeName: &samName, RoleName: "Bartender", RoleColor: "not-a-color", ShiftDate: monday, StartTime: "16:30:00", EndTime: "23:00:00", Notes: "=HYPERLINK(\"x\")"},
+		{EmployeeID: &alex, EmployeeName: &alexName, RoleName: "Server", RoleColor: "#3498db", ShiftDate: monday.AddDate(0, 0, 2), StartTime: "18:00:00", EndTime: "22:00:00"},
+		{RoleName: "Host", ShiftDate: monday.AddDate(0, 0, 6), StartTime: "10:00", EndTime: "14:00"},
+	}
+
+	dayNotes := []*store.ScheduleDayNote{{Date: "2026-03-03", Note: "Health inspection"}}
+
+	wb, err := ScheduleWorkbook(schedule, shifts, dayNotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(wb.Sheets) != 8 {
+		t.Fatalf("expected 7 day sheets and totals, got %d sheets", len(wb.Sheets))
+	}
+	if wb.Sheets[0].Name != "Mon Mar 2" || wb.Sheets[7].Name != "Totals" {
+		t.Errorf("unexpected sheet names %q, %q", wb.Sheets[0].Name, wb.Sheets[7].Name)
+	}
+
+	if note := wb.Sheets[1].Rows[0]; len(note) != 2 || note[1].Value != "Health inspection" {
+		t.Errorf("expected Tuesday's sheet to start with its note, got %v", note)
+	}
+	if first := wb.Sheets[0].Rows[0]; first[0].Value != "Start" {
+		t.Errorf("expected Monday's sheet to start with the header, got %v", first)
+	}
+
+	totals := wb.Sheets[7].Rows
+	// header, Alex, Sam, Open, total
+	if len(totals) != 5 {
+		t.Fatalf("expected 5 totals rows, got %d", len(totals))
+	}
+	if totals[1][0].Value != "Alex Smith" || totals[1][2].Value != 12.0 {
+		t.Errorf("unexpected totals for Alex: %v", totals[1])
+	}
+	if totals[3][0].Value != "Open" || totals[4][2].Value != 22.5 {
+		t.Errorf("unexpected open or total rows: %v, %v", totals[3], totals[4])
+	}
+
+	var buf bytes.Buffer
+	if err := wb.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(data)
+
+		// every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err != nil {
+				if err != io.EOF {
+					t.Errorf("%s is not well-formed: %v", f.Name, err)
+				}
+				break
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/styles.xml", "xl/worksheets/sheet8.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+
+	if !strings.Contains(parts["xl/styles.xml"], `rgb="FF3498DB"`) {
+		t.Error("expected the role color as a fill")
+	}
+	if strings.Count(parts["xl/styles.xml"], `patternType="solid"`) != 1 {
+		t.Error("expected invalid colors to be left unfilled")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet1.xml"], "Sam &lt;Lee&gt; &amp; Co") {
+		t.Error("expected cell text to be escaped")
+	}
+	if strings.Contains(parts["xl/worksheets/sheet1.xml"], "<f>") {
+		t.Error("notes must be stored as text, never formulas")
+	}
+}
+
+func TestUniqueSheetNames(t *testing.T) {
+	wb := &Workbook{}
+	wb.AddSheet("Week [1]: a/b")
+	wb.AddSheet("Week (1)- a-b")
+	wb.AddSheet(strings.Repeat("x", 40))
+
+	if wb.Sheets[0].Name != "Week (1)- a-b" || wb.Sheets[1].Name != "Week (1)- a-b (2)" {
+		t.Errorf("unexpected names %q, %q", wb.Sheets[0].Name, wb.Sheets[1].Name)
+	}
+	if len(wb.Sheets[2].Name) != maxSheetName {
+		t.Errorf("expected names cut to %d characters, got %q", maxSheetName, wb.Sheets[2].Name)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
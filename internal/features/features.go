@@ -0,0 +1,164 @@
+package features
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/balebbae/RESA/internal/billing"
+)
+
+type Flag string
+
+const (
+	AutoPopulate     Flag = "auto_populate"
+	AutoAssign       Flag = "auto_assign"
+	ScheduleEmails   Flag = "schedule_emails"
+	SMSNotifications Flag = "sms_notifications"
+	APIKeys          Flag = "api_keys"
+)
+
+// All lists every known flag in a stable order
+var All = []Flag{AutoPopulate, AutoAssign, ScheduleEmails, SMSNotifications, APIKeys}
+
+// planDefaults holds the flags each plan tier turns on; anything missing is off
+var planDefaults = map[billing.Plan]map[Flag]bool{
+	billing.PlanFree: {
+		AutoPopulate:   true,
+		ScheduleEmails: true,
+	},
+	billing.PlanPro: {
+		AutoPopulate:   true,
+		AutoAssign:     true,
+		ScheduleEmails: true,
+	},
+	billing.PlanBusiness: {
+		AutoPopulate:     true,
+		AutoAssign:       true,
+		ScheduleEmails:   true,
+		SMSNotifications: true,
+		APIKeys:          true,
+	},
+}
+
+// Config holds flag overrides, applied in order: plan < global < restaurant < user,
+// except that Disabled is a kill switch nothing turns back on
+type Config struct {
+	Enabled     []Flag
+	Disabled    []Flag
+	Restaurants map[int64]map[Flag]bool
+	Users       map[int64]map[Flag]bool
+}
+
+type Resolver struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+func NewResolver(cfg Config) *Resolver {
+	return &Resolver{cfg: cfg}
+}
+
+// SetConfig replaces the overrides, e.g. when the configuration is reloaded
+func (r *Resolver) SetConfig(cfg Config) {
+	r.mu.Lock()
+	r.cfg = cfg
+	r.mu.Unlock()
+}
+
+// Resolve returns the state of every flag for a restaurant owned by userID on the given plan
+func (r *Resolver) Resolve(plan billing.Plan, restaurantID, userID int64) map[Flag]bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	flags := make(map[Flag]bool, len(All))
+	for _, flag := range All {
+		flags[flag] = planDefaults[plan][flag]
+	}
+
+	for _, flag := range r.cfg.Enabled {
+		flags[flag] = true
+	}
+	for flag, on := range r.cfg.Restaurants[restaurantID] {
+		flags[flag] = on
+	}
+	for flag, on := range r.cfg.Users[userID] {
+		flags[flag] = on
+	}
+	for _, flag := range r.cfg.Disabled {
+		flags[flag] = false
+	}
+
+	return flags
+}
+
+// Enabled reports whether a single flag is on
+func (r *Resolver) Enabled(flag Flag, plan billing.Plan, restaurantID, userID int64) bool {
+	return r.Resolve(plan, restaurantID, userID)[flag]
+}
+
+// ParseList parses a comma-separated list of flag names, e.g. "auto_assign,api_keys"
+func ParseList(spec string) ([]Flag, error) {
+	var flags []Flag
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		flag, err := parseFlag(name)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
+
+// ParseOverrides parses per-entity overrides in the form "12:auto_assign=true,12:api_keys=false,40:sms_notifications=true"
+func ParseOverrides(spec string) (map[int64]map[Flag]bool, error) {
+	overrides := make(map[int64]map[Flag]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		idPart, rest, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature override %q", entry)
+		}
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid feature override %q", entry)
+		}
+
+		id, err := strconv.ParseInt(idPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feature override %q: %w", entry, err)
+		}
+		flag, err := parseFlag(name)
+		if err != nil {
+			return nil, err
+		}
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feature override %q: %w", entry, err)
+		}
+
+		if overrides[id] == nil {
+			overrides[id] = make(map[Flag]bool)
+		}
+		overrides[id][flag] = on
+	}
+	return overrides, nil
+}
+
+func parseFlag(name string) (Flag, error) {
+	for _, flag := range All {
+		if string(flag) == name {
+			return flag, nil
+		}
+	}
+	return "", fmt.Errorf("unknown feature flag %q", name)
+}
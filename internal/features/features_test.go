@@ -0,0 +1,41 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/balebbae/RESA/internal/billing"
+)
+
+func TestResolve(t *testing.T) {
+	resolver := NewResolver(Config{
+		Enabled:     []Flag{APIKeys},
+		Disabled:    []Flag{SMSNotifications},
+		Restaurants: map[int64]map[Flag]bool{12: {AutoAssign: true, SMSNotifications: true}},
+		Users:       map[int64]map[Flag]bool{3: {APIKeys: false, SMSNotifications: true}},
+	})
+
+	tests := []struct {
+		name         string
+		plan         billing.Plan
+		restaurantID int64
+		userID       int64
+		flag         Flag
+		want         bool
+	}{
+		{"plan default", billing.PlanFree, 1, 1, AutoPopulate, true},
+		{"off on the plan", billing.PlanFree, 1, 1, AutoAssign, false},
+		{"enabled globally", billing.PlanFree, 1, 1, APIKeys, true},
+		{"restaurant override", billing.PlanFree, 12, 1, AutoAssign, true},
+		{"user override", billing.PlanFree, 1, 3, APIKeys, false},
+		{"killed on the plan", billing.PlanBusiness, 1, 1, SMSNotifications, false},
+		{"killed despite the restaurant", billing.PlanBusiness, 12, 1, SMSNotifications, false},
+		{"killed despite the user", billing.PlanBusiness, 1, 3, SMSNotifications, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolver.Enabled(tt.flag, tt.plan, tt.restaurantID, tt.userID); got != tt.want {
+				t.Errorf("Enabled(%s) = %v, want %v", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"fmt"
+	"time"
+)
+
+var spanishWeekdays = [...]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"}
+
+var spanishMonths = [...]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"}
+
+// FormatDate formats a date with its year, e.g. "Mon, Jan 2, 2006" or "lun, 2 ene 2006"
+func FormatDate(locale Locale, t time.Time) string {
+	switch locale {
+	case Spanish:
+		return fmt.Sprintf("%s, %d %s %d", spanishWeekdays[t.Weekday()][:3], t.Day(), spanishMonths[t.Month()-1], t.Year())
+	default:
+		return t.Format("Mon, Jan 2, 2006")
+	}
+}
+
+// FormatWeekday formats a date with the full weekday and no year, e.g. "Monday, Jan 2" or "lunes, 2 ene"
+func FormatWeekday(locale Locale, t time.Time) string {
+	switch locale {
+	case Spanish:
+		return fmt.Sprintf("%s, %d %s", spanishWeekdays[t.Weekday()], t.Day(), spanishMonths[t.Month()-1])
+	default:
+		return t.Format("Monday, Jan 2")
+	}
+}
+
+// FormatTime formats a time of day, e.g. "9:00 PM" or "21:00"
+func FormatTime(locale Locale, t time.Time) string {
+	switch locale {
+	case Spanish:
+		return t.Format("15:04")
+	default:
+		return t.Format("3:04 PM")
+	}
+}
+
+// FormatShortDate formats a date without the year, e.g. "Mon, Jan 2" or "lun, 2 ene"
+func FormatShortDate(locale Locale, t time.Time) string {
+	switch locale {
+	case Spanish:
+		return fmt.Sprintf("%s, %d %s", spanishWeekdays[t.Weekday()][:3], t.Day(), spanishMonths[t.Month()-1])
+	default:
+		return t.Format("Mon, Jan 2")
+	}
+}
+
+// FormatDay formats a YYYY-MM-DD day like FormatDate; anything else comes back as it was
+func FormatDay(locale Locale, day string) string {
+	t, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return day
+	}
+	return FormatDate(locale, t)
+}
+
+// FormatTimeOfDay formats a wall-clock HH:MM:SS or HH:MM time like FormatTime;
+// anything else comes back as it was
+func FormatTimeOfDay(locale Locale, clock string) string {
+	t, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		if t, err = time.Parse("15:04", clock); err != nil {
+			return clock
+		}
+	}
+	return FormatTime(locale, t)
+}
@@ -0,0 +1,44 @@
+package i18n
+
+import "testing"
+
+func TestFormatDay(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		day    string
+		want   string
+	}{
+		{English, "2026-03-02", "Mon, Mar 2, 2026"},
+		{Spanish, "2026-03-02", "lun, 2 mar 2026"},
+		{Spanish, "2026-09-13", "dom, 13 sept 2026"},
+		{English, "2026-02-30", "2026-02-30"},
+		{Spanish, "not a day", "not a day"},
+		{English, "", ""},
+	}
+	for _, tt := range tests {
+		if got := FormatDay(tt.locale, tt.day); got != tt.want {
+			t.Errorf("FormatDay(%s, %q) = %q, want %q", tt.locale, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTimeOfDay(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		clock  string
+		want   string
+	}{
+		{English, "21:30:00", "9:30 PM"},
+		{English, "09:05", "9:05 AM"},
+		{English, "00:00", "12:00 AM"},
+		{Spanish, "21:30:00", "21:30"},
+		{Spanish, "09:05", "09:05"},
+		{English, "25:00", "25:00"},
+		{Spanish, "noon", "noon"},
+	}
+	for _, tt := range tests {
+		if got := FormatTimeOfDay(tt.locale, tt.clock); got != tt.want {
+			t.Errorf("FormatTimeOfDay(%s, %q) = %q, want %q", tt.locale, tt.clock, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English Locale = "en"
+	Spanish Locale = "es"
+)
+
+// Default is used when neither the user nor the request asks for a supported locale
+const Default = English
+
+// Supported lists every locale with translation catalogs, in a stable order
+var Supported = []Locale{English, Spanish}
+
+// Parse matches a locale tag like "es" or "es-MX" to a supported locale by its primary language
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	for _, locale := range Supported {
+		if string(locale) == tag {
+			return locale, true
+		}
+	}
+
+	return "", false
+}
+
+// FromAcceptLanguage picks the supported locale the client prefers most, honoring q-values
+func FromAcceptLanguage(header string) (Locale, bool) {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		if fields[0] == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: fields[0], q: q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if locale, ok := Parse(c.tag); ok {
+			return locale, true
+		}
+	}
+
+	return "", false
+}
+
+// Resolve returns the first supported locale among the preferences, e.g. a saved setting then a fallback
+func Resolve(preferences ...*string) Locale {
+	for _, preference := range preferences {
+		if preference == nil {
+			continue
+		}
+		if locale, ok := Parse(*preference); ok {
+			return locale
+		}
+	}
+
+	return Default
+}
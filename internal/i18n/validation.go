@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	esTranslations "github.com/go-playground/validator/v10/translations/es"
+)
+
+// ValidationTranslator renders validator errors as readable messages in each supported locale
+type ValidationTranslator struct {
+	universal *ut.UniversalTranslator
+}
+
+// NewValidationTranslator registers the en and es catalogs on v and reports fields by their json names
+func NewValidationTranslator(v *validator.Validate) (*ValidationTranslator, error) {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	universal := ut.New(en.New(), en.New(), es.New())
+
+	enTrans, _ := universal.GetTranslator(string(English))
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return nil, err
+	}
+
+	esTrans, _ := universal.GetTranslator(string(Spanish))
+	if err := esTranslations.RegisterDefaultTranslations(v, esTrans); err != nil {
+		return nil, err
+	}
+
+	return &ValidationTranslator{universal: universal}, nil
+}
+
+// Translate returns err's message in the locale; errors that didn't come from the validator are returned as is
+func (t *ValidationTranslator) Translate(err error, locale Locale) string {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err.Error()
+	}
+
+	trans, _ := t.universal.GetTranslator(string(locale))
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fieldError.Translate(trans))
+	}
+
+	return strings.Join(messages, "; ")
+}
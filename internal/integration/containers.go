@@ -0,0 +1,198 @@
+//go:build integration
+
+// Package integration starts the Postgres and Redis containers that the
+// integration tests run against. It drives the docker CLI directly, so the
+// tests need docker on PATH and nothing else:
+//
+//	go test -tags=integration ./...
+//
+// Each test package starts its own containers from TestMain and removes them
+// when its tests finish. CI runs them in the integration job of the audit
+// workflow.
+//
+// This stands in for testcontainers-go rather than wrapping it: the library
+// brings the docker engine client and containerd into go.mod for every build,
+// not just the tagged tests, and its releases pin docker client versions that
+// break each other's APIs. Two containers on ports picked here don't need more
+// than `docker run` and `docker rm`, and no docker output is parsed beyond the
+// container ID.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/balebbae/RESA/internal/db"
+	"github.com/balebbae/RESA/internal/store/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	postgresImage = "postgres:16.3"
+	redisImage    = "redis:6.2-alpine"
+
+	// startTimeout bounds pulling an image and waiting for it to accept connections
+	startTimeout = 2 * time.Minute
+)
+
+// ErrNoDocker is returned when the docker CLI isn't installed or its daemon
+// doesn't answer, so callers can skip instead of fail
+var ErrNoDocker = errors.New("integration: docker is not available")
+
+// Container is a running container with one published port
+type Container struct {
+	ID   string
+	Addr string
+}
+
+// Terminate stops and removes the container
+func (c *Container) Terminate() error {
+	_, err := docker(context.Background(), "rm", "--force", "--volumes", c.ID)
+	return err
+}
+
+// Env is a migrated Postgres database and an empty Redis
+type Env struct {
+	DB     *sql.DB
+	DBAddr string
+	Redis  *redis.Client
+
+	containers []*Container
+}
+
+// Start runs Postgres and Redis, applies every migration and connects to both.
+// Close removes the containers again.
+func Start(ctx context.Context) (*Env, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, ErrNoDocker
+	}
+	if _, err := docker(ctx, "version", "--format", "{{.Server.Version}}"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDocker, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+
+	env := &Env{}
+
+	pg, err := run(ctx, postgresImage, "5432/tcp",
+		"-e", "POSTGRES_DB=resa",
+		"-e", "POSTGRES_USER=admin",
+		"-e", "POSTGRES_PASSWORD=adminpassword",
+	)
+	if err != nil {
+		return nil, err
+	}
+	env.containers = append(env.containers, pg)
+
+	env.DBAddr = fmt.Sprintf("postgres://admin:adminpassword@%s/resa?sslmode=disable", pg.Addr)
+	if err := waitFor(ctx, func() error {
+		conn, err := db.New(env.DBAddr, 5, 5, "15m", "1h")
+		if err != nil {
+			return err
+		}
+		env.DB = conn
+		return nil
+	}); err != nil {
+		env.Close()
+		return nil, fmt.Errorf("waiting for postgres: %w", err)
+	}
+
+	if err := Migrate(ctx, env.DB); err != nil {
+		env.Close()
+		return nil, err
+	}
+
+	rd, err := run(ctx, redisImage, "6379/tcp")
+	if err != nil {
+		env.Close()
+		return nil, err
+	}
+	env.containers = append(env.containers, rd)
+
+	env.Redis = cache.NewRedisClient(rd.Addr, "", 0)
+	if err := waitFor(ctx, func() error {
+		return env.Redis.Ping(ctx).Err()
+	}); err != nil {
+		env.Close()
+		return nil, fmt.Errorf("waiting for redis: %w", err)
+	}
+
+	return env, nil
+}
+
+// Close disconnects and removes the containers
+func (e *Env) Close() error {
+	if e.DB != nil {
+		e.DB.Close()
+	}
+	if e.Redis != nil {
+		e.Redis.Close()
+	}
+
+	var errs []error
+	for _, c := range e.containers {
+		errs = append(errs, c.Terminate())
+	}
+	return errors.Join(errs...)
+}
+
+// run starts image in the background with port published on a free local port
+func run(ctx context.Context, image, port string, args ...string) (*Container, error) {
+	addr, err := freeAddr()
+	if err != nil {
+		return nil, err
+	}
+
+	runArgs := append([]string{"run", "--detach", "--publish", addr + ":" + port}, args...)
+	out, err := docker(ctx, append(runArgs, image)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Container{ID: strings.TrimSpace(out), Addr: addr}, nil
+}
+
+// freeAddr is a loopback address with a port nothing is listening on
+func freeAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// waitFor retries ready until it succeeds or ctx is done
+func waitFor(ctx context.Context, ready func() error) error {
+	for {
+		err := ready()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
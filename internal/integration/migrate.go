@@ -0,0 +1,64 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Migrate applies every up migration in cmd/migrate/migrations, in order, the
+// way `make migrate-up` would on an empty database
+func Migrate(ctx context.Context, db *sql.DB) error {
+	dir, err := migrationsDir()
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("integration: no migrations in %s", dir)
+	}
+	// the six-digit sequence prefix sorts lexically
+	sort.Strings(files)
+
+	for _, file := range files {
+		query, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if _, err := db.ExecContext(ctx, string(query)); err != nil {
+			return fmt.Errorf("migration %s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+// migrationsDir finds the migrations from the module root, so it works from
+// whichever package directory go test runs in
+func migrationsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, "cmd", "migrate", "migrations"), nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("integration: go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
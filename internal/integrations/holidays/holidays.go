@@ -0,0 +1,54 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrUnknownRegion is returned for a country the provider has no holidays for
+var ErrUnknownRegion = errors.New("no public holidays are known for that region")
+
+// Holiday is a public holiday on one date
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	// LocalName is the holiday's name in the region's language, Name in English
+	LocalName string `json:"local_name"`
+	Name      string `json:"name"`
+}
+
+// Provider fetches a region's public holidays. A subdivision's holidays are
+// the country's nationwide ones and its own.
+type Provider interface {
+	Holidays(ctx context.Context, region string, year int) ([]Holiday, error)
+}
+
+type Config struct {
+	Enabled  bool
+	Provider string // only "nager-date" for now
+}
+
+// New returns the configured provider
+func New(cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case "", "nager-date":
+		return NewNagerDate(), nil
+	default:
+		return nil, fmt.Errorf("unknown holiday provider %q", cfg.Provider)
+	}
+}
+
+var regionPattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+// ParseRegion validates an ISO 3166-1 country code, optionally with an ISO
+// 3166-2 subdivision (US, US-CA, GB-SCT), returning it upper-cased with its country
+func ParseRegion(region string) (normalized, country string, err error) {
+	normalized = strings.ToUpper(strings.TrimSpace(region))
+	if !regionPattern.MatchString(normalized) {
+		return "", "", fmt.Errorf("holiday region %q must be a country code such as US, or a subdivision such as US-CA", region)
+	}
+	country, _, _ = strings.Cut(normalized, "-")
+	return normalized, country, nil
+}
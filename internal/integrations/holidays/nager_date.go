@@ -0,0 +1,82 @@
+package holidays
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const nagerDateURL = "https://date.nager.at/api/v3"
+
+// NagerDate fetches public holidays from the Nager.Date API, which needs no key
+type NagerDate struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewNagerDate() *NagerDate {
+	return &NagerDate{
+		baseURL:    nagerDateURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type nagerDateHoliday struct {
+	Date      string `json:"date"`
+	LocalName string `json:"localName"`
+	Name      string `json:"name"`
+	// Global holidays are nationwide; the others list the subdivisions keeping them
+	Global   bool     `json:"global"`
+	Counties []string `json:"counties"`
+	Types    []string `json:"types"`
+}
+
+func (c *NagerDate) Holidays(ctx context.Context, region string, year int) ([]Holiday, error) {
+	region, country, err := ParseRegion(region)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/PublicHolidays/%d/%s", c.baseURL, year, country), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrUnknownRegion
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("nager.date: %s: %s", resp.Status, body)
+	}
+
+	var found []nagerDateHoliday
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, err
+	}
+
+	holidays := make([]Holiday, 0, len(found))
+	for _, h := range found {
+		// bank, school and observance days aren't days off for everyone
+		if !slices.Contains(h.Types, "Public") {
+			continue
+		}
+		if !h.Global && !slices.Contains(h.Counties, region) {
+			continue
+		}
+		holidays = append(holidays, Holiday{Date: h.Date, LocalName: h.LocalName, Name: h.Name})
+	}
+
+	return holidays, nil
+}
@@ -0,0 +1,65 @@
+package holidays
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNagerDateHolidays(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.URL.Path == "/PublicHolidays/2026/ZZ" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"date":"2026-01-01","localName":"New Year's Day","name":"New Year's Day","global":true,"counties":null,"types":["Public"]},
+			{"date":"2026-03-31","localName":"César Chávez Day","name":"César Chávez Day","global":false,"counties":["US-CA","US-TX"],"types":["Public"]},
+			{"date":"2026-04-20","localName":"Patriots' Day","name":"Patriots' Day","global":false,"counties":["US-MA","US-ME"],"types":["Public"]},
+			{"date":"2026-10-31","localName":"Halloween","name":"Halloween","global":true,"counties":null,"types":["Observance"]}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewNagerDate()
+	client.baseURL = server.URL
+
+	holidays, err := client.Holidays(context.Background(), "us-ca", 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/PublicHolidays/2026/US" {
+		t.Errorf("path = %s, want the country's holidays", path)
+	}
+	// nationwide and Californian public holidays, no other state's or observances
+	if len(holidays) != 2 || holidays[0].Date != "2026-01-01" || holidays[1].LocalName != "César Chávez Day" {
+		t.Errorf("holidays = %+v", holidays)
+	}
+
+	holidays, err = client.Holidays(context.Background(), "US", 2026)
+	if err != nil || len(holidays) != 1 {
+		t.Errorf("country holidays = %+v, err = %v, want New Year's Day only", holidays, err)
+	}
+
+	if _, err := client.Holidays(context.Background(), "ZZ", 2026); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("err = %v, want ErrUnknownRegion", err)
+	}
+}
+
+func TestParseRegion(t *testing.T) {
+	for region, want := range map[string]string{"us": "US", " GB-SCT ": "GB-SCT", "DE-BY": "DE-BY"} {
+		got, _, err := ParseRegion(region)
+		if err != nil || got != want {
+			t.Errorf("ParseRegion(%q) = %q, %v, want %q", region, got, err, want)
+		}
+	}
+	for _, region := range []string{"", "USA", "US-", "US_CA", "US-CALI"} {
+		if _, _, err := ParseRegion(region); err == nil {
+			t.Errorf("ParseRegion(%q) is valid", region)
+		}
+	}
+}
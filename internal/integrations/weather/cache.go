@@ -0,0 +1,56 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache keeps forecasts in memory for a while, so reloading a schedule doesn't call the
+// provider every time. Locations are rounded to about a kilometre.
+type Cache struct {
+	forecaster Forecaster
+	ttl        time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	days    []Day
+	expires time.Time
+}
+
+func NewCache(f Forecaster, ttl time.Duration) *Cache {
+	return &Cache{forecaster: f, ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+func (c *Cache) Forecast(ctx context.Context, latitude, longitude float64, from, to time.Time) ([]Day, error) {
+	key := fmt.Sprintf("%.2f,%.2f,%s,%s", latitude, longitude, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.days, nil
+	}
+
+	days, err := c.forecaster.Forecast(ctx, latitude, longitude, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{days: days, expires: now.Add(c.ttl)}
+
+	return days, nil
+}
@@ -0,0 +1,154 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	openMeteoURL         = "https://api.open-meteo.com/v1/forecast"
+	openMeteoCustomerURL = "https://customer-api.open-meteo.com/v1/forecast"
+	// openMeteoDays is how far ahead Open-Meteo forecasts, today included
+	openMeteoDays = 16
+)
+
+// OpenMeteo fetches forecasts from Open-Meteo's forecast API
+type OpenMeteo struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewOpenMeteo(apiKey string) *OpenMeteo {
+	baseURL := openMeteoURL
+	if apiKey != "" {
+		baseURL = openMeteoCustomerURL
+	}
+	return &OpenMeteo{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+type openMeteoResponse struct {
+	Daily struct {
+		Time                        []string   `json:"time"`
+		WeatherCode                 []*int     `json:"weather_code"`
+		TemperatureMax              []*float64 `json:"temperature_2m_max"`
+		TemperatureMin              []*float64 `json:"temperature_2m_min"`
+		PrecipitationProbabilityMax []*int     `json:"precipitation_probability_max"`
+		WindSpeedMax                []*float64 `json:"wind_speed_10m_max"`
+	} `json:"daily"`
+}
+
+func (c *OpenMeteo) Forecast(ctx context.Context, latitude, longitude float64, from, to time.Time) ([]Day, error) {
+	// the API rejects dates outside its forecast window, so ask only for the part inside it
+	today := c.now().UTC().Truncate(24 * time.Hour)
+	last := today.AddDate(0, 0, openMeteoDays-1)
+	if from.Before(today) {
+		from = today
+	}
+	if to.After(last) {
+		to = last
+	}
+	if to.Before(from) {
+		return []Day{}, nil
+	}
+
+	q := url.Values{}
+	q.Set("latitude", strconv.FormatFloat(latitude, 'f', 4, 64))
+	q.Set("longitude", strconv.FormatFloat(longitude, 'f', 4, 64))
+	q.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max,wind_speed_10m_max")
+	q.Set("timezone", "auto")
+	q.Set("start_date", from.Format("2006-01-02"))
+	q.Set("end_date", to.Format("2006-01-02"))
+	if c.apiKey != "" {
+		q.Set("apikey", c.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("open-meteo: %s: %s", resp.Status, body)
+	}
+
+	var forecast openMeteoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&forecast); err != nil {
+		return nil, err
+	}
+
+	daily := forecast.Daily
+	days := make([]Day, 0, len(daily.Time))
+	for i, date := range daily.Time {
+		// a day the model hasn't produced has nulls
+		if i >= len(daily.WeatherCode) || daily.WeatherCode[i] == nil {
+			continue
+		}
+		day := Day{
+			Date:                date,
+			Summary:             describe(*daily.WeatherCode[i]),
+			HighC:               value(daily.TemperatureMax, i),
+			LowC:                value(daily.TemperatureMin, i),
+			PrecipitationChance: value(daily.PrecipitationProbabilityMax, i),
+			WindKPH:             value(daily.WindSpeedMax, i),
+		}
+		day.PatioWeather = patioWeather(day)
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func value[T int | float64](values []*T, i int) T {
+	if i < len(values) && values[i] != nil {
+		return *values[i]
+	}
+	var zero T
+	return zero
+}
+
+// describe names a WMO weather interpretation code
+func describe(code int) string {
+	switch {
+	case code == 0:
+		return "Clear"
+	case code <= 2:
+		return "Partly cloudy"
+	case code == 3:
+		return "Overcast"
+	case code == 45 || code == 48:
+		return "Fog"
+	case code >= 51 && code <= 57:
+		return "Drizzle"
+	case code >= 61 && code <= 67:
+		return "Rain"
+	case code >= 71 && code <= 77:
+		return "Snow"
+	case code >= 80 && code <= 82:
+		return "Rain showers"
+	case code == 85 || code == 86:
+		return "Snow showers"
+	case code >= 95:
+		return "Thunderstorms"
+	}
+	return "Unknown"
+}
@@ -0,0 +1,54 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Day is the forecast for one date at a location
+type Day struct {
+	Date    string  `json:"date"` // YYYY-MM-DD, local to the location
+	Summary string  `json:"summary"`
+	HighC   float64 `json:"high_c"`
+	LowC    float64 `json:"low_c"`
+	// PrecipitationChance is the highest chance of rain or snow during the day, in percent
+	PrecipitationChance int     `json:"precipitation_chance"`
+	WindKPH             float64 `json:"wind_kph"`
+	// PatioWeather is set on mild, dry, calm days, when outdoor seating is likely to be busy
+	PatioWeather bool `json:"patio_weather"`
+}
+
+// Forecaster fetches daily forecasts. Days the provider can't forecast yet, or no longer
+// does, are left out, so the result may cover only part of from through to.
+type Forecaster interface {
+	Forecast(ctx context.Context, latitude, longitude float64, from, to time.Time) ([]Day, error)
+}
+
+type Config struct {
+	Enabled  bool
+	Provider string // only "open-meteo" for now
+	APIKey   string // optional for Open-Meteo; a key switches to its commercial API
+	CacheTTL time.Duration
+}
+
+// New returns the configured provider, caching its forecasts for CacheTTL
+func New(cfg Config) (Forecaster, error) {
+	var f Forecaster
+	switch cfg.Provider {
+	case "", "open-meteo":
+		f = NewOpenMeteo(cfg.APIKey)
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", cfg.Provider)
+	}
+
+	if cfg.CacheTTL > 0 {
+		f = NewCache(f, cfg.CacheTTL)
+	}
+	return f, nil
+}
+
+// patioWeather is a rough rule for good outdoor dining weather
+func patioWeather(d Day) bool {
+	return d.HighC >= 18 && d.HighC <= 32 && d.PrecipitationChance < 30 && d.WindKPH < 30
+}
@@ -0,0 +1,94 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenMeteoForecast(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"daily":{
+			"time":["2026-06-01","2026-06-02","2026-06-03"],
+			"weather_code":[0,63,null],
+			"temperature_2m_max":[24.5,16.1,null],
+			"temperature_2m_min":[14.2,11.0,null],
+			"precipitation_probability_max":[5,90,null],
+			"wind_speed_10m_max":[12.0,25.3,null]
+		}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenMeteo("")
+	client.baseURL = server.URL
+	client.now = func() time.Time { return time.Date(2026, 5, 25, 9, 0, 0, 0, time.UTC) }
+
+	days, err := client.Forecast(context.Background(), 40.7128, -74.006, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the end is cut to the last forecast day, and the day without data left out
+	if want := "daily=weather_code%2Ctemperature_2m_max%2Ctemperature_2m_min%2Cprecipitation_probability_max%2Cwind_speed_10m_max&end_date=2026-06-09&latitude=40.7128&longitude=-74.0060&start_date=2026-06-01&timezone=auto"; query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+	if len(days) != 2 {
+		t.Fatalf("days = %+v, want two", days)
+	}
+	if d := days[0]; d.Summary != "Clear" || d.HighC != 24.5 || !d.PatioWeather {
+		t.Errorf("first day = %+v, want clear patio weather", d)
+	}
+	if d := days[1]; d.Summary != "Rain" || d.PrecipitationChance != 90 || d.PatioWeather {
+		t.Errorf("second day = %+v, want rain", d)
+	}
+
+	t.Run("a range outside the forecast isn't requested", func(t *testing.T) {
+		query = ""
+		days, err := client.Forecast(context.Background(), 40.7128, -74.006, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 7, 7, 0, 0, 0, 0, time.UTC))
+		if err != nil || len(days) != 0 || query != "" {
+			t.Errorf("days = %v, err = %v, query = %q", days, err, query)
+		}
+	})
+}
+
+type countingForecaster struct{ calls int }
+
+func (f *countingForecaster) Forecast(_ context.Context, _, _ float64, from, _ time.Time) ([]Day, error) {
+	f.calls++
+	return []Day{{Date: from.Format("2006-01-02")}}, nil
+}
+
+func TestCache(t *testing.T) {
+	provider := &countingForecaster{}
+	cache := NewCache(provider, time.Hour)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	from, to := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 7, 0, 0, 0, 0, time.UTC)
+	forecast := func(latitude float64) {
+		if _, err := cache.Forecast(context.Background(), latitude, -74.006, from, to); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	forecast(40.7128)
+	forecast(40.7131) // the same place, to a kilometre
+	if provider.calls != 1 {
+		t.Errorf("calls = %d, want the second forecast cached", provider.calls)
+	}
+
+	forecast(41.8781)
+	if provider.calls != 2 {
+		t.Errorf("calls = %d, want another place fetched", provider.calls)
+	}
+
+	now = now.Add(time.Hour)
+	forecast(40.7128)
+	if provider.calls != 3 {
+		t.Errorf("calls = %d, want an expired forecast fetched again", provider.calls)
+	}
+}
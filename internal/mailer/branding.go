@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	textTemplate "text/template"
+)
+
+// Branding is a restaurant's customization of an email. Subject and HeaderMessage may reference
+// the BrandingVars fields, e.g. "{{.RestaurantName}} schedule for {{.ScheduleStart}}".
+type Branding struct {
+	Subject       string
+	HeaderMessage string
+	LogoURL       string
+	AccentColor   string
+}
+
+// BrandingVars are the only values customized text can reference
+type BrandingVars struct {
+	RestaurantName string
+	EmployeeName   string
+	ScheduleStart  string
+	ScheduleEnd    string
+}
+
+// AnnouncementVars are the only values an announcement's subject and body can reference,
+// e.g. "Hi {{.FirstName}}, {{.RestaurantName}} is closed Monday"
+type AnnouncementVars struct {
+	RestaurantName string
+	EmployeeName   string
+	FirstName      string
+}
+
+// RenderedBranding is Branding with its text resolved; the zero value renders the default email
+type RenderedBranding struct {
+	Subject       string
+	HeaderMessage string
+	LogoURL       string
+	AccentColor   string
+}
+
+// Render resolves the customized text against vars. The text is executed as a plain text
+// template with no functions, and its output is only ever inserted into the email as data,
+// so html/template escapes it like any other value and owners can't inject markup.
+func (b Branding) Render(vars BrandingVars) (RenderedBranding, error) {
+	subject, err := renderBrandingText(b.Subject, vars)
+	if err != nil {
+		return RenderedBranding{}, err
+	}
+
+	header, err := renderBrandingText(b.HeaderMessage, vars)
+	if err != nil {
+		return RenderedBranding{}, err
+	}
+
+	return RenderedBranding{
+		Subject:       strings.TrimSpace(subject),
+		HeaderMessage: strings.TrimSpace(header),
+		LogoURL:       b.LogoURL,
+		AccentColor:   b.AccentColor,
+	}, nil
+}
+
+// Render executes an email template's subject and body without sending it. The body
+// is HTML and escapes every value; the subject is a header, so it is rendered as
+// plain text on a single line instead.
+func Render(templateFile string, data any) (string, string, error) {
+	subjectTmpl, err := textTemplate.ParseFS(FS, "template/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := subjectTmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return "", "", err
+	}
+
+	tmpl, err := template.ParseFS(FS, "template/"+templateFile)
+	if err != nil {
+		return "", "", err
+	}
+
+	body := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(body, "body", data); err != nil {
+		return "", "", err
+	}
+
+	return PlainText(subject.String()), body.String(), nil
+}
+
+// RenderAnnouncement resolves an announcement's text for one employee. Like Branding's,
+// it is a plain text template with no functions whose output is only used as data.
+func RenderAnnouncement(text string, vars AnnouncementVars) (string, error) {
+	out, err := renderBrandingText(text, vars)
+	return strings.TrimSpace(out), err
+}
+
+func renderBrandingText(text string, vars any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+
+	tmpl, err := textTemplate.New("branding").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	out := new(bytes.Buffer)
+	if err := tmpl.Execute(out, vars); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
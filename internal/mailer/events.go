@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sendgrid/sendgrid-go/helpers/eventwebhook"
+)
+
+// TrackingArg is the custom argument a tracked message carries its ID in; SendGrid repeats it on every event
+const TrackingArg = "resa_delivery_id"
+
+// Headers SendGrid signs its event webhook with
+const (
+	SignatureHeader = eventwebhook.VerificationHTTPHeader
+	TimestampHeader = eventwebhook.TimestampHTTPHeader
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event is one report from SendGrid's event webhook
+type Event struct {
+	// TrackingID is the ID the message was sent with, empty for untracked mail
+	TrackingID string `json:"resa_delivery_id"`
+	Email      string `json:"email"`
+	// Event is processed, delivered, deferred, bounce, dropped, spamreport or an engagement event
+	Event string `json:"event"`
+	// Type tells a bounce ("bounce") from a block ("blocked") on bounce events
+	Type      string `json:"type"`
+	Reason    string `json:"reason"`
+	Response  string `json:"response"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Time is when SendGrid recorded the event
+func (e Event) Time() time.Time {
+	return time.Unix(e.Timestamp, 0).UTC()
+}
+
+// HardBounce reports whether the receiving server rejected the address itself
+func (e Event) HardBounce() bool {
+	return e.Event == "bounce" && e.Type != "blocked"
+}
+
+// EventVerifier checks the signature on SendGrid's signed event webhook
+type EventVerifier struct {
+	publicKey *ecdsa.PublicKey
+}
+
+// NewEventVerifier takes the webhook's verification key as SendGrid shows it, base64 encoded
+func NewEventVerifier(publicKey string) (*EventVerifier, error) {
+	key, err := eventwebhook.ConvertPublicKeyBase64ToECDSA(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EventVerifier{publicKey: key}, nil
+}
+
+// ParseEvents verifies the batch's signature and decodes it
+func (v *EventVerifier) ParseEvents(payload []byte, signature, timestamp string) ([]Event, error) {
+	ok, err := eventwebhook.VerifySignature(v.publicKey, payload, signature, timestamp)
+	if err != nil || !ok {
+		return nil, ErrInvalidSignature
+	}
+
+	var events []Event
+	if err := json.Unmarshal(payload, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
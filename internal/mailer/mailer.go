@@ -1,12 +1,26 @@
 package mailer
 
-import "embed"
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/balebbae/RESA/internal/i18n"
+)
 
 const (
-	FromName                     = "Sodia"
-	maxRetries                   = 3
-	UserWelcomeTemplate          = "user_invitation.go.tmpl"
-	ScheduleNotificationTemplate = "schedule_notification.go.tmpl"
+	FromName                            = "Sodia"
+	maxRetries                          = 3
+	UserWelcomeTemplate                 = "user_invitation.go.tmpl"
+	ScheduleNotificationTemplate        = "schedule_notification.go.tmpl"
+	ScheduleChangesTemplate             = "schedule_changes.go.tmpl"
+	CertificationExpiryTemplate         = "certification_expiry.go.tmpl"
+	ShiftAcknowledgmentReminderTemplate = "shift_acknowledgment_reminder.go.tmpl"
+	StaffMilestonesTemplate             = "staff_milestones.go.tmpl"
+	DocumentAcknowledgmentTemplate      = "document_acknowledgment.go.tmpl"
+	AnnouncementTemplate                = "announcement.go.tmpl"
+	SavedReportTemplate                 = "saved_report.go.tmpl"
+	NotificationDigestTemplate          = "notification_digest.go.tmpl"
+	EmailVerificationTemplate           = "email_verification.go.tmpl"
 )
 
 //go:embed "template"
@@ -14,4 +28,24 @@ var FS embed.FS
 
 type Client interface {
 	Send(templateFile, username, email string, data any, isSandbox bool) (int, error)
-}
\ No newline at end of file
+	// SendTracked is Send for a message whose delivery events are reported back under trackingID
+	SendTracked(templateFile, username, email string, data any, isSandbox bool, trackingID string) (int, error)
+	// SendWithAttachments is Send with files attached to the message
+	SendWithAttachments(templateFile, username, email string, data any, isSandbox bool, attachments []Attachment) (int, error)
+}
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// Localized returns the locale's translation of templateFile, or templateFile itself when there is none
+func Localized(templateFile string, locale i18n.Locale) string {
+	localized := string(locale) + "/" + templateFile
+	if _, err := fs.Stat(FS, "template/"+localized); err != nil {
+		return templateFile
+	}
+	return localized
+}
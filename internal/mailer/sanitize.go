@@ -0,0 +1,108 @@
+package mailer
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TextPolicy is how free-form text owners type (shift notes, event descriptions)
+// is put into HTML emails. Every policy escapes the text first, so the worst a
+// user can do is have their markup shown literally.
+type TextPolicy int
+
+const (
+	// TextEscaped escapes every character and keeps line breaks; the default
+	TextEscaped TextPolicy = iota
+	// TextMarkdown also renders **bold**, *italic* and "- " bullet lines. It works
+	// on the escaped text, so it can only ever add those few tags.
+	TextMarkdown
+)
+
+// HTML renders s under the policy, ready to be placed in a template as is
+func (p TextPolicy) HTML(s string) template.HTML {
+	s = strings.TrimSpace(stripControl(s, true))
+	if s == "" {
+		return ""
+	}
+
+	escaped := template.HTMLEscapeString(s)
+	if p == TextMarkdown {
+		return template.HTML(renderMarkdown(escaped))
+	}
+
+	return template.HTML(strings.ReplaceAll(escaped, "\n", "<br>"))
+}
+
+// PlainText reduces s to a single line without control characters, for names,
+// titles and subjects; templates still escape the result
+func PlainText(s string) string {
+	return strings.Join(strings.Fields(stripControl(s, false)), " ")
+}
+
+// stripControl drops control and invisible formatting characters, keeping line
+// breaks (normalized to \n) and tabs only when keepLines is set
+func stripControl(s string, keepLines bool) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			if keepLines {
+				if r == '\r' {
+					return '\n'
+				}
+				return r
+			}
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+var (
+	markdownBold   = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	markdownItalic = regexp.MustCompile(`\*([^*\n]+)\*`)
+)
+
+// renderMarkdown renders the limited markdown of TextMarkdown on already escaped text
+func renderMarkdown(escaped string) string {
+	var out strings.Builder
+	inList := false
+
+	for i, line := range strings.Split(escaped, "\n") {
+		line = strings.TrimSpace(line)
+		item, isItem := strings.CutPrefix(line, "- ")
+
+		switch {
+		case isItem && !inList:
+			out.WriteString("<ul>")
+			inList = true
+		case !isItem && inList:
+			out.WriteString("</ul>")
+			inList = false
+		case !isItem && i > 0:
+			out.WriteString("<br>")
+		}
+
+		if isItem {
+			out.WriteString("<li>" + renderInline(item) + "</li>")
+			continue
+		}
+		out.WriteString(renderInline(line))
+	}
+
+	if inList {
+		out.WriteString("</ul>")
+	}
+
+	return out.String()
+}
+
+func renderInline(s string) string {
+	s = markdownBold.ReplaceAllString(s, "<strong>$1</strong>")
+	return markdownItalic.ReplaceAllString(s, "<em>$1</em>")
+}
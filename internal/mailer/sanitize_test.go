@@ -0,0 +1,70 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+)
+
+var injections = []string{
+	`<script>alert(1)</script>`,
+	`<img src=x onerror=alert(1)>`,
+	`"><svg onload=alert(1)>`,
+	`<a href="javascript:alert(1)">click</a>`,
+	`**<script>alert(1)</script>**`,
+	"- <iframe src=//evil.test></iframe>",
+	`*<style>body{display:none}</style>*`,
+}
+
+func TestTextPolicyEscapesMarkup(t *testing.T) {
+	for _, policy := range []TextPolicy{TextEscaped, TextMarkdown} {
+		for _, input := range injections {
+			out := string(policy.HTML(input))
+			for _, tag := range []string{"<script", "<img", "<svg", "<a ", "<iframe", "<style"} {
+				if strings.Contains(out, tag) {
+					t.Errorf("policy %d let %q through in %q", policy, tag, out)
+				}
+			}
+		}
+	}
+}
+
+func TestTextPolicyRendering(t *testing.T) {
+	tests := []struct {
+		policy TextPolicy
+		input  string
+		want   string
+	}{
+		{TextEscaped, "Bring **aprons**\r\nand <gloves>", "Bring **aprons**<br>and &lt;gloves&gt;"},
+		{TextEscaped, "  \x00\u200b  ", ""},
+		{TextMarkdown, "Bring **aprons** and *smile*", "Bring <strong>aprons</strong> and <em>smile</em>"},
+		{TextMarkdown, "Setup:\n- chairs\n- tables\nThanks", "Setup:<ul><li>chairs</li><li>tables</li></ul>Thanks"},
+		{TextMarkdown, "Tom & Jerry's", "Tom &amp; Jerry&#39;s"},
+	}
+
+	for _, tt := range tests {
+		if got := string(tt.policy.HTML(tt.input)); got != tt.want {
+			t.Errorf("HTML(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	if got := PlainText("Bob's\r\nBcc: evil@example.com\t\x07Diner"); got != "Bob's Bcc: evil@example.com Diner" {
+		t.Errorf("unexpected plain text %q", got)
+	}
+}
+
+func TestRenderSubjectIsPlainText(t *testing.T) {
+	data := map[string]any{"RestaurantName": "Bob's <b>Diner</b>\nBcc: x@example.com", "Certifications": nil}
+
+	subject, body, err := Render(CertificationExpiryTemplate, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject != "Certification expirations at Bob's <b>Diner</b> Bcc: x@example.com" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if strings.Contains(body, "<b>Diner</b>") {
+		t.Error("expected the body to escape the restaurant name")
+	}
+}
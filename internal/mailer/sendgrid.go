@@ -1,9 +1,8 @@
 package mailer
 
 import (
-	"bytes"
+	"encoding/base64"
 	"fmt"
-	"html/template"
 	"time"
 
 	"github.com/sendgrid/sendgrid-go"
@@ -27,29 +26,39 @@ func NewSendGrid(apiKey, fromEmail string) *SendGridMailer {
 }
 
 func (m *SendGridMailer) Send(templateFile, username, email string, data any, isSandbox bool) (int, error) {
+	return m.send(templateFile, username, email, data, isSandbox, "", nil)
+}
+
+func (m *SendGridMailer) SendTracked(templateFile, username, email string, data any, isSandbox bool, trackingID string) (int, error) {
+	return m.send(templateFile, username, email, data, isSandbox, trackingID, nil)
+}
+
+func (m *SendGridMailer) SendWithAttachments(templateFile, username, email string, data any, isSandbox bool, attachments []Attachment) (int, error) {
+	return m.send(templateFile, username, email, data, isSandbox, "", attachments)
+}
+
+func (m *SendGridMailer) send(templateFile, username, email string, data any, isSandbox bool, trackingID string, attachments []Attachment) (int, error) {
 	from := mail.NewEmail(FromName, m.fromEmail)
 	to := mail.NewEmail(username, email)
 
 	// Template parsing and building
-	tmpl, err := template.ParseFS(FS, "template/"+templateFile)
+	subject, body, err := Render(templateFile, data)
 	if err != nil {
 		return -1, err
 	}
 
-	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
-	if err != nil {
-		return -1, err
+	message := mail.NewSingleEmail(from, subject, to, "", body)
+	if trackingID != "" {
+		message.SetCustomArg(TrackingArg, trackingID)
 	}
-
-	body := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(body, "body", data)
-	if err != nil {
-		return -1, err
+	for _, a := range attachments {
+		message.AddAttachment(mail.NewAttachment().
+			SetContent(base64.StdEncoding.EncodeToString(a.Content)).
+			SetType(a.ContentType).
+			SetFilename(a.Filename).
+			SetDisposition("attachment"))
 	}
 
-	message := mail.NewSingleEmail(from, subject.String(), to, "", body.String())
-
 	message.SetMailSettings(&mail.MailSettings{
 		SandboxMode: &mail.Setting{
 			Enable: &isSandbox,
@@ -0,0 +1,237 @@
+// Command mockgen writes function-field mocks for the interfaces a storage
+// struct is made of. It is run by go generate from the package holding the
+// struct:
+//
+//	//go:generate go run ../mockgen -type Storage -out mocks_gen.go storage.go
+//
+// Every field of the struct must be an interface declared in the same file.
+// Each interface I gets a MockI whose methods call the field of the same name
+// with a Func suffix, so a test sets only the methods it expects to be called.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "Storage", "struct whose interface fields get mocks")
+	out := flag.String("out", "mocks_gen.go", "file to write")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal("usage: mockgen -type Storage -out mocks_gen.go storage.go")
+	}
+	src := flag.Arg(0)
+
+	code, err := generate(src, *typeName)
+	if err != nil {
+		log.Fatalf("mockgen: %v", err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatalf("mockgen: %v", err)
+	}
+}
+
+func generate(src, typeName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := map[string]*ast.InterfaceType{}
+	var storage *ast.StructType
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			switch t := ts.Type.(type) {
+			case *ast.InterfaceType:
+				interfaces[ts.Name.Name] = t
+			case *ast.StructType:
+				if ts.Name.Name == typeName {
+					storage = t
+				}
+			}
+		}
+	}
+	if storage == nil {
+		return nil, fmt.Errorf("%s: no struct named %s", src, typeName)
+	}
+
+	g := &generator{fset: fset, used: map[string]bool{}}
+	for _, field := range storage.Fields.List {
+		ident, ok := field.Type.(*ast.Ident)
+		if !ok || interfaces[ident.Name] == nil {
+			return nil, fmt.Errorf("%s: field %s of %s is not an interface declared in the file", src, fieldName(field), typeName)
+		}
+		if err := g.mock(ident.Name, interfaces[ident.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var head bytes.Buffer
+	fmt.Fprintf(&head, "// Code generated by mockgen -type %s %s; DO NOT EDIT.\n\n", typeName, path.Base(src))
+	fmt.Fprintf(&head, "package %s\n\n", file.Name.Name)
+
+	imports, err := usedImports(file, g.used)
+	if err != nil {
+		return nil, err
+	}
+	if len(imports) > 0 {
+		head.WriteString("import (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&head, "\t%s\n", imp)
+		}
+		head.WriteString(")\n")
+	}
+
+	code, err := format.Source(append(head.Bytes(), g.buf.Bytes()...))
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+type generator struct {
+	fset *token.FileSet
+	buf  bytes.Buffer
+	// used collects the package names the method signatures refer to
+	used map[string]bool
+}
+
+func (g *generator) mock(name string, iface *ast.InterfaceType) error {
+	mock := "Mock" + name
+	var fields, methods bytes.Buffer
+
+	for _, m := range iface.Methods.List {
+		fn, ok := m.Type.(*ast.FuncType)
+		if !ok || len(m.Names) == 0 {
+			return fmt.Errorf("%s: embedded interfaces are not supported", name)
+		}
+		method := m.Names[0].Name
+
+		ast.Inspect(fn, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok {
+					g.used[pkg.Name] = true
+				}
+			}
+			return true
+		})
+
+		fmt.Fprintf(&fields, "\t%sFunc %s\n", method, g.node(fn))
+
+		var params, args []string
+		i := 0
+		for _, p := range fn.Params.List {
+			typ := g.node(p.Type)
+			variadic := strings.HasPrefix(typ, "...")
+			count := max(len(p.Names), 1)
+			for j := 0; j < count; j++ {
+				arg := fmt.Sprintf("a%d", i)
+				if j < len(p.Names) && p.Names[j].Name != "_" {
+					arg = p.Names[j].Name
+				}
+				params = append(params, arg+" "+typ)
+				if variadic {
+					arg += "..."
+				}
+				args = append(args, arg)
+				i++
+			}
+		}
+
+		results := ""
+		if fn.Results != nil {
+			var parts []string
+			for _, r := range fn.Results.List {
+				typ := g.node(r.Type)
+				if len(r.Names) == 0 {
+					parts = append(parts, typ)
+				}
+				for _, n := range r.Names {
+					parts = append(parts, n.Name+" "+typ)
+				}
+			}
+			results = strings.Join(parts, ", ")
+			if len(parts) > 1 || len(fn.Results.List[0].Names) > 0 {
+				results = "(" + results + ")"
+			}
+		}
+
+		call := fmt.Sprintf("m.%sFunc(%s)", method, strings.Join(args, ", "))
+		if results != "" {
+			call = "return " + call
+		}
+		fmt.Fprintf(&methods, "\nfunc (m *%s) %s(%s) %s {\n", mock, method, strings.Join(params, ", "), results)
+		fmt.Fprintf(&methods, "\tif m.%sFunc == nil {\n\t\tpanic(%s)\n\t}\n", method, strconv.Quote(mock+"."+method+" called but "+method+"Func is not set"))
+		fmt.Fprintf(&methods, "\t%s\n}\n", call)
+	}
+
+	fmt.Fprintf(&g.buf, "\n// %s is a %s whose methods call the matching Func field.\n", mock, name)
+	fmt.Fprintf(&g.buf, "// Calling a method whose Func is nil panics.\n")
+	fmt.Fprintf(&g.buf, "type %s struct {\n%s}\n\n", mock, fields.String())
+	fmt.Fprintf(&g.buf, "var _ %s = (*%s)(nil)\n", name, mock)
+	g.buf.Write(methods.Bytes())
+	return nil
+}
+
+func (g *generator) node(n ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, g.fset, n)
+	return buf.String()
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// usedImports returns the file's import lines for the packages in used
+func usedImports(file *ast.File, used map[string]bool) ([]string, error) {
+	var imports []string
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Base(importPath)
+		if majorVersion.MatchString(name) {
+			name = path.Base(path.Dir(importPath))
+		}
+		line := imp.Path.Value
+		if imp.Name != nil {
+			name = imp.Name.Name
+			line = name + " " + line
+		}
+
+		if used[name] {
+			imports = append(imports, line)
+		}
+	}
+	sort.Strings(imports)
+	return imports, nil
+}
+
+func fieldName(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return "(embedded)"
+	}
+	return field.Names[0].Name
+}
@@ -6,43 +6,81 @@ import (
 )
 
 type FixedWindowLimiter struct {
-	sync.RWMutex
-	clients map[string]int
+	sync.Mutex
+	clients map[string]*clientWindow
 	limit int
 	window time.Duration
+	now func() time.Time
+}
+
+// clientWindow is a key's count since its window started
+type clientWindow struct {
+	count int
+	resetAt time.Time
 }
 
 func NewFixedWindowLimiter(limit int, window time.Duration) *FixedWindowLimiter {
 	return &FixedWindowLimiter{
-		clients: make(map[string]int),
+		clients: make(map[string]*clientWindow),
 		limit: limit,
 		window: window,
+		now: time.Now,
 	}
 }
 
-func (rl *FixedWindowLimiter) Allow(ip string) (bool, time.Duration) {
-	rl.RLock()
-	count, exists := rl.clients[ip]
-	rl.RUnlock()
+func (rl *FixedWindowLimiter) Allow(key string) (bool, Status) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	w, exists := rl.clients[key]
+	if !exists || !now.Before(w.resetAt) {
+		w = &clientWindow{resetAt: now.Add(rl.window)}
+		rl.clients[key] = w
+		go rl.resetCount(key, w, rl.window)
+	}
+
+	if w.count >= rl.limit {
+		return false, rl.status(w, now)
+	}
 
-	if !exists || count < rl.limit {
-		rl.Lock()
-		if !exists {
-			go rl.resetCount(ip)
-		}
+	w.count++
+	return true, rl.status(w, now)
+}
 
-		rl.clients[ip]++
-		rl.Unlock()
+// Peek reports a key without a window as having its whole budget, reset a window from now
+func (rl *FixedWindowLimiter) Peek(key string) Status {
+	rl.Lock()
+	defer rl.Unlock()
 
-		return true, 0
+	now := rl.now()
+	w, exists := rl.clients[key]
+	if !exists || !now.Before(w.resetAt) {
+		return Status{Limit: rl.limit, Remaining: rl.limit, Reset: rl.window}
 	}
+	return rl.status(w, now)
+}
+
+func (rl *FixedWindowLimiter) status(w *clientWindow, now time.Time) Status {
+	return Status{Limit: rl.limit, Remaining: max(rl.limit-w.count, 0), Reset: w.resetAt.Sub(now)}
+}
 
-	return false, rl.window
+// SetLimit changes the budget. Open windows keep their end but are held to the
+// new limit; windows opened from now on last the new length.
+func (rl *FixedWindowLimiter) SetLimit(limit int, window time.Duration) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	rl.limit = limit
+	rl.window = window
 }
 
-func (rl *FixedWindowLimiter) resetCount(ip string) {
-	time.Sleep(rl.window)
+// resetCount forgets the key once its window is over, unless a newer window replaced it
+func (rl *FixedWindowLimiter) resetCount(key string, w *clientWindow, window time.Duration) {
+	time.Sleep(window)
 	rl.Lock()
-	delete(rl.clients, ip)
+	if rl.clients[key] == w {
+		delete(rl.clients, key)
+	}
 	rl.Unlock()
-}
\ No newline at end of file
+}
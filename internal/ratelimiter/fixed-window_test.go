@@ -0,0 +1,50 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixedWindowLimiter(t *testing.T) {
+	rl := NewFixedWindowLimiter(2, 10*time.Second)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	if s := rl.Peek("10.0.0.1"); s.Remaining != 2 || s.Reset != 10*time.Second {
+		t.Errorf("before any request = %+v, want the whole budget", s)
+	}
+
+	if ok, s := rl.Allow("10.0.0.1"); !ok || s.Remaining != 1 || s.Reset != 10*time.Second {
+		t.Errorf("first = %v %+v", ok, s)
+	}
+	now = now.Add(4 * time.Second)
+	if ok, s := rl.Allow("10.0.0.1"); !ok || s.Remaining != 0 || s.Reset != 6*time.Second {
+		t.Errorf("second = %v %+v, want the window's end unchanged", ok, s)
+	}
+	if ok, s := rl.Allow("10.0.0.1"); ok || s.Remaining != 0 {
+		t.Errorf("third = %v %+v, want refused", ok, s)
+	}
+	if s := rl.Peek("10.0.0.2"); s.Remaining != 2 {
+		t.Errorf("another client = %+v, want its own budget", s)
+	}
+
+	now = now.Add(6 * time.Second)
+	if ok, s := rl.Allow("10.0.0.1"); !ok || s.Remaining != 1 {
+		t.Errorf("next window = %v %+v, want a fresh budget", ok, s)
+	}
+}
+
+func TestFixedWindowLimiterSetLimit(t *testing.T) {
+	rl := NewFixedWindowLimiter(2, 10*time.Second)
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+
+	rl.Allow("10.0.0.1")
+	rl.SetLimit(1, time.Minute)
+	if ok, s := rl.Allow("10.0.0.1"); ok || s.Limit != 1 || s.Reset != 10*time.Second {
+		t.Errorf("after lowering = %v %+v, want refused with the open window's end", ok, s)
+	}
+	if s := rl.Peek("10.0.0.2"); s.Limit != 1 || s.Reset != time.Minute {
+		t.Errorf("new client = %+v, want the new budget", s)
+	}
+}
@@ -3,11 +3,23 @@ package ratelimiter
 import "time"
 
 type Limiter interface {
-	Allow(ip string) (bool, time.Duration)
+	// Allow counts a request against key, reporting whether it was within the limit
+	// and the budget left after it
+	Allow(key string) (bool, Status)
+	// Peek reports key's budget without counting a request
+	Peek(key string) Status
+}
+
+// Status is a key's budget in the current window
+type Status struct {
+	Limit     int
+	Remaining int
+	// Reset is how long until the window ends and the budget is restored
+	Reset time.Duration
 }
 
 type Config struct {
 	RequestPerTimeFrame int
 	TimeFrame time.Duration
 	Enabled bool
-}
\ No newline at end of file
+}
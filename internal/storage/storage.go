@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Blob stores opaque objects (documents, photos) by key
+type Blob interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL anyone can use to download the object until it expires;
+	// a non-empty filename makes browsers save it under that name
+	PresignGet(ctx context.Context, key string, expiry time.Duration, filename string) (string, error)
+}
+
+// Object is a downloaded object; the caller must close Body
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+}
+
+type Config struct {
+	Enabled   bool
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000 for MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const AuditEntityShift = "shift"
+
+const (
+	AuditCreated    = "created"
+	AuditUpdated    = "updated"
+	AuditAssigned   = "assigned"
+	AuditReassigned = "reassigned"
+	AuditUnassigned = "unassigned"
+	AuditDeleted    = "deleted"
+)
+
+// AuditChange is a field's value before and after a change; nil means unset
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// AuditEntry is one change to an entity, with a readable summary written when it happened
+type AuditEntry struct {
+	ID           int64                  `json:"id"`
+	RestaurantID int64                  `json:"restaurant_id"`
+	EntityType   string                 `json:"entity_type"`
+	EntityID     int64                  `json:"entity_id"`
+	Action       string                 `json:"action"`
+	Summary      string                 `json:"summary"`
+	Changes      map[string]AuditChange `json:"changes"`
+	ActorUserID  *int64                 `json:"actor_user_id,omitempty"`
+	// Joined from users; empty when the actor's account is gone
+	ActorName string    `json:"actor_name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditLogStore struct {
+	db *sql.DB
+}
+
+// Record appends the entries in one transaction
+func (s *AuditLogStore) Record(ctx context.Context, entries []*AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			INSERT INTO audit_log (restaurant_id, entity_type, entity_id, action, summary, changes, actor_user_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, e := range entries {
+			if e.Changes == nil {
+				e.Changes = map[string]AuditChange{}
+			}
+			changes, err := json.Marshal(e.Changes)
+			if err != nil {
+				return err
+			}
+
+			err = stmt.QueryRowContext(
+				ctx,
+				e.RestaurantID,
+				e.EntityType,
+				e.EntityID,
+				e.Action,
+				e.Summary,
+				changes,
+				e.ActorUserID,
+			).Scan(&e.ID, &e.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+const auditEntryColumns = `
+	a.id, a.restaurant_id, a.entity_type, a.entity_id, a.action, a.summary, a.changes,
+	a.actor_user_id, COALESCE(TRIM(u.first_name || ' ' || u.last_name), ''), a.created_at`
+
+// ListByEntity returns an entity's entries oldest first
+func (s *AuditLogStore) ListByEntity(ctx context.Context, entityType string, entityID int64) ([]*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ` + auditEntryColumns + `
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_user_id
+		WHERE a.entity_type = $1 AND a.entity_id = $2
+		ORDER BY a.created_at, a.id`
+
+	rows, err := s.db.QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+// ListByRestaurant returns every entry of the restaurant oldest first
+func (s *AuditLogStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ` + auditEntryColumns + `
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_user_id
+		WHERE a.restaurant_id = $1
+		ORDER BY a.created_at, a.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+func scanAuditEntries(rows *sql.Rows) ([]*AuditEntry, error) {
+	entries := []*AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var changes []byte
+		err := rows.Scan(
+			&e.ID,
+			&e.RestaurantID,
+			&e.EntityType,
+			&e.EntityID,
+			&e.Action,
+			&e.Summary,
+			&changes,
+			&e.ActorUserID,
+			&e.ActorName,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,450 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrBidShiftUnavailable = errors.New("a shift isn't open for bidding: it must be an unassigned shift of the schedule, not in another open round, and of a role the employee holds")
+	ErrBidRoundClosed      = errors.New("bidding on this round has closed")
+	ErrBidRoundAllocated   = errors.New("this round has already been allocated")
+)
+
+type BidRoundStatus string
+
+const (
+	BidRoundOpen      BidRoundStatus = "open"
+	BidRoundAllocated BidRoundStatus = "allocated"
+)
+
+// BidRound is a set of a schedule's open shifts employees bid on until ClosesAt,
+// ranking the ones they want, before an allocation pass assigns them
+type BidRound struct {
+	ID           int64          `json:"id"`
+	RestaurantID int64          `json:"restaurant_id"`
+	ScheduleID   int64          `json:"schedule_id"`
+	Status       BidRoundStatus `json:"status"`
+	ClosesAt     time.Time      `json:"closes_at"`
+	// SeniorityWeight is how many hours already won one point of seniority
+	// makes up for when ordering who picks next; 0 orders by hours alone
+	SeniorityWeight float64          `json:"seniority_weight"`
+	Shifts          []*BidRoundShift `json:"shifts"`
+	CreatedBy       *int64           `json:"created_by,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	AllocatedAt     *time.Time       `json:"allocated_at,omitempty"`
+}
+
+// BidRoundShift is a shift up for bids, with who won it once the round is allocated
+type BidRoundShift struct {
+	ShiftID           int64  `json:"shift_id"`
+	AwardedEmployeeID *int64 `json:"awarded_employee_id,omitempty"`
+	// AwardedRank is where the winner ranked the shift, 1 being their first choice
+	AwardedRank *int `json:"awarded_rank,omitempty"`
+}
+
+// BidPreference is where an employee ranked a shift of a round, 1 being their first choice
+type BidPreference struct {
+	RoundID    int64 `json:"round_id"`
+	EmployeeID int64 `json:"employee_id"`
+	ShiftID    int64 `json:"shift_id"`
+	Rank       int   `json:"rank"`
+}
+
+// BiddableShift is a shift of an open round an employee can bid on, with
+// where they ranked it so far
+type BiddableShift struct {
+	RoundID        int64     `json:"round_id"`
+	ClosesAt       time.Time `json:"closes_at"`
+	RestaurantID   int64     `json:"restaurant_id"`
+	RestaurantName string    `json:"restaurant_name"`
+	EmployeeID     int64     `json:"employee_id"`
+	ShiftID        int64     `json:"shift_id"`
+	ShiftDate      DateOnly  `json:"shift_date"`
+	StartTime      TimeOfDay `json:"start_time"`
+	EndTime        TimeOfDay `json:"end_time"`
+	RoleName       string    `json:"role_name"`
+	RoleColor      string    `json:"role_color"`
+	Rank           *int      `json:"rank,omitempty"`
+}
+
+type BidStore struct {
+	db *sql.DB
+}
+
+// Create opens the round for the shifts in round.Shifts, which must be
+// unassigned shifts of its schedule and not in another open round, or
+// ErrBidShiftUnavailable is returned
+func (s *BidStore) Create(ctx context.Context, round *BidRound) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	shiftIDs := make([]int64, len(round.Shifts))
+	for i, shift := range round.Shifts {
+		shiftIDs[i] = shift.ShiftID
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// locking the shifts keeps them from being assigned or put in another round meanwhile
+		rows, err := tx.QueryContext(ctx, `
+			SELECT ss.id
+			FROM scheduled_shifts ss
+			WHERE ss.id = ANY($1)
+			  AND ss.schedule_id = $2
+			  AND ss.employee_id IS NULL
+			  AND NOT EXISTS (
+			      SELECT 1 FROM bid_round_shifts brs
+			      JOIN bid_rounds br ON br.id = brs.round_id
+			      WHERE brs.shift_id = ss.id AND br.status = 'open'
+			  )
+			FOR UPDATE OF ss`, shiftIDs, round.ScheduleID)
+		if err != nil {
+			return err
+		}
+		available := 0
+		for rows.Next() {
+			available++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if available != len(shiftIDs) {
+			return ErrBidShiftUnavailable
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO bid_rounds (restaurant_id, schedule_id, closes_at, seniority_weight, created_by)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, status, created_at`,
+			round.RestaurantID, round.ScheduleID, round.ClosesAt, round.SeniorityWeight, round.CreatedBy,
+		).Scan(&round.ID, &round.Status, &round.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO bid_round_shifts (round_id, shift_id, restaurant_id)
+			SELECT $1, UNNEST($2::BIGINT[]), $3`, round.ID, shiftIDs, round.RestaurantID)
+		return err
+	})
+}
+
+const bidRoundColumns = `id, restaurant_id, schedule_id, status, closes_at, seniority_weight, created_by, created_at, allocated_at`
+
+func scanBidRound(row interface{ Scan(...any) error }) (*BidRound, error) {
+	round := &BidRound{Shifts: []*BidRoundShift{}}
+	err := row.Scan(
+		&round.ID,
+		&round.RestaurantID,
+		&round.ScheduleID,
+		&round.Status,
+		&round.ClosesAt,
+		&round.SeniorityWeight,
+		&round.CreatedBy,
+		&round.CreatedAt,
+		&round.AllocatedAt,
+	)
+	return round, err
+}
+
+func (s *BidStore) GetByID(ctx context.Context, id int64) (*BidRound, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	round, err := scanBidRound(s.db.QueryRowContext(ctx, `SELECT `+bidRoundColumns+` FROM bid_rounds WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.loadShifts(ctx, []*BidRound{round}); err != nil {
+		return nil, err
+	}
+	return round, nil
+}
+
+// ListBySchedule returns the schedule's rounds, newest first
+func (s *BidStore) ListBySchedule(ctx context.Context, scheduleID int64) ([]*BidRound, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+bidRoundColumns+` FROM bid_rounds WHERE schedule_id = $1 ORDER BY created_at DESC, id DESC`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rounds := []*BidRound{}
+	for rows.Next() {
+		round, err := scanBidRound(rows)
+		if err != nil {
+			return nil, err
+		}
+		rounds = append(rounds, round)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadShifts(ctx, rounds); err != nil {
+		return nil, err
+	}
+	return rounds, nil
+}
+
+// loadShifts fills in the shifts of each round
+func (s *BidStore) loadShifts(ctx context.Context, rounds []*BidRound) error {
+	if len(rounds) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*BidRound, len(rounds))
+	ids := make([]int64, len(rounds))
+	for i, round := range rounds {
+		byID[round.ID] = round
+		ids[i] = round.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT brs.round_id, brs.shift_id, brs.awarded_employee_id, brs.awarded_rank
+		FROM bid_round_shifts brs
+		JOIN scheduled_shifts ss ON ss.id = brs.shift_id
+		WHERE brs.round_id = ANY($1)
+		ORDER BY ss.shift_date, ss.start_time, ss.id`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var roundID int64
+		shift := &BidRoundShift{}
+		if err := rows.Scan(&roundID, &shift.ShiftID, &shift.AwardedEmployeeID, &shift.AwardedRank); err != nil {
+			return err
+		}
+		byID[roundID].Shifts = append(byID[roundID].Shifts, shift)
+	}
+	return rows.Err()
+}
+
+// OpenShiftIDs returns the schedule's shifts in rounds not yet allocated
+func (s *BidStore) OpenShiftIDs(ctx context.Context, scheduleID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT brs.shift_id
+		FROM bid_round_shifts brs
+		JOIN bid_rounds br ON br.id = brs.round_id
+		WHERE br.schedule_id = $1 AND br.status = 'open'`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListPreferences returns the round's bids by employee, first choices first
+func (s *BidStore) ListPreferences(ctx context.Context, roundID int64) ([]*BidPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT round_id, employee_id, shift_id, rank
+		FROM bid_preferences
+		WHERE round_id = $1
+		ORDER BY employee_id, rank`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := []*BidPreference{}
+	for rows.Next() {
+		p := &BidPreference{}
+		if err := rows.Scan(&p.RoundID, &p.EmployeeID, &p.ShiftID, &p.Rank); err != nil {
+			return nil, err
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+// ListBiddableForEmail returns the shifts of rounds open at now, in active
+// restaurants, that an employee record with this email address can bid on:
+// still unassigned and of a role they hold
+func (s *BidStore) ListBiddableForEmail(ctx context.Context, email string, now time.Time) ([]*BiddableShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT br.id, br.closes_at, br.restaurant_id, r.name, e.id,
+		       ss.id, ss.shift_date, ss.start_time, ss.end_time, ss.role_name, ss.role_color, bp.rank
+		FROM bid_rounds br
+		JOIN restaurants r ON r.id = br.restaurant_id
+		JOIN bid_round_shifts brs ON brs.round_id = br.id
+		JOIN scheduled_shifts ss ON ss.id = brs.shift_id
+		JOIN employees e ON e.restaurant_id = br.restaurant_id
+		JOIN employee_roles er ON er.employee_id = e.id AND er.role_id = ss.role_id
+		LEFT JOIN bid_preferences bp ON bp.round_id = br.id AND bp.shift_id = ss.id AND bp.employee_id = e.id
+		WHERE LOWER(e.email) = LOWER($1)
+		  AND br.status = 'open'
+		  AND br.closes_at > $2
+		  AND r.archived_at IS NULL
+		  AND ss.employee_id IS NULL
+		ORDER BY br.closes_at, br.id, ss.shift_date, ss.start_time, ss.id`
+
+	rows, err := s.db.QueryContext(ctx, query, email, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*BiddableShift{}
+	for rows.Next() {
+		shift := &BiddableShift{}
+		if err := rows.Scan(
+			&shift.RoundID,
+			&shift.ClosesAt,
+			&shift.RestaurantID,
+			&shift.RestaurantName,
+			&shift.EmployeeID,
+			&shift.ShiftID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.RoleName,
+			&shift.RoleColor,
+			&shift.Rank,
+		); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts, rows.Err()
+}
+
+// SubmitPreferences replaces the bids of the restaurant's employee with this
+// email address on the round with shiftIDs, first choice first. ErrNotFound
+// means no such round or employee, ErrBidRoundClosed that the round closed by
+// now, and ErrBidShiftUnavailable that a shift isn't in the round or is of a
+// role the employee doesn't hold.
+func (s *BidStore) SubmitPreferences(ctx context.Context, roundID int64, email string, shiftIDs []int64, now time.Time) ([]*BidPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	prefs := []*BidPreference{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// sharing the round's lock keeps an allocation from starting meanwhile
+		var restaurantID, employeeID int64
+		var open bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT br.restaurant_id, e.id, br.status = 'open' AND br.closes_at > $3
+			FROM bid_rounds br
+			JOIN restaurants r ON r.id = br.restaurant_id
+			JOIN employees e ON e.restaurant_id = br.restaurant_id AND LOWER(e.email) = LOWER($2)
+			WHERE br.id = $1 AND r.archived_at IS NULL
+			FOR SHARE OF br`, roundID, email, now).Scan(&restaurantID, &employeeID, &open)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !open {
+			return ErrBidRoundClosed
+		}
+
+		var eligible int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COUNT(*)
+			FROM bid_round_shifts brs
+			JOIN scheduled_shifts ss ON ss.id = brs.shift_id
+			JOIN employee_roles er ON er.role_id = ss.role_id AND er.employee_id = $3
+			WHERE brs.round_id = $1 AND brs.shift_id = ANY($2)`, roundID, shiftIDs, employeeID).Scan(&eligible)
+		if err != nil {
+			return err
+		}
+		if eligible != len(shiftIDs) {
+			return ErrBidShiftUnavailable
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM bid_preferences WHERE round_id = $1 AND employee_id = $2`, roundID, employeeID); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO bid_preferences (round_id, shift_id, employee_id, restaurant_id, rank)
+			SELECT $1, p.shift_id, $2, $3, p.rank
+			FROM UNNEST($4::BIGINT[]) WITH ORDINALITY AS p(shift_id, rank)`,
+			roundID, employeeID, restaurantID, shiftIDs)
+		if err != nil {
+			return err
+		}
+
+		for i, shiftID := range shiftIDs {
+			prefs = append(prefs, &BidPreference{RoundID: roundID, EmployeeID: employeeID, ShiftID: shiftID, Rank: i + 1})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// ClaimAllocation marks the round allocated at now so bids stop and no other
+// allocation runs, or returns ErrBidRoundAllocated if one already did
+func (s *BidStore) ClaimAllocation(ctx context.Context, roundID int64, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE bid_rounds SET status = 'allocated', allocated_at = $2
+		WHERE id = $1 AND status = 'open'
+		RETURNING id`, roundID, now).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrBidRoundAllocated
+	}
+	return err
+}
+
+// RecordAwards saves who won each of the round's shifts and at what rank
+func (s *BidStore) RecordAwards(ctx context.Context, roundID int64, awards []*BidRoundShift) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	shiftIDs := make([]int64, len(awards))
+	employeeIDs := make([]int64, len(awards))
+	ranks := make([]int64, len(awards))
+	for i, a := range awards {
+		shiftIDs[i] = a.ShiftID
+		if a.AwardedEmployeeID != nil {
+			employeeIDs[i] = *a.AwardedEmployeeID
+		}
+		if a.AwardedRank != nil {
+			ranks[i] = int64(*a.AwardedRank)
+		}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE bid_round_shifts brs
+		SET awarded_employee_id = NULLIF(a.employee_id, 0), awarded_rank = NULLIF(a.rank, 0)
+		FROM UNNEST($2::BIGINT[], $3::BIGINT[], $4::BIGINT[]) AS a(shift_id, employee_id, rank)
+		WHERE brs.round_id = $1 AND brs.shift_id = a.shift_id`,
+		roundID, shiftIDs, employeeIDs, ranks)
+	return err
+}
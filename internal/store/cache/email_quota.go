@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// EmailQuota is a restaurant's email sends in the current window
+type EmailQuota struct {
+	Limit     int       `json:"limit"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+func newEmailQuota(limit, used int, resetsAt time.Time) *EmailQuota {
+	return &EmailQuota{
+		Limit:     limit,
+		Used:      used,
+		Remaining: max(limit-used, 0),
+		ResetsAt:  resetsAt,
+	}
+}
+
+// takeQuotaScript counts a send unless the limit is reached; the window starts with the first send
+var takeQuotaScript = redis.NewScript(`
+local used = redis.call('INCR', KEYS[1])
+if used == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+local allowed = 1
+if used > tonumber(ARGV[2]) then
+	used = redis.call('DECR', KEYS[1])
+	allowed = 0
+end
+return {allowed, used, redis.call('PTTL', KEYS[1])}
+`)
+
+// EmailQuotaStore keeps the per-restaurant counters in Redis so every API instance shares them
+type EmailQuotaStore struct {
+	rdb *redis.Client
+}
+
+// Take counts one send against the restaurant's quota; it reports false, without counting, when the quota is used up
+func (s *EmailQuotaStore) Take(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error) {
+	cacheKey := fmt.Sprintf("email-quota-%d", restaurantID)
+
+	result, err := takeQuotaScript.Run(ctx, s.rdb, []string{cacheKey}, window.Milliseconds(), limit).Int64Slice()
+	if err != nil {
+		return nil, false, err
+	}
+
+	allowed, used, ttl := result[0] == 1, int(result[1]), time.Duration(result[2])*time.Millisecond
+	return newEmailQuota(limit, used, time.Now().Add(ttl)), allowed, nil
+}
+
+type quotaWindow struct {
+	used     int
+	resetsAt time.Time
+}
+
+// MemoryEmailQuotaStore is the in-process fallback used when Redis is disabled
+type MemoryEmailQuotaStore struct {
+	sync.Mutex
+	windows map[int64]*quotaWindow
+}
+
+func NewMemoryEmailQuotaStore() *MemoryEmailQuotaStore {
+	return &MemoryEmailQuotaStore{windows: make(map[int64]*quotaWindow)}
+}
+
+func (s *MemoryEmailQuotaStore) Take(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	w, ok := s.windows[restaurantID]
+	if !ok || !now.Before(w.resetsAt) {
+		w = &quotaWindow{resetsAt: now.Add(window)}
+		s.windows[restaurantID] = w
+	}
+
+	if w.used >= limit {
+		return newEmailQuota(limit, w.used, w.resetsAt), false, nil
+	}
+
+	w.used++
+	return newEmailQuota(limit, w.used, w.resetsAt), true, nil
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// entityEntry is how restaurants and schedules are stored: the value with the
+// version of its model. A deploy that changes a model bumps its version, and
+// entries the old code wrote then read as misses instead of half-decoded values.
+type entityEntry struct {
+	Version int             `json:"v"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// getEntity decodes the entry at key into v. Missing, old-version and
+// undecodable entries report false; the latter two are deleted on the way.
+func getEntity(ctx context.Context, rdb *redis.Client, key string, version int, v any) (bool, error) {
+	data, err := rdb.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var entry entityEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Version != version || json.Unmarshal(entry.Value, v) != nil {
+		return false, rdb.Del(ctx, key).Err()
+	}
+	return true, nil
+}
+
+// setEntity stores v at key stamped with version
+func setEntity(ctx context.Context, rdb *redis.Client, key string, version int, v any, exp time.Duration) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entityEntry{Version: version, Value: value})
+	if err != nil {
+		return err
+	}
+	return rdb.Set(ctx, key, data, exp).Err()
+}
+
+// keySampler walks the keys with a prefix a batch at a time, picking up where
+// the last batch stopped so repeated samples cover the whole cache
+type keySampler struct {
+	mu     sync.Mutex
+	cursor uint64
+}
+
+// sample returns the IDs of up to n keys of the form prefix<id>
+func (s *keySampler) sample(ctx context.Context, rdb *redis.Client, prefix string, n int) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []int64{}
+	for len(ids) < n {
+		keys, cursor, err := rdb.Scan(ctx, s.cursor, prefix+"[0-9]*", int64(n)).Result()
+		if err != nil {
+			return nil, err
+		}
+		s.cursor = cursor
+
+		for _, key := range keys {
+			if id, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64); err == nil && len(ids) < n {
+				ids = append(ids, id)
+			}
+		}
+
+		// a full pass over the keyspace ends the sample
+		if cursor == 0 {
+			break
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Maintenance is the maintenance switch as an operator last set it
+type Maintenance struct {
+	Mode              string    `json:"mode"`
+	Message           string    `json:"message,omitempty"`
+	RetryAfterSeconds int       `json:"retry_after_seconds"`
+	SetAt             time.Time `json:"set_at"`
+}
+
+// MaintenanceStore keeps the maintenance switch in Redis, so flipping it on one
+// API instance pauses them all
+type MaintenanceStore struct {
+	rdb *redis.Client
+}
+
+const maintenanceKey = "maintenance"
+
+// Get returns the switch, or nil when no operator has set it
+func (s *MaintenanceStore) Get(ctx context.Context) (*Maintenance, error) {
+	data, err := s.rdb.Get(ctx, maintenanceKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var maintenance Maintenance
+	if err := json.Unmarshal(data, &maintenance); err != nil {
+		return nil, err
+	}
+	return &maintenance, nil
+}
+
+func (s *MaintenanceStore) Set(ctx context.Context, maintenance *Maintenance) error {
+	data, err := json.Marshal(maintenance)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, maintenanceKey, data, 0).Err()
+}
+
+// Clear removes the switch, handing control back to the configuration
+func (s *MaintenanceStore) Clear(ctx context.Context) error {
+	return s.rdb.Del(ctx, maintenanceKey).Err()
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/balebbae/RESA/internal/store"
 )
@@ -10,11 +11,15 @@ func NewMockStore() Storage {
 	return Storage{
 		Restaurants: &MockRestaurantStore{},
 		Schedules: &MockScheduleStore{},
+		Ownership: &MockOwnershipStore{},
+		EmailQuota: &MockEmailQuotaStore{},
 	}
 }
 
 type MockRestaurantStore struct {}
 type MockScheduleStore struct {}
+type MockOwnershipStore struct {}
+type MockEmailQuotaStore struct {}
 
 func (m MockRestaurantStore) Get(ctx context.Context, id int64) (*store.Restaurant, error) {
 	return nil, nil 
@@ -28,6 +33,10 @@ func (m MockRestaurantStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (m MockRestaurantStore) Sample(ctx context.Context, n int) ([]int64, error) {
+	return nil, nil
+}
+
 func (m MockScheduleStore) Get(ctx context.Context, id int64) (*store.Schedule, error) {
 	return nil, nil 
 }
@@ -40,3 +49,22 @@ func (m MockScheduleStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (m MockScheduleStore) Sample(ctx context.Context, n int) ([]int64, error) {
+	return nil, nil
+}
+
+func (m MockOwnershipStore) Get(ctx context.Context, restaurantID int64) (*Ownership, error) {
+	return nil, nil
+}
+
+func (m MockOwnershipStore) Set(ctx context.Context, restaurantID int64, ownership *Ownership) error {
+	return nil
+}
+
+func (m MockOwnershipStore) Delete(ctx context.Context, restaurantID int64) error {
+	return nil
+}
+
+func (m MockEmailQuotaStore) Take(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error) {
+	return newEmailQuota(limit, 1, time.Now().Add(window)), true, nil
+}
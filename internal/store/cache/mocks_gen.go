@@ -0,0 +1,211 @@
+// Code generated by mockgen -type Storage storage.go; DO NOT EDIT.
+
+package cache
+
+import (
+	"context"
+	"github.com/balebbae/RESA/internal/store"
+	"time"
+)
+
+// MockScheduleStorer is a ScheduleStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockScheduleStorer struct {
+	GetFunc    func(context.Context, int64) (*store.Schedule, error)
+	SetFunc    func(context.Context, *store.Schedule) error
+	DeleteFunc func(context.Context, int64) error
+	SampleFunc func(context.Context, int) ([]int64, error)
+}
+
+var _ ScheduleStorer = (*MockScheduleStorer)(nil)
+
+func (m *MockScheduleStorer) Get(a0 context.Context, a1 int64) (*store.Schedule, error) {
+	if m.GetFunc == nil {
+		panic("MockScheduleStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Set(a0 context.Context, a1 *store.Schedule) error {
+	if m.SetFunc == nil {
+		panic("MockScheduleStorer.Set called but SetFunc is not set")
+	}
+	return m.SetFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockScheduleStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Sample(a0 context.Context, a1 int) ([]int64, error) {
+	if m.SampleFunc == nil {
+		panic("MockScheduleStorer.Sample called but SampleFunc is not set")
+	}
+	return m.SampleFunc(a0, a1)
+}
+
+// MockRestaurantStorer is a RestaurantStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockRestaurantStorer struct {
+	GetFunc    func(context.Context, int64) (*store.Restaurant, error)
+	SetFunc    func(context.Context, *store.Restaurant) error
+	DeleteFunc func(context.Context, int64) error
+	SampleFunc func(context.Context, int) ([]int64, error)
+}
+
+var _ RestaurantStorer = (*MockRestaurantStorer)(nil)
+
+func (m *MockRestaurantStorer) Get(a0 context.Context, a1 int64) (*store.Restaurant, error) {
+	if m.GetFunc == nil {
+		panic("MockRestaurantStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) Set(a0 context.Context, a1 *store.Restaurant) error {
+	if m.SetFunc == nil {
+		panic("MockRestaurantStorer.Set called but SetFunc is not set")
+	}
+	return m.SetFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockRestaurantStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) Sample(a0 context.Context, a1 int) ([]int64, error) {
+	if m.SampleFunc == nil {
+		panic("MockRestaurantStorer.Sample called but SampleFunc is not set")
+	}
+	return m.SampleFunc(a0, a1)
+}
+
+// MockOwnershipStorer is a OwnershipStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockOwnershipStorer struct {
+	GetFunc    func(context.Context, int64) (*Ownership, error)
+	SetFunc    func(context.Context, int64, *Ownership) error
+	DeleteFunc func(context.Context, int64) error
+}
+
+var _ OwnershipStorer = (*MockOwnershipStorer)(nil)
+
+func (m *MockOwnershipStorer) Get(a0 context.Context, a1 int64) (*Ownership, error) {
+	if m.GetFunc == nil {
+		panic("MockOwnershipStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockOwnershipStorer) Set(a0 context.Context, a1 int64, a2 *Ownership) error {
+	if m.SetFunc == nil {
+		panic("MockOwnershipStorer.Set called but SetFunc is not set")
+	}
+	return m.SetFunc(a0, a1, a2)
+}
+
+func (m *MockOwnershipStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockOwnershipStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+// MockEmailQuotaStorer is a EmailQuotaStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEmailQuotaStorer struct {
+	TakeFunc func(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error)
+}
+
+var _ EmailQuotaStorer = (*MockEmailQuotaStorer)(nil)
+
+func (m *MockEmailQuotaStorer) Take(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error) {
+	if m.TakeFunc == nil {
+		panic("MockEmailQuotaStorer.Take called but TakeFunc is not set")
+	}
+	return m.TakeFunc(ctx, restaurantID, limit, window)
+}
+
+// MockResponseStorer is a ResponseStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockResponseStorer struct {
+	VersionFunc func(context.Context, int64) (int64, error)
+	BumpFunc    func(context.Context, int64) error
+	BumpAllFunc func(context.Context) error
+	GetFunc     func(context.Context, int64, int64, string) (*CachedResponse, error)
+	SetFunc     func(context.Context, int64, int64, string, *CachedResponse) error
+}
+
+var _ ResponseStorer = (*MockResponseStorer)(nil)
+
+func (m *MockResponseStorer) Version(a0 context.Context, a1 int64) (int64, error) {
+	if m.VersionFunc == nil {
+		panic("MockResponseStorer.Version called but VersionFunc is not set")
+	}
+	return m.VersionFunc(a0, a1)
+}
+
+func (m *MockResponseStorer) Bump(a0 context.Context, a1 int64) error {
+	if m.BumpFunc == nil {
+		panic("MockResponseStorer.Bump called but BumpFunc is not set")
+	}
+	return m.BumpFunc(a0, a1)
+}
+
+func (m *MockResponseStorer) BumpAll(a0 context.Context) error {
+	if m.BumpAllFunc == nil {
+		panic("MockResponseStorer.BumpAll called but BumpAllFunc is not set")
+	}
+	return m.BumpAllFunc(a0)
+}
+
+func (m *MockResponseStorer) Get(a0 context.Context, a1 int64, a2 int64, a3 string) (*CachedResponse, error) {
+	if m.GetFunc == nil {
+		panic("MockResponseStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1, a2, a3)
+}
+
+func (m *MockResponseStorer) Set(a0 context.Context, a1 int64, a2 int64, a3 string, a4 *CachedResponse) error {
+	if m.SetFunc == nil {
+		panic("MockResponseStorer.Set called but SetFunc is not set")
+	}
+	return m.SetFunc(a0, a1, a2, a3, a4)
+}
+
+// MockMaintenanceStorer is a MaintenanceStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockMaintenanceStorer struct {
+	GetFunc   func(context.Context) (*Maintenance, error)
+	SetFunc   func(context.Context, *Maintenance) error
+	ClearFunc func(context.Context) error
+}
+
+var _ MaintenanceStorer = (*MockMaintenanceStorer)(nil)
+
+func (m *MockMaintenanceStorer) Get(a0 context.Context) (*Maintenance, error) {
+	if m.GetFunc == nil {
+		panic("MockMaintenanceStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0)
+}
+
+func (m *MockMaintenanceStorer) Set(a0 context.Context, a1 *Maintenance) error {
+	if m.SetFunc == nil {
+		panic("MockMaintenanceStorer.Set called but SetFunc is not set")
+	}
+	return m.SetFunc(a0, a1)
+}
+
+func (m *MockMaintenanceStorer) Clear(a0 context.Context) error {
+	if m.ClearFunc == nil {
+		panic("MockMaintenanceStorer.Clear called but ClearFunc is not set")
+	}
+	return m.ClearFunc(a0)
+}
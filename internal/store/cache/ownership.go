@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// OwnershipExpTime is kept short so a missed invalidation heals quickly
+const OwnershipExpTime = time.Minute * 5
+
+// MemoryOwnershipExpTime is shorter still: an instance can only invalidate its
+// own in-process copy, so without Redis a member removed through one API
+// instance keeps their access on the others until this runs out
+const MemoryOwnershipExpTime = time.Second * 30
+
+// Ownership is who may act on a restaurant: its owner, and its members keyed by user ID
+type Ownership struct {
+	OwnerID int64                   `json:"owner_id"`
+	Members map[int64]*MemberAccess `json:"members"`
+}
+
+// MemberAccess is what a member may do with the restaurant
+type MemberAccess struct {
+	Permission string  `json:"permission"`
+	RoleIDs    []int64 `json:"role_ids"`
+}
+
+// OwnershipStore caches restaurantID -> owner and members for authorization checks
+type OwnershipStore struct {
+	rdb *redis.Client
+}
+
+// Get returns nil on a miss
+func (s *OwnershipStore) Get(ctx context.Context, restaurantID int64) (*Ownership, error) {
+	cacheKey := fmt.Sprintf("restaurant-access-%d", restaurantID)
+
+	data, err := s.rdb.Get(ctx, cacheKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var ownership Ownership
+	if err := json.Unmarshal(data, &ownership); err != nil {
+		return nil, err
+	}
+	return &ownership, nil
+}
+
+func (s *OwnershipStore) Set(ctx context.Context, restaurantID int64, ownership *Ownership) error {
+	cacheKey := fmt.Sprintf("restaurant-access-%d", restaurantID)
+
+	data, err := json.Marshal(ownership)
+	if err != nil {
+		return err
+	}
+	return s.rdb.SetEX(ctx, cacheKey, data, OwnershipExpTime).Err()
+}
+
+func (s *OwnershipStore) Delete(ctx context.Context, restaurantID int64) error {
+	cacheKey := fmt.Sprintf("restaurant-access-%d", restaurantID)
+	return s.rdb.Del(ctx, cacheKey).Err()
+}
+
+type ownershipEntry struct {
+	ownership *Ownership
+	expiresAt time.Time
+}
+
+// MemoryOwnershipStore is the in-process fallback used when Redis is disabled.
+// Deletes only reach this process; see MemoryOwnershipExpTime.
+type MemoryOwnershipStore struct {
+	sync.RWMutex
+	entries map[int64]ownershipEntry
+	ttl     time.Duration
+}
+
+func NewMemoryOwnershipStore(ttl time.Duration) *MemoryOwnershipStore {
+	return &MemoryOwnershipStore{
+		entries: make(map[int64]ownershipEntry),
+		ttl:     ttl,
+	}
+}
+
+func (s *MemoryOwnershipStore) Get(ctx context.Context, restaurantID int64) (*Ownership, error) {
+	s.RLock()
+	entry, ok := s.entries[restaurantID]
+	s.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+
+	return entry.ownership, nil
+}
+
+func (s *MemoryOwnershipStore) Set(ctx context.Context, restaurantID int64, ownership *Ownership) error {
+	s.Lock()
+	s.entries[restaurantID] = ownershipEntry{ownership: ownership, expiresAt: time.Now().Add(s.ttl)}
+	s.Unlock()
+
+	return nil
+}
+
+func (s *MemoryOwnershipStore) Delete(ctx context.Context, restaurantID int64) error {
+	s.Lock()
+	delete(s.entries, restaurantID)
+	s.Unlock()
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ResponseExpTime bounds how long a cached response can outlive a change
+// that didn't bump its restaurant's version
+const ResponseExpTime = time.Minute * 10
+
+// CachedResponse is the data of a rendered JSON response with the validators it was sent with
+type CachedResponse struct {
+	Data         json.RawMessage `json:"data"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+}
+
+// responseVersionAllKey is the version shared by every restaurant, bumped by
+// writes that span restaurants
+const responseVersionAllKey = "restaurant-version-all"
+
+// ResponseStore caches rendered responses per restaurant. Keys include the
+// restaurant's version, so bumping it on a write retires every cached
+// response at once and they expire unread.
+type ResponseStore struct {
+	rdb *redis.Client
+}
+
+// Version is the restaurant's current cache version, 0 before its first
+// write. It adds the shared version to the restaurant's own; both only go up,
+// so a version is never reused.
+func (s *ResponseStore) Version(ctx context.Context, restaurantID int64) (int64, error) {
+	cacheKey := fmt.Sprintf("restaurant-version-%d", restaurantID)
+
+	values, err := s.rdb.MGet(ctx, cacheKey, responseVersionAllKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64
+	for _, value := range values {
+		// A key that was never bumped comes back nil
+		v, ok := value.(string)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		version += n
+	}
+	return version, nil
+}
+
+// Bump moves the restaurant to a new version after a write
+func (s *ResponseStore) Bump(ctx context.Context, restaurantID int64) error {
+	cacheKey := fmt.Sprintf("restaurant-version-%d", restaurantID)
+	return s.rdb.Incr(ctx, cacheKey).Err()
+}
+
+// BumpAll moves every restaurant to a new version after a write that spans
+// restaurants, such as a background job's
+func (s *ResponseStore) BumpAll(ctx context.Context) error {
+	return s.rdb.Incr(ctx, responseVersionAllKey).Err()
+}
+
+func (s *ResponseStore) Get(ctx context.Context, restaurantID, version int64, key string) (*CachedResponse, error) {
+	cacheKey := fmt.Sprintf("response-%d-%d-%s", restaurantID, version, key)
+
+	data, err := s.rdb.Get(ctx, cacheKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *ResponseStore) Set(ctx context.Context, restaurantID, version int64, key string, response *CachedResponse) error {
+	cacheKey := fmt.Sprintf("response-%d-%d-%s", restaurantID, version, key)
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return s.rdb.SetEX(ctx, cacheKey, data, ResponseExpTime).Err()
+}
@@ -2,7 +2,6 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,44 +10,38 @@ import (
 )
 
 type RestaurantStore struct {
-	rdb *redis.Client
+	rdb     *redis.Client
+	sampler keySampler
 }
 
 const RestaurantExpTime = time.Hour // TODO: Change to 1 hour
 
+// restaurantVersion is stamped into cached restaurants; bump it when store.Restaurant changes
+const restaurantVersion = 1
+
 func (s *RestaurantStore) Get(ctx context.Context, id int64) (*store.Restaurant, error) {
 	cacheKey := fmt.Sprintf("restaurant-%v", id)
 
-	data, err := s.rdb.Get(ctx, cacheKey).Result()
-	if err == redis.Nil {
-		return nil, nil
-	} else if err != nil {
+	var restaurant store.Restaurant
+	found, err := getEntity(ctx, s.rdb, cacheKey, restaurantVersion, &restaurant)
+	if err != nil || !found {
 		return nil, err
 	}
 
-	var restaurant store.Restaurant
-	if data != "" {
-		err := json.Unmarshal([]byte(data), &restaurant)
-		if err != nil {
-			return nil, err
-		}
-	}
-		
 	return &restaurant, nil
 }
 
 func (s *RestaurantStore) Set(ctx context.Context, restaurant *store.Restaurant) error {
 	cacheKey := fmt.Sprintf("restaurant-%d", restaurant.ID)
-
-	json, err := json.Marshal(restaurant)
-	if err != nil {
-		return err
-	}
-
-	return s.rdb.SetEX(ctx, cacheKey, json, RestaurantExpTime).Err()
+	return setEntity(ctx, s.rdb, cacheKey, restaurantVersion, restaurant, RestaurantExpTime)
 }
 
 func (s *RestaurantStore) Delete(ctx context.Context, id int64) error {
 	cacheKey := fmt.Sprintf("restaurant-%d", id)
 	return s.rdb.Del(ctx, cacheKey).Err()
 }
+
+// Sample returns the IDs of up to n cached restaurants, continuing from the last sample
+func (s *RestaurantStore) Sample(ctx context.Context, n int) ([]int64, error) {
+	return s.sampler.sample(ctx, s.rdb, "restaurant-", n)
+}
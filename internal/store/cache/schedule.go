@@ -2,7 +2,6 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,29 +10,24 @@ import (
 )
 
 type ScheduleStore struct {
-	rdb *redis.Client
+	rdb     *redis.Client
+	sampler keySampler
 }
 
 const ScheduleExpTime = time.Hour // TODO: Change to 1 hour
 
+// scheduleVersion is stamped into cached schedules; bump it when store.Schedule changes
+const scheduleVersion = 1
+
 func (s *ScheduleStore) Get(ctx context.Context, id int64) (*store.Schedule, error) {
 	cacheKey := fmt.Sprintf("schedule-%v", id)
 
-	data, err := s.rdb.Get(ctx, cacheKey).Result()
-	if err == redis.Nil {
-		return nil, nil
-	} else if err != nil {
+	var schedule store.Schedule
+	found, err := getEntity(ctx, s.rdb, cacheKey, scheduleVersion, &schedule)
+	if err != nil || !found {
 		return nil, err
 	}
 
-	var schedule store.Schedule
-	if data != "" {
-		err := json.Unmarshal([]byte(data), &schedule)
-		if err != nil {
-			return nil, err
-		}
-	}
-		
 	return &schedule, nil
 }
 
@@ -43,20 +37,15 @@ func (s *ScheduleStore) Set(ctx context.Context, schedule *store.Schedule) error
 	}
 	cacheKey := fmt.Sprintf("schedule-%d", schedule.ID)
 
-	json, err := json.Marshal(schedule)
-	if err != nil {
-		return err
-	}
-
-	err = s.rdb.Set(ctx, cacheKey, json, ScheduleExpTime).Err()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return setEntity(ctx, s.rdb, cacheKey, scheduleVersion, schedule, ScheduleExpTime)
 }
 
 func (s *ScheduleStore) Delete(ctx context.Context, id int64) error {
 	cacheKey := fmt.Sprintf("schedule-%d", id)
 	return s.rdb.Del(ctx, cacheKey).Err()
 }
+
+// Sample returns the IDs of up to n cached schedules, continuing from the last sample
+func (s *ScheduleStore) Sample(ctx context.Context, n int) ([]int64, error) {
+	return s.sampler.sample(ctx, s.rdb, "schedule-", n)
+}
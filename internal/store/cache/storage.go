@@ -2,28 +2,71 @@ package cache
 
 import (
 	"context"
+	"time"
 
 	"github.com/balebbae/RESA/internal/store"
 	"github.com/go-redis/redis/v8"
 )
 
+//go:generate go run ../../mockgen -type Storage -out mocks_gen.go storage.go
+
 type Storage struct {
-	Schedules interface {
-		Get(context.Context, int64) (*store.Schedule, error)
-		Set(context.Context, *store.Schedule) error
-		Delete(context.Context, int64) error
-	}
-	Restaurants interface {
-		Get(context.Context, int64) (*store.Restaurant, error)
-		Set(context.Context, *store.Restaurant) error
-		Delete(context.Context, int64) error
-	}
+	Schedules   ScheduleStorer
+	Restaurants RestaurantStorer
+	Ownership   OwnershipStorer
+	EmailQuota  EmailQuotaStorer
+	// Responses is only set with Redis: every API instance has to see a version bump
+	Responses ResponseStorer
+	// Maintenance is only set with Redis; without it the switch is per instance
+	Maintenance MaintenanceStorer
+}
+
+type ScheduleStorer interface {
+	Get(context.Context, int64) (*store.Schedule, error)
+	Set(context.Context, *store.Schedule) error
+	Delete(context.Context, int64) error
+	Sample(context.Context, int) ([]int64, error)
+}
+
+type RestaurantStorer interface {
+	Get(context.Context, int64) (*store.Restaurant, error)
+	Set(context.Context, *store.Restaurant) error
+	Delete(context.Context, int64) error
+	Sample(context.Context, int) ([]int64, error)
+}
+
+type OwnershipStorer interface {
+	Get(context.Context, int64) (*Ownership, error)
+	Set(context.Context, int64, *Ownership) error
+	Delete(context.Context, int64) error
+}
+
+type ResponseStorer interface {
+	Version(context.Context, int64) (int64, error)
+	Bump(context.Context, int64) error
+	BumpAll(context.Context) error
+	Get(context.Context, int64, int64, string) (*CachedResponse, error)
+	Set(context.Context, int64, int64, string, *CachedResponse) error
+}
+
+type MaintenanceStorer interface {
+	Get(context.Context) (*Maintenance, error)
+	Set(context.Context, *Maintenance) error
+	Clear(context.Context) error
+}
+
+type EmailQuotaStorer interface {
+	Take(ctx context.Context, restaurantID int64, limit int, window time.Duration) (*EmailQuota, bool, error)
 }
 
 func NewRedisStorage(rdb *redis.Client) Storage {
 	return Storage{
 		Schedules: &ScheduleStore{rdb: rdb},
 		Restaurants: &RestaurantStore{rdb: rdb},
+		Ownership: &OwnershipStore{rdb: rdb},
+		EmailQuota: &EmailQuotaStore{rdb: rdb},
+		Responses: &ResponseStore{rdb: rdb},
+		Maintenance: &MaintenanceStore{rdb: rdb},
 	}
 }
 
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CalendarFeed is a read-only calendar of a restaurant's published shifts that
+// calendar apps subscribe to, or of one employee's when EmployeeID is set
+type CalendarFeed struct {
+	ID           int64      `json:"id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	EmployeeID   *int64     `json:"employee_id,omitempty"`
+	Name         string     `json:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy    *int64     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type CalendarFeedStore struct {
+	db *sql.DB
+}
+
+const calendarFeedColumns = `id, restaurant_id, employee_id, name, last_used_at, revoked_at, created_by, created_at`
+
+func scanCalendarFeed(row interface{ Scan(...any) error }) (*CalendarFeed, error) {
+	var f CalendarFeed
+	if err := row.Scan(&f.ID, &f.RestaurantID, &f.EmployeeID, &f.Name, &f.LastUsedAt, &f.RevokedAt, &f.CreatedBy, &f.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Create registers a calendar feed; only the token's hash is stored
+func (s *CalendarFeedStore) Create(ctx context.Context, feed *CalendarFeed, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO calendar_feeds (restaurant_id, employee_id, name, token_hash, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, feed.RestaurantID, feed.EmployeeID, feed.Name, hashToken(token), feed.CreatedBy).
+		Scan(&feed.ID, &feed.CreatedAt)
+}
+
+func (s *CalendarFeedStore) List(ctx context.Context, restaurantID int64) ([]*CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `SELECT ` + calendarFeedColumns + ` FROM calendar_feeds WHERE restaurant_id = $1 ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []*CalendarFeed{}
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+// Revoke stops the feed's token from working
+func (s *CalendarFeedStore) Revoke(ctx context.Context, restaurantID, feedID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE calendar_feeds
+		SET revoked_at = NOW()
+		WHERE id = $1 AND restaurant_id = $2 AND revoked_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, feedID, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the unrevoked feed of an active restaurant with this
+// token and records that it was used
+func (s *CalendarFeedStore) Authenticate(ctx context.Context, token string) (*CalendarFeed, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE calendar_feeds f
+		SET last_used_at = NOW()
+		FROM restaurants r
+		WHERE r.id = f.restaurant_id
+		  AND f.token_hash = $1
+		  AND f.revoked_at IS NULL
+		  AND r.archived_at IS NULL
+		RETURNING f.id, f.restaurant_id, f.employee_id, f.name, f.last_used_at, f.revoked_at, f.created_by, f.created_at`
+
+	feed, err := scanCalendarFeed(s.db.QueryRowContext(ctx, query, hashToken(token)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// ListShifts returns the restaurant's shifts from published schedules on or
+// after from, only the employee's when employeeID is set, by day and start time
+func (s *CalendarFeedStore) ListShifts(ctx context.Context, restaurantID int64, employeeID *int64, from DateOnly) ([]*ScheduledShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ss.id, ss.schedule_id, ss.restaurant_id, ss.role_id, ss.employee_id, ss.shift_date, ss.start_time, ss.end_time,
+		       ss.notes, ss.updated_at, ss.employee_name, ss.role_name, ss.training
+		FROM scheduled_shifts ss
+		JOIN schedules s ON s.id = ss.schedule_id
+		WHERE ss.restaurant_id = $1
+		  AND ss.shift_date >= $2::date
+		  AND s.published_at IS NOT NULL
+		  AND ($3::bigint IS NULL OR ss.employee_id = $3)
+		ORDER BY ss.shift_date, ss.start_time, ss.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*ScheduledShift{}
+	for rows.Next() {
+		var shift ScheduledShift
+		if err := rows.Scan(
+			&shift.ID,
+			&shift.ScheduleID,
+			&shift.RestaurantID,
+			&shift.RoleID,
+			&shift.EmployeeID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.Notes,
+			&shift.UpdatedAt,
+			&shift.EmployeeName,
+			&shift.RoleName,
+			&shift.Training,
+		); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, &shift)
+	}
+
+	return shifts, rows.Err()
+}
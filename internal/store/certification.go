@@ -0,0 +1,359 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrDuplicateCertification = errors.New("a certification with that name already exists")
+)
+
+// Certification is a credential a restaurant tracks, e.g. a food handler card
+type Certification struct {
+	ID           int64     `json:"id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	Name         string    `json:"name"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// EmployeeCertification is a certification held by an employee; a nil ExpiresOn never expires
+type EmployeeCertification struct {
+	EmployeeID        int64     `json:"employee_id"`
+	EmployeeName      string    `json:"employee_name,omitempty"`
+	CertificationID   int64     `json:"certification_id"`
+	CertificationName string    `json:"certification_name"`
+	IssuedOn          *DateOnly `json:"issued_on,omitempty"`
+	ExpiresOn         *DateOnly `json:"expires_on,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type CertificationStore struct {
+	db *sql.DB
+}
+
+func (s *CertificationStore) Create(ctx context.Context, cert *Certification) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO certifications (restaurant_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at`
+
+	err := s.db.QueryRowContext(ctx, query, cert.RestaurantID, cert.Name).Scan(
+		&cert.ID,
+		&cert.CreatedAt,
+		&cert.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "uq_certifications_restaurant_name") {
+			return ErrDuplicateCertification
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *CertificationStore) GetByID(ctx context.Context, id int64) (*Certification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, name, created_at, updated_at
+		FROM certifications
+		WHERE id = $1`
+
+	var cert Certification
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&cert.ID,
+		&cert.RestaurantID,
+		&cert.Name,
+		&cert.CreatedAt,
+		&cert.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+func (s *CertificationStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Certification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, name, created_at, updated_at
+		FROM certifications
+		WHERE restaurant_id = $1
+		ORDER BY name`
+
+	return s.queryCertifications(ctx, query, restaurantID)
+}
+
+func (s *CertificationStore) Update(ctx context.Context, cert *Certification) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE certifications
+		SET name = $1, updated_at = NOW()
+		WHERE id = $2
+		RETURNING updated_at`
+
+	err := s.db.QueryRowContext(ctx, query, cert.Name, cert.ID).Scan(&cert.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		case isUniqueViolation(err, "uq_certifications_restaurant_name"):
+			return ErrDuplicateCertification
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *CertificationStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM certifications WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListByEmployee returns the certifications an employee holds
+func (s *CertificationStore) ListByEmployee(ctx context.Context, employeeID int64) ([]*EmployeeCertification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ec.employee_id, e.full_name, ec.certification_id, c.name,
+		       ec.issued_on, ec.expires_on, ec.created_at, ec.updated_at
+		FROM employee_certifications ec
+		JOIN certifications c ON c.id = ec.certification_id
+		JOIN employees e ON e.id = ec.employee_id
+		WHERE ec.employee_id = $1
+		ORDER BY c.name`
+
+	return s.queryEmployeeCertifications(ctx, query, employeeID)
+}
+
+// SetForEmployee records (or replaces the dates of) a certification held by an employee
+func (s *CertificationStore) SetForEmployee(ctx context.Context, ec *EmployeeCertification) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO employee_certifications (employee_id, certification_id, issued_on, expires_on)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (employee_id, certification_id)
+		DO UPDATE SET issued_on = EXCLUDED.issued_on, expires_on = EXCLUDED.expires_on, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	return s.db.QueryRowContext(ctx, query, ec.EmployeeID, ec.CertificationID, ec.IssuedOn, ec.ExpiresOn).Scan(
+		&ec.CreatedAt,
+		&ec.UpdatedAt,
+	)
+}
+
+func (s *CertificationStore) RemoveFromEmployee(ctx context.Context, employeeID, certificationID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `DELETE FROM employee_certifications WHERE employee_id = $1 AND certification_id = $2`
+
+	result, err := s.db.ExecContext(ctx, query, employeeID, certificationID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListRequiredByRole returns the certifications needed to work a role
+func (s *CertificationStore) ListRequiredByRole(ctx context.Context, roleID int64) ([]*Certification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT c.id, c.restaurant_id, c.name, c.created_at, c.updated_at
+		FROM certifications c
+		JOIN role_certifications rc ON rc.certification_id = c.id
+		WHERE rc.role_id = $1
+		ORDER BY c.name`
+
+	return s.queryCertifications(ctx, query, roleID)
+}
+
+// SetRequiredByRole replaces the set of certifications a role requires
+func (s *CertificationStore) SetRequiredByRole(ctx context.Context, roleID int64, certificationIDs []int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM role_certifications WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+
+		if len(certificationIDs) == 0 {
+			return nil
+		}
+
+		query := `
+			INSERT INTO role_certifications (role_id, certification_id)
+			SELECT $1, unnest($2::bigint[])
+			ON CONFLICT DO NOTHING`
+
+		_, err := tx.ExecContext(ctx, query, roleID, certificationIDs)
+		return err
+	})
+}
+
+// MissingForAssignment lists the role's required certifications the employee lacks or has expired on the given date
+func (s *CertificationStore) MissingForAssignment(ctx context.Context, employeeID, roleID int64, on time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT c.name
+		FROM role_certifications rc
+		JOIN certifications c ON c.id = rc.certification_id
+		LEFT JOIN employee_certifications ec
+			ON ec.certification_id = rc.certification_id AND ec.employee_id = $1
+		WHERE rc.role_id = $2
+		  AND (ec.employee_id IS NULL OR ec.expires_on < $3::date)
+		ORDER BY c.name`
+
+	rows, err := s.db.QueryContext(ctx, query, employeeID, roleID, on.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		missing = append(missing, name)
+	}
+
+	return missing, rows.Err()
+}
+
+// ListExpiring returns certifications in a restaurant that expire on or before the given date, including already expired ones
+func (s *CertificationStore) ListExpiring(ctx context.Context, restaurantID int64, before DateOnly) ([]*EmployeeCertification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ec.employee_id, e.full_name, ec.certification_id, c.name,
+		       ec.issued_on, ec.expires_on, ec.created_at, ec.updated_at
+		FROM employee_certifications ec
+		JOIN certifications c ON c.id = ec.certification_id
+		JOIN employees e ON e.id = ec.employee_id
+		WHERE c.restaurant_id = $1
+		  AND ec.expires_on IS NOT NULL
+		  AND ec.expires_on <= $2::date
+		ORDER BY ec.expires_on, e.full_name`
+
+	return s.queryEmployeeCertifications(ctx, query, restaurantID, before)
+}
+
+func (s *CertificationStore) queryCertifications(ctx context.Context, query string, args ...any) ([]*Certification, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []*Certification{}
+	for rows.Next() {
+		var cert Certification
+		err := rows.Scan(
+			&cert.ID,
+			&cert.RestaurantID,
+			&cert.Name,
+			&cert.CreatedAt,
+			&cert.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, &cert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return certs, nil
+}
+
+func (s *CertificationStore) queryEmployeeCertifications(ctx context.Context, query string, args ...any) ([]*EmployeeCertification, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	certs := []*EmployeeCertification{}
+	for rows.Next() {
+		var ec EmployeeCertification
+		err := rows.Scan(
+			&ec.EmployeeID,
+			&ec.EmployeeName,
+			&ec.CertificationID,
+			&ec.CertificationName,
+			&ec.IssuedOn,
+			&ec.ExpiresOn,
+			&ec.CreatedAt,
+			&ec.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, &ec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return certs, nil
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ComplianceSeverity is what a broken compliance rule does: nothing, a warning,
+// or refusing the assignment or publish
+type ComplianceSeverity string
+
+const (
+	ComplianceOff   ComplianceSeverity = "off"
+	ComplianceWarn  ComplianceSeverity = "warn"
+	ComplianceBlock ComplianceSeverity = "block"
+)
+
+// ComplianceRules are the working-hour limits a restaurant schedules under. A
+// limit of 0 turns its rule off. Employees younger than MinorAge on a shift's
+// date, going by their birthday, are held to the minor limits for it.
+type ComplianceRules struct {
+	RestaurantID int64 `json:"restaurant_id"`
+	// Jurisdiction is the template the rules started from
+	Jurisdiction            string             `json:"jurisdiction"`
+	MinRestHours            int                `json:"min_rest_hours"`
+	RestSeverity            ComplianceSeverity `json:"rest_severity"`
+	MaxConsecutiveDays      int                `json:"max_consecutive_days"`
+	ConsecutiveDaysSeverity ComplianceSeverity `json:"consecutive_days_severity"`
+	MinorAge                int                `json:"minor_age"`
+	MinorMaxDailyHours      int                `json:"minor_max_daily_hours"`
+	MinorMaxWeeklyHours     int                `json:"minor_max_weekly_hours"`
+	// MinorLatestEnd is when minors' shifts must end by; nil for no limit
+	MinorLatestEnd *TimeOfDay         `json:"minor_latest_end,omitempty"`
+	MinorSeverity  ComplianceSeverity `json:"minor_severity"`
+	// UpdatedAt is nil until the restaurant saves its own rules
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Enabled reports whether any rule can produce a violation
+func (c *ComplianceRules) Enabled() bool {
+	return (c.MinRestHours > 0 && c.RestSeverity != ComplianceOff) ||
+		(c.MaxConsecutiveDays > 0 && c.ConsecutiveDaysSeverity != ComplianceOff) ||
+		(c.MinorAge > 0 && c.MinorSeverity != ComplianceOff)
+}
+
+// ComplianceJurisdictions lists the templates in ComplianceTemplate
+var ComplianceJurisdictions = []string{"custom", "us_federal", "california", "eu"}
+
+// ComplianceTemplate returns the starting rules for a jurisdiction. They follow
+// the common limits there (minors' limits for periods outside the school year)
+// and are meant to be reviewed, not taken as legal advice.
+func ComplianceTemplate(jurisdiction string) (ComplianceRules, bool) {
+	latestEnd := func(t TimeOfDay) *TimeOfDay { return &t }
+
+	rules := ComplianceRules{
+		Jurisdiction:            jurisdiction,
+		RestSeverity:            ComplianceOff,
+		ConsecutiveDaysSeverity: ComplianceOff,
+		MinorSeverity:           ComplianceOff,
+	}
+
+	switch jurisdiction {
+	case "custom":
+	case "us_federal":
+		// FLSA hours for 14 and 15 year olds
+		rules.MinorAge, rules.MinorMaxDailyHours, rules.MinorMaxWeeklyHours = 16, 8, 40
+		rules.MinorLatestEnd = latestEnd("19:00:00")
+		rules.MinorSeverity = ComplianceBlock
+	case "california":
+		// a day of rest in seven is owed, but working the seventh is only overtime
+		rules.MaxConsecutiveDays, rules.ConsecutiveDaysSeverity = 6, ComplianceWarn
+		rules.MinorAge, rules.MinorMaxDailyHours, rules.MinorMaxWeeklyHours = 18, 8, 48
+		rules.MinorLatestEnd = latestEnd("22:00:00")
+		rules.MinorSeverity = ComplianceBlock
+	case "eu":
+		// Working Time Directive daily and weekly rest, Young Workers Directive for minors
+		rules.MinRestHours, rules.RestSeverity = 11, ComplianceBlock
+		rules.MaxConsecutiveDays, rules.ConsecutiveDaysSeverity = 6, ComplianceBlock
+		rules.MinorAge, rules.MinorMaxDailyHours, rules.MinorMaxWeeklyHours = 18, 8, 40
+		rules.MinorLatestEnd = latestEnd("22:00:00")
+		rules.MinorSeverity = ComplianceBlock
+	default:
+		return ComplianceRules{}, false
+	}
+
+	return rules, true
+}
+
+type ComplianceStore struct {
+	db *sql.DB
+}
+
+// Get returns the restaurant's rules, or the custom template, which checks
+// nothing, if it hasn't saved any
+func (s *ComplianceStore) Get(ctx context.Context, restaurantID int64) (*ComplianceRules, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT restaurant_id, jurisdiction, min_rest_hours, rest_severity, max_consecutive_days,
+		       consecutive_days_severity, minor_age, minor_max_daily_hours, minor_max_weekly_hours,
+		       minor_latest_end, minor_severity, updated_at
+		FROM compliance_rules
+		WHERE restaurant_id = $1`
+
+	rules := &ComplianceRules{}
+	err := s.db.QueryRowContext(ctx, query, restaurantID).Scan(
+		&rules.RestaurantID,
+		&rules.Jurisdiction,
+		&rules.MinRestHours,
+		&rules.RestSeverity,
+		&rules.MaxConsecutiveDays,
+		&rules.ConsecutiveDaysSeverity,
+		&rules.MinorAge,
+		&rules.MinorMaxDailyHours,
+		&rules.MinorMaxWeeklyHours,
+		&rules.MinorLatestEnd,
+		&rules.MinorSeverity,
+		&rules.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			defaults, _ := ComplianceTemplate("custom")
+			defaults.RestaurantID = restaurantID
+			return &defaults, nil
+		}
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Replace saves the restaurant's rules over any it had
+func (s *ComplianceStore) Replace(ctx context.Context, rules *ComplianceRules) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO compliance_rules (restaurant_id, jurisdiction, min_rest_hours, rest_severity,
+		    max_consecutive_days, consecutive_days_severity, minor_age, minor_max_daily_hours,
+		    minor_max_weekly_hours, minor_latest_end, minor_severity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (restaurant_id) DO UPDATE
+		SET jurisdiction = EXCLUDED.jurisdiction, min_rest_hours = EXCLUDED.min_rest_hours,
+		    rest_severity = EXCLUDED.rest_severity, max_consecutive_days = EXCLUDED.max_consecutive_days,
+		    consecutive_days_severity = EXCLUDED.consecutive_days_severity, minor_age = EXCLUDED.minor_age,
+		    minor_max_daily_hours = EXCLUDED.minor_max_daily_hours,
+		    minor_max_weekly_hours = EXCLUDED.minor_max_weekly_hours,
+		    minor_latest_end = EXCLUDED.minor_latest_end, minor_severity = EXCLUDED.minor_severity,
+		    updated_at = NOW()
+		RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		rules.RestaurantID,
+		rules.Jurisdiction,
+		rules.MinRestHours,
+		rules.RestSeverity,
+		rules.MaxConsecutiveDays,
+		rules.ConsecutiveDaysSeverity,
+		rules.MinorAge,
+		rules.MinorMaxDailyHours,
+		rules.MinorMaxWeeklyHours,
+		rules.MinorLatestEnd,
+		rules.MinorSeverity,
+	).Scan(&updatedAt)
+	if err != nil {
+		return err
+	}
+
+	rules.UpdatedAt = &updatedAt
+	return nil
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// DisplayBoard is a read-only screen showing a restaurant's shifts for the day
+type DisplayBoard struct {
+	ID           int64      `json:"id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	Name         string     `json:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedBy    *int64     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// BoardShift is a published shift as a display board shows it
+type BoardShift struct {
+	StartTime    TimeOfDay
+	EndTime      TimeOfDay
+	RoleName     string
+	RoleColor    string
+	EmployeeName *string
+	Training     bool
+}
+
+type DisplayBoardStore struct {
+	db *sql.DB
+}
+
+// Create registers a display board; only the token's hash is stored
+func (s *DisplayBoardStore) Create(ctx context.Context, board *DisplayBoard, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO display_boards (restaurant_id, name, token_hash, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, board.RestaurantID, board.Name, hashToken(token), board.CreatedBy).
+		Scan(&board.ID, &board.CreatedAt)
+}
+
+func (s *DisplayBoardStore) List(ctx context.Context, restaurantID int64) ([]*DisplayBoard, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, name, last_used_at, revoked_at, created_by, created_at
+		FROM display_boards
+		WHERE restaurant_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := []*DisplayBoard{}
+	for rows.Next() {
+		var b DisplayBoard
+		if err := rows.Scan(&b.ID, &b.RestaurantID, &b.Name, &b.LastUsedAt, &b.RevokedAt, &b.CreatedBy, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		boards = append(boards, &b)
+	}
+
+	return boards, rows.Err()
+}
+
+// Revoke stops the board's token from working
+func (s *DisplayBoardStore) Revoke(ctx context.Context, restaurantID, boardID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE display_boards
+		SET revoked_at = NOW()
+		WHERE id = $1 AND restaurant_id = $2 AND revoked_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, boardID, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the unrevoked board of an active restaurant with this
+// token and records that it was used
+func (s *DisplayBoardStore) Authenticate(ctx context.Context, token string) (*DisplayBoard, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE display_boards b
+		SET last_used_at = NOW()
+		FROM restaurants r
+		WHERE r.id = b.restaurant_id
+		  AND b.token_hash = $1
+		  AND b.revoked_at IS NULL
+		  AND r.archived_at IS NULL
+		RETURNING b.id, b.restaurant_id, b.name, b.last_used_at, b.revoked_at, b.created_by, b.created_at`
+
+	var b DisplayBoard
+	err := s.db.QueryRowContext(ctx, query, hashToken(token)).
+		Scan(&b.ID, &b.RestaurantID, &b.Name, &b.LastUsedAt, &b.RevokedAt, &b.CreatedBy, &b.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// ListShifts returns the restaurant's shifts on date from published schedules,
+// by start time. Drafts stay off the board until they're published.
+func (s *DisplayBoardStore) ListShifts(ctx context.Context, restaurantID int64, date DateOnly) ([]*BoardShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ss.start_time, ss.end_time, ss.role_name, ss.role_color, ss.employee_name, ss.training
+		FROM scheduled_shifts ss
+		JOIN schedules s ON s.id = ss.schedule_id
+		WHERE ss.restaurant_id = $1
+		  AND ss.shift_date = $2::date
+		  AND s.published_at IS NOT NULL
+		ORDER BY ss.start_time, ss.role_name, ss.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*BoardShift{}
+	for rows.Next() {
+		var shift BoardShift
+		if err := rows.Scan(&shift.StartTime, &shift.EndTime, &shift.RoleName, &shift.RoleColor, &shift.EmployeeName, &shift.Training); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, &shift)
+	}
+
+	return shifts, rows.Err()
+}
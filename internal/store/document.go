@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Document is a file a restaurant keeps in blob storage, optionally attached to an employee
+type Document struct {
+	ID           int64     `json:"id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	EmployeeID   *int64    `json:"employee_id,omitempty"`
+	Name         string    `json:"name"`
+	ObjectKey    string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	UploadedBy   *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	DownloadURL  string    `json:"download_url,omitempty"`
+}
+
+type DocumentStore struct {
+	db *sql.DB
+}
+
+func (s *DocumentStore) Create(ctx context.Context, doc *Document) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO documents (restaurant_id, employee_id, name, object_key, content_type, size_bytes, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		doc.RestaurantID,
+		doc.EmployeeID,
+		doc.Name,
+		doc.ObjectKey,
+		doc.ContentType,
+		doc.SizeBytes,
+		doc.UploadedBy,
+	).Scan(
+		&doc.ID,
+		&doc.CreatedAt,
+	)
+}
+
+func (s *DocumentStore) GetByID(ctx context.Context, id int64) (*Document, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, employee_id, name, object_key, content_type, size_bytes, uploaded_by, created_at
+		FROM documents
+		WHERE id = $1`
+
+	var doc Document
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&doc.ID,
+		&doc.RestaurantID,
+		&doc.EmployeeID,
+		&doc.Name,
+		&doc.ObjectKey,
+		&doc.ContentType,
+		&doc.SizeBytes,
+		&doc.UploadedBy,
+		&doc.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// ListByRestaurant returns the restaurant-wide documents, leaving out per-employee ones
+func (s *DocumentStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Document, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, employee_id, name, object_key, content_type, size_bytes, uploaded_by, created_at
+		FROM documents
+		WHERE restaurant_id = $1 AND employee_id IS NULL
+		ORDER BY created_at DESC`
+
+	return s.queryDocuments(ctx, query, restaurantID)
+}
+
+func (s *DocumentStore) ListByEmployee(ctx context.Context, employeeID int64) ([]*Document, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, employee_id, name, object_key, content_type, size_bytes, uploaded_by, created_at
+		FROM documents
+		WHERE employee_id = $1
+		ORDER BY created_at DESC`
+
+	return s.queryDocuments(ctx, query, employeeID)
+}
+
+func (s *DocumentStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *DocumentStore) queryDocuments(ctx context.Context, query string, args ...any) ([]*Document, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []*Document{}
+	for rows.Next() {
+		var doc Document
+		err := rows.Scan(
+			&doc.ID,
+			&doc.RestaurantID,
+			&doc.EmployeeID,
+			&doc.Name,
+			&doc.ObjectKey,
+			&doc.ContentType,
+			&doc.SizeBytes,
+			&doc.UploadedBy,
+			&doc.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
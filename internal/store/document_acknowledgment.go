@@ -0,0 +1,170 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrAlreadyAcknowledged = errors.New("the employee has already acknowledged this document")
+
+// DocumentAcknowledgment is a request for an employee to acknowledge a restaurant
+// document and, once they have, their signature
+type DocumentAcknowledgment struct {
+	ID             int64      `json:"id"`
+	DocumentID     int64      `json:"document_id"`
+	RestaurantID   int64      `json:"restaurant_id"`
+	EmployeeID     int64      `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	RequestedAt    time.Time  `json:"requested_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	SignedName     *string    `json:"signed_name,omitempty"`
+	IPAddress      *string    `json:"ip_address,omitempty"`
+	UserAgent      *string    `json:"user_agent,omitempty"`
+}
+
+// DocumentToAcknowledge is what the holder of an acknowledgment link is asked to sign
+type DocumentToAcknowledge struct {
+	*DocumentAcknowledgment
+	DocumentName   string `json:"document_name"`
+	ObjectKey      string `json:"-"`
+	RestaurantName string `json:"restaurant_name"`
+}
+
+type DocumentAcknowledgmentStore struct {
+	db *sql.DB
+}
+
+// Request asks the employee to acknowledge the document with a new token, replacing
+// any earlier one; only the token's hash is stored. It returns ErrAlreadyAcknowledged
+// once the employee has acknowledged.
+func (s *DocumentAcknowledgmentStore) Request(ctx context.Context, ack *DocumentAcknowledgment, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO document_acknowledgments (document_id, restaurant_id, employee_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (document_id, employee_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, requested_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE document_acknowledgments.acknowledged_at IS NULL
+		RETURNING id, requested_at`
+
+	err := s.db.QueryRowContext(ctx, query, ack.DocumentID, ack.RestaurantID, ack.EmployeeID, hashToken(token), ack.ExpiresAt).
+		Scan(&ack.ID, &ack.RequestedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAlreadyAcknowledged
+	}
+	return err
+}
+
+// ListByDocument returns every request for the document, by employee name
+func (s *DocumentAcknowledgmentStore) ListByDocument(ctx context.Context, documentID int64) ([]*DocumentAcknowledgment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT a.id, a.document_id, a.restaurant_id, a.employee_id, e.full_name,
+		       a.requested_at, a.expires_at, a.acknowledged_at, a.signed_name, a.ip_address, a.user_agent
+		FROM document_acknowledgments a
+		JOIN employees e ON e.id = a.employee_id
+		WHERE a.document_id = $1
+		ORDER BY e.full_name, a.id`
+
+	rows, err := s.db.QueryContext(ctx, query, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	acks := []*DocumentAcknowledgment{}
+	for rows.Next() {
+		var a DocumentAcknowledgment
+		if err := rows.Scan(
+			&a.ID,
+			&a.DocumentID,
+			&a.RestaurantID,
+			&a.EmployeeID,
+			&a.EmployeeName,
+			&a.RequestedAt,
+			&a.ExpiresAt,
+			&a.AcknowledgedAt,
+			&a.SignedName,
+			&a.IPAddress,
+			&a.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		acks = append(acks, &a)
+	}
+
+	return acks, rows.Err()
+}
+
+// Authenticate returns the request with this token, in an active restaurant, along
+// with the document it is for. A request that has been acknowledged keeps working so
+// the employee can see their signature; an outstanding one stops when it expires.
+func (s *DocumentAcknowledgmentStore) Authenticate(ctx context.Context, token string, now time.Time) (*DocumentToAcknowledge, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT a.id, a.document_id, a.restaurant_id, a.employee_id, e.full_name,
+		       a.requested_at, a.expires_at, a.acknowledged_at, a.signed_name,
+		       d.name, d.object_key, r.name
+		FROM document_acknowledgments a
+		JOIN employees e ON e.id = a.employee_id
+		JOIN documents d ON d.id = a.document_id
+		JOIN restaurants r ON r.id = a.restaurant_id
+		WHERE a.token_hash = $1
+		  AND (a.acknowledged_at IS NOT NULL OR a.expires_at > $2)
+		  AND r.archived_at IS NULL`
+
+	doc := &DocumentToAcknowledge{DocumentAcknowledgment: &DocumentAcknowledgment{}}
+	a := doc.DocumentAcknowledgment
+	err := s.db.QueryRowContext(ctx, query, hashToken(token), now).Scan(
+		&a.ID,
+		&a.DocumentID,
+		&a.RestaurantID,
+		&a.EmployeeID,
+		&a.EmployeeName,
+		&a.RequestedAt,
+		&a.ExpiresAt,
+		&a.AcknowledgedAt,
+		&a.SignedName,
+		&doc.DocumentName,
+		&doc.ObjectKey,
+		&doc.RestaurantName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Acknowledge signs the request with the name the employee typed and the device
+// they used. It returns ErrAlreadyAcknowledged if it was signed already, keeping
+// the first signature.
+func (s *DocumentAcknowledgmentStore) Acknowledge(ctx context.Context, ack *DocumentAcknowledgment, signedName, ipAddress, userAgent string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE document_acknowledgments
+		SET acknowledged_at = NOW(), signed_name = $2, ip_address = $3, user_agent = $4
+		WHERE id = $1 AND acknowledged_at IS NULL
+		RETURNING acknowledged_at, signed_name`
+
+	err := s.db.QueryRowContext(ctx, query, ack.ID, signedName, ipAddress, userAgent).
+		Scan(&ack.AcknowledgedAt, &ack.SignedName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAlreadyAcknowledged
+	}
+	return err
+}
@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Kinds of tracked schedule email
+const (
+	EmailKindSchedule               = "schedule"
+	EmailKindChanges                = "changes"
+	EmailKindAcknowledgmentReminder = "acknowledgment_reminder"
+)
+
+// Delivery statuses: sent until SendGrid reports on the message, failed when it was never handed over
+const (
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+	DeliveryDelivered  = "delivered"
+	DeliveryDeferred   = "deferred"
+	DeliveryBounced    = "bounced"
+	DeliveryDropped    = "dropped"
+	DeliverySpamReport = "spam_report"
+)
+
+// EmailDelivery is one schedule email sent to an employee and what became of it
+type EmailDelivery struct {
+	ID           int64  `json:"id"`
+	ScheduleID   int64  `json:"schedule_id"`
+	RestaurantID int64  `json:"restaurant_id"`
+	EmployeeID   *int64 `json:"employee_id,omitempty"`
+	EmployeeName string `json:"employee_name,omitempty"`
+	Email        string `json:"email"`
+	Kind         string `json:"kind"`
+	Status       string `json:"status"`
+	// Detail is why the send failed or the message bounced or was dropped
+	Detail    *string   `json:"detail,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EmailBlast is one run of a schedule's send-email: who it reached and who it didn't
+type EmailBlast struct {
+	ID           int64 `json:"id"`
+	ScheduleID   int64 `json:"schedule_id"`
+	RestaurantID int64 `json:"restaurant_id"`
+	// RetryOf is the blast whose failed recipients this one re-sent to
+	RetryOf         *int64               `json:"retry_of,omitempty"`
+	IncludeEvents   bool                 `json:"include_events"`
+	TotalRecipients int                  `json:"total_recipients"`
+	Successful      int                  `json:"successful"`
+	Failed          int                  `json:"failed"`
+	Failures        []*EmailBlastFailure `json:"failures"`
+	CreatedAt       time.Time            `json:"created_at"`
+}
+
+// EmailBlastFailure is a recipient a blast didn't reach and why
+type EmailBlastFailure struct {
+	EmployeeID   int64  `json:"employee_id"`
+	EmployeeName string `json:"employee_name"`
+	Email        string `json:"email"`
+	Error        string `json:"error"`
+}
+
+// DeliveryEvent is a webhook report on a tracked email
+type DeliveryEvent struct {
+	DeliveryID int64
+	Status     string
+	Detail     string
+	At         time.Time
+	// HardBounce flags the employee's address as undeliverable
+	HardBounce bool
+}
+
+type EmailDeliveryStore struct {
+	db *sql.DB
+}
+
+// Create records an email about to be sent, or one that failed with delivery.Status set to DeliveryFailed
+func (s *EmailDeliveryStore) Create(ctx context.Context, delivery *EmailDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if delivery.Status == "" {
+		delivery.Status = DeliverySent
+	}
+
+	query := `
+		INSERT INTO email_deliveries (schedule_id, restaurant_id, employee_id, email, kind, status, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, sent_at, updated_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		delivery.ScheduleID,
+		delivery.RestaurantID,
+		delivery.EmployeeID,
+		delivery.Email,
+		delivery.Kind,
+		delivery.Status,
+		delivery.Detail,
+	).Scan(&delivery.ID, &delivery.SentAt, &delivery.UpdatedAt)
+}
+
+// MarkFailed records that the email couldn't be handed to the mail provider
+func (s *EmailDeliveryStore) MarkFailed(ctx context.Context, id int64, detail string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE email_deliveries
+		SET status = 'failed', detail = $2, updated_at = NOW()
+		WHERE id = $1`, id, detail)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// RecordEvent applies a webhook event to its delivery, unless a later event
+// was already applied, and flags the employee's address on a hard bounce.
+// ErrNotFound when the delivery doesn't exist.
+func (s *EmailDeliveryStore) RecordEvent(ctx context.Context, event *DeliveryEvent) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		var employeeID *int64
+		var email string
+		var lastEventAt *time.Time
+		err := tx.QueryRowContext(ctx, `
+			SELECT employee_id, email, last_event_at
+			FROM email_deliveries
+			WHERE id = $1
+			FOR UPDATE`, event.DeliveryID,
+		).Scan(&employeeID, &email, &lastEventAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if lastEventAt == nil || !event.At.Before(*lastEventAt) {
+			var detail *string
+			if event.Detail != "" {
+				detail = &event.Detail
+			}
+			_, err := tx.ExecContext(ctx, `
+				UPDATE email_deliveries
+				SET status = $2, detail = $3, last_event_at = $4, updated_at = NOW()
+				WHERE id = $1`, event.DeliveryID, event.Status, detail, event.At)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Only while the employee still has the address that bounced
+		if event.HardBounce && employeeID != nil {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE employees
+				SET email_bounced_at = $3, email_bounce_reason = $4
+				WHERE id = $1 AND email = $2`, *employeeID, email, event.At, event.Detail)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListBySchedule returns every tracked email sent for the schedule, newest first
+func (s *EmailDeliveryStore) ListBySchedule(ctx context.Context, scheduleID int64) ([]*EmailDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT d.id, d.schedule_id, d.restaurant_id, d.employee_id, COALESCE(e.full_name, ''),
+		       d.email, d.kind, d.status, d.detail, d.sent_at, d.updated_at
+		FROM email_deliveries d
+		LEFT JOIN employees e ON e.id = d.employee_id
+		WHERE d.schedule_id = $1
+		ORDER BY d.sent_at DESC, d.id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*EmailDelivery{}
+	for rows.Next() {
+		var d EmailDelivery
+		err := rows.Scan(
+			&d.ID,
+			&d.ScheduleID,
+			&d.RestaurantID,
+			&d.EmployeeID,
+			&d.EmployeeName,
+			&d.Email,
+			&d.Kind,
+			&d.Status,
+			&d.Detail,
+			&d.SentAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CreateBlast records a send-email run on a schedule
+func (s *EmailDeliveryStore) CreateBlast(ctx context.Context, blast *EmailBlast) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if blast.Failures == nil {
+		blast.Failures = []*EmailBlastFailure{}
+	}
+	failures, err := json.Marshal(blast.Failures)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO schedule_email_blasts (schedule_id, restaurant_id, retry_of, include_events, total_recipients, successful, failed, failures)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		blast.ScheduleID,
+		blast.RestaurantID,
+		blast.RetryOf,
+		blast.IncludeEvents,
+		blast.TotalRecipients,
+		blast.Successful,
+		blast.Failed,
+		failures,
+	).Scan(&blast.ID, &blast.CreatedAt)
+}
+
+// ListBlasts returns the schedule's send-email runs, newest first
+func (s *EmailDeliveryStore) ListBlasts(ctx context.Context, scheduleID int64) ([]*EmailBlast, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+emailBlastColumns+`
+		FROM schedule_email_blasts
+		WHERE schedule_id = $1
+		ORDER BY created_at DESC, id DESC`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blasts := []*EmailBlast{}
+	for rows.Next() {
+		blast, err := scanEmailBlast(rows)
+		if err != nil {
+			return nil, err
+		}
+		blasts = append(blasts, blast)
+	}
+
+	return blasts, rows.Err()
+}
+
+// LatestBlast returns the schedule's most recent send-email run, retries included
+func (s *EmailDeliveryStore) LatestBlast(ctx context.Context, scheduleID int64) (*EmailBlast, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	blast, err := scanEmailBlast(s.db.QueryRowContext(ctx, `
+		SELECT `+emailBlastColumns+`
+		FROM schedule_email_blasts
+		WHERE schedule_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`, scheduleID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return blast, err
+}
+
+const emailBlastColumns = `id, schedule_id, restaurant_id, retry_of, include_events, total_recipients, successful, failed, failures, created_at`
+
+func scanEmailBlast(row interface{ Scan(...any) error }) (*EmailBlast, error) {
+	var blast EmailBlast
+	var failures []byte
+	err := row.Scan(
+		&blast.ID,
+		&blast.ScheduleID,
+		&blast.RestaurantID,
+		&blast.RetryOf,
+		&blast.IncludeEvents,
+		&blast.TotalRecipients,
+		&blast.Successful,
+		&blast.Failed,
+		&failures,
+		&blast.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(failures, &blast.Failures); err != nil {
+		return nil, err
+	}
+	return &blast, nil
+}
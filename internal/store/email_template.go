@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// EmailTemplate is a restaurant's customization of its schedule emails; empty fields use the defaults
+type EmailTemplate struct {
+	RestaurantID  int64     `json:"restaurant_id"`
+	Subject       string    `json:"subject"`
+	HeaderMessage string    `json:"header_message"`
+	LogoURL       string    `json:"logo_url"`
+	AccentColor   string    `json:"accent_color"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type EmailTemplateStore struct {
+	db *sql.DB
+}
+
+func (s *EmailTemplateStore) GetByRestaurant(ctx context.Context, restaurantID int64) (*EmailTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT restaurant_id, subject, header_message, logo_url, accent_color, created_at, updated_at
+		FROM restaurant_email_templates
+		WHERE restaurant_id = $1`
+
+	var tmpl EmailTemplate
+	err := s.db.QueryRowContext(ctx, query, restaurantID).Scan(
+		&tmpl.RestaurantID,
+		&tmpl.Subject,
+		&tmpl.HeaderMessage,
+		&tmpl.LogoURL,
+		&tmpl.AccentColor,
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &tmpl, nil
+}
+
+// Upsert creates or replaces the restaurant's email template
+func (s *EmailTemplateStore) Upsert(ctx context.Context, tmpl *EmailTemplate) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO restaurant_email_templates (restaurant_id, subject, header_message, logo_url, accent_color)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (restaurant_id)
+		DO UPDATE SET subject = EXCLUDED.subject, header_message = EXCLUDED.header_message,
+		              logo_url = EXCLUDED.logo_url, accent_color = EXCLUDED.accent_color, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		tmpl.RestaurantID,
+		tmpl.Subject,
+		tmpl.HeaderMessage,
+		tmpl.LogoURL,
+		tmpl.AccentColor,
+	).Scan(
+		&tmpl.CreatedAt,
+		&tmpl.UpdatedAt,
+	)
+}
+
+func (s *EmailTemplateStore) Delete(ctx context.Context, restaurantID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM restaurant_email_templates WHERE restaurant_id = $1`, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// VerifiedEmail is the address an employee confirmed through a verification link
+type VerifiedEmail struct {
+	EmployeeID     int64     `json:"employee_id"`
+	EmployeeName   string    `json:"employee_name"`
+	Email          string    `json:"email"`
+	RestaurantName string    `json:"restaurant_name"`
+	VerifiedAt     time.Time `json:"verified_at"`
+}
+
+// RequestEmailVerification gives the employee's current address a new verification
+// token valid until expiresAt, replacing any earlier one; only the token's hash is stored
+func (s *EmployeeStore) RequestEmailVerification(ctx context.Context, employee *Employee, token string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO employee_email_verifications (employee_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (employee_id) DO UPDATE
+		SET email = EXCLUDED.email, token_hash = EXCLUDED.token_hash, requested_at = NOW(), expires_at = EXCLUDED.expires_at`
+
+	_, err := s.db.ExecContext(ctx, query, employee.ID, employee.Email, hashToken(token), expiresAt)
+	return err
+}
+
+// VerifyEmail marks the address the token was sent to as verified and uses the
+// token up. It returns ErrNotFound when the token is unknown or expired, the
+// employee's email has changed since, or the restaurant is archived.
+func (s *EmployeeStore) VerifyEmail(ctx context.Context, token string, now time.Time) (*VerifiedEmail, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH used AS (
+			DELETE FROM employee_email_verifications v
+			USING employees e, restaurants r
+			WHERE v.token_hash = $1
+			  AND v.expires_at > $2
+			  AND e.id = v.employee_id
+			  AND e.email = v.email
+			  AND r.id = e.restaurant_id
+			  AND r.archived_at IS NULL
+			RETURNING v.employee_id, r.name
+		)
+		UPDATE employees e
+		SET email_verified_at = COALESCE(e.email_verified_at, NOW())
+		FROM used
+		WHERE e.id = used.employee_id
+		RETURNING e.id, e.full_name, e.email, used.name, e.email_verified_at`
+
+	var verified VerifiedEmail
+	err := s.db.QueryRowContext(ctx, query, hashToken(token), now).Scan(
+		&verified.EmployeeID,
+		&verified.EmployeeName,
+		&verified.Email,
+		&verified.RestaurantName,
+		&verified.VerifiedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &verified, nil
+}
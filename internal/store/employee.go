@@ -7,13 +7,40 @@ import (
 	"time"
 )
 
+var (
+	ErrDuplicateEmployee = errors.New("an employee with that email already exists")
+	ErrDuplicateExternalID = errors.New("another employee already has that external_id")
+)
+
 type Employee struct {
-    ID           int64     `db:"id" json:"id"`
-    RestaurantID int64     `db:"restaurant_id" json:"restaurant_id"`
-    FullName     string    `db:"full_name" json:"full_name"`
-    Email        string    `db:"email" json:"email"`
-    CreatedAt    time.Time `db:"created_at" json:"created_at"`
-    UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+    ID              int64     `db:"id" json:"id"`
+    RestaurantID    int64     `db:"restaurant_id" json:"restaurant_id"`
+    FullName        string    `db:"full_name" json:"full_name"`
+    Email           string    `db:"email" json:"email"`
+    Locale          *string   `db:"locale" json:"locale,omitempty"`
+    HourlyRateCents *int      `db:"hourly_rate_cents" json:"hourly_rate_cents,omitempty"` // prices the employee's shifts; nil when not set
+    // Seniority ranks the employee for automatic assignment under the seniority_first policy; higher goes first
+    Seniority       int       `db:"seniority" json:"seniority"`
+    // Birthday and HireDate feed the owner's weekly birthday and work anniversary digest; nil when not given
+    Birthday        *DateOnly `db:"birthday" json:"birthday,omitempty"`
+    HireDate        *DateOnly `db:"hire_date" json:"hire_date,omitempty"`
+    AvatarID        *string   `db:"avatar_id" json:"-"`
+    AvatarURL       string    `json:"avatar_url,omitempty"`
+    // EmailBouncedAt is set when mail to the address hard-bounced; schedule emails skip it until the email changes
+    EmailBouncedAt    *time.Time `db:"email_bounced_at" json:"email_bounced_at,omitempty"`
+    EmailBounceReason *string    `db:"email_bounce_reason" json:"email_bounce_reason,omitempty"`
+    // EmailVerifiedAt is when the employee confirmed the address through a verification link; cleared when the email changes
+    EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at,omitempty"`
+    // ExternalID is the employee's ID in an HR system, unique within the restaurant; nil when not set
+    ExternalID      *string   `db:"external_id" json:"external_id,omitempty"`
+    // NotificationMode overrides the restaurant's notification_mode for the employee; nil follows it
+    NotificationMode *NotificationMode `db:"notification_mode" json:"notification_mode,omitempty"`
+    // TerminatedOn is the day the employee left; retention policies anonymize them a while after
+    TerminatedOn    *DateOnly  `db:"terminated_on" json:"terminated_on,omitempty"`
+    // ErasedAt is when the employee's personal data was erased
+    ErasedAt        *time.Time `db:"erased_at" json:"erased_at,omitempty"`
+    CreatedAt       time.Time `db:"created_at" json:"created_at"`
+    UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type EmployeeRole struct {
@@ -23,6 +50,8 @@ type EmployeeRole struct {
 
 type EmployeeStore struct {
 	db *sql.DB
+	// replica serves reads that tolerate replication lag; nil sends them to db
+	replica *sql.DB
 }
 
 func (s *EmployeeStore) Create(ctx context.Context, employee *Employee) error {
@@ -30,8 +59,8 @@ func (s *EmployeeStore) Create(ctx context.Context, employee *Employee) error {
 	defer cancel()
 
 	query := `
-		INSERT INTO employees (restaurant_id, full_name, email, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO employees (restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date, external_id, notification_mode, terminated_on, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
 	err := s.db.QueryRowContext(
@@ -40,9 +69,23 @@ func (s *EmployeeStore) Create(ctx context.Context, employee *Employee) error {
 		employee.RestaurantID,
 		employee.FullName,
 		employee.Email,
+		employee.Locale,
+		employee.HourlyRateCents,
+		employee.Seniority,
+		employee.Birthday,
+		employee.HireDate,
+		employee.ExternalID,
+		employee.NotificationMode,
+		employee.TerminatedOn,
 	).Scan(&employee.ID, &employee.CreatedAt, &employee.UpdatedAt)
 
 	if err != nil {
+		switch {
+		case isUniqueViolation(err, "uq_employees_restaurant_email"):
+			return ErrDuplicateEmployee
+		case isUniqueViolation(err, "uq_employees_restaurant_external_id"):
+			return ErrDuplicateExternalID
+		}
 		return err
 	}
 
@@ -54,7 +97,7 @@ func (s *EmployeeStore) GetByID(ctx context.Context, id int64) (*Employee, error
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, full_name, email, created_at, updated_at
+		SELECT id, restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date, avatar_id, email_bounced_at, email_bounce_reason, email_verified_at, external_id, notification_mode, terminated_on, erased_at, created_at, updated_at
 		FROM employees
 		WHERE id = $1`
 
@@ -64,6 +107,19 @@ func (s *EmployeeStore) GetByID(ctx context.Context, id int64) (*Employee, error
 		&employee.RestaurantID,
 		&employee.FullName,
 		&employee.Email,
+		&employee.Locale,
+		&employee.HourlyRateCents,
+		&employee.Seniority,
+		&employee.Birthday,
+		&employee.HireDate,
+		&employee.AvatarID,
+		&employee.EmailBouncedAt,
+		&employee.EmailBounceReason,
+		&employee.EmailVerifiedAt,
+		&employee.ExternalID,
+		&employee.NotificationMode,
+		&employee.TerminatedOn,
+		&employee.ErasedAt,
 		&employee.CreatedAt,
 		&employee.UpdatedAt,
 	)
@@ -78,17 +134,75 @@ func (s *EmployeeStore) GetByID(ctx context.Context, id int64) (*Employee, error
 	return &employee, nil
 }
 
+// GetByIDs loads several employees in one query; IDs that don't exist are skipped
+func (s *EmployeeStore) GetByIDs(ctx context.Context, ids []int64) ([]*Employee, error) {
+	if len(ids) == 0 {
+		return []*Employee{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date, avatar_id, email_bounced_at, email_bounce_reason, email_verified_at, external_id, notification_mode, terminated_on, erased_at, created_at, updated_at
+		FROM employees
+		WHERE id = ANY($1::bigint[])`
+
+	rows, err := s.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []*Employee{}
+
+	for rows.Next() {
+		var employee Employee
+		err := rows.Scan(
+			&employee.ID,
+			&employee.RestaurantID,
+			&employee.FullName,
+			&employee.Email,
+			&employee.Locale,
+			&employee.HourlyRateCents,
+			&employee.Seniority,
+			&employee.Birthday,
+			&employee.HireDate,
+			&employee.AvatarID,
+			&employee.EmailBouncedAt,
+			&employee.EmailBounceReason,
+			&employee.EmailVerifiedAt,
+			&employee.ExternalID,
+			&employee.NotificationMode,
+			&employee.TerminatedOn,
+			&employee.ErasedAt,
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		employees = append(employees, &employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return employees, nil
+}
+
 func (s *EmployeeStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Employee, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, full_name, email, created_at, updated_at
+		SELECT id, restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date, avatar_id, email_bounced_at, email_bounce_reason, email_verified_at, external_id, notification_mode, terminated_on, erased_at, created_at, updated_at
 		FROM employees
 		WHERE restaurant_id = $1
 		ORDER BY full_name`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +217,19 @@ func (s *EmployeeStore) ListByRestaurant(ctx context.Context, restaurantID int64
 			&employee.RestaurantID,
 			&employee.FullName,
 			&employee.Email,
+			&employee.Locale,
+			&employee.HourlyRateCents,
+			&employee.Seniority,
+			&employee.Birthday,
+			&employee.HireDate,
+			&employee.AvatarID,
+			&employee.EmailBouncedAt,
+			&employee.EmailBounceReason,
+			&employee.EmailVerifiedAt,
+			&employee.ExternalID,
+			&employee.NotificationMode,
+			&employee.TerminatedOn,
+			&employee.ErasedAt,
 			&employee.CreatedAt,
 			&employee.UpdatedAt,
 		)
@@ -119,31 +246,82 @@ func (s *EmployeeStore) ListByRestaurant(ctx context.Context, restaurantID int64
 	return employees, nil
 }
 
+// Update saves the employee and propagates the name to the denormalized copies on scheduled_shifts
 func (s *EmployeeStore) Update(ctx context.Context, employee *Employee) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			UPDATE employees
+			SET full_name = $1, email = $2, locale = $3, hourly_rate_cents = $4, seniority = $5, birthday = $6, hire_date = $7, external_id = $9, notification_mode = $10, terminated_on = $11, updated_at = NOW(),
+			    email_bounced_at = CASE WHEN email = $2 THEN email_bounced_at END,
+			    email_bounce_reason = CASE WHEN email = $2 THEN email_bounce_reason END,
+			    email_verified_at = CASE WHEN email = $2 THEN email_verified_at END
+			WHERE id = $8
+			RETURNING updated_at, email_bounced_at, email_bounce_reason, email_verified_at`
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			employee.FullName,
+			employee.Email,
+			employee.Locale,
+			employee.HourlyRateCents,
+			employee.Seniority,
+			employee.Birthday,
+			employee.HireDate,
+			employee.ID,
+			employee.ExternalID,
+			employee.NotificationMode,
+			employee.TerminatedOn,
+		).Scan(&employee.UpdatedAt, &employee.EmailBouncedAt, &employee.EmailBounceReason, &employee.EmailVerifiedAt)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			case isUniqueViolation(err, "uq_employees_restaurant_email"):
+				return ErrDuplicateEmployee
+			case isUniqueViolation(err, "uq_employees_restaurant_external_id"):
+				return ErrDuplicateExternalID
+			default:
+				return err
+			}
+		}
+
+		syncQuery := `
+			UPDATE scheduled_shifts
+			SET employee_name = $1
+			WHERE employee_id = $2
+			  AND employee_name IS DISTINCT FROM $1`
+
+		_, err = tx.ExecContext(ctx, syncQuery, employee.FullName, employee.ID)
+		return err
+	})
+}
+
+// SetAvatar points the employee at a new set of avatar images, or clears it when avatarID is nil
+func (s *EmployeeStore) SetAvatar(ctx context.Context, employeeID int64, avatarID *string) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	query := `
-		UPDATE employees
-		SET full_name = $1, email = $2, updated_at = NOW()
-		WHERE id = $3
-		RETURNING updated_at`
+	query := `UPDATE employees SET avatar_id = $1, updated_at = NOW() WHERE id = $2`
 
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		employee.FullName,
-		employee.Email,
-		employee.ID,
-	).Scan(&employee.UpdatedAt)
+	result, err := s.db.ExecContext(ctx, query, avatarID, employeeID)
+	if err != nil {
+		return err
+	}
 
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
-		}
 		return err
 	}
 
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
@@ -285,4 +463,53 @@ func (s *EmployeeStore) GetRoles(ctx context.Context, employeeID, restaurantID i
 	}
 
 	return roles, nil
-}
\ No newline at end of file
+}
+
+// RoleIDsByRestaurant returns the roles each of the restaurant's employees
+// holds, keyed by employee, in one query
+func (s *EmployeeStore) RoleIDsByRestaurant(ctx context.Context, restaurantID int64) (map[int64][]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT er.employee_id, er.role_id
+		FROM employee_roles er
+		INNER JOIN roles r ON r.id = er.role_id
+		WHERE r.restaurant_id = $1
+		ORDER BY er.employee_id, er.role_id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roleIDs := make(map[int64][]int64)
+	for rows.Next() {
+		var employeeID, roleID int64
+		if err := rows.Scan(&employeeID, &roleID); err != nil {
+			return nil, err
+		}
+		roleIDs[employeeID] = append(roleIDs[employeeID], roleID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roleIDs, nil
+}
+
+func (s *EmployeeStore) CountByRestaurant(ctx context.Context, restaurantID int64) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `SELECT COUNT(*) FROM employees WHERE restaurant_id = $1`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, restaurantID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const AuditEntityEmployeeNote = "employee_note"
+
+// EmployeeNote is a private note a manager added to an employee's file. Notes are kept
+// apart from the employee record so nothing that shows employees their data carries them.
+type EmployeeNote struct {
+	ID           int64  `json:"id"`
+	RestaurantID int64  `json:"restaurant_id"`
+	EmployeeID   int64  `json:"employee_id"`
+	Body         string `json:"body"`
+	AuthorUserID *int64 `json:"author_user_id,omitempty"`
+	// Joined from users; empty when the author's account is gone
+	AuthorName string    `json:"author_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type EmployeeNoteStore struct {
+	db *sql.DB
+}
+
+// ListByEmployee returns the employee's notes, newest first
+func (s *EmployeeNoteStore) ListByEmployee(ctx context.Context, employeeID int64) ([]*EmployeeNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT n.id, n.restaurant_id, n.employee_id, n.body, n.author_user_id,
+		       COALESCE(TRIM(u.first_name || ' ' || u.last_name), ''), n.created_at
+		FROM employee_manager_notes n
+		LEFT JOIN users u ON u.id = n.author_user_id
+		WHERE n.employee_id = $1
+		ORDER BY n.created_at DESC, n.id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*EmployeeNote{}
+	for rows.Next() {
+		var note EmployeeNote
+		if err := rows.Scan(
+			&note.ID,
+			&note.RestaurantID,
+			&note.EmployeeID,
+			&note.Body,
+			&note.AuthorUserID,
+			&note.AuthorName,
+			&note.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		notes = append(notes, &note)
+	}
+
+	return notes, rows.Err()
+}
+
+// Create adds a note; notes are never edited, a correction is a new note
+func (s *EmployeeNoteStore) Create(ctx context.Context, note *EmployeeNote) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO employee_manager_notes (restaurant_id, employee_id, author_user_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, note.RestaurantID, note.EmployeeID, note.AuthorUserID, note.Body).
+		Scan(&note.ID, &note.CreatedAt)
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// EmployeeErasure reports what erasing an employee removed. The files it
+// lists are still in blob storage for the caller to delete.
+type EmployeeErasure struct {
+	Employee              *Employee `json:"employee"`
+	DocumentsDeleted      int       `json:"documents_deleted"`
+	CertificationsDeleted int64     `json:"certifications_deleted"`
+	NotificationsDeleted  int64     `json:"notifications_deleted"`
+	DocumentKeys          []string  `json:"-"`
+	// AvatarID is the avatar the employee had, its variants still to delete
+	AvatarID *string `json:"-"`
+}
+
+// ErasedEmployeeName is the name an erased employee keeps, so their shifts still count
+func ErasedEmployeeName(employeeID int64) string {
+	return fmt.Sprintf("Former employee #%d", employeeID)
+}
+
+func erasedEmployeeEmail(employeeID int64) string {
+	return fmt.Sprintf("erased-%d@erased.invalid", employeeID)
+}
+
+// Erase anonymizes an employee for a privacy request. Their name, email,
+// locale and avatar are replaced or cleared, along with the copies of their
+// name in shifts (by trigger), schedule snapshots and audit summaries, and
+// their documents, certifications, notifications, kiosk PIN and email delivery
+// records are deleted. The employee row, their shifts and role history stay
+// for scheduling totals.
+func (s *EmployeeStore) Erase(ctx context.Context, employeeID int64) (*EmployeeErasure, error) {
+	erasure := &EmployeeErasure{DocumentKeys: []string{}}
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+		defer cancel()
+
+		var oldName string
+		err := tx.QueryRowContext(ctx, `SELECT full_name, avatar_id FROM employees WHERE id = $1 FOR UPDATE`, employeeID).
+			Scan(&oldName, &erasure.AvatarID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `DELETE FROM documents WHERE employee_id = $1 RETURNING object_key`, employeeID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var key string
+			if err := rows.Scan(&key); err != nil {
+				rows.Close()
+				return err
+			}
+			erasure.DocumentKeys = append(erasure.DocumentKeys, key)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		erasure.DocumentsDeleted = len(erasure.DocumentKeys)
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM employee_certifications WHERE employee_id = $1`, employeeID)
+		if err != nil {
+			return err
+		}
+		if erasure.CertificationsDeleted, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		// The kiosk PIN goes; time entries stay, like shifts
+		if _, err := tx.ExecContext(ctx, `DELETE FROM employee_pins WHERE employee_id = $1`, employeeID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM email_deliveries WHERE employee_id = $1`, employeeID); err != nil {
+			return err
+		}
+
+		// Signatures carry the employee's typed name and device
+		if _, err := tx.ExecContext(ctx, `DELETE FROM document_acknowledgments WHERE employee_id = $1`, employeeID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM employee_manager_notes WHERE employee_id = $1`, employeeID); err != nil {
+			return err
+		}
+
+		res, err = tx.ExecContext(ctx, `DELETE FROM notifications WHERE employee_id = $1`, employeeID)
+		if err != nil {
+			return err
+		}
+		if erasure.NotificationsDeleted, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		// trg_sync_employee_name carries the new name into scheduled_shifts
+		var employee Employee
+		err = tx.QueryRowContext(ctx, `
+			UPDATE employees
+			SET full_name = $2, email = $3, locale = NULL, avatar_id = NULL, birthday = NULL, hire_date = NULL,
+			    email_bounced_at = NULL, email_bounce_reason = NULL, email_verified_at = NULL, external_id = NULL, updated_at = NOW(),
+			    erased_at = NOW()
+			WHERE id = $1
+			RETURNING id, restaurant_id, full_name, email, locale, avatar_id, terminated_on, erased_at, created_at, updated_at`,
+			employeeID, ErasedEmployeeName(employeeID), erasedEmployeeEmail(employeeID),
+		).Scan(
+			&employee.ID,
+			&employee.RestaurantID,
+			&employee.FullName,
+			&employee.Email,
+			&employee.Locale,
+			&employee.AvatarID,
+			&employee.TerminatedOn,
+			&employee.ErasedAt,
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		erasure.Employee = &employee
+
+		// snapshots are otherwise immutable; this lets the erasure rewrite the name in them
+		if _, err := tx.ExecContext(ctx, `SET LOCAL resa.erasing_employee = 'on'`); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE schedule_snapshots snap
+			SET shifts = (
+				SELECT jsonb_agg(
+					CASE WHEN (e->>'employee_id')::int = $1 THEN jsonb_set(e, '{employee_name}', to_jsonb($2::text)) ELSE e END
+					ORDER BY ord)
+				FROM jsonb_array_elements(snap.shifts) WITH ORDINALITY AS t(e, ord)
+			)
+			FROM schedules s
+			WHERE s.id = snap.schedule_id
+			  AND s.restaurant_id = $3
+			  AND snap.shifts @> jsonb_build_array(jsonb_build_object('employee_id', $1::int))`,
+			employeeID, employee.FullName, employee.RestaurantID,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Only entries about this employee's shifts are rewritten, so a namesake's stay intact
+		if oldName != "" {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE audit_log
+				SET summary = REPLACE(summary, $2, $3)
+				WHERE restaurant_id = $4
+				  AND entity_type = $5
+				  AND (changes->'employee_id'->>'from' = $1::text OR changes->'employee_id'->>'to' = $1::text)`,
+				employeeID, oldName, employee.FullName, employee.RestaurantID, AuditEntityShift,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return erasure, nil
+}
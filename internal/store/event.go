@@ -5,8 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"time"
-
-	"github.com/lib/pq"
 )
 
 // Event represents a restaurant event with time and date
@@ -21,6 +19,18 @@ type Event struct {
 	CreatedAt    time.Time   `json:"created_at"`
 	UpdatedAt    time.Time   `json:"updated_at"`
 	Employees    []*Employee `json:"employees"`
+	// Shifts are the scheduled shifts linked to the event for extra staffing
+	Shifts   []*ScheduledShift `json:"shifts"`
+	Coverage EventCoverage     `json:"coverage"`
+}
+
+// EventCoverage summarizes how well an event is staffed
+type EventCoverage struct {
+	Shifts        int `json:"shifts"`
+	StaffedShifts int `json:"staffed_shifts"`
+	OpenShifts    int `json:"open_shifts"`
+	// Staff counts each employee once, whether assigned to the event, a linked shift or both
+	Staff int `json:"staff"`
 }
 
 // EventEmployee represents the junction table for event-employee assignments
@@ -31,6 +41,8 @@ type EventEmployee struct {
 
 type EventStore struct {
 	db *sql.DB
+	// replica serves reads that tolerate replication lag; nil sends them to db
+	replica *sql.DB
 }
 
 func NewEventStore(db *sql.DB) *EventStore {
@@ -93,7 +105,11 @@ func (s *EventStore) GetByID(ctx context.Context, id int64) (*Event, error) {
 		return nil, err
 	}
 
-	if err := s.fillEmployees(ctx, []*Event{&event}); err != nil {
+	if err := s.fillEmployees(ctx, s.db, []*Event{&event}); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillShifts(ctx, s.db, []*Event{&event}); err != nil {
 		return nil, err
 	}
 
@@ -110,7 +126,8 @@ func (s *EventStore) ListByRestaurant(ctx context.Context, restaurantID int64) (
 		WHERE restaurant_id = $1
 		ORDER BY date, start_time`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	db := readDB(ctx, s.db, s.replica)
+	rows, err := db.QueryContext(ctx, query, restaurantID)
 	if err != nil {
 		return nil, err
 	}
@@ -141,7 +158,11 @@ func (s *EventStore) ListByRestaurant(ctx context.Context, restaurantID int64) (
 		return nil, err
 	}
 
-	if err := s.fillEmployees(ctx, events); err != nil {
+	if err := s.fillEmployees(ctx, db, events); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillShifts(ctx, db, events); err != nil {
 		return nil, err
 	}
 
@@ -160,7 +181,8 @@ func (s *EventStore) ListByRestaurantAndDateRange(ctx context.Context, restauran
 		  AND date <= $3
 		ORDER BY date, start_time`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID, startDate, endDate)
+	db := readDB(ctx, s.db, s.replica)
+	rows, err := db.QueryContext(ctx, query, restaurantID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -191,7 +213,11 @@ func (s *EventStore) ListByRestaurantAndDateRange(ctx context.Context, restauran
 		return nil, err
 	}
 
-	if err := s.fillEmployees(ctx, events); err != nil {
+	if err := s.fillEmployees(ctx, db, events); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillShifts(ctx, db, events); err != nil {
 		return nil, err
 	}
 
@@ -199,7 +225,7 @@ func (s *EventStore) ListByRestaurantAndDateRange(ctx context.Context, restauran
 }
 
 // fillEmployees populates the Employees field for a slice of events
-func (s *EventStore) fillEmployees(ctx context.Context, events []*Event) error {
+func (s *EventStore) fillEmployees(ctx context.Context, db *sql.DB, events []*Event) error {
 	if len(events) == 0 {
 		return nil
 	}
@@ -220,7 +246,7 @@ func (s *EventStore) fillEmployees(ctx context.Context, events []*Event) error {
 		WHERE ee.event_id = ANY($1::bigint[])
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids))
+	rows, err := db.QueryContext(ctx, query, ids)
 	if err != nil {
 		return err
 	}
@@ -250,6 +276,98 @@ func (s *EventStore) fillEmployees(ctx context.Context, events []*Event) error {
 	return rows.Err()
 }
 
+// fillShifts populates the linked shifts of a slice of events and computes
+// their coverage; it expects the employees to be filled already
+func (s *EventStore) fillShifts(ctx context.Context, db *sql.DB, events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	eventMap := make(map[int64]*Event)
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		event.Shifts = []*ScheduledShift{}
+		eventMap[event.ID] = event
+		ids[i] = event.ID
+	}
+
+	query := `
+		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
+		       shift_date, start_time, end_time, notes,
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
+		FROM scheduled_shifts
+		WHERE event_id = ANY($1::bigint[])
+		ORDER BY shift_date, start_time`
+
+	rows, err := db.QueryContext(ctx, query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift ScheduledShift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.ScheduleID,
+			&shift.RestaurantID,
+			&shift.ShiftTemplateID,
+			&shift.RoleID,
+			&shift.EmployeeID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.Notes,
+			&shift.EmployeeName,
+			&shift.RoleName,
+			&shift.RoleColor,
+			&shift.Training,
+			&shift.TrainerShiftID,
+			&shift.EventID,
+			&shift.CreatedAt,
+			&shift.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if event, ok := eventMap[*shift.EventID]; ok {
+			event.Shifts = append(event.Shifts, &shift)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		event.Coverage = event.coverage()
+	}
+
+	return nil
+}
+
+func (e *Event) coverage() EventCoverage {
+	staff := make(map[int64]bool, len(e.Employees))
+	for _, emp := range e.Employees {
+		staff[emp.ID] = true
+	}
+
+	c := EventCoverage{Shifts: len(e.Shifts)}
+	for _, shift := range e.Shifts {
+		if shift.EmployeeID == nil {
+			c.OpenShifts++
+			continue
+		}
+		c.StaffedShifts++
+		staff[*shift.EmployeeID] = true
+	}
+	c.Staff = len(staff)
+
+	return c
+}
+
 func (s *EventStore) Update(ctx context.Context, event *Event) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
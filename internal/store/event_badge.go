@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Ways a badge can be scanned at an event
+const (
+	CheckInQR  = "qr"
+	CheckInNFC = "nfc"
+)
+
+// EventBadge is an employee's badge for an event; the token itself is only
+// shown when the badge is issued
+type EventBadge struct {
+	ID           int64     `json:"id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	EventID      int64     `json:"event_id"`
+	EmployeeID   int64     `json:"employee_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// EventCheckIn is the first scan of an employee's badge at an event
+type EventCheckIn struct {
+	ID           int64     `json:"id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	EventID      int64     `json:"event_id"`
+	EmployeeID   int64     `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	Method       string    `json:"method"`
+	ScannedBy    *int64    `json:"scanned_by,omitempty"`
+	CheckedInAt  time.Time `json:"checked_in_at"`
+}
+
+type EventBadgeStore struct {
+	db *sql.DB
+}
+
+// Issue stores the badge's token hash, replacing the employee's earlier badge
+// for the event so only the newest one scans
+func (s *EventBadgeStore) Issue(ctx context.Context, badge *EventBadge, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_badges (restaurant_id, event_id, employee_id, token_hash)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (event_id, employee_id)
+		DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, badge.RestaurantID, badge.EventID, badge.EmployeeID, hashToken(token)).
+		Scan(&badge.ID, &badge.CreatedAt)
+}
+
+// Authenticate returns the event's badge with this token
+func (s *EventBadgeStore) Authenticate(ctx context.Context, eventID int64, token string) (*EventBadge, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, event_id, employee_id, created_at
+		FROM event_badges
+		WHERE event_id = $1 AND token_hash = $2`
+
+	var b EventBadge
+	err := s.db.QueryRowContext(ctx, query, eventID, hashToken(token)).
+		Scan(&b.ID, &b.RestaurantID, &b.EventID, &b.EmployeeID, &b.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// RecordCheckIn checks the employee in to the event and reports whether this
+// scan was the first. A repeat scan leaves the first one's time and method in
+// checkIn.
+func (s *EventBadgeStore) RecordCheckIn(ctx context.Context, checkIn *EventCheckIn) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH inserted AS (
+			INSERT INTO event_check_ins (restaurant_id, event_id, employee_id, method, scanned_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (event_id, employee_id) DO NOTHING
+			RETURNING id, method, scanned_by, checked_in_at, true AS first
+		)
+		SELECT id, method, scanned_by, checked_in_at, first FROM inserted
+		UNION ALL
+		SELECT id, method, scanned_by, checked_in_at, false
+		FROM event_check_ins
+		WHERE event_id = $2 AND employee_id = $3 AND NOT EXISTS (SELECT 1 FROM inserted)`
+
+	var first bool
+	err := s.db.QueryRowContext(ctx, query, checkIn.RestaurantID, checkIn.EventID, checkIn.EmployeeID, checkIn.Method, checkIn.ScannedBy).
+		Scan(&checkIn.ID, &checkIn.Method, &checkIn.ScannedBy, &checkIn.CheckedInAt, &first)
+	if err != nil {
+		return false, err
+	}
+
+	return first, nil
+}
+
+// ListCheckIns returns the event's check-ins in the order they were scanned
+func (s *EventBadgeStore) ListCheckIns(ctx context.Context, eventID int64) ([]*EventCheckIn, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT c.id, c.restaurant_id, c.event_id, c.employee_id, e.full_name, c.method, c.scanned_by, c.checked_in_at
+		FROM event_check_ins c
+		JOIN employees e ON e.id = c.employee_id
+		WHERE c.event_id = $1
+		ORDER BY c.checked_in_at, c.id`
+
+	rows, err := s.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkIns := []*EventCheckIn{}
+	for rows.Next() {
+		var c EventCheckIn
+		if err := rows.Scan(&c.ID, &c.RestaurantID, &c.EventID, &c.EmployeeID, &c.EmployeeName, &c.Method, &c.ScannedBy, &c.CheckedInAt); err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, &c)
+	}
+
+	return checkIns, rows.Err()
+}
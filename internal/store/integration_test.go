@@ -0,0 +1,2657 @@
+//go:build integration
+
+package store_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/balebbae/RESA/internal/integration"
+	"github.com/balebbae/RESA/internal/store"
+	"github.com/google/uuid"
+)
+
+// testEnv is nil when docker isn't available, and the tests skip
+var testEnv *integration.Env
+
+func TestMain(m *testing.M) {
+	env, err := integration.Start(context.Background())
+	switch {
+	case errors.Is(err, integration.ErrNoDocker):
+		fmt.Fprintln(os.Stderr, "skipping store integration tests:", err)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	default:
+		testEnv = env
+	}
+
+	code := m.Run()
+	if testEnv != nil {
+		if err := testEnv.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	os.Exit(code)
+}
+
+func newStorage(t *testing.T) store.Storage {
+	t.Helper()
+	if testEnv == nil {
+		t.Skip("docker is not available")
+	}
+	return store.NewStorage(testEnv.DB)
+}
+
+var emailSeq atomic.Int64
+
+// newOwner creates an active user with a unique email, since every test
+// shares the one database
+func newOwner(t *testing.T, s store.Storage) *store.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &store.User{
+		Email:     fmt.Sprintf("owner-%d-%d@example.com", time.Now().UnixNano(), emailSeq.Add(1)),
+		FirstName: "Test",
+		LastName:  "Owner",
+	}
+	if err := user.Password.Set("password"); err != nil {
+		t.Fatal(err)
+	}
+
+	token := fmt.Sprintf("token-%s", user.Email)
+	hash := sha256.Sum256([]byte(token))
+	if err := s.Users.CreateAndInvite(ctx, user, hex.EncodeToString(hash[:]), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Users.Activate(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func newRestaurant(t *testing.T, s store.Storage, owner *store.User) *store.Restaurant {
+	t.Helper()
+
+	restaurant := &store.Restaurant{UserID: owner.ID, Name: "Integration Bistro", Address: "1 Test St"}
+	if err := s.Restaurants.Create(context.Background(), restaurant); err != nil {
+		t.Fatal(err)
+	}
+	return restaurant
+}
+
+func TestUserInvitationLifecycle(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+
+	got, err := s.Users.GetByEmail(ctx, owner.Email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsActive || got.ID != owner.ID {
+		t.Errorf("GetByEmail = %+v, want active user %d", got, owner.ID)
+	}
+	if err := got.Password.Compare("password"); err != nil {
+		t.Errorf("stored password does not match: %v", err)
+	}
+
+	duplicate := &store.User{Email: owner.Email, FirstName: "Other", LastName: "User"}
+	duplicate.Password.Set("password")
+	if err := s.Users.CreateAndInvite(ctx, duplicate, "unused-token", time.Hour); !errors.Is(err, store.ErrDuplicateEmail) {
+		t.Errorf("duplicate email: got %v, want %v", err, store.ErrDuplicateEmail)
+	}
+}
+
+func TestRestaurantsAreScopedToTheirOwner(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner, other := newOwner(t, s), newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+
+	got, err := s.Restaurants.GetByID(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != owner.ID || got.Name != restaurant.Name {
+		t.Errorf("GetByID = %+v", got)
+	}
+
+	owned, err := s.Restaurants.ListByUser(ctx, owner.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(owned) != 1 || owned[0].ID != restaurant.ID {
+		t.Errorf("owner's restaurants = %v, want only %d", owned, restaurant.ID)
+	}
+
+	count, err := s.Restaurants.CountByUser(ctx, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("other user's restaurant count = %d, want 0", count)
+	}
+
+	if _, err := s.Restaurants.GetByID(ctx, restaurant.ID+1_000_000); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("missing restaurant: got %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func TestRolesAndEmployees(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Roles.Create(ctx, &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#000000"}); !errors.Is(err, store.ErrDuplicateRole) {
+		t.Errorf("duplicate role: got %v, want %v", err, store.ErrDuplicateRole)
+	}
+
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Employees.AssignRoles(ctx, employee.ID, []int64{role.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	roles, err := s.Employees.GetRoles(ctx, employee.ID, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].ID != role.ID {
+		t.Errorf("employee roles = %v, want [%d]", roles, role.ID)
+	}
+	roleIDs, err := s.Employees.RoleIDsByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := roleIDs[employee.ID]; len(roleIDs) != 1 || len(ids) != 1 || ids[0] != role.ID {
+		t.Errorf("role IDs by employee = %v, want %d: [%d]", roleIDs, employee.ID, role.ID)
+	}
+
+	employees, err := s.Roles.GetEmployees(ctx, role.ID, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(employees) != 1 || employees[0].ID != employee.ID {
+		t.Errorf("role employees = %v, want [%d]", employees, employee.ID)
+	}
+}
+
+func TestScheduleShiftsFromTemplates(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Cook", Color: "#FF0000"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Casey Cook", Email: "casey@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Employees.AssignRoles(ctx, employee.ID, []int64{role.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	template := &store.ShiftTemplate{
+		RestaurantID: restaurant.ID,
+		Name:         "Monday lunch",
+		DayOfWeek:    int(time.Monday),
+		StartTime:    "11:00",
+		EndTime:      "15:00",
+		RoleIDs:      []int64{role.ID},
+	}
+	if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+
+	templates, err := s.ShiftTemplates.ListByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || len(templates[0].RoleIDs) != 1 || templates[0].RoleIDs[0] != role.ID {
+		t.Fatalf("templates = %+v, want one with role %d", templates, role.ID)
+	}
+
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	byDate, err := s.Schedules.GetByDate(ctx, restaurant.ID, "2026-06-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byDate.ID != schedule.ID {
+		t.Errorf("GetByDate = schedule %d, want %d", byDate.ID, schedule.ID)
+	}
+
+	ids, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{{
+		ScheduleID:      schedule.ID,
+		RestaurantID:    restaurant.ID,
+		ShiftTemplateID: &template.ID,
+		RoleID:          role.ID,
+		ShiftDate:       time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		StartTime:       template.StartTime,
+		EndTime:         template.EndTime,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("BatchCreate returned %d IDs, want 1", len(ids))
+	}
+
+	if err := s.ScheduledShifts.AssignEmployee(ctx, ids[0], store.ShiftAssignment{EmployeeID: &employee.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	shifts, err := s.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shifts) != 1 {
+		t.Fatalf("ListBySchedule returned %d shifts, want 1", len(shifts))
+	}
+	// role and employee names are denormalized by triggers
+	shift := shifts[0]
+	if shift.RoleName != "Cook" || shift.EmployeeName == nil || *shift.EmployeeName != "Casey Cook" {
+		t.Errorf("shift = %+v, want Cook assigned to Casey Cook", shift)
+	}
+
+	if err := s.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	published, err := s.Schedules.GetByID(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.PublishedAt == nil {
+		t.Error("schedule has no publish time after Publish")
+	}
+}
+
+func TestSeasonalShiftTemplates(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	from := store.DateOnly("2025-06-01")
+	template := &store.ShiftTemplate{RestaurantID: restaurant.ID, Name: "Patio", DayOfWeek: 5, StartTime: "17:00", EndTime: "22:00", EffectiveFrom: &from}
+	if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.ShiftTemplates.GetByID(ctx, template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.EffectiveFrom == nil || *loaded.EffectiveFrom != from || loaded.EffectiveUntil != nil {
+		t.Errorf("window = %v to %v, want from %s", loaded.EffectiveFrom, loaded.EffectiveUntil, from)
+	}
+
+	until := store.DateOnly("2025-05-01")
+	loaded.EffectiveUntil = &until
+	if err := s.ShiftTemplates.Update(ctx, loaded); err == nil {
+		t.Error("saved a window that ends before it starts")
+	}
+}
+
+func TestMergeShiftTemplates(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	var roleIDs []int64
+	for _, name := range []string{"Server", "Host"} {
+		role := &store.Role{RestaurantID: restaurant.ID, Name: name, Color: "#6B7280"}
+		if err := s.Roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	keep := &store.ShiftTemplate{RestaurantID: restaurant.ID, Name: "Lunch", DayOfWeek: 1, StartTime: "11:00", EndTime: "15:00", RoleIDs: roleIDs[:1]}
+	duplicate := &store.ShiftTemplate{RestaurantID: restaurant.ID, Name: "Lunch (copy)", DayOfWeek: 1, StartTime: "11:00", EndTime: "15:00", RoleIDs: roleIDs}
+	for _, template := range []*store.ShiftTemplate{keep, duplicate} {
+		if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{{
+		ScheduleID:      schedule.ID,
+		RestaurantID:    restaurant.ID,
+		ShiftTemplateID: &duplicate.ID,
+		RoleID:          roleIDs[1],
+		ShiftDate:       time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		StartTime:       duplicate.StartTime,
+		EndTime:         duplicate.EndTime,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repointed, err := s.ShiftTemplates.Merge(ctx, keep, []int64{duplicate.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repointed != 1 || len(keep.RoleIDs) != 2 {
+		t.Errorf("repointed %d shifts, roles %v; want 1 shift and both roles", repointed, keep.RoleIDs)
+	}
+
+	if _, err := s.ShiftTemplates.GetByID(ctx, duplicate.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("merged template: got %v, want %v", err, store.ErrNotFound)
+	}
+	shift, err := s.ScheduledShifts.GetByID(ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shift.ShiftTemplateID == nil || *shift.ShiftTemplateID != keep.ID {
+		t.Errorf("shift template = %v, want %d", shift.ShiftTemplateID, keep.ID)
+	}
+
+	if _, err := s.ShiftTemplates.Merge(ctx, keep, []int64{duplicate.ID}); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("merging a deleted template: got %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func TestListShiftsByRestaurantAndRange(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-05-31", EndDate: "2026-06-08"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// Late shifts on the days either side of the week and on its first and last days
+	var shifts []*store.ScheduledShift
+	for _, day := range []int{31, 32, 38, 39} {
+		shifts = append(shifts, &store.ScheduledShift{
+			ScheduleID:   schedule.ID,
+			RestaurantID: restaurant.ID,
+			RoleID:       role.ID,
+			ShiftDate:    time.Date(2026, 5, day, 0, 0, 0, 0, time.UTC),
+			StartTime:    "20:00",
+			EndTime:      "23:59",
+		})
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+
+	week, err := s.ScheduledShifts.ListByRestaurantAndRange(ctx, restaurant.ID, "2026-06-01", "2026-06-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(week) != 2 || week[0].ShiftDate.Format("2006-01-02") != "2026-06-01" || week[1].ShiftDate.Format("2006-01-02") != "2026-06-07" {
+		t.Errorf("week = %+v, want the shifts of June 1 and 7 only", week)
+	}
+}
+
+func TestListCoverage(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-21"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two Mondays, one 09:30-11:00 shift staffed and one open each, plus a Monday outside the range
+	var shifts []*store.ScheduledShift
+	for _, day := range []int{1, 8, 15} {
+		for _, employeeID := range []*int64{&employee.ID, nil} {
+			shifts = append(shifts, &store.ScheduledShift{
+				ScheduleID:   schedule.ID,
+				RestaurantID: restaurant.ID,
+				RoleID:       role.ID,
+				EmployeeID:   employeeID,
+				ShiftDate:    time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC),
+				StartTime:    "09:30",
+				EndTime:      "11:00",
+			})
+		}
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+
+	cells, err := s.ScheduledShifts.ListCoverage(ctx, restaurant.ID, "2026-06-01", "2026-06-14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 2 {
+		t.Fatalf("cells = %+v, want the 9 and 10 o'clock hours of Monday", cells)
+	}
+	for i, hour := range []int{9, 10} {
+		cell := cells[i]
+		if cell.RoleID != role.ID || cell.DayOfWeek != 1 || cell.Hour != hour || cell.Shifts != 4 || cell.Staffed != 2 {
+			t.Errorf("cell %d = %+v, want Monday %d:00 with 4 shifts, 2 staffed", i, cell, hour)
+		}
+	}
+}
+
+func TestShiftLeadMembers(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	lead := newOwner(t, s)
+
+	var roles []*store.Role
+	for _, name := range []string{"Server", "Cook"} {
+		role := &store.Role{RestaurantID: restaurant.ID, Name: name, Color: "#6B7280"}
+		if err := s.Roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+		roles = append(roles, role)
+	}
+
+	member := &store.Member{RestaurantID: restaurant.ID, UserID: lead.ID, Permission: store.PermissionShiftLead, RoleIDs: []int64{roles[0].ID}}
+	if err := s.Members.Create(ctx, member); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Members.Create(ctx, member); !errors.Is(err, store.ErrDuplicateMember) {
+		t.Errorf("adding the member twice: err = %v, want ErrDuplicateMember", err)
+	}
+
+	member.RoleIDs = []int64{roles[1].ID}
+	if err := s.Members.UpdateRoles(ctx, member); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Members.Get(ctx, restaurant.ID, lead.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.RoleIDs) != 1 || got.RoleIDs[0] != roles[1].ID || got.Email != lead.Email {
+		t.Errorf("member = %+v, want %s leading only role %d", got, lead.Email, roles[1].ID)
+	}
+
+	memberships, err := s.Members.ListByUser(ctx, lead.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memberships) != 1 || memberships[0].RestaurantID != restaurant.ID {
+		t.Errorf("memberships = %+v, want restaurant %d", memberships, restaurant.ID)
+	}
+
+	if err := s.Members.Delete(ctx, restaurant.ID, lead.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Members.Get(ctx, restaurant.ID, lead.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("removed member: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestArchiveSchedules(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+
+	var schedules []*store.Schedule
+	for _, week := range [][2]store.DateOnly{{"2025-01-06", "2025-01-12"}, {"2025-03-03", "2025-03-09"}, {"2025-06-02", "2025-06-08"}} {
+		schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: week[0], EndDate: week[1]}
+		if err := s.Schedules.Create(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	archived, err := s.Schedules.ArchiveEndedBefore(ctx, restaurant.ID, "2025-02-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived != 1 {
+		t.Errorf("archived %d schedules before February, want 1", archived)
+	}
+
+	// Three months' retention on July 1 archives the March week too
+	months := 3
+	restaurant.ScheduleRetentionMonths = &months
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Schedules.ArchiveExpired(ctx, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := s.Schedules.ListByRestaurant(ctx, restaurant.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != schedules[2].ID {
+		t.Errorf("listed = %+v, want only the June schedule", listed)
+	}
+
+	hidden, err := s.Schedules.ListByRestaurant(ctx, restaurant.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hidden) != 2 || hidden[0].ArchivedAt == nil {
+		t.Errorf("archived = %+v, want the January and March schedules", hidden)
+	}
+}
+
+func TestScheduleNotes(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2025-03-10", EndDate: "2025-03-16", Note: "Spring break week"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.Schedules.GetByID(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Note != "Spring break week" {
+		t.Errorf("schedule note = %q", loaded.Note)
+	}
+
+	for _, note := range []*store.ScheduleDayNote{
+		{ScheduleID: schedule.ID, Date: "2025-03-12", Note: "Delivery at 7"},
+		{ScheduleID: schedule.ID, Date: "2025-03-11", Note: "Inspection"},
+		{ScheduleID: schedule.ID, Date: "2025-03-11", Note: "Health inspection"},
+	} {
+		if err := s.ScheduleNotes.Upsert(ctx, note); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes, err := s.ScheduleNotes.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].Date != "2025-03-11" || notes[0].Note != "Health inspection" {
+		t.Errorf("day notes = %+v, want the replaced Tuesday note first", notes)
+	}
+
+	if err := s.ScheduleNotes.Delete(ctx, schedule.ID, "2025-03-12"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ScheduleNotes.Delete(ctx, schedule.ID, "2025-03-12"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleting a missing note: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestScheduleShareLinks(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2025-03-10", EndDate: "2025-03-16"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	link := &store.ShareLink{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, ExpiresAt: now.Add(time.Hour)}
+	if err := s.ShareLinks.Create(ctx, link, "share-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	viewed, err := s.ShareLinks.Authenticate(ctx, "share-token", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if viewed.ID != link.ID || viewed.LastViewedAt == nil {
+		t.Errorf("viewed link = %+v", viewed)
+	}
+	if _, err := s.ShareLinks.Authenticate(ctx, "share-token", now.Add(2*time.Hour)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired link: err = %v, want ErrNotFound", err)
+	}
+
+	if _, err := s.ShareLinks.SetExpiry(ctx, schedule.ID, link.ID, now.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ShareLinks.Authenticate(ctx, "share-token", now.Add(2*time.Hour)); err != nil {
+		t.Errorf("extended link: err = %v", err)
+	}
+
+	if err := s.ShareLinks.Revoke(ctx, schedule.ID, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ShareLinks.Authenticate(ctx, "share-token", now); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked link: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.ShareLinks.SetExpiry(ctx, schedule.ID, link.ID, now.Add(time.Hour)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("extending a revoked link: err = %v, want ErrNotFound", err)
+	}
+
+	links, err := s.ShareLinks.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 1 || links[0].RevokedAt == nil {
+		t.Errorf("links = %+v, want the revoked link", links)
+	}
+}
+
+func TestUserSessions(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner, other := newOwner(t, s), newOwner(t, s)
+
+	phone := &store.Session{UserID: owner.ID, UserAgent: "Phone", IPAddress: "10.0.0.1", ExpiresAt: time.Now().Add(time.Hour)}
+	laptop := &store.Session{UserID: owner.ID, UserAgent: "Laptop", IPAddress: "10.0.0.2", ExpiresAt: time.Now().Add(time.Hour)}
+	for _, session := range []*store.Session{phone, laptop} {
+		if err := s.Sessions.Create(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Sessions.Authenticate(ctx, phone.ID, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Sessions.Authenticate(ctx, phone.ID, other.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("another user's session: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Sessions.Revoke(ctx, other.ID, phone.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoking another user's session: err = %v, want ErrNotFound", err)
+	}
+
+	if err := s.Sessions.Revoke(ctx, owner.ID, phone.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Sessions.Authenticate(ctx, phone.ID, owner.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked session: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Sessions.Extend(ctx, phone.ID, owner.ID, time.Now().Add(2*time.Hour)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("extending a revoked session: err = %v, want ErrNotFound", err)
+	}
+
+	active, err := s.Sessions.ListActive(ctx, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].ID != laptop.ID || active[0].UserAgent != "Laptop" {
+		t.Errorf("active sessions = %+v, want only the laptop", active)
+	}
+
+	sessionID := laptop.ID
+	for _, eventType := range []string{store.SecurityEventSignIn, store.SecurityEventSessionRevoked} {
+		if err := s.SecurityEvents.Record(ctx, &store.SecurityEvent{UserID: owner.ID, Type: eventType, SessionID: &sessionID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := s.SecurityEvents.ListByUser(ctx, owner.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Type != store.SecurityEventSessionRevoked {
+		t.Errorf("events = %+v, want newest first", events)
+	}
+}
+
+func TestAssignmentPriority(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	if restaurant.AssignmentPolicy != store.AssignmentManualOnly {
+		t.Errorf("new restaurant policy = %q, want manual_only", restaurant.AssignmentPolicy)
+	}
+
+	restaurant.AssignmentPolicy = store.AssignmentRotateFairly
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.Restaurants.GetByID(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.AssignmentPolicy != store.AssignmentRotateFairly {
+		t.Errorf("policy = %q, want rotate_fairly", loaded.AssignmentPolicy)
+	}
+
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Maria Lopez", Email: "maria@example.com", Seniority: 4}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	employee.Seniority = 7
+	if err := s.Employees.Update(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := s.Employees.GetByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Seniority != 7 {
+		t.Errorf("seniority = %d, want 7", reloaded.Seniority)
+	}
+}
+
+func TestCloneRestaurant(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	source := newRestaurant(t, s, owner)
+	source.ScheduleLockHours = 12
+	source.AssignmentPolicy = store.AssignmentSeniorityFirst
+	if err := s.Restaurants.Update(ctx, source); err != nil {
+		t.Fatal(err)
+	}
+
+	role := &store.Role{RestaurantID: source.ID, Name: "Cook", Color: "#FF0000"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	cert := &store.Certification{RestaurantID: source.ID, Name: "Food handler"}
+	if err := s.Certifications.Create(ctx, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Certifications.SetRequiredByRole(ctx, role.ID, []int64{cert.ID}); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: source.ID, FullName: "Casey Cook", Email: "casey@example.com", Seniority: 3}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Employees.AssignRoles(ctx, employee.ID, []int64{role.ID}); err != nil {
+		t.Fatal(err)
+	}
+	template := &store.ShiftTemplate{RestaurantID: source.ID, Name: "Monday lunch", DayOfWeek: int(time.Monday), StartTime: "11:00", EndTime: "15:00", RoleIDs: []int64{role.ID}}
+	if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+
+	clone := &store.RestaurantClone{
+		SourceID:         source.ID,
+		Restaurant:       &store.Restaurant{UserID: owner.ID, Name: "Integration Bistro Uptown", Address: "9 Hill St"},
+		IncludeEmployees: true,
+	}
+	if err := s.Restaurants.Clone(ctx, clone); err != nil {
+		t.Fatal(err)
+	}
+
+	copied := clone.Restaurant
+	if copied.ID == source.ID || copied.ScheduleLockHours != 12 || copied.AssignmentPolicy != store.AssignmentSeniorityFirst {
+		t.Errorf("clone = %+v, want a new restaurant with the source's settings", copied)
+	}
+
+	newRole := clone.IDMap.Roles[role.ID]
+	templates, err := s.ShiftTemplates.ListByRestaurant(ctx, copied.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].ID != clone.IDMap.ShiftTemplates[template.ID] || len(templates[0].RoleIDs) != 1 || templates[0].RoleIDs[0] != newRole {
+		t.Errorf("templates = %+v, want one on cloned role %d", templates, newRole)
+	}
+
+	required, err := s.Certifications.ListRequiredByRole(ctx, newRole)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(required) != 1 || required[0].ID != clone.IDMap.Certifications[cert.ID] {
+		t.Errorf("required certifications = %+v", required)
+	}
+
+	roles, err := s.Employees.GetRoles(ctx, clone.IDMap.Employees[employee.ID], copied.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].ID != newRole {
+		t.Errorf("cloned employee roles = %+v", roles)
+	}
+
+	missing := &store.RestaurantClone{SourceID: -1, Restaurant: &store.Restaurant{UserID: owner.ID, Name: "Nowhere", Address: "0 Void St"}}
+	if err := s.Restaurants.Clone(ctx, missing); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("cloning a missing restaurant: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAssigningAnotherRestaurantsEmployeeIsForbidden(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant, otherRestaurant := newRestaurant(t, s, owner), newRestaurant(t, s, owner)
+
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Host", Color: "#00FF00"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	outsider := &store.Employee{RestaurantID: otherRestaurant.ID, FullName: "Olly Outsider", Email: "olly@example.com"}
+	if err := s.Employees.Create(ctx, outsider); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-08", EndDate: "2026-06-14"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	shift := &store.ScheduledShift{
+		ScheduleID:   schedule.ID,
+		RestaurantID: restaurant.ID,
+		RoleID:       role.ID,
+		ShiftDate:    time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC),
+		StartTime:    "09:00",
+		EndTime:      "13:00",
+	}
+	if err := s.ScheduledShifts.Create(ctx, shift); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.ScheduledShifts.AssignEmployee(ctx, shift.ID, store.ShiftAssignment{EmployeeID: &outsider.ID})
+	if !errors.Is(err, store.ErrForbidden) {
+		t.Errorf("assigning another restaurant's employee: got %v, want %v", err, store.ErrForbidden)
+	}
+}
+
+func TestClaimMilestoneDigests(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	birthday, hired := store.DateOnly("1990-10-20"), store.DateOnly("2021-10-16")
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Ana Anniversary", Email: "ana@example.com", Birthday: &birthday, HireDate: &hired}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := s.Employees.GetByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Birthday == nil || *saved.Birthday != birthday || saved.HireDate == nil || *saved.HireDate != hired {
+		t.Errorf("saved dates = %v, %v", saved.Birthday, saved.HireDate)
+	}
+
+	restaurant.StaffMilestoneDigest = true
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed := func(today store.DateOnly) bool {
+		t.Helper()
+		restaurants, err := s.Restaurants.ClaimMilestoneDigests(ctx, today)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range restaurants {
+			if r.ID == restaurant.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !claimed("2026-10-16") {
+		t.Error("an opted-in restaurant's first digest wasn't claimed")
+	}
+	if claimed("2026-10-20") {
+		t.Error("the digest was claimed again within the week")
+	}
+	if !claimed("2026-10-23") {
+		t.Error("the next week's digest wasn't claimed")
+	}
+}
+
+func TestSyncChanges(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+
+	full, err := s.Sync.Changes(ctx, restaurant.ID, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(full.Roles) != 1 || full.Roles[0].ID != role.ID || len(full.Deleted) != 0 {
+		t.Fatalf("full sync = %+v", full)
+	}
+
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Roles.Delete(ctx, role.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := s.Sync.Changes(ctx, restaurant.ID, &full.ReadAt, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Roles) != 0 || len(changes.Employees) != 1 || changes.Employees[0].ID != employee.ID {
+		t.Errorf("changes = roles %+v, employees %+v, want only the new employee", changes.Roles, changes.Employees)
+	}
+	if len(changes.Deleted) != 1 || changes.Deleted[0].Entity != store.SyncEntityRole || changes.Deleted[0].ID != role.ID {
+		t.Errorf("deleted = %+v, want the role", changes.Deleted)
+	}
+}
+
+func TestDocumentAcknowledgments(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	doc := &store.Document{RestaurantID: restaurant.ID, Name: "Handbook.pdf", ObjectKey: "docs/handbook.pdf", ContentType: "application/pdf", SizeBytes: 1}
+	if err := s.Documents.Create(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	ack := &store.DocumentAcknowledgment{DocumentID: doc.ID, RestaurantID: restaurant.ID, EmployeeID: employee.ID, ExpiresAt: now.Add(time.Hour)}
+	if err := s.DocumentAcks.Request(ctx, ack, "first"); err != nil {
+		t.Fatal(err)
+	}
+	// asking again replaces the link
+	if err := s.DocumentAcks.Request(ctx, ack, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DocumentAcks.Authenticate(ctx, "first", now); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("replaced token: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.DocumentAcks.Authenticate(ctx, "second", now.Add(2*time.Hour)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired token: err = %v, want ErrNotFound", err)
+	}
+
+	pending, err := s.DocumentAcks.Authenticate(ctx, "second", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.DocumentName != "Handbook.pdf" || pending.AcknowledgedAt != nil {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	if err := s.DocumentAcks.Acknowledge(ctx, pending.DocumentAcknowledgment, "Sam Server", "127.0.0.1", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DocumentAcks.Acknowledge(ctx, pending.DocumentAcknowledgment, "Someone Else", "127.0.0.1", "test"); !errors.Is(err, store.ErrAlreadyAcknowledged) {
+		t.Errorf("second signature: err = %v, want ErrAlreadyAcknowledged", err)
+	}
+	if err := s.DocumentAcks.Request(ctx, ack, "third"); !errors.Is(err, store.ErrAlreadyAcknowledged) {
+		t.Errorf("request after signing: err = %v, want ErrAlreadyAcknowledged", err)
+	}
+	// a signed link keeps working after it expires
+	if _, err := s.DocumentAcks.Authenticate(ctx, "second", now.Add(2*time.Hour)); err != nil {
+		t.Errorf("signed token after expiry: err = %v", err)
+	}
+
+	acks, err := s.DocumentAcks.ListByDocument(ctx, doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acks) != 1 || acks[0].SignedName == nil || *acks[0].SignedName != "Sam Server" || acks[0].EmployeeName != "Sam Server" {
+		t.Errorf("acks = %+v", acks)
+	}
+}
+
+func TestEmployeeEmailVerification(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := s.Employees.RequestEmailVerification(ctx, employee, "first", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// asking again replaces the link
+	if err := s.Employees.RequestEmailVerification(ctx, employee, "second", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Employees.VerifyEmail(ctx, "first", now); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("replaced token: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Employees.VerifyEmail(ctx, "second", now.Add(2*time.Hour)); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired token: err = %v, want ErrNotFound", err)
+	}
+
+	verified, err := s.Employees.VerifyEmail(ctx, "second", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.EmployeeID != employee.ID || verified.Email != "sam@example.com" {
+		t.Errorf("verified = %+v", verified)
+	}
+	if _, err := s.Employees.VerifyEmail(ctx, "second", now); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("used token: err = %v, want ErrNotFound", err)
+	}
+
+	got, err := s.Employees.GetByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.EmailVerifiedAt == nil {
+		t.Fatal("email_verified_at not set")
+	}
+
+	// a link sent before the email changed doesn't verify the new address
+	if err := s.Employees.RequestEmailVerification(ctx, got, "third", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	got.Email = "sam@new.example.com"
+	if err := s.Employees.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.EmailVerifiedAt != nil {
+		t.Errorf("email_verified_at = %v after the email changed, want cleared", got.EmailVerifiedAt)
+	}
+	if _, err := s.Employees.VerifyEmail(ctx, "third", now); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("token for the old address: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDeleteRoleInUse(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	newRole := func(name string) *store.Role {
+		role := &store.Role{RestaurantID: restaurant.ID, Name: name, Color: "#6B7280"}
+		if err := s.Roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+		return role
+	}
+	server, host, bar := newRole("Server"), newRole("Host"), newRole("Bar")
+
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Employees.AssignRoles(ctx, employee.ID, []int64{server.ID}); err != nil {
+		t.Fatal(err)
+	}
+	template := &store.ShiftTemplate{RestaurantID: restaurant.ID, Name: "Lunch", DayOfWeek: 1, StartTime: "11:00", EndTime: "15:00", RoleIDs: []int64{server.ID, host.ID, bar.ID}}
+	if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	shifts := []*store.ScheduledShift{
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: server.ID, EmployeeID: &employee.ID, ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "11:00", EndTime: "15:00"},
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: bar.ID, ShiftDate: time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "23:00"},
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := s.Roles.Usage(ctx, server.ID, "2026-06-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage.ShiftTemplates) != 1 || len(usage.Employees) != 1 || usage.PastShifts != 1 || usage.FutureShifts != 0 {
+		t.Fatalf("usage = %+v", usage)
+	}
+
+	// the template already lists host, so it isn't listed twice
+	if err := s.Roles.Reassign(ctx, server.ID, host.ID); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := s.ScheduledShifts.GetByID(ctx, shifts[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.RoleID != host.ID || moved.RoleName != "Host" {
+		t.Errorf("shift = role %d %q, want Host", moved.RoleID, moved.RoleName)
+	}
+	roles, err := s.Employees.GetRoles(ctx, employee.ID, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].ID != host.ID {
+		t.Errorf("employee roles = %+v, want Host", roles)
+	}
+	got, err := s.ShiftTemplates.GetByID(ctx, template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.RoleIDs) != 2 || got.RoleIDs[0] != host.ID || got.RoleIDs[1] != bar.ID {
+		t.Errorf("template roles = %v, want [%d %d]", got.RoleIDs, host.ID, bar.ID)
+	}
+
+	if err := s.Roles.ForceDelete(ctx, bar.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ScheduledShifts.GetByID(ctx, shifts[1].ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("bar shift after force delete: err = %v, want ErrNotFound", err)
+	}
+	got, err = s.ShiftTemplates.GetByID(ctx, template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.RoleIDs) != 1 || got.RoleIDs[0] != host.ID {
+		t.Errorf("template roles = %v, want only Host", got.RoleIDs)
+	}
+}
+
+func TestEmployeeNotes(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{"Asked for fewer closing shifts", "Covered three shifts this week"} {
+		note := &store.EmployeeNote{RestaurantID: restaurant.ID, EmployeeID: employee.ID, AuthorUserID: &owner.ID, Body: body}
+		if err := s.EmployeeNotes.Create(ctx, note); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes, err := s.EmployeeNotes.ListByEmployee(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 2 || notes[0].Body != "Covered three shifts this week" || notes[0].AuthorName == "" {
+		t.Fatalf("notes = %+v, want both, newest first, with the author", notes)
+	}
+
+	if _, err := s.Employees.Erase(ctx, employee.ID); err != nil {
+		t.Fatal(err)
+	}
+	notes, err = s.EmployeeNotes.ListByEmployee(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("notes after erasure = %+v, want none", notes)
+	}
+}
+
+func TestPublishedScheduleVersions(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	addShift := func(day int) {
+		shift := &store.ScheduledShift{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, EmployeeID: &employee.ID, ShiftDate: time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC), StartTime: "11:00", EndTime: "15:00"}
+		if _, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{shift}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.ScheduleSnapshots.LatestPublished(ctx, schedule.ID, nil); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("unpublished schedule: err = %v, want ErrNotFound", err)
+	}
+
+	addShift(1)
+	if err := s.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	betweenPublishes := time.Now()
+	addShift(2)
+	if err := s.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	// a change notification after publishing isn't a published version
+	addShift(3)
+	if err := s.ScheduleSnapshots.Create(ctx, schedule.ID, store.SnapshotNotified); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := s.ScheduleSnapshots.LatestPublished(ctx, schedule.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Version != 2 || len(latest.Shifts) != 2 {
+		t.Errorf("latest published = version %d with %d shifts, want version 2 with 2", latest.Version, len(latest.Shifts))
+	}
+	first, err := s.ScheduleSnapshots.LatestPublished(ctx, schedule.ID, &betweenPublishes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Version != 1 || len(first.Shifts) != 1 {
+		t.Errorf("first published = version %d with %d shifts, want version 1 with 1", first.Version, len(first.Shifts))
+	}
+
+	if _, err := testEnv.DB.ExecContext(ctx, `UPDATE schedule_snapshots SET shifts = '[]' WHERE schedule_id = $1`, schedule.ID); err == nil {
+		t.Error("a snapshot was changed")
+	}
+
+	// erasure is the one change allowed, removing the employee's name
+	if _, err := s.Employees.Erase(ctx, employee.ID); err != nil {
+		t.Fatal(err)
+	}
+	latest, err = s.ScheduleSnapshots.LatestPublished(ctx, schedule.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shift := range latest.Shifts {
+		if shift.EmployeeName != nil && *shift.EmployeeName == "Sam Server" {
+			t.Error("erased employee's name is still in the published version")
+		}
+	}
+}
+
+func TestRestaurantLocation(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	latitude, longitude := 40.7128, -74.006
+	restaurant.Latitude, restaurant.Longitude = &latitude, &longitude
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Restaurants.GetByID(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Latitude == nil || *got.Latitude != latitude || got.Longitude == nil || *got.Longitude != longitude {
+		t.Errorf("location = %v, %v, want %v, %v", got.Latitude, got.Longitude, latitude, longitude)
+	}
+
+	// half a location is refused
+	got.Longitude = nil
+	if err := s.Restaurants.Update(ctx, got); err == nil {
+		t.Error("saved a latitude without a longitude")
+	}
+}
+
+func TestSalesDemand(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-03-02", EndDate: "2026-03-08"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	shifts := []*store.ScheduledShift{
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "11:00", EndTime: "15:00"},
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "22:30"},
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+
+	cents := func(c int64) *int64 { return &c }
+	hour := func(h int) *int { return &h }
+	record := func(date store.DateOnly, h *int, sales int64) *store.SalesRecord {
+		return &store.SalesRecord{RestaurantID: restaurant.ID, Date: date, Hour: h, SalesCents: cents(sales), Source: store.SalesSourceAPI}
+	}
+	if err := s.Sales.Import(ctx, []*store.SalesRecord{
+		record("2026-03-02", nil, 50000),
+		record("2026-03-02", hour(12), 9000), // the day's total wins over its hours
+		record("2026-03-03", hour(12), 10000),
+		record("2026-03-03", hour(19), 20000),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// importing the same slot again replaces it
+	if err := s.Sales.Import(ctx, []*store.SalesRecord{record("2026-03-03", hour(19), 25000)}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := s.Sales.List(ctx, restaurant.ID, "2026-03-01", "2026-03-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[0].Hour != nil {
+		t.Fatalf("records = %+v, want four with the day's total first", records)
+	}
+
+	days, err := s.Sales.DemandByDay(ctx, restaurant.ID, "2026-03-02", "2026-03-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("days = %+v, want one per date", days)
+	}
+	if d := days[0]; d.SalesCents == nil || *d.SalesCents != 50000 || d.ScheduledHours != 9.5 {
+		t.Errorf("monday = %+v, want the day's total and 9.5 hours", d)
+	}
+	if d := days[1]; d.SalesCents == nil || *d.SalesCents != 35000 || d.ScheduledHours != 0 {
+		t.Errorf("tuesday = %+v, want the hours added up", d)
+	}
+	if d := days[2]; d.SalesCents != nil {
+		t.Errorf("wednesday = %+v, want no sales", d)
+	}
+
+	if err := s.Sales.SetWebhookToken(ctx, restaurant.ID, "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Sales.SetWebhookToken(ctx, restaurant.ID, "second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sales.AuthenticateWebhook(ctx, "first"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("replaced token: err = %v, want ErrNotFound", err)
+	}
+	if id, err := s.Sales.AuthenticateWebhook(ctx, "second"); err != nil || id != restaurant.ID {
+		t.Errorf("token authenticated restaurant %d, err = %v", id, err)
+	}
+	if err := s.Sales.DeleteWebhookToken(ctx, restaurant.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Sales.AuthenticateWebhook(ctx, "second"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked token: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	var employees []*store.Employee
+	for _, name := range []string{"Ana", "Ben", "Cy"} {
+		e := &store.Employee{RestaurantID: restaurant.ID, FullName: name, Email: name + "@example.com"}
+		if err := s.Employees.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		employees = append(employees, e)
+	}
+	if err := s.Employees.AssignRoles(ctx, employees[0].ID, []int64{role.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	message := &store.Message{
+		RestaurantID: restaurant.ID,
+		Subject:      "Hi {{.FirstName}}",
+		Body:         "Staff meeting",
+		Channels:     []string{store.MessageChannelEmail},
+		RoleIDs:      []int64{role.ID},
+		EmployeeIDs:  []int64{employees[2].ID},
+		Status:       store.MessageScheduled,
+		SendAt:       now.Add(-time.Minute),
+	}
+	if err := s.Messages.Create(ctx, message); err != nil {
+		t.Fatal(err)
+	}
+
+	recipients, err := s.Messages.Recipients(ctx, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recipients) != 2 || recipients[0].FullName != "Ana" || recipients[1].FullName != "Cy" {
+		t.Errorf("recipients = %+v, want the server and the listed employee", recipients)
+	}
+
+	everyone := &store.Message{RestaurantID: restaurant.ID, RoleIDs: []int64{}, EmployeeIDs: []int64{}}
+	if all, err := s.Messages.Recipients(ctx, everyone); err != nil || len(all) != 3 {
+		t.Errorf("unfiltered recipients = %d (%v), want everyone", len(all), err)
+	}
+
+	claimed, err := s.Messages.ClaimDue(ctx, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != message.ID || claimed[0].Status != store.MessageSending {
+		t.Fatalf("claimed = %+v, want the due message", claimed)
+	}
+	if again, _ := s.Messages.ClaimDue(ctx, now); len(again) != 0 {
+		t.Errorf("claimed again = %+v, want nothing", again)
+	}
+	if err := s.Messages.Cancel(ctx, message.ID); !errors.Is(err, store.ErrMessageNotScheduled) {
+		t.Errorf("cancel while sending = %v, want ErrMessageNotScheduled", err)
+	}
+
+	claimed[0].Recipients, claimed[0].Failed = 2, 1
+	if err := s.Messages.Complete(ctx, claimed[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.Messages.ListByRestaurant(ctx, restaurant.ID, 0, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Status != store.MessageSent || history[0].Recipients != 2 || history[0].SentAt == nil {
+		t.Errorf("history = %+v, want the sent message", history)
+	}
+}
+
+func TestShiftFeedback(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "Sam.Feedback@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{{
+		ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, EmployeeID: &employee.ID,
+		ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "23:00",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feedback := &store.ShiftFeedback{ShiftID: ids[0], Rating: 2, Comment: "we were understaffed"}
+	ended := time.Date(2026, 6, 1, 23, 30, 0, 0, time.UTC)
+	if err := s.ShiftFeedback.Submit(ctx, feedback, "sam.feedback@example.com", ended); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("unpublished: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ShiftFeedback.Submit(ctx, feedback, "someone@example.com", ended); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("someone else: err = %v, want ErrNotFound", err)
+	}
+	if err := s.ShiftFeedback.Submit(ctx, feedback, "sam.feedback@example.com", time.Date(2026, 6, 1, 22, 0, 0, 0, time.UTC)); !errors.Is(err, store.ErrShiftNotEnded) {
+		t.Fatalf("during the shift: err = %v, want ErrShiftNotEnded", err)
+	}
+	if err := s.ShiftFeedback.Submit(ctx, feedback, "sam.feedback@example.com", ended); err != nil {
+		t.Fatal(err)
+	}
+	feedback.Rating = 3
+	if err := s.ShiftFeedback.Submit(ctx, feedback, "sam.feedback@example.com", ended); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := s.ShiftFeedback.ListByRestaurant(ctx, restaurant.ID, "2026-06-01", "2026-06-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Rating != 3 || entries[0].RoleName != "Server" || entries[0].EmployeeName != "Sam Server" {
+		t.Errorf("entries = %+v, want the resubmitted feedback", entries)
+	}
+
+	shifts, err := s.ShiftAcknowledgments.ListUpcomingForEmail(ctx, "sam.feedback@example.com", "2026-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shifts) != 1 || shifts[0].FeedbackRating == nil || *shifts[0].FeedbackRating != 3 {
+		t.Errorf("portal shifts = %+v, want the rating shown", shifts)
+	}
+}
+
+func TestListShiftPages(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#6B7280"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	// two shifts a day, created newest day first so ID order differs from date order
+	var shifts []*store.ScheduledShift
+	for day := 3; day >= 1; day-- {
+		for _, start := range []store.TimeOfDay{"09:00", "17:00"} {
+			shifts = append(shifts, &store.ScheduledShift{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC), StartTime: start, EndTime: "23:00"})
+		}
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+
+	var seen []*store.ScheduledShift
+	var after *store.ShiftCursor
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("paging did not stop")
+		}
+		page, err := s.ScheduledShifts.ListPage(ctx, restaurant.ID, store.MinShiftDate, store.MaxShiftDate, after, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen = append(seen, page...)
+		if len(page) < 4 {
+			break
+		}
+		last := page[len(page)-1]
+		after = &store.ShiftCursor{ShiftDate: store.DateOnly(last.ShiftDate.Format("2006-01-02")), ID: last.ID}
+	}
+	if len(seen) != 6 {
+		t.Fatalf("paged through %d shifts, want 6", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		prev, cur := seen[i-1], seen[i]
+		if cur.ShiftDate.Before(prev.ShiftDate) || cur.ShiftDate.Equal(prev.ShiftDate) && cur.ID <= prev.ID {
+			t.Errorf("shift %d comes after %d, want (shift_date, id) order", cur.ID, prev.ID)
+		}
+	}
+
+	first, err := s.Sync.Changes(ctx, restaurant.ID, nil, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Shifts) != 4 || !first.MoreShifts || first.Shifts[3].ID != seen[3].ID {
+		t.Errorf("limited full sync = %d shifts (more %v), want the first 4 and more to come", len(first.Shifts), first.MoreShifts)
+	}
+}
+
+func TestComplianceRules(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	defaults, err := s.Compliance.Get(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults.Enabled() || defaults.UpdatedAt != nil || defaults.Jurisdiction != "custom" {
+		t.Errorf("rules = %+v, want the custom template before any are saved", defaults)
+	}
+
+	rules, _ := store.ComplianceTemplate("eu")
+	rules.RestaurantID = restaurant.ID
+	if err := s.Compliance.Replace(ctx, &rules); err != nil {
+		t.Fatal(err)
+	}
+	rules.MinorLatestEnd, rules.RestSeverity = nil, store.ComplianceWarn
+	if err := s.Compliance.Replace(ctx, &rules); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Compliance.Get(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Jurisdiction != "eu" || got.MinRestHours != 11 || got.RestSeverity != store.ComplianceWarn || got.MinorLatestEnd != nil || got.UpdatedAt == nil {
+		t.Errorf("rules = %+v, want the saved eu rules", got)
+	}
+
+	// the check constraints back up the payload validation
+	rules.MinRestHours = 30
+	if err := s.Compliance.Replace(ctx, &rules); err == nil {
+		t.Error("saved 30 hours of minimum rest")
+	}
+}
+
+func TestLeaveAccrual(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+
+	// 30 hours clocked in the week of Monday 1 June 2026, and 15 the next
+	for _, shift := range []struct{ day, hours int }{{1, 10}, {2, 10}, {3, 10}, {9, 15}} {
+		in := time.Date(2026, 6, shift.day, 8, 0, 0, 0, time.UTC)
+		for _, at := range []time.Time{in, in.Add(time.Duration(shift.hours) * time.Hour)} {
+			if _, err := s.TimeClock.ClockInOrOut(ctx, restaurant.ID, employee.ID, nil, at); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	policy := &store.LeavePolicy{
+		RestaurantID:    restaurant.ID,
+		Enabled:         true,
+		Basis:           store.LeaveBasisWorked,
+		AccrualHours:    1,
+		PerHours:        10,
+		MaxBalanceHours: 4,
+		AccrueFrom:      "2026-06-01",
+	}
+	if err := s.Leave.ReplacePolicy(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second week isn't over until the 15th
+	accrued, err := s.Leave.AccrueThrough(ctx, restaurant.ID, time.Date(2026, 6, 14, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accrued) != 1 || accrued[0].Hours != 3 || accrued[0].Date != "2026-06-01" {
+		t.Fatalf("accrued = %+v, want 3 hours for the first week", accrued)
+	}
+
+	// the cap holds the second week to 1 of its 1.5 hours, and the first isn't accrued twice
+	accrued, err = s.Leave.AccrueThrough(ctx, restaurant.ID, time.Date(2026, 6, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accrued) != 1 || accrued[0].Hours != 1 || accrued[0].Date != "2026-06-08" {
+		t.Fatalf("accrued = %+v, want 1 capped hour for the second week", accrued)
+	}
+
+	paid := &store.LeaveEntry{RestaurantID: restaurant.ID, EmployeeID: employee.ID, Kind: store.LeavePaid, Hours: -5, Date: "2026-06-20"}
+	if err := s.Leave.AddEntry(ctx, paid); !errors.Is(err, store.ErrInsufficientLeave) {
+		t.Fatalf("paying 5 hours of a 4 hour balance: err = %v, want ErrInsufficientLeave", err)
+	}
+	paid.Hours = -2.5
+	if err := s.Leave.AddEntry(ctx, paid); err != nil {
+		t.Fatal(err)
+	}
+
+	balances, err := s.Leave.ListBalances(ctx, restaurant.ID, "2026-06-08", "2026-06-30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 1 || balances[0].Accrued != 1 || balances[0].Paid != 2.5 || balances[0].Balance != 1.5 {
+		t.Errorf("balances = %+v, want 1 accrued and 2.5 paid in range, 1.5 left", balances)
+	}
+
+	entries, err := s.Leave.ListEntries(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Kind != store.LeavePaid || entries[2].BasisHours == nil || *entries[2].BasisHours != 30 {
+		t.Errorf("entries = %+v, want leave paid first and the first week's 30 basis hours last", entries)
+	}
+
+	days, err := s.Leave.ListPaidBetween(ctx, restaurant.ID, "2026-06-15", "2026-06-21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 || days[0].Date != "2026-06-20" || days[0].EmployeeID != employee.ID {
+		t.Errorf("paid leave = %+v, want the day taken on 2026-06-20", days)
+	}
+}
+
+func TestBidRounds(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	var roles []*store.Role
+	for _, name := range []string{"Server", "Cook"} {
+		role := &store.Role{RestaurantID: restaurant.ID, Name: name, Color: "#6B7280"}
+		if err := s.Roles.Create(ctx, role); err != nil {
+			t.Fatal(err)
+		}
+		roles = append(roles, role)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "Sam.Bids@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Employees.AssignRoles(ctx, employee.ID, []int64{roles[0].ID}); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	june := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	ids, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: roles[0].ID, ShiftDate: june(5), StartTime: "17:00", EndTime: "23:00"},
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: roles[0].ID, ShiftDate: june(6), StartTime: "17:00", EndTime: "23:00"},
+		{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: roles[1].ID, ShiftDate: june(6), StartTime: "10:00", EndTime: "16:00"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	round := &store.BidRound{RestaurantID: restaurant.ID, ScheduleID: schedule.ID, ClosesAt: now.Add(time.Hour), SeniorityWeight: 1}
+	for _, id := range ids {
+		round.Shifts = append(round.Shifts, &store.BidRoundShift{ShiftID: id})
+	}
+	if err := s.Bids.Create(ctx, round); err != nil {
+		t.Fatal(err)
+	}
+	again := &store.BidRound{RestaurantID: restaurant.ID, ScheduleID: schedule.ID, ClosesAt: now.Add(time.Hour), Shifts: []*store.BidRoundShift{{ShiftID: ids[0]}}}
+	if err := s.Bids.Create(ctx, again); !errors.Is(err, store.ErrBidShiftUnavailable) {
+		t.Fatalf("shift in two open rounds: err = %v, want ErrBidShiftUnavailable", err)
+	}
+
+	// the cook shift isn't biddable for a server
+	biddable, err := s.Bids.ListBiddableForEmail(ctx, "sam.bids@example.com", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(biddable) != 2 || biddable[0].ShiftID != ids[0] || biddable[0].Rank != nil {
+		t.Fatalf("biddable = %+v, want the two server shifts unranked", biddable)
+	}
+	if _, err := s.Bids.SubmitPreferences(ctx, round.ID, "sam.bids@example.com", []int64{ids[2]}, now); !errors.Is(err, store.ErrBidShiftUnavailable) {
+		t.Fatalf("bidding on the cook shift: err = %v, want ErrBidShiftUnavailable", err)
+	}
+	if _, err := s.Bids.SubmitPreferences(ctx, round.ID, "sam.bids@example.com", []int64{ids[0], ids[1]}, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Bids.SubmitPreferences(ctx, round.ID, "sam.bids@example.com", []int64{ids[1], ids[0]}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	prefs, err := s.Bids.ListPreferences(ctx, round.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prefs) != 2 || prefs[0].ShiftID != ids[1] || prefs[0].Rank != 1 {
+		t.Errorf("preferences = %+v, want the resubmitted ranking", prefs)
+	}
+
+	if err := s.Bids.ClaimAllocation(ctx, round.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Bids.ClaimAllocation(ctx, round.ID, now); !errors.Is(err, store.ErrBidRoundAllocated) {
+		t.Fatalf("second allocation: err = %v, want ErrBidRoundAllocated", err)
+	}
+	if _, err := s.Bids.SubmitPreferences(ctx, round.ID, "sam.bids@example.com", []int64{ids[0]}, now); !errors.Is(err, store.ErrBidRoundClosed) {
+		t.Fatalf("bidding after allocation: err = %v, want ErrBidRoundClosed", err)
+	}
+
+	rank := 1
+	if err := s.Bids.RecordAwards(ctx, round.ID, []*store.BidRoundShift{{ShiftID: ids[1], AwardedEmployeeID: &employee.ID, AwardedRank: &rank}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Bids.GetByID(ctx, round.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	awarded := 0
+	for _, shift := range got.Shifts {
+		if shift.AwardedEmployeeID != nil {
+			awarded++
+			if shift.ShiftID != ids[1] || *shift.AwardedRank != 1 {
+				t.Errorf("awarded %+v, want shift %d at rank 1", shift, ids[1])
+			}
+		}
+	}
+	if got.Status != store.BidRoundAllocated || len(got.Shifts) != 3 || awarded != 1 {
+		t.Errorf("round = %+v, want allocated with one of its 3 shifts awarded", got)
+	}
+
+	open, err := s.Bids.OpenShiftIDs(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 0 {
+		t.Errorf("open shift IDs = %v, want none once allocated", open)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	all := &store.WebhookEndpoint{RestaurantID: restaurant.ID, URL: "https://hr.example.com/all", Secret: "whsec_all", Events: []string{}}
+	updates := &store.WebhookEndpoint{RestaurantID: restaurant.ID, URL: "https://hr.example.com/updates", Secret: "whsec_updates", Events: []string{"employee.updated"}}
+	for _, e := range []*store.WebhookEndpoint{all, updates} {
+		if err := s.Webhooks.CreateEndpoint(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// external IDs are unique within the restaurant
+	externalID := "BHR-1001"
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Hana Hooks", Email: "hana.hooks@example.com", ExternalID: &externalID}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	twin := &store.Employee{RestaurantID: restaurant.ID, FullName: "Hana Twin", Email: "hana.twin@example.com", ExternalID: &externalID}
+	if err := s.Employees.Create(ctx, twin); !errors.Is(err, store.ErrDuplicateExternalID) {
+		t.Errorf("reusing an external_id: err = %v, want ErrDuplicateExternalID", err)
+	}
+	got, err := s.Employees.GetByID(ctx, employee.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ExternalID == nil || *got.ExternalID != externalID {
+		t.Errorf("external_id = %v, want %s", got.ExternalID, externalID)
+	}
+
+	queued, err := s.Webhooks.Enqueue(ctx, &store.WebhookEvent{ID: uuid.New().String(), RestaurantID: restaurant.ID, Type: "employee.created", Payload: []byte(`{"type":"employee.created"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queued != 1 {
+		t.Errorf("employee.created queued for %d endpoints, want only the one taking every event", queued)
+	}
+	if queued, _ = s.Webhooks.Enqueue(ctx, &store.WebhookEvent{ID: uuid.New().String(), RestaurantID: restaurant.ID, Type: "employee.updated", Payload: []byte(`{}`)}); queued != 2 {
+		t.Errorf("employee.updated queued for %d endpoints, want 2", queued)
+	}
+
+	// a claimed delivery isn't claimed again until its lease runs out
+	now := time.Now()
+	claimed, err := s.Webhooks.ClaimDue(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 3 || claimed[0].URL == "" || claimed[0].Secret == "" {
+		t.Fatalf("claimed = %+v, want all 3 deliveries with their endpoint", claimed)
+	}
+	if again, _ := s.Webhooks.ClaimDue(ctx, now, time.Minute, 10); len(again) != 0 {
+		t.Errorf("claimed %d deliveries twice", len(again))
+	}
+
+	retry := now.Add(-time.Second)
+	if err := s.Webhooks.RecordAttempt(ctx, claimed[0].ID, 0, "connection refused", &retry); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Webhooks.RecordAttempt(ctx, claimed[1].ID, 204, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Webhooks.RecordAttempt(ctx, claimed[2].ID, 500, "endpoint answered 500", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	due, err := s.Webhooks.ClaimDue(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].ID != claimed[0].ID || due[0].Attempts != 1 {
+		t.Errorf("due = %+v, want only the delivery to retry, after one attempt", due)
+	}
+
+	deliveries, err := s.Webhooks.ListDeliveries(ctx, updates.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want the one employee.updated", deliveries)
+	}
+
+	if err := s.Webhooks.DeleteEndpoint(ctx, restaurant.ID+1, all.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleting another restaurant's endpoint: err = %v, want ErrNotFound", err)
+	}
+	if err := s.Webhooks.DeleteEndpoint(ctx, restaurant.ID, all.ID); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := s.Webhooks.ListEndpoints(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 1 || endpoints[0].ID != updates.ID || endpoints[0].Secret != "" {
+		t.Errorf("endpoints = %+v, want the updates endpoint, without its secret", endpoints)
+	}
+}
+
+func TestDisplayBoards(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	board := &store.DisplayBoard{RestaurantID: restaurant.ID, Name: "Kitchen TV", CreatedBy: &owner.ID}
+	if err := s.DisplayBoards.Create(ctx, board, "board-token"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.DisplayBoards.Authenticate(ctx, "board-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != board.ID || got.LastUsedAt == nil {
+		t.Errorf("board = %+v, want board %d marked used", got, board.ID)
+	}
+
+	// only published schedules reach the board
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Cook", Color: "#FF0000"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, publish := range []bool{true, false} {
+		schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+		if err := s.Schedules.Create(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+		shift := &store.ScheduledShift{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: day, StartTime: "09:00", EndTime: "17:00"}
+		if err := s.ScheduledShifts.Create(ctx, shift); err != nil {
+			t.Fatal(err)
+		}
+		if publish {
+			if err := s.Schedules.Publish(ctx, schedule.ID, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	shifts, err := s.DisplayBoards.ListShifts(ctx, restaurant.ID, "2026-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shifts) != 1 || shifts[0].RoleName != "Cook" {
+		t.Errorf("shifts = %+v, want the one published Cook shift", shifts)
+	}
+
+	if err := s.DisplayBoards.Revoke(ctx, restaurant.ID+1, board.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoking another restaurant's board: err = %v, want ErrNotFound", err)
+	}
+	if err := s.DisplayBoards.Revoke(ctx, restaurant.ID, board.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DisplayBoards.Authenticate(ctx, "board-token"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked board: err = %v, want ErrNotFound", err)
+	}
+	boards, err := s.DisplayBoards.List(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 1 || boards[0].RevokedAt == nil {
+		t.Errorf("boards = %+v, want the revoked board", boards)
+	}
+}
+
+func TestCalendarFeeds(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Cook", Color: "#FF0000"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Cam Cook", Email: "cam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+
+	feed := &store.CalendarFeed{RestaurantID: restaurant.ID, EmployeeID: &employee.ID, Name: "Cam's shifts", CreatedBy: &owner.ID}
+	if err := s.CalendarFeeds.Create(ctx, feed, "feed-token"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.CalendarFeeds.Authenticate(ctx, "feed-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != feed.ID || got.EmployeeID == nil || *got.EmployeeID != employee.ID || got.LastUsedAt == nil {
+		t.Errorf("feed = %+v, want feed %d of employee %d marked used", got, feed.ID, employee.ID)
+	}
+
+	// only published shifts on or after the date, and the employee's in their feed
+	published := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	draft := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-08", EndDate: "2026-06-14"}
+	for _, schedule := range []*store.Schedule{published, draft} {
+		if err := s.Schedules.Create(ctx, schedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	shifts := []*store.ScheduledShift{
+		{ScheduleID: published.ID, RestaurantID: restaurant.ID, RoleID: role.ID, EmployeeID: &employee.ID, ShiftDate: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StartTime: "09:00", EndTime: "17:00"},
+		{ScheduleID: published.ID, RestaurantID: restaurant.ID, RoleID: role.ID, EmployeeID: &employee.ID, ShiftDate: time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC), StartTime: "09:00", EndTime: "17:00"},
+		{ScheduleID: published.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC), StartTime: "17:00", EndTime: "23:00"},
+		{ScheduleID: draft.ID, RestaurantID: restaurant.ID, RoleID: role.ID, EmployeeID: &employee.ID, ShiftDate: time.Date(2026, 6, 8, 0, 0, 0, 0, time.UTC), StartTime: "09:00", EndTime: "17:00"},
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, shifts); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Schedules.Publish(ctx, published.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	mine, err := s.CalendarFeeds.ListShifts(ctx, restaurant.ID, &employee.ID, "2026-06-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mine) != 1 || mine[0].ID != shifts[1].ID || mine[0].RoleName != "Cook" {
+		t.Errorf("employee shifts = %+v, want shift %d", mine, shifts[1].ID)
+	}
+	all, err := s.CalendarFeeds.ListShifts(ctx, restaurant.ID, nil, "2026-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("restaurant shifts = %d, want the 3 published ones", len(all))
+	}
+
+	if err := s.CalendarFeeds.Revoke(ctx, restaurant.ID+1, feed.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoking another restaurant's feed: err = %v, want ErrNotFound", err)
+	}
+	if err := s.CalendarFeeds.Revoke(ctx, restaurant.ID, feed.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CalendarFeeds.Authenticate(ctx, "feed-token"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked feed: err = %v, want ErrNotFound", err)
+	}
+	feeds, err := s.CalendarFeeds.List(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feeds) != 1 || feeds[0].RevokedAt == nil {
+		t.Errorf("feeds = %+v, want the revoked feed", feeds)
+	}
+}
+
+func TestPublicHolidays(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	restaurant.HolidayRegion = "US-CA"
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+
+	calendars, err := s.OperatingHours.ListHolidayCalendars(ctx, 2026)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calendar *store.HolidayCalendar
+	for _, c := range calendars {
+		if c.RestaurantID == restaurant.ID {
+			calendar = c
+		}
+	}
+	if calendar == nil || calendar.Region != "US-CA" {
+		t.Fatalf("calendars = %+v, want restaurant %d in US-CA", calendars, restaurant.ID)
+	}
+
+	// the owner's own special date wins over the holiday on it
+	own := &store.HoursException{RestaurantID: restaurant.ID, Date: "2026-07-04", Name: "Fireworks", OpenTime: "10:00", CloseTime: "23:00"}
+	if err := s.OperatingHours.CreateException(ctx, own); err != nil {
+		t.Fatal(err)
+	}
+	holidays := func(region string) []*store.HoursException {
+		return []*store.HoursException{
+			{Date: "2026-03-31", Name: region + " spring holiday"},
+			{Date: "2026-07-04", Name: "Independence Day"},
+			{Date: "2026-12-25", Name: "Christmas Day"},
+		}
+	}
+	added, err := s.OperatingHours.AddHolidays(ctx, calendar, 2026, holidays("US-CA"), "2026-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Errorf("added %d holidays, want 2", added)
+	}
+
+	// a year is only added once, so deleted holidays stay deleted
+	exceptions, err := s.OperatingHours.ListExceptions(ctx, restaurant.ID, "2026-01-01", "2026-12-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exceptions) != 3 || exceptions[0].HolidayRegion != "US-CA" || !exceptions[0].RegularHours() || exceptions[1].HolidayRegion != "" {
+		t.Fatalf("exceptions = %+v, want two holidays on regular hours around the owner's date", exceptions)
+	}
+	if err := s.OperatingHours.DeleteException(ctx, exceptions[2].ID); err != nil {
+		t.Fatal(err)
+	}
+	if added, err := s.OperatingHours.AddHolidays(ctx, calendar, 2026, holidays("US-CA"), "2026-01-01"); err != nil || added != 0 {
+		t.Errorf("adding the year again: added = %d, err = %v, want none", added, err)
+	}
+	if calendars, err := s.OperatingHours.ListHolidayCalendars(ctx, 2026); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, c := range calendars {
+			if c.RestaurantID == restaurant.ID {
+				t.Errorf("restaurant %d is still listed for 2026", restaurant.ID)
+			}
+		}
+	}
+
+	// moving region swaps the holidays still to come
+	calendar.Region = "US-TX"
+	if _, err := s.OperatingHours.AddHolidays(ctx, calendar, 2026, holidays("US-TX"), "2026-06-01"); err != nil {
+		t.Fatal(err)
+	}
+	exceptions, err = s.OperatingHours.ListExceptions(ctx, restaurant.ID, "2026-01-01", "2026-12-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, ex := range exceptions {
+		names = append(names, ex.Name)
+	}
+	if strings.Join(names, ", ") != "US-CA spring holiday, Fireworks, Christmas Day" {
+		t.Errorf("special dates = %v, want the past CA holiday, the owner's date and TX's Christmas", names)
+	}
+}
+
+func TestSavedReports(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	weekly := &store.SavedReport{
+		RestaurantID:    restaurant.ID,
+		Name:            "Weekly hours",
+		Kind:            store.ReportHoursByEmployee,
+		RoleIDs:         []int64{},
+		EmployeeIDs:     []int64{},
+		PeriodDays:      7,
+		Delivery:        store.ReportDeliveryWeekly,
+		DeliveryWeekday: int(time.Monday),
+		Recipients:      []string{"gm@example.com"},
+		CreatedBy:       &owner.ID,
+	}
+	onDemand := &store.SavedReport{RestaurantID: restaurant.ID, Name: "Coverage", Kind: store.ReportCoverageByRole, PeriodDays: 14, Delivery: store.ReportDeliveryNone}
+	for _, report := range []*store.SavedReport{weekly, onDemand} {
+		if err := s.SavedReports.Create(ctx, report); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	claimed := func(today store.DateOnly) bool {
+		t.Helper()
+		reports, err := s.SavedReports.ClaimDue(ctx, today)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range reports {
+			if r.ID == onDemand.ID {
+				t.Error("a report without delivery was claimed")
+			}
+			if r.ID == weekly.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 2026-06-08 is a Monday
+	if claimed("2026-06-09") {
+		t.Error("the weekly report was claimed on a Tuesday")
+	}
+	if !claimed("2026-06-08") {
+		t.Error("the weekly report wasn't claimed on its Monday")
+	}
+	if claimed("2026-06-08") {
+		t.Error("the weekly report was claimed twice in a day")
+	}
+
+	got, err := s.SavedReports.GetByID(ctx, weekly.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastSentOn == nil || *got.LastSentOn != "2026-06-08" || len(got.Recipients) != 1 {
+		t.Errorf("report = %+v, want sent on 2026-06-08 to its recipient", got)
+	}
+
+	got.RoleIDs = []int64{1, 2}
+	got.Delivery = store.ReportDeliveryNone
+	if err := s.SavedReports.Update(ctx, got); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SavedReports.Delete(ctx, onDemand.ID); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := s.SavedReports.ListByRestaurant(ctx, restaurant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 1 || len(reports[0].RoleIDs) != 2 || reports[0].Delivery != store.ReportDeliveryNone {
+		t.Errorf("reports = %+v, want the updated weekly report only", reports)
+	}
+}
+
+func TestEventBadges(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	employee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Server", Email: "sam@example.com"}
+	if err := s.Employees.Create(ctx, employee); err != nil {
+		t.Fatal(err)
+	}
+	event := &store.Event{RestaurantID: restaurant.ID, Title: "All-hands", Date: "2026-06-01", StartTime: "15:00", EndTime: "16:00"}
+	if err := s.Events.Create(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	// issuing again replaces the old badge
+	for _, token := range []string{"old-badge", "new-badge"} {
+		badge := &store.EventBadge{RestaurantID: restaurant.ID, EventID: event.ID, EmployeeID: employee.ID}
+		if err := s.EventBadges.Issue(ctx, badge, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.EventBadges.Authenticate(ctx, event.ID, "old-badge"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("replaced badge: err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.EventBadges.Authenticate(ctx, event.ID+1, "new-badge"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("badge at another event: err = %v, want ErrNotFound", err)
+	}
+	badge, err := s.EventBadges.Authenticate(ctx, event.ID, "new-badge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first scan counts; a repeat hands it back
+	for i, method := range []string{store.CheckInNFC, store.CheckInQR} {
+		checkIn := &store.EventCheckIn{RestaurantID: restaurant.ID, EventID: event.ID, EmployeeID: badge.EmployeeID, Method: method, ScannedBy: &owner.ID}
+		first, err := s.EventBadges.RecordCheckIn(ctx, checkIn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first != (i == 0) || checkIn.Method != store.CheckInNFC {
+			t.Errorf("scan %d: first = %v, check-in %+v; want only the first scan to count", i, first, checkIn)
+		}
+	}
+
+	checkIns, err := s.EventBadges.ListCheckIns(ctx, event.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkIns) != 1 || checkIns[0].EmployeeName != "Sam Server" {
+		t.Errorf("check-ins = %+v, want Sam's one", checkIns)
+	}
+}
+
+func TestShiftsWithinScheduleDates(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Cook", Color: "#FF0000"}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	shift := func(day int) *store.ScheduledShift {
+		return &store.ScheduledShift{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID,
+			ShiftDate: time.Date(2026, 6, day, 0, 0, 0, 0, time.UTC), StartTime: "09:00", EndTime: "17:00"}
+	}
+
+	if err := s.ScheduledShifts.Create(ctx, shift(8)); !errors.Is(err, store.ErrShiftOutsideSchedule) {
+		t.Errorf("shift after the schedule: err = %v, want ErrShiftOutsideSchedule", err)
+	}
+	if _, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{shift(2), shift(31)}); !errors.Is(err, store.ErrShiftOutsideSchedule) {
+		t.Errorf("batch with a stray shift: err = %v, want ErrShiftOutsideSchedule", err)
+	}
+
+	monday, tuesday := shift(1), shift(2)
+	for _, sh := range []*store.ScheduledShift{monday, tuesday} {
+		if err := s.ScheduledShifts.Create(ctx, sh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	moved := *tuesday
+	moved.ShiftDate = time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC)
+	if err := s.ScheduledShifts.Update(ctx, &moved); !errors.Is(err, store.ErrShiftOutsideSchedule) {
+		t.Errorf("moving a shift out: err = %v, want ErrShiftOutsideSchedule", err)
+	}
+
+	// shrinking past monday's shift is refused, unless it's deleted with it
+	schedule.StartDate = "2026-06-02"
+	if err := s.Schedules.Update(ctx, schedule); !errors.Is(err, store.ErrShiftOutsideSchedule) {
+		t.Errorf("shrinking past a shift: err = %v, want ErrShiftOutsideSchedule", err)
+	}
+	deleted, err := s.Schedules.Shrink(ctx, schedule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0] != monday.ID {
+		t.Errorf("deleted %v, want monday's shift %d", deleted, monday.ID)
+	}
+	shifts, err := s.ScheduledShifts.ListBySchedule(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shifts) != 1 || shifts[0].ID != tuesday.ID {
+		t.Errorf("shifts = %+v, want only tuesday's", shifts)
+	}
+}
+
+func TestNotificationDigests(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	restaurant.NotificationMode, restaurant.DigestHour = store.NotifyDaily, 7
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+	shiftDay := store.NotifyShiftDay
+	daily := &store.Employee{RestaurantID: restaurant.ID, FullName: "Dana Daily", Email: "dana@example.com"}
+	worker := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sol Shifts", Email: "sol@example.com", NotificationMode: &shiftDay}
+	for _, e := range []*store.Employee{daily, worker} {
+		if err := s.Employees.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := []*store.DigestItem{
+		{RestaurantID: restaurant.ID, EmployeeID: daily.ID, Kind: store.DigestAnnouncement, Subject: "Staff meeting", Body: "Monday"},
+		{RestaurantID: restaurant.ID, EmployeeID: daily.ID, Kind: store.DigestScheduleChanges, Subject: "Schedule changes", Body: "- New: Server shift"},
+		{RestaurantID: restaurant.ID, EmployeeID: worker.ID, Kind: store.DigestAnnouncement, Subject: "Staff meeting", Body: "Monday"},
+	}
+	if err := s.NotificationDigests.Enqueue(ctx, items); err != nil {
+		t.Fatal(err)
+	}
+
+	// claimed returns this test's digests among everything due
+	claimed := func(now time.Time) map[int64]int {
+		digests, err := s.NotificationDigests.ClaimDue(ctx, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[int64]int{}
+		for _, d := range digests {
+			if d.Employee.RestaurantID == restaurant.ID {
+				got[d.Employee.ID] = len(d.Items)
+			}
+		}
+		return got
+	}
+
+	tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if got := claimed(tomorrow.Add(6 * time.Hour)); len(got) != 0 {
+		t.Errorf("before the digest hour: claimed %v, want nothing", got)
+	}
+	if got := claimed(tomorrow.Add(7 * time.Hour)); len(got) != 1 || got[daily.ID] != 2 {
+		t.Errorf("at the digest hour: claimed %v, want Dana's two items and Sol waiting for a shift", got)
+	}
+	if got := claimed(tomorrow.Add(8 * time.Hour)); len(got) != 0 {
+		t.Errorf("claimed %v again the same day, want nothing", got)
+	}
+	// a week without a shift sends what's waiting anyway
+	if got := claimed(tomorrow.AddDate(0, 0, 8).Add(7 * time.Hour)); len(got) != 1 || got[worker.ID] != 1 {
+		t.Errorf("after a week: claimed %v, want Sol's item", got)
+	}
+}
+
+func TestRetentionPolicies(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	owner := newOwner(t, s)
+	restaurant := newRestaurant(t, s, owner)
+	longGone := store.DateOnly("2022-03-31")
+	recent := store.DateOnly("2026-03-31")
+	old := &store.Employee{RestaurantID: restaurant.ID, FullName: "Olive Old", Email: "olive@example.com", TerminatedOn: &longGone}
+	left := &store.Employee{RestaurantID: restaurant.ID, FullName: "Lou Left", Email: "lou@example.com", TerminatedOn: &recent}
+	staying := &store.Employee{RestaurantID: restaurant.ID, FullName: "Sam Stays", Email: "sam@example.com"}
+	for _, e := range []*store.Employee{old, left, staying} {
+		if err := s.Employees.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := &store.RetentionPolicy{RestaurantID: restaurant.ID, Kind: store.RetentionAnonymizeTerminated, AfterMonths: 24, Enabled: true}
+	if err := s.Retention.Upsert(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+	policy.AfterMonths = 36
+	if err := s.Retention.Upsert(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+	if policies, err := s.Retention.ListByRestaurant(ctx, restaurant.ID); err != nil || len(policies) != 1 || policies[0].AfterMonths != 36 {
+		t.Fatalf("policies = %+v (%v), want the one policy kept for 36 months", policies, err)
+	}
+
+	cutoff := store.DateOnly("2024-01-01")
+	expired, err := s.Retention.TerminatedBefore(ctx, restaurant.ID, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 || expired[0].ID != old.ID {
+		t.Fatalf("expired = %+v, want Olive only", expired)
+	}
+	if _, err := s.Employees.Erase(ctx, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if expired, err := s.Retention.TerminatedBefore(ctx, restaurant.ID, cutoff); err != nil || len(expired) != 0 {
+		t.Errorf("expired after erasing = %+v (%v), want nobody", expired, err)
+	}
+
+	// one entry from long ago, one recent and one still open from long ago
+	longAgo := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{longAgo, longAgo.Add(8 * time.Hour), time.Now().Add(-time.Hour), time.Now()} {
+		if _, err := s.TimeClock.ClockInOrOut(ctx, restaurant.ID, left.ID, nil, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.TimeClock.ClockInOrOut(ctx, restaurant.ID, staying.ID, nil, longAgo); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := s.Retention.CountTimeEntriesBefore(ctx, restaurant.ID, cutoff); err != nil || n != 1 {
+		t.Errorf("counted %d (%v), want the one closed old entry", n, err)
+	}
+	if n, err := s.Retention.DeleteTimeEntriesBefore(ctx, restaurant.ID, cutoff); err != nil || n != 1 {
+		t.Errorf("deleted %d (%v), want the one closed old entry", n, err)
+	}
+
+	if err := s.Retention.Delete(ctx, restaurant.ID, store.RetentionAnonymizeTerminated); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Retention.Delete(ctx, restaurant.ID, store.RetentionAnonymizeTerminated); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleting again: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRotatingShiftTemplates(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	anchor := store.DateOnly("2026-01-05")
+	restaurant.RotationAnchor = &anchor
+	if err := s.Restaurants.Update(ctx, restaurant); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := s.Restaurants.GetByID(ctx, restaurant.ID); err != nil || loaded.RotationAnchor == nil || *loaded.RotationAnchor != anchor {
+		t.Fatalf("restaurant = %+v (%v), want anchored on %s", loaded, err, anchor)
+	}
+
+	weekB := store.WeekB
+	template := &store.ShiftTemplate{RestaurantID: restaurant.ID, Name: "Brunch B", DayOfWeek: 0, StartTime: "10:00", EndTime: "15:00", WeekParity: &weekB}
+	if err := s.ShiftTemplates.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := s.ShiftTemplates.GetByID(ctx, template.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.WeekParity == nil || *loaded.WeekParity != store.WeekB {
+		t.Errorf("week parity = %v, want b", loaded.WeekParity)
+	}
+	if loaded.ActiveOn("2026-01-11", &anchor) || !loaded.ActiveOn("2026-01-18", &anchor) {
+		t.Error("want the template off on week a's Sunday and on for week b's")
+	}
+
+	loaded.WeekParity = nil
+	if err := s.ShiftTemplates.Update(ctx, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := s.ShiftTemplates.GetByID(ctx, template.ID); err != nil || again.WeekParity != nil {
+		t.Errorf("week parity after clearing = %v (%v), want every week", again.WeekParity, err)
+	}
+}
+
+func TestRoleStaffing(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	target := 4
+	role := &store.Role{RestaurantID: restaurant.ID, Name: "Server", Color: "#000000", TargetHeadcount: &target}
+	if err := s.Roles.Create(ctx, role); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := s.Roles.GetByID(ctx, role.ID); err != nil || loaded.TargetHeadcount == nil || *loaded.TargetHeadcount != 4 {
+		t.Fatalf("role = %+v (%v), want a target of 4", loaded, err)
+	}
+
+	cert := &store.Certification{RestaurantID: restaurant.ID, Name: "Alcohol permit"}
+	if err := s.Certifications.Create(ctx, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Certifications.SetRequiredByRole(ctx, role.ID, []int64{cert.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	left := store.DateOnly("2026-01-31")
+	certified := &store.Employee{RestaurantID: restaurant.ID, FullName: "Cat Certified", Email: "cat@example.com"}
+	uncertified := &store.Employee{RestaurantID: restaurant.ID, FullName: "Uma Uncertified", Email: "uma@example.com"}
+	gone := &store.Employee{RestaurantID: restaurant.ID, FullName: "Gil Gone", Email: "gil@example.com", TerminatedOn: &left}
+	trainee := &store.Employee{RestaurantID: restaurant.ID, FullName: "Tia Trainee", Email: "tia@example.com"}
+	for _, e := range []*store.Employee{certified, uncertified, gone, trainee} {
+		if err := s.Employees.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []*store.Employee{certified, uncertified, gone} {
+		if err := s.Employees.AssignRoles(ctx, e.ID, []int64{role.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Certifications.SetForEmployee(ctx, &store.EmployeeCertification{EmployeeID: certified.ID, CertificationID: cert.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-03-02", EndDate: "2026-03-15"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+	shift := func(date time.Time) *store.ScheduledShift {
+		return &store.ScheduledShift{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RoleID: role.ID, ShiftDate: date, StartTime: "09:00", EndTime: "17:00"}
+	}
+	// open shifts in two weeks, and a training shift in the second
+	ids, err := s.ScheduledShifts.BatchCreate(ctx, []*store.ScheduledShift{
+		shift(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)),
+		shift(time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)),
+		shift(time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)),
+		shift(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ScheduledShifts.AssignEmployee(ctx, ids[3], store.ShiftAssignment{EmployeeID: &trainee.ID, Training: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	staffing, err := s.Roles.Staffing(ctx, restaurant.ID, "2026-03-20", "2026-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(staffing) != 1 {
+		t.Fatalf("staffing = %+v, want the one role", staffing)
+	}
+	got := staffing[0]
+	if got.Holders != 2 || got.Certified != 1 || got.InTraining != 1 || got.OpenWeeks != 2 {
+		t.Errorf("staffing = %+v, want 2 holders, 1 certified, 1 in training and 2 weeks with open shifts", got)
+	}
+}
+
+func TestScheduleEmailBlasts(t *testing.T) {
+	s := newStorage(t)
+	ctx := context.Background()
+
+	restaurant := newRestaurant(t, s, newOwner(t, s))
+	schedule := &store.Schedule{RestaurantID: restaurant.ID, StartDate: "2026-06-01", EndDate: "2026-06-07"}
+	if err := s.Schedules.Create(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.EmailDeliveries.LatestBlast(ctx, schedule.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("latest blast before sending: err = %v, want ErrNotFound", err)
+	}
+
+	first := &store.EmailBlast{
+		ScheduleID:      schedule.ID,
+		RestaurantID:    restaurant.ID,
+		IncludeEvents:   true,
+		TotalRecipients: 3,
+		Successful:      2,
+		Failed:          1,
+		Failures:        []*store.EmailBlastFailure{{EmployeeID: 7, EmployeeName: "Ana Diaz", Email: "ana@example.com", Error: "timeout"}},
+	}
+	if err := s.EmailDeliveries.CreateBlast(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	retry := &store.EmailBlast{ScheduleID: schedule.ID, RestaurantID: restaurant.ID, RetryOf: &first.ID, IncludeEvents: true, TotalRecipients: 1, Successful: 1}
+	if err := s.EmailDeliveries.CreateBlast(ctx, retry); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := s.EmailDeliveries.LatestBlast(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != retry.ID || latest.RetryOf == nil || *latest.RetryOf != first.ID || len(latest.Failures) != 0 {
+		t.Errorf("latest = %+v, want the retry of %d with no failures", latest, first.ID)
+	}
+
+	blasts, err := s.EmailDeliveries.ListBlasts(ctx, schedule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blasts) != 2 || blasts[1].ID != first.ID || len(blasts[1].Failures) != 1 || blasts[1].Failures[0].Error != "timeout" {
+		t.Errorf("blasts = %+v, want the retry then the first blast with its failure", blasts)
+	}
+}
@@ -0,0 +1,488 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+)
+
+const AuditEntityLeave = "leave"
+
+var ErrInsufficientLeave = errors.New("the employee doesn't have that much paid leave")
+
+// LeaveBasis is which hours earn paid leave
+type LeaveBasis string
+
+const (
+	// LeaveBasisWorked accrues on clocked hours from the time clock
+	LeaveBasisWorked LeaveBasis = "worked"
+	// LeaveBasisScheduled accrues on assigned shifts of published schedules
+	LeaveBasisScheduled LeaveBasis = "scheduled"
+)
+
+// LeavePolicy is how a restaurant's employees accrue paid leave: AccrualHours
+// for every PerHours of their basis hours, a week at a time from AccrueFrom,
+// which is a Monday
+type LeavePolicy struct {
+	RestaurantID int64      `json:"restaurant_id"`
+	Enabled      bool       `json:"enabled"`
+	Basis        LeaveBasis `json:"basis"`
+	AccrualHours float64    `json:"accrual_hours"`
+	PerHours     float64    `json:"per_hours"`
+	// MaxBalanceHours caps the balance accruals build up to; 0 for no cap
+	MaxBalanceHours float64  `json:"max_balance_hours"`
+	AccrueFrom      DateOnly `json:"accrue_from"`
+	// UpdatedAt is nil until the restaurant saves its own policy
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// LeaveEntryKind is why an employee's leave balance changed
+type LeaveEntryKind string
+
+const (
+	LeaveAccrual    LeaveEntryKind = "accrual"
+	LeavePaid       LeaveEntryKind = "paid"
+	LeaveAdjustment LeaveEntryKind = "adjustment"
+)
+
+// LeaveEntry is one change to an employee's paid leave balance. Hours are
+// positive for accruals, negative for leave paid, and either for adjustments.
+type LeaveEntry struct {
+	ID           int64          `json:"id"`
+	RestaurantID int64          `json:"restaurant_id"`
+	EmployeeID   int64          `json:"employee_id"`
+	Kind         LeaveEntryKind `json:"kind"`
+	Hours        float64        `json:"hours"`
+	// Date is the Monday of the week accrued, or the day of leave paid
+	Date DateOnly `json:"date"`
+	// BasisHours are the hours worked or scheduled an accrual was earned on
+	BasisHours *float64  `json:"basis_hours,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	CreatedBy  *int64    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LeaveBalance is an employee's paid leave: the balance now, and what was
+// accrued, paid and adjusted between the dates asked for
+type LeaveBalance struct {
+	EmployeeID   int64   `json:"employee_id"`
+	EmployeeName string  `json:"employee_name"`
+	Accrued      float64 `json:"accrued"`
+	Paid         float64 `json:"paid"`
+	Adjusted     float64 `json:"adjusted"`
+	Balance      float64 `json:"balance"`
+}
+
+type LeaveStore struct {
+	db *sql.DB
+}
+
+// GetPolicy returns the restaurant's policy, or a disabled one accruing an hour
+// for every 30 worked if it hasn't saved any
+func (s *LeaveStore) GetPolicy(ctx context.Context, restaurantID int64) (*LeavePolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT restaurant_id, enabled, basis, accrual_hours, per_hours, max_balance_hours, accrue_from, updated_at
+		FROM leave_policies
+		WHERE restaurant_id = $1`
+
+	policy := &LeavePolicy{}
+	err := s.db.QueryRowContext(ctx, query, restaurantID).Scan(
+		&policy.RestaurantID,
+		&policy.Enabled,
+		&policy.Basis,
+		&policy.AccrualHours,
+		&policy.PerHours,
+		&policy.MaxBalanceHours,
+		&policy.AccrueFrom,
+		&policy.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &LeavePolicy{
+				RestaurantID: restaurantID,
+				Basis:        LeaveBasisWorked,
+				AccrualHours: 1,
+				PerHours:     30,
+			}, nil
+		}
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReplacePolicy saves the restaurant's policy over any it had
+func (s *LeaveStore) ReplacePolicy(ctx context.Context, policy *LeavePolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO leave_policies (restaurant_id, enabled, basis, accrual_hours, per_hours, max_balance_hours, accrue_from)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (restaurant_id) DO UPDATE
+		SET enabled = EXCLUDED.enabled, basis = EXCLUDED.basis, accrual_hours = EXCLUDED.accrual_hours,
+		    per_hours = EXCLUDED.per_hours, max_balance_hours = EXCLUDED.max_balance_hours,
+		    accrue_from = EXCLUDED.accrue_from, updated_at = NOW()
+		RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		policy.RestaurantID,
+		policy.Enabled,
+		policy.Basis,
+		policy.AccrualHours,
+		policy.PerHours,
+		policy.MaxBalanceHours,
+		policy.AccrueFrom,
+	).Scan(&updatedAt)
+	if err != nil {
+		return err
+	}
+
+	policy.UpdatedAt = &updatedAt
+	return nil
+}
+
+// ListAccruingRestaurants returns the restaurants with an enabled policy
+func (s *LeaveStore) ListAccruingRestaurants(ctx context.Context) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT restaurant_id FROM leave_policies WHERE enabled ORDER BY restaurant_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AccrueThrough accrues every week of the restaurant's policy that ended by
+// until and returns the new accruals. It carries on from the last week accrued,
+// which it checks again for employees who had no hours then, so running it
+// repeatedly accrues each employee's week once. A disabled policy accrues nothing.
+func (s *LeaveStore) AccrueThrough(ctx context.Context, restaurantID int64, until time.Time) ([]*LeaveEntry, error) {
+	policy, err := s.GetPolicy(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	accrued := []*LeaveEntry{}
+	if !policy.Enabled {
+		return accrued, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+	defer cancel()
+
+	start, err := time.Parse("2006-01-02", string(policy.AccrueFrom))
+	if err != nil {
+		return nil, err
+	}
+
+	err = withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var last sql.NullTime
+		err := tx.QueryRowContext(ctx, `
+			SELECT MAX(entry_date) FROM leave_entries
+			WHERE restaurant_id = $1 AND kind = 'accrual'`, restaurantID).Scan(&last)
+		if err != nil {
+			return err
+		}
+		if last.Valid && last.Time.After(start) {
+			start = last.Time
+		}
+
+		balances, err := leaveBalances(ctx, tx, restaurantID)
+		if err != nil {
+			return err
+		}
+
+		for week := leaveWeek(start); !week.AddDate(0, 0, 7).After(until); week = week.AddDate(0, 0, 7) {
+			hours, err := leaveBasisHours(ctx, tx, policy, week)
+			if err != nil {
+				return err
+			}
+
+			for employeeID, basis := range hours {
+				entry := &LeaveEntry{
+					RestaurantID: restaurantID,
+					EmployeeID:   employeeID,
+					Kind:         LeaveAccrual,
+					Hours:        policy.accrual(basis, balances[employeeID]),
+					Date:         DateOnly(week.Format("2006-01-02")),
+					BasisHours:   &basis,
+				}
+
+				err := tx.QueryRowContext(ctx, `
+					INSERT INTO leave_entries (restaurant_id, employee_id, kind, hours, entry_date, basis_hours)
+					VALUES ($1, $2, 'accrual', $3, $4, $5)
+					ON CONFLICT (employee_id, entry_date) WHERE kind = 'accrual' DO NOTHING
+					RETURNING id, created_at`,
+					entry.RestaurantID, entry.EmployeeID, entry.Hours, entry.Date, basis,
+				).Scan(&entry.ID, &entry.CreatedAt)
+				if errors.Is(err, sql.ErrNoRows) {
+					// accrued by an earlier run
+					continue
+				} else if err != nil {
+					return err
+				}
+
+				balances[employeeID] += entry.Hours
+				accrued = append(accrued, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return accrued, nil
+}
+
+// accrual is what basis hours earn with the employee's balance at balance,
+// rounded to the hundredth and held to the cap
+func (p *LeavePolicy) accrual(basis, balance float64) float64 {
+	hours := math.Round(basis*p.AccrualHours/p.PerHours*100) / 100
+	if p.MaxBalanceHours > 0 {
+		hours = math.Max(0, math.Min(hours, p.MaxBalanceHours-balance))
+	}
+	return hours
+}
+
+// leaveWeek is the Monday of t's week
+func leaveWeek(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// leaveBasisHours sums each employee's basis hours in the week from Monday
+func leaveBasisHours(ctx context.Context, tx *sql.Tx, policy *LeavePolicy, monday time.Time) (map[int64]float64, error) {
+	query := `
+		SELECT employee_id, SUM(EXTRACT(EPOCH FROM clock_out_at - clock_in_at)) / 3600
+		FROM time_entries
+		WHERE restaurant_id = $1
+		  AND clock_out_at IS NOT NULL
+		  AND clock_in_at >= $2
+		  AND clock_in_at < $3
+		GROUP BY employee_id`
+	if policy.Basis == LeaveBasisScheduled {
+		query = `
+			SELECT ss.employee_id, SUM(EXTRACT(EPOCH FROM ss.end_time - ss.start_time)) / 3600
+			FROM scheduled_shifts ss
+			JOIN schedules s ON s.id = ss.schedule_id
+			WHERE s.restaurant_id = $1
+			  AND s.published_at IS NOT NULL
+			  AND ss.employee_id IS NOT NULL
+			  AND ss.shift_date >= $2
+			  AND ss.shift_date < $3
+			GROUP BY ss.employee_id`
+	}
+
+	rows, err := tx.QueryContext(ctx, query, policy.RestaurantID, monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := map[int64]float64{}
+	for rows.Next() {
+		var employeeID int64
+		var total float64
+		if err := rows.Scan(&employeeID, &total); err != nil {
+			return nil, err
+		}
+		hours[employeeID] = math.Round(total*100) / 100
+	}
+	return hours, rows.Err()
+}
+
+// leaveBalances returns the balance of every employee of the restaurant with leave entries
+func leaveBalances(ctx context.Context, tx *sql.Tx, restaurantID int64) (map[int64]float64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT employee_id, SUM(hours)::float8
+		FROM leave_entries
+		WHERE restaurant_id = $1
+		GROUP BY employee_id`, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := map[int64]float64{}
+	for rows.Next() {
+		var employeeID int64
+		var balance float64
+		if err := rows.Scan(&employeeID, &balance); err != nil {
+			return nil, err
+		}
+		balances[employeeID] = balance
+	}
+	return balances, rows.Err()
+}
+
+// AddEntry records leave paid or an adjustment. Paid leave, given as a
+// negative number of hours, can't take the balance below zero:
+// ErrInsufficientLeave. ErrNotFound means the employee isn't the restaurant's.
+func (s *LeaveStore) AddEntry(ctx context.Context, entry *LeaveEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		// the employee's row serializes changes to their balance
+		var balance float64
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE((SELECT SUM(hours) FROM leave_entries WHERE employee_id = e.id), 0)::float8
+			FROM employees e
+			WHERE e.id = $1 AND e.restaurant_id = $2
+			FOR UPDATE`, entry.EmployeeID, entry.RestaurantID).Scan(&balance)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if entry.Kind == LeavePaid && balance+entry.Hours < 0 {
+			return ErrInsufficientLeave
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO leave_entries (restaurant_id, employee_id, kind, hours, entry_date, note, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`,
+			entry.RestaurantID, entry.EmployeeID, entry.Kind, entry.Hours, entry.Date, entry.Note, entry.CreatedBy,
+		).Scan(&entry.ID, &entry.CreatedAt)
+	})
+}
+
+// ListEntries returns the employee's leave entries, newest first
+func (s *LeaveStore) ListEntries(ctx context.Context, employeeID int64) ([]*LeaveEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, employee_id, kind, hours, entry_date, basis_hours, note, created_by, created_at
+		FROM leave_entries
+		WHERE employee_id = $1
+		ORDER BY entry_date DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*LeaveEntry{}
+	for rows.Next() {
+		var entry LeaveEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.RestaurantID,
+			&entry.EmployeeID,
+			&entry.Kind,
+			&entry.Hours,
+			&entry.Date,
+			&entry.BasisHours,
+			&entry.Note,
+			&entry.CreatedBy,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListPaidBetween returns the paid leave the restaurant's employees take from
+// from to to, by date
+func (s *LeaveStore) ListPaidBetween(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*LeaveEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, employee_id, kind, hours, entry_date, basis_hours, note, created_by, created_at
+		FROM leave_entries
+		WHERE restaurant_id = $1 AND kind = 'paid' AND entry_date BETWEEN $2 AND $3
+		ORDER BY entry_date, employee_id, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*LeaveEntry{}
+	for rows.Next() {
+		var entry LeaveEntry
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.RestaurantID,
+			&entry.EmployeeID,
+			&entry.Kind,
+			&entry.Hours,
+			&entry.Date,
+			&entry.BasisHours,
+			&entry.Note,
+			&entry.CreatedBy,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// ListBalances returns the leave balance of each of the restaurant's employees,
+// with the hours accrued, paid and adjusted from from to to, in name order
+func (s *LeaveStore) ListBalances(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*LeaveBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT e.id, e.full_name,
+		       COALESCE(SUM(l.hours) FILTER (WHERE l.kind = 'accrual' AND l.entry_date BETWEEN $2 AND $3), 0)::float8,
+		       COALESCE(-SUM(l.hours) FILTER (WHERE l.kind = 'paid' AND l.entry_date BETWEEN $2 AND $3), 0)::float8,
+		       COALESCE(SUM(l.hours) FILTER (WHERE l.kind = 'adjustment' AND l.entry_date BETWEEN $2 AND $3), 0)::float8,
+		       COALESCE(SUM(l.hours), 0)::float8
+		FROM employees e
+		LEFT JOIN leave_entries l ON l.employee_id = e.id
+		WHERE e.restaurant_id = $1
+		GROUP BY e.id, e.full_name
+		ORDER BY e.full_name, e.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []*LeaveBalance{}
+	for rows.Next() {
+		var b LeaveBalance
+		if err := rows.Scan(&b.EmployeeID, &b.EmployeeName, &b.Accrued, &b.Paid, &b.Adjusted, &b.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, &b)
+	}
+
+	return balances, rows.Err()
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PermissionShiftLead lets a member see and edit the shifts of their roles only
+const PermissionShiftLead = "shift_lead"
+
+var (
+	ErrDuplicateMember = errors.New("the user is already a member of this restaurant")
+)
+
+// Member is a user other than the owner with access to part of a restaurant
+type Member struct {
+	RestaurantID int64     `json:"restaurant_id"`
+	UserID       int64     `json:"user_id"`
+	Email        string    `json:"email"`
+	FirstName    string    `json:"first_name"`
+	LastName     string    `json:"last_name"`
+	Permission   string    `json:"permission"`
+	RoleIDs      []int64   `json:"role_ids"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Membership is a restaurant the user is a member of, as they see it
+type Membership struct {
+	RestaurantID   int64   `json:"restaurant_id"`
+	RestaurantName string  `json:"restaurant_name"`
+	Permission     string  `json:"permission"`
+	RoleIDs        []int64 `json:"role_ids"`
+}
+
+type MemberStore struct {
+	db *sql.DB
+}
+
+// Create adds the member with their roles
+func (s *MemberStore) Create(ctx context.Context, member *Member) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO restaurant_members (restaurant_id, user_id, permission)
+			VALUES ($1, $2, $3)
+			RETURNING created_at, updated_at`,
+			member.RestaurantID, member.UserID, member.Permission,
+		).Scan(&member.CreatedAt, &member.UpdatedAt)
+		if err != nil {
+			if isUniqueViolation(err, "restaurant_members_pkey") {
+				return ErrDuplicateMember
+			}
+			return err
+		}
+
+		return setMemberRoles(ctx, tx, member.RestaurantID, member.UserID, member.RoleIDs)
+	})
+}
+
+// UpdateRoles replaces the member's roles
+func (s *MemberStore) UpdateRoles(ctx context.Context, member *Member) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, `
+			UPDATE restaurant_members
+			SET updated_at = NOW()
+			WHERE restaurant_id = $1 AND user_id = $2
+			RETURNING updated_at`,
+			member.RestaurantID, member.UserID,
+		).Scan(&member.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM restaurant_member_roles WHERE restaurant_id = $1 AND user_id = $2`, member.RestaurantID, member.UserID); err != nil {
+			return err
+		}
+
+		return setMemberRoles(ctx, tx, member.RestaurantID, member.UserID, member.RoleIDs)
+	})
+}
+
+func setMemberRoles(ctx context.Context, tx *sql.Tx, restaurantID, userID int64, roleIDs []int64) error {
+	for _, roleID := range roleIDs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO restaurant_member_roles (restaurant_id, user_id, role_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, restaurantID, userID, roleID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the user's membership of the restaurant, or ErrNotFound
+func (s *MemberStore) Get(ctx context.Context, restaurantID, userID int64) (*Member, error) {
+	members, err := s.list(ctx, `WHERE m.restaurant_id = $1 AND m.user_id = $2`, restaurantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, ErrNotFound
+	}
+	return members[0], nil
+}
+
+func (s *MemberStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Member, error) {
+	return s.list(ctx, `WHERE m.restaurant_id = $1`, restaurantID)
+}
+
+func (s *MemberStore) list(ctx context.Context, where string, args ...any) ([]*Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT m.restaurant_id, m.user_id, u.email, u.first_name, u.last_name,
+		       m.permission, m.created_at, m.updated_at,
+		       COALESCE(array_agg(mr.role_id ORDER BY mr.role_id) FILTER (WHERE mr.role_id IS NOT NULL), '{}')
+		FROM restaurant_members m
+		JOIN users u ON u.id = m.user_id
+		LEFT JOIN restaurant_member_roles mr ON mr.restaurant_id = m.restaurant_id AND mr.user_id = m.user_id
+		` + where + `
+		GROUP BY m.restaurant_id, m.user_id, u.email, u.first_name, u.last_name
+		ORDER BY u.email`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*Member{}
+	for rows.Next() {
+		var m Member
+		err := rows.Scan(
+			&m.RestaurantID,
+			&m.UserID,
+			&m.Email,
+			&m.FirstName,
+			&m.LastName,
+			&m.Permission,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+			pgArray(&m.RoleIDs),
+		)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, &m)
+	}
+
+	return members, rows.Err()
+}
+
+// ListByUser returns the restaurants the user is a member of, archived ones left out
+func (s *MemberStore) ListByUser(ctx context.Context, userID int64) ([]*Membership, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT m.restaurant_id, r.name, m.permission,
+		       COALESCE(array_agg(mr.role_id ORDER BY mr.role_id) FILTER (WHERE mr.role_id IS NOT NULL), '{}')
+		FROM restaurant_members m
+		JOIN restaurants r ON r.id = m.restaurant_id
+		LEFT JOIN restaurant_member_roles mr ON mr.restaurant_id = m.restaurant_id AND mr.user_id = m.user_id
+		WHERE m.user_id = $1 AND r.archived_at IS NULL
+		GROUP BY m.restaurant_id, r.name, m.permission
+		ORDER BY r.name`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []*Membership{}
+	for rows.Next() {
+		var m Membership
+		if err := rows.Scan(&m.RestaurantID, &m.RestaurantName, &m.Permission, pgArray(&m.RoleIDs)); err != nil {
+			return nil, err
+		}
+		memberships = append(memberships, &m)
+	}
+
+	return memberships, rows.Err()
+}
+
+func (s *MemberStore) Delete(ctx context.Context, restaurantID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM restaurant_members WHERE restaurant_id = $1 AND user_id = $2`, restaurantID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrMessageNotScheduled = errors.New("the message has already been sent or canceled")
+
+// Message statuses: scheduled until send_at, sending while a worker delivers it
+const (
+	MessageScheduled = "scheduled"
+	MessageSending   = "sending"
+	MessageSent      = "sent"
+	MessageCanceled  = "canceled"
+)
+
+// Channels a message can go out on
+const (
+	MessageChannelEmail = "email"
+	MessageChannelInApp = "in_app"
+)
+
+// Message is an announcement from a restaurant to its staff: everyone, or the
+// employees with one of RoleIDs plus those in EmployeeIDs
+type Message struct {
+	ID           int64    `json:"id"`
+	RestaurantID int64    `json:"restaurant_id"`
+	CreatedBy    *int64   `json:"created_by,omitempty"`
+	Subject      string   `json:"subject"`
+	Body         string   `json:"body"`
+	Channels     []string `json:"channels"`
+	// Critical emails the message straight away even to employees who get digests
+	Critical    bool       `json:"critical"`
+	RoleIDs     []int64    `json:"role_ids"`
+	EmployeeIDs []int64    `json:"employee_ids"`
+	Status      string     `json:"status"`
+	SendAt      time.Time  `json:"send_at"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	// Recipients counts the employees the message went to, Failed the emails to them that couldn't be sent
+	Recipients int       `json:"recipients"`
+	Failed     int       `json:"failed"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HasChannel reports whether the message goes out on the channel
+func (m *Message) HasChannel(channel string) bool {
+	for _, c := range m.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+type MessageStore struct {
+	db *sql.DB
+}
+
+const messageColumns = `id, restaurant_id, created_by, subject, body, channels, critical, role_ids, employee_ids,
+	status, send_at, sent_at, recipients, failed, created_at`
+
+func scanMessage(row interface{ Scan(...any) error }) (*Message, error) {
+	var m Message
+	err := row.Scan(
+		&m.ID,
+		&m.RestaurantID,
+		&m.CreatedBy,
+		&m.Subject,
+		&m.Body,
+		pgArray(&m.Channels),
+		&m.Critical,
+		pgArray(&m.RoleIDs),
+		pgArray(&m.EmployeeIDs),
+		&m.Status,
+		&m.SendAt,
+		&m.SentAt,
+		&m.Recipients,
+		&m.Failed,
+		&m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if m.RoleIDs == nil {
+		m.RoleIDs = []int64{}
+	}
+	if m.EmployeeIDs == nil {
+		m.EmployeeIDs = []int64{}
+	}
+	return &m, nil
+}
+
+// Create records the message with message.Status set to MessageScheduled, or to
+// MessageSending for one delivered straight away
+func (s *MessageStore) Create(ctx context.Context, message *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO messages (restaurant_id, created_by, subject, body, channels, role_ids, employee_ids, status, send_at, critical)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		message.RestaurantID,
+		message.CreatedBy,
+		message.Subject,
+		message.Body,
+		message.Channels,
+		message.RoleIDs,
+		message.EmployeeIDs,
+		message.Status,
+		message.SendAt,
+		message.Critical,
+	).Scan(&message.ID, &message.CreatedAt)
+}
+
+func (s *MessageStore) GetByID(ctx context.Context, id int64) (*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	message, err := scanMessage(s.db.QueryRowContext(ctx, `SELECT `+messageColumns+` FROM messages WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// ListByRestaurant pages through the restaurant's messages newest first, continuing
+// before beforeID when it isn't 0
+func (s *MessageStore) ListByRestaurant(ctx context.Context, restaurantID, beforeID int64, limit int) ([]*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE restaurant_id = $1 AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC
+		LIMIT $3`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// Cancel stops a scheduled message from going out. It returns ErrMessageNotScheduled
+// once the message is being sent or has been, or was canceled already.
+func (s *MessageStore) Cancel(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `UPDATE messages SET status = 'canceled' WHERE id = $1 AND status = 'scheduled'`, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrMessageNotScheduled
+	}
+	return nil
+}
+
+// ClaimDue marks the scheduled messages due by now as sending and returns them. Rows
+// another instance is claiming are skipped, so each message is delivered once.
+func (s *MessageStore) ClaimDue(ctx context.Context, now time.Time) ([]*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE messages
+		SET status = 'sending'
+		WHERE id IN (
+			SELECT id FROM messages
+			WHERE status = 'scheduled' AND send_at <= $1
+			ORDER BY send_at
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + messageColumns
+
+	rows, err := s.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*Message{}
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// Complete records a delivered message with its Recipients and Failed counts
+func (s *MessageStore) Complete(ctx context.Context, message *Message) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE messages
+		SET status = 'sent', sent_at = NOW(), recipients = $2, failed = $3
+		WHERE id = $1
+		RETURNING status, sent_at`
+
+	err := s.db.QueryRowContext(ctx, query, message.ID, message.Recipients, message.Failed).
+		Scan(&message.Status, &message.SentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Recipients returns the restaurant's employees the message is for, by name
+func (s *MessageStore) Recipients(ctx context.Context, message *Message) ([]*Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT e.id, e.restaurant_id, e.full_name, e.email, e.locale, e.email_bounced_at, e.notification_mode
+		FROM employees e
+		WHERE e.restaurant_id = $1
+		  AND ((cardinality($2::bigint[]) = 0 AND cardinality($3::bigint[]) = 0)
+		       OR e.id = ANY($3)
+		       OR EXISTS (SELECT 1 FROM employee_roles er WHERE er.employee_id = e.id AND er.role_id = ANY($2)))
+		ORDER BY e.full_name, e.id`
+
+	rows, err := s.db.QueryContext(ctx, query, message.RestaurantID, message.RoleIDs, message.EmployeeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []*Employee{}
+	for rows.Next() {
+		var e Employee
+		if err := rows.Scan(&e.ID, &e.RestaurantID, &e.FullName, &e.Email, &e.Locale, &e.EmailBouncedAt, &e.NotificationMode); err != nil {
+			return nil, err
+		}
+		employees = append(employees, &e)
+	}
+
+	return employees, rows.Err()
+}
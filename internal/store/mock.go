@@ -16,11 +16,13 @@ func NewMockStore() Storage {
 type MockRestaurantStore struct {}
 
 func (s *MockRestaurantStore) Create(ctx context.Context, restaurant *Restaurant) error {
+	restaurant.AssignmentPolicy = AssignmentManualOnly
+	restaurant.NotificationMode, restaurant.DigestHour, restaurant.Timezone, restaurant.WeekStart = NotifyImmediately, 7, "UTC", 1
 	return nil
 }
 
 func (s *MockRestaurantStore) GetByID(ctx context.Context, id int64) (*Restaurant, error) {
-	return &Restaurant{ID: id}, nil
+	return &Restaurant{ID: id, UserID: 1, AssignmentPolicy: AssignmentManualOnly, NotificationMode: NotifyImmediately, DigestHour: 7, Timezone: "UTC", WeekStart: 1}, nil
 }
 
 func (s *MockRestaurantStore) Update(ctx context.Context, restaurant *Restaurant) error {
@@ -32,7 +34,27 @@ func (s *MockRestaurantStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }	
 
-func (s *MockRestaurantStore) ListByUser(ctx context.Context, userID int64) ([]*Restaurant, error) {
+func (s *MockRestaurantStore) ListByUser(ctx context.Context, userID int64, archived bool) ([]*Restaurant, error) {
+	return []*Restaurant{}, nil
+}
+
+func (s *MockRestaurantStore) CountByUser(ctx context.Context, userID int64) (int, error) {
+	return 0, nil
+}
+
+func (s *MockRestaurantStore) SetArchived(ctx context.Context, restaurant *Restaurant, archived bool) error {
+	return nil
+}
+
+func (s *MockRestaurantStore) MarkExported(ctx context.Context, restaurant *Restaurant, exportedAt time.Time) error {
+	return nil
+}
+
+func (s *MockRestaurantStore) Clone(ctx context.Context, clone *RestaurantClone) error {
+	return nil
+}
+
+func (s *MockRestaurantStore) ClaimMilestoneDigests(ctx context.Context, today DateOnly) ([]*Restaurant, error) {
 	return []*Restaurant{}, nil
 }
 
@@ -68,10 +90,22 @@ func (s *MockUserStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+func (s *MockUserStore) UpdateLocale(ctx context.Context, userID int64, locale *string) error {
+	return nil
+}
+
 func (s *MockUserStore) Activate(ctx context.Context, token string) error {
 	return nil
 }
 
+func (s *MockUserStore) ActivationStatus(ctx context.Context, token string) (*ActivationStatus, error) {
+	return &ActivationStatus{Status: ActivationStatusInvalid}, nil
+}
+
+func (s *MockUserStore) DeleteExpiredInvitations(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
 func (s *MockUserStore) ResendInvitation(ctx context.Context, email string, token string, exp time.Duration) (*User, error) {
 	return &User{ID: 1, FirstName: "Test", LastName: "User", Email: email, IsActive: false}, nil
 }
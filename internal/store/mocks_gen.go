@@ -0,0 +1,2470 @@
+// Code generated by mockgen -type Storage storage.go; DO NOT EDIT.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MockUserStorer is a UserStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockUserStorer struct {
+	CreateFunc                      func(context.Context, *sql.Tx, *User) error
+	GetByIDFunc                     func(context.Context, int64) (*User, error)
+	CreateAndInviteFunc             func(context.Context, *User, string, time.Duration) error
+	ActivateFunc                    func(context.Context, string) error
+	ActivationStatusFunc            func(context.Context, string) (*ActivationStatus, error)
+	DeleteExpiredInvitationsFunc    func(context.Context, time.Time) (int64, error)
+	ResendInvitationFunc            func(context.Context, string, string, time.Duration) (*User, error)
+	DeleteFunc                      func(context.Context, int64) error
+	GetByEmailFunc                  func(context.Context, string) (*User, error)
+	GetByEmailIncludingInactiveFunc func(context.Context, string) (*User, error)
+	GetByGoogleIDFunc               func(context.Context, string) (*User, error)
+	CreateWithGoogleFunc            func(context.Context, *sql.Tx, *User, string, string) error
+	CreateUserWithGoogleFunc        func(context.Context, *User, string, string) error
+	LinkGoogleAccountFunc           func(context.Context, int64, string, string) error
+	UpdateLocaleFunc                func(context.Context, int64, *string) error
+}
+
+var _ UserStorer = (*MockUserStorer)(nil)
+
+func (m *MockUserStorer) Create(a0 context.Context, a1 *sql.Tx, a2 *User) error {
+	if m.CreateFunc == nil {
+		panic("MockUserStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1, a2)
+}
+
+func (m *MockUserStorer) GetByID(a0 context.Context, a1 int64) (*User, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockUserStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockUserStorer) CreateAndInvite(a0 context.Context, a1 *User, a2 string, a3 time.Duration) error {
+	if m.CreateAndInviteFunc == nil {
+		panic("MockUserStorer.CreateAndInvite called but CreateAndInviteFunc is not set")
+	}
+	return m.CreateAndInviteFunc(a0, a1, a2, a3)
+}
+
+func (m *MockUserStorer) Activate(a0 context.Context, a1 string) error {
+	if m.ActivateFunc == nil {
+		panic("MockUserStorer.Activate called but ActivateFunc is not set")
+	}
+	return m.ActivateFunc(a0, a1)
+}
+
+func (m *MockUserStorer) ActivationStatus(a0 context.Context, a1 string) (*ActivationStatus, error) {
+	if m.ActivationStatusFunc == nil {
+		panic("MockUserStorer.ActivationStatus called but ActivationStatusFunc is not set")
+	}
+	return m.ActivationStatusFunc(a0, a1)
+}
+
+func (m *MockUserStorer) DeleteExpiredInvitations(a0 context.Context, a1 time.Time) (int64, error) {
+	if m.DeleteExpiredInvitationsFunc == nil {
+		panic("MockUserStorer.DeleteExpiredInvitations called but DeleteExpiredInvitationsFunc is not set")
+	}
+	return m.DeleteExpiredInvitationsFunc(a0, a1)
+}
+
+func (m *MockUserStorer) ResendInvitation(a0 context.Context, a1 string, a2 string, a3 time.Duration) (*User, error) {
+	if m.ResendInvitationFunc == nil {
+		panic("MockUserStorer.ResendInvitation called but ResendInvitationFunc is not set")
+	}
+	return m.ResendInvitationFunc(a0, a1, a2, a3)
+}
+
+func (m *MockUserStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockUserStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockUserStorer) GetByEmail(a0 context.Context, a1 string) (*User, error) {
+	if m.GetByEmailFunc == nil {
+		panic("MockUserStorer.GetByEmail called but GetByEmailFunc is not set")
+	}
+	return m.GetByEmailFunc(a0, a1)
+}
+
+func (m *MockUserStorer) GetByEmailIncludingInactive(a0 context.Context, a1 string) (*User, error) {
+	if m.GetByEmailIncludingInactiveFunc == nil {
+		panic("MockUserStorer.GetByEmailIncludingInactive called but GetByEmailIncludingInactiveFunc is not set")
+	}
+	return m.GetByEmailIncludingInactiveFunc(a0, a1)
+}
+
+func (m *MockUserStorer) GetByGoogleID(a0 context.Context, a1 string) (*User, error) {
+	if m.GetByGoogleIDFunc == nil {
+		panic("MockUserStorer.GetByGoogleID called but GetByGoogleIDFunc is not set")
+	}
+	return m.GetByGoogleIDFunc(a0, a1)
+}
+
+func (m *MockUserStorer) CreateWithGoogle(a0 context.Context, a1 *sql.Tx, a2 *User, a3 string, a4 string) error {
+	if m.CreateWithGoogleFunc == nil {
+		panic("MockUserStorer.CreateWithGoogle called but CreateWithGoogleFunc is not set")
+	}
+	return m.CreateWithGoogleFunc(a0, a1, a2, a3, a4)
+}
+
+func (m *MockUserStorer) CreateUserWithGoogle(a0 context.Context, a1 *User, a2 string, a3 string) error {
+	if m.CreateUserWithGoogleFunc == nil {
+		panic("MockUserStorer.CreateUserWithGoogle called but CreateUserWithGoogleFunc is not set")
+	}
+	return m.CreateUserWithGoogleFunc(a0, a1, a2, a3)
+}
+
+func (m *MockUserStorer) LinkGoogleAccount(a0 context.Context, a1 int64, a2 string, a3 string) error {
+	if m.LinkGoogleAccountFunc == nil {
+		panic("MockUserStorer.LinkGoogleAccount called but LinkGoogleAccountFunc is not set")
+	}
+	return m.LinkGoogleAccountFunc(a0, a1, a2, a3)
+}
+
+func (m *MockUserStorer) UpdateLocale(a0 context.Context, a1 int64, a2 *string) error {
+	if m.UpdateLocaleFunc == nil {
+		panic("MockUserStorer.UpdateLocale called but UpdateLocaleFunc is not set")
+	}
+	return m.UpdateLocaleFunc(a0, a1, a2)
+}
+
+// MockRestaurantStorer is a RestaurantStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockRestaurantStorer struct {
+	CreateFunc                func(context.Context, *Restaurant) error
+	GetByIDFunc               func(context.Context, int64) (*Restaurant, error)
+	UpdateFunc                func(context.Context, *Restaurant) error
+	DeleteFunc                func(context.Context, int64) error
+	ListByUserFunc            func(context.Context, int64, bool) ([]*Restaurant, error)
+	CountByUserFunc           func(context.Context, int64) (int, error)
+	SetArchivedFunc           func(context.Context, *Restaurant, bool) error
+	MarkExportedFunc          func(context.Context, *Restaurant, time.Time) error
+	CloneFunc                 func(context.Context, *RestaurantClone) error
+	ClaimMilestoneDigestsFunc func(context.Context, DateOnly) ([]*Restaurant, error)
+}
+
+var _ RestaurantStorer = (*MockRestaurantStorer)(nil)
+
+func (m *MockRestaurantStorer) Create(a0 context.Context, a1 *Restaurant) error {
+	if m.CreateFunc == nil {
+		panic("MockRestaurantStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) GetByID(a0 context.Context, a1 int64) (*Restaurant, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockRestaurantStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) Update(a0 context.Context, a1 *Restaurant) error {
+	if m.UpdateFunc == nil {
+		panic("MockRestaurantStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockRestaurantStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) ListByUser(a0 context.Context, a1 int64, a2 bool) ([]*Restaurant, error) {
+	if m.ListByUserFunc == nil {
+		panic("MockRestaurantStorer.ListByUser called but ListByUserFunc is not set")
+	}
+	return m.ListByUserFunc(a0, a1, a2)
+}
+
+func (m *MockRestaurantStorer) CountByUser(a0 context.Context, a1 int64) (int, error) {
+	if m.CountByUserFunc == nil {
+		panic("MockRestaurantStorer.CountByUser called but CountByUserFunc is not set")
+	}
+	return m.CountByUserFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) SetArchived(a0 context.Context, a1 *Restaurant, a2 bool) error {
+	if m.SetArchivedFunc == nil {
+		panic("MockRestaurantStorer.SetArchived called but SetArchivedFunc is not set")
+	}
+	return m.SetArchivedFunc(a0, a1, a2)
+}
+
+func (m *MockRestaurantStorer) MarkExported(a0 context.Context, a1 *Restaurant, a2 time.Time) error {
+	if m.MarkExportedFunc == nil {
+		panic("MockRestaurantStorer.MarkExported called but MarkExportedFunc is not set")
+	}
+	return m.MarkExportedFunc(a0, a1, a2)
+}
+
+func (m *MockRestaurantStorer) Clone(a0 context.Context, a1 *RestaurantClone) error {
+	if m.CloneFunc == nil {
+		panic("MockRestaurantStorer.Clone called but CloneFunc is not set")
+	}
+	return m.CloneFunc(a0, a1)
+}
+
+func (m *MockRestaurantStorer) ClaimMilestoneDigests(a0 context.Context, a1 DateOnly) ([]*Restaurant, error) {
+	if m.ClaimMilestoneDigestsFunc == nil {
+		panic("MockRestaurantStorer.ClaimMilestoneDigests called but ClaimMilestoneDigestsFunc is not set")
+	}
+	return m.ClaimMilestoneDigestsFunc(a0, a1)
+}
+
+// MockEmployeeStorer is a EmployeeStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEmployeeStorer struct {
+	CreateFunc                   func(context.Context, *Employee) error
+	GetByIDFunc                  func(context.Context, int64) (*Employee, error)
+	GetByIDsFunc                 func(context.Context, []int64) ([]*Employee, error)
+	ListByRestaurantFunc         func(context.Context, int64) ([]*Employee, error)
+	UpdateFunc                   func(context.Context, *Employee) error
+	DeleteFunc                   func(context.Context, int64) error
+	AssignRolesFunc              func(context.Context, int64, []int64) error
+	RemoveRoleFunc               func(context.Context, int64, int64) error
+	GetRolesFunc                 func(context.Context, int64, int64) ([]*Role, error)
+	RoleIDsByRestaurantFunc      func(context.Context, int64) (map[int64][]int64, error)
+	CountByRestaurantFunc        func(context.Context, int64) (int, error)
+	SetAvatarFunc                func(context.Context, int64, *string) error
+	EraseFunc                    func(context.Context, int64) (*EmployeeErasure, error)
+	RequestEmailVerificationFunc func(context.Context, *Employee, string, time.Time) error
+	VerifyEmailFunc              func(context.Context, string, time.Time) (*VerifiedEmail, error)
+}
+
+var _ EmployeeStorer = (*MockEmployeeStorer)(nil)
+
+func (m *MockEmployeeStorer) Create(a0 context.Context, a1 *Employee) error {
+	if m.CreateFunc == nil {
+		panic("MockEmployeeStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) GetByID(a0 context.Context, a1 int64) (*Employee, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockEmployeeStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) GetByIDs(a0 context.Context, a1 []int64) ([]*Employee, error) {
+	if m.GetByIDsFunc == nil {
+		panic("MockEmployeeStorer.GetByIDs called but GetByIDsFunc is not set")
+	}
+	return m.GetByIDsFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Employee, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockEmployeeStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) Update(a0 context.Context, a1 *Employee) error {
+	if m.UpdateFunc == nil {
+		panic("MockEmployeeStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockEmployeeStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) AssignRoles(a0 context.Context, a1 int64, a2 []int64) error {
+	if m.AssignRolesFunc == nil {
+		panic("MockEmployeeStorer.AssignRoles called but AssignRolesFunc is not set")
+	}
+	return m.AssignRolesFunc(a0, a1, a2)
+}
+
+func (m *MockEmployeeStorer) RemoveRole(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RemoveRoleFunc == nil {
+		panic("MockEmployeeStorer.RemoveRole called but RemoveRoleFunc is not set")
+	}
+	return m.RemoveRoleFunc(a0, a1, a2)
+}
+
+func (m *MockEmployeeStorer) GetRoles(a0 context.Context, a1 int64, a2 int64) ([]*Role, error) {
+	if m.GetRolesFunc == nil {
+		panic("MockEmployeeStorer.GetRoles called but GetRolesFunc is not set")
+	}
+	return m.GetRolesFunc(a0, a1, a2)
+}
+
+func (m *MockEmployeeStorer) RoleIDsByRestaurant(a0 context.Context, a1 int64) (map[int64][]int64, error) {
+	if m.RoleIDsByRestaurantFunc == nil {
+		panic("MockEmployeeStorer.RoleIDsByRestaurant called but RoleIDsByRestaurantFunc is not set")
+	}
+	return m.RoleIDsByRestaurantFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) CountByRestaurant(a0 context.Context, a1 int64) (int, error) {
+	if m.CountByRestaurantFunc == nil {
+		panic("MockEmployeeStorer.CountByRestaurant called but CountByRestaurantFunc is not set")
+	}
+	return m.CountByRestaurantFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) SetAvatar(a0 context.Context, a1 int64, a2 *string) error {
+	if m.SetAvatarFunc == nil {
+		panic("MockEmployeeStorer.SetAvatar called but SetAvatarFunc is not set")
+	}
+	return m.SetAvatarFunc(a0, a1, a2)
+}
+
+func (m *MockEmployeeStorer) Erase(a0 context.Context, a1 int64) (*EmployeeErasure, error) {
+	if m.EraseFunc == nil {
+		panic("MockEmployeeStorer.Erase called but EraseFunc is not set")
+	}
+	return m.EraseFunc(a0, a1)
+}
+
+func (m *MockEmployeeStorer) RequestEmailVerification(a0 context.Context, a1 *Employee, a2 string, a3 time.Time) error {
+	if m.RequestEmailVerificationFunc == nil {
+		panic("MockEmployeeStorer.RequestEmailVerification called but RequestEmailVerificationFunc is not set")
+	}
+	return m.RequestEmailVerificationFunc(a0, a1, a2, a3)
+}
+
+func (m *MockEmployeeStorer) VerifyEmail(a0 context.Context, a1 string, a2 time.Time) (*VerifiedEmail, error) {
+	if m.VerifyEmailFunc == nil {
+		panic("MockEmployeeStorer.VerifyEmail called but VerifyEmailFunc is not set")
+	}
+	return m.VerifyEmailFunc(a0, a1, a2)
+}
+
+// MockRoleStorer is a RoleStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockRoleStorer struct {
+	CreateFunc           func(context.Context, *Role) error
+	GetByIDFunc          func(context.Context, int64) (*Role, error)
+	GetByIDsFunc         func(context.Context, []int64) ([]*Role, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*Role, error)
+	UpdateFunc           func(context.Context, *Role) error
+	DeleteFunc           func(context.Context, int64) error
+	GetEmployeesFunc     func(context.Context, int64, int64) ([]*Employee, error)
+	UsageFunc            func(context.Context, int64, DateOnly) (*RoleUsage, error)
+	StaffingFunc         func(context.Context, int64, DateOnly, DateOnly) ([]*RoleStaffing, error)
+	ReassignFunc         func(context.Context, int64, int64) error
+	ForceDeleteFunc      func(context.Context, int64) error
+}
+
+var _ RoleStorer = (*MockRoleStorer)(nil)
+
+func (m *MockRoleStorer) Create(a0 context.Context, a1 *Role) error {
+	if m.CreateFunc == nil {
+		panic("MockRoleStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) GetByID(a0 context.Context, a1 int64) (*Role, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockRoleStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) GetByIDs(a0 context.Context, a1 []int64) ([]*Role, error) {
+	if m.GetByIDsFunc == nil {
+		panic("MockRoleStorer.GetByIDs called but GetByIDsFunc is not set")
+	}
+	return m.GetByIDsFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Role, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockRoleStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) Update(a0 context.Context, a1 *Role) error {
+	if m.UpdateFunc == nil {
+		panic("MockRoleStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockRoleStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockRoleStorer) GetEmployees(a0 context.Context, a1 int64, a2 int64) ([]*Employee, error) {
+	if m.GetEmployeesFunc == nil {
+		panic("MockRoleStorer.GetEmployees called but GetEmployeesFunc is not set")
+	}
+	return m.GetEmployeesFunc(a0, a1, a2)
+}
+
+func (m *MockRoleStorer) Usage(a0 context.Context, a1 int64, a2 DateOnly) (*RoleUsage, error) {
+	if m.UsageFunc == nil {
+		panic("MockRoleStorer.Usage called but UsageFunc is not set")
+	}
+	return m.UsageFunc(a0, a1, a2)
+}
+
+func (m *MockRoleStorer) Staffing(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*RoleStaffing, error) {
+	if m.StaffingFunc == nil {
+		panic("MockRoleStorer.Staffing called but StaffingFunc is not set")
+	}
+	return m.StaffingFunc(a0, a1, a2, a3)
+}
+
+func (m *MockRoleStorer) Reassign(a0 context.Context, a1 int64, a2 int64) error {
+	if m.ReassignFunc == nil {
+		panic("MockRoleStorer.Reassign called but ReassignFunc is not set")
+	}
+	return m.ReassignFunc(a0, a1, a2)
+}
+
+func (m *MockRoleStorer) ForceDelete(a0 context.Context, a1 int64) error {
+	if m.ForceDeleteFunc == nil {
+		panic("MockRoleStorer.ForceDelete called but ForceDeleteFunc is not set")
+	}
+	return m.ForceDeleteFunc(a0, a1)
+}
+
+// MockShiftTemplateStorer is a ShiftTemplateStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockShiftTemplateStorer struct {
+	CreateFunc           func(context.Context, *ShiftTemplate) error
+	GetByIDFunc          func(context.Context, int64) (*ShiftTemplate, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*ShiftTemplate, error)
+	UpdateFunc           func(context.Context, *ShiftTemplate) error
+	DeleteFunc           func(context.Context, int64) error
+	MergeFunc            func(context.Context, *ShiftTemplate, []int64) (int64, error)
+}
+
+var _ ShiftTemplateStorer = (*MockShiftTemplateStorer)(nil)
+
+func (m *MockShiftTemplateStorer) Create(a0 context.Context, a1 *ShiftTemplate) error {
+	if m.CreateFunc == nil {
+		panic("MockShiftTemplateStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockShiftTemplateStorer) GetByID(a0 context.Context, a1 int64) (*ShiftTemplate, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockShiftTemplateStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockShiftTemplateStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*ShiftTemplate, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockShiftTemplateStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockShiftTemplateStorer) Update(a0 context.Context, a1 *ShiftTemplate) error {
+	if m.UpdateFunc == nil {
+		panic("MockShiftTemplateStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockShiftTemplateStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockShiftTemplateStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockShiftTemplateStorer) Merge(a0 context.Context, a1 *ShiftTemplate, a2 []int64) (int64, error) {
+	if m.MergeFunc == nil {
+		panic("MockShiftTemplateStorer.Merge called but MergeFunc is not set")
+	}
+	return m.MergeFunc(a0, a1, a2)
+}
+
+// MockScheduleStorer is a ScheduleStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockScheduleStorer struct {
+	CreateFunc             func(context.Context, *Schedule) error
+	GetByIDFunc            func(context.Context, int64) (*Schedule, error)
+	GetByDateFunc          func(context.Context, int64, DateOnly) (*Schedule, error)
+	ListByRestaurantFunc   func(context.Context, int64, bool) ([]*Schedule, error)
+	UpdateFunc             func(context.Context, *Schedule) error
+	ShrinkFunc             func(context.Context, *Schedule) ([]int64, error)
+	DeleteFunc             func(context.Context, int64) error
+	PublishFunc            func(context.Context, int64, time.Time) error
+	ArchiveEndedBeforeFunc func(context.Context, int64, DateOnly) (int64, error)
+	ArchiveExpiredFunc     func(context.Context, time.Time) (int64, error)
+}
+
+var _ ScheduleStorer = (*MockScheduleStorer)(nil)
+
+func (m *MockScheduleStorer) Create(a0 context.Context, a1 *Schedule) error {
+	if m.CreateFunc == nil {
+		panic("MockScheduleStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) GetByID(a0 context.Context, a1 int64) (*Schedule, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockScheduleStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) GetByDate(a0 context.Context, a1 int64, a2 DateOnly) (*Schedule, error) {
+	if m.GetByDateFunc == nil {
+		panic("MockScheduleStorer.GetByDate called but GetByDateFunc is not set")
+	}
+	return m.GetByDateFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleStorer) ListByRestaurant(a0 context.Context, a1 int64, a2 bool) ([]*Schedule, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockScheduleStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleStorer) Update(a0 context.Context, a1 *Schedule) error {
+	if m.UpdateFunc == nil {
+		panic("MockScheduleStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Shrink(a0 context.Context, a1 *Schedule) ([]int64, error) {
+	if m.ShrinkFunc == nil {
+		panic("MockScheduleStorer.Shrink called but ShrinkFunc is not set")
+	}
+	return m.ShrinkFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockScheduleStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockScheduleStorer) Publish(a0 context.Context, a1 int64, a2 time.Time) error {
+	if m.PublishFunc == nil {
+		panic("MockScheduleStorer.Publish called but PublishFunc is not set")
+	}
+	return m.PublishFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleStorer) ArchiveEndedBefore(a0 context.Context, a1 int64, a2 DateOnly) (int64, error) {
+	if m.ArchiveEndedBeforeFunc == nil {
+		panic("MockScheduleStorer.ArchiveEndedBefore called but ArchiveEndedBeforeFunc is not set")
+	}
+	return m.ArchiveEndedBeforeFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleStorer) ArchiveExpired(a0 context.Context, a1 time.Time) (int64, error) {
+	if m.ArchiveExpiredFunc == nil {
+		panic("MockScheduleStorer.ArchiveExpired called but ArchiveExpiredFunc is not set")
+	}
+	return m.ArchiveExpiredFunc(a0, a1)
+}
+
+// MockScheduleSnapshotStorer is a ScheduleSnapshotStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockScheduleSnapshotStorer struct {
+	CreateFunc          func(context.Context, int64, string) error
+	LatestFunc          func(context.Context, int64, *time.Time) (*ScheduleSnapshot, error)
+	LatestPublishedFunc func(context.Context, int64, *time.Time) (*ScheduleSnapshot, error)
+	CurrentShiftsFunc   func(context.Context, int64) ([]*SnapshotShift, error)
+}
+
+var _ ScheduleSnapshotStorer = (*MockScheduleSnapshotStorer)(nil)
+
+func (m *MockScheduleSnapshotStorer) Create(a0 context.Context, a1 int64, a2 string) error {
+	if m.CreateFunc == nil {
+		panic("MockScheduleSnapshotStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleSnapshotStorer) Latest(a0 context.Context, a1 int64, a2 *time.Time) (*ScheduleSnapshot, error) {
+	if m.LatestFunc == nil {
+		panic("MockScheduleSnapshotStorer.Latest called but LatestFunc is not set")
+	}
+	return m.LatestFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleSnapshotStorer) LatestPublished(a0 context.Context, a1 int64, a2 *time.Time) (*ScheduleSnapshot, error) {
+	if m.LatestPublishedFunc == nil {
+		panic("MockScheduleSnapshotStorer.LatestPublished called but LatestPublishedFunc is not set")
+	}
+	return m.LatestPublishedFunc(a0, a1, a2)
+}
+
+func (m *MockScheduleSnapshotStorer) CurrentShifts(a0 context.Context, a1 int64) ([]*SnapshotShift, error) {
+	if m.CurrentShiftsFunc == nil {
+		panic("MockScheduleSnapshotStorer.CurrentShifts called but CurrentShiftsFunc is not set")
+	}
+	return m.CurrentShiftsFunc(a0, a1)
+}
+
+// MockScheduleNoteStorer is a ScheduleNoteStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockScheduleNoteStorer struct {
+	ListByScheduleFunc func(context.Context, int64) ([]*ScheduleDayNote, error)
+	UpsertFunc         func(context.Context, *ScheduleDayNote) error
+	DeleteFunc         func(context.Context, int64, DateOnly) error
+}
+
+var _ ScheduleNoteStorer = (*MockScheduleNoteStorer)(nil)
+
+func (m *MockScheduleNoteStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*ScheduleDayNote, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockScheduleNoteStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+func (m *MockScheduleNoteStorer) Upsert(a0 context.Context, a1 *ScheduleDayNote) error {
+	if m.UpsertFunc == nil {
+		panic("MockScheduleNoteStorer.Upsert called but UpsertFunc is not set")
+	}
+	return m.UpsertFunc(a0, a1)
+}
+
+func (m *MockScheduleNoteStorer) Delete(a0 context.Context, a1 int64, a2 DateOnly) error {
+	if m.DeleteFunc == nil {
+		panic("MockScheduleNoteStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1, a2)
+}
+
+// MockScheduledShiftStorer is a ScheduledShiftStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockScheduledShiftStorer struct {
+	CreateFunc                   func(context.Context, *ScheduledShift) error
+	BatchCreateFunc              func(context.Context, []*ScheduledShift) ([]int64, error)
+	GetByIDFunc                  func(context.Context, int64) (*ScheduledShift, error)
+	ListByScheduleFunc           func(context.Context, int64) ([]*ScheduledShift, error)
+	ListWarningsByScheduleFunc   func(context.Context, int64) (map[int64][]ShiftWarning, error)
+	ListByRestaurantAndRangeFunc func(context.Context, int64, DateOnly, DateOnly) ([]*ScheduledShift, error)
+	ListPageFunc                 func(context.Context, int64, DateOnly, DateOnly, *ShiftCursor, int) ([]*ScheduledShift, error)
+	UpdateFunc                   func(context.Context, *ScheduledShift) error
+	DeleteFunc                   func(context.Context, int64) error
+	AssignEmployeeFunc           func(context.Context, int64, ShiftAssignment) error
+	RepairDenormalizedFunc       func(context.Context, int64) (*DenormalizedRepair, error)
+	ListPatternsFunc             func(context.Context, int64, time.Time) ([]*ShiftPattern, error)
+	ListCoverageFunc             func(context.Context, int64, DateOnly, DateOnly) ([]*CoverageCell, error)
+}
+
+var _ ScheduledShiftStorer = (*MockScheduledShiftStorer)(nil)
+
+func (m *MockScheduledShiftStorer) Create(a0 context.Context, a1 *ScheduledShift) error {
+	if m.CreateFunc == nil {
+		panic("MockScheduledShiftStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) BatchCreate(a0 context.Context, a1 []*ScheduledShift) ([]int64, error) {
+	if m.BatchCreateFunc == nil {
+		panic("MockScheduledShiftStorer.BatchCreate called but BatchCreateFunc is not set")
+	}
+	return m.BatchCreateFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) GetByID(a0 context.Context, a1 int64) (*ScheduledShift, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockScheduledShiftStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*ScheduledShift, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockScheduledShiftStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) ListWarningsBySchedule(a0 context.Context, a1 int64) (map[int64][]ShiftWarning, error) {
+	if m.ListWarningsByScheduleFunc == nil {
+		panic("MockScheduledShiftStorer.ListWarningsBySchedule called but ListWarningsByScheduleFunc is not set")
+	}
+	return m.ListWarningsByScheduleFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) ListByRestaurantAndRange(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*ScheduledShift, error) {
+	if m.ListByRestaurantAndRangeFunc == nil {
+		panic("MockScheduledShiftStorer.ListByRestaurantAndRange called but ListByRestaurantAndRangeFunc is not set")
+	}
+	return m.ListByRestaurantAndRangeFunc(a0, a1, a2, a3)
+}
+
+func (m *MockScheduledShiftStorer) ListPage(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly, a4 *ShiftCursor, a5 int) ([]*ScheduledShift, error) {
+	if m.ListPageFunc == nil {
+		panic("MockScheduledShiftStorer.ListPage called but ListPageFunc is not set")
+	}
+	return m.ListPageFunc(a0, a1, a2, a3, a4, a5)
+}
+
+func (m *MockScheduledShiftStorer) Update(a0 context.Context, a1 *ScheduledShift) error {
+	if m.UpdateFunc == nil {
+		panic("MockScheduledShiftStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockScheduledShiftStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) AssignEmployee(a0 context.Context, a1 int64, a2 ShiftAssignment) error {
+	if m.AssignEmployeeFunc == nil {
+		panic("MockScheduledShiftStorer.AssignEmployee called but AssignEmployeeFunc is not set")
+	}
+	return m.AssignEmployeeFunc(a0, a1, a2)
+}
+
+func (m *MockScheduledShiftStorer) RepairDenormalized(a0 context.Context, a1 int64) (*DenormalizedRepair, error) {
+	if m.RepairDenormalizedFunc == nil {
+		panic("MockScheduledShiftStorer.RepairDenormalized called but RepairDenormalizedFunc is not set")
+	}
+	return m.RepairDenormalizedFunc(a0, a1)
+}
+
+func (m *MockScheduledShiftStorer) ListPatterns(a0 context.Context, a1 int64, a2 time.Time) ([]*ShiftPattern, error) {
+	if m.ListPatternsFunc == nil {
+		panic("MockScheduledShiftStorer.ListPatterns called but ListPatternsFunc is not set")
+	}
+	return m.ListPatternsFunc(a0, a1, a2)
+}
+
+func (m *MockScheduledShiftStorer) ListCoverage(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*CoverageCell, error) {
+	if m.ListCoverageFunc == nil {
+		panic("MockScheduledShiftStorer.ListCoverage called but ListCoverageFunc is not set")
+	}
+	return m.ListCoverageFunc(a0, a1, a2, a3)
+}
+
+// MockEventStorer is a EventStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEventStorer struct {
+	CreateFunc                       func(context.Context, *Event) error
+	GetByIDFunc                      func(context.Context, int64) (*Event, error)
+	ListByRestaurantFunc             func(context.Context, int64) ([]*Event, error)
+	ListByRestaurantAndDateRangeFunc func(context.Context, int64, DateOnly, DateOnly) ([]*Event, error)
+	UpdateFunc                       func(context.Context, *Event) error
+	DeleteFunc                       func(context.Context, int64) error
+	AssignEmployeesFunc              func(context.Context, int64, []int64) error
+	RemoveEmployeeFunc               func(context.Context, int64, int64) error
+	GetEmployeesFunc                 func(context.Context, int64) ([]*Employee, error)
+	ReplaceEmployeesFunc             func(context.Context, int64, []int64) error
+}
+
+var _ EventStorer = (*MockEventStorer)(nil)
+
+func (m *MockEventStorer) Create(a0 context.Context, a1 *Event) error {
+	if m.CreateFunc == nil {
+		panic("MockEventStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockEventStorer) GetByID(a0 context.Context, a1 int64) (*Event, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockEventStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockEventStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Event, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockEventStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockEventStorer) ListByRestaurantAndDateRange(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*Event, error) {
+	if m.ListByRestaurantAndDateRangeFunc == nil {
+		panic("MockEventStorer.ListByRestaurantAndDateRange called but ListByRestaurantAndDateRangeFunc is not set")
+	}
+	return m.ListByRestaurantAndDateRangeFunc(a0, a1, a2, a3)
+}
+
+func (m *MockEventStorer) Update(a0 context.Context, a1 *Event) error {
+	if m.UpdateFunc == nil {
+		panic("MockEventStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockEventStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockEventStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockEventStorer) AssignEmployees(a0 context.Context, a1 int64, a2 []int64) error {
+	if m.AssignEmployeesFunc == nil {
+		panic("MockEventStorer.AssignEmployees called but AssignEmployeesFunc is not set")
+	}
+	return m.AssignEmployeesFunc(a0, a1, a2)
+}
+
+func (m *MockEventStorer) RemoveEmployee(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RemoveEmployeeFunc == nil {
+		panic("MockEventStorer.RemoveEmployee called but RemoveEmployeeFunc is not set")
+	}
+	return m.RemoveEmployeeFunc(a0, a1, a2)
+}
+
+func (m *MockEventStorer) GetEmployees(a0 context.Context, a1 int64) ([]*Employee, error) {
+	if m.GetEmployeesFunc == nil {
+		panic("MockEventStorer.GetEmployees called but GetEmployeesFunc is not set")
+	}
+	return m.GetEmployeesFunc(a0, a1)
+}
+
+func (m *MockEventStorer) ReplaceEmployees(a0 context.Context, a1 int64, a2 []int64) error {
+	if m.ReplaceEmployeesFunc == nil {
+		panic("MockEventStorer.ReplaceEmployees called but ReplaceEmployeesFunc is not set")
+	}
+	return m.ReplaceEmployeesFunc(a0, a1, a2)
+}
+
+// MockCertificationStorer is a CertificationStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockCertificationStorer struct {
+	CreateFunc               func(context.Context, *Certification) error
+	GetByIDFunc              func(context.Context, int64) (*Certification, error)
+	ListByRestaurantFunc     func(context.Context, int64) ([]*Certification, error)
+	UpdateFunc               func(context.Context, *Certification) error
+	DeleteFunc               func(context.Context, int64) error
+	ListByEmployeeFunc       func(context.Context, int64) ([]*EmployeeCertification, error)
+	SetForEmployeeFunc       func(context.Context, *EmployeeCertification) error
+	RemoveFromEmployeeFunc   func(context.Context, int64, int64) error
+	ListRequiredByRoleFunc   func(context.Context, int64) ([]*Certification, error)
+	SetRequiredByRoleFunc    func(context.Context, int64, []int64) error
+	MissingForAssignmentFunc func(context.Context, int64, int64, time.Time) ([]string, error)
+	ListExpiringFunc         func(context.Context, int64, DateOnly) ([]*EmployeeCertification, error)
+}
+
+var _ CertificationStorer = (*MockCertificationStorer)(nil)
+
+func (m *MockCertificationStorer) Create(a0 context.Context, a1 *Certification) error {
+	if m.CreateFunc == nil {
+		panic("MockCertificationStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) GetByID(a0 context.Context, a1 int64) (*Certification, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockCertificationStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Certification, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockCertificationStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) Update(a0 context.Context, a1 *Certification) error {
+	if m.UpdateFunc == nil {
+		panic("MockCertificationStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockCertificationStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) ListByEmployee(a0 context.Context, a1 int64) ([]*EmployeeCertification, error) {
+	if m.ListByEmployeeFunc == nil {
+		panic("MockCertificationStorer.ListByEmployee called but ListByEmployeeFunc is not set")
+	}
+	return m.ListByEmployeeFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) SetForEmployee(a0 context.Context, a1 *EmployeeCertification) error {
+	if m.SetForEmployeeFunc == nil {
+		panic("MockCertificationStorer.SetForEmployee called but SetForEmployeeFunc is not set")
+	}
+	return m.SetForEmployeeFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) RemoveFromEmployee(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RemoveFromEmployeeFunc == nil {
+		panic("MockCertificationStorer.RemoveFromEmployee called but RemoveFromEmployeeFunc is not set")
+	}
+	return m.RemoveFromEmployeeFunc(a0, a1, a2)
+}
+
+func (m *MockCertificationStorer) ListRequiredByRole(a0 context.Context, a1 int64) ([]*Certification, error) {
+	if m.ListRequiredByRoleFunc == nil {
+		panic("MockCertificationStorer.ListRequiredByRole called but ListRequiredByRoleFunc is not set")
+	}
+	return m.ListRequiredByRoleFunc(a0, a1)
+}
+
+func (m *MockCertificationStorer) SetRequiredByRole(a0 context.Context, a1 int64, a2 []int64) error {
+	if m.SetRequiredByRoleFunc == nil {
+		panic("MockCertificationStorer.SetRequiredByRole called but SetRequiredByRoleFunc is not set")
+	}
+	return m.SetRequiredByRoleFunc(a0, a1, a2)
+}
+
+func (m *MockCertificationStorer) MissingForAssignment(a0 context.Context, a1 int64, a2 int64, a3 time.Time) ([]string, error) {
+	if m.MissingForAssignmentFunc == nil {
+		panic("MockCertificationStorer.MissingForAssignment called but MissingForAssignmentFunc is not set")
+	}
+	return m.MissingForAssignmentFunc(a0, a1, a2, a3)
+}
+
+func (m *MockCertificationStorer) ListExpiring(a0 context.Context, a1 int64, a2 DateOnly) ([]*EmployeeCertification, error) {
+	if m.ListExpiringFunc == nil {
+		panic("MockCertificationStorer.ListExpiring called but ListExpiringFunc is not set")
+	}
+	return m.ListExpiringFunc(a0, a1, a2)
+}
+
+// MockEmailTemplateStorer is a EmailTemplateStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEmailTemplateStorer struct {
+	GetByRestaurantFunc func(context.Context, int64) (*EmailTemplate, error)
+	UpsertFunc          func(context.Context, *EmailTemplate) error
+	DeleteFunc          func(context.Context, int64) error
+}
+
+var _ EmailTemplateStorer = (*MockEmailTemplateStorer)(nil)
+
+func (m *MockEmailTemplateStorer) GetByRestaurant(a0 context.Context, a1 int64) (*EmailTemplate, error) {
+	if m.GetByRestaurantFunc == nil {
+		panic("MockEmailTemplateStorer.GetByRestaurant called but GetByRestaurantFunc is not set")
+	}
+	return m.GetByRestaurantFunc(a0, a1)
+}
+
+func (m *MockEmailTemplateStorer) Upsert(a0 context.Context, a1 *EmailTemplate) error {
+	if m.UpsertFunc == nil {
+		panic("MockEmailTemplateStorer.Upsert called but UpsertFunc is not set")
+	}
+	return m.UpsertFunc(a0, a1)
+}
+
+func (m *MockEmailTemplateStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockEmailTemplateStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+// MockDocumentStorer is a DocumentStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockDocumentStorer struct {
+	CreateFunc           func(context.Context, *Document) error
+	GetByIDFunc          func(context.Context, int64) (*Document, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*Document, error)
+	ListByEmployeeFunc   func(context.Context, int64) ([]*Document, error)
+	DeleteFunc           func(context.Context, int64) error
+}
+
+var _ DocumentStorer = (*MockDocumentStorer)(nil)
+
+func (m *MockDocumentStorer) Create(a0 context.Context, a1 *Document) error {
+	if m.CreateFunc == nil {
+		panic("MockDocumentStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockDocumentStorer) GetByID(a0 context.Context, a1 int64) (*Document, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockDocumentStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockDocumentStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Document, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockDocumentStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockDocumentStorer) ListByEmployee(a0 context.Context, a1 int64) ([]*Document, error) {
+	if m.ListByEmployeeFunc == nil {
+		panic("MockDocumentStorer.ListByEmployee called but ListByEmployeeFunc is not set")
+	}
+	return m.ListByEmployeeFunc(a0, a1)
+}
+
+func (m *MockDocumentStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockDocumentStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+// MockDocumentAcknowledgmentStorer is a DocumentAcknowledgmentStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockDocumentAcknowledgmentStorer struct {
+	RequestFunc        func(context.Context, *DocumentAcknowledgment, string) error
+	ListByDocumentFunc func(context.Context, int64) ([]*DocumentAcknowledgment, error)
+	AuthenticateFunc   func(context.Context, string, time.Time) (*DocumentToAcknowledge, error)
+	AcknowledgeFunc    func(context.Context, *DocumentAcknowledgment, string, string, string) error
+}
+
+var _ DocumentAcknowledgmentStorer = (*MockDocumentAcknowledgmentStorer)(nil)
+
+func (m *MockDocumentAcknowledgmentStorer) Request(a0 context.Context, a1 *DocumentAcknowledgment, a2 string) error {
+	if m.RequestFunc == nil {
+		panic("MockDocumentAcknowledgmentStorer.Request called but RequestFunc is not set")
+	}
+	return m.RequestFunc(a0, a1, a2)
+}
+
+func (m *MockDocumentAcknowledgmentStorer) ListByDocument(a0 context.Context, a1 int64) ([]*DocumentAcknowledgment, error) {
+	if m.ListByDocumentFunc == nil {
+		panic("MockDocumentAcknowledgmentStorer.ListByDocument called but ListByDocumentFunc is not set")
+	}
+	return m.ListByDocumentFunc(a0, a1)
+}
+
+func (m *MockDocumentAcknowledgmentStorer) Authenticate(a0 context.Context, a1 string, a2 time.Time) (*DocumentToAcknowledge, error) {
+	if m.AuthenticateFunc == nil {
+		panic("MockDocumentAcknowledgmentStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1, a2)
+}
+
+func (m *MockDocumentAcknowledgmentStorer) Acknowledge(a0 context.Context, a1 *DocumentAcknowledgment, a2 string, a3 string, a4 string) error {
+	if m.AcknowledgeFunc == nil {
+		panic("MockDocumentAcknowledgmentStorer.Acknowledge called but AcknowledgeFunc is not set")
+	}
+	return m.AcknowledgeFunc(a0, a1, a2, a3, a4)
+}
+
+// MockEmployeeNoteStorer is a EmployeeNoteStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEmployeeNoteStorer struct {
+	ListByEmployeeFunc func(context.Context, int64) ([]*EmployeeNote, error)
+	CreateFunc         func(context.Context, *EmployeeNote) error
+}
+
+var _ EmployeeNoteStorer = (*MockEmployeeNoteStorer)(nil)
+
+func (m *MockEmployeeNoteStorer) ListByEmployee(a0 context.Context, a1 int64) ([]*EmployeeNote, error) {
+	if m.ListByEmployeeFunc == nil {
+		panic("MockEmployeeNoteStorer.ListByEmployee called but ListByEmployeeFunc is not set")
+	}
+	return m.ListByEmployeeFunc(a0, a1)
+}
+
+func (m *MockEmployeeNoteStorer) Create(a0 context.Context, a1 *EmployeeNote) error {
+	if m.CreateFunc == nil {
+		panic("MockEmployeeNoteStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+// MockOperatingHoursStorer is a OperatingHoursStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockOperatingHoursStorer struct {
+	GetFunc                  func(context.Context, int64) (*OperatingHours, error)
+	ReplaceFunc              func(context.Context, *OperatingHours) error
+	ListExceptionsFunc       func(context.Context, int64, DateOnly, DateOnly) ([]*HoursException, error)
+	GetExceptionFunc         func(context.Context, int64) (*HoursException, error)
+	CreateExceptionFunc      func(context.Context, *HoursException) error
+	DeleteExceptionFunc      func(context.Context, int64) error
+	ListHolidayCalendarsFunc func(context.Context, int) ([]*HolidayCalendar, error)
+	AddHolidaysFunc          func(context.Context, *HolidayCalendar, int, []*HoursException, DateOnly) (int, error)
+}
+
+var _ OperatingHoursStorer = (*MockOperatingHoursStorer)(nil)
+
+func (m *MockOperatingHoursStorer) Get(a0 context.Context, a1 int64) (*OperatingHours, error) {
+	if m.GetFunc == nil {
+		panic("MockOperatingHoursStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) Replace(a0 context.Context, a1 *OperatingHours) error {
+	if m.ReplaceFunc == nil {
+		panic("MockOperatingHoursStorer.Replace called but ReplaceFunc is not set")
+	}
+	return m.ReplaceFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) ListExceptions(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*HoursException, error) {
+	if m.ListExceptionsFunc == nil {
+		panic("MockOperatingHoursStorer.ListExceptions called but ListExceptionsFunc is not set")
+	}
+	return m.ListExceptionsFunc(a0, a1, a2, a3)
+}
+
+func (m *MockOperatingHoursStorer) GetException(a0 context.Context, a1 int64) (*HoursException, error) {
+	if m.GetExceptionFunc == nil {
+		panic("MockOperatingHoursStorer.GetException called but GetExceptionFunc is not set")
+	}
+	return m.GetExceptionFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) CreateException(a0 context.Context, a1 *HoursException) error {
+	if m.CreateExceptionFunc == nil {
+		panic("MockOperatingHoursStorer.CreateException called but CreateExceptionFunc is not set")
+	}
+	return m.CreateExceptionFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) DeleteException(a0 context.Context, a1 int64) error {
+	if m.DeleteExceptionFunc == nil {
+		panic("MockOperatingHoursStorer.DeleteException called but DeleteExceptionFunc is not set")
+	}
+	return m.DeleteExceptionFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) ListHolidayCalendars(a0 context.Context, a1 int) ([]*HolidayCalendar, error) {
+	if m.ListHolidayCalendarsFunc == nil {
+		panic("MockOperatingHoursStorer.ListHolidayCalendars called but ListHolidayCalendarsFunc is not set")
+	}
+	return m.ListHolidayCalendarsFunc(a0, a1)
+}
+
+func (m *MockOperatingHoursStorer) AddHolidays(a0 context.Context, a1 *HolidayCalendar, a2 int, a3 []*HoursException, a4 DateOnly) (int, error) {
+	if m.AddHolidaysFunc == nil {
+		panic("MockOperatingHoursStorer.AddHolidays called but AddHolidaysFunc is not set")
+	}
+	return m.AddHolidaysFunc(a0, a1, a2, a3, a4)
+}
+
+// MockNotificationStorer is a NotificationStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockNotificationStorer struct {
+	CreateManyFunc  func(context.Context, []*Notification) error
+	ListFunc        func(context.Context, NotificationRecipient, NotificationQuery) ([]*Notification, error)
+	UnreadCountFunc func(context.Context, NotificationRecipient) (int, error)
+	MarkReadFunc    func(context.Context, NotificationRecipient, int64) error
+	MarkAllReadFunc func(context.Context, NotificationRecipient) (int64, error)
+}
+
+var _ NotificationStorer = (*MockNotificationStorer)(nil)
+
+func (m *MockNotificationStorer) CreateMany(a0 context.Context, a1 []*Notification) error {
+	if m.CreateManyFunc == nil {
+		panic("MockNotificationStorer.CreateMany called but CreateManyFunc is not set")
+	}
+	return m.CreateManyFunc(a0, a1)
+}
+
+func (m *MockNotificationStorer) List(a0 context.Context, a1 NotificationRecipient, a2 NotificationQuery) ([]*Notification, error) {
+	if m.ListFunc == nil {
+		panic("MockNotificationStorer.List called but ListFunc is not set")
+	}
+	return m.ListFunc(a0, a1, a2)
+}
+
+func (m *MockNotificationStorer) UnreadCount(a0 context.Context, a1 NotificationRecipient) (int, error) {
+	if m.UnreadCountFunc == nil {
+		panic("MockNotificationStorer.UnreadCount called but UnreadCountFunc is not set")
+	}
+	return m.UnreadCountFunc(a0, a1)
+}
+
+func (m *MockNotificationStorer) MarkRead(a0 context.Context, a1 NotificationRecipient, a2 int64) error {
+	if m.MarkReadFunc == nil {
+		panic("MockNotificationStorer.MarkRead called but MarkReadFunc is not set")
+	}
+	return m.MarkReadFunc(a0, a1, a2)
+}
+
+func (m *MockNotificationStorer) MarkAllRead(a0 context.Context, a1 NotificationRecipient) (int64, error) {
+	if m.MarkAllReadFunc == nil {
+		panic("MockNotificationStorer.MarkAllRead called but MarkAllReadFunc is not set")
+	}
+	return m.MarkAllReadFunc(a0, a1)
+}
+
+// MockAuditLogStorer is a AuditLogStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockAuditLogStorer struct {
+	RecordFunc           func(context.Context, []*AuditEntry) error
+	ListByEntityFunc     func(context.Context, string, int64) ([]*AuditEntry, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*AuditEntry, error)
+}
+
+var _ AuditLogStorer = (*MockAuditLogStorer)(nil)
+
+func (m *MockAuditLogStorer) Record(a0 context.Context, a1 []*AuditEntry) error {
+	if m.RecordFunc == nil {
+		panic("MockAuditLogStorer.Record called but RecordFunc is not set")
+	}
+	return m.RecordFunc(a0, a1)
+}
+
+func (m *MockAuditLogStorer) ListByEntity(a0 context.Context, a1 string, a2 int64) ([]*AuditEntry, error) {
+	if m.ListByEntityFunc == nil {
+		panic("MockAuditLogStorer.ListByEntity called but ListByEntityFunc is not set")
+	}
+	return m.ListByEntityFunc(a0, a1, a2)
+}
+
+func (m *MockAuditLogStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*AuditEntry, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockAuditLogStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+// MockVersionStorer is a VersionStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockVersionStorer struct {
+	ScheduledShiftsFunc func(context.Context, int64) (*CollectionVersion, error)
+	SchedulesFunc       func(context.Context, int64) (*CollectionVersion, error)
+	EmployeesFunc       func(context.Context, int64) (*CollectionVersion, error)
+	RolesFunc           func(context.Context, int64) (*CollectionVersion, error)
+}
+
+var _ VersionStorer = (*MockVersionStorer)(nil)
+
+func (m *MockVersionStorer) ScheduledShifts(a0 context.Context, a1 int64) (*CollectionVersion, error) {
+	if m.ScheduledShiftsFunc == nil {
+		panic("MockVersionStorer.ScheduledShifts called but ScheduledShiftsFunc is not set")
+	}
+	return m.ScheduledShiftsFunc(a0, a1)
+}
+
+func (m *MockVersionStorer) Schedules(a0 context.Context, a1 int64) (*CollectionVersion, error) {
+	if m.SchedulesFunc == nil {
+		panic("MockVersionStorer.Schedules called but SchedulesFunc is not set")
+	}
+	return m.SchedulesFunc(a0, a1)
+}
+
+func (m *MockVersionStorer) Employees(a0 context.Context, a1 int64) (*CollectionVersion, error) {
+	if m.EmployeesFunc == nil {
+		panic("MockVersionStorer.Employees called but EmployeesFunc is not set")
+	}
+	return m.EmployeesFunc(a0, a1)
+}
+
+func (m *MockVersionStorer) Roles(a0 context.Context, a1 int64) (*CollectionVersion, error) {
+	if m.RolesFunc == nil {
+		panic("MockVersionStorer.Roles called but RolesFunc is not set")
+	}
+	return m.RolesFunc(a0, a1)
+}
+
+// MockSubscriptionStorer is a SubscriptionStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSubscriptionStorer struct {
+	CreateFunc          func(context.Context, *Subscription) error
+	GetByUserIDFunc     func(context.Context, int64) (*Subscription, error)
+	GetByCustomerIDFunc func(context.Context, string) (*Subscription, error)
+	UpdateFunc          func(context.Context, *Subscription) error
+}
+
+var _ SubscriptionStorer = (*MockSubscriptionStorer)(nil)
+
+func (m *MockSubscriptionStorer) Create(a0 context.Context, a1 *Subscription) error {
+	if m.CreateFunc == nil {
+		panic("MockSubscriptionStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockSubscriptionStorer) GetByUserID(a0 context.Context, a1 int64) (*Subscription, error) {
+	if m.GetByUserIDFunc == nil {
+		panic("MockSubscriptionStorer.GetByUserID called but GetByUserIDFunc is not set")
+	}
+	return m.GetByUserIDFunc(a0, a1)
+}
+
+func (m *MockSubscriptionStorer) GetByCustomerID(a0 context.Context, a1 string) (*Subscription, error) {
+	if m.GetByCustomerIDFunc == nil {
+		panic("MockSubscriptionStorer.GetByCustomerID called but GetByCustomerIDFunc is not set")
+	}
+	return m.GetByCustomerIDFunc(a0, a1)
+}
+
+func (m *MockSubscriptionStorer) Update(a0 context.Context, a1 *Subscription) error {
+	if m.UpdateFunc == nil {
+		panic("MockSubscriptionStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+// MockShiftAcknowledgmentStorer is a ShiftAcknowledgmentStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockShiftAcknowledgmentStorer struct {
+	ListUpcomingForEmailFunc func(context.Context, string, DateOnly) ([]*EmployeeShift, error)
+	AcknowledgeFunc          func(context.Context, int64, string) (*ShiftAcknowledgment, error)
+	ListByScheduleFunc       func(context.Context, int64) ([]*ShiftAcknowledgmentStatus, error)
+}
+
+var _ ShiftAcknowledgmentStorer = (*MockShiftAcknowledgmentStorer)(nil)
+
+func (m *MockShiftAcknowledgmentStorer) ListUpcomingForEmail(a0 context.Context, a1 string, a2 DateOnly) ([]*EmployeeShift, error) {
+	if m.ListUpcomingForEmailFunc == nil {
+		panic("MockShiftAcknowledgmentStorer.ListUpcomingForEmail called but ListUpcomingForEmailFunc is not set")
+	}
+	return m.ListUpcomingForEmailFunc(a0, a1, a2)
+}
+
+func (m *MockShiftAcknowledgmentStorer) Acknowledge(a0 context.Context, a1 int64, a2 string) (*ShiftAcknowledgment, error) {
+	if m.AcknowledgeFunc == nil {
+		panic("MockShiftAcknowledgmentStorer.Acknowledge called but AcknowledgeFunc is not set")
+	}
+	return m.AcknowledgeFunc(a0, a1, a2)
+}
+
+func (m *MockShiftAcknowledgmentStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*ShiftAcknowledgmentStatus, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockShiftAcknowledgmentStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+// MockOnboardingStorer is a OnboardingStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockOnboardingStorer struct {
+	ProgressFunc   func(context.Context, int64) (*OnboardingProgress, error)
+	SeedSampleFunc func(context.Context, int64, *SampleData) (*SampleDataResult, error)
+}
+
+var _ OnboardingStorer = (*MockOnboardingStorer)(nil)
+
+func (m *MockOnboardingStorer) Progress(a0 context.Context, a1 int64) (*OnboardingProgress, error) {
+	if m.ProgressFunc == nil {
+		panic("MockOnboardingStorer.Progress called but ProgressFunc is not set")
+	}
+	return m.ProgressFunc(a0, a1)
+}
+
+func (m *MockOnboardingStorer) SeedSample(a0 context.Context, a1 int64, a2 *SampleData) (*SampleDataResult, error) {
+	if m.SeedSampleFunc == nil {
+		panic("MockOnboardingStorer.SeedSample called but SeedSampleFunc is not set")
+	}
+	return m.SeedSampleFunc(a0, a1, a2)
+}
+
+// MockTimeClockStorer is a TimeClockStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockTimeClockStorer struct {
+	CreateKioskFunc        func(context.Context, *Kiosk, string) error
+	ListKiosksFunc         func(context.Context, int64) ([]*Kiosk, error)
+	RevokeKioskFunc        func(context.Context, int64, int64) error
+	AuthenticateKioskFunc  func(context.Context, string) (*Kiosk, error)
+	SetPINFunc             func(context.Context, int64, string) error
+	DeletePINFunc          func(context.Context, int64) error
+	VerifyPINFunc          func(context.Context, int64, string, time.Time) error
+	ListKioskEmployeesFunc func(context.Context, int64) ([]*KioskEmployee, error)
+	ClockInOrOutFunc       func(context.Context, int64, int64, *int64, time.Time) (*TimeEntry, error)
+	ListTimeEntriesFunc    func(context.Context, int64, time.Time, time.Time) ([]*TimeEntry, error)
+}
+
+var _ TimeClockStorer = (*MockTimeClockStorer)(nil)
+
+func (m *MockTimeClockStorer) CreateKiosk(a0 context.Context, a1 *Kiosk, a2 string) error {
+	if m.CreateKioskFunc == nil {
+		panic("MockTimeClockStorer.CreateKiosk called but CreateKioskFunc is not set")
+	}
+	return m.CreateKioskFunc(a0, a1, a2)
+}
+
+func (m *MockTimeClockStorer) ListKiosks(a0 context.Context, a1 int64) ([]*Kiosk, error) {
+	if m.ListKiosksFunc == nil {
+		panic("MockTimeClockStorer.ListKiosks called but ListKiosksFunc is not set")
+	}
+	return m.ListKiosksFunc(a0, a1)
+}
+
+func (m *MockTimeClockStorer) RevokeKiosk(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RevokeKioskFunc == nil {
+		panic("MockTimeClockStorer.RevokeKiosk called but RevokeKioskFunc is not set")
+	}
+	return m.RevokeKioskFunc(a0, a1, a2)
+}
+
+func (m *MockTimeClockStorer) AuthenticateKiosk(a0 context.Context, a1 string) (*Kiosk, error) {
+	if m.AuthenticateKioskFunc == nil {
+		panic("MockTimeClockStorer.AuthenticateKiosk called but AuthenticateKioskFunc is not set")
+	}
+	return m.AuthenticateKioskFunc(a0, a1)
+}
+
+func (m *MockTimeClockStorer) SetPIN(a0 context.Context, a1 int64, a2 string) error {
+	if m.SetPINFunc == nil {
+		panic("MockTimeClockStorer.SetPIN called but SetPINFunc is not set")
+	}
+	return m.SetPINFunc(a0, a1, a2)
+}
+
+func (m *MockTimeClockStorer) DeletePIN(a0 context.Context, a1 int64) error {
+	if m.DeletePINFunc == nil {
+		panic("MockTimeClockStorer.DeletePIN called but DeletePINFunc is not set")
+	}
+	return m.DeletePINFunc(a0, a1)
+}
+
+func (m *MockTimeClockStorer) VerifyPIN(a0 context.Context, a1 int64, a2 string, a3 time.Time) error {
+	if m.VerifyPINFunc == nil {
+		panic("MockTimeClockStorer.VerifyPIN called but VerifyPINFunc is not set")
+	}
+	return m.VerifyPINFunc(a0, a1, a2, a3)
+}
+
+func (m *MockTimeClockStorer) ListKioskEmployees(a0 context.Context, a1 int64) ([]*KioskEmployee, error) {
+	if m.ListKioskEmployeesFunc == nil {
+		panic("MockTimeClockStorer.ListKioskEmployees called but ListKioskEmployeesFunc is not set")
+	}
+	return m.ListKioskEmployeesFunc(a0, a1)
+}
+
+func (m *MockTimeClockStorer) ClockInOrOut(a0 context.Context, a1 int64, a2 int64, a3 *int64, a4 time.Time) (*TimeEntry, error) {
+	if m.ClockInOrOutFunc == nil {
+		panic("MockTimeClockStorer.ClockInOrOut called but ClockInOrOutFunc is not set")
+	}
+	return m.ClockInOrOutFunc(a0, a1, a2, a3, a4)
+}
+
+func (m *MockTimeClockStorer) ListTimeEntries(a0 context.Context, a1 int64, a2 time.Time, a3 time.Time) ([]*TimeEntry, error) {
+	if m.ListTimeEntriesFunc == nil {
+		panic("MockTimeClockStorer.ListTimeEntries called but ListTimeEntriesFunc is not set")
+	}
+	return m.ListTimeEntriesFunc(a0, a1, a2, a3)
+}
+
+// MockMemberStorer is a MemberStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockMemberStorer struct {
+	CreateFunc           func(context.Context, *Member) error
+	UpdateRolesFunc      func(context.Context, *Member) error
+	GetFunc              func(context.Context, int64, int64) (*Member, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*Member, error)
+	ListByUserFunc       func(context.Context, int64) ([]*Membership, error)
+	DeleteFunc           func(context.Context, int64, int64) error
+}
+
+var _ MemberStorer = (*MockMemberStorer)(nil)
+
+func (m *MockMemberStorer) Create(a0 context.Context, a1 *Member) error {
+	if m.CreateFunc == nil {
+		panic("MockMemberStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockMemberStorer) UpdateRoles(a0 context.Context, a1 *Member) error {
+	if m.UpdateRolesFunc == nil {
+		panic("MockMemberStorer.UpdateRoles called but UpdateRolesFunc is not set")
+	}
+	return m.UpdateRolesFunc(a0, a1)
+}
+
+func (m *MockMemberStorer) Get(a0 context.Context, a1 int64, a2 int64) (*Member, error) {
+	if m.GetFunc == nil {
+		panic("MockMemberStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1, a2)
+}
+
+func (m *MockMemberStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*Member, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockMemberStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockMemberStorer) ListByUser(a0 context.Context, a1 int64) ([]*Membership, error) {
+	if m.ListByUserFunc == nil {
+		panic("MockMemberStorer.ListByUser called but ListByUserFunc is not set")
+	}
+	return m.ListByUserFunc(a0, a1)
+}
+
+func (m *MockMemberStorer) Delete(a0 context.Context, a1 int64, a2 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockMemberStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1, a2)
+}
+
+// MockEmailDeliveryStorer is a EmailDeliveryStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEmailDeliveryStorer struct {
+	CreateFunc         func(context.Context, *EmailDelivery) error
+	MarkFailedFunc     func(context.Context, int64, string) error
+	RecordEventFunc    func(context.Context, *DeliveryEvent) error
+	ListByScheduleFunc func(context.Context, int64) ([]*EmailDelivery, error)
+	CreateBlastFunc    func(context.Context, *EmailBlast) error
+	ListBlastsFunc     func(context.Context, int64) ([]*EmailBlast, error)
+	LatestBlastFunc    func(context.Context, int64) (*EmailBlast, error)
+}
+
+var _ EmailDeliveryStorer = (*MockEmailDeliveryStorer)(nil)
+
+func (m *MockEmailDeliveryStorer) Create(a0 context.Context, a1 *EmailDelivery) error {
+	if m.CreateFunc == nil {
+		panic("MockEmailDeliveryStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockEmailDeliveryStorer) MarkFailed(a0 context.Context, a1 int64, a2 string) error {
+	if m.MarkFailedFunc == nil {
+		panic("MockEmailDeliveryStorer.MarkFailed called but MarkFailedFunc is not set")
+	}
+	return m.MarkFailedFunc(a0, a1, a2)
+}
+
+func (m *MockEmailDeliveryStorer) RecordEvent(a0 context.Context, a1 *DeliveryEvent) error {
+	if m.RecordEventFunc == nil {
+		panic("MockEmailDeliveryStorer.RecordEvent called but RecordEventFunc is not set")
+	}
+	return m.RecordEventFunc(a0, a1)
+}
+
+func (m *MockEmailDeliveryStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*EmailDelivery, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockEmailDeliveryStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+func (m *MockEmailDeliveryStorer) CreateBlast(a0 context.Context, a1 *EmailBlast) error {
+	if m.CreateBlastFunc == nil {
+		panic("MockEmailDeliveryStorer.CreateBlast called but CreateBlastFunc is not set")
+	}
+	return m.CreateBlastFunc(a0, a1)
+}
+
+func (m *MockEmailDeliveryStorer) ListBlasts(a0 context.Context, a1 int64) ([]*EmailBlast, error) {
+	if m.ListBlastsFunc == nil {
+		panic("MockEmailDeliveryStorer.ListBlasts called but ListBlastsFunc is not set")
+	}
+	return m.ListBlastsFunc(a0, a1)
+}
+
+func (m *MockEmailDeliveryStorer) LatestBlast(a0 context.Context, a1 int64) (*EmailBlast, error) {
+	if m.LatestBlastFunc == nil {
+		panic("MockEmailDeliveryStorer.LatestBlast called but LatestBlastFunc is not set")
+	}
+	return m.LatestBlastFunc(a0, a1)
+}
+
+// MockShareLinkStorer is a ShareLinkStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockShareLinkStorer struct {
+	CreateFunc         func(context.Context, *ShareLink, string) error
+	ListByScheduleFunc func(context.Context, int64) ([]*ShareLink, error)
+	SetExpiryFunc      func(context.Context, int64, int64, time.Time) (*ShareLink, error)
+	RevokeFunc         func(context.Context, int64, int64) error
+	AuthenticateFunc   func(context.Context, string, time.Time) (*ShareLink, error)
+}
+
+var _ ShareLinkStorer = (*MockShareLinkStorer)(nil)
+
+func (m *MockShareLinkStorer) Create(a0 context.Context, a1 *ShareLink, a2 string) error {
+	if m.CreateFunc == nil {
+		panic("MockShareLinkStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1, a2)
+}
+
+func (m *MockShareLinkStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*ShareLink, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockShareLinkStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+func (m *MockShareLinkStorer) SetExpiry(a0 context.Context, a1 int64, a2 int64, a3 time.Time) (*ShareLink, error) {
+	if m.SetExpiryFunc == nil {
+		panic("MockShareLinkStorer.SetExpiry called but SetExpiryFunc is not set")
+	}
+	return m.SetExpiryFunc(a0, a1, a2, a3)
+}
+
+func (m *MockShareLinkStorer) Revoke(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RevokeFunc == nil {
+		panic("MockShareLinkStorer.Revoke called but RevokeFunc is not set")
+	}
+	return m.RevokeFunc(a0, a1, a2)
+}
+
+func (m *MockShareLinkStorer) Authenticate(a0 context.Context, a1 string, a2 time.Time) (*ShareLink, error) {
+	if m.AuthenticateFunc == nil {
+		panic("MockShareLinkStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1, a2)
+}
+
+// MockTwoFactorStorer is a TwoFactorStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockTwoFactorStorer struct {
+	GetFunc                  func(context.Context, int64) (*TwoFactor, error)
+	EnrollFunc               func(context.Context, int64, string) error
+	ConfirmFunc              func(context.Context, int64, int64, []string) error
+	SetEnabledFunc           func(context.Context, int64, bool) error
+	UseTOTPStepFunc          func(context.Context, int64, int64) error
+	UseRecoveryCodeFunc      func(context.Context, int64, string) error
+	ReplaceRecoveryCodesFunc func(context.Context, int64, []string) error
+	CreateChallengeFunc      func(context.Context, *LoginChallenge, string) error
+	GetChallengeFunc         func(context.Context, string, time.Time) (*LoginChallenge, error)
+	FailChallengeFunc        func(context.Context, int64) error
+	DeleteChallengeFunc      func(context.Context, int64) error
+}
+
+var _ TwoFactorStorer = (*MockTwoFactorStorer)(nil)
+
+func (m *MockTwoFactorStorer) Get(a0 context.Context, a1 int64) (*TwoFactor, error) {
+	if m.GetFunc == nil {
+		panic("MockTwoFactorStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockTwoFactorStorer) Enroll(a0 context.Context, a1 int64, a2 string) error {
+	if m.EnrollFunc == nil {
+		panic("MockTwoFactorStorer.Enroll called but EnrollFunc is not set")
+	}
+	return m.EnrollFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) Confirm(a0 context.Context, a1 int64, a2 int64, a3 []string) error {
+	if m.ConfirmFunc == nil {
+		panic("MockTwoFactorStorer.Confirm called but ConfirmFunc is not set")
+	}
+	return m.ConfirmFunc(a0, a1, a2, a3)
+}
+
+func (m *MockTwoFactorStorer) SetEnabled(a0 context.Context, a1 int64, a2 bool) error {
+	if m.SetEnabledFunc == nil {
+		panic("MockTwoFactorStorer.SetEnabled called but SetEnabledFunc is not set")
+	}
+	return m.SetEnabledFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) UseTOTPStep(a0 context.Context, a1 int64, a2 int64) error {
+	if m.UseTOTPStepFunc == nil {
+		panic("MockTwoFactorStorer.UseTOTPStep called but UseTOTPStepFunc is not set")
+	}
+	return m.UseTOTPStepFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) UseRecoveryCode(a0 context.Context, a1 int64, a2 string) error {
+	if m.UseRecoveryCodeFunc == nil {
+		panic("MockTwoFactorStorer.UseRecoveryCode called but UseRecoveryCodeFunc is not set")
+	}
+	return m.UseRecoveryCodeFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) ReplaceRecoveryCodes(a0 context.Context, a1 int64, a2 []string) error {
+	if m.ReplaceRecoveryCodesFunc == nil {
+		panic("MockTwoFactorStorer.ReplaceRecoveryCodes called but ReplaceRecoveryCodesFunc is not set")
+	}
+	return m.ReplaceRecoveryCodesFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) CreateChallenge(a0 context.Context, a1 *LoginChallenge, a2 string) error {
+	if m.CreateChallengeFunc == nil {
+		panic("MockTwoFactorStorer.CreateChallenge called but CreateChallengeFunc is not set")
+	}
+	return m.CreateChallengeFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) GetChallenge(a0 context.Context, a1 string, a2 time.Time) (*LoginChallenge, error) {
+	if m.GetChallengeFunc == nil {
+		panic("MockTwoFactorStorer.GetChallenge called but GetChallengeFunc is not set")
+	}
+	return m.GetChallengeFunc(a0, a1, a2)
+}
+
+func (m *MockTwoFactorStorer) FailChallenge(a0 context.Context, a1 int64) error {
+	if m.FailChallengeFunc == nil {
+		panic("MockTwoFactorStorer.FailChallenge called but FailChallengeFunc is not set")
+	}
+	return m.FailChallengeFunc(a0, a1)
+}
+
+func (m *MockTwoFactorStorer) DeleteChallenge(a0 context.Context, a1 int64) error {
+	if m.DeleteChallengeFunc == nil {
+		panic("MockTwoFactorStorer.DeleteChallenge called but DeleteChallengeFunc is not set")
+	}
+	return m.DeleteChallengeFunc(a0, a1)
+}
+
+// MockSessionStorer is a SessionStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSessionStorer struct {
+	CreateFunc       func(context.Context, *Session) error
+	AuthenticateFunc func(context.Context, int64, int64) error
+	ExtendFunc       func(context.Context, int64, int64, time.Time) error
+	ListActiveFunc   func(context.Context, int64) ([]*Session, error)
+	RevokeFunc       func(context.Context, int64, int64) error
+}
+
+var _ SessionStorer = (*MockSessionStorer)(nil)
+
+func (m *MockSessionStorer) Create(a0 context.Context, a1 *Session) error {
+	if m.CreateFunc == nil {
+		panic("MockSessionStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockSessionStorer) Authenticate(a0 context.Context, a1 int64, a2 int64) error {
+	if m.AuthenticateFunc == nil {
+		panic("MockSessionStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1, a2)
+}
+
+func (m *MockSessionStorer) Extend(a0 context.Context, a1 int64, a2 int64, a3 time.Time) error {
+	if m.ExtendFunc == nil {
+		panic("MockSessionStorer.Extend called but ExtendFunc is not set")
+	}
+	return m.ExtendFunc(a0, a1, a2, a3)
+}
+
+func (m *MockSessionStorer) ListActive(a0 context.Context, a1 int64) ([]*Session, error) {
+	if m.ListActiveFunc == nil {
+		panic("MockSessionStorer.ListActive called but ListActiveFunc is not set")
+	}
+	return m.ListActiveFunc(a0, a1)
+}
+
+func (m *MockSessionStorer) Revoke(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RevokeFunc == nil {
+		panic("MockSessionStorer.Revoke called but RevokeFunc is not set")
+	}
+	return m.RevokeFunc(a0, a1, a2)
+}
+
+// MockSecurityEventStorer is a SecurityEventStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSecurityEventStorer struct {
+	RecordFunc     func(context.Context, *SecurityEvent) error
+	ListByUserFunc func(context.Context, int64, int) ([]*SecurityEvent, error)
+}
+
+var _ SecurityEventStorer = (*MockSecurityEventStorer)(nil)
+
+func (m *MockSecurityEventStorer) Record(a0 context.Context, a1 *SecurityEvent) error {
+	if m.RecordFunc == nil {
+		panic("MockSecurityEventStorer.Record called but RecordFunc is not set")
+	}
+	return m.RecordFunc(a0, a1)
+}
+
+func (m *MockSecurityEventStorer) ListByUser(a0 context.Context, a1 int64, a2 int) ([]*SecurityEvent, error) {
+	if m.ListByUserFunc == nil {
+		panic("MockSecurityEventStorer.ListByUser called but ListByUserFunc is not set")
+	}
+	return m.ListByUserFunc(a0, a1, a2)
+}
+
+// MockSyncStorer is a SyncStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSyncStorer struct {
+	ChangesFunc func(context.Context, int64, *time.Time, int) (*SyncChanges, error)
+}
+
+var _ SyncStorer = (*MockSyncStorer)(nil)
+
+func (m *MockSyncStorer) Changes(a0 context.Context, a1 int64, a2 *time.Time, a3 int) (*SyncChanges, error) {
+	if m.ChangesFunc == nil {
+		panic("MockSyncStorer.Changes called but ChangesFunc is not set")
+	}
+	return m.ChangesFunc(a0, a1, a2, a3)
+}
+
+// MockSalesStorer is a SalesStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSalesStorer struct {
+	ImportFunc              func(context.Context, []*SalesRecord) error
+	ListFunc                func(context.Context, int64, DateOnly, DateOnly) ([]*SalesRecord, error)
+	DemandByDayFunc         func(context.Context, int64, DateOnly, DateOnly) ([]*DemandDay, error)
+	SetWebhookTokenFunc     func(context.Context, int64, string) error
+	DeleteWebhookTokenFunc  func(context.Context, int64) error
+	AuthenticateWebhookFunc func(context.Context, string) (int64, error)
+}
+
+var _ SalesStorer = (*MockSalesStorer)(nil)
+
+func (m *MockSalesStorer) Import(a0 context.Context, a1 []*SalesRecord) error {
+	if m.ImportFunc == nil {
+		panic("MockSalesStorer.Import called but ImportFunc is not set")
+	}
+	return m.ImportFunc(a0, a1)
+}
+
+func (m *MockSalesStorer) List(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*SalesRecord, error) {
+	if m.ListFunc == nil {
+		panic("MockSalesStorer.List called but ListFunc is not set")
+	}
+	return m.ListFunc(a0, a1, a2, a3)
+}
+
+func (m *MockSalesStorer) DemandByDay(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*DemandDay, error) {
+	if m.DemandByDayFunc == nil {
+		panic("MockSalesStorer.DemandByDay called but DemandByDayFunc is not set")
+	}
+	return m.DemandByDayFunc(a0, a1, a2, a3)
+}
+
+func (m *MockSalesStorer) SetWebhookToken(a0 context.Context, a1 int64, a2 string) error {
+	if m.SetWebhookTokenFunc == nil {
+		panic("MockSalesStorer.SetWebhookToken called but SetWebhookTokenFunc is not set")
+	}
+	return m.SetWebhookTokenFunc(a0, a1, a2)
+}
+
+func (m *MockSalesStorer) DeleteWebhookToken(a0 context.Context, a1 int64) error {
+	if m.DeleteWebhookTokenFunc == nil {
+		panic("MockSalesStorer.DeleteWebhookToken called but DeleteWebhookTokenFunc is not set")
+	}
+	return m.DeleteWebhookTokenFunc(a0, a1)
+}
+
+func (m *MockSalesStorer) AuthenticateWebhook(a0 context.Context, a1 string) (int64, error) {
+	if m.AuthenticateWebhookFunc == nil {
+		panic("MockSalesStorer.AuthenticateWebhook called but AuthenticateWebhookFunc is not set")
+	}
+	return m.AuthenticateWebhookFunc(a0, a1)
+}
+
+// MockMessageStorer is a MessageStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockMessageStorer struct {
+	CreateFunc           func(context.Context, *Message) error
+	GetByIDFunc          func(context.Context, int64) (*Message, error)
+	ListByRestaurantFunc func(context.Context, int64, int64, int) ([]*Message, error)
+	CancelFunc           func(context.Context, int64) error
+	ClaimDueFunc         func(context.Context, time.Time) ([]*Message, error)
+	CompleteFunc         func(context.Context, *Message) error
+	RecipientsFunc       func(context.Context, *Message) ([]*Employee, error)
+}
+
+var _ MessageStorer = (*MockMessageStorer)(nil)
+
+func (m *MockMessageStorer) Create(a0 context.Context, a1 *Message) error {
+	if m.CreateFunc == nil {
+		panic("MockMessageStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockMessageStorer) GetByID(a0 context.Context, a1 int64) (*Message, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockMessageStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockMessageStorer) ListByRestaurant(a0 context.Context, a1 int64, a2 int64, a3 int) ([]*Message, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockMessageStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1, a2, a3)
+}
+
+func (m *MockMessageStorer) Cancel(a0 context.Context, a1 int64) error {
+	if m.CancelFunc == nil {
+		panic("MockMessageStorer.Cancel called but CancelFunc is not set")
+	}
+	return m.CancelFunc(a0, a1)
+}
+
+func (m *MockMessageStorer) ClaimDue(a0 context.Context, a1 time.Time) ([]*Message, error) {
+	if m.ClaimDueFunc == nil {
+		panic("MockMessageStorer.ClaimDue called but ClaimDueFunc is not set")
+	}
+	return m.ClaimDueFunc(a0, a1)
+}
+
+func (m *MockMessageStorer) Complete(a0 context.Context, a1 *Message) error {
+	if m.CompleteFunc == nil {
+		panic("MockMessageStorer.Complete called but CompleteFunc is not set")
+	}
+	return m.CompleteFunc(a0, a1)
+}
+
+func (m *MockMessageStorer) Recipients(a0 context.Context, a1 *Message) ([]*Employee, error) {
+	if m.RecipientsFunc == nil {
+		panic("MockMessageStorer.Recipients called but RecipientsFunc is not set")
+	}
+	return m.RecipientsFunc(a0, a1)
+}
+
+// MockShiftFeedbackStorer is a ShiftFeedbackStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockShiftFeedbackStorer struct {
+	SubmitFunc           func(context.Context, *ShiftFeedback, string, time.Time) error
+	ListByRestaurantFunc func(context.Context, int64, DateOnly, DateOnly) ([]*ShiftFeedbackEntry, error)
+}
+
+var _ ShiftFeedbackStorer = (*MockShiftFeedbackStorer)(nil)
+
+func (m *MockShiftFeedbackStorer) Submit(a0 context.Context, a1 *ShiftFeedback, a2 string, a3 time.Time) error {
+	if m.SubmitFunc == nil {
+		panic("MockShiftFeedbackStorer.Submit called but SubmitFunc is not set")
+	}
+	return m.SubmitFunc(a0, a1, a2, a3)
+}
+
+func (m *MockShiftFeedbackStorer) ListByRestaurant(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*ShiftFeedbackEntry, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockShiftFeedbackStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1, a2, a3)
+}
+
+// MockComplianceStorer is a ComplianceStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockComplianceStorer struct {
+	GetFunc     func(context.Context, int64) (*ComplianceRules, error)
+	ReplaceFunc func(context.Context, *ComplianceRules) error
+}
+
+var _ ComplianceStorer = (*MockComplianceStorer)(nil)
+
+func (m *MockComplianceStorer) Get(a0 context.Context, a1 int64) (*ComplianceRules, error) {
+	if m.GetFunc == nil {
+		panic("MockComplianceStorer.Get called but GetFunc is not set")
+	}
+	return m.GetFunc(a0, a1)
+}
+
+func (m *MockComplianceStorer) Replace(a0 context.Context, a1 *ComplianceRules) error {
+	if m.ReplaceFunc == nil {
+		panic("MockComplianceStorer.Replace called but ReplaceFunc is not set")
+	}
+	return m.ReplaceFunc(a0, a1)
+}
+
+// MockLeaveStorer is a LeaveStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockLeaveStorer struct {
+	GetPolicyFunc               func(context.Context, int64) (*LeavePolicy, error)
+	ReplacePolicyFunc           func(context.Context, *LeavePolicy) error
+	ListAccruingRestaurantsFunc func(context.Context) ([]int64, error)
+	AccrueThroughFunc           func(context.Context, int64, time.Time) ([]*LeaveEntry, error)
+	AddEntryFunc                func(context.Context, *LeaveEntry) error
+	ListEntriesFunc             func(context.Context, int64) ([]*LeaveEntry, error)
+	ListPaidBetweenFunc         func(context.Context, int64, DateOnly, DateOnly) ([]*LeaveEntry, error)
+	ListBalancesFunc            func(context.Context, int64, DateOnly, DateOnly) ([]*LeaveBalance, error)
+}
+
+var _ LeaveStorer = (*MockLeaveStorer)(nil)
+
+func (m *MockLeaveStorer) GetPolicy(a0 context.Context, a1 int64) (*LeavePolicy, error) {
+	if m.GetPolicyFunc == nil {
+		panic("MockLeaveStorer.GetPolicy called but GetPolicyFunc is not set")
+	}
+	return m.GetPolicyFunc(a0, a1)
+}
+
+func (m *MockLeaveStorer) ReplacePolicy(a0 context.Context, a1 *LeavePolicy) error {
+	if m.ReplacePolicyFunc == nil {
+		panic("MockLeaveStorer.ReplacePolicy called but ReplacePolicyFunc is not set")
+	}
+	return m.ReplacePolicyFunc(a0, a1)
+}
+
+func (m *MockLeaveStorer) ListAccruingRestaurants(a0 context.Context) ([]int64, error) {
+	if m.ListAccruingRestaurantsFunc == nil {
+		panic("MockLeaveStorer.ListAccruingRestaurants called but ListAccruingRestaurantsFunc is not set")
+	}
+	return m.ListAccruingRestaurantsFunc(a0)
+}
+
+func (m *MockLeaveStorer) AccrueThrough(a0 context.Context, a1 int64, a2 time.Time) ([]*LeaveEntry, error) {
+	if m.AccrueThroughFunc == nil {
+		panic("MockLeaveStorer.AccrueThrough called but AccrueThroughFunc is not set")
+	}
+	return m.AccrueThroughFunc(a0, a1, a2)
+}
+
+func (m *MockLeaveStorer) AddEntry(a0 context.Context, a1 *LeaveEntry) error {
+	if m.AddEntryFunc == nil {
+		panic("MockLeaveStorer.AddEntry called but AddEntryFunc is not set")
+	}
+	return m.AddEntryFunc(a0, a1)
+}
+
+func (m *MockLeaveStorer) ListEntries(a0 context.Context, a1 int64) ([]*LeaveEntry, error) {
+	if m.ListEntriesFunc == nil {
+		panic("MockLeaveStorer.ListEntries called but ListEntriesFunc is not set")
+	}
+	return m.ListEntriesFunc(a0, a1)
+}
+
+func (m *MockLeaveStorer) ListPaidBetween(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*LeaveEntry, error) {
+	if m.ListPaidBetweenFunc == nil {
+		panic("MockLeaveStorer.ListPaidBetween called but ListPaidBetweenFunc is not set")
+	}
+	return m.ListPaidBetweenFunc(a0, a1, a2, a3)
+}
+
+func (m *MockLeaveStorer) ListBalances(a0 context.Context, a1 int64, a2 DateOnly, a3 DateOnly) ([]*LeaveBalance, error) {
+	if m.ListBalancesFunc == nil {
+		panic("MockLeaveStorer.ListBalances called but ListBalancesFunc is not set")
+	}
+	return m.ListBalancesFunc(a0, a1, a2, a3)
+}
+
+// MockBidStorer is a BidStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockBidStorer struct {
+	CreateFunc               func(context.Context, *BidRound) error
+	GetByIDFunc              func(context.Context, int64) (*BidRound, error)
+	ListByScheduleFunc       func(context.Context, int64) ([]*BidRound, error)
+	OpenShiftIDsFunc         func(context.Context, int64) ([]int64, error)
+	ListPreferencesFunc      func(context.Context, int64) ([]*BidPreference, error)
+	ListBiddableForEmailFunc func(context.Context, string, time.Time) ([]*BiddableShift, error)
+	SubmitPreferencesFunc    func(context.Context, int64, string, []int64, time.Time) ([]*BidPreference, error)
+	ClaimAllocationFunc      func(context.Context, int64, time.Time) error
+	RecordAwardsFunc         func(context.Context, int64, []*BidRoundShift) error
+}
+
+var _ BidStorer = (*MockBidStorer)(nil)
+
+func (m *MockBidStorer) Create(a0 context.Context, a1 *BidRound) error {
+	if m.CreateFunc == nil {
+		panic("MockBidStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockBidStorer) GetByID(a0 context.Context, a1 int64) (*BidRound, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockBidStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockBidStorer) ListBySchedule(a0 context.Context, a1 int64) ([]*BidRound, error) {
+	if m.ListByScheduleFunc == nil {
+		panic("MockBidStorer.ListBySchedule called but ListByScheduleFunc is not set")
+	}
+	return m.ListByScheduleFunc(a0, a1)
+}
+
+func (m *MockBidStorer) OpenShiftIDs(a0 context.Context, a1 int64) ([]int64, error) {
+	if m.OpenShiftIDsFunc == nil {
+		panic("MockBidStorer.OpenShiftIDs called but OpenShiftIDsFunc is not set")
+	}
+	return m.OpenShiftIDsFunc(a0, a1)
+}
+
+func (m *MockBidStorer) ListPreferences(a0 context.Context, a1 int64) ([]*BidPreference, error) {
+	if m.ListPreferencesFunc == nil {
+		panic("MockBidStorer.ListPreferences called but ListPreferencesFunc is not set")
+	}
+	return m.ListPreferencesFunc(a0, a1)
+}
+
+func (m *MockBidStorer) ListBiddableForEmail(a0 context.Context, a1 string, a2 time.Time) ([]*BiddableShift, error) {
+	if m.ListBiddableForEmailFunc == nil {
+		panic("MockBidStorer.ListBiddableForEmail called but ListBiddableForEmailFunc is not set")
+	}
+	return m.ListBiddableForEmailFunc(a0, a1, a2)
+}
+
+func (m *MockBidStorer) SubmitPreferences(a0 context.Context, a1 int64, a2 string, a3 []int64, a4 time.Time) ([]*BidPreference, error) {
+	if m.SubmitPreferencesFunc == nil {
+		panic("MockBidStorer.SubmitPreferences called but SubmitPreferencesFunc is not set")
+	}
+	return m.SubmitPreferencesFunc(a0, a1, a2, a3, a4)
+}
+
+func (m *MockBidStorer) ClaimAllocation(a0 context.Context, a1 int64, a2 time.Time) error {
+	if m.ClaimAllocationFunc == nil {
+		panic("MockBidStorer.ClaimAllocation called but ClaimAllocationFunc is not set")
+	}
+	return m.ClaimAllocationFunc(a0, a1, a2)
+}
+
+func (m *MockBidStorer) RecordAwards(a0 context.Context, a1 int64, a2 []*BidRoundShift) error {
+	if m.RecordAwardsFunc == nil {
+		panic("MockBidStorer.RecordAwards called but RecordAwardsFunc is not set")
+	}
+	return m.RecordAwardsFunc(a0, a1, a2)
+}
+
+// MockWebhookStorer is a WebhookStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockWebhookStorer struct {
+	CreateEndpointFunc func(context.Context, *WebhookEndpoint) error
+	ListEndpointsFunc  func(context.Context, int64) ([]*WebhookEndpoint, error)
+	DeleteEndpointFunc func(context.Context, int64, int64) error
+	EnqueueFunc        func(context.Context, *WebhookEvent) (int, error)
+	ClaimDueFunc       func(context.Context, time.Time, time.Duration, int) ([]*WebhookDelivery, error)
+	RecordAttemptFunc  func(context.Context, int64, int, string, *time.Time) error
+	ListDeliveriesFunc func(context.Context, int64, int) ([]*WebhookDelivery, error)
+}
+
+var _ WebhookStorer = (*MockWebhookStorer)(nil)
+
+func (m *MockWebhookStorer) CreateEndpoint(a0 context.Context, a1 *WebhookEndpoint) error {
+	if m.CreateEndpointFunc == nil {
+		panic("MockWebhookStorer.CreateEndpoint called but CreateEndpointFunc is not set")
+	}
+	return m.CreateEndpointFunc(a0, a1)
+}
+
+func (m *MockWebhookStorer) ListEndpoints(a0 context.Context, a1 int64) ([]*WebhookEndpoint, error) {
+	if m.ListEndpointsFunc == nil {
+		panic("MockWebhookStorer.ListEndpoints called but ListEndpointsFunc is not set")
+	}
+	return m.ListEndpointsFunc(a0, a1)
+}
+
+func (m *MockWebhookStorer) DeleteEndpoint(a0 context.Context, a1 int64, a2 int64) error {
+	if m.DeleteEndpointFunc == nil {
+		panic("MockWebhookStorer.DeleteEndpoint called but DeleteEndpointFunc is not set")
+	}
+	return m.DeleteEndpointFunc(a0, a1, a2)
+}
+
+func (m *MockWebhookStorer) Enqueue(a0 context.Context, a1 *WebhookEvent) (int, error) {
+	if m.EnqueueFunc == nil {
+		panic("MockWebhookStorer.Enqueue called but EnqueueFunc is not set")
+	}
+	return m.EnqueueFunc(a0, a1)
+}
+
+func (m *MockWebhookStorer) ClaimDue(a0 context.Context, a1 time.Time, a2 time.Duration, a3 int) ([]*WebhookDelivery, error) {
+	if m.ClaimDueFunc == nil {
+		panic("MockWebhookStorer.ClaimDue called but ClaimDueFunc is not set")
+	}
+	return m.ClaimDueFunc(a0, a1, a2, a3)
+}
+
+func (m *MockWebhookStorer) RecordAttempt(a0 context.Context, a1 int64, a2 int, a3 string, a4 *time.Time) error {
+	if m.RecordAttemptFunc == nil {
+		panic("MockWebhookStorer.RecordAttempt called but RecordAttemptFunc is not set")
+	}
+	return m.RecordAttemptFunc(a0, a1, a2, a3, a4)
+}
+
+func (m *MockWebhookStorer) ListDeliveries(a0 context.Context, a1 int64, a2 int) ([]*WebhookDelivery, error) {
+	if m.ListDeliveriesFunc == nil {
+		panic("MockWebhookStorer.ListDeliveries called but ListDeliveriesFunc is not set")
+	}
+	return m.ListDeliveriesFunc(a0, a1, a2)
+}
+
+// MockDisplayBoardStorer is a DisplayBoardStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockDisplayBoardStorer struct {
+	CreateFunc       func(context.Context, *DisplayBoard, string) error
+	ListFunc         func(context.Context, int64) ([]*DisplayBoard, error)
+	RevokeFunc       func(context.Context, int64, int64) error
+	AuthenticateFunc func(context.Context, string) (*DisplayBoard, error)
+	ListShiftsFunc   func(context.Context, int64, DateOnly) ([]*BoardShift, error)
+}
+
+var _ DisplayBoardStorer = (*MockDisplayBoardStorer)(nil)
+
+func (m *MockDisplayBoardStorer) Create(a0 context.Context, a1 *DisplayBoard, a2 string) error {
+	if m.CreateFunc == nil {
+		panic("MockDisplayBoardStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1, a2)
+}
+
+func (m *MockDisplayBoardStorer) List(a0 context.Context, a1 int64) ([]*DisplayBoard, error) {
+	if m.ListFunc == nil {
+		panic("MockDisplayBoardStorer.List called but ListFunc is not set")
+	}
+	return m.ListFunc(a0, a1)
+}
+
+func (m *MockDisplayBoardStorer) Revoke(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RevokeFunc == nil {
+		panic("MockDisplayBoardStorer.Revoke called but RevokeFunc is not set")
+	}
+	return m.RevokeFunc(a0, a1, a2)
+}
+
+func (m *MockDisplayBoardStorer) Authenticate(a0 context.Context, a1 string) (*DisplayBoard, error) {
+	if m.AuthenticateFunc == nil {
+		panic("MockDisplayBoardStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1)
+}
+
+func (m *MockDisplayBoardStorer) ListShifts(a0 context.Context, a1 int64, a2 DateOnly) ([]*BoardShift, error) {
+	if m.ListShiftsFunc == nil {
+		panic("MockDisplayBoardStorer.ListShifts called but ListShiftsFunc is not set")
+	}
+	return m.ListShiftsFunc(a0, a1, a2)
+}
+
+// MockSavedReportStorer is a SavedReportStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockSavedReportStorer struct {
+	CreateFunc           func(context.Context, *SavedReport) error
+	GetByIDFunc          func(context.Context, int64) (*SavedReport, error)
+	ListByRestaurantFunc func(context.Context, int64) ([]*SavedReport, error)
+	UpdateFunc           func(context.Context, *SavedReport) error
+	DeleteFunc           func(context.Context, int64) error
+	ClaimDueFunc         func(context.Context, DateOnly) ([]*SavedReport, error)
+}
+
+var _ SavedReportStorer = (*MockSavedReportStorer)(nil)
+
+func (m *MockSavedReportStorer) Create(a0 context.Context, a1 *SavedReport) error {
+	if m.CreateFunc == nil {
+		panic("MockSavedReportStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1)
+}
+
+func (m *MockSavedReportStorer) GetByID(a0 context.Context, a1 int64) (*SavedReport, error) {
+	if m.GetByIDFunc == nil {
+		panic("MockSavedReportStorer.GetByID called but GetByIDFunc is not set")
+	}
+	return m.GetByIDFunc(a0, a1)
+}
+
+func (m *MockSavedReportStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*SavedReport, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockSavedReportStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockSavedReportStorer) Update(a0 context.Context, a1 *SavedReport) error {
+	if m.UpdateFunc == nil {
+		panic("MockSavedReportStorer.Update called but UpdateFunc is not set")
+	}
+	return m.UpdateFunc(a0, a1)
+}
+
+func (m *MockSavedReportStorer) Delete(a0 context.Context, a1 int64) error {
+	if m.DeleteFunc == nil {
+		panic("MockSavedReportStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1)
+}
+
+func (m *MockSavedReportStorer) ClaimDue(a0 context.Context, a1 DateOnly) ([]*SavedReport, error) {
+	if m.ClaimDueFunc == nil {
+		panic("MockSavedReportStorer.ClaimDue called but ClaimDueFunc is not set")
+	}
+	return m.ClaimDueFunc(a0, a1)
+}
+
+// MockEventBadgeStorer is a EventBadgeStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockEventBadgeStorer struct {
+	IssueFunc         func(context.Context, *EventBadge, string) error
+	AuthenticateFunc  func(context.Context, int64, string) (*EventBadge, error)
+	RecordCheckInFunc func(context.Context, *EventCheckIn) (bool, error)
+	ListCheckInsFunc  func(context.Context, int64) ([]*EventCheckIn, error)
+}
+
+var _ EventBadgeStorer = (*MockEventBadgeStorer)(nil)
+
+func (m *MockEventBadgeStorer) Issue(a0 context.Context, a1 *EventBadge, a2 string) error {
+	if m.IssueFunc == nil {
+		panic("MockEventBadgeStorer.Issue called but IssueFunc is not set")
+	}
+	return m.IssueFunc(a0, a1, a2)
+}
+
+func (m *MockEventBadgeStorer) Authenticate(a0 context.Context, a1 int64, a2 string) (*EventBadge, error) {
+	if m.AuthenticateFunc == nil {
+		panic("MockEventBadgeStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1, a2)
+}
+
+func (m *MockEventBadgeStorer) RecordCheckIn(a0 context.Context, a1 *EventCheckIn) (bool, error) {
+	if m.RecordCheckInFunc == nil {
+		panic("MockEventBadgeStorer.RecordCheckIn called but RecordCheckInFunc is not set")
+	}
+	return m.RecordCheckInFunc(a0, a1)
+}
+
+func (m *MockEventBadgeStorer) ListCheckIns(a0 context.Context, a1 int64) ([]*EventCheckIn, error) {
+	if m.ListCheckInsFunc == nil {
+		panic("MockEventBadgeStorer.ListCheckIns called but ListCheckInsFunc is not set")
+	}
+	return m.ListCheckInsFunc(a0, a1)
+}
+
+// MockNotificationDigestStorer is a NotificationDigestStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockNotificationDigestStorer struct {
+	EnqueueFunc  func(context.Context, []*DigestItem) error
+	ClaimDueFunc func(context.Context, time.Time) ([]*EmployeeDigest, error)
+}
+
+var _ NotificationDigestStorer = (*MockNotificationDigestStorer)(nil)
+
+func (m *MockNotificationDigestStorer) Enqueue(a0 context.Context, a1 []*DigestItem) error {
+	if m.EnqueueFunc == nil {
+		panic("MockNotificationDigestStorer.Enqueue called but EnqueueFunc is not set")
+	}
+	return m.EnqueueFunc(a0, a1)
+}
+
+func (m *MockNotificationDigestStorer) ClaimDue(a0 context.Context, a1 time.Time) ([]*EmployeeDigest, error) {
+	if m.ClaimDueFunc == nil {
+		panic("MockNotificationDigestStorer.ClaimDue called but ClaimDueFunc is not set")
+	}
+	return m.ClaimDueFunc(a0, a1)
+}
+
+// MockRetentionStorer is a RetentionStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockRetentionStorer struct {
+	ListByRestaurantFunc        func(context.Context, int64) ([]*RetentionPolicy, error)
+	ListEnabledFunc             func(context.Context) ([]*RetentionPolicy, error)
+	UpsertFunc                  func(context.Context, *RetentionPolicy) error
+	DeleteFunc                  func(context.Context, int64, RetentionKind) error
+	MarkRunFunc                 func(context.Context, *RetentionPolicy, time.Time) error
+	TerminatedBeforeFunc        func(context.Context, int64, DateOnly) ([]*Employee, error)
+	CountTimeEntriesBeforeFunc  func(context.Context, int64, DateOnly) (int64, error)
+	DeleteTimeEntriesBeforeFunc func(context.Context, int64, DateOnly) (int64, error)
+}
+
+var _ RetentionStorer = (*MockRetentionStorer)(nil)
+
+func (m *MockRetentionStorer) ListByRestaurant(a0 context.Context, a1 int64) ([]*RetentionPolicy, error) {
+	if m.ListByRestaurantFunc == nil {
+		panic("MockRetentionStorer.ListByRestaurant called but ListByRestaurantFunc is not set")
+	}
+	return m.ListByRestaurantFunc(a0, a1)
+}
+
+func (m *MockRetentionStorer) ListEnabled(a0 context.Context) ([]*RetentionPolicy, error) {
+	if m.ListEnabledFunc == nil {
+		panic("MockRetentionStorer.ListEnabled called but ListEnabledFunc is not set")
+	}
+	return m.ListEnabledFunc(a0)
+}
+
+func (m *MockRetentionStorer) Upsert(a0 context.Context, a1 *RetentionPolicy) error {
+	if m.UpsertFunc == nil {
+		panic("MockRetentionStorer.Upsert called but UpsertFunc is not set")
+	}
+	return m.UpsertFunc(a0, a1)
+}
+
+func (m *MockRetentionStorer) Delete(a0 context.Context, a1 int64, a2 RetentionKind) error {
+	if m.DeleteFunc == nil {
+		panic("MockRetentionStorer.Delete called but DeleteFunc is not set")
+	}
+	return m.DeleteFunc(a0, a1, a2)
+}
+
+func (m *MockRetentionStorer) MarkRun(a0 context.Context, a1 *RetentionPolicy, a2 time.Time) error {
+	if m.MarkRunFunc == nil {
+		panic("MockRetentionStorer.MarkRun called but MarkRunFunc is not set")
+	}
+	return m.MarkRunFunc(a0, a1, a2)
+}
+
+func (m *MockRetentionStorer) TerminatedBefore(a0 context.Context, a1 int64, a2 DateOnly) ([]*Employee, error) {
+	if m.TerminatedBeforeFunc == nil {
+		panic("MockRetentionStorer.TerminatedBefore called but TerminatedBeforeFunc is not set")
+	}
+	return m.TerminatedBeforeFunc(a0, a1, a2)
+}
+
+func (m *MockRetentionStorer) CountTimeEntriesBefore(a0 context.Context, a1 int64, a2 DateOnly) (int64, error) {
+	if m.CountTimeEntriesBeforeFunc == nil {
+		panic("MockRetentionStorer.CountTimeEntriesBefore called but CountTimeEntriesBeforeFunc is not set")
+	}
+	return m.CountTimeEntriesBeforeFunc(a0, a1, a2)
+}
+
+func (m *MockRetentionStorer) DeleteTimeEntriesBefore(a0 context.Context, a1 int64, a2 DateOnly) (int64, error) {
+	if m.DeleteTimeEntriesBeforeFunc == nil {
+		panic("MockRetentionStorer.DeleteTimeEntriesBefore called but DeleteTimeEntriesBeforeFunc is not set")
+	}
+	return m.DeleteTimeEntriesBeforeFunc(a0, a1, a2)
+}
+
+// MockCalendarFeedStorer is a CalendarFeedStorer whose methods call the matching Func field.
+// Calling a method whose Func is nil panics.
+type MockCalendarFeedStorer struct {
+	CreateFunc       func(context.Context, *CalendarFeed, string) error
+	ListFunc         func(context.Context, int64) ([]*CalendarFeed, error)
+	RevokeFunc       func(context.Context, int64, int64) error
+	AuthenticateFunc func(context.Context, string) (*CalendarFeed, error)
+	ListShiftsFunc   func(context.Context, int64, *int64, DateOnly) ([]*ScheduledShift, error)
+}
+
+var _ CalendarFeedStorer = (*MockCalendarFeedStorer)(nil)
+
+func (m *MockCalendarFeedStorer) Create(a0 context.Context, a1 *CalendarFeed, a2 string) error {
+	if m.CreateFunc == nil {
+		panic("MockCalendarFeedStorer.Create called but CreateFunc is not set")
+	}
+	return m.CreateFunc(a0, a1, a2)
+}
+
+func (m *MockCalendarFeedStorer) List(a0 context.Context, a1 int64) ([]*CalendarFeed, error) {
+	if m.ListFunc == nil {
+		panic("MockCalendarFeedStorer.List called but ListFunc is not set")
+	}
+	return m.ListFunc(a0, a1)
+}
+
+func (m *MockCalendarFeedStorer) Revoke(a0 context.Context, a1 int64, a2 int64) error {
+	if m.RevokeFunc == nil {
+		panic("MockCalendarFeedStorer.Revoke called but RevokeFunc is not set")
+	}
+	return m.RevokeFunc(a0, a1, a2)
+}
+
+func (m *MockCalendarFeedStorer) Authenticate(a0 context.Context, a1 string) (*CalendarFeed, error) {
+	if m.AuthenticateFunc == nil {
+		panic("MockCalendarFeedStorer.Authenticate called but AuthenticateFunc is not set")
+	}
+	return m.AuthenticateFunc(a0, a1)
+}
+
+func (m *MockCalendarFeedStorer) ListShifts(a0 context.Context, a1 int64, a2 *int64, a3 DateOnly) ([]*ScheduledShift, error) {
+	if m.ListShiftsFunc == nil {
+		panic("MockCalendarFeedStorer.ListShifts called but ListShiftsFunc is not set")
+	}
+	return m.ListShiftsFunc(a0, a1, a2, a3)
+}
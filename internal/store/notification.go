@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	NotificationSchedulePublished = "schedule_published"
+	NotificationShiftChanged      = "shift_changed"
+	NotificationStaffMilestones   = "staff_milestones"
+	NotificationDocumentAck       = "document_acknowledgment"
+	NotificationAnnouncement      = "announcement"
+)
+
+// Notification is an in-app notification for an owner (UserID) or an employee (EmployeeID)
+type Notification struct {
+	ID           int64           `json:"id"`
+	RestaurantID int64           `json:"restaurant_id"`
+	UserID       *int64          `json:"user_id,omitempty"`
+	EmployeeID   *int64          `json:"employee_id,omitempty"`
+	Type         string          `json:"type"`
+	Title        string          `json:"title"`
+	Body         string          `json:"body"`
+	Data         json.RawMessage `json:"data" swaggertype:"object"`
+	ReadAt       *time.Time      `json:"read_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// NotificationRecipient picks whose notifications to read; exactly one of the IDs is set
+type NotificationRecipient struct {
+	UserID     *int64
+	EmployeeID *int64
+}
+
+func UserRecipient(userID int64) NotificationRecipient {
+	return NotificationRecipient{UserID: &userID}
+}
+
+func EmployeeRecipient(employeeID int64) NotificationRecipient {
+	return NotificationRecipient{EmployeeID: &employeeID}
+}
+
+// filter returns the column and ID to match; the column is never user input
+func (r NotificationRecipient) filter() (string, int64) {
+	if r.EmployeeID != nil {
+		return "employee_id", *r.EmployeeID
+	}
+	return "user_id", *r.UserID
+}
+
+// NotificationQuery pages through notifications newest first
+type NotificationQuery struct {
+	UnreadOnly bool
+	Limit      int
+	// BeforeID continues a previous page from its last notification's ID
+	BeforeID int64
+}
+
+type NotificationStore struct {
+	db *sql.DB
+}
+
+// CreateMany records the notifications in one transaction
+func (s *NotificationStore) CreateMany(ctx context.Context, notifications []*Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			INSERT INTO notifications (restaurant_id, user_id, employee_id, type, title, body, data)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at`
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, n := range notifications {
+			data := n.Data
+			if len(data) == 0 {
+				data = json.RawMessage(`{}`)
+			}
+
+			err := stmt.QueryRowContext(
+				ctx,
+				n.RestaurantID,
+				n.UserID,
+				n.EmployeeID,
+				n.Type,
+				n.Title,
+				n.Body,
+				[]byte(data),
+			).Scan(&n.ID, &n.CreatedAt)
+			if err != nil {
+				return err
+			}
+			n.Data = data
+		}
+
+		return nil
+	})
+}
+
+func (s *NotificationStore) List(ctx context.Context, recipient NotificationRecipient, q NotificationQuery) ([]*Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	column, id := recipient.filter()
+	query := fmt.Sprintf(`
+		SELECT id, restaurant_id, user_id, employee_id, type, title, body, data, read_at, created_at
+		FROM notifications
+		WHERE %s = $1
+		  AND (NOT $2 OR read_at IS NULL)
+		  AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`, column)
+
+	rows, err := s.db.QueryContext(ctx, query, id, q.UnreadOnly, q.BeforeID, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*Notification{}
+	for rows.Next() {
+		var n Notification
+		var data []byte
+		err := rows.Scan(
+			&n.ID,
+			&n.RestaurantID,
+			&n.UserID,
+			&n.EmployeeID,
+			&n.Type,
+			&n.Title,
+			&n.Body,
+			&data,
+			&n.ReadAt,
+			&n.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		n.Data = data
+		notifications = append(notifications, &n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (s *NotificationStore) UnreadCount(ctx context.Context, recipient NotificationRecipient) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	column, id := recipient.filter()
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM notifications WHERE %s = $1 AND read_at IS NULL`, column)
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of the recipient's notifications as read; reading it again is a no-op
+func (s *NotificationStore) MarkRead(ctx context.Context, recipient NotificationRecipient, notificationID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	column, id := recipient.filter()
+	query := fmt.Sprintf(`
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND %s = $2
+		RETURNING id`, column)
+
+	err := s.db.QueryRowContext(ctx, query, notificationID, id).Scan(&notificationID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the recipient as read and returns how many there were
+func (s *NotificationStore) MarkAllRead(ctx context.Context, recipient NotificationRecipient) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	column, id := recipient.filter()
+	query := fmt.Sprintf(`UPDATE notifications SET read_at = NOW() WHERE %s = $1 AND read_at IS NULL`, column)
+
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// NotificationMode is how an employee hears about shift changes and announcements by email
+type NotificationMode string
+
+const (
+	// NotifyImmediately emails each change as it's sent
+	NotifyImmediately NotificationMode = "immediate"
+	// NotifyDaily batches them into one email a day
+	NotifyDaily NotificationMode = "daily"
+	// NotifyShiftDay batches them into an email on the days the employee works,
+	// or after a week without a shift
+	NotifyShiftDay NotificationMode = "shift_day"
+)
+
+// Digest reports whether emails under the mode wait for a digest
+func (m NotificationMode) Digest() bool {
+	return m == NotifyDaily || m == NotifyShiftDay
+}
+
+// NotificationModeFor is the employee's own mode, or the restaurant's when they have none
+func (r *Restaurant) NotificationModeFor(employee *Employee) NotificationMode {
+	if employee.NotificationMode != nil {
+		return *employee.NotificationMode
+	}
+	if r.NotificationMode == "" {
+		return NotifyImmediately
+	}
+	return r.NotificationMode
+}
+
+// Kinds of digest item
+const (
+	DigestAnnouncement    = "announcement"
+	DigestScheduleChanges = "schedule_changes"
+)
+
+// DigestItem is an email held for an employee's next digest. Body is the text as
+// the owner wrote it or as the change was described, rendered when the digest goes out.
+type DigestItem struct {
+	ID           int64      `json:"id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	EmployeeID   int64      `json:"employee_id"`
+	Kind         string     `json:"kind"`
+	Subject      string     `json:"subject"`
+	Body         string     `json:"body"`
+	CreatedAt    time.Time  `json:"created_at"`
+	SentAt       *time.Time `json:"sent_at,omitempty"`
+}
+
+// EmployeeDigest is one employee's claimed items, oldest first
+type EmployeeDigest struct {
+	Employee       *Employee
+	RestaurantName string
+	Items          []*DigestItem
+}
+
+type NotificationDigestStore struct {
+	db *sql.DB
+}
+
+// Enqueue holds the items for their employees' next digests
+func (s *NotificationDigestStore) Enqueue(ctx context.Context, items []*DigestItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO notification_digest_items (restaurant_id, employee_id, kind, subject, body)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, item := range items {
+			err := stmt.QueryRowContext(ctx, item.RestaurantID, item.EmployeeID, item.Kind, item.Subject, item.Body).
+				Scan(&item.ID, &item.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ClaimDue marks as sent the items whose digest is due at now and returns them by
+// employee. A restaurant's digests are due from its digest_hour (UTC) each day and
+// take what was held before then, so each employee gets at most one a day. Under
+// shift_day they wait for a day the employee has a published shift, unless
+// something has waited a week. Rows another instance is claiming are skipped.
+func (s *NotificationDigestStore) ClaimDue(ctx context.Context, now time.Time) ([]*EmployeeDigest, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH due AS (
+			SELECT i.id
+			FROM notification_digest_items i
+			JOIN employees e ON e.id = i.employee_id
+			JOIN restaurants r ON r.id = i.restaurant_id
+			CROSS JOIN LATERAL (
+				SELECT date_trunc('day', $1::timestamptz AT TIME ZONE 'UTC') + make_interval(hours => r.digest_hour) AS cutoff
+			) c
+			WHERE i.sent_at IS NULL
+			  AND $1::timestamptz AT TIME ZONE 'UTC' >= c.cutoff
+			  AND i.created_at AT TIME ZONE 'UTC' < c.cutoff
+			  AND (COALESCE(e.notification_mode, r.notification_mode) <> 'shift_day'
+			       OR EXISTS (
+			           SELECT 1
+			           FROM scheduled_shifts ss
+			           JOIN schedules sc ON sc.id = ss.schedule_id
+			           WHERE ss.employee_id = e.id AND sc.published_at IS NOT NULL AND ss.shift_date = c.cutoff::date)
+			       OR EXISTS (
+			           SELECT 1
+			           FROM notification_digest_items o
+			           WHERE o.employee_id = e.id AND o.sent_at IS NULL
+			             AND o.created_at AT TIME ZONE 'UTC' < c.cutoff - INTERVAL '7 days'))
+			FOR UPDATE OF i SKIP LOCKED
+		),
+		claimed AS (
+			UPDATE notification_digest_items i
+			SET sent_at = $1
+			FROM due
+			WHERE i.id = due.id
+			RETURNING i.id, i.restaurant_id, i.employee_id, i.kind, i.subject, i.body, i.created_at, i.sent_at
+		)
+		SELECT c.id, c.restaurant_id, c.employee_id, c.kind, c.subject, c.body, c.created_at, c.sent_at,
+		       e.full_name, e.email, e.locale, e.email_bounced_at, r.name
+		FROM claimed c
+		JOIN employees e ON e.id = c.employee_id
+		JOIN restaurants r ON r.id = c.restaurant_id
+		ORDER BY c.employee_id, c.created_at, c.id`
+
+	rows, err := s.db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	digests := []*EmployeeDigest{}
+	var current *EmployeeDigest
+	for rows.Next() {
+		var item DigestItem
+		var employee Employee
+		var restaurantName string
+		err := rows.Scan(
+			&item.ID,
+			&item.RestaurantID,
+			&item.EmployeeID,
+			&item.Kind,
+			&item.Subject,
+			&item.Body,
+			&item.CreatedAt,
+			&item.SentAt,
+			&employee.FullName,
+			&employee.Email,
+			&employee.Locale,
+			&employee.EmailBouncedAt,
+			&restaurantName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.Employee.ID != item.EmployeeID {
+			employee.ID, employee.RestaurantID = item.EmployeeID, item.RestaurantID
+			current = &EmployeeDigest{Employee: &employee, RestaurantName: restaurantName}
+			digests = append(digests, current)
+		}
+		current.Items = append(current.Items, &item)
+	}
+
+	return digests, rows.Err()
+}
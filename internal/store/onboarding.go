@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRestaurantNotEmpty = errors.New("sample data can only be added to a restaurant without roles, employees, shift templates or schedules")
+)
+
+// OnboardingProgress counts what a restaurant has set up so far
+type OnboardingProgress struct {
+	Roles              int `json:"roles"`
+	Employees          int `json:"employees"`
+	ShiftTemplates     int `json:"shift_templates"`
+	Schedules          int `json:"schedules"`
+	PublishedSchedules int `json:"published_schedules"`
+}
+
+// SampleData is a starter configuration; employees and templates refer to roles by name
+type SampleData struct {
+	Roles          []SampleRole
+	Employees      []SampleEmployee
+	ShiftTemplates []SampleShiftTemplate
+	ScheduleStart  DateOnly
+	ScheduleEnd    DateOnly
+}
+
+type SampleRole struct {
+	Name  string
+	Color string
+}
+
+type SampleEmployee struct {
+	FullName        string
+	Email           string
+	HourlyRateCents *int
+	Roles           []string
+}
+
+type SampleShiftTemplate struct {
+	Name      string
+	DayOfWeek int
+	StartTime TimeOfDay
+	EndTime   TimeOfDay
+	Roles     []string
+}
+
+// SampleDataResult is what SeedSample created
+type SampleDataResult struct {
+	Roles          int   `json:"roles"`
+	Employees      int   `json:"employees"`
+	ShiftTemplates int   `json:"shift_templates"`
+	ScheduleID     int64 `json:"schedule_id"`
+}
+
+type OnboardingStore struct {
+	db *sql.DB
+}
+
+func (s *OnboardingStore) Progress(ctx context.Context, restaurantID int64) (*OnboardingProgress, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM roles WHERE restaurant_id = $1),
+			(SELECT COUNT(*) FROM employees WHERE restaurant_id = $1),
+			(SELECT COUNT(*) FROM shift_templates WHERE restaurant_id = $1),
+			(SELECT COUNT(*) FROM schedules WHERE restaurant_id = $1),
+			(SELECT COUNT(*) FROM schedules WHERE restaurant_id = $1 AND published_at IS NOT NULL)`
+
+	var progress OnboardingProgress
+	err := s.db.QueryRowContext(ctx, query, restaurantID).Scan(
+		&progress.Roles,
+		&progress.Employees,
+		&progress.ShiftTemplates,
+		&progress.Schedules,
+		&progress.PublishedSchedules,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &progress, nil
+}
+
+// SeedSample adds the sample data to an empty restaurant in one transaction,
+// or returns ErrRestaurantNotEmpty
+func (s *OnboardingStore) SeedSample(ctx context.Context, restaurantID int64, sample *SampleData) (*SampleDataResult, error) {
+	result := &SampleDataResult{}
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		// Lock the restaurant so two requests can't both find it empty
+		var empty bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT NOT EXISTS (SELECT 1 FROM roles WHERE restaurant_id = r.id)
+			   AND NOT EXISTS (SELECT 1 FROM employees WHERE restaurant_id = r.id)
+			   AND NOT EXISTS (SELECT 1 FROM shift_templates WHERE restaurant_id = r.id)
+			   AND NOT EXISTS (SELECT 1 FROM schedules WHERE restaurant_id = r.id)
+			FROM restaurants r
+			WHERE r.id = $1
+			FOR UPDATE`, restaurantID).Scan(&empty)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if !empty {
+			return ErrRestaurantNotEmpty
+		}
+
+		roleIDs := make(map[string]int64, len(sample.Roles))
+		for _, role := range sample.Roles {
+			var id int64
+			err := tx.QueryRowContext(ctx, `
+				INSERT INTO roles (restaurant_id, name, color, created_at, updated_at)
+				VALUES ($1, $2, $3, NOW(), NOW())
+				RETURNING id`, restaurantID, role.Name, role.Color).Scan(&id)
+			if err != nil {
+				return err
+			}
+			roleIDs[role.Name] = id
+		}
+		result.Roles = len(roleIDs)
+
+		lookup := func(names []string) ([]int64, error) {
+			ids := make([]int64, 0, len(names))
+			for _, name := range names {
+				id, ok := roleIDs[name]
+				if !ok {
+					return nil, fmt.Errorf("sample data: unknown role %q", name)
+				}
+				ids = append(ids, id)
+			}
+			return ids, nil
+		}
+
+		for _, employee := range sample.Employees {
+			ids, err := lookup(employee.Roles)
+			if err != nil {
+				return err
+			}
+
+			var employeeID int64
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO employees (restaurant_id, full_name, email, hourly_rate_cents, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, NOW(), NOW())
+				RETURNING id`, restaurantID, employee.FullName, employee.Email, employee.HourlyRateCents).Scan(&employeeID)
+			if err != nil {
+				return err
+			}
+
+			for _, roleID := range ids {
+				if _, err := tx.ExecContext(ctx, `INSERT INTO employee_roles (employee_id, role_id) VALUES ($1, $2)`, employeeID, roleID); err != nil {
+					return err
+				}
+			}
+			result.Employees++
+		}
+
+		for _, template := range sample.ShiftTemplates {
+			ids, err := lookup(template.Roles)
+			if err != nil {
+				return err
+			}
+			roleIDsJSON, err := json.Marshal(ids)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO shift_templates (restaurant_id, name, day_of_week, start_time, end_time, role_ids)
+				VALUES ($1, $2, $3, $4, $5, $6)`,
+				restaurantID, template.Name, template.DayOfWeek, template.StartTime, template.EndTime, roleIDsJSON)
+			if err != nil {
+				return err
+			}
+			result.ShiftTemplates++
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO schedules (restaurant_id, start_date, end_date, created_at, updated_at)
+			VALUES ($1, $2, $3, NOW(), NOW())
+			RETURNING id`, restaurantID, sample.ScheduleStart, sample.ScheduleEnd).Scan(&result.ScheduleID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
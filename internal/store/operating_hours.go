@@ -0,0 +1,437 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrDuplicateHoursException = errors.New("that date already has special hours")
+)
+
+// HoursEnforcement controls what happens to shifts that fall outside operating hours
+type HoursEnforcement string
+
+const (
+	HoursEnforcementOff   HoursEnforcement = "off"
+	HoursEnforcementWarn  HoursEnforcement = "warn"
+	HoursEnforcementBlock HoursEnforcement = "block"
+)
+
+// OperatingDay is a restaurant's regular hours on one weekday (0 = Sunday)
+type OperatingDay struct {
+	DayOfWeek int       `json:"day_of_week"`
+	Closed    bool      `json:"closed"`
+	OpenTime  TimeOfDay `json:"open_time,omitempty"`
+	CloseTime TimeOfDay `json:"close_time,omitempty"`
+}
+
+// OperatingHours is a restaurant's weekly hours; an empty Days means hours haven't been set up
+type OperatingHours struct {
+	RestaurantID int64            `json:"restaurant_id"`
+	Enforcement  HoursEnforcement `json:"enforcement"`
+	Days         []*OperatingDay  `json:"days"`
+}
+
+// HoursException overrides the weekly hours on a single date, e.g. a holiday closure
+type HoursException struct {
+	ID           int64     `json:"id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	Date         DateOnly  `json:"date"`
+	Name         string    `json:"name"`
+	Closed       bool      `json:"closed"`
+	OpenTime     TimeOfDay `json:"open_time,omitempty"`
+	CloseTime    TimeOfDay `json:"close_time,omitempty"`
+	// HolidayRegion is set on public holidays added from the region's calendar. Those
+	// that aren't closures have no times: the weekly hours apply, under the holiday's name.
+	HolidayRegion string    `json:"holiday_region,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// RegularHours reports whether the exception only names the date, leaving the weekly hours
+func (ex *HoursException) RegularHours() bool {
+	return !ex.Closed && ex.OpenTime == ""
+}
+
+// HolidayCalendar is a restaurant whose special dates follow a region's public holidays
+type HolidayCalendar struct {
+	RestaurantID int64
+	Region       string
+	// Closed adds the holidays as closures rather than days on regular hours
+	Closed bool
+}
+
+// EffectiveHours are the hours in effect on a date after applying exceptions
+type EffectiveHours struct {
+	Date      DateOnly  `json:"date"`
+	Closed    bool      `json:"closed"`
+	OpenTime  TimeOfDay `json:"open_time,omitempty"`
+	CloseTime TimeOfDay `json:"close_time,omitempty"`
+	Exception string    `json:"exception,omitempty"`
+}
+
+// On resolves the hours for a date; it returns nil when neither the weekly hours nor an exception cover it
+func (h *OperatingHours) On(date time.Time, exceptions []*HoursException) *EffectiveHours {
+	day := DateOnly(date.Format("2006-01-02"))
+
+	// a holiday on regular hours only names the day
+	name := ""
+	for _, ex := range exceptions {
+		if ex.Date != day {
+			continue
+		}
+		if ex.RegularHours() {
+			name = ex.Name
+			break
+		}
+		return &EffectiveHours{
+			Date:      day,
+			Closed:    ex.Closed,
+			OpenTime:  ex.OpenTime,
+			CloseTime: ex.CloseTime,
+			Exception: ex.Name,
+		}
+	}
+
+	for _, d := range h.Days {
+		if d.DayOfWeek == int(date.Weekday()) {
+			return &EffectiveHours{
+				Date:      day,
+				Closed:    d.Closed,
+				OpenTime:  d.OpenTime,
+				CloseTime: d.CloseTime,
+				Exception: name,
+			}
+		}
+	}
+
+	return nil
+}
+
+// Covers reports whether a shift from start to end fits within the hours
+func (e *EffectiveHours) Covers(start, end TimeOfDay) bool {
+	if e.Closed {
+		return false
+	}
+	return normalizeTimeString(string(start)) >= normalizeTimeString(string(e.OpenTime)) &&
+		normalizeTimeString(string(end)) <= normalizeTimeString(string(e.CloseTime))
+}
+
+type OperatingHoursStore struct {
+	db *sql.DB
+}
+
+func (s *OperatingHoursStore) Get(ctx context.Context, restaurantID int64) (*OperatingHours, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	hours := &OperatingHours{RestaurantID: restaurantID, Days: []*OperatingDay{}}
+
+	err := s.db.QueryRowContext(ctx, `SELECT hours_enforcement FROM restaurants WHERE id = $1`, restaurantID).Scan(&hours.Enforcement)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	query := `
+		SELECT day_of_week, closed, open_time, close_time
+		FROM restaurant_operating_hours
+		WHERE restaurant_id = $1
+		ORDER BY day_of_week`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d OperatingDay
+		if err := rows.Scan(&d.DayOfWeek, &d.Closed, &d.OpenTime, &d.CloseTime); err != nil {
+			return nil, err
+		}
+		hours.Days = append(hours.Days, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+// Replace sets the enforcement mode and replaces the whole week of hours
+func (s *OperatingHoursStore) Replace(ctx context.Context, hours *OperatingHours) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		result, err := tx.ExecContext(ctx, `UPDATE restaurants SET hours_enforcement = $1 WHERE id = $2`, hours.Enforcement, hours.RestaurantID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM restaurant_operating_hours WHERE restaurant_id = $1`, hours.RestaurantID); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO restaurant_operating_hours (restaurant_id, day_of_week, closed, open_time, close_time)
+			VALUES ($1, $2, $3, $4, $5)`
+
+		for _, d := range hours.Days {
+			if _, err := tx.ExecContext(ctx, query, hours.RestaurantID, d.DayOfWeek, d.Closed, d.OpenTime, d.CloseTime); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ListExceptions returns the restaurant's exceptions dated from..to inclusive
+func (s *OperatingHoursStore) ListExceptions(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*HoursException, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, date, name, closed, open_time, close_time, COALESCE(holiday_region, ''), created_at, updated_at
+		FROM restaurant_hours_exceptions
+		WHERE restaurant_id = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := []*HoursException{}
+	for rows.Next() {
+		var ex HoursException
+		err := rows.Scan(
+			&ex.ID,
+			&ex.RestaurantID,
+			&ex.Date,
+			&ex.Name,
+			&ex.Closed,
+			&ex.OpenTime,
+			&ex.CloseTime,
+			&ex.HolidayRegion,
+			&ex.CreatedAt,
+			&ex.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, &ex)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exceptions, nil
+}
+
+func (s *OperatingHoursStore) GetException(ctx context.Context, id int64) (*HoursException, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, date, name, closed, open_time, close_time, COALESCE(holiday_region, ''), created_at, updated_at
+		FROM restaurant_hours_exceptions
+		WHERE id = $1`
+
+	var ex HoursException
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&ex.ID,
+		&ex.RestaurantID,
+		&ex.Date,
+		&ex.Name,
+		&ex.Closed,
+		&ex.OpenTime,
+		&ex.CloseTime,
+		&ex.HolidayRegion,
+		&ex.CreatedAt,
+		&ex.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &ex, nil
+}
+
+func (s *OperatingHoursStore) CreateException(ctx context.Context, ex *HoursException) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO restaurant_hours_exceptions (restaurant_id, date, name, closed, open_time, close_time)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		ex.RestaurantID,
+		ex.Date,
+		ex.Name,
+		ex.Closed,
+		ex.OpenTime,
+		ex.CloseTime,
+	).Scan(
+		&ex.ID,
+		&ex.CreatedAt,
+		&ex.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err, "uq_hours_exceptions_restaurant_date") {
+			return ErrDuplicateHoursException
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *OperatingHoursStore) DeleteException(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM restaurant_hours_exceptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListHolidayCalendars returns the active restaurants with a holiday region
+// whose public holidays for year haven't been added yet
+func (s *OperatingHoursStore) ListHolidayCalendars(ctx context.Context, year int) ([]*HolidayCalendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT r.id, r.holiday_region, r.holidays_closed
+		FROM restaurants r
+		WHERE r.holiday_region <> ''
+		  AND r.archived_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM restaurant_holiday_years y
+			WHERE y.restaurant_id = r.id AND y.year = $1 AND y.region = r.holiday_region
+		  )
+		ORDER BY r.id`
+
+	rows, err := s.db.QueryContext(ctx, query, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calendars := []*HolidayCalendar{}
+	for rows.Next() {
+		var c HolidayCalendar
+		if err := rows.Scan(&c.RestaurantID, &c.Region, &c.Closed); err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, &c)
+	}
+
+	return calendars, rows.Err()
+}
+
+// AddHolidays adds a year of the calendar's public holidays to the special
+// dates, once per year and region, so holidays the owner deletes stay deleted.
+// Dates that already have an exception keep it. Holidays of another region
+// from today on are removed first, as the restaurant has moved off it. It
+// returns how many holidays were added.
+func (s *OperatingHoursStore) AddHolidays(ctx context.Context, calendar *HolidayCalendar, year int, holidays []*HoursException, today DateOnly) (int, error) {
+	added := 0
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		// a region left behind is added afresh if the restaurant comes back to it
+		_, err := tx.ExecContext(ctx, `
+			DELETE FROM restaurant_holiday_years
+			WHERE restaurant_id = $1 AND year = $2 AND region <> $3`,
+			calendar.RestaurantID, year, calendar.Region)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO restaurant_holiday_years (restaurant_id, year, region)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`,
+			calendar.RestaurantID, year, calendar.Region)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			DELETE FROM restaurant_hours_exceptions
+			WHERE restaurant_id = $1
+			  AND holiday_region IS NOT NULL AND holiday_region <> $2
+			  AND date >= $3 AND EXTRACT(YEAR FROM date) = $4`,
+			calendar.RestaurantID, calendar.Region, today, year)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO restaurant_hours_exceptions (restaurant_id, date, name, closed, open_time, close_time, holiday_region)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (restaurant_id, date) DO NOTHING
+			RETURNING id, created_at, updated_at`
+
+		for _, ex := range holidays {
+			err := tx.QueryRowContext(ctx, query, calendar.RestaurantID, ex.Date, ex.Name, ex.Closed, ex.OpenTime, ex.CloseTime, calendar.Region).
+				Scan(&ex.ID, &ex.CreatedAt, &ex.UpdatedAt)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			ex.RestaurantID = calendar.RestaurantID
+			ex.HolidayRegion = calendar.Region
+			added++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added, nil
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// uniqueViolation is the Postgres error code for a duplicate key
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a duplicate key in the named unique
+// constraint or index
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == constraint
+}
+
+// pgArray scans a Postgres array column into the slice dst points to, nil for
+// NULL. Array parameters need no wrapping: pgx encodes slices itself.
+func pgArray(dst any) sql.Scanner {
+	return pgtype.NewMap().SQLScanner(dst)
+}
+
+// withPgxTx is withTx on the pgx connection under one of db's, for work that
+// needs pgx itself, such as batches. The transaction is timed in the query
+// metrics as a whole, under its caller's name, when db is instrumented.
+func withPgxTx(db *sql.DB, ctx context.Context, fn func(pgx.Tx) error) error {
+	name := callerName()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		instrumented, _ := driverConn.(*instrumentedConn)
+		if instrumented != nil {
+			driverConn = instrumented.Conn
+		}
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("store: %T is not a pgx connection", driverConn)
+		}
+
+		start := time.Now()
+		err := pgx.BeginFunc(ctx, stdConn.Conn(), func(tx pgx.Tx) error {
+			if restaurantID := scopedRestaurant(ctx); restaurantID != "" {
+				if _, err := tx.Exec(ctx, scopeQuery, restaurantID); err != nil {
+					return err
+				}
+			}
+			return fn(tx)
+		})
+		if instrumented != nil {
+			instrumented.connector.record(name, "pgx transaction", nil, start, err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	duplicate := &pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_email_key"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"the constraint", duplicate, true},
+		{"wrapped", fmt.Errorf("create user: %w", duplicate), true},
+		{"another constraint", &pgconn.PgError{Code: uniqueViolation, ConstraintName: "users_username_key"}, false},
+		{"another code", &pgconn.PgError{Code: "23503", ConstraintName: "users_email_key"}, false},
+		{"not a postgres error", errors.New("duplicate key value violates unique constraint \"users_email_key\""), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUniqueViolation(tt.err, "users_email_key"); got != tt.want {
+				t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPgArray(t *testing.T) {
+	var ids []int64
+	if err := pgArray(&ids).Scan("{3,1,2}"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{3, 1, 2}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	names := []string{"stale"}
+	if err := pgArray(&names).Scan(nil); err != nil {
+		t.Fatal(err)
+	}
+	if names != nil {
+		t.Errorf("names = %v, want nil for NULL", names)
+	}
+}
@@ -0,0 +1,521 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxSlowQueries is how many of the latest slow queries are kept for diagnostics
+	maxSlowQueries = 50
+	// explainTimeout caps the re-run of a slow query for its plan
+	explainTimeout = 10 * time.Second
+)
+
+// QueryMetricsConfig sets when a query counts as slow and what's done about it
+type QueryMetricsConfig struct {
+	// SlowThreshold is how long a query runs before it's logged as slow; 0 never logs
+	SlowThreshold time.Duration
+	// Explain captures an EXPLAIN ANALYZE plan for each slow query. The query runs
+	// again inside a transaction that is rolled back, so it's for development only.
+	Explain bool
+}
+
+// Logger is the part of the application's logger slow queries are reported to
+type Logger interface {
+	Warnw(msg string, keysAndValues ...any)
+}
+
+// QueryStat is how the queries of one function, usually a store method, have
+// performed since the process started
+type QueryStat struct {
+	Name    string  `json:"name"`
+	Calls   int64   `json:"calls"`
+	Errors  int64   `json:"errors"`
+	Slow    int64   `json:"slow"`
+	TotalMS float64 `json:"total_ms"`
+	MeanMS  float64 `json:"mean_ms"`
+	MaxMS   float64 `json:"max_ms"`
+}
+
+// SlowQuery is a query that ran over the threshold. Args only names the type of each
+// bound parameter; their values are never kept.
+type SlowQuery struct {
+	Name       string    `json:"name"`
+	Query      string    `json:"query"`
+	Args       []string  `json:"args"`
+	DurationMS float64   `json:"duration_ms"`
+	At         time.Time `json:"at"`
+	Plan       string    `json:"plan,omitempty"`
+	PlanError  string    `json:"plan_error,omitempty"`
+}
+
+// QueryMetrics times every query run on the connections it instruments, by the
+// function that ran it, and keeps the latest slow ones
+type QueryMetrics struct {
+	cfg    QueryMetricsConfig
+	logger Logger
+
+	mu    sync.Mutex
+	stats map[string]*QueryStat
+	slow  []*SlowQuery
+	// explaining holds a slot while a plan is captured; slow queries meanwhile go without
+	explaining chan struct{}
+}
+
+func NewQueryMetrics(cfg QueryMetricsConfig, logger Logger) *QueryMetrics {
+	return &QueryMetrics{
+		cfg:        cfg,
+		logger:     logger,
+		stats:      map[string]*QueryStat{},
+		explaining: make(chan struct{}, 1),
+	}
+}
+
+// Explains reports whether slow queries get their plans captured
+func (m *QueryMetrics) Explains() bool {
+	return m.cfg.Explain
+}
+
+// Stats returns each function's query stats, the most time spent first
+func (m *QueryMetrics) Stats() []QueryStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]QueryStat, 0, len(m.stats))
+	for _, s := range m.stats {
+		stat := *s
+		stat.MeanMS = stat.TotalMS / float64(stat.Calls)
+		stats = append(stats, stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalMS != stats[j].TotalMS {
+			return stats[i].TotalMS > stats[j].TotalMS
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// SlowQueries returns the latest slow queries, newest first
+func (m *QueryMetrics) SlowQueries() []SlowQuery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	slow := make([]SlowQuery, 0, len(m.slow))
+	for i := len(m.slow) - 1; i >= 0; i-- {
+		slow = append(slow, *m.slow[i])
+	}
+	return slow
+}
+
+// Instrument wraps a connector so every query on its connections is recorded. With
+// Explain on, plans are captured over a one-connection pool of the bare connector.
+func (m *QueryMetrics) Instrument(c driver.Connector) driver.Connector {
+	ic := &instrumentedConnector{Connector: c, metrics: m}
+	if m.cfg.Explain {
+		// hide the connector's Close: closing the plan pool mustn't close what it shares
+		ic.explainDB = sql.OpenDB(struct{ driver.Connector }{c})
+		ic.explainDB.SetMaxOpenConns(1)
+	}
+	return ic
+}
+
+func (m *QueryMetrics) record(explainDB *sql.DB, name, query string, args []driver.NamedValue, d time.Duration, err error) {
+	ms := float64(d) / float64(time.Millisecond)
+	isSlow := m.cfg.SlowThreshold > 0 && d >= m.cfg.SlowThreshold
+
+	m.mu.Lock()
+	stat, ok := m.stats[name]
+	if !ok {
+		stat = &QueryStat{Name: name}
+		m.stats[name] = stat
+	}
+	stat.Calls++
+	stat.TotalMS += ms
+	if ms > stat.MaxMS {
+		stat.MaxMS = ms
+	}
+	if err != nil {
+		stat.Errors++
+	}
+
+	var slow *SlowQuery
+	if isSlow {
+		stat.Slow++
+		slow = &SlowQuery{
+			Name:       name,
+			Query:      strings.Join(strings.Fields(query), " "),
+			Args:       argTypes(args),
+			DurationMS: ms,
+			At:         time.Now().UTC(),
+		}
+		m.slow = append(m.slow, slow)
+		if len(m.slow) > maxSlowQueries {
+			m.slow = m.slow[len(m.slow)-maxSlowQueries:]
+		}
+	}
+	m.mu.Unlock()
+
+	if slow == nil {
+		return
+	}
+
+	if m.logger != nil {
+		m.logger.Warnw("slow query",
+			"name", name,
+			"duration_ms", ms,
+			"query", slow.Query,
+			"args", slow.Args,
+		)
+	}
+
+	if explainDB != nil && err == nil && explainable(query) {
+		m.explain(explainDB, slow, query, args)
+	}
+}
+
+// explain captures the slow query's plan in the background, one at a time
+func (m *QueryMetrics) explain(db *sql.DB, slow *SlowQuery, query string, args []driver.NamedValue) {
+	select {
+	case m.explaining <- struct{}{}:
+	default:
+		return
+	}
+
+	values := make([]any, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	go func() {
+		defer func() { <-m.explaining }()
+
+		plan, err := explainAnalyze(db, query, values)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		slow.Plan = plan
+		if err != nil {
+			slow.PlanError = err.Error()
+		}
+	}()
+}
+
+// explainAnalyze runs the query under EXPLAIN ANALYZE in a transaction it rolls
+// back, so a write's plan can be captured without the write being kept
+func explainAnalyze(db *sql.DB, query string, args []any) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), rows.Err()
+}
+
+// explainable reports whether the statement is one EXPLAIN accepts
+func explainable(query string) bool {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT", "WITH", "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// argTypes describes bound parameters by type only, so their values stay out of logs
+func argTypes(args []driver.NamedValue) []string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		switch arg.Value.(type) {
+		case nil:
+			types[i] = "null"
+		case []byte:
+			types[i] = "bytes"
+		default:
+			types[i] = fmt.Sprintf("%T", arg.Value)
+		}
+	}
+	return types
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)?(\.\d+)*$`)
+
+// callerName names the function that ran a query, e.g. "store.SalesStore.List",
+// skipping database/sql and the instrumentation itself
+func callerName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		fn := frame.Function
+		if fn != "" && !strings.HasPrefix(fn, "database/sql.") && !strings.Contains(fn, ".(*instrumented") {
+			if i := strings.LastIndex(fn, "/"); i >= 0 {
+				fn = fn[i+1:]
+			}
+			fn = strings.NewReplacer("(*", "", ")", "").Replace(fn)
+			return closureSuffix.ReplaceAllString(fn, "")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+type instrumentedConnector struct {
+	driver.Connector
+	metrics *QueryMetrics
+	// explainDB captures plans for slow queries; nil unless Explain is on
+	explainDB *sql.DB
+}
+
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, connector: c}, nil
+}
+
+// Close closes the plan pool along with the instrumented one, and then the
+// connector it wraps when that has anything to close, such as a pgx pool
+func (c *instrumentedConnector) Close() error {
+	if c.explainDB != nil {
+		c.explainDB.Close()
+	}
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (c *instrumentedConnector) record(name, query string, args []driver.NamedValue, start time.Time, err error) {
+	c.metrics.record(c.explainDB, name, query, args, time.Since(start), err)
+}
+
+type instrumentedConn struct {
+	driver.Conn
+	connector *instrumentedConnector
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	name, start := callerName(), time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		if err != driver.ErrSkip {
+			c.connector.record(name, query, args, start, err)
+		}
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, connector: c.connector, name: name, query: query, args: args, start: start}, nil
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	name, start := callerName(), time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	if err != driver.ErrSkip {
+		c.connector.record(name, query, args, start, err)
+	}
+	return res, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, connector: c.connector, query: query}, nil
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	// drivers without BeginTx only have Begin
+	return c.Conn.Begin()
+}
+
+// CheckNamedValue lets the driver take the arguments it encodes itself, such as
+// pgx's slices for array parameters; other drivers get database/sql's conversions
+func (c *instrumentedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type instrumentedStmt struct {
+	driver.Stmt
+	connector *instrumentedConnector
+	query     string
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	name, start := callerName(), time.Now()
+
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValues(args))
+	}
+	if err != nil {
+		s.connector.record(name, s.query, args, start, err)
+		return nil, err
+	}
+	return &instrumentedRows{Rows: rows, connector: s.connector, name: name, query: s.query, args: args, start: start}, nil
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	name, start := callerName(), time.Now()
+
+	var res driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		res, err = s.Stmt.Exec(namedValues(args))
+	}
+	s.connector.record(name, s.query, args, start, err)
+	return res, err
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// instrumentedRows times a query until its rows are closed, since rows stream in
+// after the query returns
+type instrumentedRows struct {
+	driver.Rows
+	connector *instrumentedConnector
+	name      string
+	query     string
+	args      []driver.NamedValue
+	start     time.Time
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	r.connector.record(r.name, r.query, r.args, r.start, nil)
+	return err
+}
+
+func (r *instrumentedRows) HasNextResultSet() bool {
+	if next, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return next.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *instrumentedRows) NextResultSet() error {
+	if next, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return next.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *instrumentedRows) ColumnTypeScanType(index int) reflect.Type {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return typed.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+func (r *instrumentedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return typed.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *instrumentedRows) ColumnTypeLength(index int) (int64, bool) {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return typed.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *instrumentedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if typed, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return typed.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
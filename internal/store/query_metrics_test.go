@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeConnector serves connections whose queries take 5ms when they mention "slow"
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	fakeLatency(query)
+	return fakeRows{}, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	fakeLatency(query)
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("failed")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func fakeLatency(query string) {
+	if strings.Contains(query, "slow") {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return []string{"n"} }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+type fakeLogger struct{ lines []string }
+
+func (l *fakeLogger) Warnw(msg string, keysAndValues ...any) {
+	l.lines = append(l.lines, fmt.Sprint(append([]any{msg}, keysAndValues...)...))
+}
+
+func TestQueryMetrics(t *testing.T) {
+	logger := &fakeLogger{}
+	metrics := NewQueryMetrics(QueryMetricsConfig{SlowThreshold: 3 * time.Millisecond}, logger)
+	db := sql.OpenDB(metrics.Instrument(fakeConnector{}))
+	defer db.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		rows, err := db.QueryContext(ctx, "SELECT n FROM t WHERE id = $1", 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE slow\n\tSET email = $1 WHERE id = $2", "ana@example.com", 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM fail"); err == nil {
+		t.Fatal("want the exec to fail")
+	}
+
+	stats := metrics.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("stats = %+v, want one entry for this test", stats)
+	}
+	got := stats[0]
+	if got.Name != "store.TestQueryMetrics" || got.Calls != 4 || got.Slow != 1 || got.Errors != 1 {
+		t.Errorf("stats = %+v, want 4 calls by store.TestQueryMetrics with one slow and one failed", got)
+	}
+	if got.MaxMS < 5 || got.MeanMS <= 0 {
+		t.Errorf("stats = %+v, want the slow query's time", got)
+	}
+
+	slow := metrics.SlowQueries()
+	if len(slow) != 1 {
+		t.Fatalf("slow queries = %+v, want the update", slow)
+	}
+	if slow[0].Query != "UPDATE slow SET email = $1 WHERE id = $2" {
+		t.Errorf("query = %q, want it on one line", slow[0].Query)
+	}
+	if strings.Join(slow[0].Args, ",") != "string,int64" {
+		t.Errorf("args = %v, want only their types", slow[0].Args)
+	}
+
+	if len(logger.lines) != 1 {
+		t.Fatalf("logged %v, want the slow query", logger.lines)
+	}
+	if strings.Contains(logger.lines[0], "ana@example.com") {
+		t.Errorf("logged %q, want the bound email redacted", logger.lines[0])
+	}
+}
+
+func TestExplainable(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT 1":                       true,
+		"\n\t\twith x AS (SELECT 1) ...": true,
+		"UPDATE t SET a = 1":             true,
+		"BEGIN":                          false,
+		"CREATE INDEX i ON t (a)":        false,
+		"":                               false,
+	} {
+		if got := explainable(query); got != want {
+			t.Errorf("explainable(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
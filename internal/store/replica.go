@@ -0,0 +1,26 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+type primaryKey struct{}
+
+// UsePrimary sends every read under ctx to the primary. Work that writes, or
+// acts on what it just read, uses it so replication lag can't show it stale rows.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// readDB picks the pool for a read that tolerates replication lag: the replica,
+// unless there is none or ctx asked for the primary
+func readDB(ctx context.Context, primary, replica *sql.DB) *sql.DB {
+	if replica == nil {
+		return primary
+	}
+	if usePrimary, _ := ctx.Value(primaryKey{}).(bool); usePrimary {
+		return primary
+	}
+	return replica
+}
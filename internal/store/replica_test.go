@@ -0,0 +1,27 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestReadDB(t *testing.T) {
+	primary, replica := &sql.DB{}, &sql.DB{}
+	ctx := context.Background()
+
+	if got := readDB(ctx, primary, nil); got != primary {
+		t.Error("no replica: want the primary")
+	}
+	if got := readDB(ctx, primary, replica); got != replica {
+		t.Error("with a replica: want the replica")
+	}
+	if got := readDB(UsePrimary(ctx), primary, replica); got != primary {
+		t.Error("UsePrimary: want the primary")
+	}
+
+	s := NewStorageWithReplica(primary, primary)
+	if shifts := s.ScheduledShifts.(*ScheduledShiftStore); shifts.replica != nil {
+		t.Error("replica equal to the primary should be dropped")
+	}
+}
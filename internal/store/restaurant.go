@@ -16,6 +16,96 @@ type Restaurant struct {
 	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
 	Version int `db:"version" json:"version"`
+	// ArchivedAt is set while the restaurant is archived: hidden from lists and read-only
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+	// ExportedAt is when the restaurant's data was last exported in full
+	ExportedAt *time.Time `db:"exported_at" json:"exported_at,omitempty"`
+	// ScheduleLockHours locks published shifts from edits this many hours before they start; 0 is off
+	ScheduleLockHours int `db:"schedule_lock_hours" json:"schedule_lock_hours"`
+	// WeeklyLaborBudgetCents caps a week's projected labor cost at publish time; nil is no budget
+	WeeklyLaborBudgetCents *int `db:"weekly_labor_budget_cents" json:"weekly_labor_budget_cents,omitempty"`
+	// ScheduleRetentionMonths archives schedules that ended this many months ago; nil keeps them listed
+	ScheduleRetentionMonths *int `db:"schedule_retention_months" json:"schedule_retention_months,omitempty"`
+	// AssignmentPolicy ranks employees when shifts are assigned automatically
+	AssignmentPolicy AssignmentPolicy `db:"assignment_policy" json:"assignment_policy"`
+	// StaffMilestoneDigest emails the owner the coming week's staff birthdays and work anniversaries
+	StaffMilestoneDigest bool `db:"staff_milestone_digest" json:"staff_milestone_digest"`
+	// Latitude and Longitude locate the restaurant for weather forecasts; both or neither are set
+	Latitude  *float64 `db:"latitude" json:"latitude,omitempty"`
+	Longitude *float64 `db:"longitude" json:"longitude,omitempty"`
+	// NotificationMode is how staff get shift changes and announcements by email; employees can override it
+	NotificationMode NotificationMode `db:"notification_mode" json:"notification_mode"`
+	// DigestHour is the hour of the day (UTC) from which digests go out
+	DigestHour int `db:"digest_hour" json:"digest_hour"`
+	// Timezone is the restaurant's IANA timezone, e.g. America/Chicago; moments shown to staff are in it
+	Timezone string `db:"timezone" json:"timezone"`
+	// WeekStart is the day the restaurant's weeks begin, 0 (Sunday) to 6 (Saturday)
+	WeekStart int `db:"week_start" json:"week_start"`
+	// RotationAnchor starts week A of the restaurant's alternating two-week shift template rotation
+	RotationAnchor *DateOnly `db:"rotation_anchor" json:"rotation_anchor,omitempty"`
+	// RequireEmailVerification holds schedule emails to employees until they've verified their address
+	RequireEmailVerification bool `db:"require_email_verification" json:"require_email_verification"`
+	// HolidayRegion is the country, or country-subdivision, whose public holidays are added
+	// to the restaurant's special dates each year, e.g. US or US-CA; empty turns it off
+	HolidayRegion string `db:"holiday_region" json:"holiday_region"`
+	// HolidaysClosed adds public holidays as closures rather than days on regular hours
+	HolidaysClosed bool `db:"holidays_closed" json:"holidays_closed"`
+}
+
+// AssignmentPolicy is how the restaurant picks an employee for a shift it assigns automatically
+type AssignmentPolicy string
+
+const (
+	// AssignmentSeniorityFirst offers shifts to the most senior eligible employee
+	AssignmentSeniorityFirst AssignmentPolicy = "seniority_first"
+	// AssignmentRotateFairly offers shifts to the eligible employee with the fewest hours so far
+	AssignmentRotateFairly AssignmentPolicy = "rotate_fairly"
+	// AssignmentManualOnly leaves every assignment to the owner
+	AssignmentManualOnly AssignmentPolicy = "manual_only"
+)
+
+// Archived reports whether the restaurant is archived
+func (r *Restaurant) Archived() bool {
+	return r.ArchivedAt != nil
+}
+
+// ShiftLocked reports whether a published shift starting at start is inside
+// the restaurant's lock window at now. Shifts that have already started stay locked.
+func (r *Restaurant) ShiftLocked(start, now time.Time) bool {
+	if r.ScheduleLockHours <= 0 {
+		return false
+	}
+	return !now.Before(start.Add(-time.Duration(r.ScheduleLockHours) * time.Hour))
+}
+
+// Location is the restaurant's timezone, or UTC when it has none that loads
+func (r *Restaurant) Location() *time.Location {
+	if r.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Today is the restaurant's date at now, in its timezone, as midnight UTC like shift dates
+func (r *Restaurant) Today(now time.Time) time.Time {
+	local := now.In(r.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// WeekOf is the first and last date of the restaurant's week holding the date
+func (r *Restaurant) WeekOf(date time.Time) (time.Time, time.Time) {
+	start := date.AddDate(0, 0, -(int(date.Weekday())-r.WeekStart+7)%7)
+	return start, start.AddDate(0, 0, 6)
+}
+
+// ExportedSinceArchived reports whether a full export was taken after the
+// restaurant was archived, and so after its last possible change
+func (r *Restaurant) ExportedSinceArchived() bool {
+	return r.ArchivedAt != nil && r.ExportedAt != nil && !r.ExportedAt.Before(*r.ArchivedAt)
 }
 
 type RestaurantStore struct {
@@ -26,7 +116,7 @@ func (s *RestaurantStore) Create(ctx context.Context, restaurant *Restaurant) er
 	query := `
 		INSERT INTO restaurants (employer_id, name, address, phone) 
 		VALUES ($1, $2, $3, $4) 
-		RETURNING id, created_at, updated_at;
+		RETURNING id, created_at, updated_at, assignment_policy, notification_mode, digest_hour, timezone, week_start;
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -43,6 +133,11 @@ func (s *RestaurantStore) Create(ctx context.Context, restaurant *Restaurant) er
 		&restaurant.ID,
 		&restaurant.CreatedAt,
 		&restaurant.UpdatedAt,
+		&restaurant.AssignmentPolicy,
+		&restaurant.NotificationMode,
+		&restaurant.DigestHour,
+		&restaurant.Timezone,
+		&restaurant.WeekStart,
 	)
 	if err != nil {
 		return err
@@ -54,7 +149,7 @@ func (s *RestaurantStore) Create(ctx context.Context, restaurant *Restaurant) er
 func (s *RestaurantStore) GetByID(ctx context.Context, id int64) (*Restaurant, error) {
 	query := `
 		SELECT 
-			id, employer_id, name, address, phone, created_at, updated_at, version
+			id, employer_id, name, address, phone, created_at, updated_at, version, archived_at, exported_at, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, latitude, longitude, notification_mode, digest_hour, timezone, rotation_anchor, require_email_verification, holiday_region, holidays_closed, week_start
 		FROM 
 			restaurants
 		WHERE 
@@ -75,6 +170,23 @@ func (s *RestaurantStore) GetByID(ctx context.Context, id int64) (*Restaurant, e
 		&restaurant.CreatedAt,
 		&restaurant.UpdatedAt,
 		&restaurant.Version,
+		&restaurant.ArchivedAt,
+		&restaurant.ExportedAt,
+		&restaurant.ScheduleLockHours,
+		&restaurant.WeeklyLaborBudgetCents,
+		&restaurant.ScheduleRetentionMonths,
+		&restaurant.AssignmentPolicy,
+		&restaurant.StaffMilestoneDigest,
+		&restaurant.Latitude,
+		&restaurant.Longitude,
+		&restaurant.NotificationMode,
+		&restaurant.DigestHour,
+		&restaurant.Timezone,
+		&restaurant.RotationAnchor,
+		&restaurant.RequireEmailVerification,
+		&restaurant.HolidayRegion,
+		&restaurant.HolidaysClosed,
+		&restaurant.WeekStart,
 	)
 
 	if err != nil {
@@ -96,8 +208,23 @@ func (s *RestaurantStore) Update(ctx context.Context, restaurant *Restaurant) er
 			name = $1, 
 			address = $2, 
 			phone = $3,
+			schedule_lock_hours = $4,
+			weekly_labor_budget_cents = $5,
+			schedule_retention_months = $6,
+			assignment_policy = $7,
+			staff_milestone_digest = $8,
+			latitude = $9,
+			longitude = $10,
+			notification_mode = $11,
+			digest_hour = $12,
+			timezone = $13,
+			rotation_anchor = $14,
+			require_email_verification = $15,
+			holiday_region = $16,
+			holidays_closed = $17,
+			week_start = $18,
 			version = version + 1
-		WHERE id = $4 AND version = $5
+		WHERE id = $19 AND version = $20
 		RETURNING version
 	`
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -109,6 +236,21 @@ func (s *RestaurantStore) Update(ctx context.Context, restaurant *Restaurant) er
 		restaurant.Name,
 		restaurant.Address,
 		restaurant.Phone,
+		restaurant.ScheduleLockHours,
+		restaurant.WeeklyLaborBudgetCents,
+		restaurant.ScheduleRetentionMonths,
+		restaurant.AssignmentPolicy,
+		restaurant.StaffMilestoneDigest,
+		restaurant.Latitude,
+		restaurant.Longitude,
+		restaurant.NotificationMode,
+		restaurant.DigestHour,
+		restaurant.Timezone,
+		restaurant.RotationAnchor,
+		restaurant.RequireEmailVerification,
+		restaurant.HolidayRegion,
+		restaurant.HolidaysClosed,
+		restaurant.WeekStart,
 		restaurant.ID,
 		restaurant.Version,
 	).Scan(&restaurant.Version)
@@ -148,11 +290,12 @@ func (s *RestaurantStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *RestaurantStore) ListByUser(ctx context.Context, userID int64) ([]*Restaurant, error) {
+// ListByUser lists the user's active restaurants, or only the archived ones when archived is set
+func (s *RestaurantStore) ListByUser(ctx context.Context, userID int64, archived bool) ([]*Restaurant, error) {
 	query := `
-		SELECT id, employer_id, name, address, phone, created_at, updated_at, version
+		SELECT id, employer_id, name, address, phone, created_at, updated_at, version, archived_at, exported_at, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, latitude, longitude, notification_mode, digest_hour, timezone, rotation_anchor, require_email_verification, holiday_region, holidays_closed, week_start
 		FROM restaurants
-		WHERE employer_id = $1
+		WHERE employer_id = $1 AND (archived_at IS NOT NULL) = $2
 		ORDER BY id ASC
 	`
 
@@ -161,7 +304,7 @@ func (s *RestaurantStore) ListByUser(ctx context.Context, userID int64) ([]*Rest
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	rows, err := s.db.QueryContext(ctx, query, userID, archived)
 	if err != nil {
 		return nil, err
 	}
@@ -170,7 +313,7 @@ func (s *RestaurantStore) ListByUser(ctx context.Context, userID int64) ([]*Rest
 
 	for rows.Next() {
 		var restaurant Restaurant
-		if err := rows.Scan(&restaurant.ID, &restaurant.UserID, &restaurant.Name, &restaurant.Address, &restaurant.Phone, &restaurant.CreatedAt, &restaurant.UpdatedAt, &restaurant.Version); err != nil {
+		if err := rows.Scan(&restaurant.ID, &restaurant.UserID, &restaurant.Name, &restaurant.Address, &restaurant.Phone, &restaurant.CreatedAt, &restaurant.UpdatedAt, &restaurant.Version, &restaurant.ArchivedAt, &restaurant.ExportedAt, &restaurant.ScheduleLockHours, &restaurant.WeeklyLaborBudgetCents, &restaurant.ScheduleRetentionMonths, &restaurant.AssignmentPolicy, &restaurant.StaffMilestoneDigest, &restaurant.Latitude, &restaurant.Longitude, &restaurant.NotificationMode, &restaurant.DigestHour, &restaurant.Timezone, &restaurant.RotationAnchor, &restaurant.RequireEmailVerification, &restaurant.HolidayRegion, &restaurant.HolidaysClosed, &restaurant.WeekStart); err != nil {
 			return nil, err
 		}
 		restaurants = append(restaurants, &restaurant)
@@ -181,4 +324,99 @@ func (s *RestaurantStore) ListByUser(ctx context.Context, userID int64) ([]*Rest
 	}
 
 	return restaurants, nil
-}
\ No newline at end of file
+}
+func (s *RestaurantStore) CountByUser(ctx context.Context, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM restaurants WHERE employer_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SetArchived archives or unarchives the restaurant, recording when on the restaurant
+func (s *RestaurantStore) SetArchived(ctx context.Context, restaurant *Restaurant, archived bool) error {
+	query := `
+		UPDATE restaurants
+		SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) END
+		WHERE id = $1
+		RETURNING archived_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, restaurant.ID, archived).Scan(&restaurant.ArchivedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarkExported records that the restaurant's data was exported in full at exportedAt
+func (s *RestaurantStore) MarkExported(ctx context.Context, restaurant *Restaurant, exportedAt time.Time) error {
+	query := `UPDATE restaurants SET exported_at = $2 WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, restaurant.ID, exportedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	restaurant.ExportedAt = &exportedAt
+	return nil
+}
+
+// ClaimMilestoneDigests marks the active restaurants whose weekly staff
+// milestone digest is due as sent on today and returns them. Claiming before
+// sending keeps two API instances from both sending one.
+func (s *RestaurantStore) ClaimMilestoneDigests(ctx context.Context, today DateOnly) ([]*Restaurant, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE restaurants
+		SET milestone_digest_sent_on = $1
+		WHERE staff_milestone_digest AND archived_at IS NULL
+		  AND (milestone_digest_sent_on IS NULL OR milestone_digest_sent_on <= $1::date - 7)
+		RETURNING id, employer_id, name`
+
+	rows, err := s.db.QueryContext(ctx, query, today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	restaurants := []*Restaurant{}
+	for rows.Next() {
+		restaurant := &Restaurant{StaffMilestoneDigest: true}
+		if err := rows.Scan(&restaurant.ID, &restaurant.UserID, &restaurant.Name); err != nil {
+			return nil, err
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+
+	return restaurants, rows.Err()
+}
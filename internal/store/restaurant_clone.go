@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// RestaurantClone copies a restaurant's setup into a new restaurant: its
+// settings, operating hours, email template, roles, certifications and shift
+// templates, and optionally its employees. Schedules, shifts and events stay behind.
+type RestaurantClone struct {
+	SourceID int64
+	// Restaurant is the new restaurant; the caller sets its owner, name, address and phone
+	Restaurant       *Restaurant
+	IncludeEmployees bool
+	// IDMap is filled by Clone
+	IDMap CloneIDMap
+}
+
+// CloneIDMap takes each copied entity's ID in the source restaurant to its ID in the clone
+type CloneIDMap struct {
+	Roles          map[int64]int64 `json:"roles"`
+	Certifications map[int64]int64 `json:"certifications"`
+	ShiftTemplates map[int64]int64 `json:"shift_templates"`
+	Employees      map[int64]int64 `json:"employees"`
+}
+
+// Clone creates the new restaurant and copies the source's setup into it in
+// one transaction. ErrNotFound means the source doesn't exist.
+func (s *RestaurantStore) Clone(ctx context.Context, clone *RestaurantClone) error {
+	ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+	defer cancel()
+
+	// the copy reads the source and writes the new restaurant
+	ctx = unscoped(ctx)
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		r := clone.Restaurant
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO restaurants (employer_id, name, address, phone, hours_enforcement, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, notification_mode, digest_hour, timezone, rotation_anchor, require_email_verification, holiday_region, holidays_closed, week_start)
+			SELECT $1::bigint, $2::text, $3::text, $4::text, hours_enforcement, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, notification_mode, digest_hour, timezone, rotation_anchor, require_email_verification, holiday_region, holidays_closed, week_start
+			FROM restaurants
+			WHERE id = $5
+			RETURNING id, created_at, updated_at, version, schedule_lock_hours, weekly_labor_budget_cents, schedule_retention_months, assignment_policy, staff_milestone_digest, notification_mode, digest_hour, timezone, rotation_anchor, require_email_verification, holiday_region, holidays_closed, week_start`,
+			r.UserID, r.Name, r.Address, r.Phone, clone.SourceID,
+		).Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt, &r.Version, &r.ScheduleLockHours, &r.WeeklyLaborBudgetCents, &r.ScheduleRetentionMonths, &r.AssignmentPolicy, &r.StaffMilestoneDigest, &r.NotificationMode, &r.DigestHour, &r.Timezone, &r.RotationAnchor, &r.RequireEmailVerification, &r.HolidayRegion, &r.HolidaysClosed, &r.WeekStart)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		for _, query := range []string{
+			`INSERT INTO restaurant_operating_hours (restaurant_id, day_of_week, closed, open_time, close_time)
+			 SELECT $1::bigint, day_of_week, closed, open_time, close_time FROM restaurant_operating_hours WHERE restaurant_id = $2`,
+			`INSERT INTO restaurant_email_templates (restaurant_id, subject, header_message, logo_url, accent_color)
+			 SELECT $1::bigint, subject, header_message, logo_url, accent_color FROM restaurant_email_templates WHERE restaurant_id = $2`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, r.ID, clone.SourceID); err != nil {
+				return err
+			}
+		}
+
+		ids := &clone.IDMap
+		ids.Roles, err = cloneEach(ctx, tx, clone.SourceID, r.ID,
+			`SELECT id FROM roles WHERE restaurant_id = $1 ORDER BY id`,
+			`INSERT INTO roles (restaurant_id, name, color, target_headcount) SELECT $1::bigint, name, color, target_headcount FROM roles WHERE id = $2 RETURNING id`)
+		if err != nil {
+			return err
+		}
+
+		ids.Certifications, err = cloneEach(ctx, tx, clone.SourceID, r.ID,
+			`SELECT id FROM certifications WHERE restaurant_id = $1 ORDER BY id`,
+			`INSERT INTO certifications (restaurant_id, name) SELECT $1::bigint, name FROM certifications WHERE id = $2 RETURNING id`)
+		if err != nil {
+			return err
+		}
+
+		roleOld, roleNew := idPairs(ids.Roles)
+		certOld, certNew := idPairs(ids.Certifications)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO role_certifications (role_id, certification_id)
+			SELECT r.dst, c.dst
+			FROM role_certifications rc
+			JOIN unnest($1::bigint[], $2::bigint[]) AS r(src, dst) ON r.src = rc.role_id
+			JOIN unnest($3::bigint[], $4::bigint[]) AS c(src, dst) ON c.src = rc.certification_id`,
+			roleOld, roleNew, certOld, certNew)
+		if err != nil {
+			return err
+		}
+
+		if ids.ShiftTemplates, err = cloneShiftTemplates(ctx, tx, clone.SourceID, r.ID, ids.Roles); err != nil {
+			return err
+		}
+
+		ids.Employees = map[int64]int64{}
+		if !clone.IncludeEmployees {
+			return nil
+		}
+
+		ids.Employees, err = cloneEach(ctx, tx, clone.SourceID, r.ID,
+			`SELECT id FROM employees WHERE restaurant_id = $1 ORDER BY id`,
+			`INSERT INTO employees (restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date)
+			 SELECT $1::bigint, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date FROM employees WHERE id = $2 RETURNING id`)
+		if err != nil {
+			return err
+		}
+
+		employeeOld, employeeNew := idPairs(ids.Employees)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO employee_roles (employee_id, role_id)
+			SELECT e.dst, r.dst
+			FROM employee_roles er
+			JOIN unnest($1::bigint[], $2::bigint[]) AS e(src, dst) ON e.src = er.employee_id
+			JOIN unnest($3::bigint[], $4::bigint[]) AS r(src, dst) ON r.src = er.role_id`,
+			employeeOld, employeeNew, roleOld, roleNew)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO employee_certifications (employee_id, certification_id, issued_on, expires_on)
+			SELECT e.dst, c.dst, ec.issued_on, ec.expires_on
+			FROM employee_certifications ec
+			JOIN unnest($1::bigint[], $2::bigint[]) AS e(src, dst) ON e.src = ec.employee_id
+			JOIN unnest($3::bigint[], $4::bigint[]) AS c(src, dst) ON c.src = ec.certification_id`,
+			employeeOld, employeeNew, certOld, certNew)
+		return err
+	})
+}
+
+// cloneEach copies the rows list selects from the source restaurant, one at a
+// time through insert, which takes the new restaurant and the source row's ID
+func cloneEach(ctx context.Context, tx *sql.Tx, sourceID, targetID int64, list, insert string) (map[int64]int64, error) {
+	sourceIDs, err := queryIDs(ctx, tx, list, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int64]int64, len(sourceIDs))
+	for _, id := range sourceIDs {
+		var newID int64
+		if err := tx.QueryRowContext(ctx, insert, targetID, id).Scan(&newID); err != nil {
+			return nil, err
+		}
+		ids[id] = newID
+	}
+	return ids, nil
+}
+
+// cloneShiftTemplates copies the source's templates, pointing their role IDs at the cloned roles
+func cloneShiftTemplates(ctx context.Context, tx *sql.Tx, sourceID, targetID int64, roles map[int64]int64) (map[int64]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, role_ids FROM shift_templates WHERE restaurant_id = $1 ORDER BY id`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	type template struct {
+		id      int64
+		roleIDs []int64
+	}
+	var templates []template
+	for rows.Next() {
+		var t template
+		var raw []byte
+		if err := rows.Scan(&t.id, &raw); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &t.roleIDs); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[int64]int64, len(templates))
+	for _, t := range templates {
+		roleIDs := make([]int64, 0, len(t.roleIDs))
+		for _, roleID := range t.roleIDs {
+			if id, ok := roles[roleID]; ok {
+				roleIDs = append(roleIDs, id)
+			}
+		}
+		encoded, err := json.Marshal(roleIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		var newID int64
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO shift_templates (restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, effective_from, effective_until, week_parity)
+			SELECT $1::bigint, name, day_of_week, start_time, end_time, notes, $2::jsonb, effective_from, effective_until, week_parity
+			FROM shift_templates
+			WHERE id = $3
+			RETURNING id`,
+			targetID, string(encoded), t.id,
+		).Scan(&newID)
+		if err != nil {
+			return nil, err
+		}
+		ids[t.id] = newID
+	}
+	return ids, nil
+}
+
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// idPairs splits an ID map into parallel slices for unnest
+func idPairs(ids map[int64]int64) (from, to []int64) {
+	for f, t := range ids {
+		from = append(from, f)
+		to = append(to, t)
+	}
+	return from, to
+}
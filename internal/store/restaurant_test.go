@@ -0,0 +1,30 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRestaurantExportedSinceArchived(t *testing.T) {
+	archived := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	before, after := archived.Add(-time.Hour), archived.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		archivedAt *time.Time
+		exportedAt *time.Time
+		want       bool
+	}{
+		{"active", nil, &after, false},
+		{"archived, never exported", &archived, nil, false},
+		{"exported before archiving", &archived, &before, false},
+		{"exported after archiving", &archived, &after, true},
+	}
+
+	for _, tt := range tests {
+		restaurant := &Restaurant{ArchivedAt: tt.archivedAt, ExportedAt: tt.exportedAt}
+		if got := restaurant.ExportedSinceArchived(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
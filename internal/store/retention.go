@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const AuditEntityRetention = "retention_policy"
+
+// AuditPurged is the action of a retention policy run that removed data
+const AuditPurged = "purged"
+
+// RetentionKind is which data a retention policy purges
+type RetentionKind string
+
+const (
+	// RetentionAnonymizeTerminated erases the personal data of employees terminated AfterMonths ago
+	RetentionAnonymizeTerminated RetentionKind = "anonymize_terminated_employees"
+	// RetentionDeleteTimeEntries deletes clocked-out time entries that started AfterMonths ago
+	RetentionDeleteTimeEntries RetentionKind = "delete_time_entries"
+)
+
+// RetentionPolicy purges one kind of a restaurant's data once it's AfterMonths old
+type RetentionPolicy struct {
+	ID           int64         `json:"id"`
+	RestaurantID int64         `json:"restaurant_id"`
+	Kind         RetentionKind `json:"kind"`
+	AfterMonths  int           `json:"after_months"`
+	Enabled      bool          `json:"enabled"`
+	LastRunAt    *time.Time    `json:"last_run_at,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// Cutoff is the day before which data falls under the policy at now
+func (p *RetentionPolicy) Cutoff(now time.Time) DateOnly {
+	return DateOnly(now.UTC().AddDate(0, -p.AfterMonths, 0).Format("2006-01-02"))
+}
+
+type RetentionStore struct {
+	db *sql.DB
+}
+
+const retentionPolicyColumns = `id, restaurant_id, kind, after_months, enabled, last_run_at, created_at, updated_at`
+
+func scanRetentionPolicy(row interface{ Scan(...any) error }) (*RetentionPolicy, error) {
+	var p RetentionPolicy
+	err := row.Scan(&p.ID, &p.RestaurantID, &p.Kind, &p.AfterMonths, &p.Enabled, &p.LastRunAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (s *RetentionStore) list(ctx context.Context, query string, args ...any) ([]*RetentionPolicy, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := []*RetentionPolicy{}
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// ListByRestaurant returns the restaurant's policies by kind
+func (s *RetentionStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*RetentionPolicy, error) {
+	return s.list(ctx, `SELECT `+retentionPolicyColumns+` FROM retention_policies WHERE restaurant_id = $1 ORDER BY kind`, restaurantID)
+}
+
+// ListEnabled returns every enabled policy of the active restaurants, grouped by restaurant
+func (s *RetentionStore) ListEnabled(ctx context.Context) ([]*RetentionPolicy, error) {
+	return s.list(ctx, `
+		SELECT p.id, p.restaurant_id, p.kind, p.after_months, p.enabled, p.last_run_at, p.created_at, p.updated_at
+		FROM retention_policies p
+		JOIN restaurants r ON r.id = p.restaurant_id
+		WHERE p.enabled AND r.archived_at IS NULL
+		ORDER BY p.restaurant_id, p.kind`)
+}
+
+// Upsert creates the restaurant's policy of the kind or replaces its settings
+func (s *RetentionStore) Upsert(ctx context.Context, policy *RetentionPolicy) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO retention_policies (restaurant_id, kind, after_months, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (restaurant_id, kind)
+		DO UPDATE SET after_months = EXCLUDED.after_months, enabled = EXCLUDED.enabled, updated_at = NOW()
+		RETURNING id, last_run_at, created_at, updated_at`
+
+	return s.db.QueryRowContext(ctx, query, policy.RestaurantID, policy.Kind, policy.AfterMonths, policy.Enabled).
+		Scan(&policy.ID, &policy.LastRunAt, &policy.CreatedAt, &policy.UpdatedAt)
+}
+
+// Delete removes the restaurant's policy of the kind
+func (s *RetentionStore) Delete(ctx context.Context, restaurantID int64, kind RetentionKind) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM retention_policies WHERE restaurant_id = $1 AND kind = $2`, restaurantID, kind)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkRun records when the policy was last applied
+func (s *RetentionStore) MarkRun(ctx context.Context, policy *RetentionPolicy, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, `UPDATE retention_policies SET last_run_at = $2 WHERE id = $1 RETURNING last_run_at`, policy.ID, at).
+		Scan(&policy.LastRunAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// TerminatedBefore returns the restaurant's employees terminated before the day
+// whose data hasn't been erased yet
+func (s *RetentionStore) TerminatedBefore(ctx context.Context, restaurantID int64, before DateOnly) ([]*Employee, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, full_name, terminated_on
+		FROM employees
+		WHERE restaurant_id = $1 AND terminated_on < $2 AND erased_at IS NULL
+		ORDER BY terminated_on, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []*Employee{}
+	for rows.Next() {
+		var e Employee
+		if err := rows.Scan(&e.ID, &e.RestaurantID, &e.FullName, &e.TerminatedOn); err != nil {
+			return nil, err
+		}
+		employees = append(employees, &e)
+	}
+
+	return employees, rows.Err()
+}
+
+// CountTimeEntriesBefore counts the restaurant's clocked-out time entries that
+// started before the day
+func (s *RetentionStore) CountTimeEntriesBefore(ctx context.Context, restaurantID int64, before DateOnly) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM time_entries
+		WHERE restaurant_id = $1 AND clock_out_at IS NOT NULL AND clock_in_at < $2::date`,
+		restaurantID, before,
+	).Scan(&count)
+	return count, err
+}
+
+// DeleteTimeEntriesBefore deletes the restaurant's clocked-out time entries that
+// started before the day, returning how many there were. An open entry stays
+// until it's clocked out.
+func (s *RetentionStore) DeleteTimeEntriesBefore(ctx context.Context, restaurantID int64, before DateOnly) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, `
+		DELETE FROM time_entries
+		WHERE restaurant_id = $1 AND clock_out_at IS NOT NULL AND clock_in_at < $2::date`,
+		restaurantID, before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
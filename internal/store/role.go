@@ -7,17 +7,25 @@ import (
 	"time"
 )
 
+var (
+	ErrDuplicateRole = errors.New("a role with that name already exists")
+)
+
 type Role struct {
     ID           int64     `db:"id" json:"id"`
     RestaurantID int64     `db:"restaurant_id" json:"restaurant_id"`
     Name         string    `db:"name" json:"name"`
     Color        string    `db:"color" json:"color"`
+    // TargetHeadcount is how many employees the restaurant wants able to work the role; nil has no target
+    TargetHeadcount *int   `db:"target_headcount" json:"target_headcount,omitempty"`
     CreatedAt    time.Time `db:"created_at" json:"created_at"`
     UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
 }
 
 type RoleStore struct {
 	db *sql.DB
+	// replica serves reads that tolerate replication lag; nil sends them to db
+	replica *sql.DB
 }
 
 func (s *RoleStore) Create(ctx context.Context, role *Role) error {
@@ -25,8 +33,8 @@ func (s *RoleStore) Create(ctx context.Context, role *Role) error {
 	defer cancel()
 
 	query := `
-		INSERT INTO roles (restaurant_id, name, color, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO roles (restaurant_id, name, color, target_headcount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
 	err := s.db.QueryRowContext(
@@ -35,9 +43,13 @@ func (s *RoleStore) Create(ctx context.Context, role *Role) error {
 		role.RestaurantID,
 		role.Name,
 		role.Color,
+		role.TargetHeadcount,
 	).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
 
 	if err != nil {
+		if isUniqueViolation(err, "uq_roles_restaurant_name") {
+			return ErrDuplicateRole
+		}
 		return err
 	}
 
@@ -49,7 +61,7 @@ func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, name, color, created_at, updated_at
+		SELECT id, restaurant_id, name, color, target_headcount, created_at, updated_at
 		FROM roles
 		WHERE id = $1`
 
@@ -59,6 +71,7 @@ func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 		&role.RestaurantID,
 		&role.Name,
 		&role.Color,
+		&role.TargetHeadcount,
 		&role.CreatedAt,
 		&role.UpdatedAt,
 	)
@@ -73,23 +86,28 @@ func (s *RoleStore) GetByID(ctx context.Context, id int64) (*Role, error) {
 	return &role, nil
 }
 
-func (s *RoleStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Role, error) {
+// GetByIDs loads several roles in one query, in the order of ids; IDs that no longer exist are skipped
+func (s *RoleStore) GetByIDs(ctx context.Context, ids []int64) ([]*Role, error) {
+	if len(ids) == 0 {
+		return []*Role{}, nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, name, color, created_at, updated_at
+		SELECT id, restaurant_id, name, color, target_headcount, created_at, updated_at
 		FROM roles
-		WHERE restaurant_id = $1
-		ORDER BY name`
+		WHERE id = ANY($1::bigint[])
+		ORDER BY array_position($1::bigint[], id)`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	rows, err := s.db.QueryContext(ctx, query, ids)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var roles []*Role
+	roles := []*Role{}
 
 	for rows.Next() {
 		var role Role
@@ -98,6 +116,7 @@ func (s *RoleStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([
 			&role.RestaurantID,
 			&role.Name,
 			&role.Color,
+			&role.TargetHeadcount,
 			&role.CreatedAt,
 			&role.UpdatedAt,
 		)
@@ -114,32 +133,89 @@ func (s *RoleStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([
 	return roles, nil
 }
 
-func (s *RoleStore) Update(ctx context.Context, role *Role) error {
+func (s *RoleStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Role, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
-		UPDATE roles
-		SET name = $1, color = $2, updated_at = NOW()
-		WHERE id = $3
-		RETURNING updated_at`
-
-	err := s.db.QueryRowContext(
-		ctx,
-		query,
-		role.Name,
-		role.Color,
-		role.ID,
-	).Scan(&role.UpdatedAt)
+		SELECT id, restaurant_id, name, color, target_headcount, created_at, updated_at
+		FROM roles
+		WHERE restaurant_id = $1
+		ORDER BY name`
 
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*Role
+
+	for rows.Next() {
+		var role Role
+		err := rows.Scan(
+			&role.ID,
+			&role.RestaurantID,
+			&role.Name,
+			&role.Color,
+			&role.TargetHeadcount,
+			&role.CreatedAt,
+			&role.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
 		}
-		return err
+		roles = append(roles, &role)
 	}
 
-	return nil
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// Update saves the role and propagates name/color to the denormalized copies on scheduled_shifts
+func (s *RoleStore) Update(ctx context.Context, role *Role) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			UPDATE roles
+			SET name = $1, color = $2, target_headcount = $3, updated_at = NOW()
+			WHERE id = $4
+			RETURNING updated_at`
+
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			role.Name,
+			role.Color,
+			role.TargetHeadcount,
+			role.ID,
+		).Scan(&role.UpdatedAt)
+
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			case isUniqueViolation(err, "uq_roles_restaurant_name"):
+				return ErrDuplicateRole
+			default:
+				return err
+			}
+		}
+
+		syncQuery := `
+			UPDATE scheduled_shifts
+			SET role_name = $1, role_color = $2
+			WHERE role_id = $3
+			  AND (role_name IS DISTINCT FROM $1 OR role_color IS DISTINCT FROM $2)`
+
+		_, err = tx.ExecContext(ctx, syncQuery, role.Name, role.Color, role.ID)
+		return err
+	})
 }
 
 func (s *RoleStore) Delete(ctx context.Context, id int64) error {
@@ -205,4 +281,270 @@ func (s *RoleStore) GetEmployees(ctx context.Context, roleID, restaurantID int64
 	}
 
 	return employees, nil
-}
\ No newline at end of file
+}
+
+// RoleStaffing compares a role's roster to its target headcount
+type RoleStaffing struct {
+	RoleID          int64  `json:"role_id"`
+	RoleName        string `json:"role_name"`
+	RoleColor       string `json:"role_color"`
+	TargetHeadcount *int   `json:"target_headcount,omitempty"`
+	// Holders are the employees holding the role who haven't left; Certified are
+	// those of them holding every certification it requires, unexpired
+	Holders   int `json:"holders"`
+	Certified int `json:"certified"`
+	// InTraining are employees without the role who have training shifts in it
+	InTraining int `json:"in_training"`
+	// OpenWeeks are the past weeks in which shifts of the role went unassigned
+	OpenWeeks int `json:"open_weeks"`
+}
+
+// Staffing reports each of the restaurant's roles' roster as of today. Training
+// shifts count from since on, upcoming ones included; open shifts from since
+// to yesterday.
+func (s *RoleStore) Staffing(ctx context.Context, restaurantID int64, today, since DateOnly) ([]*RoleStaffing, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH holders AS (
+			SELECT er.role_id, e.id AS employee_id,
+			       NOT EXISTS (
+			           SELECT 1 FROM role_certifications rc
+			           WHERE rc.role_id = er.role_id
+			             AND NOT EXISTS (
+			                 SELECT 1 FROM employee_certifications ec
+			                 WHERE ec.employee_id = e.id
+			                   AND ec.certification_id = rc.certification_id
+			                   AND (ec.expires_on IS NULL OR ec.expires_on >= $2)
+			             )
+			       ) AS certified
+			FROM employee_roles er
+			JOIN employees e ON e.id = er.employee_id
+			WHERE e.restaurant_id = $1
+			  AND e.erased_at IS NULL
+			  AND (e.terminated_on IS NULL OR e.terminated_on > $2)
+		)
+		SELECT r.id, r.name, r.color, r.target_headcount,
+		       (SELECT COUNT(*) FROM holders h WHERE h.role_id = r.id),
+		       (SELECT COUNT(*) FROM holders h WHERE h.role_id = r.id AND h.certified),
+		       (SELECT COUNT(DISTINCT ss.employee_id)
+		        FROM scheduled_shifts ss
+		        WHERE ss.role_id = r.id AND ss.training AND ss.shift_date >= $3
+		          AND NOT EXISTS (
+		              SELECT 1 FROM employee_roles er
+		              WHERE er.role_id = r.id AND er.employee_id = ss.employee_id
+		          )),
+		       (SELECT COUNT(DISTINCT date_trunc('week', ss.shift_date))
+		        FROM scheduled_shifts ss
+		        WHERE ss.role_id = r.id AND ss.employee_id IS NULL
+		          AND ss.shift_date >= $3 AND ss.shift_date < $2)
+		FROM roles r
+		WHERE r.restaurant_id = $1
+		ORDER BY r.name, r.id`
+
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID, today, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []*RoleStaffing{}
+	for rows.Next() {
+		var role RoleStaffing
+		err := rows.Scan(
+			&role.RoleID,
+			&role.RoleName,
+			&role.RoleColor,
+			&role.TargetHeadcount,
+			&role.Holders,
+			&role.Certified,
+			&role.InTraining,
+			&role.OpenWeeks,
+		)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, &role)
+	}
+
+	return roles, rows.Err()
+}
+
+// RoleUsage is everything that refers to a role, and so is changed when it's deleted
+type RoleUsage struct {
+	RoleID         int64                `json:"role_id"`
+	ShiftTemplates []*RoleUsageTemplate `json:"shift_templates"`
+	Employees      []*RoleUsageEmployee `json:"employees"`
+	// FutureShifts are the role's shifts from today on; PastShifts the ones before
+	FutureShifts int `json:"future_shifts"`
+	PastShifts   int `json:"past_shifts"`
+}
+
+type RoleUsageTemplate struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type RoleUsageEmployee struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+}
+
+// InUse reports whether deleting the role would change or remove anything
+func (u *RoleUsage) InUse() bool {
+	return len(u.ShiftTemplates) > 0 || len(u.Employees) > 0 || u.FutureShifts > 0 || u.PastShifts > 0
+}
+
+// Usage returns the shift templates, employees and shifts that refer to the role;
+// shifts dated before today count as past
+func (s *RoleStore) Usage(ctx context.Context, roleID int64, today DateOnly) (*RoleUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	usage := &RoleUsage{
+		RoleID:         roleID,
+		ShiftTemplates: []*RoleUsageTemplate{},
+		Employees:      []*RoleUsageEmployee{},
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name
+		FROM shift_templates
+		WHERE role_ids @> jsonb_build_array($1::bigint)
+		ORDER BY name, id`, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var template RoleUsageTemplate
+		if err := rows.Scan(&template.ID, &template.Name); err != nil {
+			return nil, err
+		}
+		usage.ShiftTemplates = append(usage.ShiftTemplates, &template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+		SELECT e.id, e.full_name
+		FROM employees e
+		JOIN employee_roles er ON er.employee_id = e.id
+		WHERE er.role_id = $1
+		ORDER BY e.full_name, e.id`, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var employee RoleUsageEmployee
+		if err := rows.Scan(&employee.ID, &employee.FullName); err != nil {
+			return nil, err
+		}
+		usage.Employees = append(usage.Employees, &employee)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FILTER (WHERE shift_date >= $2),
+		       COUNT(*) FILTER (WHERE shift_date < $2)
+		FROM scheduled_shifts
+		WHERE role_id = $1`, roleID, today).Scan(&usage.FutureShifts, &usage.PastShifts)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// Reassign moves everything that refers to the role to another one of the same restaurant,
+// then deletes it, in one transaction: its shifts (past ones included) take the new role,
+// shift templates list the new role instead, and its employees and shift leads get the
+// new role. Certification requirements of the deleted role are dropped, not copied.
+func (s *RoleStore) Reassign(ctx context.Context, roleID, toRoleID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE scheduled_shifts ss
+			SET role_id = r.id, role_name = r.name, role_color = r.color
+			FROM roles r
+			WHERE r.id = $2 AND ss.role_id = $1`, roleID, toRoleID)
+		if err != nil {
+			return err
+		}
+
+		// swap the ID in place, dropping it where the template already lists the new role
+		_, err = tx.ExecContext(ctx, `
+			UPDATE shift_templates t
+			SET role_ids = (
+				SELECT jsonb_agg(id ORDER BY ord)
+				FROM (
+					SELECT DISTINCT ON (id) id, ord
+					FROM (
+						SELECT CASE WHEN e.value = to_jsonb($1::bigint) THEN to_jsonb($2::bigint) ELSE e.value END AS id, e.ord
+						FROM jsonb_array_elements(t.role_ids) WITH ORDINALITY AS e(value, ord)
+					) swapped
+					ORDER BY id, ord
+				) deduped
+			)
+			WHERE t.role_ids @> jsonb_build_array($1::bigint)`, roleID, toRoleID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO employee_roles (employee_id, role_id)
+			SELECT employee_id, $2 FROM employee_roles WHERE role_id = $1
+			ON CONFLICT DO NOTHING`, roleID, toRoleID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO restaurant_member_roles (restaurant_id, user_id, role_id)
+			SELECT restaurant_id, user_id, $2 FROM restaurant_member_roles WHERE role_id = $1
+			ON CONFLICT DO NOTHING`, roleID, toRoleID)
+		if err != nil {
+			return err
+		}
+
+		return deleteRole(ctx, tx, roleID)
+	})
+}
+
+// ForceDelete deletes the role with every shift of it, past ones included, in one
+// transaction. Shift templates drop it and employees lose it.
+func (s *RoleStore) ForceDelete(ctx context.Context, roleID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM scheduled_shifts WHERE role_id = $1`, roleID); err != nil {
+			return err
+		}
+		return deleteRole(ctx, tx, roleID)
+	})
+}
+
+func deleteRole(ctx context.Context, tx *sql.Tx, roleID int64) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE id = $1`, roleID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
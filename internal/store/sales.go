@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Where imported sales came from
+const (
+	SalesSourceCSV     = "csv"
+	SalesSourceAPI     = "api"
+	SalesSourceWebhook = "webhook"
+)
+
+// SalesRecord is a day's or an hour's sales and covers at a restaurant, as its point of
+// sale reported them. Hour is nil for a whole-day total; either figure may be missing.
+type SalesRecord struct {
+	RestaurantID int64     `json:"restaurant_id"`
+	Date         DateOnly  `json:"date"`
+	Hour         *int      `json:"hour,omitempty"`
+	SalesCents   *int64    `json:"sales_cents,omitempty"`
+	Covers       *int      `json:"covers,omitempty"`
+	Source       string    `json:"source"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DemandDay is a date's sales and covers next to the hours scheduled for it. A day
+// with a whole-day record uses it; otherwise its hourly records are added up.
+type DemandDay struct {
+	Date           DateOnly `json:"date"`
+	SalesCents     *int64   `json:"sales_cents,omitempty"`
+	Covers         *int     `json:"covers,omitempty"`
+	ScheduledHours float64  `json:"scheduled_hours"`
+}
+
+type SalesStore struct {
+	db *sql.DB
+}
+
+// Import saves the records, replacing any already imported for the same slot
+func (s *SalesStore) Import(ctx context.Context, records []*SalesRecord) error {
+	ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO sales_records (restaurant_id, sales_date, hour, sales_cents, covers, source)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (restaurant_id, sales_date, (COALESCE(hour, -1))) DO UPDATE
+			SET sales_cents = EXCLUDED.sales_cents,
+			    covers = EXCLUDED.covers,
+			    source = EXCLUDED.source,
+			    updated_at = NOW()
+			RETURNING updated_at`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, r := range records {
+			err := stmt.QueryRowContext(ctx, r.RestaurantID, r.Date, r.Hour, r.SalesCents, r.Covers, r.Source).
+				Scan(&r.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// List returns the restaurant's records dated from through to, by date and hour with
+// the whole-day record first
+func (s *SalesStore) List(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*SalesRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT restaurant_id, sales_date, hour, sales_cents, covers, source, updated_at
+		FROM sales_records
+		WHERE restaurant_id = $1 AND sales_date >= $2::date AND sales_date <= $3::date
+		ORDER BY sales_date, hour NULLS FIRST`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*SalesRecord{}
+	for rows.Next() {
+		var r SalesRecord
+		if err := rows.Scan(&r.RestaurantID, &r.Date, &r.Hour, &r.SalesCents, &r.Covers, &r.Source, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, &r)
+	}
+
+	return records, rows.Err()
+}
+
+// DemandByDay returns every date from through to with its sales, covers and the hours
+// of the shifts scheduled on it
+func (s *SalesStore) DemandByDay(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*DemandDay, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH sales AS (
+			SELECT sales_date,
+			       COALESCE(MAX(sales_cents) FILTER (WHERE hour IS NULL), SUM(sales_cents) FILTER (WHERE hour IS NOT NULL))::bigint AS sales_cents,
+			       COALESCE(MAX(covers) FILTER (WHERE hour IS NULL), SUM(covers) FILTER (WHERE hour IS NOT NULL))::int AS covers
+			FROM sales_records
+			WHERE restaurant_id = $1 AND sales_date >= $2::date AND sales_date <= $3::date
+			GROUP BY sales_date
+		), hours AS (
+			SELECT shift_date, SUM(EXTRACT(EPOCH FROM end_time - start_time) / 3600)::float8 AS hours
+			FROM scheduled_shifts
+			WHERE restaurant_id = $1 AND shift_date >= $2::date AND shift_date <= $3::date
+			  AND end_time > start_time
+			GROUP BY shift_date
+		)
+		SELECT d::date, sales.sales_cents, sales.covers, COALESCE(hours.hours, 0)
+		FROM generate_series($2::date, $3::date, '1 day') AS d
+		LEFT JOIN sales ON sales.sales_date = d::date
+		LEFT JOIN hours ON hours.shift_date = d::date
+		ORDER BY d`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []*DemandDay{}
+	for rows.Next() {
+		var d DemandDay
+		if err := rows.Scan(&d.Date, &d.SalesCents, &d.Covers, &d.ScheduledHours); err != nil {
+			return nil, err
+		}
+		days = append(days, &d)
+	}
+
+	return days, rows.Err()
+}
+
+// SetWebhookToken issues the restaurant's POS token, replacing the old one; only its
+// hash is stored
+func (s *SalesStore) SetWebhookToken(ctx context.Context, restaurantID int64, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO sales_webhooks (restaurant_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (restaurant_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, last_used_at = NULL, created_at = NOW()`
+
+	_, err := s.db.ExecContext(ctx, query, restaurantID, hashToken(token))
+	return err
+}
+
+// DeleteWebhookToken stops the restaurant's POS token from working
+func (s *SalesStore) DeleteWebhookToken(ctx context.Context, restaurantID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM sales_webhooks WHERE restaurant_id = $1`, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateWebhook returns the active restaurant whose POS token this is and records
+// that it was used
+func (s *SalesStore) AuthenticateWebhook(ctx context.Context, token string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE sales_webhooks w
+		SET last_used_at = NOW()
+		FROM restaurants r
+		WHERE r.id = w.restaurant_id
+		  AND w.token_hash = $1
+		  AND r.archived_at IS NULL
+		RETURNING w.restaurant_id`
+
+	var restaurantID int64
+	err := s.db.QueryRowContext(ctx, query, hashToken(token)).Scan(&restaurantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+
+	return restaurantID, nil
+}
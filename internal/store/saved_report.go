@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Kinds of saved report
+const (
+	ReportHoursByEmployee = "hours_by_employee"
+	ReportCoverageByRole  = "coverage_by_role"
+	ReportLaborCost       = "labor_cost"
+)
+
+// Saved report deliveries: none is run on request only
+const (
+	ReportDeliveryNone   = "none"
+	ReportDeliveryWeekly = "weekly"
+)
+
+// SavedReport is a report configuration a manager can run again or have emailed
+type SavedReport struct {
+	ID           int64  `json:"id"`
+	RestaurantID int64  `json:"restaurant_id"`
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	// RoleIDs and EmployeeIDs narrow the shifts reported on; empty for all
+	RoleIDs     []int64 `json:"role_ids"`
+	EmployeeIDs []int64 `json:"employee_ids"`
+	// PeriodDays is how many days the report covers, up to the day before it runs
+	PeriodDays      int    `json:"period_days"`
+	Delivery        string `json:"delivery"`
+	DeliveryWeekday int    `json:"delivery_weekday"`
+	// Recipients are emailed the report; empty for the restaurant's owner
+	Recipients []string  `json:"recipients"`
+	LastSentOn *DateOnly `json:"last_sent_on,omitempty"`
+	CreatedBy  *int64    `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type SavedReportStore struct {
+	db *sql.DB
+}
+
+const savedReportColumns = `id, restaurant_id, name, kind, role_ids, employee_ids, period_days,
+	delivery, delivery_weekday, recipients, last_sent_on, created_by, created_at, updated_at`
+
+func scanSavedReport(row interface{ Scan(...any) error }) (*SavedReport, error) {
+	var r SavedReport
+	err := row.Scan(
+		&r.ID,
+		&r.RestaurantID,
+		&r.Name,
+		&r.Kind,
+		pgArray(&r.RoleIDs),
+		pgArray(&r.EmployeeIDs),
+		&r.PeriodDays,
+		&r.Delivery,
+		&r.DeliveryWeekday,
+		pgArray(&r.Recipients),
+		&r.LastSentOn,
+		&r.CreatedBy,
+		&r.CreatedAt,
+		&r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if r.RoleIDs == nil {
+		r.RoleIDs = []int64{}
+	}
+	if r.EmployeeIDs == nil {
+		r.EmployeeIDs = []int64{}
+	}
+	if r.Recipients == nil {
+		r.Recipients = []string{}
+	}
+	return &r, nil
+}
+
+func (s *SavedReportStore) Create(ctx context.Context, report *SavedReport) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO saved_reports (restaurant_id, name, kind, role_ids, employee_ids, period_days,
+		                           delivery, delivery_weekday, recipients, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		report.RestaurantID,
+		report.Name,
+		report.Kind,
+		report.RoleIDs,
+		report.EmployeeIDs,
+		report.PeriodDays,
+		report.Delivery,
+		report.DeliveryWeekday,
+		report.Recipients,
+		report.CreatedBy,
+	).Scan(&report.ID, &report.CreatedAt, &report.UpdatedAt)
+}
+
+func (s *SavedReportStore) GetByID(ctx context.Context, id int64) (*SavedReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	report, err := scanSavedReport(s.db.QueryRowContext(ctx, `SELECT `+savedReportColumns+` FROM saved_reports WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ListByRestaurant returns the restaurant's saved reports by name
+func (s *SavedReportStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*SavedReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `SELECT ` + savedReportColumns + ` FROM saved_reports WHERE restaurant_id = $1 ORDER BY name, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*SavedReport{}
+	for rows.Next() {
+		report, err := scanSavedReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (s *SavedReportStore) Update(ctx context.Context, report *SavedReport) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE saved_reports
+		SET name = $2, kind = $3, role_ids = $4, employee_ids = $5, period_days = $6,
+		    delivery = $7, delivery_weekday = $8, recipients = $9, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		report.ID,
+		report.Name,
+		report.Kind,
+		report.RoleIDs,
+		report.EmployeeIDs,
+		report.PeriodDays,
+		report.Delivery,
+		report.DeliveryWeekday,
+		report.Recipients,
+	).Scan(&report.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *SavedReportStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM saved_reports WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue marks the weekly reports due on today's weekday that haven't been
+// sent today as sent and returns them, so several instances never send one
+// twice. Reports of archived restaurants are left alone.
+func (s *SavedReportStore) ClaimDue(ctx context.Context, today DateOnly) ([]*SavedReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE saved_reports sr
+		SET last_sent_on = $1
+		FROM restaurants r
+		WHERE r.id = sr.restaurant_id
+		  AND r.archived_at IS NULL
+		  AND sr.delivery = 'weekly'
+		  AND sr.delivery_weekday = EXTRACT(DOW FROM $1::date)
+		  AND (sr.last_sent_on IS NULL OR sr.last_sent_on < $1::date)
+		RETURNING sr.id, sr.restaurant_id, sr.name, sr.kind, sr.role_ids, sr.employee_ids, sr.period_days,
+		          sr.delivery, sr.delivery_weekday, sr.recipients, sr.last_sent_on, sr.created_by, sr.created_at, sr.updated_at`
+
+	rows, err := s.db.QueryContext(ctx, query, today)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*SavedReport{}
+	for rows.Next() {
+		report, err := scanSavedReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
@@ -13,12 +13,18 @@ type Schedule struct {
     StartDate    DateOnly   `db:"start_date" json:"start_date"` // DateOnly auto-normalizes to YYYY-MM-DD
     EndDate      DateOnly   `db:"end_date" json:"end_date"`     // DateOnly auto-normalizes to YYYY-MM-DD
     PublishedAt  *time.Time `db:"published_at" json:"published_at,omitempty"`
+    // Note is shown with the whole week, e.g. "Spring break week"
+    Note         string     `db:"note" json:"note"`
+    // ArchivedAt is set once the schedule is archived: left out of the schedule list but kept
+    ArchivedAt   *time.Time `db:"archived_at" json:"archived_at,omitempty"`
     CreatedAt    time.Time  `db:"created_at" json:"created_at"`
     UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
 }
 
 type ScheduleStore struct {
 	db *sql.DB
+	// replica serves reads that tolerate replication lag; nil sends them to db
+	replica *sql.DB
 }
 
 func (s *ScheduleStore) Create(ctx context.Context, schedule *Schedule) error {
@@ -26,8 +32,8 @@ func (s *ScheduleStore) Create(ctx context.Context, schedule *Schedule) error {
 	defer cancel()
 
 	query := `
-		INSERT INTO schedules (restaurant_id, start_date, end_date, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO schedules (restaurant_id, start_date, end_date, note, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
 	err := s.db.QueryRowContext(
@@ -36,6 +42,7 @@ func (s *ScheduleStore) Create(ctx context.Context, schedule *Schedule) error {
 		schedule.RestaurantID,
 		schedule.StartDate,
 		schedule.EndDate,
+		schedule.Note,
 	).Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
 
 	if err != nil {
@@ -50,7 +57,7 @@ func (s *ScheduleStore) GetByID(ctx context.Context, id int64) (*Schedule, error
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, start_date, end_date, published_at, created_at, updated_at
+		SELECT id, restaurant_id, start_date, end_date, published_at, note, archived_at, created_at, updated_at
 		FROM schedules
 		WHERE id = $1`
 
@@ -61,6 +68,8 @@ func (s *ScheduleStore) GetByID(ctx context.Context, id int64) (*Schedule, error
 		&schedule.StartDate,
 		&schedule.EndDate,
 		&schedule.PublishedAt,
+		&schedule.Note,
+		&schedule.ArchivedAt,
 		&schedule.CreatedAt,
 		&schedule.UpdatedAt,
 	)
@@ -75,17 +84,53 @@ func (s *ScheduleStore) GetByID(ctx context.Context, id int64) (*Schedule, error
 	return &schedule, nil
 }
 
-func (s *ScheduleStore) ListByRestaurant(ctx context.Context, restaurantID int64) ([]*Schedule, error) {
+// GetByDate returns the restaurant's schedule whose week contains the date
+func (s *ScheduleStore) GetByDate(ctx context.Context, restaurantID int64, date DateOnly) (*Schedule, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, start_date, end_date, published_at, created_at, updated_at
+		SELECT id, restaurant_id, start_date, end_date, published_at, note, archived_at, created_at, updated_at
 		FROM schedules
-		WHERE restaurant_id = $1
+		WHERE restaurant_id = $1 AND $2 BETWEEN start_date AND end_date
+		ORDER BY start_date DESC
+		LIMIT 1`
+
+	var schedule Schedule
+	err := s.db.QueryRowContext(ctx, query, restaurantID, date).Scan(
+		&schedule.ID,
+		&schedule.RestaurantID,
+		&schedule.StartDate,
+		&schedule.EndDate,
+		&schedule.PublishedAt,
+		&schedule.Note,
+		&schedule.ArchivedAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// ListByRestaurant returns the restaurant's archived schedules if archived is set, otherwise the rest
+func (s *ScheduleStore) ListByRestaurant(ctx context.Context, restaurantID int64, archived bool) ([]*Schedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, start_date, end_date, published_at, note, archived_at, created_at, updated_at
+		FROM schedules
+		WHERE restaurant_id = $1 AND (archived_at IS NOT NULL) = $2
 		ORDER BY start_date DESC`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID, archived)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +146,8 @@ func (s *ScheduleStore) ListByRestaurant(ctx context.Context, restaurantID int64
 			&schedule.StartDate,
 			&schedule.EndDate,
 			&schedule.PublishedAt,
+			&schedule.Note,
+			&schedule.ArchivedAt,
 			&schedule.CreatedAt,
 			&schedule.UpdatedAt,
 		)
@@ -117,14 +164,22 @@ func (s *ScheduleStore) ListByRestaurant(ctx context.Context, restaurantID int64
 	return schedules, nil
 }
 
+// Covers reports whether date falls within the schedule's start and end dates
+func (s *Schedule) Covers(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	return day >= string(s.StartDate) && day <= string(s.EndDate)
+}
+
+// Update saves the schedule; shrinking it so that shifts fall outside its
+// dates fails with ErrShiftOutsideSchedule
 func (s *ScheduleStore) Update(ctx context.Context, schedule *Schedule) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
 		UPDATE schedules
-		SET start_date = $1, end_date = $2, updated_at = NOW()
-		WHERE id = $3
+		SET start_date = $1, end_date = $2, note = $3, updated_at = NOW()
+		WHERE id = $4
 		RETURNING updated_at`
 
 	err := s.db.QueryRowContext(
@@ -132,6 +187,7 @@ func (s *ScheduleStore) Update(ctx context.Context, schedule *Schedule) error {
 		query,
 		schedule.StartDate,
 		schedule.EndDate,
+		schedule.Note,
 		schedule.ID,
 	).Scan(&schedule.UpdatedAt)
 
@@ -139,12 +195,57 @@ func (s *ScheduleStore) Update(ctx context.Context, schedule *Schedule) error {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
 		}
-		return err
+		return shiftRangeError(err)
 	}
 
 	return nil
 }
 
+// Shrink deletes the schedule's shifts outside its new dates and saves it, in
+// one transaction, returning the IDs of the shifts deleted
+func (s *ScheduleStore) Shrink(ctx context.Context, schedule *Schedule) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	deleted := []int64{}
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			DELETE FROM scheduled_shifts
+			WHERE schedule_id = $1 AND (shift_date < $2::date OR shift_date > $3::date)
+			RETURNING id`, schedule.ID, schedule.StartDate, schedule.EndDate)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			deleted = append(deleted, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE schedules
+			SET start_date = $1, end_date = $2, note = $3, updated_at = NOW()
+			WHERE id = $4
+			RETURNING updated_at`, schedule.StartDate, schedule.EndDate, schedule.Note, schedule.ID).Scan(&schedule.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, shiftRangeError(err)
+	}
+
+	return deleted, nil
+}
+
 func (s *ScheduleStore) Delete(ctx context.Context, id int64) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -168,28 +269,71 @@ func (s *ScheduleStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// Publish marks the schedule published and snapshots its shifts as the baseline for change notifications
 func (s *ScheduleStore) Publish(ctx context.Context, id int64, publishDate time.Time) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		query := `
+			UPDATE schedules
+			SET published_at = $1, updated_at = NOW()
+			WHERE id = $2`
+
+		result, err := tx.ExecContext(ctx, query, publishDate, id)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return insertSnapshot(ctx, tx, id, SnapshotPublished)
+	})
+}
+// ArchiveEndedBefore archives the restaurant's schedules that ended before the cutoff and returns how many it archived
+func (s *ScheduleStore) ArchiveEndedBefore(ctx context.Context, restaurantID int64, cutoff DateOnly) (int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
 		UPDATE schedules
-		SET published_at = $1, updated_at = NOW()
-		WHERE id = $2`
+		SET archived_at = NOW(), updated_at = NOW()
+		WHERE restaurant_id = $1 AND end_date < $2::date AND archived_at IS NULL`
 
-	result, err := s.db.ExecContext(ctx, query, publishDate, id)
+	result, err := s.db.ExecContext(ctx, query, restaurantID, cutoff)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+	return result.RowsAffected()
+}
 
-	if rowsAffected == 0 {
-		return ErrNotFound
+// ArchiveExpired archives, in every restaurant with a retention setting, the
+// schedules that ended more than that many months before now
+func (s *ScheduleStore) ArchiveExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE schedules s
+		SET archived_at = NOW(), updated_at = NOW()
+		FROM restaurants r
+		WHERE r.id = s.restaurant_id
+		  AND r.schedule_retention_months IS NOT NULL
+		  AND s.archived_at IS NULL
+		  AND s.end_date < $1::date - make_interval(months => r.schedule_retention_months)`
+
+	result, err := s.db.ExecContext(ctx, query, now.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
-}
\ No newline at end of file
+	return result.RowsAffected()
+}
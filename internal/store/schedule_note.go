@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ScheduleDayNote is a note on one day of a schedule, e.g. "Health inspection"
+type ScheduleDayNote struct {
+	ScheduleID int64     `json:"schedule_id"`
+	Date       DateOnly  `json:"date"`
+	Note       string    `json:"note"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ScheduleNoteStore struct {
+	db *sql.DB
+}
+
+// ListBySchedule returns the schedule's day notes in date order
+func (s *ScheduleNoteStore) ListBySchedule(ctx context.Context, scheduleID int64) ([]*ScheduleDayNote, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT schedule_id, date, note, created_at, updated_at
+		FROM schedule_day_notes
+		WHERE schedule_id = $1
+		ORDER BY date`
+
+	rows, err := s.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*ScheduleDayNote{}
+	for rows.Next() {
+		var note ScheduleDayNote
+		if err := rows.Scan(&note.ScheduleID, &note.Date, &note.Note, &note.CreatedAt, &note.UpdatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// Upsert sets the note for the day, replacing any note it already has
+func (s *ScheduleNoteStore) Upsert(ctx context.Context, note *ScheduleDayNote) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO schedule_day_notes (schedule_id, date, note)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (schedule_id, date)
+		DO UPDATE SET note = EXCLUDED.note, updated_at = NOW()
+		RETURNING created_at, updated_at`
+
+	return s.db.QueryRowContext(ctx, query, note.ScheduleID, note.Date, note.Note).Scan(
+		&note.CreatedAt,
+		&note.UpdatedAt,
+	)
+}
+
+func (s *ScheduleNoteStore) Delete(ctx context.Context, scheduleID int64, date DateOnly) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM schedule_day_notes WHERE schedule_id = $1 AND date = $2`, scheduleID, date)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
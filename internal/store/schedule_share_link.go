@@ -0,0 +1,147 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ShareLink shows a schedule read-only to anyone holding its token until it
+// expires or is revoked
+type ShareLink struct {
+	ID           int64      `json:"id"`
+	ScheduleID   int64      `json:"schedule_id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type ShareLinkStore struct {
+	db *sql.DB
+}
+
+// Create saves the link; only the token's hash is stored
+func (s *ShareLinkStore) Create(ctx context.Context, link *ShareLink, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO schedule_share_links (schedule_id, restaurant_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, link.ScheduleID, link.RestaurantID, hashToken(token), link.ExpiresAt).
+		Scan(&link.ID, &link.CreatedAt)
+}
+
+func (s *ShareLinkStore) ListBySchedule(ctx context.Context, scheduleID int64) ([]*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, schedule_id, restaurant_id, expires_at, last_viewed_at, revoked_at, created_at
+		FROM schedule_share_links
+		WHERE schedule_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*ShareLink{}
+	for rows.Next() {
+		var l ShareLink
+		if err := rows.Scan(&l.ID, &l.ScheduleID, &l.RestaurantID, &l.ExpiresAt, &l.LastViewedAt, &l.RevokedAt, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, &l)
+	}
+
+	return links, rows.Err()
+}
+
+// SetExpiry moves the expiry of an unrevoked link of the schedule, which
+// also brings an expired link back
+func (s *ShareLinkStore) SetExpiry(ctx context.Context, scheduleID, linkID int64, expiresAt time.Time) (*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE schedule_share_links
+		SET expires_at = $3
+		WHERE id = $1 AND schedule_id = $2 AND revoked_at IS NULL
+		RETURNING id, schedule_id, restaurant_id, expires_at, last_viewed_at, revoked_at, created_at`
+
+	var l ShareLink
+	err := s.db.QueryRowContext(ctx, query, linkID, scheduleID, expiresAt).
+		Scan(&l.ID, &l.ScheduleID, &l.RestaurantID, &l.ExpiresAt, &l.LastViewedAt, &l.RevokedAt, &l.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// Revoke stops the link's token from working
+func (s *ShareLinkStore) Revoke(ctx context.Context, scheduleID, linkID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE schedule_share_links
+		SET revoked_at = NOW()
+		WHERE id = $1 AND schedule_id = $2 AND revoked_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, linkID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the unrevoked link with this token that hasn't expired
+// by now, on a schedule of an active restaurant, and records that it was viewed
+func (s *ShareLinkStore) Authenticate(ctx context.Context, token string, now time.Time) (*ShareLink, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE schedule_share_links l
+		SET last_viewed_at = $2
+		FROM restaurants r
+		WHERE r.id = l.restaurant_id
+		  AND l.token_hash = $1
+		  AND l.revoked_at IS NULL
+		  AND l.expires_at > $2
+		  AND r.archived_at IS NULL
+		RETURNING l.id, l.schedule_id, l.restaurant_id, l.expires_at, l.last_viewed_at, l.revoked_at, l.created_at`
+
+	var l ShareLink
+	err := s.db.QueryRowContext(ctx, query, hashToken(token), now).
+		Scan(&l.ID, &l.ScheduleID, &l.RestaurantID, &l.ExpiresAt, &l.LastViewedAt, &l.RevokedAt, &l.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
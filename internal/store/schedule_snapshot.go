@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	SnapshotPublished = "published"
+	SnapshotNotified  = "notified"
+)
+
+// shiftsJSON aggregates a schedule's shifts ($1) into the SnapshotShift JSON shape
+const shiftsJSON = `
+	SELECT COALESCE(jsonb_agg(jsonb_build_object(
+		'id', ss.id,
+		'role_id', ss.role_id,
+		'role_name', ss.role_name,
+		'role_color', ss.role_color,
+		'employee_id', ss.employee_id,
+		'employee_name', ss.employee_name,
+		'shift_date', ss.shift_date,
+		'start_time', ss.start_time,
+		'end_time', ss.end_time,
+		'training', ss.training,
+		'trainer_name', t.employee_name
+	) ORDER BY ss.id), '[]'::jsonb)
+	FROM scheduled_shifts ss
+	LEFT JOIN scheduled_shifts t ON t.id = ss.trainer_shift_id
+	WHERE ss.schedule_id = $1`
+
+// SnapshotShift is the part of a shift that matters to the employee working it
+type SnapshotShift struct {
+	ID           int64     `json:"id"`
+	RoleID       int64     `json:"role_id"`
+	RoleName     string    `json:"role_name"`
+	RoleColor    string    `json:"role_color"`
+	EmployeeID   *int64    `json:"employee_id,omitempty"`
+	EmployeeName *string   `json:"employee_name,omitempty"`
+	ShiftDate    DateOnly  `json:"shift_date"`
+	StartTime    TimeOfDay `json:"start_time"`
+	EndTime      TimeOfDay `json:"end_time"`
+	Training     bool      `json:"training,omitempty"`
+	TrainerName  *string   `json:"trainer_name,omitempty"`
+}
+
+// ScheduleSnapshot is a schedule's shifts as they were when it was published or changes were announced.
+// Snapshots are never changed once taken.
+type ScheduleSnapshot struct {
+	ID         int64     `json:"id"`
+	ScheduleID int64     `json:"schedule_id"`
+	Reason     string    `json:"reason"`
+	TakenAt    time.Time `json:"taken_at"`
+	// Version counts the schedule's publishes from 1; only set on published snapshots
+	Version int              `json:"version,omitempty"`
+	Shifts  []*SnapshotShift `json:"shifts"`
+}
+
+type ScheduleSnapshotStore struct {
+	db *sql.DB
+}
+
+// Create records the schedule's current shifts
+func (s *ScheduleSnapshotStore) Create(ctx context.Context, scheduleID int64, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	return insertSnapshot(ctx, s.db, scheduleID, reason)
+}
+
+// Latest returns the most recent snapshot taken at or before the given time, or the most recent overall if at is nil
+func (s *ScheduleSnapshotStore) Latest(ctx context.Context, scheduleID int64, at *time.Time) (*ScheduleSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, schedule_id, reason, taken_at, shifts, 0
+		FROM schedule_snapshots
+		WHERE schedule_id = $1 AND ($2::timestamptz IS NULL OR taken_at <= $2)
+		ORDER BY taken_at DESC
+		LIMIT 1`
+
+	return scanSnapshot(s.db.QueryRowContext(ctx, query, scheduleID, at))
+}
+
+// LatestPublished is Latest for the versions the schedule was published as, ignoring
+// snapshots taken when changes were announced: what employees were shown at the time
+func (s *ScheduleSnapshotStore) LatestPublished(ctx context.Context, scheduleID int64, at *time.Time) (*ScheduleSnapshot, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, schedule_id, reason, taken_at, shifts, version
+		FROM (
+			SELECT id, schedule_id, reason, taken_at, shifts,
+			       ROW_NUMBER() OVER (ORDER BY taken_at, id) AS version
+			FROM schedule_snapshots
+			WHERE schedule_id = $1 AND reason = 'published'
+		) published
+		WHERE $2::timestamptz IS NULL OR taken_at <= $2
+		ORDER BY taken_at DESC, id DESC
+		LIMIT 1`
+
+	return scanSnapshot(s.db.QueryRowContext(ctx, query, scheduleID, at))
+}
+
+func scanSnapshot(row *sql.Row) (*ScheduleSnapshot, error) {
+	var snapshot ScheduleSnapshot
+	var shifts []byte
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.ScheduleID,
+		&snapshot.Reason,
+		&snapshot.TakenAt,
+		&shifts,
+		&snapshot.Version,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(shifts, &snapshot.Shifts); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// CurrentShifts returns the schedule's shifts as they are now, in snapshot form
+func (s *ScheduleSnapshotStore) CurrentShifts(ctx context.Context, scheduleID int64) ([]*SnapshotShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var raw []byte
+	if err := s.db.QueryRowContext(ctx, shiftsJSON, scheduleID).Scan(&raw); err != nil {
+		return nil, err
+	}
+
+	var shifts []*SnapshotShift
+	if err := json.Unmarshal(raw, &shifts); err != nil {
+		return nil, err
+	}
+
+	return shifts, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertSnapshot(ctx context.Context, db execer, scheduleID int64, reason string) error {
+	query := `INSERT INTO schedule_snapshots (schedule_id, reason, shifts) VALUES ($1, $2, (` + shiftsJSON + `))`
+
+	_, err := db.ExecContext(ctx, query, scheduleID, reason)
+	return err
+}
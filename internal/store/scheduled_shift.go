@@ -5,12 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
-	ErrForbidden = errors.New("forbidden operation")
+	ErrForbidden           = errors.New("forbidden operation")
+	ErrRoleMismatch        = errors.New("employee does not have the required role for this shift")
+	ErrInvalidTrainerShift = errors.New("trainer shift must be a staffed, overlapping shift for the same role and day")
+	// ErrShiftOutsideSchedule is a shift dated outside its schedule, or a schedule shrunk past its shifts
+	ErrShiftOutsideSchedule = errors.New("shift date must be within the schedule's start and end dates")
 )
 
+// shiftRangeConstraint is what the triggers keeping shifts within their
+// schedule's dates name in the check_violation they raise
+const shiftRangeConstraint = "scheduled_shifts_within_schedule"
+
+// shiftRangeError turns the triggers' check_violation into ErrShiftOutsideSchedule
+func shiftRangeError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == shiftRangeConstraint {
+		return ErrShiftOutsideSchedule
+	}
+	return err
+}
+
 type ScheduledShift struct {
 	ID              int64     `json:"id"`
 	ScheduleID      int64     `json:"schedule_id"`
@@ -28,19 +48,43 @@ type ScheduledShift struct {
 	EmployeeName *string `json:"employee_name,omitempty"`
 	RoleName     string  `json:"role_name"`
 	RoleColor    string  `json:"role_color"`
+	// Training shifts let an employee work a role they don't hold yet, alongside the trainer's shift
+	Training       bool   `json:"training"`
+	TrainerShiftID *int64 `json:"trainer_shift_id,omitempty"`
+	// EventID links extra staffing to the event it covers
+	EventID *int64 `json:"event_id,omitempty"`
+	// Computed on request (?include_conflicts=true), never stored
+	Warnings []ShiftWarning `json:"warnings,omitempty"`
 }
 
+type ShiftWarning string
+
+const (
+	WarningDoubleBooked         ShiftWarning = "double_booked"
+	WarningOvertimeRisk         ShiftWarning = "overtime_risk"
+	WarningRoleMismatch         ShiftWarning = "role_mismatch"
+	WarningCertificationExpired ShiftWarning = "certification_expired"
+	WarningOutsideHours         ShiftWarning = "outside_operating_hours"
+	WarningCompliance           ShiftWarning = "compliance"
+)
+
+// OvertimeHoursThreshold is the scheduled hours per employee in one schedule above which shifts are flagged
+const OvertimeHoursThreshold = 40
+
 type ScheduledShiftStore struct {
 	db *sql.DB
+	// replica serves reads that tolerate replication lag; nil sends them to db
+	replica *sql.DB
 }
 
 func NewScheduledShiftStore(db *sql.DB) *ScheduledShiftStore {
 	return &ScheduledShiftStore{db: db}
 }
 
-// Create inserts a new scheduled shift with denormalized fields populated
+// Create inserts a new scheduled shift with denormalized fields populated; a
+// shift dated outside its schedule fails with ErrShiftOutsideSchedule
 func (s *ScheduledShiftStore) Create(ctx context.Context, shift *ScheduledShift) error {
-	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
 		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 		defer cancel()
 
@@ -65,9 +109,9 @@ func (s *ScheduledShiftStore) Create(ctx context.Context, shift *ScheduledShift)
 			INSERT INTO scheduled_shifts (
 				schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
 				shift_date, start_time, end_time, notes,
-				employee_name, role_name, role_color
+				employee_name, role_name, role_color, event_id
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 			RETURNING id, created_at, updated_at`
 
 		err = tx.QueryRowContext(
@@ -85,6 +129,7 @@ func (s *ScheduledShiftStore) Create(ctx context.Context, shift *ScheduledShift)
 			shift.EmployeeName,
 			shift.RoleName,
 			shift.RoleColor,
+			shift.EventID,
 		).Scan(&shift.ID, &shift.CreatedAt, &shift.UpdatedAt)
 
 		if err != nil {
@@ -93,95 +138,93 @@ func (s *ScheduledShiftStore) Create(ctx context.Context, shift *ScheduledShift)
 
 		return nil
 	})
+
+	return shiftRangeError(err)
 }
 
-// BatchCreate inserts multiple scheduled shifts in one transaction
+// batchCreateChunkSize caps how many inserts are queued in one pgx batch so
+// large auto-populates don't hold every shift's statement in memory at once
+const batchCreateChunkSize = 500
+
+// insertShiftQuery inserts one shift with its denormalized role and employee
+// names. The casts type the parameters, which INSERT ... SELECT doesn't infer.
+const insertShiftQuery = `
+	INSERT INTO scheduled_shifts (
+		schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
+		shift_date, start_time, end_time, notes,
+		employee_name, role_name, role_color
+	)
+	SELECT $1::bigint, $2::bigint, $3::bigint, r.id, $5::bigint,
+		$6::date, $7::time, $8::time, $9::text,
+		(SELECT full_name FROM employees WHERE id = $5::bigint), r.name, r.color
+	FROM roles r
+	WHERE r.id = $4::bigint
+	RETURNING id, created_at, updated_at, employee_name, role_name, role_color`
+
+// BatchCreate inserts multiple scheduled shifts in one transaction, each chunk
+// sent as a pgx batch: one round trip for all of its inserts, which share one
+// prepared statement. The whole transaction shares BatchQueryTimeoutDuration
+// rather than giving every chunk its own QueryTimeoutDuration
 func (s *ScheduledShiftStore) BatchCreate(ctx context.Context, shifts []*ScheduledShift) ([]int64, error) {
-	var createdIDs []int64
-
-	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
-		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
-		defer cancel()
-
-		// Prepare statements for lookups and insert
-		roleQuery := `SELECT name, color FROM roles WHERE id = $1`
-		roleStmt, err := tx.PrepareContext(ctx, roleQuery)
-		if err != nil {
-			return err
-		}
-		defer roleStmt.Close()
-
-		empQuery := `SELECT full_name FROM employees WHERE id = $1`
-		empStmt, err := tx.PrepareContext(ctx, empQuery)
-		if err != nil {
-			return err
-		}
-		defer empStmt.Close()
-
-		insertQuery := `
-			INSERT INTO scheduled_shifts (
-				schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
-				shift_date, start_time, end_time, notes,
-				employee_name, role_name, role_color
-			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-			RETURNING id, created_at, updated_at`
-
-		insertStmt, err := tx.PrepareContext(ctx, insertQuery)
-		if err != nil {
-			return err
-		}
-		defer insertStmt.Close()
-
-		for _, shift := range shifts {
-			// Lookup role for denormalized fields
-			err := roleStmt.QueryRowContext(ctx, shift.RoleID).Scan(&shift.RoleName, &shift.RoleColor)
-			if err != nil {
-				return err
-			}
-
-			// Lookup employee name if assigned
-			if shift.EmployeeID != nil {
-				var empName string
-				err := empStmt.QueryRowContext(ctx, *shift.EmployeeID).Scan(&empName)
-				if err == nil {
-					shift.EmployeeName = &empName
-				}
-			}
+	if len(shifts) == 0 {
+		return nil, nil
+	}
 
-			err = insertStmt.QueryRowContext(
-				ctx,
-				shift.ScheduleID,
-				shift.RestaurantID,
-				shift.ShiftTemplateID,
-				shift.RoleID,
-				shift.EmployeeID,
-				shift.ShiftDate,
-				shift.StartTime,
-				shift.EndTime,
-				shift.Notes,
-				shift.EmployeeName,
-				shift.RoleName,
-				shift.RoleColor,
-			).Scan(&shift.ID, &shift.CreatedAt, &shift.UpdatedAt)
+	ctx, cancel := context.WithTimeout(ctx, BatchQueryTimeoutDuration)
+	defer cancel()
 
-			if err != nil {
+	err := withPgxTx(s.db, ctx, func(tx pgx.Tx) error {
+		for start := 0; start < len(shifts); start += batchCreateChunkSize {
+			end := min(start+batchCreateChunkSize, len(shifts))
+			if err := insertShiftBatch(ctx, tx, shifts[start:end]); err != nil {
 				return err
 			}
-
-			createdIDs = append(createdIDs, shift.ID)
 		}
-
 		return nil
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, shiftRangeError(err)
+	}
+
+	createdIDs := make([]int64, len(shifts))
+	for i, shift := range shifts {
+		createdIDs[i] = shift.ID
 	}
 
 	return createdIDs, nil
 }
 
+// insertShiftBatch queues an insert per shift and sends them together, filling
+// in each shift's ID, timestamps and denormalized names from its row
+func insertShiftBatch(ctx context.Context, tx pgx.Tx, shifts []*ScheduledShift) error {
+	batch := &pgx.Batch{}
+	for _, shift := range shifts {
+		batch.Queue(
+			insertShiftQuery,
+			shift.ScheduleID,
+			shift.RestaurantID,
+			shift.ShiftTemplateID,
+			shift.RoleID,
+			shift.EmployeeID,
+			shift.ShiftDate.Format("2006-01-02"),
+			string(shift.StartTime),
+			string(shift.EndTime),
+			shift.Notes,
+		).QueryRow(func(row pgx.Row) error {
+			err := row.Scan(&shift.ID, &shift.CreatedAt, &shift.UpdatedAt, &shift.EmployeeName, &shift.RoleName, &shift.RoleColor)
+			// The select finds no role for a shift whose role doesn't exist;
+			// fail so the transaction rolls back
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		})
+	}
+
+	return tx.SendBatch(ctx, batch).Close()
+}
+
 // GetByID retrieves a scheduled shift by its ID (no JOINs needed)
 func (s *ScheduledShiftStore) GetByID(ctx context.Context, id int64) (*ScheduledShift, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
@@ -190,8 +233,8 @@ func (s *ScheduledShiftStore) GetByID(ctx context.Context, id int64) (*Scheduled
 	query := `
 		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
 		       shift_date, start_time, end_time, notes,
-		       employee_name, role_name, role_color,
-		       created_at, updated_at
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
 		FROM scheduled_shifts
 		WHERE id = $1`
 
@@ -210,6 +253,9 @@ func (s *ScheduledShiftStore) GetByID(ctx context.Context, id int64) (*Scheduled
 		&shift.EmployeeName,
 		&shift.RoleName,
 		&shift.RoleColor,
+		&shift.Training,
+		&shift.TrainerShiftID,
+		&shift.EventID,
 		&shift.CreatedAt,
 		&shift.UpdatedAt,
 	)
@@ -232,13 +278,13 @@ func (s *ScheduledShiftStore) ListBySchedule(ctx context.Context, scheduleID int
 	query := `
 		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
 		       shift_date, start_time, end_time, notes,
-		       employee_name, role_name, role_color,
-		       created_at, updated_at
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
 		FROM scheduled_shifts
 		WHERE schedule_id = $1
 		ORDER BY shift_date, start_time`
 
-	rows, err := s.db.QueryContext(ctx, query, scheduleID)
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, scheduleID)
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +307,9 @@ func (s *ScheduledShiftStore) ListBySchedule(ctx context.Context, scheduleID int
 			&shift.EmployeeName,
 			&shift.RoleName,
 			&shift.RoleColor,
+			&shift.Training,
+			&shift.TrainerShiftID,
+			&shift.EventID,
 			&shift.CreatedAt,
 			&shift.UpdatedAt,
 		)
@@ -278,22 +327,25 @@ func (s *ScheduledShiftStore) ListBySchedule(ctx context.Context, scheduleID int
 	return shifts, nil
 }
 
-// ListByRestaurantAndWeek retrieves all scheduled shifts for a restaurant within a date range
-// Now uses direct restaurant_id column instead of JOIN through schedules
-func (s *ScheduledShiftStore) ListByRestaurantAndWeek(ctx context.Context, restaurantID int64, weekStart, weekEnd time.Time) ([]*ScheduledShift, error) {
+// ListByRestaurantAndRange retrieves a restaurant's scheduled shifts dated from
+// from through to, inclusive, across schedules. Shift dates are the restaurant's
+// own, in its timezone, so the bounds are too: a week from Restaurant.WeekOf of
+// Restaurant.Today. They are dates rather than times so no timezone conversion
+// can move a shift into the neighbouring week.
+func (s *ScheduledShiftStore) ListByRestaurantAndRange(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*ScheduledShift, error) {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
 	query := `
 		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
 		       shift_date, start_time, end_time, notes,
-		       employee_name, role_name, role_color,
-		       created_at, updated_at
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
 		FROM scheduled_shifts
-		WHERE restaurant_id = $1 AND shift_date BETWEEN $2 AND $3
-		ORDER BY shift_date, start_time`
+		WHERE restaurant_id = $1 AND shift_date >= $2::date AND shift_date <= $3::date
+		ORDER BY shift_date, start_time, id`
 
-	rows, err := s.db.QueryContext(ctx, query, restaurantID, weekStart, weekEnd)
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID, from, to)
 	if err != nil {
 		return nil, err
 	}
@@ -316,6 +368,9 @@ func (s *ScheduledShiftStore) ListByRestaurantAndWeek(ctx context.Context, resta
 			&shift.EmployeeName,
 			&shift.RoleName,
 			&shift.RoleColor,
+			&shift.Training,
+			&shift.TrainerShiftID,
+			&shift.EventID,
 			&shift.CreatedAt,
 			&shift.UpdatedAt,
 		)
@@ -333,7 +388,86 @@ func (s *ScheduledShiftStore) ListByRestaurantAndWeek(ctx context.Context, resta
 	return shifts, nil
 }
 
-// Update updates a scheduled shift's information
+// MinShiftDate and MaxShiftDate bound every shift date, for a range covering all of them
+const (
+	MinShiftDate DateOnly = "0001-01-01"
+	MaxShiftDate DateOnly = "9999-12-31"
+)
+
+// ShiftCursor is where a page of shifts in (shift_date, id) order ends; the next
+// page starts after it
+type ShiftCursor struct {
+	ShiftDate DateOnly
+	ID        int64
+}
+
+// ListPage returns up to limit of a restaurant's shifts dated from through to,
+// ordered by date then ID, starting after the cursor when it isn't nil. Unlike an
+// offset, the cursor seeks straight to its place in the restaurant's
+// (restaurant_id, shift_date, id) index, so a page deep into years of history
+// costs the same as the first.
+func (s *ScheduledShiftStore) ListPage(ctx context.Context, restaurantID int64, from, to DateOnly, after *ShiftCursor, limit int) ([]*ScheduledShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	args := []any{restaurantID, from, to, limit}
+	seek := ""
+	if after != nil {
+		seek = `AND (shift_date, id) > ($5::date, $6)`
+		args = append(args, after.ShiftDate, after.ID)
+	}
+
+	query := `
+		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
+		       shift_date, start_time, end_time, notes,
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
+		FROM scheduled_shifts
+		WHERE restaurant_id = $1 AND shift_date >= $2::date AND shift_date <= $3::date ` + seek + `
+		ORDER BY shift_date, id
+		LIMIT $4`
+
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*ScheduledShift{}
+	for rows.Next() {
+		var shift ScheduledShift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.ScheduleID,
+			&shift.RestaurantID,
+			&shift.ShiftTemplateID,
+			&shift.RoleID,
+			&shift.EmployeeID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.Notes,
+			&shift.EmployeeName,
+			&shift.RoleName,
+			&shift.RoleColor,
+			&shift.Training,
+			&shift.TrainerShiftID,
+			&shift.EventID,
+			&shift.CreatedAt,
+			&shift.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		shifts = append(shifts, &shift)
+	}
+
+	return shifts, rows.Err()
+}
+
+// Update updates a scheduled shift's information; moving it outside its
+// schedule's dates fails with ErrShiftOutsideSchedule
 func (s *ScheduledShiftStore) Update(ctx context.Context, shift *ScheduledShift) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
@@ -341,8 +475,8 @@ func (s *ScheduledShiftStore) Update(ctx context.Context, shift *ScheduledShift)
 	query := `
 		UPDATE scheduled_shifts
 		SET shift_template_id = $1, role_id = $2, employee_id = $3,
-		    shift_date = $4, start_time = $5, end_time = $6, notes = $7
-		WHERE id = $8
+		    shift_date = $4, start_time = $5, end_time = $6, notes = $7, event_id = $8
+		WHERE id = $9
 		RETURNING updated_at`
 
 	err := s.db.QueryRowContext(
@@ -355,6 +489,7 @@ func (s *ScheduledShiftStore) Update(ctx context.Context, shift *ScheduledShift)
 		shift.StartTime,
 		shift.EndTime,
 		shift.Notes,
+		shift.EventID,
 		shift.ID,
 	).Scan(&shift.UpdatedAt)
 
@@ -362,7 +497,7 @@ func (s *ScheduledShiftStore) Update(ctx context.Context, shift *ScheduledShift)
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
 		}
-		return err
+		return shiftRangeError(err)
 	}
 
 	return nil
@@ -392,12 +527,22 @@ func (s *ScheduledShiftStore) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
+// ShiftAssignment is who works a shift. Training assignments skip the role check, so an employee
+// can work a role they don't hold yet next to the trainer's shift.
+type ShiftAssignment struct {
+	EmployeeID     *int64
+	Training       bool
+	TrainerShiftID *int64
+}
+
 // AssignEmployee assigns or unassigns an employee to/from a scheduled shift
 // Also updates the denormalized employee_name field
-func (s *ScheduledShiftStore) AssignEmployee(ctx context.Context, shiftID int64, employeeID *int64) error {
+func (s *ScheduledShiftStore) AssignEmployee(ctx context.Context, shiftID int64, assignment ShiftAssignment) error {
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
+	employeeID := assignment.EmployeeID
+
 	// Validate employee belongs to the restaurant if employee ID is provided
 	if employeeID != nil {
 		ok, err := s.employeeBelongsToShiftRestaurant(ctx, shiftID, *employeeID)
@@ -408,13 +553,25 @@ func (s *ScheduledShiftStore) AssignEmployee(ctx context.Context, shiftID int64,
 			return ErrForbidden
 		}
 
-		// Validate employee has the required role for this shift
-		hasRole, err := s.employeeHasShiftRole(ctx, shiftID, *employeeID)
-		if err != nil {
-			return err
+		// Validate employee has the required role for this shift, unless they're training for it
+		if !assignment.Training {
+			hasRole, err := s.employeeHasShiftRole(ctx, shiftID, *employeeID)
+			if err != nil {
+				return err
+			}
+			if !hasRole {
+				return ErrRoleMismatch
+			}
 		}
-		if !hasRole {
-			return errors.New("employee does not have the required role for this shift")
+
+		if assignment.TrainerShiftID != nil {
+			ok, err := s.validTrainerShift(ctx, shiftID, *assignment.TrainerShiftID, *employeeID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrInvalidTrainerShift
+			}
 		}
 	}
 
@@ -428,14 +585,21 @@ func (s *ScheduledShiftStore) AssignEmployee(ctx context.Context, shiftID int64,
 		}
 	}
 
+	// Unassigning also ends any training arrangement
+	training := employeeID != nil && assignment.Training
+	var trainerShiftID *int64
+	if training {
+		trainerShiftID = assignment.TrainerShiftID
+	}
+
 	query := `
 		UPDATE scheduled_shifts
-		SET employee_id = $1, employee_name = $2
-		WHERE id = $3
+		SET employee_id = $1, employee_name = $2, training = $3, trainer_shift_id = $4
+		WHERE id = $5
 		RETURNING id`
 
 	var id int64
-	err := s.db.QueryRowContext(ctx, query, employeeID, employeeName, shiftID).Scan(&id)
+	err := s.db.QueryRowContext(ctx, query, employeeID, employeeName, training, trainerShiftID, shiftID).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrNotFound
@@ -446,6 +610,32 @@ func (s *ScheduledShiftStore) AssignEmployee(ctx context.Context, shiftID int64,
 	return nil
 }
 
+// validTrainerShift checks the trainer's shift is someone else's, non-training shift for the same role
+// that overlaps the trainee's on the same day
+func (s *ScheduledShiftStore) validTrainerShift(ctx context.Context, shiftID, trainerShiftID, traineeID int64) (bool, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM scheduled_shifts t
+		JOIN scheduled_shifts ss ON ss.id = $1
+		WHERE t.id = $2
+		  AND t.id <> ss.id
+		  AND t.schedule_id = ss.schedule_id
+		  AND t.role_id = ss.role_id
+		  AND t.shift_date = ss.shift_date
+		  AND t.start_time < ss.end_time
+		  AND ss.start_time < t.end_time
+		  AND t.employee_id IS NOT NULL
+		  AND t.employee_id <> $3
+		  AND NOT t.training`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, shiftID, trainerShiftID, traineeID).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // employeeBelongsToShiftRestaurant checks if an employee belongs to the restaurant of a shift
 // Now uses direct restaurant_id column instead of JOIN through schedules
 func (s *ScheduledShiftStore) employeeBelongsToShiftRestaurant(ctx context.Context, shiftID, employeeID int64) (bool, error) {
@@ -486,3 +676,258 @@ func (s *ScheduledShiftStore) employeeHasShiftRole(ctx context.Context, shiftID,
 
 	return count > 0, nil
 }
+
+// DenormalizedRepair reports how many scheduled_shifts rows had drifted from their source tables
+type DenormalizedRepair struct {
+	RoleFields    int64 `json:"role_fields"`
+	EmployeeNames int64 `json:"employee_names"`
+}
+
+// RepairDenormalized re-syncs role_name/role_color/employee_name from roles and employees.
+// A restaurantID of 0 repairs every restaurant.
+func (s *ScheduledShiftStore) RepairDenormalized(ctx context.Context, restaurantID int64) (*DenormalizedRepair, error) {
+	var repair DenormalizedRepair
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		roleQuery := `
+			UPDATE scheduled_shifts ss
+			SET role_name = r.name, role_color = r.color
+			FROM roles r
+			WHERE r.id = ss.role_id
+			  AND ($1 = 0 OR ss.restaurant_id = $1)
+			  AND (ss.role_name IS DISTINCT FROM r.name OR ss.role_color IS DISTINCT FROM r.color)`
+
+		res, err := tx.ExecContext(ctx, roleQuery, restaurantID)
+		if err != nil {
+			return err
+		}
+		if repair.RoleFields, err = res.RowsAffected(); err != nil {
+			return err
+		}
+
+		// Shifts whose employee was removed keep employee_id NULL and must not carry a stale name
+		employeeQuery := `
+			UPDATE scheduled_shifts ss
+			SET employee_name = e.full_name
+			FROM scheduled_shifts src
+			LEFT JOIN employees e ON e.id = src.employee_id
+			WHERE src.id = ss.id
+			  AND ($1 = 0 OR ss.restaurant_id = $1)
+			  AND ss.employee_name IS DISTINCT FROM e.full_name`
+
+		res, err = tx.ExecContext(ctx, employeeQuery, restaurantID)
+		if err != nil {
+			return err
+		}
+		repair.EmployeeNames, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &repair, nil
+}
+
+// ListWarningsBySchedule computes conflict warnings for every assigned shift in a schedule in one query,
+// keyed by shift ID. Shifts without warnings are absent from the map.
+func (s *ScheduledShiftStore) ListWarningsBySchedule(ctx context.Context, scheduleID int64) (map[int64][]ShiftWarning, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH assigned AS (
+			SELECT id, employee_id, role_id, shift_date, start_time, end_time, training
+			FROM scheduled_shifts
+			WHERE schedule_id = $1 AND employee_id IS NOT NULL
+		),
+		hours AS (
+			SELECT employee_id, SUM(EXTRACT(EPOCH FROM end_time - start_time)) / 3600 AS total
+			FROM assigned
+			GROUP BY employee_id
+		)
+		SELECT DISTINCT a.id, 'double_booked'
+		FROM assigned a
+		JOIN assigned b ON b.employee_id = a.employee_id
+			AND b.id <> a.id
+			AND b.shift_date = a.shift_date
+			AND b.start_time < a.end_time
+			AND a.start_time < b.end_time
+		UNION ALL
+		SELECT a.id, 'role_mismatch'
+		FROM assigned a
+		LEFT JOIN employee_roles er ON er.employee_id = a.employee_id AND er.role_id = a.role_id
+		WHERE er.employee_id IS NULL AND NOT a.training
+		UNION ALL
+		SELECT a.id, 'overtime_risk'
+		FROM assigned a
+		JOIN hours h ON h.employee_id = a.employee_id
+		WHERE h.total > $2
+		UNION ALL
+		SELECT DISTINCT a.id, 'certification_expired'
+		FROM assigned a
+		JOIN role_certifications rc ON rc.role_id = a.role_id
+		LEFT JOIN employee_certifications ec
+			ON ec.certification_id = rc.certification_id AND ec.employee_id = a.employee_id
+		WHERE ec.employee_id IS NULL OR ec.expires_on < a.shift_date
+		UNION ALL
+		SELECT ss.id, 'outside_operating_hours'
+		FROM scheduled_shifts ss
+		JOIN restaurants r ON r.id = ss.restaurant_id AND r.hours_enforcement <> 'off'
+		LEFT JOIN restaurant_hours_exceptions x ON x.restaurant_id = ss.restaurant_id AND x.date = ss.shift_date
+		-- a holiday on regular hours (neither closed nor timed) keeps the weekly hours
+		LEFT JOIN restaurant_operating_hours oh ON (x.id IS NULL OR (NOT x.closed AND x.open_time IS NULL))
+			AND oh.restaurant_id = ss.restaurant_id
+			AND oh.day_of_week = EXTRACT(DOW FROM ss.shift_date)
+		WHERE ss.schedule_id = $1
+		  AND (x.closed OR x.open_time IS NOT NULL OR oh.restaurant_id IS NOT NULL)
+		  AND (COALESCE(x.closed, FALSE) OR COALESCE(oh.closed, FALSE)
+		       OR ss.start_time < COALESCE(x.open_time, oh.open_time)
+		       OR ss.end_time > COALESCE(x.close_time, oh.close_time))`
+
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, scheduleID, OvertimeHoursThreshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	warnings := make(map[int64][]ShiftWarning)
+	for rows.Next() {
+		var shiftID int64
+		var warning ShiftWarning
+		if err := rows.Scan(&shiftID, &warning); err != nil {
+			return nil, err
+		}
+		warnings[shiftID] = append(warnings[shiftID], warning)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+// ShiftPattern is a recurring (weekday, time range, role) combination seen in past shifts
+type ShiftPattern struct {
+	DayOfWeek    int       `json:"day_of_week"`
+	StartTime    TimeOfDay `json:"start_time"`
+	EndTime      TimeOfDay `json:"end_time"`
+	RoleID       int64     `json:"role_id"`
+	RoleName     string    `json:"role_name"`
+	WeeksSeen    int       `json:"weeks_seen"`
+	AvgHeadcount float64   `json:"avg_headcount"`
+}
+
+// ListPatterns groups a restaurant's shifts since the given date by weekday, time range and role
+func (s *ScheduledShiftStore) ListPatterns(ctx context.Context, restaurantID int64, since time.Time) ([]*ShiftPattern, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT EXTRACT(DOW FROM shift_date)::int AS day_of_week,
+		       start_time, end_time, role_id, MAX(role_name),
+		       COUNT(DISTINCT date_trunc('week', shift_date)) AS weeks_seen,
+		       COUNT(*)::float / COUNT(DISTINCT shift_date) AS avg_headcount
+		FROM scheduled_shifts
+		WHERE restaurant_id = $1 AND shift_date >= $2::date
+		GROUP BY day_of_week, start_time, end_time, role_id
+		ORDER BY weeks_seen DESC, day_of_week, start_time`
+
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var patterns []*ShiftPattern
+	for rows.Next() {
+		var p ShiftPattern
+		err := rows.Scan(
+			&p.DayOfWeek,
+			&p.StartTime,
+			&p.EndTime,
+			&p.RoleID,
+			&p.RoleName,
+			&p.WeeksSeen,
+			&p.AvgHeadcount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// CoverageCell is how many shifts of a role covered an hour of a weekday over a period,
+// and how many of those had someone assigned
+type CoverageCell struct {
+	RoleID    int64  `json:"role_id"`
+	RoleName  string `json:"role_name"`
+	RoleColor string `json:"role_color"`
+	DayOfWeek int    `json:"day_of_week"`
+	Hour      int    `json:"hour"`
+	Shifts    int    `json:"shifts"`
+	Staffed   int    `json:"staffed"`
+}
+
+// ListCoverage counts a restaurant's shifts dated from through to by role, weekday and
+// hour of day. A shift covers every hour it overlaps, so 09:30-13:00 counts toward 9 to 12.
+func (s *ScheduledShiftStore) ListCoverage(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*CoverageCell, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ss.role_id, MAX(ss.role_name), MAX(ss.role_color),
+		       EXTRACT(DOW FROM ss.shift_date)::int AS day_of_week,
+		       h.hour,
+		       COUNT(*) AS shifts,
+		       COUNT(ss.employee_id) AS staffed
+		FROM scheduled_shifts ss
+		CROSS JOIN LATERAL generate_series(
+			EXTRACT(HOUR FROM ss.start_time)::int,
+			CEIL(EXTRACT(EPOCH FROM ss.end_time) / 3600)::int - 1
+		) AS h(hour)
+		WHERE ss.restaurant_id = $1 AND ss.shift_date >= $2::date AND ss.shift_date <= $3::date
+		GROUP BY ss.role_id, day_of_week, h.hour
+		ORDER BY ss.role_id, day_of_week, h.hour`
+
+	rows, err := readDB(ctx, s.db, s.replica).QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cells := []*CoverageCell{}
+	for rows.Next() {
+		var c CoverageCell
+		err := rows.Scan(
+			&c.RoleID,
+			&c.RoleName,
+			&c.RoleColor,
+			&c.DayOfWeek,
+			&c.Hour,
+			&c.Shifts,
+			&c.Staffed,
+		)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return cells, nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+)
+
+// benchShiftCount is about a month of shifts for a large restaurant
+const benchShiftCount = 2000
+
+// benchShifts builds shifts for the first schedule that has a role to staff
+// them with; like the role benchmarks it needs BENCH_DB_ADDR and a seeded database
+func benchShifts(b *testing.B) (*ScheduledShiftStore, func() []*ScheduledShift) {
+	addr := os.Getenv("BENCH_DB_ADDR")
+	if addr == "" {
+		b.Skip("BENCH_DB_ADDR not set")
+	}
+
+	db, err := sql.Open("pgx", addr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	var scheduleID, restaurantID, roleID int64
+	var startDate time.Time
+	err = db.QueryRow(`
+		SELECT s.id, s.restaurant_id, s.start_date, r.id
+		FROM schedules s
+		JOIN roles r ON r.restaurant_id = s.restaurant_id
+		ORDER BY s.id
+		LIMIT 1`).Scan(&scheduleID, &restaurantID, &startDate, &roleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			b.Skip("no schedules in database, run make seed first")
+		}
+		b.Fatal(err)
+	}
+
+	newShifts := func() []*ScheduledShift {
+		shifts := make([]*ScheduledShift, benchShiftCount)
+		for i := range shifts {
+			shifts[i] = &ScheduledShift{
+				ScheduleID:   scheduleID,
+				RestaurantID: restaurantID,
+				RoleID:       roleID,
+				ShiftDate:    startDate.AddDate(0, 0, i%28),
+				StartTime:    "09:00",
+				EndTime:      "17:00",
+				Notes:        "benchmark",
+			}
+		}
+		return shifts
+	}
+
+	return &ScheduledShiftStore{db: db}, newShifts
+}
+
+func deleteBenchShifts(b *testing.B, s *ScheduledShiftStore, shifts []*ScheduledShift) {
+	ids := make([]int64, 0, len(shifts))
+	for _, shift := range shifts {
+		ids = append(ids, shift.ID)
+	}
+	if _, err := s.db.Exec(`DELETE FROM scheduled_shifts WHERE id = ANY($1)`, ids); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkShiftsCreateLoop is the row-by-row path auto-populate used to take
+func BenchmarkShiftsCreateLoop(b *testing.B) {
+	s, newShifts := benchShifts(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		shifts := newShifts()
+		for _, shift := range shifts {
+			if err := s.Create(ctx, shift); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.StopTimer()
+		deleteBenchShifts(b, s, shifts)
+		b.StartTimer()
+	}
+}
+
+func BenchmarkShiftsBatchCreate(b *testing.B) {
+	s, newShifts := benchShifts(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		shifts := newShifts()
+		if _, err := s.BatchCreate(ctx, shifts); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		deleteBenchShifts(b, s, shifts)
+		b.StartTimer()
+	}
+}
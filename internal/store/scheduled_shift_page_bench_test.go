@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+const (
+	// benchHistoryCount is years of shifts for a very large restaurant
+	benchHistoryCount = 1_000_000
+	benchHistoryPage  = 500
+)
+
+// seedShiftHistory inserts benchHistoryCount shifts, 300 a day back from the first
+// schedule's start, and removes them when the benchmark ends. Like the other shift
+// benchmarks it needs BENCH_DB_ADDR and a seeded database.
+func seedShiftHistory(b *testing.B) (*ScheduledShiftStore, int64) {
+	addr := os.Getenv("BENCH_DB_ADDR")
+	if addr == "" {
+		b.Skip("BENCH_DB_ADDR not set")
+	}
+
+	db, err := sql.Open("pgx", addr)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	var scheduleID, restaurantID int64
+	err = db.QueryRow(`SELECT id, restaurant_id FROM schedules ORDER BY id LIMIT 1`).Scan(&scheduleID, &restaurantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			b.Skip("no schedules in database, run make seed first")
+		}
+		b.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = db.Exec(`
+		INSERT INTO scheduled_shifts (schedule_id, restaurant_id, role_id, shift_date, start_time, end_time, notes, role_name, role_color)
+		SELECT s.id, s.restaurant_id, r.id, s.start_date - (g / 300), '09:00', '17:00', 'history benchmark', r.name, r.color
+		FROM schedules s
+		JOIN LATERAL (SELECT id, name, color FROM roles WHERE restaurant_id = s.restaurant_id ORDER BY id LIMIT 1) r ON true
+		CROSS JOIN generate_series(1, $2) AS g
+		WHERE s.id = $1`, scheduleID, benchHistoryCount)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		if _, err := db.Exec(`DELETE FROM scheduled_shifts WHERE schedule_id = $1 AND notes = 'history benchmark'`, scheduleID); err != nil {
+			b.Error(err)
+		}
+	})
+	if _, err := db.Exec(`ANALYZE scheduled_shifts`); err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("seeded %d shifts in %s", benchHistoryCount, time.Since(start).Round(time.Millisecond))
+
+	return &ScheduledShiftStore{db: db}, restaurantID
+}
+
+// BenchmarkShiftHistoryPage reads one page of the history at increasing depths,
+// by OFFSET and by keyset cursor. The offset pages slow down with depth; the
+// cursor pages don't.
+func BenchmarkShiftHistoryPage(b *testing.B) {
+	s, restaurantID := seedShiftHistory(b)
+	ctx := context.Background()
+
+	for _, depth := range []int{0, 100_000, 900_000} {
+		b.Run(fmt.Sprintf("offset/%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				rows, err := s.db.QueryContext(ctx, `
+					SELECT id, shift_date, start_time, end_time, role_name
+					FROM scheduled_shifts
+					WHERE restaurant_id = $1
+					ORDER BY shift_date, id
+					OFFSET $2 LIMIT $3`, restaurantID, depth, benchHistoryPage)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for rows.Next() {
+				}
+				rows.Close()
+			}
+		})
+
+		b.Run(fmt.Sprintf("keyset/%d", depth), func(b *testing.B) {
+			var after *ShiftCursor
+			if depth > 0 {
+				var date time.Time
+				var id int64
+				err := s.db.QueryRowContext(ctx, `
+					SELECT shift_date, id FROM scheduled_shifts
+					WHERE restaurant_id = $1
+					ORDER BY shift_date, id
+					OFFSET $2 LIMIT 1`, restaurantID, depth-1).Scan(&date, &id)
+				if err != nil {
+					b.Fatal(err)
+				}
+				after = &ShiftCursor{ShiftDate: DateOnly(date.Format("2006-01-02")), ID: id}
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.ListPage(ctx, restaurantID, MinShiftDate, MaxShiftDate, after, benchHistoryPage); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
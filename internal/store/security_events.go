@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	SecurityEventSignIn                   = "sign_in"
+	SecurityEventSessionRevoked           = "session_revoked"
+	SecurityEventTwoFactorEnabled         = "two_factor_enabled"
+	SecurityEventTwoFactorDisabled        = "two_factor_disabled"
+	SecurityEventRecoveryCodesRegenerated = "recovery_codes_regenerated"
+)
+
+// SecurityEvent is a sign-in or security change on an account, with the
+// device that made it
+type SecurityEvent struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	Type      string    `json:"type"`
+	SessionID *int64    `json:"session_id,omitempty"`
+	UserAgent string    `json:"user_agent"`
+	IPAddress string    `json:"ip_address"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SecurityEventStore struct {
+	db *sql.DB
+}
+
+func (s *SecurityEventStore) Record(ctx context.Context, event *SecurityEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_security_events (user_id, type, session_id, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, event.UserID, event.Type, event.SessionID, event.UserAgent, event.IPAddress).
+		Scan(&event.ID, &event.CreatedAt)
+}
+
+// ListByUser returns the user's latest events, newest first
+func (s *SecurityEventStore) ListByUser(ctx context.Context, userID int64, limit int) ([]*SecurityEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, type, session_id, user_agent, ip_address, created_at
+		FROM user_security_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*SecurityEvent{}
+	for rows.Next() {
+		var event SecurityEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.Type,
+			&event.SessionID,
+			&event.UserAgent,
+			&event.IPAddress,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
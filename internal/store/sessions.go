@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// sessionTouchInterval keeps last_used_at from being written on every request
+const sessionTouchInterval = time.Minute
+
+// Session is a signed-in device
+type Session struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+func (s *SessionStore) Create(ctx context.Context, session *Session) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_sessions (user_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, last_used_at`
+
+	return s.db.QueryRowContext(ctx, query, session.UserID, session.UserAgent, session.IPAddress, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt)
+}
+
+// Authenticate checks the user's session is neither revoked nor expired and
+// notes that it was used. ErrNotFound means the device was signed out.
+func (s *SessionStore) Authenticate(ctx context.Context, sessionID, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT last_used_at
+		FROM user_sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	var lastUsedAt time.Time
+	if err := s.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&lastUsedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if time.Since(lastUsedAt) < sessionTouchInterval {
+		return nil
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE user_sessions SET last_used_at = NOW() WHERE id = $1`, sessionID)
+	return err
+}
+
+// Extend moves an active session's expiry, as a token refresh does
+func (s *SessionStore) Extend(ctx context.Context, sessionID, userID int64, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE user_sessions
+		SET expires_at = $3, last_used_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	result, err := s.db.ExecContext(ctx, query, sessionID, userID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListActive returns the user's unrevoked, unexpired sessions, most recently used first
+func (s *SessionStore) ListActive(ctx context.Context, userID int64) ([]*Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC, id DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*Session{}
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastUsedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Revoke signs the user's session out; ErrNotFound means it isn't theirs or already ended
+func (s *SessionStore) Revoke(ctx context.Context, userID, sessionID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE user_sessions
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	result, err := s.db.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
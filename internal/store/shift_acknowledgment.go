@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// EmployeeShift is a shift on a published schedule as the employee working it
+// sees it in the employee portal
+type EmployeeShift struct {
+	ID             int64      `json:"id"`
+	ScheduleID     int64      `json:"schedule_id"`
+	RestaurantID   int64      `json:"restaurant_id"`
+	RestaurantName string     `json:"restaurant_name"`
+	EmployeeID     int64      `json:"employee_id"`
+	ShiftDate      DateOnly   `json:"shift_date"`
+	StartTime      TimeOfDay  `json:"start_time"`
+	EndTime        TimeOfDay  `json:"end_time"`
+	RoleName       string     `json:"role_name"`
+	RoleColor      string     `json:"role_color"`
+	Notes          string     `json:"notes"`
+	Training       bool       `json:"training"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	// FeedbackRating is the rating the employee gave the shift after working it
+	FeedbackRating *int `json:"feedback_rating,omitempty"`
+}
+
+// ShiftAcknowledgment records that an employee has seen a shift assigned to them
+type ShiftAcknowledgment struct {
+	ShiftID        int64     `json:"shift_id"`
+	EmployeeID     int64     `json:"employee_id"`
+	AcknowledgedAt time.Time `json:"acknowledged_at"`
+}
+
+// ShiftAcknowledgmentStatus is an assigned shift of a schedule and whether its
+// employee has acknowledged it
+type ShiftAcknowledgmentStatus struct {
+	ShiftID        int64      `json:"shift_id"`
+	EmployeeID     int64      `json:"employee_id"`
+	EmployeeName   string     `json:"employee_name"`
+	ShiftDate      DateOnly   `json:"shift_date"`
+	StartTime      TimeOfDay  `json:"start_time"`
+	EndTime        TimeOfDay  `json:"end_time"`
+	RoleName       string     `json:"role_name"`
+	RoleColor      string     `json:"role_color"`
+	Training       bool       `json:"training"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+type ShiftAcknowledgmentStore struct {
+	db *sql.DB
+}
+
+// ListUpcomingForEmail returns the shifts from the given date on, on published
+// schedules of active restaurants, assigned to any employee record with this
+// email address
+func (s *ShiftAcknowledgmentStore) ListUpcomingForEmail(ctx context.Context, email string, from DateOnly) ([]*EmployeeShift, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ss.id, ss.schedule_id, ss.restaurant_id, r.name, ss.employee_id,
+		       ss.shift_date, ss.start_time, ss.end_time, ss.role_name, ss.role_color,
+		       COALESCE(ss.notes, ''), ss.training, sa.acknowledged_at, sf.rating
+		FROM scheduled_shifts ss
+		JOIN schedules s ON s.id = ss.schedule_id
+		JOIN employees e ON e.id = ss.employee_id
+		JOIN restaurants r ON r.id = ss.restaurant_id
+		LEFT JOIN shift_acknowledgments sa ON sa.shift_id = ss.id AND sa.employee_id = ss.employee_id
+		LEFT JOIN shift_feedback sf ON sf.shift_id = ss.id AND sf.employee_id = ss.employee_id
+		WHERE LOWER(e.email) = LOWER($1)
+		  AND s.published_at IS NOT NULL
+		  AND r.archived_at IS NULL
+		  AND ss.shift_date >= $2
+		ORDER BY ss.shift_date, ss.start_time, ss.id`
+
+	rows, err := s.db.QueryContext(ctx, query, email, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shifts := []*EmployeeShift{}
+	for rows.Next() {
+		shift := &EmployeeShift{}
+		if err := rows.Scan(
+			&shift.ID,
+			&shift.ScheduleID,
+			&shift.RestaurantID,
+			&shift.RestaurantName,
+			&shift.EmployeeID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.RoleName,
+			&shift.RoleColor,
+			&shift.Notes,
+			&shift.Training,
+			&shift.AcknowledgedAt,
+			&shift.FeedbackRating,
+		); err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+
+	return shifts, rows.Err()
+}
+
+// Acknowledge records that the employee assigned to the shift has seen it. The
+// shift must be on a published schedule and assigned to an employee record
+// with this email address, or ErrNotFound is returned. Acknowledging again
+// keeps the first time.
+func (s *ShiftAcknowledgmentStore) Acknowledge(ctx context.Context, shiftID int64, email string) (*ShiftAcknowledgment, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH target AS (
+			SELECT ss.id, ss.employee_id
+			FROM scheduled_shifts ss
+			JOIN schedules s ON s.id = ss.schedule_id
+			JOIN employees e ON e.id = ss.employee_id
+			JOIN restaurants r ON r.id = ss.restaurant_id
+			WHERE ss.id = $1
+			  AND LOWER(e.email) = LOWER($2)
+			  AND s.published_at IS NOT NULL
+			  AND r.archived_at IS NULL
+		), inserted AS (
+			INSERT INTO shift_acknowledgments (shift_id, employee_id)
+			SELECT id, employee_id FROM target
+			ON CONFLICT (shift_id, employee_id) DO NOTHING
+			RETURNING acknowledged_at
+		)
+		SELECT t.id, t.employee_id, COALESCE(
+			(SELECT acknowledged_at FROM inserted),
+			(SELECT sa.acknowledged_at FROM shift_acknowledgments sa
+			 WHERE sa.shift_id = t.id AND sa.employee_id = t.employee_id)
+		)
+		FROM target t`
+
+	ack := &ShiftAcknowledgment{}
+	err := s.db.QueryRowContext(ctx, query, shiftID, email).Scan(&ack.ShiftID, &ack.EmployeeID, &ack.AcknowledgedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return ack, nil
+}
+
+// ListBySchedule returns every assigned shift of the schedule with its
+// acknowledgment, in schedule order
+func (s *ShiftAcknowledgmentStore) ListBySchedule(ctx context.Context, scheduleID int64) ([]*ShiftAcknowledgmentStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ss.id, ss.employee_id, e.full_name, ss.shift_date, ss.start_time, ss.end_time,
+		       ss.role_name, ss.role_color, ss.training, sa.acknowledged_at
+		FROM scheduled_shifts ss
+		JOIN employees e ON e.id = ss.employee_id
+		LEFT JOIN shift_acknowledgments sa ON sa.shift_id = ss.id AND sa.employee_id = ss.employee_id
+		WHERE ss.schedule_id = $1
+		ORDER BY ss.shift_date, ss.start_time, ss.id`
+
+	rows, err := s.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := []*ShiftAcknowledgmentStatus{}
+	for rows.Next() {
+		status := &ShiftAcknowledgmentStatus{}
+		if err := rows.Scan(
+			&status.ShiftID,
+			&status.EmployeeID,
+			&status.EmployeeName,
+			&status.ShiftDate,
+			&status.StartTime,
+			&status.EndTime,
+			&status.RoleName,
+			&status.RoleColor,
+			&status.Training,
+			&status.AcknowledgedAt,
+		); err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrShiftNotEnded = errors.New("feedback can be given once the shift has ended")
+
+// ShiftFeedback is the rating (1-5) and comment an employee gave a shift they worked
+type ShiftFeedback struct {
+	ShiftID      int64     `json:"shift_id"`
+	EmployeeID   int64     `json:"employee_id"`
+	RestaurantID int64     `json:"restaurant_id"`
+	Rating       int       `json:"rating"`
+	Comment      string    `json:"comment"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ShiftFeedbackEntry is a piece of feedback with the shift it is about, as a
+// manager reviews it
+type ShiftFeedbackEntry struct {
+	ShiftID      int64     `json:"shift_id"`
+	EmployeeID   int64     `json:"employee_id"`
+	EmployeeName string    `json:"employee_name"`
+	ShiftDate    DateOnly  `json:"shift_date"`
+	StartTime    TimeOfDay `json:"start_time"`
+	EndTime      TimeOfDay `json:"end_time"`
+	RoleName     string    `json:"role_name"`
+	Rating       int       `json:"rating"`
+	Comment      string    `json:"comment"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type ShiftFeedbackStore struct {
+	db *sql.DB
+}
+
+// Submit records feedback.Rating and feedback.Comment from the employee assigned
+// to the shift, replacing what they gave before. The shift must be on a published
+// schedule and assigned to an employee record with this email address, or
+// ErrNotFound is returned; until it has ended by now (read as a wall clock time,
+// like shift times) ErrShiftNotEnded is.
+func (s *ShiftFeedbackStore) Submit(ctx context.Context, feedback *ShiftFeedback, email string, now time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		WITH target AS (
+			SELECT ss.id, ss.employee_id, ss.restaurant_id,
+			       ss.shift_date + ss.end_time <= $3::timestamp AS ended
+			FROM scheduled_shifts ss
+			JOIN schedules s ON s.id = ss.schedule_id
+			JOIN employees e ON e.id = ss.employee_id
+			JOIN restaurants r ON r.id = ss.restaurant_id
+			WHERE ss.id = $1
+			  AND LOWER(e.email) = LOWER($2)
+			  AND s.published_at IS NOT NULL
+			  AND r.archived_at IS NULL
+		), saved AS (
+			INSERT INTO shift_feedback (shift_id, employee_id, restaurant_id, rating, comment)
+			SELECT id, employee_id, restaurant_id, $4, $5 FROM target WHERE ended
+			ON CONFLICT (shift_id, employee_id) DO UPDATE
+			SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, updated_at = NOW()
+			RETURNING created_at, updated_at
+		)
+		SELECT t.id, t.employee_id, t.restaurant_id, t.ended,
+		       (SELECT created_at FROM saved), (SELECT updated_at FROM saved)
+		FROM target t`
+
+	var ended bool
+	var createdAt, updatedAt sql.NullTime
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		feedback.ShiftID,
+		email,
+		now.Format("2006-01-02 15:04:05"),
+		feedback.Rating,
+		feedback.Comment,
+	).Scan(&feedback.ShiftID, &feedback.EmployeeID, &feedback.RestaurantID, &ended, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if !ended {
+		return ErrShiftNotEnded
+	}
+
+	feedback.CreatedAt, feedback.UpdatedAt = createdAt.Time, updatedAt.Time
+	return nil
+}
+
+// ListByRestaurant returns the feedback on the restaurant's shifts dated from
+// through to, by shift date and role
+func (s *ShiftFeedbackStore) ListByRestaurant(ctx context.Context, restaurantID int64, from, to DateOnly) ([]*ShiftFeedbackEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT sf.shift_id, sf.employee_id, e.full_name, ss.shift_date, ss.start_time, ss.end_time,
+		       ss.role_name, sf.rating, sf.comment, sf.updated_at
+		FROM shift_feedback sf
+		JOIN scheduled_shifts ss ON ss.id = sf.shift_id
+		JOIN employees e ON e.id = sf.employee_id
+		WHERE sf.restaurant_id = $1
+		  AND ss.shift_date BETWEEN $2 AND $3
+		ORDER BY ss.shift_date, ss.role_name, ss.start_time, sf.shift_id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*ShiftFeedbackEntry{}
+	for rows.Next() {
+		entry := &ShiftFeedbackEntry{}
+		if err := rows.Scan(
+			&entry.ShiftID,
+			&entry.EmployeeID,
+			&entry.EmployeeName,
+			&entry.ShiftDate,
+			&entry.StartTime,
+			&entry.EndTime,
+			&entry.RoleName,
+			&entry.Rating,
+			&entry.Comment,
+			&entry.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
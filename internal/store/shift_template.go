@@ -17,8 +17,68 @@ type ShiftTemplate struct {
 	EndTime      TimeOfDay `json:"end_time"`
 	Notes        string    `json:"notes"`
 	RoleIDs      []int64   `json:"role_ids"` // Stored as JSONB column
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	// A seasonal template only applies from EffectiveFrom to EffectiveUntil, inclusive; nil leaves that side open
+	EffectiveFrom  *DateOnly `json:"effective_from,omitempty"`
+	EffectiveUntil *DateOnly `json:"effective_until,omitempty"`
+	// WeekParity limits the template to week A or B of the restaurant's two-week rotation; nil is every week
+	WeekParity *WeekParity `json:"week_parity,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// WeekParity is a week of a restaurant's alternating two-week rotation
+type WeekParity string
+
+const (
+	// WeekA is the week starting on the rotation anchor, and every other week from it
+	WeekA WeekParity = "a"
+	// WeekB is the week after each week A
+	WeekB WeekParity = "b"
+)
+
+// RotationWeek is the week of the rotation anchored at anchor that date falls
+// in, counting seven-day weeks from the anchor in both directions
+func RotationWeek(anchor, date DateOnly) (WeekParity, error) {
+	from, err := anchor.ToTime()
+	if err != nil {
+		return "", err
+	}
+	to, err := date.ToTime()
+	if err != nil {
+		return "", err
+	}
+
+	days := int(to.Sub(from).Hours() / 24)
+	week := days / 7
+	if days < 0 && days%7 != 0 {
+		week--
+	}
+	if week%2 == 0 {
+		return WeekA, nil
+	}
+	return WeekB, nil
+}
+
+// ActiveOn reports whether the template applies on date, given the
+// restaurant's rotation anchor. A template for week A or B never applies while
+// the restaurant has no anchor.
+func (t *ShiftTemplate) ActiveOn(date DateOnly, anchor *DateOnly) bool {
+	if t.EffectiveFrom != nil && date < *t.EffectiveFrom {
+		return false
+	}
+	if t.EffectiveUntil != nil && date > *t.EffectiveUntil {
+		return false
+	}
+	if t.WeekParity != nil {
+		if anchor == nil {
+			return false
+		}
+		week, err := RotationWeek(*anchor, date)
+		if err != nil || week != *t.WeekParity {
+			return false
+		}
+	}
+	return true
 }
 
 type ShiftTemplateStore struct {
@@ -44,8 +104,8 @@ func (s *ShiftTemplateStore) Create(ctx context.Context, template *ShiftTemplate
 	}
 
 	query := `
-		INSERT INTO shift_templates (restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO shift_templates (restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, effective_from, effective_until, week_parity)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at`
 
 	err = s.db.QueryRowContext(
@@ -58,6 +118,9 @@ func (s *ShiftTemplateStore) Create(ctx context.Context, template *ShiftTemplate
 		template.EndTime,
 		template.Notes,
 		roleIDsJSON,
+		template.EffectiveFrom,
+		template.EffectiveUntil,
+		template.WeekParity,
 	).Scan(&template.ID, &template.CreatedAt, &template.UpdatedAt)
 
 	if err != nil {
@@ -72,7 +135,7 @@ func (s *ShiftTemplateStore) GetByID(ctx context.Context, id int64) (*ShiftTempl
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, created_at, updated_at
+		SELECT id, restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, effective_from, effective_until, week_parity, created_at, updated_at
 		FROM shift_templates
 		WHERE id = $1`
 
@@ -87,6 +150,9 @@ func (s *ShiftTemplateStore) GetByID(ctx context.Context, id int64) (*ShiftTempl
 		&template.EndTime,
 		&template.Notes,
 		&roleIDsJSON,
+		&template.EffectiveFrom,
+		&template.EffectiveUntil,
+		&template.WeekParity,
 		&template.CreatedAt,
 		&template.UpdatedAt,
 	)
@@ -116,7 +182,7 @@ func (s *ShiftTemplateStore) ListByRestaurant(ctx context.Context, restaurantID
 	defer cancel()
 
 	query := `
-		SELECT id, restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, created_at, updated_at
+		SELECT id, restaurant_id, name, day_of_week, start_time, end_time, notes, role_ids, effective_from, effective_until, week_parity, created_at, updated_at
 		FROM shift_templates
 		WHERE restaurant_id = $1
 		ORDER BY day_of_week, start_time`
@@ -141,6 +207,9 @@ func (s *ShiftTemplateStore) ListByRestaurant(ctx context.Context, restaurantID
 			&template.EndTime,
 			&template.Notes,
 			&roleIDsJSON,
+			&template.EffectiveFrom,
+			&template.EffectiveUntil,
+			&template.WeekParity,
 			&template.CreatedAt,
 			&template.UpdatedAt,
 		)
@@ -183,8 +252,9 @@ func (s *ShiftTemplateStore) Update(ctx context.Context, template *ShiftTemplate
 
 	query := `
 		UPDATE shift_templates
-		SET name = $1, day_of_week = $2, start_time = $3, end_time = $4, notes = $5, role_ids = $6, updated_at = NOW()
-		WHERE id = $7
+		SET name = $1, day_of_week = $2, start_time = $3, end_time = $4, notes = $5, role_ids = $6,
+		    effective_from = $7, effective_until = $8, week_parity = $9, updated_at = NOW()
+		WHERE id = $10
 		RETURNING updated_at`
 
 	err = s.db.QueryRowContext(
@@ -196,6 +266,9 @@ func (s *ShiftTemplateStore) Update(ctx context.Context, template *ShiftTemplate
 		template.EndTime,
 		template.Notes,
 		roleIDsJSON,
+		template.EffectiveFrom,
+		template.EffectiveUntil,
+		template.WeekParity,
 		template.ID,
 	).Scan(&template.UpdatedAt)
 
@@ -231,3 +304,98 @@ func (s *ShiftTemplateStore) Delete(ctx context.Context, id int64) error {
 
 	return nil
 }
+
+// Merge folds the merged templates into the kept one in a single transaction:
+// the kept template takes the union of their roles, scheduled shifts made from
+// them are re-pointed to it, and they are deleted. It returns the number of
+// scheduled shifts re-pointed and updates template with the saved roles.
+func (s *ShiftTemplateStore) Merge(ctx context.Context, template *ShiftTemplate, mergeIDs []int64) (int64, error) {
+	var repointed int64
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT role_ids
+			FROM shift_templates
+			WHERE id = ANY($1) AND restaurant_id = $2
+			FOR UPDATE`, mergeIDs, template.RestaurantID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		found := 0
+		roleIDs := append([]int64{}, template.RoleIDs...)
+		seen := make(map[int64]bool, len(roleIDs))
+		for _, id := range roleIDs {
+			seen[id] = true
+		}
+		for rows.Next() {
+			var roleIDsJSON []byte
+			if err := rows.Scan(&roleIDsJSON); err != nil {
+				return err
+			}
+			found++
+
+			var merged []int64
+			if len(roleIDsJSON) > 0 {
+				if err := json.Unmarshal(roleIDsJSON, &merged); err != nil {
+					return err
+				}
+			}
+			for _, id := range merged {
+				if !seen[id] {
+					seen[id] = true
+					roleIDs = append(roleIDs, id)
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if found != len(mergeIDs) {
+			return ErrNotFound
+		}
+
+		roleIDsJSON, err := json.Marshal(roleIDs)
+		if err != nil {
+			return err
+		}
+		err = tx.QueryRowContext(ctx, `
+			UPDATE shift_templates
+			SET role_ids = $1, updated_at = NOW()
+			WHERE id = $2 AND restaurant_id = $3
+			RETURNING updated_at`, roleIDsJSON, template.ID, template.RestaurantID).Scan(&template.UpdatedAt)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE scheduled_shifts
+			SET shift_template_id = $1
+			WHERE shift_template_id = ANY($2)`, template.ID, mergeIDs)
+		if err != nil {
+			return err
+		}
+		if repointed, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM shift_templates WHERE id = ANY($1)`, mergeIDs); err != nil {
+			return err
+		}
+
+		template.RoleIDs = roleIDs
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return repointed, nil
+}
@@ -5,104 +5,519 @@ import (
 	"database/sql"
 	"errors"
 	"time"
-
-	_ "github.com/lib/pq"
 )
 
 var (
 	ErrNotFound = errors.New("resource not found")
-	QueryTimeoutDuration = time.Second * 5
+	// QueryTimeoutDuration bounds a single store call and BatchQueryTimeoutDuration
+	// a whole batch write. Both only ever shorten the caller's deadline, never extend it
+	QueryTimeoutDuration      = time.Second * 5
+	BatchQueryTimeoutDuration = time.Second * 30
 )
 
+//go:generate go run ../mockgen -type Storage -out mocks_gen.go storage.go
+
 type Storage struct {
-	Users interface {
-		Create(context.Context, *sql.Tx, *User) error
-		GetByID(context.Context, int64) (*User, error)
-		CreateAndInvite(context.Context, *User, string, time.Duration) error
-		Activate(context.Context, string) error
-		ResendInvitation(context.Context, string, string, time.Duration) (*User, error)
-		Delete(context.Context, int64) error
-		GetByEmail(context.Context, string) (*User, error)
-		GetByEmailIncludingInactive(context.Context, string) (*User, error)
-		GetByGoogleID(context.Context, string) (*User, error)
-		CreateWithGoogle(context.Context, *sql.Tx, *User, string, string) error
-		CreateUserWithGoogle(context.Context, *User, string, string) error
-		LinkGoogleAccount(context.Context, int64, string, string) error
-	}
-	Restaurants interface {
-		Create(context.Context, *Restaurant) error
-		GetByID(context.Context, int64) (*Restaurant, error)
-		Update(context.Context, *Restaurant) error
-		Delete(context.Context, int64) error
-		ListByUser(context.Context, int64) ([]*Restaurant, error)
-	}
-	Employees interface {
-		Create(context.Context, *Employee) error
-		GetByID(context.Context, int64) (*Employee, error)
-		ListByRestaurant(context.Context, int64) ([]*Employee, error)
-		Update(context.Context, *Employee) error
-		Delete(context.Context, int64) error
-		AssignRoles(context.Context, int64, []int64) error
-		RemoveRole(context.Context, int64, int64) error
-		GetRoles(context.Context, int64, int64) ([]*Role, error)
-	}
-	Roles interface {
-		Create(context.Context, *Role) error
-		GetByID(context.Context, int64) (*Role, error)
-		ListByRestaurant(context.Context, int64) ([]*Role, error)
-		Update(context.Context, *Role) error
-		Delete(context.Context, int64) error
-		GetEmployees(context.Context, int64, int64) ([]*Employee, error)
-	}
-	ShiftTemplates interface {
-		Create(context.Context, *ShiftTemplate) error
-		GetByID(context.Context, int64) (*ShiftTemplate, error)
-		ListByRestaurant(context.Context, int64) ([]*ShiftTemplate, error)
-		Update(context.Context, *ShiftTemplate) error
-		Delete(context.Context, int64) error
-	}
-	Schedules interface {
-		Create(context.Context, *Schedule) error
-		GetByID(context.Context, int64) (*Schedule, error)
-		ListByRestaurant(context.Context, int64) ([]*Schedule, error)
-		Update(context.Context, *Schedule) error
-		Delete(context.Context, int64) error
-		Publish(context.Context, int64, time.Time) error
-	}
-	ScheduledShifts interface {
-		Create(context.Context, *ScheduledShift) error
-		BatchCreate(context.Context, []*ScheduledShift) ([]int64, error)
-		GetByID(context.Context, int64) (*ScheduledShift, error)
-		ListBySchedule(context.Context, int64) ([]*ScheduledShift, error)
-		ListByRestaurantAndWeek(context.Context, int64, time.Time, time.Time) ([]*ScheduledShift, error) // TODO: consume on http side
-		Update(context.Context, *ScheduledShift) error
-		Delete(context.Context, int64) error
-		AssignEmployee(context.Context, int64, *int64) error
-	}
-	Events interface {
-		Create(context.Context, *Event) error
-		GetByID(context.Context, int64) (*Event, error)
-		ListByRestaurant(context.Context, int64) ([]*Event, error)
-		ListByRestaurantAndDateRange(context.Context, int64, DateOnly, DateOnly) ([]*Event, error)
-		Update(context.Context, *Event) error
-		Delete(context.Context, int64) error
-		AssignEmployees(context.Context, int64, []int64) error
-		RemoveEmployee(context.Context, int64, int64) error
-		GetEmployees(context.Context, int64) ([]*Employee, error)
-		ReplaceEmployees(context.Context, int64, []int64) error
-	}
+	Users                UserStorer
+	Restaurants          RestaurantStorer
+	Employees            EmployeeStorer
+	Roles                RoleStorer
+	ShiftTemplates       ShiftTemplateStorer
+	Schedules            ScheduleStorer
+	ScheduleSnapshots    ScheduleSnapshotStorer
+	ScheduleNotes        ScheduleNoteStorer
+	ScheduledShifts      ScheduledShiftStorer
+	Events               EventStorer
+	Certifications       CertificationStorer
+	EmailTemplates       EmailTemplateStorer
+	Documents            DocumentStorer
+	DocumentAcks         DocumentAcknowledgmentStorer
+	EmployeeNotes        EmployeeNoteStorer
+	OperatingHours       OperatingHoursStorer
+	Notifications        NotificationStorer
+	AuditLog             AuditLogStorer
+	Versions             VersionStorer
+	Subscriptions        SubscriptionStorer
+	ShiftAcknowledgments ShiftAcknowledgmentStorer
+	Onboarding           OnboardingStorer
+	TimeClock            TimeClockStorer
+	Members              MemberStorer
+	EmailDeliveries      EmailDeliveryStorer
+	ShareLinks           ShareLinkStorer
+	TwoFactor            TwoFactorStorer
+	Sessions             SessionStorer
+	SecurityEvents       SecurityEventStorer
+	Sync                 SyncStorer
+	Sales                SalesStorer
+	Messages             MessageStorer
+	ShiftFeedback        ShiftFeedbackStorer
+	Compliance           ComplianceStorer
+	Leave                LeaveStorer
+	Bids                 BidStorer
+	Webhooks             WebhookStorer
+	DisplayBoards        DisplayBoardStorer
+	SavedReports         SavedReportStorer
+	EventBadges          EventBadgeStorer
+	NotificationDigests  NotificationDigestStorer
+	Retention            RetentionStorer
+	CalendarFeeds        CalendarFeedStorer
+}
+
+type UserStorer interface {
+	Create(context.Context, *sql.Tx, *User) error
+	GetByID(context.Context, int64) (*User, error)
+	CreateAndInvite(context.Context, *User, string, time.Duration) error
+	Activate(context.Context, string) error
+	ActivationStatus(context.Context, string) (*ActivationStatus, error)
+	DeleteExpiredInvitations(context.Context, time.Time) (int64, error)
+	ResendInvitation(context.Context, string, string, time.Duration) (*User, error)
+	Delete(context.Context, int64) error
+	GetByEmail(context.Context, string) (*User, error)
+	GetByEmailIncludingInactive(context.Context, string) (*User, error)
+	GetByGoogleID(context.Context, string) (*User, error)
+	CreateWithGoogle(context.Context, *sql.Tx, *User, string, string) error
+	CreateUserWithGoogle(context.Context, *User, string, string) error
+	LinkGoogleAccount(context.Context, int64, string, string) error
+	UpdateLocale(context.Context, int64, *string) error
+}
+
+type RestaurantStorer interface {
+	Create(context.Context, *Restaurant) error
+	GetByID(context.Context, int64) (*Restaurant, error)
+	Update(context.Context, *Restaurant) error
+	Delete(context.Context, int64) error
+	ListByUser(context.Context, int64, bool) ([]*Restaurant, error)
+	CountByUser(context.Context, int64) (int, error)
+	SetArchived(context.Context, *Restaurant, bool) error
+	MarkExported(context.Context, *Restaurant, time.Time) error
+	Clone(context.Context, *RestaurantClone) error
+	ClaimMilestoneDigests(context.Context, DateOnly) ([]*Restaurant, error)
+}
+
+type EmployeeStorer interface {
+	Create(context.Context, *Employee) error
+	GetByID(context.Context, int64) (*Employee, error)
+	GetByIDs(context.Context, []int64) ([]*Employee, error)
+	ListByRestaurant(context.Context, int64) ([]*Employee, error)
+	Update(context.Context, *Employee) error
+	Delete(context.Context, int64) error
+	AssignRoles(context.Context, int64, []int64) error
+	RemoveRole(context.Context, int64, int64) error
+	GetRoles(context.Context, int64, int64) ([]*Role, error)
+	RoleIDsByRestaurant(context.Context, int64) (map[int64][]int64, error)
+	CountByRestaurant(context.Context, int64) (int, error)
+	SetAvatar(context.Context, int64, *string) error
+	Erase(context.Context, int64) (*EmployeeErasure, error)
+	RequestEmailVerification(context.Context, *Employee, string, time.Time) error
+	VerifyEmail(context.Context, string, time.Time) (*VerifiedEmail, error)
+}
+
+type RoleStorer interface {
+	Create(context.Context, *Role) error
+	GetByID(context.Context, int64) (*Role, error)
+	GetByIDs(context.Context, []int64) ([]*Role, error)
+	ListByRestaurant(context.Context, int64) ([]*Role, error)
+	Update(context.Context, *Role) error
+	Delete(context.Context, int64) error
+	GetEmployees(context.Context, int64, int64) ([]*Employee, error)
+	Usage(context.Context, int64, DateOnly) (*RoleUsage, error)
+	Staffing(context.Context, int64, DateOnly, DateOnly) ([]*RoleStaffing, error)
+	Reassign(context.Context, int64, int64) error
+	ForceDelete(context.Context, int64) error
+}
+
+type ShiftTemplateStorer interface {
+	Create(context.Context, *ShiftTemplate) error
+	GetByID(context.Context, int64) (*ShiftTemplate, error)
+	ListByRestaurant(context.Context, int64) ([]*ShiftTemplate, error)
+	Update(context.Context, *ShiftTemplate) error
+	Delete(context.Context, int64) error
+	Merge(context.Context, *ShiftTemplate, []int64) (int64, error)
+}
+
+type ScheduleStorer interface {
+	Create(context.Context, *Schedule) error
+	GetByID(context.Context, int64) (*Schedule, error)
+	GetByDate(context.Context, int64, DateOnly) (*Schedule, error)
+	ListByRestaurant(context.Context, int64, bool) ([]*Schedule, error)
+	Update(context.Context, *Schedule) error
+	Shrink(context.Context, *Schedule) ([]int64, error)
+	Delete(context.Context, int64) error
+	Publish(context.Context, int64, time.Time) error
+	ArchiveEndedBefore(context.Context, int64, DateOnly) (int64, error)
+	ArchiveExpired(context.Context, time.Time) (int64, error)
+}
+
+type ScheduleNoteStorer interface {
+	ListBySchedule(context.Context, int64) ([]*ScheduleDayNote, error)
+	Upsert(context.Context, *ScheduleDayNote) error
+	Delete(context.Context, int64, DateOnly) error
+}
+
+type ScheduleSnapshotStorer interface {
+	Create(context.Context, int64, string) error
+	Latest(context.Context, int64, *time.Time) (*ScheduleSnapshot, error)
+	LatestPublished(context.Context, int64, *time.Time) (*ScheduleSnapshot, error)
+	CurrentShifts(context.Context, int64) ([]*SnapshotShift, error)
+}
+
+type ScheduledShiftStorer interface {
+	Create(context.Context, *ScheduledShift) error
+	BatchCreate(context.Context, []*ScheduledShift) ([]int64, error)
+	GetByID(context.Context, int64) (*ScheduledShift, error)
+	ListBySchedule(context.Context, int64) ([]*ScheduledShift, error)
+	ListWarningsBySchedule(context.Context, int64) (map[int64][]ShiftWarning, error)
+	ListByRestaurantAndRange(context.Context, int64, DateOnly, DateOnly) ([]*ScheduledShift, error)
+	ListPage(context.Context, int64, DateOnly, DateOnly, *ShiftCursor, int) ([]*ScheduledShift, error)
+	Update(context.Context, *ScheduledShift) error
+	Delete(context.Context, int64) error
+	AssignEmployee(context.Context, int64, ShiftAssignment) error
+	RepairDenormalized(context.Context, int64) (*DenormalizedRepair, error)
+	ListPatterns(context.Context, int64, time.Time) ([]*ShiftPattern, error)
+	ListCoverage(context.Context, int64, DateOnly, DateOnly) ([]*CoverageCell, error)
+}
+
+type EventStorer interface {
+	Create(context.Context, *Event) error
+	GetByID(context.Context, int64) (*Event, error)
+	ListByRestaurant(context.Context, int64) ([]*Event, error)
+	ListByRestaurantAndDateRange(context.Context, int64, DateOnly, DateOnly) ([]*Event, error)
+	Update(context.Context, *Event) error
+	Delete(context.Context, int64) error
+	AssignEmployees(context.Context, int64, []int64) error
+	RemoveEmployee(context.Context, int64, int64) error
+	GetEmployees(context.Context, int64) ([]*Employee, error)
+	ReplaceEmployees(context.Context, int64, []int64) error
+}
+
+type CertificationStorer interface {
+	Create(context.Context, *Certification) error
+	GetByID(context.Context, int64) (*Certification, error)
+	ListByRestaurant(context.Context, int64) ([]*Certification, error)
+	Update(context.Context, *Certification) error
+	Delete(context.Context, int64) error
+	ListByEmployee(context.Context, int64) ([]*EmployeeCertification, error)
+	SetForEmployee(context.Context, *EmployeeCertification) error
+	RemoveFromEmployee(context.Context, int64, int64) error
+	ListRequiredByRole(context.Context, int64) ([]*Certification, error)
+	SetRequiredByRole(context.Context, int64, []int64) error
+	MissingForAssignment(context.Context, int64, int64, time.Time) ([]string, error)
+	ListExpiring(context.Context, int64, DateOnly) ([]*EmployeeCertification, error)
+}
+
+type EmailTemplateStorer interface {
+	GetByRestaurant(context.Context, int64) (*EmailTemplate, error)
+	Upsert(context.Context, *EmailTemplate) error
+	Delete(context.Context, int64) error
+}
+
+type DocumentStorer interface {
+	Create(context.Context, *Document) error
+	GetByID(context.Context, int64) (*Document, error)
+	ListByRestaurant(context.Context, int64) ([]*Document, error)
+	ListByEmployee(context.Context, int64) ([]*Document, error)
+	Delete(context.Context, int64) error
+}
+
+type EmployeeNoteStorer interface {
+	ListByEmployee(context.Context, int64) ([]*EmployeeNote, error)
+	Create(context.Context, *EmployeeNote) error
+}
+
+type DocumentAcknowledgmentStorer interface {
+	Request(context.Context, *DocumentAcknowledgment, string) error
+	ListByDocument(context.Context, int64) ([]*DocumentAcknowledgment, error)
+	Authenticate(context.Context, string, time.Time) (*DocumentToAcknowledge, error)
+	Acknowledge(context.Context, *DocumentAcknowledgment, string, string, string) error
+}
+
+type OperatingHoursStorer interface {
+	Get(context.Context, int64) (*OperatingHours, error)
+	Replace(context.Context, *OperatingHours) error
+	ListExceptions(context.Context, int64, DateOnly, DateOnly) ([]*HoursException, error)
+	GetException(context.Context, int64) (*HoursException, error)
+	CreateException(context.Context, *HoursException) error
+	DeleteException(context.Context, int64) error
+	ListHolidayCalendars(context.Context, int) ([]*HolidayCalendar, error)
+	AddHolidays(context.Context, *HolidayCalendar, int, []*HoursException, DateOnly) (int, error)
+}
+
+type NotificationStorer interface {
+	CreateMany(context.Context, []*Notification) error
+	List(context.Context, NotificationRecipient, NotificationQuery) ([]*Notification, error)
+	UnreadCount(context.Context, NotificationRecipient) (int, error)
+	MarkRead(context.Context, NotificationRecipient, int64) error
+	MarkAllRead(context.Context, NotificationRecipient) (int64, error)
+}
+
+type AuditLogStorer interface {
+	Record(context.Context, []*AuditEntry) error
+	ListByEntity(context.Context, string, int64) ([]*AuditEntry, error)
+	ListByRestaurant(context.Context, int64) ([]*AuditEntry, error)
+}
+
+type VersionStorer interface {
+	ScheduledShifts(context.Context, int64) (*CollectionVersion, error)
+	Schedules(context.Context, int64) (*CollectionVersion, error)
+	Employees(context.Context, int64) (*CollectionVersion, error)
+	Roles(context.Context, int64) (*CollectionVersion, error)
+}
+
+type SubscriptionStorer interface {
+	Create(context.Context, *Subscription) error
+	GetByUserID(context.Context, int64) (*Subscription, error)
+	GetByCustomerID(context.Context, string) (*Subscription, error)
+	Update(context.Context, *Subscription) error
+}
+
+type ShiftAcknowledgmentStorer interface {
+	ListUpcomingForEmail(context.Context, string, DateOnly) ([]*EmployeeShift, error)
+	Acknowledge(context.Context, int64, string) (*ShiftAcknowledgment, error)
+	ListBySchedule(context.Context, int64) ([]*ShiftAcknowledgmentStatus, error)
+}
+
+type OnboardingStorer interface {
+	Progress(context.Context, int64) (*OnboardingProgress, error)
+	SeedSample(context.Context, int64, *SampleData) (*SampleDataResult, error)
+}
+
+type MemberStorer interface {
+	Create(context.Context, *Member) error
+	UpdateRoles(context.Context, *Member) error
+	Get(context.Context, int64, int64) (*Member, error)
+	ListByRestaurant(context.Context, int64) ([]*Member, error)
+	ListByUser(context.Context, int64) ([]*Membership, error)
+	Delete(context.Context, int64, int64) error
+}
+
+type EmailDeliveryStorer interface {
+	Create(context.Context, *EmailDelivery) error
+	MarkFailed(context.Context, int64, string) error
+	RecordEvent(context.Context, *DeliveryEvent) error
+	ListBySchedule(context.Context, int64) ([]*EmailDelivery, error)
+	CreateBlast(context.Context, *EmailBlast) error
+	ListBlasts(context.Context, int64) ([]*EmailBlast, error)
+	LatestBlast(context.Context, int64) (*EmailBlast, error)
+}
+
+type ShareLinkStorer interface {
+	Create(context.Context, *ShareLink, string) error
+	ListBySchedule(context.Context, int64) ([]*ShareLink, error)
+	SetExpiry(context.Context, int64, int64, time.Time) (*ShareLink, error)
+	Revoke(context.Context, int64, int64) error
+	Authenticate(context.Context, string, time.Time) (*ShareLink, error)
+}
+
+type TwoFactorStorer interface {
+	Get(context.Context, int64) (*TwoFactor, error)
+	Enroll(context.Context, int64, string) error
+	Confirm(context.Context, int64, int64, []string) error
+	SetEnabled(context.Context, int64, bool) error
+	UseTOTPStep(context.Context, int64, int64) error
+	UseRecoveryCode(context.Context, int64, string) error
+	ReplaceRecoveryCodes(context.Context, int64, []string) error
+	CreateChallenge(context.Context, *LoginChallenge, string) error
+	GetChallenge(context.Context, string, time.Time) (*LoginChallenge, error)
+	FailChallenge(context.Context, int64) error
+	DeleteChallenge(context.Context, int64) error
+}
+
+type SessionStorer interface {
+	Create(context.Context, *Session) error
+	Authenticate(context.Context, int64, int64) error
+	Extend(context.Context, int64, int64, time.Time) error
+	ListActive(context.Context, int64) ([]*Session, error)
+	Revoke(context.Context, int64, int64) error
+}
+
+type SecurityEventStorer interface {
+	Record(context.Context, *SecurityEvent) error
+	ListByUser(context.Context, int64, int) ([]*SecurityEvent, error)
+}
+
+type SyncStorer interface {
+	Changes(context.Context, int64, *time.Time, int) (*SyncChanges, error)
+}
+
+type SalesStorer interface {
+	Import(context.Context, []*SalesRecord) error
+	List(context.Context, int64, DateOnly, DateOnly) ([]*SalesRecord, error)
+	DemandByDay(context.Context, int64, DateOnly, DateOnly) ([]*DemandDay, error)
+	SetWebhookToken(context.Context, int64, string) error
+	DeleteWebhookToken(context.Context, int64) error
+	AuthenticateWebhook(context.Context, string) (int64, error)
+}
+
+type MessageStorer interface {
+	Create(context.Context, *Message) error
+	GetByID(context.Context, int64) (*Message, error)
+	ListByRestaurant(context.Context, int64, int64, int) ([]*Message, error)
+	Cancel(context.Context, int64) error
+	ClaimDue(context.Context, time.Time) ([]*Message, error)
+	Complete(context.Context, *Message) error
+	Recipients(context.Context, *Message) ([]*Employee, error)
+}
+
+type ShiftFeedbackStorer interface {
+	Submit(context.Context, *ShiftFeedback, string, time.Time) error
+	ListByRestaurant(context.Context, int64, DateOnly, DateOnly) ([]*ShiftFeedbackEntry, error)
+}
+
+type ComplianceStorer interface {
+	Get(context.Context, int64) (*ComplianceRules, error)
+	Replace(context.Context, *ComplianceRules) error
+}
+
+type LeaveStorer interface {
+	GetPolicy(context.Context, int64) (*LeavePolicy, error)
+	ReplacePolicy(context.Context, *LeavePolicy) error
+	ListAccruingRestaurants(context.Context) ([]int64, error)
+	AccrueThrough(context.Context, int64, time.Time) ([]*LeaveEntry, error)
+	AddEntry(context.Context, *LeaveEntry) error
+	ListEntries(context.Context, int64) ([]*LeaveEntry, error)
+	ListPaidBetween(context.Context, int64, DateOnly, DateOnly) ([]*LeaveEntry, error)
+	ListBalances(context.Context, int64, DateOnly, DateOnly) ([]*LeaveBalance, error)
+}
+
+type BidStorer interface {
+	Create(context.Context, *BidRound) error
+	GetByID(context.Context, int64) (*BidRound, error)
+	ListBySchedule(context.Context, int64) ([]*BidRound, error)
+	OpenShiftIDs(context.Context, int64) ([]int64, error)
+	ListPreferences(context.Context, int64) ([]*BidPreference, error)
+	ListBiddableForEmail(context.Context, string, time.Time) ([]*BiddableShift, error)
+	SubmitPreferences(context.Context, int64, string, []int64, time.Time) ([]*BidPreference, error)
+	ClaimAllocation(context.Context, int64, time.Time) error
+	RecordAwards(context.Context, int64, []*BidRoundShift) error
+}
+
+type WebhookStorer interface {
+	CreateEndpoint(context.Context, *WebhookEndpoint) error
+	ListEndpoints(context.Context, int64) ([]*WebhookEndpoint, error)
+	DeleteEndpoint(context.Context, int64, int64) error
+	Enqueue(context.Context, *WebhookEvent) (int, error)
+	ClaimDue(context.Context, time.Time, time.Duration, int) ([]*WebhookDelivery, error)
+	RecordAttempt(context.Context, int64, int, string, *time.Time) error
+	ListDeliveries(context.Context, int64, int) ([]*WebhookDelivery, error)
+}
+
+type DisplayBoardStorer interface {
+	Create(context.Context, *DisplayBoard, string) error
+	List(context.Context, int64) ([]*DisplayBoard, error)
+	Revoke(context.Context, int64, int64) error
+	Authenticate(context.Context, string) (*DisplayBoard, error)
+	ListShifts(context.Context, int64, DateOnly) ([]*BoardShift, error)
+}
+
+type CalendarFeedStorer interface {
+	Create(context.Context, *CalendarFeed, string) error
+	List(context.Context, int64) ([]*CalendarFeed, error)
+	Revoke(context.Context, int64, int64) error
+	Authenticate(context.Context, string) (*CalendarFeed, error)
+	ListShifts(context.Context, int64, *int64, DateOnly) ([]*ScheduledShift, error)
+}
+
+type SavedReportStorer interface {
+	Create(context.Context, *SavedReport) error
+	GetByID(context.Context, int64) (*SavedReport, error)
+	ListByRestaurant(context.Context, int64) ([]*SavedReport, error)
+	Update(context.Context, *SavedReport) error
+	Delete(context.Context, int64) error
+	ClaimDue(context.Context, DateOnly) ([]*SavedReport, error)
+}
+
+type EventBadgeStorer interface {
+	Issue(context.Context, *EventBadge, string) error
+	Authenticate(context.Context, int64, string) (*EventBadge, error)
+	RecordCheckIn(context.Context, *EventCheckIn) (bool, error)
+	ListCheckIns(context.Context, int64) ([]*EventCheckIn, error)
+}
+
+type NotificationDigestStorer interface {
+	Enqueue(context.Context, []*DigestItem) error
+	ClaimDue(context.Context, time.Time) ([]*EmployeeDigest, error)
+}
+
+type RetentionStorer interface {
+	ListByRestaurant(context.Context, int64) ([]*RetentionPolicy, error)
+	ListEnabled(context.Context) ([]*RetentionPolicy, error)
+	Upsert(context.Context, *RetentionPolicy) error
+	Delete(context.Context, int64, RetentionKind) error
+	MarkRun(context.Context, *RetentionPolicy, time.Time) error
+	TerminatedBefore(context.Context, int64, DateOnly) ([]*Employee, error)
+	CountTimeEntriesBefore(context.Context, int64, DateOnly) (int64, error)
+	DeleteTimeEntriesBefore(context.Context, int64, DateOnly) (int64, error)
+}
+
+type TimeClockStorer interface {
+	CreateKiosk(context.Context, *Kiosk, string) error
+	ListKiosks(context.Context, int64) ([]*Kiosk, error)
+	RevokeKiosk(context.Context, int64, int64) error
+	AuthenticateKiosk(context.Context, string) (*Kiosk, error)
+	SetPIN(context.Context, int64, string) error
+	DeletePIN(context.Context, int64) error
+	VerifyPIN(context.Context, int64, string, time.Time) error
+	ListKioskEmployees(context.Context, int64) ([]*KioskEmployee, error)
+	ClockInOrOut(context.Context, int64, int64, *int64, time.Time) (*TimeEntry, error)
+	ListTimeEntries(context.Context, int64, time.Time, time.Time) ([]*TimeEntry, error)
 }
 
 func NewStorage(db *sql.DB) Storage {
+	return NewStorageWithReplica(db, nil)
+}
+
+// NewStorageWithReplica builds the stores with list queries that tolerate
+// replication lag sent to replica. Writes, single-row lookups and reads under
+// UsePrimary stay on db; a nil replica sends everything to db.
+func NewStorageWithReplica(db, replica *sql.DB) Storage {
+	if replica == db {
+		replica = nil
+	}
+
 	return Storage{
-		Users:           &UserStore{db},
-		Restaurants:     &RestaurantStore{db},
-		Employees:       &EmployeeStore{db},
-		Roles:           &RoleStore{db},
-		ShiftTemplates:  &ShiftTemplateStore{db},
-		Schedules:       &ScheduleStore{db},
-		ScheduledShifts: &ScheduledShiftStore{db},
-		Events:          &EventStore{db},
+		Users:                &UserStore{db},
+		Restaurants:          &RestaurantStore{db},
+		Employees:            &EmployeeStore{db: db, replica: replica},
+		Roles:                &RoleStore{db: db, replica: replica},
+		ShiftTemplates:       &ShiftTemplateStore{db},
+		Schedules:            &ScheduleStore{db: db, replica: replica},
+		ScheduleSnapshots:    &ScheduleSnapshotStore{db},
+		ScheduleNotes:        &ScheduleNoteStore{db},
+		ScheduledShifts:      &ScheduledShiftStore{db: db, replica: replica},
+		Events:               &EventStore{db: db, replica: replica},
+		Subscriptions:        &SubscriptionStore{db},
+		Certifications:       &CertificationStore{db},
+		EmailTemplates:       &EmailTemplateStore{db},
+		Documents:            &DocumentStore{db},
+		DocumentAcks:         &DocumentAcknowledgmentStore{db},
+		EmployeeNotes:        &EmployeeNoteStore{db},
+		OperatingHours:       &OperatingHoursStore{db},
+		Notifications:        &NotificationStore{db},
+		AuditLog:             &AuditLogStore{db},
+		Versions:             &VersionStore{db},
+		ShiftAcknowledgments: &ShiftAcknowledgmentStore{db},
+		Onboarding:           &OnboardingStore{db},
+		TimeClock:            &TimeClockStore{db},
+		Members:              &MemberStore{db},
+		EmailDeliveries:      &EmailDeliveryStore{db},
+		ShareLinks:           &ShareLinkStore{db},
+		TwoFactor:            &TwoFactorStore{db},
+		Sessions:             &SessionStore{db},
+		SecurityEvents:       &SecurityEventStore{db},
+		Sync:                 &SyncStore{db},
+		Sales:                &SalesStore{db},
+		Messages:             &MessageStore{db},
+		ShiftFeedback:        &ShiftFeedbackStore{db},
+		Compliance:           &ComplianceStore{db},
+		Leave:                &LeaveStore{db},
+		Bids:                 &BidStore{db},
+		Webhooks:             &WebhookStore{db},
+		DisplayBoards:        &DisplayBoardStore{db},
+		SavedReports:         &SavedReportStore{db},
+		EventBadges:          &EventBadgeStore{db},
+		NotificationDigests:  &NotificationDigestStore{db},
+		Retention:            &RetentionStore{db},
+		CalendarFeeds:        &CalendarFeedStore{db},
 	}
 }
 
@@ -112,6 +527,11 @@ func withTx(db *sql.DB, ctx context.Context, fn func(*sql.Tx) error) error {
 		return err
 	}
 
+	if err := scopeTx(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	if err := fn(tx); err != nil {
 		_ = tx.Rollback()
 		return err
@@ -122,8 +542,8 @@ func withTx(db *sql.DB, ctx context.Context, fn func(*sql.Tx) error) error {
 
 // normalizeTimeString converts various time formats to HH:MM:SS format
 // Handles: "HH:MM:SS", "HH:MM", "0000-01-01THH:MM:SSZ", time.Time, etc.
-// This is needed because PostgreSQL TIME columns are scanned as RFC3339 timestamps
-// (e.g., "0000-01-01T02:00:00Z") by the pq driver, but PostgreSQL expects "HH:MM:SS" format for inserts
+// This is needed because TIME values can be scanned as RFC3339 timestamps
+// (e.g., "0000-01-01T02:00:00Z"), but PostgreSQL expects "HH:MM:SS" format for inserts
 func normalizeTimeString(timeStr string) string {
 	// If already in HH:MM:SS or HH:MM format, return as-is
 	if len(timeStr) == 8 && timeStr[2] == ':' && timeStr[5] == ':' {
@@ -153,4 +573,4 @@ func normalizeTimeString(timeStr string) string {
 
 	// If all parsing fails, return as-is (will likely cause DB error but won't panic)
 	return timeStr
-}
\ No newline at end of file
+}
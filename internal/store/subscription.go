@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Subscription struct {
+	ID                   int64      `db:"id" json:"id"`
+	UserID               int64      `db:"user_id" json:"user_id"`
+	StripeCustomerID     string     `db:"stripe_customer_id" json:"-"`
+	StripeSubscriptionID *string    `db:"stripe_subscription_id" json:"-"`
+	Plan                 string     `db:"plan" json:"plan"`
+	Status               string     `db:"status" json:"status"`
+	CurrentPeriodEnd     *time.Time `db:"current_period_end" json:"current_period_end,omitempty"`
+	CreatedAt            time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+type SubscriptionStore struct {
+	db *sql.DB
+}
+
+func (s *SubscriptionStore) Create(ctx context.Context, sub *Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO subscriptions (user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		sub.UserID,
+		sub.StripeCustomerID,
+		sub.StripeSubscriptionID,
+		sub.Plan,
+		sub.Status,
+		sub.CurrentPeriodEnd,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+func (s *SubscriptionStore) GetByUserID(ctx context.Context, userID int64) (*Subscription, error) {
+	query := `
+		SELECT id, user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end, created_at, updated_at
+		FROM subscriptions
+		WHERE user_id = $1`
+
+	return s.getOne(ctx, query, userID)
+}
+
+func (s *SubscriptionStore) GetByCustomerID(ctx context.Context, customerID string) (*Subscription, error) {
+	query := `
+		SELECT id, user_id, stripe_customer_id, stripe_subscription_id, plan, status, current_period_end, created_at, updated_at
+		FROM subscriptions
+		WHERE stripe_customer_id = $1`
+
+	return s.getOne(ctx, query, customerID)
+}
+
+func (s *SubscriptionStore) Update(ctx context.Context, sub *Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE subscriptions
+		SET stripe_subscription_id = $1, plan = $2, status = $3, current_period_end = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at`
+
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		sub.StripeSubscriptionID,
+		sub.Plan,
+		sub.Status,
+		sub.CurrentPeriodEnd,
+		sub.ID,
+	).Scan(&sub.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *SubscriptionStore) getOne(ctx context.Context, query string, arg any) (*Subscription, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var sub Subscription
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.StripeCustomerID,
+		&sub.StripeSubscriptionID,
+		&sub.Plan,
+		&sub.Status,
+		&sub.CurrentPeriodEnd,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
@@ -0,0 +1,336 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	SyncEntityRole     = "role"
+	SyncEntityEmployee = "employee"
+	SyncEntitySchedule = "schedule"
+	SyncEntityShift    = "shift"
+	SyncEntityEvent    = "event"
+)
+
+// SyncOverlap is how far before the time of a sync its cursor points. A row's updated_at
+// is when its transaction started, so a write still committing when the changes were read
+// can carry a time before then; re-reading the overlap picks it up on the next sync.
+const SyncOverlap = time.Minute
+
+// Tombstone marks a deleted role, employee, schedule, shift or event
+type Tombstone struct {
+	Entity    string    `json:"entity"`
+	ID        int64     `json:"id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncChanges is everything in a restaurant that changed after a point in time. ReadAt is
+// the database's clock when they were read; the next sync starts SyncOverlap before it.
+type SyncChanges struct {
+	ReadAt    time.Time
+	Roles     []*Role
+	Employees []*Employee
+	Schedules []*Schedule
+	Shifts    []*ScheduledShift
+	Events    []*Event
+	Deleted   []*Tombstone
+	// MoreShifts is set when a full sync's shifts were cut off at the limit; the rest
+	// follow the last one in (shift_date, id) order
+	MoreShifts bool
+}
+
+type SyncStore struct {
+	db *sql.DB
+}
+
+// Changes returns the restaurant's rows updated after since, with tombstones for those
+// deleted after it. A nil since returns every row and no tombstones, except that a
+// shiftLimit above 0 returns only the first shiftLimit shifts by date, so a restaurant
+// with years of history can page through the rest. It reads from the primary:
+// replication lag would let a cursor skip rows the replica hasn't seen yet.
+func (s *SyncStore) Changes(ctx context.Context, restaurantID int64, since *time.Time, shiftLimit int) (*SyncChanges, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	changes := &SyncChanges{
+		Roles:     []*Role{},
+		Employees: []*Employee{},
+		Schedules: []*Schedule{},
+		Shifts:    []*ScheduledShift{},
+		Events:    []*Event{},
+		Deleted:   []*Tombstone{},
+	}
+
+	// taken first, so anything written while the rest is read comes after the cursor
+	if err := s.db.QueryRowContext(ctx, `SELECT NOW()`).Scan(&changes.ReadAt); err != nil {
+		return nil, err
+	}
+
+	var after time.Time
+	if since != nil {
+		after = *since
+	}
+
+	if err := s.roles(ctx, changes, restaurantID, after); err != nil {
+		return nil, err
+	}
+	if err := s.employees(ctx, changes, restaurantID, after); err != nil {
+		return nil, err
+	}
+	if err := s.schedules(ctx, changes, restaurantID, after); err != nil {
+		return nil, err
+	}
+	if since == nil && shiftLimit > 0 {
+		if err := s.firstShifts(ctx, changes, restaurantID, shiftLimit); err != nil {
+			return nil, err
+		}
+	} else if err := s.shifts(ctx, changes, restaurantID, after); err != nil {
+		return nil, err
+	}
+	if err := s.events(ctx, changes, restaurantID, after); err != nil {
+		return nil, err
+	}
+
+	if since != nil {
+		if err := s.tombstones(ctx, changes, restaurantID, after); err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *SyncStore) roles(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT id, restaurant_id, name, color, target_headcount, created_at, updated_at
+		FROM roles
+		WHERE restaurant_id = $1 AND updated_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.ID, &role.RestaurantID, &role.Name, &role.Color, &role.TargetHeadcount, &role.CreatedAt, &role.UpdatedAt); err != nil {
+			return err
+		}
+		changes.Roles = append(changes.Roles, &role)
+	}
+
+	return rows.Err()
+}
+
+func (s *SyncStore) employees(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT id, restaurant_id, full_name, email, locale, hourly_rate_cents, seniority, birthday, hire_date, avatar_id, email_bounced_at, email_bounce_reason, email_verified_at, external_id, notification_mode, terminated_on, erased_at, created_at, updated_at
+		FROM employees
+		WHERE restaurant_id = $1 AND updated_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var employee Employee
+		err := rows.Scan(
+			&employee.ID,
+			&employee.RestaurantID,
+			&employee.FullName,
+			&employee.Email,
+			&employee.Locale,
+			&employee.HourlyRateCents,
+			&employee.Seniority,
+			&employee.Birthday,
+			&employee.HireDate,
+			&employee.AvatarID,
+			&employee.EmailBouncedAt,
+			&employee.EmailBounceReason,
+			&employee.EmailVerifiedAt,
+			&employee.ExternalID,
+			&employee.NotificationMode,
+			&employee.TerminatedOn,
+			&employee.ErasedAt,
+			&employee.CreatedAt,
+			&employee.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		changes.Employees = append(changes.Employees, &employee)
+	}
+
+	return rows.Err()
+}
+
+func (s *SyncStore) schedules(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT id, restaurant_id, start_date, end_date, published_at, note, archived_at, created_at, updated_at
+		FROM schedules
+		WHERE restaurant_id = $1 AND updated_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule Schedule
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.RestaurantID,
+			&schedule.StartDate,
+			&schedule.EndDate,
+			&schedule.PublishedAt,
+			&schedule.Note,
+			&schedule.ArchivedAt,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		changes.Schedules = append(changes.Schedules, &schedule)
+	}
+
+	return rows.Err()
+}
+
+func (s *SyncStore) shifts(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT id, schedule_id, restaurant_id, shift_template_id, role_id, employee_id,
+		       shift_date, start_time, end_time, notes,
+		       employee_name, role_name, role_color, training, trainer_shift_id,
+		       event_id, created_at, updated_at
+		FROM scheduled_shifts
+		WHERE restaurant_id = $1 AND updated_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift ScheduledShift
+		err := rows.Scan(
+			&shift.ID,
+			&shift.ScheduleID,
+			&shift.RestaurantID,
+			&shift.ShiftTemplateID,
+			&shift.RoleID,
+			&shift.EmployeeID,
+			&shift.ShiftDate,
+			&shift.StartTime,
+			&shift.EndTime,
+			&shift.Notes,
+			&shift.EmployeeName,
+			&shift.RoleName,
+			&shift.RoleColor,
+			&shift.Training,
+			&shift.TrainerShiftID,
+			&shift.EventID,
+			&shift.CreatedAt,
+			&shift.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		changes.Shifts = append(changes.Shifts, &shift)
+	}
+
+	return rows.Err()
+}
+
+// firstShifts reads the first limit of the restaurant's shifts in (shift_date, id) order,
+// setting MoreShifts when there are others
+func (s *SyncStore) firstShifts(ctx context.Context, changes *SyncChanges, restaurantID int64, limit int) error {
+	shifts, err := (&ScheduledShiftStore{db: s.db}).ListPage(ctx, restaurantID, MinShiftDate, MaxShiftDate, nil, limit+1)
+	if err != nil {
+		return err
+	}
+
+	if len(shifts) > limit {
+		shifts, changes.MoreShifts = shifts[:limit], true
+	}
+	changes.Shifts = shifts
+	return nil
+}
+
+func (s *SyncStore) events(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT id, restaurant_id, title, description, date, start_time, end_time, created_at, updated_at
+		FROM events
+		WHERE restaurant_id = $1 AND updated_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event Event
+		err := rows.Scan(
+			&event.ID,
+			&event.RestaurantID,
+			&event.Title,
+			&event.Description,
+			&event.Date,
+			&event.StartTime,
+			&event.EndTime,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		changes.Events = append(changes.Events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	events := &EventStore{db: s.db}
+	if err := events.fillEmployees(ctx, s.db, changes.Events); err != nil {
+		return err
+	}
+	return events.fillShifts(ctx, s.db, changes.Events)
+}
+
+func (s *SyncStore) tombstones(ctx context.Context, changes *SyncChanges, restaurantID int64, after time.Time) error {
+	query := `
+		SELECT entity, entity_id, deleted_at
+		FROM sync_tombstones
+		WHERE restaurant_id = $1 AND deleted_at > $2
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, after)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tombstone Tombstone
+		if err := rows.Scan(&tombstone.Entity, &tombstone.ID, &tombstone.DeletedAt); err != nil {
+			return err
+		}
+		changes.Deleted = append(changes.Deleted, &tombstone)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+)
+
+type restaurantScopeKey struct{}
+
+// ScopeToRestaurant limits the transactions run under ctx to the restaurant's
+// rows, through the row-level security policies on the restaurant tables. It's
+// defence in depth behind the handlers' own checks, not a replacement for them.
+func ScopeToRestaurant(ctx context.Context, restaurantID int64) context.Context {
+	return context.WithValue(ctx, restaurantScopeKey{}, restaurantID)
+}
+
+// unscoped lifts ScopeToRestaurant for work that spans restaurants
+func unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, restaurantScopeKey{}, int64(0))
+}
+
+// scopeQuery sets app.restaurant_id for the rest of the transaction.
+// set_config with is_local is SET LOCAL taking a parameter.
+const scopeQuery = `SELECT set_config('app.restaurant_id', $1, true)`
+
+// scopedRestaurant is the restaurant ctx is scoped to, as scopeQuery's
+// parameter; empty when it isn't scoped
+func scopedRestaurant(ctx context.Context) string {
+	restaurantID, _ := ctx.Value(restaurantScopeKey{}).(int64)
+	if restaurantID == 0 {
+		return ""
+	}
+	return strconv.FormatInt(restaurantID, 10)
+}
+
+// scopeTx sets app.restaurant_id for the rest of tx if ctx is scoped to a restaurant
+func scopeTx(ctx context.Context, tx *sql.Tx) error {
+	restaurantID := scopedRestaurant(ctx)
+	if restaurantID == "" {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, scopeQuery, restaurantID)
+	return err
+}
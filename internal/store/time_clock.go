@@ -0,0 +1,372 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// PINMaxAttempts wrong PINs in a row lock the employee out of the kiosk
+	PINMaxAttempts = 5
+	// PINLockoutDuration is how long the lockout lasts
+	PINLockoutDuration = 15 * time.Minute
+)
+
+var (
+	ErrPINNotSet   = errors.New("no PIN has been set for this employee")
+	ErrPINMismatch = errors.New("wrong PIN")
+	ErrPINLocked   = errors.New("too many wrong PINs, try again later")
+)
+
+// Kiosk is a shared device registered to clock a restaurant's employees in and out
+type Kiosk struct {
+	ID           int64      `json:"id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	Name         string     `json:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// KioskEmployee is an employee with a PIN as the kiosk lists them
+type KioskEmployee struct {
+	ID          int64      `json:"id"`
+	FullName    string     `json:"full_name"`
+	ClockedInAt *time.Time `json:"clocked_in_at,omitempty"`
+}
+
+// TimeEntry is a stretch of time an employee was clocked in; ClockOutAt is
+// nil while they still are
+type TimeEntry struct {
+	ID           int64      `json:"id"`
+	RestaurantID int64      `json:"restaurant_id"`
+	EmployeeID   int64      `json:"employee_id"`
+	EmployeeName string     `json:"employee_name"`
+	KioskID      *int64     `json:"kiosk_id,omitempty"`
+	ClockInAt    time.Time  `json:"clock_in_at"`
+	ClockOutAt   *time.Time `json:"clock_out_at,omitempty"`
+}
+
+type TimeClockStore struct {
+	db *sql.DB
+}
+
+// hashToken is how bearer secrets such as kiosk tokens, share link tokens and
+// recovery codes are stored: only their SHA-256 is kept
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateKiosk registers a kiosk; only the token's hash is stored
+func (s *TimeClockStore) CreateKiosk(ctx context.Context, kiosk *Kiosk, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO kiosks (restaurant_id, name, token_hash)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(ctx, query, kiosk.RestaurantID, kiosk.Name, hashToken(token)).
+		Scan(&kiosk.ID, &kiosk.CreatedAt)
+}
+
+func (s *TimeClockStore) ListKiosks(ctx context.Context, restaurantID int64) ([]*Kiosk, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, name, last_used_at, revoked_at, created_at
+		FROM kiosks
+		WHERE restaurant_id = $1
+		ORDER BY created_at, id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kiosks := []*Kiosk{}
+	for rows.Next() {
+		var k Kiosk
+		if err := rows.Scan(&k.ID, &k.RestaurantID, &k.Name, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt); err != nil {
+			return nil, err
+		}
+		kiosks = append(kiosks, &k)
+	}
+
+	return kiosks, rows.Err()
+}
+
+// RevokeKiosk stops the kiosk's token from working
+func (s *TimeClockStore) RevokeKiosk(ctx context.Context, restaurantID, kioskID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE kiosks
+		SET revoked_at = NOW()
+		WHERE id = $1 AND restaurant_id = $2 AND revoked_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, kioskID, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateKiosk returns the unrevoked kiosk of an active restaurant with
+// this token and records that it was used
+func (s *TimeClockStore) AuthenticateKiosk(ctx context.Context, token string) (*Kiosk, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE kiosks k
+		SET last_used_at = NOW()
+		FROM restaurants r
+		WHERE r.id = k.restaurant_id
+		  AND k.token_hash = $1
+		  AND k.revoked_at IS NULL
+		  AND r.archived_at IS NULL
+		RETURNING k.id, k.restaurant_id, k.name, k.last_used_at, k.revoked_at, k.created_at`
+
+	var k Kiosk
+	err := s.db.QueryRowContext(ctx, query, hashToken(token)).
+		Scan(&k.ID, &k.RestaurantID, &k.Name, &k.LastUsedAt, &k.RevokedAt, &k.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// SetPIN replaces the employee's PIN and lifts any lockout
+func (s *TimeClockStore) SetPIN(ctx context.Context, employeeID int64, pin string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO employee_pins (employee_id, pin_hash, failed_attempts, locked_until, rotated_at)
+		VALUES ($1, $2, 0, NULL, NOW())
+		ON CONFLICT (employee_id) DO UPDATE
+		SET pin_hash = EXCLUDED.pin_hash, failed_attempts = 0, locked_until = NULL, rotated_at = NOW()`
+
+	_, err = s.db.ExecContext(ctx, query, employeeID, hash)
+	return err
+}
+
+// DeletePIN removes the employee's PIN, taking them off the kiosk
+func (s *TimeClockStore) DeletePIN(ctx context.Context, employeeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM employee_pins WHERE employee_id = $1`, employeeID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrPINNotSet
+	}
+
+	return nil
+}
+
+// VerifyPIN checks the employee's PIN. A wrong one counts towards the lockout,
+// and the PINMaxAttempts-th in a row locks the employee out for
+// PINLockoutDuration; a right one resets the count.
+func (s *TimeClockStore) VerifyPIN(ctx context.Context, employeeID int64, pin string, now time.Time) error {
+	// The failed attempt has to be committed, so the verdict is returned
+	// outside the transaction instead of rolling it back
+	var verdict error
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		var (
+			hash        []byte
+			attempts    int
+			lockedUntil *time.Time
+		)
+		err := tx.QueryRowContext(ctx, `
+			SELECT pin_hash, failed_attempts, locked_until
+			FROM employee_pins
+			WHERE employee_id = $1
+			FOR UPDATE`, employeeID).Scan(&hash, &attempts, &lockedUntil)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				verdict = ErrPINNotSet
+				return nil
+			}
+			return err
+		}
+
+		if lockedUntil != nil && now.Before(*lockedUntil) {
+			verdict = ErrPINLocked
+			return nil
+		}
+
+		if bcrypt.CompareHashAndPassword(hash, []byte(pin)) == nil {
+			if attempts > 0 || lockedUntil != nil {
+				_, err := tx.ExecContext(ctx, `UPDATE employee_pins SET failed_attempts = 0, locked_until = NULL WHERE employee_id = $1`, employeeID)
+				return err
+			}
+			return nil
+		}
+
+		attempts++
+		verdict = ErrPINMismatch
+		var until *time.Time
+		if attempts >= PINMaxAttempts {
+			lockout := now.Add(PINLockoutDuration)
+			until, attempts, verdict = &lockout, 0, ErrPINLocked
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE employee_pins SET failed_attempts = $2, locked_until = $3 WHERE employee_id = $1`, employeeID, attempts, until)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return verdict
+}
+
+// ListKioskEmployees lists the restaurant's employees who have a PIN and when
+// the clocked-in ones clocked in
+func (s *TimeClockStore) ListKioskEmployees(ctx context.Context, restaurantID int64) ([]*KioskEmployee, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT e.id, e.full_name, t.clock_in_at
+		FROM employees e
+		JOIN employee_pins p ON p.employee_id = e.id
+		LEFT JOIN time_entries t ON t.employee_id = e.id AND t.clock_out_at IS NULL
+		WHERE e.restaurant_id = $1
+		ORDER BY e.full_name, e.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []*KioskEmployee{}
+	for rows.Next() {
+		var e KioskEmployee
+		if err := rows.Scan(&e.ID, &e.FullName, &e.ClockedInAt); err != nil {
+			return nil, err
+		}
+		employees = append(employees, &e)
+	}
+
+	return employees, rows.Err()
+}
+
+// ClockInOrOut closes the employee's open time entry, or opens one when
+// there is none
+func (s *TimeClockStore) ClockInOrOut(ctx context.Context, restaurantID, employeeID int64, kioskID *int64, now time.Time) (*TimeEntry, error) {
+	entry := &TimeEntry{RestaurantID: restaurantID, EmployeeID: employeeID}
+
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		err := tx.QueryRowContext(ctx, `SELECT full_name FROM employees WHERE id = $1 AND restaurant_id = $2`, employeeID, restaurantID).
+			Scan(&entry.EmployeeName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			SELECT id, kiosk_id, clock_in_at
+			FROM time_entries
+			WHERE employee_id = $1 AND clock_out_at IS NULL
+			FOR UPDATE`, employeeID).Scan(&entry.ID, &entry.KioskID, &entry.ClockInAt)
+		switch {
+		case err == nil:
+			entry.ClockOutAt = &now
+			_, err = tx.ExecContext(ctx, `UPDATE time_entries SET clock_out_at = $2 WHERE id = $1`, entry.ID, now)
+			return err
+		case errors.Is(err, sql.ErrNoRows):
+			entry.KioskID, entry.ClockInAt = kioskID, now
+			return tx.QueryRowContext(ctx, `
+				INSERT INTO time_entries (restaurant_id, employee_id, kiosk_id, clock_in_at)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id`, restaurantID, employeeID, kioskID, now).Scan(&entry.ID)
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// ListTimeEntries returns the restaurant's time entries clocked in from from until before to
+func (s *TimeClockStore) ListTimeEntries(ctx context.Context, restaurantID int64, from, to time.Time) ([]*TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT t.id, t.restaurant_id, t.employee_id, e.full_name, t.kiosk_id, t.clock_in_at, t.clock_out_at
+		FROM time_entries t
+		JOIN employees e ON e.id = t.employee_id
+		WHERE t.restaurant_id = $1
+		  AND t.clock_in_at >= $2
+		  AND t.clock_in_at < $3
+		ORDER BY t.clock_in_at, t.id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*TimeEntry{}
+	for rows.Next() {
+		var t TimeEntry
+		if err := rows.Scan(&t.ID, &t.RestaurantID, &t.EmployeeID, &t.EmployeeName, &t.KioskID, &t.ClockInAt, &t.ClockOutAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &t)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryCanceled is the Postgres error code for a statement cancelled by the
+// client or by statement_timeout, which is how an expired context surfaces
+// once the query has reached the server
+const queryCanceled = "57014"
+
+// IsTimeout reports whether err came from a store call running out of time,
+// either its own query timeout or the caller's deadline, rather than failing
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == queryCanceled
+}
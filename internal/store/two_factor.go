@@ -0,0 +1,296 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	// LoginChallengeTTL is how long a sign-in waits for its second factor
+	LoginChallengeTTL = 5 * time.Minute
+	// LoginChallengeMaxAttempts wrong codes end a sign-in; the password has to be entered again
+	LoginChallengeMaxAttempts = 5
+)
+
+var (
+	ErrTwoFactorEnrolled = errors.New("two-factor authentication is already set up")
+	ErrTOTPCodeUsed      = errors.New("this code has already been used")
+)
+
+// TwoFactor is an account's authenticator app enrollment
+type TwoFactor struct {
+	UserID int64
+	Secret string
+	// ConfirmedAt is nil while the enrollment waits for its first code
+	ConfirmedAt *time.Time
+	// Enabled asks for a code at sign-in
+	Enabled           bool
+	RecoveryCodesLeft int
+}
+
+// LoginChallenge is a sign-in that passed the password and waits for a code
+type LoginChallenge struct {
+	ID             int64
+	UserID         int64
+	FailedAttempts int
+	ExpiresAt      time.Time
+}
+
+type TwoFactorStore struct {
+	db *sql.DB
+}
+
+// Get returns the user's enrollment, confirmed or not; ErrNotFound means they have none
+func (s *TwoFactorStore) Get(ctx context.Context, userID int64) (*TwoFactor, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT t.user_id, t.totp_secret, t.confirmed_at, t.enabled,
+		       (SELECT COUNT(*) FROM user_recovery_codes c WHERE c.user_id = t.user_id)
+		FROM user_two_factor t
+		WHERE t.user_id = $1`
+
+	var tf TwoFactor
+	err := s.db.QueryRowContext(ctx, query, userID).
+		Scan(&tf.UserID, &tf.Secret, &tf.ConfirmedAt, &tf.Enabled, &tf.RecoveryCodesLeft)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &tf, nil
+}
+
+// Enroll starts an enrollment with a new secret, replacing an unconfirmed
+// one. ErrTwoFactorEnrolled means the user already confirmed an app.
+func (s *TwoFactorStore) Enroll(ctx context.Context, userID int64, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO user_two_factor (user_id, totp_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret, created_at = NOW()
+		WHERE user_two_factor.confirmed_at IS NULL`
+
+	result, err := s.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTwoFactorEnrolled
+	}
+
+	return nil
+}
+
+// Confirm completes the enrollment with the step of the code that proved it,
+// turns sign-in codes on and issues the recovery codes, all in one transaction
+func (s *TwoFactorStore) Confirm(ctx context.Context, userID, step int64, recoveryCodes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		result, err := tx.ExecContext(ctx, `
+			UPDATE user_two_factor
+			SET confirmed_at = NOW(), enabled = TRUE, last_step = $2
+			WHERE user_id = $1 AND confirmed_at IS NULL`, userID, step)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, recoveryCodes)
+	})
+}
+
+// SetEnabled turns sign-in codes on or off for a confirmed enrollment
+func (s *TwoFactorStore) SetEnabled(ctx context.Context, userID int64, enabled bool) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE user_two_factor
+		SET enabled = $2
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL`
+
+	result, err := s.db.ExecContext(ctx, query, userID, enabled)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// UseTOTPStep records that the code of this time step was accepted.
+// ErrTOTPCodeUsed means it, or a later one, already was.
+func (s *TwoFactorStore) UseTOTPStep(ctx context.Context, userID, step int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE user_two_factor
+		SET last_step = $2
+		WHERE user_id = $1 AND (last_step IS NULL OR last_step < $2)`
+
+	result, err := s.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTOTPCodeUsed
+	}
+
+	return nil
+}
+
+// UseRecoveryCode spends one of the user's recovery codes; ErrNotFound means it isn't one
+func (s *TwoFactorStore) UseRecoveryCode(ctx context.Context, userID int64, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `DELETE FROM user_recovery_codes WHERE user_id = $1 AND code_hash = $2`
+
+	result, err := s.db.ExecContext(ctx, query, userID, hashToken(code))
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for new ones
+func (s *TwoFactorStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, codes []string) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+		defer cancel()
+
+		return replaceRecoveryCodes(ctx, tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, hashToken(code))
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO user_recovery_codes (user_id, code_hash)
+		SELECT $1::bigint, unnest($2::text[])`, userID, hashes)
+	return err
+}
+
+// CreateChallenge starts a sign-in's wait for its second factor, clearing the
+// user's expired ones; only the token's hash is stored
+func (s *TwoFactorStore) CreateChallenge(ctx context.Context, challenge *LoginChallenge, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM login_challenges WHERE user_id = $1 AND expires_at <= NOW()`, challenge.UserID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO login_challenges (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id`
+
+	return s.db.QueryRowContext(ctx, query, challenge.UserID, hashToken(token), challenge.ExpiresAt).Scan(&challenge.ID)
+}
+
+// GetChallenge returns the unexpired challenge with this token that still has
+// attempts left
+func (s *TwoFactorStore) GetChallenge(ctx context.Context, token string, now time.Time) (*LoginChallenge, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, user_id, failed_attempts, expires_at
+		FROM login_challenges
+		WHERE token_hash = $1 AND expires_at > $2 AND failed_attempts < $3`
+
+	var c LoginChallenge
+	err := s.db.QueryRowContext(ctx, query, hashToken(token), now, LoginChallengeMaxAttempts).
+		Scan(&c.ID, &c.UserID, &c.FailedAttempts, &c.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// FailChallenge counts a wrong code against the challenge
+func (s *TwoFactorStore) FailChallenge(ctx context.Context, challengeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `UPDATE login_challenges SET failed_attempts = failed_attempts + 1 WHERE id = $1`, challengeID)
+	return err
+}
+
+// DeleteChallenge ends a challenge once it has been answered. ErrNotFound
+// means another request answered it first.
+func (s *TwoFactorStore) DeleteChallenge(ctx context.Context, challengeID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM login_challenges WHERE id = $1`, challengeID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
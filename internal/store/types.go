@@ -7,8 +7,8 @@ import (
 )
 
 // TimeOfDay represents a time-of-day (HH:MM:SS) without date component.
-// Handles the pq driver quirk where PostgreSQL TIME columns are scanned
-// as RFC3339 timestamps (e.g., "0000-01-01T14:30:00Z").
+// Accepts TIME columns as "HH:MM:SS" text as well as RFC3339 timestamps
+// (e.g., "0000-01-01T14:30:00Z").
 //
 // Usage:
 //   - Use in structs for PostgreSQL TIME columns
@@ -17,8 +17,8 @@ import (
 type TimeOfDay string
 
 // Scan implements sql.Scanner for automatic normalization on database reads.
-// This handles the pq driver behavior where TIME columns are returned as
-// RFC3339 timestamps like "0000-01-01T14:30:00Z".
+// TIME columns may arrive as "HH:MM:SS" text or as RFC3339 timestamps like
+// "0000-01-01T14:30:00Z".
 func (t *TimeOfDay) Scan(src interface{}) error {
 	if src == nil {
 		*t = ""
@@ -32,7 +32,7 @@ func (t *TimeOfDay) Scan(src interface{}) error {
 	case []byte:
 		str = string(v)
 	case time.Time:
-		// Handle case where pq might return actual time.Time
+		// Handle drivers that return an actual time.Time
 		str = v.Format("15:04:05")
 	default:
 		return fmt.Errorf("TimeOfDay.Scan: unsupported type %T", src)
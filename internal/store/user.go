@@ -14,8 +14,43 @@ import (
 var (
 	ErrDuplicateEmail = errors.New("a user with that email already exists")
 	ErrDuplicateUsername = errors.New("a user with that username already exists")
+	ErrInvitationInvalid = errors.New("invitation token is invalid")
+	ErrInvitationExpired = errors.New("invitation token has expired")
+	ErrInvitationUsed = errors.New("invitation token has already been used")
 )
 
+const (
+	ActivationStatusValid = "valid"
+	ActivationStatusExpired = "expired"
+	ActivationStatusUsed = "used"
+	ActivationStatusInvalid = "invalid"
+)
+
+// ActivationStatus describes what would happen if an invitation token were used to activate now
+type ActivationStatus struct {
+	Status string `json:"status"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type invitation struct {
+	userID int64
+	expiry time.Time
+	usedAt *time.Time
+	userActive bool
+}
+
+// err reports why the invitation can't be used at the given time, if at all
+func (i *invitation) err(now time.Time) error {
+	switch {
+	case i.usedAt != nil || i.userActive:
+		return ErrInvitationUsed
+	case !i.expiry.After(now):
+		return ErrInvitationExpired
+	default:
+		return nil
+	}
+}
+
 type User struct {
 	ID int64 `db:"id" json:"id"`
 	Email string `db:"email" json:"email"`
@@ -27,6 +62,7 @@ type User struct {
 	IsActive bool `db:"is_active" json:"is_active"`
 	GoogleID *string `db:"google_id" json:"google_id,omitempty"`
 	AvatarURL *string `db:"avatar_url" json:"avatar_url,omitempty"`
+	Locale *string `db:"locale" json:"locale,omitempty"`
 }
 
 type password struct {
@@ -56,8 +92,8 @@ type UserStore struct {
 
 func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 	query := `
-		INSERT INTO users (email, password, first_name, last_name) 
-		VALUES ($1, $2, $3, $4) 
+		INSERT INTO users (email, password, first_name, last_name, locale) 
+		VALUES ($1, $2, $3, $4, $5) 
 		RETURNING id, created_at
 		`
 
@@ -71,6 +107,7 @@ func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 		user.Password.hash,
 		user.FirstName,
 		user.LastName,
+		user.Locale,
 	).Scan(
 		&user.ID,
 		&user.CreatedAt,
@@ -78,9 +115,9 @@ func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 
 	if err != nil {
 		switch {
-		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
+		case isUniqueViolation(err, "users_email_key"):
 			return ErrDuplicateEmail
-		case err.Error() == `pq: duplicate key value violates unique constraint "users_username_key"`:
+		case isUniqueViolation(err, "users_username_key"):
 			return ErrDuplicateUsername
 		default:
 			return err
@@ -93,7 +130,7 @@ func (s *UserStore) Create(ctx context.Context, tx *sql.Tx, user *User) error {
 
 func (s *UserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
 	query := `
-		SELECT users.id, email, password, first_name, last_name, created_at
+		SELECT users.id, email, password, first_name, last_name, created_at, locale
 		FROM users 
 		WHERE users.id = $1 AND is_active = true;
 	`
@@ -114,6 +151,7 @@ func (s *UserStore) GetByID(ctx context.Context, userID int64) (*User, error) {
 		&user.FirstName,
 		&user.LastName,
 		&user.CreatedAt,
+		&user.Locale,
 	)
 
 	if err != nil {
@@ -142,22 +180,26 @@ func (s *UserStore) CreateAndInvite(ctx context.Context, user *User, token strin
 	})
 }
 
+// Activate activates the user the token was issued to, returning ErrInvitationInvalid,
+// ErrInvitationExpired or ErrInvitationUsed when the token can't be used
 func (s *UserStore) Activate(ctx context.Context, token string) error {
 	return withTx(s.db, ctx, func(tx *sql.Tx) error {
-	// 1. find the user that this token belongs to
-		user, err := s.getUserFromInvitation(ctx, tx, token)
+	// 1. find the invitation and check it can still be used
+		inv, err := s.getInvitation(ctx, tx, token)
 		if err != nil {
 			return err
 		}
+		if err := inv.err(time.Now()); err != nil {
+			return err
+		}
 
-	// 2. update the user
-		user.IsActive = true
-		if err := s.update(ctx, tx, user); err != nil {
+	// 2. activate the user
+		if err := s.activate(ctx, tx, inv.userID); err != nil {
 			return err
 		}
 
-	// 3. clean the invitations
-		if err := s.deleteUserInvitations(ctx, tx, user.ID); err != nil {
+	// 3. mark this token used and clean the other invitations
+		if err := s.useUserInvitation(ctx, tx, token, inv.userID); err != nil {
 			return err
 		}
 
@@ -165,6 +207,42 @@ func (s *UserStore) Activate(ctx context.Context, token string) error {
 	})
 }
 
+// ActivationStatus reports whether a token is valid, expired, already used or unknown without consuming it
+func (s *UserStore) ActivationStatus(ctx context.Context, token string) (*ActivationStatus, error) {
+	inv, err := s.getInvitation(ctx, s.db, token)
+	if err != nil {
+		if errors.Is(err, ErrInvitationInvalid) {
+			return &ActivationStatus{Status: ActivationStatusInvalid}, nil
+		}
+		return nil, err
+	}
+
+	status := &ActivationStatus{Status: ActivationStatusValid, ExpiresAt: &inv.expiry}
+	switch inv.err(time.Now()) {
+	case ErrInvitationUsed:
+		status.Status = ActivationStatusUsed
+	case ErrInvitationExpired:
+		status.Status = ActivationStatusExpired
+	}
+
+	return status, nil
+}
+
+// DeleteExpiredInvitations removes invitations that expired before the given time
+func (s *UserStore) DeleteExpiredInvitations(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM user_invitations WHERE expiry < $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (s *UserStore) ResendInvitation(ctx context.Context, email string, token string, invitationExp time.Duration) (*User, error) {
 	var user *User
 	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
@@ -200,12 +278,17 @@ func (s *UserStore) ResendInvitation(ctx context.Context, email string, token st
 	return user, nil
 }
 
-func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token string) (*User, error) {
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}
+
+func (s *UserStore) getInvitation(ctx context.Context, q rowQuerier, token string) (*invitation, error) {
 	query := `
-		SELECT u.id, u.email, u.first_name, u.last_name, u.created_at, u.is_active
-		FROM users u
-		JOIN user_invitations ui ON u.id = ui.user_id
-		WHERE ui.token = $1 AND ui.expiry > $2;	
+		SELECT ui.user_id, ui.expiry, ui.used_at, u.is_active
+		FROM user_invitations ui
+		JOIN users u ON u.id = ui.user_id
+		WHERE ui.token = $1;
 	`
 
 	hash := sha256.Sum256([]byte(token))
@@ -214,25 +297,23 @@ func (s *UserStore) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	user := &User{}
-	err := tx.QueryRowContext(ctx, query, hashToken, time.Now()).Scan(
-		&user.ID,
-		&user.Email,
-		&user.FirstName,
-		&user.LastName,
-		&user.CreatedAt,
-		&user.IsActive,
+	inv := &invitation{}
+	err := q.QueryRowContext(ctx, query, hashToken).Scan(
+		&inv.userID,
+		&inv.expiry,
+		&inv.usedAt,
+		&inv.userActive,
 	)
 	if err != nil {
 		switch err {
 		case sql.ErrNoRows:
-			return nil, ErrNotFound
+			return nil, ErrInvitationInvalid
 		default:
 			return nil, err
 		}
 	}
 
-	return user, nil
+	return inv, nil
 }
 
 func (s *UserStore) createUserInvitation(ctx context.Context, tx *sql.Tx, token string, exp time.Duration, userID int64) error {
@@ -249,13 +330,33 @@ func (s *UserStore) createUserInvitation(ctx context.Context, tx *sql.Tx, token
 	return nil
 }
 
-func (s *UserStore) update(ctx context.Context, tx *sql.Tx, user *User) error {
-	query := `UPDATE users SET email = $1, first_name = $2, last_name = $3, is_active = $4 WHERE id = $5`
+func (s *UserStore) activate(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `UPDATE users SET is_active = true, updated_at = NOW() WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// useUserInvitation keeps the used token around (so it reports as used rather than invalid) and drops the rest
+func (s *UserStore) useUserInvitation(ctx context.Context, tx *sql.Tx, token string, userID int64) error {
+	hash := sha256.Sum256([]byte(token))
+	hashToken := hex.EncodeToString(hash[:])
 
 	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query, user.Email, user.FirstName, user.LastName, user.IsActive, user.ID)
+	if _, err := tx.ExecContext(ctx, `UPDATE user_invitations SET used_at = NOW() WHERE token = $1`, hashToken); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM user_invitations WHERE user_id = $1 AND token <> $2`, userID, hashToken)
 	if err != nil {
 		return err
 	}
@@ -348,7 +449,7 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*User, error)
 // Used for OAuth account linking to find existing unactivated accounts
 func (s *UserStore) GetByEmailIncludingInactive(ctx context.Context, email string) (*User, error) {
 	query := `
-	SELECT id, email, password, first_name, last_name, created_at, google_id, avatar_url, is_active
+	SELECT id, email, password, first_name, last_name, created_at, google_id, avatar_url, is_active, locale
 		FROM users
 	WHERE email = $1;
 	`
@@ -372,6 +473,7 @@ func (s *UserStore) GetByEmailIncludingInactive(ctx context.Context, email strin
 		&user.GoogleID,
 		&user.AvatarURL,
 		&user.IsActive,
+		&user.Locale,
 	)
 
 	if err != nil {
@@ -453,9 +555,9 @@ func (s *UserStore) CreateWithGoogle(ctx context.Context, tx *sql.Tx, user *User
 
 	if err != nil {
 		switch {
-		case err.Error() == `pq: duplicate key value violates unique constraint "users_email_key"`:
+		case isUniqueViolation(err, "users_email_key"):
 			return ErrDuplicateEmail
-		case err.Error() == `pq: duplicate key value violates unique constraint "users_google_id_key"`:
+		case isUniqueViolation(err, "users_google_id_key"):
 			return errors.New("a user with that Google account already exists")
 		default:
 			return err
@@ -469,6 +571,30 @@ func (s *UserStore) CreateWithGoogle(ctx context.Context, tx *sql.Tx, user *User
 	return nil
 }
 
+// UpdateLocale sets the user's preferred locale; nil follows the browser's Accept-Language
+func (s *UserStore) UpdateLocale(ctx context.Context, userID int64, locale *string) error {
+	query := `UPDATE users SET locale = $1, updated_at = NOW() WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, locale, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 // LinkGoogleAccount links a Google account to an existing user
 func (s *UserStore) LinkGoogleAccount(ctx context.Context, userID int64, googleID, avatarURL string) error {
 	query := `
@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// CollectionVersion summarizes a collection so clients can revalidate without refetching it.
+// Count catches deletions, which don't move LastModified.
+type CollectionVersion struct {
+	Count        int
+	LastModified time.Time
+}
+
+type VersionStore struct {
+	db *sql.DB
+}
+
+func (s *VersionStore) ScheduledShifts(ctx context.Context, scheduleID int64) (*CollectionVersion, error) {
+	return s.version(ctx, `SELECT COUNT(*), MAX(updated_at) FROM scheduled_shifts WHERE schedule_id = $1`, scheduleID)
+}
+
+func (s *VersionStore) Schedules(ctx context.Context, restaurantID int64) (*CollectionVersion, error) {
+	return s.version(ctx, `SELECT COUNT(*), MAX(updated_at) FROM schedules WHERE restaurant_id = $1`, restaurantID)
+}
+
+func (s *VersionStore) Employees(ctx context.Context, restaurantID int64) (*CollectionVersion, error) {
+	return s.version(ctx, `SELECT COUNT(*), MAX(updated_at) FROM employees WHERE restaurant_id = $1`, restaurantID)
+}
+
+func (s *VersionStore) Roles(ctx context.Context, restaurantID int64) (*CollectionVersion, error) {
+	return s.version(ctx, `SELECT COUNT(*), MAX(updated_at) FROM roles WHERE restaurant_id = $1`, restaurantID)
+}
+
+func (s *VersionStore) version(ctx context.Context, query string, id int64) (*CollectionVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	var version CollectionVersion
+	var lastModified sql.NullTime
+	if err := s.db.QueryRowContext(ctx, query, id).Scan(&version.Count, &lastModified); err != nil {
+		return nil, err
+	}
+	version.LastModified = lastModified.Time
+
+	return &version, nil
+}
@@ -0,0 +1,285 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses: pending until the endpoint accepts it or attempts run out
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	WebhookFailed    = "failed"
+)
+
+// WebhookEndpoint is a URL a restaurant has events sent to
+type WebhookEndpoint struct {
+	ID           int64  `json:"id"`
+	RestaurantID int64  `json:"restaurant_id"`
+	URL          string `json:"url"`
+	// Secret signs deliveries; it's only shown when the endpoint is created
+	Secret string `json:"secret,omitempty"`
+	// Events are the event types sent; empty for all of them
+	Events    []string  `json:"events"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookEvent is something that happened at a restaurant, queued for each
+// endpoint subscribed to its type
+type WebhookEvent struct {
+	ID           string
+	RestaurantID int64
+	Type         string
+	Payload      json.RawMessage
+}
+
+// WebhookDelivery is one event on its way to one endpoint
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	EndpointID     int64           `json:"endpoint_id"`
+	RestaurantID   int64           `json:"restaurant_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	// URL and Secret are the endpoint's, loaded when the delivery is claimed
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+type WebhookStore struct {
+	db *sql.DB
+}
+
+const webhookDeliveryColumns = `d.id, d.endpoint_id, d.restaurant_id, d.event_id, d.event_type, d.payload, d.status,
+	d.attempts, d.next_attempt_at, d.response_status, d.last_error, d.delivered_at, d.created_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }, extra ...any) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+	dest := append([]any{
+		&d.ID,
+		&d.EndpointID,
+		&d.RestaurantID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.ResponseStatus,
+		&d.LastError,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (s *WebhookStore) CreateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_endpoints (restaurant_id, url, secret, events, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		endpoint.RestaurantID,
+		endpoint.URL,
+		endpoint.Secret,
+		endpoint.Events,
+		endpoint.CreatedBy,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt)
+}
+
+// ListEndpoints returns the restaurant's endpoints, oldest first, without their secrets
+func (s *WebhookStore) ListEndpoints(ctx context.Context, restaurantID int64) ([]*WebhookEndpoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT id, restaurant_id, url, events, created_by, created_at
+		FROM webhook_endpoints
+		WHERE restaurant_id = $1
+		ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := []*WebhookEndpoint{}
+	for rows.Next() {
+		var e WebhookEndpoint
+		if err := rows.Scan(&e.ID, &e.RestaurantID, &e.URL, pgArray(&e.Events), &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if e.Events == nil {
+			e.Events = []string{}
+		}
+		endpoints = append(endpoints, &e)
+	}
+
+	return endpoints, rows.Err()
+}
+
+// DeleteEndpoint removes the restaurant's endpoint and the deliveries queued for it
+func (s *WebhookStore) DeleteEndpoint(ctx context.Context, restaurantID, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND restaurant_id = $2`, id, restaurantID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// Enqueue queues the event for each of the restaurant's endpoints subscribed
+// to its type and returns how many that was
+func (s *WebhookStore) Enqueue(ctx context.Context, event *WebhookEvent) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, restaurant_id, event_id, event_type, payload)
+		SELECT id, restaurant_id, $2::uuid, $3::text, $4::jsonb
+		FROM webhook_endpoints
+		WHERE restaurant_id = $1 AND (cardinality(events) = 0 OR $3 = ANY(events))`
+
+	result, err := s.db.ExecContext(ctx, query, event.RestaurantID, event.ID, event.Type, []byte(event.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := result.RowsAffected()
+	return int(queued), err
+}
+
+// ClaimDue returns up to limit pending deliveries due by now with their
+// endpoint's URL and secret, and holds them for lease so several instances
+// never send the same one at once. A delivery whose worker dies comes due
+// again when the lease runs out.
+func (s *WebhookStore) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = $2
+		FROM webhook_endpoints e
+		WHERE e.id = d.endpoint_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, e.url, e.secret`
+
+	rows, err := s.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*WebhookDelivery{}
+	for rows.Next() {
+		var url, secret string
+		d, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		d.URL, d.Secret = url, secret
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt records sending a claimed delivery. An empty errMsg marks it
+// delivered; otherwise it's tried again at retryAt, or failed for good when
+// retryAt is nil. status is what the endpoint answered, 0 for no answer.
+func (s *WebhookStore) RecordAttempt(ctx context.Context, id int64, status int, errMsg string, retryAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+		    response_status = NULLIF($2, 0),
+		    last_error = NULLIF($3, ''),
+		    status = CASE WHEN $3 = '' THEN 'delivered' WHEN $4::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+		    delivered_at = CASE WHEN $3 = '' THEN NOW() END,
+		    next_attempt_at = COALESCE($4, next_attempt_at)
+		WHERE id = $1`
+
+	result, err := s.db.ExecContext(ctx, query, id, status, errMsg, retryAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the endpoint's latest deliveries, newest first
+func (s *WebhookStore) ListDeliveries(ctx context.Context, endpointID int64, limit int) ([]*WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeoutDuration)
+	defer cancel()
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.endpoint_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2`
+
+	rows, err := s.db.QueryContext(ctx, query, endpointID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	body := []byte(`{"type":"employee.created"}`)
+	header := Sign("whsec_test", now, body)
+
+	if err := Verify("whsec_test", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("verifying its own signature: %v", err)
+	}
+	if err := Verify("whsec_other", header, body, now, 5*time.Minute); err == nil {
+		t.Error("verified with the wrong secret")
+	}
+	if err := Verify("whsec_test", header, []byte(`{}`), now, 5*time.Minute); err == nil {
+		t.Error("verified a changed body")
+	}
+	if err := Verify("whsec_test", header, body, now.Add(time.Hour), 5*time.Minute); err == nil {
+		t.Error("verified a signature an hour old")
+	}
+}
+
+func TestSend(t *testing.T) {
+	var status int
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := NewSender()
+	d := Delivery{URL: server.URL, Secret: "whsec_test", EventID: "0b3a", EventType: "employee.updated", Body: []byte(`{"id":"0b3a"}`)}
+
+	status = http.StatusNoContent
+	code, err := sender.Send(context.Background(), d)
+	if err != nil || code != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v; want 204", code, err)
+	}
+	if got.Header.Get(EventHeader) != "employee.updated" || got.Header.Get(DeliveryHeader) != "0b3a" {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if err := Verify("whsec_test", got.Header.Get(SignatureHeader), gotBody, time.Now(), time.Minute); err != nil {
+		t.Errorf("the receiver can't verify the delivery: %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if code, err := sender.Send(context.Background(), d); err == nil || code != http.StatusInternalServerError {
+		t.Errorf("Send = %d, %v; want a 500 error", code, err)
+	}
+}
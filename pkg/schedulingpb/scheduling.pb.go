@@ -0,0 +1,701 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: scheduling/v1/scheduling.proto
+
+package schedulingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Shift struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ScheduleId      int64                  `protobuf:"varint,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	RestaurantId    int64                  `protobuf:"varint,3,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	ShiftTemplateId *int64                 `protobuf:"varint,4,opt,name=shift_template_id,json=shiftTemplateId,proto3,oneof" json:"shift_template_id,omitempty"`
+	RoleId          int64                  `protobuf:"varint,5,opt,name=role_id,json=roleId,proto3" json:"role_id,omitempty"`
+	EmployeeId      *int64                 `protobuf:"varint,6,opt,name=employee_id,json=employeeId,proto3,oneof" json:"employee_id,omitempty"`
+	// YYYY-MM-DD
+	ShiftDate string `protobuf:"bytes,7,opt,name=shift_date,json=shiftDate,proto3" json:"shift_date,omitempty"`
+	// HH:MM:SS
+	StartTime     string  `protobuf:"bytes,8,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string  `protobuf:"bytes,9,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Notes         string  `protobuf:"bytes,10,opt,name=notes,proto3" json:"notes,omitempty"`
+	EmployeeName  *string `protobuf:"bytes,11,opt,name=employee_name,json=employeeName,proto3,oneof" json:"employee_name,omitempty"`
+	RoleName      string  `protobuf:"bytes,12,opt,name=role_name,json=roleName,proto3" json:"role_name,omitempty"`
+	RoleColor     string  `protobuf:"bytes,13,opt,name=role_color,json=roleColor,proto3" json:"role_color,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shift) Reset() {
+	*x = Shift{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shift) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shift) ProtoMessage() {}
+
+func (x *Shift) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shift.ProtoReflect.Descriptor instead.
+func (*Shift) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Shift) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Shift) GetScheduleId() int64 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *Shift) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *Shift) GetShiftTemplateId() int64 {
+	if x != nil && x.ShiftTemplateId != nil {
+		return *x.ShiftTemplateId
+	}
+	return 0
+}
+
+func (x *Shift) GetRoleId() int64 {
+	if x != nil {
+		return x.RoleId
+	}
+	return 0
+}
+
+func (x *Shift) GetEmployeeId() int64 {
+	if x != nil && x.EmployeeId != nil {
+		return *x.EmployeeId
+	}
+	return 0
+}
+
+func (x *Shift) GetShiftDate() string {
+	if x != nil {
+		return x.ShiftDate
+	}
+	return ""
+}
+
+func (x *Shift) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Shift) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Shift) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Shift) GetEmployeeName() string {
+	if x != nil && x.EmployeeName != nil {
+		return *x.EmployeeName
+	}
+	return ""
+}
+
+func (x *Shift) GetRoleName() string {
+	if x != nil {
+		return x.RoleName
+	}
+	return ""
+}
+
+func (x *Shift) GetRoleColor() string {
+	if x != nil {
+		return x.RoleColor
+	}
+	return ""
+}
+
+type Schedule struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Id           int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	RestaurantId int64                  `protobuf:"varint,2,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	// YYYY-MM-DD
+	StartDate string `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate   string `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	// RFC 3339, empty while unpublished
+	PublishedAt   string `protobuf:"bytes,5,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Schedule) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Schedule) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *Schedule) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *Schedule) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *Schedule) GetPublishedAt() string {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return ""
+}
+
+type ListShiftsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RestaurantId  int64                  `protobuf:"varint,1,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	ScheduleId    int64                  `protobuf:"varint,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShiftsRequest) Reset() {
+	*x = ListShiftsRequest{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShiftsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShiftsRequest) ProtoMessage() {}
+
+func (x *ListShiftsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShiftsRequest.ProtoReflect.Descriptor instead.
+func (*ListShiftsRequest) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListShiftsRequest) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *ListShiftsRequest) GetScheduleId() int64 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+type ListShiftsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Shifts        []*Shift               `protobuf:"bytes,1,rep,name=shifts,proto3" json:"shifts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShiftsResponse) Reset() {
+	*x = ListShiftsResponse{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShiftsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShiftsResponse) ProtoMessage() {}
+
+func (x *ListShiftsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShiftsResponse.ProtoReflect.Descriptor instead.
+func (*ListShiftsResponse) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListShiftsResponse) GetShifts() []*Shift {
+	if x != nil {
+		return x.Shifts
+	}
+	return nil
+}
+
+type CreateShiftRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RestaurantId    int64                  `protobuf:"varint,1,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	ScheduleId      int64                  `protobuf:"varint,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	ShiftTemplateId *int64                 `protobuf:"varint,3,opt,name=shift_template_id,json=shiftTemplateId,proto3,oneof" json:"shift_template_id,omitempty"`
+	RoleId          int64                  `protobuf:"varint,4,opt,name=role_id,json=roleId,proto3" json:"role_id,omitempty"`
+	EmployeeId      *int64                 `protobuf:"varint,5,opt,name=employee_id,json=employeeId,proto3,oneof" json:"employee_id,omitempty"`
+	// YYYY-MM-DD
+	ShiftDate string `protobuf:"bytes,6,opt,name=shift_date,json=shiftDate,proto3" json:"shift_date,omitempty"`
+	// HH:MM
+	StartTime     string `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       string `protobuf:"bytes,8,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Notes         string `protobuf:"bytes,9,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShiftRequest) Reset() {
+	*x = CreateShiftRequest{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShiftRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShiftRequest) ProtoMessage() {}
+
+func (x *CreateShiftRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShiftRequest.ProtoReflect.Descriptor instead.
+func (*CreateShiftRequest) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateShiftRequest) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *CreateShiftRequest) GetScheduleId() int64 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+func (x *CreateShiftRequest) GetShiftTemplateId() int64 {
+	if x != nil && x.ShiftTemplateId != nil {
+		return *x.ShiftTemplateId
+	}
+	return 0
+}
+
+func (x *CreateShiftRequest) GetRoleId() int64 {
+	if x != nil {
+		return x.RoleId
+	}
+	return 0
+}
+
+func (x *CreateShiftRequest) GetEmployeeId() int64 {
+	if x != nil && x.EmployeeId != nil {
+		return *x.EmployeeId
+	}
+	return 0
+}
+
+func (x *CreateShiftRequest) GetShiftDate() string {
+	if x != nil {
+		return x.ShiftDate
+	}
+	return ""
+}
+
+func (x *CreateShiftRequest) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *CreateShiftRequest) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *CreateShiftRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+type AssignEmployeeRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	RestaurantId int64                  `protobuf:"varint,1,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	ShiftId      int64                  `protobuf:"varint,2,opt,name=shift_id,json=shiftId,proto3" json:"shift_id,omitempty"`
+	// Omit to unassign
+	EmployeeId    *int64 `protobuf:"varint,3,opt,name=employee_id,json=employeeId,proto3,oneof" json:"employee_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignEmployeeRequest) Reset() {
+	*x = AssignEmployeeRequest{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignEmployeeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignEmployeeRequest) ProtoMessage() {}
+
+func (x *AssignEmployeeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignEmployeeRequest.ProtoReflect.Descriptor instead.
+func (*AssignEmployeeRequest) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AssignEmployeeRequest) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *AssignEmployeeRequest) GetShiftId() int64 {
+	if x != nil {
+		return x.ShiftId
+	}
+	return 0
+}
+
+func (x *AssignEmployeeRequest) GetEmployeeId() int64 {
+	if x != nil && x.EmployeeId != nil {
+		return *x.EmployeeId
+	}
+	return 0
+}
+
+type PublishScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RestaurantId  int64                  `protobuf:"varint,1,opt,name=restaurant_id,json=restaurantId,proto3" json:"restaurant_id,omitempty"`
+	ScheduleId    int64                  `protobuf:"varint,2,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishScheduleRequest) Reset() {
+	*x = PublishScheduleRequest{}
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishScheduleRequest) ProtoMessage() {}
+
+func (x *PublishScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scheduling_v1_scheduling_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishScheduleRequest.ProtoReflect.Descriptor instead.
+func (*PublishScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_scheduling_v1_scheduling_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PublishScheduleRequest) GetRestaurantId() int64 {
+	if x != nil {
+		return x.RestaurantId
+	}
+	return 0
+}
+
+func (x *PublishScheduleRequest) GetScheduleId() int64 {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return 0
+}
+
+var File_scheduling_v1_scheduling_proto protoreflect.FileDescriptor
+
+const file_scheduling_v1_scheduling_proto_rawDesc = "" +
+	"\n" +
+	"\x1escheduling/v1/scheduling.proto\x12\rscheduling.v1\"\xda\x03\n" +
+	"\x05Shift\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\x03R\n" +
+	"scheduleId\x12#\n" +
+	"\rrestaurant_id\x18\x03 \x01(\x03R\frestaurantId\x12/\n" +
+	"\x11shift_template_id\x18\x04 \x01(\x03H\x00R\x0fshiftTemplateId\x88\x01\x01\x12\x17\n" +
+	"\arole_id\x18\x05 \x01(\x03R\x06roleId\x12$\n" +
+	"\vemployee_id\x18\x06 \x01(\x03H\x01R\n" +
+	"employeeId\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"shift_date\x18\a \x01(\tR\tshiftDate\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\b \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\t \x01(\tR\aendTime\x12\x14\n" +
+	"\x05notes\x18\n" +
+	" \x01(\tR\x05notes\x12(\n" +
+	"\remployee_name\x18\v \x01(\tH\x02R\femployeeName\x88\x01\x01\x12\x1b\n" +
+	"\trole_name\x18\f \x01(\tR\broleName\x12\x1d\n" +
+	"\n" +
+	"role_color\x18\r \x01(\tR\troleColorB\x14\n" +
+	"\x12_shift_template_idB\x0e\n" +
+	"\f_employee_idB\x10\n" +
+	"\x0e_employee_name\"\x9c\x01\n" +
+	"\bSchedule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12#\n" +
+	"\rrestaurant_id\x18\x02 \x01(\x03R\frestaurantId\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x03 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x04 \x01(\tR\aendDate\x12!\n" +
+	"\fpublished_at\x18\x05 \x01(\tR\vpublishedAt\"Y\n" +
+	"\x11ListShiftsRequest\x12#\n" +
+	"\rrestaurant_id\x18\x01 \x01(\x03R\frestaurantId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\x03R\n" +
+	"scheduleId\"B\n" +
+	"\x12ListShiftsResponse\x12,\n" +
+	"\x06shifts\x18\x01 \x03(\v2\x14.scheduling.v1.ShiftR\x06shifts\"\xdf\x02\n" +
+	"\x12CreateShiftRequest\x12#\n" +
+	"\rrestaurant_id\x18\x01 \x01(\x03R\frestaurantId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\x03R\n" +
+	"scheduleId\x12/\n" +
+	"\x11shift_template_id\x18\x03 \x01(\x03H\x00R\x0fshiftTemplateId\x88\x01\x01\x12\x17\n" +
+	"\arole_id\x18\x04 \x01(\x03R\x06roleId\x12$\n" +
+	"\vemployee_id\x18\x05 \x01(\x03H\x01R\n" +
+	"employeeId\x88\x01\x01\x12\x1d\n" +
+	"\n" +
+	"shift_date\x18\x06 \x01(\tR\tshiftDate\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\a \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\b \x01(\tR\aendTime\x12\x14\n" +
+	"\x05notes\x18\t \x01(\tR\x05notesB\x14\n" +
+	"\x12_shift_template_idB\x0e\n" +
+	"\f_employee_id\"\x8d\x01\n" +
+	"\x15AssignEmployeeRequest\x12#\n" +
+	"\rrestaurant_id\x18\x01 \x01(\x03R\frestaurantId\x12\x19\n" +
+	"\bshift_id\x18\x02 \x01(\x03R\ashiftId\x12$\n" +
+	"\vemployee_id\x18\x03 \x01(\x03H\x00R\n" +
+	"employeeId\x88\x01\x01B\x0e\n" +
+	"\f_employee_id\"^\n" +
+	"\x16PublishScheduleRequest\x12#\n" +
+	"\rrestaurant_id\x18\x01 \x01(\x03R\frestaurantId\x12\x1f\n" +
+	"\vschedule_id\x18\x02 \x01(\x03R\n" +
+	"scheduleId2\xcf\x02\n" +
+	"\x11SchedulingService\x12Q\n" +
+	"\n" +
+	"ListShifts\x12 .scheduling.v1.ListShiftsRequest\x1a!.scheduling.v1.ListShiftsResponse\x12F\n" +
+	"\vCreateShift\x12!.scheduling.v1.CreateShiftRequest\x1a\x14.scheduling.v1.Shift\x12L\n" +
+	"\x0eAssignEmployee\x12$.scheduling.v1.AssignEmployeeRequest\x1a\x14.scheduling.v1.Shift\x12Q\n" +
+	"\x0fPublishSchedule\x12%.scheduling.v1.PublishScheduleRequest\x1a\x17.scheduling.v1.ScheduleB8Z6github.com/balebbae/RESA/pkg/schedulingpb;schedulingpbb\x06proto3"
+
+var (
+	file_scheduling_v1_scheduling_proto_rawDescOnce sync.Once
+	file_scheduling_v1_scheduling_proto_rawDescData []byte
+)
+
+func file_scheduling_v1_scheduling_proto_rawDescGZIP() []byte {
+	file_scheduling_v1_scheduling_proto_rawDescOnce.Do(func() {
+		file_scheduling_v1_scheduling_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_scheduling_v1_scheduling_proto_rawDesc), len(file_scheduling_v1_scheduling_proto_rawDesc)))
+	})
+	return file_scheduling_v1_scheduling_proto_rawDescData
+}
+
+var file_scheduling_v1_scheduling_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_scheduling_v1_scheduling_proto_goTypes = []any{
+	(*Shift)(nil),                  // 0: scheduling.v1.Shift
+	(*Schedule)(nil),               // 1: scheduling.v1.Schedule
+	(*ListShiftsRequest)(nil),      // 2: scheduling.v1.ListShiftsRequest
+	(*ListShiftsResponse)(nil),     // 3: scheduling.v1.ListShiftsResponse
+	(*CreateShiftRequest)(nil),     // 4: scheduling.v1.CreateShiftRequest
+	(*AssignEmployeeRequest)(nil),  // 5: scheduling.v1.AssignEmployeeRequest
+	(*PublishScheduleRequest)(nil), // 6: scheduling.v1.PublishScheduleRequest
+}
+var file_scheduling_v1_scheduling_proto_depIdxs = []int32{
+	0, // 0: scheduling.v1.ListShiftsResponse.shifts:type_name -> scheduling.v1.Shift
+	2, // 1: scheduling.v1.SchedulingService.ListShifts:input_type -> scheduling.v1.ListShiftsRequest
+	4, // 2: scheduling.v1.SchedulingService.CreateShift:input_type -> scheduling.v1.CreateShiftRequest
+	5, // 3: scheduling.v1.SchedulingService.AssignEmployee:input_type -> scheduling.v1.AssignEmployeeRequest
+	6, // 4: scheduling.v1.SchedulingService.PublishSchedule:input_type -> scheduling.v1.PublishScheduleRequest
+	3, // 5: scheduling.v1.SchedulingService.ListShifts:output_type -> scheduling.v1.ListShiftsResponse
+	0, // 6: scheduling.v1.SchedulingService.CreateShift:output_type -> scheduling.v1.Shift
+	0, // 7: scheduling.v1.SchedulingService.AssignEmployee:output_type -> scheduling.v1.Shift
+	1, // 8: scheduling.v1.SchedulingService.PublishSchedule:output_type -> scheduling.v1.Schedule
+	5, // [5:9] is the sub-list for method output_type
+	1, // [1:5] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_scheduling_v1_scheduling_proto_init() }
+func file_scheduling_v1_scheduling_proto_init() {
+	if File_scheduling_v1_scheduling_proto != nil {
+		return
+	}
+	file_scheduling_v1_scheduling_proto_msgTypes[0].OneofWrappers = []any{}
+	file_scheduling_v1_scheduling_proto_msgTypes[4].OneofWrappers = []any{}
+	file_scheduling_v1_scheduling_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scheduling_v1_scheduling_proto_rawDesc), len(file_scheduling_v1_scheduling_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_scheduling_v1_scheduling_proto_goTypes,
+		DependencyIndexes: file_scheduling_v1_scheduling_proto_depIdxs,
+		MessageInfos:      file_scheduling_v1_scheduling_proto_msgTypes,
+	}.Build()
+	File_scheduling_v1_scheduling_proto = out.File
+	file_scheduling_v1_scheduling_proto_goTypes = nil
+	file_scheduling_v1_scheduling_proto_depIdxs = nil
+}
@@ -0,0 +1,251 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: scheduling/v1/scheduling.proto
+
+package schedulingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SchedulingService_ListShifts_FullMethodName      = "/scheduling.v1.SchedulingService/ListShifts"
+	SchedulingService_CreateShift_FullMethodName     = "/scheduling.v1.SchedulingService/CreateShift"
+	SchedulingService_AssignEmployee_FullMethodName  = "/scheduling.v1.SchedulingService/AssignEmployee"
+	SchedulingService_PublishSchedule_FullMethodName = "/scheduling.v1.SchedulingService/PublishSchedule"
+)
+
+// SchedulingServiceClient is the client API for SchedulingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SchedulingService exposes the core scheduling operations to internal consumers.
+// Calls must carry the same JWT as the HTTP API in the "authorization" metadata
+// ("Bearer <token>"); restaurants are only visible to their owner.
+type SchedulingServiceClient interface {
+	// ListShifts returns every shift in a schedule ordered by date and start time.
+	ListShifts(ctx context.Context, in *ListShiftsRequest, opts ...grpc.CallOption) (*ListShiftsResponse, error)
+	// CreateShift adds a shift to a schedule.
+	CreateShift(ctx context.Context, in *CreateShiftRequest, opts ...grpc.CallOption) (*Shift, error)
+	// AssignEmployee assigns (or, with no employee_id, unassigns) a shift.
+	AssignEmployee(ctx context.Context, in *AssignEmployeeRequest, opts ...grpc.CallOption) (*Shift, error)
+	// PublishSchedule marks a schedule as published.
+	PublishSchedule(ctx context.Context, in *PublishScheduleRequest, opts ...grpc.CallOption) (*Schedule, error)
+}
+
+type schedulingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSchedulingServiceClient(cc grpc.ClientConnInterface) SchedulingServiceClient {
+	return &schedulingServiceClient{cc}
+}
+
+func (c *schedulingServiceClient) ListShifts(ctx context.Context, in *ListShiftsRequest, opts ...grpc.CallOption) (*ListShiftsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListShiftsResponse)
+	err := c.cc.Invoke(ctx, SchedulingService_ListShifts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulingServiceClient) CreateShift(ctx context.Context, in *CreateShiftRequest, opts ...grpc.CallOption) (*Shift, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shift)
+	err := c.cc.Invoke(ctx, SchedulingService_CreateShift_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulingServiceClient) AssignEmployee(ctx context.Context, in *AssignEmployeeRequest, opts ...grpc.CallOption) (*Shift, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Shift)
+	err := c.cc.Invoke(ctx, SchedulingService_AssignEmployee_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *schedulingServiceClient) PublishSchedule(ctx context.Context, in *PublishScheduleRequest, opts ...grpc.CallOption) (*Schedule, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Schedule)
+	err := c.cc.Invoke(ctx, SchedulingService_PublishSchedule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SchedulingServiceServer is the server API for SchedulingService service.
+// All implementations must embed UnimplementedSchedulingServiceServer
+// for forward compatibility.
+//
+// SchedulingService exposes the core scheduling operations to internal consumers.
+// Calls must carry the same JWT as the HTTP API in the "authorization" metadata
+// ("Bearer <token>"); restaurants are only visible to their owner.
+type SchedulingServiceServer interface {
+	// ListShifts returns every shift in a schedule ordered by date and start time.
+	ListShifts(context.Context, *ListShiftsRequest) (*ListShiftsResponse, error)
+	// CreateShift adds a shift to a schedule.
+	CreateShift(context.Context, *CreateShiftRequest) (*Shift, error)
+	// AssignEmployee assigns (or, with no employee_id, unassigns) a shift.
+	AssignEmployee(context.Context, *AssignEmployeeRequest) (*Shift, error)
+	// PublishSchedule marks a schedule as published.
+	PublishSchedule(context.Context, *PublishScheduleRequest) (*Schedule, error)
+	mustEmbedUnimplementedSchedulingServiceServer()
+}
+
+// UnimplementedSchedulingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSchedulingServiceServer struct{}
+
+func (UnimplementedSchedulingServiceServer) ListShifts(context.Context, *ListShiftsRequest) (*ListShiftsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShifts not implemented")
+}
+func (UnimplementedSchedulingServiceServer) CreateShift(context.Context, *CreateShiftRequest) (*Shift, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShift not implemented")
+}
+func (UnimplementedSchedulingServiceServer) AssignEmployee(context.Context, *AssignEmployeeRequest) (*Shift, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignEmployee not implemented")
+}
+func (UnimplementedSchedulingServiceServer) PublishSchedule(context.Context, *PublishScheduleRequest) (*Schedule, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishSchedule not implemented")
+}
+func (UnimplementedSchedulingServiceServer) mustEmbedUnimplementedSchedulingServiceServer() {}
+func (UnimplementedSchedulingServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeSchedulingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SchedulingServiceServer will
+// result in compilation errors.
+type UnsafeSchedulingServiceServer interface {
+	mustEmbedUnimplementedSchedulingServiceServer()
+}
+
+func RegisterSchedulingServiceServer(s grpc.ServiceRegistrar, srv SchedulingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSchedulingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SchedulingService_ServiceDesc, srv)
+}
+
+func _SchedulingService_ListShifts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShiftsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulingServiceServer).ListShifts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SchedulingService_ListShifts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulingServiceServer).ListShifts(ctx, req.(*ListShiftsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SchedulingService_CreateShift_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShiftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulingServiceServer).CreateShift(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SchedulingService_CreateShift_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulingServiceServer).CreateShift(ctx, req.(*CreateShiftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SchedulingService_AssignEmployee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignEmployeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulingServiceServer).AssignEmployee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SchedulingService_AssignEmployee_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulingServiceServer).AssignEmployee(ctx, req.(*AssignEmployeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SchedulingService_PublishSchedule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishScheduleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SchedulingServiceServer).PublishSchedule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SchedulingService_PublishSchedule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SchedulingServiceServer).PublishSchedule(ctx, req.(*PublishScheduleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SchedulingService_ServiceDesc is the grpc.ServiceDesc for SchedulingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SchedulingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "scheduling.v1.SchedulingService",
+	HandlerType: (*SchedulingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListShifts",
+			Handler:    _SchedulingService_ListShifts_Handler,
+		},
+		{
+			MethodName: "CreateShift",
+			Handler:    _SchedulingService_CreateShift_Handler,
+		},
+		{
+			MethodName: "AssignEmployee",
+			Handler:    _SchedulingService_AssignEmployee_Handler,
+		},
+		{
+			MethodName: "PublishSchedule",
+			Handler:    _SchedulingService_PublishSchedule_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "scheduling/v1/scheduling.proto",
+}